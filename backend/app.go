@@ -8,28 +8,55 @@ import (
 	"syscall"
 	"time"
 
+	docs "github.com/consensuslabs/pavilion-network/backend/docs/api"
+	"github.com/consensuslabs/pavilion-network/backend/internal/accesslog"
+	"github.com/consensuslabs/pavilion-network/backend/internal/activitypub"
+	"github.com/consensuslabs/pavilion-network/backend/internal/admin"
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/audit"
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/block"
 	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
 	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
 	"github.com/consensuslabs/pavilion-network/backend/internal/config"
 	"github.com/consensuslabs/pavilion-network/backend/internal/database"
 	"github.com/consensuslabs/pavilion-network/backend/internal/database/scylladb"
+	"github.com/consensuslabs/pavilion-network/backend/internal/embed"
+	"github.com/consensuslabs/pavilion-network/backend/internal/entitlement"
+	"github.com/consensuslabs/pavilion-network/backend/internal/federation"
+	"github.com/consensuslabs/pavilion-network/backend/internal/feed"
+	"github.com/consensuslabs/pavilion-network/backend/internal/follow"
+	"github.com/consensuslabs/pavilion-network/backend/internal/geoip"
+	"github.com/consensuslabs/pavilion-network/backend/internal/graphql"
 	"github.com/consensuslabs/pavilion-network/backend/internal/health"
+	"github.com/consensuslabs/pavilion-network/backend/internal/history"
 	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/jobs"
+	"github.com/consensuslabs/pavilion-network/backend/internal/live"
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/mail"
+	"github.com/consensuslabs/pavilion-network/backend/internal/moderation"
 	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification/webpush"
+	"github.com/consensuslabs/pavilion-network/backend/internal/p2p"
+	"github.com/consensuslabs/pavilion-network/backend/internal/sharing"
 	"github.com/consensuslabs/pavilion-network/backend/internal/storage"
 	"github.com/consensuslabs/pavilion-network/backend/internal/storage/ipfs"
-	"github.com/consensuslabs/pavilion-network/backend/internal/storage/s3"
-	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+	syncapi "github.com/consensuslabs/pavilion-network/backend/internal/sync"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
+	"github.com/consensuslabs/pavilion-network/backend/internal/user"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/scan"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/tempfile"
+	"github.com/consensuslabs/pavilion-network/backend/internal/webhook"
 	"github.com/consensuslabs/pavilion-network/backend/migrations"
 	"github.com/gin-gonic/gin"
 	"github.com/gocql/gocql"
+	"github.com/prometheus/client_golang/prometheus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"gorm.io/gorm"
 )
 
@@ -46,7 +73,14 @@ type App struct {
 	refreshTokens       auth.RefreshTokenService
 	logger              logger.Logger
 	ipfsService         storage.IPFSService
-	s3Service           storage.S3Service
+	storageBackend      storage.Backend
+	replicationQueue    *video.ReplicationQueue
+	p2pNode             *p2p.Node
+	p2pHandler          *p2p.Handler
+	uploadJobs          *video.JobTracker
+	uploadEvents        *video.UploadEvents
+	transcodeScheduler  *video.TranscodeScheduler
+	tempManager         tempfile.TempFileManager
 	videoHandler        *video.VideoHandler
 	healthHandler       *health.Handler
 	httpHandler         httpHandler.ResponseHandler
@@ -54,8 +88,48 @@ type App struct {
 	commentHandler      *comment.Handler
 	notificationService notification.NotificationService
 	notificationHandler *notification.Handler
+	notificationMetrics *notification.MetricsHandler
+	notificationMonitor *notification.LagMonitor
+	pushService         *notification.PushService
+	digestScheduler     *notification.DigestScheduler
+	commentConsumer     *notification.CommentCountConsumer
+	commentReconciler   *video.CommentCountReconciler
+	videoCleanup        *notification.VideoCleanupConsumer
+	followerFanout      *notification.FollowerFanoutConsumer
+	syncHandler         *syncapi.Handler
+	followHandler       *follow.Handler
+	blockHandler        *block.Handler
+	userHandler         *user.Handler
+	entitlementHandler  *entitlement.Handler
+	rateLimiter         *httpHandler.RateLimiter
+	responseCache       *httpHandler.ResponseCache
+	idempotency         *httpHandler.Idempotency
+	moderationHandler   *moderation.Handler
+	accessLogHandler    *accesslog.Handler
+	accessLogPruner     *accesslog.Pruner
+	jobRunner           *jobs.Runner
+	jobsHandler         *jobs.Handler
+	webhookHandler      *webhook.Handler
+	webhookDispatcher   *webhook.Dispatcher
+	federationHandler   *federation.Handler
+	federationPoller    *federation.Poller
+	activityPubHandler  *activitypub.Handler
+	activityPubQueue    *activitypub.Deliverer
+	feedHandler         *feed.Handler
+	sharingHandler      *sharing.Handler
+	embedHandler        *embed.Handler
+	geoIP               *geoip.Reader
+	liveHandler         *live.Handler
+	liveIngest          *live.Ingest
+	adminHandler        *admin.Handler
+	auditHandler        *audit.Handler
+	orphanCleaner       *video.OrphanCleaner
+	storageTiering      *video.StorageTiering
+	historyHandler      *history.Handler
+	graphqlHandler      *graphql.Handler
 	scyllaSession       *gocql.Session
 	scyllaManager       *scylladb.SchemaManager
+	tracingShutdown     tracing.ShutdownFunc
 }
 
 // NewApp creates a new application instance
@@ -66,26 +140,8 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 		Format:      cfg.Logging.Format,
 		Output:      cfg.Logging.Output,
 		Development: cfg.Logging.Development,
-		File: struct {
-			Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
-			Path    string `mapstructure:"path" yaml:"path"`
-			Rotate  bool   `mapstructure:"rotate" yaml:"rotate"`
-			MaxSize string `mapstructure:"maxSize" yaml:"maxSize"`
-			MaxAge  string `mapstructure:"maxAge" yaml:"maxAge"`
-		}{
-			Enabled: cfg.Logging.File.Enabled,
-			Path:    cfg.Logging.File.Path,
-			Rotate:  cfg.Logging.File.Rotate,
-			MaxSize: cfg.Logging.File.MaxSize,
-			MaxAge:  cfg.Logging.File.MaxAge,
-		},
-		Sampling: struct {
-			Initial    int `mapstructure:"initial" yaml:"initial"`
-			Thereafter int `mapstructure:"thereafter" yaml:"thereafter"`
-		}{
-			Initial:    cfg.Logging.Sampling.Initial,
-			Thereafter: cfg.Logging.Sampling.Thereafter,
-		},
+		File:        cfg.Logging.File,
+		Sampling:    cfg.Logging.Sampling,
 	}
 
 	loggerService, err := logger.NewLogger(loggerConfig)
@@ -93,6 +149,12 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize logger: %v", err)
 	}
 
+	// Initialize tracing before any instrumented client is created
+	tracingShutdown, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %v", err)
+	}
+
 	// Initialize response handler
 	responseHandler := httpHandler.NewResponseHandler(loggerService)
 
@@ -109,6 +171,21 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 	}
 	loggerService.LogInfo("Database migrations completed successfully", nil)
 
+	// Initialize the background job runner. Subsystems register their job
+	// types and schedules below, and the runner starts once they all have.
+	var jobRunner *jobs.Runner
+	if cfg.Jobs.Enabled {
+		jobRunner = jobs.NewRunner(db, jobs.Config{
+			Workers:       cfg.Jobs.Workers,
+			PollInterval:  cfg.Jobs.PollInterval,
+			MaxAttempts:   cfg.Jobs.MaxAttempts,
+			RetryDelay:    cfg.Jobs.RetryDelay,
+			MaxRetryDelay: cfg.Jobs.MaxRetryDelay,
+			Timeout:       cfg.Jobs.Timeout,
+			Retention:     cfg.Jobs.Retention,
+		}, loggerService)
+	}
+
 	// Initialize cache service
 	redisConfig := &cache.Config{
 		Addr:     cfg.Redis.Addr,
@@ -120,6 +197,18 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("failed to initialize Redis service: %v", err)
 	}
 
+	// Cache video records, comment counts and related video rankings in front
+	// of CockroachDB and ScyllaDB
+	var videoCache *video.VideoCache
+	var commentCounts *comment.CountCache
+	var relatedRankings *cache.ReadThrough
+	if cfg.ReadCache.Enabled {
+		cacheMetrics := cache.NewMetrics(prometheus.DefaultRegisterer)
+		videoCache = video.NewVideoCache(cacheService, cfg.ReadCache.VideoTTL, cacheMetrics)
+		commentCounts = comment.NewCountCache(cacheService, cfg.ReadCache.CommentCountTTL, cacheMetrics)
+		relatedRankings = cache.NewReadThrough(cacheService, "related", cfg.ReadCache.RelatedTTL, cacheMetrics)
+	}
+
 	// Initialize IPFS service
 	ipfsConfig := &storage.IPFSConfig{
 		APIAddress: cfg.Storage.IPFS.APIAddress,
@@ -128,37 +217,31 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 	ipfsService := ipfs.NewService(ipfsConfig, loggerService)
 	ipfsAdapter := storage.NewVideoIPFSAdapter(ipfsService)
 
-	// Initialize S3 service
-	s3Config := &videostorage.Config{
-		Endpoint:        cfg.Storage.S3.Endpoint,
-		AccessKeyID:     cfg.Storage.S3.AccessKeyID,
-		SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
-		UseSSL:          cfg.Storage.S3.UseSSL,
-		Region:          cfg.Storage.S3.Region,
-		Bucket:          cfg.Storage.S3.Bucket,
-	}
-
-	// Debug log for S3 configuration
-	loggerService.LogInfo("S3 Configuration in App", map[string]interface{}{
-		"endpoint":        cfg.Storage.S3.Endpoint,
-		"region":          cfg.Storage.S3.Region,
-		"bucket":          cfg.Storage.S3.Bucket,
-		"useSSL":          cfg.Storage.S3.UseSSL,
-		"accessKeyID":     cfg.Storage.S3.AccessKeyID,
-		"secretAccessKey": cfg.Storage.S3.SecretAccessKey != "", // Don't log the actual secret
-		"accessKeyLength": len(cfg.Storage.S3.AccessKeyID),
-		"secretKeyLength": len(cfg.Storage.S3.SecretAccessKey),
-	})
+	// Initialize the storage backend for videos and avatars
+	storageBackend, err := storage.NewBackend(cfg, loggerService)
+	if err != nil {
+		return nil, err
+	}
 
-	s3Service, err := s3.NewService(s3Config, loggerService)
+	// Initialize the CDN, if stored files are served through one. Links to
+	// captions and avatars then point at the CDN too.
+	cdnService, err := storage.NewCDN(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize S3 service: %v", err)
+		return nil, err
+	}
+	fileURLs := storageBackend
+	if cdnService != nil {
+		fileURLs = storage.WithCDN(storageBackend, cdnService)
 	}
 
-	// Initialize temporary file manager
+	// Initialize temporary file manager; directories left behind by crashed
+	// processes are swept before uploads start
 	tempConfig := &tempfile.Config{
-		BaseDir:     "/tmp/videos",
+		BaseDir:     cfg.Video.Temp.Dir,
 		Permissions: 0755,
+		StaleAfter:  cfg.Video.Temp.StaleAfter,
+		MaxBytes:    cfg.Video.Temp.MaxBytes,
+		Metrics:     tempfile.NewMetrics(prometheus.DefaultRegisterer),
 	}
 	tempManager, err := tempfile.NewManager(tempConfig, loggerService)
 	if err != nil {
@@ -167,42 +250,150 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 
 	// Initialize FFmpeg service
 	ffmpegConfig := &ffmpeg.Config{
-		Path:        cfg.Ffmpeg.Path,
-		ProbePath:   cfg.Ffmpeg.ProbePath,
-		VideoCodec:  cfg.Ffmpeg.VideoCodec,
-		AudioCodec:  cfg.Ffmpeg.AudioCodec,
-		Preset:      cfg.Ffmpeg.Preset,
-		OutputPath:  cfg.Ffmpeg.OutputPath,
-		Resolutions: cfg.Ffmpeg.Resolutions,
+		Path:          cfg.Ffmpeg.Path,
+		ProbePath:     cfg.Ffmpeg.ProbePath,
+		VideoCodec:    cfg.Ffmpeg.VideoCodec,
+		AudioCodec:    cfg.Ffmpeg.AudioCodec,
+		Preset:        cfg.Ffmpeg.Preset,
+		OutputPath:    cfg.Ffmpeg.OutputPath,
+		Resolutions:   cfg.Ffmpeg.Resolutions,
+		TimeoutFactor: cfg.Ffmpeg.TimeoutFactor,
+		MinTimeout:    cfg.Ffmpeg.MinTimeout,
+		ProbeTimeout:  cfg.Ffmpeg.ProbeTimeout,
+		HWAccel:       cfg.Ffmpeg.HWAccel,
+		VAAPIDevice:   cfg.Ffmpeg.VAAPIDevice,
+		Profiles:      cfg.Ffmpeg.Profiles,
+		Audio:         cfg.Ffmpeg.Audio,
+		Preview:       cfg.Ffmpeg.Preview,
 	}
 	ffmpegService := ffmpeg.NewService(ffmpegConfig, loggerService)
+	// Detect the hardware encoder now, so an unavailable accelerator is reported at startup
+	ffmpegService.Encoder(ctx)
+
+	// Announce replicated videos through the IPFS node's libp2p host when p2p is enabled
+	var p2pNode *p2p.Node
+	var peerAnnouncer video.PeerAnnouncer
+	if cfg.P2P.Enabled {
+		p2pNode = p2p.NewNode(cfg.Storage.IPFS.APIAddress, p2p.Config{
+			Topic:          cfg.P2P.Topic,
+			PublicURL:      cfg.P2P.PublicURL,
+			ProvideTimeout: cfg.P2P.ProvideTimeout,
+		}, loggerService)
+		p2pNode.Start()
+		peerAnnouncer = p2pNode
+	}
 
-	// Initialize video service
-	videoService := video.NewVideoService(
-		db,
+	// Initialize IPFS replication queue so uploads are pinned in the background
+	replicationQueue := video.NewReplicationQueue(
+		video.NewGormReplicationStore(db),
 		ipfsAdapter,
-		s3Service,
+		storageBackend,
+		peerAnnouncer,
+		video.ReplicationConfig{
+			Workers:        cfg.Storage.IPFS.Replication.Workers,
+			QueueSize:      cfg.Storage.IPFS.Replication.QueueSize,
+			MaxRetries:     cfg.Storage.IPFS.Replication.MaxRetries,
+			RetryDelay:     cfg.Storage.IPFS.Replication.RetryDelay,
+			RescanInterval: cfg.Storage.IPFS.Replication.RescanInterval,
+			StaleAfter:     cfg.Storage.IPFS.Replication.StaleAfter,
+		},
+		video.NewLoggerAdapter(loggerService),
+	)
+	// Start also queues files left S3 only, now and every rescanInterval
+	replicationQueue.Start()
+
+	// Initialize the transcode scheduler shared by all uploads
+	transcodeScheduler := video.NewTranscodeScheduler(
+		video.TranscodeSchedulerConfig{
+			Workers:    cfg.Video.Transcode.Workers,
+			MaxBacklog: cfg.Video.Transcode.MaxBacklog,
+			RetryAfter: cfg.Video.Transcode.RetryAfter,
+		},
+		video.NewTranscodeMetrics(prometheus.DefaultRegisterer),
+	)
+	transcodeScheduler.Start()
+
+	videoLimits := video.LimitsConfig{
+		MaxFileSize:        cfg.Video.MaxSize,
+		MinTitleLength:     cfg.Video.MinTitleLength,
+		MaxTitleLength:     cfg.Video.MaxTitleLength,
+		MaxDescLength:      cfg.Video.MaxDescLength,
+		AllowedFormats:     cfg.Video.AllowedFormats,
+		MaxDuration:        cfg.Video.MaxDuration,
+		AllowedVideoCodecs: cfg.Video.AllowedVideoCodecs,
+	}
+
+	// Initialize the content scanner, if uploads are scanned
+	var contentScanner *video.ContentScanner
+	if scanConfig := cfg.Video.Scan; scanConfig.Enabled {
+		var scanner scan.Scanner = scan.NewClamAV(scanConfig.ClamAV.Address, scanConfig.Timeout)
+		if scanConfig.Provider == scan.ProviderHTTP {
+			scanner = scan.NewHTTP(scanConfig.HTTP.URL, scanConfig.HTTP.Token, scanConfig.Timeout)
+		}
+		contentScanner = &video.ContentScanner{
+			Scanner:  scanner,
+			Action:   video.ScanAction(scanConfig.Action),
+			FailOpen: scanConfig.FailOpen,
+		}
+	}
+
+	// Initialize video service; shutdown waits on its tracked jobs
+	uploadJobs := video.NewJobTracker()
+	uploadEvents := video.NewUploadEvents()
+	videoPurger := video.NewPurger(db, storageBackend, ipfsService)
+	videoRepository := video.NewGormVideoRepository(db)
+	videoService := video.NewVideoService(
+		videoRepository,
+		replicationQueue,
+		storageBackend,
 		ffmpegService,
+		videoLimits,
+		contentScanner,
 		tempManager,
+		uploadJobs,
+		transcodeScheduler,
+		videoPurger,
+		videoCache,
+		cdnService,
+		uploadEvents,
 		video.NewLoggerAdapter(loggerService),
 	)
 
+	// Deleted videos can be restored until the cleanup purges them
+	var trashRetention time.Duration
+	if cfg.Video.Cleanup.Enabled {
+		trashRetention = time.Duration(cfg.Video.Cleanup.DeletedRetentionDays) * 24 * time.Hour
+	}
+
+	// Storage tiering needs storage classes, which only the S3 backend has.
+	// It is created even when disabled, to regenerate dropped renditions and
+	// to report.
+	var storageTiering *video.StorageTiering
+	var tieringService video.TieringService
+	if tierer, ok := storageBackend.(video.StorageTierer); ok {
+		storageTiering = video.NewStorageTiering(
+			db,
+			tierer,
+			videoService,
+			videoCache,
+			video.TieringConfig{
+				Interval:            cfg.Video.Tiering.Interval,
+				StorageClass:        cfg.Video.Tiering.StorageClass,
+				OriginalsAfter:      time.Duration(cfg.Video.Tiering.OriginalsAfterDays) * 24 * time.Hour,
+				RenditionsIdleAfter: time.Duration(cfg.Video.Tiering.RenditionsIdleDays) * 24 * time.Hour,
+				BatchSize:           cfg.Video.Tiering.BatchSize,
+				DryRun:              cfg.Video.Tiering.DryRun,
+			},
+			video.NewTieringMetrics(prometheus.DefaultRegisterer),
+			video.NewLoggerAdapter(loggerService),
+		)
+		tieringService = storageTiering
+	}
+
 	// Initialize video app context
 	videoApp := &video.App{
 		Config: &video.Config{
-			Video: struct {
-				MaxFileSize    int64    `yaml:"max_file_size"`
-				MinTitleLength int      `yaml:"min_title_length"`
-				MaxTitleLength int      `yaml:"max_title_length"`
-				MaxDescLength  int      `yaml:"max_desc_length"`
-				AllowedFormats []string `yaml:"allowed_formats"`
-			}{
-				MaxFileSize:    cfg.Video.MaxSize,
-				MinTitleLength: cfg.Video.MinTitleLength,
-				MaxTitleLength: cfg.Video.MaxTitleLength,
-				MaxDescLength:  cfg.Video.MaxDescLength,
-				AllowedFormats: cfg.Video.AllowedFormats,
-			},
+			Video: videoLimits,
 			FFmpeg: video.FfmpegConfig{
 				Path:        cfg.Ffmpeg.Path,
 				ProbePath:   cfg.Ffmpeg.ProbePath,
@@ -217,17 +408,41 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 		IPFS:                ipfsAdapter,
 		ResponseHandler:     responseHandler,
 		Video:               videoService,
+		Streams:             storageBackend,
+		Captions:            video.NewCaptionService(videoRepository, fileURLs, ffmpegService, tempManager, videoCache, video.NewLoggerAdapter(loggerService)),
 		NotificationService: nil, // Will be set later after notification service is initialized
+		Trash:               video.NewTrashService(videoRepository, trashRetention, videoCache, video.NewLoggerAdapter(loggerService)),
+		Transcodes:          transcodeScheduler,
+		TempSpace:           tempManager,
+		UploadEvents:        uploadEvents,
+		Tiering:             tieringService,
+		CDN:                 cdnService,
+		Files:               storageBackend,
+		Sources: video.SourceConfig{
+			IPFSGateway: cfg.Storage.IPFS.Gateway,
+			IPFSPeers:   cfg.Storage.IPFS.Peers,
+		},
 	}
 
 	// Initialize video handler
 	videoHandler := video.NewVideoHandler(videoApp)
 
-	// Initialize health handler
-	healthHandler := health.NewHandler(responseHandler)
-
-	// Initialize router
-	router := gin.Default()
+	// Initialize health handler; dependencies are registered as they are connected
+	healthHandler := health.NewHandler(responseHandler, cfg.Health.Timeout)
+	healthHandler.Register(health.Dependency{Name: "cockroachdb", Critical: true, Check: dbService.Ping})
+	healthHandler.Register(health.Dependency{Name: "redis", Critical: true, Check: cacheService.Ping})
+	healthHandler.Register(health.Dependency{Name: cfg.Storage.Backend, Critical: true, Check: storageBackend.Ping})
+	healthHandler.Register(health.Dependency{Name: "ipfs", Check: ipfsService.Ping})
+	healthHandler.Register(health.Dependency{Name: "transcode_queue", Check: transcodeScheduler.Check})
+
+	// Initialize router. Requests are logged and panics recovered by our own
+	// middleware (see setupRoutes) rather than gin's, which print to stdout.
+	router := gin.New()
+	// Only the configured proxies may set the client IP with X-Forwarded-For,
+	// which the per-IP rate limits rely on
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %v", err)
+	}
 
 	// Initialize JWT service
 	authConfig := auth.NewConfigFromAuthConfig(&cfg.Auth)
@@ -238,28 +453,91 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 
 	// Initialize auth service
 	authService := auth.NewService(db, jwtService, refreshTokens, authConfig, loggerService)
+	mailer := mail.NewMailer(&mail.Config{
+		Host:     cfg.Email.SMTP.Host,
+		Port:     cfg.Email.SMTP.Port,
+		Username: cfg.Email.SMTP.Username,
+		Password: cfg.Email.SMTP.Password,
+		From:     cfg.Email.From,
+	}, loggerService)
+	authService.SetMailer(mailer)
+
+	// Promote the configured admin accounts
+	if err := authService.EnsureAdmins(cfg.Auth.AdminEmails); err != nil {
+		return nil, fmt.Errorf("failed to bootstrap admin accounts: %v", err)
+	}
+
+	// Throttle requests per client with token buckets shared through Redis
+	rateLimiter := httpHandler.NewRateLimiter(cacheService, cfg.RateLimit, responseHandler, loggerService)
+
+	// Serve hot list responses from Redis until a write invalidates them
+	responseCache := httpHandler.NewResponseCache(cacheService, cfg.HTTPCache, loggerService)
+
+	// Replay the responses of uploads and comments retried with the same Idempotency-Key
+	idempotency := httpHandler.NewIdempotency(cacheService, cfg.Idempotency, responseHandler, loggerService)
 
 	// Initialize auth handler
 	authHandler := auth.NewHandler(authService, responseHandler)
+	authHandler.SetRateLimiter(rateLimiter.Limit)
 
 	// Create app instance
 	app := &App{
-		ctx:           ctx,
-		Config:        cfg,
-		db:            db,
-		dbService:     dbService,
-		cache:         cacheService,
-		router:        router,
-		auth:          authService,
-		jwtService:    jwtService,
-		refreshTokens: refreshTokens,
-		logger:        loggerService,
-		ipfsService:   ipfsService,
-		s3Service:     s3Service,
-		videoHandler:  videoHandler,
-		healthHandler: healthHandler,
-		httpHandler:   responseHandler,
-		authHandler:   authHandler,
+		ctx:                ctx,
+		Config:             cfg,
+		db:                 db,
+		dbService:          dbService,
+		cache:              cacheService,
+		router:             router,
+		auth:               authService,
+		jwtService:         jwtService,
+		refreshTokens:      refreshTokens,
+		logger:             loggerService,
+		ipfsService:        ipfsService,
+		storageBackend:     storageBackend,
+		replicationQueue:   replicationQueue,
+		p2pNode:            p2pNode,
+		uploadJobs:         uploadJobs,
+		uploadEvents:       uploadEvents,
+		transcodeScheduler: transcodeScheduler,
+		tempManager:        tempManager,
+		videoHandler:       videoHandler,
+		healthHandler:      healthHandler,
+		httpHandler:        responseHandler,
+		authHandler:        authHandler,
+		rateLimiter:        rateLimiter,
+		responseCache:      responseCache,
+		idempotency:        idempotency,
+		tracingShutdown:    tracingShutdown,
+	}
+
+	// Purge storage of videos deleted past their retention, of failed uploads and of replaced versions
+	if cfg.Video.Cleanup.Enabled {
+		app.orphanCleaner = video.NewOrphanCleaner(
+			db,
+			videoPurger,
+			video.CleanupConfig{
+				Interval:      cfg.Video.Cleanup.Interval,
+				DeletedAfter:  time.Duration(cfg.Video.Cleanup.DeletedRetentionDays) * 24 * time.Hour,
+				FailedAfter:   cfg.Video.Cleanup.FailedRetention,
+				VersionsAfter: time.Duration(cfg.Video.Cleanup.VersionRetentionDays) * 24 * time.Hour,
+				BatchSize:     cfg.Video.Cleanup.BatchSize,
+				DryRun:        cfg.Video.Cleanup.DryRun,
+			},
+			video.NewCleanupMetrics(prometheus.DefaultRegisterer),
+			video.NewLoggerAdapter(loggerService),
+		)
+		app.orphanCleaner.Start()
+	}
+
+	// Serve the P2P node's peer info
+	if p2pNode != nil {
+		app.p2pHandler = p2p.NewHandler(p2pNode, responseHandler)
+	}
+
+	// Move old originals to a cheaper storage class and drop renditions of unwatched videos
+	app.storageTiering = storageTiering
+	if storageTiering != nil && cfg.Video.Tiering.Enabled {
+		storageTiering.Start()
 	}
 
 	// Initialize ScyllaDB connection
@@ -288,82 +566,391 @@ func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
 	}
 
 	app.scyllaSession = scyllaClient.Session()
-
-	// Initialize schema manager
-	app.scyllaManager = scylladb.NewSchemaManager(app.scyllaSession, scyllaConfig, loggerAdapter)
-
-	// Get migration config to check if we should auto-migrate
-	migrationConfig := app.dbService.GetMigrationConfig()
-
-	// Initialize schema only if auto-migrate is enabled
-	if migrationConfig.ShouldAutoMigrate() {
-		loggerService.LogInfo("Auto-migrate enabled, initializing ScyllaDB schema", nil)
-		if err := app.scyllaManager.InitializeSchema(); err != nil {
-			loggerService.LogError(fmt.Errorf("failed to initialize ScyllaDB schema: %w", err), "ScyllaDB schema error")
-			return nil, fmt.Errorf("failed to initialize ScyllaDB schema: %w", err)
-		}
-	} else {
-		loggerService.LogInfo("Auto-migrate disabled, skipping ScyllaDB schema initialization", nil)
+	healthHandler.Register(health.Dependency{Name: "scylladb", Critical: true, Check: scyllaClient.Ping})
+
+	// Apply pending CQL migrations and warn when the live schema has drifted
+	// from what they describe
+	app.scyllaManager = scyllaClient.Schema()
+	if err := app.scyllaManager.InitializeSchema(); err != nil {
+		loggerService.LogError(fmt.Errorf("failed to initialize ScyllaDB schema: %w", err), "ScyllaDB schema error")
+		return nil, fmt.Errorf("failed to initialize ScyllaDB schema: %w", err)
+	}
+	drifts, err := app.scyllaManager.DetectDrift()
+	if err != nil {
+		loggerService.LogWarn("Failed to check ScyllaDB schema drift", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	for _, drift := range drifts {
+		loggerService.LogWarn("ScyllaDB schema drift", map[string]interface{}{
+			"drift": drift.String(),
+		})
 	}
 
 	// Initialize comment repository
 	commentRepo := scylladb.NewCommentRepository(app.scyllaSession, loggerAdapter)
 
-	// Initialize comment service
-	commentService := comment.NewService(commentRepo)
+	// Initialize block service; blocks hide comments and suppress notifications
+	blockService := block.NewService(db)
+	app.blockHandler = block.NewHandler(blockService, responseHandler, loggerService)
+	app.blockHandler.SetResponseCache(app.responseCache)
 
-	// Initialize comment handler
-	app.commentHandler = comment.NewHandler(commentService, responseHandler, loggerAdapter)
-
-	// Initialize notification repository
-	notificationRepo := scylladb.NewNotificationRepository(app.scyllaSession, loggerService)
-
-	// Initialize notification schema manager
-	notificationSchemaManager := notification.NewSchemaManager(app.scyllaSession, scyllaConfig.Keyspace, loggerService)
+	// Initialize notification repository, with unread counts kept in Redis
+	var notificationRepo notification.NotificationRepository = scylladb.NewNotificationRepository(app.scyllaSession, loggerService)
+	if cfg.Notification.UnreadCountTTL > 0 {
+		notificationRepo = notification.NewUnreadCounter(notificationRepo, cacheService, cfg.Notification.UnreadCountTTL, loggerService)
+	}
 
-	// Initialize notification schema if auto-migrate is enabled
-	if migrationConfig.ShouldAutoMigrate() {
-		loggerService.LogInfo("Auto-migrate enabled, initializing notification schema", nil)
-		if err := notificationSchemaManager.CreateTables(); err != nil {
-			loggerService.LogError(fmt.Errorf("failed to initialize notification schema: %w", err), "Notification schema error")
-			loggerService.LogWarn("Continuing without notification service", nil)
+	// Send stored notifications to registered browsers with Web Push
+	if push := cfg.Notification.Push; push.Enabled {
+		vapid, err := webpush.NewVAPID(push.VAPIDPublicKey, push.VAPIDPrivateKey, push.VAPIDSubject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Web Push: %w", err)
 		}
+		sender := webpush.NewSender(vapid, &http.Client{Timeout: push.Timeout})
+		app.pushService = notification.NewPushService(scylladb.NewPushRepository(app.scyllaSession, loggerService), sender,
+			vapid.PublicKey(), push.TTL, push.Timeout, loggerService)
+		notificationRepo = notification.NewPushNotifier(notificationRepo, app.pushService)
+	}
+
+	// Email users who ask for it a digest of their unread notifications,
+	// claiming each digest in Redis so that one instance sends it
+	var digestService *notification.DigestService
+	if digest := cfg.Notification.Digest; digest.Enabled {
+		digestService = notification.NewDigestService(scylladb.NewDigestRepository(app.scyllaSession, loggerService), authService, mailer,
+			notification.DigestConfig{MaxItems: digest.MaxItems, URL: digest.URL}, loggerService)
+		digestService.SetClaimer(cacheService)
+		app.digestScheduler = notification.NewDigestScheduler(digestService, digest.Interval)
+		app.digestScheduler.Start()
 	}
 
 	// Initialize notification service config
 	notificationConfig := notification.NewServiceConfigFromConfig(cfg)
 
-	// Initialize notification service
+	// Initialize notification service; its backlog is reported on the admin dashboard
+	var notificationStats admin.NotificationStats
 	notificationService, err := notification.NewService(ctx, notificationConfig, loggerService, notificationRepo)
 	if err != nil {
 		loggerService.LogError(fmt.Errorf("failed to initialize notification service: %w", err), "Notification service error")
 		loggerService.LogWarn("Continuing without notification service", nil)
 	} else {
 		app.notificationService = notificationService
-		
+		if notificationConfig.Enabled {
+			healthHandler.Register(health.Dependency{Name: "pulsar", Check: notificationService.Ping})
+		}
+
 		// Initialize notification handler only if service is successfully created
 		app.notificationHandler = notification.NewHandler(notificationService, responseHandler, loggerService)
-		
+		if app.pushService != nil {
+			app.notificationHandler.SetPush(app.pushService)
+		}
+		if digestService != nil {
+			app.notificationHandler.SetDigest(digestService)
+		}
+
+		// Export throughput, failures and consumer lag of the notification topics
+		metrics := notification.NewMetrics(prometheus.DefaultRegisterer)
+		notificationService.SetMetrics(metrics)
+		notificationService.SetBlockChecker(blockService)
+		app.notificationMetrics = notification.NewMetricsHandler(metrics, responseHandler)
+		notificationStats = metrics
+		if notificationConfig.Enabled {
+			app.notificationMonitor = notification.NewLagMonitor(notificationConfig, metrics, loggerService)
+			app.notificationMonitor.Start()
+		}
+
 		// Create adapter and inject notification service into video app for video events
 		notificationAdapter := notification.NewVideoNotificationAdapter(notificationService)
 		videoApp.NotificationService = notificationAdapter
-		
+
 		loggerService.LogInfo("Notification service and handler initialized successfully", nil)
 	}
 
-	loggerService.LogInfo("ScyllaDB, comment and notification services initialized successfully", nil)
+	// Initialize follow service, publishing follow events and fanning out
+	// upload notifications when the notification service is available
+	var followPublisher follow.EventPublisher
+	if app.notificationService != nil {
+		followPublisher = notificationService
+	}
+	followService := follow.NewService(db, followPublisher, loggerService)
+	app.followHandler = follow.NewHandler(followService, responseHandler, loggerService)
+	if app.notificationService != nil {
+		notificationService.SetFollowerLister(followService)
+		// Fan out in a consumer so uploads don't wait on every follower
+		if notificationConfig.Enabled {
+			app.followerFanout, err = notificationService.NewFollowerFanoutConsumer(cfg.Notification.FollowerSubscription)
+			if err != nil {
+				return nil, fmt.Errorf("failed to consume video events: %w", err)
+			}
+			app.followerFanout.Start()
+		}
+	}
 
-	return app, nil
-}
+	// Initialize comment service, publishing comment events when the
+	// notification service is available
+	commentService := comment.NewService(commentRepo, commentCounts, blockService, videoService, comment.ContentPolicy{
+		MaxLength:    cfg.Comments.MaxLength,
+		Markdown:     cfg.Comments.Markdown,
+		BannedWords:  cfg.Comments.BannedWords,
+		FilterAction: comment.FilterAction(cfg.Comments.FilterAction),
+	})
+	if app.notificationService != nil {
+		commentService = comment.WithEvents(commentService, videoService, notificationService, loggerAdapter)
+	}
 
-func (a *App) initConfig() error {
-	configService := config.NewConfigService(a.logger)
-	cfg, err := configService.Load(".")
-	if err != nil {
-		return fmt.Errorf("failed to load config: %v", err)
+	// Initialize comment handler
+	app.commentHandler = comment.NewHandler(commentService, responseHandler, loggerAdapter)
+	app.commentHandler.SetResponseCache(app.responseCache)
+	app.commentHandler.SetIdempotency(app.idempotency)
+
+	// Keep comment counts on videos from comment events, recounting them
+	// from ScyllaDB periodically to correct drift
+	if counts := cfg.Video.CommentCounts; counts.Enabled {
+		if app.notificationService != nil && notificationConfig.Enabled {
+			app.commentConsumer, err = notificationService.NewCommentCountConsumer(counts.Subscription, videoService)
+			if err != nil {
+				return nil, fmt.Errorf("failed to consume comment events: %w", err)
+			}
+			app.commentConsumer.Start()
+		}
+		app.commentReconciler = video.NewCommentCountReconciler(db, commentRepo, video.CommentCountConfig{
+			Interval:  counts.ReconcileInterval,
+			BatchSize: counts.BatchSize,
+		}, loggerAdapter)
+		app.commentReconciler.Start()
 	}
-	a.Config = cfg
-	return nil
+
+	// Remove the comments of deleted videos and redact notifications about
+	// them, following the VIDEO_DELETED events published on deletion
+	if cleanup := cfg.Video.DeletionCleanup; cleanup.Enabled && app.notificationService != nil && notificationConfig.Enabled {
+		app.videoCleanup, err = notificationService.NewVideoCleanupConsumer(cleanup.Subscription, commentService)
+		if err != nil {
+			return nil, fmt.Errorf("failed to consume video events: %w", err)
+		}
+		app.videoCleanup.Start()
+	}
+
+	// Initialize public profile service for channel pages
+	userService := user.NewService(db, fileURLs, followService, loggerService)
+	app.userHandler = user.NewHandler(userService, responseHandler, loggerService)
+
+	// Initialize the GraphQL endpoint over the video, comment, profile and notification services
+	if cfg.GraphQL.Enabled {
+		services := graphql.Services{
+			Videos:   videoService,
+			Comments: commentService,
+			Users:    userService,
+		}
+		if app.notificationService != nil {
+			services.Notifications = app.notificationService
+		}
+		app.graphqlHandler = graphql.NewHandler(services, graphql.Options{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+			BatchWait:     cfg.GraphQL.BatchWait,
+		}, loggerService)
+	}
+
+	// Initialize entitlement service and enforce it on video playback
+	entitlementService := entitlement.NewService(db, videoCache, loggerService)
+	app.entitlementHandler = entitlement.NewHandler(entitlementService, entitlement.WebhookConfig{
+		Secret:    cfg.Entitlements.WebhookSecret,
+		Tolerance: cfg.Entitlements.WebhookTolerance,
+	}, responseHandler, loggerService)
+	videoApp.Entitlements = entitlementService
+
+	// Initialize content reports, snapshotting reported videos and comments as evidence
+	moderationService := moderation.NewService(db, commentService, loggerService)
+	app.moderationHandler = moderation.NewHandler(moderationService, responseHandler, loggerService)
+	videoApp.Evidence = moderationService
+	app.commentHandler.SetEvidenceRecorder(moderationService)
+
+	// Initialize per-video access logs, recorded on playback and pruned after the retention period
+	accessLogService := accesslog.NewService(db, accesslog.Config{
+		Enabled:       cfg.AccessLog.Enabled,
+		RetentionDays: cfg.AccessLog.RetentionDays,
+		CountryHeader: cfg.AccessLog.CountryHeader,
+	}, loggerService)
+	app.accessLogHandler = accesslog.NewHandler(accessLogService, responseHandler, loggerService)
+	app.accessLogPruner = accesslog.NewPruner(accessLogService, cfg.AccessLog.PruneInterval, loggerService)
+	app.accessLogPruner.Start()
+	videoApp.Access = accessLogService
+
+	// Initialize webhooks, delivered in the background with retries
+	if cfg.Webhooks.Enabled {
+		app.webhookDispatcher = webhook.NewDispatcher(db, webhook.DispatcherConfig{
+			Workers:              cfg.Webhooks.Workers,
+			MaxAttempts:          cfg.Webhooks.MaxAttempts,
+			RetryDelay:           cfg.Webhooks.RetryDelay,
+			MaxRetryDelay:        cfg.Webhooks.MaxRetryDelay,
+			Timeout:              cfg.Webhooks.Timeout,
+			PollInterval:         cfg.Webhooks.PollInterval,
+			AllowPrivateNetworks: cfg.Webhooks.AllowPrivateNetworks,
+		}, loggerService)
+		app.webhookDispatcher.Start()
+		webhookService := webhook.NewService(db, webhook.Config{MaxPerUser: cfg.Webhooks.MaxPerUser}, app.webhookDispatcher, loggerService)
+		app.webhookHandler = webhook.NewHandler(webhookService, responseHandler, loggerService)
+		videoApp.Webhooks = webhookService
+		app.commentHandler.SetWebhookPublisher(webhookService)
+	}
+
+	// Initialize federation, pulling remote instances' videos into listings
+	if cfg.Federation.Enabled {
+		privateKey, err := federation.ParsePrivateKey(cfg.Federation.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize federation: %v", err)
+		}
+		app.federationPoller = federation.NewPoller(db, federation.PollerConfig{
+			Interval: cfg.Federation.PollInterval,
+			PageSize: cfg.Federation.PageSize,
+			MaxPages: cfg.Federation.MaxPagesPerPoll,
+			Timeout:  cfg.Federation.Timeout,
+		}, loggerService)
+		app.federationPoller.Start()
+		federationService := federation.NewService(db, federation.Config{
+			Name:       cfg.Federation.Name,
+			PrivateKey: privateKey,
+			Timeout:    cfg.Federation.Timeout,
+		}, app.federationPoller, loggerService)
+		app.federationHandler = federation.NewHandler(federationService, responseHandler, loggerService)
+		videoApp.Remote = federationService
+	}
+
+	// Initialize ActivityPub, letting Fediverse platforms follow channels
+	if cfg.ActivityPub.Enabled {
+		app.activityPubQueue = activitypub.NewDeliverer(db, activitypub.DelivererConfig{
+			Workers:              cfg.ActivityPub.Workers,
+			QueueSize:            cfg.ActivityPub.QueueSize,
+			MaxAttempts:          cfg.ActivityPub.MaxAttempts,
+			RetryDelay:           cfg.ActivityPub.RetryDelay,
+			Timeout:              cfg.ActivityPub.Timeout,
+			AllowPrivateNetworks: cfg.ActivityPub.AllowPrivateNetworks,
+		}, loggerService)
+		app.activityPubQueue.Start()
+		activityPubService := activitypub.NewService(db, activitypub.Config{
+			PublicURL:            cfg.ActivityPub.PublicURL,
+			WatchURL:             cfg.ActivityPub.WatchURL,
+			MaxClockSkew:         cfg.ActivityPub.MaxClockSkew,
+			Timeout:              cfg.ActivityPub.Timeout,
+			AllowPrivateNetworks: cfg.ActivityPub.AllowPrivateNetworks,
+		}, app.activityPubQueue, loggerService)
+		app.activityPubHandler = activitypub.NewHandler(activityPubService, responseHandler, loggerService)
+		videoApp.Fediverse = activityPubService
+	}
+
+	// Initialize the public RSS feeds
+	if cfg.Feeds.Enabled {
+		feedService := feed.NewService(db, feed.Config{
+			Title:    cfg.Feeds.Title,
+			SiteURL:  cfg.Feeds.SiteURL,
+			WatchURL: cfg.Feeds.WatchURL,
+			Items:    cfg.Feeds.Items,
+		}, videoApp.CDN, videoApp.Sources, loggerService)
+		app.feedHandler = feed.NewHandler(feedService, cfg.Feeds.MaxAge, responseHandler, loggerService)
+	}
+
+	// Initialize oEmbed and the sitemap, for shared links and search engines
+	if cfg.Sharing.Enabled {
+		sharingService := sharing.NewService(db, sharing.Config{
+			ProviderName:   cfg.Sharing.ProviderName,
+			SiteURL:        cfg.Sharing.SiteURL,
+			WatchURL:       cfg.Sharing.WatchURL,
+			EmbedURL:       cfg.Sharing.EmbedURL,
+			ChannelURL:     cfg.Sharing.ChannelURL,
+			EmbedWidth:     cfg.Sharing.EmbedWidth,
+			EmbedHeight:    cfg.Sharing.EmbedHeight,
+			ThumbnailWidth: cfg.Ffmpeg.Preview.Width,
+			SitemapSize:    cfg.Sharing.SitemapSize,
+			MaxAge:         cfg.Sharing.MaxAge,
+		}, videoApp.CDN, loggerService)
+		app.sharingHandler = sharing.NewHandler(sharingService, cfg.Sharing.MaxAge, responseHandler, loggerService)
+	}
+
+	// Initialize the embedded player and the domains users allow it on
+	if cfg.Embed.Enabled {
+		embedService := embed.NewService(db, embed.Config{
+			MaxDomains: cfg.Embed.MaxDomains,
+		}, videoApp.Video, videoApp.CDN, videoApp.Files, videoApp.Sources, loggerService)
+		app.embedHandler = embed.NewHandler(embedService, responseHandler, loggerService)
+	}
+
+	// Open the GeoIP database, so videos can be restricted by country
+	if cfg.GeoIP.DatabasePath != "" {
+		if app.geoIP, err = geoip.Open(cfg.GeoIP.DatabasePath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Receive live streams over RTMP, notifying followers when they start and
+	// publishing their recordings as videos
+	if cfg.Live.Enabled {
+		var livePublisher live.EventPublisher
+		if app.notificationService != nil {
+			livePublisher = app.notificationService
+		}
+		liveService := live.NewService(db, live.Config{
+			IngestURL: cfg.Live.IngestURL,
+			PublicURL: cfg.Live.PublicURL,
+			OutputDir: cfg.Live.OutputDir,
+			MaxKeys:   cfg.Live.MaxKeys,
+		}, livePublisher, loggerService)
+		app.liveHandler = live.NewHandler(liveService, responseHandler, loggerService)
+		app.liveIngest = live.NewIngest(live.IngestConfig{
+			Addr:      cfg.Live.ListenAddr,
+			OutputDir: cfg.Live.OutputDir,
+			Packager: live.PackagerConfig{
+				FFmpegPath:      cfg.Ffmpeg.Path,
+				SegmentDuration: cfg.Live.SegmentDuration,
+				PlaylistSize:    cfg.Live.PlaylistSize,
+				Record:          cfg.Live.Record,
+			},
+		}, liveService, videoService, loggerService)
+		// Rotating or revoking a key disconnects the stream live with it
+		liveService.SetDisconnector(app.liveIngest)
+		if err := app.liveIngest.Start(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize the operator dashboard
+	adminService := admin.NewService(db, cfg.Storage.Backend, notificationStats, loggerService)
+	app.adminHandler = admin.NewHandler(adminService, responseHandler, loggerService)
+
+	// Initialize the audit log, kept in monthly ScyllaDB tables created on first write
+	auditService := audit.NewService(audit.NewScyllaRepository(app.scyllaSession, scyllaConfig.Keyspace, loggerService), loggerService)
+	app.auditHandler = audit.NewHandler(auditService, responseHandler, loggerService)
+	app.auth.SetAuditLog(auditService)
+	app.commentHandler.SetAuditLog(auditService)
+	videoApp.Audit = auditService
+
+	// Initialize watch history, kept in ScyllaDB, and resume positions on video details
+	historyService := history.NewService(db, history.NewScyllaRepository(app.scyllaSession, scyllaConfig.Keyspace))
+	app.historyHandler = history.NewHandler(historyService, responseHandler, loggerService)
+	videoApp.History = historyService
+
+	// Rank related videos by shared tags, channel and co-watching from watch history
+	relatedScorer := video.NewSignalScorer(videoRepository, historyService, video.DefaultRelatedWeights, videoApp.Logger)
+	videoApp.Related = video.NewRelatedVideos(videoRepository, relatedScorer, relatedRankings)
+
+	// Initialize sync service over the entity types clients can sync incrementally
+	syncService := syncapi.NewService(loggerService,
+		syncapi.NewVideoSource(db),
+		syncapi.NewNotificationSource(notificationRepo),
+		syncapi.NewReadStateSource(notificationRepo),
+		syncapi.NewFollowSource(followService),
+	)
+	app.syncHandler = syncapi.NewHandler(syncService, responseHandler, loggerService)
+
+	loggerService.LogInfo("ScyllaDB, comment and notification services initialized successfully", nil)
+
+	// Start running background jobs now that every job type is registered
+	if jobRunner != nil {
+		app.jobRunner = jobRunner
+		app.jobsHandler = jobs.NewHandler(jobRunner, responseHandler, loggerService)
+		jobRunner.Start()
+	}
+
+	return app, nil
 }
 
 func (a *App) initDatabase() error {
@@ -398,10 +985,6 @@ func (a *App) initIPFS() error {
 	return nil
 }
 
-func (a *App) initP2P() error {
-	return nil
-}
-
 func (a *App) initServices() {
 	// Initialize JWT service
 	authConfig := auth.NewConfigFromAuthConfig(&a.Config.Auth)
@@ -418,20 +1001,45 @@ func (a *App) initServices() {
 }
 
 func (a *App) setupRoutes() error {
-	// Add CORS middleware
-	a.router.Use(httpHandler.CORSMiddleware())
+	// Start a span for every request, continuing any trace in the headers
+	a.router.Use(otelgin.Middleware(a.Config.Tracing.ServiceName))
 
-	// Add request logging middleware
-	a.router.Use(httpHandler.RequestLoggerMiddleware(a.logger))
+	// Add CORS and security headers middleware
+	a.router.Use(httpHandler.CORSMiddleware(a.Config.CORS))
+	a.router.Use(httpHandler.SecurityHeadersMiddleware(a.Config.Security))
+
+	// Add request logging middleware; bodies are only logged in development
+	logBodies := a.Config.Logging.LogBodies && a.Config.Environment == "development"
+	a.router.Use(httpHandler.RequestLoggerMiddleware(a.logger, logBodies))
 
 	// Add recovery middleware
 	a.router.Use(httpHandler.RecoveryMiddleware(a.httpHandler, a.logger))
 
+	// Apply the default rate limit to every request by client IP
+	if a.rateLimiter != nil {
+		a.router.Use(a.rateLimiter.Limit(httpHandler.DefaultRateLimitRule))
+	}
+
+	// Record the client's country for videos restricted by country
+	if a.geoIP != nil {
+		a.router.Use(geoip.Middleware(a.geoIP, a.logger))
+	}
+
+	// Bound handlers by route so slow dependencies cannot hold requests open
+	a.router.Use(httpHandler.TimeoutMiddleware(a.Config.Server.HandlerTimeouts, a.Config.Server.BasePath, a.httpHandler))
+
+	// Write responses for service errors handlers pass to apierror.Abort
+	a.router.Use(apierror.Middleware(a.httpHandler))
+
 	// Set up routes
 	SetupRoutes(a.router, a)
 
-	// Set up Swagger documentation
-	a.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Set up Swagger documentation, with the API's configured base path
+	docs.SwaggerInfo.BasePath = a.Config.Server.BasePath
+	if docs.SwaggerInfo.BasePath == "" {
+		docs.SwaggerInfo.BasePath = "/"
+	}
+	a.router.GET("/swagger/*any", httpHandler.ContentSecurityPolicy(a.Config.Security.SwaggerCSP), ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	return nil
 }
@@ -450,9 +1058,10 @@ func (a *App) Run() error {
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: a.router,
-		// Add BaseContext to ensure the server uses our context
+		// Requests outlive the cancellation of our context so shutdown can
+		// drain them; Shutdown interrupts what is still running
 		BaseContext: func(net.Listener) context.Context {
-			return a.ctx
+			return context.WithoutCancel(a.ctx)
 		},
 	}
 
@@ -497,6 +1106,38 @@ func (a *App) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Disconnect streamers, letting the recordings of their streams start
+	// uploading before uploads are drained
+	if a.liveIngest != nil {
+		liveCtx, liveCancel := context.WithTimeout(ctx, a.Config.Server.DrainTimeout)
+		a.liveIngest.Stop(liveCtx)
+		liveCancel()
+	}
+
+	// Let uploads being processed finish, interrupting those that take too long
+	if a.uploadJobs != nil {
+		running := a.uploadJobs.Running()
+		if running > 0 {
+			a.logger.LogInfo("Waiting for uploads to finish", map[string]interface{}{
+				"running": running,
+				"timeout": a.Config.Server.DrainTimeout.String(),
+			})
+		}
+		if interrupted := a.uploadJobs.Drain(ctx, a.Config.Server.DrainTimeout); interrupted > 0 {
+			a.logger.LogWarn("Interrupted uploads still processing at shutdown", map[string]interface{}{
+				"interrupted": interrupted,
+			})
+		}
+	}
+
+	// Uploads have finished, so no renditions are left waiting
+	if a.transcodeScheduler != nil {
+		a.transcodeScheduler.Stop()
+	}
+
+	// End upload event streams, which would otherwise hold the server open
+	a.uploadEvents.Close()
+
 	// Get the server from context
 	if srv, ok := a.ctx.Value("server").(*http.Server); ok {
 		// First shutdown the HTTP server
@@ -516,6 +1157,97 @@ func (a *App) Shutdown() error {
 		}
 	}
 
+	// Stop polling Pulsar for consumer lag
+	if a.notificationMonitor != nil {
+		a.notificationMonitor.Stop()
+	}
+
+	// Stop applying comment events; unacknowledged ones are redelivered
+	if a.commentConsumer != nil {
+		a.commentConsumer.Stop()
+	}
+
+	// Stop cleaning up after deleted videos; unacknowledged events are redelivered
+	if a.videoCleanup != nil {
+		a.videoCleanup.Stop()
+	}
+
+	// Stop notifying followers; unacknowledged events are redelivered
+	if a.followerFanout != nil {
+		a.followerFanout.Stop()
+	}
+
+	// Stop recounting video comments
+	if a.commentReconciler != nil {
+		a.commentReconciler.Stop()
+	}
+
+	// Stop sending notification digests, finishing the one in progress
+	if a.digestScheduler != nil {
+		a.digestScheduler.Stop()
+	}
+
+	// Stop pruning access logs
+	if a.accessLogPruner != nil {
+		a.accessLogPruner.Stop()
+	}
+
+	// Stop running background jobs; interrupted jobs run again after the next start
+	if a.jobRunner != nil {
+		a.jobRunner.Stop()
+	}
+
+	// Stop delivering webhooks; undelivered events are sent after the next start
+	if a.webhookDispatcher != nil {
+		a.webhookDispatcher.Stop()
+	}
+
+	// Stop pulling remote instances; each continues from its stored cursor
+	if a.federationPoller != nil {
+		a.federationPoller.Stop()
+	}
+
+	// Stop delivering ActivityPub activities; queued deliveries are dropped
+	if a.activityPubQueue != nil {
+		a.activityPubQueue.Stop()
+	}
+
+	// Close the GeoIP database
+	if a.geoIP != nil {
+		if err := a.geoIP.Close(); err != nil {
+			a.logger.LogError(err, "Failed to close GeoIP database")
+		}
+	}
+
+	// Stop purging orphaned video storage
+	if a.orphanCleaner != nil {
+		a.orphanCleaner.Stop()
+	}
+
+	// Stop tiering video storage; interrupted regenerations restart on the next stream
+	if a.storageTiering != nil {
+		a.storageTiering.Stop()
+	}
+
+	// Let in-flight IPFS replications finish before closing the database
+	if a.replicationQueue != nil {
+		a.replicationQueue.Stop()
+	}
+
+	// Stop listening for peers' announcements once nothing is left to announce
+	if a.p2pNode != nil {
+		a.p2pNode.Stop()
+	}
+
+	// Remove temp files left by uploads that did not clean up after themselves
+	if a.tempManager != nil {
+		if err := a.tempManager.CleanupAll(); err != nil {
+			a.logger.LogWarn("Error removing temporary upload files", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	// Close database connections
 	if a.db != nil {
 		sqlDB, err := a.db.DB()
@@ -541,6 +1273,11 @@ func (a *App) Shutdown() error {
 		}
 	}
 
+	// Finish sending push messages for notifications already stored
+	if a.pushService != nil {
+		a.pushService.Wait()
+	}
+
 	// Close notification service connections if any
 	if a.notificationService != nil {
 		if err := a.notificationService.Close(); err != nil {
@@ -550,6 +1287,15 @@ func (a *App) Shutdown() error {
 		}
 	}
 
+	// Flush spans buffered for export
+	if a.tracingShutdown != nil {
+		if err := a.tracingShutdown(ctx); err != nil {
+			a.logger.LogWarn("Error flushing traces", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	a.logger.LogInfo("Application shutdown complete", nil)
 	return nil
 }
@@ -559,6 +1305,11 @@ type loggerAdapter struct {
 	logger logger.Logger
 }
 
+// LogDebug implements the video.Logger interface
+func (a *loggerAdapter) LogDebug(msg string, fields map[string]interface{}) {
+	a.logger.LogDebug(msg, fields)
+}
+
 // LogInfo implements the video.Logger interface
 func (a *loggerAdapter) LogInfo(msg string, fields map[string]interface{}) {
 	a.logger.LogInfo(msg, fields)
@@ -568,7 +1319,7 @@ func (a *loggerAdapter) LogInfo(msg string, fields map[string]interface{}) {
 func (a *loggerAdapter) LogError(msg string, fields map[string]interface{}) {
 	if err, ok := fields["error"]; ok {
 		if errStr, ok := err.(string); ok {
-			a.logger.LogError(fmt.Errorf(errStr), msg)
+			a.logger.LogError(fmt.Errorf("%s", errStr), msg)
 		} else {
 			a.logger.LogError(fmt.Errorf("unknown error"), msg)
 		}
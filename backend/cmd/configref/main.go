@@ -0,0 +1,33 @@
+// Command configref writes the reference configuration file, listing every
+// setting with its default value and documentation.
+//
+//	go run ./cmd/configref -o config.reference.yaml
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+)
+
+func main() {
+	output := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := config.WriteReference(w); err != nil {
+		log.Fatalf("Failed to write reference config: %v", err)
+	}
+}
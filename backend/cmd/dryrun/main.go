@@ -0,0 +1,90 @@
+// Command dryrun runs a sample file through the video pipeline's checks,
+// probing and rendition planning without uploading it or touching the
+// database, to tune FFmpeg profiles before rolling them out. It reads the same
+// configuration as the server, so run it from the backend directory.
+//
+//	go run ./cmd/dryrun -f sample.mp4 [-resolutions 720p,480p,360p] [-sample 5s]
+//
+// The report is printed as JSON; it exits with status 2 when any check failed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+)
+
+func main() {
+	file := flag.String("f", "", "sample video file (required)")
+	resolutions := flag.String("resolutions", "", "comma-separated renditions to plan (default: the upload ladder)")
+	sample := flag.Duration("sample", 5*time.Second, "length of each rendition to encode for time estimates; 0 skips encoding")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	loggerService, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	cfg, err := config.NewConfigService(loggerService).Load(".")
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	ffmpegService := ffmpeg.NewService(&ffmpeg.Config{
+		Path:          cfg.Ffmpeg.Path,
+		ProbePath:     cfg.Ffmpeg.ProbePath,
+		VideoCodec:    cfg.Ffmpeg.VideoCodec,
+		AudioCodec:    cfg.Ffmpeg.AudioCodec,
+		Preset:        cfg.Ffmpeg.Preset,
+		OutputPath:    cfg.Ffmpeg.OutputPath,
+		Resolutions:   cfg.Ffmpeg.Resolutions,
+		TimeoutFactor: cfg.Ffmpeg.TimeoutFactor,
+		MinTimeout:    cfg.Ffmpeg.MinTimeout,
+		ProbeTimeout:  cfg.Ffmpeg.ProbeTimeout,
+		HWAccel:       cfg.Ffmpeg.HWAccel,
+		VAAPIDevice:   cfg.Ffmpeg.VAAPIDevice,
+		Profiles:      cfg.Ffmpeg.Profiles,
+		Audio:         cfg.Ffmpeg.Audio,
+		Preview:       cfg.Ffmpeg.Preview,
+	}, loggerService)
+
+	opts := video.DryRunOptions{SampleSeconds: sample.Seconds()}
+	if *resolutions != "" {
+		for _, resolution := range strings.Split(*resolutions, ",") {
+			opts.Resolutions = append(opts.Resolutions, strings.TrimSpace(resolution))
+		}
+	}
+
+	report, err := video.DryRun(context.Background(), ffmpegService, video.LimitsConfig{
+		MaxFileSize:        cfg.Video.MaxSize,
+		AllowedFormats:     cfg.Video.AllowedFormats,
+		MaxDuration:        cfg.Video.MaxDuration,
+		AllowedVideoCodecs: cfg.Video.AllowedVideoCodecs,
+	}, *file, opts)
+	if err != nil {
+		log.Fatalf("Dry run failed: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	if !report.Valid {
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Check the server configuration",
+	}
+
+	var dir string
+	validate := &cobra.Command{
+		Use:   "validate",
+		Short: "Load the configuration the way the server does and report whether it is valid",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigValidate(dir)
+		},
+	}
+	validate.Flags().StringVar(&dir, "dir", ".", "directory holding config.yaml")
+
+	cmd.AddCommand(validate)
+	return cmd
+}
+
+// runConfigValidate loads the configuration the way the server does and
+// reports whether it is valid
+func runConfigValidate(dir string) error {
+	loggerService, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	if _, err := config.NewConfigService(loggerService).Load(dir); err != nil {
+		return fmt.Errorf("configuration is invalid: %w", err)
+	}
+	fmt.Println("Configuration is valid")
+	return nil
+}
@@ -0,0 +1,71 @@
+// Command pavilionctl runs operational tasks against a deployment. It reads
+// the same configuration as the server, so run it from the backend directory.
+//
+//	go run ./cmd/pavilionctl migrate cockroach status | up | up-to VERSION | rollback [--steps N]
+//	go run ./cmd/pavilionctl migrate scylla status | up [--dry-run] | drift
+//	go run ./cmd/pavilionctl user create-admin --email EMAIL --username NAME [--name NAME] [--password PASSWORD]
+//	go run ./cmd/pavilionctl video reprocess VIDEO_ID
+//	go run ./cmd/pavilionctl storage gc [--dry-run]
+//	go run ./cmd/pavilionctl notification replay-dlq [--limit N] [--wait 5s] [--dry-run] [--subscription NAME]
+//	go run ./cmd/pavilionctl notification vapid-keys
+//	go run ./cmd/pavilionctl config validate [--dir .]
+//
+// Every command takes --help. Commands exit with status 1 on errors and 2
+// when they ran but found a problem, such as schema drift or a record that
+// could not be created.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCommand builds the command tree. Errors are printed by main, and
+// usage only for mistakes in the command line.
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "pavilionctl",
+		Short:         "Run operational tasks against a Pavilion deployment",
+		SilenceErrors: true,
+		// Arguments have been validated by the time a command runs, so its
+		// errors are not about usage
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			cmd.SilenceUsage = true
+		},
+	}
+	root.AddCommand(
+		newMigrateCommand(),
+		newUserCommand(),
+		newVideoCommand(),
+		newStorageCommand(),
+		newNotificationCommand(),
+		newConfigCommand(),
+	)
+	return root
+}
+
+// loadConfig loads the server configuration from the working directory
+func loadConfig() (*config.Config, logger.Logger) {
+	loggerService, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	cfg, err := config.NewConfigService(loggerService).Load(".")
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	return cfg, loggerService
+}
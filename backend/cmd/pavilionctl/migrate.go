@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/database"
+	"github.com/consensuslabs/pavilion-network/backend/internal/database/scylladb"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/migrations"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Run database migrations",
+	}
+	cmd.AddCommand(newMigrateCockroachCommand(), newMigrateScyllaCommand())
+	return cmd
+}
+
+// newMigrateCockroachCommand runs the versioned SQL migrations. Running it
+// is an explicit request, so FORCE_MIGRATION is not needed.
+func newMigrateCockroachCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cockroach",
+		Short: "Run the versioned SQL migrations",
+	}
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "List the SQL migrations and whether they are applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printMigrationStatus(newMigrationRunner())
+		},
+	}
+
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending SQL migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			done, err := newMigrationRunner().Up(0)
+			return reportMigrations("Applied", done, err)
+		},
+	}
+
+	upTo := &cobra.Command{
+		Use:   "up-to VERSION",
+		Short: "Apply pending SQL migrations up to and including VERSION",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			version, err := strconv.Atoi(args[0])
+			if err != nil || version < 1 {
+				return fmt.Errorf("up-to needs a migration version, e.g. up-to 3")
+			}
+			done, err := newMigrationRunner().Up(version)
+			return reportMigrations("Applied", done, err)
+		},
+	}
+
+	var steps int
+	rollback := &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll back the last batch of SQL migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			done, err := newMigrationRunner().Rollback(steps)
+			return reportMigrations("Rolled back", done, err)
+		},
+	}
+	rollback.Flags().IntVar(&steps, "steps", 0, "number of migrations to roll back (default: the whole last batch)")
+
+	cmd.AddCommand(status, up, upTo, rollback)
+	return cmd
+}
+
+// newMigrationRunner connects to CockroachDB and loads the embedded SQL
+// migrations
+func newMigrationRunner() *migrations.MigrationRunner {
+	cfg, loggerService := loadConfig()
+
+	// A plain connection, since connecting through the database service
+	// auto-migrates in development
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		cfg.Database.Host,
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.Dbname,
+		cfg.Database.Port,
+		cfg.Database.Sslmode,
+	)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	embedded, err := migrations.Embedded()
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	return migrations.NewMigrationRunner(db, database.NewMigrationConfig(db, loggerService), embedded)
+}
+
+// printMigrationStatus prints a table of the SQL migrations
+func printMigrationStatus(runner *migrations.MigrationRunner) error {
+	statuses, err := runner.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tSTATUS\tBATCH\tAPPLIED AT")
+	for _, s := range statuses {
+		state := "pending"
+		switch {
+		case s.Missing:
+			state = "applied, file missing"
+		case s.Modified:
+			state = "applied, modified"
+		case s.Applied:
+			state = "applied"
+		}
+		batch, appliedAt := "", ""
+		if s.Applied {
+			batch = strconv.Itoa(s.BatchNo)
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, state, batch, appliedAt)
+	}
+	return w.Flush()
+}
+
+// reportMigrations prints the migrations that ran and returns err, the
+// failure of the next one
+func reportMigrations(action string, done []migrations.Migration, err error) error {
+	for _, m := range done {
+		fmt.Printf("%s %s\n", action, m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	if len(done) == 0 {
+		fmt.Println("Nothing to do")
+	}
+	return nil
+}
+
+// newMigrateScyllaCommand runs the versioned CQL migrations, the same ones
+// the server applies on startup
+func newMigrateScyllaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scylla",
+		Short: "Run the versioned CQL migrations",
+	}
+
+	status := &cobra.Command{
+		Use:   "status",
+		Short: "List the CQL migrations and whether they are applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := connectScylla()
+			defer client.Close()
+			return printScyllaStatus(client.Schema())
+		},
+	}
+
+	var dryRun bool
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending CQL migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := connectScylla()
+			defer client.Close()
+			if dryRun {
+				return printScyllaPlan(client.Schema())
+			}
+			applied, err := client.Schema().Migrate()
+			for _, migration := range applied {
+				fmt.Printf("Applied %s\n", migration.Name)
+			}
+			if err != nil {
+				return fmt.Errorf("migration failed: %w", err)
+			}
+			if len(applied) == 0 {
+				fmt.Println("Nothing to do")
+			}
+			return nil
+		},
+	}
+	up.Flags().BoolVar(&dryRun, "dry-run", false, "print the pending migrations without applying them")
+
+	drift := &cobra.Command{
+		Use:   "drift",
+		Short: "Compare the live schema with the one the migrations produce",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := connectScylla()
+			drifts, err := client.Schema().DetectDrift()
+			if err != nil {
+				client.Close()
+				return fmt.Errorf("failed to check schema drift: %w", err)
+			}
+			client.Close()
+			if len(drifts) == 0 {
+				fmt.Println("No schema drift")
+				return nil
+			}
+			for _, drift := range drifts {
+				fmt.Println(drift.String())
+			}
+			os.Exit(2)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(status, up, drift)
+	return cmd
+}
+
+// connectScylla connects to ScyllaDB with the server's settings
+func connectScylla() *scylladb.Client {
+	cfg, loggerService := loadConfig()
+	client := scylladb.NewClient(scylladb.Config{
+		Hosts:          cfg.ScyllaDB.Hosts,
+		Port:           cfg.ScyllaDB.Port,
+		Keyspace:       cfg.ScyllaDB.Keyspace,
+		Username:       cfg.ScyllaDB.Username,
+		Password:       cfg.ScyllaDB.Password,
+		Consistency:    cfg.ScyllaDB.Consistency,
+		Timeout:        cfg.ScyllaDB.Timeout,
+		ConnectTimeout: cfg.ScyllaDB.ConnectTimeout,
+		Replication: scylladb.Replication{
+			Class:             cfg.ScyllaDB.Replication.Class,
+			ReplicationFactor: cfg.ScyllaDB.Replication.ReplicationFactor,
+		},
+	}, &scyllaLogger{logger: loggerService})
+	if err := client.Connect(); err != nil {
+		log.Fatalf("Failed to connect to ScyllaDB: %v", err)
+	}
+	return client
+}
+
+// printScyllaStatus prints a table of the CQL migrations
+func printScyllaStatus(schema *scylladb.SchemaManager) error {
+	statuses, err := schema.MigrationStatus()
+	if err != nil {
+		return fmt.Errorf("failed to read migration status: %w", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "MIGRATION\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		switch {
+		case s.Missing:
+			state = "applied, file missing"
+		case s.Modified:
+			state = "applied, modified"
+		case s.Applied:
+			state = "applied"
+		}
+		if s.Applied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
+
+// printScyllaPlan prints the statements of the pending CQL migrations
+func printScyllaPlan(schema *scylladb.SchemaManager) error {
+	pending, err := schema.Plan()
+	if err != nil {
+		return fmt.Errorf("failed to plan migrations: %w", err)
+	}
+	if len(pending) == 0 {
+		fmt.Println("Nothing to do")
+	}
+	for _, migration := range pending {
+		fmt.Printf("-- %s\n", migration.Name)
+		for _, statement := range migration.Statements {
+			fmt.Printf("%s;\n", statement)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// scyllaLogger adapts the application logger to the ScyllaDB client
+type scyllaLogger struct {
+	logger logger.Logger
+}
+
+func (l *scyllaLogger) LogDebug(msg string, fields map[string]interface{}) {
+	l.logger.LogDebug(msg, fields)
+}
+
+func (l *scyllaLogger) LogInfo(msg string, fields map[string]interface{}) {
+	l.logger.LogInfo(msg, fields)
+}
+
+func (l *scyllaLogger) LogError(msg string, fields map[string]interface{}) {
+	l.logger.LogError(fmt.Errorf("%v", fields["error"]), msg)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification/webpush"
+	"github.com/spf13/cobra"
+)
+
+func newNotificationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notification",
+		Short: "Operate the notification pipeline",
+	}
+
+	var opts notification.ReplayOptions
+	replay := &cobra.Command{
+		Use:   "replay-dlq",
+		Short: "Publish dead-lettered notification events to the topics they came from",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runReplayDLQ(opts)
+		},
+	}
+	flags := replay.Flags()
+	flags.IntVar(&opts.Limit, "limit", 0, "maximum number of messages to replay (default: all)")
+	flags.DurationVar(&opts.Wait, "wait", 5*time.Second, "how long to wait for the next message before stopping")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "count the messages without replaying them")
+	flags.StringVar(&opts.Subscription, "subscription", notification.DefaultReplaySubscription, "dead letter subscription to read from")
+
+	vapidKeys := &cobra.Command{
+		Use:   "vapid-keys",
+		Short: "Print a new VAPID key pair for Web Push",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVAPIDKeys()
+		},
+	}
+
+	cmd.AddCommand(replay, vapidKeys)
+	return cmd
+}
+
+// runReplayDLQ publishes dead-lettered notification events to the topics
+// they came from, so consumers process them again
+func runReplayDLQ(opts notification.ReplayOptions) error {
+	cfg, loggerService := loadConfig()
+	if !cfg.Notification.Enabled {
+		return errors.New("notifications are disabled in the configuration")
+	}
+
+	ctx := context.Background()
+	service, err := notification.NewService(ctx, notification.NewServiceConfigFromConfig(cfg), loggerService, nil)
+	if err != nil {
+		return fmt.Errorf("failed to initialize notification service: %w", err)
+	}
+	defer service.Close()
+
+	result, err := service.ReplayDeadLetters(ctx, opts)
+	if result != nil {
+		for topic, count := range result.Replayed {
+			fmt.Printf("%s\t%d\n", topic, count)
+		}
+		if result.Skipped > 0 {
+			fmt.Printf("Skipped %d messages with an unknown topic\n", result.Skipped)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("replay failed: %w", err)
+	}
+	if opts.DryRun {
+		fmt.Println("Dry run: nothing was replayed")
+	}
+	return nil
+}
+
+// runVAPIDKeys prints a new VAPID key pair for Web Push, as environment
+// variables for the notification.push settings
+func runVAPIDKeys() error {
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		return fmt.Errorf("failed to generate VAPID keys: %w", err)
+	}
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\nVAPID_PRIVATE_KEY=%s\n", publicKey, privateKey)
+	return nil
+}
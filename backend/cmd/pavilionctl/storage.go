@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/storage"
+	"github.com/consensuslabs/pavilion-network/backend/internal/storage/ipfs"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newStorageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Maintain video storage",
+	}
+
+	var dryRun bool
+	gc := &cobra.Command{
+		Use:   "gc",
+		Short: "Purge deleted videos, failed uploads and replaced versions past their retention",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStorageGC(dryRun)
+		},
+	}
+	gc.Flags().BoolVar(&dryRun, "dry-run", false, "list what would be purged without deleting anything")
+
+	cmd.AddCommand(gc)
+	return cmd
+}
+
+// runStorageGC purges the storage of videos deleted past their retention,
+// of failed uploads and of replaced versions once, like the cleanup worker
+func runStorageGC(dryRun bool) error {
+	cfg, loggerService := loadConfig()
+	db := connectDatabase(cfg, loggerService)
+	backend := newStorageBackend(cfg, loggerService)
+
+	cleaner := video.NewOrphanCleaner(
+		db,
+		newPurger(cfg, db, backend, loggerService),
+		video.CleanupConfig{
+			DeletedAfter:  time.Duration(cfg.Video.Cleanup.DeletedRetentionDays) * 24 * time.Hour,
+			FailedAfter:   cfg.Video.Cleanup.FailedRetention,
+			VersionsAfter: time.Duration(cfg.Video.Cleanup.VersionRetentionDays) * 24 * time.Hour,
+			BatchSize:     cfg.Video.Cleanup.BatchSize,
+			DryRun:        dryRun,
+		},
+		video.NewCleanupMetrics(prometheus.NewRegistry()),
+		video.NewLoggerAdapter(loggerService),
+	)
+	result, err := cleaner.RunOnce(context.Background())
+	for _, reason := range []string{video.PurgeReasonDeleted, video.PurgeReasonFailed, video.PurgeReasonReplaced} {
+		for _, id := range result.Candidates[reason] {
+			fmt.Printf("%s\t%s\n", reason, id)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("storage cleanup failed: %w", err)
+	}
+	if dryRun {
+		fmt.Println("Dry run: nothing was purged")
+		return nil
+	}
+	fmt.Printf("Purged %d, failed %d\n", result.Purged, result.Failed)
+	if result.Failed > 0 {
+		os.Exit(2)
+	}
+	return nil
+}
+
+// newPurger creates the purger of video files, pins and records
+func newPurger(cfg *config.Config, db *gorm.DB, backend storage.Backend, loggerService logger.Logger) *video.Purger {
+	ipfsService := ipfs.NewService(&storage.IPFSConfig{
+		APIAddress: cfg.Storage.IPFS.APIAddress,
+		Gateway:    cfg.Storage.IPFS.Gateway,
+	}, loggerService)
+	return video.NewPurger(db, backend, ipfsService)
+}
+
+// newCDN creates the configured CDN, purged when videos change, or returns
+// nil without one
+func newCDN(cfg *config.Config) video.CDNPurger {
+	cdnService, err := storage.NewCDN(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize CDN: %v", err)
+	}
+	return cdnService
+}
+
+// newStorageBackend creates the configured storage, exiting when it cannot be reached
+func newStorageBackend(cfg *config.Config, loggerService logger.Logger) storage.Backend {
+	backend, err := storage.NewBackend(cfg, loggerService)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	return backend
+}
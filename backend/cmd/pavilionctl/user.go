@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/database"
+	"github.com/consensuslabs/pavilion-network/backend/internal/mail"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// adminOptions are the flags of user create-admin
+type adminOptions struct {
+	email    string
+	username string
+	name     string
+	password string
+}
+
+func newUserCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage user accounts",
+	}
+
+	var opts adminOptions
+	createAdmin := &cobra.Command{
+		Use:   "create-admin",
+		Short: "Create an admin account with a verified email",
+		Long: "Create an admin account with a verified email. Without a password " +
+			"the admin is emailed a link to choose one.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCreateAdmin(opts)
+		},
+	}
+	flags := createAdmin.Flags()
+	flags.StringVar(&opts.email, "email", "", "email address")
+	flags.StringVar(&opts.username, "username", "", "username")
+	flags.StringVar(&opts.name, "name", "", "full name")
+	flags.StringVar(&opts.password, "password", "", "initial password (default: email a link to set one)")
+	createAdmin.MarkFlagRequired("email")
+	createAdmin.MarkFlagRequired("username")
+
+	cmd.AddCommand(createAdmin)
+	return cmd
+}
+
+// runCreateAdmin creates an admin account with a verified email. Without a
+// password the admin is emailed a link to choose one.
+func runCreateAdmin(opts adminOptions) error {
+	cfg, loggerService := loadConfig()
+	db, err := database.NewDatabaseService(&cfg.Database, loggerService).Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	authConfig := auth.NewConfigFromAuthConfig(&cfg.Auth)
+	service := auth.NewService(db, auth.NewJWTService(authConfig), auth.NewRefreshTokenRepository(db, loggerService), authConfig, loggerService)
+	service.SetMailer(mail.NewMailer(&mail.Config{
+		Host:     cfg.Email.SMTP.Host,
+		Port:     cfg.Email.SMTP.Port,
+		Username: cfg.Email.SMTP.Username,
+		Password: cfg.Email.SMTP.Password,
+		From:     cfg.Email.From,
+	}, loggerService))
+
+	// An import of one record gets the same validation as bulk imports
+	report, err := service.ImportUsers(uuid.Nil, []auth.ImportUser{{
+		Username:      opts.username,
+		Email:         opts.email,
+		Name:          opts.name,
+		Role:          auth.RoleAdmin,
+		Password:      opts.password,
+		EmailVerified: true,
+	}}, auth.ImportOptions{
+		OnDuplicate: auth.DuplicateFail,
+		SendWelcome: opts.password == "",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create admin: %w", err)
+	}
+
+	result := report.Results[0]
+	if result.Status != auth.ImportCreated {
+		fmt.Fprintf(os.Stderr, "Admin not created: %s\n", result.Error)
+		os.Exit(2)
+	}
+	fmt.Printf("Created admin %s (%s)\n", result.Username, result.UserID)
+	if opts.password == "" && !result.WelcomeSent {
+		fmt.Println("The email with a link to set a password could not be sent; use a password reset instead")
+	}
+	return nil
+}
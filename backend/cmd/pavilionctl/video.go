@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/database"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tempfile"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+func newVideoCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "video",
+		Short: "Operate on videos",
+	}
+
+	reprocess := &cobra.Command{
+		Use:   "reprocess VIDEO_ID",
+		Short: "Transcode a video's original again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			videoID, err := uuid.Parse(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid video ID %q", args[0])
+			}
+			return runReprocess(videoID)
+		},
+	}
+
+	cmd.AddCommand(reprocess)
+	return cmd
+}
+
+// runReprocess transcodes a video's original again. The new renditions are
+// replicated to IPFS by the server, which queues unreplicated files when it
+// starts.
+func runReprocess(videoID uuid.UUID) error {
+	cfg, loggerService := loadConfig()
+	db := connectDatabase(cfg, loggerService)
+	videoService, stop := newVideoService(cfg, db, loggerService)
+	defer stop()
+
+	if err := videoService.ReprocessVideo(context.Background(), videoID); err != nil {
+		return fmt.Errorf("failed to reprocess video %s: %w", videoID, err)
+	}
+	fmt.Printf("Reprocessed video %s\n", videoID)
+	return nil
+}
+
+// connectDatabase connects to CockroachDB through the database service
+func connectDatabase(cfg *config.Config, loggerService logger.Logger) *gorm.DB {
+	db, err := database.NewDatabaseService(&cfg.Database, loggerService).Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	return db
+}
+
+// newVideoService creates a video service like the server's, without IPFS
+// replication or upload scanning. The returned function stops its
+// transcode workers.
+func newVideoService(cfg *config.Config, db *gorm.DB, loggerService logger.Logger) (video.VideoService, func()) {
+	backend := newStorageBackend(cfg, loggerService)
+
+	tempManager, err := tempfile.NewManager(&tempfile.Config{
+		BaseDir:     cfg.Video.Temp.Dir,
+		Permissions: 0755,
+		StaleAfter:  cfg.Video.Temp.StaleAfter,
+	}, loggerService)
+	if err != nil {
+		log.Fatalf("Failed to initialize temporary file manager: %v", err)
+	}
+
+	ffmpegService := ffmpeg.NewService(&ffmpeg.Config{
+		Path:          cfg.Ffmpeg.Path,
+		ProbePath:     cfg.Ffmpeg.ProbePath,
+		VideoCodec:    cfg.Ffmpeg.VideoCodec,
+		AudioCodec:    cfg.Ffmpeg.AudioCodec,
+		Preset:        cfg.Ffmpeg.Preset,
+		OutputPath:    cfg.Ffmpeg.OutputPath,
+		Resolutions:   cfg.Ffmpeg.Resolutions,
+		TimeoutFactor: cfg.Ffmpeg.TimeoutFactor,
+		MinTimeout:    cfg.Ffmpeg.MinTimeout,
+		ProbeTimeout:  cfg.Ffmpeg.ProbeTimeout,
+		HWAccel:       cfg.Ffmpeg.HWAccel,
+		VAAPIDevice:   cfg.Ffmpeg.VAAPIDevice,
+		Profiles:      cfg.Ffmpeg.Profiles,
+		Audio:         cfg.Ffmpeg.Audio,
+		Preview:       cfg.Ffmpeg.Preview,
+	}, loggerService)
+
+	// Cached video records are invalidated, so the server does not keep serving the old renditions
+	var videoCache *video.VideoCache
+	if cfg.ReadCache.Enabled {
+		cacheService, err := cache.NewRedisService(&cache.Config{
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis service: %v", err)
+		}
+		videoCache = video.NewVideoCache(cacheService, cfg.ReadCache.VideoTTL, cache.NewMetrics(prometheus.NewRegistry()))
+	}
+
+	scheduler := video.NewTranscodeScheduler(
+		video.TranscodeSchedulerConfig{Workers: cfg.Video.Transcode.Workers},
+		video.NewTranscodeMetrics(prometheus.NewRegistry()),
+	)
+	scheduler.Start()
+
+	service := video.NewVideoService(
+		video.NewGormVideoRepository(db),
+		nil,
+		backend,
+		ffmpegService,
+		video.LimitsConfig{
+			MaxFileSize:        cfg.Video.MaxSize,
+			MinTitleLength:     cfg.Video.MinTitleLength,
+			MaxTitleLength:     cfg.Video.MaxTitleLength,
+			MaxDescLength:      cfg.Video.MaxDescLength,
+			AllowedFormats:     cfg.Video.AllowedFormats,
+			MaxDuration:        cfg.Video.MaxDuration,
+			AllowedVideoCodecs: cfg.Video.AllowedVideoCodecs,
+		},
+		nil,
+		tempManager,
+		nil,
+		scheduler,
+		newPurger(cfg, db, backend, loggerService),
+		videoCache,
+		newCDN(cfg),
+		nil, // Reprocessing is not followed over SSE
+		video.NewLoggerAdapter(loggerService),
+	)
+	return service, scheduler.Stop
+}
@@ -0,0 +1,151 @@
+// Command users imports and exports user accounts in bulk, for migrations
+// from other platforms. It reads the same configuration as the server, so run
+// it from the backend directory.
+//
+//	go run ./cmd/users import -f users.csv [-on-duplicate skip|update|fail] [-send-welcome]
+//	go run ./cmd/users export [-format csv|json] [-role admin] [-password-hashes] [-o users.csv]
+//
+// The import report is printed as JSON; it exits with status 2 when any record failed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/database"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/mail"
+	"github.com/google/uuid"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "import":
+		runImport(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: users import -f FILE [flags] | users export [flags]")
+	os.Exit(1)
+}
+
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("f", "", "CSV or JSON file to import (required)")
+	format := flags.String("format", "", "file format: csv or json (default from the file extension)")
+	onDuplicate := flags.String("on-duplicate", string(auth.DuplicateSkip), "existing accounts: skip, update or fail")
+	sendWelcome := flags.Bool("send-welcome", false, "email created users a link to set their password")
+	flags.Parse(args)
+
+	if *file == "" {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	users, err := auth.ParseUserImport(f, auth.BulkFormat(strings.ToLower(*format)))
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", *file, err)
+	}
+
+	service := newAuthService()
+	report, err := service.ImportUsers(uuid.Nil, users, auth.ImportOptions{
+		OnDuplicate: auth.DuplicatePolicy(*onDuplicate),
+		SendWelcome: *sendWelcome,
+	})
+	if err != nil {
+		log.Fatalf("Failed to import users: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+	if report.Failed > 0 {
+		os.Exit(2)
+	}
+}
+
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", string(auth.FormatCSV), "file format: csv or json")
+	role := flags.String("role", "", "only export users with this role")
+	passwordHashes := flags.Bool("password-hashes", false, "include bcrypt password hashes")
+	output := flags.String("o", "", "output file (default stdout)")
+	flags.Parse(args)
+
+	service := newAuthService()
+	users, err := service.ExportUsers(auth.Role(*role), *passwordHashes)
+	if err != nil {
+		log.Fatalf("Failed to export users: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := auth.WriteUserExport(w, users, auth.BulkFormat(*format)); err != nil {
+		log.Fatalf("Failed to write export: %v", err)
+	}
+}
+
+// newAuthService connects to the database and mail server from the configuration
+func newAuthService() *auth.Service {
+	loggerService, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+
+	cfg, err := config.NewConfigService(loggerService).Load(".")
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewDatabaseService(&cfg.Database, loggerService).Connect()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	authConfig := auth.NewConfigFromAuthConfig(&cfg.Auth)
+	service := auth.NewService(db, auth.NewJWTService(authConfig), auth.NewRefreshTokenRepository(db, loggerService), authConfig, loggerService)
+	service.SetMailer(mail.NewMailer(&mail.Config{
+		Host:     cfg.Email.SMTP.Host,
+		Port:     cfg.Email.SMTP.Port,
+		Username: cfg.Email.SMTP.Username,
+		Password: cfg.Email.SMTP.Password,
+		From:     cfg.Email.From,
+	}, loggerService))
+	return service
+}
@@ -24,38 +24,136 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/notifications/": {
+        "/.well-known/webfinger": {
+            "get": {
+                "description": "Resolves acct:username@domain to the channel's ActivityPub actor, so Fediverse users can look channels up by handle. Served at the root of the domain as application/jrd+json; only served when ActivityPub is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "activitypub"
+                ],
+                "summary": "Resolve a Fediverse account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account to resolve, e.g. acct:johndoe@pavilion.example.com",
+                        "name": "resource",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resource descriptor",
+                        "schema": {
+                            "$ref": "#/definitions/activitypub.WebFinger"
+                        }
+                    },
+                    "400": {
+                        "description": "Resource is not an account of this server",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Channel not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve a paginated list of notifications for the authenticated user",
+                "description": "Get a page of the audit log, newest first: uploads and deletions of videos and comments, comments caught by the banned words filter, logins and role changes. Filter by a user, who may be the actor or the target, by event type and by time range. Without from, the last 30 days are searched; a range may be at most 366 days. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "admin"
                 ],
-                "summary": "Get user notifications",
+                "summary": "List audit events",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Number of notifications to return (default: 10)",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "Only events by or about this user (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "video.uploaded",
+                            "video.deleted",
+                            "comment.created",
+                            "comment.deleted",
+                            "comment.filtered",
+                            "user.login",
+                            "user.role_changed"
+                        ],
+                        "type": "string",
+                        "description": "Event type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range, RFC 3339 (default: 30 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, exclusive, RFC 3339 (default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor of the previous page",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Page number for pagination (default: 1)",
-                        "name": "page",
+                        "description": "Events per page (default: 50, max: 200)",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Notifications retrieved successfully",
+                        "description": "Audit events retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -65,18 +163,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/notification.Notification"
-                                            }
+                                            "$ref": "#/definitions/audit.Page"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Invalid filter or cursor",
                         "schema": {
                             "allOf": [
                                 {
@@ -93,8 +188,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
@@ -110,34 +205,9 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/api/v1/notifications/read-all": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mark all notifications as read for the authenticated user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "notifications"
-                ],
-                "summary": "Mark all notifications as read",
-                "responses": {
-                    "200": {
-                        "description": "All notifications marked as read",
-                        "schema": {
-                            "$ref": "#/definitions/http.APIResponse"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
                             "allOf": [
                                 {
@@ -175,24 +245,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/notifications/unread-count": {
+        "/admin/audit/export": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get the count of unread notifications for the authenticated user",
+                "description": "Download every audit event matching the filters as CSV, newest first, with the columns created_at, id, type, actor_id, target_id, ip_address and details (key=value pairs separated by semicolons). Admins only.",
                 "produces": [
+                    "text/csv",
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "admin"
+                ],
+                "summary": "Export audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only events by or about this user (UUID)",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "video.uploaded",
+                            "video.deleted",
+                            "comment.created",
+                            "comment.deleted",
+                            "comment.filtered",
+                            "user.login",
+                            "user.role_changed"
+                        ],
+                        "type": "string",
+                        "description": "Event type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range, RFC 3339 (default: 30 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, exclusive, RFC 3339 (default: now)",
+                        "name": "to",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get unread notification count",
                 "responses": {
                     "200": {
-                        "description": "Unread count retrieved successfully",
+                        "description": "CSV file of audit events",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
                         "schema": {
                             "allOf": [
                                 {
@@ -201,11 +313,8 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "integer"
-                                            }
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -230,8 +339,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
                             "allOf": [
                                 {
@@ -251,39 +360,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/notifications/{id}/read": {
-            "put": {
+        "/admin/federation/instances": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mark a specific notification as read",
+                "description": "Get the remote instances this instance subscribes to, with the outcome of the last pull of each",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
-                ],
-                "summary": "Mark notification as read",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Notification ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "List remote instances",
                 "responses": {
                     "200": {
-                        "description": "Notification marked as read",
-                        "schema": {
-                            "$ref": "#/definitions/http.APIResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid notification ID",
+                        "description": "Instances retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -292,8 +386,11 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "error": {
-                                            "$ref": "#/definitions/http.APIError"
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/federation.Instance"
+                                            }
                                         }
                                     }
                                 }
@@ -318,8 +415,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Notification not found",
+                    "403": {
+                        "description": "Forbidden - admin only",
                         "schema": {
                             "allOf": [
                                 {
@@ -355,11 +452,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/login": {
+            },
             "post": {
-                "description": "Authenticate user and return JWT tokens",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a remote Pavilion instance by its API base URL. Its federation document is fetched and its public key pinned; its public videos are then pulled periodically and included in video listings with their origin.",
                 "consumes": [
                     "application/json"
                 ],
@@ -367,23 +467,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Login user",
+                "summary": "Subscribe to a remote instance",
                 "parameters": [
                     {
-                        "description": "Login credentials",
+                        "description": "Instance URL",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.LoginRequest"
+                            "$ref": "#/definitions/federation.RegisterRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful",
+                        "description": "Instance registered",
                         "schema": {
                             "allOf": [
                                 {
@@ -393,7 +493,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/auth.LoginResponse"
+                                            "$ref": "#/definitions/federation.Instance"
                                         }
                                     }
                                 }
@@ -401,7 +501,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request format",
+                        "description": "Invalid URL",
                         "schema": {
                             "allOf": [
                                 {
@@ -419,7 +519,7 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Invalid credentials",
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
@@ -435,48 +535,27 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Invalidate refresh token and end user session",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Logout user",
-                "parameters": [
-                    {
-                        "description": "Refresh token to invalidate",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/auth.RefreshTokenRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Logout successful",
+                    },
+                    "403": {
+                        "description": "Forbidden - admin only",
                         "schema": {
-                            "$ref": "#/definitions/http.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request format",
+                    "409": {
+                        "description": "Instance already registered",
                         "schema": {
                             "allOf": [
                                 {
@@ -493,8 +572,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized or invalid token",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "allOf": [
                                 {
@@ -510,37 +589,61 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/auth/refresh": {
-            "post": {
-                "description": "Get a new access token using a valid refresh token",
-                "consumes": [
-                    "application/json"
+                    },
+                    "502": {
+                        "description": "Instance unreachable or its document is invalid",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/federation/instances/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Stop pulling a remote instance and remove its videos from listings",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Refresh access token",
+                "summary": "Unsubscribe from a remote instance",
                 "parameters": [
                     {
-                        "description": "Refresh token",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/auth.RefreshTokenRequest"
-                        }
+                        "type": "string",
+                        "description": "Instance ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Token refresh successful",
+                        "description": "Instance deleted",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid instance ID",
                         "schema": {
                             "allOf": [
                                 {
@@ -549,16 +652,16 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/auth.LoginResponse"
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request format",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
@@ -575,8 +678,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "Invalid or expired refresh token",
+                    "403": {
+                        "description": "Forbidden - admin only",
                         "schema": {
                             "allOf": [
                                 {
@@ -592,37 +695,9 @@ const docTemplate = `{
                                 }
                             ]
                         }
-                    }
-                }
-            }
-        },
-        "/auth/register": {
-            "post": {
-                "description": "Register a new user account",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Register new user",
-                "parameters": [
-                    {
-                        "description": "Registration details",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/auth.RegisterRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Registration successful",
+                    },
+                    "404": {
+                        "description": "Instance not found",
                         "schema": {
                             "allOf": [
                                 {
@@ -631,16 +706,16 @@ const docTemplate = `{
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/auth.User"
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request format or user already exists",
+                    "500": {
+                        "description": "Internal server error",
                         "schema": {
                             "allOf": [
                                 {
@@ -660,62 +735,67 @@ const docTemplate = `{
                 }
             }
         },
-        "/comment/{id}": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+        "/admin/jobs": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates the content of an existing comment",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a page of background jobs, newest first, with their status, attempts and last error. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
-                    "comment"
+                    "admin"
                 ],
-                "summary": "Update a comment",
+                "summary": "List background jobs",
                 "parameters": [
                     {
+                        "enum": [
+                            "pending",
+                            "running",
+                            "succeeded",
+                            "failed",
+                            "cancelled"
+                        ],
                         "type": "string",
-                        "description": "Comment ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Only jobs in this status",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
-                        "description": "Updated comment data",
-                        "name": "comment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/comment.UpdateCommentRequest"
-                            "$ref": "#/definitions/comment.UpdateCommentRequest"
-                        }
+                        "type": "string",
+                        "description": "Only jobs of this type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Comment updated successfully",
+                        "description": "Jobs retrieved",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "message": {
-                                            "type": "string"
+                                        "data": {
+                                            "$ref": "#/definitions/jobs.ListResponse"
                                         }
                                     }
                                 }
@@ -723,17 +803,17 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid comment ID format or invalid comment data",
+                        "description": "Invalid status",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -741,35 +821,35 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - user not authenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Comment not found",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -781,13 +861,13 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -795,71 +875,39 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Deletes an existing comment",
+            }
+        },
+        "/admin/jobs/schedules": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Deletes an existing comment",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the recurring job schedules with their cron spec, next run and last queued job. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
-                    "comment"
-                ],
-                "summary": "Delete a comment",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Comment ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "List job schedules",
                 "responses": {
                     "200": {
-                        "description": "Comment deleted successfully",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/http.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "message": {
-                                            "type": "string"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid comment ID format",
+                        "description": "Schedules retrieved",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/jobs.Schedule"
+                                            }
                                         }
                                     }
                                 }
@@ -867,35 +915,35 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - user not authenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Comment not found",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -907,13 +955,13 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -923,63 +971,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/comment/{id}/reaction": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Adds a reaction (like/dislike) to a comment",
+        "/admin/jobs/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a reaction (like/dislike) to a comment",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a background job with its payload, attempts and last error. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
-                    "comment"
+                    "admin"
                 ],
-                "summary": "Add a reaction to a comment",
+                "summary": "Get a background job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comment ID (UUID)",
+                        "description": "Job ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Reaction data",
-                        "name": "reaction",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/comment.ReactionRequest"
-                            "$ref": "#/definitions/comment.ReactionRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Reaction added successfully",
+                        "description": "Job retrieved",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "message": {
-                                            "type": "string"
+                                        "data": {
+                                            "$ref": "#/definitions/jobs.Job"
                                         }
                                     }
                                 }
@@ -987,17 +1015,17 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid comment ID format or invalid reaction type",
+                        "description": "Invalid job ID",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1005,17 +1033,35 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - user not authenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1023,17 +1069,17 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Comment not found",
+                        "description": "Job not found",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1045,13 +1091,13 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1059,34 +1105,27 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
+            }
+        },
+        "/admin/jobs/{id}/cancel": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Removes a user's reaction from a comment",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stop a pending or running job from running again. A running attempt is interrupted; handlers that ignore their context finish the attempt, but its outcome is not recorded. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
-                    "comment"
+                    "admin"
                 ],
-                "summary": "Remove a reaction from a comment",
+                "summary": "Cancel a background job",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comment ID (UUID)",
+                        "description": "Job ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1094,17 +1133,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Reaction removed successfully",
+                        "description": "Job cancelled",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "message": {
-                                            "type": "string"
+                                        "data": {
+                                            "$ref": "#/definitions/jobs.Job"
                                         }
                                     }
                                 }
@@ -1112,17 +1151,17 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid comment ID format",
+                        "description": "Invalid job ID",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1130,17 +1169,35 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized - user not authenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1148,18 +1205,35 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Comment or reaction not found",
-                        "description": "Comment or reaction not found",
+                        "description": "Job not found",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Job has already finished",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1171,13 +1245,13 @@ const docTemplate = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1187,90 +1261,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/comment/{id}/replies": {
+        "/admin/notifications/metrics": {
             "get": {
-                "description": "Retrieves a paginated list of replies for a specific comment",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Per-topic throughput, failure counts and subscription backlog of the notification pipeline. The same data is exported to Prometheus at /metrics.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
-                    "comment"
-                ],
-                "summary": "Get replies to a comment",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Comment ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of replies per page (default: 20, max: 100)",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "notifications"
                 ],
+                "summary": "Get notification pipeline metrics",
                 "responses": {
                     "200": {
-                        "description": "Replies retrieved successfully",
+                        "description": "Metrics retrieved",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/comment.PaginatedComments"
+                                            "$ref": "#/definitions/notification.MetricsReport"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid comment ID format",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1280,70 +1334,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/health": {
+        "/admin/scans": {
             "get": {
-                "description": "Checks if the API server is running properly",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "health"
-                ],
-                "summary": "Health check endpoint",
-                "responses": {
-                    "200": {
-                        "description": "Health check successful",
-                        "schema": {}
-                    }
-                }
-            }
-        },
-        "/video/upload": {
-            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload a new video file",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+                "description": "List uploads whose content scan needs review, most recently scanned first: flagged, quarantined, blocked and failed scans, or only those with the given status. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "video"
                 ],
-                "summary": "Upload video",
+                "summary": "List content scan results",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Video file to upload (.mp4, .mov)",
-                        "name": "video",
-                        "in": "formData",
-                        "required": true
+                        "enum": [
+                            "clean",
+                            "flagged",
+                            "quarantined",
+                            "blocked",
+                            "error",
+                            "released"
+                        ],
+                        "type": "string",
+                        "description": "Only results with this status",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
-                        "maxLength": 100,
-                        "minLength": 3,
-                        "type": "string",
-                        "description": "Video title (3-100 characters)",
-                        "name": "title",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "maxLength": 1000,
-                        "type": "string",
-                        "description": "Video description (max 1000 characters)",
-                        "name": "description",
-                        "in": "formData"
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Upload completed successfully",
+                        "description": "Scan results retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -1353,7 +1389,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/video.UploadResponse"
+                                            "$ref": "#/definitions/video.ScanListResponse"
                                         }
                                     }
                                 }
@@ -1361,7 +1397,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request format or validation error",
+                        "description": "Invalid status",
                         "schema": {
                             "$ref": "#/definitions/video.APIResponse"
                         }
@@ -1372,8 +1408,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/video.APIResponse"
                         }
                     },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
                     "500": {
-                        "description": "Processing error",
+                        "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/video.APIResponse"
                         }
@@ -1381,43 +1423,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/video/{id}": {
+        "/admin/stats": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve detailed information about a specific video",
+                "description": "User counts, uploads per day, storage taken by original files per backend and the notification backlog. Days without uploads are listed with zero counts. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "video"
+                    "admin"
                 ],
-                "summary": "Get video details",
+                "summary": "Get dashboard statistics",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Video ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "Days of uploads and sign-ins to report (default: 30, max: 365)",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Video details retrieved successfully",
+                        "description": "Statistics retrieved",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/video.APIResponse"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/video.VideoDetailsResponse"
+                                            "$ref": "#/definitions/admin.Stats"
                                         }
                                     }
                                 }
@@ -1427,117 +1468,78 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Video not found or has been deleted",
-                        "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Soft delete a video (marks as deleted but preserves the record)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "video"
-                ],
-                "summary": "Delete video",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Video ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Video deleted successfully",
-                        "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid video ID format",
-                        "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Video not found",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/storage/tiering": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update a video's title and/or description",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "The storage tiering policy, originals and their bytes by storage class, videos whose renditions are dropped, per-video overrides and the outcome of the latest run. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "video"
                 ],
-                "summary": "Update video details",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Video ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/video.VideoUpdateRequest"
-                        }
-                    }
-                ],
+                "summary": "Get the storage tiering report",
                 "responses": {
                     "200": {
-                        "description": "Video updated successfully",
+                        "description": "Tiering report retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -1547,27 +1549,21 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/video.VideoDetailsResponse"
+                                            "$ref": "#/definitions/video.TieringReport"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid request format or validation error",
-                        "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/video.APIResponse"
                         }
                     },
-                    "404": {
-                        "description": "Video not found or has been deleted",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
                             "$ref": "#/definitions/video.APIResponse"
                         }
@@ -1577,54 +1573,48 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/video.APIResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Storage tiering is not available with this storage backend",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
                     }
                 }
             }
         },
-        "/video/{id}/comment": {
-            "post": {
-                "description": "Creates a new comment for a video",
-                "consumes": [
-                    "application/json"
+        "/admin/transcodes/failed": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get a page of uploads that failed or could not produce every rendition, most recently updated first, with the reason for each missing rendition. Admins only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
-                    "comment"
+                    "admin"
                 ],
-                "summary": "Create a new comment",
-                "summary": "Create a new comment",
+                "summary": "List failed transcodes",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Video ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Bearer token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Comment data",
-                        "name": "comment",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/comment.CreateCommentRequest"
-                            "$ref": "#/definitions/comment.CreateCommentRequest"
-                        }
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Comment created successfully",
+                        "description": "Failed transcodes retrieved",
                         "schema": {
                             "allOf": [
                                 {
@@ -1634,15 +1624,15 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/comment.Comment"
+                                            "$ref": "#/definitions/admin.FailedTranscodeListResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Invalid video ID format or invalid comment",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
@@ -1659,8 +1649,8 @@ const docTemplate = `{
                             ]
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
                             "allOf": [
                                 {
@@ -1678,7 +1668,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "Failed to create comment",
+                        "description": "Internal server error",
                         "schema": {
                             "allOf": [
                                 {
@@ -1698,60 +1688,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/video/{id}/comments": {
+        "/admin/users": {
             "get": {
-                "description": "Retrieves a paginated list of comments for a specific video",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "List user accounts with their roles. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "comment"
-                    "comment"
+                    "admin"
                 ],
-                "summary": "Get comments for a video",
+                "summary": "List users",
                 "parameters": [
                     {
+                        "enum": [
+                            "user",
+                            "moderator",
+                            "admin"
+                        ],
                         "type": "string",
-                        "description": "Video ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of comments per page (default: 20, max: 100)",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sort order (options: newest, oldest, most_liked; default: newest)",
-                        "name": "sort",
+                        "description": "Only return users with this role",
+                        "name": "role",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Comments retrieved successfully",
+                        "description": "Users retrieved",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/comment.PaginatedComments"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/auth.User"
+                                            }
                                         }
                                     }
                                 }
@@ -1759,35 +1739,53 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid video ID format",
+                        "description": "Invalid role",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/http.Response"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "error": {
-                                            "$ref": "#/definitions/http.Error"
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1797,46 +1795,73 @@ const docTemplate = `{
                 }
             }
         },
-        "/video/{id}/status": {
+        "/admin/users/export": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve the current upload status of a specific video",
+                "description": "Download all users as a CSV file or JSON array, optionally filtered by role. The export can be imported into another instance. Password hashes are only included when includePasswordHashes is set. Admin only.",
                 "produces": [
+                    "text/csv",
                     "application/json"
                 ],
                 "tags": [
-                    "video"
+                    "admin"
                 ],
-                "summary": "Get video upload status",
+                "summary": "Export users",
                 "parameters": [
                     {
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
                         "type": "string",
-                        "description": "Video ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                        "description": "File format (default: csv)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "user",
+                            "moderator",
+                            "admin"
+                        ],
+                        "type": "string",
+                        "description": "Only export users with this role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include bcrypt password hashes, so users keep their passwords after a migration",
+                        "name": "includePasswordHashes",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "Video status retrieved successfully",
+                        "description": "Exported users",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.ExportUser"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid format or role",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/video.APIResponse"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "additionalProperties": {
-                                                "type": "string"
-                                            }
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
                                         }
                                     }
                                 }
@@ -1846,66 +1871,115 @@ const docTemplate = `{
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Video not found or has been deleted",
-                        "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/videos": {
-            "get": {
+        "/admin/users/import": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve a paginated list of videos with detailed information including transcodes",
+                "description": "Create accounts in bulk, e.g. when migrating from another platform. The body is a CSV file with a header row or a JSON array of records. Each record carries a bcrypt passwordHash, a temporary password, or neither, in which case the user has to set a password through the welcome email or a password reset. Every record is validated and imported on its own; the report lists the outcome of each. Existing accounts are skipped, reported as failed or updated depending on onDuplicate; imports never change roles of existing accounts. Admin only.",
+                "consumes": [
+                    "text/csv",
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "video"
+                    "admin"
                 ],
-                "summary": "List videos",
+                "summary": "Import users",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Number of videos to return (default: 10, max: 50)",
-                        "name": "limit",
+                        "enum": [
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Body format; defaults to csv for a text/csv body and json otherwise",
+                        "name": "format",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "Page number for pagination (default: 1)",
-                        "name": "page",
+                        "enum": [
+                            "skip",
+                            "update",
+                            "fail"
+                        ],
+                        "type": "string",
+                        "description": "What to do with records whose email or username is taken (default: skip)",
+                        "name": "onDuplicate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Email each created user a welcome message with a link to set their password",
+                        "name": "sendWelcome",
                         "in": "query"
+                    },
+                    {
+                        "description": "Users to import",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/auth.ImportUser"
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Videos retrieved successfully with detailed information",
+                        "description": "Import finished; see the report for failed records",
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/video.APIResponse"
+                                    "$ref": "#/definitions/http.APIResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/video.VideoListResponse"
+                                            "$ref": "#/definitions/auth.ImportReport"
                                         }
                                     }
                                 }
@@ -1913,605 +1987,17001 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request parameters",
+                        "description": "Unreadable file or invalid options",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "403": {
+                        "description": "Admin role required",
                         "schema": {
-                            "$ref": "#/definitions/video.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "auth.LoginRequest": {
-            "description": "Login request payload",
-            "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
-            "properties": {
-                "email": {
-                    "description": "User email address",
-                    "type": "string",
-                    "example": "user@example.com"
-                },
-                "password": {
-                    "description": "User password",
-                    "type": "string",
-                    "minLength": 8,
-                    "example": "Pass123!"
-                }
-            }
         },
-        "auth.LoginResponse": {
-            "description": "Login response payload",
-            "type": "object",
-            "properties": {
-                "accessToken": {
-                    "description": "JWT access token",
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIs..."
-                },
-                "expiresIn": {
-                    "description": "Token expiration time in seconds",
-                    "type": "integer",
-                    "example": 3600
-                },
-                "refreshToken": {
-                    "description": "JWT refresh token",
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIs..."
-                },
-                "tokenType": {
-                    "description": "Token type (always \"Bearer\")",
-                    "type": "string",
-                    "example": "Bearer"
-                },
-                "user": {
-                    "description": "User information",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/auth.User"
+        "/admin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set a user's role to user, moderator or admin. The user's sessions are revoked so the change applies at their next login. The last admin cannot be demoted. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Change a user's role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.UpdateRoleRequest"
                         }
-                    ]
-                }
-            }
-        },
-        "auth.RefreshTokenRequest": {
-            "description": "Refresh token request payload",
-            "type": "object",
-            "required": [
-                "refreshToken"
-            ],
-            "properties": {
-                "refreshToken": {
-                    "description": "Valid refresh token",
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIs..."
-                }
-            }
-        },
-        "auth.RegisterRequest": {
-            "description": "Registration request payload",
-            "type": "object",
-            "required": [
-                "email",
-                "password",
-                "username"
-            ],
-            "properties": {
-                "email": {
-                    "description": "User email address",
-                    "type": "string",
-                    "example": "user@example.com"
-                },
-                "name": {
-                    "description": "User's full name",
-                    "type": "string",
-                    "example": "John Doe"
-                },
-                "password": {
-                    "description": "User password (min 8 characters)",
-                    "type": "string",
-                    "minLength": 6,
-                    "example": "Pass123!"
-                },
-                "username": {
-                    "description": "Unique username",
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role updated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or role",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot demote the last admin",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/suspend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deactivate an account. The user can no longer sign in, refresh tokens or use API keys; access tokens already issued stay valid until they expire. Admins cannot suspend themselves or the last active admin. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Suspend a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Suspension reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.SuspendUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User suspended",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or missing reason",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot suspend yourself or the last admin",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Reactivate a suspended account. The user signs in again to get new tokens. Admin only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reinstate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User reinstated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/videos/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Clear a flagged, quarantined or failed content scan, publishing the video. Blocked uploads have nothing stored and cannot be released. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Release a video from its content scan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video released",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Video has no scan result to release",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/videos/{id}/takedown": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Hide a video from listings and from everyone but its owner and staff, who see the reason. Taking down a video that is already down updates the reason. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Take down a video",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Takedown reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/video.TakedownRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video taken down",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID or missing reason",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lift a takedown, publishing the video again. Admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Restore a taken down video",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video restored",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Video is not taken down",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/videos/{id}/tiering": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Exempt a video from storage tiering, or tier it at the next run whatever its age and views. Exempting a video whose renditions were dropped regenerates them; an original already moved keeps its storage class. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Set a video's storage tiering override",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Override",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/video.TieringOverrideRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Override set",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoTiering"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID or override",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage tiering is not available with this storage backend",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ap/users/{username}": {
+            "get": {
+                "description": "Returns the channel's ActivityPub Person, with its inbox, outbox, followers collection and the RSA key its deliveries are signed with",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "activitypub"
+                ],
+                "summary": "Get a channel's actor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Channel username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Actor",
+                        "schema": {
+                            "$ref": "#/definitions/activitypub.Actor"
+                        }
+                    },
+                    "404": {
+                        "description": "Channel not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/ap/users/{username}/followers": {
+            "get": {
+                "description": "Returns how many Fediverse actors follow the channel; the followers themselves are not listed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "activitypub"
+                ],
+                "summary": "Get a channel's followers collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Channel username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Followers collection",
+                        "schema": {
+                            "$ref": "#/definitions/activitypub.OrderedCollection"
+                        }
+                    },
+                    "404": {
+                        "description": "Channel not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/ap/users/{username}/inbox": {
+            "post": {
+                "description": "Accepts Follow and Undo of a Follow from Fediverse actors; a Follow is answered with an Accept delivered to the follower's inbox. The request must carry an HTTP signature (rsa-sha256) over (request-target), host, date and digest, made with the key of the activity's actor. Other activities are accepted and ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "activitypub"
+                ],
+                "summary": "Deliver an activity to a channel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Channel username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Activity",
+                        "name": "activity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/activitypub.Activity"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Activity accepted"
+                    },
+                    "400": {
+                        "description": "Malformed activity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid HTTP signature",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Channel not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/ap/users/{username}/outbox": {
+            "get": {
+                "description": "Without page, returns the outbox collection with its total and links to the first and last pages. With page, returns that page of Create activities for the channel's public videos, newest first, 20 per page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "activitypub"
+                ],
+                "summary": "Get a channel's outbox",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Channel username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Outbox collection, or a page of it",
+                        "schema": {
+                            "$ref": "#/definitions/activitypub.OrderedCollectionPage"
+                        }
+                    },
+                    "404": {
+                        "description": "Channel not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/ap/videos/{id}": {
+            "get": {
+                "description": "Returns a public video as an ActivityStreams Video, the object of the Create activities in its channel's outbox",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "activitypub"
+                ],
+                "summary": "Get a video object",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video object",
+                        "schema": {
+                            "$ref": "#/definitions/activitypub.Video"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found or not public",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/apikeys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current user's API keys, including revoked ones. Plaintext keys are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List API keys",
+                "responses": {
+                    "200": {
+                        "description": "API keys retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/auth.APIKey"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a scoped API key for server-to-server access. Send it in the X-API-Key header. The plaintext key is only returned in this response. Only admins can grant the admin scope.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "Key name, scopes and lifetime",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.CreateAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API key created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.CreateAPIKeyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or scope",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Admin scope not allowed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/apikeys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the current user's API keys. Requests made with it are rejected from then on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "API key revoked",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid key ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "API key not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Email a time-limited password reset link. The response is the same whether or not the account exists.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset email sent if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate user and return JWT tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.LoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Email not verified",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "423": {
+                        "description": "Account temporarily locked; see the Retry-After header",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invalidate refresh token and end user session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout user",
+                "parameters": [
+                    {
+                        "description": "Refresh token to invalidate",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logout successful",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized or invalid token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oauth/{provider}/callback": {
+            "get": {
+                "description": "Exchange the provider's authorization code and return the same token pair as password login. On first login the provider identity is linked to the account with the same verified email, or a new account is created.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Complete OAuth login",
+                "parameters": [
+                    {
+                        "enum": [
+                            "google",
+                            "github"
+                        ],
+                        "type": "string",
+                        "description": "OAuth provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State returned by the provider",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.LoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Missing code, invalid state or login denied",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Account disabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Provider email not verified",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown or disabled provider",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "502": {
+                        "description": "Provider request failed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oauth/{provider}/login": {
+            "get": {
+                "description": "Redirect to the provider's consent page. A short-lived state cookie ties the callback to this browser.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Start OAuth login",
+                "parameters": [
+                    {
+                        "enum": [
+                            "google",
+                            "github"
+                        ],
+                        "type": "string",
+                        "description": "OAuth provider",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the provider"
+                    },
+                    "404": {
+                        "description": "Unknown or disabled provider",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Get a new access token using a valid refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token refresh successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.LoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired refresh token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register new user",
+                "parameters": [
+                    {
+                        "description": "Registration details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Registration successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or user already exists",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/resend-verification": {
+            "post": {
+                "description": "Send a new verification email to an unverified account. Requests for the same account are throttled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend verification email",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResendVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verification email sent if the account needs one",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password using the token from a password reset email. All existing sessions are signed out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset successful",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request, token or password",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current user's active sessions with the device each was started from. The session making the request is marked as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "Sessions retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/auth.Session"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sign out every session of the current user except the one making the request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke other sessions",
+                "responses": {
+                    "200": {
+                        "description": "Other sessions revoked",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/auth.RevokeSessionsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sign out one of the current user's sessions. Its refresh token stops working immediately; access tokens already issued to it expire on their own.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session revoked",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid session ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "get": {
+                "description": "Mark an account as verified using the token from a verification email",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify email address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing, invalid or expired token",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/comment/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the content of an existing comment, sanitized and checked like a new comment. Banned words are filtered as in new comments, so an edit may be rejected, masked or hold the comment for review.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Update a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated comment data",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/comment.UpdateCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comment updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.Comment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid comment ID format or invalid comment data",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - user not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes an existing comment. Authors can delete their own comments; moderators and admins can delete any comment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Delete a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comment deleted successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid comment ID format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - user not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Not the comment author or a moderator",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/comment/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publishes a comment held for review on a video whose comments policy is review_required. Only the video owner can review comments; comments that are not held are returned unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Approve a held comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comment approved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.Comment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid comment ID format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - user not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/comment/{id}/reaction": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds a reaction (like/dislike) to a comment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Add a reaction to a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reaction data",
+                        "name": "reaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/comment.ReactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reaction added successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid comment ID format or invalid reaction type",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - user not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a user's reaction from a comment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Remove a reaction from a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reaction removed successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "message": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid comment ID format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - user not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Comment or reaction not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/comment/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a comment held for review on a video whose comments policy is review_required. Only the video owner can review comments; comments that are not held are returned unchanged.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Reject a held comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comment rejected",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.Comment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid comment ID format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - user not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Comment not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/comment/{id}/replies": {
+            "get": {
+                "description": "Retrieves a paginated list of replies for a specific comment. Replies held for review are only listed for their author and the video owner. With a bearer token, replies of users the caller blocked are left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Get replies to a comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of replies per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Replies retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.PaginatedComments"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "304": {
+                        "description": "The client's copy is current"
+                    },
+                    "400": {
+                        "description": "Invalid comment ID format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/embed/{id}": {
+            "get": {
+                "description": "Minimal player page for iframes, or with format=json the config for a custom player. Only completed videos that need no entitlement and whose owner allows embedding can be played. When the owner lists allowed domains, the host of the Origin header, or else the Referer header, must be one of them or their subdomains, and the page's Content-Security-Policy only lets browsers frame it there. Sources may be signed URLs that expire, so responses are not cached. Served at the root of the domain; only served when embedding is enabled.",
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "embed"
+                ],
+                "summary": "Embedded player",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "html",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Response format (default: html)",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Player page, or player config",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/embed.PlayerConfig"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Video cannot be embedded, or not on this site",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "451": {
+                        "description": "Video is not available in the viewer's country",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/entitlements": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the authenticated user's video entitlements, including expired and revoked ones",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entitlements"
+                ],
+                "summary": "List my entitlements",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entitlements retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entitlement.EntitlementListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/entitlements/webhook": {
+            "post": {
+                "description": "Grant or revoke video access from an external payment system. The request must carry an X-Pavilion-Timestamp header with the Unix time it was signed at, within the configured tolerance of the server's clock, and an X-Pavilion-Signature header of the form \"sha256=\u003chex HMAC-SHA256 of the timestamp, a dot and the body\u003e\" keyed with the configured webhook secret. Each event carries an id and is applied once; an event with an id already applied is acknowledged without being applied again, so retries are safe and captured events cannot be replayed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entitlements"
+                ],
+                "summary": "Entitlement webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Unix time the event was signed at",
+                        "name": "X-Pavilion-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timestamp and body signature",
+                        "name": "X-Pavilion-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Grant or revoke event",
+                        "name": "event",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entitlement.WebhookEvent"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event applied, or already applied",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid signature, or timestamp outside the tolerance",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User or video not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Webhook not configured",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/federation/instance": {
+            "get": {
+                "description": "Returns this instance's name and the base64 Ed25519 public key its outbox is signed with. Served as bare JSON, outside the usual response envelope, for remote Pavilion instances; only served when federation is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "federation"
+                ],
+                "summary": "Get the federation document",
+                "responses": {
+                    "200": {
+                        "description": "Federation document",
+                        "schema": {
+                            "$ref": "#/definitions/federation.Document"
+                        }
+                    }
+                }
+            }
+        },
+        "/federation/outbox": {
+            "get": {
+                "description": "Returns a page of this instance's public videos, ordered by when each last changed. Videos that were deleted, taken down or made private since are listed with only their ID and deleted set. Pass next_cursor as since to continue. Served as bare JSON, outside the usual response envelope, with an X-Pavilion-Signature header of the form \"ed25519=\u003cbase64 signature of the body\u003e\" made with the key in the federation document.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "federation"
+                ],
+                "summary": "Get the federation outbox",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor from a previous page's next_cursor; omit to start from the beginning",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Videos per page (default: 100, max: 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Outbox page",
+                        "schema": {
+                            "$ref": "#/definitions/federation.Outbox"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/latest": {
+            "get": {
+                "description": "MRSS feed of the newest public videos of every channel, built like channel feeds. Only served when feeds are enabled.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Get the latest videos feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Feed",
+                        "schema": {
+                            "$ref": "#/definitions/feed.RSS"
+                        }
+                    },
+                    "304": {
+                        "description": "Feed unchanged"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Run a read-only GraphQL query over videos, comments, profiles and the viewer's notifications, so a page can fetch what it shows in one request. Comment counts and user lookups of one query are batched.\nQueries deeper than graphql.maxDepth or costlier than graphql.maxComplexity are rejected. Every field costs 1, and the fields under a list taking a limit argument count once per item.\nResponses follow the GraphQL over HTTP format: data and errors, not the API's usual envelope. The schema can be fetched by introspection.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Run a GraphQL query",
+                "parameters": [
+                    {
+                        "description": "GraphQL query",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Query result; field errors are listed in errors",
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed request, invalid query or query over the limits",
+                        "schema": {
+                            "$ref": "#/definitions/graphql.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "API key lacks the read scope",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Checks if the API server is running properly. Same as /health/live.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "Health check successful",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Reports that the server process is up without checking dependencies, for orchestrators deciding whether to restart it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "Server is live",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Checks every dependency concurrently and reports each one's status and latency. Returns 503 when a critical dependency (CockroachDB, Redis, ScyllaDB, S3) is down; non-critical ones (IPFS, Pulsar) only degrade the status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "Server is ready",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/health.Report"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "A critical dependency is down",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/health.Report"
+                                        },
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/live": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the streams that are live, most recently started first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "List live streams",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum streams (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Live streams",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.StreamListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/live/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get a live or ended stream. Live streams have the playback_url of their HLS playlist; ended streams link the video their recording was published as, once it is processed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Get a live stream",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Live stream",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.StreamResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid stream ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Stream not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/live/{id}/hls/{file}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Serve the HLS playlist (index.m3u8) of a live stream, or one of the MPEG-TS segments it lists. The playlist lists the latest segments and changes as the stream goes on, so it is not cached; segments never change. Both are served while the stream is live, and until its recording is published.",
+                "produces": [
+                    "application/vnd.apple.mpegurl",
+                    "video/mp2t"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Get a live stream's HLS playlist or segment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "index.m3u8, or a segment name from the playlist",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Playlist or segment",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid stream ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Stream, playlist or segment not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/comments/blocklist": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists the words and phrases the authenticated user bans from comments on their videos, on top of the site-wide list, and the action taken on comments containing them: reject, mask or hold for review.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "List banned comment words",
+                "responses": {
+                    "200": {
+                        "description": "Banned words",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.BannedWordsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the words and phrases the authenticated user bans from comments on their videos. Words are matched whole and ignoring case; the list is lowercased, deduplicated and sorted. New comments and edits are filtered; comments already posted are not. An empty list leaves only the site-wide list.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Set banned comment words",
+                "parameters": [
+                    {
+                        "description": "Banned words",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/comment.BannedWordsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Banned words, normalized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.BannedWordsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Too many words, or a word that is too long",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/embed/domains": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the domains the authenticated user's videos may be embedded on. An empty list means anywhere.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "embed"
+                ],
+                "summary": "List allowed embed domains",
+                "responses": {
+                    "200": {
+                        "description": "Allowed domains",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/embed.DomainsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the domains the authenticated user's videos may be embedded on; subdomains are allowed too. Schemes, ports and paths are dropped. An empty list allows embedding anywhere. Embedding can also be turned off per video with PATCH /video/{id}.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "embed"
+                ],
+                "summary": "Set allowed embed domains",
+                "parameters": [
+                    {
+                        "description": "Allowed domains",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/embed.DomainsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Allowed domains, normalized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/embed.DomainsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid domain, or too many domains",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the videos the authenticated user watched, most recent first, with the last position and completion percentage. Deleted videos are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Get watch history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from next_cursor of the previous page",
+                        "name": "before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Watch history retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/history.ListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid cursor",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete the authenticated user's whole watch history, including resume positions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Clear watch history",
+                "responses": {
+                    "200": {
+                        "description": "Watch history cleared",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/live": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the RTMP server to stream to, the title of the next streams, and the current stream while live. Stream keys are managed under /me/stream-keys.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Get streaming settings",
+                "responses": {
+                    "200": {
+                        "description": "Streaming settings",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.Settings"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set the title of the next live streams, which their recordings are published with. Streams already live keep their title.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Update streaming settings",
+                "parameters": [
+                    {
+                        "description": "Streaming settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/live.SettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Streaming settings",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.Settings"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid title",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/security/activity": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current user's recent logins, failed login attempts, token refreshes, logouts and password resets, newest first, with the IP address and user agent of each. Pass the createdAt of the last event as before to get older events.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List account activity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only events before this time, RFC 3339",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Events to return (default: 50, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account activity retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/auth.SecurityEvent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid before time",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/stream-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current user's stream keys, newest first, including revoked ones. Keys are masked; the full key is only shown when it is created or rotated.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "List stream keys",
+                "responses": {
+                    "200": {
+                        "description": "Stream keys",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.StreamKeyListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a named stream key. Streaming software publishes to ingest_url with the key as the stream key; each key has one live stream at a time. The key is only shown in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Create a stream key",
+                "parameters": [
+                    {
+                        "description": "Key name",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/live.CreateKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New stream key",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.KeyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid name",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Too many active stream keys",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/stream-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the current user's stream keys. It stops working, and a stream live with it is disconnected. Revoked keys stay listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Revoke a stream key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream key ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream key revoked",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid key ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Stream key not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/stream-keys/{id}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the secret of one of the current user's stream keys, keeping its name. The old key stops working, and a stream live with it is disconnected. The new key is only shown in this response.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Rotate a stream key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream key ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rotated stream key",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.KeyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid key ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Stream key not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Stream key is revoked",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/streams": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the current user's streams, live and ended, latest first, with the key each was published with, how long it lasted, and what was received. The average bitrate is known once a stream ends.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "List my streams",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Streams per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Streams",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/live.SessionListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/me/videos/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the requesting user's deleted videos that can still be restored, most recently deleted first. purge_at is when each is purged for good. Videos removed by a moderator or admin are listed with restorable=false; only an admin can restore them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "List deleted videos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of videos to return (default: 20, max: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted videos retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.TrashListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "The trash is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List reports in the moderation queue, newest first. Moderators and admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "List reports",
+                "parameters": [
+                    {
+                        "enum": [
+                            "open",
+                            "resolved",
+                            "dismissed"
+                        ],
+                        "type": "string",
+                        "description": "Only return reports with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reports retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/moderation.ReportListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Moderator role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a report with every snapshot of the reported content, oldest first. Snapshots keep the original text and file references even after the content is edited or deleted. Moderators and admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Get a report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/moderation.ReportDetail"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid report ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Moderator role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports/{id}/resolve": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Close a report as resolved or dismissed. Its evidence is kept. Moderators and admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Resolve a report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Outcome",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moderation.ResolveReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report resolved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/moderation.Report"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Moderator role required",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Report not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a cursor-paginated list of notifications for the authenticated user, along with the total unread count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get user notifications",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of notifications to return (default: 10, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as nextCursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return unread notifications (default: false)",
+                        "name": "unreadOnly",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notifications retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.NotificationPage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/digest/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get how often the authenticated user is emailed a digest of their unread notifications, and when the next one is due",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get digest preferences",
+                "responses": {
+                    "200": {
+                        "description": "Digest preferences retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.DigestPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Receive a daily or weekly email of the notifications that are still unread, or turn digests off. A new frequency starts its first period now. Only verified email addresses receive digests.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Update digest preferences",
+                "parameters": [
+                    {
+                        "description": "New digest frequency; nextDigestAt is ignored",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/notification.DigestPreferences"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Digest preferences updated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.DigestPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown frequency",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/push/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get which notification types are sent to the authenticated user's devices as push messages",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get push preferences",
+                "responses": {
+                    "200": {
+                        "description": "Push preferences retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.PushPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn push messages off, or mute notification types. Muted notifications are still stored and listed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Update push preferences",
+                "parameters": [
+                    {
+                        "description": "New push preferences",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/notification.PushPreferences"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Push preferences updated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.PushPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown notification type",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/push/public-key": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the VAPID public key to pass as applicationServerKey when subscribing a browser with pushManager.subscribe",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get the Web Push public key",
+                "responses": {
+                    "200": {
+                        "description": "Public key retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.PushPublicKeyResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/push/subscriptions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the browsers registered to receive push notifications for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "List push devices",
+                "responses": {
+                    "200": {
+                        "description": "Push subscriptions retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/notification.PushSubscription"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register the PushSubscription of a browser, as returned by pushManager.subscribe, to receive notifications as push messages. Registering the same endpoint again replaces its keys. Subscriptions the push service reports as expired are removed automatically.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Register a push device",
+                "parameters": [
+                    {
+                        "description": "The browser's PushSubscription as JSON",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/notification.PushSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Push subscription registered",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.PushSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid endpoint or keys",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Too many devices registered",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop sending push messages to a browser, e.g. after pushSubscription.unsubscribe. Unknown endpoints are ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Unregister a push device",
+                "parameters": [
+                    {
+                        "description": "Endpoint of the subscription to remove",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/notification.PushUnsubscribeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Push subscription removed",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing endpoint",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read-all": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark all notifications as read for the authenticated user by moving their read watermark to now",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark all notifications as read",
+                "responses": {
+                    "200": {
+                        "description": "All notifications marked as read",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read-state": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the authenticated user's read marks, so a device can sync them: every notification created at or before lastReadAt is read, and so is every notification listed in read",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get read state",
+                "responses": {
+                    "200": {
+                        "description": "Read state retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.ReadState"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a device's read marks and get back the merged state. lastReadAt moves the read watermark forward and is ignored when older than the stored one; the notifications listed in read are marked read one by one, skipping unknown IDs.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Sync read state",
+                "parameters": [
+                    {
+                        "description": "Read marks to apply",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/notification.ReadStateUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Read state updated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/notification.ReadState"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid read state",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the count of unread notifications for the authenticated user, e.g. for a badge. The count is kept in Redis and recounted from storage every few minutes, so it is cheap to poll.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get unread notification count",
+                "responses": {
+                    "200": {
+                        "description": "Unread count retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": {
+                                                "type": "integer"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark one of the authenticated user's notifications as read. Devices syncing several read marks at once should use PUT /notifications/read-state.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark notification as read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Notification marked as read",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid notification ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Notification not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/oembed": {
+            "get": {
+                "description": "oEmbed endpoint for watch page URLs, so shared links unfurl with the video's title, channel, thumbnail and an embedded player. The url must match sharing.watchUrl; its query and fragment are ignored. The player is scaled down, keeping its ratio, to fit maxwidth and maxheight. Served at the root of the domain as bare JSON; only served when sharing is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "Describe a shared video link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Watch page URL, e.g. https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum player width in pixels",
+                        "name": "maxwidth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum player height in pixels",
+                        "name": "maxheight",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "Response format; only json is supported",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "oEmbed response",
+                        "schema": {
+                            "$ref": "#/definitions/sharing.OEmbed"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing url",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "URL is not a public video of this site",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "501": {
+                        "description": "Format not supported",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/p2p/info": {
+            "get": {
+                "description": "Returns the peer ID and multiaddrs of the libp2p host videos are announced from, its connected peer count, and the Pavilion nodes heard on the announcement topic. Only served when p2p is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "p2p"
+                ],
+                "summary": "Get peer-to-peer node info",
+                "responses": {
+                    "200": {
+                        "description": "P2P info retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/p2p.Info"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "The IPFS node is unreachable",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/reports": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report a video or comment to moderators. The content is snapshotted when the report is filed, and again whenever it is edited or deleted while the report is open, so moderators can see what was reported.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Report content",
+                "parameters": [
+                    {
+                        "description": "Reported content and reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/moderation.CreateReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Report filed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/moderation.Report"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid report",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Content not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Already reported",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemap.xml": {
+            "get": {
+                "description": "Sitemap of the watch pages of public videos, with video extension entries for videos whose thumbnail can be linked. When there are more videos than sharing.sitemapSize, the sitemap is a sitemap index of pages fetched with page. Served at the root of the domain; only served when sharing is enabled.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "Get the sitemap",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Sitemap page, as linked from the sitemap index",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sitemap, or sitemap index",
+                        "schema": {
+                            "$ref": "#/definitions/sharing.URLSet"
+                        }
+                    },
+                    "404": {
+                        "description": "Page not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/changes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a compact changefeed of the caller's own videos, notifications and follows since the given cursor, oldest first. Notifications appear when created and again when marked read; marking all read appears as a notification_read_state change carrying the read watermark and unread count. Following a user appears as a follow change with the user's ID, and unfollowing as its delete. Omit since on the first sync, then pass back nextCursor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Get changes since a cursor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as nextCursor by the previous sync",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of changes to return (default: 100, max: 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Changes retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/sync.ChangeSet"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/popular": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the tags used by the most videos, most used first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Popular tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of tags to return (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Popular tags retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/video.TagCount"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a new avatar image (JPEG, PNG or WebP, max 5MB) for the authenticated user",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Upload avatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID), must be the authenticated user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar image",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Avatar uploaded",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.Profile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid image",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Not the profile owner",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/block": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Block a user. Their comments are hidden from your comment listings, they cannot reply to your comments, and you are not notified of their follows, comments or reactions. Follows between you and the user are removed. Blocking an already blocked user has no effect.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocks"
+                ],
+                "summary": "Block a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User blocked",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/block.BlockStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or self-block",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Unblock a user. Unblocking a user that is not blocked has no effect. Removed follows are not restored.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocks"
+                ],
+                "summary": "Unblock a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User unblocked",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/block.BlockStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/feed.rss": {
+            "get": {
+                "description": "MRSS feed of a user's newest public videos, with each video's renditions as media:content and its original file as the enclosure. Files are linked on the CDN when its URLs do not expire, or else on the IPFS gateway once replicated; videos neither can link are listed without files. Carries an ETag and Last-Modified, and answers If-None-Match and If-Modified-Since with 304. Only served when feeds are enabled.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Get a channel's feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Feed",
+                        "schema": {
+                            "$ref": "#/definitions/feed.RSS"
+                        }
+                    },
+                    "304": {
+                        "description": "Feed unchanged"
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/follow": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Follow a user to be notified when they upload videos. Following an already followed user has no effect.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Follow a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User followed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/follow.FollowStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID or self-follow",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop following a user. Unfollowing a user that is not followed has no effect.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Unfollow a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User unfollowed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/follow.FollowStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/followers": {
+            "get": {
+                "description": "Get a paginated list of users following the given user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "List followers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Followers retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/follow.UserListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/following": {
+            "get": {
+                "description": "Get a paginated list of users the given user follows",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "List followed users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Followed users retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/follow.UserListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/profile": {
+            "get": {
+                "description": "Get a user's public profile with display name, avatar, bio and follower count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.Profile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update the authenticated user's display name and bio",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID), must be the authenticated user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Profile fields to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user.UpdateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/user.Profile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Not the profile owner",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/videos": {
+            "get": {
+                "description": "Get a paginated list of a user's public videos, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List user videos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Videos retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/video/upload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload a new video file",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Upload video",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Video file to upload (.mp4, .mov)",
+                        "name": "video",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "maxLength": 100,
+                        "minLength": 3,
+                        "type": "string",
+                        "description": "Video title (3-100 characters)",
+                        "name": "title",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "maxLength": 1000,
+                        "type": "string",
+                        "description": "Video description (max 1000 characters)",
+                        "name": "description",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "education",
+                            "entertainment",
+                            "gaming",
+                            "music",
+                            "news",
+                            "science",
+                            "sports",
+                            "technology",
+                            "other"
+                        ],
+                        "type": "string",
+                        "description": "Video category",
+                        "name": "category",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated tags (max 10, each up to 32 letters, digits or hyphens)",
+                        "name": "tags",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries of this upload return the original response instead of uploading again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload completed successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.UploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "An upload with the same Idempotency-Key is still being processed",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Not a decodable video, unsupported codec or too long (ERR_INVALID_MEDIA), rejected by the content scan (ERR_CONTENT_REJECTED), or Idempotency-Key reused for a different request (INVALID_REQUEST)",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many uploads, or the transcode backlog is full; see the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Processing error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Server is shutting down, or temporary storage for uploads is full",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve detailed information about a specific video",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Get video details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video details retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoDetailsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "304": {
+                        "description": "The client's copy is current"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Video requires an entitlement the user does not hold",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "451": {
+                        "description": "Video is not available in the client's country (GEO_BLOCKED)",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Soft delete a video (marks as deleted but preserves the record). Owners can delete their own videos; moderators and admins can delete any video. Files are kept until the retention period passes, then purged with the record; until then the video is in its owner's trash and can be restored.\nWith hard=true the video's files, IPFS pins and records are removed immediately; only the owner and admins may do this. If a step fails the video stays deleted and the request can be retried.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Delete video",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Permanently delete the video now",
+                        "name": "hard",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID format",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner, a moderator or an admin; hard deletes need the owner or an admin",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update a video's title, description, category, tags, comments policy, whether it may be embedded or the countries it is played in. Tags replace the existing set. Country restrictions apply to everyone but the owner and staff, and only when GeoIP is configured: videos with allowed countries are only played where the client's country is known to be one of them, and blocked countries get 451 GEO_BLOCKED. Only the owner or an admin can update a video.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Update video details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/video.VideoUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoDetailsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner or an admin",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/access": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Check whether the authenticated user may play a video, so clients can show a paywall before requesting playback",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entitlements"
+                ],
+                "summary": "Get video access",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access status retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entitlement.AccessStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Require an entitlement to play a video, or make it freely playable again. Only the video owner may change this.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entitlements"
+                ],
+                "summary": "Set video access",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Access settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entitlement.AccessRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access settings updated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entitlement.AccessStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/access-log": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List playback starts of a video, newest first, with coarse viewer information: country, device class and referring site. Viewers are not identified and the owner's own playbacks are not logged. Entries are kept for retention_days. Only the video's owner and admins can read it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Get a video's access log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access log retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/accesslog.ListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video's owner",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/captions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List a video's caption tracks by language, with URLs serving them as WebVTT. URLs from S3 expire, so clients should fetch them again rather than store them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "List captions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Captions retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.CaptionListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Video requires an entitlement the user does not hold",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Captions are not available",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a caption track to a video in one language, replacing the track already in that language. SubRip (.srt) and WebVTT (.vtt) files are accepted and stored as WebVTT. With burn_in, a copy of the video with the captions drawn onto the picture is also stored, for players without text track support; this re-encodes the video and takes about as long as a transcode. Only the owner and admins can upload captions.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Upload captions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Subtitle file (.srt or .vtt, at most 2 MiB)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag, e.g. en or pt-BR",
+                        "name": "language",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "maxLength": 64,
+                        "type": "string",
+                        "description": "Name shown in players' track menus (max 64 characters)",
+                        "name": "label",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Also store a copy of the video with the captions burned in",
+                        "name": "burn_in",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Captions uploaded successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.CaptionTrack"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID, or a malformed or unsupported subtitle file",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner or an admin",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Captions cannot be burned in before the video has finished processing",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many uploads, or the transcode backlog is full; see the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Captions are not available",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/chapters": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Replace a video's chapters, shown by players as a chapter list. The first chapter starts at 0, each starts after the one before, and all start before the end of the video. An empty list removes them; video details then show the chapters listed in the description, if any. Only the owner and admins can set chapters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Set video chapters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Chapters, in order",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/video.ChaptersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Chapters updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.ChaptersResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID, or chapters out of order or past the end of the video",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner or an admin",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The video has not finished processing",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/comment": {
+            "post": {
+                "description": "Creates a new comment for a video. HTML tags are stripped from the content, which may be at most comments.maxLength characters; the links in it are returned as links, and the content rendered from Markdown as content_html.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Create a new comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key making retries of this comment return the original response instead of posting it again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Comment data",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/comment.CreateCommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comment created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.Comment"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID format or invalid comment",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Comments are disabled on the video, or replying to a user who blocked the caller",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "A comment with the same Idempotency-Key is still being processed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency-Key reused for a different request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create comment",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/comments": {
+            "get": {
+                "description": "Retrieves a paginated list of comments for a specific video, with the video's comments_policy. Comments held for review are only listed for their author and the video owner. With a bearer token, comments and replies of users the caller blocked are left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comment"
+                ],
+                "summary": "Get comments for a video",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of comments per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order (options: newest, oldest, most_liked; default: newest)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of newest replies to inline in each comment (default: 0, max: 10)",
+                        "name": "replies",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Comments retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/comment.PaginatedComments"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "304": {
+                        "description": "The client's copy is current"
+                    },
+                    "400": {
+                        "description": "Invalid video ID format",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.Error"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Follow the processing of a video's upload as Server-Sent Events. The stream opens with a status event carrying the same data as GET /video/{id}/status, then sends stage (the upload reached a stage or ended), stored (bytes of the original stored), rendition (a rendition changed status) and progress (percent of a resolution encoded) events as they happen, and the status again every 5 seconds. It ends with an end event carrying the final status once the upload completes, fails or is interrupted; end has no data when the video no longer exists, as when a duplicate upload is discarded. Only the owner or an admin can follow an upload.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Stream upload progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the owner of the video",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/progress": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Store the current playback position in a video and move it to the top of the watch history. Players report every few seconds while playing and when paused. GET /video/{id} returns the position as resume_at.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Report playback progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Playback position",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/history.ProgressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Progress recorded",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/history.Item"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID or position",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/related": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the videos most related to a video by shared tags, the same channel and co-watching, best first. Rankings are cached, so they can be a few minutes stale.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "List related videos",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of videos to return (default: 10, max: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Related videos retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.RelatedVideosResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "304": {
+                        "description": "The client's copy is current"
+                    },
+                    "400": {
+                        "description": "Invalid video ID or limit",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "451": {
+                        "description": "Video is not available in the client's country (GEO_BLOCKED)",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Related videos are not available",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/replace": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upload a new file for an existing video. The video keeps its ID, metadata, comments and views; the file is processed like a new upload and replaces the current renditions. The files being replaced are archived as a version and kept for the version retention period. Only the owner can replace a video.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Replace a video's file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "New video file (.mp4, .mov)",
+                        "name": "video",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video replaced successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.UploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID, request format or validation error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The video's upload is still being processed",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Not a decodable video, unsupported codec or too long (ERR_INVALID_MEDIA), or rejected by the content scan (ERR_CONTENT_REJECTED)",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many uploads, or the transcode backlog is full; see the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Processing error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Server is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Restore a video from the trash before it is purged. Owners can restore videos they deleted themselves; videos removed by a moderator or admin can only be restored by an admin. The video comes back as it was, with its files, comments and views.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Restore a deleted video",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video restored successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoDetailsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID format",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to restore this video",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No deleted video with this ID",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The video is being purged or past the retention period",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "The trash is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/sources": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Every place each rendition of a video can be fetched from, so clients can pick one or fetch parts from several: the API's stream endpoint, the CDN, a temporary storage link and the IPFS copy, with its CID, gateway URL and peers known to hold it. Each rendition carries the SHA-256 fetched files must match. Resolutions dropped by storage tiering are left out until streaming one regenerates them; gated videos follow the same rules as GET /video/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "List video sources",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Sources retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoSourcesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Video requires an entitlement the user does not hold",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "451": {
+                        "description": "Video is not available in the client's country (GEO_BLOCKED)",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve the current upload status of a specific video: the stage of processing it has reached and when it entered each stage, how much of the original file has been stored, the progress and timing of each rendition, and how many files are pinned to IPFS",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Get video upload status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Video status retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoStatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/stream/{resolution}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stream a stored rendition of a video. Range requests are supported, so players can seek without downloading the whole file.",
+                "produces": [
+                    "video/mp4"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "Stream video",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "original",
+                            "720p",
+                            "480p",
+                            "360p"
+                        ],
+                        "type": "string",
+                        "description": "Rendition to stream",
+                        "name": "resolution",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=0-1048575",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Whole rendition",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested byte range",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID or resolution",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Video requires an entitlement the user does not hold",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video or rendition not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "416": {
+                        "description": "Requested range not satisfiable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "451": {
+                        "description": "Video is not available in the client's country (GEO_BLOCKED)",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Streaming is not available, or the rendition was dropped by storage tiering and is being regenerated; see the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/video/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List the files a video has had, newest first: the current one followed by those it replaced. Replaced versions are marked purged once their archived files are deleted. Only the owner, moderators and admins can see a video's versions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "List video versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Video ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Versions retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VersionListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid video ID",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the video owner, a moderator or an admin",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Video not found",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Video has been deleted",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/videos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve a paginated list of videos with detailed information including transcodes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "video"
+                ],
+                "summary": "List videos",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Number of videos to return (default: 10, max: 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only videos with this tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "education",
+                            "entertainment",
+                            "gaming",
+                            "music",
+                            "news",
+                            "science",
+                            "sports",
+                            "technology",
+                            "other"
+                        ],
+                        "type": "string",
+                        "description": "Only videos in this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "newest",
+                            "oldest",
+                            "most_viewed",
+                            "title"
+                        ],
+                        "type": "string",
+                        "description": "Order of the videos (default: newest)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "local",
+                            "remote"
+                        ],
+                        "type": "string",
+                        "description": "Only this instance's videos, or only those of subscribed remote instances; both are listed by default when federation is enabled, for the first 1000 videos",
+                        "name": "origin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a copy the client already has",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Videos retrieved successfully with detailed information",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/video.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/video.VideoListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "304": {
+                        "description": "The client's copy is current"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/video.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the authenticated user's webhooks. Secrets are not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List my webhooks",
+                "responses": {
+                    "200": {
+                        "description": "Webhooks retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/webhook.Webhook"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a URL to receive JSON callbacks for video.processed, video.failed and comment.created events on your videos. Each delivery is a POST carrying X-Pavilion-Event, X-Pavilion-Delivery and an X-Pavilion-Signature header of the form \"sha256=\u003chex HMAC-SHA256 of the body\u003e\" keyed with the secret returned here, which is not shown again. Failed deliveries are retried with exponential backoff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook URL and events",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/webhook.CreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook registered",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/webhook.CreateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid URL or events",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "409": {
+                        "description": "Webhook limit reached",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop sending events to a webhook and remove its delivery log",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook deleted",
+                        "schema": {
+                            "$ref": "#/definitions/http.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a page of a webhook's delivery log, newest first, with the payload sent, the number of attempts and the last response or error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 20, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries retrieved",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/webhook.DeliveryListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/http.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "error": {
+                                            "$ref": "#/definitions/http.APIError"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "accesslog.DeviceClass": {
+            "type": "string",
+            "enum": [
+                "desktop",
+                "mobile",
+                "tablet",
+                "tv",
+                "bot",
+                "other"
+            ],
+            "x-enum-varnames": [
+                "DeviceDesktop",
+                "DeviceMobile",
+                "DeviceTablet",
+                "DeviceTV",
+                "DeviceBot",
+                "DeviceOther"
+            ]
+        },
+        "accesslog.Entry": {
+            "type": "object",
+            "properties": {
+                "country": {
+                    "type": "string",
+                    "example": "DE"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "device_class": {
+                    "enum": [
+                        "desktop",
+                        "mobile",
+                        "tablet",
+                        "tv",
+                        "bot",
+                        "other"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/accesslog.DeviceClass"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "referrer": {
+                    "type": "string",
+                    "example": "news.example.com"
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "accesslog.ListResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/accesslog.Entry"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "retention_days": {
+                    "description": "Entries older than this many days are removed",
+                    "type": "integer",
+                    "example": 90
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "activitypub.Activity": {
+            "type": "object",
+            "properties": {
+                "@context": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "cc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "object"
+                },
+                "published": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Create"
+                }
+            }
+        },
+        "activitypub.Actor": {
+            "type": "object",
+            "properties": {
+                "@context": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "endpoints": {
+                    "$ref": "#/definitions/activitypub.Endpoints"
+                },
+                "followers": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "inbox": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "outbox": {
+                    "type": "string"
+                },
+                "preferredUsername": {
+                    "type": "string",
+                    "example": "johndoe"
+                },
+                "publicKey": {
+                    "$ref": "#/definitions/activitypub.PublicKey"
+                },
+                "published": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string",
+                    "example": "Filmmaker and open source enthusiast"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Person"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "activitypub.Endpoints": {
+            "type": "object",
+            "properties": {
+                "sharedInbox": {
+                    "type": "string"
+                }
+            }
+        },
+        "activitypub.Hashtag": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "#golang"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Hashtag"
+                }
+            }
+        },
+        "activitypub.Link": {
+            "type": "object",
+            "properties": {
+                "href": {
+                    "type": "string"
+                },
+                "mediaType": {
+                    "type": "string",
+                    "example": "text/html"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Link"
+                }
+            }
+        },
+        "activitypub.OrderedCollection": {
+            "type": "object",
+            "properties": {
+                "@context": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "first": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last": {
+                    "type": "string"
+                },
+                "totalItems": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "type": {
+                    "type": "string",
+                    "example": "OrderedCollection"
+                }
+            }
+        },
+        "activitypub.OrderedCollectionPage": {
+            "type": "object",
+            "properties": {
+                "@context": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "orderedItems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/activitypub.Activity"
+                    }
+                },
+                "partOf": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "totalItems": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "type": {
+                    "type": "string",
+                    "example": "OrderedCollectionPage"
+                }
+            }
+        },
+        "activitypub.PublicKey": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "publicKeyPem": {
+                    "type": "string"
+                }
+            }
+        },
+        "activitypub.Video": {
+            "type": "object",
+            "properties": {
+                "@context": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "attributedTo": {
+                    "type": "string"
+                },
+                "cc": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "content": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "Duration is an ISO 8601 duration, e.g. PT754S",
+                    "type": "string",
+                    "example": "PT754S"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Intro to Go"
+                },
+                "published": {
+                    "type": "string"
+                },
+                "tag": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/activitypub.Hashtag"
+                    }
+                },
+                "to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Video"
+                },
+                "updated": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/activitypub.Link"
+                    }
+                }
+            }
+        },
+        "activitypub.WebFinger": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/activitypub.WebFingerLink"
+                    }
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "acct:johndoe@pavilion.example.com"
+                }
+            }
+        },
+        "activitypub.WebFingerLink": {
+            "type": "object",
+            "properties": {
+                "href": {
+                    "type": "string"
+                },
+                "rel": {
+                    "type": "string",
+                    "example": "self"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "application/activity+json"
+                }
+            }
+        },
+        "admin.BackendStorage": {
+            "type": "object",
+            "properties": {
+                "backend": {
+                    "type": "string",
+                    "example": "s3"
+                },
+                "bytes": {
+                    "type": "integer",
+                    "example": 52613349376
+                },
+                "videos": {
+                    "type": "integer",
+                    "example": 980
+                }
+            }
+        },
+        "admin.DailyUploads": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2024-03-01"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "admin.FailedTranscode": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "$ref": "#/definitions/video.TranscodeFailures"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/video.UploadStatus"
+                        }
+                    ],
+                    "example": "failed"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "videoId": {
+                    "type": "string"
+                }
+            }
+        },
+        "admin.FailedTranscodeListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "transcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.FailedTranscode"
+                    }
+                }
+            }
+        },
+        "admin.NotificationBacklog": {
+            "type": "object",
+            "properties": {
+                "backlog": {
+                    "description": "Backlog is the number of messages waiting across all subscriptions",
+                    "type": "integer",
+                    "example": 120
+                },
+                "stalled": {
+                    "description": "Stalled lists subscriptions, as topic/subscription, whose backlog is not shrinking",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "admin.Stats": {
+            "type": "object",
+            "properties": {
+                "generatedAt": {
+                    "type": "string"
+                },
+                "notifications": {
+                    "$ref": "#/definitions/admin.NotificationBacklog"
+                },
+                "storage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.BackendStorage"
+                    }
+                },
+                "uploads": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/admin.DailyUploads"
+                    }
+                },
+                "users": {
+                    "$ref": "#/definitions/admin.UserStats"
+                }
+            }
+        },
+        "admin.UserStats": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer",
+                    "example": 1498
+                },
+                "recentlyActive": {
+                    "description": "RecentlyActive counts users who signed in during the reporting window",
+                    "type": "integer",
+                    "example": 312
+                },
+                "suspended": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1520
+                }
+            }
+        },
+        "apierror.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "title"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "title must be between 3 and 100 characters"
+                },
+                "rule": {
+                    "description": "Rule is the validation rule that failed, e.g. required or max",
+                    "type": "string",
+                    "example": "videotitle"
+                }
+            }
+        },
+        "audit.Event": {
+            "description": "An audited domain event",
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "description": "ActorID is the user who acted",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Details holds event specific values, e.g. the previous and new role",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "description": "TargetID is the video, comment or user acted on",
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "video.deleted"
+                }
+            }
+        },
+        "audit.Page": {
+            "description": "A page of audit events with the cursor for the next page",
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.Event"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is passed as cursor to get the next page; empty on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "auth.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Key creation timestamp",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "When the key stops working; never when empty",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique key ID",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "lastUsedAt": {
+                    "description": "Last time the key authenticated a request",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Human-readable label",
+                    "type": "string",
+                    "example": "Upload bot"
+                },
+                "prefix": {
+                    "description": "First characters of the key, for telling keys apart",
+                    "type": "string",
+                    "example": "pvk_3q2-7wEA"
+                },
+                "revokedAt": {
+                    "description": "When the key was revoked",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Operations the key may perform",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "upload"
+                    ]
+                },
+                "userId": {
+                    "description": "Owner of the key; requests made with the key act as this user",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "auth.CreateAPIKeyRequest": {
+            "description": "API key creation request payload",
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expiresInDays": {
+                    "description": "Days until the key expires; 0 means it never expires",
+                    "type": "integer",
+                    "maximum": 3650,
+                    "minimum": 0,
+                    "example": 90
+                },
+                "name": {
+                    "description": "Name that identifies the key, e.g. the service using it",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "upload-bot"
+                },
+                "scopes": {
+                    "description": "Scopes granted to the key: read, upload or admin",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "upload"
+                    ]
+                }
+            }
+        },
+        "auth.CreateAPIKeyResponse": {
+            "description": "API key creation response payload",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "Key creation timestamp",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "When the key stops working; never when empty",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique key ID",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "key": {
+                    "description": "Plaintext key. It is only returned once and should be stored securely.",
+                    "type": "string",
+                    "example": "pvk_3q2-7wEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+                },
+                "lastUsedAt": {
+                    "description": "Last time the key authenticated a request",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Human-readable label",
+                    "type": "string",
+                    "example": "Upload bot"
+                },
+                "prefix": {
+                    "description": "First characters of the key, for telling keys apart",
+                    "type": "string",
+                    "example": "pvk_3q2-7wEA"
+                },
+                "revokedAt": {
+                    "description": "When the key was revoked",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Operations the key may perform",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "upload"
+                    ]
+                },
+                "userId": {
+                    "description": "Owner of the key; requests made with the key act as this user",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "auth.ExportUser": {
+            "description": "Bulk export user record",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "emailVerified": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "passwordHash": {
+                    "description": "Only included when explicitly requested",
+                    "type": "string"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.Role"
+                        }
+                    ],
+                    "example": "user"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "auth.ForgotPasswordRequest": {
+            "description": "Forgot password request payload",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email address of the account to recover",
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "auth.ImportReport": {
+            "description": "Bulk import report",
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "description": "One result per record, in input order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/auth.ImportResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "auth.ImportResult": {
+            "description": "Bulk import result for one record",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "error": {
+                    "description": "Why the record was skipped or failed",
+                    "type": "string",
+                    "example": "email is invalid"
+                },
+                "row": {
+                    "description": "Record number, starting at 1 (for CSV, the header is not counted)",
+                    "type": "integer",
+                    "example": 1
+                },
+                "status": {
+                    "description": "created, updated, skipped or failed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.ImportStatus"
+                        }
+                    ],
+                    "example": "created"
+                },
+                "userId": {
+                    "description": "ID of the created or updated user",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                },
+                "welcomeSent": {
+                    "description": "Whether a welcome email was sent",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "auth.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "skipped",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportCreated",
+                "ImportUpdated",
+                "ImportSkipped",
+                "ImportFailed"
+            ]
+        },
+        "auth.ImportUser": {
+            "description": "Bulk import user record",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Unique email address",
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "emailVerified": {
+                    "description": "Whether the email address was already verified on the other platform",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "description": "User's full name",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "password": {
+                    "description": "Temporary password, used when no hash is given. Without either the user\nhas to choose a password through the welcome email or a password reset.",
+                    "type": "string",
+                    "example": "TempPass123!"
+                },
+                "passwordHash": {
+                    "description": "bcrypt hash carried over from another platform, so users keep their password",
+                    "type": "string",
+                    "example": "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+                },
+                "role": {
+                    "description": "Role to create the account with; defaults to user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.Role"
+                        }
+                    ],
+                    "example": "user"
+                },
+                "username": {
+                    "description": "Unique username",
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "auth.LoginRequest": {
+            "description": "Login request payload",
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "description": "User email address",
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "password": {
+                    "description": "User password",
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "Pass123!"
+                }
+            }
+        },
+        "auth.LoginResponse": {
+            "description": "Login response payload",
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "description": "JWT access token",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                },
+                "expiresIn": {
+                    "description": "Token expiration time in seconds",
+                    "type": "integer",
+                    "example": 3600
+                },
+                "refreshToken": {
+                    "description": "JWT refresh token",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                },
+                "tokenType": {
+                    "description": "Token type (always \"Bearer\")",
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "user": {
+                    "description": "User information",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.User"
+                        }
+                    ]
+                }
+            }
+        },
+        "auth.RefreshTokenRequest": {
+            "description": "Refresh token request payload",
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "description": "Valid refresh token",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                }
+            }
+        },
+        "auth.RegisterRequest": {
+            "description": "Registration request payload",
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "description": "User email address",
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "name": {
+                    "description": "User's full name",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "password": {
+                    "description": "User password (min 8 characters)",
+                    "type": "string",
+                    "minLength": 6,
+                    "example": "Pass123!"
+                },
+                "username": {
+                    "description": "Unique username",
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "auth.ResendVerificationRequest": {
+            "description": "Resend verification email request payload",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email address of the unverified account",
+                    "type": "string",
+                    "example": "user@example.com"
+                }
+            }
+        },
+        "auth.ResetPasswordRequest": {
+            "description": "Reset password request payload",
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
+                "password": {
+                    "description": "New password",
+                    "type": "string",
+                    "minLength": 8,
+                    "example": "NewPass123!"
+                },
+                "token": {
+                    "description": "Token from the password reset email",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                }
+            }
+        },
+        "auth.RevokeSessionsResponse": {
+            "description": "Revoke other sessions response payload",
+            "type": "object",
+            "properties": {
+                "revoked": {
+                    "description": "Number of sessions revoked",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "auth.Role": {
+            "type": "string",
+            "enum": [
+                "user",
+                "moderator",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleUser",
+                "RoleModerator",
+                "RoleAdmin"
+            ]
+        },
+        "auth.SecurityEvent": {
+            "description": "Account activity event",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "When it happened",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique event ID",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "ipAddress": {
+                    "description": "Client the request came from",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "method": {
+                    "description": "How the user signed in: password, or the OAuth provider",
+                    "type": "string",
+                    "example": "password"
+                },
+                "outcome": {
+                    "description": "Whether the attempt succeeded",
+                    "enum": [
+                        "success",
+                        "failure"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.SecurityOutcome"
+                        }
+                    ],
+                    "example": "success"
+                },
+                "reason": {
+                    "description": "Why a failed attempt was rejected",
+                    "type": "string",
+                    "example": "invalid_password"
+                },
+                "type": {
+                    "description": "What happened: login, token_refresh, logout or password_reset",
+                    "enum": [
+                        "login",
+                        "token_refresh",
+                        "logout",
+                        "password_reset"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.SecurityEventType"
+                        }
+                    ],
+                    "example": "login"
+                },
+                "userAgent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"
+                }
+            }
+        },
+        "auth.SecurityEventType": {
+            "type": "string",
+            "enum": [
+                "login",
+                "token_refresh",
+                "logout",
+                "password_reset"
+            ],
+            "x-enum-varnames": [
+                "SecurityLogin",
+                "SecurityTokenRefresh",
+                "SecurityLogout",
+                "SecurityPasswordReset"
+            ]
+        },
+        "auth.SecurityOutcome": {
+            "type": "string",
+            "enum": [
+                "success",
+                "failure"
+            ],
+            "x-enum-varnames": [
+                "OutcomeSuccess",
+                "OutcomeFailure"
+            ]
+        },
+        "auth.Session": {
+            "description": "Active session",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "When the session was started",
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Whether this is the session making the request",
+                    "type": "boolean",
+                    "example": true
+                },
+                "expiresAt": {
+                    "description": "When the session expires unless revoked first",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Session ID",
+                    "type": "string",
+                    "example": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+                },
+                "ipAddress": {
+                    "description": "IP address the session was started from",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "lastUsedAt": {
+                    "description": "When the session last refreshed its access token",
+                    "type": "string"
+                },
+                "userAgent": {
+                    "description": "User agent of the client that started the session",
+                    "type": "string",
+                    "example": "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"
+                }
+            }
+        },
+        "auth.SuspendUserRequest": {
+            "description": "Account suspension request payload",
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "description": "Why the account is suspended, shown to admins and the user",
+                    "type": "string",
+                    "example": "Repeated copyright violations"
+                }
+            }
+        },
+        "auth.UpdateRoleRequest": {
+            "description": "Role change request payload",
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "description": "New role: user, moderator or admin",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.Role"
+                        }
+                    ],
+                    "example": "moderator"
+                }
+            }
+        },
+        "auth.User": {
+            "description": "User model",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Whether account is active; suspended accounts cannot sign in",
+                    "type": "boolean",
+                    "example": true
+                },
+                "avatarPath": {
+                    "description": "Storage key of the user's avatar image",
+                    "type": "string",
+                    "example": "avatars/550e8400-e29b-41d4-a716-446655440000/avatar.png"
+                },
+                "bio": {
+                    "description": "Short public biography",
+                    "type": "string",
+                    "example": "Filmmaker and open source enthusiast"
+                },
+                "createdAt": {
+                    "description": "Account creation timestamp",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "User email address",
+                    "type": "string",
+                    "example": "user@example.com"
+                },
+                "emailVerified": {
+                    "description": "Whether email is verified",
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "description": "Unique user ID",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "lastLoginAt": {
+                    "description": "Last login timestamp",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "User's full name",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "role": {
+                    "description": "Permission level: user, moderator or admin",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/auth.Role"
+                        }
+                    ],
+                    "example": "user"
+                },
+                "suspendedAt": {
+                    "description": "When and why an admin suspended the account",
+                    "type": "string"
+                },
+                "suspensionReason": {
+                    "type": "string",
+                    "example": "Repeated copyright violations"
+                },
+                "updatedAt": {
+                    "description": "Last update timestamp",
+                    "type": "string"
+                },
+                "username": {
+                    "description": "Unique username",
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "block.BlockStatus": {
+            "description": "Block state between the caller and a user",
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "description": "Whether the caller blocks the user",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "comment.BannedWordsRequest": {
+            "type": "object",
+            "properties": {
+                "words": {
+                    "description": "Words are words or phrases, matched whole and ignoring case. An empty\nlist bans nothing beyond the site-wide list.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spoiler"
+                    ]
+                }
+            }
+        },
+        "comment.BannedWordsResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action is what happens to comments containing them",
+                    "enum": [
+                        "reject",
+                        "mask",
+                        "hold"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/comment.FilterAction"
+                        }
+                    ],
+                    "example": "hold"
+                },
+                "words": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "spoiler"
+                    ]
+                }
+            }
+        },
+        "comment.Comment": {
+            "description": "A comment on a video with metadata and reaction counts",
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "This is a **great** video!"
+                },
+                "content_html": {
+                    "description": "ContentHTML is Content rendered from Markdown to HTML that is safe to\ninsert into a page, when comments.markdown is enabled",
+                    "type": "string",
+                    "example": "\u003cp\u003eThis is a \u003cstrong\u003egreat\u003c/strong\u003e video!\u003c/p\u003e"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "deleted_at": {
+                    "type": "string",
+                    "example": "2023-01-02T12:00:00Z"
+                },
+                "dislikes": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
+                },
+                "likes": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "links": {
+                    "description": "Links are the URLs found in Content, for clients to show previews of",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/comment.Link"
+                    }
+                },
+                "parent_id": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "123e4567-e89b-12d3-a456-426614174003"
+                },
+                "replies": {
+                    "description": "Replies are the newest replies, inlined when listing comments with replies=N",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/comment.Comment"
+                    }
+                },
+                "reply_count": {
+                    "description": "ReplyCount is the number of replies to a top-level comment, kept up to\ndate as replies are created and deleted",
+                    "type": "integer",
+                    "example": 12
+                },
+                "status": {
+                    "enum": [
+                        "ACTIVE",
+                        "FLAGGED",
+                        "HIDDEN",
+                        "PENDING"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/comment.Status"
+                        }
+                    ],
+                    "example": "ACTIVE"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "123e4567-e89b-12d3-a456-426614174002"
+                },
+                "video_id": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "123e4567-e89b-12d3-a456-426614174001"
+                }
+            }
+        },
+        "comment.CreateCommentRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "This is a comment"
+                },
+                "parent_id": {
+                    "type": "string",
+                    "format": "uuid",
+                    "example": ""
+                }
+            }
+        },
+        "comment.FilterAction": {
+            "type": "string",
+            "enum": [
+                "reject",
+                "mask",
+                "hold"
+            ],
+            "x-enum-varnames": [
+                "FilterReject",
+                "FilterMask",
+                "FilterHold"
+            ]
+        },
+        "comment.Link": {
+            "description": "A link found in a comment",
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/article"
+                }
+            }
+        },
+        "comment.PaginatedComments": {
+            "description": "A paginated list of comments with metadata about the pagination",
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/comment.Comment"
+                    }
+                },
+                "comments_policy": {
+                    "description": "CommentsPolicy is whether the video takes comments (enabled, disabled\nor review_required), so clients can show the comment box accordingly",
+                    "type": "string",
+                    "enum": [
+                        "enabled",
+                        "disabled",
+                        "review_required"
+                    ],
+                    "example": "enabled"
+                },
+                "current_page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "has_next_page": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "has_prev_page": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "total_count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "total_pages": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "comment.ReactionRequest": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "type": {
+                    "enum": [
+                        "LIKE",
+                        "DISLIKE"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/comment.Type"
+                        }
+                    ],
+                    "example": "LIKE"
+                }
+            }
+        },
+        "comment.Status": {
+            "description": "Status of a comment (ACTIVE, FLAGGED, HIDDEN or PENDING)",
+            "type": "string",
+            "enum": [
+                "ACTIVE",
+                "FLAGGED",
+                "HIDDEN",
+                "PENDING"
+            ],
+            "x-enum-varnames": [
+                "StatusActive",
+                "StatusFlagged",
+                "StatusHidden",
+                "StatusPending"
+            ]
+        },
+        "comment.Type": {
+            "description": "Type of reaction (LIKE or DISLIKE)",
+            "type": "string",
+            "enum": [
+                "LIKE",
+                "DISLIKE"
+            ],
+            "x-enum-varnames": [
+                "TypeLike",
+                "TypeDislike"
+            ]
+        },
+        "comment.UpdateCommentRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "example": "Updated comment content"
+                }
+            }
+        },
+        "embed.DomainsRequest": {
+            "type": "object",
+            "properties": {
+                "domains": {
+                    "description": "Domains are hostnames, e.g. blog.example.com; subdomains are allowed\ntoo. An empty list allows embedding anywhere.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "example.com"
+                    ]
+                }
+            }
+        },
+        "embed.DomainsResponse": {
+            "type": "object",
+            "properties": {
+                "domains": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "example.com"
+                    ]
+                }
+            }
+        },
+        "embed.PlayerConfig": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "Duration is the length of the video in seconds, when known",
+                    "type": "number",
+                    "example": 754.2
+                },
+                "poster": {
+                    "description": "Poster is the video's animated preview, when it can be linked",
+                    "type": "string",
+                    "example": "https://cdn.example.com/videos/5f0c.../preview.webp"
+                },
+                "sources": {
+                    "description": "Sources are the video's renditions, highest resolution first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/embed.Source"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Intro to Go"
+                },
+                "video_id": {
+                    "type": "string",
+                    "example": "5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"
+                }
+            }
+        },
+        "embed.Source": {
+            "type": "object",
+            "properties": {
+                "rendition": {
+                    "type": "string",
+                    "example": "720p"
+                },
+                "type": {
+                    "description": "Type is the file's MIME type, when known from its extension",
+                    "type": "string",
+                    "example": "video/mp4"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://cdn.example.com/videos/5f0c.../720p.mp4"
+                }
+            }
+        },
+        "entitlement.AccessRequest": {
+            "type": "object",
+            "required": [
+                "requires_entitlement"
+            ],
+            "properties": {
+                "requires_entitlement": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "entitlement.AccessStatus": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "has_access": {
+                    "type": "boolean"
+                },
+                "requires_entitlement": {
+                    "type": "boolean"
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entitlement.Entitlement": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "entitlement.EntitlementListResponse": {
+            "type": "object",
+            "properties": {
+                "entitlements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entitlement.Entitlement"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entitlement.WebhookEvent": {
+            "type": "object",
+            "properties": {
+                "duration_seconds": {
+                    "description": "DurationSeconds ends the grant this long after it is issued, e.g. a 48 hour rental",
+                    "type": "integer"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt ends the grant at a fixed time; mutually exclusive with DurationSeconds",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "ID identifies the event in the sender; an event is applied only once",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "Reference is the payment or order ID in the source system. Grants with the\nsame source and reference are updated rather than duplicated, so webhook\nretries are safe.",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source names the system issuing the grant, e.g. \"stripe\"",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type is either \"entitlement.granted\" or \"entitlement.revoked\"",
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "federation.Document": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Pavilion"
+                },
+                "public_key": {
+                    "description": "PublicKey is the base64 Ed25519 key outbox pages are signed with",
+                    "type": "string",
+                    "example": "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="
+                },
+                "software": {
+                    "type": "string",
+                    "example": "pavilion"
+                }
+            }
+        },
+        "federation.Instance": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "outbox signature does not match the instance's key"
+                },
+                "last_polled_at": {
+                    "description": "LastPolledAt and LastError describe the most recent pull",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Peer Pavilion"
+                },
+                "public_key": {
+                    "description": "PublicKey is the base64 Ed25519 key the instance signs its outbox with,\npinned when the instance is registered",
+                    "type": "string",
+                    "example": "11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the base URL of the remote API, under which its federation endpoints are served",
+                    "type": "string",
+                    "example": "https://peer.example.com/api/v1"
+                }
+            }
+        },
+        "federation.Outbox": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "description": "NextCursor continues after the last video; pass it as since",
+                    "type": "string"
+                },
+                "since": {
+                    "description": "Since is the cursor the page was requested with, so a page cannot be\nreplayed for another request",
+                    "type": "string"
+                },
+                "videos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/federation.OutboxVideo"
+                    }
+                }
+            }
+        },
+        "federation.OutboxVideo": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "education"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "number",
+                    "example": 754.2
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f6c2a4e-8b1d-4c6e-9a7f-2d5b8e1c0a94"
+                },
+                "ipfs_cid": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tutorial",
+                        "golang"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 1280
+                }
+            }
+        },
+        "federation.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "url": {
+                    "description": "URL is the base URL of the remote API, e.g. https://peer.example.com/api/v1",
+                    "type": "string",
+                    "example": "https://peer.example.com/api/v1"
+                }
+            }
+        },
+        "feed.AtomLink": {
+            "type": "object",
+            "properties": {
+                "href": {
+                    "type": "string"
+                },
+                "rel": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.Channel": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "generator": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/feed.Item"
+                    }
+                },
+                "lastBuildDate": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "self": {
+                    "description": "Self is the feed's own URL, which aggregators use to identify it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/feed.AtomLink"
+                        }
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.Enclosure": {
+            "type": "object",
+            "properties": {
+                "length": {
+                    "description": "Length is the file's size in bytes; 0 when unknown",
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.GUID": {
+            "type": "object",
+            "properties": {
+                "isPermaLink": {
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.Item": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "credit": {
+                    "description": "Credit names the channel the video is from",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/feed.MediaCredit"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enclosure": {
+                    "description": "Enclosure is the original file, for podcast apps that download one file",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/feed.Enclosure"
+                        }
+                    ]
+                },
+                "group": {
+                    "$ref": "#/definitions/feed.MediaGroup"
+                },
+                "guid": {
+                    "$ref": "#/definitions/feed.GUID"
+                },
+                "keywords": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "pubDate": {
+                    "type": "string"
+                },
+                "thumbnail": {
+                    "$ref": "#/definitions/feed.MediaThumbnail"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.MediaContent": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "description": "Duration is in whole seconds",
+                    "type": "integer"
+                },
+                "fileSize": {
+                    "type": "integer"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "isDefault": {
+                    "type": "boolean"
+                },
+                "medium": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.MediaCredit": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.MediaGroup": {
+            "type": "object",
+            "properties": {
+                "contents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/feed.MediaContent"
+                    }
+                }
+            }
+        },
+        "feed.MediaThumbnail": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "feed.RSS": {
+            "type": "object",
+            "properties": {
+                "atom": {
+                    "type": "string"
+                },
+                "channel": {
+                    "$ref": "#/definitions/feed.Channel"
+                },
+                "media": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "follow.FollowStatus": {
+            "description": "Follow state between the caller and a user",
+            "type": "object",
+            "properties": {
+                "followerCount": {
+                    "description": "Number of followers the user has",
+                    "type": "integer",
+                    "example": 42
+                },
+                "following": {
+                    "description": "Whether the caller follows the user",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "follow.UserListResponse": {
+            "description": "Paginated list of followers or followed users",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/follow.UserSummary"
+                    }
+                }
+            }
+        },
+        "follow.UserSummary": {
+            "description": "Public user information shown in follower and following lists",
+            "type": "object",
+            "properties": {
+                "followedAt": {
+                    "description": "When the follow relationship started",
+                    "type": "string",
+                    "example": "2025-03-05T21:26:06Z"
+                },
+                "id": {
+                    "description": "User ID",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "description": "Display name",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "username": {
+                    "description": "Username",
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "graphql.Request": {
+            "type": "object",
+            "required": [
+                "query"
+            ],
+            "properties": {
+                "operationName": {
+                    "description": "Operation to run when the document holds several",
+                    "type": "string",
+                    "example": ""
+                },
+                "query": {
+                    "description": "The GraphQL document",
+                    "type": "string",
+                    "example": "{ videos(limit: 5) { total videos { title commentCount owner { username } } } }"
+                },
+                "variables": {
+                    "description": "Values of the document's variables",
+                    "type": "object"
+                }
+            }
+        },
+        "graphql.Response": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Result of the query; null when it could not run",
+                    "type": "object"
+                },
+                "errors": {
+                    "description": "Problems with the request or its fields",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                }
+            }
+        },
+        "health.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.8
+                },
+                "name": {
+                    "type": "string",
+                    "example": "cockroachdb"
+                },
+                "status": {
+                    "enum": [
+                        "up",
+                        "down"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/health.Status"
+                        }
+                    ],
+                    "example": "up"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "enum": [
+                        "up",
+                        "degraded",
+                        "down"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/health.Status"
+                        }
+                    ],
+                    "example": "up"
+                }
+            }
+        },
+        "health.Status": {
+            "type": "string",
+            "enum": [
+                "up",
+                "degraded",
+                "down"
+            ],
+            "x-enum-varnames": [
+                "StatusUp",
+                "StatusDegraded",
+                "StatusDown"
+            ]
+        },
+        "history.Item": {
+            "type": "object",
+            "properties": {
+                "completion": {
+                    "description": "Completion is how much of the video has been watched, in percent",
+                    "type": "number",
+                    "example": 41.9
+                },
+                "duration": {
+                    "type": "number",
+                    "example": 1800
+                },
+                "position": {
+                    "description": "Position is the last playback position in seconds",
+                    "type": "number",
+                    "example": 754.2
+                },
+                "title": {
+                    "type": "string"
+                },
+                "video_id": {
+                    "type": "string"
+                },
+                "watched_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "history.ListResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/history.Item"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "description": "NextCursor is passed as before to get the next page; empty on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "history.ProgressRequest": {
+            "type": "object",
+            "required": [
+                "duration",
+                "position"
+            ],
+            "properties": {
+                "duration": {
+                    "description": "Duration is the video's length in seconds",
+                    "type": "number",
+                    "example": 1800
+                },
+                "position": {
+                    "description": "Position is the playback position in seconds",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 754.2
+                }
+            }
+        },
+        "http.APIError": {
+            "description": "Error response structure",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Error code identifying the type of error",
+                    "type": "string",
+                    "example": "VALIDATION_ERROR"
+                },
+                "field": {
+                    "description": "Optional field name for validation errors",
+                    "type": "string",
+                    "example": "email"
+                },
+                "fields": {
+                    "description": "Every field that failed validation",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.ValidationError"
+                    }
+                },
+                "message": {
+                    "description": "Human-readable error message",
+                    "type": "string",
+                    "example": "Invalid input parameters"
+                }
+            }
+        },
+        "http.APIResponse": {
+            "description": "Standard API response format",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "Optional data returned by the API"
+                },
+                "error": {
+                    "description": "Error information, if any",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.APIError"
+                        }
+                    ]
+                },
+                "message": {
+                    "description": "Optional message describing the response",
+                    "type": "string",
+                    "example": "Operation completed successfully"
+                },
+                "success": {
+                    "description": "Indicates if the request was successful",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "http.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields lists every field that failed validation",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apierror.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "http.Response": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "error": {
+                    "$ref": "#/definitions/http.Error"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "http.ValidationError": {
+            "description": "Validation error structure",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field that failed validation",
+                    "type": "string",
+                    "example": "email"
+                },
+                "message": {
+                    "description": "Validation error message",
+                    "type": "string",
+                    "example": "Email is required"
+                },
+                "rule": {
+                    "description": "Validation rule that failed",
+                    "type": "string",
+                    "example": "required"
+                }
+            }
+        },
+        "jobs.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "payload": {
+                    "description": "Payload is the job's JSON input, handed to its handler as is",
+                    "type": "string",
+                    "example": "{}"
+                },
+                "run_at": {
+                    "description": "RunAt is when the job's next attempt is due",
+                    "type": "string"
+                },
+                "schedule": {
+                    "description": "Schedule names the recurring schedule that queued the job, if any",
+                    "type": "string",
+                    "example": "jobs.prune"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/jobs.Status"
+                        }
+                    ],
+                    "example": "succeeded"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "jobs.prune"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.ListResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/jobs.Job"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "jobs.Schedule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "last_job_id": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "jobs.prune"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string",
+                    "example": "{}"
+                },
+                "spec": {
+                    "description": "Spec is a five-field cron expression in UTC, or a descriptor such as @daily",
+                    "type": "string",
+                    "example": "@daily"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "jobs.prune"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.Status": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusRunning",
+                "StatusSucceeded",
+                "StatusFailed",
+                "StatusCancelled"
+            ]
+        },
+        "live.CreateKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "minLength": 1,
+                    "example": "OBS at home"
+                }
+            }
+        },
+        "live.KeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c"
+                },
+                "ingest_url": {
+                    "type": "string",
+                    "example": "rtmp://live.example.com/live"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "pvs_3q2-7wEAhX0XkYc2Q3lq1bqgVtCk0o8T9l4GLJ1Zc8A"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "masked_key": {
+                    "description": "MaskedKey is the start and end of the key",
+                    "type": "string",
+                    "example": "pvs_3q2-7wEA...Zc8A"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "OBS at home"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "live.SessionListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/live.SessionResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "live.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "bitrate_kbps": {
+                    "description": "BitrateKbps is the average bitrate the streamer sent; set once the stream ended",
+                    "type": "integer",
+                    "example": 6000
+                },
+                "bytes_received": {
+                    "type": "integer",
+                    "example": 5970000000
+                },
+                "duration_seconds": {
+                    "description": "DurationSeconds is how long the stream has been live, or was",
+                    "type": "number",
+                    "example": 7960
+                },
+                "ended_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "key_id": {
+                    "description": "KeyID is the stream key the stream was published with",
+                    "type": "string",
+                    "example": "3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c"
+                },
+                "key_name": {
+                    "type": "string",
+                    "example": "OBS at home"
+                },
+                "playback_url": {
+                    "description": "PlaybackURL is the HLS playlist, served while the stream is live and\nshortly after",
+                    "type": "string",
+                    "example": "https://pavilion.example.com/api/v1/live/7c9e6679-7425-40de-944b-e07fc1f90ae7/hls/index.m3u8"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "live",
+                        "ended"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/live.Status"
+                        }
+                    ],
+                    "example": "live"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Friday night coding"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "video_id": {
+                    "description": "VideoID is the video the stream's recording was published as, once processed",
+                    "type": "string",
+                    "example": "5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"
+                }
+            }
+        },
+        "live.Settings": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "description": "Current is the user's latest stream while they are live",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/live.StreamResponse"
+                        }
+                    ]
+                },
+                "ingest_url": {
+                    "description": "IngestURL is the RTMP server streaming software publishes to",
+                    "type": "string",
+                    "example": "rtmp://live.example.com/live"
+                },
+                "title": {
+                    "description": "Title is given to the user's streams and their recordings",
+                    "type": "string",
+                    "example": "Friday night coding"
+                }
+            }
+        },
+        "live.SettingsRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "title": {
+                    "description": "Title is given to the user's next streams and their recordings",
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 3,
+                    "example": "Friday night coding"
+                }
+            }
+        },
+        "live.Status": {
+            "type": "string",
+            "enum": [
+                "live",
+                "ended"
+            ],
+            "x-enum-varnames": [
+                "StatusLive",
+                "StatusEnded"
+            ]
+        },
+        "live.StreamKeyListResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/live.StreamKeyResponse"
+                    }
+                }
+            }
+        },
+        "live.StreamKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "masked_key": {
+                    "description": "MaskedKey is the start and end of the key",
+                    "type": "string",
+                    "example": "pvs_3q2-7wEA...Zc8A"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "OBS at home"
+                },
+                "revoked_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "live.StreamListResponse": {
+            "type": "object",
+            "properties": {
+                "streams": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/live.StreamResponse"
+                    }
+                }
+            }
+        },
+        "live.StreamResponse": {
+            "type": "object",
+            "properties": {
+                "ended_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "playback_url": {
+                    "description": "PlaybackURL is the HLS playlist, served while the stream is live and\nshortly after",
+                    "type": "string",
+                    "example": "https://pavilion.example.com/api/v1/live/7c9e6679-7425-40de-944b-e07fc1f90ae7/hls/index.m3u8"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "live",
+                        "ended"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/live.Status"
+                        }
+                    ],
+                    "example": "live"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Friday night coding"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "video_id": {
+                    "description": "VideoID is the video the stream's recording was published as, once processed",
+                    "type": "string",
+                    "example": "5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"
+                }
+            }
+        },
+        "moderation.CreateReportRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "target_id",
+                "target_type"
+            ],
+            "properties": {
+                "details": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "harassment"
+                },
+                "target_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "target_type": {
+                    "type": "string",
+                    "enum": [
+                        "video",
+                        "comment"
+                    ]
+                }
+            }
+        },
+        "moderation.EvidenceSnapshot": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "body": {
+                    "description": "Body is the video description or the comment text",
+                    "type": "string"
+                },
+                "captured_at": {
+                    "type": "string"
+                },
+                "checksum": {
+                    "type": "string"
+                },
+                "content_updated_at": {
+                    "description": "ContentUpdatedAt is when the content was last changed before the snapshot",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ipfs_cid": {
+                    "type": "string"
+                },
+                "report_id": {
+                    "description": "ReportID is set on the snapshot taken when that report was filed",
+                    "type": "string"
+                },
+                "storage_path": {
+                    "description": "File references of a video, so the original upload can be retrieved",
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string",
+                    "enum": [
+                        "video",
+                        "comment"
+                    ]
+                },
+                "title": {
+                    "description": "Title is the video title; empty for comments",
+                    "type": "string"
+                },
+                "trigger": {
+                    "type": "string",
+                    "enum": [
+                        "report",
+                        "edit",
+                        "delete"
+                    ]
+                },
+                "version": {
+                    "description": "Version numbers the snapshots of a target from 1",
+                    "type": "integer"
+                },
+                "video_id": {
+                    "description": "VideoID is the video a comment was posted on",
+                    "type": "string"
+                }
+            }
+        },
+        "moderation.Report": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reporter_id": {
+                    "type": "string"
+                },
+                "resolution": {
+                    "description": "Resolution is the moderator's note on how the report was handled",
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "open",
+                        "resolved",
+                        "dismissed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moderation.ReportStatus"
+                        }
+                    ]
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string",
+                    "enum": [
+                        "video",
+                        "comment"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "moderation.ReportDetail": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "evidence": {
+                    "description": "Evidence lists every snapshot of the reported content, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moderation.EvidenceSnapshot"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reporter_id": {
+                    "type": "string"
+                },
+                "resolution": {
+                    "description": "Resolution is the moderator's note on how the report was handled",
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "resolved_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "open",
+                        "resolved",
+                        "dismissed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moderation.ReportStatus"
+                        }
+                    ]
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string",
+                    "enum": [
+                        "video",
+                        "comment"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "moderation.ReportListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moderation.Report"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "moderation.ReportStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "resolved",
+                "dismissed"
+            ],
+            "x-enum-varnames": [
+                "StatusOpen",
+                "StatusResolved",
+                "StatusDismissed"
+            ]
+        },
+        "moderation.ResolveReportRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "resolution": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "status": {
+                    "enum": [
+                        "resolved",
+                        "dismissed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/moderation.ReportStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "notification.DigestFrequency": {
+            "type": "string",
+            "enum": [
+                "off",
+                "daily",
+                "weekly"
+            ],
+            "x-enum-varnames": [
+                "DigestOff",
+                "DigestDaily",
+                "DigestWeekly"
+            ]
+        },
+        "notification.DigestPreferences": {
+            "description": "How often unread notifications are emailed as a digest",
+            "type": "object",
+            "required": [
+                "frequency"
+            ],
+            "properties": {
+                "frequency": {
+                    "description": "off, daily or weekly",
+                    "enum": [
+                        "off",
+                        "daily",
+                        "weekly"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/notification.DigestFrequency"
+                        }
+                    ],
+                    "example": "daily"
+                },
+                "nextDigestAt": {
+                    "description": "When the next digest is due; unset while digests are off",
+                    "type": "string"
+                }
+            }
+        },
+        "notification.EventType": {
+            "description": "Type of notification event (e.g. VIDEO_UPLOADED, COMMENT_CREATED)",
+            "type": "string",
+            "enum": [
+                "VIDEO_UPLOADED",
+                "VIDEO_PROCESSED",
+                "VIDEO_UPDATED",
+                "VIDEO_DELETED",
+                "FOLLOWED_USER_UPLOADED",
+                "LIVE_STARTED",
+                "COMMENT_CREATED",
+                "COMMENT_REPLIED",
+                "COMMENT_REACTION",
+                "COMMENT_DELETED",
+                "USER_FOLLOWED",
+                "USER_UNFOLLOWED",
+                "USER_MENTIONED",
+                "AUTH_EVENT"
+            ],
+            "x-enum-varnames": [
+                "VideoUploaded",
+                "VideoProcessed",
+                "VideoUpdated",
+                "VideoDeleted",
+                "FollowedUserUploaded",
+                "LiveStarted",
+                "CommentCreated",
+                "CommentReplied",
+                "CommentReaction",
+                "CommentDeleted",
+                "UserFollowed",
+                "UserUnfollowed",
+                "UserMentioned",
+                "AuthEvent"
+            ]
+        },
+        "notification.MetricsReport": {
+            "type": "object",
+            "properties": {
+                "generatedAt": {
+                    "type": "string"
+                },
+                "stalled": {
+                    "description": "Stalled lists \"topic/subscription\" pairs whose backlog is not shrinking",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notification.TopicStats"
+                    }
+                }
+            }
+        },
+        "notification.Notification": {
+            "description": "A notification entity with metadata and status information",
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "Human-readable notification content",
+                    "type": "string",
+                    "example": "Your video 'My awesome video' has been uploaded successfully"
+                },
+                "createdAt": {
+                    "description": "When the notification was created",
+                    "type": "string",
+                    "example": "2025-03-05T21:26:06Z"
+                },
+                "id": {
+                    "description": "Unique identifier for the notification",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "metadata": {
+                    "description": "Additional metadata about the notification. Prefer payload, which has\na fixed shape per type; metadata is kept for existing clients.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "payload": {
+                    "description": "Typed details of the notification, shaped by its type, e.g. a\nCommentReplyPayload for COMMENT_REPLIED",
+                    "type": "object"
+                },
+                "readAt": {
+                    "description": "When the notification was marked as read (null if unread)",
+                    "type": "string"
+                },
+                "schemaVersion": {
+                    "description": "Version of the payload's shape, see PayloadSchemaVersion",
+                    "type": "integer",
+                    "example": 1
+                },
+                "type": {
+                    "description": "Type of notification (VIDEO_UPLOADED, COMMENT_CREATED, etc.)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/notification.EventType"
+                        }
+                    ],
+                    "example": "VIDEO_UPLOADED"
+                },
+                "userId": {
+                    "description": "User ID who should receive this notification",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                }
+            }
+        },
+        "notification.NotificationPage": {
+            "description": "A page of notifications with the cursor for the next page and the total unread count",
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "description": "Whether more notifications are available after this page",
+                    "type": "boolean",
+                    "example": true
+                },
+                "nextCursor": {
+                    "description": "Opaque cursor for the next page, empty when there are no more results",
+                    "type": "string",
+                    "example": "MTc0MTIwOTk2NjAwMDAwMDAwMHw1NTBlODQwMC1lMjliLTQxZDQtYTcxNi00NDY2NTU0NDAwMDA"
+                },
+                "notifications": {
+                    "description": "Notifications in this page, newest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notification.Notification"
+                    }
+                },
+                "totalUnread": {
+                    "description": "Total number of unread notifications for the user",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "notification.PushPreferences": {
+            "description": "Which notifications are sent as push messages",
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Whether any push messages are sent",
+                    "type": "boolean"
+                },
+                "mutedTypes": {
+                    "description": "Notification types not sent as push messages",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notification.EventType"
+                    }
+                }
+            }
+        },
+        "notification.PushPublicKeyResponse": {
+            "type": "object",
+            "properties": {
+                "publicKey": {
+                    "description": "VAPID public key, base64url encoded; pass it as applicationServerKey to pushManager.subscribe",
+                    "type": "string",
+                    "example": "BEl62iUYgUivxIkv69yViEuiBIa-Ib9-SkvMeAtA3LFgDzkrxZJjSgSnfckjBJuBkr3qBUYIHBQFLXYp5Nksh8U"
+                }
+            }
+        },
+        "notification.PushSubscription": {
+            "description": "A device registered for push notifications",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "endpoint": {
+                    "description": "Push service URL the browser subscribed with",
+                    "type": "string",
+                    "example": "https://fcm.googleapis.com/fcm/send/abc123"
+                },
+                "expiresAt": {
+                    "description": "When the push service expires the subscription, if it said so",
+                    "type": "string"
+                },
+                "userAgent": {
+                    "description": "Browser that registered the subscription",
+                    "type": "string"
+                }
+            }
+        },
+        "notification.PushSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "endpoint"
+            ],
+            "properties": {
+                "endpoint": {
+                    "type": "string"
+                },
+                "expirationTime": {
+                    "description": "ExpirationTime is milliseconds since the epoch, or null",
+                    "type": "integer"
+                },
+                "keys": {
+                    "type": "object",
+                    "required": [
+                        "auth",
+                        "p256dh"
+                    ],
+                    "properties": {
+                        "auth": {
+                            "type": "string"
+                        },
+                        "p256dh": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "notification.PushUnsubscribeRequest": {
+            "type": "object",
+            "required": [
+                "endpoint"
+            ],
+            "properties": {
+                "endpoint": {
+                    "type": "string"
+                }
+            }
+        },
+        "notification.ReadReceipt": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "description": "The device it was read on, when the client said",
+                    "type": "string",
+                    "example": "ios-4f2a"
+                },
+                "notificationId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "readAt": {
+                    "description": "When it was marked read",
+                    "type": "string",
+                    "example": "2025-03-05T21:30:00Z"
+                }
+            }
+        },
+        "notification.ReadState": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "description": "The device that last moved lastReadAt",
+                    "type": "string",
+                    "example": "web-9c1d"
+                },
+                "lastReadAt": {
+                    "description": "Notifications created at or before this are read (null if the user never marked all read)",
+                    "type": "string",
+                    "example": "2025-03-05T21:26:06Z"
+                },
+                "read": {
+                    "description": "Notifications newer than lastReadAt that were marked read one by one",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notification.ReadReceipt"
+                    }
+                },
+                "unreadCount": {
+                    "description": "Notifications that are still unread",
+                    "type": "integer",
+                    "example": 3
+                },
+                "updatedAt": {
+                    "description": "When lastReadAt last moved",
+                    "type": "string",
+                    "example": "2025-03-05T21:30:00Z"
+                }
+            }
+        },
+        "notification.ReadStateUpdate": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "description": "Identifies the device, recorded on the read marks",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "web-9c1d"
+                },
+                "lastReadAt": {
+                    "description": "Mark every notification created at or before this read. A watermark\nolder than the stored one is ignored and one in the future is clamped to now.",
+                    "type": "string",
+                    "example": "2025-03-05T21:26:06Z"
+                },
+                "read": {
+                    "description": "Notifications to mark read, at most 100; unknown IDs are skipped",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "notification.SubscriptionLag": {
+            "type": "object",
+            "properties": {
+                "backlog": {
+                    "type": "integer"
+                },
+                "consumers": {
+                    "type": "integer"
+                },
+                "msgRateOut": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "stalled": {
+                    "description": "Stalled is set when the backlog is non-zero and did not shrink since the previous poll",
+                    "type": "boolean"
+                }
+            }
+        },
+        "notification.TopicStats": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "lastFailureAt": {
+                    "type": "string"
+                },
+                "lastProcessedAt": {
+                    "type": "string"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/notification.SubscriptionLag"
+                    }
+                },
+                "throughputPerMinute": {
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "p2p.Info": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/ip4/203.0.113.7/tcp/4001/p2p/12D3KooWAbc"
+                    ]
+                },
+                "agent_version": {
+                    "type": "string",
+                    "example": "kubo/0.29.0/"
+                },
+                "connected_peers": {
+                    "description": "ConnectedPeers counts the libp2p peers currently connected, Pavilion or not",
+                    "type": "integer",
+                    "example": 42
+                },
+                "pavilion_peers": {
+                    "description": "PavilionPeers are the nodes this node has heard announcements from, most recent first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/p2p.Peer"
+                    }
+                },
+                "peer_id": {
+                    "description": "PeerID and Addresses identify the libp2p host clients and peers dial",
+                    "type": "string",
+                    "example": "12D3KooWAbc"
+                },
+                "topic": {
+                    "description": "Topic is the pubsub topic video announcements are gossiped on",
+                    "type": "string",
+                    "example": "pavilion/videos/v1"
+                }
+            }
+        },
+        "p2p.Peer": {
+            "type": "object",
+            "properties": {
+                "last_seen": {
+                    "type": "string"
+                },
+                "peer_id": {
+                    "type": "string",
+                    "example": "12D3KooWXyz"
+                },
+                "url": {
+                    "description": "URL is the base URL of the peer's API, when it sent one",
+                    "type": "string",
+                    "example": "https://pavilion.example.com/api/v1"
+                },
+                "videos": {
+                    "description": "Videos counts the announcements heard from the peer",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "sharing.OEmbed": {
+            "type": "object",
+            "properties": {
+                "author_name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "author_url": {
+                    "type": "string",
+                    "example": "https://pavilion.example.com/channel/550e8400-e29b-41d4-a716-446655440000"
+                },
+                "cache_age": {
+                    "description": "CacheAge is how many seconds consumers may keep the response",
+                    "type": "integer",
+                    "example": 3600
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 360
+                },
+                "html": {
+                    "description": "HTML embeds the player in an iframe",
+                    "type": "string",
+                    "example": "\u003ciframe src=\"https://pavilion.example.com/embed/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b\" width=\"640\" height=\"360\" frameborder=\"0\" allowfullscreen\u003e\u003c/iframe\u003e"
+                },
+                "provider_name": {
+                    "type": "string",
+                    "example": "Pavilion"
+                },
+                "provider_url": {
+                    "type": "string",
+                    "example": "https://pavilion.example.com"
+                },
+                "thumbnail_height": {
+                    "type": "integer",
+                    "example": 180
+                },
+                "thumbnail_url": {
+                    "description": "ThumbnailURL is the video's animated preview; the thumbnail fields are\nonly set when it can be linked",
+                    "type": "string"
+                },
+                "thumbnail_width": {
+                    "type": "integer",
+                    "example": 320
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Intro to Go"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "video"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.0"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 640
+                }
+            }
+        },
+        "sharing.SitemapURL": {
+            "type": "object",
+            "properties": {
+                "lastMod": {
+                    "description": "LastMod is when the video last changed, in W3C datetime format",
+                    "type": "string"
+                },
+                "loc": {
+                    "type": "string"
+                },
+                "video": {
+                    "description": "Video describes the video on the page; only set when its thumbnail,\nwhich search engines require, can be linked",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sharing.SitemapVideo"
+                        }
+                    ]
+                }
+            }
+        },
+        "sharing.SitemapVideo": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "playerLoc": {
+                    "type": "string"
+                },
+                "publicationDate": {
+                    "type": "string"
+                },
+                "thumbnailLoc": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "sharing.URLSet": {
+            "type": "object",
+            "properties": {
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/sharing.SitemapURL"
+                    }
+                },
+                "video": {
+                    "type": "string"
+                },
+                "xmlns": {
+                    "type": "string"
+                }
+            }
+        },
+        "sync.Change": {
+            "description": "A single changed entity. Data is omitted for deletes.",
+            "type": "object",
+            "properties": {
+                "changedAt": {
+                    "description": "When the change happened",
+                    "type": "string",
+                    "example": "2025-03-05T21:26:06Z"
+                },
+                "data": {
+                    "description": "Current state of the entity, omitted for deletes"
+                },
+                "entity": {
+                    "description": "Kind of entity that changed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sync.EntityType"
+                        }
+                    ],
+                    "example": "video"
+                },
+                "id": {
+                    "description": "ID of the entity that changed",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "op": {
+                    "description": "Whether the entity was created/updated or deleted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/sync.Operation"
+                        }
+                    ],
+                    "example": "upsert"
+                }
+            }
+        },
+        "sync.ChangeSet": {
+            "description": "A page of changes in the order they happened, with a cursor to resume from",
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "description": "Changes in chronological order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/sync.Change"
+                    }
+                },
+                "hasMore": {
+                    "description": "Whether more changes are immediately available",
+                    "type": "boolean",
+                    "example": false
+                },
+                "nextCursor": {
+                    "description": "Cursor to pass as since on the next request",
+                    "type": "string",
+                    "example": "MTc0MTIwOTk2NjAwMDAwMDAwMA"
+                }
+            }
+        },
+        "sync.EntityType": {
+            "description": "Kind of entity a change refers to (e.g. video, notification)",
+            "type": "string",
+            "enum": [
+                "video",
+                "notification",
+                "follow",
+                "notification_read_state"
+            ],
+            "x-enum-varnames": [
+                "EntityVideo",
+                "EntityNotification",
+                "EntityFollow",
+                "EntityNotificationReadState"
+            ]
+        },
+        "sync.Operation": {
+            "description": "Change operation: upsert (created or updated) or delete",
+            "type": "string",
+            "enum": [
+                "upsert",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "OperationUpsert",
+                "OperationDelete"
+            ]
+        },
+        "user.Profile": {
+            "description": "Public profile information for a user's channel page",
+            "type": "object",
+            "properties": {
+                "avatarUrl": {
+                    "description": "Temporary URL of the avatar image, empty if the user has none",
+                    "type": "string",
+                    "example": "https://storage.example.com/avatars/550e8400-e29b-41d4-a716-446655440000/avatar.png"
+                },
+                "bio": {
+                    "description": "Short biography",
+                    "type": "string",
+                    "example": "Filmmaker and open source enthusiast"
+                },
+                "createdAt": {
+                    "description": "When the user joined",
+                    "type": "string",
+                    "example": "2025-03-05T21:26:06Z"
+                },
+                "followerCount": {
+                    "description": "Number of followers",
+                    "type": "integer",
+                    "example": 42
+                },
+                "id": {
+                    "description": "User ID",
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "description": "Display name",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "username": {
+                    "description": "Username",
                     "type": "string",
                     "example": "johndoe"
                 }
             }
         },
-        "auth.User": {
-            "description": "User model",
+        "user.UpdateProfileRequest": {
+            "description": "Profile fields to update; omitted fields are left unchanged",
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "description": "Short biography (max 500 characters)",
+                    "type": "string",
+                    "example": "Filmmaker and open source enthusiast"
+                },
+                "name": {
+                    "description": "Display name",
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "video.APIResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "video.CaptionListResponse": {
+            "type": "object",
+            "properties": {
+                "captions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.CaptionTrack"
+                    }
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "video.CaptionTrack": {
+            "type": "object",
+            "properties": {
+                "burned_in_url": {
+                    "description": "BurnedInURL serves a copy of the video with the captions drawn onto the picture",
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "English"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL serves the track as WebVTT; URLs from S3 expire",
+                    "type": "string"
+                }
+            }
+        },
+        "video.Chapter": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "start": {
+                    "description": "Start is the offset of the chapter in seconds",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 0
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Intro"
+                }
+            }
+        },
+        "video.ChaptersRequest": {
+            "type": "object",
+            "properties": {
+                "chapters": {
+                    "description": "Chapters replace the video's chapters; an empty list removes them, so\nchapters are taken from the description again",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "$ref": "#/definitions/video.Chapter"
+                    }
+                }
+            }
+        },
+        "video.ChaptersResponse": {
+            "type": "object",
+            "properties": {
+                "chapters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.Chapter"
+                    }
+                },
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "video.RelatedVideosResponse": {
+            "type": "object",
+            "properties": {
+                "videos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.VideoDetailsResponse"
+                    }
+                }
+            }
+        },
+        "video.RenditionSources": {
+            "type": "object",
+            "properties": {
+                "rendition": {
+                    "type": "string",
+                    "example": "720p"
+                },
+                "sha256": {
+                    "description": "SHA256 is the hex SHA-256 of the file; omitted for renditions stored\nbefore checksums were recorded",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "size": {
+                    "description": "Size is the file's size in bytes, when known",
+                    "type": "integer",
+                    "example": 104857600
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.VideoSource"
+                    }
+                }
+            }
+        },
+        "video.RenditionTimes": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/video.RenditionTiming"
+            }
+        },
+        "video.RenditionTiming": {
+            "type": "object",
+            "properties": {
+                "finished_at": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "video.ScanListResponse": {
             "type": "object",
             "properties": {
-                "active": {
-                    "description": "Whether account is active",
-                    "type": "boolean",
-                    "example": true
+                "limit": {
+                    "type": "integer"
                 },
-                "createdAt": {
-                    "description": "Account creation timestamp",
-                    "type": "string"
+                "page": {
+                    "type": "integer"
                 },
-                "email": {
-                    "description": "User email address",
-                    "type": "string",
-                    "example": "user@example.com"
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.ScanReport"
+                    }
+                }
+            }
+        },
+        "video.ScanReport": {
+            "type": "object",
+            "properties": {
+                "findings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Eicar-Test-Signature"
+                    ]
                 },
-                "emailVerified": {
-                    "description": "Whether email is verified",
-                    "type": "boolean",
-                    "example": true
+                "scanned_at": {
+                    "type": "string"
                 },
-                "id": {
-                    "description": "Unique user ID",
+                "scanner": {
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                    "example": "clamav"
                 },
-                "lastLoginAt": {
-                    "description": "Last login timestamp",
-                    "type": "string"
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/video.ScanStatus"
+                        }
+                    ],
+                    "example": "quarantined"
                 },
-                "name": {
-                    "description": "User's full name",
+                "title": {
                     "type": "string",
-                    "example": "John Doe"
+                    "example": "My upload"
                 },
-                "updatedAt": {
-                    "description": "Last update timestamp",
-                    "type": "string"
+                "user_id": {
+                    "type": "string",
+                    "example": "123e4567-e89b-12d3-a456-426614174001"
                 },
-                "username": {
-                    "description": "Unique username",
+                "video_id": {
                     "type": "string",
-                    "example": "johndoe"
+                    "example": "123e4567-e89b-12d3-a456-426614174000"
                 }
             }
         },
-        "comment.Comment": {
-            "description": "A comment on a video with metadata and reaction counts",
+        "video.ScanStatus": {
+            "type": "string",
+            "enum": [
+                "",
+                "clean",
+                "flagged",
+                "quarantined",
+                "blocked",
+                "error",
+                "released"
+            ],
+            "x-enum-varnames": [
+                "ScanNotScanned",
+                "ScanClean",
+                "ScanFlagged",
+                "ScanQuarantined",
+                "ScanBlocked",
+                "ScanError",
+                "ScanReleased"
+            ]
+        },
+        "video.StorageClassUsage": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string",
-                    "example": "This is a great video!"
-                },
-                "created_at": {
-                    "type": "string",
-                    "example": "2023-01-01T12:00:00Z"
+                "bytes": {
+                    "type": "integer"
                 },
-                "deleted_at": {
+                "storage_class": {
                     "type": "string",
-                    "example": "2023-01-02T12:00:00Z"
+                    "example": "STANDARD_IA"
                 },
-                "dislikes": {
+                "videos": {
+                    "type": "integer"
+                }
+            }
+        },
+        "video.TagCount": {
+            "type": "object",
+            "properties": {
+                "count": {
                     "type": "integer",
-                    "example": 1
+                    "example": 42
                 },
-                "id": {
+                "name": {
                     "type": "string",
-                    "format": "uuid",
-                    "example": "123e4567-e89b-12d3-a456-426614174000"
-                },
-                "likes": {
-                    "type": "integer",
-                    "example": 5
-                },
-                "parent_id": {
+                    "example": "tutorial"
+                }
+            }
+        },
+        "video.TakedownRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Copyright claim"
+                }
+            }
+        },
+        "video.TieringOverrideRequest": {
+            "type": "object",
+            "required": [
+                "override"
+            ],
+            "properties": {
+                "override": {
                     "type": "string",
-                    "format": "uuid",
-                    "example": "123e4567-e89b-12d3-a456-426614174003"
-                },
-                "status": {
                     "enum": [
-                        "ACTIVE",
-                        "FLAGGED",
-                        "HIDDEN"
+                        "default",
+                        "exempt",
+                        "archive"
                     ],
+                    "example": "exempt"
+                }
+            }
+        },
+        "video.TieringReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "enabled": {
+                    "description": "Enabled is whether tiering runs periodically; overrides and\nregenerating renditions work either way",
+                    "type": "boolean"
+                },
+                "last_run": {
+                    "description": "LastRun is the outcome of this instance's latest run; absent before the first",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/comment.Status"
+                            "$ref": "#/definitions/video.TieringRun"
                         }
-                    ],
-                    "example": "ACTIVE"
+                    ]
                 },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2023-01-01T12:00:00Z"
+                "originals": {
+                    "description": "Originals counts originals and their bytes by storage class",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.StorageClassUsage"
+                    }
                 },
-                "user_id": {
-                    "type": "string",
-                    "format": "uuid",
-                    "example": "123e4567-e89b-12d3-a456-426614174002"
+                "originals_after_days": {
+                    "type": "integer"
                 },
-                "video_id": {
-                    "type": "string",
-                    "format": "uuid",
-                    "example": "123e4567-e89b-12d3-a456-426614174001"
+                "overrides": {
+                    "description": "Overrides counts videos that do not follow the policy, by override",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "regenerating": {
+                    "description": "Regenerating counts videos whose renditions this instance is transcoding again",
+                    "type": "integer"
+                },
+                "renditions_dropped": {
+                    "description": "RenditionsDropped counts videos whose resolutions are dropped",
+                    "type": "integer"
+                },
+                "renditions_idle_days": {
+                    "type": "integer"
+                },
+                "storage_class": {
+                    "type": "string"
                 }
             }
         },
-        "comment.CreateCommentRequest": {
-            "description": "Request body for creating a new comment",
+        "video.TieringRun": {
             "type": "object",
-            "required": [
-                "content"
-            ],
             "properties": {
-                "content": {
-                    "type": "string",
-                    "example": "This is a great video!"
+                "applied": {
+                    "type": "integer"
                 },
-                "parent_id": {
-                    "type": "string",
-                    "format": "uuid",
-                    "example": "123e4567-e89b-12d3-a456-426614174003"
+                "candidates": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
                 }
             }
         },
-        "comment.PaginatedComments": {
-            "description": "A paginated list of comments with metadata about the pagination",
+        "video.TranscodeFailureReason": {
+            "type": "string",
+            "enum": [
+                "timeout",
+                "encode_error",
+                "storage_error"
+            ],
+            "x-enum-varnames": [
+                "TranscodeFailureTimeout",
+                "TranscodeFailureEncode",
+                "TranscodeFailureStorage"
+            ]
+        },
+        "video.TranscodeFailures": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/video.TranscodeFailureReason"
+            }
+        },
+        "video.TranscodeInfo": {
             "type": "object",
             "properties": {
-                "comments": {
+                "created_at": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "resolution": {
+                    "type": "string"
+                },
+                "segments": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/comment.Comment"
+                        "$ref": "#/definitions/video.TranscodeSegmentInfo"
                     }
+                }
+            }
+        },
+        "video.TranscodeSegmentInfo": {
+            "type": "object",
+            "properties": {
+                "duration": {
+                    "type": "integer"
                 },
-                "current_page": {
-                    "type": "integer",
-                    "example": 1
+                "id": {
+                    "type": "string"
                 },
-                "has_next_page": {
-                    "type": "boolean",
-                    "example": true
+                "ipfs_cid": {
+                    "type": "string"
                 },
-                "has_prev_page": {
-                    "type": "boolean",
-                    "example": false
+                "replication_status": {
+                    "type": "string"
+                },
+                "storage_path": {
+                    "type": "string"
+                }
+            }
+        },
+        "video.TrashListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "videos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.TrashedVideo"
+                    }
+                }
+            }
+        },
+        "video.TrashedVideo": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "purge_at": {
+                    "description": "PurgeAt is when the video's files and records are purged; omitted when\ndeleted videos are kept until purged by hand",
+                    "type": "string"
                 },
-                "total_count": {
-                    "type": "integer",
-                    "example": 42
+                "restorable": {
+                    "description": "Restorable is false for videos removed by a moderator or admin, which\nonly an admin can restore",
+                    "type": "boolean"
                 },
-                "total_pages": {
-                    "type": "integer",
-                    "example": 3
+                "title": {
+                    "type": "string"
                 }
             }
         },
-        "comment.ReactionRequest": {
-            "description": "Request body for adding a reaction to a comment",
+        "video.UploadResponse": {
             "type": "object",
-            "required": [
-                "type"
-            ],
             "properties": {
-                "type": {
-                    "type": "string",
-                    "enum": [
-                        "LIKE",
-                        "DISLIKE"
-                    ],
-                    "example": "LIKE"
+                "duplicate": {
+                    "description": "Duplicate is set when the file was already uploaded by the same user;\nthe response describes that earlier video",
+                    "type": "boolean"
+                },
+                "file_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ipfs_cid": {
+                    "type": "string"
+                },
+                "replication_status": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "storage_path": {
+                    "type": "string"
+                },
+                "transcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.TranscodeInfo"
+                    }
+                },
+                "version": {
+                    "description": "Version counts the files the video has had, starting at 1",
+                    "type": "integer"
                 }
             }
         },
-        "comment.Status": {
-            "description": "Status of a comment (ACTIVE, FLAGGED, or HIDDEN)",
+        "video.UploadStage": {
             "type": "string",
             "enum": [
-                "ACTIVE",
-                "FLAGGED",
-                "HIDDEN"
+                "receiving",
+                "validating",
+                "scanning",
+                "storing_original",
+                "transcoding",
+                "finalizing",
+                "completed"
             ],
             "x-enum-varnames": [
-                "StatusActive",
-                "StatusFlagged",
-                "StatusHidden"
+                "UploadStageReceiving",
+                "UploadStageValidating",
+                "UploadStageScanning",
+                "UploadStageStoringOriginal",
+                "UploadStageTranscoding",
+                "UploadStageFinalizing",
+                "UploadStageCompleted"
             ]
         },
-        "comment.UpdateCommentRequest": {
-            "description": "Request body for updating a comment",
-            "type": "object",
-            "required": [
-                "content"
+        "video.UploadStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "uploading",
+                "completed",
+                "failed",
+                "interrupted"
             ],
+            "x-enum-varnames": [
+                "UploadStatusPending",
+                "UploadStatusUploading",
+                "UploadStatusCompleted",
+                "UploadStatusFailed",
+                "UploadStatusInterrupted"
+            ]
+        },
+        "video.VersionInfo": {
+            "type": "object",
             "properties": {
-                "content": {
-                    "type": "string",
-                    "example": "This is an updated comment."
+                "checksum": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current is set for the version the video plays now",
+                    "type": "boolean"
+                },
+                "file_size": {
+                    "type": "integer"
+                },
+                "purged": {
+                    "description": "Purged is set once the version's archived files have been deleted",
+                    "type": "boolean"
+                },
+                "replaced_at": {
+                    "type": "string"
+                },
+                "uploaded_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
                 }
             }
         },
-        "http.APIError": {
-            "description": "Error response structure",
+        "video.VersionListResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "Error code identifying the type of error",
-                    "type": "string",
-                    "example": "VALIDATION_ERROR"
-                },
-                "field": {
-                    "description": "Optional field name for validation errors",
-                    "type": "string",
-                    "example": "email"
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.VersionInfo"
+                    }
                 },
-                "message": {
-                    "description": "Human-readable error message",
-                    "type": "string",
-                    "example": "Invalid input parameters"
+                "video_id": {
+                    "type": "string"
                 }
             }
         },
-        "http.APIResponse": {
-            "description": "Standard API response format",
+        "video.VideoDetailsResponse": {
             "type": "object",
             "properties": {
-                "data": {
-                    "description": "Optional data returned by the API"
+                "allowed_countries": {
+                    "description": "AllowedCountries are the only countries the video is played in, and\nBlockedCountries those it is not played in, by ISO 3166-1 alpha-2 code",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "US",
+                        "CA"
+                    ]
                 },
-                "error": {
-                    "description": "Error information, if any",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/http.APIError"
-                        }
+                "audio_path": {
+                    "description": "AudioPath is the storage key of the audio-only rendition, for low-bandwidth playback",
+                    "type": "string",
+                    "example": "videos/3f6c.../audio.m4a"
+                },
+                "blocked_countries": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "DE"
                     ]
                 },
-                "message": {
-                    "description": "Optional message describing the response",
+                "captions": {
+                    "description": "Captions are the video's caption tracks; only set on GET /video/{id}",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.CaptionTrack"
+                    }
+                },
+                "category": {
                     "type": "string",
-                    "example": "Operation completed successfully"
+                    "example": "education"
                 },
-                "success": {
-                    "description": "Indicates if the request was successful",
+                "chapters": {
+                    "description": "Chapters are those the creator set, or else those listed in the description",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.Chapter"
+                    }
+                },
+                "comment_count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "comments_policy": {
+                    "description": "CommentsPolicy is whether the video takes comments, and whether they are held for the owner's review",
+                    "type": "string",
+                    "enum": [
+                        "enabled",
+                        "disabled",
+                        "review_required"
+                    ],
+                    "example": "enabled"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "Duration is the length of the video in seconds, when known",
+                    "type": "number",
+                    "example": 754.2
+                },
+                "embeddable": {
+                    "description": "Embeddable is whether the video may be played in the embedded player on other sites",
                     "type": "boolean",
                     "example": true
-                }
-            }
-        },
-        "http.Error": {
-            "type": "object",
-            "properties": {
-                "code": {
+                },
+                "file_id": {
                     "type": "string"
                 },
-                "field": {
+                "file_size": {
+                    "type": "integer"
+                },
+                "id": {
                     "type": "string"
                 },
-                "message": {
+                "ipfs_cid": {
                     "type": "string"
-                }
-            }
-        },
-        "http.Response": {
-            "type": "object",
-            "properties": {
-                "data": {},
-                "error": {
-                    "$ref": "#/definitions/http.Error"
                 },
-                "message": {
+                "origin": {
+                    "description": "Origin is the API base URL of the remote instance a federated video\nlives on, where it is fetched by ID; omitted for this instance's videos",
+                    "type": "string",
+                    "example": "https://peer.example.com/api/v1"
+                },
+                "playback_urls": {
+                    "description": "PlaybackURLs are CDN URLs of the video's renditions, by name: original,\n720p, 480p, 360p, audio and preview; only set on GET /video/{id} when a\nCDN is configured",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "720p": "https://cdn.example.com/videos/3f6c.../720p.mp4"
+                    }
+                },
+                "preview_path": {
+                    "description": "PreviewPath is the storage key of a few seconds of silent, looping WebP animation for listings",
+                    "type": "string",
+                    "example": "videos/3f6c.../preview.webp"
+                },
+                "replication_status": {
                     "type": "string"
                 },
-                "success": {
+                "requires_entitlement": {
                     "type": "boolean"
+                },
+                "resume_at": {
+                    "description": "ResumeAt is where the requesting user left off, in seconds; only set on GET /video/{id}",
+                    "type": "number",
+                    "example": 754.2
+                },
+                "status": {
+                    "type": "string"
+                },
+                "storage_path": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "tutorial",
+                        "golang"
+                    ]
+                },
+                "takedown_reason": {
+                    "type": "string",
+                    "example": "Copyright claim"
+                },
+                "taken_down_at": {
+                    "description": "TakenDownAt and TakedownReason are set on videos an admin took down,\nwhich only their owner and staff can see",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "transcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.TranscodeInfo"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 1280
                 }
             }
         },
-        "notification.EventType": {
-            "description": "Type of notification event (e.g. VIDEO_UPLOADED, COMMENT_CREATED)",
-            "type": "string",
-            "enum": [
-                "VIDEO_UPLOADED",
-                "VIDEO_PROCESSED",
-                "VIDEO_UPDATED",
-                "VIDEO_DELETED",
-                "COMMENT_CREATED",
-                "COMMENT_REPLIED",
-                "COMMENT_REACTION",
-                "USER_FOLLOWED",
-                "USER_MENTIONED",
-                "AUTH_EVENT"
-            ],
-            "x-enum-varnames": [
-                "VideoUploaded",
-                "VideoProcessed",
-                "VideoUpdated",
-                "VideoDeleted",
-                "CommentCreated",
-                "CommentReplied",
-                "CommentReaction",
-                "UserFollowed",
-                "UserMentioned",
-                "AuthEvent"
-            ]
+        "video.VideoListResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "videos": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.VideoDetailsResponse"
+                    }
+                }
+            }
         },
-        "notification.Notification": {
-            "description": "A notification entity with metadata and status information",
+        "video.VideoSource": {
             "type": "object",
             "properties": {
-                "content": {
-                    "description": "Human-readable notification content",
+                "cid": {
+                    "description": "CID identifies an IPFS copy, and URI addresses it for IPFS-native clients",
                     "type": "string",
-                    "example": "Your video 'My awesome video' has been uploaded successfully"
+                    "example": "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
                 },
-                "createdAt": {
-                    "description": "When the notification was created",
+                "peers": {
+                    "description": "Peers are multiaddrs of IPFS nodes known to hold the CID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/dns4/ipfs.example.com/tcp/4001/p2p/12D3KooWAbc"
+                    ]
+                },
+                "type": {
                     "type": "string",
-                    "example": "2025-03-05T21:26:06Z"
+                    "enum": [
+                        "stream",
+                        "cdn",
+                        "storage",
+                        "ipfs"
+                    ],
+                    "example": "ipfs"
                 },
-                "id": {
-                    "description": "Unique identifier for the notification",
+                "uri": {
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                    "example": "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
                 },
-                "metadata": {
-                    "description": "Additional metadata about the notification",
-                    "type": "object",
-                    "additionalProperties": true
+                "url": {
+                    "description": "URL fetches the file over HTTP; CDN and storage URLs may expire",
+                    "type": "string",
+                    "example": "https://ipfs.io/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+                }
+            }
+        },
+        "video.VideoSourcesResponse": {
+            "type": "object",
+            "properties": {
+                "renditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/video.RenditionSources"
+                    }
                 },
-                "readAt": {
-                    "description": "When the notification was marked as read (null if unread)",
+                "video_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "video.VideoStatusResponse": {
+            "type": "object",
+            "properties": {
+                "ended_at": {
                     "type": "string"
                 },
-                "type": {
-                    "description": "Type of notification (VIDEO_UPLOADED, COMMENT_CREATED, etc.)",
+                "ipfs_files": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "ipfs_pinned": {
+                    "description": "Files pinned to IPFS, out of the original and its stored renditions.\nPinning happens in the background once the upload completes.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "rendition_progress": {
+                    "description": "How much of each rendition has been encoded, in percent",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    },
+                    "example": {
+                        "720p": 42
+                    }
+                },
+                "rendition_times": {
+                    "description": "When each rendition started transcoding and was stored or failed",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/notification.EventType"
+                            "$ref": "#/definitions/video.RenditionTimes"
+                        }
+                    ]
+                },
+                "renditions": {
+                    "description": "Progress of each rendition: queued, transcoding, storing, completed or failed",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "480p": "completed"
+                    }
+                },
+                "stage": {
+                    "description": "Stage of the pipeline: receiving, validating, scanning,\nstoring_original, transcoding, finalizing or completed. Failed and\ninterrupted uploads keep the stage they stopped at.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/video.UploadStage"
                         }
                     ],
-                    "example": "VIDEO_UPLOADED"
+                    "example": "transcoding"
                 },
-                "userId": {
-                    "description": "User ID who should receive this notification",
+                "stage_times": {
+                    "description": "When the upload entered each stage",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "transcoding": "2026-01-02T15:04:05Z"
+                    }
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440001"
+                    "example": "completed"
+                },
+                "stored_bytes": {
+                    "description": "Bytes of the original file stored so far, out of TotalBytes",
+                    "type": "integer",
+                    "example": 52428800
+                },
+                "total_bytes": {
+                    "type": "integer",
+                    "example": 104857600
+                },
+                "transcode_failures": {
+                    "description": "Renditions that could not be produced, by resolution: timeout, encode_error or storage_error",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "720p": "timeout"
+                    }
+                },
+                "transcode_percent": {
+                    "description": "How much of the renditions has been produced overall, in percent.\nStored and failed renditions count as done.",
+                    "type": "integer",
+                    "example": 71
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "video.APIResponse": {
+        "video.VideoTiering": {
             "type": "object",
             "properties": {
-                "data": {},
-                "message": {
+                "override": {
+                    "type": "string",
+                    "enum": [
+                        "default",
+                        "exempt",
+                        "archive"
+                    ]
+                },
+                "regenerating": {
+                    "type": "boolean"
+                },
+                "renditions_dropped_at": {
                     "type": "string"
                 },
-                "status": {
+                "storage_class": {
+                    "type": "string"
+                },
+                "video_id": {
                     "type": "string"
                 }
             }
         },
-        "video.TranscodeInfo": {
+        "video.VideoUpdateRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "allowed_countries": {
+                    "description": "AllowedCountries replaces the only countries the video is played in,\nand BlockedCountries those it is not played in, as ISO 3166-1 alpha-2\ncodes; an empty list lifts the restriction",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "US",
+                        "CA"
+                    ]
                 },
-                "format": {
-                    "type": "string"
+                "blocked_countries": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "DE"
+                    ]
                 },
-                "id": {
-                    "type": "string"
+                "category": {
+                    "description": "Category replaces the category; an empty string removes it",
+                    "type": "string",
+                    "example": "education"
                 },
-                "resolution": {
+                "comments_policy": {
+                    "description": "CommentsPolicy turns comments off, or holds them for the owner's review",
+                    "type": "string",
+                    "enum": [
+                        "enabled",
+                        "disabled",
+                        "review_required"
+                    ],
+                    "example": "review_required"
+                },
+                "description": {
                     "type": "string"
                 },
-                "segments": {
+                "embeddable": {
+                    "description": "Embeddable allows or forbids playing the video in the embedded player",
+                    "type": "boolean",
+                    "example": false
+                },
+                "tags": {
+                    "description": "Tags replaces all tags; an empty list removes them",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/video.TranscodeSegmentInfo"
+                        "type": "string"
                     }
+                },
+                "title": {
+                    "type": "string"
                 }
             }
         },
-        "video.TranscodeSegmentInfo": {
+        "webhook.CreateRequest": {
             "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
             "properties": {
-                "duration": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "ipfs_cid": {
-                    "type": "string"
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "video.processed",
+                        "video.failed"
+                    ]
                 },
-                "storage_path": {
-                    "type": "string"
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/pavilion"
                 }
             }
         },
-        "video.UploadResponse": {
+        "webhook.CreateResponse": {
             "type": "object",
             "properties": {
-                "file_id": {
+                "created_at": {
                     "type": "string"
                 },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "video.processed",
+                        "video.failed"
+                    ]
+                },
                 "id": {
                     "type": "string"
                 },
-                "ipfs_cid": {
-                    "type": "string"
+                "secret": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
                 },
-                "status": {
+                "updated_at": {
                     "type": "string"
                 },
-                "storage_path": {
-                    "type": "string"
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/pavilion"
                 },
-                "transcodes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/video.TranscodeInfo"
-                    }
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
-        "video.VideoDetailsResponse": {
+        "webhook.Delivery": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string"
+                "attempts": {
+                    "type": "integer"
                 },
-                "description": {
+                "created_at": {
                     "type": "string"
                 },
-                "file_id": {
+                "delivered_at": {
                     "type": "string"
                 },
-                "file_size": {
-                    "type": "integer"
+                "event": {
+                    "type": "string",
+                    "example": "video.processed"
                 },
                 "id": {
                     "type": "string"
                 },
-                "ipfs_cid": {
+                "last_error": {
                     "type": "string"
                 },
-                "status": {
+                "next_attempt_at": {
                     "type": "string"
                 },
-                "storage_path": {
+                "payload": {
+                    "description": "Payload is the exact JSON body sent, so every attempt carries the same signature",
                     "type": "string"
                 },
-                "title": {
-                    "type": "string"
+                "response_status": {
+                    "type": "integer",
+                    "example": 200
                 },
-                "transcodes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/video.TranscodeInfo"
-                    }
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/webhook.DeliveryStatus"
+                        }
+                    ],
+                    "example": "succeeded"
                 },
                 "updated_at": {
                     "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
                 }
             }
         },
-        "video.VideoListResponse": {
+        "webhook.DeliveryListResponse": {
             "type": "object",
             "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Delivery"
+                    }
+                },
                 "limit": {
                     "type": "integer"
                 },
@@ -2520,28 +18990,63 @@ const docTemplate = `{
                 },
                 "total": {
                     "type": "integer"
-                },
-                "videos": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/video.VideoDetailsResponse"
-                    }
                 }
             }
         },
-        "video.VideoUpdateRequest": {
+        "webhook.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivering",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliveryInProgress",
+                "DeliverySucceeded",
+                "DeliveryFailed"
+            ]
+        },
+        "webhook.Webhook": {
             "type": "object",
             "properties": {
-                "description": {
+                "created_at": {
                     "type": "string"
                 },
-                "title": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "video.processed",
+                        "video.failed"
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/pavilion"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Scoped API key for server-to-server access",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BasicAuth": {
             "type": "basic"
         },
@@ -2568,6 +19073,34 @@ const docTemplate = `{
         {
             "description": "Health check endpoints",
             "name": "health"
+        },
+        {
+            "description": "Public user profile and channel endpoints",
+            "name": "users"
+        },
+        {
+            "description": "User follow and follower listing endpoints",
+            "name": "follows"
+        },
+        {
+            "description": "Blocking users endpoints",
+            "name": "blocks"
+        },
+        {
+            "description": "Paid and time-limited video access endpoints",
+            "name": "entitlements"
+        },
+        {
+            "description": "Content reports and the moderation queue",
+            "name": "moderation"
+        },
+        {
+            "description": "Incremental sync endpoints for offline-capable clients",
+            "name": "sync"
+        },
+        {
+            "description": "Administrative endpoints restricted to the admin role",
+            "name": "admin"
         }
     ]
 }`
@@ -2576,7 +19109,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/",
+	BasePath:         "/api/v1",
 	Schemes:          []string{},
 	Title:            "Pavilion Network API",
 	Description:      "API Server for Pavilion Network Application - A decentralized video platform",
@@ -0,0 +1,105 @@
+package accesslog
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for video access logs
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new access log handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the access log route alongside the other video
+// routes, which accept either a bearer token or an API key with readMiddleware's scope
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, readMiddleware gin.HandlerFunc) {
+	router.GET("/video/:id/access-log", authMiddleware, readMiddleware, h.handleListAccessLog)
+}
+
+// @Summary Get a video's access log
+// @Description List playback starts of a video, newest first, with coarse viewer information: country, device class and referring site. Viewers are not identified and the owner's own playbacks are not logged. Entries are kept for retention_days. Only the video's owner and admins can read it.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=ListResponse} "Access log retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid video ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Not the video's owner"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /video/{id}/access-log [get]
+func (h *Handler) handleListAccessLog(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID format", err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	asAdmin := auth.RoleFromContext(c).AtLeast(auth.RoleAdmin)
+
+	list, err := h.service.List(c.Request.Context(), userID, asAdmin, videoID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve access log")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Access log retrieved successfully")
+}
+
+// getUserID returns the authenticated user's ID, writing an error response when it is missing
+func (h *Handler) getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleServiceError maps access log service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrVideoNotFound):
+		h.responseHandler.ErrorResponse(c, http.StatusNotFound, "VIDEO_NOT_FOUND", "Video not found", err)
+	case errors.Is(err, ErrNotOwner):
+		h.responseHandler.ForbiddenResponse(c, err.Error())
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
@@ -0,0 +1,28 @@
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrVideoNotFound is returned when the video does not exist or has been deleted
+	ErrVideoNotFound = errors.New("video not found")
+	// ErrNotOwner is returned when someone other than the owner asks for a video's access log
+	ErrNotOwner = errors.New("only the video owner can view its access log")
+)
+
+// Service defines the interface for per-video access logs
+type Service interface {
+	// RecordPlayback logs a playback start with coarse information about the
+	// client that made r; it does nothing when access logging is disabled
+	RecordPlayback(ctx context.Context, videoID uuid.UUID, r *http.Request) error
+	// List returns a page of a video's access log, newest first. Only the
+	// owner may read it unless asAdmin is set.
+	List(ctx context.Context, requesterID uuid.UUID, asAdmin bool, videoID uuid.UUID, page, limit int) (*ListResponse, error)
+	// Prune deletes entries older than the retention period and returns how many were removed
+	Prune(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,55 @@
+package accesslog
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceClass is the coarse kind of device a video was played on
+type DeviceClass string
+
+const (
+	DeviceDesktop DeviceClass = "desktop"
+	DeviceMobile  DeviceClass = "mobile"
+	DeviceTablet  DeviceClass = "tablet"
+	DeviceTV      DeviceClass = "tv"
+	DeviceBot     DeviceClass = "bot"
+	DeviceOther   DeviceClass = "other"
+)
+
+// Entry is one playback start of a video. It deliberately keeps no viewer
+// identity or IP address, only information coarse enough to share with the
+// video's owner.
+type Entry struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	VideoID     uuid.UUID   `gorm:"type:uuid;not null;index:idx_video_access_logs_video_created,priority:1" json:"video_id"`
+	Country     string      `gorm:"type:text" json:"country,omitempty" example:"DE"`
+	DeviceClass DeviceClass `gorm:"type:text;not null" json:"device_class" enums:"desktop,mobile,tablet,tv,bot,other"`
+	Referrer    string      `gorm:"type:text" json:"referrer,omitempty" example:"news.example.com"`
+	CreatedAt   time.Time   `gorm:"not null;default:now();index:idx_video_access_logs_video_created,priority:2;index" json:"created_at"`
+}
+
+// TableName specifies the table name for the Entry model
+func (Entry) TableName() string {
+	return "video_access_logs"
+}
+
+// Viewer is what is recorded about the client that started a playback
+type Viewer struct {
+	// Country is an ISO 3166-1 alpha-2 code, empty when unknown
+	Country     string
+	DeviceClass DeviceClass
+	// Referrer is the host of the referring page, without path or query
+	Referrer string
+}
+
+// ListResponse represents a page of a video's access log
+type ListResponse struct {
+	Entries []Entry `json:"entries"`
+	Total   int64   `json:"total"`
+	Page    int     `json:"page"`
+	Limit   int     `json:"limit"`
+	// Entries older than this many days are removed
+	RetentionDays int `json:"retention_days" example:"90"`
+}
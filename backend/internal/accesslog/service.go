@@ -0,0 +1,194 @@
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Config represents access log settings
+type Config struct {
+	// Enabled turns recording on; logs already recorded can still be read when disabled
+	Enabled bool
+	// RetentionDays is how long entries are kept
+	RetentionDays int
+	// CountryHeader names the request header carrying the viewer's country code
+	CountryHeader string
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db     *gorm.DB
+	config Config
+	logger logger.Logger
+}
+
+// NewService creates a new access log service
+func NewService(db *gorm.DB, config Config, logger logger.Logger) Service {
+	if config.RetentionDays < 1 {
+		config.RetentionDays = 1
+	}
+	return &serviceImpl{
+		db:     db,
+		config: config,
+		logger: logger,
+	}
+}
+
+// RecordPlayback logs a playback start
+func (s *serviceImpl) RecordPlayback(ctx context.Context, videoID uuid.UUID, r *http.Request) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	viewer := viewerFromRequest(r, s.config.CountryHeader)
+	entry := &Entry{
+		ID:          uuid.New(),
+		VideoID:     videoID,
+		Country:     viewer.Country,
+		DeviceClass: viewer.DeviceClass,
+		Referrer:    viewer.Referrer,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to record playback: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of a video's access log, newest first
+func (s *serviceImpl) List(ctx context.Context, requesterID uuid.UUID, asAdmin bool, videoID uuid.UUID, page, limit int) (*ListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	var v video.Video
+	if err := s.db.WithContext(ctx).Select("id", "user_id").First(&v, "id = ?", videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to load video: %w", err)
+	}
+	if v.UserID != requesterID && !asAdmin {
+		return nil, ErrNotOwner
+	}
+
+	// Entries past retention are hidden even before the pruner removes them
+	query := s.db.WithContext(ctx).Model(&Entry{}).
+		Where("video_id = ? AND created_at > ?", videoID, s.retentionCutoff())
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count access log entries: %w", err)
+	}
+
+	entries := make([]Entry, 0, limit)
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list access log entries: %w", err)
+	}
+
+	return &ListResponse{
+		Entries:       entries,
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+		RetentionDays: s.config.RetentionDays,
+	}, nil
+}
+
+// Prune deletes entries older than the retention period
+func (s *serviceImpl) Prune(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Where("created_at <= ?", s.retentionCutoff()).Delete(&Entry{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune access log: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// retentionCutoff returns the creation time before which entries have expired
+func (s *serviceImpl) retentionCutoff() time.Time {
+	return time.Now().AddDate(0, 0, -s.config.RetentionDays)
+}
+
+// Pruner periodically removes access log entries past their retention
+type Pruner struct {
+	service  Service
+	interval time.Duration
+	logger   logger.Logger
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewPruner creates a pruner that runs every interval
+func NewPruner(service Service, interval time.Duration, logger logger.Logger) *Pruner {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Pruner{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start prunes once and then on every interval until Stop is called
+func (p *Pruner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.prune(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops pruning and waits for a running prune to finish
+func (p *Pruner) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+// prune runs one pruning pass, logging the outcome
+func (p *Pruner) prune(ctx context.Context) {
+	removed, err := p.service.Prune(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.LogError(err, "Failed to prune video access logs")
+		}
+		return
+	}
+	if removed > 0 {
+		p.logger.LogInfo("Pruned video access logs", map[string]interface{}{
+			"removed": removed,
+		})
+	}
+}
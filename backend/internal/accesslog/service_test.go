@@ -0,0 +1,72 @@
+package accesslog
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewerFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/video/123", nil)
+	r.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148")
+	r.Header.Set("Referer", "https://News.Example.com/story?user=alice")
+	r.Header.Set("CF-IPCountry", "de")
+
+	viewer := viewerFromRequest(r, "CF-IPCountry")
+	assert.Equal(t, Viewer{Country: "DE", DeviceClass: DeviceMobile, Referrer: "news.example.com"}, viewer)
+
+	// Without a configured header no country is recorded
+	assert.Empty(t, viewerFromRequest(r, "").Country)
+}
+
+func TestCountryCode(t *testing.T) {
+	for value, want := range map[string]string{
+		"us":  "US",
+		" FR": "FR",
+		"XX":  "",
+		"T1":  "",
+		"USA": "",
+		"":    "",
+	} {
+		assert.Equal(t, want, countryCode(value), value)
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	for referrer, want := range map[string]string{
+		"https://example.com/watch?v=1": "example.com",
+		"http://example.com:8080/":      "example.com",
+		"android-app://com.example":     "",
+		"not a url":                     "",
+		"":                              "",
+	} {
+		assert.Equal(t, want, referrerHost(referrer), referrer)
+	}
+}
+
+func TestDeviceClass(t *testing.T) {
+	for userAgent, want := range map[string]DeviceClass{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0":                     DeviceDesktop,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Safari/605.1.15":             DeviceDesktop,
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36": DeviceMobile,
+		"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 Chrome/120.0 Safari/537.36":        DeviceTablet,
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15":                            DeviceTablet,
+		"Mozilla/5.0 (SMART-TV; Linux; Tizen 7.0) AppleWebKit/537.36":                                   DeviceTV,
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)":                      DeviceBot,
+		"curl/8.4.0": DeviceBot,
+		"":           DeviceOther,
+	} {
+		assert.Equal(t, want, deviceClass(userAgent), userAgent)
+	}
+}
+
+func TestRecordPlaybackDisabled(t *testing.T) {
+	// A disabled service never touches the database
+	service := NewService(nil, Config{Enabled: false, RetentionDays: 30}, nil)
+
+	r := httptest.NewRequest("GET", "/video/123", nil)
+	assert.NoError(t, service.RecordPlayback(context.Background(), uuid.New(), r))
+}
@@ -0,0 +1,80 @@
+package accesslog
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// viewerFromRequest extracts the coarse viewer information kept in the log.
+// The country comes from a header set by the CDN or proxy in front of the
+// API, since the API has no geolocation database of its own.
+func viewerFromRequest(r *http.Request, countryHeader string) Viewer {
+	viewer := Viewer{
+		DeviceClass: deviceClass(r.UserAgent()),
+		Referrer:    referrerHost(r.Referer()),
+	}
+	if countryHeader != "" {
+		viewer.Country = countryCode(r.Header.Get(countryHeader))
+	}
+	return viewer
+}
+
+// countryCode normalizes a two-letter country code, returning "" for unknown
+// values such as Cloudflare's "XX" and "T1"
+func countryCode(value string) string {
+	code := strings.ToUpper(strings.TrimSpace(value))
+	if len(code) != 2 || code == "XX" {
+		return ""
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+	}
+	return code
+}
+
+// referrerHost reduces a referrer URL to its host so paths and query strings,
+// which can carry personal data, are not stored
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	u, err := url.Parse(referrer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// deviceClass guesses the kind of device from its user agent
+func deviceClass(userAgent string) DeviceClass {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "":
+		return DeviceOther
+	case containsAny(ua, "bot", "crawler", "spider", "curl/", "wget/", "python-requests", "go-http-client"):
+		return DeviceBot
+	case containsAny(ua, "smart-tv", "smarttv", "appletv", "googletv", "hbbtv", "roku", "crkey", "web0s", "tizen"):
+		return DeviceTV
+	// Android tablets leave "mobile" out of their user agent
+	case containsAny(ua, "ipad", "tablet") || (strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return DeviceTablet
+	case containsAny(ua, "mobile", "iphone", "ipod", "android", "windows phone"):
+		return DeviceMobile
+	case containsAny(ua, "windows", "macintosh", "x11", "linux", "cros"):
+		return DeviceDesktop
+	}
+	return DeviceOther
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxDocumentBytes bounds the remote documents and inbox bodies read
+const maxDocumentBytes = 1 << 20
+
+// newClient returns the HTTP client remote actors are fetched and
+// activities delivered with. Redirects are not followed, and unless private
+// networks are allowed, connections to non-public addresses are refused after
+// DNS resolution, since any server can point its actor at them.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublic reports whether ip is a publicly routable unicast address
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// fetchActor fetches a remote actor document. The fragment of a key ID is
+// dropped, since keys are published in their owner's document. The document
+// must have the ID it was fetched from, so a server cannot speak for actors
+// it does not host.
+func fetchActor(ctx context.Context, client *http.Client, actorURL string) (*Actor, error) {
+	u, err := url.Parse(actorURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid actor URL %q", ErrInvalidActivity, actorURL)
+	}
+	u.Fragment = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	req.Header.Set("Accept", ContentType+`, application/ld+json; profile="https://www.w3.org/ns/activitystreams"`)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned %d", ErrUnreachable, u, resp.StatusCode)
+	}
+
+	var actor Actor
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentBytes)).Decode(&actor); err != nil {
+		return nil, fmt.Errorf("%w: invalid actor document: %v", ErrUnreachable, err)
+	}
+	if actor.ID == "" || actor.Inbox == "" {
+		return nil, fmt.Errorf("%w: actor document has no id or inbox", ErrUnreachable)
+	}
+	if actor.ID != u.String() {
+		return nil, fmt.Errorf("%w: actor document from %s has id %s", ErrBadSignature, u, actor.ID)
+	}
+	return &actor, nil
+}
+
+// sameOrigin reports whether two URLs have the same scheme and host
+func sameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Host != "" && strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// post delivers a signed activity to an inbox
+func post(ctx context.Context, client *http.Client, inbox string, body []byte, sign func(*http.Request, []byte) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, inbox, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid inbox %q: %w", inbox, err)
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set("Accept", ContentType)
+	if err := sign(req, body); err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDocumentBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("inbox %s returned %d", inbox, resp.StatusCode)
+	}
+	return nil
+}
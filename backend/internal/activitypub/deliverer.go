@@ -0,0 +1,161 @@
+package activitypub
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DelivererConfig represents how activities are delivered to remote inboxes
+type DelivererConfig struct {
+	// Workers is how many deliveries are sent concurrently
+	Workers int
+	// QueueSize bounds how many deliveries wait for a worker; further ones are dropped
+	QueueSize int
+	// MaxAttempts is how many times a delivery is tried before it is dropped
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles with every further attempt
+	RetryDelay time.Duration
+	// Timeout bounds each delivery request
+	Timeout time.Duration
+	// AllowPrivateNetworks permits inboxes on loopback and private
+	// addresses; only for development
+	AllowPrivateNetworks bool
+}
+
+// delivery is an activity waiting to be posted to an inbox, signed with the
+// key of the channel it is from
+type delivery struct {
+	userID uuid.UUID
+	keyID  string
+	inbox  string
+	body   []byte
+}
+
+// Deliverer posts activities to the inboxes of remote followers, retrying
+// failures with exponential backoff. Deliveries are kept in memory: those
+// still queued at shutdown are dropped, as Fediverse servers expect some
+// activities to be lost.
+type Deliverer struct {
+	config DelivererConfig
+	client *http.Client
+	keys   *keyStore
+	logger logger.Logger
+	queue  chan delivery
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDeliverer creates an activity deliverer
+func NewDeliverer(db *gorm.DB, config DelivererConfig, logger logger.Logger) *Deliverer {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 1000
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = 30 * time.Second
+	}
+	return &Deliverer{
+		config: config,
+		client: newClient(config.Timeout, config.AllowPrivateNetworks),
+		keys:   &keyStore{db: db},
+		logger: logger,
+		queue:  make(chan delivery, config.QueueSize),
+	}
+}
+
+// Start starts the workers, which run until Stop is called
+func (d *Deliverer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+}
+
+// Stop stops the workers and waits for deliveries being sent to finish
+func (d *Deliverer) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+// enqueue queues a delivery, reporting false when the queue is full
+func (d *Deliverer) enqueue(item delivery) bool {
+	select {
+	case d.queue <- item:
+		return true
+	default:
+		d.logger.LogWarn("Dropping ActivityPub delivery, queue is full", map[string]interface{}{
+			"inbox": item.inbox,
+		})
+		return false
+	}
+}
+
+// work sends queued deliveries until the context is cancelled
+func (d *Deliverer) work(ctx context.Context) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item := <-d.queue:
+			d.deliver(ctx, item)
+		}
+	}
+}
+
+// deliver posts one activity, retrying until it is accepted or attempts run out
+func (d *Deliverer) deliver(ctx context.Context, item delivery) {
+	delay := d.config.RetryDelay
+	var err error
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		if err = d.send(ctx, item); err == nil {
+			return
+		}
+		if attempt == d.config.MaxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+
+	d.logger.LogWarn("Failed to deliver ActivityPub activity", map[string]interface{}{
+		"inbox":    item.inbox,
+		"attempts": d.config.MaxAttempts,
+		"error":    err.Error(),
+	})
+}
+
+// send signs and posts one activity
+func (d *Deliverer) send(ctx context.Context, item delivery) error {
+	key, err := d.keys.private(ctx, item.userID)
+	if err != nil {
+		return err
+	}
+	return post(ctx, d.client, item.inbox, item.body, func(req *http.Request, body []byte) error {
+		return signRequest(req, item.keyID, key, body)
+	})
+}
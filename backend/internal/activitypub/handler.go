@@ -0,0 +1,217 @@
+package activitypub
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for ActivityPub endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new ActivityPub handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the ActivityPub routes under the API base path.
+// They are public: Fediverse servers fetch them without an account, and
+// inbox deliveries are authenticated by their HTTP signature.
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	actors := router.Group("/ap")
+	{
+		actors.GET("/users/:username", h.handleGetActor)
+		actors.GET("/users/:username/outbox", h.handleGetOutbox)
+		actors.GET("/users/:username/followers", h.handleGetFollowers)
+		actors.POST("/users/:username/inbox", h.handlePostInbox)
+		actors.GET("/videos/:id", h.handleGetVideo)
+	}
+}
+
+// RegisterWellKnownRoutes registers WebFinger, which must be served at the
+// root of the domain
+func (h *Handler) RegisterWellKnownRoutes(router gin.IRouter) {
+	router.GET("/.well-known/webfinger", h.handleWebFinger)
+}
+
+// @Summary Resolve a Fediverse account
+// @Description Resolves acct:username@domain to the channel's ActivityPub actor, so Fediverse users can look channels up by handle. Served at the root of the domain as application/jrd+json; only served when ActivityPub is enabled.
+// @Tags activitypub
+// @Produce json
+// @Param resource query string true "Account to resolve, e.g. acct:johndoe@pavilion.example.com"
+// @Success 200 {object} WebFinger "Resource descriptor"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Resource is not an account of this server"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Channel not found"
+// @Router /.well-known/webfinger [get]
+func (h *Handler) handleWebFinger(c *gin.Context) {
+	webfinger, err := h.service.WebFinger(c.Request.Context(), c.Query("resource"))
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to resolve account")
+		return
+	}
+	h.writeDocument(c, "application/jrd+json", webfinger)
+}
+
+// @Summary Get a channel's actor
+// @Description Returns the channel's ActivityPub Person, with its inbox, outbox, followers collection and the RSA key its deliveries are signed with
+// @Tags activitypub
+// @Produce json
+// @Param username path string true "Channel username"
+// @Success 200 {object} Actor "Actor"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Channel not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /ap/users/{username} [get]
+func (h *Handler) handleGetActor(c *gin.Context) {
+	actor, err := h.service.Actor(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve actor")
+		return
+	}
+	h.writeDocument(c, ContentType, actor)
+}
+
+// @Summary Get a channel's outbox
+// @Description Without page, returns the outbox collection with its total and links to the first and last pages. With page, returns that page of Create activities for the channel's public videos, newest first, 20 per page.
+// @Tags activitypub
+// @Produce json
+// @Param username path string true "Channel username"
+// @Param page query int false "Page number"
+// @Success 200 {object} OrderedCollectionPage "Outbox collection, or a page of it"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Channel not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /ap/users/{username}/outbox [get]
+func (h *Handler) handleGetOutbox(c *gin.Context) {
+	username := c.Param("username")
+	if c.Query("page") == "" {
+		outbox, err := h.service.Outbox(c.Request.Context(), username)
+		if err != nil {
+			h.handleServiceError(c, err, "Failed to retrieve outbox")
+			return
+		}
+		h.writeDocument(c, ContentType, outbox)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	outboxPage, err := h.service.OutboxPage(c.Request.Context(), username, page)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve outbox")
+		return
+	}
+	h.writeDocument(c, ContentType, outboxPage)
+}
+
+// @Summary Get a channel's followers collection
+// @Description Returns how many Fediverse actors follow the channel; the followers themselves are not listed
+// @Tags activitypub
+// @Produce json
+// @Param username path string true "Channel username"
+// @Success 200 {object} OrderedCollection "Followers collection"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Channel not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /ap/users/{username}/followers [get]
+func (h *Handler) handleGetFollowers(c *gin.Context) {
+	followers, err := h.service.Followers(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve followers")
+		return
+	}
+	h.writeDocument(c, ContentType, followers)
+}
+
+// @Summary Deliver an activity to a channel
+// @Description Accepts Follow and Undo of a Follow from Fediverse actors; a Follow is answered with an Accept delivered to the follower's inbox. The request must carry an HTTP signature (rsa-sha256) over (request-target), host, date and digest, made with the key of the activity's actor. Other activities are accepted and ignored.
+// @Tags activitypub
+// @Accept json
+// @Produce json
+// @Param username path string true "Channel username"
+// @Param activity body Activity true "Activity"
+// @Success 202 "Activity accepted"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Malformed activity"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Missing or invalid HTTP signature"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Channel not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /ap/users/{username}/inbox [post]
+func (h *Handler) handlePostInbox(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxDocumentBytes))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read activity", err)
+		return
+	}
+
+	if err := h.service.HandleInbox(c.Request.Context(), c.Param("username"), c.Request, body); err != nil {
+		h.handleServiceError(c, err, "Failed to handle activity")
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Get a video object
+// @Description Returns a public video as an ActivityStreams Video, the object of the Create activities in its channel's outbox
+// @Tags activitypub
+// @Produce json
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {object} Video "Video object"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video not found or not public"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /ap/videos/{id} [get]
+func (h *Handler) handleGetVideo(c *gin.Context) {
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.NotFoundResponse(c, "Video not found")
+		return
+	}
+
+	object, err := h.service.VideoObject(c.Request.Context(), videoID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve video")
+		return
+	}
+	h.writeDocument(c, ContentType, object)
+}
+
+// writeDocument writes a bare JSON document with the given media type,
+// outside the usual response envelope
+func (h *Handler) writeDocument(c *gin.Context, contentType string, document interface{}) {
+	body, err := json.Marshal(document)
+	if err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to encode document", err)
+		return
+	}
+	c.Data(http.StatusOK, contentType+"; charset=utf-8", body)
+}
+
+// handleServiceError maps ActivityPub service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrActorNotFound):
+		h.responseHandler.NotFoundResponse(c, "Channel not found")
+	case errors.Is(err, ErrVideoNotFound):
+		h.responseHandler.NotFoundResponse(c, "Video not found")
+	case errors.Is(err, ErrInvalidResource):
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", err.Error(), nil)
+	case errors.Is(err, ErrInvalidActivity):
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", err.Error(), nil)
+	case errors.Is(err, ErrBadSignature), errors.Is(err, ErrInvalidKey),
+		errors.Is(err, ErrUnreachable), errors.Is(err, ErrPrivateAddress):
+		h.responseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Could not verify the request's HTTP signature: "+err.Error(), nil)
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
@@ -0,0 +1,50 @@
+package activitypub
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrActorNotFound is returned when no active channel has the username
+	ErrActorNotFound = errors.New("actor not found")
+	// ErrVideoNotFound is returned when a video does not exist or is not public
+	ErrVideoNotFound = errors.New("video not found")
+	// ErrInvalidResource is returned when a WebFinger resource is not an acct: URI of this domain
+	ErrInvalidResource = errors.New("resource must be acct:username@domain of this server")
+	// ErrBadSignature is returned when an inbox request is unsigned or its HTTP signature does not verify
+	ErrBadSignature = errors.New("HTTP signature does not verify")
+	// ErrInvalidKey is returned when a remote actor publishes no usable RSA key
+	ErrInvalidKey = errors.New("actor key is not an RSA public key")
+	// ErrInvalidActivity is returned when an inbox activity is malformed or not sent by its signer
+	ErrInvalidActivity = errors.New("invalid activity")
+	// ErrUnreachable is returned when a remote actor cannot be fetched
+	ErrUnreachable = errors.New("remote actor unreachable")
+	// ErrPrivateAddress is returned when a remote URL resolves to a loopback,
+	// private or link-local address and private networks are not allowed
+	ErrPrivateAddress = errors.New("remote address is not publicly routable")
+)
+
+// Service defines the interface for publishing channels to the Fediverse
+type Service interface {
+	// WebFinger resolves acct:username@domain to a channel's actor
+	WebFinger(ctx context.Context, resource string) (*WebFinger, error)
+	// Actor returns a channel's actor document
+	Actor(ctx context.Context, username string) (*Actor, error)
+	// Outbox returns a channel's outbox collection, which links to its pages
+	Outbox(ctx context.Context, username string) (*OrderedCollection, error)
+	// OutboxPage returns a page of a channel's Create activities, newest first
+	OutboxPage(ctx context.Context, username string, page int) (*OrderedCollectionPage, error)
+	// Followers returns a channel's followers collection
+	Followers(ctx context.Context, username string) (*OrderedCollection, error)
+	// VideoObject returns a public video as an ActivityStreams object
+	VideoObject(ctx context.Context, videoID uuid.UUID) (*Video, error)
+	// HandleInbox verifies and applies an activity delivered to a channel's inbox
+	HandleInbox(ctx context.Context, username string, req *http.Request, body []byte) error
+	// AnnounceVideo delivers a newly published video to its channel's followers
+	AnnounceVideo(ctx context.Context, v *video.Video) error
+}
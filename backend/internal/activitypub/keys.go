@@ -0,0 +1,54 @@
+package activitypub
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// keyStore loads actor keys, generating a channel's key the first time it is
+// needed
+type keyStore struct {
+	db *gorm.DB
+}
+
+// get returns a channel's key pair
+func (k *keyStore) get(ctx context.Context, userID uuid.UUID) (*ActorKey, error) {
+	var key ActorKey
+	err := k.db.WithContext(ctx).Where("user_id = ?", userID).First(&key).Error
+	if err == nil {
+		return &key, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load actor key: %w", err)
+	}
+
+	privatePEM, publicPEM, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	key = ActorKey{UserID: userID, PrivateKeyPEM: privatePEM, PublicKeyPEM: publicPEM, CreatedAt: time.Now()}
+	// Two requests may generate a key at once; the first one stored wins
+	if err := k.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&key).Error; err != nil {
+		return nil, fmt.Errorf("failed to store actor key: %w", err)
+	}
+	if err := k.db.WithContext(ctx).Where("user_id = ?", userID).First(&key).Error; err != nil {
+		return nil, fmt.Errorf("failed to load actor key: %w", err)
+	}
+	return &key, nil
+}
+
+// private returns a channel's signing key
+func (k *keyStore) private(ctx context.Context, userID uuid.UUID) (*rsa.PrivateKey, error) {
+	key, err := k.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrivateKey(key.PrivateKeyPEM)
+}
@@ -0,0 +1,154 @@
+package activitypub
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContentType is the media type ActivityPub documents are served and
+// delivered with
+const ContentType = "application/activity+json"
+
+// ActorKey is the RSA key pair a channel's actor signs deliveries with,
+// generated the first time the actor is needed
+type ActorKey struct {
+	UserID        uuid.UUID `gorm:"type:uuid;primary_key"`
+	PrivateKeyPEM string    `gorm:"type:text;not null"`
+	PublicKeyPEM  string    `gorm:"type:text;not null"`
+	CreatedAt     time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName keeps the ActivityPub tables together
+func (ActorKey) TableName() string {
+	return "activitypub_actor_keys"
+}
+
+// Follower is a Fediverse actor following a channel
+type Follower struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_activitypub_followers_actor"`
+	// ActorID is the follower's actor URI
+	ActorID string `gorm:"type:text;not null;uniqueIndex:idx_activitypub_followers_actor"`
+	// Inbox is where activities for the follower are delivered; the shared
+	// inbox of its server when it has one
+	Inbox     string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName keeps the ActivityPub tables together
+func (Follower) TableName() string {
+	return "activitypub_followers"
+}
+
+// PublicKey is an actor's key as published in its actor document
+type PublicKey struct {
+	ID           string `json:"id"`
+	Owner        string `json:"owner"`
+	PublicKeyPem string `json:"publicKeyPem"`
+}
+
+// Endpoints lists an actor's server-wide endpoints
+type Endpoints struct {
+	SharedInbox string `json:"sharedInbox,omitempty"`
+}
+
+// Actor is the ActivityPub actor of a channel, or a remote actor as fetched
+// to verify its signatures and find its inbox
+type Actor struct {
+	Context           interface{} `json:"@context,omitempty" swaggertype:"array,string"`
+	ID                string      `json:"id"`
+	Type              string      `json:"type" example:"Person"`
+	PreferredUsername string      `json:"preferredUsername" example:"johndoe"`
+	Name              string      `json:"name,omitempty" example:"John Doe"`
+	Summary           string      `json:"summary,omitempty" example:"Filmmaker and open source enthusiast"`
+	URL               string      `json:"url,omitempty"`
+	Inbox             string      `json:"inbox"`
+	Outbox            string      `json:"outbox,omitempty"`
+	Followers         string      `json:"followers,omitempty"`
+	Endpoints         *Endpoints  `json:"endpoints,omitempty"`
+	PublicKey         PublicKey   `json:"publicKey"`
+	Published         *time.Time  `json:"published,omitempty"`
+}
+
+// Link is an ActivityStreams link to a representation of an object
+type Link struct {
+	Type      string `json:"type" example:"Link"`
+	MediaType string `json:"mediaType" example:"text/html"`
+	Href      string `json:"href"`
+}
+
+// Hashtag tags an object
+type Hashtag struct {
+	Type string `json:"type" example:"Hashtag"`
+	Name string `json:"name" example:"#golang"`
+}
+
+// Video is a published video as an ActivityStreams object
+type Video struct {
+	Context      interface{} `json:"@context,omitempty" swaggertype:"array,string"`
+	ID           string      `json:"id"`
+	Type         string      `json:"type" example:"Video"`
+	AttributedTo string      `json:"attributedTo"`
+	Name         string      `json:"name" example:"Intro to Go"`
+	Content      string      `json:"content,omitempty"`
+	// Duration is an ISO 8601 duration, e.g. PT754S
+	Duration  string    `json:"duration,omitempty" example:"PT754S"`
+	URL       []Link    `json:"url,omitempty"`
+	Tag       []Hashtag `json:"tag,omitempty"`
+	To        []string  `json:"to"`
+	Cc        []string  `json:"cc,omitempty"`
+	Published time.Time `json:"published"`
+	Updated   time.Time `json:"updated"`
+}
+
+// Activity is an ActivityStreams activity. Object is a nested object, or the
+// URI of one, as received.
+type Activity struct {
+	Context   interface{} `json:"@context,omitempty" swaggertype:"array,string"`
+	ID        string      `json:"id"`
+	Type      string      `json:"type" example:"Create"`
+	Actor     string      `json:"actor"`
+	Object    interface{} `json:"object" swaggertype:"object"`
+	To        []string    `json:"to,omitempty"`
+	Cc        []string    `json:"cc,omitempty"`
+	Published *time.Time  `json:"published,omitempty"`
+}
+
+// OrderedCollection is a channel's outbox or followers collection. The
+// outbox links to its pages; the followers collection only has a count.
+type OrderedCollection struct {
+	Context    interface{} `json:"@context,omitempty" swaggertype:"array,string"`
+	ID         string      `json:"id"`
+	Type       string      `json:"type" example:"OrderedCollection"`
+	TotalItems int64       `json:"totalItems" example:"12"`
+	First      string      `json:"first,omitempty"`
+	Last       string      `json:"last,omitempty"`
+}
+
+// OrderedCollectionPage is a page of a channel's outbox, newest first
+type OrderedCollectionPage struct {
+	Context      interface{} `json:"@context,omitempty" swaggertype:"array,string"`
+	ID           string      `json:"id"`
+	Type         string      `json:"type" example:"OrderedCollectionPage"`
+	PartOf       string      `json:"partOf"`
+	Next         string      `json:"next,omitempty"`
+	Prev         string      `json:"prev,omitempty"`
+	TotalItems   int64       `json:"totalItems" example:"12"`
+	OrderedItems []Activity  `json:"orderedItems"`
+}
+
+// WebFingerLink is a link of a WebFinger resource
+type WebFingerLink struct {
+	Rel  string `json:"rel" example:"self"`
+	Type string `json:"type,omitempty" example:"application/activity+json"`
+	Href string `json:"href"`
+}
+
+// WebFinger is the JSON resource descriptor mapping acct:user@domain to a
+// channel's actor
+type WebFinger struct {
+	Subject string          `json:"subject" example:"acct:johndoe@pavilion.example.com"`
+	Aliases []string        `json:"aliases,omitempty"`
+	Links   []WebFingerLink `json:"links"`
+}
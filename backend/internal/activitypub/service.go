@@ -0,0 +1,522 @@
+package activitypub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// publicAddress addresses an activity to everyone
+const publicAddress = "https://www.w3.org/ns/activitystreams#Public"
+
+// outboxPageSize is how many activities an outbox page holds
+const outboxPageSize = 20
+
+// activityContext is the JSON-LD context of served documents; the security
+// vocabulary defines publicKey
+var activityContext = []string{"https://www.w3.org/ns/activitystreams", "https://w3id.org/security/v1"}
+
+// Config represents how channels are published to the Fediverse
+type Config struct {
+	// PublicURL is the public base URL of the API, under which actors live
+	PublicURL string
+	// WatchURL is the page a video is watched on, with {id} replaced by the
+	// video ID; videos link no page when empty
+	WatchURL string
+	// MaxClockSkew bounds how far the Date of a signed inbox request may be from now
+	MaxClockSkew time.Duration
+	// Timeout bounds each request fetching a remote actor
+	Timeout time.Duration
+	// AllowPrivateNetworks permits fetching actors on loopback and private
+	// addresses; only for development
+	AllowPrivateNetworks bool
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db        *gorm.DB
+	config    Config
+	domain    string
+	client    *http.Client
+	keys      *keyStore
+	deliverer *Deliverer
+	logger    logger.Logger
+}
+
+// NewService creates a new ActivityPub service. Activities for remote
+// followers are queued on the deliverer; with a nil deliverer, follows are
+// recorded but nothing is delivered.
+func NewService(db *gorm.DB, config Config, deliverer *Deliverer, logger logger.Logger) Service {
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+	if config.MaxClockSkew <= 0 {
+		config.MaxClockSkew = time.Hour
+	}
+	domain := ""
+	if u, err := url.Parse(config.PublicURL); err == nil {
+		domain = u.Host
+	}
+	return &serviceImpl{
+		db:        db,
+		config:    config,
+		domain:    domain,
+		client:    newClient(config.Timeout, config.AllowPrivateNetworks),
+		keys:      &keyStore{db: db},
+		deliverer: deliverer,
+		logger:    logger,
+	}
+}
+
+// actorURL returns the actor ID of a channel
+func (s *serviceImpl) actorURL(username string) string {
+	return s.config.PublicURL + "/ap/users/" + url.PathEscape(username)
+}
+
+// videoURL returns the object ID of a video
+func (s *serviceImpl) videoURL(videoID uuid.UUID) string {
+	return s.config.PublicURL + "/ap/videos/" + videoID.String()
+}
+
+// WebFinger resolves acct:username@domain to a channel's actor
+func (s *serviceImpl) WebFinger(ctx context.Context, resource string) (*WebFinger, error) {
+	account, ok := strings.CutPrefix(resource, "acct:")
+	if !ok {
+		return nil, ErrInvalidResource
+	}
+	username, domain, ok := strings.Cut(account, "@")
+	if !ok || username == "" || !strings.EqualFold(domain, s.domain) {
+		return nil, ErrInvalidResource
+	}
+
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	actorURL := s.actorURL(user.Username)
+	return &WebFinger{
+		Subject: "acct:" + user.Username + "@" + s.domain,
+		Aliases: []string{actorURL},
+		Links:   []WebFingerLink{{Rel: "self", Type: ContentType, Href: actorURL}},
+	}, nil
+}
+
+// Actor returns a channel's actor document, generating its key the first
+// time it is requested
+func (s *serviceImpl) Actor(ctx context.Context, username string) (*Actor, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.keys.get(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	actorURL := s.actorURL(user.Username)
+	published := user.CreatedAt
+	return &Actor{
+		Context:           activityContext,
+		ID:                actorURL,
+		Type:              "Person",
+		PreferredUsername: user.Username,
+		Name:              user.Name,
+		Summary:           html.EscapeString(user.Bio),
+		Inbox:             actorURL + "/inbox",
+		Outbox:            actorURL + "/outbox",
+		Followers:         actorURL + "/followers",
+		PublicKey: PublicKey{
+			ID:           actorURL + "#main-key",
+			Owner:        actorURL,
+			PublicKeyPem: key.PublicKeyPEM,
+		},
+		Published: &published,
+	}, nil
+}
+
+// Outbox returns a channel's outbox collection, which links to its pages
+func (s *serviceImpl) Outbox(ctx context.Context, username string) (*OrderedCollection, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	if err := s.publicVideos(ctx, user.ID).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count outbox videos: %w", err)
+	}
+
+	outboxURL := s.actorURL(user.Username) + "/outbox"
+	pages := int((total + outboxPageSize - 1) / outboxPageSize)
+	if pages < 1 {
+		pages = 1
+	}
+	return &OrderedCollection{
+		Context:    activityContext,
+		ID:         outboxURL,
+		Type:       "OrderedCollection",
+		TotalItems: total,
+		First:      fmt.Sprintf("%s?page=1", outboxURL),
+		Last:       fmt.Sprintf("%s?page=%d", outboxURL, pages),
+	}, nil
+}
+
+// OutboxPage returns a page of a channel's Create activities, newest first
+func (s *serviceImpl) OutboxPage(ctx context.Context, username string, page int) (*OrderedCollectionPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := s.publicVideos(ctx, user.ID).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count outbox videos: %w", err)
+	}
+	var videos []video.Video
+	if err := s.publicVideos(ctx, user.ID).Preload("Tags").
+		Order("videos.created_at DESC, videos.id DESC").
+		Offset((page - 1) * outboxPageSize).Limit(outboxPageSize).
+		Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to list outbox videos: %w", err)
+	}
+
+	outboxURL := s.actorURL(user.Username) + "/outbox"
+	result := &OrderedCollectionPage{
+		Context:      activityContext,
+		ID:           fmt.Sprintf("%s?page=%d", outboxURL, page),
+		Type:         "OrderedCollectionPage",
+		PartOf:       outboxURL,
+		TotalItems:   total,
+		OrderedItems: make([]Activity, 0, len(videos)),
+	}
+	if int64(page*outboxPageSize) < total {
+		result.Next = fmt.Sprintf("%s?page=%d", outboxURL, page+1)
+	}
+	if page > 1 {
+		result.Prev = fmt.Sprintf("%s?page=%d", outboxURL, page-1)
+	}
+	for i := range videos {
+		result.OrderedItems = append(result.OrderedItems, s.createActivity(user.Username, &videos[i], false))
+	}
+	return result, nil
+}
+
+// Followers returns a channel's followers collection. Only the count is
+// published; who follows a channel stays private.
+func (s *serviceImpl) Followers(ctx context.Context, username string) (*OrderedCollection, error) {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	if err := s.db.WithContext(ctx).Model(&Follower{}).Where("user_id = ?", user.ID).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count followers: %w", err)
+	}
+	return &OrderedCollection{
+		Context:    activityContext,
+		ID:         s.actorURL(user.Username) + "/followers",
+		Type:       "OrderedCollection",
+		TotalItems: total,
+	}, nil
+}
+
+// VideoObject returns a public video as an ActivityStreams object
+func (s *serviceImpl) VideoObject(ctx context.Context, videoID uuid.UUID) (*Video, error) {
+	var v video.Video
+	err := s.db.WithContext(ctx).Model(&video.Video{}).Preload("Tags").
+		Joins("JOIN video_uploads ON video_uploads.video_id = videos.id").
+		Where("videos.id = ? AND video_uploads.status = ?", videoID, video.UploadStatusCompleted).
+		First(&v).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !public(&v)) {
+		return nil, ErrVideoNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	var user auth.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND active = ?", v.UserID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video owner: %w", err)
+	}
+
+	object := s.videoObject(user.Username, &v)
+	object.Context = activityContext
+	return &object, nil
+}
+
+// HandleInbox verifies the HTTP signature of an activity delivered to a
+// channel's inbox and applies it. Follow and Undo of a Follow are handled;
+// other activities are accepted and ignored.
+func (s *serviceImpl) HandleInbox(ctx context.Context, username string, req *http.Request, body []byte) error {
+	user, err := s.getUser(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	signer, err := s.verify(ctx, req, body)
+	if err != nil {
+		return err
+	}
+
+	var activity Activity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidActivity, err)
+	}
+	if activity.Actor != signer.ID {
+		return fmt.Errorf("%w: activity is not from the actor that signed it", ErrInvalidActivity)
+	}
+
+	switch activity.Type {
+	case "Follow":
+		if objectID(activity.Object) != s.actorURL(user.Username) {
+			return fmt.Errorf("%w: Follow is not for this actor", ErrInvalidActivity)
+		}
+		return s.follow(ctx, user, signer, &activity)
+	case "Undo":
+		if objectType(activity.Object) != "Follow" {
+			return nil
+		}
+		if err := s.db.WithContext(ctx).Where("user_id = ? AND actor_id = ?", user.ID, signer.ID).Delete(&Follower{}).Error; err != nil {
+			return fmt.Errorf("failed to remove follower: %w", err)
+		}
+		s.logger.LogInfo("Fediverse actor unfollowed channel", map[string]interface{}{
+			"user_id": user.ID,
+			"actor":   signer.ID,
+		})
+		return nil
+	default:
+		return nil
+	}
+}
+
+// verify checks the HTTP signature of an inbox request and returns the actor
+// whose key signed it
+func (s *serviceImpl) verify(ctx context.Context, req *http.Request, body []byte) (*Actor, error) {
+	sig, err := parseSignature(req.Header.Get("Signature"))
+	if err != nil {
+		return nil, err
+	}
+	signer, err := fetchActor(ctx, s.client, sig.keyID)
+	if err != nil {
+		return nil, err
+	}
+	if signer.PublicKey.ID != sig.keyID || !sameOrigin(sig.keyID, signer.ID) ||
+		(signer.PublicKey.Owner != "" && signer.PublicKey.Owner != signer.ID) {
+		return nil, fmt.Errorf("%w: key %s is not published by its actor", ErrBadSignature, sig.keyID)
+	}
+	key, err := parsePublicKey(signer.PublicKey.PublicKeyPem)
+	if err != nil {
+		return nil, err
+	}
+	if err := sig.verify(req, body, key, s.config.MaxClockSkew); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// follow records a follower and accepts the Follow
+func (s *serviceImpl) follow(ctx context.Context, user *auth.User, follower *Actor, activity *Activity) error {
+	inbox := follower.Inbox
+	if follower.Endpoints != nil && follower.Endpoints.SharedInbox != "" {
+		inbox = follower.Endpoints.SharedInbox
+	}
+	record := Follower{ID: uuid.New(), UserID: user.ID, ActorID: follower.ID, Inbox: inbox, CreatedAt: time.Now()}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "actor_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"inbox"}),
+	}).Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to record follower: %w", err)
+	}
+	s.logger.LogInfo("Fediverse actor followed channel", map[string]interface{}{
+		"user_id": user.ID,
+		"actor":   follower.ID,
+	})
+
+	actorURL := s.actorURL(user.Username)
+	accept := Activity{
+		Context: activityContext,
+		ID:      actorURL + "#accepts/follows/" + record.ID.String(),
+		Type:    "Accept",
+		Actor:   actorURL,
+		Object:  activity,
+	}
+	s.deliver(user, follower.Inbox, accept)
+	return nil
+}
+
+// AnnounceVideo delivers a Create activity for a newly published video to
+// the inboxes of its channel's followers. Videos that are not public are
+// skipped.
+func (s *serviceImpl) AnnounceVideo(ctx context.Context, v *video.Video) error {
+	if s.deliverer == nil || !public(v) {
+		return nil
+	}
+
+	var user auth.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND active = ?", v.UserID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get video owner: %w", err)
+	}
+
+	var inboxes []string
+	if err := s.db.WithContext(ctx).Model(&Follower{}).Where("user_id = ?", user.ID).
+		Distinct().Pluck("inbox", &inboxes).Error; err != nil {
+		return fmt.Errorf("failed to list follower inboxes: %w", err)
+	}
+	if len(inboxes) == 0 {
+		return nil
+	}
+
+	if len(v.Tags) == 0 {
+		if err := s.db.WithContext(ctx).Model(v).Association("Tags").Find(&v.Tags); err != nil {
+			return fmt.Errorf("failed to load video tags: %w", err)
+		}
+	}
+	activity := s.createActivity(user.Username, v, true)
+	for _, inbox := range inboxes {
+		s.deliver(&user, inbox, activity)
+	}
+	return nil
+}
+
+// deliver queues an activity from a channel for an inbox
+func (s *serviceImpl) deliver(user *auth.User, inbox string, activity Activity) {
+	if s.deliverer == nil {
+		return
+	}
+	body, err := json.Marshal(activity)
+	if err != nil {
+		s.logger.LogError(err, "Failed to encode ActivityPub activity")
+		return
+	}
+	s.deliverer.enqueue(delivery{
+		userID: user.ID,
+		keyID:  s.actorURL(user.Username) + "#main-key",
+		inbox:  inbox,
+		body:   body,
+	})
+}
+
+// createActivity wraps a video in the Create activity that published it.
+// Delivered activities carry the JSON-LD context; outbox items inherit the
+// page's.
+func (s *serviceImpl) createActivity(username string, v *video.Video, withContext bool) Activity {
+	object := s.videoObject(username, v)
+	activity := Activity{
+		ID:        object.ID + "/activity",
+		Type:      "Create",
+		Actor:     object.AttributedTo,
+		Object:    object,
+		To:        object.To,
+		Cc:        object.Cc,
+		Published: &object.Published,
+	}
+	if withContext {
+		activity.Context = activityContext
+	}
+	return activity
+}
+
+// videoObject returns the ActivityStreams object of a video
+func (s *serviceImpl) videoObject(username string, v *video.Video) Video {
+	actorURL := s.actorURL(username)
+	object := Video{
+		ID:           s.videoURL(v.ID),
+		Type:         "Video",
+		AttributedTo: actorURL,
+		Name:         v.Title,
+		To:           []string{publicAddress},
+		Cc:           []string{actorURL + "/followers"},
+		Published:    v.CreatedAt,
+		Updated:      v.UpdatedAt,
+	}
+	if v.Description != "" {
+		object.Content = "<p>" + strings.ReplaceAll(html.EscapeString(v.Description), "\n", "<br>") + "</p>"
+	}
+	if v.Duration > 0 {
+		object.Duration = fmt.Sprintf("PT%dS", int64(math.Round(v.Duration)))
+	}
+	if s.config.WatchURL != "" {
+		object.URL = append(object.URL, Link{
+			Type:      "Link",
+			MediaType: "text/html",
+			Href:      strings.ReplaceAll(s.config.WatchURL, "{id}", v.ID.String()),
+		})
+	}
+	if v.Replication == video.ReplicationReplicated && v.IPFSCID != "" {
+		object.URL = append(object.URL, Link{Type: "Link", MediaType: "video/mp4", Href: "ipfs://" + v.IPFSCID})
+	}
+	for _, tag := range v.Tags {
+		object.Tag = append(object.Tag, Hashtag{Type: "Hashtag", Name: "#" + tag.Name})
+	}
+	return object
+}
+
+// publicVideos returns the query of a channel's videos that are published
+// to the Fediverse
+func (s *serviceImpl) publicVideos(ctx context.Context, userID uuid.UUID) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&video.Video{}).
+		Joins("JOIN video_uploads ON video_uploads.video_id = videos.id").
+		Where("videos.user_id = ? AND video_uploads.status = ?", userID, video.UploadStatusCompleted).
+		Where("videos.requires_entitlement = ?", false).
+		Where("videos.scan_status NOT IN ?", []video.ScanStatus{video.ScanQuarantined, video.ScanBlocked}).
+		Where("videos.taken_down_at IS NULL")
+}
+
+// public reports whether a video is published to the Fediverse
+func public(v *video.Video) bool {
+	return !v.DeletedAt.Valid && !v.RequiresEntitlement && !v.ScanStatus.Hidden() && v.TakenDownAt == nil
+}
+
+// getUser loads an active channel by username
+func (s *serviceImpl) getUser(ctx context.Context, username string) (*auth.User, error) {
+	var user auth.User
+	if err := s.db.WithContext(ctx).Where("username = ? AND active = ?", username, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrActorNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// objectID returns the ID of an activity's object, given inline or by URI
+func objectID(object interface{}) string {
+	switch o := object.(type) {
+	case string:
+		return o
+	case map[string]interface{}:
+		id, _ := o["id"].(string)
+		return id
+	default:
+		return ""
+	}
+}
+
+// objectType returns the type of an inline object
+func objectType(object interface{}) string {
+	if o, ok := object.(map[string]interface{}); ok {
+		t, _ := o["type"].(string)
+		return t
+	}
+	return ""
+}
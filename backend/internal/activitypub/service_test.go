@@ -0,0 +1,249 @@
+package activitypub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedRequest returns an inbox request signed with a new key, and the
+// public key to verify it with
+func signedRequest(t *testing.T, body []byte) (*http.Request, string) {
+	t.Helper()
+	privatePEM, publicPEM, err := generateKey()
+	require.NoError(t, err)
+	key, err := parsePrivateKey(privatePEM)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "https://pavilion.example.com/api/v1/ap/users/johndoe/inbox", bytes.NewReader(body))
+	require.NoError(t, signRequest(req, "https://remote.example/users/alice#main-key", key, body))
+	return req, publicPEM
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"Follow"}`)
+	req, publicPEM := signedRequest(t, body)
+	public, err := parsePublicKey(publicPEM)
+	require.NoError(t, err)
+
+	sig, err := parseSignature(req.Header.Get("Signature"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote.example/users/alice#main-key", sig.keyID)
+	assert.Equal(t, []string{"(request-target)", "host", "date", "digest"}, sig.headers)
+	assert.NoError(t, sig.verify(req, body, public, time.Hour))
+
+	assert.ErrorIs(t, sig.verify(req, []byte(`{"type":"Undo"}`), public, time.Hour), ErrBadSignature)
+
+	_, otherPEM := signedRequest(t, body)
+	other, err := parsePublicKey(otherPEM)
+	require.NoError(t, err)
+	assert.ErrorIs(t, sig.verify(req, body, other, time.Hour), ErrBadSignature)
+
+	req.Header.Set("Date", time.Now().Add(-2*time.Hour).UTC().Format(http.TimeFormat))
+	assert.ErrorIs(t, sig.verify(req, body, public, time.Hour), ErrBadSignature)
+}
+
+func TestVerifyRequiresSignedHeaders(t *testing.T) {
+	body := []byte(`{"type":"Follow"}`)
+	req, publicPEM := signedRequest(t, body)
+	public, err := parsePublicKey(publicPEM)
+	require.NoError(t, err)
+	sig, err := parseSignature(req.Header.Get("Signature"))
+	require.NoError(t, err)
+
+	sig.headers = []string{"(request-target)", "host", "date"}
+	assert.ErrorIs(t, sig.verify(req, body, public, time.Hour), ErrBadSignature)
+	sig.headers = []string{"date", "digest"}
+	assert.ErrorIs(t, sig.verify(req, body, public, time.Hour), ErrBadSignature)
+	sig.algorithm = "hmac-sha256"
+	assert.ErrorIs(t, sig.verify(req, body, public, time.Hour), ErrBadSignature)
+}
+
+func TestParseSignature(t *testing.T) {
+	sig, err := parseSignature(`keyId="https://remote.example/actor#key", algorithm="hs2019", signature="c2ln"`)
+	require.NoError(t, err)
+	assert.Equal(t, "https://remote.example/actor#key", sig.keyID)
+	assert.Equal(t, "hs2019", sig.algorithm)
+	assert.Equal(t, []string{"date"}, sig.headers)
+	assert.Equal(t, []byte("sig"), sig.value)
+
+	for _, header := range []string{
+		"",
+		`algorithm="rsa-sha256",signature="c2ln"`,
+		`keyId="key",signature="not base64!"`,
+		`keyId="key",signature="c2ln`,
+		`keyId=key`,
+	} {
+		_, err := parseSignature(header)
+		assert.ErrorIs(t, err, ErrBadSignature, header)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	_, err := parsePublicKey("not a key")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = parsePublicKey("-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestWebFingerRejectsOtherResources(t *testing.T) {
+	service := NewService(nil, Config{PublicURL: "https://pavilion.example.com/api/v1/"}, nil, nil)
+	for _, resource := range []string{
+		"",
+		"johndoe@pavilion.example.com",
+		"acct:johndoe",
+		"acct:@pavilion.example.com",
+		"acct:johndoe@remote.example",
+		"https://pavilion.example.com/api/v1/ap/users/johndoe",
+	} {
+		_, err := service.WebFinger(context.Background(), resource)
+		assert.ErrorIs(t, err, ErrInvalidResource, resource)
+	}
+}
+
+func TestCreateActivity(t *testing.T) {
+	service := NewService(nil, Config{
+		PublicURL: "https://pavilion.example.com/api/v1/",
+		WatchURL:  "https://pavilion.example.com/watch/{id}",
+	}, nil, nil).(*serviceImpl)
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	v := &video.Video{
+		ID:          uuid.MustParse("5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"),
+		Title:       "Intro to Go",
+		Description: "Part <1>\nBasics",
+		Duration:    125.6,
+		IPFSCID:     "bafybeigdyrzt",
+		Replication: video.ReplicationReplicated,
+		Tags:        []video.Tag{{Name: "golang"}},
+		CreatedAt:   created,
+		UpdatedAt:   created,
+	}
+
+	activity := service.createActivity("johndoe", v, true)
+	actor := "https://pavilion.example.com/api/v1/ap/users/johndoe"
+	objectURL := "https://pavilion.example.com/api/v1/ap/videos/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"
+	assert.Equal(t, objectURL+"/activity", activity.ID)
+	assert.Equal(t, "Create", activity.Type)
+	assert.Equal(t, actor, activity.Actor)
+	assert.Equal(t, []string{publicAddress}, activity.To)
+	assert.Equal(t, []string{actor + "/followers"}, activity.Cc)
+	assert.NotNil(t, activity.Context)
+
+	object := activity.Object.(Video)
+	assert.Equal(t, objectURL, object.ID)
+	assert.Equal(t, actor, object.AttributedTo)
+	assert.Equal(t, "Intro to Go", object.Name)
+	assert.Equal(t, "<p>Part &lt;1&gt;<br>Basics</p>", object.Content)
+	assert.Equal(t, "PT126S", object.Duration)
+	assert.Equal(t, []Link{
+		{Type: "Link", MediaType: "text/html", Href: "https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"},
+		{Type: "Link", MediaType: "video/mp4", Href: "ipfs://bafybeigdyrzt"},
+	}, object.URL)
+	assert.Equal(t, []Hashtag{{Type: "Hashtag", Name: "#golang"}}, object.Tag)
+
+	// Outbox pages carry the context once, at the top
+	assert.Nil(t, service.createActivity("johndoe", v, false).Context)
+}
+
+func TestObjectIDAndType(t *testing.T) {
+	assert.Equal(t, "https://remote.example/follows/1", objectID("https://remote.example/follows/1"))
+	assert.Equal(t, "https://remote.example/follows/1", objectID(map[string]interface{}{"id": "https://remote.example/follows/1", "type": "Follow"}))
+	assert.Equal(t, "", objectID(42))
+	assert.Equal(t, "Follow", objectType(map[string]interface{}{"type": "Follow"}))
+	assert.Equal(t, "", objectType("https://remote.example/follows/1"))
+}
+
+func TestFetchActor(t *testing.T) {
+	var accept string
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		id := server.URL + r.URL.Path
+		switch r.URL.Path {
+		case "/users/alice":
+		case "/users/mallory":
+			id = "https://mastodon.social/users/alice"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(Actor{
+			ID:        id,
+			Type:      "Person",
+			Inbox:     id + "/inbox",
+			PublicKey: PublicKey{ID: id + "#main-key"},
+		})
+	}))
+	defer server.Close()
+
+	actor, err := fetchActor(context.Background(), newClient(time.Second, true), server.URL+"/users/alice#main-key")
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/users/alice/inbox", actor.Inbox)
+	assert.Contains(t, accept, ContentType)
+
+	_, err = fetchActor(context.Background(), newClient(time.Second, true), server.URL+"/users/bob")
+	assert.ErrorIs(t, err, ErrUnreachable)
+	_, err = fetchActor(context.Background(), newClient(time.Second, true), "ftp://remote.example/users/alice")
+	assert.ErrorIs(t, err, ErrInvalidActivity)
+
+	// A document claiming another server's actor is refused
+	_, err = fetchActor(context.Background(), newClient(time.Second, true), server.URL+"/users/mallory")
+	assert.ErrorIs(t, err, ErrBadSignature)
+
+	// Without allowing private networks, the loopback test server is refused
+	_, err = fetchActor(context.Background(), newClient(time.Second, false), server.URL+"/users/alice")
+	assert.Error(t, err)
+}
+
+func TestVerifyRejectsSpoofedActor(t *testing.T) {
+	privatePEM, publicPEM, err := generateKey()
+	require.NoError(t, err)
+	key, err := parsePrivateKey(privatePEM)
+	require.NoError(t, err)
+
+	// The server publishes its own key, but under the id of an actor it
+	// does not host
+	spoofed := "https://mastodon.social/users/alice"
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := server.URL + r.URL.Path
+		if r.URL.Path == "/users/mallory" {
+			id = spoofed
+		}
+		json.NewEncoder(w).Encode(Actor{
+			ID:    id,
+			Type:  "Person",
+			Inbox: id + "/inbox",
+			PublicKey: PublicKey{
+				ID:           server.URL + r.URL.Path + "#main-key",
+				Owner:        id,
+				PublicKeyPem: publicPEM,
+			},
+		})
+	}))
+	defer server.Close()
+
+	s := &serviceImpl{config: Config{MaxClockSkew: time.Hour}, client: newClient(time.Second, true)}
+	verify := func(keyID string) (*Actor, error) {
+		body := []byte(`{"type":"Follow","actor":"` + spoofed + `"}`)
+		req := httptest.NewRequest(http.MethodPost, "https://pavilion.example.com/api/v1/ap/users/johndoe/inbox", bytes.NewReader(body))
+		require.NoError(t, signRequest(req, keyID, key, body))
+		return s.verify(context.Background(), req, body)
+	}
+
+	signer, err := verify(server.URL + "/users/alice#main-key")
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/users/alice", signer.ID)
+
+	_, err = verify(server.URL + "/users/mallory#main-key")
+	assert.ErrorIs(t, err, ErrBadSignature)
+}
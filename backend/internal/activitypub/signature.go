@@ -0,0 +1,241 @@
+package activitypub
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// keyBits is the size of the RSA keys generated for actors, the size
+// Mastodon and PeerTube use
+const keyBits = 2048
+
+// generateKey returns a new actor key pair as PEM
+func generateKey() (privatePEM, publicPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, keyBits)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate actor key: %w", err)
+	}
+	private, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode actor key: %w", err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode actor public key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})), nil
+}
+
+// parsePrivateKey decodes a stored actor key
+func parsePrivateKey(encoded string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, fmt.Errorf("actor key is not PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse actor key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("actor key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// parsePublicKey decodes the PEM key of a remote actor, in either the PKIX
+// form most servers publish or PKCS #1
+func parsePublicKey(encoded string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, ErrInvalidKey
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		return key, nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	return rsaKey, nil
+}
+
+// digest returns the Digest header value of body
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// signRequest signs req with an actor's key, following the HTTP Signatures
+// draft the Fediverse uses: the request target, host and date are signed,
+// and the digest of the body when there is one
+func signRequest(req *http.Request, keyID string, key *rsa.PrivateKey, body []byte) error {
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	headers := []string{"(request-target)", "host", "date"}
+	if body != nil {
+		req.Header.Set("Digest", digest(body))
+		headers = append(headers, "digest")
+	}
+
+	signing, err := signingString(req, headers)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(signing))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	req.Header.Set("Signature", fmt.Sprintf(`keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(signature)))
+	return nil
+}
+
+// signingString returns the string signed over the given headers of req
+func signingString(req *http.Request, headers []string) (string, error) {
+	lines := make([]string, 0, len(headers))
+	for _, name := range headers {
+		switch name {
+		case "(request-target)":
+			lines = append(lines, "(request-target): "+strings.ToLower(req.Method)+" "+req.URL.RequestURI())
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			lines = append(lines, "host: "+host)
+		default:
+			values := req.Header.Values(name)
+			if len(values) == 0 {
+				return "", fmt.Errorf("%w: signed header %s is missing", ErrBadSignature, name)
+			}
+			lines = append(lines, name+": "+strings.Join(values, ", "))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// signature is a parsed Signature header
+type signature struct {
+	keyID     string
+	algorithm string
+	headers   []string
+	value     []byte
+}
+
+// parseSignature parses a Signature header of comma-separated key="value"
+// parameters
+func parseSignature(header string) (*signature, error) {
+	params := make(map[string]string)
+	rest := strings.TrimSpace(header)
+	for rest != "" {
+		name, after, ok := strings.Cut(rest, `="`)
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed Signature header", ErrBadSignature)
+		}
+		value, after, ok := strings.Cut(after, `"`)
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed Signature header", ErrBadSignature)
+		}
+		params[strings.ToLower(strings.TrimSpace(name))] = value
+		rest = strings.TrimPrefix(strings.TrimSpace(after), ",")
+		rest = strings.TrimSpace(rest)
+	}
+
+	sig := &signature{keyID: params["keyid"], algorithm: strings.ToLower(params["algorithm"])}
+	if sig.keyID == "" {
+		return nil, fmt.Errorf("%w: Signature header has no keyId", ErrBadSignature)
+	}
+	if headers := params["headers"]; headers != "" {
+		sig.headers = strings.Fields(strings.ToLower(headers))
+	} else {
+		sig.headers = []string{"date"}
+	}
+	value, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil || len(value) == 0 {
+		return nil, fmt.Errorf("%w: Signature header has no valid signature", ErrBadSignature)
+	}
+	sig.value = value
+	return sig, nil
+}
+
+// covers reports whether the signature covers a header
+func (s *signature) covers(header string) bool {
+	for _, h := range s.headers {
+		if h == header {
+			return true
+		}
+	}
+	return false
+}
+
+// verify checks a parsed signature of req against the signer's key. The
+// request target, host and date must be signed, and the digest of the body
+// when there is one; the date must be within maxSkew of now.
+func (s *signature) verify(req *http.Request, body []byte, key *rsa.PublicKey, maxSkew time.Duration) error {
+	if s.algorithm != "" && s.algorithm != "rsa-sha256" && s.algorithm != "hs2019" {
+		return fmt.Errorf("%w: unsupported algorithm %s", ErrBadSignature, s.algorithm)
+	}
+	required := []string{"(request-target)", "host", "date"}
+	if len(body) > 0 {
+		required = append(required, "digest")
+	}
+	for _, header := range required {
+		if !s.covers(header) {
+			return fmt.Errorf("%w: %s is not signed", ErrBadSignature, header)
+		}
+	}
+
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("%w: invalid Date header", ErrBadSignature)
+	}
+	if skew := time.Since(date); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("%w: Date is more than %s off", ErrBadSignature, maxSkew)
+	}
+	if len(body) > 0 && !digestMatches(req.Header.Get("Digest"), body) {
+		return fmt.Errorf("%w: Digest does not match the body", ErrBadSignature)
+	}
+
+	signing, err := signingString(req, s.headers)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256([]byte(signing))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], s.value); err != nil {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// digestMatches reports whether a Digest header carries the SHA-256 digest
+// of body among its comma-separated values
+func digestMatches(header string, body []byte) bool {
+	want := digest(body)
+	for _, value := range strings.Split(header, ",") {
+		algorithm, encoded, ok := strings.Cut(strings.TrimSpace(value), "=")
+		if ok && strings.EqualFold(algorithm, "SHA-256") && "SHA-256="+encoded == want {
+			return true
+		}
+	}
+	return false
+}
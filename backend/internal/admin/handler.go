@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for the admin dashboard
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new admin dashboard handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the dashboard routes behind the authentication
+// and admin role middlewares
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, adminMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware, adminMiddleware)
+	{
+		admin.GET("/stats", h.handleGetStats)
+		admin.GET("/transcodes/failed", h.handleListFailedTranscodes)
+	}
+}
+
+// @Summary Get dashboard statistics
+// @Description User counts, uploads per day, storage taken by original files per backend and the notification backlog. Days without uploads are listed with zero counts. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Days of uploads and sign-ins to report (default: 30, max: 365)"
+// @Success 200 {object} httpHandler.APIResponse{data=Stats} "Statistics retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/stats [get]
+func (h *Handler) handleGetStats(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+	stats, err := h.service.Stats(c.Request.Context(), days)
+	if err != nil {
+		h.logger.LogError(err, "Failed to compute dashboard statistics")
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to compute dashboard statistics", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, stats, "Statistics retrieved successfully")
+}
+
+// @Summary List failed transcodes
+// @Description Get a page of uploads that failed or could not produce every rendition, most recently updated first, with the reason for each missing rendition. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=FailedTranscodeListResponse} "Failed transcodes retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/transcodes/failed [get]
+func (h *Handler) handleListFailedTranscodes(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, err := h.service.ListFailedTranscodes(c.Request.Context(), page, limit)
+	if err != nil {
+		h.logger.LogError(err, "Failed to retrieve failed transcodes")
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve failed transcodes", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Failed transcodes retrieved successfully")
+}
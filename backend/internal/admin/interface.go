@@ -0,0 +1,21 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+)
+
+// Service defines the interface for the operator dashboard
+type Service interface {
+	// Stats aggregates users, uploads per day over the last days, storage per
+	// backend and the notification backlog
+	Stats(ctx context.Context, days int) (*Stats, error)
+	// ListFailedTranscodes returns uploads that failed or lost renditions, most recent first
+	ListFailedTranscodes(ctx context.Context, page, limit int) (*FailedTranscodeListResponse, error)
+}
+
+// NotificationStats reports the state of the notification pipeline
+type NotificationStats interface {
+	Snapshot() []notification.TopicStats
+}
@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+// Stats is the operator dashboard summary
+type Stats struct {
+	Users         UserStats           `json:"users"`
+	Uploads       []DailyUploads      `json:"uploads"`
+	Storage       []BackendStorage    `json:"storage"`
+	Notifications NotificationBacklog `json:"notifications"`
+	GeneratedAt   time.Time           `json:"generatedAt"`
+}
+
+// UserStats counts accounts
+type UserStats struct {
+	Total     int64 `json:"total" example:"1520"`
+	Active    int64 `json:"active" example:"1498"`
+	Suspended int64 `json:"suspended" example:"4"`
+	// RecentlyActive counts users who signed in during the reporting window
+	RecentlyActive int64 `json:"recentlyActive" example:"312"`
+}
+
+// DailyUploads counts uploads started on a day, in UTC
+type DailyUploads struct {
+	Date   string `json:"date" example:"2024-03-01"`
+	Total  int64  `json:"total" example:"42"`
+	Failed int64  `json:"failed" example:"2"`
+}
+
+// BackendStorage is the space taken by original video files on a storage backend
+type BackendStorage struct {
+	Backend string `json:"backend" example:"s3"`
+	Videos  int64  `json:"videos" example:"980"`
+	Bytes   int64  `json:"bytes" example:"52613349376"`
+}
+
+// NotificationBacklog summarizes unconsumed notification messages
+type NotificationBacklog struct {
+	// Backlog is the number of messages waiting across all subscriptions
+	Backlog int64 `json:"backlog" example:"120"`
+	// Stalled lists subscriptions, as topic/subscription, whose backlog is not shrinking
+	Stalled []string `json:"stalled"`
+}
+
+// FailedTranscode is an upload that failed or could not produce every rendition
+type FailedTranscode struct {
+	VideoID   uuid.UUID               `json:"videoId"`
+	UserID    uuid.UUID               `json:"userId"`
+	Title     string                  `json:"title"`
+	Status    video.UploadStatus      `json:"status" example:"failed"`
+	Failures  video.TranscodeFailures `json:"failures,omitempty"`
+	UpdatedAt time.Time               `json:"updatedAt"`
+}
+
+// FailedTranscodeListResponse represents a paginated list of failed transcodes
+type FailedTranscodeListResponse struct {
+	Transcodes []FailedTranscode `json:"transcodes"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+}
@@ -0,0 +1,215 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultDays is the reporting window when none is given
+	defaultDays = 30
+	// maxDays bounds the reporting window
+	maxDays = 365
+	// ipfsBackend names the IPFS replicas in storage stats
+	ipfsBackend = "ipfs"
+)
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db             *gorm.DB
+	storageBackend string
+	notifications  NotificationStats
+	logger         logger.Logger
+	now            func() time.Time
+}
+
+// NewService creates a new admin dashboard service. storageBackend names
+// where originals are stored; notifications may be nil when the
+// notification pipeline is not running.
+func NewService(db *gorm.DB, storageBackend string, notifications NotificationStats, logger logger.Logger) Service {
+	return &serviceImpl{
+		db:             db,
+		storageBackend: storageBackend,
+		notifications:  notifications,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Stats aggregates the dashboard summary
+func (s *serviceImpl) Stats(ctx context.Context, days int) (*Stats, error) {
+	if days < 1 {
+		days = defaultDays
+	} else if days > maxDays {
+		days = maxDays
+	}
+	now := s.now().UTC()
+	since := startOfDay(now).AddDate(0, 0, -(days - 1))
+
+	users, err := s.userStats(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	uploads, err := s.dailyUploads(ctx, since, now)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := s.storageStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Stats{
+		Users:         *users,
+		Uploads:       uploads,
+		Storage:       storage,
+		Notifications: s.notificationBacklog(),
+		GeneratedAt:   now,
+	}, nil
+}
+
+// userStats counts accounts, including those who signed in since the given time
+func (s *serviceImpl) userStats(ctx context.Context, since time.Time) (*UserStats, error) {
+	var stats UserStats
+	err := s.db.WithContext(ctx).Model(&auth.User{}).Select(
+		"COUNT(*) AS total, "+
+			"COALESCE(SUM(CASE WHEN active THEN 1 ELSE 0 END), 0) AS active, "+
+			"COALESCE(SUM(CASE WHEN suspended_at IS NOT NULL THEN 1 ELSE 0 END), 0) AS suspended, "+
+			"COALESCE(SUM(CASE WHEN last_login_at >= ? THEN 1 ELSE 0 END), 0) AS recently_active", since,
+	).Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	return &stats, nil
+}
+
+// dailyUploads counts uploads started each day from since to now
+func (s *serviceImpl) dailyUploads(ctx context.Context, since, now time.Time) ([]DailyUploads, error) {
+	var rows []struct {
+		Day    time.Time
+		Total  int64
+		Failed int64
+	}
+	err := s.db.WithContext(ctx).Model(&video.VideoUpload{}).
+		Select("date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, COUNT(*) AS total, "+
+			"SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS failed", video.UploadStatusFailed).
+		Where("created_at >= ?", since).
+		Group("day").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count uploads: %w", err)
+	}
+
+	counts := make(map[string]DailyUploads, len(rows))
+	for _, row := range rows {
+		date := row.Day.Format(time.DateOnly)
+		counts[date] = DailyUploads{Date: date, Total: row.Total, Failed: row.Failed}
+	}
+	return fillDays(counts, since, now), nil
+}
+
+// fillDays lists a day for each date from since to now, with zero counts
+// for days without uploads
+func fillDays(counts map[string]DailyUploads, since, now time.Time) []DailyUploads {
+	days := make([]DailyUploads, 0)
+	for day := startOfDay(since); !day.After(now); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		if count, ok := counts[date]; ok {
+			days = append(days, count)
+			continue
+		}
+		days = append(days, DailyUploads{Date: date})
+	}
+	return days
+}
+
+// storageStats sums the size of original files on the primary backend and
+// of those replicated to IPFS
+func (s *serviceImpl) storageStats(ctx context.Context) ([]BackendStorage, error) {
+	primary := BackendStorage{Backend: s.storageBackend}
+	err := s.db.WithContext(ctx).Model(&video.VideoUpload{}).
+		Joins("JOIN videos ON videos.id = video_uploads.video_id AND videos.deleted_at IS NULL").
+		Select("COUNT(*) AS videos, COALESCE(SUM(video_uploads.stored_bytes), 0) AS bytes").
+		Scan(&primary).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum stored bytes: %w", err)
+	}
+
+	ipfs := BackendStorage{Backend: ipfsBackend}
+	err = s.db.WithContext(ctx).Model(&video.Video{}).
+		Where("replication_status = ?", video.ReplicationReplicated).
+		Select("COUNT(*) AS videos, COALESCE(SUM(file_size), 0) AS bytes").
+		Scan(&ipfs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum replicated bytes: %w", err)
+	}
+
+	return []BackendStorage{primary, ipfs}, nil
+}
+
+// notificationBacklog totals subscription backlogs of the notification topics
+func (s *serviceImpl) notificationBacklog() NotificationBacklog {
+	backlog := NotificationBacklog{Stalled: []string{}}
+	if s.notifications == nil {
+		return backlog
+	}
+	for _, topic := range s.notifications.Snapshot() {
+		for _, sub := range topic.Subscriptions {
+			backlog.Backlog += sub.Backlog
+			if sub.Stalled {
+				backlog.Stalled = append(backlog.Stalled, topic.Topic+"/"+sub.Name)
+			}
+		}
+	}
+	return backlog
+}
+
+// ListFailedTranscodes returns uploads that failed or lost renditions
+func (s *serviceImpl) ListFailedTranscodes(ctx context.Context, page, limit int) (*FailedTranscodeListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	query := s.db.WithContext(ctx).Model(&video.VideoUpload{}).
+		Joins("JOIN videos ON videos.id = video_uploads.video_id AND videos.deleted_at IS NULL").
+		Where("video_uploads.status = ? OR video_uploads.transcode_failures IS NOT NULL", video.UploadStatusFailed)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed transcodes: %w", err)
+	}
+
+	transcodes := make([]FailedTranscode, 0, limit)
+	if err := query.
+		Select("video_uploads.video_id, videos.user_id, videos.title, video_uploads.status, " +
+			"video_uploads.transcode_failures AS failures, video_uploads.updated_at").
+		Order("video_uploads.updated_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Scan(&transcodes).Error; err != nil {
+		return nil, fmt.Errorf("failed to list failed transcodes: %w", err)
+	}
+
+	return &FailedTranscodeListResponse{
+		Transcodes: transcodes,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
+
+// startOfDay truncates t to midnight UTC
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
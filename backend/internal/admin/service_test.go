@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubNotifications []notification.TopicStats
+
+func (s stubNotifications) Snapshot() []notification.TopicStats {
+	return s
+}
+
+func TestFillDays(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 4, 15, 30, 0, 0, time.UTC)
+
+	days := fillDays(map[string]DailyUploads{
+		"2024-03-02": {Date: "2024-03-02", Total: 5, Failed: 1},
+		"2024-03-04": {Date: "2024-03-04", Total: 2},
+	}, since, now)
+
+	assert.Equal(t, []DailyUploads{
+		{Date: "2024-03-01"},
+		{Date: "2024-03-02", Total: 5, Failed: 1},
+		{Date: "2024-03-03"},
+		{Date: "2024-03-04", Total: 2},
+	}, days)
+}
+
+func TestNotificationBacklog(t *testing.T) {
+	service := &serviceImpl{notifications: stubNotifications{
+		{Topic: "video-events", Subscriptions: []notification.SubscriptionLag{
+			{Name: "notifier", Backlog: 40, Stalled: true},
+			{Name: "search", Backlog: 2},
+		}},
+		{Topic: "comment-events", Subscriptions: []notification.SubscriptionLag{
+			{Name: "notifier", Backlog: 8},
+		}},
+	}}
+
+	assert.Equal(t, NotificationBacklog{Backlog: 50, Stalled: []string{"video-events/notifier"}}, service.notificationBacklog())
+
+	service.notifications = nil
+	assert.Equal(t, NotificationBacklog{Stalled: []string{}}, service.notificationBacklog())
+}
+
+func TestStartOfDay(t *testing.T) {
+	at := time.Date(2024, 3, 4, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+	assert.Equal(t, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), startOfDay(at))
+}
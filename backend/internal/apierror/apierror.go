@@ -0,0 +1,219 @@
+// Package apierror is the catalog of machine-readable error codes returned by
+// the API. Each code has one HTTP status, so clients can rely on the code alone.
+//
+// Services return *Error values, usually package-level sentinels declared with
+// New, and handlers pass them to Abort. Middleware then writes the response,
+// so the mapping from service errors to statuses lives in one place instead
+// of a switch in every handler.
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Error codes. The values are part of the API and must not change.
+const (
+	// Generic request errors
+	CodeValidation         = "VALIDATION_ERROR"
+	CodeInvalidID          = "INVALID_ID"
+	CodeInvalidParameter   = "INVALID_PARAMETER"
+	CodeInvalidRequest     = "INVALID_REQUEST"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeRateLimited        = "RATE_LIMITED"
+	CodeRequestTimeout     = "REQUEST_TIMEOUT"
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeDatabase           = "DATABASE_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+
+	// Authentication and accounts
+	CodeAuth             = "AUTH_ERROR"
+	CodeAccountLocked    = "ACCOUNT_LOCKED"
+	CodeAccountSuspended = "ACCOUNT_SUSPENDED"
+	CodeEmailNotVerified = "EMAIL_NOT_VERIFIED"
+	CodeInvalidToken     = "INVALID_TOKEN"
+	CodeRefresh          = "REFRESH_ERROR"
+	CodeLogout           = "LOGOUT_ERROR"
+	CodeRegistration     = "REGISTRATION_ERROR"
+	CodeLastAdmin        = "LAST_ADMIN"
+	CodeInvalidImport    = "INVALID_IMPORT"
+	CodeUnknownProvider  = "UNKNOWN_PROVIDER"
+	CodeInvalidState     = "INVALID_STATE"
+	CodeOAuthDenied      = "OAUTH_DENIED"
+	CodeOAuthProvider    = "OAUTH_PROVIDER_ERROR"
+
+	// Videos. The ERR_ codes predate the catalog and are kept for existing clients.
+	CodeVideoNotFound          = "VIDEO_NOT_FOUND"
+	CodeVideoDeleted           = "VIDEO_DELETED"
+	CodeNoFile                 = "ERR_NO_FILE"
+	CodeUploadValidation       = "ERR_VALIDATION"
+	CodeUploadFailed           = "UPLOAD_FAILED"
+	CodeTranscodeFailed        = "TRANSCODE_FAILED"
+	CodeUpdateFailed           = "UPDATE_FAILED"
+	CodeDeleteFailed           = "DELETE_FAILED"
+	CodeEntitlementRequired    = "ENTITLEMENT_REQUIRED"
+	CodeEntitlementCheckFailed = "ENTITLEMENT_CHECK_FAILED"
+	CodeRenditionNotFound      = "RENDITION_NOT_FOUND"
+	CodeStreamUnavailable      = "STREAM_UNAVAILABLE"
+	CodeRenditionRegenerating  = "RENDITION_REGENERATING"
+	CodeInvalidMedia           = "ERR_INVALID_MEDIA"
+	CodeContentRejected        = "ERR_CONTENT_REJECTED"
+	CodeGeoBlocked             = "GEO_BLOCKED"
+)
+
+// statuses maps each code to its HTTP status
+var statuses = map[string]int{
+	CodeValidation:         http.StatusBadRequest,
+	CodeInvalidID:          http.StatusBadRequest,
+	CodeInvalidParameter:   http.StatusBadRequest,
+	CodeInvalidRequest:     http.StatusBadRequest,
+	CodeUnauthorized:       http.StatusUnauthorized,
+	CodeForbidden:          http.StatusForbidden,
+	CodeNotFound:           http.StatusNotFound,
+	CodeConflict:           http.StatusConflict,
+	CodeRateLimited:        http.StatusTooManyRequests,
+	CodeRequestTimeout:     http.StatusGatewayTimeout,
+	CodeInternal:           http.StatusInternalServerError,
+	CodeDatabase:           http.StatusInternalServerError,
+	CodeServiceUnavailable: http.StatusServiceUnavailable,
+
+	CodeAuth:             http.StatusUnauthorized,
+	CodeAccountLocked:    http.StatusLocked,
+	CodeAccountSuspended: http.StatusForbidden,
+	CodeEmailNotVerified: http.StatusForbidden,
+	CodeInvalidToken:     http.StatusBadRequest,
+	CodeRefresh:          http.StatusUnauthorized,
+	CodeLogout:           http.StatusUnauthorized,
+	CodeRegistration:     http.StatusBadRequest,
+	CodeLastAdmin:        http.StatusConflict,
+	CodeInvalidImport:    http.StatusBadRequest,
+	CodeUnknownProvider:  http.StatusNotFound,
+	CodeInvalidState:     http.StatusBadRequest,
+	CodeOAuthDenied:      http.StatusBadRequest,
+	CodeOAuthProvider:    http.StatusBadGateway,
+
+	CodeVideoNotFound:          http.StatusNotFound,
+	CodeVideoDeleted:           http.StatusGone,
+	CodeNoFile:                 http.StatusBadRequest,
+	CodeUploadValidation:       http.StatusBadRequest,
+	CodeUploadFailed:           http.StatusInternalServerError,
+	CodeTranscodeFailed:        http.StatusInternalServerError,
+	CodeUpdateFailed:           http.StatusInternalServerError,
+	CodeDeleteFailed:           http.StatusInternalServerError,
+	CodeEntitlementRequired:    http.StatusPaymentRequired,
+	CodeEntitlementCheckFailed: http.StatusInternalServerError,
+	CodeRenditionNotFound:      http.StatusNotFound,
+	CodeStreamUnavailable:      http.StatusServiceUnavailable,
+	CodeRenditionRegenerating:  http.StatusServiceUnavailable,
+	CodeInvalidMedia:           http.StatusUnprocessableEntity,
+	CodeContentRejected:        http.StatusUnprocessableEntity,
+	CodeGeoBlocked:             http.StatusUnavailableForLegalReasons,
+}
+
+// internalMessage is shown for errors outside the catalog, whose text may
+// leak implementation details
+const internalMessage = "Internal server error"
+
+// Status returns the HTTP status of a code, or 500 for codes not in the catalog
+func Status(code string) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError describes one request field that failed validation
+type FieldError struct {
+	Field   string `json:"field" example:"title"`
+	Message string `json:"message" example:"title must be between 3 and 100 characters"`
+	// Rule is the validation rule that failed, e.g. required or max
+	Rule string `json:"rule,omitempty" example:"videotitle"`
+}
+
+// Error is an error with a catalog code. Its message is shown to clients.
+type Error struct {
+	Code    string
+	Message string
+	// Field names the offending request field of a validation error
+	Field string
+	// Fields lists every offending field when a request fails validation
+	// on more than one rule
+	Fields []FieldError
+	// Err is the underlying cause; it is logged but never shown to clients
+	Err error
+}
+
+// New creates an error with the given code, typically a package-level sentinel
+func New(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap creates an error with the given code and client-facing message for an underlying cause
+func Wrap(err error, code, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// Validation creates a validation error for a request field
+func Validation(field, message string) *Error {
+	return &Error{Code: CodeValidation, Message: message, Field: field}
+}
+
+// Invalid creates a validation error listing the offending fields. The
+// message is that of the first field, for clients that only show one.
+func Invalid(fields ...FieldError) *Error {
+	err := &Error{Code: CodeValidation, Message: "Request validation failed", Fields: fields}
+	if len(fields) > 0 {
+		err.Message = fields[0].Message
+	}
+	return err
+}
+
+// Error returns the message, followed by the cause if there is one
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status of the error's code
+func (e *Error) Status() int {
+	return Status(e.Code)
+}
+
+// From returns the catalog error carried by err. When a service adds context
+// around a client error sentinel, e.g. fmt.Errorf("%w: too short",
+// ErrInvalidPassword), the full text becomes the message. Errors outside the
+// catalog become internal errors with fallback as their message, or a generic
+// one when fallback is empty.
+func From(err error, fallback string) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		if apiErr == err || apiErr.Err != nil {
+			return apiErr
+		}
+		// Server errors never show details to clients
+		message := err.Error()
+		if apiErr.Status() >= http.StatusInternalServerError {
+			message = apiErr.Message
+		}
+		return &Error{Code: apiErr.Code, Message: message, Field: apiErr.Field, Fields: apiErr.Fields, Err: err}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Wrap(err, CodeRequestTimeout, "The request did not complete in time")
+	}
+	if fallback == "" {
+		fallback = internalMessage
+	}
+	return Wrap(err, CodeInternal, fallback)
+}
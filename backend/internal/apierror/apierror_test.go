@@ -0,0 +1,159 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingResponder records the last error response
+type recordingResponder struct {
+	status  int
+	code    string
+	message string
+	field   string
+	fields  []FieldError
+	err     error
+}
+
+func (r *recordingResponder) ErrorResponse(c *gin.Context, status int, code, message string, err error) {
+	r.status, r.code, r.message, r.err = status, code, message, err
+	c.Status(status)
+}
+
+func (r *recordingResponder) ValidationErrorResponse(c *gin.Context, field, message string) {
+	r.status, r.code, r.message, r.field = http.StatusBadRequest, CodeValidation, message, field
+	c.Status(http.StatusBadRequest)
+}
+
+func (r *recordingResponder) FieldErrorsResponse(c *gin.Context, code, message string, fields []FieldError) {
+	r.status, r.code, r.message, r.fields = Status(code), code, message, fields
+	if len(fields) > 0 {
+		r.field = fields[0].Field
+	}
+	c.Status(r.status)
+}
+
+func TestEveryCodeHasAStatus(t *testing.T) {
+	for code, status := range statuses {
+		assert.GreaterOrEqual(t, status, 400, code)
+	}
+	assert.Equal(t, http.StatusInternalServerError, Status("SOMETHING_NEW"))
+}
+
+func TestFrom(t *testing.T) {
+	errMissing := New(CodeNotFound, "thing not found")
+
+	// Sentinels are returned as they are
+	assert.Same(t, errMissing, From(errMissing, ""))
+
+	// Context added around a sentinel becomes the message
+	wrapped := From(fmt.Errorf("%w: id 42", errMissing), "")
+	assert.Equal(t, CodeNotFound, wrapped.Code)
+	assert.Equal(t, "thing not found: id 42", wrapped.Message)
+	assert.ErrorIs(t, wrapped, errMissing)
+
+	// Wrapped causes keep their client-facing message
+	cause := errors.New("connection refused")
+	assert.Equal(t, "Failed to save", From(fmt.Errorf("save: %w", Wrap(cause, CodeDatabase, "Failed to save")), "").Message)
+
+	// Other errors are internal and hide their text
+	internal := From(cause, "")
+	assert.Equal(t, CodeInternal, internal.Code)
+	assert.Equal(t, internalMessage, internal.Message)
+	assert.ErrorIs(t, internal, cause)
+	assert.Equal(t, "Failed to load things", From(cause, "Failed to load things").Message)
+
+	assert.Equal(t, CodeRequestTimeout, From(fmt.Errorf("query: %w", context.DeadlineExceeded), "").Code)
+}
+
+func TestInvalid(t *testing.T) {
+	err := Invalid(
+		FieldError{Field: "email", Message: "email is required", Rule: "required"},
+		FieldError{Field: "password", Message: "password must be at least 8 characters", Rule: "min"},
+	)
+	assert.Equal(t, CodeValidation, err.Code)
+	assert.Equal(t, "email is required", err.Message)
+	assert.Len(t, err.Fields, 2)
+
+	// Context around it keeps the fields
+	assert.Len(t, From(fmt.Errorf("bind: %w", err), "").Fields, 2)
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantCode   string
+		wantField  string
+		wantLogged bool
+	}{
+		{
+			name:       "catalog error",
+			handler:    func(c *gin.Context) { Abort(c, New(CodeLastAdmin, "cannot remove the last admin"), "") },
+			wantStatus: http.StatusConflict,
+			wantCode:   CodeLastAdmin,
+		},
+		{
+			name:       "validation error",
+			handler:    func(c *gin.Context) { Abort(c, Validation("role", "role is invalid"), "") },
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeValidation,
+			wantField:  "role",
+		},
+		{
+			name: "field errors",
+			handler: func(c *gin.Context) {
+				Abort(c, fmt.Errorf("bind: %w", Invalid(
+					FieldError{Field: "title", Message: "title is required", Rule: "required"},
+					FieldError{Field: "tags", Message: "tags are invalid", Rule: "videotags"},
+				)), "")
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   CodeValidation,
+			wantField:  "title",
+		},
+		{
+			name:       "unknown error",
+			handler:    func(c *gin.Context) { Abort(c, errors.New("boom"), "Failed to do it") },
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   CodeInternal,
+			wantLogged: true,
+		},
+		{
+			name: "handler already responded",
+			handler: func(c *gin.Context) {
+				c.Status(http.StatusTeapot)
+				c.Writer.WriteHeaderNow()
+				Abort(c, errors.New("late"), "")
+			},
+			wantStatus: http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responder := &recordingResponder{}
+			router := gin.New()
+			router.Use(Middleware(responder))
+			router.GET("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCode, responder.code)
+			assert.Equal(t, tt.wantField, responder.field)
+			assert.Equal(t, tt.wantLogged, responder.err != nil)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package apierror
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Responder writes error responses in the API's response format. The
+// internal/http ResponseHandler implements it.
+type Responder interface {
+	ErrorResponse(c *gin.Context, status int, code, message string, err error)
+	ValidationErrorResponse(c *gin.Context, field, message string)
+	FieldErrorsResponse(c *gin.Context, code, message string, fields []FieldError)
+}
+
+// fallbackKey is the context key of the message Abort keeps for errors outside the catalog
+const fallbackKey = "apierror.fallback"
+
+// Abort records err for Middleware to write as the response and stops the
+// handler chain. fallback is the message shown when err is not from the
+// catalog; empty shows a generic message.
+func Abort(c *gin.Context, err error, fallback string) {
+	c.Set(fallbackKey, fallback)
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// Middleware writes the response for the last error recorded with Abort,
+// unless the handler already responded. Only causes of server errors are
+// logged; client errors are expected.
+func Middleware(responder Responder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		apiErr := From(c.Errors.Last().Err, c.GetString(fallbackKey))
+		if len(apiErr.Fields) > 0 {
+			responder.FieldErrorsResponse(c, apiErr.Code, apiErr.Message, apiErr.Fields)
+			return
+		}
+		if apiErr.Code == CodeValidation && apiErr.Field != "" {
+			responder.ValidationErrorResponse(c, apiErr.Field, apiErr.Message)
+			return
+		}
+
+		var logged error
+		if apiErr.Status() >= http.StatusInternalServerError {
+			logged = apiErr
+		}
+		responder.ErrorResponse(c, apiErr.Status(), apiErr.Code, apiErr.Message, logged)
+	}
+}
@@ -0,0 +1,157 @@
+package audit
+
+import (
+	stdhttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for the audit log
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new audit log handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the audit log routes behind the authentication
+// and admin role middlewares
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, adminMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin/audit")
+	admin.Use(authMiddleware, adminMiddleware)
+	{
+		admin.GET("", h.handleListEvents)
+		admin.GET("/export", h.handleExportEvents)
+	}
+}
+
+// @Summary List audit events
+// @Description Get a page of the audit log, newest first: uploads and deletions of videos and comments, comments caught by the banned words filter, logins and role changes. Filter by a user, who may be the actor or the target, by event type and by time range. Without from, the last 30 days are searched; a range may be at most 366 days. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query string false "Only events by or about this user (UUID)"
+// @Param type query string false "Event type" Enums(video.uploaded, video.deleted, comment.created, comment.deleted, comment.filtered, user.login, user.role_changed)
+// @Param from query string false "Start of the range, RFC 3339 (default: 30 days before to)"
+// @Param to query string false "End of the range, exclusive, RFC 3339 (default: now)"
+// @Param cursor query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Events per page (default: 50, max: 200)"
+// @Success 200 {object} httpHandler.APIResponse{data=Page} "Audit events retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid filter or cursor"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/audit [get]
+func (h *Handler) handleListEvents(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+	if cursor := c.Query("cursor"); cursor != "" {
+		if filter.Cursor, err = DecodeCursor(cursor); err != nil {
+			apierror.Abort(c, err, "")
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	page, err := h.service.List(c.Request.Context(), filter, limit)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to retrieve audit events")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, page, "Audit events retrieved successfully")
+}
+
+// @Summary Export audit events
+// @Description Download every audit event matching the filters as CSV, newest first, with the columns created_at, id, type, actor_id, target_id, ip_address and details (key=value pairs separated by semicolons). Admins only.
+// @Tags admin
+// @Produce text/csv,json
+// @Security BearerAuth
+// @Param user_id query string false "Only events by or about this user (UUID)"
+// @Param type query string false "Event type" Enums(video.uploaded, video.deleted, comment.created, comment.deleted, comment.filtered, user.login, user.role_changed)
+// @Param from query string false "Start of the range, RFC 3339 (default: 30 days before to)"
+// @Param to query string false "End of the range, exclusive, RFC 3339 (default: now)"
+// @Success 200 {file} file "CSV file of audit events"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid filter"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Router /admin/audit/export [get]
+func (h *Handler) handleExportEvents(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+	// Check the filter before the response is started, so a bad one is
+	// still answered with an error
+	if err := filter.Resolve(time.Now()); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	h.logger.LogInfo("Audit log exported", map[string]interface{}{
+		"actorID": c.GetString("userID"),
+		"type":    string(filter.Type),
+		"userID":  filter.UserID.String(),
+	})
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=audit.csv")
+	c.Status(stdhttp.StatusOK)
+	if err := h.service.Export(c.Request.Context(), filter, c.Writer); err != nil {
+		// The status is already sent; the truncated file is all we can do
+		h.logger.LogError(err, "Failed to export audit events")
+	}
+}
+
+// parseFilter reads the user, type and time range filters from the query
+func parseFilter(c *gin.Context) (Filter, error) {
+	var filter Filter
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return filter, apierror.New(apierror.CodeInvalidID, "Invalid user ID format")
+		}
+		filter.UserID = id
+	}
+	filter.Type = EventType(c.Query("type"))
+
+	var err error
+	if filter.From, err = parseTime(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTime(c, "to"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseTime reads an optional RFC 3339 time from the query
+func parseTime(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apierror.Validation(name, name+" must be an RFC 3339 time")
+	}
+	return t, nil
+}
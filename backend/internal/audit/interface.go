@@ -0,0 +1,45 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+)
+
+var (
+	// ErrInvalidCursor is returned when an audit cursor cannot be decoded
+	ErrInvalidCursor = apierror.New(apierror.CodeInvalidParameter, "invalid audit cursor")
+	// ErrInvalidType is returned when filtering by an event type that is not audited
+	ErrInvalidType = apierror.Validation("type", "unknown audit event type")
+	// ErrInvalidRange is returned when the time range is reversed or too long
+	ErrInvalidRange = apierror.Validation("from", "from must be before to and at most a year earlier")
+)
+
+// Recorder appends events to the audit log. Other packages depend on this
+// interface only.
+type Recorder interface {
+	// Record appends an event. Failures are logged, never returned, so
+	// auditing never fails the action being audited.
+	Record(ctx context.Context, event Event)
+}
+
+// Service defines the interface for the audit log
+type Service interface {
+	Recorder
+	// List returns up to limit events matching the filter, newest first
+	List(ctx context.Context, filter Filter, limit int) (*Page, error)
+	// Export writes every event matching the filter to w as CSV, newest first
+	Export(ctx context.Context, filter Filter, w io.Writer) error
+}
+
+// Repository stores audit events
+type Repository interface {
+	// Append writes an event
+	Append(ctx context.Context, event *Event) error
+	// ListDay calls fn with the events of the UTC day containing day created
+	// at or before the given time, newest first, until fn returns false. A
+	// zero before lists the whole day.
+	ListDay(ctx context.Context, day, before time.Time, fn func(*Event) bool) error
+}
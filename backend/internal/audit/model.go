@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType is the kind of domain event recorded in the audit log
+type EventType string
+
+// Audited event types
+const (
+	VideoUploaded   EventType = "video.uploaded"
+	VideoDeleted    EventType = "video.deleted"
+	CommentCreated  EventType = "comment.created"
+	CommentDeleted  EventType = "comment.deleted"
+	CommentFiltered EventType = "comment.filtered"
+	UserLogin       EventType = "user.login"
+	RoleChanged     EventType = "user.role_changed"
+)
+
+const (
+	// DefaultRange is how far back the log is searched when no start is given
+	DefaultRange = 30 * 24 * time.Hour
+	// MaxRange bounds the time range of one search or export
+	MaxRange = 366 * 24 * time.Hour
+)
+
+// knownTypes are the event types the log can be filtered by
+var knownTypes = []EventType{VideoUploaded, VideoDeleted, CommentCreated, CommentDeleted, CommentFiltered, UserLogin, RoleChanged}
+
+// IsValid reports whether t is an audited event type
+func (t EventType) IsValid() bool {
+	for _, known := range knownTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is one entry of the audit log. Entries are never changed once written.
+// @Description An audited domain event
+type Event struct {
+	ID   uuid.UUID `json:"id"`
+	Type EventType `json:"type" swaggertype:"string" example:"video.deleted"`
+	// ActorID is the user who acted
+	ActorID uuid.UUID `json:"actor_id"`
+	// TargetID is the video, comment or user acted on
+	TargetID string `json:"target_id,omitempty"`
+	// Details holds event specific values, e.g. the previous and new role
+	Details   map[string]string `json:"details,omitempty"`
+	IPAddress string            `json:"ip_address,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Filter selects the audit events to list or export
+type Filter struct {
+	// UserID matches events the user acted in or was the target of
+	UserID uuid.UUID
+	Type   EventType
+	// From and To bound the time range; To is exclusive
+	From time.Time
+	To   time.Time
+	// Cursor resumes after the last event of the previous page
+	Cursor *Cursor
+}
+
+// Resolve checks the filter and fills in the default time range ending at now
+func (f *Filter) Resolve(now time.Time) error {
+	if f.Type != "" && !f.Type.IsValid() {
+		return ErrInvalidType
+	}
+	if f.To.IsZero() {
+		f.To = now
+	}
+	if f.From.IsZero() {
+		f.From = f.To.Add(-DefaultRange)
+	}
+	if !f.From.Before(f.To) || f.To.Sub(f.From) > MaxRange {
+		return ErrInvalidRange
+	}
+	return nil
+}
+
+// Matches reports whether event passes the user and type filters
+func (f *Filter) Matches(event *Event) bool {
+	if f.Type != "" && event.Type != f.Type {
+		return false
+	}
+	if f.UserID != uuid.Nil && event.ActorID != f.UserID && event.TargetID != f.UserID.String() {
+		return false
+	}
+	return true
+}
+
+// Page is a page of the audit log, newest first
+// @Description A page of audit events with the cursor for the next page
+type Page struct {
+	Events []Event `json:"events"`
+	// NextCursor is passed as cursor to get the next page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// Cursor marks the last event of a page. Events are ordered by created_at
+// DESC, then id, so the next page starts strictly after this pair.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// After reports whether event comes after the cursor in log order
+func (c *Cursor) After(event *Event) bool {
+	if c == nil {
+		return true
+	}
+	if !event.CreatedAt.Equal(c.CreatedAt) {
+		return event.CreatedAt.Before(c.CreatedAt)
+	}
+	return event.ID.String() > c.ID.String()
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *Cursor) Encode() string {
+	raw := fmt.Sprintf("%d|%s", c.CreatedAt.UnixNano(), c.ID.String())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
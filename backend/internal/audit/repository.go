@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gocql/gocql"
+	"github.com/google/uuid"
+)
+
+// ScyllaRepository implements the Repository interface on ScyllaDB. Events
+// are kept in one table per month, audit_events_YYYYMM, so old months can be
+// archived or dropped as a whole. A month's table is created by its first
+// event; months without a table have no events.
+type ScyllaRepository struct {
+	session  *gocql.Session
+	keyspace string
+	logger   logger.Logger
+
+	// tables caches the monthly tables known to exist
+	mu     sync.RWMutex
+	tables map[string]bool
+}
+
+// NewScyllaRepository creates a new ScyllaDB audit log repository
+func NewScyllaRepository(session *gocql.Session, keyspace string, logger logger.Logger) *ScyllaRepository {
+	return &ScyllaRepository{
+		session:  session,
+		keyspace: keyspace,
+		logger:   logger,
+		tables:   make(map[string]bool),
+	}
+}
+
+// Append writes an event to the table of its month
+func (r *ScyllaRepository) Append(ctx context.Context, event *Event) error {
+	table := TableName(event.CreatedAt)
+	if err := r.ensureTable(ctx, table); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s.%s (day, created_at, id, type, actor_id, target_id, details, ip_address) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, r.keyspace, table)
+	if err := r.session.Query(query,
+		dayOf(event.CreatedAt),
+		event.CreatedAt,
+		gocql.UUID(event.ID),
+		string(event.Type),
+		gocql.UUID(event.ActorID),
+		event.TargetID,
+		event.Details,
+		event.IPAddress,
+	).WithContext(ctx).Exec(); err != nil {
+		return fmt.Errorf("failed to append audit event: %w", err)
+	}
+	return nil
+}
+
+// ListDay streams one day's partition, newest first
+func (r *ScyllaRepository) ListDay(ctx context.Context, day, before time.Time, fn func(*Event) bool) error {
+	table := TableName(day)
+	exists, err := r.tableExists(ctx, table)
+	if err != nil || !exists {
+		return err
+	}
+
+	query := fmt.Sprintf(`SELECT created_at, id, type, actor_id, target_id, details, ip_address FROM %s.%s WHERE day = ?`, r.keyspace, table)
+	args := []interface{}{dayOf(day)}
+	if !before.IsZero() {
+		query += ` AND created_at <= ?`
+		args = append(args, before)
+	}
+
+	iter := r.session.Query(query, args...).WithContext(ctx).PageSize(500).Iter()
+	var (
+		createdAt time.Time
+		id        gocql.UUID
+		eventType string
+		actorID   gocql.UUID
+		targetID  string
+		details   map[string]string
+		ipAddress string
+	)
+	for iter.Scan(&createdAt, &id, &eventType, &actorID, &targetID, &details, &ipAddress) {
+		event := &Event{
+			ID:        uuid.UUID(id),
+			Type:      EventType(eventType),
+			ActorID:   uuid.UUID(actorID),
+			TargetID:  targetID,
+			Details:   details,
+			IPAddress: ipAddress,
+			CreatedAt: createdAt.UTC(),
+		}
+		details = nil
+		if !fn(event) {
+			break
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("failed to list audit events: %w", err)
+	}
+	return nil
+}
+
+// ensureTable creates a monthly table unless it is known to exist
+func (r *ScyllaRepository) ensureTable(ctx context.Context, table string) error {
+	r.mu.RLock()
+	exists := r.tables[table]
+	r.mu.RUnlock()
+	if exists {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s.%s (
+			day date,
+			created_at timestamp,
+			id uuid,
+			type text,
+			actor_id uuid,
+			target_id text,
+			details map<text, text>,
+			ip_address text,
+			PRIMARY KEY ((day), created_at, id)
+		) WITH CLUSTERING ORDER BY (created_at DESC, id ASC)`,
+		r.keyspace, table,
+	)
+	if err := r.session.Query(query).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to create audit table")
+		return fmt.Errorf("failed to create %s table: %w", table, err)
+	}
+	r.logger.LogInfo("Audit table created", map[string]interface{}{"table": table})
+
+	r.mu.Lock()
+	r.tables[table] = true
+	r.mu.Unlock()
+	return nil
+}
+
+// tableExists reports whether a monthly table has been created. Only tables
+// found are cached, since a missing one may be created by another instance.
+func (r *ScyllaRepository) tableExists(ctx context.Context, table string) (bool, error) {
+	r.mu.RLock()
+	exists := r.tables[table]
+	r.mu.RUnlock()
+	if exists {
+		return true, nil
+	}
+
+	var name string
+	err := r.session.Query(`SELECT table_name FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?`, r.keyspace, table).
+		WithContext(ctx).
+		Scan(&name)
+	if err == gocql.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s table: %w", table, err)
+	}
+
+	r.mu.Lock()
+	r.tables[table] = true
+	r.mu.Unlock()
+	return true, nil
+}
+
+// TableName returns the table holding the events of t's month
+func TableName(t time.Time) string {
+	return "audit_events_" + t.UTC().Format("200601")
+}
+
+// dayOf returns the partition of the UTC day containing t
+func dayOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,205 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+)
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	repo   Repository
+	logger logger.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new audit log service over repo
+func NewService(repo Repository, logger logger.Logger) Service {
+	return &serviceImpl{
+		repo:   repo,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Record appends an event, filling in its ID and time. The write is not
+// cancelled with the request that caused it.
+func (s *serviceImpl) Record(ctx context.Context, event Event) {
+	if event.ID == uuid.Nil {
+		event.ID = uuid.New()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = s.now()
+	}
+	// Timestamps are stored with millisecond precision; truncate so cursors
+	// match the stored events
+	event.CreatedAt = event.CreatedAt.UTC().Truncate(time.Millisecond)
+
+	if err := s.repo.Append(context.WithoutCancel(ctx), &event); err != nil {
+		s.logger.LogError(err, "Failed to record audit event")
+		return
+	}
+	s.logger.LogInfo("Audit event recorded", map[string]interface{}{
+		"type":     string(event.Type),
+		"actorID":  event.ActorID.String(),
+		"targetID": event.TargetID,
+	})
+}
+
+// List returns a page of events matching the filter
+func (s *serviceImpl) List(ctx context.Context, filter Filter, limit int) (*Page, error) {
+	if limit < 1 {
+		limit = 50
+	} else if limit > 200 {
+		limit = 200
+	}
+
+	events := make([]Event, 0, limit)
+	hasMore := false
+	err := s.scan(ctx, filter, func(event *Event) bool {
+		if len(events) == limit {
+			hasMore = true
+			return false
+		}
+		events = append(events, *event)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Events: events, HasMore: hasMore}
+	if hasMore {
+		last := events[len(events)-1]
+		page.NextCursor = (&Cursor{CreatedAt: last.CreatedAt, ID: last.ID}).Encode()
+	}
+	return page, nil
+}
+
+// Export writes the matching events as CSV, one row per event
+func (s *serviceImpl) Export(ctx context.Context, filter Filter, w io.Writer) error {
+	writer := NewCSVWriter(w)
+	var writeErr error
+	err := s.scan(ctx, filter, func(event *Event) bool {
+		writeErr = writer.Write(event)
+		return writeErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return writer.Flush()
+}
+
+// scan calls fn with the events matching the filter, newest first, reading
+// the range one day at a time until fn returns false
+func (s *serviceImpl) scan(ctx context.Context, filter Filter, fn func(*Event) bool) error {
+	if err := filter.Resolve(s.now()); err != nil {
+		return err
+	}
+
+	upper := filter.To
+	if filter.Cursor != nil && filter.Cursor.CreatedAt.Before(upper) {
+		upper = filter.Cursor.CreatedAt
+	}
+
+	more := true
+	for day := dayOf(upper); more && !day.Before(dayOf(filter.From)); day = day.AddDate(0, 0, -1) {
+		err := s.repo.ListDay(ctx, day, upper, func(event *Event) bool {
+			if event.CreatedAt.Before(filter.From) {
+				more = false
+				return false
+			}
+			if !event.CreatedAt.Before(filter.To) || !filter.Cursor.After(event) || !filter.Matches(event) {
+				return true
+			}
+			more = fn(event)
+			return more
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvHeader names the columns of the audit export
+var csvHeader = []string{"created_at", "id", "type", "actor_id", "target_id", "ip_address", "details"}
+
+// CSVWriter writes events in the audit export format
+type CSVWriter struct {
+	writer      *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVWriter creates a CSV writer over w
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{writer: csv.NewWriter(w)}
+}
+
+// Write writes one event, preceded by the header row on the first call
+func (w *CSVWriter) Write(event *Event) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if err := w.writer.Write([]string{
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		event.ID.String(),
+		string(event.Type),
+		event.ActorID.String(),
+		event.TargetID,
+		event.IPAddress,
+		formatDetails(event.Details),
+	}); err != nil {
+		return fmt.Errorf("failed to write audit export: %w", err)
+	}
+	return nil
+}
+
+// Flush writes the header when no events were written and flushes buffered rows
+func (w *CSVWriter) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("failed to write audit export: %w", err)
+	}
+	return nil
+}
+
+// writeHeader writes the header row once
+func (w *CSVWriter) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	if err := w.writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write audit export: %w", err)
+	}
+	return nil
+}
+
+// formatDetails renders details as key=value pairs sorted by key
+func formatDetails(details map[string]string) string {
+	keys := make([]string, 0, len(details))
+	for key := range details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+details[key])
+	}
+	return strings.Join(pairs, ";")
+}
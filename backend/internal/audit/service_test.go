@@ -0,0 +1,176 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRepository keeps events in memory for tests
+type memoryRepository struct {
+	events []Event
+}
+
+func (r *memoryRepository) Append(ctx context.Context, event *Event) error {
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memoryRepository) ListDay(ctx context.Context, day, before time.Time, fn func(*Event) bool) error {
+	var events []Event
+	for _, event := range r.events {
+		if dayOf(event.CreatedAt).Equal(dayOf(day)) && (before.IsZero() || !event.CreatedAt.After(before)) {
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.After(events[j].CreatedAt)
+		}
+		return events[i].ID.String() < events[j].ID.String()
+	})
+	for i := range events {
+		if !fn(&events[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) LogInfo(string, map[string]interface{})                {}
+func (nopLogger) LogError(err error, _ string) error                    { return err }
+func (nopLogger) LogErrorf(err error, _ string, _ ...interface{}) error { return err }
+func (nopLogger) LogFatal(error, string)                                {}
+func (nopLogger) LogDebug(string, map[string]interface{})               {}
+func (nopLogger) LogWarn(string, map[string]interface{})                {}
+func (l nopLogger) WithFields(map[string]interface{}) logger.Logger     { return l }
+func (l nopLogger) WithContext(context.Context) logger.Logger           { return l }
+func (l nopLogger) WithRequestID(string) logger.Logger                  { return l }
+func (l nopLogger) WithUserID(string) logger.Logger                     { return l }
+
+// newTestService returns a service whose clock is fixed at now
+func newTestService(repo Repository, now time.Time) *serviceImpl {
+	return &serviceImpl{repo: repo, logger: nopLogger{}, now: func() time.Time { return now }}
+}
+
+func TestRecordFillsIDAndTime(t *testing.T) {
+	repo := &memoryRepository{}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	service := newTestService(repo, now)
+
+	service.Record(context.Background(), Event{Type: UserLogin, ActorID: uuid.New()})
+
+	require.Len(t, repo.events, 1)
+	assert.NotEqual(t, uuid.Nil, repo.events[0].ID)
+	assert.Equal(t, now.Truncate(time.Millisecond), repo.events[0].CreatedAt)
+}
+
+func TestListPagesAcrossDays(t *testing.T) {
+	repo := &memoryRepository{}
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	service := newTestService(repo, now)
+	admin, user := uuid.New(), uuid.New()
+	latest := now.Add(-time.Hour)
+
+	// One event a day for five days, alternating types
+	for i := 0; i < 5; i++ {
+		eventType := VideoUploaded
+		if i%2 == 1 {
+			eventType = RoleChanged
+		}
+		service.Record(context.Background(), Event{Type: eventType, ActorID: admin, TargetID: user.String(), CreatedAt: latest.AddDate(0, 0, -i)})
+	}
+
+	first, err := service.List(context.Background(), Filter{}, 3)
+	require.NoError(t, err)
+	require.Len(t, first.Events, 3)
+	assert.True(t, first.HasMore)
+	assert.Equal(t, latest, first.Events[0].CreatedAt)
+
+	cursor, err := DecodeCursor(first.NextCursor)
+	require.NoError(t, err)
+	second, err := service.List(context.Background(), Filter{Cursor: cursor}, 3)
+	require.NoError(t, err)
+	require.Len(t, second.Events, 2)
+	assert.False(t, second.HasMore)
+	assert.Empty(t, second.NextCursor)
+	assert.Equal(t, latest.AddDate(0, 0, -3), second.Events[0].CreatedAt)
+
+	// The user filter matches the target as well as the actor
+	roles, err := service.List(context.Background(), Filter{UserID: user, Type: RoleChanged}, 10)
+	require.NoError(t, err)
+	assert.Len(t, roles.Events, 2)
+
+	// The range excludes its end
+	ranged, err := service.List(context.Background(), Filter{From: latest.AddDate(0, 0, -2), To: latest}, 10)
+	require.NoError(t, err)
+	assert.Len(t, ranged.Events, 2)
+}
+
+func TestListRejectsBadFilters(t *testing.T) {
+	now := time.Now()
+	service := newTestService(&memoryRepository{}, now)
+
+	_, err := service.List(context.Background(), Filter{Type: "video.watched"}, 10)
+	assert.ErrorIs(t, err, ErrInvalidType)
+
+	_, err = service.List(context.Background(), Filter{From: now, To: now.Add(-time.Hour)}, 10)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = service.List(context.Background(), Filter{From: now.AddDate(-2, 0, 0)}, 10)
+	assert.ErrorIs(t, err, ErrInvalidRange)
+}
+
+func TestExportWritesCSV(t *testing.T) {
+	repo := &memoryRepository{}
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	service := newTestService(repo, now)
+	admin := uuid.New()
+	service.Record(context.Background(), Event{
+		Type:      RoleChanged,
+		ActorID:   admin,
+		TargetID:  "target",
+		Details:   map[string]string{"role": "admin", "previous": "user"},
+		IPAddress: "10.0.0.1",
+		CreatedAt: now.Add(-time.Minute),
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, service.Export(context.Background(), Filter{}, &buf))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, csvHeader, rows[0])
+	assert.Equal(t, []string{"2026-05-10T11:59:00Z", repo.events[0].ID.String(), "user.role_changed", admin.String(), "target", "10.0.0.1", "previous=user;role=admin"}, rows[1])
+
+	// An empty export still has the header
+	buf.Reset()
+	require.NoError(t, service.Export(context.Background(), Filter{Type: UserLogin}, &buf))
+	assert.Equal(t, "created_at,id,type,actor_id,target_id,ip_address,details\n", buf.String())
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := &Cursor{CreatedAt: time.Date(2026, 5, 10, 12, 0, 0, 5000000, time.UTC), ID: uuid.New()}
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	_, err = DecodeCursor("not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestTableName(t *testing.T) {
+	assert.Equal(t, "audit_events_202605", TableName(time.Date(2026, 5, 31, 23, 0, 0, 0, time.UTC)))
+}
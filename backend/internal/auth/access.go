@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// accessCheckTTL is how long the outcome of checking an access token's
+	// account and session is reused. Suspensions, revoked sessions and role
+	// changes reach every instance within this time.
+	accessCheckTTL = 30 * time.Second
+	// accessCacheSize bounds the number of checked sessions remembered
+	accessCacheSize = 10000
+)
+
+var errSessionEnded = errors.New("account is suspended or session has been revoked")
+
+// accessState is the checked state of the account and session behind an
+// access token
+type accessState struct {
+	allowed bool
+	// role is the user's current role, which replaces the one in the token
+	role      Role
+	checkedAt time.Time
+}
+
+// accessCache remembers checked sessions, keyed by user and session ID
+type accessCache struct {
+	mu      sync.Mutex
+	entries map[string]accessState
+}
+
+func newAccessCache() *accessCache {
+	return &accessCache{entries: make(map[string]accessState)}
+}
+
+func accessKey(userID, sessionID uuid.UUID) string {
+	return userID.String() + "/" + sessionID.String()
+}
+
+func (c *accessCache) get(key string, now time.Time) (accessState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state, ok := c.entries[key]
+	if !ok || now.Sub(state.checkedAt) >= accessCheckTTL {
+		return accessState{}, false
+	}
+	return state, true
+}
+
+func (c *accessCache) put(key string, state accessState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= accessCacheSize {
+		for k, s := range c.entries {
+			if state.checkedAt.Sub(s.checkedAt) >= accessCheckTTL {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= accessCacheSize {
+			c.entries = make(map[string]accessState)
+		}
+	}
+	c.entries[key] = state
+}
+
+// forget drops the checked sessions of a user, so changes made on this
+// instance apply at once
+func (c *accessCache) forget(userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := userID.String() + "/"
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// checkAccess returns the current role of the user an access token was
+// issued to, or errSessionEnded if the account has been suspended or
+// deleted or the token's session revoked since
+func (s *Service) checkAccess(claims *TokenClaims) (Role, error) {
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return "", fmt.Errorf("invalid user ID in token: %v", err)
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return "", fmt.Errorf("invalid session ID in token: %v", err)
+	}
+
+	now := time.Now()
+	key := accessKey(userID, sessionID)
+	state, ok := s.access.get(key, now)
+	if !ok {
+		state, err = s.lookupAccess(userID, sessionID)
+		if err != nil {
+			return "", err
+		}
+		state.checkedAt = now
+		s.access.put(key, state)
+	}
+	if !state.allowed {
+		return "", errSessionEnded
+	}
+	return state.role, nil
+}
+
+// lookupAccess reads the state of an account and session from the database
+func (s *Service) lookupAccess(userID, sessionID uuid.UUID) (accessState, error) {
+	var user User
+	if err := s.db.Select("id", "role", "active").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return accessState{}, nil
+		}
+		s.logger.LogError(err, "Failed to look up user for access token")
+		return accessState{}, fmt.Errorf("failed to look up user: %v", err)
+	}
+	if !user.Active {
+		return accessState{}, nil
+	}
+
+	active, err := s.refreshTokens.IsActive(userID, sessionID)
+	if err != nil {
+		return accessState{}, fmt.Errorf("failed to look up session: %v", err)
+	}
+	return accessState{allowed: active, role: user.Role}, nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/mail"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// actionTokenClaims represents the claims of a single-purpose token sent by
+// email, such as a password reset or email verification link
+type actionTokenClaims struct {
+	UserID  string `json:"userId"`
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// signingKeyFunc derives the key used to sign a user's action tokens. Keys
+// differ from the access token secret so action tokens are never accepted as
+// access tokens.
+type signingKeyFunc func(user *User) []byte
+
+// SetMailer sets the mailer used to deliver password reset and verification emails
+func (s *Service) SetMailer(mailer mail.Mailer) {
+	s.mailer = mailer
+}
+
+// signActionToken creates a signed, time-limited token for a single purpose
+func (s *Service) signActionToken(user *User, purpose string, ttl time.Duration, key signingKeyFunc) (string, error) {
+	claims := &actionTokenClaims{
+		UserID:  user.ID.String(),
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			Subject:   user.ID.String(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(key(user))
+}
+
+// parseActionToken validates an action token and returns the active user it was issued for
+func (s *Service) parseActionToken(tokenString, purpose string, key signingKeyFunc) (*User, error) {
+	// The signing key depends on the user, so read the user ID before verifying
+	unverified := &actionTokenClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, unverified); err != nil {
+		return nil, fmt.Errorf("malformed token: %v", err)
+	}
+
+	var user User
+	if err := s.db.Where("id = ? AND active = ?", unverified.UserID, true).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found: %v", err)
+	}
+
+	claims := &actionTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key(&user), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token: %v", err)
+	}
+
+	if claims.Purpose != purpose {
+		return nil, errors.New("token was issued for a different purpose")
+	}
+
+	return &user, nil
+}
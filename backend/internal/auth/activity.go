@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecurityEventType is the kind of account activity recorded
+type SecurityEventType string
+
+const (
+	SecurityLogin         SecurityEventType = "login"
+	SecurityTokenRefresh  SecurityEventType = "token_refresh"
+	SecurityLogout        SecurityEventType = "logout"
+	SecurityPasswordReset SecurityEventType = "password_reset"
+)
+
+// SecurityOutcome is whether a recorded attempt succeeded
+type SecurityOutcome string
+
+const (
+	OutcomeSuccess SecurityOutcome = "success"
+	OutcomeFailure SecurityOutcome = "failure"
+)
+
+// Reasons recorded with failed attempts
+const (
+	reasonInvalidPassword  = "invalid_password"
+	reasonAccountLocked    = "account_locked"
+	reasonEmailNotVerified = "email_not_verified"
+	reasonAccountSuspended = "account_suspended"
+	reasonTokenRevoked     = "token_revoked"
+)
+
+// Sign-in methods recorded with logins other than through an OAuth provider
+const methodPassword = "password"
+
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 100
+)
+
+// recordSecurityEvent stores account activity for the user to review and
+// removes the user's events older than the retention. Failures are logged,
+// never returned, so recording never fails the action being recorded.
+func (s *Service) recordSecurityEvent(event SecurityEvent, client ClientInfo) {
+	event.IPAddress = client.IPAddress
+	event.UserAgent = client.UserAgent
+	event.CreatedAt = time.Now()
+	if err := s.db.Create(&event).Error; err != nil {
+		s.logger.LogError(err, "Failed to record account activity")
+		return
+	}
+
+	if s.config.ActivityRetention > 0 {
+		cutoff := event.CreatedAt.Add(-s.config.ActivityRetention)
+		if err := s.db.Where("user_id = ? AND created_at < ?", event.UserID, cutoff).Delete(&SecurityEvent{}).Error; err != nil {
+			s.logger.LogError(err, "Failed to prune account activity")
+		}
+	}
+}
+
+// ListSecurityActivity returns up to limit of the user's account activity
+// events created before the given time, newest first. A zero before starts
+// from the most recent event.
+func (s *Service) ListSecurityActivity(userID uuid.UUID, before time.Time, limit int) ([]SecurityEvent, error) {
+	if limit <= 0 {
+		limit = defaultActivityLimit
+	}
+	if limit > maxActivityLimit {
+		limit = maxActivityLimit
+	}
+
+	query := s.db.Where("user_id = ?", userID)
+	if !before.IsZero() {
+		query = query.Where("created_at < ?", before)
+	}
+	events := make([]SecurityEvent, 0, limit)
+	if err := query.Order("created_at DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list account activity: %v", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,93 @@
+package auth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+)
+
+func TestSecurityActivity(t *testing.T) {
+	db := testhelper.SetupTestDB(t)
+	logger := testhelper.NewTestLogger(true)
+
+	config := newTestConfig("test-secret-" + uuid.New().String())
+	config.ActivityRetention = 24 * time.Hour
+	jwtService := auth.NewJWTService(config)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db, logger)
+	authService := auth.NewService(db, jwtService, refreshTokenRepo, config, logger)
+
+	regReq := auth.RegisterRequest{
+		Username: "activityuser",
+		Email:    "activity@example.com",
+		Password: "Pass123!",
+		Name:     "Activity User",
+	}
+	user, err := authService.Register(regReq)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := authService.MarkEmailVerified(user.ID); err != nil {
+		t.Fatalf("MarkEmailVerified failed: %v", err)
+	}
+
+	// An event past the retention is pruned by the next one recorded
+	stale := auth.SecurityEvent{UserID: user.ID, Type: auth.SecurityLogin, Outcome: auth.OutcomeSuccess, CreatedAt: time.Now().Add(-48 * time.Hour)}
+	if err := db.Create(&stale).Error; err != nil {
+		t.Fatalf("Failed to create stale event: %v", err)
+	}
+
+	laptop := auth.ClientInfo{IPAddress: "203.0.113.7", UserAgent: "Firefox"}
+	if _, err := authService.Login(regReq.Email, "WrongPass1!", laptop); err == nil {
+		t.Fatal("expected login with a wrong password to fail")
+	}
+	login, err := authService.Login(regReq.Email, regReq.Password, laptop)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := authService.RefreshToken(login.RefreshToken, laptop); err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if err := authService.Logout(user.ID, login.RefreshToken, laptop); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	events, err := authService.ListSecurityActivity(user.ID, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("ListSecurityActivity failed: %v", err)
+	}
+	want := []struct {
+		eventType auth.SecurityEventType
+		outcome   auth.SecurityOutcome
+	}{
+		{auth.SecurityLogout, auth.OutcomeSuccess},
+		{auth.SecurityTokenRefresh, auth.OutcomeSuccess},
+		{auth.SecurityLogin, auth.OutcomeSuccess},
+		{auth.SecurityLogin, auth.OutcomeFailure},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i, event := range events {
+		if event.Type != want[i].eventType || event.Outcome != want[i].outcome {
+			t.Errorf("event %d: expected %s %s, got %s %s", i, want[i].eventType, want[i].outcome, event.Type, event.Outcome)
+		}
+		if event.IPAddress != laptop.IPAddress || event.UserAgent != laptop.UserAgent {
+			t.Errorf("event %d: expected client %+v, got %s %s", i, laptop, event.IPAddress, event.UserAgent)
+		}
+	}
+	if events[3].Reason != "invalid_password" {
+		t.Errorf("expected the failed login to record invalid_password, got %q", events[3].Reason)
+	}
+
+	// Older events are paged with before
+	older, err := authService.ListSecurityActivity(user.ID, events[1].CreatedAt, 1)
+	if err != nil {
+		t.Fatalf("ListSecurityActivity failed: %v", err)
+	}
+	if len(older) != 1 || older[0].ID != events[2].ID {
+		t.Errorf("expected the page before the refresh to start with the login, got %+v", older)
+	}
+}
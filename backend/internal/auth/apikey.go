@@ -0,0 +1,325 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyHeader is the request header that carries an API key
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix marks Pavilion API keys so they are easy to spot in secret scanners
+const apiKeyPrefix = "pvk_"
+
+// apiKeyScopesContextKey holds the scopes of the API key that authenticated a request
+const apiKeyScopesContextKey = "apiKeyScopes"
+
+// apiKeyLastUsedInterval limits how often last-used timestamps are written
+const apiKeyLastUsedInterval = time.Minute
+
+// APIScope is an operation an API key is allowed to perform
+type APIScope string
+
+const (
+	// ScopeRead allows listing and viewing videos
+	ScopeRead APIScope = "read"
+	// ScopeUpload allows uploading and managing the owner's videos
+	ScopeUpload APIScope = "upload"
+	// ScopeAdmin grants every scope and the owner's role; only admins can create it
+	ScopeAdmin APIScope = "admin"
+)
+
+// IsValid reports whether s is a known scope
+func (s APIScope) IsValid() bool {
+	switch s {
+	case ScopeRead, ScopeUpload, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// APIScopes is a set of scopes stored as a comma-separated list
+type APIScopes []APIScope
+
+// Has reports whether the scopes allow the given operation
+func (s APIScopes) Has(scope APIScope) bool {
+	for _, granted := range s {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements driver.Valuer
+func (s APIScopes) Value() (driver.Value, error) {
+	parts := make([]string, len(s))
+	for i, scope := range s {
+		parts[i] = string(scope)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (s *APIScopes) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+		*s = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for APIScopes: %T", value)
+	}
+
+	*s = nil
+	for _, part := range strings.Split(raw, ",") {
+		if part != "" {
+			*s = append(*s, APIScope(part))
+		}
+	}
+	return nil
+}
+
+var ErrInvalidAPIKey = apierror.New(apierror.CodeUnauthorized, "invalid or expired API key")
+var ErrAPIKeyNotFound = apierror.New(apierror.CodeNotFound, "API key not found")
+var ErrInvalidAPIScope = apierror.Validation("scopes", "scopes must be read, upload or admin")
+var ErrAPIScopeNotAllowed = apierror.New(apierror.CodeForbidden, "only admins can create keys with the admin scope")
+
+// CreateAPIKey creates a key for the user and returns it together with the
+// plaintext key, which is not stored and cannot be retrieved again
+func (s *Service) CreateAPIKey(userID uuid.UUID, req CreateAPIKeyRequest) (*APIKey, string, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var user User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrUserNotFound
+		}
+		s.logger.LogError(err, "Failed to look up user for API key")
+		return nil, "", fmt.Errorf("failed to look up user: %v", err)
+	}
+	if scopes.Has(ScopeAdmin) && !user.Role.AtLeast(RoleAdmin) {
+		return nil, "", ErrAPIScopeNotAllowed
+	}
+
+	raw, err := generateAPIKey()
+	if err != nil {
+		s.logger.LogError(err, "Failed to generate API key")
+		return nil, "", fmt.Errorf("failed to generate API key: %v", err)
+	}
+
+	key := APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    raw[:len(apiKeyPrefix)+8],
+		KeyHash:   hashAPIKey(raw),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.db.Create(&key).Error; err != nil {
+		s.logger.LogError(err, "Failed to store API key")
+		return nil, "", fmt.Errorf("failed to store API key: %v", err)
+	}
+
+	s.logger.LogInfo("API key created", map[string]interface{}{
+		"userID": userID,
+		"keyID":  key.ID,
+		"scopes": scopes,
+	})
+
+	return &key, raw, nil
+}
+
+// ListAPIKeys returns the user's keys, newest first, including revoked ones
+func (s *Service) ListAPIKeys(userID uuid.UUID) ([]APIKey, error) {
+	var keys []APIKey
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		s.logger.LogError(err, "Failed to list API keys")
+		return nil, fmt.Errorf("failed to list API keys: %v", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes one of the user's keys. Revoking an already revoked key succeeds.
+func (s *Service) RevokeAPIKey(userID, keyID uuid.UUID) error {
+	var key APIKey
+	if err := s.db.Where("id = ? AND user_id = ?", keyID, userID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		s.logger.LogError(err, "Failed to look up API key")
+		return fmt.Errorf("failed to look up API key: %v", err)
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.db.Model(&key).Update("revoked_at", time.Now()).Error; err != nil {
+		s.logger.LogError(err, "Failed to revoke API key")
+		return fmt.Errorf("failed to revoke API key: %v", err)
+	}
+
+	s.logger.LogInfo("API key revoked", map[string]interface{}{
+		"userID": userID,
+		"keyID":  keyID,
+	})
+
+	return nil
+}
+
+// AuthenticateAPIKey validates a plaintext key and returns it with its owner
+func (s *Service) AuthenticateAPIKey(raw string) (*APIKey, *User, error) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var key APIKey
+	if err := s.db.Where("key_hash = ?", hashAPIKey(raw)).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		s.logger.LogError(err, "Failed to look up API key")
+		return nil, nil, fmt.Errorf("failed to look up API key: %v", err)
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var user User
+	if err := s.db.Where("id = ? AND active = ?", key.UserID, true).First(&user).Error; err != nil {
+		s.logger.LogWarn("API key used for missing or inactive user", map[string]interface{}{
+			"keyID":  key.ID,
+			"userID": key.UserID,
+		})
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	// Recording every use would add a write to each request
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyLastUsedInterval {
+		if err := s.db.Model(&key).Update("last_used_at", now).Error; err != nil {
+			s.logger.LogWarn("Failed to record API key use", map[string]interface{}{
+				"keyID": key.ID,
+				"error": err.Error(),
+			})
+		}
+	}
+
+	return &key, &user, nil
+}
+
+// APIKeyOrBearerMiddleware authenticates requests with an API key when the
+// X-API-Key header is present and with a bearer token otherwise. Requests made
+// with a key act as the key's owner, but only keep the owner's role when the
+// key has the admin scope.
+func APIKeyOrBearerMiddleware(service *Service, responseHandler ResponseHandler) gin.HandlerFunc {
+	bearer := AuthMiddleware(service, responseHandler)
+
+	return func(c *gin.Context) {
+		raw := c.GetHeader(APIKeyHeader)
+		if raw == "" {
+			bearer(c)
+			return
+		}
+
+		key, user, err := service.AuthenticateAPIKey(raw)
+		if err != nil {
+			responseHandler.UnauthorizedResponse(c, "Invalid API key")
+			c.Abort()
+			return
+		}
+
+		role := RoleUser
+		if key.Scopes.Has(ScopeAdmin) {
+			role = user.Role
+		}
+
+		c.Set("userID", user.ID.String())
+		c.Set("email", user.Email)
+		c.Set("role", string(role))
+		c.Set(apiKeyScopesContextKey, key.Scopes)
+		c.Next()
+	}
+}
+
+// RequireScope rejects API key requests whose key lacks the scope. Requests
+// authenticated with a bearer token are let through, since a user session
+// is not limited by scopes.
+func RequireScope(scope APIScope, responseHandler ResponseHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(apiKeyScopesContextKey)
+		if !exists {
+			c.Next()
+			return
+		}
+
+		if scopes, ok := value.(APIScopes); !ok || !scopes.Has(scope) {
+			responseHandler.ForbiddenResponse(c, fmt.Sprintf("This API key requires the %s scope", scope))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// normalizeScopes validates the requested scopes and removes duplicates
+func normalizeScopes(requested []APIScope) (APIScopes, error) {
+	if len(requested) == 0 {
+		return nil, ErrInvalidAPIScope
+	}
+
+	var scopes APIScopes
+	seen := make(map[APIScope]bool)
+	for _, scope := range requested {
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAPIScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// generateAPIKey returns a new random key
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIKey returns the stored form of a key. Keys are random and long, so a
+// fast hash is enough; unlike passwords they cannot be guessed from a dictionary.
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
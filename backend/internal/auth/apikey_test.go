@@ -0,0 +1,151 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAPIScopes(t *testing.T) {
+	scopes := auth.APIScopes{auth.ScopeRead}
+	if !scopes.Has(auth.ScopeRead) || scopes.Has(auth.ScopeUpload) {
+		t.Errorf("Read-only scopes should only allow reads")
+	}
+
+	admin := auth.APIScopes{auth.ScopeAdmin}
+	if !admin.Has(auth.ScopeRead) || !admin.Has(auth.ScopeUpload) {
+		t.Errorf("The admin scope should allow every operation")
+	}
+
+	value, err := auth.APIScopes{auth.ScopeRead, auth.ScopeUpload}.Value()
+	if err != nil {
+		t.Fatalf("Value failed: %v", err)
+	}
+	var scanned auth.APIScopes
+	if err := scanned.Scan(value); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(scanned) != 2 || scanned[0] != auth.ScopeRead || scanned[1] != auth.ScopeUpload {
+		t.Errorf("Expected [read upload] after round trip, got %v", scanned)
+	}
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	router, authService, _ := setupTestRouter(t)
+
+	log, _ := logger.NewLogger(logger.DefaultConfig())
+	responseHandler := httpHandler.NewResponseHandler(log)
+	router.POST("/scoped/upload",
+		auth.APIKeyOrBearerMiddleware(authService, responseHandler),
+		auth.RequireScope(auth.ScopeUpload, responseHandler),
+		func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"userID": c.GetString("userID"), "role": c.GetString("role")})
+		})
+
+	user, err := authService.Register(auth.RegisterRequest{
+		Username: "apikeyowner",
+		Email:    "apikeyowner@example.com",
+		Password: "Pass123!",
+	})
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	if err := authService.MarkEmailVerified(user.ID); err != nil {
+		t.Fatalf("Failed to verify user: %v", err)
+	}
+	login, err := authService.Login(user.Email, "Pass123!", auth.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	callUpload := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/scoped/upload", nil)
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var created auth.CreateAPIKeyResponse
+	t.Run("Create key", func(t *testing.T) {
+		body, _ := json.Marshal(auth.CreateAPIKeyRequest{Name: "upload-bot", Scopes: []auth.APIScope{auth.ScopeUpload}})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/apikeys", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+login.AccessToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response struct {
+			Data auth.CreateAPIKeyResponse `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		created = response.Data
+		if !strings.HasPrefix(created.Key, created.Prefix) {
+			t.Errorf("Expected key to start with prefix %q", created.Prefix)
+		}
+	})
+
+	t.Run("Key authenticates as its owner", func(t *testing.T) {
+		w := callUpload(auth.APIKeyHeader, created.Key)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), user.ID.String()) {
+			t.Errorf("Expected request to act as the key owner, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("Missing scope is forbidden", func(t *testing.T) {
+		_, readKey, err := authService.CreateAPIKey(user.ID, auth.CreateAPIKeyRequest{Name: "reader", Scopes: []auth.APIScope{auth.ScopeRead}})
+		if err != nil {
+			t.Fatalf("Failed to create read key: %v", err)
+		}
+		if w := callUpload(auth.APIKeyHeader, readKey); w.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", w.Code)
+		}
+	})
+
+	t.Run("Bearer tokens are not limited by scopes", func(t *testing.T) {
+		if w := callUpload("Authorization", "Bearer "+login.AccessToken); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("Only admins can create admin keys", func(t *testing.T) {
+		_, _, err := authService.CreateAPIKey(user.ID, auth.CreateAPIKeyRequest{Name: "admin", Scopes: []auth.APIScope{auth.ScopeAdmin}})
+		if !errors.Is(err, auth.ErrAPIScopeNotAllowed) {
+			t.Errorf("Expected ErrAPIScopeNotAllowed, got %v", err)
+		}
+	})
+
+	t.Run("Revoked key is rejected", func(t *testing.T) {
+		if err := authService.RevokeAPIKey(user.ID, created.ID); err != nil {
+			t.Fatalf("Failed to revoke key: %v", err)
+		}
+		if w := callUpload(auth.APIKeyHeader, created.Key); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+
+		keys, err := authService.ListAPIKeys(user.ID)
+		if err != nil {
+			t.Fatalf("Failed to list keys: %v", err)
+		}
+		if len(keys) != 2 {
+			t.Errorf("Expected 2 keys including the revoked one, got %d", len(keys))
+		}
+	})
+}
@@ -0,0 +1,461 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// BulkFormat is the file format of a bulk user import or export
+type BulkFormat string
+
+const (
+	FormatCSV  BulkFormat = "csv"
+	FormatJSON BulkFormat = "json"
+)
+
+// DuplicatePolicy decides what a bulk import does with a record whose email
+// or username belongs to an existing account
+type DuplicatePolicy string
+
+const (
+	// DuplicateSkip leaves the existing account untouched
+	DuplicateSkip DuplicatePolicy = "skip"
+	// DuplicateUpdate updates the name, password and email verification of the
+	// account with the same email. Roles are never changed by an import.
+	DuplicateUpdate DuplicatePolicy = "update"
+	// DuplicateFail reports the record as failed
+	DuplicateFail DuplicatePolicy = "fail"
+)
+
+// ImportStatus is the outcome of importing one record
+type ImportStatus string
+
+const (
+	ImportCreated ImportStatus = "created"
+	ImportUpdated ImportStatus = "updated"
+	ImportSkipped ImportStatus = "skipped"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// MaxImportRecords caps the number of records in one bulk import
+const MaxImportRecords = 10000
+
+// maxImportBytes caps the size of an uploaded import file
+const maxImportBytes = 10 << 20
+
+var ErrInvalidImport = apierror.New(apierror.CodeInvalidImport, "invalid import file")
+var ErrInvalidDuplicatePolicy = apierror.Validation("onDuplicate", "onDuplicate must be one of skip, update or fail")
+
+// exportColumns are the CSV columns written by WriteUserExport
+var exportColumns = []string{"id", "username", "email", "name", "role", "emailVerified", "active", "createdAt", "passwordHash"}
+
+// ParseUserImport reads the records of a bulk import. CSV files need a header
+// row naming their columns (username, email, name, role, passwordHash,
+// password and emailVerified; others, such as the extra columns of an
+// export, are ignored). JSON files hold an array of records.
+func ParseUserImport(r io.Reader, format BulkFormat) ([]ImportUser, error) {
+	var users []ImportUser
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&users); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+	case FormatCSV:
+		var err error
+		if users, err = parseUserCSV(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, format)
+	}
+
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w: no records", ErrInvalidImport)
+	}
+	if len(users) > MaxImportRecords {
+		return nil, fmt.Errorf("%w: %d records exceed the limit of %d", ErrInvalidImport, len(users), MaxImportRecords)
+	}
+	return users, nil
+}
+
+// parseUserCSV reads import records from a CSV file with a header row
+func parseUserCSV(r io.Reader) ([]ImportUser, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row: %v", ErrInvalidImport, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.TrimSpace(column)] = i
+	}
+	for _, required := range []string{"username", "email"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("%w: missing %s column", ErrInvalidImport, required)
+		}
+	}
+
+	var users []ImportUser
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if len(users) == MaxImportRecords {
+			return nil, fmt.Errorf("%w: more than %d records", ErrInvalidImport, MaxImportRecords)
+		}
+
+		field := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		verified, _ := strconv.ParseBool(field("emailVerified"))
+		users = append(users, ImportUser{
+			Username:      field("username"),
+			Email:         field("email"),
+			Name:          field("name"),
+			Role:          Role(field("role")),
+			PasswordHash:  field("passwordHash"),
+			Password:      field("password"),
+			EmailVerified: verified,
+		})
+	}
+	return users, nil
+}
+
+// WriteUserExport writes exported users as a CSV file with a header row or as a JSON array
+func WriteUserExport(w io.Writer, users []ExportUser, format BulkFormat) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(users)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportColumns); err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := writer.Write([]string{
+				user.ID,
+				user.Username,
+				user.Email,
+				user.Name,
+				string(user.Role),
+				strconv.FormatBool(user.EmailVerified),
+				strconv.FormatBool(user.Active),
+				user.CreatedAt.UTC().Format(time.RFC3339),
+				user.PasswordHash,
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// ImportUsers creates accounts from bulk import records, typically when
+// migrating from another platform. Every record is validated and imported on
+// its own, so one bad record does not stop the rest; the report lists the
+// outcome of each.
+func (s *Service) ImportUsers(actorID uuid.UUID, users []ImportUser, opts ImportOptions) (*ImportReport, error) {
+	switch opts.OnDuplicate {
+	case "":
+		opts.OnDuplicate = DuplicateSkip
+	case DuplicateSkip, DuplicateUpdate, DuplicateFail:
+	default:
+		return nil, ErrInvalidDuplicatePolicy
+	}
+
+	report := &ImportReport{Total: len(users), Results: make([]ImportResult, 0, len(users))}
+	// Emails and usernames seen earlier in the file, mapped to their row
+	seenEmails := make(map[string]int, len(users))
+	seenUsernames := make(map[string]int, len(users))
+
+	for i := range users {
+		row := i + 1
+		record := normalizeImportUser(users[i])
+		result := ImportResult{Row: row, Username: record.Username, Email: record.Email}
+
+		if err := validateImportUser(&record); err != nil {
+			result.Status, result.Error = ImportFailed, err.Error()
+		} else if first, ok := seenEmails[record.Email]; ok {
+			result.Status, result.Error = ImportFailed, fmt.Sprintf("email duplicates row %d", first)
+		} else if first, ok := seenUsernames[record.Username]; ok {
+			result.Status, result.Error = ImportFailed, fmt.Sprintf("username duplicates row %d", first)
+		} else {
+			seenEmails[record.Email] = row
+			seenUsernames[record.Username] = row
+			s.importUser(&record, opts, &result)
+		}
+
+		switch result.Status {
+		case ImportCreated:
+			report.Created++
+		case ImportUpdated:
+			report.Updated++
+		case ImportSkipped:
+			report.Skipped++
+		case ImportFailed:
+			report.Failed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	s.logger.LogInfo("Bulk user import finished", map[string]interface{}{
+		"actorID": actorID,
+		"total":   report.Total,
+		"created": report.Created,
+		"updated": report.Updated,
+		"skipped": report.Skipped,
+		"failed":  report.Failed,
+	})
+
+	return report, nil
+}
+
+// importUser imports one validated record, filling in its result
+func (s *Service) importUser(record *ImportUser, opts ImportOptions, result *ImportResult) {
+	var existing User
+	err := s.db.Where("email = ? OR username = ?", record.Email, record.Username).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.LogError(err, "Failed to check existing user during import")
+		result.Status, result.Error = ImportFailed, "failed to check for an existing account"
+		return
+	}
+
+	if err == nil {
+		switch {
+		case opts.OnDuplicate == DuplicateSkip:
+			result.Status, result.Error = ImportSkipped, "user already exists"
+		case opts.OnDuplicate == DuplicateUpdate && existing.Email == record.Email && existing.Username == record.Username:
+			s.updateImportedUser(&existing, record, result)
+		case opts.OnDuplicate == DuplicateUpdate:
+			result.Status, result.Error = ImportFailed, "email and username belong to different accounts"
+		default:
+			result.Status, result.Error = ImportFailed, "user already exists"
+		}
+		return
+	}
+
+	hash, err := importPasswordHash(record)
+	if err != nil {
+		s.logger.LogError(err, "Failed to hash imported password")
+		result.Status, result.Error = ImportFailed, "failed to hash password"
+		return
+	}
+
+	user := User{
+		Username:      record.Username,
+		Email:         record.Email,
+		Password:      hash,
+		Name:          record.Name,
+		EmailVerified: record.EmailVerified,
+		Role:          record.Role,
+		Active:        true,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := s.db.Create(&user).Error; err != nil {
+		s.logger.LogError(err, "Failed to create imported user")
+		result.Status, result.Error = ImportFailed, "failed to create user"
+		return
+	}
+	result.Status, result.UserID = ImportCreated, user.ID.String()
+
+	if opts.SendWelcome {
+		result.WelcomeSent = s.sendWelcomeEmail(&user, record.PasswordHash != "")
+	}
+}
+
+// updateImportedUser applies a record to the existing account with the same email and username
+func (s *Service) updateImportedUser(user *User, record *ImportUser, result *ImportResult) {
+	updates := map[string]interface{}{"name": record.Name}
+	if record.EmailVerified {
+		updates["email_verified"] = true
+	}
+	passwordChanged := record.PasswordHash != "" || record.Password != ""
+	if passwordChanged {
+		hash, err := importPasswordHash(record)
+		if err != nil {
+			s.logger.LogError(err, "Failed to hash imported password")
+			result.Status, result.Error = ImportFailed, "failed to hash password"
+			return
+		}
+		updates["password"] = hash
+	}
+
+	if err := s.db.Model(user).Updates(updates).Error; err != nil {
+		s.logger.LogError(err, "Failed to update imported user")
+		result.Status, result.Error = ImportFailed, "failed to update user"
+		return
+	}
+	// Sessions started with the old password should not outlive it
+	if passwordChanged {
+		if err := s.refreshTokens.RevokeAllUserTokens(user.ID); err != nil {
+			s.logger.LogError(err, "Failed to revoke refresh tokens after import")
+		}
+		s.access.forget(user.ID)
+	}
+	result.Status, result.UserID = ImportUpdated, user.ID.String()
+}
+
+// normalizeImportUser trims a record and lowercases its email
+func normalizeImportUser(record ImportUser) ImportUser {
+	record.Username = strings.TrimSpace(record.Username)
+	record.Email = strings.ToLower(strings.TrimSpace(record.Email))
+	record.Name = strings.TrimSpace(record.Name)
+	record.Role = Role(strings.ToLower(strings.TrimSpace(string(record.Role))))
+	record.PasswordHash = strings.TrimSpace(record.PasswordHash)
+	if record.Role == "" {
+		record.Role = RoleUser
+	}
+	return record
+}
+
+// validateImportUser checks a normalized record before it is imported
+func validateImportUser(record *ImportUser) error {
+	if record.Username == "" {
+		return errors.New("username is required")
+	}
+	if address, err := mail.ParseAddress(record.Email); err != nil || address.Address != record.Email {
+		return errors.New("email is invalid")
+	}
+	if !record.Role.IsValid() {
+		return errors.New("role must be one of user, moderator or admin")
+	}
+	if record.PasswordHash != "" && record.Password != "" {
+		return errors.New("give either passwordHash or password, not both")
+	}
+	if record.PasswordHash != "" {
+		if _, err := bcrypt.Cost([]byte(record.PasswordHash)); err != nil {
+			return errors.New("passwordHash must be a bcrypt hash")
+		}
+	}
+	if record.Password != "" {
+		if err := validatePassword(record.Password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importPasswordHash returns the password hash to store for a record. Records
+// without a password get a random one nobody knows, so the account can only
+// be used after a password reset.
+func importPasswordHash(record *ImportUser) (string, error) {
+	if record.PasswordHash != "" {
+		return record.PasswordHash, nil
+	}
+
+	password := record.Password
+	if password == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return "", err
+		}
+		password = hex.EncodeToString(random)
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// sendWelcomeEmail welcomes an imported user with a link to set their
+// password, and asks unverified users to verify their email. It reports
+// whether the welcome email was sent; failures are only logged.
+func (s *Service) sendWelcomeEmail(user *User, keepsPassword bool) bool {
+	if s.mailer == nil {
+		s.logger.LogWarn("No mailer configured, cannot send welcome email", map[string]interface{}{
+			"userID": user.ID,
+		})
+		return false
+	}
+
+	token, err := s.generatePasswordResetToken(user)
+	if err != nil {
+		s.logger.LogError(err, "Failed to generate password token for welcome email")
+		return false
+	}
+
+	link := s.config.PasswordReset.URL + "?token=" + url.QueryEscape(token)
+	instructions := "Open the link below to choose your password."
+	if keepsPassword {
+		instructions = "You can sign in with your existing password, or open the link below to choose a new one."
+	}
+	body := fmt.Sprintf("Hi %s,\n\nYour account has been moved to Pavilion. %s "+
+		"The link expires in %s.\n\n%s\n\n"+
+		"If you did not expect this email, you can ignore it.\n",
+		user.Username, instructions, s.config.PasswordReset.TokenTTL, link)
+
+	if err := s.mailer.Send(context.Background(), user.Email, "Welcome to Pavilion", body); err != nil {
+		s.logger.LogError(err, "Failed to send welcome email")
+		return false
+	}
+
+	if !user.EmailVerified {
+		if err := s.sendVerificationEmail(user); err != nil {
+			s.logger.LogWarn("Failed to send verification email after import", map[string]interface{}{
+				"userID": user.ID,
+				"error":  err.Error(),
+			})
+		}
+	}
+
+	return true
+}
+
+// ExportUsers returns all users for a bulk export, optionally filtered by
+// role. Password hashes are only included when includePasswordHashes is set.
+func (s *Service) ExportUsers(role Role, includePasswordHashes bool) ([]ExportUser, error) {
+	users, err := s.ListUsers(role)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]ExportUser, 0, len(users))
+	for _, user := range users {
+		record := ExportUser{
+			ID:            user.ID.String(),
+			Username:      user.Username,
+			Email:         user.Email,
+			Name:          user.Name,
+			Role:          user.Role,
+			EmailVerified: user.EmailVerified,
+			Active:        user.Active,
+			CreatedAt:     user.CreatedAt,
+		}
+		if includePasswordHashes {
+			record.PasswordHash = user.Password
+		}
+		exported = append(exported, record)
+	}
+	return exported, nil
+}
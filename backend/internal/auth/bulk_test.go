@@ -0,0 +1,177 @@
+package auth_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestParseUserImport(t *testing.T) {
+	csvFile := "email,username,name,emailVerified,ignored\n" +
+		"alice@example.com,alice,Alice,true,x\n" +
+		"bob@example.com,bob,\"Bob, Jr.\",,y\n"
+	users, err := auth.ParseUserImport(strings.NewReader(csvFile), auth.FormatCSV)
+	if err != nil {
+		t.Fatalf("ParseUserImport(csv) failed: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(users))
+	}
+	if users[0].Username != "alice" || !users[0].EmailVerified {
+		t.Errorf("unexpected first record: %+v", users[0])
+	}
+	if users[1].Name != "Bob, Jr." || users[1].EmailVerified {
+		t.Errorf("unexpected second record: %+v", users[1])
+	}
+
+	jsonFile := `[{"username":"carol","email":"carol@example.com","role":"moderator"}]`
+	users, err = auth.ParseUserImport(strings.NewReader(jsonFile), auth.FormatJSON)
+	if err != nil {
+		t.Fatalf("ParseUserImport(json) failed: %v", err)
+	}
+	if len(users) != 1 || users[0].Role != auth.RoleModerator {
+		t.Errorf("unexpected records: %+v", users)
+	}
+
+	for name, input := range map[string]struct {
+		body   string
+		format auth.BulkFormat
+	}{
+		"missing email column": {"username,name\nalice,Alice\n", auth.FormatCSV},
+		"no records":           {"username,email\n", auth.FormatCSV},
+		"malformed json":       {`{"username":`, auth.FormatJSON},
+		"unknown format":       {"", auth.BulkFormat("xml")},
+	} {
+		if _, err := auth.ParseUserImport(strings.NewReader(input.body), input.format); !errors.Is(err, auth.ErrInvalidImport) {
+			t.Errorf("%s: expected ErrInvalidImport, got %v", name, err)
+		}
+	}
+}
+
+func TestWriteUserExportRoundTrip(t *testing.T) {
+	exported := []auth.ExportUser{
+		{ID: uuid.New().String(), Username: "alice", Email: "alice@example.com", Name: "Alice", Role: auth.RoleAdmin, EmailVerified: true, Active: true, PasswordHash: "$2a$10$hash"},
+	}
+
+	for _, format := range []auth.BulkFormat{auth.FormatCSV, auth.FormatJSON} {
+		var buf bytes.Buffer
+		if err := auth.WriteUserExport(&buf, exported, format); err != nil {
+			t.Fatalf("WriteUserExport(%s) failed: %v", format, err)
+		}
+
+		// An export can be imported again
+		users, err := auth.ParseUserImport(&buf, format)
+		if err != nil {
+			t.Fatalf("ParseUserImport(%s) failed: %v", format, err)
+		}
+		want := auth.ImportUser{Username: "alice", Email: "alice@example.com", Name: "Alice", Role: auth.RoleAdmin, PasswordHash: "$2a$10$hash", EmailVerified: true}
+		if len(users) != 1 || users[0] != want {
+			t.Errorf("%s: expected %+v, got %+v", format, want, users)
+		}
+	}
+}
+
+func TestImportUsers(t *testing.T) {
+	db := testhelper.SetupTestDB(t)
+	logger := testhelper.NewTestLogger(true)
+
+	config := newTestConfig("test-secret-" + uuid.New().String())
+	jwtService := auth.NewJWTService(config)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db, logger)
+	authService := auth.NewService(db, jwtService, refreshTokenRepo, config, logger)
+	mailer := &captureMailer{}
+	authService.SetMailer(mailer)
+
+	existing, err := authService.Register(auth.RegisterRequest{
+		Username: "existing",
+		Email:    "existing@example.com",
+		Password: "Pass123!",
+		Name:     "Existing User",
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Migrated1!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword failed: %v", err)
+	}
+
+	records := []auth.ImportUser{
+		{Username: "migrated", Email: "Migrated@Example.com", PasswordHash: string(hash), EmailVerified: true},
+		{Username: "tempuser", Email: "temp@example.com", Password: "TempPass1!", EmailVerified: true},
+		{Username: "invited", Email: "invited@example.com", Role: auth.RoleModerator},
+		{Username: "existing", Email: "existing@example.com", Name: "Renamed"},
+		{Username: "again", Email: "migrated@example.com"},
+		{Username: "", Email: "nousername@example.com"},
+		{Username: "badhash", Email: "badhash@example.com", PasswordHash: "plaintext"},
+		{Username: "weak", Email: "weak@example.com", Password: "weak"},
+	}
+
+	report, err := authService.ImportUsers(uuid.New(), records, auth.ImportOptions{SendWelcome: true})
+	if err != nil {
+		t.Fatalf("ImportUsers failed: %v", err)
+	}
+
+	wantStatus := []auth.ImportStatus{
+		auth.ImportCreated, auth.ImportCreated, auth.ImportCreated,
+		auth.ImportSkipped,
+		auth.ImportFailed, auth.ImportFailed, auth.ImportFailed, auth.ImportFailed,
+	}
+	for i, result := range report.Results {
+		if result.Status != wantStatus[i] {
+			t.Errorf("row %d: expected %s, got %s (%s)", result.Row, wantStatus[i], result.Status, result.Error)
+		}
+	}
+	if report.Created != 3 || report.Skipped != 1 || report.Failed != 4 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	if !report.Results[2].WelcomeSent {
+		t.Error("expected a welcome email for the created user")
+	}
+
+	// Pre-hashed and temporary passwords work right away
+	if _, err := authService.Login("migrated@example.com", "Migrated1!", auth.ClientInfo{}); err != nil {
+		t.Errorf("expected migrated user to log in with their old password, got %v", err)
+	}
+	if _, err := authService.Login("temp@example.com", "TempPass1!", auth.ClientInfo{}); err != nil {
+		t.Errorf("expected temporary password to work, got %v", err)
+	}
+
+	// Updating only touches the account with the same email and username
+	report, err = authService.ImportUsers(uuid.New(), []auth.ImportUser{
+		{Username: "existing", Email: "existing@example.com", Name: "Renamed", Role: auth.RoleAdmin},
+		{Username: "existing", Email: "other@example.com"},
+	}, auth.ImportOptions{OnDuplicate: auth.DuplicateUpdate})
+	if err != nil {
+		t.Fatalf("ImportUsers failed: %v", err)
+	}
+	if report.Updated != 1 || report.Failed != 1 {
+		t.Errorf("unexpected counts: %+v", report)
+	}
+	var updated auth.User
+	if err := db.First(&updated, "id = ?", existing.ID).Error; err != nil {
+		t.Fatalf("failed to load updated user: %v", err)
+	}
+	if updated.Name != "Renamed" || updated.Role != auth.RoleUser {
+		t.Errorf("expected name updated and role unchanged, got %q and %q", updated.Name, updated.Role)
+	}
+
+	if _, err := authService.ImportUsers(uuid.New(), records, auth.ImportOptions{OnDuplicate: "merge"}); !errors.Is(err, auth.ErrInvalidDuplicatePolicy) {
+		t.Errorf("expected ErrInvalidDuplicatePolicy for an unknown duplicate policy, got %v", err)
+	}
+
+	exported, err := authService.ExportUsers(auth.RoleModerator, false)
+	if err != nil {
+		t.Fatalf("ExportUsers failed: %v", err)
+	}
+	if len(exported) != 1 || exported[0].Username != "invited" || exported[0].PasswordHash != "" {
+		t.Errorf("unexpected export: %+v", exported)
+	}
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+)
+
+// emailVerificationPurpose marks tokens that may only be used to verify an email address
+const emailVerificationPurpose = "email_verification"
+
+var ErrInvalidVerificationToken = apierror.New(apierror.CodeInvalidToken, "invalid or expired email verification token")
+var ErrVerificationRateLimited = apierror.New(apierror.CodeRateLimited, "verification email was sent recently, please wait before requesting another")
+
+// VerifyEmail marks the account a verification token was issued for as verified
+func (s *Service) VerifyEmail(token string) error {
+	user, err := s.parseActionToken(token, emailVerificationPurpose, s.verificationSigningKey)
+	if err != nil {
+		s.logger.LogWarn("Invalid email verification token", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return ErrInvalidVerificationToken
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	return s.MarkEmailVerified(user.ID)
+}
+
+// ResendVerification sends a new verification email to an unverified account.
+// Unknown and already verified addresses succeed silently.
+func (s *Service) ResendVerification(email string) error {
+	var user User
+	if err := s.db.Where("email = ? AND active = ?", email, true).First(&user).Error; err != nil {
+		s.logger.LogWarn("Verification resend requested for unknown account", map[string]interface{}{
+			"email": email,
+		})
+		return nil
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < s.config.EmailVerification.ResendCooldown {
+		s.logger.LogWarn("Verification resend throttled", map[string]interface{}{
+			"userID": user.ID,
+		})
+		return ErrVerificationRateLimited
+	}
+
+	return s.sendVerificationEmail(&user)
+}
+
+// sendVerificationEmail emails a verification link to the user and records when it was sent
+func (s *Service) sendVerificationEmail(user *User) error {
+	if s.mailer == nil {
+		s.logger.LogWarn("No mailer configured, cannot send verification email", map[string]interface{}{
+			"userID": user.ID,
+		})
+		return nil
+	}
+
+	token, err := s.signActionToken(user, emailVerificationPurpose, s.config.EmailVerification.TokenTTL, s.verificationSigningKey)
+	if err != nil {
+		s.logger.LogError(err, "Failed to generate email verification token")
+		return fmt.Errorf("failed to generate email verification token: %v", err)
+	}
+
+	link := s.config.EmailVerification.URL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nWelcome to Pavilion! Please confirm your email address by opening the link below. "+
+		"The link expires in %s.\n\n%s\n\n"+
+		"If you did not create an account, you can ignore this email.\n",
+		user.Username, s.config.EmailVerification.TokenTTL, link)
+
+	if err := s.mailer.Send(context.Background(), user.Email, "Verify your Pavilion email address", body); err != nil {
+		s.logger.LogError(err, "Failed to send verification email")
+		return fmt.Errorf("failed to send verification email: %v", err)
+	}
+
+	now := time.Now()
+	if err := s.db.Model(user).Update("verification_sent_at", now).Error; err != nil {
+		s.logger.LogError(err, "Failed to record verification email timestamp")
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+	user.VerificationSentAt = &now
+
+	s.logger.LogInfo("Verification email sent", map[string]interface{}{
+		"userID": user.ID,
+	})
+
+	return nil
+}
+
+// verificationSigningKey derives the signing key for a user's verification
+// tokens. Including the email address means a token stops working if the
+// address on the account changes.
+func (s *Service) verificationSigningKey(user *User) []byte {
+	return []byte(s.config.JWT.Secret + "|" + emailVerificationPurpose + "|" + user.Email)
+}
@@ -0,0 +1,87 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+)
+
+func TestEmailVerification(t *testing.T) {
+	db := testhelper.SetupTestDB(t)
+	logger := testhelper.NewTestLogger(true)
+
+	config := newTestConfig("test-secret-" + uuid.New().String())
+	config.EmailVerification.TokenTTL = time.Hour
+	config.EmailVerification.URL = "http://localhost:8080/api/v1/auth/verify-email"
+	config.EmailVerification.ResendCooldown = time.Minute
+
+	jwtService := auth.NewJWTService(config)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db, logger)
+	authService := auth.NewService(db, jwtService, refreshTokenRepo, config, logger)
+	mailer := &captureMailer{}
+	authService.SetMailer(mailer)
+
+	regReq := auth.RegisterRequest{
+		Username: "verifyuser",
+		Email:    "verify@example.com",
+		Password: "Pass123!",
+		Name:     "Verify User",
+	}
+	user, err := authService.Register(regReq)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	t.Run("Registration sends verification email", func(t *testing.T) {
+		if mailer.to != regReq.Email {
+			t.Fatalf("expected verification email to %s, got %q", regReq.Email, mailer.to)
+		}
+	})
+
+	t.Run("Resend is throttled", func(t *testing.T) {
+		if err := authService.ResendVerification(regReq.Email); !errors.Is(err, auth.ErrVerificationRateLimited) {
+			t.Errorf("expected ErrVerificationRateLimited, got %v", err)
+		}
+	})
+
+	t.Run("Invalid token is rejected", func(t *testing.T) {
+		if err := authService.VerifyEmail("not-a-token"); !errors.Is(err, auth.ErrInvalidVerificationToken) {
+			t.Errorf("expected ErrInvalidVerificationToken, got %v", err)
+		}
+	})
+
+	t.Run("Valid token verifies the account", func(t *testing.T) {
+		token := tokenFromBody(t, mailer.body)
+		if err := authService.VerifyEmail(token); err != nil {
+			t.Fatalf("VerifyEmail failed: %v", err)
+		}
+
+		var stored auth.User
+		if err := db.First(&stored, "id = ?", user.ID).Error; err != nil {
+			t.Fatalf("failed to load user: %v", err)
+		}
+		if !stored.EmailVerified {
+			t.Error("expected email to be verified")
+		}
+	})
+
+	t.Run("Resend for verified account is a no-op", func(t *testing.T) {
+		mailer.to = ""
+		if err := authService.ResendVerification(regReq.Email); err != nil {
+			t.Fatalf("ResendVerification failed: %v", err)
+		}
+		if mailer.to != "" {
+			t.Error("expected no email for an already verified account")
+		}
+	})
+
+	t.Run("Resend for unknown account succeeds silently", func(t *testing.T) {
+		if err := authService.ResendVerification("nobody@example.com"); err != nil {
+			t.Errorf("expected nil error, got %v", err)
+		}
+	})
+}
@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"errors"
 	stdhttp "net/http"
+	"strconv"
+	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -11,6 +16,9 @@ import (
 type Handler struct {
 	service         *Service
 	responseHandler ResponseHandler
+	rateLimit       func(rule string) gin.HandlerFunc
+	// oauthPath scopes the OAuth state cookie to the OAuth routes
+	oauthPath string
 }
 
 // NewHandler creates a new auth handler instance
@@ -18,22 +26,69 @@ func NewHandler(service *Service, responseHandler ResponseHandler) *Handler {
 	return &Handler{
 		service:         service,
 		responseHandler: responseHandler,
+		oauthPath:       "/auth/oauth",
 	}
 }
 
+// SetRateLimiter throttles the login, registration and account recovery
+// routes with the rules of the given names. It must be called before RegisterRoutes.
+func (h *Handler) SetRateLimiter(limit func(rule string) gin.HandlerFunc) {
+	h.rateLimit = limit
+}
+
+// limit returns the rate limit middleware for a rule, or a no-op without a rate limiter
+func (h *Handler) limit(rule string) gin.HandlerFunc {
+	if h.rateLimit == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return h.rateLimit(rule)
+}
+
 // RegisterRoutes registers all auth routes
-func (h *Handler) RegisterRoutes(router *gin.Engine) {
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
 	auth := router.Group("/auth")
+	h.oauthPath = auth.BasePath() + "/oauth"
 	{
 		// Public routes
-		auth.POST("/login", h.handleLogin)
-		auth.POST("/register", h.handleRegister)
+		auth.POST("/login", h.limit("login"), h.handleLogin)
+		auth.POST("/register", h.limit("register"), h.handleRegister)
 		auth.POST("/refresh", h.handleRefresh)
+		auth.POST("/forgot-password", h.limit("recovery"), h.handleForgotPassword)
+		auth.POST("/reset-password", h.limit("recovery"), h.handleResetPassword)
+		auth.GET("/verify-email", h.handleVerifyEmail)
+		auth.POST("/resend-verification", h.limit("recovery"), h.handleResendVerification)
+		auth.GET("/oauth/:provider/login", h.handleOAuthLogin)
+		auth.GET("/oauth/:provider/callback", h.handleOAuthCallback)
 
 		// Protected routes (require authentication)
 		protected := auth.Group("")
 		protected.Use(AuthMiddleware(h.service, h.responseHandler))
 		protected.POST("/logout", h.handleLogout)
+		protected.POST("/apikeys", h.handleCreateAPIKey)
+		protected.GET("/apikeys", h.handleListAPIKeys)
+		protected.DELETE("/apikeys/:id", h.handleRevokeAPIKey)
+		protected.GET("/sessions", h.handleListSessions)
+		protected.DELETE("/sessions", h.handleRevokeOtherSessions)
+		protected.DELETE("/sessions/:id", h.handleRevokeSession)
+	}
+
+	// Account activity of the signed-in user
+	security := router.Group("/me/security")
+	security.Use(AuthMiddleware(h.service, h.responseHandler))
+	{
+		security.GET("/activity", h.handleListSecurityActivity)
+	}
+
+	// Role management (admin only)
+	admin := router.Group("/admin/users")
+	admin.Use(AuthMiddleware(h.service, h.responseHandler), RequireRole(RoleAdmin, h.responseHandler))
+	{
+		admin.GET("", h.handleListUsers)
+		admin.PUT("/:id/role", h.handleUpdateRole)
+		admin.POST("/:id/suspend", h.handleSuspendUser)
+		admin.DELETE("/:id/suspend", h.handleReinstateUser)
+		admin.POST("/import", h.handleImportUsers)
+		admin.GET("/export", h.handleExportUsers)
 	}
 }
 
@@ -46,17 +101,24 @@ func (h *Handler) RegisterRoutes(router *gin.Engine) {
 // @Success 200 {object} http.APIResponse{data=LoginResponse} "Login successful"
 // @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid request format"
 // @Failure 401 {object} http.APIResponse{error=http.APIError} "Invalid credentials"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Email not verified"
+// @Failure 423 {object} http.APIResponse{error=http.APIError} "Account temporarily locked; see the Retry-After header"
+// @Failure 429 {object} http.APIResponse{error=http.APIError} "Too many requests"
 // @Router /auth/login [post]
 func (h *Handler) handleLogin(c *gin.Context) {
 	var req LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.responseHandler.ValidationErrorResponse(c, "request", "Invalid request format")
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
-	response, err := h.service.Login(req.Email, req.Password)
+	response, err := h.service.Login(req.Email, req.Password, clientInfo(c))
 	if err != nil {
-		h.responseHandler.ErrorResponse(c, stdhttp.StatusUnauthorized, "AUTH_ERROR", err.Error(), err)
+		var locked *AccountLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter(time.Now())/time.Second)))
+		}
+		apierror.Abort(c, err, "Login failed")
 		return
 	}
 
@@ -71,17 +133,19 @@ func (h *Handler) handleLogin(c *gin.Context) {
 // @Param request body RegisterRequest true "Registration details"
 // @Success 200 {object} http.APIResponse{data=User} "Registration successful"
 // @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid request format or user already exists"
+// @Failure 429 {object} http.APIResponse{error=http.APIError} "Too many requests"
+// @Failure 500 {object} http.APIResponse{error=http.APIError} "Internal server error"
 // @Router /auth/register [post]
 func (h *Handler) handleRegister(c *gin.Context) {
 	var req RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.responseHandler.ValidationErrorResponse(c, "request", "Invalid request format")
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
 	user, err := h.service.Register(req)
 	if err != nil {
-		h.responseHandler.ErrorResponse(c, stdhttp.StatusBadRequest, "REGISTRATION_ERROR", err.Error(), err)
+		apierror.Abort(c, err, "Registration failed")
 		return
 	}
 
@@ -102,14 +166,14 @@ func (h *Handler) handleRefresh(c *gin.Context) {
 	var req struct {
 		RefreshToken string `json:"refreshToken" binding:"required"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.responseHandler.ValidationErrorResponse(c, "refreshToken", "Refresh token is required")
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
-	response, err := h.service.RefreshToken(req.RefreshToken)
+	response, err := h.service.RefreshToken(req.RefreshToken, clientInfo(c))
 	if err != nil {
-		h.responseHandler.ErrorResponse(c, stdhttp.StatusUnauthorized, "REFRESH_ERROR", err.Error(), err)
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusUnauthorized, apierror.CodeRefresh, err.Error(), err)
 		return
 	}
 
@@ -131,8 +195,8 @@ func (h *Handler) handleLogout(c *gin.Context) {
 	var req struct {
 		RefreshToken string `json:"refreshToken" binding:"required"`
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.responseHandler.ValidationErrorResponse(c, "refreshToken", "Refresh token is required")
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
@@ -145,14 +209,622 @@ func (h *Handler) handleLogout(c *gin.Context) {
 
 	userID, err := uuid.Parse(userIDStr.(string))
 	if err != nil {
-		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
 		return
 	}
 
-	if err := h.service.Logout(userID, req.RefreshToken); err != nil {
-		h.responseHandler.ErrorResponse(c, stdhttp.StatusUnauthorized, "LOGOUT_ERROR", err.Error(), err)
+	if err := h.service.Logout(userID, req.RefreshToken, clientInfo(c)); err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusUnauthorized, apierror.CodeLogout, err.Error(), err)
 		return
 	}
 
 	h.responseHandler.SuccessResponse(c, nil, "Logout successful")
 }
+
+// @Summary Request password reset
+// @Description Email a time-limited password reset link. The response is the same whether or not the account exists.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Account email"
+// @Success 200 {object} http.APIResponse "Password reset email sent if the account exists"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid request format"
+// @Failure 429 {object} http.APIResponse{error=http.APIError} "Too many requests"
+// @Router /auth/forgot-password [post]
+func (h *Handler) handleForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	if err := h.service.ForgotPassword(req.Email); err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to process password reset request", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "If an account exists for this email, a password reset link has been sent")
+}
+
+// @Summary Reset password
+// @Description Set a new password using the token from a password reset email. All existing sessions are signed out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} http.APIResponse "Password reset successful"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid request, token or password"
+// @Failure 429 {object} http.APIResponse{error=http.APIError} "Too many requests"
+// @Router /auth/reset-password [post]
+func (h *Handler) handleResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	if err := h.service.ResetPassword(req.Token, req.Password, clientInfo(c)); err != nil {
+		apierror.Abort(c, err, "Failed to reset password")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "Password reset successful")
+}
+
+// @Summary Verify email address
+// @Description Mark an account as verified using the token from a verification email
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} http.APIResponse "Email verified"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Missing, invalid or expired token"
+// @Router /auth/verify-email [get]
+func (h *Handler) handleVerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		h.responseHandler.ValidationErrorResponse(c, "token", "Verification token is required")
+		return
+	}
+
+	if err := h.service.VerifyEmail(token); err != nil {
+		apierror.Abort(c, err, "Failed to verify email")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "Email verified successfully")
+}
+
+// @Summary Resend verification email
+// @Description Send a new verification email to an unverified account. Requests for the same account are throttled.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ResendVerificationRequest true "Account email"
+// @Success 200 {object} http.APIResponse "Verification email sent if the account needs one"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid request format"
+// @Failure 429 {object} http.APIResponse{error=http.APIError} "Verification email sent too recently"
+// @Failure 429 {object} http.APIResponse{error=http.APIError} "Too many requests"
+// @Router /auth/resend-verification [post]
+func (h *Handler) handleResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	if err := h.service.ResendVerification(req.Email); err != nil {
+		apierror.Abort(c, err, "Failed to send verification email")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "If this account needs verification, a new email has been sent")
+}
+
+// @Summary Start OAuth login
+// @Description Redirect to the provider's consent page. A short-lived state cookie ties the callback to this browser.
+// @Tags auth
+// @Param provider path string true "OAuth provider" Enums(google, github)
+// @Success 302 "Redirect to the provider"
+// @Failure 404 {object} http.APIResponse{error=http.APIError} "Unknown or disabled provider"
+// @Router /auth/oauth/{provider}/login [get]
+func (h *Handler) handleOAuthLogin(c *gin.Context) {
+	redirectURL, state, err := h.service.OAuthLoginURL(c.Param("provider"))
+	if err != nil {
+		h.handleOAuthError(c, err)
+		return
+	}
+
+	c.SetSameSite(stdhttp.SameSiteLaxMode)
+	c.SetCookie(OAuthStateCookie, state, int(OAuthStateTTL.Seconds()), h.oauthPath, "", c.Request.TLS != nil, true)
+	c.Redirect(stdhttp.StatusFound, redirectURL)
+}
+
+// @Summary Complete OAuth login
+// @Description Exchange the provider's authorization code and return the same token pair as password login. On first login the provider identity is linked to the account with the same verified email, or a new account is created.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "OAuth provider" Enums(google, github)
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the provider"
+// @Success 200 {object} http.APIResponse{data=LoginResponse} "Login successful"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Missing code, invalid state or login denied"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Account disabled"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Provider email not verified"
+// @Failure 404 {object} http.APIResponse{error=http.APIError} "Unknown or disabled provider"
+// @Failure 502 {object} http.APIResponse{error=http.APIError} "Provider request failed"
+// @Router /auth/oauth/{provider}/callback [get]
+func (h *Handler) handleOAuthCallback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusBadRequest, apierror.CodeOAuthDenied, "Login was cancelled or denied at the provider", errors.New(providerErr))
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		h.responseHandler.ValidationErrorResponse(c, "code", "Authorization code is required")
+		return
+	}
+
+	// The state cookie is single-use
+	stateCookie, _ := c.Cookie(OAuthStateCookie)
+	c.SetCookie(OAuthStateCookie, "", -1, h.oauthPath, "", c.Request.TLS != nil, true)
+
+	response, err := h.service.OAuthCallback(c.Request.Context(), c.Param("provider"), code, c.Query("state"), stateCookie, clientInfo(c))
+	if err != nil {
+		h.handleOAuthError(c, err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, response, "Login successful")
+}
+
+// handleOAuthError maps OAuth errors to HTTP responses
+func (h *Handler) handleOAuthError(c *gin.Context, err error) {
+	apierror.Abort(c, err, "Failed to complete OAuth login")
+}
+
+// @Summary List users
+// @Description List user accounts with their roles. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param role query string false "Only return users with this role" Enums(user, moderator, admin)
+// @Success 200 {object} http.APIResponse{data=[]User} "Users retrieved"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid role"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Admin role required"
+// @Router /admin/users [get]
+func (h *Handler) handleListUsers(c *gin.Context) {
+	users, err := h.service.ListUsers(Role(c.Query("role")))
+	if err != nil {
+		apierror.Abort(c, err, "Failed to list users")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, users, "Users retrieved successfully")
+}
+
+// @Summary Change a user's role
+// @Description Set a user's role to user, moderator or admin. The user's sessions are revoked so the change applies at their next login. The last admin cannot be demoted. Admin only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Param request body UpdateRoleRequest true "New role"
+// @Success 200 {object} http.APIResponse{data=User} "Role updated"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid user ID or role"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Admin role required"
+// @Failure 404 {object} http.APIResponse{error=http.APIError} "User not found"
+// @Failure 409 {object} http.APIResponse{error=http.APIError} "Cannot demote the last admin"
+// @Router /admin/users/{id}/role [put]
+func (h *Handler) handleUpdateRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ValidationErrorResponse(c, "id", "Invalid user ID format")
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	actorID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	user, err := h.service.SetUserRole(actorID, userID, req.Role)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to update role")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, user, "Role updated successfully")
+}
+
+// @Summary Suspend a user
+// @Description Deactivate an account. The user can no longer sign in, refresh tokens or use API keys; access tokens already issued stay valid until they expire. Admins cannot suspend themselves or the last active admin. Admin only.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Param request body SuspendUserRequest true "Suspension reason"
+// @Success 200 {object} http.APIResponse{data=User} "User suspended"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid user ID or missing reason"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Admin role required"
+// @Failure 404 {object} http.APIResponse{error=http.APIError} "User not found"
+// @Failure 409 {object} http.APIResponse{error=http.APIError} "Cannot suspend yourself or the last admin"
+// @Router /admin/users/{id}/suspend [post]
+func (h *Handler) handleSuspendUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ValidationErrorResponse(c, "id", "Invalid user ID format")
+		return
+	}
+
+	var req SuspendUserRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	actorID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	user, err := h.service.SuspendUser(actorID, userID, req.Reason)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to suspend user")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, user, "User suspended successfully")
+}
+
+// @Summary Reinstate a user
+// @Description Reactivate a suspended account. The user signs in again to get new tokens. Admin only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} http.APIResponse{data=User} "User reinstated"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid user ID"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Admin role required"
+// @Failure 404 {object} http.APIResponse{error=http.APIError} "User not found"
+// @Router /admin/users/{id}/suspend [delete]
+func (h *Handler) handleReinstateUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ValidationErrorResponse(c, "id", "Invalid user ID format")
+		return
+	}
+
+	actorID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	user, err := h.service.ReinstateUser(actorID, userID)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to reinstate user")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, user, "User reinstated successfully")
+}
+
+// @Summary Import users
+// @Description Create accounts in bulk, e.g. when migrating from another platform. The body is a CSV file with a header row or a JSON array of records. Each record carries a bcrypt passwordHash, a temporary password, or neither, in which case the user has to set a password through the welcome email or a password reset. Every record is validated and imported on its own; the report lists the outcome of each. Existing accounts are skipped, reported as failed or updated depending on onDuplicate; imports never change roles of existing accounts. Admin only.
+// @Tags admin
+// @Accept text/csv,json
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "Body format; defaults to csv for a text/csv body and json otherwise" Enums(csv, json)
+// @Param onDuplicate query string false "What to do with records whose email or username is taken (default: skip)" Enums(skip, update, fail)
+// @Param sendWelcome query bool false "Email each created user a welcome message with a link to set their password"
+// @Param file body []ImportUser true "Users to import"
+// @Success 200 {object} http.APIResponse{data=ImportReport} "Import finished; see the report for failed records"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Unreadable file or invalid options"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Admin role required"
+// @Router /admin/users/import [post]
+func (h *Handler) handleImportUsers(c *gin.Context) {
+	actorID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	format := BulkFormat(c.Query("format"))
+	if format == "" {
+		format = FormatJSON
+		if c.ContentType() == "text/csv" {
+			format = FormatCSV
+		}
+	}
+	sendWelcome, _ := strconv.ParseBool(c.Query("sendWelcome"))
+
+	users, err := ParseUserImport(stdhttp.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes), format)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to read import file")
+		return
+	}
+
+	report, err := h.service.ImportUsers(actorID, users, ImportOptions{
+		OnDuplicate: DuplicatePolicy(c.Query("onDuplicate")),
+		SendWelcome: sendWelcome,
+	})
+	if err != nil {
+		apierror.Abort(c, err, "Failed to import users")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, report, "Import finished")
+}
+
+// @Summary Export users
+// @Description Download all users as a CSV file or JSON array, optionally filtered by role. The export can be imported into another instance. Password hashes are only included when includePasswordHashes is set. Admin only.
+// @Tags admin
+// @Produce text/csv,json
+// @Security BearerAuth
+// @Param format query string false "File format (default: csv)" Enums(csv, json)
+// @Param role query string false "Only export users with this role" Enums(user, moderator, admin)
+// @Param includePasswordHashes query bool false "Include bcrypt password hashes, so users keep their passwords after a migration"
+// @Success 200 {array} ExportUser "Exported users"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid format or role"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Admin role required"
+// @Router /admin/users/export [get]
+func (h *Handler) handleExportUsers(c *gin.Context) {
+	format := BulkFormat(c.DefaultQuery("format", string(FormatCSV)))
+	if format != FormatCSV && format != FormatJSON {
+		h.responseHandler.ValidationErrorResponse(c, "format", "Format must be csv or json")
+		return
+	}
+	includeHashes, _ := strconv.ParseBool(c.Query("includePasswordHashes"))
+
+	users, err := h.service.ExportUsers(Role(c.Query("role")), includeHashes)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to export users")
+		return
+	}
+
+	h.service.logger.LogInfo("Users exported", map[string]interface{}{
+		"actorID":        c.GetString("userID"),
+		"count":          len(users),
+		"passwordHashes": includeHashes,
+	})
+
+	contentType := "application/json"
+	if format == FormatCSV {
+		contentType = "text/csv"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", "attachment; filename=users."+string(format))
+	c.Status(stdhttp.StatusOK)
+	if err := WriteUserExport(c.Writer, users, format); err != nil {
+		h.service.logger.LogError(err, "Failed to write user export")
+	}
+}
+
+// @Summary Create an API key
+// @Description Create a scoped API key for server-to-server access. Send it in the X-API-Key header. The plaintext key is only returned in this response. Only admins can grant the admin scope.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPIKeyRequest true "Key name, scopes and lifetime"
+// @Success 200 {object} http.APIResponse{data=CreateAPIKeyResponse} "API key created"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid request or scope"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 403 {object} http.APIResponse{error=http.APIError} "Admin scope not allowed"
+// @Router /auth/apikeys [post]
+func (h *Handler) handleCreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	key, raw, err := h.service.CreateAPIKey(userID, req)
+	if err != nil {
+		// The key's owner is the caller, so a missing user means a stale token
+		if errors.Is(err, ErrUserNotFound) {
+			h.responseHandler.UnauthorizedResponse(c, "User not found")
+			return
+		}
+		apierror.Abort(c, err, "Failed to create API key")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, CreateAPIKeyResponse{APIKey: *key, Key: raw}, "API key created successfully")
+}
+
+// @Summary List API keys
+// @Description List the current user's API keys, including revoked ones. Plaintext keys are never returned.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} http.APIResponse{data=[]APIKey} "API keys retrieved"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Router /auth/apikeys [get]
+func (h *Handler) handleListAPIKeys(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	keys, err := h.service.ListAPIKeys(userID)
+	if err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to list API keys", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, keys, "API keys retrieved successfully")
+}
+
+// @Summary Revoke an API key
+// @Description Revoke one of the current user's API keys. Requests made with it are rejected from then on.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID (UUID)"
+// @Success 200 {object} http.APIResponse "API key revoked"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid key ID"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 404 {object} http.APIResponse{error=http.APIError} "API key not found"
+// @Router /auth/apikeys/{id} [delete]
+func (h *Handler) handleRevokeAPIKey(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ValidationErrorResponse(c, "id", "Invalid API key ID format")
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	if err := h.service.RevokeAPIKey(userID, keyID); err != nil {
+		apierror.Abort(c, err, "Failed to revoke API key")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "API key revoked successfully")
+}
+
+// @Summary List sessions
+// @Description List the current user's active sessions with the device each was started from. The session making the request is marked as current.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} http.APIResponse{data=[]Session} "Sessions retrieved"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Router /auth/sessions [get]
+func (h *Handler) handleListSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	sessions, err := h.service.ListSessions(userID, currentSessionID(c))
+	if err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to list sessions", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, sessions, "Sessions retrieved successfully")
+}
+
+// @Summary Revoke a session
+// @Description Sign out one of the current user's sessions. Its refresh token stops working immediately; access tokens already issued to it expire on their own.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID (UUID)"
+// @Success 200 {object} http.APIResponse "Session revoked"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid session ID"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Failure 404 {object} http.APIResponse{error=http.APIError} "Session not found"
+// @Router /auth/sessions/{id} [delete]
+func (h *Handler) handleRevokeSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ValidationErrorResponse(c, "id", "Invalid session ID format")
+		return
+	}
+
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	if err := h.service.RevokeSession(userID, sessionID); err != nil {
+		apierror.Abort(c, err, "Failed to revoke session")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "Session revoked successfully")
+}
+
+// @Summary List account activity
+// @Description List the current user's recent logins, failed login attempts, token refreshes, logouts and password resets, newest first, with the IP address and user agent of each. Pass the createdAt of the last event as before to get older events.
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param before query string false "Only events before this time, RFC 3339"
+// @Param limit query int false "Events to return (default: 50, max: 100)"
+// @Success 200 {object} http.APIResponse{data=[]SecurityEvent} "Account activity retrieved"
+// @Failure 400 {object} http.APIResponse{error=http.APIError} "Invalid before time"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Router /me/security/activity [get]
+func (h *Handler) handleListSecurityActivity(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	var before time.Time
+	if value := c.Query("before"); value != "" {
+		if before, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			h.responseHandler.ValidationErrorResponse(c, "before", "before must be an RFC 3339 time")
+			return
+		}
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	events, err := h.service.ListSecurityActivity(userID, before, limit)
+	if err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to list account activity", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, events, "Account activity retrieved successfully")
+}
+
+// @Summary Revoke other sessions
+// @Description Sign out every session of the current user except the one making the request
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} http.APIResponse{data=RevokeSessionsResponse} "Other sessions revoked"
+// @Failure 401 {object} http.APIResponse{error=http.APIError} "Unauthorized"
+// @Router /auth/sessions [delete]
+func (h *Handler) handleRevokeOtherSessions(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, stdhttp.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return
+	}
+
+	revoked, err := h.service.RevokeOtherSessions(userID, currentSessionID(c))
+	if err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to revoke sessions", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, RevokeSessionsResponse{Revoked: revoked}, "Other sessions revoked successfully")
+}
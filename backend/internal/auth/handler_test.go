@@ -6,8 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
 	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
@@ -38,17 +38,7 @@ func setupTestRouter(t *testing.T) (*gin.Engine, *auth.Service, *gorm.DB) {
 	db := testhelper.SetupTestDB(t)
 
 	// Create auth config
-	config := &auth.Config{
-		JWT: struct {
-			Secret          string
-			AccessTokenTTL  time.Duration
-			RefreshTokenTTL time.Duration
-		}{
-			Secret:          "test-secret-key",
-			AccessTokenTTL:  time.Hour,
-			RefreshTokenTTL: time.Hour * 24 * 7,
-		},
-	}
+	config := newTestConfig("test-secret-key")
 
 	// Create logger
 	testLogger := testhelper.NewTestLogger(true)
@@ -63,39 +53,21 @@ func setupTestRouter(t *testing.T) (*gin.Engine, *auth.Service, *gorm.DB) {
 	router.Use(gin.Recovery())
 
 	// Create response handler
-	logger, _ := logger.NewLogger(&logger.Config{
-		Level:       logger.Level("debug"),
-		Format:      "console",
-		Output:      "stdout",
-		Development: true,
-		File: struct {
-			Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
-			Path    string `mapstructure:"path" yaml:"path"`
-			Rotate  bool   `mapstructure:"rotate" yaml:"rotate"`
-			MaxSize string `mapstructure:"maxSize" yaml:"maxSize"`
-			MaxAge  string `mapstructure:"maxAge" yaml:"maxAge"`
-		}{
-			Enabled: false,
-			Path:    "/var/log/pavilion",
-			Rotate:  false,
-			MaxSize: "100MB",
-			MaxAge:  "7d",
-		},
-		Sampling: struct {
-			Initial    int `mapstructure:"initial" yaml:"initial"`
-			Thereafter int `mapstructure:"thereafter" yaml:"thereafter"`
-		}{
-			Initial:    100,
-			Thereafter: 100,
-		},
-	})
+	loggerConfig := logger.DefaultConfig()
+	loggerConfig.Level = logger.DebugLevel
+	loggerConfig.Format = "console"
+	loggerConfig.Development = true
+	logger, _ := logger.NewLogger(loggerConfig)
 	responseHandler := httpHandler.NewResponseHandler(logger)
 
+	// Service errors are written by the error middleware
+	router.Use(apierror.Middleware(responseHandler))
+
 	// Create auth handler and register routes
 	authHandler := auth.NewHandler(authService, responseHandler)
 
-	// Register all routes
-	authHandler.RegisterRoutes(router)
+	// Register all routes under the API base path
+	authHandler.RegisterRoutes(router.Group("/api/v1"))
 
 	return router, authService, db
 }
@@ -114,7 +86,7 @@ func TestRegisterAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 
@@ -142,7 +114,7 @@ func TestRegisterAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 
@@ -162,7 +134,7 @@ func TestRegisterAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 
@@ -201,7 +173,7 @@ func TestLoginAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 
@@ -243,7 +215,7 @@ func TestLoginAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 
@@ -274,7 +246,7 @@ func TestRefreshTokenAPI(t *testing.T) {
 	}
 
 	// Login to get tokens
-	loginResp, err := authService.Login("refresh@example.com", "Pass123!")
+	loginResp, err := authService.Login("refresh@example.com", "Pass123!", auth.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Failed to login test user: %v", err)
 	}
@@ -287,7 +259,7 @@ func TestRefreshTokenAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 
@@ -328,7 +300,7 @@ func TestRefreshTokenAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		router.ServeHTTP(w, req)
 
@@ -359,7 +331,7 @@ func TestLogoutAPI(t *testing.T) {
 	}
 
 	// Login to get tokens
-	loginResp, err := authService.Login("logout@example.com", "Pass123!")
+	loginResp, err := authService.Login("logout@example.com", "Pass123!", auth.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Failed to login test user: %v", err)
 	}
@@ -372,7 +344,7 @@ func TestLogoutAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/logout", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/logout", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+loginResp.AccessToken)
 		router.ServeHTTP(w, req)
@@ -392,7 +364,7 @@ func TestLogoutAPI(t *testing.T) {
 		}
 
 		// Verify the refresh token is actually revoked
-		_, err = authService.RefreshToken(loginResp.RefreshToken)
+		_, err = authService.RefreshToken(loginResp.RefreshToken, auth.ClientInfo{})
 		if err == nil {
 			t.Error("Expected error when using revoked refresh token")
 		}
@@ -406,7 +378,7 @@ func TestLogoutAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/logout", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/logout", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		// Intentionally not setting Authorization header
 		router.ServeHTTP(w, req)
@@ -424,7 +396,7 @@ func TestLogoutAPI(t *testing.T) {
 		body, _ := json.Marshal(reqBody)
 
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("POST", "/auth/logout", bytes.NewBuffer(body))
+		req, _ := http.NewRequest("POST", "/api/v1/auth/logout", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", "Bearer "+loginResp.AccessToken)
 		router.ServeHTTP(w, req)
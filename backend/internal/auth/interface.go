@@ -9,16 +9,16 @@ import (
 
 // AuthService handles authentication operations
 type AuthService interface {
-	Login(identifier, password string) (*LoginResponse, error)
-	Logout(userID uuid.UUID, refreshToken string) error
-	RefreshToken(refreshToken string) (*LoginResponse, error)
+	Login(identifier, password string, client ClientInfo) (*LoginResponse, error)
+	Logout(userID uuid.UUID, refreshToken string, client ClientInfo) error
+	RefreshToken(refreshToken string, client ClientInfo) (*LoginResponse, error)
 	ValidateToken(token string) (*TokenClaims, error)
 	MarkEmailVerified(userID uuid.UUID) error
 }
 
 // TokenService handles JWT operations
 type TokenService interface {
-	GenerateAccessToken(user *User) (string, error)
+	GenerateAccessToken(user *User, sessionID uuid.UUID) (string, error)
 	GenerateRefreshToken(user *User) (string, error)
 	ValidateAccessToken(token string) (*TokenClaims, error)
 	ValidateRefreshToken(token string) (*TokenClaims, error)
@@ -43,9 +43,14 @@ type Logger interface {
 
 // RefreshTokenService handles refresh token operations
 type RefreshTokenService interface {
-	Create(userID uuid.UUID, token string, expiresAt time.Time) error
+	Create(userID uuid.UUID, token string, expiresAt time.Time, client ClientInfo) (*RefreshToken, error)
 	GetByToken(token string) (*RefreshToken, error)
+	MarkUsed(id uuid.UUID) error
+	IsActive(userID, id uuid.UUID) (bool, error)
+	ListActiveByUser(userID uuid.UUID) ([]RefreshToken, error)
+	RevokeByID(userID, id uuid.UUID) error
 	RevokeByToken(token string) error
 	RevokeAllUserTokens(userID uuid.UUID) error
+	RevokeAllUserTokensExcept(userID, keepID uuid.UUID) (int64, error)
 	DeleteExpired() error
 }
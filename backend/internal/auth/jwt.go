@@ -8,6 +8,11 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
 // JWTService implements the TokenService interface using JWT tokens
 type JWTService struct {
 	config *Config
@@ -20,11 +25,15 @@ func NewJWTService(config *Config) TokenService {
 	}
 }
 
-// GenerateAccessToken generates a new JWT access token for a user
-func (s *JWTService) GenerateAccessToken(user *User) (string, error) {
+// GenerateAccessToken generates a new JWT access token for a user within the
+// session of the given refresh token
+func (s *JWTService) GenerateAccessToken(user *User, sessionID uuid.UUID) (string, error) {
 	claims := &TokenClaims{
-		UserID: user.ID.String(),
-		Email:  user.Email,
+		UserID:    user.ID.String(),
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: sessionID.String(),
+		TokenType: tokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.JWT.AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -41,8 +50,10 @@ func (s *JWTService) GenerateAccessToken(user *User) (string, error) {
 // GenerateRefreshToken generates a new JWT refresh token for a user
 func (s *JWTService) GenerateRefreshToken(user *User) (string, error) {
 	claims := &TokenClaims{
-		UserID: user.ID.String(),
-		Email:  user.Email,
+		UserID:    user.ID.String(),
+		Email:     user.Email,
+		Role:      user.Role,
+		TokenType: tokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.JWT.RefreshTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -56,8 +67,42 @@ func (s *JWTService) GenerateRefreshToken(user *User) (string, error) {
 	return token.SignedString([]byte(s.config.JWT.Secret))
 }
 
-// ValidateAccessToken validates a JWT access token and returns its claims
+// ValidateAccessToken validates a JWT access token and returns its claims.
+// Refresh tokens are rejected.
 func (s *JWTService) ValidateAccessToken(tokenString string) (*TokenClaims, error) {
+	claims, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.tokenType() != tokenTypeAccess {
+		return nil, fmt.Errorf("invalid token: not an access token")
+	}
+	return claims, nil
+}
+
+// ValidateRefreshToken validates a JWT refresh token and returns its claims.
+// Access tokens are rejected. Whether the token's session is still active is
+// checked against the stored refresh tokens by the service.
+func (s *JWTService) ValidateRefreshToken(tokenString string) (*TokenClaims, error) {
+	claims, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.tokenType() != tokenTypeRefresh {
+		return nil, fmt.Errorf("invalid token: not a refresh token")
+	}
+
+	// Convert string ID back to UUID and validate it's a valid UUID
+	_, err = uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID in token: %v", err)
+	}
+
+	return claims, nil
+}
+
+// parse verifies a JWT's signature and lifetime and returns its claims
+func (s *JWTService) parse(tokenString string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -76,23 +121,14 @@ func (s *JWTService) ValidateAccessToken(tokenString string) (*TokenClaims, erro
 	return nil, fmt.Errorf("invalid token claims")
 }
 
-// ValidateRefreshToken validates a JWT refresh token and returns its claims
-func (s *JWTService) ValidateRefreshToken(tokenString string) (*TokenClaims, error) {
-	// For refresh tokens, we also need to check if the token exists in the database
-	// and hasn't been revoked
-	claims, err := s.ValidateAccessToken(tokenString) // Reuse validation logic
-	if err != nil {
-		return nil, err
+// tokenType returns the type of a token. Tokens issued before the type claim
+// existed are told apart by the session ID, which only access tokens carry.
+func (c *TokenClaims) tokenType() string {
+	if c.TokenType != "" {
+		return c.TokenType
 	}
-
-	// Convert string ID back to UUID and validate it's a valid UUID
-	_, err = uuid.Parse(claims.UserID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid user ID in token: %v", err)
+	if c.SessionID != "" {
+		return tokenTypeAccess
 	}
-
-	// TODO: Check if refresh token exists in database and hasn't been revoked
-	// This will be implemented when we add the refresh token table and repository
-
-	return claims, nil
+	return tokenTypeRefresh
 }
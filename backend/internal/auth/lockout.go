@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrAccountLocked = apierror.New(apierror.CodeAccountLocked, "account temporarily locked")
+
+// AccountLockedError is returned by Login while an account is locked after
+// too many consecutive failed attempts
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account temporarily locked after too many failed login attempts, try again after %s",
+		e.Until.UTC().Format(time.RFC3339))
+}
+
+// Unwrap makes errors.Is(err, ErrAccountLocked) match
+func (e *AccountLockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
+// RetryAfter returns how long until the lock ends, rounded up to whole seconds
+func (e *AccountLockedError) RetryAfter(now time.Time) time.Duration {
+	remaining := e.Until.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	return (remaining + time.Second - 1).Truncate(time.Second)
+}
+
+// checkLockout returns an AccountLockedError while the user's lock is in effect
+func (s *Service) checkLockout(user *User) error {
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return &AccountLockedError{Until: *user.LockedUntil}
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failed password attempt and locks the account once
+// the configured limit is reached. It returns an AccountLockedError when this
+// attempt caused the lock.
+func (s *Service) recordFailedLogin(user *User) error {
+	if s.config.Lockout.MaxAttempts <= 0 {
+		return nil
+	}
+
+	// Increment in the database so concurrent attempts are all counted
+	var counted User
+	err := s.db.Model(&counted).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		Where("id = ?", user.ID).
+		UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error
+	if err != nil {
+		s.logger.LogError(err, "Failed to record failed login attempt")
+		return nil
+	}
+
+	if counted.FailedLoginAttempts < s.config.Lockout.MaxAttempts {
+		return nil
+	}
+
+	until := time.Now().Add(s.config.Lockout.Duration)
+	if err := s.db.Model(&User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          until,
+	}).Error; err != nil {
+		s.logger.LogError(err, "Failed to lock account")
+		return nil
+	}
+
+	s.logger.LogWarn("Account locked after repeated failed logins", map[string]interface{}{
+		"userID":   user.ID,
+		"attempts": counted.FailedLoginAttempts,
+		"until":    until,
+	})
+
+	s.sendLockoutNotice(user, counted.FailedLoginAttempts, until)
+
+	return &AccountLockedError{Until: until}
+}
+
+// clearFailedLogins resets the failed attempt counter and any expired lock
+func (s *Service) clearFailedLogins(user *User) {
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return
+	}
+
+	if err := s.db.Model(&User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+	}).Error; err != nil {
+		s.logger.LogError(err, "Failed to reset failed login attempts")
+		return
+	}
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+}
+
+// sendLockoutNotice emails the account owner that their account was locked
+func (s *Service) sendLockoutNotice(user *User, attempts int, until time.Time) {
+	if s.mailer == nil {
+		s.logger.LogWarn("No mailer configured, cannot send account lockout notice", map[string]interface{}{
+			"userID": user.ID,
+		})
+		return
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nThere were %d failed attempts to sign in to your Pavilion account, "+
+		"so we have locked it until %s.\n\n"+
+		"If this was you, you can try again after that time or reset your password to unlock it now. "+
+		"If it was not you, we recommend resetting your password.\n",
+		user.Username, attempts, until.UTC().Format("2006-01-02 15:04 MST"))
+
+	if err := s.mailer.Send(context.Background(), user.Email, "Your Pavilion account was locked", body); err != nil {
+		s.logger.LogError(err, "Failed to send account lockout notice")
+	}
+}
@@ -0,0 +1,82 @@
+package auth_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+)
+
+func TestAccountLockout(t *testing.T) {
+	db := testhelper.SetupTestDB(t)
+	logger := testhelper.NewTestLogger(true)
+
+	config := newTestConfig("test-secret-" + uuid.New().String())
+	config.Lockout.MaxAttempts = 3
+	config.Lockout.Duration = time.Hour
+
+	jwtService := auth.NewJWTService(config)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db, logger)
+	authService := auth.NewService(db, jwtService, refreshTokenRepo, config, logger)
+	mailer := &captureMailer{}
+	authService.SetMailer(mailer)
+
+	regReq := auth.RegisterRequest{
+		Username: "lockoutuser",
+		Email:    "lockout@example.com",
+		Password: "Pass123!",
+		Name:     "Lockout User",
+	}
+	user, err := authService.Register(regReq)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := authService.MarkEmailVerified(user.ID); err != nil {
+		t.Fatalf("MarkEmailVerified failed: %v", err)
+	}
+
+	// A success in between resets the counter
+	for i := 0; i < config.Lockout.MaxAttempts-1; i++ {
+		if _, err := authService.Login(regReq.Username, "Wrong123!", auth.ClientInfo{}); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+		}
+	}
+	if _, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{}); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	for i := 0; i < config.Lockout.MaxAttempts-1; i++ {
+		if _, err := authService.Login(regReq.Username, "Wrong123!", auth.ClientInfo{}); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+		}
+	}
+
+	// The attempt that reaches the limit locks the account and notifies the owner
+	_, err = authService.Login(regReq.Username, "Wrong123!", auth.ClientInfo{})
+	var locked *auth.AccountLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("expected AccountLockedError, got %v", err)
+	}
+	if locked.RetryAfter(time.Now()) <= 0 {
+		t.Errorf("expected a positive retry-after, got %v", locked.RetryAfter(time.Now()))
+	}
+	if mailer.to != regReq.Email {
+		t.Errorf("expected lockout notice to %s, got %q", regReq.Email, mailer.to)
+	}
+
+	// The correct password is rejected while locked
+	if _, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{}); !errors.Is(err, auth.ErrAccountLocked) {
+		t.Fatalf("expected ErrAccountLocked, got %v", err)
+	}
+
+	// An expired lock no longer applies
+	if err := db.Model(&auth.User{}).Where("id = ?", user.ID).Update("locked_until", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatalf("failed to expire lock: %v", err)
+	}
+	if _, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{}); err != nil {
+		t.Errorf("expected login after the lock expired to succeed, got %v", err)
+	}
+}
@@ -20,12 +20,27 @@ type User struct {
 	Password string `gorm:"not null" json:"-"`
 	// User's full name
 	Name string `json:"name" example:"John Doe"`
+	// Short public biography
+	Bio string `gorm:"type:text" json:"bio" example:"Filmmaker and open source enthusiast"`
+	// Storage key of the user's avatar image
+	AvatarPath string `json:"avatarPath,omitempty" example:"avatars/550e8400-e29b-41d4-a716-446655440000/avatar.png"`
 	// Whether email is verified
 	EmailVerified bool `gorm:"default:false" json:"emailVerified" example:"true"`
+	// When the last verification email was sent, used to throttle resends
+	VerificationSentAt *time.Time `json:"-"`
+	// Consecutive failed password logins since the last success or lockout
+	FailedLoginAttempts int `gorm:"not null;default:0" json:"-"`
+	// Until when the account is locked after too many failed logins
+	LockedUntil *time.Time `json:"-"`
 	// Last login timestamp
 	LastLoginAt time.Time `json:"lastLoginAt,omitempty"`
-	// Whether account is active
+	// Permission level: user, moderator or admin
+	Role Role `gorm:"type:text;not null;default:'user'" json:"role" example:"user"`
+	// Whether account is active; suspended accounts cannot sign in
 	Active bool `gorm:"default:true" json:"active" example:"true"`
+	// When and why an admin suspended the account
+	SuspendedAt      *time.Time `json:"suspendedAt,omitempty"`
+	SuspensionReason string     `json:"suspensionReason,omitempty" example:"Repeated copyright violations"`
 	// Account creation timestamp
 	CreatedAt time.Time `json:"createdAt"`
 	// Last update timestamp
@@ -42,6 +57,74 @@ type RefreshToken struct {
 	ExpiresAt time.Time  `json:"expiresAt"`
 	CreatedAt time.Time  `json:"createdAt"`
 	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	// Client the session was started from, captured at login
+	IPAddress  string     `json:"ipAddress"`
+	UserAgent  string     `json:"userAgent"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// OAuthIdentity links a user to an account at an OAuth provider
+type OAuthIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"userId"`
+	Provider  string    `gorm:"not null;uniqueIndex:idx_oauth_identities_provider_subject" json:"provider"`
+	Subject   string    `gorm:"not null;uniqueIndex:idx_oauth_identities_provider_subject" json:"subject"` // Provider's stable user ID
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// APIKey is a long-lived credential for server-to-server integrations.
+// Only a hash of the key is stored; the key itself is shown once at creation.
+type APIKey struct {
+	// Unique key ID
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Owner of the key; requests made with the key act as this user
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"userId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Human-readable label
+	Name string `gorm:"not null" json:"name" example:"Upload bot"`
+	// First characters of the key, for telling keys apart
+	Prefix string `gorm:"not null" json:"prefix" example:"pvk_3q2-7wEA"`
+	// SHA-256 hash of the key (not exposed in JSON)
+	KeyHash string `gorm:"uniqueIndex;not null" json:"-"`
+	// Operations the key may perform
+	Scopes APIScopes `gorm:"type:text;not null" json:"scopes" swaggertype:"array,string" example:"read,upload"`
+	// Last time the key authenticated a request
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	// When the key stops working; never when empty
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// When the key was revoked
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	// Key creation timestamp
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SecurityEvent is one sign-in, token refresh, logout or password change on
+// an account, kept so the user can spot activity they do not recognize
+// @Description Account activity event
+type SecurityEvent struct {
+	// Unique event ID
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Account the event happened on
+	UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_auth_security_events_user_created,priority:1" json:"-"`
+	// What happened: login, token_refresh, logout or password_reset
+	Type SecurityEventType `gorm:"type:text;not null" json:"type" example:"login" enums:"login,token_refresh,logout,password_reset"`
+	// Whether the attempt succeeded
+	Outcome SecurityOutcome `gorm:"type:text;not null" json:"outcome" example:"success" enums:"success,failure"`
+	// Why a failed attempt was rejected
+	Reason string `gorm:"type:text" json:"reason,omitempty" example:"invalid_password"`
+	// How the user signed in: password, or the OAuth provider
+	Method string `gorm:"type:text" json:"method,omitempty" example:"password"`
+	// Client the request came from
+	IPAddress string `gorm:"type:text" json:"ipAddress" example:"203.0.113.7"`
+	UserAgent string `gorm:"type:text" json:"userAgent" example:"Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"`
+	// When it happened
+	CreatedAt time.Time `gorm:"not null;default:now();index:idx_auth_security_events_user_created,priority:2" json:"createdAt"`
+}
+
+// TableName specifies the table name for the SecurityEvent model
+func (SecurityEvent) TableName() string {
+	return "auth_security_events"
 }
 
 // BeforeCreate hook for User model
@@ -0,0 +1,440 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/audit"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+	"gorm.io/gorm"
+)
+
+// OAuthStateCookie holds the signed state of an OAuth login between the redirect and the callback
+const OAuthStateCookie = "oauth_state"
+
+// OAuthStateTTL is how long a user has to complete an OAuth login
+const OAuthStateTTL = 10 * time.Minute
+
+var ErrUnknownOAuthProvider = apierror.New(apierror.CodeUnknownProvider, "unknown or disabled OAuth provider")
+var ErrInvalidOAuthState = apierror.New(apierror.CodeInvalidState, "invalid or expired OAuth state")
+var ErrOAuthEmailNotVerified = apierror.New(apierror.CodeEmailNotVerified, "the provider did not return a verified email address")
+var ErrOAuthProvider = apierror.New(apierror.CodeOAuthProvider, "failed to complete login with the provider")
+
+// oauthProfile is the identity returned by a provider's user info endpoint
+type oauthProfile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string
+}
+
+// oauthProvider combines the OAuth2 client settings and profile lookup of one provider
+type oauthProvider struct {
+	config       *oauth2.Config
+	userInfoURL  string
+	fetchProfile func(ctx context.Context, client *http.Client, userInfoURL string) (*oauthProfile, error)
+}
+
+// oauthStateClaims is stored in the state cookie. Nonce is also sent to the
+// provider as the state parameter; Verifier is the PKCE code verifier.
+type oauthStateClaims struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// newOAuthProviders builds the enabled providers. Providers without a client ID are skipped.
+func newOAuthProviders(cfgs map[string]config.OAuthProviderConfig) map[string]*oauthProvider {
+	providers := make(map[string]*oauthProvider)
+	for name, cfg := range cfgs {
+		if cfg.ClientID == "" {
+			continue
+		}
+
+		var endpoint oauth2.Endpoint
+		var scopes []string
+		var userInfoURL string
+		var fetch func(ctx context.Context, client *http.Client, userInfoURL string) (*oauthProfile, error)
+		switch name {
+		case "google":
+			endpoint = endpoints.Google
+			scopes = []string{"openid", "email", "profile"}
+			userInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+			fetch = fetchGoogleProfile
+		case "github":
+			endpoint = endpoints.GitHub
+			scopes = []string{"read:user", "user:email"}
+			userInfoURL = "https://api.github.com/user"
+			fetch = fetchGitHubProfile
+		default:
+			continue
+		}
+
+		if cfg.AuthURL != "" {
+			endpoint.AuthURL = cfg.AuthURL
+		}
+		if cfg.TokenURL != "" {
+			endpoint.TokenURL = cfg.TokenURL
+		}
+		if cfg.UserInfoURL != "" {
+			userInfoURL = cfg.UserInfoURL
+		}
+
+		providers[name] = &oauthProvider{
+			config: &oauth2.Config{
+				ClientID:     cfg.ClientID,
+				ClientSecret: cfg.ClientSecret,
+				RedirectURL:  cfg.RedirectURL,
+				Endpoint:     endpoint,
+				Scopes:       scopes,
+			},
+			userInfoURL:  userInfoURL,
+			fetchProfile: fetch,
+		}
+	}
+	return providers
+}
+
+// OAuthLoginURL returns the provider URL to redirect the user to, and the
+// signed state to store in the OAuthStateCookie
+func (s *Service) OAuthLoginURL(provider string) (string, string, error) {
+	p, ok := s.oauthProviders[provider]
+	if !ok {
+		return "", "", ErrUnknownOAuthProvider
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", fmt.Errorf("failed to generate OAuth state: %v", err)
+	}
+
+	claims := &oauthStateClaims{
+		Provider: provider,
+		Nonce:    hex.EncodeToString(nonce),
+		Verifier: oauth2.GenerateVerifier(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(OAuthStateTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	state, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.oauthStateKey())
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign OAuth state: %v", err)
+	}
+
+	return p.config.AuthCodeURL(claims.Nonce, oauth2.S256ChallengeOption(claims.Verifier)), state, nil
+}
+
+// OAuthCallback completes an OAuth login: it checks the state against the
+// cookie, exchanges the code, and signs in the linked user, linking or
+// creating an account by verified email on first login
+func (s *Service) OAuthCallback(ctx context.Context, provider, code, state, stateCookie string, client ClientInfo) (*LoginResponse, error) {
+	p, ok := s.oauthProviders[provider]
+	if !ok {
+		return nil, ErrUnknownOAuthProvider
+	}
+
+	claims := &oauthStateClaims{}
+	token, err := jwt.ParseWithClaims(stateCookie, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.oauthStateKey(), nil
+	})
+	if err != nil || !token.Valid || claims.Provider != provider ||
+		subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(state)) != 1 {
+		s.logger.LogWarn("OAuth callback with invalid state", map[string]interface{}{
+			"provider": provider,
+		})
+		return nil, ErrInvalidOAuthState
+	}
+
+	oauthToken, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(claims.Verifier))
+	if err != nil {
+		s.logger.LogError(err, "OAuth code exchange failed")
+		return nil, fmt.Errorf("%w: %v", ErrOAuthProvider, err)
+	}
+
+	profile, err := p.fetchProfile(ctx, p.config.Client(ctx, oauthToken), p.userInfoURL)
+	if err != nil {
+		s.logger.LogError(err, "Failed to fetch OAuth profile")
+		return nil, fmt.Errorf("%w: %v", ErrOAuthProvider, err)
+	}
+
+	user, err := s.findOrCreateOAuthUser(provider, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.issueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.LogInfo("OAuth login successful", map[string]interface{}{
+		"userID":   user.ID,
+		"provider": provider,
+	})
+	s.recordAudit(audit.Event{
+		Type:      audit.UserLogin,
+		ActorID:   user.ID,
+		TargetID:  user.ID.String(),
+		Details:   map[string]string{"provider": provider},
+		IPAddress: client.IPAddress,
+	})
+	s.recordSecurityEvent(SecurityEvent{
+		UserID:  user.ID,
+		Type:    SecurityLogin,
+		Outcome: OutcomeSuccess,
+		Method:  provider,
+	}, client)
+
+	return response, nil
+}
+
+// findOrCreateOAuthUser returns the user linked to a provider identity. On
+// first login the identity is linked to the account with the same verified
+// email, or a new account is created.
+func (s *Service) findOrCreateOAuthUser(provider string, profile *oauthProfile) (*User, error) {
+	var identity OAuthIdentity
+	err := s.db.Where("provider = ? AND subject = ?", provider, profile.Subject).First(&identity).Error
+	if err == nil {
+		var user User
+		if err := s.db.Where("id = ? AND active = ?", identity.UserID, true).First(&user).Error; err != nil {
+			return nil, ErrInvalidCredentials
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.LogError(err, "Failed to look up OAuth identity")
+		return nil, err
+	}
+
+	// Linking by email is only safe when the provider has verified it
+	if profile.Email == "" || !profile.EmailVerified {
+		s.logger.LogWarn("OAuth login without verified email", map[string]interface{}{
+			"provider": provider,
+		})
+		return nil, ErrOAuthEmailNotVerified
+	}
+
+	var user User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("email = ?", profile.Email).First(&user).Error
+		switch {
+		case err == nil:
+			if !user.Active {
+				return ErrInvalidCredentials
+			}
+			if !user.EmailVerified {
+				// Nobody has proven ownership of this address before, so a password set
+				// at registration may belong to someone else. Disable it and end its sessions.
+				unusable, err := unusablePasswordHash()
+				if err != nil {
+					return err
+				}
+				user.Password = unusable
+				user.EmailVerified = true
+				if err := tx.Save(&user).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).
+					Update("revoked_at", time.Now()).Error; err != nil {
+					return err
+				}
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			username, err := s.availableUsername(tx, profile)
+			if err != nil {
+				return err
+			}
+			password, err := unusablePasswordHash()
+			if err != nil {
+				return err
+			}
+			user = User{
+				Username:      username,
+				Email:         profile.Email,
+				Password:      password,
+				Name:          profile.Name,
+				EmailVerified: true,
+				Role:          RoleUser,
+				Active:        true,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		return tx.Create(&OAuthIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  profile.Subject,
+			Email:    profile.Email,
+		}).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCredentials) {
+			s.logger.LogError(err, "Failed to link OAuth identity")
+		}
+		return nil, err
+	}
+
+	s.logger.LogInfo("Linked OAuth identity", map[string]interface{}{
+		"userID":   user.ID,
+		"provider": provider,
+	})
+
+	return &user, nil
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// availableUsername derives an unused username from the provider profile
+func (s *Service) availableUsername(tx *gorm.DB, profile *oauthProfile) (string, error) {
+	base := profile.Username
+	if base == "" {
+		base, _, _ = strings.Cut(profile.Email, "@")
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(strings.ToLower(base), "_"), "_")
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 30 {
+		base = base[:30]
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		var count int64
+		if err := tx.Model(&User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = base + strconv.Itoa(i)
+	}
+	return base + "_" + uuid.New().String()[:8], nil
+}
+
+// oauthStateKey derives the key used to sign OAuth state cookies
+func (s *Service) oauthStateKey() []byte {
+	return []byte(s.config.JWT.Secret + "|oauth_state")
+}
+
+// unusablePasswordHash returns a bcrypt hash of a random password, so the
+// account can only sign in through OAuth until the user resets the password
+func unusablePasswordHash() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(random)), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// fetchGoogleProfile reads the OpenID Connect user info of a Google account
+func fetchGoogleProfile(ctx context.Context, client *http.Client, userInfoURL string) (*oauthProfile, error) {
+	var info struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := getJSON(ctx, client, userInfoURL, &info); err != nil {
+		return nil, err
+	}
+	if info.Sub == "" {
+		return nil, errors.New("user info has no subject")
+	}
+
+	return &oauthProfile{
+		Subject:       info.Sub,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Name:          info.Name,
+	}, nil
+}
+
+// fetchGitHubProfile reads a GitHub user and their primary verified email,
+// which is not part of the user object when the address is private
+func fetchGitHubProfile(ctx context.Context, client *http.Client, userInfoURL string) (*oauthProfile, error) {
+	var info struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, client, userInfoURL, &info); err != nil {
+		return nil, err
+	}
+	if info.ID == 0 {
+		return nil, errors.New("user info has no ID")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, userInfoURL+"/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	profile := &oauthProfile{
+		Subject:  strconv.FormatInt(info.ID, 10),
+		Name:     info.Name,
+		Username: info.Login,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			profile.Email = e.Email
+			profile.EmailVerified = e.Verified
+			break
+		}
+	}
+	return profile, nil
+}
+
+// getJSON performs an authenticated GET and decodes the JSON response
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
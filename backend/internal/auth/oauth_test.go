@@ -0,0 +1,180 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+)
+
+// fakeProvider serves the token and user info endpoints of a Google-style OAuth provider
+type fakeProvider struct {
+	server  *httptest.Server
+	profile map[string]interface{}
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	p := &fakeProvider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("code_verifier") == "" {
+			http.Error(w, "missing code verifier", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "provider-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer provider-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(p.profile)
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// login runs the redirect and callback steps of an OAuth login
+func login(t *testing.T, authService *auth.Service) (*auth.LoginResponse, error) {
+	redirectURL, stateCookie, err := authService.OAuthLoginURL("google")
+	if err != nil {
+		t.Fatalf("OAuthLoginURL failed: %v", err)
+	}
+	parsed, err := url.Parse(redirectURL)
+	if err != nil {
+		t.Fatalf("invalid redirect URL %q: %v", redirectURL, err)
+	}
+	state := parsed.Query().Get("state")
+	if state == "" || parsed.Query().Get("code_challenge") == "" {
+		t.Fatalf("redirect URL is missing state or PKCE challenge: %s", redirectURL)
+	}
+
+	return authService.OAuthCallback(context.Background(), "google", "auth-code", state, stateCookie, auth.ClientInfo{})
+}
+
+func TestOAuthLogin(t *testing.T) {
+	db := testhelper.SetupTestDB(t)
+	logger := testhelper.NewTestLogger(true)
+	provider := newFakeProvider(t)
+
+	config := &auth.Config{
+		JWT: config.JWTConfig{
+			Secret:          "test-secret-" + uuid.New().String(),
+			AccessTokenTTL:  time.Hour,
+			RefreshTokenTTL: time.Hour * 24 * 7,
+		},
+		OAuth: map[string]config.OAuthProviderConfig{
+			"google": {
+				ClientID:     "client-id",
+				ClientSecret: "client-secret",
+				RedirectURL:  "http://localhost:8080/api/v1/auth/oauth/google/callback",
+				AuthURL:      provider.server.URL + "/authorize",
+				TokenURL:     provider.server.URL + "/token",
+				UserInfoURL:  provider.server.URL + "/userinfo",
+			},
+		},
+	}
+
+	jwtService := auth.NewJWTService(config)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db, logger)
+	authService := auth.NewService(db, jwtService, refreshTokenRepo, config, logger)
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		if _, _, err := authService.OAuthLoginURL("github"); !errors.Is(err, auth.ErrUnknownOAuthProvider) {
+			t.Errorf("expected ErrUnknownOAuthProvider, got %v", err)
+		}
+	})
+
+	t.Run("Invalid state is rejected", func(t *testing.T) {
+		_, stateCookie, err := authService.OAuthLoginURL("google")
+		if err != nil {
+			t.Fatalf("OAuthLoginURL failed: %v", err)
+		}
+		_, err = authService.OAuthCallback(context.Background(), "google", "auth-code", "forged-state", stateCookie, auth.ClientInfo{})
+		if !errors.Is(err, auth.ErrInvalidOAuthState) {
+			t.Errorf("expected ErrInvalidOAuthState, got %v", err)
+		}
+	})
+
+	t.Run("Unverified provider email is rejected", func(t *testing.T) {
+		provider.profile = map[string]interface{}{
+			"sub": "unverified-subject", "email": "unverified@example.com", "email_verified": false,
+		}
+		if _, err := login(t, authService); !errors.Is(err, auth.ErrOAuthEmailNotVerified) {
+			t.Errorf("expected ErrOAuthEmailNotVerified, got %v", err)
+		}
+	})
+
+	var newUserID uuid.UUID
+	t.Run("First login creates a verified account", func(t *testing.T) {
+		provider.profile = map[string]interface{}{
+			"sub": "new-subject", "email": "oauth.new@example.com", "email_verified": true, "name": "OAuth User",
+		}
+		response, err := login(t, authService)
+		if err != nil {
+			t.Fatalf("OAuth login failed: %v", err)
+		}
+		if response.AccessToken == "" || response.RefreshToken == "" {
+			t.Error("expected an access and refresh token")
+		}
+		if !response.User.EmailVerified || response.User.Username != "oauth_new" {
+			t.Errorf("unexpected user: %+v", response.User)
+		}
+		newUserID = response.User.ID
+	})
+
+	t.Run("Repeat login returns the same account", func(t *testing.T) {
+		response, err := login(t, authService)
+		if err != nil {
+			t.Fatalf("OAuth login failed: %v", err)
+		}
+		if response.User.ID != newUserID {
+			t.Errorf("expected user %s, got %s", newUserID, response.User.ID)
+		}
+	})
+
+	t.Run("Existing account is linked by email", func(t *testing.T) {
+		existing, err := authService.Register(auth.RegisterRequest{
+			Username: "linkeduser",
+			Email:    "linked@example.com",
+			Password: "Pass123!",
+		})
+		if err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+
+		provider.profile = map[string]interface{}{
+			"sub": "linked-subject", "email": "linked@example.com", "email_verified": true,
+		}
+		response, err := login(t, authService)
+		if err != nil {
+			t.Fatalf("OAuth login failed: %v", err)
+		}
+		if response.User.ID != existing.ID {
+			t.Errorf("expected account %s to be linked, got %s", existing.ID, response.User.ID)
+		}
+		if !response.User.EmailVerified {
+			t.Error("expected linked account to be verified")
+		}
+
+		// The unverified account's password was never proven to belong to the email owner
+		if _, err := authService.Login("linked@example.com", "Pass123!", auth.ClientInfo{}); err == nil {
+			t.Error("expected the pre-existing password to be disabled")
+		}
+	})
+}
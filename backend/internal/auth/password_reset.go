@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetPurpose marks tokens that may only be used to reset a password
+const passwordResetPurpose = "password_reset"
+
+var ErrInvalidResetToken = apierror.New(apierror.CodeInvalidToken, "invalid or expired password reset token")
+var ErrInvalidPassword = apierror.Validation("password", "invalid password")
+
+// ForgotPassword emails a password reset link to the user with the given email.
+// It succeeds whether or not the account exists so callers cannot probe for
+// registered addresses.
+func (s *Service) ForgotPassword(email string) error {
+	s.logger.LogInfo("Password reset requested", map[string]interface{}{
+		"email": email,
+	})
+
+	var user User
+	if err := s.db.Where("email = ? AND active = ?", email, true).First(&user).Error; err != nil {
+		s.logger.LogWarn("Password reset requested for unknown account", map[string]interface{}{
+			"email": email,
+		})
+		return nil
+	}
+
+	if s.mailer == nil {
+		s.logger.LogWarn("No mailer configured, cannot send password reset email", map[string]interface{}{
+			"userID": user.ID,
+		})
+		return nil
+	}
+
+	token, err := s.generatePasswordResetToken(&user)
+	if err != nil {
+		s.logger.LogError(err, "Failed to generate password reset token")
+		return fmt.Errorf("failed to generate password reset token: %v", err)
+	}
+
+	link := s.config.PasswordReset.URL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nWe received a request to reset your Pavilion password. "+
+		"Open the link below to choose a new one. The link expires in %s.\n\n%s\n\n"+
+		"If you did not request this, you can ignore this email.\n",
+		user.Username, s.config.PasswordReset.TokenTTL, link)
+
+	if err := s.mailer.Send(context.Background(), user.Email, "Reset your Pavilion password", body); err != nil {
+		// Not returned to the caller, which would reveal that the account exists
+		s.logger.LogError(err, "Failed to send password reset email")
+		return nil
+	}
+
+	s.logger.LogInfo("Password reset email sent", map[string]interface{}{
+		"userID": user.ID,
+	})
+
+	return nil
+}
+
+// ResetPassword sets a new password using a password reset token and revokes
+// all of the user's refresh tokens
+func (s *Service) ResetPassword(token, password string, client ClientInfo) error {
+	user, err := s.parsePasswordResetToken(token)
+	if err != nil {
+		s.logger.LogWarn("Invalid password reset token", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return ErrInvalidResetToken
+	}
+
+	if err := validatePassword(password); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPassword, err)
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.LogError(err, "Failed to hash password")
+		return err
+	}
+
+	// Changing the hash also invalidates every outstanding reset token for the user.
+	// Proving control of the email also lifts a lockout.
+	user.Password = string(hashed)
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	if err := s.db.Save(user).Error; err != nil {
+		s.logger.LogError(err, "Failed to update password")
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+
+	if err := s.refreshTokens.RevokeAllUserTokens(user.ID); err != nil {
+		s.logger.LogError(err, "Failed to revoke refresh tokens after password reset")
+		return fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+	s.access.forget(user.ID)
+
+	s.logger.LogInfo("Password reset successful", map[string]interface{}{
+		"userID": user.ID,
+	})
+	s.recordSecurityEvent(SecurityEvent{
+		UserID:  user.ID,
+		Type:    SecurityPasswordReset,
+		Outcome: OutcomeSuccess,
+	}, client)
+
+	return nil
+}
+
+// generatePasswordResetToken creates a signed, time-limited reset token for a user
+func (s *Service) generatePasswordResetToken(user *User) (string, error) {
+	return s.signActionToken(user, passwordResetPurpose, s.config.PasswordReset.TokenTTL, s.resetSigningKey)
+}
+
+// parsePasswordResetToken validates a reset token and returns the user it was issued for
+func (s *Service) parsePasswordResetToken(tokenString string) (*User, error) {
+	return s.parseActionToken(tokenString, passwordResetPurpose, s.resetSigningKey)
+}
+
+// resetSigningKey derives the signing key for a user's reset tokens. Mixing in
+// the current password hash makes each token single-use: once the password
+// changes, tokens issued before the change no longer verify.
+func (s *Service) resetSigningKey(user *User) []byte {
+	return []byte(s.config.JWT.Secret + user.Password)
+}
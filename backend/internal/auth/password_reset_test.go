@@ -0,0 +1,117 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+)
+
+// captureMailer records the last message instead of sending it
+type captureMailer struct {
+	to   string
+	body string
+}
+
+func (m *captureMailer) Send(_ context.Context, to, subject, body string) error {
+	m.to = to
+	m.body = body
+	return nil
+}
+
+// tokenFromBody extracts the token query parameter from the reset link in an email body
+func tokenFromBody(t *testing.T, body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "http") {
+			link, err := url.Parse(line)
+			if err != nil {
+				t.Fatalf("invalid reset link %q: %v", line, err)
+			}
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no reset link in email body: %q", body)
+	return ""
+}
+
+func TestPasswordReset(t *testing.T) {
+	db := testhelper.SetupTestDB(t)
+	logger := testhelper.NewTestLogger(true)
+
+	config := newTestConfig("test-secret-" + uuid.New().String())
+	config.PasswordReset.TokenTTL = time.Hour
+	config.PasswordReset.URL = "http://localhost:3000/reset-password"
+
+	jwtService := auth.NewJWTService(config)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db, logger)
+	authService := auth.NewService(db, jwtService, refreshTokenRepo, config, logger)
+	mailer := &captureMailer{}
+	authService.SetMailer(mailer)
+
+	regReq := auth.RegisterRequest{
+		Username: "resetuser",
+		Email:    "reset@example.com",
+		Password: "Pass123!",
+		Name:     "Reset User",
+	}
+	user, err := authService.Register(regReq)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := authService.MarkEmailVerified(user.ID); err != nil {
+		t.Fatalf("MarkEmailVerified failed: %v", err)
+	}
+
+	loginResp, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// Unknown accounts succeed silently without sending mail
+	if err := authService.ForgotPassword("nobody@example.com"); err != nil {
+		t.Fatalf("expected no error for unknown email, got %v", err)
+	}
+	if mailer.to != "" {
+		t.Fatalf("expected no email for unknown account, sent to %s", mailer.to)
+	}
+
+	if err := authService.ForgotPassword(regReq.Email); err != nil {
+		t.Fatalf("ForgotPassword failed: %v", err)
+	}
+	if mailer.to != regReq.Email {
+		t.Fatalf("expected email to %s, got %s", regReq.Email, mailer.to)
+	}
+	token := tokenFromBody(t, mailer.body)
+
+	// Weak passwords are rejected without consuming the token
+	if err := authService.ResetPassword(token, "weak", auth.ClientInfo{}); !errors.Is(err, auth.ErrInvalidPassword) {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+
+	if err := authService.ResetPassword(token, "NewPass456!", auth.ClientInfo{}); err != nil {
+		t.Fatalf("ResetPassword failed: %v", err)
+	}
+
+	// The token is single-use
+	if err := authService.ResetPassword(token, "Other789!", auth.ClientInfo{}); !errors.Is(err, auth.ErrInvalidResetToken) {
+		t.Errorf("expected ErrInvalidResetToken on reuse, got %v", err)
+	}
+
+	// Existing sessions are revoked
+	if _, err := authService.RefreshToken(loginResp.RefreshToken, auth.ClientInfo{}); err == nil {
+		t.Error("expected refresh token to be revoked after password reset")
+	}
+
+	if _, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{}); err == nil {
+		t.Error("expected old password to be rejected")
+	}
+	if _, err := authService.Login(regReq.Username, "NewPass456!", auth.ClientInfo{}); err != nil {
+		t.Errorf("expected login with new password to succeed, got %v", err)
+	}
+}
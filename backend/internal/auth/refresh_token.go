@@ -24,8 +24,11 @@ func NewRefreshTokenRepository(db *gorm.DB, logger logger.Logger) *RefreshTokenR
 	}
 }
 
-// Create stores a new refresh token
-func (r *RefreshTokenRepository) Create(userID uuid.UUID, token string, expiresAt time.Time) error {
+// maxUserAgentLength bounds the stored user agent, which clients control
+const maxUserAgentLength = 512
+
+// Create stores a new refresh token together with the client it was issued to
+func (r *RefreshTokenRepository) Create(userID uuid.UUID, token string, expiresAt time.Time, client ClientInfo) (*RefreshToken, error) {
 	r.logger.LogInfo("Creating refresh token", map[string]interface{}{
 		"userID":    userID,
 		"expiresAt": expiresAt,
@@ -35,13 +38,18 @@ func (r *RefreshTokenRepository) Create(userID uuid.UUID, token string, expiresA
 	var count int64
 	if err := r.db.Model(&RefreshToken{}).Where("token = ?", token).Count(&count).Error; err != nil {
 		r.logger.LogError(err, "Error checking existing token")
-		return err
+		return nil, err
 	}
 	if count > 0 {
 		r.logger.LogWarn("Token already exists in database", map[string]interface{}{
 			"userID": userID,
 		})
-		return fmt.Errorf("refresh token already exists")
+		return nil, fmt.Errorf("refresh token already exists")
+	}
+
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
 	}
 
 	refreshToken := RefreshToken{
@@ -49,17 +57,19 @@ func (r *RefreshTokenRepository) Create(userID uuid.UUID, token string, expiresA
 		Token:     token,
 		ExpiresAt: expiresAt,
 		CreatedAt: time.Now(),
+		IPAddress: client.IPAddress,
+		UserAgent: userAgent,
 	}
 
-	err := r.db.Create(&refreshToken).Error
-	if err != nil {
+	if err := r.db.Create(&refreshToken).Error; err != nil {
 		r.logger.LogError(err, "Failed to create refresh token")
-	} else {
-		r.logger.LogInfo("Successfully created refresh token", map[string]interface{}{
-			"userID": userID,
-		})
+		return nil, err
 	}
-	return err
+
+	r.logger.LogInfo("Successfully created refresh token", map[string]interface{}{
+		"userID": userID,
+	})
+	return &refreshToken, nil
 }
 
 // GetByToken retrieves a refresh token by its token string
@@ -77,6 +87,64 @@ func (r *RefreshTokenRepository) GetByToken(token string) (*RefreshToken, error)
 	return &refreshToken, nil
 }
 
+// MarkUsed records that a refresh token was just used to get an access token
+func (r *RefreshTokenRepository) MarkUsed(id uuid.UUID) error {
+	if err := r.db.Model(&RefreshToken{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error; err != nil {
+		r.logger.LogError(err, "Failed to record refresh token use")
+		return err
+	}
+	return nil
+}
+
+// IsActive reports whether a refresh token of the user exists and is
+// neither revoked nor expired
+func (r *RefreshTokenRepository) IsActive(userID, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&RefreshToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", id, userID, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		r.logger.LogError(err, "Failed to look up refresh token")
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListActiveByUser returns the user's unrevoked, unexpired refresh tokens, newest first
+func (r *RefreshTokenRepository) ListActiveByUser(userID uuid.UUID) ([]RefreshToken, error) {
+	var tokens []RefreshToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		r.logger.LogError(err, "Failed to list refresh tokens")
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeByID revokes one of the user's active refresh tokens
+func (r *RefreshTokenRepository) RevokeByID(userID, id uuid.UUID) error {
+	result := r.db.Model(&RefreshToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", id, userID, time.Now()).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		r.logger.LogError(result.Error, "Failed to revoke refresh token")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+
+	r.logger.LogInfo("Successfully revoked refresh token", map[string]interface{}{
+		"userID":  userID,
+		"tokenID": id,
+	})
+	return nil
+}
+
 // RevokeByToken revokes a refresh token
 func (r *RefreshTokenRepository) RevokeByToken(token string) error {
 	r.logger.LogInfo("Revoking refresh token", nil)
@@ -121,6 +189,26 @@ func (r *RefreshTokenRepository) RevokeAllUserTokens(userID uuid.UUID) error {
 	return nil
 }
 
+// RevokeAllUserTokensExcept revokes all of the user's refresh tokens but one
+// and returns how many were revoked
+func (r *RefreshTokenRepository) RevokeAllUserTokensExcept(userID, keepID uuid.UUID) (int64, error) {
+	result := r.db.Model(&RefreshToken{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		r.logger.LogError(result.Error, "Failed to revoke user tokens")
+		return 0, result.Error
+	}
+
+	r.logger.LogInfo("Successfully revoked other user tokens", map[string]interface{}{
+		"userID":        userID,
+		"keptTokenID":   keepID,
+		"tokensRevoked": result.RowsAffected,
+	})
+	return result.RowsAffected, nil
+}
+
 // DeleteExpired deletes all expired refresh tokens
 func (r *RefreshTokenRepository) DeleteExpired() error {
 	r.logger.LogInfo("Deleting expired refresh tokens", nil)
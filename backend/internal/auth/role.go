@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Role is a user's permission level. Each role includes the permissions of
+// the roles below it.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// roleRank orders roles from least to most privileged
+var roleRank = map[Role]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+var ErrInvalidRole = apierror.Validation("role", "role must be one of user, moderator or admin")
+var ErrUserNotFound = apierror.New(apierror.CodeNotFound, "user not found")
+var ErrLastAdmin = apierror.New(apierror.CodeLastAdmin, "cannot remove the last admin")
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// AtLeast reports whether r grants the permissions of min
+func (r Role) AtLeast(min Role) bool {
+	return r.IsValid() && roleRank[r] >= roleRank[min]
+}
+
+// RoleFromContext returns the role set by the auth middleware, falling back
+// to RoleUser for tokens issued before roles existed
+func RoleFromContext(c *gin.Context) Role {
+	role := Role(c.GetString("role"))
+	if !role.IsValid() {
+		return RoleUser
+	}
+	return role
+}
+
+// RequireRole creates a middleware that only lets through users with at
+// least the given role. It must run after AuthMiddleware.
+func RequireRole(min Role, responseHandler ResponseHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("userID"); !exists {
+			responseHandler.UnauthorizedResponse(c, "Authentication required")
+			c.Abort()
+			return
+		}
+
+		if !RoleFromContext(c).AtLeast(min) {
+			responseHandler.ForbiddenResponse(c, fmt.Sprintf("This action requires the %s role", min))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ListUsers returns all users, optionally filtered by role
+func (s *Service) ListUsers(role Role) ([]User, error) {
+	query := s.db.Order("created_at ASC")
+	if role != "" {
+		if !role.IsValid() {
+			return nil, ErrInvalidRole
+		}
+		query = query.Where("role = ?", role)
+	}
+
+	var users []User
+	if err := query.Find(&users).Error; err != nil {
+		s.logger.LogError(err, "Failed to list users")
+		return nil, fmt.Errorf("failed to list users: %v", err)
+	}
+
+	return users, nil
+}
+
+// SetUserRole changes a user's role. The user's sessions are revoked, which
+// ends their access tokens too, so the new role takes effect at their next
+// login rather than lingering in tokens already issued.
+func (s *Service) SetUserRole(actorID, userID uuid.UUID, role Role) (*User, error) {
+	if !role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+
+	var user User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		s.logger.LogError(err, "Failed to look up user for role change")
+		return nil, fmt.Errorf("failed to look up user: %v", err)
+	}
+
+	if user.Role == role {
+		return &user, nil
+	}
+
+	// Never leave the instance without an admin
+	if user.Role == RoleAdmin {
+		var admins int64
+		if err := s.db.Model(&User{}).Where("role = ?", RoleAdmin).Count(&admins).Error; err != nil {
+			s.logger.LogError(err, "Failed to count admins")
+			return nil, fmt.Errorf("failed to count admins: %v", err)
+		}
+		if admins <= 1 {
+			return nil, ErrLastAdmin
+		}
+	}
+
+	previous := user.Role
+	user.Role = role
+	if err := s.db.Model(&user).Update("role", role).Error; err != nil {
+		s.logger.LogError(err, "Failed to update user role")
+		return nil, fmt.Errorf("failed to update user role: %v", err)
+	}
+
+	if err := s.refreshTokens.RevokeAllUserTokens(user.ID); err != nil {
+		s.logger.LogError(err, "Failed to revoke refresh tokens after role change")
+		return nil, fmt.Errorf("failed to revoke refresh tokens: %v", err)
+	}
+	s.access.forget(user.ID)
+
+	s.logger.LogInfo("User role changed", map[string]interface{}{
+		"actorID":  actorID,
+		"userID":   user.ID,
+		"previous": previous,
+		"role":     role,
+	})
+	s.recordAudit(audit.Event{
+		Type:     audit.RoleChanged,
+		ActorID:  actorID,
+		TargetID: user.ID.String(),
+		Details:  map[string]string{"previous": string(previous), "role": string(role)},
+	})
+
+	return &user, nil
+}
+
+// EnsureAdmins grants the admin role to the accounts with the given emails.
+// It bootstraps the first admins from configuration; emails without an
+// account are skipped.
+func (s *Service) EnsureAdmins(emails []string) error {
+	for _, email := range emails {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			continue
+		}
+
+		result := s.db.Model(&User{}).
+			Where("email = ? AND role <> ?", email, RoleAdmin).
+			Update("role", RoleAdmin)
+		if result.Error != nil {
+			s.logger.LogError(result.Error, "Failed to grant configured admin role")
+			return fmt.Errorf("failed to grant admin role to %s: %v", email, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			s.logger.LogInfo("Granted admin role from configuration", map[string]interface{}{
+				"email": email,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/google/uuid"
+)
+
+func TestRoleRanking(t *testing.T) {
+	tests := []struct {
+		role auth.Role
+		min  auth.Role
+		want bool
+	}{
+		{auth.RoleAdmin, auth.RoleModerator, true},
+		{auth.RoleModerator, auth.RoleModerator, true},
+		{auth.RoleUser, auth.RoleModerator, false},
+		{auth.RoleModerator, auth.RoleAdmin, false},
+		{auth.Role("superuser"), auth.RoleUser, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.role.AtLeast(tt.min); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.role, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestRoleManagement(t *testing.T) {
+	router, authService, db := setupTestRouter(t)
+
+	register := func(username string) *auth.User {
+		user, err := authService.Register(auth.RegisterRequest{
+			Username: username,
+			Email:    username + "@example.com",
+			Password: "Pass123!",
+		})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", username, err)
+		}
+		if err := authService.MarkEmailVerified(user.ID); err != nil {
+			t.Fatalf("Failed to verify %s: %v", username, err)
+		}
+		return user
+	}
+
+	admin := register("roleadmin")
+	member := register("rolemember")
+
+	if member.Role != auth.RoleUser {
+		t.Fatalf("Expected new users to have the user role, got %q", member.Role)
+	}
+
+	t.Run("Configured admins are promoted", func(t *testing.T) {
+		if err := authService.EnsureAdmins([]string{admin.Email, "missing@example.com"}); err != nil {
+			t.Fatalf("EnsureAdmins failed: %v", err)
+		}
+
+		var stored auth.User
+		db.First(&stored, "id = ?", admin.ID)
+		if stored.Role != auth.RoleAdmin {
+			t.Errorf("Expected admin role, got %q", stored.Role)
+		}
+	})
+
+	login := func(email string) string {
+		response, err := authService.Login(email, "Pass123!", auth.ClientInfo{})
+		if err != nil {
+			t.Fatalf("Login failed for %s: %v", email, err)
+		}
+		return response.AccessToken
+	}
+
+	updateRole := func(token string, userID uuid.UUID, role auth.Role) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(auth.UpdateRoleRequest{Role: role})
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/users/"+userID.String()+"/role", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Non-admins cannot change roles", func(t *testing.T) {
+		w := updateRole(login(member.Email), member.ID, auth.RoleAdmin)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Admin promotes a moderator", func(t *testing.T) {
+		w := updateRole(login(admin.Email), member.ID, auth.RoleModerator)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		// The new role is carried in freshly issued tokens
+		claims, err := authService.ValidateToken(login(member.Email))
+		if err != nil {
+			t.Fatalf("Failed to validate token: %v", err)
+		}
+		if claims.Role != auth.RoleModerator {
+			t.Errorf("Expected moderator role in token, got %q", claims.Role)
+		}
+	})
+
+	t.Run("Invalid role is rejected", func(t *testing.T) {
+		w := updateRole(login(admin.Email), member.ID, auth.Role("owner"))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("Last admin cannot be demoted", func(t *testing.T) {
+		_, err := authService.SetUserRole(admin.ID, admin.ID, auth.RoleUser)
+		if !errors.Is(err, auth.ErrLastAdmin) {
+			t.Errorf("Expected ErrLastAdmin, got %v", err)
+		}
+	})
+
+	t.Run("List users by role", func(t *testing.T) {
+		moderators, err := authService.ListUsers(auth.RoleModerator)
+		if err != nil {
+			t.Fatalf("ListUsers failed: %v", err)
+		}
+		if len(moderators) != 1 || moderators[0].ID != member.ID {
+			t.Errorf("Expected only the promoted member, got %d users", len(moderators))
+		}
+	})
+}
+
+func TestDemotedAdminTokens(t *testing.T) {
+	router, authService, _ := setupTestRouter(t)
+
+	register := func(username string) *auth.User {
+		user, err := authService.Register(auth.RegisterRequest{
+			Username: username,
+			Email:    username + "@example.com",
+			Password: "Pass123!",
+		})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", username, err)
+		}
+		if err := authService.MarkEmailVerified(user.ID); err != nil {
+			t.Fatalf("Failed to verify %s: %v", username, err)
+		}
+		return user
+	}
+
+	owner := register("demoteowner")
+	demoted := register("demotedadmin")
+	if err := authService.EnsureAdmins([]string{owner.Email, demoted.Email}); err != nil {
+		t.Fatalf("EnsureAdmins failed: %v", err)
+	}
+	login, err := authService.Login(demoted.Email, "Pass123!", auth.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	listUsers := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := listUsers(login.AccessToken); code != http.StatusOK {
+		t.Fatalf("Expected status 200 before the demotion, got %d", code)
+	}
+
+	if _, err := authService.SetUserRole(owner.ID, demoted.ID, auth.RoleUser); err != nil {
+		t.Fatalf("SetUserRole failed: %v", err)
+	}
+
+	// Neither token issued with the admin role still grants it
+	if code := listUsers(login.AccessToken); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for the old access token, got %d", code)
+	}
+	if code := listUsers(login.RefreshToken); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for the old refresh token, got %d", code)
+	}
+
+	// After signing in again the user has the user role
+	fresh, err := authService.Login(demoted.Email, "Pass123!", auth.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if code := listUsers(fresh.AccessToken); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 after signing in again, got %d", code)
+	}
+}
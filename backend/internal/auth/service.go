@@ -1,11 +1,15 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/audit"
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/mail"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -18,21 +22,41 @@ type Service struct {
 	refreshTokens RefreshTokenService
 	config        *Config
 	logger        logger.Logger
+	mailer        mail.Mailer
+	audit         audit.Recorder
+	// oauthProviders holds the enabled social login providers by name
+	oauthProviders map[string]*oauthProvider
+	// access remembers the checked sessions of recent access tokens
+	access *accessCache
 }
 
 // NewService creates a new auth service instance
 func NewService(db *gorm.DB, ts TokenService, rt RefreshTokenService, config *Config, logger logger.Logger) *Service {
 	return &Service{
-		db:            db,
-		tokenService:  ts,
-		refreshTokens: rt,
-		config:        config,
-		logger:        logger,
+		db:             db,
+		tokenService:   ts,
+		refreshTokens:  rt,
+		config:         config,
+		logger:         logger,
+		oauthProviders: newOAuthProviders(config.OAuth),
+		access:         newAccessCache(),
 	}
 }
 
-// Login handles user authentication
-func (s *Service) Login(identifier, password string) (*LoginResponse, error) {
+// SetAuditLog records logins and role changes in the audit log
+func (s *Service) SetAuditLog(recorder audit.Recorder) {
+	s.audit = recorder
+}
+
+// recordAudit appends an event to the audit log when one is set
+func (s *Service) recordAudit(event audit.Event) {
+	if s.audit != nil {
+		s.audit.Record(context.Background(), event)
+	}
+}
+
+// Login handles user authentication and starts a session for the client
+func (s *Service) Login(identifier, password string, client ClientInfo) (*LoginResponse, error) {
 	s.logger.LogInfo("Login attempt", map[string]interface{}{
 		"identifier": identifier,
 	})
@@ -47,12 +71,23 @@ func (s *Service) Login(identifier, password string) (*LoginResponse, error) {
 		return nil, ErrInvalidCredentials
 	}
 
+	// A locked account rejects even the correct password until the lock expires
+	if err := s.checkLockout(&user); err != nil {
+		s.logger.LogWarn("Login attempt on locked account", map[string]interface{}{
+			"email":  user.Email,
+			"userID": user.ID,
+		})
+		s.recordRejectedLogin(user.ID, reasonAccountLocked, client)
+		return nil, err
+	}
+
 	// Check if user's email is verified
 	if !user.EmailVerified {
 		s.logger.LogWarn("Login attempt with unverified email", map[string]interface{}{
 			"email":  user.Email,
 			"userID": user.ID,
 		})
+		s.recordRejectedLogin(user.ID, reasonEmailNotVerified, client)
 		return nil, ErrEmailNotVerified
 	}
 
@@ -61,22 +96,71 @@ func (s *Service) Login(identifier, password string) (*LoginResponse, error) {
 			"email":  user.Email,
 			"userID": user.ID,
 		})
+		s.recordRejectedLogin(user.ID, reasonInvalidPassword, client)
+		if err := s.recordFailedLogin(&user); err != nil {
+			return nil, err
+		}
 		return nil, ErrInvalidCredentials
 	}
 
-	s.logger.LogInfo("Generating tokens", map[string]interface{}{
+	s.clearFailedLogins(&user)
+
+	// Only reveal the suspension to someone who knows the password
+	if !user.Active {
+		s.logger.LogWarn("Login attempt on suspended account", map[string]interface{}{
+			"email":  user.Email,
+			"userID": user.ID,
+		})
+		s.recordRejectedLogin(user.ID, reasonAccountSuspended, client)
+		return nil, ErrAccountSuspended
+	}
+
+	response, err := s.issueTokens(&user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.LogInfo("Login successful", map[string]interface{}{
 		"userID": user.ID,
 		"email":  user.Email,
 	})
+	s.recordAudit(audit.Event{
+		Type:      audit.UserLogin,
+		ActorID:   user.ID,
+		TargetID:  user.ID.String(),
+		IPAddress: client.IPAddress,
+	})
+	s.recordSecurityEvent(SecurityEvent{
+		UserID:  user.ID,
+		Type:    SecurityLogin,
+		Outcome: OutcomeSuccess,
+		Method:  methodPassword,
+	}, client)
 
-	// Generate tokens
-	accessToken, err := s.tokenService.GenerateAccessToken(&user)
-	if err != nil {
-		s.logger.LogError(err, "Failed to generate access token")
-		return nil, fmt.Errorf("failed to generate access token: %v", err)
-	}
+	return response, nil
+}
+
+// recordRejectedLogin records a rejected password login on the user's account
+func (s *Service) recordRejectedLogin(userID uuid.UUID, reason string, client ClientInfo) {
+	s.recordSecurityEvent(SecurityEvent{
+		UserID:  userID,
+		Type:    SecurityLogin,
+		Outcome: OutcomeFailure,
+		Reason:  reason,
+		Method:  methodPassword,
+	}, client)
+}
+
+// issueTokens starts a session for an authenticated user: it creates and
+// stores an access/refresh token pair and records the login time
+func (s *Service) issueTokens(user *User, client ClientInfo) (*LoginResponse, error) {
+	s.logger.LogInfo("Generating tokens", map[string]interface{}{
+		"userID": user.ID,
+		"email":  user.Email,
+	})
 
-	refreshToken, err := s.tokenService.GenerateRefreshToken(&user)
+	// The refresh token is stored first; its record ID identifies the session in access tokens
+	refreshToken, err := s.tokenService.GenerateRefreshToken(user)
 	if err != nil {
 		s.logger.LogError(err, "Failed to generate refresh token")
 		return nil, fmt.Errorf("failed to generate refresh token: %v", err)
@@ -87,36 +171,39 @@ func (s *Service) Login(identifier, password string) (*LoginResponse, error) {
 	})
 
 	// Store refresh token
-	if err := s.refreshTokens.Create(user.ID, refreshToken, time.Now().Add(s.config.JWT.RefreshTokenTTL)); err != nil {
+	session, err := s.refreshTokens.Create(user.ID, refreshToken, time.Now().Add(s.config.JWT.RefreshTokenTTL), client)
+	if err != nil {
 		s.logger.LogError(err, "Failed to store refresh token")
 		return nil, fmt.Errorf("failed to store refresh token: %v", err)
 	}
 
+	accessToken, err := s.tokenService.GenerateAccessToken(user, session.ID)
+	if err != nil {
+		s.logger.LogError(err, "Failed to generate access token")
+		return nil, fmt.Errorf("failed to generate access token: %v", err)
+	}
+
 	// Update last login timestamp
 	user.LastLoginAt = time.Now()
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := s.db.Save(user).Error; err != nil {
 		s.logger.LogError(err, "Failed to update last login timestamp")
 		return nil, err
 	}
 
 	response := &LoginResponse{
-		User:         user,
+		User:         *user,
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    int(s.config.JWT.AccessTokenTTL.Seconds()),
 	}
 
-	s.logger.LogInfo("Login successful", map[string]interface{}{
-		"userID": user.ID,
-		"email":  user.Email,
-	})
-
 	return response, nil
 }
 
-var ErrInvalidCredentials = errors.New("invalid credentials")
-var ErrEmailNotVerified = errors.New("email not verified")
+var ErrInvalidCredentials = apierror.New(apierror.CodeAuth, "invalid credentials")
+var ErrEmailNotVerified = apierror.New(apierror.CodeEmailNotVerified, "email not verified")
+var ErrUserExists = apierror.New(apierror.CodeRegistration, "user already exists")
 
 func checkPasswordHash(password, hash string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
@@ -152,7 +239,7 @@ func (s *Service) Register(req RegisterRequest) (*User, error) {
 			"username": req.Username,
 			"email":    req.Email,
 		})
-		return nil, errors.New("user already exists")
+		return nil, ErrUserExists
 	}
 
 	// Hash the password
@@ -169,6 +256,7 @@ func (s *Service) Register(req RegisterRequest) (*User, error) {
 		Password:      string(hashed),
 		Name:          req.Name,
 		EmailVerified: false,
+		Role:          RoleUser,
 		Active:        true,
 		CreatedAt:     time.Now(),
 		UpdatedAt:     time.Now(),
@@ -185,13 +273,19 @@ func (s *Service) Register(req RegisterRequest) (*User, error) {
 		"email":    user.Email,
 	})
 
-	// TODO: Send verification email to the user
+	// Registration succeeds even if the email cannot be sent; the user can request a resend
+	if err := s.sendVerificationEmail(&user); err != nil {
+		s.logger.LogWarn("Failed to send verification email after registration", map[string]interface{}{
+			"userID": user.ID,
+			"error":  err.Error(),
+		})
+	}
 
 	return &user, nil
 }
 
 // Logout invalidates the provided refresh token and ends the session for the user.
-func (s *Service) Logout(userID uuid.UUID, refreshToken string) error {
+func (s *Service) Logout(userID uuid.UUID, refreshToken string, client ClientInfo) error {
 	s.logger.LogInfo("Logout attempt", map[string]interface{}{
 		"userID": userID,
 	})
@@ -226,16 +320,22 @@ func (s *Service) Logout(userID uuid.UUID, refreshToken string) error {
 		s.logger.LogError(err, "Failed to revoke refresh token")
 		return fmt.Errorf("failed to revoke refresh token: %v", err)
 	}
+	s.access.forget(userID)
 
 	s.logger.LogInfo("Logout successful", map[string]interface{}{
 		"userID": userID,
 	})
+	s.recordSecurityEvent(SecurityEvent{
+		UserID:  userID,
+		Type:    SecurityLogout,
+		Outcome: OutcomeSuccess,
+	}, client)
 
 	return nil
 }
 
 // RefreshToken generates a new access token using the provided refresh token.
-func (s *Service) RefreshToken(refreshToken string) (*LoginResponse, error) {
+func (s *Service) RefreshToken(refreshToken string, client ClientInfo) (*LoginResponse, error) {
 	s.logger.LogInfo("Token refresh attempt", nil)
 
 	// Validate the refresh token
@@ -253,6 +353,18 @@ func (s *Service) RefreshToken(refreshToken string) (*LoginResponse, error) {
 		s.logger.LogError(err, "User not found during token refresh")
 		return nil, fmt.Errorf("user not found: %v", err)
 	}
+	if !user.Active {
+		s.logger.LogWarn("Token refresh for suspended account", map[string]interface{}{
+			"userID": user.ID,
+		})
+		s.recordSecurityEvent(SecurityEvent{
+			UserID:  user.ID,
+			Type:    SecurityTokenRefresh,
+			Outcome: OutcomeFailure,
+			Reason:  reasonAccountSuspended,
+		}, client)
+		return nil, ErrAccountSuspended
+	}
 
 	// Verify refresh token exists and is valid
 	storedToken, err := s.refreshTokens.GetByToken(refreshToken)
@@ -267,19 +379,34 @@ func (s *Service) RefreshToken(refreshToken string) (*LoginResponse, error) {
 		s.logger.LogWarn("Attempt to use revoked refresh token", map[string]interface{}{
 			"userID": user.ID,
 		})
+		// A revoked token in use may have been stolen, which is worth showing the user
+		s.recordSecurityEvent(SecurityEvent{
+			UserID:  user.ID,
+			Type:    SecurityTokenRefresh,
+			Outcome: OutcomeFailure,
+			Reason:  reasonTokenRevoked,
+		}, client)
 		return nil, fmt.Errorf("refresh token has been revoked")
 	}
 
 	// Generate only new access token
-	accessToken, err := s.tokenService.GenerateAccessToken(&user)
+	accessToken, err := s.tokenService.GenerateAccessToken(&user, storedToken.ID)
 	if err != nil {
 		s.logger.LogError(err, "Failed to generate new access token")
 		return nil, fmt.Errorf("failed to generate access token: %v", err)
 	}
 
+	// Shown as the session's last activity; a failure here should not fail the refresh
+	_ = s.refreshTokens.MarkUsed(storedToken.ID)
+
 	s.logger.LogInfo("Token refresh successful", map[string]interface{}{
 		"userID": user.ID,
 	})
+	s.recordSecurityEvent(SecurityEvent{
+		UserID:  user.ID,
+		Type:    SecurityTokenRefresh,
+		Outcome: OutcomeSuccess,
+	}, client)
 
 	return &LoginResponse{
 		User:         user,
@@ -290,19 +417,21 @@ func (s *Service) RefreshToken(refreshToken string) (*LoginResponse, error) {
 	}, nil
 }
 
-// ValidateToken validates the provided token and returns its claims if valid.
+// ValidateToken validates the provided access token and returns its claims
+// if valid. Refresh tokens are not accepted. The token's account must still
+// be active and its session unrevoked, and its role is replaced with the
+// user's current one; these are looked up at most every accessCheckTTL.
 func (s *Service) ValidateToken(token string) (*TokenClaims, error) {
-	// First try to validate as access token
 	claims, err := s.tokenService.ValidateAccessToken(token)
-	if err == nil {
-		return claims, nil
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %v", err)
 	}
 
-	// If not an access token, try as refresh token
-	claims, err = s.tokenService.ValidateRefreshToken(token)
+	role, err := s.checkAccess(claims)
 	if err != nil {
-		return nil, fmt.Errorf("invalid token: %v", err)
+		return nil, fmt.Errorf("invalid token: %w", err)
 	}
+	claims.Role = role
 
 	return claims, nil
 }
@@ -332,3 +461,26 @@ func (s *Service) MarkEmailVerified(userID uuid.UUID) error {
 
 	return nil
 }
+
+// DigestRecipient returns the name and email address notification digests
+// are sent to. Users who have not verified their email, suspended users and
+// deleted users are not emailed.
+func (s *Service) DigestRecipient(ctx context.Context, userID uuid.UUID) (string, string, bool, error) {
+	var user User
+	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", false, nil
+		}
+		s.logger.LogError(err, "Failed to look up digest recipient")
+		return "", "", false, fmt.Errorf("failed to look up user: %v", err)
+	}
+	if !user.Active || !user.EmailVerified {
+		return "", "", false, nil
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Username
+	}
+	return name, user.Email, true, nil
+}
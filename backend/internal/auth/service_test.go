@@ -6,27 +6,30 @@ import (
 	"time"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
 	"github.com/consensuslabs/pavilion-network/backend/testhelper"
 	"github.com/google/uuid"
 )
 
+// newTestConfig returns an auth configuration with the token lifetimes used across the tests
+func newTestConfig(secret string) *auth.Config {
+	return &auth.Config{
+		JWT: config.JWTConfig{
+			Secret:          secret,
+			AccessTokenTTL:  time.Hour,
+			RefreshTokenTTL: time.Hour * 24 * 7,
+		},
+		Password: auth.DefaultPasswordPolicy(),
+	}
+}
+
 func TestRegisterAndLogin(t *testing.T) {
 	fmt.Printf("\n=== Starting TestRegisterAndLogin ===\n")
 
 	db := testhelper.SetupTestDB(t)
 	logger := testhelper.NewTestLogger(true)
 
-	config := &auth.Config{
-		JWT: struct {
-			Secret          string
-			AccessTokenTTL  time.Duration
-			RefreshTokenTTL time.Duration
-		}{
-			Secret:          "test-secret-" + uuid.New().String(),
-			AccessTokenTTL:  time.Hour,
-			RefreshTokenTTL: time.Hour * 24 * 7,
-		},
-	}
+	config := newTestConfig("test-secret-" + uuid.New().String())
 
 	fmt.Printf("Test config initialized with secret: %s\n", config.JWT.Secret)
 
@@ -81,7 +84,7 @@ func TestRegisterAndLogin(t *testing.T) {
 
 	// Test Login with username
 	fmt.Printf("Attempting login with username: %s\n", regReq.Username)
-	loginResp, err := authService.Login(regReq.Username, regReq.Password)
+	loginResp, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
@@ -101,7 +104,7 @@ func TestRegisterAndLogin(t *testing.T) {
 
 	// Test Login with email
 	fmt.Printf("Attempting login with email: %s\n", regReq.Email)
-	loginResp, err = authService.Login(regReq.Email, regReq.Password)
+	loginResp, err = authService.Login(regReq.Email, regReq.Password, auth.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Login failed with email: %v", err)
 	}
@@ -109,7 +112,7 @@ func TestRegisterAndLogin(t *testing.T) {
 	fmt.Printf("Login successful with email\n")
 
 	// Test login with invalid password
-	_, err = authService.Login(regReq.Username, "WrongPass")
+	_, err = authService.Login(regReq.Username, "WrongPass", auth.ClientInfo{})
 	if err == nil {
 		t.Errorf("expected error on invalid password, got nil")
 	}
@@ -121,17 +124,7 @@ func TestLogout(t *testing.T) {
 	db := testhelper.SetupTestDB(t)
 	logger := testhelper.NewTestLogger(true)
 
-	config := &auth.Config{
-		JWT: struct {
-			Secret          string
-			AccessTokenTTL  time.Duration
-			RefreshTokenTTL time.Duration
-		}{
-			Secret:          "test-secret-" + uuid.New().String(),
-			AccessTokenTTL:  time.Hour,
-			RefreshTokenTTL: time.Hour * 24 * 7,
-		},
-	}
+	config := newTestConfig("test-secret-" + uuid.New().String())
 
 	// Use real JWT service instead of dummy
 	jwtService := auth.NewJWTService(config)
@@ -163,13 +156,13 @@ func TestLogout(t *testing.T) {
 	}
 
 	// Login to get a real refresh token
-	loginResp, err := authService.Login(regReq.Username, regReq.Password)
+	loginResp, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
 
 	// Test logout with the real refresh token
-	err = authService.Logout(user.ID, loginResp.RefreshToken)
+	err = authService.Logout(user.ID, loginResp.RefreshToken, auth.ClientInfo{})
 	if err != nil {
 		t.Errorf("expected successful logout, got error: %v", err)
 	}
@@ -185,17 +178,7 @@ func TestRefreshToken(t *testing.T) {
 	db := testhelper.SetupTestDB(t)
 	logger := testhelper.NewTestLogger(true)
 
-	config := &auth.Config{
-		JWT: struct {
-			Secret          string
-			AccessTokenTTL  time.Duration
-			RefreshTokenTTL time.Duration
-		}{
-			Secret:          "test-secret-" + uuid.New().String(),
-			AccessTokenTTL:  time.Hour,
-			RefreshTokenTTL: time.Hour * 24 * 7,
-		},
-	}
+	config := newTestConfig("test-secret-" + uuid.New().String())
 
 	// Use real JWT service instead of dummy
 	jwtService := auth.NewJWTService(config)
@@ -222,7 +205,7 @@ func TestRefreshToken(t *testing.T) {
 	}
 
 	// Login to get initial tokens
-	loginResp, err := authService.Login(regReq.Username, regReq.Password)
+	loginResp, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
@@ -241,7 +224,7 @@ func TestRefreshToken(t *testing.T) {
 	}
 
 	// Test refresh token
-	refreshResp, err := authService.RefreshToken(originalRefreshToken)
+	refreshResp, err := authService.RefreshToken(originalRefreshToken, auth.ClientInfo{})
 	if err != nil {
 		t.Errorf("Token refresh failed: %v", err)
 	}
@@ -280,7 +263,7 @@ func TestRefreshToken(t *testing.T) {
 
 	// Test error cases
 	t.Run("Invalid refresh token", func(t *testing.T) {
-		_, err := authService.RefreshToken("invalid-token")
+		_, err := authService.RefreshToken("invalid-token", auth.ClientInfo{})
 		if err == nil {
 			t.Error("Expected error with invalid refresh token")
 		}
@@ -294,7 +277,7 @@ func TestRefreshToken(t *testing.T) {
 			t.Fatalf("Failed to expire token: %v", err)
 		}
 
-		_, err := authService.RefreshToken(originalRefreshToken)
+		_, err := authService.RefreshToken(originalRefreshToken, auth.ClientInfo{})
 		if err == nil {
 			t.Error("Expected error with expired refresh token")
 		}
@@ -305,17 +288,7 @@ func TestValidateToken(t *testing.T) {
 	db := testhelper.SetupTestDB(t)
 	logger := testhelper.NewTestLogger(true)
 
-	config := &auth.Config{
-		JWT: struct {
-			Secret          string
-			AccessTokenTTL  time.Duration
-			RefreshTokenTTL time.Duration
-		}{
-			Secret:          "test-secret-" + uuid.New().String(),
-			AccessTokenTTL:  time.Hour,
-			RefreshTokenTTL: time.Hour * 24 * 7,
-		},
-	}
+	config := newTestConfig("test-secret-" + uuid.New().String())
 
 	// Use real JWT service
 	jwtService := auth.NewJWTService(config)
@@ -347,7 +320,7 @@ func TestValidateToken(t *testing.T) {
 	}
 
 	// Login to get a real token
-	loginResp, err := authService.Login(regReq.Username, regReq.Password)
+	loginResp, err := authService.Login(regReq.Username, regReq.Password, auth.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Login failed: %v", err)
 	}
@@ -370,3 +343,30 @@ func TestValidateToken(t *testing.T) {
 		t.Errorf("expected user ID %s, got %s", user.ID.String(), claims.UserID)
 	}
 }
+
+func TestTokenTypes(t *testing.T) {
+	jwtService := auth.NewJWTService(newTestConfig("test-secret-" + uuid.New().String()))
+	user := &auth.User{ID: uuid.New(), Email: "types@example.com", Role: auth.RoleAdmin}
+
+	access, err := jwtService.GenerateAccessToken(user, uuid.New())
+	if err != nil {
+		t.Fatalf("GenerateAccessToken failed: %v", err)
+	}
+	refresh, err := jwtService.GenerateRefreshToken(user)
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken failed: %v", err)
+	}
+
+	if _, err := jwtService.ValidateAccessToken(access); err != nil {
+		t.Errorf("Expected access token to validate, got %v", err)
+	}
+	if _, err := jwtService.ValidateRefreshToken(refresh); err != nil {
+		t.Errorf("Expected refresh token to validate, got %v", err)
+	}
+	if _, err := jwtService.ValidateAccessToken(refresh); err == nil {
+		t.Error("Expected refresh token to be rejected as an access token")
+	}
+	if _, err := jwtService.ValidateRefreshToken(access); err == nil {
+		t.Error("Expected access token to be rejected as a refresh token")
+	}
+}
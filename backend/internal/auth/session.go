@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sessionIDContextKey holds the session of the access token that authenticated a request
+const sessionIDContextKey = "sessionID"
+
+var ErrSessionNotFound = apierror.New(apierror.CodeNotFound, "session not found")
+
+// ListSessions returns the user's active sessions, newest first. The session
+// with currentID is marked as the current one.
+func (s *Service) ListSessions(userID, currentID uuid.UUID) ([]Session, error) {
+	tokens, err := s.refreshTokens.ListActiveByUser(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %v", err)
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, Session{
+			ID:         token.ID.String(),
+			IPAddress:  token.IPAddress,
+			UserAgent:  token.UserAgent,
+			CreatedAt:  token.CreatedAt,
+			LastUsedAt: token.LastUsedAt,
+			ExpiresAt:  token.ExpiresAt,
+			Current:    token.ID == currentID,
+		})
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs the user out on one device by revoking the session's
+// refresh token. Access tokens issued to the session are rejected from then
+// on, within accessCheckTTL on other instances.
+func (s *Service) RevokeSession(userID, sessionID uuid.UUID) error {
+	if err := s.refreshTokens.RevokeByID(userID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to revoke session: %v", err)
+	}
+	s.access.forget(userID)
+
+	s.logger.LogInfo("Session revoked", map[string]interface{}{
+		"userID":    userID,
+		"sessionID": sessionID,
+	})
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except the current
+// session and returns how many sessions were revoked. When the current
+// session is unknown (uuid.Nil) every session is revoked.
+func (s *Service) RevokeOtherSessions(userID, currentID uuid.UUID) (int64, error) {
+	revoked, err := s.refreshTokens.RevokeAllUserTokensExcept(userID, currentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %v", err)
+	}
+	s.access.forget(userID)
+
+	s.logger.LogInfo("Other sessions revoked", map[string]interface{}{
+		"userID":    userID,
+		"sessionID": currentID,
+		"revoked":   revoked,
+	})
+	return revoked, nil
+}
+
+// clientInfo captures the device details stored with a new session
+func clientInfo(c *gin.Context) ClientInfo {
+	return ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// currentSessionID returns the session of the request's access token, or
+// uuid.Nil for tokens issued before sessions were tracked
+func currentSessionID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString(sessionIDContextKey))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
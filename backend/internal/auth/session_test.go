@@ -0,0 +1,167 @@
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+)
+
+func TestSessions(t *testing.T) {
+	db := testhelper.SetupTestDB(t)
+	logger := testhelper.NewTestLogger(true)
+
+	config := newTestConfig("test-secret-" + uuid.New().String())
+	jwtService := auth.NewJWTService(config)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db, logger)
+	authService := auth.NewService(db, jwtService, refreshTokenRepo, config, logger)
+
+	regReq := auth.RegisterRequest{
+		Username: "sessionuser",
+		Email:    "sessions@example.com",
+		Password: "Pass123!",
+		Name:     "Session User",
+	}
+	user, err := authService.Register(regReq)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := authService.MarkEmailVerified(user.ID); err != nil {
+		t.Fatalf("MarkEmailVerified failed: %v", err)
+	}
+
+	laptop := auth.ClientInfo{IPAddress: "203.0.113.7", UserAgent: "Firefox"}
+	phone := auth.ClientInfo{IPAddress: "198.51.100.2", UserAgent: "PavilionApp/1.0"}
+	tablet := auth.ClientInfo{IPAddress: "192.0.2.10", UserAgent: "Safari"}
+
+	current, err := authService.Login(regReq.Email, regReq.Password, laptop)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	phoneLogin, err := authService.Login(regReq.Email, regReq.Password, phone)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	tabletLogin, err := authService.Login(regReq.Email, regReq.Password, tablet)
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// Access tokens identify their session
+	claims, err := authService.ValidateToken(current.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	currentID := uuid.MustParse(claims.SessionID)
+
+	sessions, err := authService.ListSessions(user.ID, currentID)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("expected 3 sessions, got %d", len(sessions))
+	}
+	var phoneID uuid.UUID
+	for _, session := range sessions {
+		if session.Current != (session.ID == currentID.String()) {
+			t.Errorf("session %s: unexpected current flag %v", session.ID, session.Current)
+		}
+		if session.UserAgent == phone.UserAgent {
+			phoneID = uuid.MustParse(session.ID)
+			if session.IPAddress != phone.IPAddress {
+				t.Errorf("expected IP %s, got %s", phone.IPAddress, session.IPAddress)
+			}
+		}
+	}
+
+	if err := authService.RevokeSession(user.ID, phoneID); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+	if _, err := authService.RefreshToken(phoneLogin.RefreshToken, auth.ClientInfo{}); err == nil {
+		t.Error("expected revoked session's refresh token to be rejected")
+	}
+	if err := authService.RevokeSession(user.ID, phoneID); !errors.Is(err, auth.ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound for a revoked session, got %v", err)
+	}
+
+	revoked, err := authService.RevokeOtherSessions(user.ID, currentID)
+	if err != nil {
+		t.Fatalf("RevokeOtherSessions failed: %v", err)
+	}
+	if revoked != 1 {
+		t.Errorf("expected 1 session revoked, got %d", revoked)
+	}
+	if _, err := authService.RefreshToken(tabletLogin.RefreshToken, auth.ClientInfo{}); err == nil {
+		t.Error("expected other session's refresh token to be rejected")
+	}
+	if _, err := authService.RefreshToken(current.RefreshToken, auth.ClientInfo{}); err != nil {
+		t.Errorf("expected current session to stay active, got %v", err)
+	}
+}
+
+func TestRevokedSessionTokens(t *testing.T) {
+	router, authService, _ := setupTestRouter(t)
+
+	regReq := auth.RegisterRequest{
+		Username: "revokeduser",
+		Email:    "revoked@example.com",
+		Password: "Pass123!",
+	}
+	user, err := authService.Register(regReq)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := authService.MarkEmailVerified(user.ID); err != nil {
+		t.Fatalf("MarkEmailVerified failed: %v", err)
+	}
+
+	laptop, err := authService.Login(regReq.Email, regReq.Password, auth.ClientInfo{UserAgent: "Firefox"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	phone, err := authService.Login(regReq.Email, regReq.Password, auth.ClientInfo{UserAgent: "PavilionApp/1.0"})
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := get(phone.AccessToken); code != http.StatusOK {
+		t.Fatalf("Expected status 200 for an access token, got %d", code)
+	}
+	if code := get(phone.RefreshToken); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a refresh token used as a bearer token, got %d", code)
+	}
+
+	claims, err := authService.ValidateToken(phone.AccessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/auth/sessions/"+claims.SessionID, nil)
+	req.Header.Set("Authorization", "Bearer "+laptop.AccessToken)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 revoking the session, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if code := get(phone.RefreshToken); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for the revoked session's refresh token, got %d", code)
+	}
+	if code := get(phone.AccessToken); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for the revoked session's access token, got %d", code)
+	}
+	if code := get(laptop.AccessToken); code != http.StatusOK {
+		t.Errorf("Expected the other session to keep working, got %d", code)
+	}
+}
@@ -0,0 +1,106 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/google/uuid"
+)
+
+func TestSuspension(t *testing.T) {
+	router, authService, _ := setupTestRouter(t)
+
+	register := func(username string) *auth.User {
+		user, err := authService.Register(auth.RegisterRequest{
+			Username: username,
+			Email:    username + "@example.com",
+			Password: "Pass123!",
+		})
+		if err != nil {
+			t.Fatalf("Failed to register %s: %v", username, err)
+		}
+		if err := authService.MarkEmailVerified(user.ID); err != nil {
+			t.Fatalf("Failed to verify %s: %v", username, err)
+		}
+		return user
+	}
+
+	admin := register("suspendadmin")
+	member := register("suspendmember")
+	if err := authService.EnsureAdmins([]string{admin.Email}); err != nil {
+		t.Fatalf("EnsureAdmins failed: %v", err)
+	}
+
+	adminLogin, err := authService.Login(admin.Email, "Pass123!", auth.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Admin login failed: %v", err)
+	}
+	memberLogin, err := authService.Login(member.Email, "Pass123!", auth.ClientInfo{})
+	if err != nil {
+		t.Fatalf("Member login failed: %v", err)
+	}
+
+	suspend := func(userID uuid.UUID, reason string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(auth.SuspendUserRequest{Reason: reason})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/users/"+userID.String()+"/suspend", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+adminLogin.AccessToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Reason is required", func(t *testing.T) {
+		if w := suspend(member.ID, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Admins cannot suspend themselves", func(t *testing.T) {
+		if _, err := authService.SuspendUser(admin.ID, admin.ID, "testing"); !errors.Is(err, auth.ErrSuspendSelf) {
+			t.Errorf("Expected ErrSuspendSelf, got %v", err)
+		}
+	})
+
+	t.Run("Suspended users cannot sign in or refresh", func(t *testing.T) {
+		if w := suspend(member.ID, "Spam"); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		if _, err := authService.Login(member.Email, "Pass123!", auth.ClientInfo{}); !errors.Is(err, auth.ErrAccountSuspended) {
+			t.Errorf("Expected ErrAccountSuspended on login, got %v", err)
+		}
+		if _, err := authService.RefreshToken(memberLogin.RefreshToken, auth.ClientInfo{}); err == nil {
+			t.Error("Expected refresh to fail for a suspended user")
+		}
+
+		// Tokens issued before the suspension no longer authenticate
+		for name, token := range map[string]string{"access": memberLogin.AccessToken, "refresh": memberLogin.RefreshToken} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/sessions", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status 401 for the %s token, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("Reinstated users can sign in again", func(t *testing.T) {
+		user, err := authService.ReinstateUser(admin.ID, member.ID)
+		if err != nil {
+			t.Fatalf("ReinstateUser failed: %v", err)
+		}
+		if !user.Active || user.SuspendedAt != nil || user.SuspensionReason != "" {
+			t.Errorf("Expected an active account without suspension details, got %+v", user)
+		}
+		if _, err := authService.Login(member.Email, "Pass123!", auth.ClientInfo{}); err != nil {
+			t.Errorf("Login after reinstatement failed: %v", err)
+		}
+	})
+}
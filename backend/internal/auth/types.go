@@ -9,32 +9,50 @@ import (
 
 // Config represents authentication configuration
 type Config struct {
-	JWT struct {
-		Secret          string
-		AccessTokenTTL  time.Duration
-		RefreshTokenTTL time.Duration
-	}
-	Password struct {
-		MinLength  int
-		MaxLength  int
-		MinDigits  int
-		MinSymbols int
+	JWT               config.JWTConfig
+	Password          PasswordPolicy
+	PasswordReset     config.PasswordResetConfig
+	EmailVerification config.EmailVerificationConfig
+	// Lockout temporarily locks accounts after repeated failed logins
+	Lockout config.LockoutConfig
+	// ActivityRetention is how long account activity is kept
+	ActivityRetention time.Duration
+	// OAuth holds the social login providers; a provider is enabled when it has a client ID
+	OAuth map[string]config.OAuthProviderConfig
+	// AdminEmails are promoted to admin at startup
+	AdminEmails []string
+}
+
+// PasswordPolicy represents the password strength requirements
+type PasswordPolicy struct {
+	MinLength  int
+	MaxLength  int
+	MinDigits  int
+	MinSymbols int
+}
+
+// DefaultPasswordPolicy returns the password requirements applied to new passwords
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:  8,
+		MaxLength:  72, // bcrypt max length
+		MinDigits:  1,
+		MinSymbols: 1,
 	}
 }
 
 // NewConfigFromAuthConfig creates an auth.Config from config.AuthConfig
 func NewConfigFromAuthConfig(cfg *config.AuthConfig) *Config {
-	authConfig := &Config{}
-	authConfig.JWT.Secret = cfg.JWT.Secret
-	authConfig.JWT.AccessTokenTTL = cfg.JWT.AccessTokenTTL
-	authConfig.JWT.RefreshTokenTTL = cfg.JWT.RefreshTokenTTL
-
-	authConfig.Password.MinLength = 8  // Default password requirements
-	authConfig.Password.MaxLength = 72 // bcrypt max length
-	authConfig.Password.MinDigits = 1
-	authConfig.Password.MinSymbols = 1
-
-	return authConfig
+	return &Config{
+		JWT:               cfg.JWT,
+		Password:          DefaultPasswordPolicy(),
+		PasswordReset:     cfg.PasswordReset,
+		EmailVerification: cfg.EmailVerification,
+		Lockout:           cfg.Lockout,
+		ActivityRetention: cfg.ActivityRetention,
+		OAuth:             cfg.OAuth,
+		AdminEmails:       cfg.AdminEmails,
+	}
 }
 
 // App represents the application context needed by auth handlers
@@ -46,6 +64,12 @@ type App struct {
 	ResponseHandler ResponseHandler
 }
 
+// ClientInfo describes the device a session is started from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
 // LoginRequest represents the login request payload
 // @Description Login request payload
 type LoginRequest struct {
@@ -62,6 +86,54 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required" example:"eyJhbGciOiJIUzI1NiIs..."`
 }
 
+// ForgotPasswordRequest represents the forgot password request payload
+// @Description Forgot password request payload
+type ForgotPasswordRequest struct {
+	// Email address of the account to recover
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// ResendVerificationRequest represents the resend verification email request payload
+// @Description Resend verification email request payload
+type ResendVerificationRequest struct {
+	// Email address of the unverified account
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+// UpdateRoleRequest represents the role change request payload
+// @Description Role change request payload
+type UpdateRoleRequest struct {
+	// New role: user, moderator or admin
+	Role Role `json:"role" binding:"required,enum" example:"moderator"`
+}
+
+// SuspendUserRequest represents the account suspension request payload
+// @Description Account suspension request payload
+type SuspendUserRequest struct {
+	// Why the account is suspended, shown to admins and the user
+	Reason string `json:"reason" binding:"required" example:"Repeated copyright violations"`
+}
+
+// CreateAPIKeyRequest represents the API key creation request payload
+// @Description API key creation request payload
+type CreateAPIKeyRequest struct {
+	// Name that identifies the key, e.g. the service using it
+	Name string `json:"name" binding:"required,max=100" example:"upload-bot"`
+	// Scopes granted to the key: read, upload or admin
+	Scopes []APIScope `json:"scopes" binding:"required,min=1,dive,enum" swaggertype:"array,string" example:"read,upload"`
+	// Days until the key expires; 0 means it never expires
+	ExpiresInDays int `json:"expiresInDays" binding:"min=0,max=3650" example:"90"`
+}
+
+// ResetPasswordRequest represents the reset password request payload
+// @Description Reset password request payload
+type ResetPasswordRequest struct {
+	// Token from the password reset email
+	Token string `json:"token" binding:"required" example:"eyJhbGciOiJIUzI1NiIs..."`
+	// New password
+	Password string `json:"password" binding:"required,min=8" example:"NewPass123!"`
+}
+
 // LoginResponse represents the login response
 // @Description Login response payload
 type LoginResponse struct {
@@ -77,6 +149,113 @@ type LoginResponse struct {
 	ExpiresIn int `json:"expiresIn" example:"3600"`
 }
 
+// CreateAPIKeyResponse represents the API key creation response
+// @Description API key creation response payload
+type CreateAPIKeyResponse struct {
+	APIKey
+	// Plaintext key. It is only returned once and should be stored securely.
+	Key string `json:"key" example:"pvk_3q2-7wEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"`
+}
+
+// Session represents an active login on one device
+// @Description Active session
+type Session struct {
+	// Session ID
+	ID string `json:"id" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`
+	// IP address the session was started from
+	IPAddress string `json:"ipAddress" example:"203.0.113.7"`
+	// User agent of the client that started the session
+	UserAgent string `json:"userAgent" example:"Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0"`
+	// When the session was started
+	CreatedAt time.Time `json:"createdAt"`
+	// When the session last refreshed its access token
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	// When the session expires unless revoked first
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Whether this is the session making the request
+	Current bool `json:"current" example:"true"`
+}
+
+// RevokeSessionsResponse represents the result of revoking other sessions
+// @Description Revoke other sessions response payload
+type RevokeSessionsResponse struct {
+	// Number of sessions revoked
+	Revoked int64 `json:"revoked" example:"2"`
+}
+
+// ImportUser is one account in a bulk user import
+// @Description Bulk import user record
+type ImportUser struct {
+	// Unique username
+	Username string `json:"username" example:"johndoe"`
+	// Unique email address
+	Email string `json:"email" example:"user@example.com"`
+	// User's full name
+	Name string `json:"name" example:"John Doe"`
+	// Role to create the account with; defaults to user
+	Role Role `json:"role,omitempty" example:"user"`
+	// bcrypt hash carried over from another platform, so users keep their password
+	PasswordHash string `json:"passwordHash,omitempty" example:"$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"`
+	// Temporary password, used when no hash is given. Without either the user
+	// has to choose a password through the welcome email or a password reset.
+	Password string `json:"password,omitempty" example:"TempPass123!"`
+	// Whether the email address was already verified on the other platform
+	EmailVerified bool `json:"emailVerified" example:"true"`
+}
+
+// ImportOptions controls how a bulk user import treats existing accounts
+type ImportOptions struct {
+	// OnDuplicate decides what happens to records whose email or username is taken
+	OnDuplicate DuplicatePolicy
+	// SendWelcome emails every created user a welcome message with a link to set their password
+	SendWelcome bool
+}
+
+// ImportResult is the outcome of importing one record
+// @Description Bulk import result for one record
+type ImportResult struct {
+	// Record number, starting at 1 (for CSV, the header is not counted)
+	Row      int    `json:"row" example:"1"`
+	Username string `json:"username" example:"johndoe"`
+	Email    string `json:"email" example:"user@example.com"`
+	// created, updated, skipped or failed
+	Status ImportStatus `json:"status" example:"created"`
+	// ID of the created or updated user
+	UserID string `json:"userId,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Why the record was skipped or failed
+	Error string `json:"error,omitempty" example:"email is invalid"`
+	// Whether a welcome email was sent
+	WelcomeSent bool `json:"welcomeSent,omitempty" example:"true"`
+}
+
+// ImportReport summarizes a bulk user import
+// @Description Bulk import report
+type ImportReport struct {
+	Total   int `json:"total" example:"3"`
+	Created int `json:"created" example:"1"`
+	Updated int `json:"updated" example:"0"`
+	Skipped int `json:"skipped" example:"1"`
+	Failed  int `json:"failed" example:"1"`
+	// One result per record, in input order
+	Results []ImportResult `json:"results"`
+}
+
+// ExportUser is one account in a bulk user export. Its fields are a superset
+// of ImportUser, so an export can be imported into another instance.
+// @Description Bulk export user record
+type ExportUser struct {
+	ID            string    `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Username      string    `json:"username" example:"johndoe"`
+	Email         string    `json:"email" example:"user@example.com"`
+	Name          string    `json:"name" example:"John Doe"`
+	Role          Role      `json:"role" example:"user"`
+	EmailVerified bool      `json:"emailVerified" example:"true"`
+	Active        bool      `json:"active" example:"true"`
+	CreatedAt     time.Time `json:"createdAt"`
+	// Only included when explicitly requested
+	PasswordHash string `json:"passwordHash,omitempty"`
+}
+
 // TokenClaims represents the JWT claims
 // @Description JWT claims structure
 type TokenClaims struct {
@@ -84,5 +263,11 @@ type TokenClaims struct {
 	UserID string `json:"userId" example:"550e8400-e29b-41d4-a716-446655440000"`
 	// User email
 	Email string `json:"email" example:"user@example.com"`
+	// User role at the time the token was issued
+	Role Role `json:"role,omitempty" example:"user"`
+	// ID of the session (refresh token) the access token was issued for
+	SessionID string `json:"sid,omitempty" example:"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`
+	// Token type: access or refresh
+	TokenType string `json:"typ,omitempty" example:"access"`
 	jwt.RegisteredClaims
 }
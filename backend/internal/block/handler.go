@@ -0,0 +1,133 @@
+package block
+
+import (
+	"errors"
+	"net/http"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for block endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+	cache           *httpHandler.ResponseCache
+}
+
+// NewHandler creates a new block handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// SetResponseCache drops cached comment listings when a block changes, since
+// they hide the comments of blocked users
+func (h *Handler) SetResponseCache(cache *httpHandler.ResponseCache) {
+	h.cache = cache
+}
+
+// RegisterRoutes registers all block routes
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	protected := router.Group("/users")
+	protected.Use(authMiddleware, h.cache.Invalidate("comments"))
+	{
+		protected.POST("/:id/block", h.handleBlock)
+		protected.DELETE("/:id/block", h.handleUnblock)
+	}
+}
+
+// @Summary Block a user
+// @Description Block a user. Their comments are hidden from your comment listings, they cannot reply to your comments, and you are not notified of their follows, comments or reactions. Follows between you and the user are removed. Blocking an already blocked user has no effect.
+// @Tags blocks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=BlockStatus} "User blocked"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID or self-block"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/block [post]
+func (h *Handler) handleBlock(c *gin.Context) {
+	blockerID, blockedID, ok := h.parseBlockRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Block(c.Request.Context(), blockerID, blockedID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to block user")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, status, "User blocked successfully")
+}
+
+// @Summary Unblock a user
+// @Description Unblock a user. Unblocking a user that is not blocked has no effect. Removed follows are not restored.
+// @Tags blocks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=BlockStatus} "User unblocked"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/block [delete]
+func (h *Handler) handleUnblock(c *gin.Context) {
+	blockerID, blockedID, ok := h.parseBlockRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Unblock(c.Request.Context(), blockerID, blockedID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to unblock user")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, status, "User unblocked successfully")
+}
+
+// parseBlockRequest extracts the authenticated blocker and the target user from the request
+func (h *Handler) parseBlockRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	blockerID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	blockedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return blockerID, blockedID, true
+}
+
+// handleServiceError maps block service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrCannotBlockSelf):
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "You cannot block yourself", err)
+	case errors.Is(err, ErrUserNotFound):
+		h.responseHandler.NotFoundResponse(c, "User not found")
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
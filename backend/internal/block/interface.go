@@ -0,0 +1,28 @@
+package block
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCannotBlockSelf is returned when a user tries to block themselves
+	ErrCannotBlockSelf = errors.New("users cannot block themselves")
+	// ErrUserNotFound is returned when the user to block does not exist
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// Service defines the interface for block operations
+type Service interface {
+	// Block makes blockerID block blockedID and removes any follows between
+	// them; blocking twice is a no-op
+	Block(ctx context.Context, blockerID, blockedID uuid.UUID) (*BlockStatus, error)
+	// Unblock removes the block; unblocking twice is a no-op
+	Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) (*BlockStatus, error)
+	// IsBlocked reports whether blockerID blocked blockedID
+	IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error)
+	// BlockedIDs returns the IDs of every user blockerID blocked
+	BlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
+}
@@ -0,0 +1,26 @@
+package block
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Block records that one user blocked another. Unblocking deletes the row.
+type Block struct {
+	BlockerID uuid.UUID `gorm:"type:uuid;primaryKey" json:"blockerId"`
+	BlockedID uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"blockedId"`
+	CreatedAt time.Time `gorm:"not null" json:"createdAt"`
+}
+
+// TableName specifies the table name for blocks
+func (Block) TableName() string {
+	return "user_blocks"
+}
+
+// BlockStatus is returned after blocking or unblocking
+// @Description Block state between the caller and a user
+type BlockStatus struct {
+	// Whether the caller blocks the user
+	Blocked bool `json:"blocked" example:"true"`
+}
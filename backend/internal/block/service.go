@@ -0,0 +1,103 @@
+package block
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/follow"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db *gorm.DB
+}
+
+// NewService creates a new block service
+func NewService(db *gorm.DB) Service {
+	return &serviceImpl{db: db}
+}
+
+// Block makes blockerID block blockedID
+func (s *serviceImpl) Block(ctx context.Context, blockerID, blockedID uuid.UUID) (*BlockStatus, error) {
+	if blockerID == blockedID {
+		return nil, ErrCannotBlockSelf
+	}
+	if err := s.ensureUserExists(ctx, blockedID); err != nil {
+		return nil, err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		block := &Block{BlockerID: blockerID, BlockedID: blockedID}
+		if err := tx.Where(block).FirstOrCreate(block).Error; err != nil {
+			return fmt.Errorf("failed to create block: %w", err)
+		}
+
+		// Neither user keeps following the other, so follow notifications
+		// and upload fan-out stop in both directions
+		if err := tx.Where("(follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)",
+			blockerID, blockedID, blockedID, blockerID).
+			Delete(&follow.Follow{}).Error; err != nil {
+			return fmt.Errorf("failed to remove follows: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockStatus{Blocked: true}, nil
+}
+
+// Unblock removes the block if it exists
+func (s *serviceImpl) Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) (*BlockStatus, error) {
+	if blockerID == blockedID {
+		return nil, ErrCannotBlockSelf
+	}
+
+	if err := s.db.WithContext(ctx).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&Block{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete block: %w", err)
+	}
+
+	return &BlockStatus{Blocked: false}, nil
+}
+
+// IsBlocked reports whether blockerID blocked blockedID
+func (s *serviceImpl) IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	if blockerID == blockedID {
+		return false, nil
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&Block{}).
+		Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to look up block: %w", err)
+	}
+	return count > 0, nil
+}
+
+// BlockedIDs returns the IDs of every user blockerID blocked
+func (s *serviceImpl) BlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&Block{}).Where("blocker_id = ?", blockerID).Pluck("blocked_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list blocked user IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// ensureUserExists returns ErrUserNotFound if there is no active user with the given ID
+func (s *serviceImpl) ensureUserExists(ctx context.Context, userID uuid.UUID) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&auth.User{}).Where("id = ? AND active = ?", userID, true).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package block_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/block"
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/consensuslabs/pavilion-network/backend/internal/follow"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func createUser(t *testing.T, db *gorm.DB, name string) uuid.UUID {
+	id := uuid.New()
+	user := &auth.User{ID: id, Username: name + "-" + id.String()[:8], Email: id.String() + "@example.com", Name: name}
+	require.NoError(t, db.Create(user).Error)
+	return id
+}
+
+// commentRepository pages through a fixed set of top-level comments; other
+// methods are not used
+type commentRepository struct {
+	comment.Repository
+	comments []comment.Comment
+	created  []*comment.Comment
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uuid.UUID) (*comment.Comment, error) {
+	for i := range r.comments {
+		if r.comments[i].ID == id {
+			return &r.comments[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *commentRepository) GetByVideoID(ctx context.Context, options comment.CommentFilterOptions) (comment.PaginatedComments, error) {
+	start := min((options.Page-1)*options.Limit, len(r.comments))
+	end := min(start+options.Limit, len(r.comments))
+	return comment.PaginatedComments{Comments: append([]comment.Comment(nil), r.comments[start:end]...), CurrentPage: options.Page}, nil
+}
+
+func (r *commentRepository) Count(ctx context.Context, videoID uuid.UUID) (int, error) {
+	return len(r.comments), nil
+}
+
+func (r *commentRepository) Create(ctx context.Context, c *comment.Comment) error {
+	r.created = append(r.created, c)
+	return nil
+}
+
+// notificationRepository keeps saved notifications by ID; other methods are
+// not used
+type notificationRepository struct {
+	notification.NotificationRepository
+	saved map[uuid.UUID]*notification.Notification
+}
+
+func (r *notificationRepository) SaveNotification(ctx context.Context, n *notification.Notification) error {
+	r.saved[n.ID] = n
+	return nil
+}
+
+func (r *notificationRepository) GetNotification(ctx context.Context, id uuid.UUID) (*notification.Notification, error) {
+	if n, ok := r.saved[id]; ok {
+		return n, nil
+	}
+	return nil, notification.ErrNotificationNotFound
+}
+
+func (r *notificationRepository) recipients() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, n := range r.saved {
+		ids = append(ids, n.UserID)
+	}
+	return ids
+}
+
+func TestCannotBlockSelf(t *testing.T) {
+	service := block.NewService(nil)
+	userID := uuid.New()
+
+	_, err := service.Block(context.Background(), userID, userID)
+	assert.ErrorIs(t, err, block.ErrCannotBlockSelf)
+
+	_, err = service.Unblock(context.Background(), userID, userID)
+	assert.ErrorIs(t, err, block.ErrCannotBlockSelf)
+
+	blocked, err := service.IsBlocked(context.Background(), userID, userID)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+}
+
+func TestBlockAndUnblock(t *testing.T) {
+	ctx := context.Background()
+	db := testhelper.SetupTestDB(t)
+	service := block.NewService(db)
+	follows := follow.NewService(db, nil, testhelper.NewTestLogger(true))
+	blocker, blocked, other := createUser(t, db, "blocker"), createUser(t, db, "blocked"), createUser(t, db, "other")
+
+	_, err := follows.Follow(ctx, blocker, blocked)
+	require.NoError(t, err)
+	_, err = follows.Follow(ctx, blocked, blocker)
+	require.NoError(t, err)
+	_, err = follows.Follow(ctx, other, blocker)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		status, err := service.Block(ctx, blocker, blocked)
+		require.NoError(t, err)
+		assert.True(t, status.Blocked)
+	}
+
+	isBlocked, err := service.IsBlocked(ctx, blocker, blocked)
+	require.NoError(t, err)
+	assert.True(t, isBlocked)
+	isBlocked, err = service.IsBlocked(ctx, blocked, blocker)
+	require.NoError(t, err)
+	assert.False(t, isBlocked, "blocks are one way")
+
+	ids, err := service.BlockedIDs(ctx, blocker)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{blocked}, ids, "blocking twice keeps one block")
+
+	// Follows between the two are removed in both directions, others are kept
+	followers, err := follows.GetFollowerIDs(ctx, blocker)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{other}, followers)
+	followers, err = follows.GetFollowerIDs(ctx, blocked)
+	require.NoError(t, err)
+	assert.Empty(t, followers)
+
+	for i := 0; i < 2; i++ {
+		status, err := service.Unblock(ctx, blocker, blocked)
+		require.NoError(t, err)
+		assert.False(t, status.Blocked)
+	}
+
+	isBlocked, err = service.IsBlocked(ctx, blocker, blocked)
+	require.NoError(t, err)
+	assert.False(t, isBlocked)
+	ids, err = service.BlockedIDs(ctx, blocker)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+
+	// Removed follows are not restored
+	followers, err = follows.GetFollowerIDs(ctx, blocked)
+	require.NoError(t, err)
+	assert.Empty(t, followers)
+
+	_, err = service.Block(ctx, blocker, uuid.New())
+	assert.ErrorIs(t, err, block.ErrUserNotFound)
+
+	// Suspended users are not found either
+	require.NoError(t, db.Model(&auth.User{}).Where("id = ?", other).Update("active", false).Error)
+	_, err = service.Block(ctx, blocker, other)
+	assert.ErrorIs(t, err, block.ErrUserNotFound)
+}
+
+func TestBlockedUsersHiddenFromComments(t *testing.T) {
+	ctx := context.Background()
+	db := testhelper.SetupTestDB(t)
+	service := block.NewService(db)
+	viewer, blocked, other := createUser(t, db, "viewer"), createUser(t, db, "blocked"), createUser(t, db, "other")
+
+	videoID := uuid.New()
+	repo := &commentRepository{}
+	for _, author := range []uuid.UUID{other, blocked, other, blocked, viewer} {
+		repo.comments = append(repo.comments, comment.Comment{ID: uuid.New(), VideoID: videoID, UserID: author, Status: comment.StatusActive})
+	}
+	comments := comment.NewService(repo, nil, service, nil, comment.ContentPolicy{})
+
+	authors := func(page int) ([]uuid.UUID, comment.PaginatedComments) {
+		result, err := comments.GetCommentsByVideoID(ctx, comment.CommentFilterOptions{VideoID: videoID, Page: page, Limit: 2, ViewerID: viewer})
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, c := range result.Comments {
+			ids = append(ids, c.UserID)
+		}
+		return ids, result
+	}
+
+	_, err := service.Block(ctx, viewer, blocked)
+	require.NoError(t, err)
+
+	// Each page leaves out the blocked user's comments; the totals still count them
+	ids, page := authors(1)
+	assert.Equal(t, []uuid.UUID{other}, ids)
+	assert.Equal(t, 5, page.TotalCount)
+	assert.Equal(t, 3, page.TotalPages)
+	assert.True(t, page.HasNextPage)
+
+	ids, page = authors(2)
+	assert.Equal(t, []uuid.UUID{other}, ids)
+	assert.True(t, page.HasPrevPage)
+
+	ids, page = authors(3)
+	assert.Equal(t, []uuid.UUID{viewer}, ids)
+	assert.False(t, page.HasNextPage)
+
+	// The blocked user cannot reply to the blocker
+	parent := repo.comments[4]
+	err = comments.CreateComment(ctx, comment.NewComment(videoID, blocked, "hello", &parent.ID))
+	assert.ErrorIs(t, err, comment.ErrPermissionDenied)
+	assert.Empty(t, repo.created)
+
+	// Unblocking shows their comments again and lets them reply
+	_, err = service.Unblock(ctx, viewer, blocked)
+	require.NoError(t, err)
+
+	ids, _ = authors(1)
+	assert.Equal(t, []uuid.UUID{other, blocked}, ids)
+	require.NoError(t, comments.CreateComment(ctx, comment.NewComment(videoID, blocked, "hello", &parent.ID)))
+	assert.Len(t, repo.created, 1)
+}
+
+func TestBlockedUsersNotNotified(t *testing.T) {
+	ctx := context.Background()
+	db := testhelper.SetupTestDB(t)
+	service := block.NewService(db)
+	follows := follow.NewService(db, nil, testhelper.NewTestLogger(true))
+	creator, fan, blocker := createUser(t, db, "creator"), createUser(t, db, "fan"), createUser(t, db, "blocker")
+
+	config := notification.DefaultConfig()
+	config.Enabled = false
+	repo := &notificationRepository{saved: map[uuid.UUID]*notification.Notification{}}
+	notifications, err := notification.NewService(ctx, config, testhelper.NewTestLogger(true), repo)
+	require.NoError(t, err)
+	notifications.SetFollowerLister(follows)
+	notifications.SetBlockChecker(service)
+
+	upload := func(title string) *notification.VideoEvent {
+		return &notification.VideoEvent{
+			BaseEvent: notification.BaseEvent{ID: uuid.New(), Type: notification.VideoUploaded, CreatedAt: time.Now()},
+			VideoID:   uuid.New(),
+			UserID:    creator,
+			Title:     title,
+		}
+	}
+
+	for _, follower := range []uuid.UUID{fan, blocker} {
+		_, err := follows.Follow(ctx, follower, creator)
+		require.NoError(t, err)
+	}
+	_, err = service.Block(ctx, blocker, creator)
+	require.NoError(t, err)
+
+	stored, err := notifications.NotifyFollowers(ctx, upload("First"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored)
+	assert.Equal(t, []uuid.UUID{fan}, repo.recipients(), "users who blocked the creator are not notified")
+
+	// Once unblocked and following again, uploads reach them
+	_, err = service.Unblock(ctx, blocker, creator)
+	require.NoError(t, err)
+	_, err = follows.Follow(ctx, blocker, creator)
+	require.NoError(t, err)
+
+	repo.saved = map[uuid.UUID]*notification.Notification{}
+	stored, err = notifications.NotifyFollowers(ctx, upload("Second"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored)
+	assert.ElementsMatch(t, []uuid.UUID{fan, blocker}, repo.recipients())
+}
@@ -2,13 +2,22 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNotFound is returned by Get when the key does not exist
+var ErrNotFound = errors.New("cache key not found")
+
 // Service defines the interface for cache operations
 type Service interface {
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// Get returns ErrNotFound when the key does not exist
 	Get(ctx context.Context, key string) (string, error)
 	Delete(ctx context.Context, key string) error
+	// Incr increments the integer stored at key, starting from zero, and returns the new value
+	Incr(ctx context.Context, key string) (int64, error)
+	// TakeToken takes a token from a rate limit bucket of capacity tokens refilled over period
+	TakeToken(ctx context.Context, key string, capacity int, period time.Duration) (*TokenBucketResult, error)
 	Close() error
 }
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills a bucket for the time elapsed since it was last
+// used, takes a token if one is available and returns whether it did and how
+// many tokens are left. Running it as a script keeps concurrent requests from
+// different API instances from racing on the same bucket.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / interval)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity * interval))
+return {allowed, tostring(tokens)}
+`)
+
+// TakeToken takes a token from the bucket stored at key. The bucket holds up
+// to capacity tokens and refills evenly, capacity tokens per period.
+func (r *RedisService) TakeToken(ctx context.Context, key string, capacity int, period time.Duration) (*TokenBucketResult, error) {
+	if capacity < 1 || period <= 0 {
+		return nil, fmt.Errorf("invalid token bucket: capacity %d, period %s", capacity, period)
+	}
+	// Milliseconds it takes to refill one token
+	interval := float64(period.Milliseconds()) / float64(capacity)
+
+	reply, err := tokenBucketScript.Run(ctx, r.client, []string{key}, capacity, interval).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take token: %w", err)
+	}
+	if len(reply) != 2 {
+		return nil, fmt.Errorf("unexpected token bucket reply: %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensStr, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected token bucket reply: %v", reply)
+	}
+
+	return newTokenBucketResult(allowed == 1, tokens, capacity, interval), nil
+}
+
+// newTokenBucketResult describes a bucket holding tokens after a take
+func newTokenBucketResult(allowed bool, tokens float64, capacity int, interval float64) *TokenBucketResult {
+	result := &TokenBucketResult{
+		Allowed:    allowed,
+		Limit:      capacity,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(capacity) - tokens) * interval * float64(time.Millisecond)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * interval * float64(time.Millisecond))
+	}
+	return result
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Lookup results reported by ReadThrough
+const (
+	ResultHit   = "hit"
+	ResultMiss  = "miss"
+	ResultError = "error"
+)
+
+// ReadThrough caches values loaded from a slower store under a common key
+// prefix. Values are gob-encoded, so fields hidden from JSON are kept.
+// Redis errors are counted but never returned: the value is loaded from the
+// store instead. A nil *ReadThrough caches nothing.
+type ReadThrough struct {
+	service Service
+	name    string
+	ttl     time.Duration
+	metrics *Metrics
+}
+
+// NewReadThrough creates a cache named name whose entries expire after ttl
+func NewReadThrough(service Service, name string, ttl time.Duration, metrics *Metrics) *ReadThrough {
+	return &ReadThrough{
+		service: service,
+		name:    name,
+		ttl:     ttl,
+		metrics: metrics,
+	}
+}
+
+// Get decodes the value cached under key into dest. On a miss it calls load,
+// which fills dest from the store, and caches dest unless load failed or
+// returned ok false, for values that must not be cached yet.
+func (r *ReadThrough) Get(ctx context.Context, key string, dest interface{}, load func() (ok bool, err error)) error {
+	if r == nil {
+		_, err := load()
+		return err
+	}
+
+	cached, err := r.service.Get(ctx, r.key(key))
+	switch {
+	case err == nil:
+		if gob.NewDecoder(bytes.NewReader([]byte(cached))).Decode(dest) == nil {
+			r.metrics.record(r.name, ResultHit)
+			return nil
+		}
+		r.metrics.record(r.name, ResultError)
+	case errors.Is(err, ErrNotFound):
+		r.metrics.record(r.name, ResultMiss)
+	default:
+		r.metrics.record(r.name, ResultError)
+	}
+
+	ok, err := load()
+	if err != nil || !ok {
+		return err
+	}
+
+	r.Set(ctx, key, dest)
+	return nil
+}
+
+// Set caches value under key, for values loaded outside of Get, e.g. several
+// at once
+func (r *ReadThrough) Set(ctx context.Context, key string, value interface{}) {
+	if r == nil {
+		return
+	}
+
+	var encoded bytes.Buffer
+	if err := gob.NewEncoder(&encoded).Encode(value); err != nil {
+		return
+	}
+	// The value is loaded; a request that is cancelled now still caches it
+	if err := r.service.Set(context.WithoutCancel(ctx), r.key(key), encoded.Bytes(), r.ttl); err != nil {
+		r.metrics.record(r.name, ResultError)
+	}
+}
+
+// Invalidate removes the entries cached under keys, so the next Get loads
+// them again
+func (r *ReadThrough) Invalidate(ctx context.Context, keys ...string) {
+	if r == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := r.service.Delete(context.WithoutCancel(ctx), r.key(key)); err != nil {
+			r.metrics.record(r.name, ResultError)
+		}
+	}
+}
+
+func (r *ReadThrough) key(key string) string {
+	return "readcache:" + r.name + ":" + key
+}
+
+// Metrics exports read-through cache lookups to Prometheus. A nil *Metrics
+// is valid and records nothing.
+type Metrics struct {
+	lookups *prometheus.CounterVec
+}
+
+// NewMetrics creates cache metrics and registers them with registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Read-through cache lookups by cache and result (hit, miss or error).",
+		}, []string{"cache", "result"}),
+	}
+	registerer.MustRegister(m.lookups)
+	return m
+}
+
+func (m *Metrics) record(name, result string) {
+	if m == nil {
+		return
+	}
+	m.lookups.WithLabelValues(name, result).Inc()
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// memoryService keeps values in a map and ignores TTLs
+type memoryService struct {
+	values map[string]string
+	err    error
+}
+
+func (s *memoryService) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key] = string(value.([]byte))
+	return nil
+}
+
+func (s *memoryService) Get(ctx context.Context, key string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *memoryService) Delete(ctx context.Context, key string) error {
+	delete(s.values, key)
+	return s.err
+}
+
+func (s *memoryService) Incr(ctx context.Context, key string) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (s *memoryService) TakeToken(ctx context.Context, key string, capacity int, period time.Duration) (*TokenBucketResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *memoryService) Close() error { return nil }
+
+type cachedRecord struct {
+	Name string
+	// Hidden is kept even though JSON would drop it
+	Hidden string `json:"-"`
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+
+	newCache := func(service Service) (*ReadThrough, *Metrics) {
+		metrics := NewMetrics(prometheus.NewRegistry())
+		return NewReadThrough(service, "records", time.Minute, metrics), metrics
+	}
+	lookups := func(m *Metrics, result string) float64 {
+		return testutil.ToFloat64(m.lookups.WithLabelValues("records", result))
+	}
+
+	t.Run("Loads once and serves later reads from the cache", func(t *testing.T) {
+		c, metrics := newCache(&memoryService{values: map[string]string{}})
+		loads := 0
+		load := func(dest *cachedRecord) func() (bool, error) {
+			return func() (bool, error) {
+				loads++
+				*dest = cachedRecord{Name: "first", Hidden: "secret"}
+				return true, nil
+			}
+		}
+
+		for i := 0; i < 2; i++ {
+			var record cachedRecord
+			if err := c.Get(ctx, "a", &record, load(&record)); err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if record.Name != "first" || record.Hidden != "secret" {
+				t.Errorf("Unexpected record %+v", record)
+			}
+		}
+		if loads != 1 {
+			t.Errorf("Expected one load, got %d", loads)
+		}
+		if lookups(metrics, ResultMiss) != 1 || lookups(metrics, ResultHit) != 1 {
+			t.Errorf("Expected one miss and one hit, got %v and %v", lookups(metrics, ResultMiss), lookups(metrics, ResultHit))
+		}
+
+		c.Invalidate(ctx, "a")
+		var record cachedRecord
+		if err := c.Get(ctx, "a", &record, load(&record)); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if loads != 2 {
+			t.Errorf("Expected Invalidate to force a load, got %d loads", loads)
+		}
+	})
+
+	t.Run("Does not cache failed or uncacheable loads", func(t *testing.T) {
+		service := &memoryService{values: map[string]string{}}
+		c, _ := newCache(service)
+
+		var count int
+		loadErr := errors.New("store unavailable")
+		if err := c.Get(ctx, "a", &count, func() (bool, error) { return false, loadErr }); !errors.Is(err, loadErr) {
+			t.Errorf("Expected the load error, got %v", err)
+		}
+		if err := c.Get(ctx, "a", &count, func() (bool, error) { count = 3; return false, nil }); err != nil || count != 3 {
+			t.Errorf("Expected the loaded value, got %d, %v", count, err)
+		}
+		if len(service.values) != 0 {
+			t.Errorf("Expected nothing cached, got %v", service.values)
+		}
+	})
+
+	t.Run("Falls back to the store when Redis fails", func(t *testing.T) {
+		c, metrics := newCache(&memoryService{values: map[string]string{}, err: errors.New("connection refused")})
+
+		var count int
+		if err := c.Get(ctx, "a", &count, func() (bool, error) { count = 5; return true, nil }); err != nil || count != 5 {
+			t.Errorf("Expected the loaded value, got %d, %v", count, err)
+		}
+		if lookups(metrics, ResultError) == 0 {
+			t.Error("Expected the Redis error to be counted")
+		}
+	})
+
+	t.Run("Serves values cached with Set", func(t *testing.T) {
+		c, _ := newCache(&memoryService{values: map[string]string{}})
+		c.Set(ctx, "a", 4)
+
+		var count int
+		if err := c.Get(ctx, "a", &count, func() (bool, error) { t.Error("Unexpected load"); return false, nil }); err != nil || count != 4 {
+			t.Errorf("Expected the value set, got %d, %v", count, err)
+		}
+	})
+
+	t.Run("A nil cache always loads", func(t *testing.T) {
+		var c *ReadThrough
+		var count int
+		if err := c.Get(ctx, "a", &count, func() (bool, error) { count = 7; return true, nil }); err != nil || count != 7 {
+			t.Errorf("Expected the loaded value, got %d, %v", count, err)
+		}
+		c.Set(ctx, "a", 7)
+		c.Invalidate(ctx, "a")
+	})
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -37,7 +38,16 @@ func (r *RedisService) Set(ctx context.Context, key string, value interface{}, t
 
 // Get retrieves a value from Redis by key
 func (r *RedisService) Get(ctx context.Context, key string) (string, error) {
-	return r.client.Get(ctx, key).Result()
+	value, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return value, err
+}
+
+// SetNX stores a key-value pair unless the key exists, reporting whether it was stored
+func (r *RedisService) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, ttl).Result()
 }
 
 // Delete removes a key from Redis
@@ -45,6 +55,45 @@ func (r *RedisService) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// Incr increments the integer stored at key
+func (r *RedisService) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// adjustCounterScript adds ARGV[1] to the counter at KEYS[1] without going
+// below zero. A missing counter is left missing, since adding to it would
+// start it from zero instead of the true count.
+var adjustCounterScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local value = redis.call('INCRBY', KEYS[1], ARGV[1])
+if value < 0 then
+	redis.call('SET', KEYS[1], 0, 'KEEPTTL')
+	value = 0
+end
+return value
+`)
+
+// AdjustCounter adds delta to the counter stored at key, stopping at zero,
+// and returns the new value. It reports false, and stores nothing, when the
+// key does not exist.
+func (r *RedisService) AdjustCounter(ctx context.Context, key string, delta int64) (int64, bool, error) {
+	value, err := adjustCounterScript.Run(ctx, r.client, []string{key}, delta).Int64()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to adjust counter: %w", err)
+	}
+	if value < 0 {
+		return 0, false, nil
+	}
+	return value, true, nil
+}
+
+// Ping checks that Redis is reachable
+func (r *RedisService) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Close closes the Redis connection
 func (r *RedisService) Close() error {
 	return r.client.Close()
@@ -1,8 +1,24 @@
 package cache
 
+import "time"
+
 // Config represents Redis configuration settings
 type Config struct {
 	Addr     string `mapstructure:"addr"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
 }
+
+// TokenBucketResult is the state of a rate limit bucket after taking a token
+type TokenBucketResult struct {
+	// Allowed is false when the bucket was empty
+	Allowed bool
+	// Limit is the bucket capacity
+	Limit int
+	// Remaining is the number of whole tokens left
+	Remaining int
+	// RetryAfter is how long until a token is available; zero when Allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
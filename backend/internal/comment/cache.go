@@ -0,0 +1,105 @@
+package comment
+
+import (
+	"context"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/google/uuid"
+)
+
+// CountCache keeps the number of comments on each video and of replies to
+// each comment in Redis, so paging through comments does not run a COUNT
+// against ScyllaDB every time. Creating or deleting a comment invalidates
+// the counts it is part of. A nil *CountCache caches nothing.
+type CountCache struct {
+	cache *cache.ReadThrough
+}
+
+// NewCountCache creates a count cache whose entries expire after ttl
+func NewCountCache(service cache.Service, ttl time.Duration, metrics *cache.Metrics) *CountCache {
+	return &CountCache{cache: cache.NewReadThrough(service, "comment_count", ttl, metrics)}
+}
+
+// get returns the cached count under key, calling load on a miss
+func (c *CountCache) get(ctx context.Context, key string, load func() (int, error)) (int, error) {
+	if c == nil {
+		return load()
+	}
+
+	var count int
+	err := c.cache.Get(ctx, key, &count, func() (bool, error) {
+		var err error
+		count, err = load()
+		return err == nil, err
+	})
+	return count, err
+}
+
+// getMany returns the counts of ids, calling load once with the ids whose
+// counts are not cached. Counts load leaves out are zero.
+func (c *CountCache) getMany(ctx context.Context, ids []uuid.UUID, key func(uuid.UUID) string, load func([]uuid.UUID) (map[uuid.UUID]int, error)) (map[uuid.UUID]int, error) {
+	if c == nil {
+		return load(ids)
+	}
+
+	counts := make(map[uuid.UUID]int, len(ids))
+	var missing []uuid.UUID
+	for _, id := range ids {
+		var count int
+		cached := true
+		// Misses are loaded below, all at once
+		if err := c.cache.Get(ctx, key(id), &count, func() (bool, error) {
+			cached = false
+			return false, nil
+		}); err != nil {
+			return nil, err
+		}
+		if cached {
+			counts[id] = count
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return counts, nil
+	}
+
+	loaded, err := load(missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range missing {
+		counts[id] = loaded[id]
+		c.cache.Set(ctx, key(id), loaded[id])
+	}
+	return counts, nil
+}
+
+// invalidate drops the counts comment is part of
+func (c *CountCache) invalidate(ctx context.Context, comment *Comment) {
+	if c == nil {
+		return
+	}
+	keys := []string{videoCountKey(comment.VideoID)}
+	if comment.ParentID != nil {
+		keys = append(keys, replyCountKey(*comment.ParentID))
+	}
+	c.cache.Invalidate(ctx, keys...)
+}
+
+// invalidateVideo drops the comment count of a video
+func (c *CountCache) invalidateVideo(ctx context.Context, videoID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	c.cache.Invalidate(ctx, videoCountKey(videoID))
+}
+
+func videoCountKey(videoID uuid.UUID) string {
+	return "video:" + videoID.String()
+}
+
+func replyCountKey(parentID uuid.UUID) string {
+	return "replies:" + parentID.String()
+}
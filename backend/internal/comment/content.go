@@ -0,0 +1,163 @@
+package comment
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// MaxLinks is the most links detected in a comment
+const MaxLinks = 10
+
+// Link is a URL found in a comment's content, stored with the comment so
+// clients can show a preview of it. Previews are not fetched by the server.
+// @Description A link found in a comment
+type Link struct {
+	URL  string `json:"url" example:"https://example.com/article"`
+	Host string `json:"host" example:"example.com"`
+}
+
+// ContentPolicy is how comment content is checked and presented
+type ContentPolicy struct {
+	// MaxLength is the most characters a comment may have once sanitized; 0
+	// for no limit
+	MaxLength int
+	// Markdown renders content to ContentHTML in responses
+	Markdown bool
+	// BannedWords are kept out of comments on every video, on top of the
+	// words each channel bans
+	BannedWords []string
+	// FilterAction is what happens to comments with banned words; reject
+	// when empty
+	FilterAction FilterAction
+}
+
+// prepare sanitizes content for storage, checks its length and detects the
+// links in it
+func (p ContentPolicy) prepare(content string) (string, []Link, error) {
+	content = SanitizeContent(content)
+	if content == "" {
+		return "", nil, fmt.Errorf("%w: content is required", ErrInvalidComment)
+	}
+	if p.MaxLength > 0 && utf8.RuneCountInString(content) > p.MaxLength {
+		return "", nil, fmt.Errorf("%w: content must be at most %d characters", ErrInvalidComment, p.MaxLength)
+	}
+	return content, DetectLinks(content), nil
+}
+
+// present fills in the rendered content of c and its inlined replies
+func (p ContentPolicy) present(c *Comment) {
+	if c == nil || !p.Markdown {
+		return
+	}
+	c.ContentHTML = RenderMarkdown(c.Content)
+	p.presentAll(c.Replies)
+}
+
+// presentAll fills in the rendered content of each of comments
+func (p ContentPolicy) presentAll(comments []Comment) {
+	for i := range comments {
+		p.present(&comments[i])
+	}
+}
+
+// droppedElements are left out of sanitized content along with everything
+// inside them
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "noembed": true, "noframes": true, "template": true,
+	"textarea": true, "title": true, "xmp": true, "plaintext": true,
+	"svg": true, "math": true,
+}
+
+// SanitizeContent strips HTML from comment content: tags, comments and
+// doctypes are removed, and so are the contents of elements such as script
+// and style. The remaining text is kept as written, entities included, so
+// a "<" that does not start a tag survives. Content is stripped until
+// nothing changes, so removing one tag cannot complete another.
+func SanitizeContent(content string) string {
+	for {
+		stripped := stripTags(content)
+		if stripped == content {
+			return strings.TrimSpace(content)
+		}
+		content = stripped
+	}
+}
+
+// stripTags makes one pass of SanitizeContent over content
+func stripTags(content string) string {
+	var out bytes.Buffer
+	z := html.NewTokenizer(strings.NewReader(content))
+	skip, depth := "", 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return out.String()
+		case html.TextToken:
+			if skip == "" {
+				out.Write(z.Raw())
+			}
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			switch {
+			case skip == "" && droppedElements[string(name)]:
+				skip, depth = string(name), 1
+			case string(name) == skip:
+				depth++
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if string(name) == skip {
+				if depth--; depth == 0 {
+					skip = ""
+				}
+			}
+		}
+	}
+}
+
+// linkPattern matches http and https URLs written out in text
+var linkPattern = regexp.MustCompile("(?i)\\bhttps?://[^\\s<>\"'`]+")
+
+// DetectLinks returns the distinct http and https URLs in content, in the
+// order they appear, up to MaxLinks
+func DetectLinks(content string) []Link {
+	var links []Link
+	seen := make(map[string]bool)
+	for _, match := range linkPattern.FindAllString(content, -1) {
+		raw := trimLink(match)
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" || seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		links = append(links, Link{URL: raw, Host: strings.ToLower(u.Hostname())})
+		if len(links) == MaxLinks {
+			break
+		}
+	}
+	return links
+}
+
+// trimLink drops the punctuation that ends the sentence around a URL, and
+// closing parentheses the URL did not open
+func trimLink(link string) string {
+	for link != "" {
+		last := link[len(link)-1]
+		switch {
+		case strings.IndexByte(".,;:!?*_~", last) >= 0:
+			link = link[:len(link)-1]
+		case last == ')' && strings.Count(link, ")") > strings.Count(link, "("):
+			link = link[:len(link)-1]
+		default:
+			return link
+		}
+	}
+	return link
+}
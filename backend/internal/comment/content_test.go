@@ -0,0 +1,98 @@
+package comment
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeContent(t *testing.T) {
+	tests := []struct {
+		name, content, expected string
+	}{
+		{"plain text", "  Great video!  ", "Great video!"},
+		{"markup", `<b>bold</b> and <a href="javascript:alert(1)">a link</a>`, "bold and a link"},
+		{"script", "before<script>alert('hi')</script>after", "beforeafter"},
+		{"unclosed script", "hello <script>alert(1)", "hello"},
+		{"event handler", `<img src=x onerror="alert(1)">nice`, "nice"},
+		{"comment", "a<!-- hidden -->b", "ab"},
+		{"raw text element", "<xmp><img src=x onerror=alert(1)></xmp>ok", "ok"},
+		{"tag split by another", "<<b>script>alert(1)<</b>/script>", ""},
+		{"text that looks like markup", "2 < 3 && 5 > 4 <3", "2 < 3 && 5 > 4 <3"},
+		{"entities are kept", "&lt;b&gt; is bold", "&lt;b&gt; is bold"},
+		{"markdown is kept", "**bold** and `code`", "**bold** and `code`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SanitizeContent(tt.content))
+		})
+	}
+}
+
+func TestDetectLinks(t *testing.T) {
+	links := DetectLinks("See https://Example.com/a?b=1, (https://en.wikipedia.org/wiki/Go_(language)) " +
+		"and https://example.com/a?b=1. Not ftp://example.com or example.org")
+	assert.Equal(t, []Link{
+		{URL: "https://Example.com/a?b=1", Host: "example.com"},
+		{URL: "https://en.wikipedia.org/wiki/Go_(language)", Host: "en.wikipedia.org"},
+		{URL: "https://example.com/a?b=1", Host: "example.com"},
+	}, links)
+
+	assert.Nil(t, DetectLinks("no links here"))
+	assert.Len(t, DetectLinks(strings.Repeat("http://example.com/x ", 3)), 1, "repeated links are listed once")
+
+	var many strings.Builder
+	for i := 0; i < MaxLinks+5; i++ {
+		many.WriteString("https://example.com/" + strings.Repeat("a", i+1) + " ")
+	}
+	assert.Len(t, DetectLinks(many.String()), MaxLinks)
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name, content, expected string
+	}{
+		{"paragraphs", "one\ntwo\n\nthree", "<p>one<br>\ntwo</p>\n<p>three</p>"},
+		{"emphasis", "**bold**, *italic*, _also_ and ~~gone~~", "<p><strong>bold</strong>, <em>italic</em>, <em>also</em> and <del>gone</del></p>"},
+		{"not emphasis", "2 * 3 * 4 and snake_case_name", "<p>2 * 3 * 4 and snake_case_name</p>"},
+		{"escaped html", `<img src=x onerror="alert(1)"> & co`, "<p>&lt;img src=x onerror=&#34;alert(1)&#34;&gt; &amp; co</p>"},
+		{"inline code", "run `rm -rf <dir>` **now**", "<p>run <code>rm -rf &lt;dir&gt;</code> <strong>now</strong></p>"},
+		{"code block", "```\n<b>\n  x\n```\nafter", "<pre><code>&lt;b&gt;\n  x</code></pre>\n<p>after</p>"},
+		{"quote", "> quoted *text*\n> more\n\nreply", "<blockquote>\n<p>quoted <em>text</em><br>\nmore</p>\n</blockquote>\n<p>reply</p>"},
+		{"lists", "- one\n- two\n1. first\n2. second", "<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n<ol>\n<li>first</li>\n<li>second</li>\n</ol>"},
+		{"link", "[the **docs**](https://example.com/docs?a=1&b=2)", `<p><a href="https://example.com/docs?a=1&amp;b=2" rel="nofollow ugc noopener">the <strong>docs</strong></a></p>`},
+		{"unsafe link", "[click](javascript:alert(1))", "<p>[click](javascript:alert(1))</p>"},
+		{"autolink", "see https://example.com/a.", `<p>see <a href="https://example.com/a" rel="nofollow ugc noopener">https://example.com/a</a>.</p>`},
+		{"backslash escape", `\*not italic\*`, "<p>*not italic*</p>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RenderMarkdown(tt.content))
+		})
+	}
+}
+
+func TestCreateCommentContentPolicy(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepository{}
+	service := NewService(repo, nil, nil, nil, ContentPolicy{MaxLength: 40, Markdown: true})
+
+	c := NewComment(uuid.New(), uuid.New(), "<script>x</script>**Nice** https://example.com", nil)
+	require.NoError(t, service.CreateComment(ctx, c))
+	require.Len(t, repo.created, 1)
+	assert.Equal(t, "**Nice** https://example.com", repo.created[0].Content)
+	assert.Equal(t, []Link{{URL: "https://example.com", Host: "example.com"}}, repo.created[0].Links)
+	assert.Equal(t, `<p><strong>Nice</strong> <a href="https://example.com" rel="nofollow ugc noopener">https://example.com</a></p>`, c.ContentHTML)
+
+	err := service.CreateComment(ctx, NewComment(uuid.New(), uuid.New(), "<b></b>", nil))
+	assert.ErrorIs(t, err, ErrInvalidComment, "content that is only markup is empty")
+
+	// The limit counts characters rather than bytes
+	require.NoError(t, service.CreateComment(ctx, NewComment(uuid.New(), uuid.New(), strings.Repeat("é", 40), nil)))
+	err = service.CreateComment(ctx, NewComment(uuid.New(), uuid.New(), strings.Repeat("é", 41), nil))
+	assert.ErrorIs(t, err, ErrInvalidComment)
+}
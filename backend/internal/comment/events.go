@@ -0,0 +1,115 @@
+package comment
+
+import (
+	"context"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+// EventPublisher publishes comment events to the comment events topic
+type EventPublisher interface {
+	PublishCommentEvent(ctx context.Context, event *notification.CommentEvent) error
+}
+
+// eventService publishes an event for every comment created or deleted
+// through the service it wraps
+type eventService struct {
+	Service
+	videos    VideoLookup
+	publisher EventPublisher
+	logger    video.Logger
+}
+
+// WithEvents wraps service so that new comments are published as
+// COMMENT_CREATED, for the video's owner, or COMMENT_REPLIED, for the parent's
+// author, and deleted or rejected comments as COMMENT_DELETED. Consumers keep
+// video comment counts from these events. Publishing failures are logged and
+// do not fail the call.
+func WithEvents(service Service, videos VideoLookup, publisher EventPublisher, logger video.Logger) Service {
+	return &eventService{Service: service, videos: videos, publisher: publisher, logger: logger}
+}
+
+func (s *eventService) CreateComment(ctx context.Context, comment *Comment) error {
+	if err := s.Service.CreateComment(ctx, comment); err != nil {
+		return err
+	}
+
+	event := &notification.CommentEvent{
+		BaseEvent: notification.BaseEvent{Type: notification.CommentCreated},
+		CommentID: comment.ID,
+		VideoID:   comment.VideoID,
+		Content:   comment.Content,
+		ActorID:   comment.UserID,
+	}
+	if comment.ParentID != nil {
+		event.Type = notification.CommentReplied
+		event.ParentID = *comment.ParentID
+		if parent, err := s.Service.GetCommentByID(ctx, *comment.ParentID); err == nil && parent != nil {
+			event.UserID = parent.UserID
+		}
+	} else if s.videos != nil {
+		if v, err := s.videos.GetVideo(ctx, comment.VideoID); err == nil {
+			event.UserID = v.UserID
+		}
+	}
+	s.publish(ctx, event)
+	return nil
+}
+
+func (s *eventService) ReviewComment(ctx context.Context, id, reviewerID uuid.UUID, approve bool) (*Comment, error) {
+	before, err := s.Service.GetCommentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pending := before != nil && before.Status == StatusPending
+	comment, err := s.Service.ReviewComment(ctx, id, reviewerID, approve)
+	if err != nil {
+		return nil, err
+	}
+	if !approve && pending {
+		s.publishDeleted(ctx, comment)
+	}
+	return comment, nil
+}
+
+func (s *eventService) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	comment, err := s.Service.GetCommentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Deleting a deleted comment again changes nothing
+	live := comment != nil && comment.DeletedAt == nil
+	if err := s.Service.DeleteComment(ctx, id); err != nil {
+		return err
+	}
+	if live {
+		s.publishDeleted(ctx, comment)
+	}
+	return nil
+}
+
+func (s *eventService) publishDeleted(ctx context.Context, comment *Comment) {
+	event := &notification.CommentEvent{
+		BaseEvent: notification.BaseEvent{Type: notification.CommentDeleted},
+		CommentID: comment.ID,
+		UserID:    comment.UserID,
+		VideoID:   comment.VideoID,
+		ActorID:   comment.UserID,
+	}
+	if comment.ParentID != nil {
+		event.ParentID = *comment.ParentID
+	}
+	s.publish(ctx, event)
+}
+
+func (s *eventService) publish(ctx context.Context, event *notification.CommentEvent) {
+	if err := s.publisher.PublishCommentEvent(ctx, event); err != nil {
+		s.logger.LogError("Failed to publish comment event", map[string]interface{}{
+			"error":     err.Error(),
+			"eventType": string(event.Type),
+			"commentID": event.CommentID.String(),
+		})
+	}
+}
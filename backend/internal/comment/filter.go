@@ -0,0 +1,247 @@
+package comment
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+)
+
+// FilterAction is what happens to a comment containing banned words
+type FilterAction string
+
+const (
+	// FilterReject rejects the comment
+	FilterReject FilterAction = "reject"
+	// FilterMask replaces the banned words with asterisks
+	FilterMask FilterAction = "mask"
+	// FilterHold holds the comment for the video owner's review, as the
+	// review_required comments policy does
+	FilterHold FilterAction = "hold"
+)
+
+// IsValid reports whether a is a known filter action
+func (a FilterAction) IsValid() bool {
+	return a == FilterReject || a == FilterMask || a == FilterHold
+}
+
+const (
+	// MaxBannedWords is the most words a channel may ban
+	MaxBannedWords = 500
+	// MaxBannedWordLength is the most characters a banned word or phrase may have
+	MaxBannedWordLength = 100
+)
+
+var (
+	// ErrCommentRejected is returned for comments with banned words when the
+	// filter action is reject
+	ErrCommentRejected = apierror.Validation("content", "comment contains words that are not allowed")
+	// ErrInvalidBannedWords is returned for lists with too many words, or
+	// with words that are too long
+	ErrInvalidBannedWords = apierror.Validation("words", "invalid banned words")
+)
+
+// Filtered records what the word filter did to a comment. It is not stored.
+type Filtered struct {
+	Action FilterAction
+	// Words are the banned words found, lowercased
+	Words []string
+}
+
+// FilterError is returned when a comment is rejected for banned words
+type FilterError struct {
+	Filtered
+}
+
+func (e *FilterError) Error() string { return ErrCommentRejected.Error() }
+func (e *FilterError) Unwrap() error { return ErrCommentRejected }
+
+// BannedWordsRequest replaces the words a channel bans from comments on its videos
+type BannedWordsRequest struct {
+	// Words are words or phrases, matched whole and ignoring case. An empty
+	// list bans nothing beyond the site-wide list.
+	Words []string `json:"words" example:"spoiler"`
+}
+
+// BannedWordsResponse lists the words a channel bans from comments on its videos
+type BannedWordsResponse struct {
+	Words []string `json:"words" example:"spoiler"`
+	// Action is what happens to comments containing them
+	Action FilterAction `json:"action" example:"hold" enums:"reject,mask,hold"`
+}
+
+// NormalizeBannedWords lowercases words, collapses the spaces in phrases and
+// drops blanks and duplicates, returning the words sorted. Channels may ban
+// at most MaxBannedWords words of at most MaxBannedWordLength characters.
+func NormalizeBannedWords(words []string) ([]string, error) {
+	normalized := normalizeWords(words)
+	if len(normalized) > MaxBannedWords {
+		return nil, fmt.Errorf("%w: at most %d words may be banned", ErrInvalidBannedWords, MaxBannedWords)
+	}
+	for _, word := range normalized {
+		if utf8.RuneCountInString(word) > MaxBannedWordLength {
+			return nil, fmt.Errorf("%w: words must be at most %d characters", ErrInvalidBannedWords, MaxBannedWordLength)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeWords normalizes words as NormalizeBannedWords does, without limits
+func normalizeWords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.Join(strings.Fields(word), " "))
+		if word == "" || seen[word] {
+			continue
+		}
+		seen[word] = true
+		normalized = append(normalized, word)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// bannedPattern matches one of words at the start of a string, as a whole
+// word, ignoring case and with any spaces between the words of a phrase.
+// Longer words are tried first, so phrases win over the words in them.
+func bannedPattern(words []string) *regexp.Regexp {
+	sorted := append([]string(nil), words...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	quoted := make([]string, len(sorted))
+	for i, word := range sorted {
+		quoted[i] = strings.ReplaceAll(regexp.QuoteMeta(word), " ", `\s+`)
+	}
+	return regexp.MustCompile(`(?i)^(` + strings.Join(quoted, "|") + `)(?:[^\p{L}\p{N}_]|$)`)
+}
+
+// findBanned returns the byte ranges of the banned words in content
+func findBanned(content string, words []string) [][2]int {
+	if len(words) == 0 {
+		return nil
+	}
+	pattern := bannedPattern(words)
+	var matches [][2]int
+	prev := ' '
+	for i := 0; i < len(content); {
+		if !isWordRune(prev) {
+			if m := pattern.FindStringSubmatchIndex(content[i:]); m != nil {
+				matches = append(matches, [2]int{i + m[2], i + m[3]})
+				prev, _ = utf8.DecodeLastRuneInString(content[:i+m[3]])
+				i += m[3]
+				continue
+			}
+		}
+		r, size := utf8.DecodeRuneInString(content[i:])
+		prev = r
+		i += size
+	}
+	return matches
+}
+
+// maskBanned replaces everything but the spaces in matches with asterisks
+func maskBanned(content string, matches [][2]int) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(content[last:m[0]])
+		for _, r := range content[m[0]:m[1]] {
+			if unicode.IsSpace(r) {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('*')
+			}
+		}
+		last = m[1]
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// filterWords looks for the site-wide banned words, and those of the video's
+// owner, in c. Depending on the filter action the words are masked, c is held
+// for review, or a *FilterError is returned; c.Filtered records what was done.
+func (s *serviceImpl) filterWords(ctx context.Context, c *Comment, ownerID uuid.UUID) error {
+	words := s.content.BannedWords
+	if ownerID != uuid.Nil {
+		channel, err := s.repo.GetBannedWords(ctx, ownerID)
+		if err != nil {
+			return fmt.Errorf("error loading banned words: %w", err)
+		}
+		words = append(append([]string(nil), words...), channel...)
+	}
+	matches := findBanned(c.Content, words)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	found := make([]string, 0, len(matches))
+	seen := make(map[string]bool, len(matches))
+	for _, m := range matches {
+		word := strings.ToLower(strings.Join(strings.Fields(c.Content[m[0]:m[1]]), " "))
+		if !seen[word] {
+			seen[word] = true
+			found = append(found, word)
+		}
+	}
+	filtered := Filtered{Action: s.content.filterAction(), Words: found}
+
+	switch filtered.Action {
+	case FilterMask:
+		c.Content = maskBanned(c.Content, matches)
+		c.Links = DetectLinks(c.Content)
+	case FilterHold:
+		// Flagged comments stay with the moderators
+		if c.Status == StatusActive {
+			c.Status = StatusPending
+		}
+	default:
+		return &FilterError{Filtered: filtered}
+	}
+	c.Filtered = &filtered
+	return nil
+}
+
+// GetBannedWords returns the words a channel bans from comments on its videos
+func (s *serviceImpl) GetBannedWords(ctx context.Context, ownerID uuid.UUID) (*BannedWordsResponse, error) {
+	words, err := s.repo.GetBannedWords(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if words == nil {
+		words = []string{}
+	}
+	return &BannedWordsResponse{Words: words, Action: s.content.filterAction()}, nil
+}
+
+// SetBannedWords replaces the words a channel bans from comments on its
+// videos. Comments already posted are not filtered again.
+func (s *serviceImpl) SetBannedWords(ctx context.Context, ownerID uuid.UUID, words []string) (*BannedWordsResponse, error) {
+	words, err := NormalizeBannedWords(words)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetBannedWords(ctx, ownerID, words); err != nil {
+		return nil, err
+	}
+	return &BannedWordsResponse{Words: words, Action: s.content.filterAction()}, nil
+}
+
+// filterAction is the action taken on comments with banned words, reject
+// unless set
+func (p ContentPolicy) filterAction() FilterAction {
+	if p.FilterAction == "" {
+		return FilterReject
+	}
+	return p.FilterAction
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r)
+}
@@ -0,0 +1,131 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *fakeRepository) GetBannedWords(ctx context.Context, ownerID uuid.UUID) ([]string, error) {
+	return r.bannedWords[ownerID], nil
+}
+
+func (r *fakeRepository) SetBannedWords(ctx context.Context, ownerID uuid.UUID, words []string) error {
+	if r.bannedWords == nil {
+		r.bannedWords = make(map[uuid.UUID][]string)
+	}
+	r.bannedWords[ownerID] = words
+	return nil
+}
+
+func (r *fakeRepository) Update(ctx context.Context, id uuid.UUID, content string, links []Link) error {
+	for i := range r.comments {
+		if r.comments[i].ID == id {
+			r.comments[i].Content, r.comments[i].Links = content, links
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status Status) error {
+	for i := range r.comments {
+		if r.comments[i].ID == id {
+			r.comments[i].Status = status
+		}
+	}
+	return nil
+}
+
+func TestNormalizeBannedWords(t *testing.T) {
+	words, err := NormalizeBannedWords([]string{"  Spoiler ", "BAD   phrase", "", "spoiler", "zebra"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bad phrase", "spoiler", "zebra"}, words)
+
+	_, err = NormalizeBannedWords([]string{strings.Repeat("a", MaxBannedWordLength+1)})
+	assert.ErrorIs(t, err, ErrInvalidBannedWords)
+
+	many := make([]string, MaxBannedWords+1)
+	for i := range many {
+		many[i] = strings.Repeat("a", i+1)
+	}
+	_, err = NormalizeBannedWords(many)
+	assert.ErrorIs(t, err, ErrInvalidBannedWords)
+}
+
+func TestFindBanned(t *testing.T) {
+	words := []string{"darn", "bad phrase", "c++"}
+	tests := []struct {
+		name, content, masked string
+	}{
+		{"whole words only", "Darn, darnation and undarn", "****, darnation and undarn"},
+		{"phrases across spaces", "a Bad\n phrase here", "a ***\n ****** here"},
+		{"punctuation in words", "I like c++.", "I like ***."},
+		{"unicode boundaries", "éDarn darné darn!", "éDarn darné ****!"},
+		{"nothing banned", "all good", "all good"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.masked, maskBanned(tt.content, findBanned(tt.content, words)))
+		})
+	}
+}
+
+func TestBannedWordsFilter(t *testing.T) {
+	ctx := context.Background()
+	owner, viewer := uuid.New(), uuid.New()
+	v := &video.Video{ID: uuid.New(), UserID: owner}
+
+	newService := func(action FilterAction) (Service, *fakeRepository) {
+		repo := &fakeRepository{}
+		service := NewService(repo, nil, nil, fakeVideos{v.ID: v}, ContentPolicy{BannedWords: []string{"Darn"}, FilterAction: action})
+		_, err := service.SetBannedWords(ctx, owner, []string{"Spoiler"})
+		require.NoError(t, err)
+		return service, repo
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		service, repo := newService(FilterReject)
+		err := service.CreateComment(ctx, NewComment(v.ID, viewer, "darn, the SPOILER", nil))
+		var filtered *FilterError
+		require.True(t, errors.As(err, &filtered))
+		assert.ErrorIs(t, err, ErrCommentRejected)
+		assert.Equal(t, Filtered{Action: FilterReject, Words: []string{"darn", "spoiler"}}, filtered.Filtered)
+		assert.Empty(t, repo.created)
+
+		other := NewComment(uuid.New(), viewer, "a spoiler elsewhere", nil)
+		service = NewService(repo, nil, nil, fakeVideos{other.VideoID: {ID: other.VideoID, UserID: uuid.New()}}, ContentPolicy{})
+		require.NoError(t, service.CreateComment(ctx, other), "channel lists only apply to the channel's videos")
+		assert.Nil(t, other.Filtered)
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		service, repo := newService(FilterMask)
+		c := NewComment(v.ID, viewer, "no spoilers, but a spoiler: https://example.com/spoiler", nil)
+		require.NoError(t, service.CreateComment(ctx, c))
+		assert.Equal(t, "no spoilers, but a *******: https://example.com/*******", repo.created[0].Content)
+		assert.Equal(t, StatusActive, c.Status)
+		assert.Equal(t, &Filtered{Action: FilterMask, Words: []string{"spoiler"}}, c.Filtered)
+	})
+
+	t.Run("hold", func(t *testing.T) {
+		service, repo := newService(FilterHold)
+		c := NewComment(v.ID, viewer, "Darn", nil)
+		require.NoError(t, service.CreateComment(ctx, c))
+		assert.Equal(t, StatusPending, c.Status)
+		assert.Equal(t, "Darn", repo.created[0].Content)
+
+		published := Comment{ID: uuid.New(), VideoID: v.ID, UserID: viewer, Content: "fine", Status: StatusActive}
+		repo.comments = []Comment{published}
+		updated, err := service.UpdateComment(ctx, published.ID, "now a spoiler")
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, updated.Status)
+		assert.Equal(t, StatusPending, repo.comments[0].Status, "edits with banned words are held too")
+		assert.Equal(t, &Filtered{Action: FilterHold, Words: []string{"spoiler"}}, updated.Filtered)
+	})
+}
@@ -1,17 +1,19 @@
 package comment
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/audit"
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
 	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
-	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -21,40 +23,81 @@ type Handler struct {
 	service  Service
 	response httpHandler.ResponseHandler
 	logger   video.Logger
-	logger   video.Logger
+	evidence video.EvidenceRecorder
+	webhooks WebhookPublisher
+	audit    audit.Recorder
+	cache    *httpHandler.ResponseCache
+	// idempotency replays comments retried with the same Idempotency-Key
+	idempotency *httpHandler.Idempotency
+}
+
+// WebhookPublisher queues events for the webhooks of a video's owner
+type WebhookPublisher interface {
+	PublishForVideo(ctx context.Context, videoID uuid.UUID, event string, data interface{}) error
 }
 
+// WebhookCommentCreated is the webhook event sent to a video's owner for new comments
+const WebhookCommentCreated = "comment.created"
+
 // NewHandler creates a new comment handler
-func NewHandler(service Service, response httpHandler.ResponseHandler, logger video.Logger) *Handler {
 func NewHandler(service Service, response httpHandler.ResponseHandler, logger video.Logger) *Handler {
 	return &Handler{
 		service:  service,
 		response: response,
 		logger:   logger,
-		logger:   logger,
 	}
 }
 
+// SetEvidenceRecorder snapshots reported comments when they are edited or deleted
+func (h *Handler) SetEvidenceRecorder(evidence video.EvidenceRecorder) {
+	h.evidence = evidence
+}
+
+// SetWebhookPublisher sends new comments to the video owner's webhooks
+func (h *Handler) SetWebhookPublisher(webhooks WebhookPublisher) {
+	h.webhooks = webhooks
+}
+
+// SetAuditLog records created and deleted comments in the audit log
+func (h *Handler) SetAuditLog(recorder audit.Recorder) {
+	h.audit = recorder
+}
+
+// SetResponseCache caches comment listings, dropping them when comments change
+func (h *Handler) SetResponseCache(cache *httpHandler.ResponseCache) {
+	h.cache = cache
+}
+
+// SetIdempotency replays the response to comments retried with the same Idempotency-Key
+func (h *Handler) SetIdempotency(idempotency *httpHandler.Idempotency) {
+	h.idempotency = idempotency
+}
+
 // RegisterRoutes registers the comment API routes
-func (h *Handler) RegisterRoutes(router *gin.Engine, authService *auth.Service) {
-	// Unprotected routes
-	router.GET("/video/:id/comments", h.GetCommentsByVideoID)
-	router.GET("/comment/:id/replies", h.GetRepliesByCommentID)
+func (h *Handler) RegisterRoutes(router gin.IRouter, authService *auth.Service) {
+	// Unprotected routes; signed in users don't see the comments of users they blocked
+	optionalAuth := auth.OptionalAuthMiddleware(authService)
+	router.GET("/video/:id/comments", optionalAuth, h.cache.Cache("comments"), h.GetCommentsByVideoID)
+	router.GET("/comment/:id/replies", optionalAuth, h.cache.Cache("comments"), h.GetRepliesByCommentID)
 
 	// Protected routes
 	protected := router.Group("")
-	protected.Use(auth.AuthMiddleware(authService, h.response))
+	protected.Use(auth.AuthMiddleware(authService, h.response), h.cache.Invalidate("comments"))
 	{
-		protected.POST("/video/:id/comment", h.CreateComment)
+		protected.POST("/video/:id/comment", h.idempotency.Keys(), h.CreateComment)
 		protected.PUT("/comment/:id", h.UpdateComment)
 		protected.DELETE("/comment/:id", h.DeleteComment)
+		protected.POST("/comment/:id/approve", h.ApproveComment)
+		protected.POST("/comment/:id/reject", h.RejectComment)
 		protected.POST("/comment/:id/reaction", h.AddReaction)
 		protected.DELETE("/comment/:id/reaction", h.RemoveReaction)
+		protected.GET("/me/comments/blocklist", h.GetBannedWords)
+		protected.PUT("/me/comments/blocklist", h.SetBannedWords)
 	}
 }
 
 // @Summary Get comments for a video
-// @Description Retrieves a paginated list of comments for a specific video
+// @Description Retrieves a paginated list of comments for a specific video, with the video's comments_policy. Comments held for review are only listed for their author and the video owner. With a bearer token, comments and replies of users the caller blocked are left out.
 // @Tags comment
 // @Accept json
 // @Produce json
@@ -62,15 +105,19 @@ func (h *Handler) RegisterRoutes(router *gin.Engine, authService *auth.Service)
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Number of comments per page (default: 20, max: 100)"
 // @Param sort query string false "Sort order (options: newest, oldest, most_liked; default: newest)"
+// @Param replies query int false "Number of newest replies to inline in each comment (default: 0, max: 10)"
+// @Param If-None-Match header string false "ETag of a copy the client already has"
 // @Success 200 {object} http.Response{data=PaginatedComments} "Comments retrieved successfully"
+// @Success 304 "The client's copy is current"
 // @Failure 400 {object} http.Response{error=http.Error} "Invalid video ID format"
+// @Failure 404 {object} http.Response{error=http.Error} "Video not found"
 // @Failure 500 {object} http.Response{error=http.Error} "Internal server error"
 // @Router /video/{id}/comments [get]
 func (h *Handler) GetCommentsByVideoID(c *gin.Context) {
 	videoIDStr := c.Param("id")
 	videoID, err := uuid.Parse(videoIDStr)
 	if err != nil {
-		h.response.ErrorResponse(c, http.StatusBadRequest, "invalid_video_id", "Invalid video ID format", err)
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
 		return
 	}
 
@@ -83,26 +130,69 @@ func (h *Handler) GetCommentsByVideoID(c *gin.Context) {
 		Limit:     limit,
 		SortBy:    sortBy,
 		SortOrder: sortOrder,
+		ViewerID:  viewerID(c),
+	}
+	if repliesStr := c.Query("replies"); repliesStr != "" {
+		if val, err := strconv.Atoi(repliesStr); err == nil && val > 0 {
+			options.Replies = val
+		}
 	}
 
 	comments, err := h.service.GetCommentsByVideoID(c.Request.Context(), options)
 	if err != nil {
-		h.response.InternalErrorResponse(c, "Failed to retrieve comments", err)
+		apierror.Abort(c, err, "Failed to retrieve comments")
+		return
+	}
+	if httpHandler.NotModified(c, commentsETag(comments), listingCacheControl(options)) {
 		return
 	}
 
 	h.response.SuccessResponse(c, comments, "Comments retrieved successfully")
 }
 
+// viewerID returns the signed in user listing comments, or uuid.Nil
+func viewerID(c *gin.Context) uuid.UUID {
+	id, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
+
+// listingCacheControl keeps listings filtered for a signed in user out of
+// shared caches
+func listingCacheControl(options CommentFilterOptions) string {
+	if options.ViewerID != uuid.Nil {
+		return httpHandler.CacheControlPrivate
+	}
+	return httpHandler.CacheControlPublic
+}
+
+// commentsETag identifies a page of comments for conditional requests.
+// Reactions and replies do not update updated_at, so the counts and any
+// inlined replies are part of it, as is the video's comments policy.
+func commentsETag(page PaginatedComments) string {
+	parts := []interface{}{page.TotalCount, page.CurrentPage, page.CommentsPolicy}
+	for _, c := range page.Comments {
+		parts = append(parts, c.ID, c.UpdatedAt, c.Likes, c.Dislikes, c.Status, c.ReplyCount)
+		for _, r := range c.Replies {
+			parts = append(parts, r.ID, r.UpdatedAt, r.Likes, r.Dislikes, r.Status)
+		}
+	}
+	return httpHandler.ETag(parts...)
+}
+
 // @Summary Get replies to a comment
-// @Description Retrieves a paginated list of replies for a specific comment
+// @Description Retrieves a paginated list of replies for a specific comment. Replies held for review are only listed for their author and the video owner. With a bearer token, replies of users the caller blocked are left out.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param id path string true "Comment ID (UUID)"
 // @Param page query int false "Page number (default: 1)"
 // @Param limit query int false "Number of replies per page (default: 20, max: 100)"
+// @Param If-None-Match header string false "ETag of a copy the client already has"
 // @Success 200 {object} http.Response{data=PaginatedComments} "Replies retrieved successfully"
+// @Success 304 "The client's copy is current"
 // @Failure 400 {object} http.Response{error=http.Error} "Invalid comment ID format"
 // @Failure 500 {object} http.Response{error=http.Error} "Internal server error"
 // @Router /comment/{id}/replies [get]
@@ -110,7 +200,7 @@ func (h *Handler) GetRepliesByCommentID(c *gin.Context) {
 	commentIDStr := c.Param("id")
 	commentID, err := uuid.Parse(commentIDStr)
 	if err != nil {
-		h.response.ErrorResponse(c, http.StatusBadRequest, "invalid_comment_id", "Invalid comment ID format", err)
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid comment ID format", err)
 		return
 	}
 
@@ -123,11 +213,15 @@ func (h *Handler) GetRepliesByCommentID(c *gin.Context) {
 		Limit:     limit,
 		SortBy:    "created_at",
 		SortOrder: "desc",
+		ViewerID:  viewerID(c),
 	}
 
 	replies, err := h.service.GetRepliesByCommentID(c.Request.Context(), options)
 	if err != nil {
-		h.response.InternalErrorResponse(c, "Failed to retrieve replies", err)
+		apierror.Abort(c, err, "Failed to retrieve replies")
+		return
+	}
+	if httpHandler.NotModified(c, commentsETag(replies), listingCacheControl(options)) {
 		return
 	}
 
@@ -135,120 +229,112 @@ func (h *Handler) GetRepliesByCommentID(c *gin.Context) {
 }
 
 // @Summary Create a new comment
-// @Description Creates a new comment for a video
+// @Description Creates a new comment for a video. HTML tags are stripped from the content, which may be at most comments.maxLength characters; the links in it are returned as links, and the content rendered from Markdown as content_html.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param id path string true "Video ID"
 // @Param Authorization header string true "Bearer token"
+// @Param Idempotency-Key header string false "Key making retries of this comment return the original response instead of posting it again"
 // @Param comment body CreateCommentRequest true "Comment data"
 // @Success 200 {object} httpHandler.APIResponse{data=Comment} "Comment created successfully"
 // @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid video ID format or invalid comment"
 // @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not authenticated"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Comments are disabled on the video, or replying to a user who blocked the caller"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "A comment with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Idempotency-Key reused for a different request"
 // @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Failed to create comment"
 // @Router /video/{id}/comment [post]
 func (h *Handler) CreateComment(c *gin.Context) {
-	fmt.Printf("DEBUG HANDLER: Starting CreateComment handler\n")
-
 	// Try-catch equivalent to prevent panics from bringing down the server
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("DEBUG HANDLER: PANIC RECOVERED in CreateComment: %v\n", r)
+			h.logger.LogError("Panic recovered in CreateComment", map[string]interface{}{
+				"error": fmt.Sprintf("%v", r),
+			})
 			h.response.InternalErrorResponse(c, "Internal server error", fmt.Errorf("panic: %v", r))
 		}
 	}()
 
-	videoIDStr := c.Param("id")
-	fmt.Printf("DEBUG HANDLER: Video ID string: %s\n", videoIDStr)
-
-	videoID, err := uuid.Parse(videoIDStr)
+	videoID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		fmt.Printf("DEBUG HANDLER: Failed to parse video ID: %v\n", err)
-		h.response.ErrorResponse(c, http.StatusBadRequest, "invalid_video_id", "Invalid video ID format", err)
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
 		return
 	}
-	fmt.Printf("DEBUG HANDLER: Parsed video ID: %s\n", videoID.String())
 
 	// Get user ID from context (set by auth middleware)
 	userIDRaw, exists := c.Get("userID")
-	fmt.Printf("DEBUG HANDLER: User ID exists: %v, userIDRaw: %v, type: %T\n", exists, userIDRaw, userIDRaw)
-
 	if !exists {
-		fmt.Printf("DEBUG HANDLER: User not authenticated\n")
 		h.response.UnauthorizedResponse(c, "User not authenticated")
 		return
 	}
 
 	// Check the type and try to convert userID
-	fmt.Printf("DEBUG HANDLER: Attempting to convert userID from type %T\n", userIDRaw)
 	var userID uuid.UUID
-
 	switch v := userIDRaw.(type) {
 	case string:
-		fmt.Printf("DEBUG HANDLER: userID is string: %s\n", v)
 		parsedID, parseErr := uuid.Parse(v)
 		if parseErr != nil {
-			fmt.Printf("DEBUG HANDLER: Failed to parse userID string: %v\n", parseErr)
 			h.response.InternalErrorResponse(c, "Invalid user ID format", parseErr)
 			return
 		}
 		userID = parsedID
 	case uuid.UUID:
-		fmt.Printf("DEBUG HANDLER: userID is already UUID\n")
 		userID = v
 	default:
-		fmt.Printf("DEBUG HANDLER: userID is unknown type: %T\n", v)
 		h.response.InternalErrorResponse(c, "Invalid user ID type", fmt.Errorf("unexpected user ID type: %T", v))
 		return
 	}
 
-	fmt.Printf("DEBUG HANDLER: Final userID: %s\n", userID.String())
-
 	// Parse request body
 	var req CreateCommentRequest
-	fmt.Printf("DEBUG HANDLER: About to bind JSON request\n")
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		// Include full error details in the response
-		fmt.Printf("DEBUG HANDLER: JSON binding error: %v\n", err)
-		h.response.ErrorResponse(c, http.StatusBadRequest, "binding_error",
-			fmt.Sprintf("JSON binding error: %v", err), err)
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
-	fmt.Printf("DEBUG HANDLER: JSON bound successfully, content: %s, parentID: %v\n",
-		req.Content, req.ParentID)
-
 	// Create comment
 	comment := NewComment(videoID, userID, req.Content, req.ParentID)
-	fmt.Printf("DEBUG HANDLER: Comment created, about to save: ID=%s\n", comment.ID.String())
-
-	fmt.Printf("DEBUG HANDLER: Attempting to create comment - VideoID: %s, UserID: %s, Content length: %d\n",
-		videoID.String(), userID.String(), len(req.Content))
+	h.logger.LogDebug("Creating comment", map[string]interface{}{
+		"comment_id":     comment.ID,
+		"video_id":       videoID,
+		"user_id":        userID,
+		"parent_id":      req.ParentID,
+		"content_length": len(req.Content),
+	})
 
 	if err := h.service.CreateComment(c.Request.Context(), comment); err != nil {
-		// Include full error details in the response
-		fmt.Printf("DEBUG HANDLER: Service.CreateComment failed: %v\n", err)
-		h.response.ErrorResponse(c, http.StatusInternalServerError, "create_comment_error",
-			fmt.Sprintf("Failed to create comment: %v", err), err)
+		var filtered *FilterError
+		if errors.As(err, &filtered) {
+			h.recordFiltered(c, userID, comment.ID, videoID, &filtered.Filtered)
+		}
+		apierror.Abort(c, err, "Failed to create comment")
 		return
 	}
 
-	fmt.Printf("DEBUG HANDLER: Comment created successfully\n")
+	if h.webhooks != nil {
+		if err := h.webhooks.PublishForVideo(c.Request.Context(), videoID, WebhookCommentCreated, comment); err != nil {
+			h.logger.LogError("Failed to publish webhook event", map[string]interface{}{
+				"event":    WebhookCommentCreated,
+				"video_id": videoID,
+				"error":    err.Error(),
+			})
+		}
+	}
+	h.recordAudit(c, audit.CommentCreated, userID, comment.ID, map[string]string{"video_id": videoID.String()})
+	h.recordFiltered(c, userID, comment.ID, videoID, comment.Filtered)
 	h.response.SuccessResponse(c, comment, "Comment created successfully")
 }
 
 // @Summary Update a comment
-// @Description Updates the content of an existing comment
+// @Description Updates the content of an existing comment, sanitized and checked like a new comment. Banned words are filtered as in new comments, so an edit may be rejected, masked or hold the comment for review.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param id path string true "Comment ID (UUID)"
 // @Security BearerAuth
 // @Param comment body UpdateCommentRequest true "Updated comment data"
-// @Security BearerAuth
-// @Param comment body UpdateCommentRequest true "Updated comment data"
-// @Success 200 {object} http.Response{message=string} "Comment updated successfully"
+// @Success 200 {object} http.Response{data=Comment} "Comment updated successfully"
 // @Failure 400 {object} http.Response{error=http.Error} "Invalid comment ID format or invalid comment data"
 // @Failure 401 {object} http.Response{error=http.Error} "Unauthorized - user not authenticated"
 // @Failure 404 {object} http.Response{error=http.Error} "Comment not found"
@@ -258,44 +344,46 @@ func (h *Handler) UpdateComment(c *gin.Context) {
 	commentIDStr := c.Param("id")
 	commentID, err := uuid.Parse(commentIDStr)
 	if err != nil {
-		h.response.ErrorResponse(c, http.StatusBadRequest, "invalid_comment_id", "Invalid comment ID format", err)
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid comment ID format", err)
 		return
 	}
 
 	// Parse request body
 	var req UpdateCommentRequest
-	var req UpdateCommentRequest
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.response.ValidationErrorResponse(c, "content", "Invalid request body")
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
 	// Update comment
-	if err := h.service.UpdateComment(c.Request.Context(), commentID, req.Content); err != nil {
-		if errors.Is(err, ErrCommentNotFound) {
-			h.response.NotFoundResponse(c, "Comment not found")
-			return
+	actorID, _ := uuid.Parse(c.GetString("userID"))
+	comment, err := h.service.UpdateComment(c.Request.Context(), commentID, req.Content)
+	if err != nil {
+		var filtered *FilterError
+		if errors.As(err, &filtered) {
+			h.recordFiltered(c, actorID, commentID, uuid.Nil, &filtered.Filtered)
 		}
-		h.response.InternalErrorResponse(c, "Failed to update comment", err)
+		apierror.Abort(c, err, "Failed to update comment")
 		return
 	}
 
-	h.response.SuccessResponse(c, nil, "Comment updated successfully")
+	h.recordEvidence(c, commentID, "edit")
+	h.recordFiltered(c, actorID, commentID, comment.VideoID, comment.Filtered)
+
+	h.response.SuccessResponse(c, comment, "Comment updated successfully")
 }
 
 // @Summary Delete a comment
-// @Description Deletes an existing comment
-// @Description Deletes an existing comment
+// @Description Deletes an existing comment. Authors can delete their own comments; moderators and admins can delete any comment.
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param id path string true "Comment ID (UUID)"
 // @Security BearerAuth
-// @Security BearerAuth
 // @Success 200 {object} http.Response{message=string} "Comment deleted successfully"
 // @Failure 400 {object} http.Response{error=http.Error} "Invalid comment ID format"
 // @Failure 401 {object} http.Response{error=http.Error} "Unauthorized - user not authenticated"
+// @Failure 403 {object} http.Response{error=http.Error} "Not the comment author or a moderator"
 // @Failure 404 {object} http.Response{error=http.Error} "Comment not found"
 // @Failure 500 {object} http.Response{error=http.Error} "Internal server error"
 // @Router /comment/{id} [delete]
@@ -303,34 +391,105 @@ func (h *Handler) DeleteComment(c *gin.Context) {
 	commentIDStr := c.Param("id")
 	commentID, err := uuid.Parse(commentIDStr)
 	if err != nil {
-		h.response.ErrorResponse(c, http.StatusBadRequest, "invalid_comment_id", "Invalid comment ID format", err)
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid comment ID format", err)
 		return
 	}
 
+	// Only the author or a moderator may delete a comment
+	comment, err := h.service.GetCommentByID(c.Request.Context(), commentID)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to delete comment")
+		return
+	}
+	if comment == nil {
+		h.response.NotFoundResponse(c, "Comment not found")
+		return
+	}
+	if comment.UserID.String() != c.GetString("userID") && !auth.RoleFromContext(c).AtLeast(auth.RoleModerator) {
+		h.response.ForbiddenResponse(c, "You do not have permission to delete this comment")
+		return
+	}
+
+	// Capture reported comments while they can still be read
+	h.recordEvidence(c, commentID, "delete")
+
 	// Delete comment
 	if err := h.service.DeleteComment(c.Request.Context(), commentID); err != nil {
-		if errors.Is(err, ErrCommentNotFound) {
-			h.response.NotFoundResponse(c, "Comment not found")
-			return
-		}
-		h.response.InternalErrorResponse(c, "Failed to delete comment", err)
+		apierror.Abort(c, err, "Failed to delete comment")
 		return
 	}
 
+	actorID, _ := uuid.Parse(c.GetString("userID"))
+	h.recordAudit(c, audit.CommentDeleted, actorID, commentID, map[string]string{
+		"video_id": comment.VideoID.String(),
+		"author":   comment.UserID.String(),
+	})
 	h.response.SuccessResponse(c, nil, "Comment deleted successfully")
 }
 
+// @Summary Approve a held comment
+// @Description Publishes a comment held for review on a video whose comments policy is review_required. Only the video owner can review comments; comments that are not held are returned unchanged.
+// @Tags comment
+// @Produce json
+// @Param id path string true "Comment ID (UUID)"
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=Comment} "Comment approved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid comment ID format"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized - user not authenticated"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Not the video owner"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Comment not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /comment/{id}/approve [post]
+func (h *Handler) ApproveComment(c *gin.Context) {
+	h.reviewComment(c, true, "Comment approved")
+}
+
+// @Summary Reject a held comment
+// @Description Deletes a comment held for review on a video whose comments policy is review_required. Only the video owner can review comments; comments that are not held are returned unchanged.
+// @Tags comment
+// @Produce json
+// @Param id path string true "Comment ID (UUID)"
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=Comment} "Comment rejected"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid comment ID format"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized - user not authenticated"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Not the video owner"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Comment not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /comment/{id}/reject [post]
+func (h *Handler) RejectComment(c *gin.Context) {
+	h.reviewComment(c, false, "Comment rejected")
+}
+
+// reviewComment approves or rejects the held comment named in the path
+func (h *Handler) reviewComment(c *gin.Context, approve bool, message string) {
+	commentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid comment ID format", err)
+		return
+	}
+	reviewerID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.response.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	comment, err := h.service.ReviewComment(c.Request.Context(), commentID, reviewerID, approve)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to review comment")
+		return
+	}
+	h.response.SuccessResponse(c, comment, message)
+}
+
 // @Summary Add a reaction to a comment
 // @Description Adds a reaction (like/dislike) to a comment
-// @Description Adds a reaction (like/dislike) to a comment
 // @Tags comment
 // @Accept json
 // @Produce json
 // @Param id path string true "Comment ID (UUID)"
 // @Security BearerAuth
 // @Param reaction body ReactionRequest true "Reaction data"
-// @Security BearerAuth
-// @Param reaction body ReactionRequest true "Reaction data"
 // @Success 200 {object} http.Response{message=string} "Reaction added successfully"
 // @Failure 400 {object} http.Response{error=http.Error} "Invalid comment ID format or invalid reaction type"
 // @Failure 401 {object} http.Response{error=http.Error} "Unauthorized - user not authenticated"
@@ -341,13 +500,12 @@ func (h *Handler) AddReaction(c *gin.Context) {
 	commentIDStr := c.Param("id")
 	commentID, err := uuid.Parse(commentIDStr)
 	if err != nil {
-		h.response.ErrorResponse(c, http.StatusBadRequest, "invalid_comment_id", "Invalid comment ID format", err)
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid comment ID format", err)
 		return
 	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
-	userID, exists := c.Get("userID")
 	if !exists {
 		h.response.UnauthorizedResponse(c, "User not authenticated")
 		return
@@ -355,23 +513,8 @@ func (h *Handler) AddReaction(c *gin.Context) {
 
 	// Parse request body
 	var req ReactionRequest
-	var req ReactionRequest
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.response.ValidationErrorResponse(c, "type", "Invalid request body")
-		return
-	}
-
-	// Validate reaction type
-	var reactionType Type
-	if req.Type == string(TypeLike) {
-	if req.Type == string(TypeLike) {
-		reactionType = TypeLike
-	} else if req.Type == string(TypeDislike) {
-	} else if req.Type == string(TypeDislike) {
-		reactionType = TypeDislike
-	} else {
-		h.response.ValidationErrorResponse(c, "type", "Invalid reaction type")
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
@@ -379,17 +522,13 @@ func (h *Handler) AddReaction(c *gin.Context) {
 	reaction := &Reaction{
 		CommentID: commentID,
 		UserID:    userID.(uuid.UUID),
-		Type:      reactionType,
+		Type:      req.Type,
 		CreatedAt: getNowUTC(),
 		UpdatedAt: getNowUTC(),
 	}
 
 	if err := h.service.AddReaction(c.Request.Context(), reaction); err != nil {
-		if errors.Is(err, ErrCommentNotFound) {
-			h.response.NotFoundResponse(c, "Comment not found")
-			return
-		}
-		h.response.InternalErrorResponse(c, "Failed to add reaction", err)
+		apierror.Abort(c, err, "Failed to add reaction")
 		return
 	}
 
@@ -403,25 +542,22 @@ func (h *Handler) AddReaction(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Comment ID (UUID)"
 // @Security BearerAuth
-// @Security BearerAuth
 // @Success 200 {object} http.Response{message=string} "Reaction removed successfully"
 // @Failure 400 {object} http.Response{error=http.Error} "Invalid comment ID format"
 // @Failure 401 {object} http.Response{error=http.Error} "Unauthorized - user not authenticated"
 // @Failure 404 {object} http.Response{error=http.Error} "Comment or reaction not found"
-// @Failure 404 {object} http.Response{error=http.Error} "Comment or reaction not found"
 // @Failure 500 {object} http.Response{error=http.Error} "Internal server error"
 // @Router /comment/{id}/reaction [delete]
 func (h *Handler) RemoveReaction(c *gin.Context) {
 	commentIDStr := c.Param("id")
 	commentID, err := uuid.Parse(commentIDStr)
 	if err != nil {
-		h.response.ErrorResponse(c, http.StatusBadRequest, "invalid_comment_id", "Invalid comment ID format", err)
+		h.response.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid comment ID format", err)
 		return
 	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
-	userID, exists := c.Get("userID")
 	if !exists {
 		h.response.UnauthorizedResponse(c, "User not authenticated")
 		return
@@ -429,11 +565,7 @@ func (h *Handler) RemoveReaction(c *gin.Context) {
 
 	// Remove reaction
 	if err := h.service.RemoveReaction(c.Request.Context(), commentID, userID.(uuid.UUID)); err != nil {
-		if errors.Is(err, ErrCommentNotFound) {
-			h.response.NotFoundResponse(c, "Comment not found")
-			return
-		}
-		h.response.InternalErrorResponse(c, "Failed to remove reaction", err)
+		apierror.Abort(c, err, "Failed to remove reaction")
 		return
 	}
 
@@ -486,3 +618,107 @@ func getPaginationParams(c *gin.Context) (page, limit int, sortBy, sortOrder str
 func getNowUTC() time.Time {
 	return time.Now().UTC()
 }
+
+// @Summary List banned comment words
+// @Description Lists the words and phrases the authenticated user bans from comments on their videos, on top of the site-wide list, and the action taken on comments containing them: reject, mask or hold for review.
+// @Tags comment
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=BannedWordsResponse} "Banned words"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not authenticated"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/comments/blocklist [get]
+func (h *Handler) GetBannedWords(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.response.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	words, err := h.service.GetBannedWords(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to list banned words")
+		return
+	}
+	h.response.SuccessResponse(c, words, "Banned words retrieved successfully")
+}
+
+// @Summary Set banned comment words
+// @Description Replaces the words and phrases the authenticated user bans from comments on their videos. Words are matched whole and ignoring case; the list is lowercased, deduplicated and sorted. New comments and edits are filtered; comments already posted are not. An empty list leaves only the site-wide list.
+// @Tags comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BannedWordsRequest true "Banned words"
+// @Success 200 {object} httpHandler.APIResponse{data=BannedWordsResponse} "Banned words, normalized"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Too many words, or a word that is too long"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not authenticated"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/comments/blocklist [put]
+func (h *Handler) SetBannedWords(c *gin.Context) {
+	userID, err := uuid.Parse(c.GetString("userID"))
+	if err != nil {
+		h.response.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	var req BannedWordsRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	words, err := h.service.SetBannedWords(c.Request.Context(), userID, req.Words)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to set banned words")
+		return
+	}
+	h.logger.LogInfo("Banned comment words set", map[string]interface{}{
+		"user_id": userID,
+		"words":   len(words.Words),
+	})
+	h.response.SuccessResponse(c, words, "Banned words updated successfully")
+}
+
+// recordEvidence snapshots a comment for open moderation reports without blocking the change
+func (h *Handler) recordEvidence(c *gin.Context, commentID uuid.UUID, trigger string) {
+	if h.evidence == nil {
+		return
+	}
+	if err := h.evidence.RecordChange(c.Request.Context(), "comment", commentID, trigger); err != nil {
+		h.logger.LogError("Failed to record moderation evidence", map[string]interface{}{
+			"comment_id": commentID,
+			"trigger":    trigger,
+			"error":      err.Error(),
+		})
+	}
+}
+
+// recordFiltered audits a comment the banned words filter acted on, if it did
+func (h *Handler) recordFiltered(c *gin.Context, actorID, commentID, videoID uuid.UUID, filtered *Filtered) {
+	if filtered == nil {
+		return
+	}
+	details := map[string]string{
+		"action": string(filtered.Action),
+		"words":  strings.Join(filtered.Words, ", "),
+	}
+	if videoID != uuid.Nil {
+		details["video_id"] = videoID.String()
+	}
+	h.recordAudit(c, audit.CommentFiltered, actorID, commentID, details)
+}
+
+// recordAudit appends a comment event to the audit log
+func (h *Handler) recordAudit(c *gin.Context, eventType audit.EventType, actorID, commentID uuid.UUID, details map[string]string) {
+	if h.audit == nil {
+		return
+	}
+	h.audit.Record(c.Request.Context(), audit.Event{
+		Type:      eventType,
+		ActorID:   actorID,
+		TargetID:  commentID.String(),
+		Details:   details,
+		IPAddress: c.ClientIP(),
+	})
+}
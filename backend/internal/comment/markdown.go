@@ -0,0 +1,243 @@
+package comment
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// linkRel is set on every link in rendered comments, so search engines do
+// not credit them and opened pages cannot reach back into ours
+const linkRel = "nofollow ugc noopener"
+
+var (
+	orderedItem   = regexp.MustCompile(`^\d{1,9}[.)] `)
+	unorderedItem = regexp.MustCompile(`^[-*+] `)
+)
+
+// RenderMarkdown renders comment content, written in a small subset of
+// Markdown, to HTML: paragraphs and line breaks, lists, block quotes, fenced
+// code, inline code, emphasis, strikethrough and links. Everything else is
+// escaped, including HTML, so the result is safe to insert into a page.
+// Links only go to http, https and mailto URLs.
+func RenderMarkdown(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(content, "\n"))
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// renderBlocks renders lines as a sequence of block elements
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			end := i + 1
+			for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "```") {
+				end++
+			}
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(lines[i+1:end], "\n")))
+			b.WriteString("</code></pre>\n")
+			i = end + 1
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quoted = append(quoted, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+
+		case unorderedItem.MatchString(trimmed):
+			i = renderList(b, lines, i, "ul", unorderedItem)
+
+		case orderedItem.MatchString(trimmed):
+			i = renderList(b, lines, i, "ol", orderedItem)
+
+		default:
+			b.WriteString("<p>")
+			for first := true; i < len(lines) && startsParagraphLine(lines[i]); i++ {
+				if !first {
+					b.WriteString("<br>\n")
+				}
+				renderInline(b, strings.TrimSpace(lines[i]), true)
+				first = false
+			}
+			b.WriteString("</p>\n")
+		}
+	}
+}
+
+// startsParagraphLine reports whether line continues a paragraph rather
+// than ending it or starting another block
+func startsParagraphLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" &&
+		!strings.HasPrefix(trimmed, "```") &&
+		!strings.HasPrefix(trimmed, ">") &&
+		!unorderedItem.MatchString(trimmed) &&
+		!orderedItem.MatchString(trimmed)
+}
+
+// renderList renders the list items matching marker from lines[i] on as a
+// tag list, and returns the index of the first line after it
+func renderList(b *strings.Builder, lines []string, i int, tag string, marker *regexp.Regexp) int {
+	b.WriteString("<" + tag + ">\n")
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		prefix := marker.FindString(trimmed)
+		if prefix == "" {
+			break
+		}
+		b.WriteString("<li>")
+		renderInline(b, strings.TrimSpace(trimmed[len(prefix):]), true)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// inlineTags are the emphasis delimiters, longest first, and the elements
+// they render to
+var inlineTags = []struct{ delimiter, tag string }{
+	{"**", "strong"},
+	{"__", "strong"},
+	{"~~", "del"},
+	{"*", "em"},
+	{"_", "em"},
+}
+
+// renderInline renders the spans of one line of text. links is false inside
+// a link's text, which may not hold another link.
+func renderInline(b *strings.Builder, text string, links bool) {
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		// A backslash escapes the punctuation after it
+		if rest[0] == '\\' && len(rest) > 1 && strings.IndexByte("\\`*_~[]()>#+-.!", rest[1]) >= 0 {
+			b.WriteString(html.EscapeString(rest[1:2]))
+			i += 2
+			continue
+		}
+
+		if rest[0] == '`' {
+			if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+				b.WriteString("<code>" + html.EscapeString(rest[1:1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+		}
+
+		if n := renderEmphasis(b, text, i, links); n > 0 {
+			i += n
+			continue
+		}
+
+		if links && rest[0] == '[' {
+			if n := renderLink(b, rest); n > 0 {
+				i += n
+				continue
+			}
+		}
+
+		if links && startsLink(rest) && (i == 0 || !isWordByte(text[i-1])) {
+			if match := linkPattern.FindStringIndex(rest); match != nil && match[0] == 0 {
+				link := trimLink(rest[:match[1]])
+				writeLink(b, link, func() { b.WriteString(html.EscapeString(link)) })
+				i += len(link)
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+}
+
+// renderEmphasis renders the emphasised span starting at text[i], if there
+// is one, and returns how much of text it took
+func renderEmphasis(b *strings.Builder, text string, i int, links bool) int {
+	rest := text[i:]
+	for _, t := range inlineTags {
+		d := t.delimiter
+		if !strings.HasPrefix(rest, d) || len(rest) <= len(d) || rest[len(d)] == ' ' {
+			continue
+		}
+		// Underscores inside words, as in snake_case, are not emphasis
+		if d[0] == '_' && i > 0 && isWordByte(text[i-1]) {
+			return 0
+		}
+		end := strings.Index(rest[len(d):], d)
+		if end <= 0 || rest[len(d)+end-1] == ' ' {
+			continue
+		}
+		after := len(d) + end + len(d)
+		if d[0] == '_' && after < len(rest) && isWordByte(rest[after]) {
+			continue
+		}
+		b.WriteString("<" + t.tag + ">")
+		renderInline(b, rest[len(d):len(d)+end], links)
+		b.WriteString("</" + t.tag + ">")
+		return after
+	}
+	return 0
+}
+
+// renderLink renders the [text](url) link at the start of text, if there
+// is one, and returns how much of text it took
+func renderLink(b *strings.Builder, text string) int {
+	closing := strings.Index(text, "](")
+	if closing <= 1 {
+		return 0
+	}
+	end := strings.IndexByte(text[closing+2:], ')')
+	if end <= 0 {
+		return 0
+	}
+	target := strings.TrimSpace(text[closing+2 : closing+2+end])
+	if !safeLink(target) {
+		return 0
+	}
+	writeLink(b, target, func() { renderInline(b, text[1:closing], false) })
+	return closing + 2 + end + 1
+}
+
+// writeLink writes a link to href whose text is written by body
+func writeLink(b *strings.Builder, href string, body func()) {
+	b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="` + linkRel + `">`)
+	body()
+	b.WriteString("</a>")
+}
+
+// safeLink reports whether link may be the target of a rendered link
+func safeLink(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+// startsLink reports whether text starts with an http or https scheme
+func startsLink(text string) bool {
+	prefix := strings.ToLower(text[:min(len(text), len("https://"))])
+	return strings.HasPrefix(prefix, "http://") || prefix == "https://"
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -3,6 +3,7 @@ package comment
 import (
 	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/google/uuid"
 )
 
@@ -12,14 +13,27 @@ type Comment struct {
 	ID        uuid.UUID  `json:"id" example:"123e4567-e89b-12d3-a456-426614174000" swaggertype:"string" format:"uuid"`
 	VideoID   uuid.UUID  `json:"video_id" example:"123e4567-e89b-12d3-a456-426614174001" swaggertype:"string" format:"uuid"`
 	UserID    uuid.UUID  `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174002" swaggertype:"string" format:"uuid"`
-	Content   string     `json:"content" example:"This is a great video!"`
+	Content   string     `json:"content" example:"This is a **great** video!"`
 	CreatedAt time.Time  `json:"created_at" example:"2023-01-01T12:00:00Z"`
 	UpdatedAt time.Time  `json:"updated_at" example:"2023-01-01T12:00:00Z"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"2023-01-02T12:00:00Z"`
 	ParentID  *uuid.UUID `json:"parent_id,omitempty" example:"123e4567-e89b-12d3-a456-426614174003" swaggertype:"string" format:"uuid"`
 	Likes     int        `json:"likes" example:"5"`
 	Dislikes  int        `json:"dislikes" example:"1"`
-	Status    Status     `json:"status" example:"ACTIVE" enums:"ACTIVE,FLAGGED,HIDDEN"`
+	Status    Status     `json:"status" example:"ACTIVE" enums:"ACTIVE,FLAGGED,HIDDEN,PENDING"`
+	// ReplyCount is the number of replies to a top-level comment, kept up to
+	// date as replies are created and deleted
+	ReplyCount int `json:"reply_count" example:"12"`
+	// Replies are the newest replies, inlined when listing comments with replies=N
+	Replies []Comment `json:"replies,omitempty"`
+	// ContentHTML is Content rendered from Markdown to HTML that is safe to
+	// insert into a page, when comments.markdown is enabled
+	ContentHTML string `json:"content_html,omitempty" example:"<p>This is a <strong>great</strong> video!</p>"`
+	// Links are the URLs found in Content, for clients to show previews of
+	Links []Link `json:"links,omitempty"`
+	// Filtered is set when banned words were masked in the comment, or held
+	// it for review, as it was posted or edited
+	Filtered *Filtered `json:"-"`
 }
 
 // Reaction represents a user's reaction to a comment
@@ -33,7 +47,7 @@ type Reaction struct {
 }
 
 // Status represents the possible statuses of a comment
-// @Description Status of a comment (ACTIVE, FLAGGED, or HIDDEN)
+// @Description Status of a comment (ACTIVE, FLAGGED, HIDDEN or PENDING)
 type Status string
 
 const (
@@ -45,6 +59,10 @@ const (
 
 	// StatusHidden represents a comment hidden from general view
 	StatusHidden Status = "HIDDEN"
+
+	// StatusPending represents a comment held for the video owner's review,
+	// visible only to its author and the owner
+	StatusPending Status = "PENDING"
 )
 
 // Type represents the possible reaction types
@@ -59,6 +77,11 @@ const (
 	TypeDislike Type = "DISLIKE"
 )
 
+// IsValid reports whether t is a known reaction type
+func (t Type) IsValid() bool {
+	return t == TypeLike || t == TypeDislike
+}
+
 // CommentFilterOptions provides filtering options for comment queries
 // @Description Options for filtering and paginating comments
 type CommentFilterOptions struct {
@@ -68,6 +91,11 @@ type CommentFilterOptions struct {
 	Limit     int        `json:"limit" example:"20"`
 	SortBy    string     `json:"sort_by" example:"created_at"`
 	SortOrder string     `json:"sort_order" example:"desc"`
+	// Replies is the number of replies to inline in each top-level comment
+	Replies int `json:"replies,omitempty" example:"3"`
+	// ViewerID is the user requesting the comments, whose blocked users'
+	// comments are left out; uuid.Nil for anonymous requests
+	ViewerID uuid.UUID `json:"-"`
 }
 
 // ReactionFilterOptions provides filtering options for reaction queries
@@ -88,6 +116,9 @@ type PaginatedComments struct {
 	TotalPages  int       `json:"total_pages" example:"3"`
 	HasNextPage bool      `json:"has_next_page" example:"true"`
 	HasPrevPage bool      `json:"has_prev_page" example:"false"`
+	// CommentsPolicy is whether the video takes comments (enabled, disabled
+	// or review_required), so clients can show the comment box accordingly
+	CommentsPolicy video.CommentsPolicy `json:"comments_policy,omitempty" swaggertype:"string" enums:"enabled,disabled,review_required" example:"enabled"`
 }
 
 // CreateCommentRequest represents the request body for creating a new comment
@@ -95,7 +126,7 @@ type PaginatedComments struct {
 // For replies, set parent_id to the UUID of the parent comment
 type CreateCommentRequest struct {
 	Content  string     `json:"content" binding:"required" example:"This is a comment"`
-	ParentID *uuid.UUID `json:"parent_id" example:""`
+	ParentID *uuid.UUID `json:"parent_id" binding:"omitempty,uuid" swaggertype:"string" format:"uuid" example:""`
 }
 
 // UpdateCommentRequest represents the request body for updating a comment
@@ -105,7 +136,7 @@ type UpdateCommentRequest struct {
 
 // ReactionRequest represents the request body for adding a reaction to a comment
 type ReactionRequest struct {
-	Type string `json:"type" binding:"required" example:"like"`
+	Type Type `json:"type" binding:"required,enum" example:"LIKE" enums:"LIKE,DISLIKE"`
 }
 
 // NewComment creates a new comment with default values
@@ -10,12 +10,23 @@ import (
 type Repository interface {
 	// Comment operations
 	GetByID(ctx context.Context, id uuid.UUID) (*Comment, error)
+	// GetByVideoID and GetReplies return one page of comments; the service
+	// fills in the totals from Count and CountReplies
 	GetByVideoID(ctx context.Context, options CommentFilterOptions) (PaginatedComments, error)
 	GetReplies(ctx context.Context, options CommentFilterOptions) (PaginatedComments, error)
+	// Create and Delete also keep the parent's ReplyCount for replies
 	Create(ctx context.Context, comment *Comment) error
-	Update(ctx context.Context, id uuid.UUID, content string) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Update(ctx context.Context, id uuid.UUID, content string, links []Link) error
+	UpdateStatus(ctx context.Context, id uuid.UUID, status Status) error
+	Delete(ctx context.Context, comment *Comment) error
+	// DeleteByVideo soft-deletes every comment on a video and drops the
+	// video's comment indexes, returning how many comments it deleted
+	DeleteByVideo(ctx context.Context, videoID uuid.UUID) (int, error)
 	Count(ctx context.Context, videoID uuid.UUID) (int, error)
+	// CountByVideos counts the comments of several videos at once, leaving
+	// out videos without comments
+	CountByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error)
+	CountReplies(ctx context.Context, parentID uuid.UUID) (int, error)
 
 	// Reaction operations
 	GetReactions(ctx context.Context, options ReactionFilterOptions) ([]Reaction, int, error)
@@ -23,6 +34,10 @@ type Repository interface {
 	CreateOrUpdateReaction(ctx context.Context, reaction *Reaction) error
 	DeleteReaction(ctx context.Context, commentID, userID uuid.UUID) error
 	GetReactionCounts(ctx context.Context, commentID uuid.UUID) (int, int, error)
+
+	// Banned words of channels, filtered out of comments on their videos
+	GetBannedWords(ctx context.Context, ownerID uuid.UUID) ([]string, error)
+	SetBannedWords(ctx context.Context, ownerID uuid.UUID, words []string) error
 }
 
 // Service defines the business logic interface for comment operations
@@ -31,12 +46,25 @@ type Service interface {
 	GetCommentByID(ctx context.Context, id uuid.UUID) (*Comment, error)
 	GetCommentsByVideoID(ctx context.Context, options CommentFilterOptions) (PaginatedComments, error)
 	GetRepliesByCommentID(ctx context.Context, options CommentFilterOptions) (PaginatedComments, error)
+	// CountComments returns the number of comments on each of videoIDs
+	CountComments(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error)
 	CreateComment(ctx context.Context, comment *Comment) error
-	UpdateComment(ctx context.Context, id uuid.UUID, content string) error
+	// UpdateComment replaces a comment's content and returns the comment
+	UpdateComment(ctx context.Context, id uuid.UUID, content string) (*Comment, error)
+	// ReviewComment publishes or deletes a comment held for review; only the
+	// video owner may review
+	ReviewComment(ctx context.Context, id, reviewerID uuid.UUID, approve bool) (*Comment, error)
 	DeleteComment(ctx context.Context, id uuid.UUID) error
+	// DeleteVideoComments soft-deletes the comments of a deleted video
+	DeleteVideoComments(ctx context.Context, videoID uuid.UUID) (int, error)
 
 	// Reaction operations
 	GetUserReaction(ctx context.Context, commentID, userID uuid.UUID) (*Reaction, error)
 	AddReaction(ctx context.Context, reaction *Reaction) error
 	RemoveReaction(ctx context.Context, commentID, userID uuid.UUID) error
+
+	// GetBannedWords and SetBannedWords manage the words a channel bans from
+	// comments on its videos
+	GetBannedWords(ctx context.Context, ownerID uuid.UUID) (*BannedWordsResponse, error)
+	SetBannedWords(ctx context.Context, ownerID uuid.UUID, words []string) (*BannedWordsResponse, error)
 }
@@ -2,38 +2,73 @@ package comment
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/google/uuid"
 )
 
 // Common errors
 var (
-	ErrCommentNotFound  = errors.New("comment not found")
-	ErrInvalidComment   = errors.New("invalid comment")
-	ErrInvalidReaction  = errors.New("invalid reaction")
-	ErrPermissionDenied = errors.New("permission denied")
-	ErrInvalidPage      = errors.New("invalid page number")
-	ErrInvalidLimit     = errors.New("invalid limit number")
+	ErrCommentNotFound  = apierror.New(apierror.CodeNotFound, "comment not found")
+	ErrInvalidComment   = apierror.New(apierror.CodeValidation, "invalid comment")
+	ErrInvalidReaction  = apierror.Validation("type", "invalid reaction")
+	ErrPermissionDenied = apierror.New(apierror.CodeForbidden, "permission denied")
+	ErrInvalidPage      = apierror.New(apierror.CodeInvalidParameter, "invalid page number")
+	ErrInvalidLimit     = apierror.New(apierror.CodeInvalidParameter, "invalid limit number")
+	ErrCommentsDisabled = apierror.New(apierror.CodeForbidden, "comments are disabled on this video")
 )
 
+// MaxInlineReplies is the most replies inlined in each top-level comment
+const MaxInlineReplies = 10
+
+// BlockList looks up the users a user has blocked
+type BlockList interface {
+	IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error)
+	BlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// VideoLookup loads the video comments belong to, for its owner and comments policy
+type VideoLookup interface {
+	GetVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error)
+}
+
 // serviceImpl implements the Service interface
 type serviceImpl struct {
-	repo Repository
+	repo    Repository
+	counts  *CountCache
+	blocks  BlockList
+	videos  VideoLookup
+	content ContentPolicy
 }
 
-// NewService creates a new comment service
-func NewService(repo Repository) Service {
+// NewService creates a new comment service. counts caches comment and reply
+// counts; it may be nil. blocks hides the comments of blocked users and stops
+// them replying; it may be nil as well. videos enforces the comments policy of
+// videos; when nil, every video takes comments. content sanitizes and
+// limits what comments say, and renders it for responses.
+func NewService(repo Repository, counts *CountCache, blocks BlockList, videos VideoLookup, content ContentPolicy) Service {
+	content.BannedWords = normalizeWords(content.BannedWords)
 	return &serviceImpl{
-		repo: repo,
+		repo:    repo,
+		counts:  counts,
+		blocks:  blocks,
+		videos:  videos,
+		content: content,
 	}
 }
 
 // GetCommentByID retrieves a comment by its ID
 func (s *serviceImpl) GetCommentByID(ctx context.Context, id uuid.UUID) (*Comment, error) {
-	return s.repo.GetByID(ctx, id)
+	comment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.content.present(comment)
+	return comment, nil
 }
 
 // GetCommentsByVideoID retrieves comments for a video with pagination
@@ -48,10 +83,45 @@ func (s *serviceImpl) GetCommentsByVideoID(ctx context.Context, options CommentF
 		options.Limit = 100
 	}
 
+	if options.Replies < 0 {
+		options.Replies = 0
+	} else if options.Replies > MaxInlineReplies {
+		options.Replies = MaxInlineReplies
+	}
+
 	// Ensure we're only getting top-level comments
 	options.ParentID = nil
 
-	return s.repo.GetByVideoID(ctx, options)
+	var ownerID uuid.UUID
+	var policy video.CommentsPolicy
+	if s.videos != nil {
+		v, err := s.videos.GetVideo(ctx, options.VideoID)
+		if err != nil {
+			return PaginatedComments{}, err
+		}
+		ownerID, policy = v.UserID, v.CommentsPolicy
+	}
+
+	result, err := s.repo.GetByVideoID(ctx, options)
+	if err != nil {
+		return result, err
+	}
+	result.CommentsPolicy = policy
+	if err := s.inlineReplies(ctx, result.Comments, options.Replies); err != nil {
+		return result, err
+	}
+	if result.Comments, err = s.visible(ctx, options.ViewerID, ownerID, result.Comments); err != nil {
+		return result, err
+	}
+	s.content.presentAll(result.Comments)
+
+	count, err := s.counts.get(ctx, videoCountKey(options.VideoID), func() (int, error) {
+		return s.repo.Count(ctx, options.VideoID)
+	})
+	if err != nil {
+		return result, err
+	}
+	return paginate(result, count, options), nil
 }
 
 // GetRepliesByCommentID retrieves replies for a comment with pagination
@@ -68,34 +138,153 @@ func (s *serviceImpl) GetRepliesByCommentID(ctx context.Context, options Comment
 
 	// Validate commentID
 	if options.ParentID == nil {
-		return PaginatedComments{}, errors.New("parent comment ID is required")
+		return PaginatedComments{}, fmt.Errorf("%w: parent comment ID is required", ErrInvalidComment)
+	}
+
+	ownerID, err := s.videoOwner(ctx, *options.ParentID)
+	if err != nil {
+		return PaginatedComments{}, err
+	}
+
+	result, err := s.repo.GetReplies(ctx, options)
+	if err != nil {
+		return result, err
 	}
+	if result.Comments, err = s.visible(ctx, options.ViewerID, ownerID, result.Comments); err != nil {
+		return result, err
+	}
+	s.content.presentAll(result.Comments)
 
-	return s.repo.GetReplies(ctx, options)
+	count, err := s.counts.get(ctx, replyCountKey(*options.ParentID), func() (int, error) {
+		return s.repo.CountReplies(ctx, *options.ParentID)
+	})
+	if err != nil {
+		return result, err
+	}
+	return paginate(result, count, options), nil
+}
+
+// inlineReplies fills in the newest n replies of each comment that has any
+func (s *serviceImpl) inlineReplies(ctx context.Context, comments []Comment, n int) error {
+	if n == 0 {
+		return nil
+	}
+	for i := range comments {
+		if comments[i].ReplyCount == 0 {
+			continue
+		}
+		replies, err := s.repo.GetReplies(ctx, CommentFilterOptions{
+			ParentID:  &comments[i].ID,
+			Page:      1,
+			Limit:     n,
+			SortBy:    "created_at",
+			SortOrder: "desc",
+		})
+		if err != nil {
+			return fmt.Errorf("error getting replies to comment %s: %w", comments[i].ID, err)
+		}
+		comments[i].Replies = replies.Comments
+	}
+	return nil
+}
+
+// visible leaves out the comments and inlined replies the viewer may not
+// see: those of users the viewer blocked, and those held for review unless
+// the viewer wrote them or owns the video. The totals of the page still
+// count them.
+func (s *serviceImpl) visible(ctx context.Context, viewerID, ownerID uuid.UUID, comments []Comment) ([]Comment, error) {
+	blocked := map[uuid.UUID]bool{}
+	if s.blocks != nil && viewerID != uuid.Nil && len(comments) > 0 {
+		ids, err := s.blocks.BlockedIDs(ctx, viewerID)
+		if err != nil {
+			return comments, fmt.Errorf("error loading blocked users: %w", err)
+		}
+		for _, id := range ids {
+			blocked[id] = true
+		}
+	}
+	hidden := func(c Comment) bool {
+		if blocked[c.UserID] {
+			return true
+		}
+		return c.Status == StatusPending && (viewerID == uuid.Nil || (viewerID != c.UserID && viewerID != ownerID))
+	}
+
+	visible := comments[:0]
+	for _, comment := range comments {
+		if hidden(comment) {
+			continue
+		}
+		if len(comment.Replies) > 0 {
+			replies := make([]Comment, 0, len(comment.Replies))
+			for _, reply := range comment.Replies {
+				if !hidden(reply) {
+					replies = append(replies, reply)
+				}
+			}
+			comment.Replies = replies
+		}
+		visible = append(visible, comment)
+	}
+	return visible, nil
+}
+
+// videoOwner returns the owner of the video a comment belongs to, or
+// uuid.Nil when videos are not looked up
+func (s *serviceImpl) videoOwner(ctx context.Context, commentID uuid.UUID) (uuid.UUID, error) {
+	if s.videos == nil {
+		return uuid.Nil, nil
+	}
+	c, err := s.repo.GetByID(ctx, commentID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if c == nil {
+		return uuid.Nil, ErrCommentNotFound
+	}
+	v, err := s.videos.GetVideo(ctx, c.VideoID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return v.UserID, nil
+}
+
+// CountComments returns the number of comments on each of videoIDs. Counts
+// missing from the cache are loaded with one query.
+func (s *serviceImpl) CountComments(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	return s.counts.getMany(ctx, videoIDs, videoCountKey, func(missing []uuid.UUID) (map[uuid.UUID]int, error) {
+		return s.repo.CountByVideos(ctx, missing)
+	})
+}
+
+// paginate fills in the totals of a page of comments out of count
+func paginate(result PaginatedComments, count int, options CommentFilterOptions) PaginatedComments {
+	result.TotalCount = count
+	result.TotalPages = int(math.Ceil(float64(count) / float64(options.Limit)))
+	result.HasNextPage = options.Page < result.TotalPages
+	result.HasPrevPage = options.Page > 1
+	return result
 }
 
 // CreateComment creates a new comment
 func (s *serviceImpl) CreateComment(ctx context.Context, comment *Comment) error {
-	fmt.Printf("DEBUG SERVICE: Starting CreateComment for videoID %s\n", comment.VideoID.String())
 
 	// Validate comment
 	if comment.VideoID == uuid.Nil {
-		fmt.Printf("DEBUG SERVICE: Invalid videoID - nil UUID\n")
-		return errors.New("video ID is required")
+		return fmt.Errorf("%w: video ID is required", ErrInvalidComment)
 	}
 	if comment.UserID == uuid.Nil {
-		fmt.Printf("DEBUG SERVICE: Invalid userID - nil UUID\n")
-		return errors.New("user ID is required")
+		return fmt.Errorf("%w: user ID is required", ErrInvalidComment)
 	}
-	if comment.Content == "" {
-		fmt.Printf("DEBUG SERVICE: Empty content\n")
-		return errors.New("content is required")
+	content, links, err := s.content.prepare(comment.Content)
+	if err != nil {
+		return err
 	}
+	comment.Content, comment.Links = content, links
 
 	// Set default values
 	if comment.ID == uuid.Nil {
 		comment.ID = uuid.New()
-		fmt.Printf("DEBUG SERVICE: Generated new comment ID: %s\n", comment.ID.String())
 	}
 	now := time.Now().UTC()
 	if comment.CreatedAt.IsZero() {
@@ -108,55 +297,147 @@ func (s *serviceImpl) CreateComment(ctx context.Context, comment *Comment) error
 		comment.Status = StatusActive
 	}
 
+	var ownerID uuid.UUID
+	if s.videos != nil {
+		v, err := s.videos.GetVideo(ctx, comment.VideoID)
+		if err != nil {
+			return err
+		}
+		ownerID = v.UserID
+		switch v.CommentsPolicy {
+		case video.CommentsDisabled:
+			return ErrCommentsDisabled
+		case video.CommentsReviewRequired:
+			if comment.UserID != v.UserID {
+				comment.Status = StatusPending
+			}
+		}
+	}
+	if err := s.filterWords(ctx, comment, ownerID); err != nil {
+		return err
+	}
+
 	// If this is a reply, validate parent comment exists
 	if comment.ParentID != nil {
-		fmt.Printf("DEBUG SERVICE: This is a reply to comment %s\n", comment.ParentID.String())
 		parent, err := s.repo.GetByID(ctx, *comment.ParentID)
 		if err != nil {
-			fmt.Printf("DEBUG SERVICE: Error validating parent comment: %v\n", err)
 			return fmt.Errorf("error validating parent comment: %w", err)
 		}
 		if parent == nil {
-			fmt.Printf("DEBUG SERVICE: Parent comment not found\n")
 			return ErrCommentNotFound
 		}
 
 		// Ensure parent is not itself a reply
 		if parent.ParentID != nil {
-			fmt.Printf("DEBUG SERVICE: Cannot reply to a reply\n")
-			return errors.New("cannot reply to a reply")
+			return fmt.Errorf("%w: cannot reply to a reply", ErrInvalidComment)
+		}
+
+		if s.blocks != nil {
+			blocked, err := s.blocks.IsBlocked(ctx, parent.UserID, comment.UserID)
+			if err != nil {
+				return fmt.Errorf("error checking blocks: %w", err)
+			}
+			if blocked {
+				return fmt.Errorf("%w: the author of the comment has blocked you", ErrPermissionDenied)
+			}
 		}
 	}
 
-	fmt.Printf("DEBUG SERVICE: Calling repository.Create\n")
 	// Save the comment to the repository
-	err := s.repo.Create(ctx, comment)
-	if err != nil {
-		fmt.Printf("DEBUG SERVICE: Repository error: %v\n", err)
+	if err := s.repo.Create(ctx, comment); err != nil {
 		return fmt.Errorf("error creating comment in repository: %w", err)
 	}
+	s.counts.invalidate(ctx, comment)
+	s.content.present(comment)
 
-	fmt.Printf("DEBUG SERVICE: Comment created successfully\n")
 	return nil
 }
 
-// UpdateComment updates a comment's content
-func (s *serviceImpl) UpdateComment(ctx context.Context, id uuid.UUID, content string) error {
+// UpdateComment updates a comment's content. Edits are filtered for banned
+// words like new comments, and may hold a published comment for review.
+func (s *serviceImpl) UpdateComment(ctx context.Context, id uuid.UUID, content string) (*Comment, error) {
 	// Validate content
-	if content == "" {
-		return errors.New("content is required")
+	content, links, err := s.content.prepare(content)
+	if err != nil {
+		return nil, err
 	}
 
 	// Get comment to update
 	comment, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if comment == nil {
-		return ErrCommentNotFound
+		return nil, ErrCommentNotFound
 	}
 
-	return s.repo.Update(ctx, id, content)
+	var ownerID uuid.UUID
+	if s.videos != nil {
+		v, err := s.videos.GetVideo(ctx, comment.VideoID)
+		if err != nil {
+			return nil, err
+		}
+		ownerID = v.UserID
+	}
+	status := comment.Status
+	comment.Content, comment.Links = content, links
+	if err := s.filterWords(ctx, comment, ownerID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, id, comment.Content, comment.Links); err != nil {
+		return nil, err
+	}
+	if comment.Status != status {
+		if err := s.repo.UpdateStatus(ctx, id, comment.Status); err != nil {
+			return nil, err
+		}
+	}
+	comment.UpdatedAt = time.Now().UTC()
+	s.content.present(comment)
+	return comment, nil
+}
+
+// ReviewComment publishes a comment held for review, or deletes it when
+// approve is false. Comments that are not held are returned unchanged.
+func (s *serviceImpl) ReviewComment(ctx context.Context, id, reviewerID uuid.UUID, approve bool) (*Comment, error) {
+	comment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil || comment.DeletedAt != nil {
+		return nil, ErrCommentNotFound
+	}
+	if s.videos == nil {
+		return nil, fmt.Errorf("%w: only the video owner can review comments", ErrPermissionDenied)
+	}
+	v, err := s.videos.GetVideo(ctx, comment.VideoID)
+	if err != nil {
+		return nil, err
+	}
+	if v.UserID != reviewerID {
+		return nil, fmt.Errorf("%w: only the video owner can review comments", ErrPermissionDenied)
+	}
+	if comment.Status != StatusPending {
+		s.content.present(comment)
+		return comment, nil
+	}
+
+	if !approve {
+		if err := s.repo.Delete(ctx, comment); err != nil {
+			return nil, err
+		}
+		s.counts.invalidate(ctx, comment)
+		comment.Status = StatusHidden
+		s.content.present(comment)
+		return comment, nil
+	}
+	if err := s.repo.UpdateStatus(ctx, id, StatusActive); err != nil {
+		return nil, err
+	}
+	comment.Status = StatusActive
+	s.content.present(comment)
+	return comment, nil
 }
 
 // DeleteComment soft-deletes a comment
@@ -170,13 +451,29 @@ func (s *serviceImpl) DeleteComment(ctx context.Context, id uuid.UUID) error {
 		return ErrCommentNotFound
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, comment); err != nil {
+		return err
+	}
+	s.counts.invalidate(ctx, comment)
+	return nil
+}
+
+// DeleteVideoComments soft-deletes every comment on a video, once the video
+// itself is deleted. No COMMENT_DELETED events are published for them; the
+// video's comment count is put right by reconciliation.
+func (s *serviceImpl) DeleteVideoComments(ctx context.Context, videoID uuid.UUID) (int, error) {
+	deleted, err := s.repo.DeleteByVideo(ctx, videoID)
+	if err != nil {
+		return deleted, err
+	}
+	s.counts.invalidateVideo(ctx, videoID)
+	return deleted, nil
 }
 
 // GetUserReaction gets a user's reaction to a comment
 func (s *serviceImpl) GetUserReaction(ctx context.Context, commentID, userID uuid.UUID) (*Reaction, error) {
 	if commentID == uuid.Nil || userID == uuid.Nil {
-		return nil, errors.New("comment ID and user ID are required")
+		return nil, fmt.Errorf("%w: comment ID and user ID are required", ErrInvalidComment)
 	}
 
 	return s.repo.GetReactionByUser(ctx, commentID, userID)
@@ -186,13 +483,13 @@ func (s *serviceImpl) GetUserReaction(ctx context.Context, commentID, userID uui
 func (s *serviceImpl) AddReaction(ctx context.Context, reaction *Reaction) error {
 	// Validate reaction
 	if reaction.CommentID == uuid.Nil {
-		return errors.New("comment ID is required")
+		return fmt.Errorf("%w: comment ID is required", ErrInvalidReaction)
 	}
 	if reaction.UserID == uuid.Nil {
-		return errors.New("user ID is required")
+		return fmt.Errorf("%w: user ID is required", ErrInvalidReaction)
 	}
 	if reaction.Type != TypeLike && reaction.Type != TypeDislike {
-		return errors.New("invalid reaction type")
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidReaction, reaction.Type)
 	}
 
 	// Check if comment exists
@@ -217,7 +514,7 @@ func (s *serviceImpl) AddReaction(ctx context.Context, reaction *Reaction) error
 // RemoveReaction removes a user's reaction from a comment
 func (s *serviceImpl) RemoveReaction(ctx context.Context, commentID, userID uuid.UUID) error {
 	if commentID == uuid.Nil || userID == uuid.Nil {
-		return errors.New("comment ID and user ID are required")
+		return fmt.Errorf("%w: comment ID and user ID are required", ErrInvalidComment)
 	}
 
 	// Check if comment exists
@@ -0,0 +1,312 @@
+package comment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository serves a fixed set of comments; other methods are not used
+type fakeRepository struct {
+	Repository
+	comments []Comment
+	replies  map[uuid.UUID][]Comment
+	created  []*Comment
+	// replyQueries are the options GetReplies was called with
+	replyQueries []CommentFilterOptions
+	// bannedWords are the channels' banned words, by owner
+	bannedWords map[uuid.UUID][]string
+}
+
+func (r *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Comment, error) {
+	for i := range r.comments {
+		if r.comments[i].ID == id {
+			return &r.comments[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeRepository) GetByVideoID(ctx context.Context, options CommentFilterOptions) (PaginatedComments, error) {
+	return PaginatedComments{Comments: append([]Comment(nil), r.comments...), CurrentPage: options.Page}, nil
+}
+
+func (r *fakeRepository) GetReplies(ctx context.Context, options CommentFilterOptions) (PaginatedComments, error) {
+	r.replyQueries = append(r.replyQueries, options)
+	replies := r.replies[*options.ParentID]
+	start := min((options.Page-1)*options.Limit, len(replies))
+	end := min(start+options.Limit, len(replies))
+	return PaginatedComments{Comments: append([]Comment(nil), replies[start:end]...), CurrentPage: options.Page}, nil
+}
+
+func (r *fakeRepository) Count(ctx context.Context, videoID uuid.UUID) (int, error) {
+	return len(r.comments), nil
+}
+
+func (r *fakeRepository) CountReplies(ctx context.Context, parentID uuid.UUID) (int, error) {
+	return len(r.replies[parentID]), nil
+}
+
+func (r *fakeRepository) Create(ctx context.Context, comment *Comment) error {
+	r.created = append(r.created, comment)
+	return nil
+}
+
+// fakeBlockList maps blockers to the users they blocked
+type fakeBlockList map[uuid.UUID][]uuid.UUID
+
+func (b fakeBlockList) IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	for _, id := range b[blockerID] {
+		if id == blockedID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (b fakeBlockList) BlockedIDs(ctx context.Context, blockerID uuid.UUID) ([]uuid.UUID, error) {
+	return b[blockerID], nil
+}
+
+func TestGetCommentsHidesBlockedUsers(t *testing.T) {
+	viewer, blocked, other := uuid.New(), uuid.New(), uuid.New()
+	videoID := uuid.New()
+	parent := Comment{ID: uuid.New(), VideoID: videoID, UserID: other, ReplyCount: 2}
+	repo := &fakeRepository{
+		comments: []Comment{
+			parent,
+			{ID: uuid.New(), VideoID: videoID, UserID: blocked},
+		},
+		replies: map[uuid.UUID][]Comment{parent.ID: {
+			{ID: uuid.New(), VideoID: videoID, UserID: blocked, ParentID: &parent.ID},
+			{ID: uuid.New(), VideoID: videoID, UserID: viewer, ParentID: &parent.ID},
+		}},
+	}
+	service := NewService(repo, nil, fakeBlockList{viewer: {blocked}}, nil, ContentPolicy{})
+
+	page, err := service.GetCommentsByVideoID(context.Background(), CommentFilterOptions{VideoID: videoID, Replies: 5, ViewerID: viewer})
+	require.NoError(t, err)
+	require.Len(t, page.Comments, 1)
+	assert.Equal(t, parent.ID, page.Comments[0].ID)
+	require.Len(t, page.Comments[0].Replies, 1)
+	assert.Equal(t, viewer, page.Comments[0].Replies[0].UserID)
+
+	replies, err := service.GetRepliesByCommentID(context.Background(), CommentFilterOptions{ParentID: &parent.ID, ViewerID: viewer})
+	require.NoError(t, err)
+	assert.Len(t, replies.Comments, 1)
+
+	page, err = service.GetCommentsByVideoID(context.Background(), CommentFilterOptions{VideoID: videoID})
+	require.NoError(t, err)
+	assert.Len(t, page.Comments, 2, "anonymous listings are not filtered")
+}
+
+func TestGetCommentsInlinesReplies(t *testing.T) {
+	videoID := uuid.New()
+	threaded := Comment{ID: uuid.New(), VideoID: videoID, UserID: uuid.New(), ReplyCount: 12}
+	quiet := Comment{ID: uuid.New(), VideoID: videoID, UserID: uuid.New()}
+	var replies []Comment
+	for i := 0; i < threaded.ReplyCount; i++ {
+		replies = append(replies, Comment{ID: uuid.New(), VideoID: videoID, UserID: uuid.New(), ParentID: &threaded.ID})
+	}
+	repo := &fakeRepository{
+		comments: []Comment{threaded, quiet},
+		replies:  map[uuid.UUID][]Comment{threaded.ID: replies},
+	}
+	service := NewService(repo, nil, nil, nil, ContentPolicy{})
+
+	page, err := service.GetCommentsByVideoID(context.Background(), CommentFilterOptions{VideoID: videoID, Replies: 3})
+	require.NoError(t, err)
+	require.Len(t, page.Comments, 2)
+	assert.Equal(t, 12, page.Comments[0].ReplyCount)
+	assert.Equal(t, replies[:3], page.Comments[0].Replies, "the first page of the newest replies")
+	assert.Empty(t, page.Comments[1].Replies)
+
+	// Only comments with replies are looked up, newest first
+	require.Len(t, repo.replyQueries, 1)
+	assert.Equal(t, threaded.ID, *repo.replyQueries[0].ParentID)
+	assert.Equal(t, "desc", repo.replyQueries[0].SortOrder)
+
+	// At most MaxInlineReplies are inlined
+	page, err = service.GetCommentsByVideoID(context.Background(), CommentFilterOptions{VideoID: videoID, Replies: 50})
+	require.NoError(t, err)
+	assert.Len(t, page.Comments[0].Replies, MaxInlineReplies)
+
+	// None without replies=N
+	repo.replyQueries = nil
+	page, err = service.GetCommentsByVideoID(context.Background(), CommentFilterOptions{VideoID: videoID})
+	require.NoError(t, err)
+	assert.Empty(t, page.Comments[0].Replies)
+	assert.Equal(t, 12, page.Comments[0].ReplyCount, "the count is still reported")
+	assert.Empty(t, repo.replyQueries)
+}
+
+func TestCannotReplyToBlocker(t *testing.T) {
+	author, blocked := uuid.New(), uuid.New()
+	parent := Comment{ID: uuid.New(), VideoID: uuid.New(), UserID: author}
+	repo := &fakeRepository{comments: []Comment{parent}}
+	service := NewService(repo, nil, fakeBlockList{author: {blocked}}, nil, ContentPolicy{})
+
+	err := service.CreateComment(context.Background(), NewComment(parent.VideoID, blocked, "hello", &parent.ID))
+	var apiErr *apierror.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, apierror.CodeForbidden, apiErr.Code)
+	assert.Empty(t, repo.created)
+
+	// Top-level comments on the blocker's video are still allowed
+	require.NoError(t, service.CreateComment(context.Background(), NewComment(parent.VideoID, blocked, "hello", nil)))
+	assert.Len(t, repo.created, 1)
+}
+
+// fakeVideos serves videos by ID
+type fakeVideos map[uuid.UUID]*video.Video
+
+func (v fakeVideos) GetVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	if found, ok := v[videoID]; ok {
+		return found, nil
+	}
+	return nil, video.ErrVideoNotFound
+}
+
+func TestCommentsPolicy(t *testing.T) {
+	owner, viewer := uuid.New(), uuid.New()
+	disabled := &video.Video{ID: uuid.New(), UserID: owner, CommentsPolicy: video.CommentsDisabled}
+	review := &video.Video{ID: uuid.New(), UserID: owner, CommentsPolicy: video.CommentsReviewRequired}
+	repo := &fakeRepository{}
+	service := NewService(repo, nil, nil, fakeVideos{disabled.ID: disabled, review.ID: review}, ContentPolicy{})
+
+	err := service.CreateComment(context.Background(), NewComment(disabled.ID, viewer, "hello", nil))
+	assert.ErrorIs(t, err, ErrCommentsDisabled)
+
+	held := NewComment(review.ID, viewer, "hello", nil)
+	require.NoError(t, service.CreateComment(context.Background(), held))
+	assert.Equal(t, StatusPending, held.Status)
+	own := NewComment(review.ID, owner, "thanks for watching", nil)
+	require.NoError(t, service.CreateComment(context.Background(), own))
+	assert.Equal(t, StatusActive, own.Status, "the owner's comments are not held")
+
+	repo.comments = []Comment{*held, *own}
+	for viewerID, expected := range map[uuid.UUID]int{uuid.Nil: 1, uuid.New(): 1, viewer: 2, owner: 2} {
+		page, err := service.GetCommentsByVideoID(context.Background(), CommentFilterOptions{VideoID: review.ID, ViewerID: viewerID})
+		require.NoError(t, err)
+		assert.Len(t, page.Comments, expected)
+		assert.Equal(t, video.CommentsReviewRequired, page.CommentsPolicy)
+	}
+
+	_, err = service.ReviewComment(context.Background(), held.ID, viewer, true)
+	var apiErr *apierror.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, apierror.CodeForbidden, apiErr.Code)
+}
+
+func (r *fakeRepository) Delete(ctx context.Context, comment *Comment) error {
+	for i := range r.comments {
+		if r.comments[i].ID == comment.ID {
+			now := time.Now()
+			r.comments[i].DeletedAt = &now
+		}
+	}
+	return nil
+}
+
+// fakePublisher records the comment events it is given
+type fakePublisher struct {
+	events []*notification.CommentEvent
+}
+
+func (p *fakePublisher) PublishCommentEvent(ctx context.Context, event *notification.CommentEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+// nopLogger discards log messages
+type nopLogger struct{}
+
+func (nopLogger) LogDebug(string, map[string]interface{}) {}
+func (nopLogger) LogInfo(string, map[string]interface{})  {}
+func (nopLogger) LogError(string, map[string]interface{}) {}
+
+func TestWithEventsPublishesCommentEvents(t *testing.T) {
+	ctx := context.Background()
+	owner, author, replier := uuid.New(), uuid.New(), uuid.New()
+	v := &video.Video{ID: uuid.New(), UserID: owner}
+	repo := &fakeRepository{}
+	publisher := &fakePublisher{}
+	service := WithEvents(NewService(repo, nil, nil, fakeVideos{v.ID: v}, ContentPolicy{}), fakeVideos{v.ID: v}, publisher, nopLogger{})
+
+	parent := NewComment(v.ID, author, "first", nil)
+	require.NoError(t, service.CreateComment(ctx, parent))
+	repo.comments = append(repo.comments, *parent)
+	reply := NewComment(v.ID, replier, "second", &parent.ID)
+	require.NoError(t, service.CreateComment(ctx, reply))
+	repo.comments = append(repo.comments, *reply)
+
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, notification.CommentCreated, publisher.events[0].Type)
+	assert.Equal(t, owner, publisher.events[0].UserID, "new comments are for the video owner")
+	assert.Equal(t, author, publisher.events[0].ActorID)
+	assert.Equal(t, notification.CommentReplied, publisher.events[1].Type)
+	assert.Equal(t, author, publisher.events[1].UserID, "replies are for the parent's author")
+	assert.Equal(t, parent.ID, publisher.events[1].ParentID)
+
+	require.NoError(t, service.DeleteComment(ctx, reply.ID))
+	require.NoError(t, service.DeleteComment(ctx, reply.ID))
+	require.Len(t, publisher.events, 3, "deleting a deleted comment publishes nothing")
+	assert.Equal(t, notification.CommentDeleted, publisher.events[2].Type)
+	assert.Equal(t, v.ID, publisher.events[2].VideoID)
+}
+
+func TestWithEventsPublishesRejectedComments(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	v := &video.Video{ID: uuid.New(), UserID: owner, CommentsPolicy: video.CommentsReviewRequired}
+	held := NewComment(v.ID, uuid.New(), "hello", nil)
+	held.Status = StatusPending
+	repo := &fakeRepository{comments: []Comment{*held}}
+	publisher := &fakePublisher{}
+	service := WithEvents(NewService(repo, nil, nil, fakeVideos{v.ID: v}, ContentPolicy{}), fakeVideos{v.ID: v}, publisher, nopLogger{})
+
+	rejected, err := service.ReviewComment(ctx, held.ID, owner, false)
+	require.NoError(t, err)
+	assert.Equal(t, StatusHidden, rejected.Status)
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, notification.CommentDeleted, publisher.events[0].Type)
+	assert.Equal(t, held.ID, publisher.events[0].CommentID)
+}
+
+func (r *fakeRepository) DeleteByVideo(ctx context.Context, videoID uuid.UUID) (int, error) {
+	deleted := 0
+	for i := range r.comments {
+		if r.comments[i].VideoID == videoID && r.comments[i].DeletedAt == nil {
+			now := time.Now()
+			r.comments[i].DeletedAt = &now
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestDeleteVideoComments(t *testing.T) {
+	ctx := context.Background()
+	videoID, otherID := uuid.New(), uuid.New()
+	parent := NewComment(videoID, uuid.New(), "first", nil)
+	reply := NewComment(videoID, uuid.New(), "second", &parent.ID)
+	other := NewComment(otherID, uuid.New(), "elsewhere", nil)
+	repo := &fakeRepository{comments: []Comment{*parent, *reply, *other}}
+	publisher := &fakePublisher{}
+	service := WithEvents(NewService(repo, nil, nil, nil, ContentPolicy{}), nil, publisher, nopLogger{})
+
+	deleted, err := service.DeleteVideoComments(ctx, videoID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.NotNil(t, repo.comments[0].DeletedAt)
+	assert.NotNil(t, repo.comments[1].DeletedAt)
+	assert.Nil(t, repo.comments[2].DeletedAt, "comments on other videos are kept")
+	assert.Empty(t, publisher.events, "the video is gone, so nobody is told")
+}
@@ -0,0 +1,497 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/scan"
+	"github.com/spf13/viper"
+)
+
+// envBinding maps a configuration key to the environment variable that overrides it
+type envBinding struct {
+	Key string
+	Env string
+}
+
+// envBindings lists the settings that are read from explicitly named
+// environment variables, mostly credentials that should stay out of config files
+var envBindings = []envBinding{
+	{"environment", "ENV"},
+
+	{"logging.level", "LOG_LEVEL"},
+	{"logging.format", "LOG_FORMAT"},
+	{"logging.output", "LOG_OUTPUT"},
+	{"logging.file.enabled", "LOG_FILE_ENABLED"},
+	{"logging.file.path", "LOG_FILE_PATH"},
+	{"logging.file.rotate", "LOG_FILE_ROTATE"},
+	{"logging.file.maxSize", "LOG_FILE_MAX_SIZE"},
+	{"logging.file.maxAge", "LOG_FILE_MAX_AGE"},
+	{"logging.development", "LOG_ENV_DEVELOPMENT"},
+	{"logging.logBodies", "LOG_BODIES"},
+	{"logging.sampling.initial", "LOG_SAMPLING_INITIAL"},
+	{"logging.sampling.thereafter", "LOG_SAMPLING_THEREAFTER"},
+
+	{"database.password", "DB_PASSWORD"},
+	{"redis.password", "REDIS_PASSWORD"},
+	{"scylladb.password", "SCYLLA_PASSWORD"},
+	{"pulsar.auth_token", "PULSAR_AUTH_TOKEN"},
+	{"auth.jwt.secret", "JWT_SECRET"},
+	{"email.smtp.password", "SMTP_PASSWORD"},
+	{"auth.oauth.google.clientSecret", "GOOGLE_CLIENT_SECRET"},
+	{"auth.oauth.github.clientSecret", "GITHUB_CLIENT_SECRET"},
+	{"entitlements.webhookSecret", "ENTITLEMENTS_WEBHOOK_SECRET"},
+	{"notification.push.vapid_public_key", "VAPID_PUBLIC_KEY"},
+	{"notification.push.vapid_private_key", "VAPID_PRIVATE_KEY"},
+	{"federation.privateKey", "FEDERATION_PRIVATE_KEY"},
+
+	// Only credentials come from the environment; region and bucket come from the config file
+	{"storage.s3.accessKeyId", "S3_ACCESS_KEY_ID"},
+	{"storage.s3.secretAccessKey", "S3_SECRET_ACCESS_KEY"},
+	{"storage.cdn.cloudfront.privateKey", "CLOUDFRONT_PRIVATE_KEY"},
+	{"storage.cdn.fastly.tokenSecret", "FASTLY_TOKEN_SECRET"},
+	{"storage.cdn.fastly.apiToken", "FASTLY_API_TOKEN"},
+}
+
+// Default returns the configuration used for any setting that is missing
+// from the config file and the environment
+func Default() *Config {
+	logging := logger.DefaultConfig()
+
+	return &Config{
+		Environment: "development",
+		Server: ServerConfig{
+			Port:     8080,
+			BasePath: "/api/v1",
+			HandlerTimeouts: map[string]time.Duration{
+				httpHandler.DefaultRouteTimeout: 30 * time.Second,
+				// Uploads are transcoded within the request, bounded by the FFmpeg deadlines instead
+				"post /video/upload": 0,
+				// Streams last as long as the client keeps reading
+				"get /video/:id/stream/:resolution": 0,
+				// Upload event streams last until processing ends
+				"get /video/:id/events": 0,
+			},
+			DrainTimeout:   20 * time.Second,
+			TrustedProxies: []string{},
+		},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			User:     "root",
+			Dbname:   "pavilion_db",
+			Port:     26257,
+			Sslmode:  "disable",
+			Timezone: "UTC",
+			Pool: DatabasePoolConfig{
+				MaxOpen:         100,
+				MaxIdle:         10,
+				ConnMaxLifetime: time.Hour,
+			},
+		},
+		Redis: RedisConfig{
+			Addr: "localhost:6379",
+			DB:   0,
+		},
+		Storage: StorageConfig{
+			UploadDir: "uploads",
+			TempDir:   "temp",
+			Backend:   StorageBackendS3,
+			Local: LocalStorageConfig{
+				Dir:     "storage",
+				BaseURL: "http://localhost:8080/storage",
+			},
+			IPFS: IPFSConfig{
+				APIAddress: "/ip4/127.0.0.1/tcp/5001",
+				Gateway:    "http://localhost:8080",
+				Peers:      []string{},
+				Replication: IPFSReplicationConfig{
+					Workers:        2,
+					QueueSize:      100,
+					MaxRetries:     3,
+					RetryDelay:     5 * time.Second,
+					RescanInterval: 5 * time.Minute,
+					StaleAfter:     time.Hour,
+				},
+			},
+			S3: S3Config{
+				UseSSL: true,
+				Bootstrap: S3BootstrapConfig{
+					CORSAllowedOrigins:        []string{"http://localhost:3000"},
+					AbortIncompleteUploadDays: 7,
+				},
+				Multipart: S3MultipartConfig{
+					PartSizeMB:  16,
+					Concurrency: 4,
+				},
+			},
+			CDN: CDNConfig{
+				Provider: CDNProviderCloudFront,
+				URLTTL:   6 * time.Hour,
+			},
+		},
+		Logging: LoggingConfig{
+			Level:       string(logging.Level),
+			Format:      logging.Format,
+			Output:      logging.Output,
+			Development: logging.Development,
+			File:        logging.File,
+			Sampling:    logging.Sampling,
+		},
+		Ffmpeg: video.FfmpegConfig{
+			Path:          "ffmpeg",
+			ProbePath:     "ffprobe",
+			VideoCodec:    "libx264",
+			AudioCodec:    "aac",
+			Preset:        "fast",
+			OutputPath:    "/tmp/videos",
+			Resolutions:   []string{"720p", "480p", "360p"},
+			TimeoutFactor: 3,
+			MinTimeout:    time.Minute,
+			ProbeTimeout:  30 * time.Second,
+			HWAccel:       ffmpeg.HWAccelNone,
+			VAAPIDevice:   ffmpeg.DefaultVAAPIDevice,
+			Profiles: map[string]ffmpeg.Profile{
+				"720p": {CRF: 23, MaxBitrate: "3000k", AudioBitrate: "128k"},
+				"480p": {CRF: 23, MaxBitrate: "1500k", AudioBitrate: "128k"},
+				"360p": {CRF: 23, MaxBitrate: "800k", AudioBitrate: "96k"},
+			},
+			Audio: ffmpeg.AudioOutput{
+				Enabled: true,
+				Codec:   ffmpeg.AudioCodecAAC,
+				Bitrate: "64k",
+			},
+			Preview: ffmpeg.PreviewOutput{
+				Enabled: true,
+				Seconds: 4,
+				Width:   320,
+				FPS:     10,
+				Quality: 60,
+			},
+		},
+		Video: VideoConfig{
+			MaxSize:        1024 * 1024 * 1024, // 1GB
+			MinTitleLength: 3,
+			MaxTitleLength: 100,
+			MaxDescLength:  5000,
+			AllowedFormats: []string{".mp4", ".mov", ".avi"},
+			MaxDuration:    3 * time.Hour,
+			AllowedVideoCodecs: []string{
+				"h264", "hevc", "vp8", "vp9", "av1", "mpeg4", "mpeg2video", "prores",
+			},
+			Cleanup: VideoCleanupConfig{
+				Enabled:              true,
+				Interval:             time.Hour,
+				DeletedRetentionDays: 30,
+				FailedRetention:      24 * time.Hour,
+				VersionRetentionDays: 30,
+				BatchSize:            100,
+			},
+			Tiering: VideoTieringConfig{
+				Interval:           24 * time.Hour,
+				StorageClass:       video.StorageClassStandardIA,
+				OriginalsAfterDays: 90,
+				RenditionsIdleDays: 180,
+				BatchSize:          100,
+			},
+			Transcode: VideoTranscodeConfig{
+				Workers:    2,
+				MaxBacklog: 50,
+				RetryAfter: 30 * time.Second,
+			},
+			Scan: VideoScanConfig{
+				Provider: scan.ProviderClamAV,
+				Action:   string(video.ScanActionQuarantine),
+				Timeout:  2 * time.Minute,
+				ClamAV: ClamAVConfig{
+					Address: "localhost:3310",
+				},
+			},
+			CommentCounts: VideoCommentCounts{
+				Enabled:           true,
+				Subscription:      "video-comment-counts",
+				ReconcileInterval: 6 * time.Hour,
+				BatchSize:         100,
+			},
+			DeletionCleanup: VideoDeletionCleanup{
+				Enabled:      true,
+				Subscription: "video-deletion-cleanup",
+			},
+			Temp: VideoTempConfig{
+				Dir:        "/tmp/videos",
+				StaleAfter: 24 * time.Hour,
+				MaxBytes:   10 * 1024 * 1024 * 1024, // 10GB
+			},
+		},
+		Comments: CommentsConfig{
+			MaxLength:    5000,
+			Markdown:     true,
+			BannedWords:  []string{},
+			FilterAction: "reject",
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				AccessTokenTTL:  time.Hour,
+				RefreshTokenTTL: 7 * 24 * time.Hour,
+			},
+			PasswordReset: PasswordResetConfig{
+				TokenTTL: time.Hour,
+				URL:      "http://localhost:3000/reset-password",
+			},
+			EmailVerification: EmailVerificationConfig{
+				TokenTTL:       24 * time.Hour,
+				URL:            "http://localhost:8080/api/v1/auth/verify-email",
+				ResendCooldown: time.Minute,
+			},
+			Lockout: LockoutConfig{
+				MaxAttempts: 5,
+				Duration:    15 * time.Minute,
+			},
+			ActivityRetention: 90 * 24 * time.Hour,
+			OAuth: map[string]OAuthProviderConfig{
+				"google": {RedirectURL: "http://localhost:8080/api/v1/auth/oauth/google/callback"},
+				"github": {RedirectURL: "http://localhost:8080/api/v1/auth/oauth/github/callback"},
+			},
+			AdminEmails: []string{},
+		},
+		ScyllaDB: ScyllaDBConfig{
+			Hosts:       []string{"localhost"},
+			Port:        9042,
+			Keyspace:    "pavilion_db",
+			Consistency: "quorum",
+			Replication: ScyllaReplicationConfig{
+				Class:             "SimpleStrategy",
+				ReplicationFactor: 3,
+			},
+			Timeout:        5 * time.Second,
+			ConnectTimeout: 10 * time.Second,
+		},
+		Pulsar: PulsarConfig{
+			URL:               "pulsar://localhost:6650",
+			WebServiceURL:     "http://localhost:8083",
+			OperationTimeout:  30 * time.Second,
+			ConnectionTimeout: 30 * time.Second,
+			Namespace:         "pavilion/notifications",
+		},
+		Notification: NotificationConfig{
+			Enabled:              true,
+			VideoEventsTopic:     "persistent://pavilion/notifications/video-events",
+			CommentEventsTopic:   "persistent://pavilion/notifications/comment-events",
+			UserEventsTopic:      "persistent://pavilion/notifications/user-events",
+			DeadLetterTopic:      "persistent://pavilion/notifications/dead-letter",
+			RetryQueueTopic:      "persistent://pavilion/notifications/retry-queue",
+			RetentionTimeHours:   48,
+			DeduplicationEnabled: true,
+			DeduplicationWindow:  2 * time.Hour,
+			RetryEnabled:         true,
+			MaxRetries:           5,
+			BackoffInitial:       time.Second,
+			BackoffMax:           time.Minute,
+			BackoffMultiplier:    2.0,
+			LagPollInterval:      30 * time.Second,
+			AggregationWindow:    time.Hour,
+			UnreadCountTTL:       10 * time.Minute,
+			FollowerSubscription: "follower-fanout",
+			Push: PushConfig{
+				TTL:     24 * time.Hour,
+				Timeout: 30 * time.Second,
+			},
+			Digest: DigestConfig{
+				Interval: 15 * time.Minute,
+				MaxItems: 20,
+				URL:      "http://localhost:3000/notifications",
+			},
+		},
+		Entitlements: EntitlementsConfig{
+			WebhookTolerance: 5 * time.Minute,
+		},
+		Email: EmailConfig{
+			From: "Pavilion <noreply@pavilion.local>",
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+		RateLimit: httpHandler.RateLimitConfig{
+			Enabled: true,
+			Rules: map[string]httpHandler.RateLimitRule{
+				httpHandler.DefaultRateLimitRule: {Requests: 300, Period: time.Minute},
+				"login":                          {Requests: 5, Period: time.Minute},
+				"register":                       {Requests: 10, Period: time.Hour},
+				"recovery":                       {Requests: 5, Period: time.Hour},
+				"upload":                         {Requests: 10, Period: time.Hour},
+			},
+		},
+		HTTPCache: httpHandler.CacheConfig{
+			Enabled: true,
+			TTL:     30 * time.Second,
+		},
+		Idempotency: httpHandler.IdempotencyConfig{
+			Enabled: true,
+			TTL:     24 * time.Hour,
+		},
+		CORS: httpHandler.CORSConfig{
+			AllowedOrigins:   []string{"http://localhost:3000"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept", "Cache-Control", "X-Requested-With", "X-CSRF-Token", "If-None-Match", "Range", "Idempotency-Key"},
+			ExposedHeaders:   []string{"ETag", "X-Cache", "Retry-After", "Content-Range", "Accept-Ranges", "Idempotent-Replayed"},
+			AllowCredentials: true,
+			MaxAge:           12 * time.Hour,
+		},
+		Security: httpHandler.SecurityHeadersConfig{
+			HSTSMaxAge: 365 * 24 * time.Hour,
+			SwaggerCSP: "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:",
+		},
+		ReadCache: ReadCacheConfig{
+			Enabled:         true,
+			VideoTTL:        time.Minute,
+			CommentCountTTL: 5 * time.Minute,
+			RelatedTTL:      10 * time.Minute,
+		},
+		AccessLog: AccessLogConfig{
+			Enabled:       true,
+			RetentionDays: 90,
+			CountryHeader: "CF-IPCountry",
+			PruneInterval: time.Hour,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:       true,
+			MaxPerUser:    10,
+			Workers:       4,
+			MaxAttempts:   8,
+			RetryDelay:    30 * time.Second,
+			MaxRetryDelay: time.Hour,
+			Timeout:       10 * time.Second,
+			PollInterval:  5 * time.Second,
+		},
+		Jobs: JobsConfig{
+			Enabled:       true,
+			Workers:       4,
+			PollInterval:  5 * time.Second,
+			MaxAttempts:   5,
+			RetryDelay:    30 * time.Second,
+			MaxRetryDelay: time.Hour,
+			Timeout:       time.Hour,
+			Retention:     7 * 24 * time.Hour,
+		},
+		Health: HealthConfig{
+			Timeout: 2 * time.Second,
+		},
+		GraphQL: GraphQLConfig{
+			Enabled:       true,
+			MaxDepth:      8,
+			MaxComplexity: 1000,
+			BatchWait:     2 * time.Millisecond,
+		},
+		Tracing: tracing.Config{
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			ServiceName: "pavilion-backend",
+			SampleRatio: 1,
+		},
+		P2P: P2PConfig{
+			Topic:          "pavilion/videos/v1",
+			ProvideTimeout: time.Minute,
+		},
+		Federation: FederationConfig{
+			Name:            "Pavilion",
+			PollInterval:    5 * time.Minute,
+			PageSize:        100,
+			MaxPagesPerPoll: 10,
+			Timeout:         10 * time.Second,
+		},
+		ActivityPub: ActivityPubConfig{
+			MaxClockSkew: time.Hour,
+			Timeout:      10 * time.Second,
+			Workers:      4,
+			QueueSize:    1000,
+			MaxAttempts:  5,
+			RetryDelay:   time.Minute,
+		},
+		Feeds: FeedsConfig{
+			Title:  "Pavilion",
+			Items:  50,
+			MaxAge: 5 * time.Minute,
+		},
+		Sharing: SharingConfig{
+			ProviderName: "Pavilion",
+			EmbedWidth:   640,
+			EmbedHeight:  360,
+			SitemapSize:  50000,
+			MaxAge:       time.Hour,
+		},
+		Embed: EmbedConfig{
+			MaxDomains: 50,
+		},
+		Live: LiveConfig{
+			ListenAddr:      ":1935",
+			IngestURL:       "rtmp://localhost:1935/live",
+			PublicURL:       "http://localhost:8080/api/v1",
+			OutputDir:       "temp/live",
+			SegmentDuration: time.Second,
+			PlaylistSize:    6,
+			Record:          true,
+			MaxKeys:         10,
+		},
+	}
+}
+
+// setDefaults registers every non-zero value of Default with v
+func setDefaults(v *viper.Viper) {
+	walkSettings(reflect.ValueOf(Default()).Elem(), "", func(key string, value reflect.Value, _ reflect.StructField) {
+		if !value.IsZero() {
+			v.SetDefault(key, value.Interface())
+		}
+	})
+}
+
+// settingKey returns the configuration key of a struct field: its
+// mapstructure tag, or the field name with a lowercase first letter, which is
+// what viper matches untagged fields against
+func settingKey(field reflect.StructField) string {
+	if tag := strings.Split(field.Tag.Get("mapstructure"), ",")[0]; tag != "" {
+		return tag
+	}
+	return strings.ToLower(field.Name[:1]) + field.Name[1:]
+}
+
+// walkSettings calls visit for every leaf setting below v in declaration
+// order. Structs and string-keyed maps are descended into; everything else,
+// including slices and durations, is a leaf.
+func walkSettings(v reflect.Value, prefix string, visit func(key string, value reflect.Value, field reflect.StructField)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := prefix + settingKey(field)
+		value := v.Field(i)
+
+		switch {
+		case value.Kind() == reflect.Struct:
+			walkSettings(value, key+".", visit)
+		case value.Kind() == reflect.Map && value.Type().Elem().Kind() == reflect.Struct:
+			for _, name := range sortedKeys(value) {
+				walkSettings(value.MapIndex(reflect.ValueOf(name)), key+"."+name+".", visit)
+			}
+		default:
+			visit(key, value, field)
+		}
+	}
+}
+
+// sortedKeys returns the keys of a string-keyed map in a stable order
+func sortedKeys(m reflect.Value) []string {
+	keys := make([]string, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
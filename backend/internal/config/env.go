@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envReference matches ${VAR} and ${VAR:-default} in a config file
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces ${VAR} references in a config file with the values of
+// environment variables, and ${VAR:-default} with the default when VAR is
+// unset or empty. A ${VAR} whose variable is unset is an error, so a missing
+// secret fails loading instead of leaving the setting empty.
+func expandEnv(data []byte) ([]byte, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		match := envReference.FindSubmatch(ref)
+		name, hasDefault := string(match[1]), match[2] != nil
+		if value := os.Getenv(name); value != "" {
+			return []byte(value)
+		}
+		if hasDefault {
+			return match[3]
+		}
+		if _, set := os.LookupEnv(name); !set {
+			missing = append(missing, name)
+		}
+		return nil
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("unset environment variables referenced: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
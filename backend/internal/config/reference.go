@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// referenceHeader is written at the top of the generated reference config
+const referenceHeader = `# Pavilion backend reference configuration.
+#
+# Generated from the defaults in internal/config by "make config-reference";
+# do not edit by hand. Every setting is listed with its default value. Copy the
+# settings you need into config.yaml; anything left out keeps its default.
+
+`
+
+// WriteReference writes a YAML config file listing every setting with its
+// default value, its doc tag and the environment variable that overrides it
+func WriteReference(w io.Writer) error {
+	envByKey := make(map[string]string, len(envBindings))
+	for _, binding := range envBindings {
+		envByKey[binding.Key] = binding.Env
+	}
+
+	out := bufio.NewWriter(w)
+	out.WriteString(referenceHeader)
+	writeReferenceSection(out, reflect.ValueOf(Default()).Elem(), "", 0, envByKey)
+	return out.Flush()
+}
+
+// writeReferenceSection writes the fields of a config struct at the given indentation
+func writeReferenceSection(out *bufio.Writer, v reflect.Value, prefix string, depth int, envByKey map[string]string) {
+	indent := strings.Repeat("  ", depth)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := settingKey(field)
+		key := prefix + name
+		value := v.Field(i)
+
+		// Separate top-level sections for readability
+		if depth == 0 && i > 0 {
+			out.WriteString("\n")
+		}
+		if doc := field.Tag.Get("doc"); doc != "" {
+			fmt.Fprintf(out, "%s# %s\n", indent, doc)
+		}
+
+		switch {
+		case value.Kind() == reflect.Struct:
+			fmt.Fprintf(out, "%s%s:\n", indent, name)
+			writeReferenceSection(out, value, key+".", depth+1, envByKey)
+		case value.Kind() == reflect.Map && value.Type().Elem().Kind() == reflect.Struct:
+			fmt.Fprintf(out, "%s%s:\n", indent, name)
+			for _, entry := range sortedKeys(value) {
+				fmt.Fprintf(out, "%s  %s:\n", indent, entry)
+				writeReferenceSection(out, value.MapIndex(reflect.ValueOf(entry)), key+"."+entry+".", depth+2, envByKey)
+			}
+		case value.Kind() == reflect.Map && value.Len() > 0:
+			fmt.Fprintf(out, "%s%s:\n", indent, name)
+			for _, entry := range sortedKeys(value) {
+				fmt.Fprintf(out, "%s  %s: %s\n", indent, strconv.Quote(entry), formatReferenceValue(value.MapIndex(reflect.ValueOf(entry))))
+			}
+		default:
+			line := fmt.Sprintf("%s%s: %s", indent, name, formatReferenceValue(value))
+			if env, ok := envByKey[key]; ok {
+				line += "  # env: " + env
+			}
+			out.WriteString(line + "\n")
+		}
+	}
+}
+
+// formatReferenceValue renders a leaf setting as a YAML scalar or flow sequence
+func formatReferenceValue(v reflect.Value) string {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return formatDuration(d)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatReferenceValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
+
+// formatDuration renders a duration the way it is usually written in config
+// files, e.g. 1h instead of 1h0m0s
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
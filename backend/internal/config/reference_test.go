@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestReferenceMatchesDefaults(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteReference(&buf); err != nil {
+		t.Fatalf("Failed to write reference config: %v", err)
+	}
+
+	// Reading the reference back must reproduce the defaults exactly
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(&buf); err != nil {
+		t.Fatalf("Reference config is not valid YAML: %v", err)
+	}
+
+	var got Config
+	if err := v.Unmarshal(&got); err != nil {
+		t.Fatalf("Failed to unmarshal reference config: %v", err)
+	}
+
+	if want := Default(); !reflect.DeepEqual(&got, want) {
+		t.Errorf("Reference config does not round-trip to the defaults\ngot:  %+v\nwant: %+v", got, *want)
+	}
+}
+
+func TestEnvBindingsMatchSettings(t *testing.T) {
+	keys := make(map[string]bool)
+	walkSettings(reflect.ValueOf(Default()).Elem(), "", func(key string, _ reflect.Value, _ reflect.StructField) {
+		keys[key] = true
+	})
+
+	for _, binding := range envBindings {
+		if !keys[binding.Key] {
+			t.Errorf("%s is bound to unknown setting %q", binding.Env, binding.Key)
+		}
+	}
+}
+
+func TestReferenceFileUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("../../config.reference.yaml")
+	if err != nil {
+		t.Fatalf("Failed to read config.reference.yaml: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReference(&buf); err != nil {
+		t.Fatalf("Failed to write reference config: %v", err)
+	}
+
+	if !bytes.Equal(committed, buf.Bytes()) {
+		t.Error("config.reference.yaml is out of date; run make config-reference")
+	}
+}
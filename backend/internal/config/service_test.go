@@ -56,6 +56,9 @@ func TestLoadConfig(t *testing.T) {
 			// Set environment
 			os.Setenv("ENV", tt.env)
 			defer os.Unsetenv("ENV")
+			// config.yaml uses the s3 backend, whose credentials only come from the environment
+			t.Setenv("S3_ACCESS_KEY_ID", "test-access-key")
+			t.Setenv("S3_SECRET_ACCESS_KEY", "test-secret-key")
 
 			// Load configuration
 			cfg, err := configService.Load("../..")
@@ -3,60 +3,352 @@ package config
 import (
 	"time"
 
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 )
 
-// Config represents the application configuration
+// Config represents the application configuration.
+//
+// Every setting is read with the key in its mapstructure tag. The doc tags
+// are written into the generated reference config (see WriteReference), so
+// keep them short and user-facing.
 type Config struct {
-	Environment string             `mapstructure:"environment" yaml:"environment"`
-	Server      ServerConfig       `yaml:"server"`
-	Database    DatabaseConfig     `yaml:"database"`
-	Redis       RedisConfig        `yaml:"redis"`
-	Storage     StorageConfig      `yaml:"storage"`
-	Logging     LoggingConfig      `yaml:"logging"`
-	Ffmpeg      video.FfmpegConfig `yaml:"ffmpeg"`
-	Video       VideoConfig        `yaml:"video"`
-	Auth        AuthConfig         `yaml:"auth"`
-	ScyllaDB    ScyllaDBConfig     `yaml:"scylladb"`
-	Pulsar      PulsarConfig       `yaml:"pulsar"`
-	Notification NotificationConfig `yaml:"notification"`
+	Environment  string                            `mapstructure:"environment" yaml:"environment" doc:"Deployment environment: development, test or production"`
+	Server       ServerConfig                      `mapstructure:"server" yaml:"server"`
+	Database     DatabaseConfig                    `mapstructure:"database" yaml:"database"`
+	Redis        RedisConfig                       `mapstructure:"redis" yaml:"redis"`
+	Storage      StorageConfig                     `mapstructure:"storage" yaml:"storage"`
+	Logging      LoggingConfig                     `mapstructure:"logging" yaml:"logging"`
+	Ffmpeg       video.FfmpegConfig                `mapstructure:"ffmpeg" yaml:"ffmpeg"`
+	Video        VideoConfig                       `mapstructure:"video" yaml:"video"`
+	Comments     CommentsConfig                    `mapstructure:"comments" yaml:"comments"`
+	Auth         AuthConfig                        `mapstructure:"auth" yaml:"auth"`
+	ScyllaDB     ScyllaDBConfig                    `mapstructure:"scylladb" yaml:"scylladb"`
+	Pulsar       PulsarConfig                      `mapstructure:"pulsar" yaml:"pulsar"`
+	Notification NotificationConfig                `mapstructure:"notification" yaml:"notification"`
+	Email        EmailConfig                       `mapstructure:"email" yaml:"email"`
+	Entitlements EntitlementsConfig                `mapstructure:"entitlements" yaml:"entitlements"`
+	RateLimit    httpHandler.RateLimitConfig       `mapstructure:"rateLimit" yaml:"rateLimit"`
+	HTTPCache    httpHandler.CacheConfig           `mapstructure:"httpCache" yaml:"httpCache"`
+	Idempotency  httpHandler.IdempotencyConfig     `mapstructure:"idempotency" yaml:"idempotency"`
+	CORS         httpHandler.CORSConfig            `mapstructure:"cors" yaml:"cors"`
+	Security     httpHandler.SecurityHeadersConfig `mapstructure:"securityHeaders" yaml:"securityHeaders"`
+	ReadCache    ReadCacheConfig                   `mapstructure:"readCache" yaml:"readCache"`
+	AccessLog    AccessLogConfig                   `mapstructure:"accessLog" yaml:"accessLog"`
+	Webhooks     WebhooksConfig                    `mapstructure:"webhooks" yaml:"webhooks"`
+	Health       HealthConfig                      `mapstructure:"health" yaml:"health"`
+	GraphQL      GraphQLConfig                     `mapstructure:"graphql" yaml:"graphql"`
+	Tracing      tracing.Config                    `mapstructure:"tracing" yaml:"tracing"`
+	P2P          P2PConfig                         `mapstructure:"p2p" yaml:"p2p"`
+	Federation   FederationConfig                  `mapstructure:"federation" yaml:"federation"`
+	ActivityPub  ActivityPubConfig                 `mapstructure:"activityPub" yaml:"activityPub"`
+	Feeds        FeedsConfig                       `mapstructure:"feeds" yaml:"feeds"`
+	Sharing      SharingConfig                     `mapstructure:"sharing" yaml:"sharing"`
+	Embed        EmbedConfig                       `mapstructure:"embed" yaml:"embed"`
+	GeoIP        GeoIPConfig                       `mapstructure:"geoip" yaml:"geoip"`
+	Live         LiveConfig                        `mapstructure:"live" yaml:"live"`
+	Jobs         JobsConfig                        `mapstructure:"jobs" yaml:"jobs"`
 }
 
 // AuthConfig represents authentication configuration settings
 type AuthConfig struct {
-	JWT struct {
-		Secret          string        `mapstructure:"secret"`
-		AccessTokenTTL  time.Duration `mapstructure:"accessTokenTTL"`
-		RefreshTokenTTL time.Duration `mapstructure:"refreshTokenTTL"`
-	} `mapstructure:"jwt"`
+	JWT               JWTConfig               `mapstructure:"jwt"`
+	PasswordReset     PasswordResetConfig     `mapstructure:"passwordReset"`
+	EmailVerification EmailVerificationConfig `mapstructure:"emailVerification"`
+	Lockout           LockoutConfig           `mapstructure:"lockout"`
+	// ActivityRetention bounds the account activity kept for each user
+	ActivityRetention time.Duration `mapstructure:"activityRetention" doc:"How long logins, token refreshes, logouts and password changes are kept for users to review"`
+	// OAuth maps a provider name ("google" or "github") to its client settings
+	OAuth map[string]OAuthProviderConfig `mapstructure:"oauth" doc:"Social login providers keyed by name (google, github); a provider is enabled when it has a clientId"`
+	// AdminEmails are granted the admin role at startup so a fresh install has an admin
+	AdminEmails []string `mapstructure:"adminEmails" doc:"Accounts promoted to admin at startup"`
+}
+
+// JWTConfig represents access and refresh token settings
+type JWTConfig struct {
+	Secret          string        `mapstructure:"secret" doc:"HMAC key used to sign tokens"`
+	AccessTokenTTL  time.Duration `mapstructure:"accessTokenTTL" doc:"Lifetime of access tokens"`
+	RefreshTokenTTL time.Duration `mapstructure:"refreshTokenTTL" doc:"Lifetime of refresh tokens"`
+}
+
+// PasswordResetConfig represents password reset email settings
+type PasswordResetConfig struct {
+	TokenTTL time.Duration `mapstructure:"tokenTTL" doc:"Lifetime of password reset links"`
+	URL      string        `mapstructure:"url" doc:"Frontend page that accepts ?token="`
+}
+
+// EmailVerificationConfig represents email verification settings
+type EmailVerificationConfig struct {
+	TokenTTL       time.Duration `mapstructure:"tokenTTL" doc:"Lifetime of verification links"`
+	URL            string        `mapstructure:"url" doc:"Verification endpoint linked from the email"`
+	ResendCooldown time.Duration `mapstructure:"resendCooldown" doc:"Minimum time between verification emails"`
+}
+
+// LockoutConfig represents account lockout settings for repeated failed logins
+type LockoutConfig struct {
+	MaxAttempts int           `mapstructure:"maxAttempts" doc:"Consecutive failed logins that lock an account; 0 disables lockout"`
+	Duration    time.Duration `mapstructure:"duration" doc:"How long a locked account stays locked"`
+}
+
+// OAuthProviderConfig represents an OAuth2 social login provider
+type OAuthProviderConfig struct {
+	ClientID     string `mapstructure:"clientId"`
+	ClientSecret string `mapstructure:"clientSecret"`
+	RedirectURL  string `mapstructure:"redirectUrl" doc:"Must point at {server.basePath}/auth/oauth/{provider}/callback"`
+	AuthURL      string `mapstructure:"authUrl" doc:"Endpoint override, e.g. for GitHub Enterprise; the provider default is used when empty"`
+	TokenURL     string `mapstructure:"tokenUrl" doc:"Endpoint override, e.g. for GitHub Enterprise; the provider default is used when empty"`
+	UserInfoURL  string `mapstructure:"userInfoUrl" doc:"Endpoint override, e.g. for GitHub Enterprise; the provider default is used when empty"`
+}
+
+// EmailConfig represents outgoing email settings
+type EmailConfig struct {
+	From string     `mapstructure:"from" doc:"Sender address of outgoing emails"`
+	SMTP SMTPConfig `mapstructure:"smtp"`
+}
+
+// SMTPConfig represents the SMTP relay used to send email
+type SMTPConfig struct {
+	Host     string `mapstructure:"host" doc:"SMTP server; emails are logged instead of sent when empty"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// EntitlementsConfig represents settings for paid or time-limited video access
+type EntitlementsConfig struct {
+	WebhookSecret    string        `mapstructure:"webhookSecret" doc:"Signs grant/revoke webhooks from payment systems; the webhook is disabled when empty"`
+	WebhookTolerance time.Duration `mapstructure:"webhookTolerance" doc:"How far a webhook's signed timestamp may be from the server's clock; older events are rejected as replays"`
+}
+
+// AccessLogConfig represents settings for the per-video access logs shown to owners
+type AccessLogConfig struct {
+	Enabled       bool          `mapstructure:"enabled" doc:"Record playback starts for owners' access logs"`
+	RetentionDays int           `mapstructure:"retentionDays" doc:"Days an access log entry is kept"`
+	CountryHeader string        `mapstructure:"countryHeader" doc:"Request header with the viewer's country code, set by the CDN or proxy; empty to record no country"`
+	PruneInterval time.Duration `mapstructure:"pruneInterval" doc:"How often expired entries are deleted"`
+}
+
+// WebhooksConfig represents settings for user-registered webhooks and their delivery
+type WebhooksConfig struct {
+	Enabled              bool          `mapstructure:"enabled" doc:"Let users register webhooks and deliver events to them"`
+	MaxPerUser           int           `mapstructure:"maxPerUser" doc:"Webhooks a user can register"`
+	Workers              int           `mapstructure:"workers" doc:"Deliveries sent concurrently"`
+	MaxAttempts          int           `mapstructure:"maxAttempts" doc:"Attempts before a delivery is marked failed"`
+	RetryDelay           time.Duration `mapstructure:"retryDelay" doc:"Wait before the first retry; doubles with each further attempt"`
+	MaxRetryDelay        time.Duration `mapstructure:"maxRetryDelay" doc:"Longest wait between attempts"`
+	Timeout              time.Duration `mapstructure:"timeout" doc:"Time a webhook has to respond"`
+	PollInterval         time.Duration `mapstructure:"pollInterval" doc:"How often due retries are picked up"`
+	AllowPrivateNetworks bool          `mapstructure:"allowPrivateNetworks" doc:"Allow webhook URLs on loopback and private addresses; for development only"`
+}
+
+// JobsConfig represents settings for the background job runner
+type JobsConfig struct {
+	Enabled       bool          `mapstructure:"enabled" doc:"Run queued and scheduled background jobs on this instance; instances share the queue"`
+	Workers       int           `mapstructure:"workers" doc:"Jobs run concurrently on this instance"`
+	PollInterval  time.Duration `mapstructure:"pollInterval" doc:"How often due jobs and schedules are picked up; running jobs report they are alive as often and are requeued after four missed reports"`
+	MaxAttempts   int           `mapstructure:"maxAttempts" doc:"Attempts before a job is marked failed"`
+	RetryDelay    time.Duration `mapstructure:"retryDelay" doc:"Wait before the first retry; doubles with each further attempt"`
+	MaxRetryDelay time.Duration `mapstructure:"maxRetryDelay" doc:"Longest wait between attempts"`
+	Timeout       time.Duration `mapstructure:"timeout" doc:"Time each attempt may take before it is interrupted"`
+	Retention     time.Duration `mapstructure:"retention" doc:"How long finished jobs are kept; 0 keeps them"`
+}
+
+// HealthConfig represents settings for the readiness checks
+type HealthConfig struct {
+	Timeout time.Duration `mapstructure:"timeout" doc:"How long each dependency check in /health/ready may take before the dependency is reported down"`
+}
+
+// GraphQLConfig represents the GraphQL endpoint the frontend reads pages through
+type GraphQLConfig struct {
+	Enabled       bool          `mapstructure:"enabled" doc:"Serve GraphQL queries at {server.basePath}/graphql"`
+	MaxDepth      int           `mapstructure:"maxDepth" doc:"Deepest field nesting a query may have"`
+	MaxComplexity int           `mapstructure:"maxComplexity" doc:"Highest cost a query may have: each field costs 1, and the fields under a list cost its limit argument times over"`
+	BatchWait     time.Duration `mapstructure:"batchWait" doc:"How long lookups of comment counts and users wait to be batched with others of the same query"`
+}
+
+// P2PConfig controls peer-assisted delivery through the libp2p host of the
+// IPFS node at storage.ipfs.apiAddress
+type P2PConfig struct {
+	Enabled bool   `mapstructure:"enabled" doc:"Announce replicated videos on the DHT and gossip them to peer Pavilion nodes; the IPFS node must run with pubsub enabled"`
+	Topic   string `mapstructure:"topic" doc:"Pubsub topic video announcements are gossiped on; nodes only hear peers on the same topic"`
+	// PublicURL lets peers that receive an announcement reach this node's API
+	PublicURL      string        `mapstructure:"publicUrl" doc:"Base URL of this node's API, e.g. https://pavilion.example.com/api/v1, sent with announcements"`
+	ProvideTimeout time.Duration `mapstructure:"provideTimeout" doc:"How long announcing one CID on the DHT may take"`
+}
+
+// FederationConfig controls subscriptions to remote Pavilion instances and
+// the signed outbox they pull from this one
+type FederationConfig struct {
+	Enabled bool   `mapstructure:"enabled" doc:"Serve the signed federation outbox and pull the public videos of registered remote instances into listings"`
+	Name    string `mapstructure:"name" doc:"Instance name shown to subscribing instances"`
+	// PrivateKey signs the outbox; subscribers pin its public key when they register this instance
+	PrivateKey      string        `mapstructure:"privateKey" doc:"Base64 32-byte Ed25519 seed the outbox is signed with, e.g. from openssl rand -base64 32; changing it breaks existing subscriptions"`
+	PollInterval    time.Duration `mapstructure:"pollInterval" doc:"How often the outbox of every registered instance is pulled"`
+	PageSize        int           `mapstructure:"pageSize" doc:"Videos requested per outbox page, at most 500"`
+	MaxPagesPerPoll int           `mapstructure:"maxPagesPerPoll" doc:"Outbox pages pulled from one instance per poll; the rest follow on the next"`
+	Timeout         time.Duration `mapstructure:"timeout" doc:"Deadline of each request to a remote instance"`
+}
+
+// ActivityPubConfig controls publishing channels to the Fediverse, where
+// platforms like Mastodon and PeerTube can follow them
+type ActivityPubConfig struct {
+	Enabled bool `mapstructure:"enabled" doc:"Serve an ActivityPub actor, outbox and inbox per channel, and WebFinger at /.well-known/webfinger"`
+	// PublicURL is the base of every actor ID, so it must not change once channels have followers
+	PublicURL    string        `mapstructure:"publicUrl" doc:"Public base URL of the API, e.g. https://pavilion.example.com/api/v1; its host is the domain of channel handles"`
+	WatchURL     string        `mapstructure:"watchUrl" doc:"Page videos are watched on, with {id} replaced by the video ID, e.g. https://pavilion.example.com/watch/{id}; linked from published videos"`
+	MaxClockSkew time.Duration `mapstructure:"maxClockSkew" doc:"How far the Date of a signed inbox delivery may be from now"`
+	Timeout      time.Duration `mapstructure:"timeout" doc:"Deadline of each request fetching a remote actor or delivering an activity"`
+	Workers      int           `mapstructure:"workers" doc:"Activities delivered concurrently"`
+	QueueSize    int           `mapstructure:"queueSize" doc:"Activities waiting for delivery before further ones are dropped"`
+	MaxAttempts  int           `mapstructure:"maxAttempts" doc:"Delivery attempts before an activity is dropped"`
+	RetryDelay   time.Duration `mapstructure:"retryDelay" doc:"Wait before the first retry; doubles with every further attempt"`
+	// AllowPrivateNetworks is only for development, since any server can point its actor at internal services
+	AllowPrivateNetworks bool `mapstructure:"allowPrivateNetworks" doc:"Allow actors and inboxes on loopback and private addresses"`
+}
+
+// FeedsConfig controls the public RSS feeds of channels and of the latest videos
+type FeedsConfig struct {
+	Enabled bool   `mapstructure:"enabled" doc:"Serve MRSS feeds at /users/{id}/feed.rss and /feeds/latest"`
+	Title   string `mapstructure:"title" doc:"Title of the latest videos feed, and suffix of channel feed titles"`
+	SiteURL string `mapstructure:"siteUrl" doc:"Home page feeds link to, e.g. https://pavilion.example.com; feeds link to themselves when empty"`
+	// WatchURL is the same kind of template as activityPub.watchUrl
+	WatchURL string        `mapstructure:"watchUrl" doc:"Page videos are watched on, with {id} replaced by the video ID, e.g. https://pavilion.example.com/watch/{id}"`
+	Items    int           `mapstructure:"items" doc:"Newest videos listed in each feed"`
+	MaxAge   time.Duration `mapstructure:"maxAge" doc:"How long clients and proxies may reuse a feed before fetching it again"`
+}
+
+// SharingConfig controls oEmbed, which unfurls shared video links, and the
+// sitemap search engines index videos from
+type SharingConfig struct {
+	Enabled      bool   `mapstructure:"enabled" doc:"Serve oEmbed at /oembed and a sitemap of public videos at /sitemap.xml"`
+	ProviderName string `mapstructure:"providerName" doc:"Site name shown on unfurled links"`
+	SiteURL      string `mapstructure:"siteUrl" doc:"Home page of the site, e.g. https://pavilion.example.com"`
+	// WatchURL is both what oEmbed matches requested URLs against and what the sitemap lists
+	WatchURL    string        `mapstructure:"watchUrl" doc:"Page videos are watched on, with {id} replaced by the video ID, e.g. https://pavilion.example.com/watch/{id}"`
+	EmbedURL    string        `mapstructure:"embedUrl" doc:"Player page embedded in an iframe, with {id} replaced by the video ID, e.g. https://pavilion.example.com/embed/{id}"`
+	ChannelURL  string        `mapstructure:"channelUrl" doc:"Channel page, with {id} replaced by the user ID; unfurled links name the channel without linking it when empty"`
+	EmbedWidth  int           `mapstructure:"embedWidth" doc:"Width of the embedded player in pixels, before maxwidth is applied"`
+	EmbedHeight int           `mapstructure:"embedHeight" doc:"Height of the embedded player in pixels, before maxheight is applied"`
+	SitemapSize int           `mapstructure:"sitemapSize" doc:"Videos per sitemap page; /sitemap.xml becomes a sitemap index when there are more (at most 50000)"`
+	MaxAge      time.Duration `mapstructure:"maxAge" doc:"How long clients and proxies may reuse oEmbed responses and sitemaps"`
+}
+
+// EmbedConfig controls the embedded player, which other sites show videos
+// in, and the domains users allow it on
+type EmbedConfig struct {
+	Enabled    bool `mapstructure:"enabled" doc:"Serve the embedded player at /embed/{id}; point sharing.embedUrl at it"`
+	MaxDomains int  `mapstructure:"maxDomains" doc:"Most domains each user may allow their videos to be embedded on"`
+}
+
+// GeoIPConfig controls the lookup of the country requests come from, which
+// videos' country restrictions are enforced against
+type GeoIPConfig struct {
+	DatabasePath string `mapstructure:"databasePath" doc:"MaxMind Country or City database, e.g. GeoLite2-Country.mmdb; country restrictions are not enforced when empty"`
+}
+
+// LiveConfig controls the RTMP server users stream to, the HLS it is
+// packaged as, and the recordings published when streams end
+type LiveConfig struct {
+	Enabled    bool   `mapstructure:"enabled" doc:"Receive live streams over RTMP; runs on a single instance, which ends streams left live when it starts"`
+	ListenAddr string `mapstructure:"listenAddr" doc:"Address the RTMP server listens on"`
+	// IngestURL is shown to streamers, so it is the address behind any proxy
+	IngestURL string `mapstructure:"ingestUrl" doc:"RTMP URL streaming software publishes to, e.g. rtmp://live.pavilion.example.com/live"`
+	PublicURL string `mapstructure:"publicUrl" doc:"Base URL of the API playback URLs are built on, including server.basePath, e.g. https://pavilion.example.com/api/v1"`
+	OutputDir string `mapstructure:"outputDir" doc:"Directory HLS playlists, segments and recordings are written to while streams are live"`
+	// SegmentDuration trades latency against the number of requests players make
+	SegmentDuration time.Duration `mapstructure:"segmentDuration" doc:"Target length of HLS segments; segments are cut on keyframes, so streamers should send one at least this often"`
+	PlaylistSize    int           `mapstructure:"playlistSize" doc:"Segments listed in the live playlist; older segments are deleted"`
+	Record          bool          `mapstructure:"record" doc:"Publish each stream's recording as a video of its streamer when the stream ends"`
+	MaxKeys         int           `mapstructure:"maxKeys" doc:"Most active stream keys each user may hold"`
 }
 
 // ServerConfig represents server configuration settings
 type ServerConfig struct {
-	Port int `mapstructure:"port"`
+	Port int `mapstructure:"port" doc:"HTTP listen port"`
+	// BasePath prefixes every API route; health checks, metrics and Swagger stay at the root
+	BasePath string `mapstructure:"basePath" doc:"Path prefix of the API routes, e.g. /api/v1; the unversioned paths of earlier releases redirect here"`
+	// HandlerTimeouts maps a route pattern such as "GET /video/:id" to the deadline of its handlers
+	HandlerTimeouts map[string]time.Duration `mapstructure:"handlerTimeouts" doc:"Request deadlines by route (\"method /path\" relative to basePath, case-insensitive); \"default\" applies to every other route and 0 disables the deadline"`
+	DrainTimeout    time.Duration            `mapstructure:"drainTimeout" doc:"How long shutdown waits for uploads being processed before interrupting them"`
+	// TrustedProxies may set the client IP with X-Forwarded-For; by default no one can
+	TrustedProxies []string `mapstructure:"trustedProxies" doc:"IPs or CIDRs of the reverse proxies whose X-Forwarded-For header gives the client IP used for rate limits and logs; empty trusts none and uses the connection's address"`
 }
 
 // DatabaseConfig represents database configuration settings
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	Dbname   string `mapstructure:"dbname"`
-	Port     int    `mapstructure:"port"`
-	Sslmode  string `mapstructure:"sslmode"`
-	Timezone string `mapstructure:"timezone"`
-	Pool     struct {
-		MaxOpen int `mapstructure:"maxOpen"`
-		MaxIdle int `mapstructure:"maxIdle"`
-	} `mapstructure:"pool"`
+	Host     string             `mapstructure:"host"`
+	User     string             `mapstructure:"user"`
+	Password string             `mapstructure:"password"`
+	Dbname   string             `mapstructure:"dbname"`
+	Port     int                `mapstructure:"port"`
+	Sslmode  string             `mapstructure:"sslmode"`
+	Timezone string             `mapstructure:"timezone"`
+	Pool     DatabasePoolConfig `mapstructure:"pool"`
+}
+
+// DatabasePoolConfig represents the SQL connection pool limits
+type DatabasePoolConfig struct {
+	MaxOpen         int           `mapstructure:"maxOpenConns" doc:"Maximum open connections"`
+	MaxIdle         int           `mapstructure:"maxIdleConns" doc:"Maximum idle connections"`
+	ConnMaxLifetime time.Duration `mapstructure:"connMaxLifetime" doc:"Connections older than this are closed; 0 keeps them forever"`
 }
 
 // StorageConfig represents storage configuration settings
 type StorageConfig struct {
-	UploadDir string     `mapstructure:"uploadDir"`
-	TempDir   string     `mapstructure:"tempDir"`
-	IPFS      IPFSConfig `mapstructure:"ipfs"`
-	S3        S3Config   `mapstructure:"s3"`
+	UploadDir string             `mapstructure:"uploadDir" doc:"Directory for uploaded files, relative to the config file"`
+	TempDir   string             `mapstructure:"tempDir" doc:"Directory for transcoding scratch files, relative to the config file"`
+	Backend   string             `mapstructure:"backend" doc:"Where videos and avatars are stored: s3, or local for development and CI"`
+	IPFS      IPFSConfig         `mapstructure:"ipfs"`
+	S3        S3Config           `mapstructure:"s3"`
+	Local     LocalStorageConfig `mapstructure:"local"`
+	CDN       CDNConfig          `mapstructure:"cdn"`
+}
+
+// CDNConfig represents a content delivery network in front of the storage
+// bucket, which video details link to and which is purged when videos are
+// deleted, replaced or taken down
+type CDNConfig struct {
+	Enabled    bool                `mapstructure:"enabled" doc:"Serve stored files through a CDN"`
+	Provider   string              `mapstructure:"provider" doc:"CDN: cloudfront or fastly"`
+	Domain     string              `mapstructure:"domain" doc:"Domain files are served from, e.g. cdn.example.com"`
+	SignURLs   bool                `mapstructure:"signUrls" doc:"Sign URLs, for CDNs that only serve signed requests; gated videos only get playback URLs when set"`
+	URLTTL     time.Duration       `mapstructure:"urlTtl" doc:"How long signed URLs stay valid; each is valid for at least half of this"`
+	CloudFront CloudFrontCDNConfig `mapstructure:"cloudfront"`
+	Fastly     FastlyCDNConfig     `mapstructure:"fastly"`
+}
+
+// CDN providers selectable with storage.cdn.provider
+const (
+	CDNProviderCloudFront = "cloudfront"
+	CDNProviderFastly     = "fastly"
+)
+
+// CloudFrontCDNConfig represents a CloudFront distribution. Invalidations
+// are authenticated with the storage.s3 credentials.
+type CloudFrontCDNConfig struct {
+	KeyPairID      string `mapstructure:"keyPairId" doc:"ID of the public key URLs are signed for"`
+	PrivateKey     string `mapstructure:"privateKey" doc:"PEM-encoded RSA key URLs are signed with"`
+	DistributionID string `mapstructure:"distributionId" doc:"Distribution invalidated when files change; files are not purged when empty"`
+}
+
+// FastlyCDNConfig represents a Fastly service
+type FastlyCDNConfig struct {
+	TokenSecret string `mapstructure:"tokenSecret" doc:"Key URL tokens are signed with, shared with the service's token validation"`
+	APIToken    string `mapstructure:"apiToken" doc:"API token purges are sent with; files are not purged when empty"`
+}
+
+// Storage backends selectable with storage.backend
+const (
+	StorageBackendS3    = "s3"
+	StorageBackendLocal = "local"
+)
+
+// LocalStorageConfig represents the local filesystem storage settings
+type LocalStorageConfig struct {
+	Dir     string `mapstructure:"dir" doc:"Directory videos and avatars are stored in, relative to the config file"`
+	BaseURL string `mapstructure:"baseURL" doc:"Public URL of the server's /storage path, used in links to stored files"`
 }
 
 // RedisConfig represents Redis configuration settings
@@ -66,94 +358,249 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db"`
 }
 
+// ReadCacheConfig represents the Redis read-through caches in front of
+// video records, comment counts and related video rankings
+type ReadCacheConfig struct {
+	Enabled         bool          `mapstructure:"enabled" doc:"Cache video records, comment counts and related video rankings in Redis"`
+	VideoTTL        time.Duration `mapstructure:"videoTTL" doc:"How long a video record is cached"`
+	CommentCountTTL time.Duration `mapstructure:"commentCountTTL" doc:"How long comment and reply counts are cached"`
+	RelatedTTL      time.Duration `mapstructure:"relatedTTL" doc:"How long a video's related video ranking is cached"`
+}
+
+// CommentsConfig controls what comments may say and how they are returned
+type CommentsConfig struct {
+	MaxLength int  `mapstructure:"maxLength" doc:"Most characters a comment may have once HTML is stripped from it"`
+	Markdown  bool `mapstructure:"markdown" doc:"Render comments from Markdown to sanitized HTML, returned as content_html next to content"`
+	// BannedWords apply on every video; channels add their own at /me/comments/blocklist
+	BannedWords  []string `mapstructure:"bannedWords" doc:"Words and phrases banned from all comments, matched whole and ignoring case"`
+	FilterAction string   `mapstructure:"filterAction" doc:"What happens to comments with banned words: reject them, mask the words with asterisks, or hold the comment for the video owner's review"`
+}
+
 // VideoConfig represents video configuration settings
 type VideoConfig struct {
-	MaxSize        int64    `mapstructure:"maxSize"`
+	MaxSize        int64    `mapstructure:"maxSize" doc:"Maximum upload size in bytes"`
 	MinTitleLength int      `mapstructure:"minTitleLength"`
 	MaxTitleLength int      `mapstructure:"maxTitleLength"`
 	MaxDescLength  int      `mapstructure:"maxDescLength"`
-	AllowedFormats []string `mapstructure:"allowedFormats"`
+	AllowedFormats []string `mapstructure:"allowedFormats" doc:"Accepted upload file extensions"`
+	// Uploads are probed with FFprobe and checked against these before they are stored
+	MaxDuration        time.Duration        `mapstructure:"maxDuration" doc:"Longest video accepted; 0 accepts any length"`
+	AllowedVideoCodecs []string             `mapstructure:"allowedVideoCodecs" doc:"FFmpeg names of accepted video codecs; empty accepts any codec FFmpeg can decode"`
+	Cleanup            VideoCleanupConfig   `mapstructure:"cleanup"`
+	Tiering            VideoTieringConfig   `mapstructure:"tiering"`
+	Transcode          VideoTranscodeConfig `mapstructure:"transcode"`
+	Scan               VideoScanConfig      `mapstructure:"scan"`
+	CommentCounts      VideoCommentCounts   `mapstructure:"commentCounts"`
+	DeletionCleanup    VideoDeletionCleanup `mapstructure:"deletionCleanup"`
+	Temp               VideoTempConfig      `mapstructure:"temp"`
+}
+
+// VideoTempConfig represents settings for the temporary files uploads are
+// saved and transcoded in
+type VideoTempConfig struct {
+	Dir        string        `mapstructure:"dir" doc:"Directory temporary upload files are kept in; shared by the server and pavilionctl"`
+	StaleAfter time.Duration `mapstructure:"staleAfter" doc:"Age at which temporary directories left behind by crashed processes are removed on startup; 0 keeps them"`
+	MaxBytes   int64         `mapstructure:"maxBytes" doc:"Bytes temporary files may use before new uploads get 503; 0 sets no limit"`
+}
+
+// VideoCommentCounts represents settings for the comment counts kept on
+// videos from comment events
+type VideoCommentCounts struct {
+	Enabled           bool          `mapstructure:"enabled" doc:"Keep comment counts on videos; they follow comment events when notification.enabled and are otherwise only updated by reconciliation"`
+	Subscription      string        `mapstructure:"subscription" doc:"Pulsar subscription to the comment events topic that applies the counts"`
+	ReconcileInterval time.Duration `mapstructure:"reconcileInterval" doc:"How often every video's count is recounted from ScyllaDB to correct drift"`
+	BatchSize         int           `mapstructure:"batchSize" doc:"Videos recounted at once during reconciliation"`
+}
+
+// VideoDeletionCleanup represents settings for removing the comments and
+// redacting the notifications of deleted videos
+type VideoDeletionCleanup struct {
+	Enabled      bool   `mapstructure:"enabled" doc:"Soft-delete the comments of deleted videos and redact notifications about them; needs notification.enabled"`
+	Subscription string `mapstructure:"subscription" doc:"Pulsar subscription to the video events topic that cleans up after deleted videos"`
+}
+
+// VideoScanConfig represents settings for scanning uploads for malware or
+// unwanted content before they are stored
+type VideoScanConfig struct {
+	Enabled  bool                `mapstructure:"enabled" doc:"Scan uploads before they are stored"`
+	Provider string              `mapstructure:"provider" doc:"Scanner: clamav, or http for an external moderation API"`
+	Action   string              `mapstructure:"action" doc:"What happens to flagged uploads: block rejects them, quarantine hides the video until an admin releases it, flag publishes it for admins to review"`
+	FailOpen bool                `mapstructure:"failOpen" doc:"Accept uploads the scanner cannot check, recording the error for admins; otherwise they fail"`
+	Timeout  time.Duration       `mapstructure:"timeout" doc:"Longest a scan may take"`
+	ClamAV   ClamAVConfig        `mapstructure:"clamav"`
+	HTTP     ModerationAPIConfig `mapstructure:"http"`
+}
+
+// ClamAVConfig represents the clamd daemon uploads are streamed to
+type ClamAVConfig struct {
+	Address string `mapstructure:"address" doc:"clamd TCP address, e.g. clamav:3310"`
+}
+
+// ModerationAPIConfig represents an external moderation API, such as an
+// NSFW classifier. Uploads are POSTed to it and it answers with
+// {"flagged": bool, "labels": [...]}.
+type ModerationAPIConfig struct {
+	URL   string `mapstructure:"url" doc:"Moderation API endpoint"`
+	Token string `mapstructure:"token" doc:"Bearer token for the moderation API"`
+}
+
+// VideoTranscodeConfig represents settings for the transcode scheduler shared by all uploads
+type VideoTranscodeConfig struct {
+	Workers    int           `mapstructure:"workers" doc:"Renditions transcoded at once across all uploads; queued renditions of shorter videos run first"`
+	MaxBacklog int           `mapstructure:"maxBacklog" doc:"Queued renditions at which uploads get 429 and the transcode_queue health check fails; 0 never rejects uploads"`
+	RetryAfter time.Duration `mapstructure:"retryAfter" doc:"Retry-After sent with uploads rejected for a full backlog"`
+}
+
+// VideoCleanupConfig represents settings for purging storage of deleted videos, failed uploads and replaced versions
+type VideoCleanupConfig struct {
+	Enabled              bool          `mapstructure:"enabled" doc:"Periodically purge deleted videos and failed uploads"`
+	Interval             time.Duration `mapstructure:"interval" doc:"How often the cleanup runs"`
+	DeletedRetentionDays int           `mapstructure:"deletedRetentionDays" doc:"Days a soft-deleted video is kept before its files and records are purged"`
+	FailedRetention      time.Duration `mapstructure:"failedRetention" doc:"How long a failed or interrupted upload is kept before it is purged"`
+	VersionRetentionDays int           `mapstructure:"versionRetentionDays" doc:"Days the files of a replaced video version are kept before they are purged"`
+	BatchSize            int           `mapstructure:"batchSize" doc:"Most videos purged for each reason in one run"`
+	DryRun               bool          `mapstructure:"dryRun" doc:"Log and count what would be purged without deleting anything"`
+}
+
+// VideoTieringConfig represents settings for moving old originals to a
+// cheaper storage class and dropping the renditions of unwatched videos
+type VideoTieringConfig struct {
+	Enabled            bool          `mapstructure:"enabled" doc:"Periodically tier video storage; needs the s3 backend"`
+	Interval           time.Duration `mapstructure:"interval" doc:"How often tiering runs"`
+	StorageClass       string        `mapstructure:"storageClass" doc:"S3 storage class originals move to: STANDARD_IA, ONEZONE_IA, INTELLIGENT_TIERING or GLACIER_IR"`
+	OriginalsAfterDays int           `mapstructure:"originalsAfterDays" doc:"Days after upload an original moves to storageClass; 0 only moves originals of videos overridden to archive"`
+	RenditionsIdleDays int           `mapstructure:"renditionsIdleDays" doc:"Days a video goes unwatched before its resolutions are dropped, to be transcoded again when next streamed; 0 only drops those of videos overridden to archive"`
+	BatchSize          int           `mapstructure:"batchSize" doc:"Most videos tiered for each action in one run"`
+	DryRun             bool          `mapstructure:"dryRun" doc:"Log and count what would be tiered without changing anything"`
 }
 
 // IPFSConfig represents IPFS configuration settings
 type IPFSConfig struct {
-	APIAddress string `mapstructure:"apiAddress"`
-	Gateway    string `mapstructure:"gateway"`
+	APIAddress  string                `mapstructure:"apiAddress"`
+	Gateway     string                `mapstructure:"gateway"`
+	Peers       []string              `mapstructure:"peers" doc:"Multiaddrs of the IPFS nodes pinning videos, listed in video sources for clients to dial directly"`
+	Replication IPFSReplicationConfig `mapstructure:"replication"`
+}
+
+// IPFSReplicationConfig controls the background queue that copies uploads from S3 to IPFS
+type IPFSReplicationConfig struct {
+	Workers        int           `mapstructure:"workers" doc:"Concurrent IPFS adds"`
+	QueueSize      int           `mapstructure:"queueSize" doc:"Files waiting for a worker before uploads fall back to S3 only"`
+	MaxRetries     int           `mapstructure:"maxRetries"`
+	RetryDelay     time.Duration `mapstructure:"retryDelay"`
+	RescanInterval time.Duration `mapstructure:"rescanInterval" doc:"How often files left S3 only are queued again; 0 only queues them at startup"`
+	StaleAfter     time.Duration `mapstructure:"staleAfter" doc:"How long a file may stay replicating without an attempt before it is queued again"`
 }
 
 // S3Config represents S3 configuration settings
 type S3Config struct {
-	Endpoint        string `mapstructure:"endpoint"`
+	Endpoint        string `mapstructure:"endpoint" doc:"Custom endpoint for S3-compatible stores; AWS is used when empty"`
 	AccessKeyID     string `mapstructure:"accessKeyId"`
 	SecretAccessKey string `mapstructure:"secretAccessKey"`
 	UseSSL          bool   `mapstructure:"useSSL"`
 	Region          string `mapstructure:"region"`
 	Bucket          string `mapstructure:"bucket"`
+	RootDirectory   string `mapstructure:"root_directory" doc:"Key prefix for all objects in the bucket"`
+
+	Bootstrap S3BootstrapConfig `mapstructure:"bootstrap"`
+	Multipart S3MultipartConfig `mapstructure:"multipart"`
+}
+
+// S3MultipartConfig controls how large files are split into parts when uploaded
+type S3MultipartConfig struct {
+	PartSizeMB  int `mapstructure:"partSizeMB" doc:"Size of each uploaded part in MiB (minimum 5); files up to one part are sent in a single request"`
+	Concurrency int `mapstructure:"concurrency" doc:"Parts of one file uploaded in parallel"`
+}
+
+// S3BootstrapConfig controls the storage bootstrap run at startup
+type S3BootstrapConfig struct {
+	Enabled                   bool     `mapstructure:"enabled" doc:"Create the bucket, apply CORS and lifecycle rules and run a self-test at startup; meant for MinIO and local development"`
+	CORSAllowedOrigins        []string `mapstructure:"corsAllowedOrigins" doc:"Origins allowed to fetch objects from the bucket directly"`
+	AbortIncompleteUploadDays int      `mapstructure:"abortIncompleteUploadDays" doc:"Days after which unfinished multipart uploads are removed; 0 leaves lifecycle rules alone"`
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
-	Level       string `mapstructure:"level" yaml:"level"`
-	Format      string `mapstructure:"format" yaml:"format"`
-	Output      string `mapstructure:"output" yaml:"output"`
+	Level       string `mapstructure:"level" yaml:"level" doc:"debug, info, warn, error or fatal"`
+	Format      string `mapstructure:"format" yaml:"format" doc:"json or console"`
+	Output      string `mapstructure:"output" yaml:"output" doc:"stdout, file or both"`
 	Development bool   `mapstructure:"development" yaml:"development"`
+	// LogBodies is ignored outside development so bodies cannot leak from production
+	LogBodies bool `mapstructure:"logBodies" yaml:"logBodies" doc:"Add request and response bodies to request logs; only honored when environment is development"`
 
-	File struct {
-		Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
-		Path    string `mapstructure:"path" yaml:"path"`
-		Rotate  bool   `mapstructure:"rotate" yaml:"rotate"`
-		MaxSize string `mapstructure:"maxSize" yaml:"maxSize"`
-		MaxAge  string `mapstructure:"maxAge" yaml:"maxAge"`
-	} `mapstructure:"file" yaml:"file"`
-
-	Sampling struct {
-		Initial    int `mapstructure:"initial" yaml:"initial"`
-		Thereafter int `mapstructure:"thereafter" yaml:"thereafter"`
-	} `mapstructure:"sampling" yaml:"sampling"`
+	File     logger.FileConfig     `mapstructure:"file" yaml:"file"`
+	Sampling logger.SamplingConfig `mapstructure:"sampling" yaml:"sampling"`
 }
 
 // ScyllaDBConfig represents ScyllaDB configuration settings
 type ScyllaDBConfig struct {
-	Hosts       []string `mapstructure:"hosts" yaml:"hosts"`
-	Port        int      `mapstructure:"port" yaml:"port"`
-	Keyspace    string   `mapstructure:"keyspace" yaml:"keyspace"`
-	Username    string   `mapstructure:"username" yaml:"username"`
-	Password    string   `mapstructure:"password" yaml:"password"`
-	Consistency string   `mapstructure:"consistency" yaml:"consistency"`
-	Replication struct {
-		Class             string `mapstructure:"class" yaml:"class"`
-		ReplicationFactor int    `mapstructure:"replicationFactor" yaml:"replicationFactor"`
-	} `mapstructure:"replication" yaml:"replication"`
-	Timeout        time.Duration `mapstructure:"timeout" yaml:"timeout"`
-	ConnectTimeout time.Duration `mapstructure:"connectTimeout" yaml:"connectTimeout"`
+	Hosts          []string                `mapstructure:"hosts" yaml:"hosts"`
+	Port           int                     `mapstructure:"port" yaml:"port"`
+	Keyspace       string                  `mapstructure:"keyspace" yaml:"keyspace"`
+	Username       string                  `mapstructure:"username" yaml:"username"`
+	Password       string                  `mapstructure:"password" yaml:"password"`
+	Consistency    string                  `mapstructure:"consistency" yaml:"consistency" doc:"Read/write consistency level, e.g. quorum"`
+	Replication    ScyllaReplicationConfig `mapstructure:"replication" yaml:"replication"`
+	Timeout        time.Duration           `mapstructure:"timeout" yaml:"timeout"`
+	ConnectTimeout time.Duration           `mapstructure:"connectTimeout" yaml:"connectTimeout"`
+}
+
+// ScyllaReplicationConfig represents the keyspace replication strategy
+type ScyllaReplicationConfig struct {
+	Class             string `mapstructure:"class" yaml:"class"`
+	ReplicationFactor int    `mapstructure:"replicationFactor" yaml:"replicationFactor"`
 }
 
 // PulsarConfig represents Apache Pulsar configuration settings
 type PulsarConfig struct {
-	URL              string        `mapstructure:"url" yaml:"url"`
-	TLSEnabled       bool          `mapstructure:"tls_enabled" yaml:"tls_enabled"`
-	TLSCertPath      string        `mapstructure:"tls_cert_path" yaml:"tls_cert_path"`
-	WebServiceURL    string        `mapstructure:"web_service_url" yaml:"web_service_url"`
-	OperationTimeout time.Duration `mapstructure:"operation_timeout" yaml:"operation_timeout"`
+	URL               string        `mapstructure:"url" yaml:"url"`
+	TLSEnabled        bool          `mapstructure:"tls_enabled" yaml:"tls_enabled"`
+	TLSCertPath       string        `mapstructure:"tls_cert_path" yaml:"tls_cert_path"`
+	WebServiceURL     string        `mapstructure:"web_service_url" yaml:"web_service_url" doc:"Pulsar admin API, used to read consumer lag"`
+	OperationTimeout  time.Duration `mapstructure:"operation_timeout" yaml:"operation_timeout"`
 	ConnectionTimeout time.Duration `mapstructure:"connection_timeout" yaml:"connection_timeout"`
-	Namespace        string        `mapstructure:"namespace" yaml:"namespace"`
-	AuthToken        string        `mapstructure:"auth_token" yaml:"auth_token"` // Token for auth, sourced from env var
+	Namespace         string        `mapstructure:"namespace" yaml:"namespace"`
+	AuthToken         string        `mapstructure:"auth_token" yaml:"auth_token"`
 }
 
 // NotificationConfig represents notification system configuration settings
 type NotificationConfig struct {
-	Enabled            bool          `mapstructure:"enabled" yaml:"enabled"`
-	VideoEventsTopic   string        `mapstructure:"video_events_topic" yaml:"video_events_topic"`
-	CommentEventsTopic string        `mapstructure:"comment_events_topic" yaml:"comment_events_topic"`
-	UserEventsTopic    string        `mapstructure:"user_events_topic" yaml:"user_events_topic"`
-	DeadLetterTopic    string        `mapstructure:"dead_letter_topic" yaml:"dead_letter_topic"`
-	RetryQueueTopic    string        `mapstructure:"retry_queue_topic" yaml:"retry_queue_topic"`
-	RetentionTimeHours int           `mapstructure:"retention_time_hours" yaml:"retention_time_hours"`
-	DeduplicationEnabled bool        `mapstructure:"deduplication_enabled" yaml:"deduplication_enabled"`
-	DeduplicationWindow time.Duration `mapstructure:"deduplication_window" yaml:"deduplication_window"`
-	RetryEnabled       bool          `mapstructure:"retry_enabled" yaml:"retry_enabled"`
-	MaxRetries         int           `mapstructure:"max_retries" yaml:"max_retries"`
-	BackoffInitial     time.Duration `mapstructure:"backoff_initial" yaml:"backoff_initial"`
-	BackoffMax         time.Duration `mapstructure:"backoff_max" yaml:"backoff_max"`
-	BackoffMultiplier  float64       `mapstructure:"backoff_multiplier" yaml:"backoff_multiplier"`
+	Enabled              bool          `mapstructure:"enabled" yaml:"enabled" doc:"Publish and consume notification events"`
+	VideoEventsTopic     string        `mapstructure:"video_events_topic" yaml:"video_events_topic"`
+	CommentEventsTopic   string        `mapstructure:"comment_events_topic" yaml:"comment_events_topic"`
+	UserEventsTopic      string        `mapstructure:"user_events_topic" yaml:"user_events_topic"`
+	DeadLetterTopic      string        `mapstructure:"dead_letter_topic" yaml:"dead_letter_topic"`
+	RetryQueueTopic      string        `mapstructure:"retry_queue_topic" yaml:"retry_queue_topic"`
+	RetentionTimeHours   int           `mapstructure:"retention_time_hours" yaml:"retention_time_hours"`
+	DeduplicationEnabled bool          `mapstructure:"deduplication_enabled" yaml:"deduplication_enabled"`
+	DeduplicationWindow  time.Duration `mapstructure:"deduplication_window" yaml:"deduplication_window"`
+	RetryEnabled         bool          `mapstructure:"retry_enabled" yaml:"retry_enabled"`
+	MaxRetries           int           `mapstructure:"max_retries" yaml:"max_retries"`
+	BackoffInitial       time.Duration `mapstructure:"backoff_initial" yaml:"backoff_initial"`
+	BackoffMax           time.Duration `mapstructure:"backoff_max" yaml:"backoff_max"`
+	BackoffMultiplier    float64       `mapstructure:"backoff_multiplier" yaml:"backoff_multiplier"`
+	LagPollInterval      time.Duration `mapstructure:"lag_poll_interval" yaml:"lag_poll_interval" doc:"How often consumer lag is read from the Pulsar admin API"`
+	AggregationWindow    time.Duration `mapstructure:"aggregation_window" yaml:"aggregation_window" doc:"How long likes, comments, replies and follows on the same object are collapsed into one unread notification; 0 stores each one"`
+	UnreadCountTTL       time.Duration `mapstructure:"unread_count_ttl" yaml:"unread_count_ttl" doc:"How long a user's unread count is kept in Redis before it is recounted from ScyllaDB; 0 counts in ScyllaDB on every request"`
+	FollowerSubscription string        `mapstructure:"follower_subscription" yaml:"follower_subscription" doc:"Pulsar subscription to the video events topic that notifies followers of uploads and live streams"`
+	Push                 PushConfig    `mapstructure:"push" yaml:"push"`
+	Digest               DigestConfig  `mapstructure:"digest" yaml:"digest"`
+}
+
+// PushConfig represents settings for sending notifications to browsers with Web Push
+type PushConfig struct {
+	Enabled         bool          `mapstructure:"enabled" yaml:"enabled" doc:"Send new notifications to registered browsers as Web Push messages"`
+	VAPIDPublicKey  string        `mapstructure:"vapid_public_key" yaml:"vapid_public_key" doc:"VAPID public key, base64url encoded; generate a pair with pavilionctl notification vapid-keys"`
+	VAPIDPrivateKey string        `mapstructure:"vapid_private_key" yaml:"vapid_private_key" doc:"VAPID private key, base64url encoded"`
+	VAPIDSubject    string        `mapstructure:"vapid_subject" yaml:"vapid_subject" doc:"mailto: or https:// contact for push services"`
+	TTL             time.Duration `mapstructure:"ttl" yaml:"ttl" doc:"How long push services keep a message for an offline browser"`
+	Timeout         time.Duration `mapstructure:"timeout" yaml:"timeout" doc:"Deadline for delivering a notification to all of a user's browsers"`
+}
+
+// DigestConfig represents settings for emailing users digests of their unread notifications
+type DigestConfig struct {
+	Enabled  bool          `mapstructure:"enabled" yaml:"enabled" doc:"Email users who ask for it a daily or weekly digest of their unread notifications"`
+	Interval time.Duration `mapstructure:"interval" yaml:"interval" doc:"How often users whose digest is due are looked up"`
+	MaxItems int           `mapstructure:"max_items" yaml:"max_items" doc:"Notifications listed in a digest; the rest are counted"`
+	URL      string        `mapstructure:"url" yaml:"url" doc:"Notifications page of the frontend, linked from digests"`
 }
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/federation"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/scan"
+)
+
+// Validate checks the configuration, including the settings each enabled
+// subsystem needs, and reports every problem found rather than the first
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0, "invalid server port")
+	if p := c.Server.BasePath; p != "" {
+		check(strings.HasPrefix(p, "/") && !strings.HasSuffix(p, "/"),
+			"server basePath %q must start with a slash and not end with one, e.g. /api/v1", p)
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "server trustedProxies entry %q is not an IP or CIDR", proxy)
+	}
+
+	check(c.Database.Host != "", "database host is required")
+	check(c.Database.User != "", "database user is required")
+	check(c.Database.Dbname != "", "database name is required")
+	check(c.Database.Port > 0, "invalid database port")
+
+	check(len(c.ScyllaDB.Hosts) > 0, "scylladb hosts are required")
+	check(c.ScyllaDB.Keyspace != "", "scylladb keyspace is required")
+
+	check(c.Auth.JWT.Secret != "", "auth.jwt.secret is required; set JWT_SECRET")
+	for _, name := range sortedProviders(c.Auth.OAuth) {
+		provider := c.Auth.OAuth[name]
+		if provider.ClientID == "" {
+			continue
+		}
+		check(provider.ClientSecret != "", "auth.oauth.%s needs a clientSecret when clientId is set", name)
+		check(provider.RedirectURL != "", "auth.oauth.%s needs a redirectUrl when clientId is set", name)
+	}
+
+	check(ffmpeg.ValidHWAccel(c.Ffmpeg.HWAccel), "unknown ffmpeg hwAccel %q, expected none, auto, %s, %s or %s",
+		c.Ffmpeg.HWAccel, ffmpeg.HWAccelVAAPI, ffmpeg.HWAccelNVENC, ffmpeg.HWAccelVideoToolbox)
+	if c.Ffmpeg.Audio.Enabled {
+		check(ffmpeg.ValidAudioCodec(c.Ffmpeg.Audio.Codec), "unknown ffmpeg audio codec %q, expected %s or %s",
+			c.Ffmpeg.Audio.Codec, ffmpeg.AudioCodecAAC, ffmpeg.AudioCodecOpus)
+	}
+	if preview := c.Ffmpeg.Preview; preview.Enabled {
+		check(preview.Seconds > 0 && preview.Width > 0 && preview.FPS > 0 && preview.Quality >= 0 && preview.Quality <= 100,
+			"ffmpeg preview needs positive seconds, width and fps, and a quality of 0-100")
+	}
+
+	check(c.Video.Transcode.MaxBacklog >= 0, "video.transcode.maxBacklog cannot be negative")
+	check(c.Video.Temp.Dir != "", "video.temp.dir is required")
+	check(c.Video.Temp.StaleAfter >= 0, "video.temp.staleAfter cannot be negative")
+	// A smaller budget would reject every upload of the largest accepted size
+	check(c.Video.Temp.MaxBytes == 0 || c.Video.Temp.MaxBytes >= c.Video.MaxSize, "video.temp.maxBytes must be 0 or at least video.maxSize")
+
+	if tiering := c.Video.Tiering; tiering.Enabled {
+		check(c.Storage.Backend == StorageBackendS3, "video tiering needs the s3 storage backend")
+		check(video.ValidTieringStorageClass(tiering.StorageClass), "unknown video tiering storageClass %q, expected %s, %s, %s or %s",
+			tiering.StorageClass, video.StorageClassStandardIA, video.StorageClassOneZoneIA, video.StorageClassIntelligentTiering, video.StorageClassGlacierIR)
+		check(tiering.OriginalsAfterDays >= 0 && tiering.RenditionsIdleDays >= 0, "video tiering days cannot be negative")
+	}
+
+	if scanConfig := c.Video.Scan; scanConfig.Enabled {
+		switch scanConfig.Provider {
+		case scan.ProviderClamAV:
+			check(scanConfig.ClamAV.Address != "", "video scan with clamav needs clamav.address")
+		case scan.ProviderHTTP:
+			check(scanConfig.HTTP.URL != "", "video scan with http needs http.url")
+		default:
+			check(false, "unknown video scan provider %q, expected %s or %s", scanConfig.Provider, scan.ProviderClamAV, scan.ProviderHTTP)
+		}
+		check(video.ScanAction(scanConfig.Action).IsValid(), "unknown video scan action %q, expected %s, %s or %s",
+			scanConfig.Action, video.ScanActionBlock, video.ScanActionQuarantine, video.ScanActionFlag)
+	}
+
+	if counts := c.Video.CommentCounts; counts.Enabled {
+		check(counts.Subscription != "", "video.commentCounts.subscription is required")
+		check(counts.ReconcileInterval > 0 && counts.BatchSize > 0, "video.commentCounts needs a positive reconcileInterval and batchSize")
+	}
+
+	if cleanup := c.Video.DeletionCleanup; cleanup.Enabled {
+		check(cleanup.Subscription != "", "video.deletionCleanup.subscription is required")
+	}
+
+	check(c.Comments.MaxLength >= 1, "comments.maxLength must be at least 1, got %d", c.Comments.MaxLength)
+	switch c.Comments.FilterAction {
+	case "reject", "mask", "hold":
+	default:
+		check(false, "unknown comments.filterAction %q, expected reject, mask or hold", c.Comments.FilterAction)
+	}
+
+	if jobs := c.Jobs; jobs.Enabled {
+		check(jobs.Workers > 0 && jobs.MaxAttempts > 0, "jobs needs at least one worker and one attempt")
+		check(jobs.PollInterval > 0 && jobs.Timeout > 0, "jobs needs a positive pollInterval and timeout")
+		check(jobs.Retention >= 0, "jobs.retention cannot be negative")
+	}
+
+	switch c.Storage.Backend {
+	case StorageBackendS3:
+		s3 := c.Storage.S3
+		check(s3.Bucket != "", "storage.s3.bucket is required with the s3 backend")
+		check(s3.Region != "", "storage.s3.region is required with the s3 backend")
+		check(s3.AccessKeyID != "", "storage.s3.accessKeyId is required with the s3 backend; set S3_ACCESS_KEY_ID")
+		check(s3.SecretAccessKey != "", "storage.s3.secretAccessKey is required with the s3 backend; set S3_SECRET_ACCESS_KEY")
+	case StorageBackendLocal:
+		check(c.Storage.Local.Dir != "", "storage.local.dir is required with the local backend")
+	default:
+		check(false, "unknown storage backend %q, expected %s or %s", c.Storage.Backend, StorageBackendS3, StorageBackendLocal)
+	}
+
+	replication := c.Storage.IPFS.Replication
+	check(replication.RescanInterval >= 0, "storage.ipfs.replication.rescanInterval cannot be negative")
+	check(replication.StaleAfter > 0, "storage.ipfs.replication.staleAfter must be positive")
+
+	if cdn := c.Storage.CDN; cdn.Enabled {
+		check(cdn.Domain != "", "storage.cdn.domain is required when the CDN is enabled")
+		check(!cdn.SignURLs || cdn.URLTTL > 0, "storage.cdn.urlTtl must be positive when URLs are signed")
+		switch cdn.Provider {
+		case CDNProviderCloudFront:
+			check(!cdn.SignURLs || (cdn.CloudFront.KeyPairID != "" && cdn.CloudFront.PrivateKey != ""),
+				"storage.cdn.cloudfront needs keyPairId and privateKey to sign URLs; set CLOUDFRONT_PRIVATE_KEY")
+			check(cdn.CloudFront.DistributionID == "" || c.Storage.S3.AccessKeyID != "",
+				"storage.cdn.cloudfront.distributionId needs the storage.s3 credentials to invalidate files")
+		case CDNProviderFastly:
+			check(!cdn.SignURLs || cdn.Fastly.TokenSecret != "",
+				"storage.cdn.fastly.tokenSecret is required to sign URLs; set FASTLY_TOKEN_SECRET")
+		default:
+			check(false, "unknown CDN provider %q, expected %s or %s", cdn.Provider, CDNProviderCloudFront, CDNProviderFastly)
+		}
+	}
+
+	if p2p := c.P2P; p2p.Enabled {
+		check(c.Storage.IPFS.APIAddress != "", "storage.ipfs.apiAddress is required when p2p is enabled")
+		check(p2p.Topic != "", "p2p.topic is required when p2p is enabled")
+		check(p2p.ProvideTimeout > 0, "p2p.provideTimeout must be positive when p2p is enabled")
+		if p2p.PublicURL != "" {
+			u, err := url.Parse(p2p.PublicURL)
+			check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+				"p2p.publicUrl must be an absolute http(s) URL, got %q", p2p.PublicURL)
+		}
+	}
+
+	if fed := c.Federation; fed.Enabled {
+		_, err := federation.ParsePrivateKey(fed.PrivateKey)
+		check(err == nil, "federation.privateKey is invalid: %v; set FEDERATION_PRIVATE_KEY", err)
+		check(fed.PollInterval > 0, "federation.pollInterval must be positive when federation is enabled")
+		check(fed.PageSize >= 1 && fed.PageSize <= 500, "federation.pageSize must be between 1 and 500, got %d", fed.PageSize)
+		check(fed.MaxPagesPerPoll >= 1, "federation.maxPagesPerPoll must be at least 1, got %d", fed.MaxPagesPerPoll)
+	}
+
+	if ap := c.ActivityPub; ap.Enabled {
+		u, err := url.Parse(ap.PublicURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"activityPub.publicUrl must be an absolute http(s) URL when activityPub is enabled, got %q", ap.PublicURL)
+		check(ap.WatchURL == "" || strings.Contains(ap.WatchURL, "{id}"), "activityPub.watchUrl must contain {id}, got %q", ap.WatchURL)
+		check(ap.MaxClockSkew > 0 && ap.Timeout > 0, "activityPub.maxClockSkew and activityPub.timeout must be positive")
+		check(ap.Workers >= 1 && ap.MaxAttempts >= 1, "activityPub.workers and activityPub.maxAttempts must be at least 1")
+	}
+
+	if feeds := c.Feeds; feeds.Enabled {
+		if feeds.SiteURL != "" {
+			u, err := url.Parse(feeds.SiteURL)
+			check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+				"feeds.siteUrl must be an absolute http(s) URL, got %q", feeds.SiteURL)
+		}
+		check(feeds.WatchURL == "" || strings.Contains(feeds.WatchURL, "{id}"), "feeds.watchUrl must contain {id}, got %q", feeds.WatchURL)
+		check(feeds.Items >= 1 && feeds.Items <= 500, "feeds.items must be between 1 and 500, got %d", feeds.Items)
+		check(feeds.MaxAge >= 0, "feeds.maxAge must not be negative")
+	}
+
+	if sharing := c.Sharing; sharing.Enabled {
+		for name, value := range map[string]string{"siteUrl": sharing.SiteURL, "watchUrl": sharing.WatchURL} {
+			u, err := url.Parse(value)
+			check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+				"sharing.%s must be an absolute http(s) URL when sharing is enabled, got %q", name, value)
+		}
+		check(strings.Contains(sharing.WatchURL, "{id}"), "sharing.watchUrl must contain {id}, got %q", sharing.WatchURL)
+		check(strings.Contains(sharing.EmbedURL, "{id}"), "sharing.embedUrl must contain {id}, got %q", sharing.EmbedURL)
+		check(sharing.ChannelURL == "" || strings.Contains(sharing.ChannelURL, "{id}"), "sharing.channelUrl must contain {id}, got %q", sharing.ChannelURL)
+		check(sharing.EmbedWidth >= 1 && sharing.EmbedHeight >= 1, "sharing.embedWidth and sharing.embedHeight must be positive")
+		check(sharing.SitemapSize >= 1 && sharing.SitemapSize <= 50000, "sharing.sitemapSize must be between 1 and 50000, got %d", sharing.SitemapSize)
+		check(sharing.MaxAge >= 0, "sharing.maxAge must not be negative")
+	}
+
+	if c.Embed.Enabled {
+		check(c.Embed.MaxDomains >= 1, "embed.maxDomains must be at least 1, got %d", c.Embed.MaxDomains)
+	}
+
+	if live := c.Live; live.Enabled {
+		check(live.ListenAddr != "", "live.listenAddr is required when live is enabled")
+		u, err := url.Parse(live.IngestURL)
+		check(err == nil && (u.Scheme == "rtmp" || u.Scheme == "rtmps") && u.Host != "",
+			"live.ingestUrl must be an rtmp(s) URL when live is enabled, got %q", live.IngestURL)
+		u, err = url.Parse(live.PublicURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"live.publicUrl must be an absolute http(s) URL when live is enabled, got %q", live.PublicURL)
+		check(live.OutputDir != "", "live.outputDir is required when live is enabled")
+		check(live.SegmentDuration > 0, "live.segmentDuration must be positive")
+		check(live.PlaylistSize >= 2, "live.playlistSize must be at least 2, got %d", live.PlaylistSize)
+		check(live.MaxKeys >= 1, "live.maxKeys must be at least 1, got %d", live.MaxKeys)
+	}
+
+	if c.Notification.Enabled {
+		check(c.Pulsar.URL != "", "pulsar.url is required when notifications are enabled")
+		check(!c.Pulsar.TLSEnabled || c.Pulsar.TLSCertPath != "", "pulsar.tls_cert_path is required when pulsar.tls_enabled is set")
+		check(c.Notification.VideoEventsTopic != "" && c.Notification.CommentEventsTopic != "" && c.Notification.UserEventsTopic != "",
+			"notification video, comment and user event topics are required when notifications are enabled")
+		check(c.Notification.FollowerSubscription != "", "notification.follower_subscription is required when notifications are enabled")
+	}
+
+	if push := c.Notification.Push; push.Enabled {
+		check(push.VAPIDPublicKey != "" && push.VAPIDPrivateKey != "",
+			"notification.push needs vapid_public_key and vapid_private_key when enabled; set VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY")
+		check(push.VAPIDSubject != "", "notification.push.vapid_subject is required when push is enabled")
+		check(push.Timeout > 0, "notification.push.timeout must be positive when push is enabled")
+	}
+
+	if digest := c.Notification.Digest; digest.Enabled {
+		check(digest.Interval > 0, "notification.digest.interval must be positive when digests are enabled")
+		check(digest.MaxItems >= 1, "notification.digest.max_items must be at least 1, got %d", digest.MaxItems)
+	}
+
+	if c.Entitlements.WebhookSecret != "" {
+		check(c.Entitlements.WebhookTolerance > 0, "entitlements.webhookTolerance must be positive when entitlements.webhookSecret is set")
+	}
+
+	if c.Email.SMTP.Host != "" {
+		check(c.Email.SMTP.Port > 0, "email.smtp.port is required when email.smtp.host is set")
+		check(c.Email.From != "", "email.from is required when email.smtp.host is set")
+	}
+
+	return errors.Join(errs...)
+}
+
+// sortedProviders returns the OAuth provider names in a stable order, so
+// problems are always reported in the same order
+func sortedProviders(providers map[string]OAuthProviderConfig) []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,325 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig returns the defaults with the settings they leave empty filled in
+func validConfig() *Config {
+	cfg := Default()
+	cfg.ScyllaDB.Hosts = []string{"localhost"}
+	cfg.ScyllaDB.Keyspace = "pavilion_db"
+	cfg.Auth.JWT.Secret = "secret"
+	cfg.Storage.S3.Bucket = "bucket"
+	cfg.Storage.S3.Region = "eu-central-1"
+	cfg.Storage.S3.AccessKeyID = "key"
+	cfg.Storage.S3.SecretAccessKey = "secret"
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name: "s3 backend without credentials",
+			modify: func(cfg *Config) {
+				cfg.Storage.S3.AccessKeyID = ""
+				cfg.Storage.S3.SecretAccessKey = ""
+			},
+			wantErr: []string{"storage.s3.accessKeyId", "storage.s3.secretAccessKey"},
+		},
+		{
+			name: "local backend needs no s3 credentials",
+			modify: func(cfg *Config) {
+				cfg.Storage.Backend = StorageBackendLocal
+				cfg.Storage.S3 = S3Config{}
+			},
+		},
+		{
+			name: "notifications without pulsar",
+			modify: func(cfg *Config) {
+				cfg.Notification.Enabled = true
+				cfg.Pulsar.URL = ""
+			},
+			wantErr: []string{"pulsar.url"},
+		},
+		{
+			name: "pulsar url unused when notifications are disabled",
+			modify: func(cfg *Config) {
+				cfg.Notification.Enabled = false
+				cfg.Pulsar.URL = ""
+			},
+		},
+		{
+			name: "oauth provider without secret",
+			modify: func(cfg *Config) {
+				cfg.Auth.OAuth = map[string]OAuthProviderConfig{
+					"github": {ClientID: "id", RedirectURL: "http://localhost/callback"},
+				}
+			},
+			wantErr: []string{"auth.oauth.github needs a clientSecret"},
+		},
+		{
+			name: "video tiering with the local backend and a class needing restores",
+			modify: func(cfg *Config) {
+				cfg.Storage.Backend = StorageBackendLocal
+				cfg.Video.Tiering.Enabled = true
+				cfg.Video.Tiering.StorageClass = "GLACIER"
+			},
+			wantErr: []string{"needs the s3 storage backend", `unknown video tiering storageClass "GLACIER"`},
+		},
+		{
+			name: "video comment counts without a subscription or batch size",
+			modify: func(cfg *Config) {
+				cfg.Video.CommentCounts.Subscription = ""
+				cfg.Video.CommentCounts.BatchSize = 0
+			},
+			wantErr: []string{"video.commentCounts.subscription", "positive reconcileInterval and batchSize"},
+		},
+		{
+			name: "video deletion cleanup without a subscription",
+			modify: func(cfg *Config) {
+				cfg.Video.DeletionCleanup.Subscription = ""
+			},
+			wantErr: []string{"video.deletionCleanup.subscription is required"},
+		},
+		{
+			name: "notifications without a follower subscription",
+			modify: func(cfg *Config) {
+				cfg.Notification.Enabled = true
+				cfg.Notification.FollowerSubscription = ""
+			},
+			wantErr: []string{"notification.follower_subscription is required"},
+		},
+		{
+			name: "entitlement webhook without a tolerance",
+			modify: func(cfg *Config) {
+				cfg.Entitlements.WebhookSecret = "secret"
+				cfg.Entitlements.WebhookTolerance = 0
+			},
+			wantErr: []string{"entitlements.webhookTolerance must be positive"},
+		},
+		{
+			name: "replication without a stale timeout",
+			modify: func(cfg *Config) {
+				cfg.Storage.IPFS.Replication.StaleAfter = 0
+			},
+			wantErr: []string{"storage.ipfs.replication.staleAfter must be positive"},
+		},
+		{
+			name: "trusted proxy that is not an address",
+			modify: func(cfg *Config) {
+				cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.1", "proxy.internal"}
+			},
+			wantErr: []string{`trustedProxies entry "proxy.internal"`},
+		},
+		{
+			name: "comments without a length limit",
+			modify: func(cfg *Config) {
+				cfg.Comments.MaxLength = 0
+			},
+			wantErr: []string{"comments.maxLength"},
+		},
+		{
+			name: "comments with an unknown filter action",
+			modify: func(cfg *Config) {
+				cfg.Comments.FilterAction = "delete"
+			},
+			wantErr: []string{"comments.filterAction"},
+		},
+		{
+			name: "video temp budget smaller than an upload",
+			modify: func(cfg *Config) {
+				cfg.Video.Temp.Dir = ""
+				cfg.Video.Temp.MaxBytes = cfg.Video.MaxSize - 1
+			},
+			wantErr: []string{"video.temp.dir is required", "video.temp.maxBytes must be 0 or at least video.maxSize"},
+		},
+		{
+			name: "jobs without workers or a poll interval",
+			modify: func(cfg *Config) {
+				cfg.Jobs.Workers = 0
+				cfg.Jobs.PollInterval = 0
+			},
+			wantErr: []string{"at least one worker", "positive pollInterval and timeout"},
+		},
+		{
+			name: "signed cdn without a domain or signing key",
+			modify: func(cfg *Config) {
+				cfg.Storage.CDN.Enabled = true
+				cfg.Storage.CDN.SignURLs = true
+			},
+			wantErr: []string{"storage.cdn.domain", "keyPairId and privateKey"},
+		},
+		{
+			name: "unknown cdn provider",
+			modify: func(cfg *Config) {
+				cfg.Storage.CDN = CDNConfig{Enabled: true, Provider: "akamai", Domain: "cdn.example.com"}
+			},
+			wantErr: []string{`unknown CDN provider "akamai"`},
+		},
+		{
+			name: "p2p without a topic or a usable public url",
+			modify: func(cfg *Config) {
+				cfg.P2P = P2PConfig{Enabled: true, PublicURL: "pavilion.example.com", ProvideTimeout: time.Minute}
+			},
+			wantErr: []string{"p2p.topic", "p2p.publicUrl"},
+		},
+		{
+			name: "p2p settings unused when disabled",
+			modify: func(cfg *Config) {
+				cfg.P2P = P2PConfig{PublicURL: "pavilion.example.com"}
+			},
+		},
+		{
+			name: "federation without a usable key",
+			modify: func(cfg *Config) {
+				cfg.Federation.Enabled = true
+				cfg.Federation.PrivateKey = "c2hvcnQ="
+				cfg.Federation.PageSize = 1000
+			},
+			wantErr: []string{"federation.privateKey", "federation.pageSize"},
+		},
+		{
+			name: "federation with a key",
+			modify: func(cfg *Config) {
+				cfg.Federation.Enabled = true
+				cfg.Federation.PrivateKey = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+			},
+		},
+		{
+			name: "activitypub without a public url",
+			modify: func(cfg *Config) {
+				cfg.ActivityPub.Enabled = true
+				cfg.ActivityPub.WatchURL = "https://pavilion.example.com/watch"
+			},
+			wantErr: []string{"activityPub.publicUrl", "activityPub.watchUrl"},
+		},
+		{
+			name: "activitypub with a public url",
+			modify: func(cfg *Config) {
+				cfg.ActivityPub.Enabled = true
+				cfg.ActivityPub.PublicURL = "https://pavilion.example.com/api/v1"
+				cfg.ActivityPub.WatchURL = "https://pavilion.example.com/watch/{id}"
+			},
+		},
+		{
+			name: "feeds with an invalid site url and too many items",
+			modify: func(cfg *Config) {
+				cfg.Feeds.Enabled = true
+				cfg.Feeds.SiteURL = "pavilion.example.com"
+				cfg.Feeds.Items = 1000
+			},
+			wantErr: []string{"feeds.siteUrl", "feeds.items"},
+		},
+		{
+			name: "sharing without page urls",
+			modify: func(cfg *Config) {
+				cfg.Sharing.Enabled = true
+				cfg.Sharing.SiteURL = "https://pavilion.example.com"
+			},
+			wantErr: []string{"sharing.watchUrl", "sharing.embedUrl"},
+		},
+		{
+			name: "sharing with page urls",
+			modify: func(cfg *Config) {
+				cfg.Sharing.Enabled = true
+				cfg.Sharing.SiteURL = "https://pavilion.example.com"
+				cfg.Sharing.WatchURL = "https://pavilion.example.com/watch/{id}"
+				cfg.Sharing.EmbedURL = "https://pavilion.example.com/embed/{id}"
+			},
+		},
+		{
+			name: "embed without domains",
+			modify: func(cfg *Config) {
+				cfg.Embed.Enabled = true
+				cfg.Embed.MaxDomains = 0
+			},
+			wantErr: []string{"embed.maxDomains"},
+		},
+		{
+			name: "live with defaults",
+			modify: func(cfg *Config) {
+				cfg.Live.Enabled = true
+			},
+		},
+		{
+			name: "live with an http ingest url",
+			modify: func(cfg *Config) {
+				cfg.Live.Enabled = true
+				cfg.Live.IngestURL = "http://live.example.com/live"
+				cfg.Live.PlaylistSize = 1
+				cfg.Live.MaxKeys = 0
+			},
+			wantErr: []string{"live.ingestUrl", "live.playlistSize", "live.maxKeys"},
+		},
+		{
+			name: "enabled digests need an interval and items",
+			modify: func(cfg *Config) {
+				cfg.Notification.Digest.Enabled = true
+				cfg.Notification.Digest.Interval = 0
+				cfg.Notification.Digest.MaxItems = 0
+			},
+			wantErr: []string{"notification.digest.interval", "notification.digest.max_items"},
+		},
+		{
+			name: "every problem is reported",
+			modify: func(cfg *Config) {
+				cfg.Server.Port = 0
+				cfg.Database.Host = ""
+				cfg.Auth.JWT.Secret = ""
+			},
+			wantErr: []string{"invalid server port", "database host is required", "auth.jwt.secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Expected a valid config, got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Expected an error mentioning %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to mention %q, got: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("PAVILION_TEST_SECRET", "s3cret")
+	t.Setenv("PAVILION_TEST_EMPTY", "")
+
+	got, err := expandEnv([]byte("a: ${PAVILION_TEST_SECRET}\nb: ${PAVILION_TEST_UNSET:-fallback}\nc: ${PAVILION_TEST_EMPTY}\nd: $PLAIN\n"))
+	if err != nil {
+		t.Fatalf("Failed to expand: %v", err)
+	}
+	if want := "a: s3cret\nb: fallback\nc: \nd: $PLAIN\n"; string(got) != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	_, err = expandEnv([]byte("a: ${PAVILION_TEST_UNSET}\n"))
+	if err == nil || !strings.Contains(err.Error(), "PAVILION_TEST_UNSET") {
+		t.Errorf("Expected an error naming the unset variable, got: %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
 	"gorm.io/gorm"
 )
@@ -9,6 +11,7 @@ import (
 type Service interface {
 	Connect() (*gorm.DB, error)
 	Close() error
+	Ping(ctx context.Context) error
 	GetMigrationConfig() *MigrationConfig
 }
 
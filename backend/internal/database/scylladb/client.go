@@ -1,8 +1,10 @@
 package scylladb
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/gocql/gocql"
 )
@@ -52,6 +54,11 @@ func (c *Client) Connect() error {
 	cluster.Timeout = c.config.Timeout
 	cluster.ConnectTimeout = c.config.ConnectTimeout
 
+	// Trace every query and batch
+	observer := tracing.NewCQLObserver()
+	cluster.QueryObserver = observer
+	cluster.BatchObserver = observer
+
 	// Connect without keyspace initially
 	var err error
 	c.session, err = cluster.CreateSession()
@@ -112,26 +119,10 @@ func (c *Client) Connect() error {
 		"status":   "connected",
 	})
 
-	// Update schema manager with new session
+	// Update schema manager with new session. Tables are created by the
+	// migrations, which the caller runs through Schema().
 	c.schema = NewSchemaManager(c.session, c.config, c.logger)
 
-	// Initialize schema
-	c.logger.LogInfo("Initializing schema tables", map[string]interface{}{
-		"keyspace": c.config.Keyspace,
-	})
-
-	if err := c.schema.InitializeSchema(); err != nil {
-		c.logger.LogError("Failed to initialize schema", map[string]interface{}{
-			"error":    err.Error(),
-			"keyspace": c.config.Keyspace,
-		})
-		return err
-	}
-
-	c.logger.LogInfo("Schema initialized successfully", map[string]interface{}{
-		"keyspace": c.config.Keyspace,
-	})
-
 	return nil
 }
 
@@ -144,20 +135,25 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// Schema returns the schema manager bound to the keyspace session
+func (c *Client) Schema() *SchemaManager {
+	return c.schema
+}
+
 // Session returns the current database session
 func (c *Client) Session() *gocql.Session {
 	return c.session
 }
 
 // Ping checks if the connection is alive
-func (c *Client) Ping() error {
+func (c *Client) Ping(ctx context.Context) error {
 	if c.session == nil {
 		return fmt.Errorf("session is not established")
 	}
 
 	// Simple query to verify connection
 	var version string
-	if err := c.session.Query("SELECT release_version FROM system.local").Scan(&version); err != nil {
+	if err := c.session.Query("SELECT release_version FROM system.local").WithContext(ctx).Scan(&version); err != nil {
 		return err
 	}
 
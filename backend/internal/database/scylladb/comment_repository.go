@@ -2,8 +2,8 @@ package scylladb
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -34,20 +34,20 @@ func (r *CommentRepository) GetByID(ctx context.Context, id uuid.UUID) (*comment
 
 	query := `
 		SELECT id, video_id, user_id, content, created_at, updated_at, 
-			   deleted_at, parent_id, likes, dislikes, status
+			   deleted_at, parent_id, status, links
 		FROM comments
 		WHERE id = ?
 	`
 
 	var c comment.Comment
-	var status string
+	var status, links string
 	var parentIDBytes []byte
 	parentIDBytes = nil // Initialize as nil to properly handle NULL values
 
 	err := r.session.Query(query, idBytes).WithContext(ctx).Scan(
 		&c.ID, &c.VideoID, &c.UserID, &c.Content,
 		&c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
-		&parentIDBytes, &c.Likes, &c.Dislikes, &status,
+		&parentIDBytes, &status, &links,
 	)
 
 	if err != nil {
@@ -77,12 +77,22 @@ func (r *CommentRepository) GetByID(ctx context.Context, id uuid.UUID) (*comment
 	}
 
 	c.Status = comment.Status(status)
+	c.Links = decodeLinks(links)
 
-	return &c, nil
+	found := []comment.Comment{c}
+	if err := r.fillCounts(ctx, found); err != nil {
+		return nil, err
+	}
+	return &found[0], nil
 }
 
-// GetByVideoID retrieves comments for a video with pagination
+// GetByVideoID retrieves a page of comments for a video. The pagination
+// totals are left to the caller; see Count.
 func (r *CommentRepository) GetByVideoID(ctx context.Context, options comment.CommentFilterOptions) (comment.PaginatedComments, error) {
+	if options.SortBy == "likes" {
+		return r.getByVideoIDByLikes(ctx, options)
+	}
+
 	// Initialize result
 	result := comment.PaginatedComments{
 		Comments:    []comment.Comment{},
@@ -96,7 +106,7 @@ func (r *CommentRepository) GetByVideoID(ctx context.Context, options comment.Co
 	// Query to get comments by video ID
 	query := `
 		SELECT c.id, c.video_id, c.user_id, c.content, c.created_at, c.updated_at, 
-			   c.deleted_at, c.parent_id, c.likes, c.dislikes, c.status
+			   c.deleted_at, c.parent_id, c.status, c.links
 		FROM comments c
 		JOIN comments_by_video cv ON c.id = cv.comment_id
 		WHERE cv.video_id = ? AND c.parent_id IS NULL AND c.deleted_at IS NULL
@@ -114,13 +124,13 @@ func (r *CommentRepository) GetByVideoID(ctx context.Context, options comment.Co
 	// Process results
 	for iter.Scanner().Next() {
 		var c comment.Comment
-		var status string
+		var status, links string
 		var parentID *uuid.UUID
 
 		err := iter.Scanner().Scan(
 			&c.ID, &c.VideoID, &c.UserID, &c.Content,
 			&c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
-			&parentID, &c.Likes, &c.Dislikes, &status,
+			&parentID, &status, &links,
 		)
 		if err != nil {
 			r.logger.LogError("Error scanning comment", map[string]interface{}{"error": err.Error()})
@@ -129,6 +139,7 @@ func (r *CommentRepository) GetByVideoID(ctx context.Context, options comment.Co
 
 		c.Status = comment.Status(status)
 		c.ParentID = parentID
+		c.Links = decodeLinks(links)
 
 		result.Comments = append(result.Comments, c)
 	}
@@ -138,22 +149,161 @@ func (r *CommentRepository) GetByVideoID(ctx context.Context, options comment.Co
 		return result, err
 	}
 
-	// Get total count
-	count, err := r.Count(ctx, options.VideoID)
-	if err != nil {
+	if err := r.fillCounts(ctx, result.Comments); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// getByVideoIDByLikes retrieves a page of comments for a video, most liked
+// first, from the comments_by_video_likes index. Rows a concurrent reaction
+// or delete left behind are reconciled with the counters on the way.
+func (r *CommentRepository) getByVideoIDByLikes(ctx context.Context, options comment.CommentFilterOptions) (comment.PaginatedComments, error) {
+	result := comment.PaginatedComments{
+		Comments:    []comment.Comment{},
+		CurrentPage: options.Page,
+	}
+
+	offset := (options.Page - 1) * options.Limit
+
+	query := `
+		SELECT likes, comment_id
+		FROM comments_by_video_likes
+		WHERE video_id = ?
+	`
+	iter := r.session.Query(query, options.VideoID).WithContext(ctx).PageSize(offset + options.Limit).Iter()
+
+	// Read until the page has as many comments as it can hold, counting
+	// each comment once
+	var (
+		rows []likesIndexRow
+		row  likesIndexRow
+	)
+	seen := make(map[uuid.UUID]bool)
+	for len(seen) < offset+options.Limit && iter.Scan(&row.Likes, &row.CommentID) {
+		rows = append(rows, row)
+		seen[row.CommentID] = true
+	}
+	if err := iter.Close(); err != nil {
+		r.logger.LogError("Error reading comments by likes", map[string]interface{}{
+			"error":   err.Error(),
+			"videoID": options.VideoID,
+		})
 		return result, err
 	}
 
-	// Calculate pagination info
-	result.TotalCount = count
-	result.TotalPages = int(math.Ceil(float64(count) / float64(options.Limit)))
-	result.HasNextPage = options.Page < result.TotalPages
-	result.HasPrevPage = options.Page > 1
+	page, repeated := pageLikesIndex(rows, offset, options.Limit)
+	rowsOf := make(map[uuid.UUID][]likesIndexRow)
+	for _, row := range rows {
+		rowsOf[row.CommentID] = append(rowsOf[row.CommentID], row)
+	}
+
+	repairs := r.session.NewBatch(gocql.LoggedBatch)
+	// reconcile queues the repairs of a comment's rows and reports whether
+	// the comment belongs in the index
+	reconcile := func(c *comment.Comment, commentID uuid.UUID) bool {
+		likes, listed := 0, c != nil && c.ParentID == nil && c.DeletedAt == nil
+		if listed {
+			likes = c.Likes
+		}
+		stale, missing := staleLikesRows(rowsOf[commentID], likes, listed)
+		for _, row := range stale {
+			repairs.Query("DELETE FROM comments_by_video_likes WHERE video_id = ? AND likes = ? AND comment_id = ?", options.VideoID, row.Likes, commentID)
+		}
+		if missing {
+			repairs.Query(`
+				INSERT INTO comments_by_video_likes (video_id, likes, comment_id, created_at)
+				VALUES (?, ?, ?, ?)
+			`, options.VideoID, c.Likes, c.ID, c.CreatedAt)
+		}
+		return listed
+	}
+
+	for _, row := range page {
+		c, err := r.GetByID(ctx, row.CommentID)
+		if err != nil {
+			return result, err
+		}
+		if reconcile(c, row.CommentID) {
+			result.Comments = append(result.Comments, *c)
+		}
+	}
+	for _, commentID := range repeated {
+		if !onPage(page, commentID) {
+			c, err := r.GetByID(ctx, commentID)
+			if err != nil {
+				return result, err
+			}
+			reconcile(c, commentID)
+		}
+	}
+
+	if repairs.Size() > 0 {
+		if err := r.session.ExecuteBatch(repairs.WithContext(ctx)); err != nil {
+			// The page is still right; the next read tries again
+			r.logger.LogError("Error reconciling comments by likes", map[string]interface{}{
+				"error":   err.Error(),
+				"videoID": options.VideoID,
+			})
+		}
+	}
 
 	return result, nil
 }
 
-// GetReplies retrieves replies for a comment with pagination
+// likesIndexRow is a row of a video's partition of comments_by_video_likes
+type likesIndexRow struct {
+	Likes     int
+	CommentID uuid.UUID
+}
+
+// pageLikesIndex picks the rows of the page starting at offset from rows
+// read in index order, counting each comment at its first row only. It also
+// returns the comments with more than one row, which concurrent reactions
+// leave behind until they are reconciled.
+func pageLikesIndex(rows []likesIndexRow, offset, limit int) (page []likesIndexRow, repeated []uuid.UUID) {
+	seen := make(map[uuid.UUID]int)
+	for _, row := range rows {
+		seen[row.CommentID]++
+		switch n := seen[row.CommentID]; {
+		case n == 1:
+			if position := len(seen) - 1; position >= offset && position < offset+limit {
+				page = append(page, row)
+			}
+		case n == 2:
+			repeated = append(repeated, row.CommentID)
+		}
+	}
+	return page, repeated
+}
+
+// staleLikesRows returns the index rows of a comment that do not match its
+// likes, all of them when the comment is no longer listed, and whether the
+// row at its likes is missing
+func staleLikesRows(rows []likesIndexRow, likes int, listed bool) (stale []likesIndexRow, missing bool) {
+	missing = listed
+	for _, row := range rows {
+		if listed && row.Likes == likes {
+			missing = false
+			continue
+		}
+		stale = append(stale, row)
+	}
+	return stale, missing
+}
+
+// onPage reports whether a comment is among the rows of a page
+func onPage(page []likesIndexRow, commentID uuid.UUID) bool {
+	for _, row := range page {
+		if row.CommentID == commentID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetReplies retrieves a page of replies to a comment. The pagination
+// totals are left to the caller; see CountReplies.
 func (r *CommentRepository) GetReplies(ctx context.Context, options comment.CommentFilterOptions) (comment.PaginatedComments, error) {
 	// Initialize result
 	result := comment.PaginatedComments{
@@ -172,7 +322,7 @@ func (r *CommentRepository) GetReplies(ctx context.Context, options comment.Comm
 	// Query to get replies
 	query := `
 		SELECT c.id, c.video_id, c.user_id, c.content, c.created_at, c.updated_at, 
-			   c.deleted_at, c.parent_id, c.likes, c.dislikes, c.status
+			   c.deleted_at, c.parent_id, c.status, c.links
 		FROM comments c
 		JOIN replies r ON c.id = r.comment_id
 		WHERE r.parent_id = ? AND c.deleted_at IS NULL
@@ -190,13 +340,13 @@ func (r *CommentRepository) GetReplies(ctx context.Context, options comment.Comm
 	// Process results
 	for iter.Scanner().Next() {
 		var c comment.Comment
-		var status string
+		var status, links string
 		var parentID *uuid.UUID
 
 		err := iter.Scanner().Scan(
 			&c.ID, &c.VideoID, &c.UserID, &c.Content,
 			&c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
-			&parentID, &c.Likes, &c.Dislikes, &status,
+			&parentID, &status, &links,
 		)
 		if err != nil {
 			r.logger.LogError("Error scanning reply", map[string]interface{}{"error": err.Error()})
@@ -205,6 +355,7 @@ func (r *CommentRepository) GetReplies(ctx context.Context, options comment.Comm
 
 		c.Status = comment.Status(status)
 		c.ParentID = parentID
+		c.Links = decodeLinks(links)
 
 		result.Comments = append(result.Comments, c)
 	}
@@ -214,39 +365,17 @@ func (r *CommentRepository) GetReplies(ctx context.Context, options comment.Comm
 		return result, err
 	}
 
-	// Count total replies
-	countQuery := `
-		SELECT COUNT(*)
-		FROM replies
-		WHERE parent_id = ?
-	`
-
-	var count int
-	if err := r.session.Query(countQuery, options.ParentID).WithContext(ctx).Scan(&count); err != nil {
-		r.logger.LogError("Error counting replies", map[string]interface{}{
-			"error":     err.Error(),
-			"commentID": *options.ParentID,
-		})
+	if err := r.fillCounts(ctx, result.Comments); err != nil {
 		return result, err
 	}
-
-	// Calculate pagination info
-	result.TotalCount = count
-	result.TotalPages = int(math.Ceil(float64(count) / float64(options.Limit)))
-	result.HasNextPage = options.Page < result.TotalPages
-	result.HasPrevPage = options.Page > 1
-
 	return result, nil
 }
 
 // Create creates a new comment
 func (r *CommentRepository) Create(ctx context.Context, c *comment.Comment) error {
-	fmt.Printf("DEBUG REPO: Starting Create for comment ID %s, videoID %s\n", c.ID.String(), c.VideoID.String())
-
 	// Set default values if not provided
 	if c.ID == uuid.Nil {
 		c.ID = uuid.New()
-		fmt.Printf("DEBUG REPO: Generated new comment ID: %s\n", c.ID.String())
 	}
 	if c.CreatedAt.IsZero() {
 		c.CreatedAt = time.Now().UTC()
@@ -258,19 +387,13 @@ func (r *CommentRepository) Create(ctx context.Context, c *comment.Comment) erro
 		c.Status = comment.StatusActive
 	}
 
-	// Log what we're about to do
-	r.logger.LogInfo("Creating comment in ScyllaDB", map[string]interface{}{
-		"commentID":   c.ID.String(),
-		"videoID":     c.VideoID.String(),
-		"userID":      c.UserID.String(),
-		"hasParentID": c.ParentID != nil,
+	r.logger.LogDebug("Creating comment in ScyllaDB", map[string]interface{}{
+		"commentID":     c.ID.String(),
+		"videoID":       c.VideoID.String(),
+		"userID":        c.UserID.String(),
+		"hasParentID":   c.ParentID != nil,
+		"contentLength": len(c.Content),
 	})
-	fmt.Printf("DEBUG REPO: User ID: %s, Content length: %d\n", c.UserID.String(), len(c.Content))
-	if c.ParentID != nil {
-		fmt.Printf("DEBUG REPO: This is a reply to comment: %s\n", c.ParentID.String())
-	} else {
-		fmt.Printf("DEBUG REPO: This is a top-level comment\n")
-	}
 
 	// Convert UUIDs to byte arrays for ScyllaDB
 	commentIDBytes, _ := c.ID.MarshalBinary()
@@ -282,13 +405,8 @@ func (r *CommentRepository) Create(ctx context.Context, c *comment.Comment) erro
 		// Only marshal the parent ID if it's not nil
 		bytes, _ := c.ParentID.MarshalBinary()
 		parentIDBytes = bytes
-		fmt.Printf("DEBUG REPO: Marshaled parent ID to bytes\n")
-	} else {
-		fmt.Printf("DEBUG REPO: Parent ID is nil, using nil value directly\n")
 	}
 
-	fmt.Printf("DEBUG REPO: Successfully marshaled UUIDs to binary\n")
-
 	// Create batch to insert comment and update indexes
 	batch := r.session.NewBatch(gocql.LoggedBatch)
 
@@ -296,14 +414,13 @@ func (r *CommentRepository) Create(ctx context.Context, c *comment.Comment) erro
 	commentQuery := `
 		INSERT INTO comments (
 			id, video_id, user_id, content, created_at, updated_at, 
-			deleted_at, parent_id, likes, dislikes, status
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			deleted_at, parent_id, status, links
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	fmt.Printf("DEBUG REPO: Comment query: %s\n", commentQuery)
 	batch.Query(commentQuery,
 		commentIDBytes, videoIDBytes, userIDBytes, c.Content,
 		c.CreatedAt, c.UpdatedAt, c.DeletedAt,
-		parentIDBytes, c.Likes, c.Dislikes, string(c.Status),
+		parentIDBytes, string(c.Status), encodeLinks(c.Links),
 	)
 
 	// Update comment_by_video index
@@ -311,7 +428,6 @@ func (r *CommentRepository) Create(ctx context.Context, c *comment.Comment) erro
 		INSERT INTO comments_by_video (video_id, comment_id, created_at)
 		VALUES (?, ?, ?)
 	`
-	fmt.Printf("DEBUG REPO: Video index query: %s\n", videoIndexQuery)
 	batch.Query(videoIndexQuery, videoIDBytes, commentIDBytes, c.CreatedAt)
 
 	// Update replies index if this is a reply
@@ -320,11 +436,15 @@ func (r *CommentRepository) Create(ctx context.Context, c *comment.Comment) erro
 			INSERT INTO replies (parent_id, comment_id, created_at)
 			VALUES (?, ?, ?)
 		`
-		fmt.Printf("DEBUG REPO: Reply index query: %s\n", replyIndexQuery)
 		batch.Query(replyIndexQuery, parentIDBytes, commentIDBytes, c.CreatedAt)
+	} else {
+		// Index top-level comments for sorting by likes
+		batch.Query(`
+			INSERT INTO comments_by_video_likes (video_id, likes, comment_id, created_at)
+			VALUES (?, ?, ?, ?)
+		`, videoIDBytes, c.Likes, commentIDBytes, c.CreatedAt)
 	}
 
-	fmt.Printf("DEBUG REPO: Executing batch with %d statements\n", batch.Size())
 	// Execute batch
 	if err := r.session.ExecuteBatch(batch); err != nil {
 		r.logger.LogError("Error creating comment", map[string]interface{}{
@@ -332,33 +452,48 @@ func (r *CommentRepository) Create(ctx context.Context, c *comment.Comment) erro
 			"commentID": c.ID.String(),
 			"videoID":   c.VideoID.String(),
 			"errorType": fmt.Sprintf("%T", err),
-			"commentID": c.ID.String(),
-			"videoID":   c.VideoID.String(),
-			"errorType": fmt.Sprintf("%T", err),
 		})
-		fmt.Printf("DEBUG REPO: Batch execution failed: %v (type: %T)\n", err, err)
 		return fmt.Errorf("failed to execute batch: %w", err)
 	}
 
-	r.logger.LogInfo("Comment created successfully", map[string]interface{}{
-		"commentID": c.ID.String(),
+	r.logger.LogDebug("Comment created", map[string]interface{}{
+		"commentID":  c.ID.String(),
+		"statements": batch.Size(),
 	})
-	fmt.Printf("DEBUG REPO: Comment created successfully\n")
+
+	if c.ParentID != nil {
+		r.addReplyCount(ctx, *c.ParentID, 1)
+	}
 
 	return nil
 }
 
-// Update updates a comment's content
-func (r *CommentRepository) Update(ctx context.Context, id uuid.UUID, content string) error {
+// addReplyCount moves a comment's count in comment_reply_counts. Counter
+// updates cannot join the logged batch that stores the reply, so they follow
+// it; a failure is logged rather than returned, since the reply is already
+// stored and retrying it would store it twice.
+func (r *CommentRepository) addReplyCount(ctx context.Context, parentID uuid.UUID, delta int) {
+	if err := r.session.Query("UPDATE comment_reply_counts SET replies = replies + ? WHERE comment_id = ?", delta, parentID).
+		WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError("Error updating reply count", map[string]interface{}{
+			"error":     err.Error(),
+			"commentID": parentID,
+			"delta":     delta,
+		})
+	}
+}
+
+// Update updates a comment's content and the links found in it
+func (r *CommentRepository) Update(ctx context.Context, id uuid.UUID, content string, links []comment.Link) error {
 	now := time.Now().UTC()
 
 	query := `
 		UPDATE comments
-		SET content = ?, updated_at = ?
+		SET content = ?, links = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	if err := r.session.Query(query, content, now, id).WithContext(ctx).Exec(); err != nil {
+	if err := r.session.Query(query, content, encodeLinks(links), now, id).WithContext(ctx).Exec(); err != nil {
 		r.logger.LogError("Error updating comment", map[string]interface{}{
 			"error":     err.Error(),
 			"commentID": id,
@@ -369,18 +504,41 @@ func (r *CommentRepository) Update(ctx context.Context, id uuid.UUID, content st
 	return nil
 }
 
-// Delete soft-deletes a comment
-func (r *CommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	now := time.Now().UTC()
+// encodeLinks stores the links of a comment as JSON, or NULL when it has none
+func encodeLinks(links []comment.Link) interface{} {
+	if len(links) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(links)
+	if err != nil {
+		return nil
+	}
+	return string(b)
+}
+
+// decodeLinks reads links stored by encodeLinks. Comments written before
+// links were detected have none.
+func decodeLinks(raw string) []comment.Link {
+	if raw == "" {
+		return nil
+	}
+	var links []comment.Link
+	if err := json.Unmarshal([]byte(raw), &links); err != nil {
+		return nil
+	}
+	return links
+}
 
+// UpdateStatus sets the status of a comment
+func (r *CommentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status comment.Status) error {
 	query := `
 		UPDATE comments
-		SET deleted_at = ?, status = ?
+		SET status = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	if err := r.session.Query(query, now, string(comment.StatusHidden), id).WithContext(ctx).Exec(); err != nil {
-		r.logger.LogError("Error soft deleting comment", map[string]interface{}{
+	if err := r.session.Query(query, string(status), time.Now().UTC(), id).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError("Error updating comment status", map[string]interface{}{
 			"error":     err.Error(),
 			"commentID": id,
 		})
@@ -390,7 +548,92 @@ func (r *CommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-// Count gets total number of comments for a video
+// Delete soft-deletes a comment and takes it out of its video's index, so
+// it no longer counts towards the video's comments. Deleting a reply the
+// first time also takes it out of its parent's reply count; top-level
+// comments leave the likes index.
+func (r *CommentRepository) Delete(ctx context.Context, c *comment.Comment) error {
+	now := time.Now().UTC()
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`
+		UPDATE comments
+		SET deleted_at = ?, status = ?
+		WHERE id = ?
+	`, now, string(comment.StatusHidden), c.ID)
+	batch.Query("DELETE FROM comments_by_video WHERE video_id = ? AND created_at = ? AND comment_id = ?", c.VideoID, c.CreatedAt, c.ID)
+	if c.ParentID == nil {
+		batch.Query("DELETE FROM comments_by_video_likes WHERE video_id = ? AND likes = ? AND comment_id = ?", c.VideoID, c.Likes, c.ID)
+	}
+
+	if err := r.session.ExecuteBatch(batch.WithContext(ctx)); err != nil {
+		r.logger.LogError("Error soft deleting comment", map[string]interface{}{
+			"error":     err.Error(),
+			"commentID": c.ID,
+		})
+		return err
+	}
+
+	if c.ParentID != nil && c.DeletedAt == nil {
+		r.addReplyCount(ctx, *c.ParentID, -1)
+	}
+
+	return nil
+}
+
+// deleteByVideoBatch is how many comments DeleteByVideo soft-deletes per batch
+const deleteByVideoBatch = 100
+
+// DeleteByVideo soft-deletes every comment still listed on a video, then
+// drops the video's partitions of comments_by_video and
+// comments_by_video_likes. Running it again after a failure picks up the
+// comments that are still listed.
+func (r *CommentRepository) DeleteByVideo(ctx context.Context, videoID uuid.UUID) (int, error) {
+	var ids []uuid.UUID
+	iter := r.session.Query("SELECT comment_id FROM comments_by_video WHERE video_id = ?", videoID).WithContext(ctx).Iter()
+	var id uuid.UUID
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		r.logger.LogError("Error listing comments of deleted video", map[string]interface{}{
+			"error":   err.Error(),
+			"videoID": videoID,
+		})
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	for start := 0; start < len(ids); start += deleteByVideoBatch {
+		batch := r.session.NewBatch(gocql.UnloggedBatch)
+		for _, id := range ids[start:min(start+deleteByVideoBatch, len(ids))] {
+			batch.Query("UPDATE comments SET deleted_at = ?, status = ? WHERE id = ?", now, string(comment.StatusHidden), id)
+		}
+		if err := r.session.ExecuteBatch(batch.WithContext(ctx)); err != nil {
+			r.logger.LogError("Error soft deleting comments of deleted video", map[string]interface{}{
+				"error":   err.Error(),
+				"videoID": videoID,
+			})
+			return start, err
+		}
+	}
+
+	// The indexes go last, so a failure above leaves the rest findable
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("DELETE FROM comments_by_video WHERE video_id = ?", videoID)
+	batch.Query("DELETE FROM comments_by_video_likes WHERE video_id = ?", videoID)
+	if err := r.session.ExecuteBatch(batch.WithContext(ctx)); err != nil {
+		r.logger.LogError("Error dropping comment indexes of deleted video", map[string]interface{}{
+			"error":   err.Error(),
+			"videoID": videoID,
+		})
+		return len(ids), err
+	}
+
+	return len(ids), nil
+}
+
+// Count gets total number of comments for a video, leaving out deleted ones
 func (r *CommentRepository) Count(ctx context.Context, videoID uuid.UUID) (int, error) {
 	query := `
 		SELECT COUNT(*)
@@ -410,6 +653,54 @@ func (r *CommentRepository) Count(ctx context.Context, videoID uuid.UUID) (int,
 	return count, nil
 }
 
+// CountByVideos gets the number of comments on each of videoIDs in one
+// query. Videos without comments are left out.
+func (r *CommentRepository) CountByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	query := `
+		SELECT video_id, COUNT(*)
+		FROM comments_by_video
+		WHERE video_id IN ?
+		GROUP BY video_id
+	`
+
+	counts := make(map[uuid.UUID]int, len(videoIDs))
+	iter := r.session.Query(query, videoIDs).WithContext(ctx).Iter()
+	var videoID uuid.UUID
+	var count int
+	for iter.Scan(&videoID, &count) {
+		counts[videoID] = count
+	}
+	if err := iter.Close(); err != nil {
+		r.logger.LogError("Error counting comments of videos", map[string]interface{}{
+			"error":  err.Error(),
+			"videos": len(videoIDs),
+		})
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// CountReplies gets total number of replies to a comment
+func (r *CommentRepository) CountReplies(ctx context.Context, parentID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM replies
+		WHERE parent_id = ?
+	`
+
+	var count int
+	if err := r.session.Query(query, parentID).WithContext(ctx).Scan(&count); err != nil {
+		r.logger.LogError("Error counting replies", map[string]interface{}{
+			"error":     err.Error(),
+			"commentID": parentID,
+		})
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // GetReactions retrieves reactions for a comment with pagination
 func (r *CommentRepository) GetReactions(ctx context.Context, options comment.ReactionFilterOptions) ([]comment.Reaction, int, error) {
 	// Calculate offset
@@ -528,7 +819,8 @@ func (r *CommentRepository) GetReactionByUser(ctx context.Context, commentID, us
 	return &reaction, nil
 }
 
-// CreateOrUpdateReaction creates or updates a reaction
+// CreateOrUpdateReaction creates or updates a reaction, then moves the
+// comment's counters by the change
 func (r *CommentRepository) CreateOrUpdateReaction(ctx context.Context, reaction *comment.Reaction) error {
 	// Get existing reaction if any
 	existingReaction, err := r.GetReactionByUser(ctx, reaction.CommentID, reaction.UserID)
@@ -537,7 +829,6 @@ func (r *CommentRepository) CreateOrUpdateReaction(ctx context.Context, reaction
 	}
 
 	now := time.Now().UTC()
-	batch := r.session.NewBatch(gocql.LoggedBatch)
 
 	// Set default values
 	if reaction.CreatedAt.IsZero() {
@@ -550,36 +841,10 @@ func (r *CommentRepository) CreateOrUpdateReaction(ctx context.Context, reaction
 		INSERT INTO reactions (comment_id, user_id, type, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?)
 	`
-	batch.Query(reactionQuery,
+	if err := r.session.Query(reactionQuery,
 		reaction.CommentID, reaction.UserID, string(reaction.Type),
 		reaction.CreatedAt, reaction.UpdatedAt,
-	)
-
-	// Handle comment likes/dislikes counters
-	if existingReaction != nil && existingReaction.Type != reaction.Type {
-		// If changing reaction type, decrement the old counter
-		if existingReaction.Type == comment.TypeLike {
-			batch.Query("UPDATE comments SET likes = likes - 1 WHERE id = ?", reaction.CommentID)
-		} else if existingReaction.Type == comment.TypeDislike {
-			batch.Query("UPDATE comments SET dislikes = dislikes - 1 WHERE id = ?", reaction.CommentID)
-		}
-	}
-
-	// Increment the appropriate counter
-	if reaction.Type == comment.TypeLike {
-		// Only increment if it's a new reaction or changed type
-		if existingReaction == nil || existingReaction.Type != reaction.Type {
-			batch.Query("UPDATE comments SET likes = likes + 1 WHERE id = ?", reaction.CommentID)
-		}
-	} else if reaction.Type == comment.TypeDislike {
-		// Only increment if it's a new reaction or changed type
-		if existingReaction == nil || existingReaction.Type != reaction.Type {
-			batch.Query("UPDATE comments SET dislikes = dislikes + 1 WHERE id = ?", reaction.CommentID)
-		}
-	}
-
-	// Execute batch
-	if err := r.session.ExecuteBatch(batch); err != nil {
+	).WithContext(ctx).Exec(); err != nil {
 		r.logger.LogError("Error creating/updating reaction", map[string]interface{}{
 			"error":     err.Error(),
 			"commentID": reaction.CommentID,
@@ -588,10 +853,11 @@ func (r *CommentRepository) CreateOrUpdateReaction(ctx context.Context, reaction
 		return err
 	}
 
-	return nil
+	likes, dislikes := reactionDeltas(existingReaction, reaction.Type)
+	return r.addReactionCounts(ctx, reaction.CommentID, likes, dislikes)
 }
 
-// DeleteReaction removes a reaction
+// DeleteReaction removes a reaction and takes it out of the comment's counters
 func (r *CommentRepository) DeleteReaction(ctx context.Context, commentID, userID uuid.UUID) error {
 	// Get existing reaction to determine type for counter updates
 	existingReaction, err := r.GetReactionByUser(ctx, commentID, userID)
@@ -604,49 +870,204 @@ func (r *CommentRepository) DeleteReaction(ctx context.Context, commentID, userI
 		return nil
 	}
 
-	batch := r.session.NewBatch(gocql.LoggedBatch)
-
 	// Delete from reactions table
 	deleteQuery := `
 		DELETE FROM reactions
 		WHERE comment_id = ? AND user_id = ?
 	`
-	batch.Query(deleteQuery, commentID, userID)
+	if err := r.session.Query(deleteQuery, commentID, userID).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError("Error deleting reaction", map[string]interface{}{
+			"error":     err.Error(),
+			"commentID": commentID,
+			"userID":    userID,
+		})
+		return err
+	}
+
+	likes, dislikes := reactionDeltas(existingReaction, "")
+	return r.addReactionCounts(ctx, commentID, likes, dislikes)
+}
 
-	// Update the appropriate counter
-	if existingReaction.Type == comment.TypeLike {
-		batch.Query("UPDATE comments SET likes = likes - 1 WHERE id = ?", commentID)
-	} else if existingReaction.Type == comment.TypeDislike {
-		batch.Query("UPDATE comments SET dislikes = dislikes - 1 WHERE id = ?", commentID)
+// reactionDeltas returns how a user's reaction changing from existing to
+// next, "" when it is removed, moves a comment's likes and dislikes
+func reactionDeltas(existing *comment.Reaction, next comment.Type) (likes, dislikes int) {
+	if existing != nil {
+		if existing.Type == next {
+			return 0, 0
+		}
+		switch existing.Type {
+		case comment.TypeLike:
+			likes--
+		case comment.TypeDislike:
+			dislikes--
+		}
 	}
+	switch next {
+	case comment.TypeLike:
+		likes++
+	case comment.TypeDislike:
+		dislikes++
+	}
+	return likes, dislikes
+}
 
-	// Execute batch
-	if err := r.session.ExecuteBatch(batch); err != nil {
-		r.logger.LogError("Error deleting reaction", map[string]interface{}{
+// addReactionCounts moves a comment's counters in comment_reaction_counts.
+// Counter updates cannot be batched with other writes, so they follow the
+// reaction's own write.
+func (r *CommentRepository) addReactionCounts(ctx context.Context, commentID uuid.UUID, likes, dislikes int) error {
+	if likes == 0 && dislikes == 0 {
+		return nil
+	}
+	if err := r.session.Query(`
+		UPDATE comment_reaction_counts SET likes = likes + ?, dislikes = dislikes + ?
+		WHERE comment_id = ?
+	`, likes, dislikes, commentID).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError("Error updating reaction counts", map[string]interface{}{
 			"error":     err.Error(),
 			"commentID": commentID,
-			"userID":    userID,
+		})
+		return err
+	}
+	return r.reindexLikes(ctx, commentID, likes)
+}
+
+// reindexLikes moves a top-level comment in comments_by_video_likes after its
+// likes changed by delta. The position is the count read back from the
+// counter, so it includes concurrent reactions; the row at the count before
+// this change is deleted. When reactions race, a row neither of them deletes
+// can remain, and getByVideoIDByLikes reconciles it.
+func (r *CommentRepository) reindexLikes(ctx context.Context, commentID uuid.UUID, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	c, err := r.GetByID(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if c == nil || c.ParentID != nil || c.DeletedAt != nil {
+		return nil
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("DELETE FROM comments_by_video_likes WHERE video_id = ? AND likes = ? AND comment_id = ?", c.VideoID, c.Likes-delta, c.ID)
+	batch.Query(`
+		INSERT INTO comments_by_video_likes (video_id, likes, comment_id, created_at)
+		VALUES (?, ?, ?, ?)
+	`, c.VideoID, c.Likes, c.ID, c.CreatedAt)
+	if err := r.session.ExecuteBatch(batch.WithContext(ctx)); err != nil {
+		r.logger.LogError("Error reindexing comment by likes", map[string]interface{}{
+			"error":     err.Error(),
+			"commentID": commentID,
+		})
+		return err
+	}
+	return nil
+}
+
+// fillCounts sets the likes, dislikes and reply counts of comments from
+// their counters. Comments nobody reacted or replied to have no counter
+// rows and keep zero.
+func (r *CommentRepository) fillCounts(ctx context.Context, comments []comment.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(comments))
+	byID := make(map[uuid.UUID][]int, len(comments))
+	for i := range comments {
+		if _, ok := byID[comments[i].ID]; !ok {
+			ids = append(ids, comments[i].ID)
+		}
+		byID[comments[i].ID] = append(byID[comments[i].ID], i)
+	}
+
+	iter := r.session.Query(`
+		SELECT comment_id, likes, dislikes
+		FROM comment_reaction_counts
+		WHERE comment_id IN ?
+	`, ids).WithContext(ctx).Iter()
+	var (
+		id              uuid.UUID
+		likes, dislikes int64
+	)
+	for iter.Scan(&id, &likes, &dislikes) {
+		for _, i := range byID[id] {
+			comments[i].Likes, comments[i].Dislikes = int(likes), int(dislikes)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		r.logger.LogError("Error reading reaction counts", map[string]interface{}{
+			"error":    err.Error(),
+			"comments": len(ids),
 		})
 		return err
 	}
 
+	iter = r.session.Query(`
+		SELECT comment_id, replies
+		FROM comment_reply_counts
+		WHERE comment_id IN ?
+	`, ids).WithContext(ctx).Iter()
+	var replies int64
+	for iter.Scan(&id, &replies) {
+		for _, i := range byID[id] {
+			comments[i].ReplyCount = int(replies)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		r.logger.LogError("Error reading reply counts", map[string]interface{}{
+			"error":    err.Error(),
+			"comments": len(ids),
+		})
+		return err
+	}
+	return nil
+}
+
+// GetBannedWords gets the words a channel bans from comments on its videos,
+// in order, as sets are stored
+func (r *CommentRepository) GetBannedWords(ctx context.Context, ownerID uuid.UUID) ([]string, error) {
+	var words []string
+	err := r.session.Query("SELECT words FROM comment_banned_words WHERE owner_id = ?", ownerID).WithContext(ctx).Scan(&words)
+	if err != nil && err != gocql.ErrNotFound {
+		r.logger.LogError("Error getting banned words", map[string]interface{}{
+			"error":   err.Error(),
+			"ownerID": ownerID,
+		})
+		return nil, err
+	}
+	return words, nil
+}
+
+// SetBannedWords replaces the words a channel bans from comments on its
+// videos. The list is one row, so it is replaced whole.
+func (r *CommentRepository) SetBannedWords(ctx context.Context, ownerID uuid.UUID, words []string) error {
+	query := `
+		INSERT INTO comment_banned_words (owner_id, words, updated_at)
+		VALUES (?, ?, ?)
+	`
+	if err := r.session.Query(query, ownerID, words, time.Now().UTC()).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError("Error setting banned words", map[string]interface{}{
+			"error":   err.Error(),
+			"ownerID": ownerID,
+		})
+		return err
+	}
 	return nil
 }
 
 // GetReactionCounts gets the count of likes and dislikes for a comment
 func (r *CommentRepository) GetReactionCounts(ctx context.Context, commentID uuid.UUID) (int, int, error) {
-	// We can either query the comments table directly for the cached counters
-	// or count from the reactions table for accuracy
-
-	// Using the cached counters for performance
 	query := `
 		SELECT likes, dislikes
-		FROM comments
-		WHERE id = ?
+		FROM comment_reaction_counts
+		WHERE comment_id = ?
 	`
 
-	var likes, dislikes int
+	var likes, dislikes int64
 	if err := r.session.Query(query, commentID).WithContext(ctx).Scan(&likes, &dislikes); err != nil {
+		if err == gocql.ErrNotFound {
+			return 0, 0, nil
+		}
 		r.logger.LogError("Error getting reaction counts", map[string]interface{}{
 			"error":     err.Error(),
 			"commentID": commentID,
@@ -654,5 +1075,5 @@ func (r *CommentRepository) GetReactionCounts(ctx context.Context, commentID uui
 		return 0, 0, err
 	}
 
-	return likes, dislikes, nil
+	return int(likes), int(dislikes), nil
 }
@@ -0,0 +1,78 @@
+package scylladb
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+)
+
+func TestReactionDeltas(t *testing.T) {
+	like := &comment.Reaction{Type: comment.TypeLike}
+	dislike := &comment.Reaction{Type: comment.TypeDislike}
+
+	tests := map[string]struct {
+		existing        *comment.Reaction
+		next            comment.Type
+		likes, dislikes int
+	}{
+		"new like":          {nil, comment.TypeLike, 1, 0},
+		"new dislike":       {nil, comment.TypeDislike, 0, 1},
+		"same like again":   {like, comment.TypeLike, 0, 0},
+		"like to dislike":   {like, comment.TypeDislike, -1, 1},
+		"dislike to like":   {dislike, comment.TypeLike, 1, -1},
+		"like removed":      {like, "", -1, 0},
+		"dislike removed":   {dislike, "", 0, -1},
+		"nothing to remove": {nil, "", 0, 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			likes, dislikes := reactionDeltas(tt.existing, tt.next)
+			assert.Equal(t, tt.likes, likes)
+			assert.Equal(t, tt.dislikes, dislikes)
+		})
+	}
+}
+
+func TestPageLikesIndex(t *testing.T) {
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	// b was liked twice at once, and both reactions left its old row behind
+	rows := []likesIndexRow{{9, a}, {7, b}, {5, c}, {5, b}, {2, d}}
+
+	page, repeated := pageLikesIndex(rows, 0, 2)
+	assert.Equal(t, []likesIndexRow{{9, a}, {7, b}}, page)
+	assert.Equal(t, []uuid.UUID{b}, repeated)
+
+	// The stale row does not take up a place on the next page
+	page, _ = pageLikesIndex(rows, 2, 2)
+	assert.Equal(t, []likesIndexRow{{5, c}, {2, d}}, page)
+
+	page, repeated = pageLikesIndex(rows, 4, 2)
+	assert.Empty(t, page)
+	assert.Equal(t, []uuid.UUID{b}, repeated)
+}
+
+func TestStaleLikesRows(t *testing.T) {
+	id := uuid.New()
+	rows := []likesIndexRow{{7, id}, {5, id}}
+
+	stale, missing := staleLikesRows(rows, 7, true)
+	assert.Equal(t, []likesIndexRow{{5, id}}, stale)
+	assert.False(t, missing)
+
+	// Neither row has the count the counter holds
+	stale, missing = staleLikesRows(rows, 8, true)
+	assert.Equal(t, rows, stale)
+	assert.True(t, missing)
+
+	// Deleted comments leave the index
+	stale, missing = staleLikesRows(rows, 0, false)
+	assert.Equal(t, rows, stale)
+	assert.False(t, missing)
+
+	stale, missing = staleLikesRows([]likesIndexRow{{3, id}}, 3, true)
+	assert.Empty(t, stale)
+	assert.False(t, missing)
+}
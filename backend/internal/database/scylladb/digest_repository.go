@@ -0,0 +1,167 @@
+package scylladb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/gocql/gocql"
+	"github.com/google/uuid"
+)
+
+// DigestRepository implements the notification.DigestStore interface
+type DigestRepository struct {
+	session *gocql.Session
+	logger  logger.Logger
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(session *gocql.Session, logger logger.Logger) *DigestRepository {
+	return &DigestRepository{
+		session: session,
+		logger:  logger,
+	}
+}
+
+// GetDigestSubscription returns the user's subscription, or nil when none was saved
+func (r *DigestRepository) GetDigestSubscription(ctx context.Context, userID uuid.UUID) (*notification.DigestSubscription, error) {
+	query := `SELECT frequency, period_start FROM digest_preferences WHERE user_id = ?`
+
+	subscription := &notification.DigestSubscription{UserID: userID}
+	if err := r.session.Query(query, userID).WithContext(ctx).Scan(&subscription.Frequency, &subscription.PeriodStart); err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, nil
+		}
+		r.logger.LogError(err, "Failed to get digest subscription")
+		return nil, fmt.Errorf("failed to get digest subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// SaveDigestSubscription replaces the user's subscription
+func (r *DigestRepository) SaveDigestSubscription(ctx context.Context, subscription *notification.DigestSubscription) error {
+	query := `INSERT INTO digest_preferences (user_id, frequency, period_start) VALUES (?, ?, ?)`
+
+	if err := r.session.Query(query,
+		subscription.UserID,
+		string(subscription.Frequency),
+		subscription.PeriodStart,
+	).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to save digest subscription")
+		return fmt.Errorf("failed to save digest subscription: %w", err)
+	}
+	return nil
+}
+
+// ListDigestSubscriptions returns the subscriptions with frequency
+func (r *DigestRepository) ListDigestSubscriptions(ctx context.Context, frequency notification.DigestFrequency) ([]*notification.DigestSubscription, error) {
+	query := `SELECT user_id, period_start FROM digest_preferences WHERE frequency = ?`
+
+	scanner := r.session.Query(query, string(frequency)).WithContext(ctx).Iter().Scanner()
+	subscriptions := []*notification.DigestSubscription{}
+	for scanner.Next() {
+		subscription := &notification.DigestSubscription{Frequency: frequency}
+		if err := scanner.Scan(&subscription.UserID, &subscription.PeriodStart); err != nil {
+			r.logger.LogError(err, "Failed to scan digest subscription")
+			return nil, fmt.Errorf("failed to scan digest subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := scanner.Err(); err != nil {
+		r.logger.LogError(err, "Failed to list digest subscriptions")
+		return nil, fmt.Errorf("failed to list digest subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// ListUndigested returns the user's unread notifications created after since
+// that no digest included, oldest first. Notifications up to the user's read
+// watermark are skipped by the query; CQL cannot filter on null columns, so
+// read_at and digested_at are checked while scanning.
+func (r *DigestRepository) ListUndigested(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*notification.Notification, error) {
+	query := `SELECT id, type, content, metadata, read_at, digested_at, created_at FROM notifications
+			WHERE user_id = ? AND created_at > ? ORDER BY created_at ASC`
+
+	watermark, err := readWatermark(ctx, r.session, userID)
+	if err != nil {
+		r.logger.LogError(err, "Failed to get read watermark")
+		return nil, err
+	}
+	if watermark.At.After(since) {
+		since = watermark.At
+	}
+
+	iter := r.session.Query(query, userID, since).WithContext(ctx).Iter()
+
+	notifications := []*notification.Notification{}
+	var metadataBytes []byte
+	var readAt gocql.UUID
+	var digestedAt time.Time
+	var emptyUUID gocql.UUID
+	for len(notifications) < limit {
+		notif := &notification.Notification{UserID: userID}
+		if !iter.Scan(&notif.ID, &notif.Type, &notif.Content, &metadataBytes, &readAt, &digestedAt, &notif.CreatedAt) {
+			break
+		}
+
+		if readAt == emptyUUID && digestedAt.IsZero() {
+			notif.Metadata = make(map[string]interface{})
+			if len(metadataBytes) > 0 {
+				if err := decodeFromJSONBytes(metadataBytes, &notif.Metadata); err != nil {
+					r.logger.LogError(err, "Failed to deserialize notification metadata")
+				}
+			}
+			notifications = append(notifications, notif)
+		}
+		metadataBytes = nil
+		readAt = emptyUUID
+		digestedAt = time.Time{}
+	}
+
+	if err := iter.Close(); err != nil {
+		r.logger.LogError(err, "Failed to list undigested notifications")
+		return nil, fmt.Errorf("failed to list undigested notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// markDigestedBatchSize caps the statements in each batch MarkDigested runs
+const markDigestedBatchSize = 100
+
+// MarkDigested sets digested_at on the notifications, in unlogged batches
+// that each update a single user's partition
+func (r *DigestRepository) MarkDigested(ctx context.Context, notifications []*notification.Notification, at time.Time) error {
+	query := `UPDATE notifications SET digested_at = ? WHERE user_id = ? AND created_at = ? AND id = ?`
+
+	var batch *gocql.Batch
+	var batchUser uuid.UUID
+	for i, notif := range notifications {
+		if batch == nil || notif.UserID != batchUser || batch.Size() >= markDigestedBatchSize {
+			if err := r.executeBatch(batch); err != nil {
+				return err
+			}
+			batch = r.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+			batchUser = notif.UserID
+		}
+		batch.Query(query, at, notif.UserID, notif.CreatedAt, notif.ID)
+		if i == len(notifications)-1 {
+			return r.executeBatch(batch)
+		}
+	}
+	return nil
+}
+
+// executeBatch runs a batch of MarkDigested, if there is one
+func (r *DigestRepository) executeBatch(batch *gocql.Batch) error {
+	if batch == nil || batch.Size() == 0 {
+		return nil
+	}
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		r.logger.LogError(err, "Failed to mark notifications digested")
+		return fmt.Errorf("failed to mark notifications digested: %w", err)
+	}
+	return nil
+}
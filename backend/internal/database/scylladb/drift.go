@@ -0,0 +1,186 @@
+package scylladb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kinds of schema drift
+const (
+	DriftMissingTable     = "missing table"
+	DriftMissingColumn    = "missing column"
+	DriftTypeMismatch     = "type mismatch"
+	DriftUnexpectedColumn = "unexpected column"
+)
+
+// Drift is one difference between the live schema and the schema the
+// migrations create
+type Drift struct {
+	Kind     string
+	Table    string
+	Column   string
+	Expected string
+	Actual   string
+}
+
+// String describes the difference
+func (d Drift) String() string {
+	switch d.Kind {
+	case DriftMissingTable:
+		return fmt.Sprintf("%s: %s", d.Kind, d.Table)
+	case DriftTypeMismatch:
+		return fmt.Sprintf("%s: %s.%s is %s, expected %s", d.Kind, d.Table, d.Column, d.Actual, d.Expected)
+	default:
+		return fmt.Sprintf("%s: %s.%s", d.Kind, d.Table, d.Column)
+	}
+}
+
+// ExpectedSchema returns the column types of each table the migrations
+// create, by table and column. Statements other than CREATE TABLE, ALTER
+// TABLE ADD/DROP and DROP TABLE do not change it.
+func ExpectedSchema(migrations []Migration) map[string]map[string]string {
+	tables := make(map[string]map[string]string)
+	for _, migration := range migrations {
+		for _, statement := range migration.Statements {
+			if table, body, ok := createTable(statement); ok {
+				if _, ok := tables[table]; ok {
+					continue
+				}
+				columns := make(map[string]string)
+				for _, definition := range splitTopLevel(body) {
+					fields := strings.Fields(definition)
+					if len(fields) < 2 || strings.EqualFold(fields[0], "PRIMARY") {
+						continue
+					}
+					cqlType := strings.TrimSpace(strings.TrimPrefix(definition, fields[0]))
+					if i := strings.Index(strings.ToUpper(cqlType), " PRIMARY KEY"); i >= 0 {
+						cqlType = cqlType[:i]
+					}
+					columns[strings.ToLower(fields[0])] = normalizeType(cqlType)
+				}
+				tables[table] = columns
+			} else if match := addColumnPattern.FindStringSubmatch(statement); match != nil {
+				if columns, ok := tables[strings.ToLower(match[1])]; ok {
+					columns[strings.ToLower(match[2])] = normalizeType(match[3])
+				}
+			} else if match := dropColumnPattern.FindStringSubmatch(statement); match != nil {
+				delete(tables[strings.ToLower(match[1])], strings.ToLower(match[2]))
+			} else if match := dropTablePattern.FindStringSubmatch(statement); match != nil {
+				delete(tables, strings.ToLower(match[1]))
+			}
+		}
+	}
+	return tables
+}
+
+// DetectDrift compares the live schema of the tables the migrations create
+// with what the migrations expect. Other tables, such as the monthly audit
+// tables, are not checked.
+func (m *SchemaManager) DetectDrift() ([]Drift, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	live := make(map[string]map[string]string)
+	iter := m.session.Query(`
+		SELECT table_name, column_name, type FROM system_schema.columns
+		WHERE keyspace_name = ?
+	`, m.config.Keyspace).Iter()
+	var table, column, cqlType string
+	for iter.Scan(&table, &column, &cqlType) {
+		if live[table] == nil {
+			live[table] = make(map[string]string)
+		}
+		live[table][column] = normalizeType(cqlType)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read live schema: %w", err)
+	}
+	return compareSchemas(ExpectedSchema(m.migrations), live), nil
+}
+
+// compareSchemas lists the differences between expected and live column
+// types, ordered by table and column
+func compareSchemas(expected, live map[string]map[string]string) []Drift {
+	var drifts []Drift
+	for table, columns := range expected {
+		liveColumns, ok := live[table]
+		if !ok {
+			drifts = append(drifts, Drift{Kind: DriftMissingTable, Table: table})
+			continue
+		}
+		for column, cqlType := range columns {
+			actual, ok := liveColumns[column]
+			switch {
+			case !ok:
+				drifts = append(drifts, Drift{Kind: DriftMissingColumn, Table: table, Column: column, Expected: cqlType})
+			case actual != cqlType:
+				drifts = append(drifts, Drift{Kind: DriftTypeMismatch, Table: table, Column: column, Expected: cqlType, Actual: actual})
+			}
+		}
+		for column, actual := range liveColumns {
+			if _, ok := columns[column]; !ok {
+				drifts = append(drifts, Drift{Kind: DriftUnexpectedColumn, Table: table, Column: column, Actual: actual})
+			}
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Table != drifts[j].Table {
+			return drifts[i].Table < drifts[j].Table
+		}
+		return drifts[i].Column < drifts[j].Column
+	})
+	return drifts
+}
+
+// createTable returns the table and the column list of a CREATE TABLE statement
+func createTable(statement string) (string, string, bool) {
+	loc := createTablePattern.FindStringSubmatchIndex(statement)
+	if loc == nil {
+		return "", "", false
+	}
+	table := strings.ToLower(statement[loc[2]:loc[3]])
+	depth := 1
+	for i := loc[1]; i < len(statement); i++ {
+		switch statement[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return table, statement[loc[1]:i], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// splitTopLevel splits a column list at commas outside parentheses and
+// angle brackets, so map<text, text> and PRIMARY KEY (a, b) stay whole
+func splitTopLevel(s string) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i, r := range s {
+		switch r {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(s[start:]))
+}
+
+// normalizeType lowercases a CQL type and removes spaces, so map<text, text>
+// matches the map<text,text> form some versions report
+func normalizeType(cqlType string) string {
+	return strings.ToLower(strings.Join(strings.Fields(cqlType), ""))
+}
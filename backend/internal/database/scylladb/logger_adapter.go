@@ -16,6 +16,16 @@ func NewLoggerAdapter(logger video.Logger) *LoggerAdapter {
 	}
 }
 
+// LogDebug logs tracing details
+func (l *LoggerAdapter) LogDebug(message string, fields map[string]interface{}) {
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	fields["component"] = "scylladb"
+
+	l.logger.LogDebug(message, fields)
+}
+
 // LogInfo logs informational messages
 func (l *LoggerAdapter) LogInfo(message string, fields map[string]interface{}) {
 	// Add a prefix to clearly identify ScyllaDB logs
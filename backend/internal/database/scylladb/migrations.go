@@ -0,0 +1,357 @@
+package scylladb
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// migrationFiles holds the versioned CQL migrations shipped with the binary
+//
+//go:embed cql/*.cql
+var migrationFiles embed.FS
+
+// ErrChecksumMismatch is returned when an applied migration's file has
+// changed since it was applied
+var ErrChecksumMismatch = errors.New("applied migration has been modified")
+
+// migrationFilePattern matches NNNN_description.cql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.cql$`)
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)\s*\(`)
+	addColumnPattern   = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(\w+)\s+ADD\s+(\w+)\s+(.+)$`)
+	dropColumnPattern  = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+(\w+)\s+DROP\s+(\w+)$`)
+	dropTablePattern   = regexp.MustCompile(`(?is)^DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(\w+)$`)
+)
+
+// Migration is one versioned schema change. CQL has no transactions, so
+// migrations only move forward and every statement must be safe to run
+// again: tables and indexes are created with IF NOT EXISTS, columns a
+// table already has are not added again and columns it lacks are not
+// dropped.
+type Migration struct {
+	Version int
+	// Name is the file name without the extension, e.g. 0001_comments
+	Name       string
+	Statements []string
+	// Checksum is the SHA-256 of the file, recorded when it is applied so
+	// later edits are detected
+	Checksum string
+}
+
+// migrationHooks run after a migration's statements, for data changes CQL
+// cannot express. They get the tables the migration created, as opposed to
+// ones that already existed.
+var migrationHooks = map[string]func(m *SchemaManager, created map[string]bool) error{
+	"0002_comments_by_video_likes": func(m *SchemaManager, created map[string]bool) error {
+		if !created["comments_by_video_likes"] {
+			return nil
+		}
+		return m.backfillCommentsByVideoLikes()
+	},
+	"0013_comment_reaction_counts": func(m *SchemaManager, created map[string]bool) error {
+		if !created["comment_reaction_counts"] {
+			return nil
+		}
+		return m.backfillCommentReactionCounts()
+	},
+	"0014_comment_reply_counts": func(m *SchemaManager, created map[string]bool) error {
+		if !created["comment_reply_counts"] {
+			return nil
+		}
+		return m.backfillCommentReplyCounts()
+	},
+}
+
+// EmbeddedMigrations returns the migrations shipped with the binary, ordered by version
+func EmbeddedMigrations() ([]Migration, error) {
+	sub, err := fs.Sub(migrationFiles, "cql")
+	if err != nil {
+		return nil, err
+	}
+	return LoadMigrations(sub)
+}
+
+// LoadMigrations reads the .cql files in the root of fsys, ordered by version
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".cql" {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named NNNN_description.cql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version == 0 {
+			return nil, fmt.Errorf("migration file %s: versions start at 1", entry.Name())
+		}
+		name := strings.TrimSuffix(entry.Name(), ".cql")
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", other, name)
+		}
+		seen[version] = name
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		statements := splitStatements(string(content))
+		if len(statements) == 0 {
+			return nil, fmt.Errorf("migration %s has no statements", name)
+		}
+		sum := sha256.Sum256(content)
+		migrations = append(migrations, Migration{
+			Version:    version,
+			Name:       name,
+			Statements: statements,
+			Checksum:   hex.EncodeToString(sum[:]),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitStatements splits a file into statements at semicolons, dropping
+// comment lines. Semicolons inside string literals are not supported.
+func splitStatements(content string) []string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// MigrationStatus describes one migration, or an applied migration whose
+// file no longer exists
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified is set when the file changed after the migration was applied
+	Modified bool
+	// Missing is set when an applied migration has no file
+	Missing bool
+}
+
+// appliedMigration is a row of the schema_migrations table
+type appliedMigration struct {
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// MigrationStatus lists every migration with whether it has been applied
+func (m *SchemaManager) MigrationStatus() ([]MigrationStatus, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			status.Modified = record.Checksum != migration.Checksum
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for version, record := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version:   version,
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: record.AppliedAt,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Plan returns the migrations Migrate would apply, without changing anything
+func (m *SchemaManager) Plan() ([]Migration, error) {
+	if m.loadErr != nil {
+		return nil, m.loadErr
+	}
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.verifyChecksums(applied); err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies the pending migrations in order. Nothing is applied while
+// an applied migration's file has been modified.
+func (m *SchemaManager) Migrate() ([]Migration, error) {
+	if err := m.session.Query(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version int PRIMARY KEY,
+			name text,
+			checksum text,
+			applied_at timestamp
+		)
+	`).Exec(); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	pending, err := m.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		m.logger.LogInfo("Applying ScyllaDB migration", map[string]interface{}{
+			"keyspace": m.config.Keyspace,
+			"name":     migration.Name,
+		})
+		if err := m.apply(migration); err != nil {
+			return pending[:i], fmt.Errorf("failed to apply migration %s: %w", migration.Name, err)
+		}
+	}
+	return pending, nil
+}
+
+// apply runs a migration's statements and hook, then records it
+func (m *SchemaManager) apply(migration Migration) error {
+	created := make(map[string]bool)
+	for _, statement := range migration.Statements {
+		if table, _, ok := createTable(statement); ok {
+			exists, err := m.tableExists(table)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				created[table] = true
+			}
+		}
+
+		// CQL has no ADD IF NOT EXISTS, so skip columns the table has
+		if match := addColumnPattern.FindStringSubmatch(statement); match != nil {
+			exists, err := m.columnExists(match[1], match[2])
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+		}
+		if match := dropColumnPattern.FindStringSubmatch(statement); match != nil {
+			exists, err := m.columnExists(match[1], match[2])
+			if err != nil {
+				return err
+			}
+			if !exists {
+				continue
+			}
+		}
+
+		if err := m.session.Query(statement).Exec(); err != nil {
+			return err
+		}
+	}
+
+	if hook, ok := migrationHooks[migration.Name]; ok {
+		if err := hook(m, created); err != nil {
+			return err
+		}
+	}
+
+	return m.session.Query(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+		migration.Version, migration.Name, migration.Checksum, time.Now()).Exec()
+}
+
+// verifyChecksums fails when an applied migration's file has changed
+func (m *SchemaManager) verifyChecksums(applied map[int]appliedMigration) error {
+	for _, migration := range m.migrations {
+		if record, ok := applied[migration.Version]; ok && record.Checksum != migration.Checksum {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, migration.Name)
+		}
+	}
+	return nil
+}
+
+// appliedMigrations returns the applied migrations by version. A keyspace
+// without the schema_migrations table has none.
+func (m *SchemaManager) appliedMigrations() (map[int]appliedMigration, error) {
+	applied := make(map[int]appliedMigration)
+	exists, err := m.tableExists("schema_migrations")
+	if err != nil || !exists {
+		return applied, err
+	}
+
+	iter := m.session.Query(`SELECT version, name, checksum, applied_at FROM schema_migrations`).Iter()
+	var (
+		version int
+		record  appliedMigration
+	)
+	for iter.Scan(&version, &record.Name, &record.Checksum, &record.AppliedAt) {
+		applied[version] = record
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// columnExists reports whether a table has a column
+func (m *SchemaManager) columnExists(table, column string) (bool, error) {
+	var existing string
+	err := m.session.Query(`
+		SELECT column_name FROM system_schema.columns
+		WHERE keyspace_name = ? AND table_name = ? AND column_name = ?
+	`, m.config.Keyspace, strings.ToLower(table), strings.ToLower(column)).Scan(&existing)
+	if err == gocql.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up column %s.%s: %w", table, column, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,124 @@
+package scylladb
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrationsAreContiguous(t *testing.T) {
+	migrations, err := EmbeddedMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, migration.Name)
+		assert.NotEmpty(t, migration.Checksum)
+	}
+}
+
+func TestEmbeddedSchemaCountsInCounterTables(t *testing.T) {
+	migrations, err := EmbeddedMigrations()
+	require.NoError(t, err)
+	schema := ExpectedSchema(migrations)
+
+	// Scylla only increments counter columns, so counts live in their own table
+	assert.Equal(t, map[string]string{"comment_id": "uuid", "likes": "counter", "dislikes": "counter"}, schema["comment_reaction_counts"])
+	assert.NotContains(t, schema["comments"], "likes")
+	assert.NotContains(t, schema["comments"], "dislikes")
+
+	assert.Equal(t, map[string]string{"comment_id": "uuid", "replies": "counter"}, schema["comment_reply_counts"])
+	assert.NotContains(t, schema["comments"], "reply_count")
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_column.cql": {Data: []byte("ALTER TABLE t ADD b text;")},
+		"0001_create.cql": {Data: []byte(`-- the first table
+CREATE TABLE IF NOT EXISTS t (
+    a uuid PRIMARY KEY
+);
+CREATE INDEX IF NOT EXISTS ON t (a);
+`)},
+		"README.md": {Data: []byte("ignored")},
+	}
+
+	migrations, err := LoadMigrations(fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, "0001_create", migrations[0].Name)
+	assert.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS t (\n    a uuid PRIMARY KEY\n)",
+		"CREATE INDEX IF NOT EXISTS ON t (a)",
+	}, migrations[0].Statements)
+	assert.Equal(t, 2, migrations[1].Version)
+}
+
+func TestLoadMigrationsRejectsBadFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name":          {"create.cql": {Data: []byte("SELECT 1;")}},
+		"version zero":      {"0000_create.cql": {Data: []byte("SELECT 1;")}},
+		"duplicate version": {"0001_a.cql": {Data: []byte("SELECT 1;")}, "01_b.cql": {Data: []byte("SELECT 1;")}},
+		"no statements":     {"0001_empty.cql": {Data: []byte("-- nothing yet\n")}},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadMigrations(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestExpectedSchema(t *testing.T) {
+	migrations := []Migration{
+		{Statements: []string{
+			`CREATE TABLE IF NOT EXISTS events (
+				day date,
+				id uuid,
+				details map<text, text>,
+				legacy text,
+				PRIMARY KEY ((day), id)
+			) WITH CLUSTERING ORDER BY (id ASC)`,
+			"CREATE INDEX IF NOT EXISTS ON events (legacy)",
+		}},
+		{Statements: []string{
+			"ALTER TABLE events ADD count int",
+			"ALTER TABLE events DROP legacy",
+			"CREATE TABLE dropped (id uuid PRIMARY KEY)",
+			"DROP TABLE IF EXISTS dropped",
+		}},
+	}
+
+	assert.Equal(t, map[string]map[string]string{
+		"events": {
+			"day":     "date",
+			"id":      "uuid",
+			"details": "map<text,text>",
+			"count":   "int",
+		},
+	}, ExpectedSchema(migrations))
+}
+
+func TestCompareSchemas(t *testing.T) {
+	expected := map[string]map[string]string{
+		"comments": {"id": "uuid", "likes": "int", "status": "text"},
+		"replies":  {"parent_id": "uuid"},
+	}
+	live := map[string]map[string]string{
+		"comments":          {"id": "uuid", "likes": "bigint", "extra": "text"},
+		"schema_migrations": {"version": "int"},
+	}
+
+	drifts := compareSchemas(expected, live)
+	require.Len(t, drifts, 4)
+	assert.Equal(t, Drift{Kind: DriftUnexpectedColumn, Table: "comments", Column: "extra", Actual: "text"}, drifts[0])
+	assert.Equal(t, Drift{Kind: DriftTypeMismatch, Table: "comments", Column: "likes", Expected: "int", Actual: "bigint"}, drifts[1])
+	assert.Equal(t, Drift{Kind: DriftMissingColumn, Table: "comments", Column: "status", Expected: "text"}, drifts[2])
+	assert.Equal(t, Drift{Kind: DriftMissingTable, Table: "replies"}, drifts[3])
+	assert.Equal(t, "type mismatch: comments.likes is bigint, expected int", drifts[1].String())
+
+	assert.Empty(t, compareSchemas(expected, expected))
+}
@@ -2,6 +2,7 @@ package scylladb
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -27,8 +28,8 @@ func NewNotificationRepository(session *gocql.Session, logger logger.Logger) *No
 
 // SaveNotification saves a notification to the database
 func (r *NotificationRepository) SaveNotification(ctx context.Context, notification *notification.Notification) error {
-	query := `INSERT INTO notifications (id, user_id, type, content, metadata, created_at) 
-			VALUES (?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO notifications (id, user_id, type, content, metadata, payload, schema_version, created_at) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
 	// Serialize metadata to bytes for storage
 	metadataBytes, err := encodeToJSONBytes(notification.Metadata)
@@ -36,16 +37,28 @@ func (r *NotificationRepository) SaveNotification(ctx context.Context, notificat
 		r.logger.LogError(err, "Failed to serialize notification metadata")
 		return fmt.Errorf("failed to serialize notification metadata: %w", err)
 	}
+	if err := notification.SetPayload(); err != nil {
+		r.logger.LogError(err, "Failed to build notification payload")
+		return fmt.Errorf("failed to build notification payload: %w", err)
+	}
 
-	// Execute the query
-	if err := r.session.Query(query,
+	// Notifications about a video are indexed by it in the same batch
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(query,
 		notification.ID,
 		notification.UserID,
 		notification.Type,
 		notification.Content,
 		metadataBytes,
+		string(notification.Payload),
+		notification.SchemaVersion,
 		notification.CreatedAt,
-	).WithContext(ctx).Exec(); err != nil {
+	)
+	if videoID, ok := notification.VideoID(); ok {
+		batch.Query(`INSERT INTO notifications_by_video (video_id, user_id, created_at, id) VALUES (?, ?, ?, ?)`,
+			videoID, notification.UserID, notification.CreatedAt, notification.ID)
+	}
+	if err := r.session.ExecuteBatch(batch.WithContext(ctx)); err != nil {
 		r.logger.LogError(err, "Failed to save notification")
 		return fmt.Errorf("failed to save notification: %w", err)
 	}
@@ -53,28 +66,187 @@ func (r *NotificationRepository) SaveNotification(ctx context.Context, notificat
 	return nil
 }
 
-// GetNotificationsByUserID retrieves notifications for a user
-func (r *NotificationRepository) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*notification.Notification, error) {
-	query := `SELECT id, user_id, type, content, metadata, read_at, created_at FROM notifications 
-			WHERE user_id = ? ORDER BY created_at DESC LIMIT ?`
-
-	// Execute the query
-	scanner := r.session.Query(query, userID, limit+offset).WithContext(ctx).Iter().Scanner()
-	
-	notifications := make([]*notification.Notification, 0)
-	
-	// Skip offset rows
-	skipped := 0
-	for scanner.Next() && skipped < offset {
-		skipped++
-	}
-	
-	count := 0
-	for scanner.Next() && count < limit {
+// GetNotification retrieves a notification by ID
+func (r *NotificationRepository) GetNotification(ctx context.Context, notificationID uuid.UUID) (*notification.Notification, error) {
+	query := `SELECT id, user_id, type, content, metadata, payload, schema_version, read_at, created_at FROM notifications 
+			WHERE id = ?`
+
+	var notif notification.Notification
+	var metadataBytes []byte
+	var payload string
+	var readAt gocql.UUID
+	if err := r.session.Query(query, notificationID).WithContext(ctx).Scan(
+		&notif.ID,
+		&notif.UserID,
+		&notif.Type,
+		&notif.Content,
+		&metadataBytes,
+		&payload,
+		&notif.SchemaVersion,
+		&readAt,
+		&notif.CreatedAt,
+	); err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, notification.ErrNotificationNotFound
+		}
+		r.logger.LogError(err, "Failed to get notification")
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	var emptyUUID gocql.UUID
+	if readAt != emptyUUID {
+		t := readAt.Time()
+		notif.ReadAt = &t
+	}
+	watermark, err := readWatermark(ctx, r.session, notif.UserID)
+	if err != nil {
+		r.logger.LogError(err, "Failed to get read watermark")
+		return nil, err
+	}
+	watermark.Apply(&notif)
+
+	notif.Metadata = make(map[string]interface{})
+	if len(metadataBytes) > 0 {
+		if err := decodeFromJSONBytes(metadataBytes, &notif.Metadata); err != nil {
+			r.logger.LogError(err, "Failed to deserialize notification metadata")
+		}
+	}
+	r.loadPayload(&notif, payload)
+
+	return &notif, nil
+}
+
+// UpdateNotification replaces the content, metadata and payload of a notification in
+// place, keeping its position in the user's list
+func (r *NotificationRepository) UpdateNotification(ctx context.Context, notification *notification.Notification) error {
+	query := `UPDATE notifications SET content = ?, metadata = ?, payload = ?, schema_version = ? 
+			WHERE user_id = ? AND created_at = ? AND id = ?`
+
+	metadataBytes, err := encodeToJSONBytes(notification.Metadata)
+	if err != nil {
+		r.logger.LogError(err, "Failed to serialize notification metadata")
+		return fmt.Errorf("failed to serialize notification metadata: %w", err)
+	}
+	if err := notification.SetPayload(); err != nil {
+		r.logger.LogError(err, "Failed to build notification payload")
+		return fmt.Errorf("failed to build notification payload: %w", err)
+	}
+
+	if err := r.session.Query(query,
+		notification.Content,
+		metadataBytes,
+		string(notification.Payload),
+		notification.SchemaVersion,
+		notification.UserID,
+		notification.CreatedAt,
+		notification.ID,
+	).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to update notification")
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotificationsByVideoID looks up the notifications indexed under a video
+// in notifications_by_video. Index rows whose notification is gone are skipped.
+func (r *NotificationRepository) GetNotificationsByVideoID(ctx context.Context, videoID uuid.UUID) ([]*notification.Notification, error) {
+	type key struct {
+		userID    uuid.UUID
+		createdAt time.Time
+		id        uuid.UUID
+	}
+	var keys []key
+	iter := r.session.Query(`SELECT user_id, created_at, id FROM notifications_by_video WHERE video_id = ?`, videoID).WithContext(ctx).Iter()
+	var k key
+	for iter.Scan(&k.userID, &k.createdAt, &k.id) {
+		keys = append(keys, k)
+	}
+	if err := iter.Close(); err != nil {
+		r.logger.LogError(err, "Failed to get notifications by video ID")
+		return nil, fmt.Errorf("failed to get notifications by video ID: %w", err)
+	}
+
+	notifications := make([]*notification.Notification, 0, len(keys))
+	for _, k := range keys {
+		notif := notification.Notification{UserID: k.userID, CreatedAt: k.createdAt, ID: k.id}
+		var metadataBytes []byte
+		var payload string
+		err := r.session.Query(`SELECT type, content, metadata, payload, schema_version FROM notifications 
+				WHERE user_id = ? AND created_at = ? AND id = ?`, k.userID, k.createdAt, k.id).WithContext(ctx).Scan(
+			&notif.Type,
+			&notif.Content,
+			&metadataBytes,
+			&payload,
+			&notif.SchemaVersion,
+		)
+		if err == gocql.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			r.logger.LogError(err, "Failed to get notification by video ID")
+			return nil, fmt.Errorf("failed to get notification by video ID: %w", err)
+		}
+
+		notif.Metadata = make(map[string]interface{})
+		if len(metadataBytes) > 0 {
+			if err := decodeFromJSONBytes(metadataBytes, &notif.Metadata); err != nil {
+				r.logger.LogError(err, "Failed to deserialize notification metadata")
+			}
+		}
+		r.loadPayload(&notif, payload)
+		notifications = append(notifications, &notif)
+	}
+
+	return notifications, nil
+}
+
+// ForgetVideo drops a video's partition of notifications_by_video
+func (r *NotificationRepository) ForgetVideo(ctx context.Context, videoID uuid.UUID) error {
+	if err := r.session.Query(`DELETE FROM notifications_by_video WHERE video_id = ?`, videoID).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to drop notifications by video ID")
+		return fmt.Errorf("failed to drop notifications by video ID: %w", err)
+	}
+	return nil
+}
+
+// GetNotificationsByUserID retrieves notifications for a user, newest first.
+// Paging is driven by a created_at cursor rather than an offset, since CQL has no OFFSET.
+func (r *NotificationRepository) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, opts notification.ListOptions) ([]*notification.Notification, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+
+	watermark, err := readWatermark(ctx, r.session, userID)
+	if err != nil {
+		r.logger.LogError(err, "Failed to get read watermark")
+		return nil, err
+	}
+
+	query := `SELECT id, user_id, type, content, metadata, payload, schema_version, read_at, created_at FROM notifications 
+			WHERE user_id = ?`
+	args := []interface{}{userID}
+	if opts.Cursor != nil {
+		query += ` AND created_at <= ?`
+		args = append(args, opts.Cursor.CreatedAt)
+	}
+	// Everything up to the watermark is read, so unread pages start after it
+	if opts.UnreadOnly && !watermark.At.IsZero() {
+		query += ` AND created_at > ?`
+		args = append(args, watermark.At)
+	}
+
+	// Unread filtering happens client side, so let the driver page through the partition
+	scanner := r.session.Query(query, args...).WithContext(ctx).PageSize(opts.Limit).Iter().Scanner()
+
+	filter := notification.NewCursorFilter(opts.Cursor)
+	notifications := make([]*notification.Notification, 0, opts.Limit)
+
+	for len(notifications) < opts.Limit && scanner.Next() {
 		var notif notification.Notification
 		var metadataBytes []byte
+		var payload string
 		var readAt gocql.UUID
-		var readAtPtr *time.Time
 
 		// Scan values from the row
 		if err := scanner.Scan(
@@ -83,6 +255,8 @@ func (r *NotificationRepository) GetNotificationsByUserID(ctx context.Context, u
 			&notif.Type,
 			&notif.Content,
 			&metadataBytes,
+			&payload,
+			&notif.SchemaVersion,
 			&readAt,
 			&notif.CreatedAt,
 		); err != nil {
@@ -90,6 +264,22 @@ func (r *NotificationRepository) GetNotificationsByUserID(ctx context.Context, u
 			return nil, fmt.Errorf("failed to scan notification row: %w", err)
 		}
 
+		if filter.Skip(notif.CreatedAt, notif.ID) {
+			continue
+		}
+
+		// Convert readAt UUID to time.Time if it's not nil
+		var emptyUUID gocql.UUID
+		if readAt != emptyUUID {
+			t := readAt.Time()
+			notif.ReadAt = &t
+		}
+		watermark.Apply(&notif)
+
+		if opts.UnreadOnly && notif.IsRead() {
+			continue
+		}
+
 		// Deserialize metadata from bytes
 		if len(metadataBytes) > 0 {
 			if err := decodeFromJSONBytes(metadataBytes, &notif.Metadata); err != nil {
@@ -100,57 +290,152 @@ func (r *NotificationRepository) GetNotificationsByUserID(ctx context.Context, u
 		} else {
 			notif.Metadata = make(map[string]interface{})
 		}
+		r.loadPayload(&notif, payload)
+
+		notifications = append(notifications, &notif)
+	}
+
+	if err := scanner.Err(); err != nil {
+		r.logger.LogError(err, "Error iterating notification results")
+		return nil, fmt.Errorf("error iterating notification results: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// GetNotificationsSince retrieves notifications created after since, oldest first.
+// Used by incremental sync, where clients replay changes in the order they happened.
+func (r *NotificationRepository) GetNotificationsSince(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*notification.Notification, error) {
+	query := `SELECT id, user_id, type, content, metadata, payload, schema_version, read_at, created_at FROM notifications 
+			WHERE user_id = ? AND created_at > ? ORDER BY created_at ASC LIMIT ?`
+
+	watermark, err := readWatermark(ctx, r.session, userID)
+	if err != nil {
+		r.logger.LogError(err, "Failed to get read watermark")
+		return nil, err
+	}
+
+	iter := r.session.Query(query, userID, since, limit).WithContext(ctx).Iter()
+
+	notifications := make([]*notification.Notification, 0, limit)
+	var metadataBytes []byte
+	var payload string
+	var readAt gocql.UUID
+	for {
+		notif := &notification.Notification{}
+		if !iter.Scan(&notif.ID, &notif.UserID, &notif.Type, &notif.Content, &metadataBytes, &payload, &notif.SchemaVersion, &readAt, &notif.CreatedAt) {
+			break
+		}
 
-		// Convert readAt UUID to time.Time if it's not nil
 		var emptyUUID gocql.UUID
 		if readAt != emptyUUID {
 			t := readAt.Time()
-			readAtPtr = &t
+			notif.ReadAt = &t
 		}
-		notif.ReadAt = readAtPtr
+		watermark.Apply(notif)
 
-		notifications = append(notifications, &notif)
-		count++
+		notif.Metadata = make(map[string]interface{})
+		if len(metadataBytes) > 0 {
+			if err := decodeFromJSONBytes(metadataBytes, &notif.Metadata); err != nil {
+				r.logger.LogError(err, "Failed to deserialize notification metadata")
+			}
+		}
+
+		r.loadPayload(notif, payload)
+
+		notifications = append(notifications, notif)
+		metadataBytes = nil
+		payload = ""
+		readAt = emptyUUID
 	}
 
-	if err := scanner.Err(); err != nil {
-		r.logger.LogError(err, "Error iterating notification results")
-		return nil, fmt.Errorf("error iterating notification results: %w", err)
+	if err := iter.Close(); err != nil {
+		r.logger.LogError(err, "Failed to get notifications since cursor")
+		return nil, fmt.Errorf("failed to get notifications since cursor: %w", err)
 	}
 
 	return notifications, nil
 }
 
-// GetUnreadCount gets the count of unread notifications for a user
+// GetUnreadCount gets the count of unread notifications for a user: those
+// newer than the read watermark that were not marked read on their own
 func (r *NotificationRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
-	query := `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`
+	state, err := r.GetReadState(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return state.UnreadCount, nil
+}
 
-	var count int
-	if err := r.session.Query(query, userID).WithContext(ctx).Scan(&count); err != nil {
-		r.logger.LogError(err, "Failed to get unread count")
-		return 0, fmt.Errorf("failed to get unread count: %w", err)
+// GetReadState returns the user's read watermark along with the notifications
+// newer than it that were marked read and the count of those that were not.
+// Only the part of the partition after the watermark is read.
+func (r *NotificationRepository) GetReadState(ctx context.Context, userID uuid.UUID) (*notification.ReadState, error) {
+	watermark, err := readWatermark(ctx, r.session, userID)
+	if err != nil {
+		r.logger.LogError(err, "Failed to get read watermark")
+		return nil, err
 	}
 
-	return count, nil
-}
+	state := &notification.ReadState{Device: watermark.Device, Read: []notification.ReadReceipt{}}
+	query := `SELECT id, read_at, read_device FROM notifications WHERE user_id = ?`
+	args := []interface{}{userID}
+	if !watermark.At.IsZero() {
+		state.LastReadAt = &watermark.At
+		state.UpdatedAt = &watermark.UpdatedAt
+		query += ` AND created_at > ?`
+		args = append(args, watermark.At)
+	}
+
+	iter := r.session.Query(query, args...).WithContext(ctx).Iter()
 
-// MarkAsRead marks a notification as read
-func (r *NotificationRepository) MarkAsRead(ctx context.Context, notificationID uuid.UUID) error {
-	// First check if the notification exists
-	checkQuery := `SELECT id FROM notifications WHERE id = ?`
 	var id uuid.UUID
-	if err := r.session.Query(checkQuery, notificationID).WithContext(ctx).Scan(&id); err != nil {
+	var readAt gocql.UUID
+	var device string
+	var emptyUUID gocql.UUID
+	for iter.Scan(&id, &readAt, &device) {
+		if readAt == emptyUUID {
+			state.UnreadCount++
+		} else {
+			state.Read = append(state.Read, notification.ReadReceipt{NotificationID: id, ReadAt: readAt.Time(), Device: device})
+		}
+		readAt = emptyUUID
+		device = ""
+	}
+
+	if err := iter.Close(); err != nil {
+		r.logger.LogError(err, "Failed to get read state")
+		return nil, fmt.Errorf("failed to get read state: %w", err)
+	}
+
+	return state, nil
+}
+
+// MarkAsRead marks one of the user's notifications as read on device. The
+// notification's clustering key is looked up first so the update names the
+// full primary key. A notification that is already read keeps its first read mark.
+func (r *NotificationRepository) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID, device string) error {
+	lookup := `SELECT user_id, created_at, read_at FROM notifications WHERE id = ?`
+	var owner uuid.UUID
+	var createdAt time.Time
+	var readAt gocql.UUID
+	if err := r.session.Query(lookup, notificationID).WithContext(ctx).Scan(&owner, &createdAt, &readAt); err != nil {
 		if err == gocql.ErrNotFound {
-			return fmt.Errorf("notification not found")
+			return notification.ErrNotificationNotFound
 		}
-		r.logger.LogError(err, "Failed to check notification existence")
-		return fmt.Errorf("failed to check notification existence: %w", err)
+		r.logger.LogError(err, "Failed to look up notification")
+		return fmt.Errorf("failed to look up notification: %w", err)
+	}
+	if owner != userID {
+		return notification.ErrNotificationNotFound
+	}
+	var emptyUUID gocql.UUID
+	if readAt != emptyUUID {
+		return nil
 	}
 
-	// Update the notification
-	query := `UPDATE notifications SET read_at = ? WHERE id = ?`
-	now := time.Now()
-	if err := r.session.Query(query, gocql.UUIDFromTime(now), notificationID).WithContext(ctx).Exec(); err != nil {
+	query := `UPDATE notifications SET read_at = ?, read_device = ? WHERE user_id = ? AND created_at = ? AND id = ?`
+	if err := r.session.Query(query, gocql.UUIDFromTime(time.Now()), device, userID, createdAt, notificationID).WithContext(ctx).Exec(); err != nil {
 		r.logger.LogError(err, "Failed to mark notification as read")
 		return fmt.Errorf("failed to mark notification as read: %w", err)
 	}
@@ -158,14 +443,43 @@ func (r *NotificationRepository) MarkAsRead(ctx context.Context, notificationID
 	return nil
 }
 
-// MarkAllAsRead marks all notifications for a user as read
-func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
-	query := `UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`
-	now := time.Now()
-	if err := r.session.Query(query, gocql.UUIDFromTime(now), userID).WithContext(ctx).Exec(); err != nil {
-		r.logger.LogError(err, "Failed to mark all notifications as read")
-		return fmt.Errorf("failed to mark all notifications as read: %w", err)
+// MarkAllAsRead moves the user's read watermark to at. The write is stamped
+// with at as its timestamp, so ScyllaDB keeps the newest watermark even when
+// devices sync out of order.
+func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID, at time.Time, device string) error {
+	query := `UPDATE notification_read_state USING TIMESTAMP ? SET last_read_at = ?, device = ?, updated_at = ? WHERE user_id = ?`
+	if err := r.session.Query(query, at.UnixMicro(), at, device, time.Now(), userID).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to move read watermark")
+		return fmt.Errorf("failed to move read watermark: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// readWatermark returns the user's read watermark, which is zero when they
+// never marked all their notifications read
+func readWatermark(ctx context.Context, session *gocql.Session, userID uuid.UUID) (notification.ReadWatermark, error) {
+	query := `SELECT last_read_at, device, updated_at FROM notification_read_state WHERE user_id = ?`
+
+	var watermark notification.ReadWatermark
+	if err := session.Query(query, userID).WithContext(ctx).Scan(&watermark.At, &watermark.Device, &watermark.UpdatedAt); err != nil {
+		if err == gocql.ErrNotFound {
+			return notification.ReadWatermark{}, nil
+		}
+		return notification.ReadWatermark{}, fmt.Errorf("failed to get read watermark: %w", err)
+	}
+	return watermark, nil
+}
+
+// loadPayload sets a scanned notification's payload, upgrading payloads of
+// older schema versions. A payload that cannot be upgraded is logged and
+// left out rather than failing the read.
+func (r *NotificationRepository) loadPayload(notif *notification.Notification, payload string) {
+	if payload != "" {
+		notif.Payload = json.RawMessage(payload)
+	}
+	if err := notif.UpgradePayload(); err != nil {
+		r.logger.LogError(err, "Failed to upgrade notification payload")
+		notif.Payload = nil
+	}
+}
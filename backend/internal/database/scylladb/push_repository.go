@@ -0,0 +1,126 @@
+package scylladb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/gocql/gocql"
+	"github.com/google/uuid"
+)
+
+// PushRepository implements the notification.PushStore interface
+type PushRepository struct {
+	session *gocql.Session
+	logger  logger.Logger
+}
+
+// NewPushRepository creates a new push subscription repository
+func NewPushRepository(session *gocql.Session, logger logger.Logger) *PushRepository {
+	return &PushRepository{
+		session: session,
+		logger:  logger,
+	}
+}
+
+// SavePushSubscription upserts the subscription for its user and endpoint
+func (r *PushRepository) SavePushSubscription(ctx context.Context, subscription *notification.PushSubscription) error {
+	query := `INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth, user_agent, expires_at, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	if err := r.session.Query(query,
+		subscription.UserID,
+		subscription.Endpoint,
+		subscription.P256dh,
+		subscription.Auth,
+		subscription.UserAgent,
+		subscription.ExpiresAt,
+		subscription.CreatedAt,
+	).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to save push subscription")
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	return nil
+}
+
+// DeletePushSubscription removes the user's subscription for endpoint
+func (r *PushRepository) DeletePushSubscription(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	query := `DELETE FROM push_subscriptions WHERE user_id = ? AND endpoint = ?`
+
+	if err := r.session.Query(query, userID, endpoint).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to delete push subscription")
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+// ListPushSubscriptions returns the user's subscriptions
+func (r *PushRepository) ListPushSubscriptions(ctx context.Context, userID uuid.UUID) ([]*notification.PushSubscription, error) {
+	query := `SELECT endpoint, p256dh, auth, user_agent, expires_at, created_at FROM push_subscriptions
+			WHERE user_id = ?`
+
+	scanner := r.session.Query(query, userID).WithContext(ctx).Iter().Scanner()
+	subscriptions := []*notification.PushSubscription{}
+	for scanner.Next() {
+		subscription := &notification.PushSubscription{UserID: userID}
+		var expiresAt time.Time
+		if err := scanner.Scan(
+			&subscription.Endpoint,
+			&subscription.P256dh,
+			&subscription.Auth,
+			&subscription.UserAgent,
+			&expiresAt,
+			&subscription.CreatedAt,
+		); err != nil {
+			r.logger.LogError(err, "Failed to scan push subscription")
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		if !expiresAt.IsZero() {
+			subscription.ExpiresAt = &expiresAt
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := scanner.Err(); err != nil {
+		r.logger.LogError(err, "Failed to list push subscriptions")
+		return nil, fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// GetPushPreferences returns the user's preferences, or nil when none were saved
+func (r *PushRepository) GetPushPreferences(ctx context.Context, userID uuid.UUID) (*notification.PushPreferences, error) {
+	query := `SELECT enabled, muted_types FROM push_preferences WHERE user_id = ?`
+
+	var enabled bool
+	var mutedTypes []string
+	if err := r.session.Query(query, userID).WithContext(ctx).Scan(&enabled, &mutedTypes); err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, nil
+		}
+		r.logger.LogError(err, "Failed to get push preferences")
+		return nil, fmt.Errorf("failed to get push preferences: %w", err)
+	}
+
+	preferences := &notification.PushPreferences{Enabled: enabled, MutedTypes: make([]notification.EventType, 0, len(mutedTypes))}
+	for _, mutedType := range mutedTypes {
+		preferences.MutedTypes = append(preferences.MutedTypes, notification.EventType(mutedType))
+	}
+	return preferences, nil
+}
+
+// SavePushPreferences replaces the user's preferences
+func (r *PushRepository) SavePushPreferences(ctx context.Context, userID uuid.UUID, preferences *notification.PushPreferences) error {
+	query := `INSERT INTO push_preferences (user_id, enabled, muted_types) VALUES (?, ?, ?)`
+
+	mutedTypes := make([]string, 0, len(preferences.MutedTypes))
+	for _, mutedType := range preferences.MutedTypes {
+		mutedTypes = append(mutedTypes, string(mutedType))
+	}
+	if err := r.session.Query(query, userID, preferences.Enabled, mutedTypes).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to save push preferences")
+		return fmt.Errorf("failed to save push preferences: %w", err)
+	}
+	return nil
+}
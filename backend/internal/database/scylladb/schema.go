@@ -2,24 +2,32 @@ package scylladb
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/gocql/gocql"
 )
 
 // SchemaManager handles ScyllaDB schema creation and migrations
 type SchemaManager struct {
-	session *gocql.Session
-	config  Config
-	logger  video.Logger
+	session    *gocql.Session
+	config     Config
+	logger     video.Logger
+	migrations []Migration
+	// loadErr is set when the embedded migrations cannot be read
+	loadErr error
 }
 
-// NewSchemaManager creates a new schema manager
+// NewSchemaManager creates a new schema manager over the embedded migrations
 func NewSchemaManager(session *gocql.Session, config Config, logger video.Logger) *SchemaManager {
+	migrations, err := EmbeddedMigrations()
 	return &SchemaManager{
-		session: session,
-		config:  config,
-		logger:  logger,
+		session:    session,
+		config:     config,
+		logger:     logger,
+		migrations: migrations,
+		loadErr:    err,
 	}
 }
 
@@ -36,140 +44,153 @@ func (m *SchemaManager) CreateKeyspaceIfNotExists() error {
 	return m.session.Query(query).Exec()
 }
 
-// InitializeSchema initializes all tables needed for the comment system
+// InitializeSchema brings the keyspace up to date by applying the pending
+// migrations in cql/
 func (m *SchemaManager) InitializeSchema() error {
 	m.logger.LogInfo("Beginning schema initialization", map[string]interface{}{
 		"keyspace": m.config.Keyspace,
 	})
 
-	// Create comments table
-	m.logger.LogInfo("Creating comments table", nil)
-	if err := m.createCommentsTable(); err != nil {
-		m.logger.LogError("Failed to create comments table", map[string]interface{}{
-			"error": err.Error(),
+	applied, err := m.Migrate()
+	if err != nil {
+		m.logger.LogError("Failed to migrate schema", map[string]interface{}{
+			"error":    err.Error(),
+			"keyspace": m.config.Keyspace,
 		})
 		return err
 	}
-	m.logger.LogInfo("Comments table created successfully", nil)
 
-	// Create comment_by_video index table
-	m.logger.LogInfo("Creating comment_by_video table", nil)
-	if err := m.createCommentByVideoTable(); err != nil {
-		m.logger.LogError("Failed to create comment_by_video table", map[string]interface{}{
-			"error": err.Error(),
-		})
-		return err
-	}
-	m.logger.LogInfo("Comment_by_video table created successfully", nil)
+	m.logger.LogInfo("Schema initialization completed successfully", map[string]interface{}{
+		"keyspace": m.config.Keyspace,
+		"applied":  len(applied),
+	})
+	return nil
+}
 
-	// Create replies index table
-	m.logger.LogInfo("Creating replies table", nil)
-	if err := m.createRepliesTable(); err != nil {
-		m.logger.LogError("Failed to create replies table", map[string]interface{}{
-			"error": err.Error(),
-		})
-		return err
+// backfillCommentsByVideoLikes indexes the top-level comments written before
+// the comments_by_video_likes table existed
+func (m *SchemaManager) backfillCommentsByVideoLikes() error {
+	iter := m.session.Query(`
+		SELECT id, video_id, likes, created_at, parent_id, deleted_at
+		FROM comments
+	`).Iter()
+
+	var (
+		id, videoID, parentID []byte
+		likes                 int
+		createdAt             time.Time
+		deletedAt             *time.Time
+		indexed               int
+	)
+	for iter.Scan(&id, &videoID, &likes, &createdAt, &parentID, &deletedAt) {
+		if parentID != nil || deletedAt != nil {
+			continue
+		}
+		if err := m.session.Query(`
+			INSERT INTO comments_by_video_likes (video_id, likes, comment_id, created_at)
+			VALUES (?, ?, ?, ?)
+		`, videoID, likes, id, createdAt).Exec(); err != nil {
+			iter.Close()
+			return fmt.Errorf("failed to index comment by likes: %w", err)
+		}
+		indexed++
 	}
-	m.logger.LogInfo("Replies table created successfully", nil)
-
-	// Create reactions table
-	m.logger.LogInfo("Creating reactions table", nil)
-	if err := m.createReactionsTable(); err != nil {
-		m.logger.LogError("Failed to create reactions table", map[string]interface{}{
-			"error": err.Error(),
-		})
-		return err
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("failed to read comments: %w", err)
 	}
-	m.logger.LogInfo("Reactions table created successfully", nil)
 
-	m.logger.LogInfo("Schema initialization completed successfully", map[string]interface{}{
-		"keyspace": m.config.Keyspace,
+	m.logger.LogInfo("Indexed existing comments by likes", map[string]interface{}{
+		"comments": indexed,
 	})
-
 	return nil
 }
 
-// Helper functions for schema creation
-func (m *SchemaManager) createCommentsTable() error {
-	m.logger.LogInfo("Creating comments table with schema", map[string]interface{}{
-		"table": "comments",
-		"columns": []string{
-			"id uuid PRIMARY KEY",
-			"video_id uuid",
-			"user_id uuid",
-			"content text",
-			"created_at timestamp",
-			"updated_at timestamp",
-			"deleted_at timestamp",
-			"parent_id uuid",
-			"likes int",
-			"dislikes int",
-			"status text",
-		},
-	})
+// backfillCommentReactionCounts counts the reactions stored before the
+// comment_reaction_counts table existed
+func (m *SchemaManager) backfillCommentReactionCounts() error {
+	iter := m.session.Query(`SELECT comment_id, type FROM reactions`).Iter()
 
-	query := `
-	CREATE TABLE IF NOT EXISTS comments (
-		id uuid PRIMARY KEY,
-		video_id uuid,
-		user_id uuid,
-		content text,
-		created_at timestamp,
-		updated_at timestamp,
-		deleted_at timestamp,
-		parent_id uuid,
-		likes int,
-		dislikes int,
-		status text
+	counts := make(map[gocql.UUID][2]int)
+	var (
+		commentID    gocql.UUID
+		reactionType string
 	)
-	`
-	return m.session.Query(query).Exec()
-}
+	for iter.Scan(&commentID, &reactionType) {
+		count := counts[commentID]
+		switch reactionType {
+		case string(comment.TypeLike):
+			count[0]++
+		case string(comment.TypeDislike):
+			count[1]++
+		}
+		counts[commentID] = count
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("failed to read reactions: %w", err)
+	}
 
-func (m *SchemaManager) createCommentByVideoTable() error {
-	m.logger.LogInfo("Creating comments_by_video table with schema", map[string]interface{}{
-		"table": "comments_by_video",
-		"columns": []string{
-			"video_id uuid",
-			"comment_id uuid",
-			"created_at timestamp",
-		},
-		"primary_key":      "(video_id, created_at, comment_id)",
-		"clustering_order": "created_at DESC, comment_id ASC",
-	})
+	for commentID, count := range counts {
+		if err := m.session.Query(`
+			UPDATE comment_reaction_counts SET likes = likes + ?, dislikes = dislikes + ?
+			WHERE comment_id = ?
+		`, count[0], count[1], commentID).Exec(); err != nil {
+			return fmt.Errorf("failed to count reactions of comment: %w", err)
+		}
+	}
 
-	query := `
-	CREATE TABLE IF NOT EXISTS comments_by_video (
-		video_id uuid,
-		comment_id uuid,
-		created_at timestamp,
-		PRIMARY KEY (video_id, created_at, comment_id)
-	) WITH CLUSTERING ORDER BY (created_at DESC, comment_id ASC)
-	`
-	return m.session.Query(query).Exec()
+	m.logger.LogInfo("Counted existing comment reactions", map[string]interface{}{
+		"comments": len(counts),
+	})
+	return nil
 }
 
-func (m *SchemaManager) createRepliesTable() error {
-	m.logger.LogInfo("Creating replies table with schema", map[string]interface{}{
-		"table": "replies",
-		"columns": []string{
-			"parent_id uuid",
-			"comment_id uuid",
-			"created_at timestamp",
-		},
-		"primary_key":      "(parent_id, created_at, comment_id)",
-		"clustering_order": "created_at DESC, comment_id ASC",
+// backfillCommentReplyCounts counts the replies stored before the
+// comment_reply_counts table existed, leaving out deleted ones
+func (m *SchemaManager) backfillCommentReplyCounts() error {
+	iter := m.session.Query(`SELECT parent_id, deleted_at FROM comments`).Iter()
+
+	counts := make(map[gocql.UUID]int)
+	var (
+		parentID  *gocql.UUID
+		deletedAt *time.Time
+	)
+	for iter.Scan(&parentID, &deletedAt) {
+		if parentID != nil && deletedAt == nil {
+			counts[*parentID]++
+		}
+		parentID, deletedAt = nil, nil
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("failed to read comments: %w", err)
+	}
+
+	for commentID, count := range counts {
+		if err := m.session.Query(`UPDATE comment_reply_counts SET replies = replies + ? WHERE comment_id = ?`,
+			count, commentID).Exec(); err != nil {
+			return fmt.Errorf("failed to count replies of comment: %w", err)
+		}
+	}
+
+	m.logger.LogInfo("Counted existing comment replies", map[string]interface{}{
+		"comments": len(counts),
 	})
+	return nil
+}
 
-	query := `
-	CREATE TABLE IF NOT EXISTS replies (
-		parent_id uuid,
-		comment_id uuid,
-		created_at timestamp,
-		PRIMARY KEY (parent_id, created_at, comment_id)
-	) WITH CLUSTERING ORDER BY (created_at DESC, comment_id ASC)
-	`
-	return m.session.Query(query).Exec()
+// tableExists reports whether a table exists in the keyspace
+func (m *SchemaManager) tableExists(table string) (bool, error) {
+	var name string
+	err := m.session.Query(`
+		SELECT table_name FROM system_schema.tables
+		WHERE keyspace_name = ? AND table_name = ?
+	`, m.config.Keyspace, table).Scan(&name)
+	if err == gocql.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", table, err)
+	}
+	return true, nil
 }
 
 func (m *SchemaManager) createReactionsTable() error {
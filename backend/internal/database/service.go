@@ -1,13 +1,25 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/accesslog"
+	"github.com/consensuslabs/pavilion-network/backend/internal/activitypub"
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/block"
 	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/embed"
+	"github.com/consensuslabs/pavilion-network/backend/internal/entitlement"
+	"github.com/consensuslabs/pavilion-network/backend/internal/federation"
+	"github.com/consensuslabs/pavilion-network/backend/internal/follow"
+	"github.com/consensuslabs/pavilion-network/backend/internal/live"
+	"github.com/consensuslabs/pavilion-network/backend/internal/moderation"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/webhook"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -62,6 +74,12 @@ func (s *DatabaseService) Connect() (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
+	// Trace every statement
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		s.logger.LogError(err, "Failed to register tracing plugin")
+		return nil, fmt.Errorf("failed to register tracing plugin: %v", err)
+	}
+
 	// Explicitly set the database
 	if err := db.Exec("USE " + s.config.Dbname).Error; err != nil {
 		s.logger.LogError(err, "Failed to switch database")
@@ -90,10 +108,12 @@ func (s *DatabaseService) Connect() (*gorm.DB, error) {
 	// Configure connection pool using values from config
 	sqlDB.SetMaxOpenConns(s.config.Pool.MaxOpen)
 	sqlDB.SetMaxIdleConns(s.config.Pool.MaxIdle)
+	sqlDB.SetConnMaxLifetime(s.config.Pool.ConnMaxLifetime)
 
 	s.logger.LogInfo("Configured connection pool", map[string]interface{}{
-		"maxOpenConns": s.config.Pool.MaxOpen,
-		"maxIdleConns": s.config.Pool.MaxIdle,
+		"maxOpenConns":    s.config.Pool.MaxOpen,
+		"maxIdleConns":    s.config.Pool.MaxIdle,
+		"connMaxLifetime": s.config.Pool.ConnMaxLifetime.String(),
 	})
 
 	// Initialize migration config now that we have the database connection
@@ -107,7 +127,7 @@ func (s *DatabaseService) Connect() (*gorm.DB, error) {
 	// Create enum type using a transaction to handle CockroachDB's transaction retry logic
 	err = db.Transaction(func(tx *gorm.DB) error {
 		// Create upload_status enum
-		if err := tx.Exec(`CREATE TYPE IF NOT EXISTS upload_status AS ENUM ('pending', 'uploading', 'completed', 'failed')`).Error; err != nil {
+		if err := tx.Exec(`CREATE TYPE IF NOT EXISTS upload_status AS ENUM ('pending', 'uploading', 'completed', 'failed', 'interrupted')`).Error; err != nil {
 			if !strings.Contains(err.Error(), "already exists") {
 				return fmt.Errorf("failed to create upload_status enum: %v", err)
 			}
@@ -119,6 +139,14 @@ func (s *DatabaseService) Connect() (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to create enums: %v", err)
 	}
 
+	// Databases created before uploads could be interrupted lack the value.
+	// New enum values cannot be used in the transaction that adds them, so
+	// this runs on its own.
+	if err := db.Exec(`ALTER TYPE upload_status ADD VALUE IF NOT EXISTS 'interrupted'`).Error; err != nil {
+		s.logger.LogError(err, "Failed to extend upload_status enum")
+		return nil, fmt.Errorf("failed to extend upload_status enum: %v", err)
+	}
+
 	// Initialize migration tracking table
 	if err := s.migrationConfig.InitializeMigrationTable(); err != nil {
 		s.logger.LogError(err, "Failed to initialize migration tracking")
@@ -149,10 +177,32 @@ func (s *DatabaseService) Connect() (*gorm.DB, error) {
 		if err = db.AutoMigrate(
 			&auth.User{},
 			&auth.RefreshToken{},
+			&auth.OAuthIdentity{},
+			&auth.APIKey{},
+			&auth.SecurityEvent{},
 			&video.Video{},
 			&video.VideoUpload{},
 			&video.Transcode{},
 			&video.TranscodeSegment{},
+			&video.Tag{},
+			&video.VideoVersion{},
+			&video.VideoCaption{},
+			&follow.Follow{},
+			&block.Block{},
+			&entitlement.Entitlement{},
+			&moderation.Report{},
+			&moderation.EvidenceSnapshot{},
+			&accesslog.Entry{},
+			&webhook.Webhook{},
+			&webhook.Delivery{},
+			&federation.Instance{},
+			&federation.RemoteVideo{},
+			&activitypub.ActorKey{},
+			&activitypub.Follower{},
+			&embed.Domain{},
+			&live.StreamKey{},
+			&live.StreamSettings{},
+			&live.Stream{},
 		); err != nil {
 			s.logger.LogError(err, "Auto-migration failed")
 			return nil, fmt.Errorf("auto migration failed: %v", err)
@@ -185,6 +235,18 @@ func (s *DatabaseService) Close() error {
 	return nil
 }
 
+// Ping checks that the database is reachable
+func (s *DatabaseService) Ping(ctx context.Context) error {
+	if s.db == nil {
+		return fmt.Errorf("database is not connected")
+	}
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // GetMigrationConfig returns the migration configuration
 func (s *DatabaseService) GetMigrationConfig() *MigrationConfig {
 	return s.migrationConfig
@@ -0,0 +1,260 @@
+package embed
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/geoip"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// pageTemplate is the embedded player page. It is shown with an error
+// message instead of a player when the video cannot be played.
+var pageTemplate = template.Must(template.New("embed").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Config}}{{.Config.Title}}{{else}}{{.Message}}{{end}}</title>
+<style>html,body{margin:0;height:100%;background:#000;color:#fff;font-family:sans-serif}video{width:100%;height:100%}p{margin:0;padding:1em;text-align:center}</style>
+</head>
+<body>
+{{- if .Config}}
+<video controls playsinline preload="metadata"{{with .Config.Poster}} poster="{{.}}"{{end}}>
+{{- range .Config.Sources}}
+<source src="{{.URL}}"{{with .Type}} type="{{.}}"{{end}} data-rendition="{{.Rendition}}">
+{{- end}}
+</video>
+{{- else}}
+<p>{{.Message}}</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// page is the data of the player page
+type page struct {
+	Config  *PlayerConfig
+	Message string
+}
+
+// Handler handles HTTP requests for the embedded player and allowed domains
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new embed handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the routes managing the authenticated user's
+// allowed domains
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	me := router.Group("/me/embed", authMiddleware)
+	{
+		me.GET("/domains", h.handleGetDomains)
+		me.PUT("/domains", h.handleSetDomains)
+	}
+}
+
+// RegisterPlayerRoutes registers the player, which is served at the root of
+// the domain next to oEmbed, where its iframes point
+func (h *Handler) RegisterPlayerRoutes(router gin.IRouter) {
+	router.GET("/embed/:id", h.handlePlayer)
+}
+
+// @Summary Embedded player
+// @Description Minimal player page for iframes, or with format=json the config for a custom player. Only completed videos that need no entitlement and whose owner allows embedding can be played. When the owner lists allowed domains, the host of the Origin header, or else the Referer header, must be one of them or their subdomains, and the page's Content-Security-Policy only lets browsers frame it there. Sources may be signed URLs that expire, so responses are not cached. Served at the root of the domain; only served when embedding is enabled.
+// @Tags embed
+// @Produce html
+// @Produce json
+// @Param id path string true "Video ID (UUID)"
+// @Param format query string false "Response format (default: html)" Enums(html, json)
+// @Success 200 {object} httpHandler.APIResponse{data=PlayerConfig} "Player page, or player config"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid video ID"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video cannot be embedded, or not on this site"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video not found"
+// @Failure 451 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video is not available in the viewer's country"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /embed/{id} [get]
+func (h *Handler) handlePlayer(c *gin.Context) {
+	asJSON := c.Query("format") == "json"
+	c.Header("Cache-Control", "no-store")
+
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		if asJSON {
+			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID format", err)
+		} else {
+			h.writePage(c, http.StatusBadRequest, page{Message: "Invalid video ID"})
+		}
+		return
+	}
+
+	country, located := geoip.Country(c)
+	player, err := h.service.Player(c.Request.Context(), videoID, Viewer{Embedder: embedder(c), Country: country, Located: located})
+	if err != nil {
+		if asJSON {
+			h.handleServiceError(c, err, "Failed to load player")
+			return
+		}
+		status := h.errorStatus(err)
+		message := err.Error()
+		if status == http.StatusInternalServerError {
+			h.logger.LogError(err, "Failed to load player")
+			message = "This video cannot be played right now"
+		}
+		h.writePage(c, status, page{Message: message})
+		return
+	}
+
+	c.Header("Content-Security-Policy", frameAncestors(player.Domains))
+	if asJSON {
+		h.responseHandler.SuccessResponse(c, player.Config, "Player config retrieved successfully")
+		return
+	}
+	h.writePage(c, http.StatusOK, page{Config: &player.Config})
+}
+
+// @Summary List allowed embed domains
+// @Description List the domains the authenticated user's videos may be embedded on. An empty list means anywhere.
+// @Tags embed
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=DomainsResponse} "Allowed domains"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/embed/domains [get]
+func (h *Handler) handleGetDomains(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	domains, err := h.service.Domains(c.Request.Context(), userID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to list embed domains")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, DomainsResponse{Domains: domains}, "Embed domains retrieved successfully")
+}
+
+// @Summary Set allowed embed domains
+// @Description Replace the domains the authenticated user's videos may be embedded on; subdomains are allowed too. Schemes, ports and paths are dropped. An empty list allows embedding anywhere. Embedding can also be turned off per video with PATCH /video/{id}.
+// @Tags embed
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body DomainsRequest true "Allowed domains"
+// @Success 200 {object} httpHandler.APIResponse{data=DomainsResponse} "Allowed domains, normalized"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid domain, or too many domains"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/embed/domains [put]
+func (h *Handler) handleSetDomains(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req DomainsRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	domains, err := h.service.SetDomains(c.Request.Context(), userID, req.Domains)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to set embed domains")
+		return
+	}
+	h.logger.LogInfo("Embed domains set", map[string]interface{}{
+		"userId":  userID.String(),
+		"domains": len(domains),
+	})
+	h.responseHandler.SuccessResponse(c, DomainsResponse{Domains: domains}, "Embed domains updated successfully")
+}
+
+// writePage renders the player page
+func (h *Handler) writePage(c *gin.Context, status int, data page) {
+	var body bytes.Buffer
+	if err := pageTemplate.Execute(&body, data); err != nil {
+		h.logger.LogError(err, "Failed to render player page")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", body.Bytes())
+}
+
+// embedder returns the host of the page embedding the player, from the
+// Origin header or else the Referer header, or an empty string when neither
+// names one
+func embedder(c *gin.Context) string {
+	for _, header := range []string{"Origin", "Referer"} {
+		if value := c.GetHeader(header); value != "" && value != "null" {
+			if u, err := url.Parse(value); err == nil && u.Host != "" {
+				return u.Host
+			}
+		}
+	}
+	return ""
+}
+
+// getUserID returns the authenticated user's ID, aborting with an error when it is missing
+func (h *Handler) getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, _ := c.Get("userID")
+	str, _ := userIDStr.(string)
+	userID, err := uuid.Parse(str)
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", nil)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// errorStatus returns the HTTP status of an embed service error
+func (h *Handler) errorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrVideoNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotEmbeddable), errors.Is(err, ErrDomainNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, ErrInvalidDomain), errors.Is(err, ErrTooManyDomains):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrGeoBlocked):
+		return http.StatusUnavailableForLegalReasons
+	}
+	return http.StatusInternalServerError
+}
+
+// handleServiceError maps embed service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch h.errorStatus(err) {
+	case http.StatusNotFound:
+		h.responseHandler.NotFoundResponse(c, err.Error())
+	case http.StatusForbidden:
+		h.responseHandler.ErrorResponse(c, http.StatusForbidden, "FORBIDDEN", err.Error(), nil)
+	case http.StatusBadRequest:
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", err.Error(), nil)
+	case http.StatusUnavailableForLegalReasons:
+		h.responseHandler.ErrorResponse(c, http.StatusUnavailableForLegalReasons, "GEO_BLOCKED", err.Error(), nil)
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
@@ -0,0 +1,45 @@
+package embed
+
+import (
+	"context"
+	"errors"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrVideoNotFound is returned when a video does not exist, was deleted,
+	// has not finished processing or is hidden
+	ErrVideoNotFound = errors.New("video not found")
+	// ErrNotEmbeddable is returned for videos whose owner turned embedding
+	// off, and for videos that need an entitlement, since embedded players
+	// are anonymous
+	ErrNotEmbeddable = errors.New("video cannot be embedded")
+	// ErrDomainNotAllowed is returned when the page embedding a video is not
+	// on one of its owner's allowed domains
+	ErrDomainNotAllowed = errors.New("video cannot be embedded on this site")
+	// ErrGeoBlocked is returned when the video is not played in the viewer's country
+	ErrGeoBlocked = errors.New("video is not available in your country")
+	// ErrInvalidDomain is returned for allowed domains that are not hostnames
+	ErrInvalidDomain = errors.New("invalid domain")
+	// ErrTooManyDomains is returned when more domains are allowed than the configured limit
+	ErrTooManyDomains = errors.New("too many domains")
+)
+
+// VideoLookup reads videos with their renditions. The video service implements it.
+type VideoLookup interface {
+	GetVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error)
+}
+
+// Service defines the interface for the embedded player
+type Service interface {
+	// Player returns the player of a video for a viewer
+	Player(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*Player, error)
+	// Domains returns the domains a user's videos may be embedded on; empty
+	// when they may be embedded anywhere
+	Domains(ctx context.Context, userID uuid.UUID) ([]string, error)
+	// SetDomains replaces the domains a user's videos may be embedded on and
+	// returns them normalized
+	SetDomains(ctx context.Context, userID uuid.UUID, domains []string) ([]string, error)
+}
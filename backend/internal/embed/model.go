@@ -0,0 +1,74 @@
+package embed
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain is a site a user's videos may be embedded on. Users without any
+// may have their videos embedded anywhere.
+type Domain struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_embed_domains_user_domain"`
+	// Domain is a lowercase hostname; its subdomains are allowed too
+	Domain    string    `gorm:"type:text;not null;uniqueIndex:idx_embed_domains_user_domain"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName keeps the embedding tables together
+func (Domain) TableName() string {
+	return "embed_domains"
+}
+
+// Viewer describes who is loading the player, and where
+type Viewer struct {
+	// Embedder is the host of the embedding page, or an empty string when it is unknown
+	Embedder string
+	// Country is the viewer's country by ISO code, or an empty string when it
+	// is not known; it is only looked up, and Located set, when GeoIP is
+	// configured
+	Country string
+	Located bool
+}
+
+// Player is the embedded player of a video
+type Player struct {
+	Config PlayerConfig
+	// Domains are those the owner allows the video to be embedded on; empty
+	// when it may be embedded anywhere
+	Domains []string
+}
+
+// PlayerConfig describes a video to a player
+type PlayerConfig struct {
+	VideoID string `json:"video_id" example:"5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"`
+	Title   string `json:"title" example:"Intro to Go"`
+	// Duration is the length of the video in seconds, when known
+	Duration float64 `json:"duration,omitempty" example:"754.2"`
+	// Poster is the video's animated preview, when it can be linked
+	Poster string `json:"poster,omitempty" example:"https://cdn.example.com/videos/5f0c.../preview.webp"`
+	// Sources are the video's renditions, highest resolution first
+	Sources []Source `json:"sources"`
+}
+
+// Source is a rendition a player can fetch. CDN and storage URLs may be
+// signed and expire, so configs are not meant to be kept.
+type Source struct {
+	Rendition string `json:"rendition" example:"720p"`
+	// Type is the file's MIME type, when known from its extension
+	Type string `json:"type,omitempty" example:"video/mp4"`
+	URL  string `json:"url" example:"https://cdn.example.com/videos/5f0c.../720p.mp4"`
+}
+
+// DomainsRequest replaces the domains a user's videos may be embedded on
+type DomainsRequest struct {
+	// Domains are hostnames, e.g. blog.example.com; subdomains are allowed
+	// too. An empty list allows embedding anywhere.
+	Domains []string `json:"domains" example:"example.com"`
+}
+
+// DomainsResponse lists the domains a user's videos may be embedded on
+type DomainsResponse struct {
+	Domains []string `json:"domains" example:"example.com"`
+}
@@ -0,0 +1,257 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// videoTypes maps the extensions of stored files to their media types
+var videoTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".mov":  "video/quicktime",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+}
+
+// hostnamePattern matches lowercase hostnames
+var hostnamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// Config represents how videos are embedded
+type Config struct {
+	// MaxDomains is how many domains each user may allow
+	MaxDomains int
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db      *gorm.DB
+	config  Config
+	videos  VideoLookup
+	cdn     video.PlaybackCDN
+	files   video.FileURLs
+	sources video.SourceConfig
+	logger  logger.Logger
+}
+
+// NewService creates a new embed service. Renditions are linked on the CDN,
+// then in storage, then on the IPFS gateway; cdn and files may be nil.
+func NewService(db *gorm.DB, config Config, videos VideoLookup, cdn video.PlaybackCDN, files video.FileURLs, sources video.SourceConfig, logger logger.Logger) Service {
+	if config.MaxDomains < 1 {
+		config.MaxDomains = 50
+	}
+	return &serviceImpl{
+		db:      db,
+		config:  config,
+		videos:  videos,
+		cdn:     cdn,
+		files:   files,
+		sources: sources,
+		logger:  logger,
+	}
+}
+
+// Player returns the player of a video for a viewer
+func (s *serviceImpl) Player(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*Player, error) {
+	v, err := s.videos.GetVideo(ctx, videoID)
+	if err != nil {
+		if errors.Is(err, video.ErrVideoNotFound) || errors.Is(err, video.ErrVideoDeleted) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	if v.Upload == nil || v.Upload.Status != video.UploadStatusCompleted || v.HiddenFrom(uuid.Nil, "") {
+		return nil, ErrVideoNotFound
+	}
+	if !v.Embeddable || v.RequiresEntitlement {
+		return nil, ErrNotEmbeddable
+	}
+	if viewer.Located && !v.AvailableIn(viewer.Country) {
+		return nil, ErrGeoBlocked
+	}
+
+	domains, err := s.Domains(ctx, v.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed(domains, viewer.Embedder) {
+		return nil, ErrDomainNotAllowed
+	}
+	return &Player{Config: s.playerConfig(ctx, v), Domains: domains}, nil
+}
+
+// playerConfig describes a video to a player, listing the transcoded
+// resolutions before the original, which browsers may not be able to play
+func (s *serviceImpl) playerConfig(ctx context.Context, v *video.Video) PlayerConfig {
+	config := PlayerConfig{
+		VideoID:  v.ID.String(),
+		Title:    v.Title,
+		Duration: v.Duration,
+		Sources:  []Source{},
+	}
+	if v.PreviewPath != "" {
+		config.Poster = s.fileURL(ctx, v.ID, v.PreviewPath, "")
+	}
+
+	var original *Source
+	for _, rendition := range video.Renditions(v) {
+		url := s.fileURL(ctx, v.ID, rendition.Key, rendition.CID)
+		if url == "" {
+			continue
+		}
+		source := Source{Rendition: rendition.Name, Type: videoTypes[strings.ToLower(path.Ext(rendition.Key))], URL: url}
+		if rendition.Name == "original" {
+			original = &source
+			continue
+		}
+		config.Sources = append(config.Sources, source)
+	}
+	if original != nil {
+		config.Sources = append(config.Sources, *original)
+	}
+	return config
+}
+
+// fileURL links a stored file for an anonymous viewer, or returns an empty
+// string when it cannot be linked
+func (s *serviceImpl) fileURL(ctx context.Context, videoID uuid.UUID, key, cid string) string {
+	logFailure := func(source string, err error) {
+		s.logger.LogWarn("Failed to link embedded video file", map[string]interface{}{
+			"video_id": videoID,
+			"key":      key,
+			"source":   source,
+			"error":    err.Error(),
+		})
+	}
+	if s.cdn != nil {
+		url, err := s.cdn.URL(key)
+		if err == nil {
+			return url
+		}
+		logFailure(video.SourceCDN, err)
+	}
+	if s.files != nil {
+		url, err := s.files.GetVideoURL(ctx, key)
+		if err == nil {
+			return url
+		}
+		logFailure(video.SourceStorage, err)
+	}
+	if cid != "" {
+		return s.sources.GatewayURL(cid)
+	}
+	return ""
+}
+
+// Domains returns the domains a user's videos may be embedded on
+func (s *serviceImpl) Domains(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	domains := []string{}
+	if err := s.db.WithContext(ctx).Model(&Domain{}).Where("user_id = ?", userID).
+		Order("domain").Pluck("domain", &domains).Error; err != nil {
+		return nil, fmt.Errorf("failed to list embed domains: %w", err)
+	}
+	return domains, nil
+}
+
+// SetDomains replaces the domains a user's videos may be embedded on
+func (s *serviceImpl) SetDomains(ctx context.Context, userID uuid.UUID, domains []string) ([]string, error) {
+	normalized, err := normalizeDomains(domains)
+	if err != nil {
+		return nil, err
+	}
+	if len(normalized) > s.config.MaxDomains {
+		return nil, fmt.Errorf("%w: at most %d may be allowed", ErrTooManyDomains, s.config.MaxDomains)
+	}
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&Domain{}).Error; err != nil {
+			return err
+		}
+		if len(normalized) == 0 {
+			return nil
+		}
+		rows := make([]Domain, 0, len(normalized))
+		for _, domain := range normalized {
+			rows = append(rows, Domain{UserID: userID, Domain: domain})
+		}
+		return tx.Create(&rows).Error
+	}); err != nil {
+		return nil, fmt.Errorf("failed to set embed domains: %w", err)
+	}
+	return normalized, nil
+}
+
+// normalizeDomains lowercases, sorts and deduplicates hostnames. Schemes,
+// ports, paths and leading wildcards are accepted and dropped, since
+// subdomains are always allowed.
+func normalizeDomains(domains []string) ([]string, error) {
+	seen := make(map[string]bool, len(domains))
+	normalized := []string{}
+	for _, domain := range domains {
+		host := strings.ToLower(strings.TrimSpace(domain))
+		if _, rest, ok := strings.Cut(host, "://"); ok {
+			host = rest
+		}
+		host, _, _ = strings.Cut(host, "/")
+		host = hostname(host)
+		host = strings.TrimPrefix(host, "*.")
+		if !hostnamePattern.MatchString(host) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidDomain, domain)
+		}
+		if !seen[host] {
+			seen[host] = true
+			normalized = append(normalized, host)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// hostname strips the port and trailing dot from a host
+func hostname(host string) string {
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return strings.TrimSuffix(host, ".")
+}
+
+// allowed reports whether a page at embedder may embed a video whose owner
+// allows domains. Pages of unknown hosts are only allowed when the owner
+// allows every domain.
+func allowed(domains []string, embedder string) bool {
+	if len(domains) == 0 {
+		return true
+	}
+	embedder = hostname(strings.ToLower(embedder))
+	if embedder == "" {
+		return false
+	}
+	for _, domain := range domains {
+		if embedder == domain || strings.HasSuffix(embedder, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// frameAncestors returns the Content-Security-Policy directive that lets
+// browsers frame the player on the allowed domains and their subdomains
+func frameAncestors(domains []string) string {
+	if len(domains) == 0 {
+		return "frame-ancestors *"
+	}
+	sources := make([]string, 0, 2*len(domains))
+	for _, domain := range domains {
+		sources = append(sources, domain, "*."+domain)
+	}
+	return "frame-ancestors " + strings.Join(sources, " ")
+}
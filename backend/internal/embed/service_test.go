@@ -0,0 +1,283 @@
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCDN links files on a fixed domain, failing for keys it does not hold
+type fakeCDN struct {
+	missing string
+}
+
+func (f fakeCDN) URL(key string) (string, error) {
+	if key == f.missing {
+		return "", errors.New("not on the CDN")
+	}
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (f fakeCDN) Signed() bool {
+	return true
+}
+
+// testVideo returns a completed video with two resolutions
+func testVideo() *video.Video {
+	return &video.Video{
+		ID:          uuid.MustParse("5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"),
+		UserID:      uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+		Title:       "Intro to Go",
+		StoragePath: "videos/5f0c/original.mov",
+		IPFSCID:     "bafyoriginal",
+		PreviewPath: "videos/5f0c/preview.webp",
+		Duration:    754.2,
+		Embeddable:  true,
+		Upload:      &video.VideoUpload{Status: video.UploadStatusCompleted},
+		Transcodes: []video.Transcode{{Format: "mp4", Segments: []video.TranscodeSegment{
+			{StoragePath: "videos/5f0c/480p.mp4"},
+			{StoragePath: "videos/5f0c/720p.mp4"},
+		}}},
+	}
+}
+
+func TestPlayerConfig(t *testing.T) {
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+	service := NewService(nil, Config{}, nil, fakeCDN{missing: "videos/5f0c/original.mov"}, nil,
+		video.SourceConfig{IPFSGateway: "https://ipfs.io"}, log).(*serviceImpl)
+
+	config := service.playerConfig(context.Background(), testVideo())
+	assert.Equal(t, PlayerConfig{
+		VideoID:  "5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b",
+		Title:    "Intro to Go",
+		Duration: 754.2,
+		Poster:   "https://cdn.example.com/videos/5f0c/preview.webp",
+		Sources: []Source{
+			{Rendition: "720p", Type: "video/mp4", URL: "https://cdn.example.com/videos/5f0c/720p.mp4"},
+			{Rendition: "480p", Type: "video/mp4", URL: "https://cdn.example.com/videos/5f0c/480p.mp4"},
+			// The original is not on the CDN, so its IPFS copy is linked
+			{Rendition: "original", Type: "video/quicktime", URL: "https://ipfs.io/ipfs/bafyoriginal"},
+		},
+	}, config)
+
+	// Without anywhere to link files, the player has no sources
+	service = NewService(nil, Config{}, nil, nil, nil, video.SourceConfig{}, log).(*serviceImpl)
+	config = service.playerConfig(context.Background(), testVideo())
+	assert.Empty(t, config.Poster)
+	assert.Equal(t, []Source{}, config.Sources)
+}
+
+// fakeVideos returns a fixed video
+type fakeVideos struct {
+	video *video.Video
+	err   error
+}
+
+func (f fakeVideos) GetVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	return f.video, f.err
+}
+
+func TestPlayerVisibility(t *testing.T) {
+	takenDown := time.Now()
+	tests := []struct {
+		name    string
+		videos  fakeVideos
+		modify  func(v *video.Video)
+		viewer  Viewer
+		wantErr error
+	}{
+		{name: "missing", videos: fakeVideos{err: video.ErrVideoNotFound}, wantErr: ErrVideoNotFound},
+		{name: "deleted", videos: fakeVideos{err: video.ErrVideoDeleted}, wantErr: ErrVideoNotFound},
+		{name: "uploading", modify: func(v *video.Video) { v.Upload.Status = video.UploadStatusUploading }, wantErr: ErrVideoNotFound},
+		{name: "taken down", modify: func(v *video.Video) { v.TakenDownAt = &takenDown }, wantErr: ErrVideoNotFound},
+		{name: "quarantined", modify: func(v *video.Video) { v.ScanStatus = video.ScanQuarantined }, wantErr: ErrVideoNotFound},
+		{name: "embedding off", modify: func(v *video.Video) { v.Embeddable = false }, wantErr: ErrNotEmbeddable},
+		{name: "gated", modify: func(v *video.Video) { v.RequiresEntitlement = true }, wantErr: ErrNotEmbeddable},
+		{
+			name:    "blocked country",
+			modify:  func(v *video.Video) { v.BlockedCountries = video.CountryList{"DE"} },
+			viewer:  Viewer{Country: "DE", Located: true},
+			wantErr: ErrGeoBlocked,
+		},
+		{
+			name:    "unknown country with allowed countries",
+			modify:  func(v *video.Video) { v.AllowedCountries = video.CountryList{"US"} },
+			viewer:  Viewer{Located: true},
+			wantErr: ErrGeoBlocked,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			videos := tt.videos
+			if videos.err == nil {
+				videos.video = testVideo()
+				tt.modify(videos.video)
+			}
+			// Visibility is checked before the owner's domains are looked up,
+			// so no database is needed
+			service := NewService(nil, Config{}, videos, nil, nil, video.SourceConfig{}, nil)
+			_, err := service.Player(context.Background(), uuid.New(), tt.viewer)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNormalizeDomains(t *testing.T) {
+	domains, err := normalizeDomains([]string{
+		"Example.com",
+		"https://blog.example.org:8443/posts/1",
+		"*.news.example.net",
+		"example.com.",
+		"localhost:3000",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"blog.example.org", "example.com", "localhost", "news.example.net"}, domains)
+
+	domains, err = normalizeDomains(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, domains)
+
+	for _, domain := range []string{"", "exa mple.com", "-example.com", "example..com", "https://"} {
+		_, err := normalizeDomains([]string{domain})
+		assert.ErrorIs(t, err, ErrInvalidDomain, domain)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	assert.True(t, allowed(nil, ""))
+	assert.True(t, allowed(nil, "anywhere.example.org"))
+
+	domains := []string{"example.com"}
+	assert.True(t, allowed(domains, "example.com"))
+	assert.True(t, allowed(domains, "Blog.Example.com:8443"))
+	assert.False(t, allowed(domains, "notexample.com"))
+	assert.False(t, allowed(domains, "example.com.evil.org"))
+	assert.False(t, allowed(domains, ""))
+}
+
+func TestFrameAncestors(t *testing.T) {
+	assert.Equal(t, "frame-ancestors *", frameAncestors(nil))
+	assert.Equal(t, "frame-ancestors example.com *.example.com blog.example.org *.blog.example.org",
+		frameAncestors([]string{"example.com", "blog.example.org"}))
+}
+
+// fakeService returns a fixed player, or an error
+type fakeService struct {
+	domains  []string
+	err      error
+	embedder string
+}
+
+func (f *fakeService) Player(ctx context.Context, videoID uuid.UUID, viewer Viewer) (*Player, error) {
+	f.embedder = viewer.Embedder
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &Player{
+		Config: PlayerConfig{
+			VideoID: videoID.String(),
+			Title:   "Intro to <Go>",
+			Sources: []Source{{Rendition: "720p", Type: "video/mp4", URL: "https://cdn.example.com/720p.mp4?sig=a&exp=1"}},
+		},
+		Domains: f.domains,
+	}, nil
+}
+
+func (f *fakeService) Domains(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return f.domains, nil
+}
+
+func (f *fakeService) SetDomains(ctx context.Context, userID uuid.UUID, domains []string) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.domains = domains
+	return domains, nil
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+
+	service := &fakeService{domains: []string{"example.com"}}
+	handler := NewHandler(service, httpHandler.NewResponseHandler(log), log)
+	router := gin.New()
+	handler.RegisterPlayerRoutes(router)
+	handler.RegisterRoutes(router, func(c *gin.Context) {
+		c.Set("userID", "550e8400-e29b-41d4-a716-446655440000")
+	})
+
+	request := func(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	videoPath := "/embed/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"
+	w := request(http.MethodGet, videoPath, "", http.Header{"Referer": {"https://blog.example.com/posts/1"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "blog.example.com", service.embedder)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "frame-ancestors example.com *.example.com", w.Header().Get("Content-Security-Policy"))
+	assert.Contains(t, w.Body.String(), "<title>Intro to &lt;Go&gt;</title>")
+	assert.Contains(t, w.Body.String(), `<source src="https://cdn.example.com/720p.mp4?sig=a&amp;exp=1" type="video/mp4" data-rendition="720p">`)
+
+	// Origin is preferred over Referer
+	request(http.MethodGet, videoPath, "", http.Header{"Origin": {"https://example.com"}, "Referer": {"https://other.example.org/"}})
+	assert.Equal(t, "example.com", service.embedder)
+
+	w = request(http.MethodGet, videoPath+"?format=json", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data PlayerConfig `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Intro to <Go>", response.Data.Title)
+	assert.Len(t, response.Data.Sources, 1)
+
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/embed/not-a-uuid", "", nil).Code)
+
+	service.err = ErrDomainNotAllowed
+	w = request(http.MethodGet, videoPath, "", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "<p>video cannot be embedded on this site</p>")
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, http.StatusForbidden, request(http.MethodGet, videoPath+"?format=json", "", nil).Code)
+
+	service.err = ErrVideoNotFound
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, videoPath, "", nil).Code)
+
+	service.err = nil
+	w = request(http.MethodPut, "/me/embed/domains", `{"domains":["example.org"]}`, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"example.org"}, service.domains)
+	w = request(http.MethodGet, "/me/embed/domains", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"domains":["example.org"]`)
+
+	service.err = ErrInvalidDomain
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, "/me/embed/domains", `{"domains":["exa mple.org"]}`, nil).Code)
+}
@@ -0,0 +1,299 @@
+package entitlement
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the webhook timestamp, a
+// dot and the body, prefixed with "sha256="
+const SignatureHeader = "X-Pavilion-Signature"
+
+// TimestampHeader carries the Unix time in seconds at which the webhook was signed
+const TimestampHeader = "X-Pavilion-Timestamp"
+
+// maxWebhookBody bounds the size of webhook payloads
+const maxWebhookBody = 1 << 20
+
+// WebhookConfig holds the settings of the entitlement webhook
+type WebhookConfig struct {
+	// Secret keys the signature; the webhook is disabled when it is empty
+	Secret string
+	// Tolerance is how far a webhook's timestamp may be from now, either way
+	Tolerance time.Duration
+}
+
+// Handler handles HTTP requests for entitlement endpoints
+type Handler struct {
+	service         Service
+	webhook         WebhookConfig
+	now             func() time.Time
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new entitlement handler instance
+func NewHandler(service Service, webhook WebhookConfig, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		webhook:         webhook,
+		now:             time.Now,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers all entitlement routes
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	// Called by payment systems and authenticated by signature rather than user token
+	router.POST("/entitlements/webhook", h.handleWebhook)
+
+	protected := router.Group("")
+	protected.Use(authMiddleware)
+	{
+		protected.GET("/entitlements", h.handleListEntitlements)
+		protected.GET("/video/:id/access", h.handleGetAccess)
+		protected.PUT("/video/:id/access", h.handleSetAccess)
+	}
+}
+
+// @Summary Entitlement webhook
+// @Description Grant or revoke video access from an external payment system. The request must carry an X-Pavilion-Timestamp header with the Unix time it was signed at, within the configured tolerance of the server's clock, and an X-Pavilion-Signature header of the form "sha256=<hex HMAC-SHA256 of the timestamp, a dot and the body>" keyed with the configured webhook secret. Each event carries an id and is applied once; an event with an id already applied is acknowledged without being applied again, so retries are safe and captured events cannot be replayed.
+// @Tags entitlements
+// @Accept json
+// @Produce json
+// @Param X-Pavilion-Timestamp header string true "Unix time the event was signed at"
+// @Param X-Pavilion-Signature header string true "Timestamp and body signature"
+// @Param event body WebhookEvent true "Grant or revoke event"
+// @Success 200 {object} httpHandler.APIResponse "Event applied, or already applied"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid event"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Missing or invalid signature, or timestamp outside the tolerance"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User or video not found"
+// @Failure 503 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Webhook not configured"
+// @Router /entitlements/webhook [post]
+func (h *Handler) handleWebhook(c *gin.Context) {
+	if h.webhook.Secret == "" {
+		h.responseHandler.ErrorResponse(c, http.StatusServiceUnavailable, "WEBHOOK_DISABLED", "Entitlement webhook is not configured", nil)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body", err)
+		return
+	}
+	timestamp := c.GetHeader(TimestampHeader)
+	if !verifySignature(h.webhook.Secret, timestamp, body, c.GetHeader(SignatureHeader)) {
+		h.logger.LogWarn("Rejected entitlement webhook with invalid signature", map[string]interface{}{
+			"remote_addr": c.ClientIP(),
+		})
+		h.responseHandler.UnauthorizedResponse(c, "Invalid webhook signature")
+		return
+	}
+	if !h.withinTolerance(timestamp) {
+		h.logger.LogWarn("Rejected entitlement webhook with stale timestamp", map[string]interface{}{
+			"remote_addr": c.ClientIP(),
+			"timestamp":   timestamp,
+		})
+		h.responseHandler.UnauthorizedResponse(c, "Webhook timestamp is outside the allowed tolerance")
+		return
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		h.responseHandler.ValidationErrorResponse(c, "body", "Invalid event payload")
+		return
+	}
+
+	// A replay must carry a timestamp within the tolerance either way, so
+	// IDs are needed for twice as long
+	result, err := h.service.ApplyEvent(c.Request.Context(), &event, 2*h.webhook.Tolerance)
+	switch {
+	case errors.Is(err, ErrDuplicateEvent):
+		h.responseHandler.SuccessResponse(c, gin.H{"duplicate": true}, "Event already applied")
+	case errors.Is(err, ErrInvalidEvent):
+		h.responseHandler.ValidationErrorResponse(c, "body", err.Error())
+	case err != nil:
+		h.handleServiceError(c, err, "Failed to apply entitlement event")
+	case event.Type == EventGranted:
+		h.responseHandler.SuccessResponse(c, result.Entitlement, "Entitlement granted")
+	default:
+		h.responseHandler.SuccessResponse(c, gin.H{"revoked": result.Revoked}, "Entitlements revoked")
+	}
+}
+
+// @Summary List my entitlements
+// @Description Get the authenticated user's video entitlements, including expired and revoked ones
+// @Tags entitlements
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=EntitlementListResponse} "Entitlements retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /entitlements [get]
+func (h *Handler) handleListEntitlements(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, err := h.service.ListForUser(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve entitlements")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Entitlements retrieved successfully")
+}
+
+// @Summary Get video access
+// @Description Check whether the authenticated user may play a video, so clients can show a paywall before requesting playback
+// @Tags entitlements
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=AccessStatus} "Access status retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid video ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /video/{id}/access [get]
+func (h *Handler) handleGetAccess(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID", err)
+		return
+	}
+
+	status, err := h.service.GetAccess(c.Request.Context(), userID, videoID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to check video access")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, status, "Access status retrieved successfully")
+}
+
+// @Summary Set video access
+// @Description Require an entitlement to play a video, or make it freely playable again. Only the video owner may change this.
+// @Tags entitlements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param request body AccessRequest true "Access settings"
+// @Success 200 {object} httpHandler.APIResponse{data=AccessStatus} "Access settings updated"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid request"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Not the video owner"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /video/{id}/access [put]
+func (h *Handler) handleSetAccess(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID", err)
+		return
+	}
+
+	var req AccessRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	status, err := h.service.SetRequiresEntitlement(c.Request.Context(), userID, videoID, *req.RequiresEntitlement)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to update video access")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, status, "Access settings updated successfully")
+}
+
+// getUserID returns the authenticated user's ID, writing an error response when it is missing
+func (h *Handler) getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleServiceError maps entitlement service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidGrant):
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", err.Error(), err)
+	case errors.Is(err, ErrVideoNotFound):
+		h.responseHandler.NotFoundResponse(c, "Video not found")
+	case errors.Is(err, ErrUserNotFound):
+		h.responseHandler.NotFoundResponse(c, "User not found")
+	case errors.Is(err, ErrNotVideoOwner):
+		h.responseHandler.ForbiddenResponse(c, err.Error())
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
+
+// withinTolerance reports whether a webhook timestamp is close enough to now
+func (h *Handler) withinTolerance(timestamp string) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := h.now().Sub(time.Unix(seconds, 0))
+	return skew <= h.webhook.Tolerance && skew >= -h.webhook.Tolerance
+}
+
+// verifySignature checks a "sha256=<hex>" HMAC of the timestamp, a dot and
+// body in constant time
+func verifySignature(secret, timestamp string, body []byte, header string) bool {
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
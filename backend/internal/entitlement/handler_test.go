@@ -0,0 +1,124 @@
+package entitlement
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := "webhook-secret"
+	body := []byte(`{"type":"entitlement.granted"}`)
+	valid := sign(secret, "1700000000", body)
+
+	assert.True(t, verifySignature(secret, "1700000000", body, valid))
+	assert.False(t, verifySignature("other-secret", "1700000000", body, valid))
+	assert.False(t, verifySignature(secret, "1700000001", body, valid), "the timestamp is signed")
+	assert.False(t, verifySignature(secret, "1700000000", []byte(`{"type":"entitlement.revoked"}`), valid))
+	assert.False(t, verifySignature(secret, "1700000000", body, valid[len("sha256="):]))
+	assert.False(t, verifySignature(secret, "1700000000", body, "sha256=not-hex"))
+	assert.False(t, verifySignature(secret, "1700000000", body, ""))
+}
+
+// fakeEventService applies each event ID once, like the database does
+type fakeEventService struct {
+	Service
+	applied []WebhookEvent
+	keep    time.Duration
+}
+
+func (s *fakeEventService) ApplyEvent(ctx context.Context, event *WebhookEvent, keep time.Duration) (*WebhookEventResult, error) {
+	if event.ID == "" || (event.Type != EventGranted && event.Type != EventRevoked) {
+		return nil, ErrInvalidEvent
+	}
+	for _, applied := range s.applied {
+		if applied.ID == event.ID {
+			return nil, ErrDuplicateEvent
+		}
+	}
+	s.applied = append(s.applied, *event)
+	s.keep = keep
+	if event.Type == EventRevoked {
+		return &WebhookEventResult{Revoked: 1}, nil
+	}
+	return &WebhookEventResult{Entitlement: &Entitlement{UserID: event.UserID, VideoID: event.VideoID}}, nil
+}
+
+func TestWebhook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+
+	const secret = "webhook-secret"
+	now := time.Unix(1700000000, 0)
+	service := &fakeEventService{}
+	handler := NewHandler(service, WebhookConfig{Secret: secret, Tolerance: 5 * time.Minute}, httpHandler.NewResponseHandler(log), log)
+	handler.now = func() time.Time { return now }
+	router := gin.New()
+	handler.RegisterRoutes(router, func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) })
+
+	send := func(body string, signedAt time.Time) int {
+		timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/entitlements/webhook", bytes.NewBufferString(body))
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, sign(secret, timestamp, []byte(body)))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	userID, videoID := uuid.New(), uuid.New()
+	grant := `{"id":"evt_1","type":"entitlement.granted","user_id":"` + userID.String() + `","video_id":"` + videoID.String() + `","source":"stripe","reference":"pi_1"}`
+	revoke := `{"id":"evt_2","type":"entitlement.revoked","source":"stripe","reference":"pi_1"}`
+
+	assert.Equal(t, http.StatusOK, send(grant, now.Add(-time.Minute)))
+	assert.Equal(t, http.StatusOK, send(revoke, now))
+	require.Len(t, service.applied, 2)
+	assert.Equal(t, 10*time.Minute, service.keep, "IDs outlive a timestamp's tolerance in both directions")
+
+	t.Run("replayed grant is not applied", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(grant, now))
+		assert.Len(t, service.applied, 2)
+	})
+
+	t.Run("timestamp outside the tolerance", func(t *testing.T) {
+		stale := `{"id":"evt_3","type":"entitlement.granted","user_id":"` + userID.String() + `","video_id":"` + videoID.String() + `"}`
+		assert.Equal(t, http.StatusUnauthorized, send(stale, now.Add(-6*time.Minute)))
+		assert.Equal(t, http.StatusUnauthorized, send(stale, now.Add(6*time.Minute)))
+		assert.Len(t, service.applied, 2)
+	})
+
+	t.Run("timestamp not covered by the signature", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/entitlements/webhook", bytes.NewBufferString(grant))
+		req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(SignatureHeader, sign(secret, strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), []byte(grant)))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("event without an ID", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(`{"type":"entitlement.granted"}`, now))
+	})
+}
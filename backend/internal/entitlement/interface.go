@@ -0,0 +1,44 @@
+package entitlement
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidGrant is returned when a grant is missing its user or video, or has an invalid expiry
+	ErrInvalidGrant = errors.New("invalid entitlement grant")
+	// ErrVideoNotFound is returned when the video does not exist or has been deleted
+	ErrVideoNotFound = errors.New("video not found")
+	// ErrUserNotFound is returned when the user to grant access to does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrNotVideoOwner is returned when a user changes access settings of someone else's video
+	ErrNotVideoOwner = errors.New("only the video owner can change access settings")
+	// ErrInvalidEvent is returned when a webhook event has no ID or an unknown type
+	ErrInvalidEvent = errors.New("webhook event needs an id and a type of entitlement.granted or entitlement.revoked")
+	// ErrDuplicateEvent is returned when a webhook event with the same ID was already applied
+	ErrDuplicateEvent = errors.New("webhook event has already been applied")
+)
+
+// Service defines the interface for entitlement operations
+type Service interface {
+	// Grant records access to a video, updating an existing grant with the same source and reference
+	Grant(ctx context.Context, req *GrantRequest) (*Entitlement, error)
+	// Revoke ends matching grants and returns how many were revoked
+	Revoke(ctx context.Context, req *RevokeRequest) (int64, error)
+	// ApplyEvent grants or revokes as a webhook event asks, once per event ID.
+	// IDs are remembered for keep, after which the event's timestamp no
+	// longer passes the webhook's skew check.
+	ApplyEvent(ctx context.Context, event *WebhookEvent, keep time.Duration) (*WebhookEventResult, error)
+	// HasAccess reports whether userID holds an active entitlement to videoID
+	HasAccess(ctx context.Context, userID, videoID uuid.UUID) (bool, error)
+	// GetAccess describes userID's access to videoID, including ownership
+	GetAccess(ctx context.Context, userID, videoID uuid.UUID) (*AccessStatus, error)
+	// SetRequiresEntitlement gates or ungates a video; only its owner may do so
+	SetRequiresEntitlement(ctx context.Context, ownerID, videoID uuid.UUID, required bool) (*AccessStatus, error)
+	// ListForUser returns a user's entitlements, newest first
+	ListForUser(ctx context.Context, userID uuid.UUID, page, limit int) (*EntitlementListResponse, error)
+}
@@ -0,0 +1,131 @@
+package entitlement
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entitlement grants a user access to a video that requires entitlement.
+// Grants without an expiry never expire; revoked grants are kept for auditing.
+type Entitlement struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index:idx_entitlements_user_video" json:"user_id"`
+	VideoID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_entitlements_user_video" json:"video_id"`
+	Source    string     `gorm:"type:text;not null;index:idx_entitlements_source_reference" json:"source"`
+	Reference string     `gorm:"type:text;index:idx_entitlements_source_reference" json:"reference,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Entitlement model
+func (Entitlement) TableName() string {
+	return "entitlements"
+}
+
+// IsActive reports whether the entitlement grants access at the given time
+func (e *Entitlement) IsActive(now time.Time) bool {
+	if e.RevokedAt != nil {
+		return false
+	}
+	return e.ExpiresAt == nil || e.ExpiresAt.After(now)
+}
+
+// GrantRequest describes access to grant, typically sent by a payment system
+type GrantRequest struct {
+	UserID  uuid.UUID `json:"user_id"`
+	VideoID uuid.UUID `json:"video_id"`
+	// ExpiresAt ends the grant at a fixed time; mutually exclusive with DurationSeconds
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// DurationSeconds ends the grant this long after it is issued, e.g. a 48 hour rental
+	DurationSeconds int64 `json:"duration_seconds,omitempty"`
+	// Source names the system issuing the grant, e.g. "stripe"
+	Source string `json:"source"`
+	// Reference is the payment or order ID in the source system. Grants with the
+	// same source and reference are updated rather than duplicated, so webhook
+	// retries are safe.
+	Reference string `json:"reference,omitempty"`
+}
+
+// RevokeRequest identifies grants to revoke, either by source and reference or by user and video
+type RevokeRequest struct {
+	UserID    uuid.UUID `json:"user_id,omitempty"`
+	VideoID   uuid.UUID `json:"video_id,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	Reference string    `json:"reference,omitempty"`
+}
+
+// WebhookEvent is the payload accepted from external payment systems
+type WebhookEvent struct {
+	// ID identifies the event in the sender; an event is applied only once
+	ID string `json:"id"`
+	// Type is either "entitlement.granted" or "entitlement.revoked"
+	Type string `json:"type"`
+	GrantRequest
+}
+
+// WebhookEventResult is the outcome of applying a webhook event
+type WebhookEventResult struct {
+	// Entitlement is the grant made or updated by a granted event
+	Entitlement *Entitlement
+	// Revoked counts the grants ended by a revoked event
+	Revoked int64
+}
+
+// processedEvent records a webhook event that has been applied
+type processedEvent struct {
+	ID         string    `gorm:"type:text;primary_key"`
+	ReceivedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName specifies the table name for processed webhook events
+func (processedEvent) TableName() string {
+	return "entitlement_webhook_events"
+}
+
+// Webhook event types
+const (
+	EventGranted = "entitlement.granted"
+	EventRevoked = "entitlement.revoked"
+)
+
+// AccessRequest changes whether a video requires entitlement
+type AccessRequest struct {
+	RequiresEntitlement *bool `json:"requires_entitlement" binding:"required"`
+}
+
+// AccessStatus describes whether a user may play a video
+type AccessStatus struct {
+	VideoID             uuid.UUID  `json:"video_id"`
+	RequiresEntitlement bool       `json:"requires_entitlement"`
+	HasAccess           bool       `json:"has_access"`
+	ExpiresAt           *time.Time `json:"expires_at,omitempty"`
+}
+
+// EntitlementListResponse represents a paginated list of entitlements
+type EntitlementListResponse struct {
+	Entitlements []Entitlement `json:"entitlements"`
+	Total        int64         `json:"total"`
+	Page         int           `json:"page"`
+	Limit        int           `json:"limit"`
+}
+
+// expiry resolves the expiry time of a grant issued at now
+func (r *GrantRequest) expiry(now time.Time) (*time.Time, error) {
+	if r.ExpiresAt != nil && r.DurationSeconds != 0 {
+		return nil, ErrInvalidGrant
+	}
+	if r.DurationSeconds < 0 {
+		return nil, ErrInvalidGrant
+	}
+	if r.DurationSeconds > 0 {
+		expiresAt := now.Add(time.Duration(r.DurationSeconds) * time.Second)
+		return &expiresAt, nil
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return nil, ErrInvalidGrant
+	}
+	return r.ExpiresAt, nil
+}
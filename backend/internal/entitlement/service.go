@@ -0,0 +1,307 @@
+package entitlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db     *gorm.DB
+	videos *video.VideoCache
+	logger logger.Logger
+}
+
+// NewService creates a new entitlement service. videos is the video cache
+// to invalidate when a video is gated or ungated; it may be nil.
+func NewService(db *gorm.DB, videos *video.VideoCache, logger logger.Logger) Service {
+	return &serviceImpl{
+		db:     db,
+		videos: videos,
+		logger: logger,
+	}
+}
+
+// Grant records access to a video
+func (s *serviceImpl) Grant(ctx context.Context, req *GrantRequest) (*Entitlement, error) {
+	if req.UserID == uuid.Nil || req.VideoID == uuid.Nil {
+		return nil, ErrInvalidGrant
+	}
+	now := time.Now()
+	expiresAt, err := req.expiry(now)
+	if err != nil {
+		return nil, err
+	}
+	source := req.Source
+	if source == "" {
+		source = "api"
+	}
+
+	if _, err := s.getVideo(ctx, req.VideoID); err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&auth.User{}).
+		Where("id = ? AND active = ?", req.UserID, true).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	// A retried webhook carries the same reference; update the grant instead of duplicating it
+	if req.Reference != "" {
+		var existing Entitlement
+		err := s.db.WithContext(ctx).
+			Where("source = ? AND reference = ?", source, req.Reference).
+			First(&existing).Error
+		switch {
+		case err == nil:
+			if err := s.db.WithContext(ctx).Model(&existing).Updates(map[string]interface{}{
+				"user_id":    req.UserID,
+				"video_id":   req.VideoID,
+				"expires_at": expiresAt,
+				"revoked_at": nil,
+				"updated_at": now,
+			}).Error; err != nil {
+				return nil, fmt.Errorf("failed to update entitlement: %w", err)
+			}
+			existing.UserID = req.UserID
+			existing.VideoID = req.VideoID
+			existing.ExpiresAt = expiresAt
+			existing.RevokedAt = nil
+			return &existing, nil
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, fmt.Errorf("failed to load entitlement: %w", err)
+		}
+	}
+
+	entitlement := &Entitlement{
+		ID:        uuid.New(),
+		UserID:    req.UserID,
+		VideoID:   req.VideoID,
+		Source:    source,
+		Reference: req.Reference,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.WithContext(ctx).Create(entitlement).Error; err != nil {
+		return nil, fmt.Errorf("failed to create entitlement: %w", err)
+	}
+
+	s.logger.LogInfo("Entitlement granted", map[string]interface{}{
+		"entitlement_id": entitlement.ID,
+		"user_id":        entitlement.UserID,
+		"video_id":       entitlement.VideoID,
+		"source":         entitlement.Source,
+	})
+	return entitlement, nil
+}
+
+// Revoke ends matching grants
+func (s *serviceImpl) Revoke(ctx context.Context, req *RevokeRequest) (int64, error) {
+	if req.Reference == "" && (req.UserID == uuid.Nil || req.VideoID == uuid.Nil) {
+		return 0, ErrInvalidGrant
+	}
+
+	query := s.db.WithContext(ctx).Model(&Entitlement{}).Where("revoked_at IS NULL")
+	if req.Reference != "" {
+		source := req.Source
+		if source == "" {
+			source = "api"
+		}
+		query = query.Where("source = ? AND reference = ?", source, req.Reference)
+	} else {
+		query = query.Where("user_id = ? AND video_id = ?", req.UserID, req.VideoID)
+	}
+
+	now := time.Now()
+	result := query.Updates(map[string]interface{}{"revoked_at": now, "updated_at": now})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke entitlements: %w", result.Error)
+	}
+
+	s.logger.LogInfo("Entitlements revoked", map[string]interface{}{
+		"user_id":   req.UserID,
+		"video_id":  req.VideoID,
+		"reference": req.Reference,
+		"count":     result.RowsAffected,
+	})
+	return result.RowsAffected, nil
+}
+
+// ApplyEvent applies a webhook event in the same transaction that records its
+// ID, so a failed event can be sent again and an applied one cannot
+func (s *serviceImpl) ApplyEvent(ctx context.Context, event *WebhookEvent, keep time.Duration) (*WebhookEventResult, error) {
+	if event.ID == "" || (event.Type != EventGranted && event.Type != EventRevoked) {
+		return nil, ErrInvalidEvent
+	}
+
+	var result WebhookEventResult
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Where("received_at < ?", now.Add(-keep)).Delete(&processedEvent{}).Error; err != nil {
+			return fmt.Errorf("failed to prune webhook events: %w", err)
+		}
+		recorded := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&processedEvent{ID: event.ID, ReceivedAt: now})
+		if recorded.Error != nil {
+			return fmt.Errorf("failed to record webhook event: %w", recorded.Error)
+		}
+		if recorded.RowsAffected == 0 {
+			return ErrDuplicateEvent
+		}
+
+		txService := &serviceImpl{db: tx, videos: s.videos, logger: s.logger}
+		var err error
+		if event.Type == EventGranted {
+			result.Entitlement, err = txService.Grant(ctx, &event.GrantRequest)
+		} else {
+			result.Revoked, err = txService.Revoke(ctx, &RevokeRequest{
+				UserID:    event.UserID,
+				VideoID:   event.VideoID,
+				Source:    event.Source,
+				Reference: event.Reference,
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// HasAccess reports whether userID holds an active entitlement to videoID
+func (s *serviceImpl) HasAccess(ctx context.Context, userID, videoID uuid.UUID) (bool, error) {
+	entitlement, err := s.activeEntitlement(ctx, userID, videoID)
+	if err != nil {
+		return false, err
+	}
+	return entitlement != nil, nil
+}
+
+// GetAccess describes userID's access to videoID
+func (s *serviceImpl) GetAccess(ctx context.Context, userID, videoID uuid.UUID) (*AccessStatus, error) {
+	v, err := s.getVideo(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &AccessStatus{
+		VideoID:             v.ID,
+		RequiresEntitlement: v.RequiresEntitlement,
+		HasAccess:           !v.RequiresEntitlement || v.UserID == userID,
+	}
+	if status.HasAccess {
+		return status, nil
+	}
+
+	entitlement, err := s.activeEntitlement(ctx, userID, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if entitlement != nil {
+		status.HasAccess = true
+		status.ExpiresAt = entitlement.ExpiresAt
+	}
+	return status, nil
+}
+
+// SetRequiresEntitlement gates or ungates a video
+func (s *serviceImpl) SetRequiresEntitlement(ctx context.Context, ownerID, videoID uuid.UUID, required bool) (*AccessStatus, error) {
+	v, err := s.getVideo(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if v.UserID != ownerID {
+		return nil, ErrNotVideoOwner
+	}
+
+	if err := s.db.WithContext(ctx).Model(&video.Video{}).Where("id = ?", videoID).
+		Updates(map[string]interface{}{"requires_entitlement": required, "updated_at": time.Now()}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update video: %w", err)
+	}
+	s.videos.Invalidate(ctx, videoID)
+
+	s.logger.LogInfo("Video access updated", map[string]interface{}{
+		"video_id":             videoID,
+		"requires_entitlement": required,
+	})
+	return &AccessStatus{VideoID: videoID, RequiresEntitlement: required, HasAccess: true}, nil
+}
+
+// ListForUser returns a user's entitlements, newest first
+func (s *serviceImpl) ListForUser(ctx context.Context, userID uuid.UUID, page, limit int) (*EntitlementListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	query := s.db.WithContext(ctx).Model(&Entitlement{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count entitlements: %w", err)
+	}
+
+	entitlements := make([]Entitlement, 0, limit)
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&entitlements).Error; err != nil {
+		return nil, fmt.Errorf("failed to list entitlements: %w", err)
+	}
+
+	return &EntitlementListResponse{
+		Entitlements: entitlements,
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}, nil
+}
+
+// activeEntitlement returns the active grant that lasts longest, or nil when there is none
+func (s *serviceImpl) activeEntitlement(ctx context.Context, userID, videoID uuid.UUID) (*Entitlement, error) {
+	var entitlements []Entitlement
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND video_id = ? AND revoked_at IS NULL", userID, videoID).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&entitlements).Error; err != nil {
+		return nil, fmt.Errorf("failed to load entitlements: %w", err)
+	}
+
+	var best *Entitlement
+	for i := range entitlements {
+		e := &entitlements[i]
+		if e.ExpiresAt == nil {
+			return e, nil
+		}
+		if best == nil || e.ExpiresAt.After(*best.ExpiresAt) {
+			best = e
+		}
+	}
+	return best, nil
+}
+
+// getVideo loads a video that has not been deleted
+func (s *serviceImpl) getVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	var v video.Video
+	if err := s.db.WithContext(ctx).First(&v, "id = ?", videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to load video: %w", err)
+	}
+	return &v, nil
+}
@@ -0,0 +1,85 @@
+package entitlement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGrantRequiresUserAndVideo(t *testing.T) {
+	service := NewService(nil, nil, nil)
+
+	_, err := service.Grant(context.Background(), &GrantRequest{VideoID: uuid.New()})
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+
+	_, err = service.Grant(context.Background(), &GrantRequest{UserID: uuid.New()})
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+}
+
+func TestRevokeRequiresTarget(t *testing.T) {
+	service := NewService(nil, nil, nil)
+
+	_, err := service.Revoke(context.Background(), &RevokeRequest{UserID: uuid.New()})
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+}
+
+func TestApplyEventRequiresIDAndType(t *testing.T) {
+	service := NewService(nil, nil, nil)
+
+	_, err := service.ApplyEvent(context.Background(), &WebhookEvent{Type: EventGranted}, time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+
+	_, err = service.ApplyEvent(context.Background(), &WebhookEvent{ID: "evt_1", Type: "entitlement.renewed"}, time.Minute)
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+}
+
+func TestGrantExpiry(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	t.Run("No expiry", func(t *testing.T) {
+		expiresAt, err := (&GrantRequest{}).expiry(now)
+		require.NoError(t, err)
+		assert.Nil(t, expiresAt)
+	})
+
+	t.Run("Rental duration", func(t *testing.T) {
+		expiresAt, err := (&GrantRequest{DurationSeconds: 48 * 3600}).expiry(now)
+		require.NoError(t, err)
+		require.NotNil(t, expiresAt)
+		assert.Equal(t, now.Add(48*time.Hour), *expiresAt)
+	})
+
+	t.Run("Fixed expiry", func(t *testing.T) {
+		expiresAt, err := (&GrantRequest{ExpiresAt: &future}).expiry(now)
+		require.NoError(t, err)
+		assert.Equal(t, &future, expiresAt)
+	})
+
+	t.Run("Invalid expiries", func(t *testing.T) {
+		for _, req := range []GrantRequest{
+			{ExpiresAt: &past},
+			{DurationSeconds: -1},
+			{ExpiresAt: &future, DurationSeconds: 60},
+		} {
+			_, err := req.expiry(now)
+			assert.ErrorIs(t, err, ErrInvalidGrant)
+		}
+	})
+}
+
+func TestEntitlementIsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.True(t, (&Entitlement{}).IsActive(now))
+	assert.True(t, (&Entitlement{ExpiresAt: &future}).IsActive(now))
+	assert.False(t, (&Entitlement{ExpiresAt: &past}).IsActive(now))
+	assert.False(t, (&Entitlement{RevokedAt: &past}).IsActive(now))
+}
@@ -0,0 +1,110 @@
+package federation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxResponseBytes bounds the documents and outbox pages read from remote instances
+const maxResponseBytes = 8 << 20
+
+// newClient returns the HTTP client remote instances are fetched with.
+// Redirects are not followed, so an instance cannot send its subscribers
+// elsewhere.
+func newClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// normalizeURL checks an instance URL and returns it without a trailing slash
+func normalizeURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil ||
+		u.RawQuery != "" || u.Fragment != "" {
+		return "", ErrInvalidURL
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+// get fetches url and returns its body and signature header
+func get(ctx context.Context, client *http.Client, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("%w: %s returned %d", ErrUnreachable, url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	return body, resp.Header.Get(SignatureHeader), nil
+}
+
+// fetchDocument fetches the federation document of the instance at baseURL
+func fetchDocument(ctx context.Context, client *http.Client, baseURL string) (*Document, error) {
+	body, _, err := get(ctx, client, baseURL+"/federation/instance")
+	if err != nil {
+		return nil, err
+	}
+
+	var document Document
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("%w: invalid instance document: %v", ErrUnreachable, err)
+	}
+	if _, err := parsePublicKey(document.PublicKey); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// fetchOutbox fetches the page of an instance's outbox after its cursor and
+// checks that the instance signed it for that cursor
+func fetchOutbox(ctx context.Context, client *http.Client, instance *Instance, limit int) (*Outbox, error) {
+	key, err := parsePublicKey(instance.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"since": {instance.Cursor}, "limit": {strconv.Itoa(limit)}}
+	body, signature, err := get(ctx, client, instance.URL+"/federation/outbox?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(ed25519.PublicKey(key), body, signature); err != nil {
+		return nil, err
+	}
+
+	var outbox Outbox
+	if err := json.Unmarshal(body, &outbox); err != nil {
+		return nil, fmt.Errorf("%w: invalid outbox: %v", ErrUnreachable, err)
+	}
+	if outbox.Since != instance.Cursor {
+		return nil, fmt.Errorf("%w: page was signed for another cursor", ErrBadSignature)
+	}
+	return &outbox, nil
+}
@@ -0,0 +1,175 @@
+package federation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for federation endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new federation handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the federation routes. The instance document and
+// outbox are public, since remote instances pull them without an account;
+// subscriptions are managed by admins.
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, adminMiddleware gin.HandlerFunc) {
+	router.GET("/federation/instance", h.handleGetDocument)
+	router.GET("/federation/outbox", h.handleGetOutbox)
+
+	instances := router.Group("/admin/federation/instances")
+	instances.Use(authMiddleware, adminMiddleware)
+	{
+		instances.POST("", h.handleRegister)
+		instances.GET("", h.handleList)
+		instances.DELETE("/:id", h.handleDelete)
+	}
+}
+
+// @Summary Get the federation document
+// @Description Returns this instance's name and the base64 Ed25519 public key its outbox is signed with. Served as bare JSON, outside the usual response envelope, for remote Pavilion instances; only served when federation is enabled.
+// @Tags federation
+// @Produce json
+// @Success 200 {object} Document "Federation document"
+// @Router /federation/instance [get]
+func (h *Handler) handleGetDocument(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Document())
+}
+
+// @Summary Get the federation outbox
+// @Description Returns a page of this instance's public videos, ordered by when each last changed. Videos that were deleted, taken down or made private since are listed with only their ID and deleted set. Pass next_cursor as since to continue. Served as bare JSON, outside the usual response envelope, with an X-Pavilion-Signature header of the form "ed25519=<base64 signature of the body>" made with the key in the federation document.
+// @Tags federation
+// @Produce json
+// @Param since query string false "Cursor from a previous page's next_cursor; omit to start from the beginning"
+// @Param limit query int false "Videos per page (default: 100, max: 500)"
+// @Success 200 {object} Outbox "Outbox page"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid cursor"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /federation/outbox [get]
+func (h *Handler) handleGetOutbox(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	body, signature, err := h.service.Outbox(c.Request.Context(), c.Query("since"), limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve outbox")
+		return
+	}
+
+	c.Header(SignatureHeader, signature)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// @Summary Subscribe to a remote instance
+// @Description Register a remote Pavilion instance by its API base URL. Its federation document is fetched and its public key pinned; its public videos are then pulled periodically and included in video listings with their origin.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RegisterRequest true "Instance URL"
+// @Success 200 {object} httpHandler.APIResponse{data=Instance} "Instance registered"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid URL"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Forbidden - admin only"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Instance already registered"
+// @Failure 502 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Instance unreachable or its document is invalid"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/federation/instances [post]
+func (h *Handler) handleRegister(c *gin.Context) {
+	var req RegisterRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	instance, err := h.service.Register(c.Request.Context(), &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to register instance")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, instance, "Instance registered successfully")
+}
+
+// @Summary List remote instances
+// @Description Get the remote instances this instance subscribes to, with the outcome of the last pull of each
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=[]Instance} "Instances retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Forbidden - admin only"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/federation/instances [get]
+func (h *Handler) handleList(c *gin.Context) {
+	instances, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve instances")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, instances, "Instances retrieved successfully")
+}
+
+// @Summary Unsubscribe from a remote instance
+// @Description Stop pulling a remote instance and remove its videos from listings
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Instance ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse "Instance deleted"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid instance ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Forbidden - admin only"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Instance not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/federation/instances/{id} [delete]
+func (h *Handler) handleDelete(c *gin.Context) {
+	instanceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid instance ID", err)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), instanceID); err != nil {
+		h.handleServiceError(c, err, "Failed to delete instance")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "Instance deleted successfully")
+}
+
+// handleServiceError maps federation service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidURL):
+		h.responseHandler.ValidationErrorResponse(c, "url", err.Error())
+	case errors.Is(err, ErrInvalidCursor):
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", err.Error(), err)
+	case errors.Is(err, ErrInstanceExists):
+		h.responseHandler.ErrorResponse(c, http.StatusConflict, "CONFLICT", err.Error(), err)
+	case errors.Is(err, ErrInstanceNotFound):
+		h.responseHandler.NotFoundResponse(c, "Instance not found")
+	case errors.Is(err, ErrUnreachable), errors.Is(err, ErrInvalidKey):
+		h.responseHandler.ErrorResponse(c, http.StatusBadGateway, "INSTANCE_UNREACHABLE", err.Error(), err)
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
@@ -0,0 +1,42 @@
+package federation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidURL is returned when an instance URL is not an absolute http or https URL
+	ErrInvalidURL = errors.New("instance URL must be an absolute http or https URL")
+	// ErrInvalidKey is returned when an instance publishes a key that is not a base64 Ed25519 public key
+	ErrInvalidKey = errors.New("instance public key is not a base64 Ed25519 key")
+	// ErrBadSignature is returned when an outbox page is unsigned or its signature does not match the instance's key
+	ErrBadSignature = errors.New("outbox signature does not match the instance's key")
+	// ErrInstanceExists is returned when an instance is registered twice
+	ErrInstanceExists = errors.New("instance already registered")
+	// ErrInstanceNotFound is returned when the instance does not exist
+	ErrInstanceNotFound = errors.New("instance not found")
+	// ErrUnreachable is returned when a remote instance cannot be fetched from
+	ErrUnreachable = errors.New("instance unreachable")
+	// ErrInvalidCursor is returned when an outbox cursor cannot be decoded
+	ErrInvalidCursor = errors.New("invalid outbox cursor")
+)
+
+// Service defines the interface for federation between Pavilion instances
+type Service interface {
+	// Document returns this instance's federation document
+	Document() Document
+	// Outbox returns a page of this instance's public video changes after since, and its signature
+	Outbox(ctx context.Context, since string, limit int) (body []byte, signature string, err error)
+	// Register subscribes to a remote instance, pinning the key its document publishes
+	Register(ctx context.Context, req *RegisterRequest) (*Instance, error)
+	// List returns the registered instances, oldest first
+	List(ctx context.Context) ([]Instance, error)
+	// Delete unsubscribes from an instance and drops its videos
+	Delete(ctx context.Context, instanceID uuid.UUID) error
+	// ListRemoteVideos returns a page of remote videos for video listings
+	ListRemoteVideos(ctx context.Context, offset, limit int, filter video.ListFilter) ([]video.VideoDetailsResponse, int64, error)
+}
@@ -0,0 +1,137 @@
+package federation
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Instance is a remote Pavilion node this node subscribes to
+type Instance struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	// URL is the base URL of the remote API, under which its federation endpoints are served
+	URL  string `gorm:"type:text;not null;uniqueIndex" json:"url" example:"https://peer.example.com/api/v1"`
+	Name string `gorm:"type:text;not null" json:"name" example:"Peer Pavilion"`
+	// PublicKey is the base64 Ed25519 key the instance signs its outbox with,
+	// pinned when the instance is registered
+	PublicKey string `gorm:"type:text;not null" json:"public_key" example:"11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="`
+	// Cursor is where the next pull of the instance's outbox starts
+	Cursor string `gorm:"column:outbox_cursor;type:text;not null;default:''" json:"-"`
+	// LastPolledAt and LastError describe the most recent pull
+	LastPolledAt *time.Time `json:"last_polled_at,omitempty"`
+	LastError    string     `gorm:"type:text;not null;default:''" json:"last_error,omitempty" example:"outbox signature does not match the instance's key"`
+	CreatedAt    time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName keeps the federation tables together
+func (Instance) TableName() string {
+	return "federation_instances"
+}
+
+// Tags is a list of tag names stored as a comma-separated list
+type Tags []string
+
+// Value implements driver.Valuer
+func (t Tags) Value() (driver.Value, error) {
+	return strings.Join(t, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (t *Tags) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+		*t = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for Tags: %T", value)
+	}
+
+	*t = nil
+	for _, part := range strings.Split(raw, ",") {
+		if part != "" {
+			*t = append(*t, part)
+		}
+	}
+	return nil
+}
+
+// RemoteVideo is a reference to a public video of a remote instance, as
+// last pulled from its outbox
+type RemoteVideo struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	InstanceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_federation_remote_videos_remote"`
+	// RemoteID is the video's ID on its instance
+	RemoteID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_federation_remote_videos_remote"`
+	Title       string    `gorm:"type:text;not null"`
+	Description string    `gorm:"type:text;not null;default:''"`
+	Category    string    `gorm:"type:text;not null;default:''"`
+	Tags        Tags      `gorm:"type:text;not null;default:''"`
+	Duration    float64   `gorm:"not null;default:0"`
+	Views       int64     `gorm:"not null;default:0"`
+	IPFSCID     string    `gorm:"column:ipfs_cid;type:text;not null;default:''"`
+	// PublishedAt and RemoteUpdatedAt are the video's creation and last
+	// update on its instance
+	PublishedAt     time.Time `gorm:"not null;index"`
+	RemoteUpdatedAt time.Time `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"not null;default:now()"`
+	UpdatedAt       time.Time `gorm:"not null;default:now()"`
+	Instance        *Instance `gorm:"foreignKey:InstanceID"`
+}
+
+// TableName keeps the federation tables together
+func (RemoteVideo) TableName() string {
+	return "federation_remote_videos"
+}
+
+// RegisterRequest names a remote instance to subscribe to
+type RegisterRequest struct {
+	// URL is the base URL of the remote API, e.g. https://peer.example.com/api/v1
+	URL string `json:"url" binding:"required" example:"https://peer.example.com/api/v1"`
+}
+
+// Document identifies an instance to those subscribing to it. It is served
+// unsigned at /federation/instance; its key is pinned at registration.
+type Document struct {
+	Name string `json:"name" example:"Pavilion"`
+	// PublicKey is the base64 Ed25519 key outbox pages are signed with
+	PublicKey string `json:"public_key" example:"11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="`
+	Software  string `json:"software" example:"pavilion"`
+}
+
+// OutboxVideo is a public video as listed in an outbox. A deleted entry only
+// carries its ID: the video was deleted or is no longer public, and
+// subscribers drop their reference to it.
+type OutboxVideo struct {
+	ID          string    `json:"id" example:"3f6c2a4e-8b1d-4c6e-9a7f-2d5b8e1c0a94"`
+	Deleted     bool      `json:"deleted,omitempty"`
+	Title       string    `json:"title,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Category    string    `json:"category,omitempty" example:"education"`
+	Tags        []string  `json:"tags,omitempty" example:"tutorial,golang"`
+	Duration    float64   `json:"duration,omitempty" example:"754.2"`
+	Views       int64     `json:"views,omitempty" example:"1280"`
+	IPFSCID     string    `json:"ipfs_cid,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Outbox is a page of an instance's public video changes, oldest first. It
+// is signed with the instance's key; see SignatureHeader.
+type Outbox struct {
+	// Since is the cursor the page was requested with, so a page cannot be
+	// replayed for another request
+	Since  string        `json:"since"`
+	Videos []OutboxVideo `json:"videos"`
+	// NextCursor continues after the last video; pass it as since
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
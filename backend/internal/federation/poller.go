@@ -0,0 +1,223 @@
+package federation
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PollerConfig represents how remote instances are pulled
+type PollerConfig struct {
+	// Interval is how often every instance's outbox is pulled
+	Interval time.Duration
+	// PageSize is how many videos are requested per outbox page
+	PageSize int
+	// MaxPages bounds how many pages of one instance are pulled per poll, so
+	// a large instance cannot hold up the others
+	MaxPages int
+	// Timeout bounds each request to a remote instance
+	Timeout time.Duration
+}
+
+// Poller periodically pulls the outboxes of registered instances and stores
+// their public videos. Each instance's cursor is kept in the database, so a
+// restart continues where the last pull stopped.
+type Poller struct {
+	db     *gorm.DB
+	config PollerConfig
+	client *http.Client
+	logger logger.Logger
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPoller creates a federation poller
+func NewPoller(db *gorm.DB, config PollerConfig, logger logger.Logger) *Poller {
+	if config.Interval <= 0 {
+		config.Interval = 5 * time.Minute
+	}
+	if config.PageSize < 1 || config.PageSize > maxOutboxLimit {
+		config.PageSize = defaultOutboxLimit
+	}
+	if config.MaxPages < 1 {
+		config.MaxPages = 1
+	}
+	return &Poller{
+		db:     db,
+		config: config,
+		client: newClient(config.Timeout),
+		logger: logger,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Start starts pulling, which runs until Stop is called
+func (p *Poller) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go p.run(ctx)
+}
+
+// Stop stops pulling and waits for a pull in progress to finish
+func (p *Poller) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+// Wake starts a pull without waiting for the next interval
+func (p *Poller) Wake() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run pulls every instance, then waits to be woken or for the next interval
+func (p *Poller) run(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.pollAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollAll pulls the outbox of every registered instance
+func (p *Poller) pollAll(ctx context.Context) {
+	var instances []Instance
+	if err := p.db.WithContext(ctx).Order("created_at ASC").Find(&instances).Error; err != nil {
+		if ctx.Err() == nil {
+			p.logger.LogError(err, "Failed to load federated instances")
+		}
+		return
+	}
+
+	for i := range instances {
+		if ctx.Err() != nil {
+			return
+		}
+		p.poll(ctx, &instances[i])
+	}
+}
+
+// poll pulls pages of an instance's outbox until it is caught up or the page
+// limit is reached, and records the outcome on the instance
+func (p *Poller) poll(ctx context.Context, instance *Instance) {
+	var pullErr error
+	for page := 0; page < p.config.MaxPages; page++ {
+		outbox, err := fetchOutbox(ctx, p.client, instance, p.config.PageSize)
+		if err != nil {
+			pullErr = err
+			break
+		}
+		if err := p.apply(ctx, instance, outbox); err != nil {
+			pullErr = err
+			break
+		}
+		instance.Cursor = outbox.NextCursor
+		if !outbox.HasMore {
+			break
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	lastError := ""
+	if pullErr != nil {
+		lastError = pullErr.Error()
+		p.logger.LogWarn("Failed to pull federated instance", map[string]interface{}{
+			"instance_id": instance.ID,
+			"url":         instance.URL,
+			"error":       lastError,
+		})
+	}
+	now := time.Now()
+	if err := p.db.WithContext(ctx).Model(&Instance{}).Where("id = ?", instance.ID).
+		Updates(map[string]interface{}{"last_polled_at": now, "last_error": lastError, "updated_at": now}).Error; err != nil {
+		p.logger.LogError(err, "Failed to record federated instance pull")
+	}
+}
+
+// apply stores the videos of an outbox page and advances the instance's
+// cursor past them
+func (p *Poller) apply(ctx context.Context, instance *Instance, outbox *Outbox) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, entry := range outbox.Videos {
+			remoteID, err := uuid.Parse(entry.ID)
+			if err != nil {
+				p.logger.LogWarn("Skipping federated video with an invalid ID", map[string]interface{}{
+					"instance_id": instance.ID,
+					"remote_id":   entry.ID,
+				})
+				continue
+			}
+
+			if entry.Deleted {
+				if err := tx.Where("instance_id = ? AND remote_id = ?", instance.ID, remoteID).Delete(&RemoteVideo{}).Error; err != nil {
+					return fmt.Errorf("failed to delete remote video: %w", err)
+				}
+				continue
+			}
+
+			remote := remoteVideo(instance.ID, remoteID, &entry)
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "instance_id"}, {Name: "remote_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"title", "description", "category", "tags", "duration", "views",
+					"ipfs_cid", "published_at", "remote_updated_at", "updated_at",
+				}),
+			}).Create(&remote).Error; err != nil {
+				return fmt.Errorf("failed to store remote video: %w", err)
+			}
+		}
+
+		if err := tx.Model(&Instance{}).Where("id = ?", instance.ID).
+			Update("outbox_cursor", outbox.NextCursor).Error; err != nil {
+			return fmt.Errorf("failed to advance instance cursor: %w", err)
+		}
+		return nil
+	})
+}
+
+// remoteVideo returns the stored reference to an outbox video
+func remoteVideo(instanceID, remoteID uuid.UUID, entry *OutboxVideo) RemoteVideo {
+	now := time.Now()
+	return RemoteVideo{
+		ID:              uuid.New(),
+		InstanceID:      instanceID,
+		RemoteID:        remoteID,
+		Title:           entry.Title,
+		Description:     entry.Description,
+		Category:        entry.Category,
+		Tags:            Tags(entry.Tags),
+		Duration:        entry.Duration,
+		Views:           entry.Views,
+		IPFSCID:         entry.IPFSCID,
+		PublishedAt:     entry.CreatedAt,
+		RemoteUpdatedAt: entry.UpdatedAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
@@ -0,0 +1,313 @@
+package federation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox page sizes
+const (
+	defaultOutboxLimit = 100
+	maxOutboxLimit     = 500
+)
+
+// Config represents this instance's federation identity
+type Config struct {
+	// Name is the instance name published in its document
+	Name string
+	// PrivateKey signs outbox pages
+	PrivateKey ed25519.PrivateKey
+	// Timeout bounds each request to a remote instance
+	Timeout time.Duration
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db     *gorm.DB
+	config Config
+	client *http.Client
+	poller *Poller
+	logger logger.Logger
+}
+
+// NewService creates a new federation service. Newly registered instances
+// are pulled by the poller, which is woken for them; a nil poller leaves them
+// for whichever instance polls next.
+func NewService(db *gorm.DB, config Config, poller *Poller, logger logger.Logger) Service {
+	return &serviceImpl{
+		db:     db,
+		config: config,
+		client: newClient(config.Timeout),
+		poller: poller,
+		logger: logger,
+	}
+}
+
+// Document returns this instance's federation document
+func (s *serviceImpl) Document() Document {
+	return Document{
+		Name:      s.config.Name,
+		PublicKey: EncodePublicKey(s.config.PrivateKey.Public().(ed25519.PublicKey)),
+		Software:  "pavilion",
+	}
+}
+
+// Outbox returns a page of this instance's public video changes after since,
+// ordered by when each video last changed. Videos that were deleted or are
+// no longer public are listed as deleted, so subscribers drop them.
+func (s *serviceImpl) Outbox(ctx context.Context, since string, limit int) ([]byte, string, error) {
+	if limit < 1 {
+		limit = defaultOutboxLimit
+	} else if limit > maxOutboxLimit {
+		limit = maxOutboxLimit
+	}
+
+	query := s.db.WithContext(ctx).Unscoped().Preload("Upload").Preload("Tags")
+	if since != "" {
+		at, id, err := decodeCursor(since)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("COALESCE(deleted_at, updated_at) > ? OR (COALESCE(deleted_at, updated_at) = ? AND id > ?)", at, at, id)
+	}
+
+	var videos []video.Video
+	if err := query.Order("COALESCE(deleted_at, updated_at) ASC, id ASC").Limit(limit + 1).Find(&videos).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to list outbox videos: %w", err)
+	}
+
+	outbox := Outbox{Since: since, Videos: make([]OutboxVideo, 0, len(videos)), NextCursor: since}
+	if len(videos) > limit {
+		outbox.HasMore = true
+		videos = videos[:limit]
+	}
+	for i := range videos {
+		outbox.Videos = append(outbox.Videos, outboxVideo(&videos[i]))
+	}
+	if len(videos) > 0 {
+		last := &videos[len(videos)-1]
+		outbox.NextCursor = encodeCursor(changedAt(last), last.ID)
+	}
+
+	body, err := json.Marshal(outbox)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode outbox: %w", err)
+	}
+	return body, Sign(s.config.PrivateKey, body), nil
+}
+
+// Register subscribes to the instance at req.URL, pinning the key its
+// document publishes
+func (s *serviceImpl) Register(ctx context.Context, req *RegisterRequest) (*Instance, error) {
+	baseURL, err := normalizeURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&Instance{}).Where("url = ?", baseURL).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check instance: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrInstanceExists
+	}
+
+	document, err := fetchDocument(ctx, s.client, baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	instance := Instance{
+		ID:        uuid.New(),
+		URL:       baseURL,
+		Name:      document.Name,
+		PublicKey: document.PublicKey,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.db.WithContext(ctx).Create(&instance).Error; err != nil {
+		return nil, fmt.Errorf("failed to create instance: %w", err)
+	}
+
+	s.logger.LogInfo("Subscribed to federated instance", map[string]interface{}{
+		"instance_id": instance.ID,
+		"url":         instance.URL,
+	})
+	if s.poller != nil {
+		s.poller.Wake()
+	}
+	return &instance, nil
+}
+
+// List returns the registered instances, oldest first
+func (s *serviceImpl) List(ctx context.Context) ([]Instance, error) {
+	instances := make([]Instance, 0)
+	if err := s.db.WithContext(ctx).Order("created_at ASC").Find(&instances).Error; err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	return instances, nil
+}
+
+// Delete unsubscribes from an instance and drops its videos
+func (s *serviceImpl) Delete(ctx context.Context, instanceID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", instanceID).Delete(&Instance{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete instance: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrInstanceNotFound
+		}
+		if err := tx.Where("instance_id = ?", instanceID).Delete(&RemoteVideo{}).Error; err != nil {
+			return fmt.Errorf("failed to delete remote videos: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListRemoteVideos returns a page of remote videos, filtered and ordered
+// like local listings
+func (s *serviceImpl) ListRemoteVideos(ctx context.Context, offset, limit int, filter video.ListFilter) ([]video.VideoDetailsResponse, int64, error) {
+	query := s.db.WithContext(ctx).Model(&RemoteVideo{})
+	if filter.Category != "" {
+		query = query.Where("category = ?", string(filter.Category))
+	}
+	if filter.Tag != "" {
+		query = query.Where("(',' || tags || ',') LIKE ?", "%,"+filter.Tag+",%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count remote videos: %w", err)
+	}
+
+	var videos []RemoteVideo
+	if err := query.Preload("Instance").Order(orderClause(filter.Sort)).Offset(offset).Limit(limit).Find(&videos).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list remote videos: %w", err)
+	}
+
+	responses := make([]video.VideoDetailsResponse, 0, len(videos))
+	for i := range videos {
+		responses = append(responses, remoteVideoResponse(&videos[i]))
+	}
+	return responses, total, nil
+}
+
+// orderClause returns the ORDER BY clause for sort, matching the order of
+// local listings with the remote publication time as the creation time
+func orderClause(sort video.ListSort) string {
+	switch sort {
+	case video.SortOldest:
+		return "published_at ASC, remote_id ASC"
+	case video.SortMostViewed:
+		return "views DESC, published_at DESC, remote_id DESC"
+	case video.SortTitle:
+		return "lower(title) ASC, remote_id ASC"
+	default:
+		return "published_at DESC, remote_id DESC"
+	}
+}
+
+// remoteVideoResponse returns the listing entry of a remote video
+func remoteVideoResponse(v *RemoteVideo) video.VideoDetailsResponse {
+	tags := []string(v.Tags)
+	if tags == nil {
+		tags = []string{}
+	}
+	response := video.VideoDetailsResponse{
+		ID:          v.RemoteID.String(),
+		Title:       v.Title,
+		Description: v.Description,
+		IPFSCID:     v.IPFSCID,
+		Status:      string(video.UploadStatusCompleted),
+		Category:    v.Category,
+		Tags:        tags,
+		Views:       v.Views,
+		CreatedAt:   v.PublishedAt,
+		UpdatedAt:   v.RemoteUpdatedAt,
+		Duration:    v.Duration,
+	}
+	if v.Instance != nil {
+		response.Origin = v.Instance.URL
+	}
+	return response
+}
+
+// listable reports whether a video is public and listed in the outbox
+func listable(v *video.Video) bool {
+	return !v.DeletedAt.Valid && v.Upload != nil && v.Upload.Status == video.UploadStatusCompleted &&
+		!v.RequiresEntitlement && !v.ScanStatus.Hidden() && v.TakenDownAt == nil
+}
+
+// changedAt returns when a video last changed, which orders the outbox
+func changedAt(v *video.Video) time.Time {
+	if v.DeletedAt.Valid {
+		return v.DeletedAt.Time
+	}
+	return v.UpdatedAt
+}
+
+// outboxVideo returns the outbox entry of a video
+func outboxVideo(v *video.Video) OutboxVideo {
+	if !listable(v) {
+		return OutboxVideo{ID: v.ID.String(), Deleted: true, CreatedAt: v.CreatedAt, UpdatedAt: changedAt(v)}
+	}
+
+	entry := OutboxVideo{
+		ID:          v.ID.String(),
+		Title:       v.Title,
+		Description: v.Description,
+		Category:    string(v.Category),
+		Duration:    v.Duration,
+		Views:       v.Views,
+		CreatedAt:   v.CreatedAt,
+		UpdatedAt:   v.UpdatedAt,
+	}
+	for _, tag := range v.Tags {
+		entry.Tags = append(entry.Tags, tag.Name)
+	}
+	if v.Replication == video.ReplicationReplicated {
+		entry.IPFSCID = v.IPFSCID
+	}
+	return entry
+}
+
+// encodeCursor returns the outbox cursor continuing after the video with id
+// that changed at at
+func encodeCursor(at time.Time, id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(at.UnixNano(), 10) + ":" + id.String()))
+}
+
+// decodeCursor parses an outbox cursor
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	nanos, rest, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	id, err := uuid.Parse(rest)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	return time.Unix(0, unixNano).UTC(), id, nil
+}
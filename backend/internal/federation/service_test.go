@@ -0,0 +1,203 @@
+package federation
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// testKey is a fixed signing key for tests
+var testKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"since":"","videos":[]}`)
+	signature := Sign(testKey, body)
+	public := testKey.Public().(ed25519.PublicKey)
+
+	assert.NoError(t, Verify(public, body, signature))
+	assert.ErrorIs(t, Verify(public, []byte(`{"since":"","videos":null}`), signature), ErrBadSignature)
+	assert.ErrorIs(t, Verify(public, body, ""), ErrBadSignature)
+	assert.ErrorIs(t, Verify(public, body, "ed25519=not-base64"), ErrBadSignature)
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := ParsePrivateKey("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	require.NoError(t, err)
+	assert.Len(t, key, ed25519.PrivateKeySize)
+
+	_, err = ParsePrivateKey("c2hvcnQ=")
+	assert.Error(t, err)
+	_, err = ParsePrivateKey("not base64!")
+	assert.Error(t, err)
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 17, 9, 30, 0, 123456000, time.UTC)
+	id := uuid.New()
+
+	gotAt, gotID, err := decodeCursor(encodeCursor(at, id))
+	require.NoError(t, err)
+	assert.True(t, at.Equal(gotAt))
+	assert.Equal(t, id, gotID)
+
+	for _, cursor := range []string{"%%%", "bm8tY29sb24", "MTIzOm5vdC1hLXV1aWQ"} {
+		_, _, err := decodeCursor(cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
+
+func TestOutboxVideo(t *testing.T) {
+	now := time.Now()
+	public := func() video.Video {
+		return video.Video{
+			ID:          uuid.New(),
+			Title:       "Intro to Go",
+			Category:    video.Category("education"),
+			IPFSCID:     "bafy",
+			Replication: video.ReplicationReplicated,
+			Upload:      &video.VideoUpload{Status: video.UploadStatusCompleted},
+			Tags:        []video.Tag{{Name: "golang"}},
+			CreatedAt:   now.Add(-time.Hour),
+			UpdatedAt:   now,
+		}
+	}
+
+	v := public()
+	entry := outboxVideo(&v)
+	assert.False(t, entry.Deleted)
+	assert.Equal(t, "Intro to Go", entry.Title)
+	assert.Equal(t, []string{"golang"}, entry.Tags)
+	assert.Equal(t, "bafy", entry.IPFSCID)
+
+	v.Replication = video.ReplicationS3Only
+	assert.Empty(t, outboxVideo(&v).IPFSCID, "CIDs are only published once replicated")
+
+	hidden := map[string]func(*video.Video){
+		"deleted":     func(v *video.Video) { v.DeletedAt = gorm.DeletedAt{Time: now.Add(time.Minute), Valid: true} },
+		"gated":       func(v *video.Video) { v.RequiresEntitlement = true },
+		"quarantined": func(v *video.Video) { v.ScanStatus = video.ScanQuarantined },
+		"taken down":  func(v *video.Video) { v.TakenDownAt = &now },
+		"processing":  func(v *video.Video) { v.Upload.Status = video.UploadStatusPending },
+	}
+	for name, hide := range hidden {
+		v := public()
+		hide(&v)
+		entry := outboxVideo(&v)
+		assert.True(t, entry.Deleted, name)
+		assert.Empty(t, entry.Title, name)
+		assert.Equal(t, changedAt(&v), entry.UpdatedAt, name)
+	}
+}
+
+func TestRemoteVideoResponse(t *testing.T) {
+	remote := RemoteVideo{
+		RemoteID:    uuid.New(),
+		Title:       "Remote talk",
+		PublishedAt: time.Now(),
+		Instance:    &Instance{URL: "https://peer.example.com/api/v1"},
+	}
+
+	response := remoteVideoResponse(&remote)
+	assert.Equal(t, remote.RemoteID.String(), response.ID)
+	assert.Equal(t, "https://peer.example.com/api/v1", response.Origin)
+	assert.Equal(t, []string{}, response.Tags)
+	assert.True(t, remote.PublishedAt.Equal(response.CreatedAt))
+}
+
+func TestTagsRoundTrip(t *testing.T) {
+	value, err := Tags{"golang", "tutorial"}.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "golang,tutorial", value)
+
+	var tags Tags
+	require.NoError(t, tags.Scan("golang,tutorial"))
+	assert.Equal(t, Tags{"golang", "tutorial"}, tags)
+	require.NoError(t, tags.Scan(""))
+	assert.Empty(t, tags)
+}
+
+func TestNormalizeURL(t *testing.T) {
+	got, err := normalizeURL(" https://peer.example.com/api/v1/ ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://peer.example.com/api/v1", got)
+
+	for _, raw := range []string{"", "peer.example.com", "ftp://peer.example.com", "https://user:pw@peer.example.com", "https://peer.example.com/?x=1"} {
+		_, err := normalizeURL(raw)
+		assert.ErrorIs(t, err, ErrInvalidURL, raw)
+	}
+}
+
+func TestRegisterValidatesURL(t *testing.T) {
+	service := NewService(nil, Config{PrivateKey: testKey}, nil, nil)
+
+	_, err := service.Register(context.Background(), &RegisterRequest{URL: "peer.example.com"})
+	assert.ErrorIs(t, err, ErrInvalidURL)
+}
+
+// outboxServer serves a federation document and one outbox page, signed with key
+func outboxServer(t *testing.T, key ed25519.PrivateKey, outbox Outbox) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/federation/instance":
+			json.NewEncoder(w).Encode(Document{Name: "Peer", PublicKey: EncodePublicKey(key.Public().(ed25519.PublicKey)), Software: "pavilion"})
+		case "/federation/outbox":
+			outbox.Since = r.URL.Query().Get("since")
+			body, err := json.Marshal(outbox)
+			require.NoError(t, err)
+			w.Header().Set(SignatureHeader, Sign(key, body))
+			w.Write(body)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestFetchOutbox(t *testing.T) {
+	server := outboxServer(t, testKey, Outbox{
+		Videos:     []OutboxVideo{{ID: uuid.NewString(), Title: "Remote talk"}},
+		NextCursor: "next",
+	})
+	defer server.Close()
+
+	client := newClient(time.Second)
+	document, err := fetchDocument(context.Background(), client, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "Peer", document.Name)
+
+	instance := &Instance{URL: server.URL, PublicKey: document.PublicKey, Cursor: "previous"}
+	outbox, err := fetchOutbox(context.Background(), client, instance, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", outbox.Since)
+	assert.Equal(t, "next", outbox.NextCursor)
+	require.Len(t, outbox.Videos, 1)
+	assert.Equal(t, "Remote talk", outbox.Videos[0].Title)
+}
+
+func TestFetchOutboxRejectsOtherKeys(t *testing.T) {
+	impostor := ed25519.NewKeyFromSeed([]byte("an impostor's 32-byte test seed!"))
+	server := outboxServer(t, impostor, Outbox{Videos: []OutboxVideo{}})
+	defer server.Close()
+
+	instance := &Instance{URL: server.URL, PublicKey: EncodePublicKey(testKey.Public().(ed25519.PublicKey))}
+	_, err := fetchOutbox(context.Background(), newClient(time.Second), instance, 10)
+	assert.ErrorIs(t, err, ErrBadSignature)
+}
+
+func TestFetchDocumentUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := fetchDocument(context.Background(), newClient(time.Second), server.URL)
+	assert.ErrorIs(t, err, ErrUnreachable)
+}
@@ -0,0 +1,59 @@
+package federation
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// SignatureHeader carries the base64 Ed25519 signature of an outbox body
+// with the instance's key, prefixed with "ed25519="
+const SignatureHeader = "X-Pavilion-Signature"
+
+// ParsePrivateKey decodes a base64 Ed25519 seed, as set in
+// federation.privateKey
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("federation private key is not base64: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("federation private key must be a %d-byte Ed25519 seed, got %d bytes", ed25519.SeedSize, len(seed))
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// EncodePublicKey returns the base64 form of a public key published in the
+// instance document
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// parsePublicKey decodes a key published in an instance document
+func parsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// Sign returns the signature header value of body
+func Sign(key ed25519.PrivateKey, body []byte) string {
+	return "ed25519=" + base64.StdEncoding.EncodeToString(ed25519.Sign(key, body))
+}
+
+// Verify checks a signature header value against body and the signer's
+// public key
+func Verify(key ed25519.PublicKey, body []byte, header string) error {
+	encoded, ok := strings.CutPrefix(header, "ed25519=")
+	if !ok {
+		return ErrBadSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || !ed25519.Verify(key, body, signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
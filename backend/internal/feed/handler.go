@@ -0,0 +1,146 @@
+package feed
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for feeds
+type Handler struct {
+	service         Service
+	maxAge          time.Duration
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new feed handler instance. Clients and proxies may
+// reuse a feed for maxAge before fetching it again.
+func NewHandler(service Service, maxAge time.Duration, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		maxAge:          maxAge,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the feed routes. They are public, so podcast apps
+// and aggregators can subscribe without an account.
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/users/:id/feed.rss", h.handleChannelFeed)
+	router.GET("/feeds/latest", h.handleLatestFeed)
+}
+
+// @Summary Get a channel's feed
+// @Description MRSS feed of a user's newest public videos, with each video's renditions as media:content and its original file as the enclosure. Files are linked on the CDN when its URLs do not expire, or else on the IPFS gateway once replicated; videos neither can link are listed without files. Carries an ETag and Last-Modified, and answers If-None-Match and If-Modified-Since with 304. Only served when feeds are enabled.
+// @Tags feeds
+// @Produce xml
+// @Param id path string true "User ID (UUID)"
+// @Param If-None-Match header string false "ETag of a copy the client already has"
+// @Success 200 {object} RSS "Feed"
+// @Success 304 "Feed unchanged"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/feed.rss [get]
+func (h *Handler) handleChannelFeed(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return
+	}
+
+	rss, err := h.service.Channel(c.Request.Context(), userID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to build feed")
+		return
+	}
+	h.writeFeed(c, rss)
+}
+
+// @Summary Get the latest videos feed
+// @Description MRSS feed of the newest public videos of every channel, built like channel feeds. Only served when feeds are enabled.
+// @Tags feeds
+// @Produce xml
+// @Param If-None-Match header string false "ETag of a copy the client already has"
+// @Success 200 {object} RSS "Feed"
+// @Success 304 "Feed unchanged"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /feeds/latest [get]
+func (h *Handler) handleLatestFeed(c *gin.Context) {
+	rss, err := h.service.Latest(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to build feed")
+		return
+	}
+	h.writeFeed(c, rss)
+}
+
+// writeFeed writes a feed with its caching headers, or 304 when the client's
+// copy is current
+func (h *Handler) writeFeed(c *gin.Context, rss *RSS) {
+	rss.Channel.Self.Href = requestURL(c)
+	if rss.Channel.Link == "" {
+		rss.Channel.Link = rss.Channel.Self.Href
+	}
+
+	body, err := xml.Marshal(rss)
+	if err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to encode feed", err)
+		return
+	}
+	body = append([]byte(xml.Header), body...)
+
+	if !rss.Updated.IsZero() {
+		c.Header("Last-Modified", rss.Updated.UTC().Format(http.TimeFormat))
+	}
+	if httpHandler.NotModified(c, httpHandler.ETag(string(body)), h.cacheControl()) {
+		return
+	}
+	// If-Modified-Since only counts when the client sent no ETag to compare
+	if c.GetHeader("If-None-Match") == "" && !rss.Updated.IsZero() {
+		if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !rss.Updated.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+	}
+	c.Data(http.StatusOK, ContentType+"; charset=utf-8", body)
+}
+
+// cacheControl returns the Cache-Control value of feeds
+func (h *Handler) cacheControl() string {
+	if h.maxAge <= 0 {
+		return httpHandler.CacheControlPublic
+	}
+	return fmt.Sprintf("public, max-age=%d", int64(h.maxAge/time.Second))
+}
+
+// requestURL returns the absolute URL a feed was requested at, which
+// aggregators identify it by
+func requestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + c.Request.URL.Path
+}
+
+// handleServiceError maps feed service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		h.responseHandler.NotFoundResponse(c, "User not found")
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
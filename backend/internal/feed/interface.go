@@ -0,0 +1,21 @@
+package feed
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrUserNotFound is returned when no active user has the given ID
+var ErrUserNotFound = errors.New("user not found")
+
+// Service defines the interface for building feeds. Feeds list public
+// videos only: completed, needing no entitlement, not quarantined and not
+// taken down.
+type Service interface {
+	// Channel returns the feed of a user's newest public videos
+	Channel(ctx context.Context, userID uuid.UUID) (*RSS, error)
+	// Latest returns the feed of the newest public videos of every channel
+	Latest(ctx context.Context) (*RSS, error)
+}
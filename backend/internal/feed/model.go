@@ -0,0 +1,104 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// ContentType is the media type feeds are served with
+const ContentType = "application/rss+xml"
+
+// XML namespaces of the Media RSS and Atom extensions feeds use
+const (
+	mediaNamespace = "http://search.yahoo.com/mrss/"
+	atomNamespace  = "http://www.w3.org/2005/Atom"
+)
+
+// RSS is an RSS 2.0 document with Media RSS extensions
+type RSS struct {
+	XMLName xml.Name `xml:"rss" swaggerignore:"true"`
+	// Updated is when the newest change to a listed video was made
+	Updated time.Time `xml:"-" swaggerignore:"true"`
+	Version string    `xml:"version,attr"`
+	Media   string    `xml:"xmlns:media,attr"`
+	Atom    string    `xml:"xmlns:atom,attr"`
+	Channel Channel   `xml:"channel"`
+}
+
+// Channel describes a feed and lists its items, newest first
+type Channel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	// Self is the feed's own URL, which aggregators use to identify it
+	Self          AtomLink `xml:"atom:link"`
+	Generator     string   `xml:"generator"`
+	LastBuildDate string   `xml:"lastBuildDate,omitempty"`
+	Items         []Item   `xml:"item"`
+}
+
+// AtomLink is an atom:link element
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// Item is a video in a feed
+type Item struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	GUID        GUID     `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category,omitempty"`
+	// Credit names the channel the video is from
+	Credit *MediaCredit `xml:"media:credit,omitempty"`
+	// Enclosure is the original file, for podcast apps that download one file
+	Enclosure *Enclosure      `xml:"enclosure,omitempty"`
+	Group     *MediaGroup     `xml:"media:group,omitempty"`
+	Thumbnail *MediaThumbnail `xml:"media:thumbnail,omitempty"`
+	Keywords  string          `xml:"media:keywords,omitempty"`
+}
+
+// GUID identifies an item across fetches of a feed
+type GUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// Enclosure is a file attached to an item
+type Enclosure struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+	// Length is the file's size in bytes; 0 when unknown
+	Length int64 `xml:"length,attr"`
+}
+
+// MediaGroup lists the renditions of a video
+type MediaGroup struct {
+	Contents []MediaContent `xml:"media:content"`
+}
+
+// MediaContent is one rendition of a video
+type MediaContent struct {
+	URL      string `xml:"url,attr"`
+	Type     string `xml:"type,attr"`
+	Medium   string `xml:"medium,attr"`
+	FileSize int64  `xml:"fileSize,attr,omitempty"`
+	// Duration is in whole seconds
+	Duration  int64 `xml:"duration,attr,omitempty"`
+	Height    int   `xml:"height,attr,omitempty"`
+	IsDefault bool  `xml:"isDefault,attr,omitempty"`
+}
+
+// MediaCredit is a person or channel credited for a video
+type MediaCredit struct {
+	Value string `xml:",chardata"`
+	Role  string `xml:"role,attr"`
+}
+
+// MediaThumbnail is an image representing a video
+type MediaThumbnail struct {
+	URL string `xml:"url,attr"`
+}
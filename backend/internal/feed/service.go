@@ -0,0 +1,256 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// generator is the generator element of every feed
+const generator = "Pavilion"
+
+// videoTypes maps the extensions of stored files to their media types
+var videoTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".mov":  "video/quicktime",
+	".avi":  "video/x-msvideo",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+}
+
+// Config represents how feeds are built
+type Config struct {
+	// Title is the title of the latest videos feed, and the suffix of
+	// channel feed titles
+	Title string
+	// SiteURL is the home page feeds link to; feeds link to themselves when empty
+	SiteURL string
+	// WatchURL is the page a video is watched on, with {id} replaced by the
+	// video ID; items link no page when empty
+	WatchURL string
+	// Items is how many of the newest videos each feed lists
+	Items int
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db      *gorm.DB
+	config  Config
+	cdn     video.PlaybackCDN
+	sources video.SourceConfig
+	logger  logger.Logger
+}
+
+// NewService creates a new feed service. Files are linked on the CDN when
+// its URLs do not expire, and otherwise on the IPFS gateway once replicated;
+// cdn may be nil.
+func NewService(db *gorm.DB, config Config, cdn video.PlaybackCDN, sources video.SourceConfig, logger logger.Logger) Service {
+	if config.Items < 1 {
+		config.Items = 50
+	}
+	return &serviceImpl{
+		db:      db,
+		config:  config,
+		cdn:     cdn,
+		sources: sources,
+		logger:  logger,
+	}
+}
+
+// Channel returns the feed of a user's newest public videos
+func (s *serviceImpl) Channel(ctx context.Context, userID uuid.UUID) (*RSS, error) {
+	var user auth.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	videos, err := s.newest(ctx, s.publicVideos(ctx).Where("videos.user_id = ?", userID))
+	if err != nil {
+		return nil, err
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Username
+	}
+	description := user.Bio
+	if description == "" {
+		description = "Videos from " + name
+	}
+	users := map[uuid.UUID]auth.User{user.ID: user}
+	return s.feed(name+" - "+s.config.Title, description, videos, users), nil
+}
+
+// Latest returns the feed of the newest public videos of every channel
+func (s *serviceImpl) Latest(ctx context.Context) (*RSS, error) {
+	videos, err := s.newest(ctx, s.publicVideos(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(videos))
+	for _, v := range videos {
+		userIDs = append(userIDs, v.UserID)
+	}
+	users := map[uuid.UUID]auth.User{}
+	if len(userIDs) > 0 {
+		var found []auth.User
+		if err := s.db.WithContext(ctx).Where("id IN ?", userIDs).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to get channels: %w", err)
+		}
+		for _, u := range found {
+			users[u.ID] = u
+		}
+	}
+
+	return s.feed(s.config.Title, "The latest videos on "+s.config.Title, videos, users), nil
+}
+
+// publicVideos returns a query for the videos feeds may list
+func (s *serviceImpl) publicVideos(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Model(&video.Video{}).Scopes(video.PublicVideos)
+}
+
+// newest loads the newest videos of a query, with what items are built from
+func (s *serviceImpl) newest(ctx context.Context, query *gorm.DB) ([]video.Video, error) {
+	var videos []video.Video
+	if err := query.Preload("Transcodes.Segments").Preload("Tags").
+		Order("videos.created_at DESC").Limit(s.config.Items).
+		Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	return videos, nil
+}
+
+// feed builds a feed of videos, newest first. Its self link is left for the
+// handler, which knows the URL it is served at.
+func (s *serviceImpl) feed(title, description string, videos []video.Video, users map[uuid.UUID]auth.User) *RSS {
+	rss := &RSS{
+		Version: "2.0",
+		Media:   mediaNamespace,
+		Atom:    atomNamespace,
+		Channel: Channel{
+			Title:       title,
+			Link:        s.config.SiteURL,
+			Description: description,
+			Self:        AtomLink{Rel: "self", Type: ContentType},
+			Generator:   generator,
+			Items:       []Item{},
+		},
+	}
+	for i := range videos {
+		if videos[i].UpdatedAt.After(rss.Updated) {
+			rss.Updated = videos[i].UpdatedAt
+		}
+		rss.Channel.Items = append(rss.Channel.Items, s.item(&videos[i], users[videos[i].UserID]))
+	}
+	// The build date follows the listed videos, so an unchanged feed is
+	// byte for byte the same and keeps its ETag
+	if !rss.Updated.IsZero() {
+		rss.Channel.LastBuildDate = rss.Updated.UTC().Format(time.RFC1123Z)
+	}
+	return rss
+}
+
+// item builds the feed item of a video
+func (s *serviceImpl) item(v *video.Video, user auth.User) Item {
+	item := Item{
+		Title:       v.Title,
+		Description: v.Description,
+		GUID:        GUID{Value: v.ID.String()},
+		PubDate:     v.CreatedAt.UTC().Format(time.RFC1123Z),
+	}
+	if s.config.WatchURL != "" {
+		item.Link = strings.ReplaceAll(s.config.WatchURL, "{id}", v.ID.String())
+	}
+	if v.Category != "" {
+		item.Categories = append(item.Categories, string(v.Category))
+	}
+	if user.Username != "" {
+		item.Credit = &MediaCredit{Role: "author", Value: user.Username}
+	}
+	if len(v.Tags) > 0 {
+		names := make([]string, 0, len(v.Tags))
+		for _, tag := range v.Tags {
+			names = append(names, tag.Name)
+		}
+		item.Keywords = strings.Join(names, ", ")
+	}
+	if url := s.fileURL(v.PreviewPath, ""); url != "" {
+		item.Thumbnail = &MediaThumbnail{URL: url}
+	}
+
+	duration := int64(math.Round(v.Duration))
+	group := &MediaGroup{}
+	for _, rendition := range video.Renditions(v) {
+		url := s.fileURL(rendition.Key, rendition.CID)
+		if url == "" {
+			continue
+		}
+		content := MediaContent{
+			URL:       url,
+			Type:      mediaType(rendition.Key),
+			Medium:    "video",
+			FileSize:  rendition.Size,
+			Duration:  duration,
+			IsDefault: rendition.Name == "original",
+		}
+		if height, err := strconv.Atoi(strings.TrimSuffix(rendition.Name, "p")); err == nil {
+			content.Height = height
+		}
+		if content.IsDefault {
+			item.Enclosure = &Enclosure{URL: url, Type: content.Type, Length: rendition.Size}
+		}
+		group.Contents = append(group.Contents, content)
+	}
+	if len(group.Contents) > 0 {
+		item.Group = group
+	}
+	return item
+}
+
+// fileURL links a stored file where anyone can fetch it for as long as a
+// feed may be kept: on the CDN when its URLs are not signed, or else on the
+// IPFS gateway when the file has a CID. It returns an empty string when
+// neither can link the file.
+func (s *serviceImpl) fileURL(key, cid string) string {
+	if key == "" {
+		return ""
+	}
+	if s.cdn != nil && !s.cdn.Signed() {
+		url, err := s.cdn.URL(key)
+		if err == nil {
+			return url
+		}
+		s.logger.LogWarn("Failed to link file on the CDN", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+	}
+	if cid != "" {
+		return s.sources.GatewayURL(cid)
+	}
+	return ""
+}
+
+// mediaType returns the media type of a stored video file
+func mediaType(key string) string {
+	if contentType, ok := videoTypes[strings.ToLower(path.Ext(key))]; ok {
+		return contentType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,179 @@
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCDN links files on a fixed domain
+type fakeCDN struct {
+	signed bool
+}
+
+func (f fakeCDN) URL(key string) (string, error) {
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (f fakeCDN) Signed() bool {
+	return f.signed
+}
+
+// testVideo returns a replicated video with a 720p rendition
+func testVideo() *video.Video {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &video.Video{
+		ID:          uuid.MustParse("5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"),
+		Title:       "Intro to Go",
+		Description: "Basics",
+		Category:    "education",
+		StoragePath: "videos/5f0c/original.mov",
+		PreviewPath: "videos/5f0c/preview.webp",
+		FileSize:    1048576,
+		IPFSCID:     "bafyoriginal",
+		Duration:    125.6,
+		Tags:        []video.Tag{{Name: "golang"}, {Name: "tutorial"}},
+		Transcodes: []video.Transcode{{
+			Format:   "mp4",
+			Segments: []video.TranscodeSegment{{StoragePath: "videos/5f0c/720p.mp4"}},
+		}},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}
+}
+
+func TestItemOnCDN(t *testing.T) {
+	service := NewService(nil, Config{WatchURL: "https://pavilion.example.com/watch/{id}"}, fakeCDN{}, video.SourceConfig{}, nil).(*serviceImpl)
+	item := service.item(testVideo(), auth.User{Username: "johndoe"})
+
+	assert.Equal(t, "Intro to Go", item.Title)
+	assert.Equal(t, "https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b", item.Link)
+	assert.Equal(t, GUID{Value: "5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"}, item.GUID)
+	assert.Equal(t, "Fri, 01 May 2026 12:00:00 +0000", item.PubDate)
+	assert.Equal(t, []string{"education"}, item.Categories)
+	assert.Equal(t, &MediaCredit{Role: "author", Value: "johndoe"}, item.Credit)
+	assert.Equal(t, "golang, tutorial", item.Keywords)
+	assert.Equal(t, &MediaThumbnail{URL: "https://cdn.example.com/videos/5f0c/preview.webp"}, item.Thumbnail)
+	assert.Equal(t, &Enclosure{URL: "https://cdn.example.com/videos/5f0c/original.mov", Type: "video/quicktime", Length: 1048576}, item.Enclosure)
+	require.NotNil(t, item.Group)
+	assert.Equal(t, []MediaContent{
+		{URL: "https://cdn.example.com/videos/5f0c/original.mov", Type: "video/quicktime", Medium: "video", FileSize: 1048576, Duration: 126, IsDefault: true},
+		{URL: "https://cdn.example.com/videos/5f0c/720p.mp4", Type: "video/mp4", Medium: "video", Duration: 126, Height: 720},
+	}, item.Group.Contents)
+}
+
+func TestItemWithoutUnsignedCDN(t *testing.T) {
+	// Signed CDN URLs expire while feeds are kept, so the IPFS gateway is
+	// used for replicated files and the rest are left out
+	service := NewService(nil, Config{}, fakeCDN{signed: true}, video.SourceConfig{IPFSGateway: "https://ipfs.io"}, nil).(*serviceImpl)
+	item := service.item(testVideo(), auth.User{})
+
+	assert.Empty(t, item.Link)
+	assert.Nil(t, item.Credit)
+	assert.Nil(t, item.Thumbnail)
+	assert.Equal(t, &Enclosure{URL: "https://ipfs.io/ipfs/bafyoriginal", Type: "video/quicktime", Length: 1048576}, item.Enclosure)
+	require.NotNil(t, item.Group)
+	assert.Len(t, item.Group.Contents, 1)
+
+	service = NewService(nil, Config{}, nil, video.SourceConfig{}, nil).(*serviceImpl)
+	item = service.item(testVideo(), auth.User{})
+	assert.Nil(t, item.Enclosure)
+	assert.Nil(t, item.Group)
+}
+
+func TestFeedDocument(t *testing.T) {
+	service := NewService(nil, Config{Title: "Pavilion"}, fakeCDN{}, video.SourceConfig{}, nil).(*serviceImpl)
+	older := testVideo()
+	newer := testVideo()
+	newer.ID = uuid.New()
+	newer.UpdatedAt = older.UpdatedAt.Add(time.Hour)
+
+	rss := service.feed("Pavilion", "The latest videos on Pavilion", []video.Video{*newer, *older}, nil)
+	assert.Equal(t, newer.UpdatedAt, rss.Updated)
+	assert.Equal(t, "Fri, 01 May 2026 13:00:00 +0000", rss.Channel.LastBuildDate)
+	assert.Len(t, rss.Channel.Items, 2)
+
+	body, err := xml.Marshal(rss)
+	require.NoError(t, err)
+	document := string(body)
+	assert.Contains(t, document, `<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/" xmlns:atom="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, document, `<atom:link href="" rel="self" type="application/rss+xml"></atom:link>`)
+	assert.Contains(t, document, `<guid isPermaLink="false">5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b</guid>`)
+	assert.Contains(t, document, `<media:content url="https://cdn.example.com/videos/5f0c/720p.mp4" type="video/mp4" medium="video" duration="126" height="720"></media:content>`)
+}
+
+func TestMediaType(t *testing.T) {
+	assert.Equal(t, "video/mp4", mediaType("videos/a/720p.mp4"))
+	assert.Equal(t, "video/quicktime", mediaType("videos/a/original.MOV"))
+	assert.Equal(t, "application/octet-stream", mediaType("videos/a/original"))
+}
+
+// fakeService returns a fixed feed
+type fakeService struct {
+	rss *RSS
+	err error
+}
+
+func (f *fakeService) Channel(ctx context.Context, userID uuid.UUID) (*RSS, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	copied := *f.rss
+	return &copied, nil
+}
+
+func (f *fakeService) Latest(ctx context.Context) (*RSS, error) {
+	return f.Channel(ctx, uuid.Nil)
+}
+
+func TestHandlerCaching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+
+	updated := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	service := &fakeService{rss: &RSS{Version: "2.0", Updated: updated, Channel: Channel{Title: "Pavilion", Items: []Item{}}}}
+	router := gin.New()
+	NewHandler(service, 5*time.Minute, httpHandler.NewResponseHandler(log), log).RegisterRoutes(router)
+
+	request := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := request("/feeds/latest", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.True(t, strings.HasPrefix(first.Header().Get("Content-Type"), ContentType))
+	assert.Equal(t, "public, max-age=300", first.Header().Get("Cache-Control"))
+	assert.Equal(t, "Fri, 01 May 2026 12:00:00 GMT", first.Header().Get("Last-Modified"))
+	assert.Contains(t, first.Body.String(), `<atom:link href="http://example.com/feeds/latest"`)
+	assert.Contains(t, first.Body.String(), `<link>http://example.com/feeds/latest</link>`)
+
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, http.StatusNotModified, request("/feeds/latest", map[string]string{"If-None-Match": etag}).Code)
+	assert.Equal(t, http.StatusNotModified, request("/feeds/latest", map[string]string{"If-Modified-Since": "Fri, 01 May 2026 12:00:00 GMT"}).Code)
+	assert.Equal(t, http.StatusOK, request("/feeds/latest", map[string]string{"If-Modified-Since": "Fri, 01 May 2026 11:00:00 GMT"}).Code)
+	assert.Equal(t, http.StatusOK, request("/feeds/latest", map[string]string{"If-None-Match": `W/"other"`, "If-Modified-Since": "Fri, 01 May 2026 12:00:00 GMT"}).Code)
+
+	assert.Equal(t, http.StatusBadRequest, request("/users/not-a-uuid/feed.rss", nil).Code)
+	service.err = ErrUserNotFound
+	assert.Equal(t, http.StatusNotFound, request("/users/"+uuid.NewString()+"/feed.rss", nil).Code)
+}
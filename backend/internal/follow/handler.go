@@ -0,0 +1,200 @@
+package follow
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for follow endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new follow handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers all follow routes
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	users := router.Group("/users")
+	{
+		// Public routes
+		users.GET("/:id/followers", h.handleListFollowers)
+		users.GET("/:id/following", h.handleListFollowing)
+
+		// Protected routes
+		protected := users.Group("")
+		protected.Use(authMiddleware)
+		{
+			protected.POST("/:id/follow", h.handleFollow)
+			protected.DELETE("/:id/follow", h.handleUnfollow)
+		}
+	}
+}
+
+// @Summary Follow a user
+// @Description Follow a user to be notified when they upload videos. Following an already followed user has no effect.
+// @Tags follows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=FollowStatus} "User followed"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID or self-follow"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/follow [post]
+func (h *Handler) handleFollow(c *gin.Context) {
+	followerID, followeeID, ok := h.parseFollowRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Follow(c.Request.Context(), followerID, followeeID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to follow user")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, status, "User followed successfully")
+}
+
+// @Summary Unfollow a user
+// @Description Stop following a user. Unfollowing a user that is not followed has no effect.
+// @Tags follows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=FollowStatus} "User unfollowed"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/follow [delete]
+func (h *Handler) handleUnfollow(c *gin.Context) {
+	followerID, followeeID, ok := h.parseFollowRequest(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.service.Unfollow(c.Request.Context(), followerID, followeeID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to unfollow user")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, status, "User unfollowed successfully")
+}
+
+// @Summary List followers
+// @Description Get a paginated list of users following the given user
+// @Tags follows
+// @Produce json
+// @Param id path string true "User ID (UUID)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=UserListResponse} "Followers retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/followers [get]
+func (h *Handler) handleListFollowers(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return
+	}
+
+	page, limit := parsePagination(c)
+	list, err := h.service.ListFollowers(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve followers")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Followers retrieved successfully")
+}
+
+// @Summary List followed users
+// @Description Get a paginated list of users the given user follows
+// @Tags follows
+// @Produce json
+// @Param id path string true "User ID (UUID)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=UserListResponse} "Followed users retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/following [get]
+func (h *Handler) handleListFollowing(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return
+	}
+
+	page, limit := parsePagination(c)
+	list, err := h.service.ListFollowing(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve followed users")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Followed users retrieved successfully")
+}
+
+// parseFollowRequest extracts the authenticated follower and the target user from the request
+func (h *Handler) parseFollowRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	followerID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	followeeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return followerID, followeeID, true
+}
+
+// handleServiceError maps follow service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrCannotFollowSelf):
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "You cannot follow yourself", err)
+	case errors.Is(err, ErrUserNotFound):
+		h.responseHandler.NotFoundResponse(c, "User not found")
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
+
+// parsePagination reads page and limit query parameters, leaving bounds checks to the service
+func parsePagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	return page, limit
+}
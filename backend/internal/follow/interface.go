@@ -0,0 +1,40 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCannotFollowSelf is returned when a user tries to follow themselves
+	ErrCannotFollowSelf = errors.New("users cannot follow themselves")
+	// ErrUserNotFound is returned when the user to follow does not exist
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// Service defines the interface for follow operations
+type Service interface {
+	// Follow makes followerID follow followeeID; following twice is a no-op
+	Follow(ctx context.Context, followerID, followeeID uuid.UUID) (*FollowStatus, error)
+	// Unfollow removes the relationship; unfollowing twice is a no-op
+	Unfollow(ctx context.Context, followerID, followeeID uuid.UUID) (*FollowStatus, error)
+	// ListFollowers returns the users following userID
+	ListFollowers(ctx context.Context, userID uuid.UUID, page, limit int) (*UserListResponse, error)
+	// ListFollowing returns the users that userID follows
+	ListFollowing(ctx context.Context, userID uuid.UUID, page, limit int) (*UserListResponse, error)
+	// CountFollowers returns how many users follow userID
+	CountFollowers(ctx context.Context, userID uuid.UUID) (int64, error)
+	// GetFollowerIDs returns the IDs of every follower of userID, for notification fan-out
+	GetFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	// FollowingChanges returns up to limit follows and unfollows by userID after since, oldest first
+	FollowingChanges(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]FollowChange, error)
+}
+
+// EventPublisher publishes follow events to the notification system
+type EventPublisher interface {
+	PublishUserEvent(ctx context.Context, event *notification.UserEvent) error
+}
@@ -0,0 +1,63 @@
+package follow
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Follow records that one user follows another. Unfollowing soft deletes the
+// row so incremental sync can report it; following again restores it.
+type Follow struct {
+	FollowerID uuid.UUID      `gorm:"type:uuid;primaryKey" json:"followerId"`
+	FolloweeID uuid.UUID      `gorm:"type:uuid;primaryKey;index" json:"followeeId"`
+	CreatedAt  time.Time      `gorm:"not null" json:"createdAt"`
+	UpdatedAt  time.Time      `gorm:"not null" json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TableName specifies the table name for follows
+func (Follow) TableName() string {
+	return "followers"
+}
+
+// UserSummary is the public view of a user in follower listings
+// @Description Public user information shown in follower and following lists
+type UserSummary struct {
+	// User ID
+	ID uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Username
+	Username string `json:"username" example:"johndoe"`
+	// Display name
+	Name string `json:"name" example:"John Doe"`
+	// When the follow relationship started
+	FollowedAt time.Time `json:"followedAt" example:"2025-03-05T21:26:06Z"`
+}
+
+// UserListResponse is a paginated list of users
+// @Description Paginated list of followers or followed users
+type UserListResponse struct {
+	Users []UserSummary `json:"users"`
+	Total int64         `json:"total" example:"42"`
+	Page  int           `json:"page" example:"1"`
+	Limit int           `json:"limit" example:"20"`
+}
+
+// FollowChange is a follow or unfollow of another user, for incremental sync
+type FollowChange struct {
+	// User is the followed user; FollowedAt is when the follow started
+	User UserSummary
+	// ChangedAt is when the user was followed, or unfollowed if Unfollowed
+	ChangedAt  time.Time
+	Unfollowed bool
+}
+
+// FollowStatus is returned after following or unfollowing
+// @Description Follow state between the caller and a user
+type FollowStatus struct {
+	// Whether the caller follows the user
+	Following bool `json:"following" example:"true"`
+	// Number of followers the user has
+	FollowerCount int64 `json:"followerCount" example:"42"`
+}
@@ -0,0 +1,229 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db        *gorm.DB
+	publisher EventPublisher
+	logger    logger.Logger
+}
+
+// NewService creates a new follow service. publisher may be nil when the
+// notification system is unavailable.
+func NewService(db *gorm.DB, publisher EventPublisher, logger logger.Logger) Service {
+	return &serviceImpl{
+		db:        db,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Follow makes followerID follow followeeID
+func (s *serviceImpl) Follow(ctx context.Context, followerID, followeeID uuid.UUID) (*FollowStatus, error) {
+	if followerID == followeeID {
+		return nil, ErrCannotFollowSelf
+	}
+	if err := s.ensureUserExists(ctx, followeeID); err != nil {
+		return nil, err
+	}
+
+	var existing Follow
+	err := s.db.WithContext(ctx).Unscoped().
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		First(&existing).Error
+
+	changed := false
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		follow := &Follow{FollowerID: followerID, FolloweeID: followeeID}
+		if err := s.db.WithContext(ctx).Create(follow).Error; err != nil {
+			return nil, fmt.Errorf("failed to create follow: %w", err)
+		}
+		changed = true
+	case err != nil:
+		return nil, fmt.Errorf("failed to load follow: %w", err)
+	case existing.DeletedAt.Valid:
+		// Restore a previous follow rather than inserting a duplicate key
+		if err := s.db.WithContext(ctx).Unscoped().Model(&existing).
+			Updates(map[string]interface{}{"deleted_at": nil, "created_at": time.Now()}).Error; err != nil {
+			return nil, fmt.Errorf("failed to restore follow: %w", err)
+		}
+		changed = true
+	}
+
+	if changed {
+		s.publish(ctx, notification.UserFollowed, followerID, followeeID)
+	}
+
+	return s.status(ctx, followeeID, true)
+}
+
+// Unfollow removes the follow relationship if it exists
+func (s *serviceImpl) Unfollow(ctx context.Context, followerID, followeeID uuid.UUID) (*FollowStatus, error) {
+	if followerID == followeeID {
+		return nil, ErrCannotFollowSelf
+	}
+
+	result := s.db.WithContext(ctx).
+		Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Delete(&Follow{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to delete follow: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		s.publish(ctx, notification.UserUnfollowed, followerID, followeeID)
+	}
+
+	return s.status(ctx, followeeID, false)
+}
+
+// ListFollowers returns the users following userID, most recent first
+func (s *serviceImpl) ListFollowers(ctx context.Context, userID uuid.UUID, page, limit int) (*UserListResponse, error) {
+	return s.list(ctx, "followers.followee_id = ?", "users.id = followers.follower_id", userID, page, limit)
+}
+
+// ListFollowing returns the users that userID follows, most recent first
+func (s *serviceImpl) ListFollowing(ctx context.Context, userID uuid.UUID, page, limit int) (*UserListResponse, error) {
+	return s.list(ctx, "followers.follower_id = ?", "users.id = followers.followee_id", userID, page, limit)
+}
+
+// CountFollowers returns how many users follow userID
+func (s *serviceImpl) CountFollowers(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&Follow{}).Where("followee_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count followers: %w", err)
+	}
+	return count, nil
+}
+
+// GetFollowerIDs returns the IDs of every follower of userID
+func (s *serviceImpl) GetFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&Follow{}).Where("followee_id = ?", userID).Pluck("follower_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list follower IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// FollowingChanges returns follows and unfollows by userID after since.
+// Unfollows only set deleted_at, so it takes precedence as the change time.
+func (s *serviceImpl) FollowingChanges(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]FollowChange, error) {
+	var rows []struct {
+		UserSummary
+		UpdatedAt time.Time
+		DeletedAt *time.Time
+	}
+	err := s.db.WithContext(ctx).Unscoped().Model(&Follow{}).
+		Select("users.id, users.username, users.name, followers.created_at AS followed_at, followers.updated_at, followers.deleted_at").
+		Joins("JOIN users ON users.id = followers.followee_id").
+		Where("followers.follower_id = ?", userID).
+		Where("COALESCE(followers.deleted_at, followers.updated_at) > ?", since).
+		Order("COALESCE(followers.deleted_at, followers.updated_at) ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query follow changes: %w", err)
+	}
+
+	changes := make([]FollowChange, 0, len(rows))
+	for _, row := range rows {
+		change := FollowChange{User: row.UserSummary, ChangedAt: row.UpdatedAt}
+		if row.DeletedAt != nil {
+			change.ChangedAt, change.Unfollowed = *row.DeletedAt, true
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// list pages through follow rows joined with the user on the other side
+func (s *serviceImpl) list(ctx context.Context, where, join string, userID uuid.UUID, page, limit int) (*UserListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&Follow{}).Where(where, userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count follows: %w", err)
+	}
+
+	users := make([]UserSummary, 0, limit)
+	err := query.
+		Select("users.id, users.username, users.name, followers.created_at AS followed_at").
+		Joins("JOIN users ON " + join).
+		Order("followers.created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list follows: %w", err)
+	}
+
+	return &UserListResponse{
+		Users: users,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// ensureUserExists returns ErrUserNotFound if there is no active user with the given ID
+func (s *serviceImpl) ensureUserExists(ctx context.Context, userID uuid.UUID) error {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&auth.User{}).Where("id = ? AND active = ?", userID, true).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to look up user: %w", err)
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// status builds the follow status returned after a follow or unfollow
+func (s *serviceImpl) status(ctx context.Context, followeeID uuid.UUID, following bool) (*FollowStatus, error) {
+	count, err := s.CountFollowers(ctx, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	return &FollowStatus{Following: following, FollowerCount: count}, nil
+}
+
+// publish sends a follow event; failures are logged since the follow itself succeeded
+func (s *serviceImpl) publish(ctx context.Context, eventType notification.EventType, followerID, followeeID uuid.UUID) {
+	if s.publisher == nil {
+		return
+	}
+
+	event := &notification.UserEvent{
+		BaseEvent:    notification.BaseEvent{Type: eventType},
+		UserID:       followerID,
+		TargetUserID: followeeID,
+	}
+	if err := s.publisher.PublishUserEvent(ctx, event); err != nil {
+		s.logger.LogError(err, "Failed to publish follow event")
+	}
+}
@@ -0,0 +1,174 @@
+package follow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/follow"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// recordingPublisher keeps the follow events published
+type recordingPublisher struct {
+	events []*notification.UserEvent
+}
+
+func (p *recordingPublisher) PublishUserEvent(ctx context.Context, event *notification.UserEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func createUser(t *testing.T, db *gorm.DB, name string) uuid.UUID {
+	id := uuid.New()
+	user := &auth.User{ID: id, Username: name + "-" + id.String()[:8], Email: id.String() + "@example.com", Name: name}
+	require.NoError(t, db.Create(user).Error)
+	return id
+}
+
+func TestCannotFollowSelf(t *testing.T) {
+	service := follow.NewService(nil, nil, nil)
+	userID := uuid.New()
+
+	_, err := service.Follow(context.Background(), userID, userID)
+	assert.ErrorIs(t, err, follow.ErrCannotFollowSelf)
+
+	_, err = service.Unfollow(context.Background(), userID, userID)
+	assert.ErrorIs(t, err, follow.ErrCannotFollowSelf)
+}
+
+func TestFollowIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	db := testhelper.SetupTestDB(t)
+	publisher := &recordingPublisher{}
+	service := follow.NewService(db, publisher, testhelper.NewTestLogger(true))
+	follower, creator := createUser(t, db, "follower"), createUser(t, db, "creator")
+
+	for i := 0; i < 2; i++ {
+		status, err := service.Follow(ctx, follower, creator)
+		require.NoError(t, err)
+		assert.Equal(t, &follow.FollowStatus{Following: true, FollowerCount: 1}, status)
+	}
+	require.Len(t, publisher.events, 1, "following again publishes nothing")
+	assert.Equal(t, notification.UserFollowed, publisher.events[0].Type)
+
+	for i := 0; i < 2; i++ {
+		status, err := service.Unfollow(ctx, follower, creator)
+		require.NoError(t, err)
+		assert.Equal(t, &follow.FollowStatus{Following: false, FollowerCount: 0}, status)
+	}
+	require.Len(t, publisher.events, 2, "unfollowing again publishes nothing")
+	assert.Equal(t, notification.UserUnfollowed, publisher.events[1].Type)
+
+	// Following again restores the row
+	status, err := service.Follow(ctx, follower, creator)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), status.FollowerCount)
+	assert.Len(t, publisher.events, 3)
+
+	_, err = service.Follow(ctx, follower, uuid.New())
+	assert.ErrorIs(t, err, follow.ErrUserNotFound)
+}
+
+func TestListPagination(t *testing.T) {
+	ctx := context.Background()
+	db := testhelper.SetupTestDB(t)
+	service := follow.NewService(db, nil, testhelper.NewTestLogger(true))
+	creator := createUser(t, db, "creator")
+
+	var fans []uuid.UUID
+	for i := 0; i < 5; i++ {
+		fan := createUser(t, db, "fan")
+		_, err := service.Follow(ctx, fan, creator)
+		require.NoError(t, err)
+		// Keep follow times distinct, so the order is well defined
+		require.NoError(t, db.Model(&follow.Follow{}).Where("follower_id = ? AND followee_id = ?", fan, creator).
+			Update("created_at", time.Now().Add(time.Duration(i)*time.Minute)).Error)
+		fans = append(fans, fan)
+	}
+	_, err := service.Unfollow(ctx, fans[0], creator)
+	require.NoError(t, err)
+
+	// Most recent first, without the unfollowed fan
+	first, err := service.ListFollowers(ctx, creator, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), first.Total)
+	require.Len(t, first.Users, 3)
+	assert.Equal(t, []uuid.UUID{fans[4], fans[3], fans[2]}, []uuid.UUID{first.Users[0].ID, first.Users[1].ID, first.Users[2].ID})
+
+	second, err := service.ListFollowers(ctx, creator, 2, 3)
+	require.NoError(t, err)
+	require.Len(t, second.Users, 1)
+	assert.Equal(t, fans[1], second.Users[0].ID)
+
+	following, err := service.ListFollowing(ctx, fans[2], 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), following.Total)
+	require.Len(t, following.Users, 1)
+	assert.Equal(t, creator, following.Users[0].ID)
+
+	following, err = service.ListFollowing(ctx, fans[0], 1, 20)
+	require.NoError(t, err)
+	assert.Zero(t, following.Total)
+	assert.Empty(t, following.Users)
+
+	// Out of range values fall back to the defaults
+	defaults, err := service.ListFollowing(ctx, fans[2], 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, defaults.Page)
+	assert.Equal(t, 20, defaults.Limit)
+
+	_, err = service.ListFollowers(ctx, uuid.New(), 1, 20)
+	assert.ErrorIs(t, err, follow.ErrUserNotFound)
+}
+
+func TestFollowingChanges(t *testing.T) {
+	ctx := context.Background()
+	db := testhelper.SetupTestDB(t)
+	service := follow.NewService(db, nil, testhelper.NewTestLogger(true))
+	follower, kept, dropped := createUser(t, db, "follower"), createUser(t, db, "kept"), createUser(t, db, "dropped")
+
+	since := time.Now().Add(-time.Second)
+	_, err := service.Follow(ctx, follower, kept)
+	require.NoError(t, err)
+	_, err = service.Follow(ctx, follower, dropped)
+	require.NoError(t, err)
+	_, err = service.Unfollow(ctx, follower, dropped)
+	require.NoError(t, err)
+
+	changes, err := service.FollowingChanges(ctx, follower, since, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, kept, changes[0].User.ID)
+	assert.False(t, changes[0].Unfollowed)
+	assert.Equal(t, dropped, changes[1].User.ID)
+	assert.True(t, changes[1].Unfollowed, "unfollows are reported")
+
+	changes, err = service.FollowingChanges(ctx, follower, changes[1].ChangedAt, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestCannotFollowSuspendedUser(t *testing.T) {
+	ctx := context.Background()
+	db := testhelper.SetupTestDB(t)
+	service := follow.NewService(db, nil, testhelper.NewTestLogger(true))
+	follower, suspended := createUser(t, db, "follower"), createUser(t, db, "suspended")
+	require.NoError(t, db.Model(&auth.User{}).Where("id = ?", suspended).Update("active", false).Error)
+
+	_, err := service.Follow(ctx, follower, suspended)
+	assert.ErrorIs(t, err, follow.ErrUserNotFound)
+
+	_, err = service.ListFollowers(ctx, suspended, 1, 20)
+	assert.ErrorIs(t, err, follow.ErrUserNotFound)
+
+	count, err := service.CountFollowers(ctx, suspended)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
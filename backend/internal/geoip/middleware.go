@@ -0,0 +1,39 @@
+package geoip
+
+import (
+	"net"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// countryKey is the context key of the requesting client's country
+const countryKey = "country"
+
+// Middleware records the country of the client's IP address, as resolved
+// through the router's trusted proxies, for Country to return
+func Middleware(lookup Lookup, logger logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var country string
+		if ip := net.ParseIP(c.ClientIP()); ip != nil {
+			var err error
+			if country, err = lookup.Country(ip); err != nil {
+				logger.LogWarn("GeoIP lookup failed", map[string]interface{}{
+					"request_id": c.GetString("request_id"),
+					"error":      err.Error(),
+				})
+			}
+		}
+		c.Set(countryKey, country)
+		c.Next()
+	}
+}
+
+// Country returns the ISO code of the requesting client's country, which is
+// empty when it is not known. located is false when no lookup was made
+// because GeoIP is not configured.
+func Country(c *gin.Context) (country string, located bool) {
+	value, located := c.Get(countryKey)
+	country, _ = value.(string)
+	return country, located
+}
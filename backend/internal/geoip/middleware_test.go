@@ -0,0 +1,63 @@
+package geoip
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLookup places 192.0.2.0/24 in Germany and fails for other addresses
+type fakeLookup struct{}
+
+func (fakeLookup) Country(ip net.IP) (string, error) {
+	if ip.Mask(net.CIDRMask(24, 32)).Equal(net.IPv4(192, 0, 2, 0)) {
+		return "DE", nil
+	}
+	return "", errors.New("address not in database")
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+
+	country := func(remoteAddr string, middleware ...gin.HandlerFunc) (string, bool) {
+		var got string
+		var located bool
+		router := gin.New()
+		router.Use(middleware...)
+		router.GET("/", func(c *gin.Context) {
+			got, located = Country(c)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		return got, located
+	}
+
+	got, located := country("192.0.2.10:4711", Middleware(fakeLookup{}, log))
+	assert.Equal(t, "DE", got)
+	assert.True(t, located)
+
+	// Failed lookups leave the country unknown
+	got, located = country("198.51.100.7:4711", Middleware(fakeLookup{}, log))
+	assert.Empty(t, got)
+	assert.True(t, located)
+
+	// Without the middleware, no lookup was made
+	got, located = country("192.0.2.10:4711")
+	assert.Empty(t, got)
+	assert.False(t, located)
+}
+
+func TestOpenMissingDatabase(t *testing.T) {
+	_, err := Open("testdata/missing.mmdb")
+	assert.Error(t, err)
+}
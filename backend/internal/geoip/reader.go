@@ -0,0 +1,62 @@
+// Package geoip looks up the country requests come from, for region
+// restrictions on videos
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Lookup finds the country of an IP address
+type Lookup interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country ip is in,
+	// or an empty string when it is not known
+	Country(ip net.IP) (string, error)
+}
+
+// record holds the fields read from GeoIP2 and GeoLite2 Country and City
+// databases
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	// RegisteredCountry is where the network is registered, used for
+	// addresses with no country, such as anycast networks
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Reader looks up countries in a MaxMind database
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// Open opens a MaxMind Country or City database, such as GeoLite2-Country.mmdb
+func Open(path string) (*Reader, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	return &Reader{db: db}, nil
+}
+
+// Country returns the ISO code of the country ip is in
+func (r *Reader) Country(ip net.IP) (string, error) {
+	var result record
+	if err := r.db.Lookup(ip, &result); err != nil {
+		return "", fmt.Errorf("failed to look up %s: %w", ip, err)
+	}
+	if result.Country.ISOCode != "" {
+		return strings.ToUpper(result.Country.ISOCode), nil
+	}
+	return strings.ToUpper(result.RegisteredCountry.ISOCode), nil
+}
+
+// Close releases the database
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// complexity estimates the work a query asks for, for gqlgen's complexity
+// limit. Every field costs 1 plus the cost of its selections, and the
+// selections of a field taking a limit argument are counted once per item
+// it may return.
+func complexity() ComplexityRoot {
+	var c ComplexityRoot
+	c.Query.Videos = func(childComplexity int, page, limit *int, tag, category *string, sort *VideoSort) int {
+		return 1 + limitOf(limit)*childComplexity
+	}
+	c.Video.Comments = func(childComplexity int, page, limit *int) int {
+		return 1 + limitOf(limit)*childComplexity
+	}
+	return c
+}
+
+// limitOf returns a limit argument, with its default filled in, or 1
+func limitOf(limit *int) int {
+	if limit == nil || *limit < 1 {
+		return 1
+	}
+	return *limit
+}
+
+// depthLimit rejects operations whose fields nest deeper than max.
+// Introspection is not counted, so tools can fetch the schema.
+type depthLimit struct {
+	max int
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+} = depthLimit{}
+
+func (d depthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+func (d depthLimit) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (d depthLimit) MutateOperationContext(ctx context.Context, opCtx *graphql.OperationContext) *gqlerror.Error {
+	if depth := selectionDepth(opCtx.Operation.SelectionSet); depth > d.max {
+		err := gqlerror.Errorf("query depth %d exceeds the limit of %d", depth, d.max)
+		errcode.Set(err, errcode.ValidationFailed)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth returns how deep fields nest in selections, counting
+// fragments as the fields they spread
+func selectionDepth(selections ast.SelectionSet) int {
+	depth := 0
+	for _, selection := range selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Name == "__schema" || s.Name == "__type" {
+				continue
+			}
+			depth = max(depth, 1+selectionDepth(s.SelectionSet))
+		case *ast.FragmentSpread:
+			depth = max(depth, selectionDepth(s.Definition.SelectionSet))
+		case *ast.InlineFragment:
+			depth = max(depth, selectionDepth(s.SelectionSet))
+		}
+	}
+	return depth
+}
@@ -0,0 +1,147 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/executor"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Request is a GraphQL query sent to POST /graphql
+type Request struct {
+	// The GraphQL document
+	Query string `json:"query" binding:"required" example:"{ videos(limit: 5) { total videos { title commentCount owner { username } } } }"`
+	// Operation to run when the document holds several
+	OperationName string `json:"operationName,omitempty" example:""`
+	// Values of the document's variables
+	Variables map[string]interface{} `json:"variables,omitempty" swaggertype:"object"`
+}
+
+// Response is the body of POST /graphql, shaped like graphql.Response
+type Response struct {
+	// Result of the query; null when it could not run
+	Data json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+	// Problems with the request or its fields
+	Errors gqlerror.List `json:"errors,omitempty" swaggertype:"array,object"`
+}
+
+// Handler serves the GraphQL endpoint
+type Handler struct {
+	executor *executor.Executor
+	services Services
+	options  Options
+	logger   logger.Logger
+}
+
+// NewHandler binds the generated schema to the services
+func NewHandler(services Services, options Options, logger logger.Logger) *Handler {
+	exec := executor.New(NewExecutableSchema(Config{
+		Resolvers:  &resolver{services: services, logger: logger},
+		Complexity: complexity(),
+	}))
+	exec.Use(extension.Introspection{})
+	if options.MaxDepth > 0 {
+		exec.Use(depthLimit{max: options.MaxDepth})
+	}
+	if options.MaxComplexity > 0 {
+		exec.Use(extension.FixedComplexityLimit(options.MaxComplexity))
+	}
+
+	return &Handler{
+		executor: exec,
+		services: services,
+		options:  options,
+		logger:   logger,
+	}
+}
+
+// RegisterRoutes registers the GraphQL endpoint
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, readMiddleware gin.HandlerFunc) {
+	router.POST("/graphql", authMiddleware, readMiddleware, h.handleQuery)
+}
+
+// @Summary Run a GraphQL query
+// @Description Run a read-only GraphQL query over videos, comments, profiles and the viewer's notifications, so a page can fetch what it shows in one request. Comment counts and user lookups of one query are batched.
+// @Description Queries deeper than graphql.maxDepth or costlier than graphql.maxComplexity are rejected. Every field costs 1, and the fields under a list taking a limit argument count once per item.
+// @Description Responses follow the GraphQL over HTTP format: data and errors, not the API's usual envelope. The schema can be fetched by introspection.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param request body Request true "GraphQL query"
+// @Success 200 {object} Response "Query result; field errors are listed in errors"
+// @Failure 400 {object} Response "Malformed request, invalid query or query over the limits"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "API key lacks the read scope"
+// @Router /graphql [post]
+func (h *Handler) handleQuery(c *gin.Context) {
+	var req Request
+	if err := bindRequest(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, &Response{Errors: bindErrors(err)})
+		return
+	}
+
+	viewerID, _ := uuid.Parse(c.GetString("userID"))
+	ctx := withRequestState(c.Request.Context(), &requestState{
+		viewerID:   viewerID,
+		viewerRole: c.GetString("role"),
+		loaders:    newLoaders(h.services, h.options.BatchWait),
+	})
+	ctx = graphql.StartOperationTrace(ctx)
+
+	// Invalid queries and queries over the limits are rejected before any
+	// resolver runs
+	opCtx, errs := h.executor.CreateOperationContext(ctx, &graphql.RawParams{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+	})
+	if len(errs) > 0 {
+		c.JSON(http.StatusBadRequest, &Response{Errors: errs})
+		return
+	}
+
+	responses, ctx := h.executor.DispatchOperation(ctx, opCtx)
+	c.JSON(http.StatusOK, responses(ctx))
+}
+
+// bindRequest reads the body like httpHandler.Bind, but keeps numbers as
+// json.Number, since Int variables do not take floats
+func bindRequest(c *gin.Context, req *Request) error {
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(req); err != nil {
+		return httpHandler.BindError(err)
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return httpHandler.BindError(err)
+	}
+	return nil
+}
+
+// bindErrors lists the problems of a malformed request as GraphQL errors
+func bindErrors(err error) gqlerror.List {
+	var apiErr *apierror.Error
+	if !errors.As(err, &apiErr) {
+		return gqlerror.List{{Message: "Invalid request format"}}
+	}
+	if len(apiErr.Fields) == 0 {
+		return gqlerror.List{{Message: apiErr.Message}}
+	}
+	errs := make(gqlerror.List, len(apiErr.Fields))
+	for i, field := range apiErr.Fields {
+		errs[i] = &gqlerror.Error{Message: field.Message}
+	}
+	return errs
+}
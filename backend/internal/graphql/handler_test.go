@@ -0,0 +1,256 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/user"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVideos struct {
+	videos []video.Video
+}
+
+func (f *fakeVideos) GetVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	for i := range f.videos {
+		if f.videos[i].ID == videoID {
+			return &f.videos[i], nil
+		}
+	}
+	return nil, video.ErrVideoNotFound
+}
+
+func (f *fakeVideos) ListVideos(ctx context.Context, page, limit int, filter video.ListFilter) ([]video.Video, int64, error) {
+	return f.videos, int64(len(f.videos)), nil
+}
+
+// fakeComments and fakeUsers record the keys of each batched call
+type fakeComments struct {
+	mu    sync.Mutex
+	calls [][]uuid.UUID
+}
+
+func (f *fakeComments) GetCommentsByVideoID(ctx context.Context, options comment.CommentFilterOptions) (comment.PaginatedComments, error) {
+	return comment.PaginatedComments{CurrentPage: options.Page}, nil
+}
+
+func (f *fakeComments) CountComments(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, videoIDs)
+	f.mu.Unlock()
+
+	counts := make(map[uuid.UUID]int, len(videoIDs))
+	for _, id := range videoIDs {
+		counts[id] = 3
+	}
+	return counts, nil
+}
+
+type fakeUsers struct {
+	mu       sync.Mutex
+	calls    [][]uuid.UUID
+	profiles map[uuid.UUID]*user.Profile
+}
+
+func (f *fakeUsers) GetProfiles(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*user.Profile, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, userIDs)
+	f.mu.Unlock()
+
+	profiles := make(map[uuid.UUID]*user.Profile)
+	for _, id := range userIDs {
+		if profile, ok := f.profiles[id]; ok {
+			profiles[id] = profile
+		}
+	}
+	return profiles, nil
+}
+
+type testServer struct {
+	router   *gin.Engine
+	videos   *fakeVideos
+	comments *fakeComments
+	users    *fakeUsers
+	viewer   uuid.UUID
+}
+
+func newTestServer(t *testing.T, options Options) *testServer {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+
+	alice := &user.Profile{ID: uuid.New(), Username: "alice"}
+	bob := &user.Profile{ID: uuid.New(), Username: "bob"}
+	s := &testServer{
+		videos: &fakeVideos{videos: []video.Video{
+			{ID: uuid.New(), UserID: alice.ID, Title: "One"},
+			{ID: uuid.New(), UserID: bob.ID, Title: "Two"},
+			{ID: uuid.New(), UserID: alice.ID, Title: "Three"},
+			{ID: uuid.New(), UserID: bob.ID, Title: "Taken down", TakenDownAt: &time.Time{}},
+		}},
+		comments: &fakeComments{},
+		users:    &fakeUsers{profiles: map[uuid.UUID]*user.Profile{alice.ID: alice, bob.ID: bob}},
+		viewer:   alice.ID,
+	}
+
+	handler := NewHandler(Services{Videos: s.videos, Comments: s.comments, Users: s.users}, options, log)
+
+	s.router = gin.New()
+	authenticate := func(c *gin.Context) {
+		c.Set("userID", s.viewer.String())
+		c.Set("role", "user")
+	}
+	handler.RegisterRoutes(s.router, authenticate, func(c *gin.Context) {})
+	return s
+}
+
+func (s *testServer) query(t *testing.T, query string, variables map[string]interface{}) (int, map[string]interface{}) {
+	body, err := json.Marshal(Request{Query: query, Variables: variables})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return w.Code, resp
+}
+
+var defaultOptions = Options{MaxDepth: 8, MaxComplexity: 1000, BatchWait: 5 * time.Millisecond}
+
+func TestBatchesLookups(t *testing.T) {
+	s := newTestServer(t, defaultOptions)
+
+	code, resp := s.query(t, `{ videos { total videos { title commentCount owner { username } } } }`, nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Nil(t, resp["errors"])
+
+	videos := resp["data"].(map[string]interface{})["videos"].(map[string]interface{})["videos"].([]interface{})
+	require.Len(t, videos, 4)
+	first := videos[0].(map[string]interface{})
+	assert.Equal(t, "One", first["title"])
+	assert.Equal(t, float64(3), first["commentCount"])
+	assert.Equal(t, "alice", first["owner"].(map[string]interface{})["username"])
+
+	// Four videos by two owners: one call each, and each owner fetched once
+	require.Len(t, s.comments.calls, 1)
+	assert.Len(t, s.comments.calls[0], 4)
+	require.Len(t, s.users.calls, 1)
+	assert.Len(t, s.users.calls[0], 2)
+}
+
+func TestVideo(t *testing.T) {
+	s := newTestServer(t, defaultOptions)
+	query := `query Video($id: ID!) { video(id: $id) { title } }`
+
+	_, resp := s.query(t, query, map[string]interface{}{"id": s.videos.videos[0].ID.String()})
+	assert.Equal(t, map[string]interface{}{"video": map[string]interface{}{"title": "One"}}, resp["data"])
+
+	// Unknown and taken down videos are null
+	_, resp = s.query(t, query, map[string]interface{}{"id": uuid.NewString()})
+	assert.Equal(t, map[string]interface{}{"video": nil}, resp["data"])
+	_, resp = s.query(t, query, map[string]interface{}{"id": s.videos.videos[3].ID.String()})
+	assert.Equal(t, map[string]interface{}{"video": nil}, resp["data"])
+
+	// except to their owner
+	s.viewer = s.videos.videos[3].UserID
+	_, resp = s.query(t, query, map[string]interface{}{"id": s.videos.videos[3].ID.String()})
+	assert.Equal(t, map[string]interface{}{"video": map[string]interface{}{"title": "Taken down"}}, resp["data"])
+}
+
+func TestViewer(t *testing.T) {
+	s := newTestServer(t, defaultOptions)
+
+	_, resp := s.query(t, `{ viewer { user { username } unreadNotificationCount } }`, nil)
+	assert.Equal(t, map[string]interface{}{"viewer": map[string]interface{}{
+		"user":                    map[string]interface{}{"username": "alice"},
+		"unreadNotificationCount": nil,
+	}}, resp["data"])
+}
+
+func TestQueryLimits(t *testing.T) {
+	t.Run("too deep", func(t *testing.T) {
+		s := newTestServer(t, Options{MaxDepth: 3, MaxComplexity: 1000})
+		code, resp := s.query(t, `{ videos { videos { comments { comments { id } } } } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Nil(t, resp["data"])
+		assert.Contains(t, resp["errors"].([]interface{})[0].(map[string]interface{})["message"], "depth 5 exceeds the limit of 3")
+	})
+
+	t.Run("introspection is not counted", func(t *testing.T) {
+		s := newTestServer(t, Options{MaxDepth: 3, MaxComplexity: 1000})
+		code, resp := s.query(t, `{ __schema { types { name fields { name type { name ofType { name } } } } } }`, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Nil(t, resp["errors"])
+	})
+
+	t.Run("too complex", func(t *testing.T) {
+		s := newTestServer(t, Options{MaxDepth: 8, MaxComplexity: 100})
+		code, resp := s.query(t, `{ videos(limit: 50) { videos { title owner { username } } } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, resp["errors"].([]interface{})[0].(map[string]interface{})["message"], "exceeds the limit of 100")
+		assert.Empty(t, s.comments.calls)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		s := newTestServer(t, defaultOptions)
+		code, resp := s.query(t, `{ videos { storagePath } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.NotEmpty(t, resp["errors"])
+	})
+
+	t.Run("missing query", func(t *testing.T) {
+		s := newTestServer(t, defaultOptions)
+		code, resp := s.query(t, "", nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.NotEmpty(t, resp["errors"])
+	})
+}
+
+func TestQueryComplexity(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      int
+	}{
+		{"scalar fields", `{ viewer { unreadNotificationCount } }`, nil, 2},
+		// videos(1) + limit 10 * (videos(1) + title(1) + owner(1) + username(1))
+		{"default limit", `{ videos { videos { title owner { username } } } }`, nil, 1 + 10*(1+3)},
+		{"variable limit", `query($n: Int) { videos(limit: $n) { total } }`, map[string]interface{}{"n": float64(5)}, 1 + 5},
+		{"fragments", `{ videos(limit: 2) { ...page } } fragment page on VideoPage { total page }`, nil, 1 + 2*2},
+		// videos(1) + 1 * (videos(1) + comments(1 + limit 3 * (comments(1) + id(1))))
+		{"nested limits", `{ videos(limit: 1) { videos { comments(limit: 3) { comments { id } } } } }`, nil, 1 + 1*(1+1+3*2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A query runs at the limit of its complexity and not below it
+			s := newTestServer(t, Options{MaxDepth: 8, MaxComplexity: tt.want})
+			code, resp := s.query(t, tt.query, tt.variables)
+			require.Equal(t, http.StatusOK, code, resp["errors"])
+			assert.Nil(t, resp["errors"])
+
+			s = newTestServer(t, Options{MaxDepth: 8, MaxComplexity: tt.want - 1})
+			code, resp = s.query(t, tt.query, tt.variables)
+			require.Equal(t, http.StatusBadRequest, code)
+			assert.Contains(t, resp["errors"].([]interface{})[0].(map[string]interface{})["message"],
+				fmt.Sprintf("complexity %d, which exceeds the limit of %d", tt.want, tt.want-1))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Package graphql serves a read-only GraphQL endpoint over the video,
+// comment, profile and notification services, so a frontend page can fetch
+// everything it shows in one request.
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/consensuslabs/pavilion-network/backend/internal/user"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+// VideoService looks up videos
+type VideoService interface {
+	GetVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error)
+	ListVideos(ctx context.Context, page, limit int, filter video.ListFilter) ([]video.Video, int64, error)
+}
+
+// CommentService pages through comments and counts them
+type CommentService interface {
+	GetCommentsByVideoID(ctx context.Context, options comment.CommentFilterOptions) (comment.PaginatedComments, error)
+	CountComments(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+// UserService looks up public profiles
+type UserService interface {
+	GetProfiles(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*user.Profile, error)
+}
+
+// NotificationCounter counts a user's unread notifications
+type NotificationCounter interface {
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// Services are what queries read from. Notifications may be nil when the
+// notification service is unavailable.
+type Services struct {
+	Videos        VideoService
+	Comments      CommentService
+	Users         UserService
+	Notifications NotificationCounter
+}
+
+// Options limit the cost of queries
+type Options struct {
+	// MaxDepth is the deepest field nesting a query may have
+	MaxDepth int
+	// MaxComplexity is the highest cost a query may have, see queryComplexity
+	MaxComplexity int
+	// BatchWait is how long a lookup waits to be batched with others
+	BatchWait time.Duration
+}
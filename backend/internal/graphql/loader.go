@@ -0,0 +1,28 @@
+package graphql
+
+import (
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/user"
+	"github.com/google/uuid"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// maxBatch bounds how many keys one batched lookup asks for
+const maxBatch = 100
+
+// loaders batch the lookups made while one query resolves. Resolvers of
+// list items run concurrently, so keys asked for within wait of the first
+// are fetched with one call; each key is fetched at most once per query.
+type loaders struct {
+	commentCounts *dataloadgen.Loader[uuid.UUID, int]
+	users         *dataloadgen.Loader[uuid.UUID, *user.Profile]
+}
+
+func newLoaders(services Services, wait time.Duration) *loaders {
+	options := []dataloadgen.Option{dataloadgen.WithWait(wait), dataloadgen.WithBatchCapacity(maxBatch)}
+	return &loaders{
+		commentCounts: dataloadgen.NewMappedLoader(services.Comments.CountComments, options...),
+		users:         dataloadgen.NewMappedLoader(services.Users.GetProfiles, options...),
+	}
+}
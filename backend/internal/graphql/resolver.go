@@ -0,0 +1,94 @@
+package graphql
+
+//go:generate go tool gqlgen generate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/user"
+	"github.com/google/uuid"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// maxVideoLimit caps the limit of a video listing, as GET /videos does
+const maxVideoLimit = 50
+
+// Errors returned to clients. Internal errors are logged and replaced by
+// one of these so that queries do not leak database details.
+var (
+	errInvalidID       = errors.New("invalid ID")
+	errInvalidPage     = errors.New("page must be a positive integer")
+	errInvalidLimit    = errors.New("limit must be a positive integer")
+	errInvalidTag      = errors.New("invalid tag")
+	errInvalidCategory = errors.New("invalid category")
+	errVideos          = errors.New("failed to retrieve videos")
+	errComments        = errors.New("failed to retrieve comments")
+	errUsers           = errors.New("failed to retrieve users")
+	errNotifications   = errors.New("failed to retrieve notifications")
+)
+
+type contextKey struct{}
+
+// requestState is what resolvers know about the request being served
+type requestState struct {
+	viewerID   uuid.UUID
+	viewerRole string
+	loaders    *loaders
+}
+
+func withRequestState(ctx context.Context, state *requestState) context.Context {
+	return context.WithValue(ctx, contextKey{}, state)
+}
+
+func stateFrom(ctx context.Context) *requestState {
+	return ctx.Value(contextKey{}).(*requestState)
+}
+
+// Viewer is the signed-in user, resolved field by field
+type Viewer struct {
+	UserID uuid.UUID
+}
+
+// resolver is the root of the schema; the resolvers of its types are
+// generated from schema.graphql into schema.resolvers.go
+type resolver struct {
+	services Services
+	logger   logger.Logger
+}
+
+// internalError logs err and returns public in its place
+func (r *resolver) internalError(err error, msg string, public error) error {
+	r.logger.LogError(err, msg)
+	return public
+}
+
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, errInvalidID
+	}
+	return parsed, nil
+}
+
+// loadUser looks up a profile through the request's batched loader.
+// Unknown and inactive users are nil.
+func (r *resolver) loadUser(ctx context.Context, userID uuid.UUID) (*user.Profile, error) {
+	profile, err := stateFrom(ctx).loaders.users.Load(ctx, userID)
+	if errors.Is(err, dataloadgen.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.internalError(err, "Failed to get profiles", errUsers)
+	}
+	return profile, nil
+}
+
+// optional returns nil for the empty string, which the schema shows as null
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,201 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.66
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/consensuslabs/pavilion-network/backend/internal/user"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// ContentHTML is the resolver for the contentHtml field.
+func (r *commentResolver) ContentHTML(ctx context.Context, obj *comment.Comment) (*string, error) {
+	return optional(obj.ContentHTML), nil
+}
+
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *comment.Comment) (*user.Profile, error) {
+	return r.loadUser(ctx, obj.UserID)
+}
+
+// Video is the resolver for the video field.
+func (r *queryResolver) Video(ctx context.Context, id string) (*video.Video, error) {
+	videoID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := r.services.Videos.GetVideo(ctx, videoID)
+	if errors.Is(err, video.ErrVideoNotFound) || errors.Is(err, video.ErrVideoDeleted) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.internalError(err, "Failed to get video", errVideos)
+	}
+
+	state := stateFrom(ctx)
+	if v == nil || v.HiddenFrom(state.viewerID, state.viewerRole) {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// Videos is the resolver for the videos field.
+func (r *queryResolver) Videos(ctx context.Context, page *int, limit *int, tag *string, category *string, sort *VideoSort) (*VideoPage, error) {
+	// Arguments with a default are never null
+	if *page < 1 {
+		return nil, errInvalidPage
+	}
+	if *limit < 1 {
+		return nil, errInvalidLimit
+	}
+	listLimit := min(*limit, maxVideoLimit)
+
+	var filter video.ListFilter
+	if tag != nil {
+		tags, err := video.NormalizeTags([]string{*tag})
+		if err != nil || len(tags) == 0 {
+			return nil, errInvalidTag
+		}
+		filter.Tag = tags[0]
+	}
+	if category != nil {
+		c := video.Category(*category)
+		if !c.IsValid() {
+			return nil, errInvalidCategory
+		}
+		filter.Category = c
+	}
+	filter.Sort = video.ListSort(strings.ToLower(sort.String()))
+
+	videos, total, err := r.services.Videos.ListVideos(ctx, *page, listLimit, filter)
+	if err != nil {
+		return nil, r.internalError(err, "Failed to list videos", errVideos)
+	}
+
+	result := &VideoPage{Videos: make([]*video.Video, len(videos)), Page: *page, Limit: listLimit, Total: int(total)}
+	for i := range videos {
+		result.Videos[i] = &videos[i]
+	}
+	return result, nil
+}
+
+// User is the resolver for the user field.
+func (r *queryResolver) User(ctx context.Context, id string) (*user.Profile, error) {
+	userID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	return r.loadUser(ctx, userID)
+}
+
+// Viewer is the resolver for the viewer field.
+func (r *queryResolver) Viewer(ctx context.Context) (*Viewer, error) {
+	return &Viewer{UserID: stateFrom(ctx).viewerID}, nil
+}
+
+// AvatarURL is the resolver for the avatarUrl field.
+func (r *userResolver) AvatarURL(ctx context.Context, obj *user.Profile) (*string, error) {
+	return optional(obj.AvatarURL), nil
+}
+
+// Status is the resolver for the status field.
+func (r *videoResolver) Status(ctx context.Context, obj *video.Video) (string, error) {
+	if obj.Upload == nil {
+		return "", nil
+	}
+	return string(obj.Upload.Status), nil
+}
+
+// Category is the resolver for the category field.
+func (r *videoResolver) Category(ctx context.Context, obj *video.Video) (*string, error) {
+	return optional(string(obj.Category)), nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *videoResolver) Tags(ctx context.Context, obj *video.Video) ([]string, error) {
+	tags := make([]string, len(obj.Tags))
+	for i, tag := range obj.Tags {
+		tags[i] = tag.Name
+	}
+	return tags, nil
+}
+
+// PreviewPath is the resolver for the previewPath field.
+func (r *videoResolver) PreviewPath(ctx context.Context, obj *video.Video) (*string, error) {
+	return optional(obj.PreviewPath), nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *videoResolver) Owner(ctx context.Context, obj *video.Video) (*user.Profile, error) {
+	return r.loadUser(ctx, obj.UserID)
+}
+
+// CommentCount is the resolver for the commentCount field.
+func (r *videoResolver) CommentCount(ctx context.Context, obj *video.Video) (int, error) {
+	count, err := stateFrom(ctx).loaders.commentCounts.Load(ctx, obj.ID)
+	if errors.Is(err, dataloadgen.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, r.internalError(err, "Failed to count comments", errComments)
+	}
+	return count, nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *videoResolver) Comments(ctx context.Context, obj *video.Video, page *int, limit *int) (*comment.PaginatedComments, error) {
+	// The comment service clamps page and limit like GET /video/{id}/comments
+	options := comment.CommentFilterOptions{VideoID: obj.ID, Page: *page, Limit: *limit}
+
+	result, err := r.services.Comments.GetCommentsByVideoID(ctx, options)
+	if err != nil {
+		return nil, r.internalError(err, "Failed to get comments", errComments)
+	}
+	return &result, nil
+}
+
+// User is the resolver for the user field.
+func (r *viewerResolver) User(ctx context.Context, obj *Viewer) (*user.Profile, error) {
+	return r.loadUser(ctx, obj.UserID)
+}
+
+// UnreadNotificationCount is the resolver for the unreadNotificationCount field.
+func (r *viewerResolver) UnreadNotificationCount(ctx context.Context, obj *Viewer) (*int, error) {
+	if r.services.Notifications == nil {
+		return nil, nil
+	}
+	count, err := r.services.Notifications.GetUnreadCount(ctx, obj.UserID)
+	if err != nil {
+		return nil, r.internalError(err, "Failed to count unread notifications", errNotifications)
+	}
+	return &count, nil
+}
+
+// Comment returns CommentResolver implementation.
+func (r *resolver) Comment() CommentResolver { return &commentResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *resolver) User() UserResolver { return &userResolver{r} }
+
+// Video returns VideoResolver implementation.
+func (r *resolver) Video() VideoResolver { return &videoResolver{r} }
+
+// Viewer returns ViewerResolver implementation.
+func (r *resolver) Viewer() ViewerResolver { return &viewerResolver{r} }
+
+type commentResolver struct{ *resolver }
+type queryResolver struct{ *resolver }
+type userResolver struct{ *resolver }
+type videoResolver struct{ *resolver }
+type viewerResolver struct{ *resolver }
@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RunChecks checks all dependencies concurrently, giving each up to timeout,
+// and returns their statuses in the order given
+func RunChecks(ctx context.Context, dependencies []Dependency, timeout time.Duration) Report {
+	report := Report{
+		Status:       StatusUp,
+		Dependencies: make([]DependencyStatus, len(dependencies)),
+		CheckedAt:    time.Now(),
+	}
+
+	var wg sync.WaitGroup
+	for i, dependency := range dependencies {
+		wg.Add(1)
+		go func(i int, dependency Dependency) {
+			defer wg.Done()
+			report.Dependencies[i] = runCheck(ctx, dependency, timeout)
+		}(i, dependency)
+	}
+	wg.Wait()
+
+	for _, dependency := range report.Dependencies {
+		if dependency.Status == StatusUp {
+			continue
+		}
+		if dependency.Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// runCheck runs one check, abandoning it when it outlives its timeout
+func runCheck(ctx context.Context, dependency Dependency, timeout time.Duration) DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- dependency.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := DependencyStatus{
+		Name:      dependency.Name,
+		Status:    StatusUp,
+		Critical:  dependency.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
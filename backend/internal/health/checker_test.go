@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(ctx context.Context) error { return nil }
+
+func down(ctx context.Context) error { return errors.New("connection refused") }
+
+// hang ignores its context, like a client without context support
+func hang(ctx context.Context) error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestRunChecks(t *testing.T) {
+	report := RunChecks(context.Background(), []Dependency{
+		{Name: "db", Critical: true, Check: up},
+		{Name: "ipfs", Check: down},
+	}, time.Second)
+
+	assert.Equal(t, StatusDegraded, report.Status, "only a non-critical dependency is down")
+	require.Len(t, report.Dependencies, 2)
+	assert.Equal(t, "db", report.Dependencies[0].Name)
+	assert.Equal(t, StatusUp, report.Dependencies[0].Status)
+	assert.Equal(t, StatusDown, report.Dependencies[1].Status)
+	assert.Equal(t, "connection refused", report.Dependencies[1].Error)
+
+	report = RunChecks(context.Background(), []Dependency{
+		{Name: "db", Critical: true, Check: down},
+		{Name: "ipfs", Check: up},
+	}, time.Second)
+	assert.Equal(t, StatusDown, report.Status)
+
+	report = RunChecks(context.Background(), nil, time.Second)
+	assert.Equal(t, StatusUp, report.Status)
+}
+
+func TestRunChecksTimeout(t *testing.T) {
+	start := time.Now()
+	report := RunChecks(context.Background(), []Dependency{
+		{Name: "pulsar", Check: hang},
+		{Name: "redis", Critical: true, Check: hang},
+	}, 20*time.Millisecond)
+
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "checks run concurrently and are abandoned at the timeout")
+	assert.Equal(t, StatusDown, report.Status)
+	for _, dependency := range report.Dependencies {
+		assert.Equal(t, context.DeadlineExceeded.Error(), dependency.Error)
+	}
+}
+
+func TestHandleReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, tc := range []struct {
+		name   string
+		check  Check
+		status int
+	}{
+		{"ready", up, http.StatusOK},
+		{"not ready", down, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewHandler(httpHandler.NewResponseHandler(nil), time.Second)
+			handler.Register(Dependency{Name: "db", Critical: true, Check: tc.check})
+			router := gin.New()
+			handler.RegisterRoutes(router)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+			assert.Equal(t, tc.status, w.Code)
+
+			var response struct {
+				Data Report `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response.Data.Dependencies, 1)
+			assert.Equal(t, "db", response.Data.Dependencies[0].Name)
+		})
+	}
+}
@@ -1,23 +1,51 @@
 package health
 
 import (
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
 	"github.com/gin-gonic/gin"
 )
 
 // Handler handles health check related endpoints
 type Handler struct {
 	responseHandler ResponseHandler
+	timeout         time.Duration
+
+	mu           sync.RWMutex
+	dependencies []Dependency
 }
 
-// NewHandler creates a new health check handler
-func NewHandler(responseHandler ResponseHandler) *Handler {
+// NewHandler creates a new health check handler. Each dependency check is
+// given up to timeout.
+func NewHandler(responseHandler ResponseHandler, timeout time.Duration) *Handler {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
 	return &Handler{
 		responseHandler: responseHandler,
+		timeout:         timeout,
 	}
 }
 
+// Register adds a dependency to the readiness check
+func (h *Handler) Register(dependency Dependency) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.dependencies = append(h.dependencies, dependency)
+}
+
+// RegisterRoutes registers the health check routes
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/health", h.HandleHealthCheck)
+	router.GET("/health/live", h.HandleLive)
+	router.GET("/health/ready", h.HandleReady)
+}
+
 // @Summary Health check endpoint
-// @Description Checks if the API server is running properly
+// @Description Checks if the API server is running properly. Same as /health/live.
 // @Tags health
 // @Produce json
 // @Success 200 {object} interface{} "Health check successful"
@@ -25,3 +53,41 @@ func NewHandler(responseHandler ResponseHandler) *Handler {
 func (h *Handler) HandleHealthCheck(c *gin.Context) {
 	h.responseHandler.SuccessResponse(c, nil, "Health check successful")
 }
+
+// @Summary Liveness probe
+// @Description Reports that the server process is up without checking dependencies, for orchestrators deciding whether to restart it
+// @Tags health
+// @Produce json
+// @Success 200 {object} httpHandler.APIResponse "Server is live"
+// @Router /health/live [get]
+func (h *Handler) HandleLive(c *gin.Context) {
+	h.responseHandler.SuccessResponse(c, nil, "Server is live")
+}
+
+// @Summary Readiness probe
+// @Description Checks every dependency concurrently and reports each one's status and latency. Returns 503 when a critical dependency (CockroachDB, Redis, ScyllaDB, S3) is down; non-critical ones (IPFS, Pulsar) only degrade the status.
+// @Tags health
+// @Produce json
+// @Success 200 {object} httpHandler.APIResponse{data=Report} "Server is ready"
+// @Failure 503 {object} httpHandler.APIResponse{data=Report,error=httpHandler.APIError} "A critical dependency is down"
+// @Router /health/ready [get]
+func (h *Handler) HandleReady(c *gin.Context) {
+	h.mu.RLock()
+	dependencies := append([]Dependency(nil), h.dependencies...)
+	h.mu.RUnlock()
+
+	report := RunChecks(c.Request.Context(), dependencies, h.timeout)
+	if report.Status == StatusDown {
+		c.JSON(apierror.Status(apierror.CodeServiceUnavailable), httpHandler.Response{
+			Success: false,
+			Data:    report,
+			Error: &httpHandler.Error{
+				Code:    apierror.CodeServiceUnavailable,
+				Message: "A critical dependency is down",
+			},
+		})
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, report, "Server is ready")
+}
@@ -1,6 +1,8 @@
 package health
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,3 +11,15 @@ type ResponseHandler interface {
 	SuccessResponse(c *gin.Context, data interface{}, message string)
 	ErrorResponse(c *gin.Context, status int, code, message string, err error)
 }
+
+// Check verifies that a dependency is reachable, returning an error when it is not
+type Check func(ctx context.Context) error
+
+// Dependency is a downstream service checked by the readiness endpoint
+type Dependency struct {
+	Name string
+	// Critical dependencies make the server not ready when they are down;
+	// the others only degrade it
+	Critical bool
+	Check    Check
+}
@@ -0,0 +1,29 @@
+package health
+
+import "time"
+
+// Status is the state of a dependency or of the server as a whole
+type Status string
+
+const (
+	StatusUp Status = "up"
+	// StatusDegraded means only non-critical dependencies are down
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// DependencyStatus is the result of checking one dependency
+type DependencyStatus struct {
+	Name      string  `json:"name" example:"cockroachdb"`
+	Status    Status  `json:"status" enums:"up,down" example:"up"`
+	Critical  bool    `json:"critical" example:"true"`
+	LatencyMs float64 `json:"latency_ms" example:"1.8"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the result of a readiness check
+type Report struct {
+	Status       Status             `json:"status" enums:"up,degraded,down" example:"up"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	CheckedAt    time.Time          `json:"checked_at"`
+}
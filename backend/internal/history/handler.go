@@ -0,0 +1,156 @@
+package history
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for watch history
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new watch history handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the watch history routes
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	router.POST("/video/:id/progress", authMiddleware, h.handleRecordProgress)
+
+	me := router.Group("/me", authMiddleware)
+	{
+		me.GET("/history", h.handleListHistory)
+		me.DELETE("/history", h.handleClearHistory)
+	}
+}
+
+// @Summary Report playback progress
+// @Description Store the current playback position in a video and move it to the top of the watch history. Players report every few seconds while playing and when paused. GET /video/{id} returns the position as resume_at.
+// @Tags history
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param request body ProgressRequest true "Playback position"
+// @Success 200 {object} httpHandler.APIResponse{data=Item} "Progress recorded"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid video ID or position"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Video not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /video/{id}/progress [post]
+func (h *Handler) handleRecordProgress(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	videoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidID, "Invalid video ID format"), "")
+		return
+	}
+
+	var req ProgressRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	item, err := h.service.RecordProgress(c.Request.Context(), userID, videoID, *req.Position, req.Duration)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to record progress")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, item, "Progress recorded successfully")
+}
+
+// @Summary Get watch history
+// @Description List the videos the authenticated user watched, most recent first, with the last position and completion percentage. Deleted videos are left out.
+// @Tags history
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Param before query string false "Cursor from next_cursor of the previous page"
+// @Success 200 {object} httpHandler.APIResponse{data=ListResponse} "Watch history retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid cursor"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/history [get]
+func (h *Handler) handleListHistory(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	var before time.Time
+	if cursor := c.Query("before"); cursor != "" {
+		var err error
+		if before, err = DecodeCursor(cursor); err != nil {
+			apierror.Abort(c, err, "")
+			return
+		}
+	}
+
+	list, err := h.service.List(c.Request.Context(), userID, before, limit)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to retrieve watch history")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Watch history retrieved successfully")
+}
+
+// @Summary Clear watch history
+// @Description Delete the authenticated user's whole watch history, including resume positions
+// @Tags history
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse "Watch history cleared"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/history [delete]
+func (h *Handler) handleClearHistory(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Clear(c.Request.Context(), userID); err != nil {
+		apierror.Abort(c, err, "Failed to clear watch history")
+		return
+	}
+
+	h.logger.LogInfo("Watch history cleared", map[string]interface{}{
+		"userId": userID.String(),
+	})
+	h.responseHandler.SuccessResponse(c, nil, "Watch history cleared successfully")
+}
+
+// getUserID returns the authenticated user's ID, aborting with an error when it is missing
+func (h *Handler) getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, _ := c.Get("userID")
+	str, _ := userIDStr.(string)
+	userID, err := uuid.Parse(str)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized, "User not authenticated"), "")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
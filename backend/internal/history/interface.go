@@ -0,0 +1,52 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrVideoNotFound is returned when progress is reported for a video that does not exist or has been deleted
+	ErrVideoNotFound = apierror.New(apierror.CodeVideoNotFound, "video not found")
+	// ErrInvalidPosition is returned when a position is negative or past the end of the video
+	ErrInvalidPosition = apierror.Validation("position", "position must be between 0 and the duration")
+	// ErrInvalidDuration is returned when the duration is not positive
+	ErrInvalidDuration = apierror.Validation("duration", "duration must be positive")
+	// ErrInvalidCursor is returned when a history cursor cannot be parsed
+	ErrInvalidCursor = apierror.New(apierror.CodeInvalidParameter, "invalid cursor")
+)
+
+// Service defines the interface for watch history
+type Service interface {
+	// RecordProgress stores the user's playback position in a video and moves
+	// it to the top of their history
+	RecordProgress(ctx context.Context, userID, videoID uuid.UUID, position, duration float64) (*Item, error)
+	// List returns the videos the user watched before the given time, most
+	// recent first; a zero before starts from the most recent
+	List(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (*ListResponse, error)
+	// Clear deletes the user's whole watch history
+	Clear(ctx context.Context, userID uuid.UUID) error
+	// ResumePosition returns where the user should resume the video, or false
+	// when there is nothing to resume
+	ResumePosition(ctx context.Context, userID, videoID uuid.UUID) (float64, bool, error)
+	// CoWatched counts, for each other video, how many of a sample of the
+	// video's recent viewers also watched it lately
+	CoWatched(ctx context.Context, videoID uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+// Repository stores playback positions
+type Repository interface {
+	// SaveProgress upserts the entry for its user and video
+	SaveProgress(ctx context.Context, entry *Entry) error
+	// GetProgress returns the entry for a user and video, or nil when there is none
+	GetProgress(ctx context.Context, userID, videoID uuid.UUID) (*Entry, error)
+	// ListRecent returns up to limit entries watched before the given time, most recent first
+	ListRecent(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]Entry, error)
+	// ListViewers returns up to limit users who recently watched a video
+	ListViewers(ctx context.Context, videoID uuid.UUID, limit int) ([]uuid.UUID, error)
+	// Clear deletes all of a user's entries
+	Clear(ctx context.Context, userID uuid.UUID) error
+}
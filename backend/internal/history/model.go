@@ -0,0 +1,72 @@
+package history
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompletedPercent is the completion at which a video counts as watched to
+// the end, so playback starts over instead of resuming near the credits
+const CompletedPercent = 95
+
+// Entry is a user's playback position in a video
+type Entry struct {
+	UserID  uuid.UUID
+	VideoID uuid.UUID
+	// Position is the playback position in seconds
+	Position float64
+	// Duration is the video's length in seconds as reported by the player
+	Duration float64
+	// WatchedAt is when the position was last reported
+	WatchedAt time.Time
+}
+
+// Completion returns how much of the video has been watched, in percent
+func (e Entry) Completion() float64 {
+	if e.Duration <= 0 {
+		return 0
+	}
+	percent := e.Position / e.Duration * 100
+	if percent > 100 {
+		return 100
+	}
+	return percent
+}
+
+// ResumeAt returns the position playback should resume from, or false when
+// the video was watched to the end or barely started
+func (e Entry) ResumeAt() (float64, bool) {
+	if e.Position <= 0 || e.Completion() >= CompletedPercent {
+		return 0, false
+	}
+	return e.Position, true
+}
+
+// Item is a watched video in a user's history
+type Item struct {
+	VideoID uuid.UUID `json:"video_id"`
+	Title   string    `json:"title"`
+	// Position is the last playback position in seconds
+	Position float64 `json:"position" example:"754.2"`
+	Duration float64 `json:"duration" example:"1800"`
+	// Completion is how much of the video has been watched, in percent
+	Completion float64   `json:"completion" example:"41.9"`
+	WatchedAt  time.Time `json:"watched_at"`
+}
+
+// ProgressRequest represents a playback position report
+type ProgressRequest struct {
+	// Position is the playback position in seconds
+	Position *float64 `json:"position" binding:"required,gte=0" example:"754.2"`
+	// Duration is the video's length in seconds
+	Duration float64 `json:"duration" binding:"required,gt=0" example:"1800"`
+}
+
+// ListResponse represents a page of a user's watch history, most recent first
+type ListResponse struct {
+	Items []Item `json:"items"`
+	Limit int    `json:"limit"`
+	// NextCursor is passed as before to get the next page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
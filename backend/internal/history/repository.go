@@ -0,0 +1,155 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/google/uuid"
+)
+
+// ScyllaRepository implements the Repository interface on ScyllaDB
+type ScyllaRepository struct {
+	session  *gocql.Session
+	keyspace string
+}
+
+// NewScyllaRepository creates a new ScyllaDB watch history repository
+func NewScyllaRepository(session *gocql.Session, keyspace string) *ScyllaRepository {
+	return &ScyllaRepository{
+		session:  session,
+		keyspace: keyspace,
+	}
+}
+
+// SaveProgress upserts the entry, replacing the video's previous row in the
+// time-ordered history, and records the user among the video's viewers
+func (r *ScyllaRepository) SaveProgress(ctx context.Context, entry *Entry) error {
+	// Timestamps are stored with millisecond precision; truncate so the row
+	// can be found again when it moves
+	entry.WatchedAt = entry.WatchedAt.Truncate(time.Millisecond)
+
+	previous, err := r.GetProgress(ctx, entry.UserID, entry.VideoID)
+	if err != nil {
+		return err
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	if previous != nil {
+		batch.Query(fmt.Sprintf(`DELETE FROM %s.watch_history WHERE user_id = ? AND watched_at = ? AND video_id = ?`, r.keyspace),
+			gocqlUUID(entry.UserID), previous.WatchedAt, gocqlUUID(entry.VideoID))
+	}
+	batch.Query(fmt.Sprintf(`INSERT INTO %s.watch_progress (user_id, video_id, position, duration, watched_at) VALUES (?, ?, ?, ?, ?)`, r.keyspace),
+		gocqlUUID(entry.UserID), gocqlUUID(entry.VideoID), entry.Position, entry.Duration, entry.WatchedAt)
+	batch.Query(fmt.Sprintf(`INSERT INTO %s.watch_history (user_id, watched_at, video_id, position, duration) VALUES (?, ?, ?, ?, ?)`, r.keyspace),
+		gocqlUUID(entry.UserID), entry.WatchedAt, gocqlUUID(entry.VideoID), entry.Position, entry.Duration)
+	batch.Query(fmt.Sprintf(`INSERT INTO %s.video_viewers (video_id, user_id, watched_at) VALUES (?, ?, ?)`, r.keyspace),
+		gocqlUUID(entry.VideoID), gocqlUUID(entry.UserID), entry.WatchedAt)
+
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("failed to save watch progress: %w", err)
+	}
+	return nil
+}
+
+// GetProgress returns the entry for a user and video
+func (r *ScyllaRepository) GetProgress(ctx context.Context, userID, videoID uuid.UUID) (*Entry, error) {
+	entry := &Entry{UserID: userID, VideoID: videoID}
+	err := r.session.Query(fmt.Sprintf(`SELECT position, duration, watched_at FROM %s.watch_progress WHERE user_id = ? AND video_id = ?`, r.keyspace),
+		gocqlUUID(userID), gocqlUUID(videoID)).
+		WithContext(ctx).
+		Scan(&entry.Position, &entry.Duration, &entry.WatchedAt)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watch progress: %w", err)
+	}
+	return entry, nil
+}
+
+// ListRecent returns a user's entries, most recent first
+func (r *ScyllaRepository) ListRecent(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]Entry, error) {
+	query := fmt.Sprintf(`SELECT video_id, position, duration, watched_at FROM %s.watch_history WHERE user_id = ?`, r.keyspace)
+	args := []interface{}{gocqlUUID(userID)}
+	if !before.IsZero() {
+		query += ` AND watched_at < ?`
+		args = append(args, before)
+	}
+	query += ` LIMIT ?`
+	args = append(args, limit)
+
+	iter := r.session.Query(query, args...).WithContext(ctx).Iter()
+	var (
+		entries  []Entry
+		videoID  gocql.UUID
+		position float64
+		duration float64
+		watched  time.Time
+	)
+	for iter.Scan(&videoID, &position, &duration, &watched) {
+		entries = append(entries, Entry{
+			UserID:    userID,
+			VideoID:   uuid.UUID(videoID),
+			Position:  position,
+			Duration:  duration,
+			WatchedAt: watched,
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list watch history: %w", err)
+	}
+	return entries, nil
+}
+
+// ListViewers returns up to limit users who watched a video in the last 90 days
+func (r *ScyllaRepository) ListViewers(ctx context.Context, videoID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	iter := r.session.Query(fmt.Sprintf(`SELECT user_id FROM %s.video_viewers WHERE video_id = ? LIMIT ?`, r.keyspace),
+		gocqlUUID(videoID), limit).WithContext(ctx).Iter()
+	var (
+		viewers []uuid.UUID
+		userID  gocql.UUID
+	)
+	for iter.Scan(&userID) {
+		viewers = append(viewers, uuid.UUID(userID))
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to list video viewers: %w", err)
+	}
+	return viewers, nil
+}
+
+// Clear deletes a user's partitions from both tables and takes the user out
+// of the viewers of every video they watched
+func (r *ScyllaRepository) Clear(ctx context.Context, userID uuid.UUID) error {
+	iter := r.session.Query(fmt.Sprintf(`SELECT video_id FROM %s.watch_progress WHERE user_id = ?`, r.keyspace),
+		gocqlUUID(userID)).WithContext(ctx).Iter()
+	var (
+		videoIDs []gocql.UUID
+		videoID  gocql.UUID
+	)
+	for iter.Scan(&videoID) {
+		videoIDs = append(videoIDs, videoID)
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("failed to list watched videos: %w", err)
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for _, id := range videoIDs {
+		batch.Query(fmt.Sprintf(`DELETE FROM %s.video_viewers WHERE video_id = ? AND user_id = ?`, r.keyspace), id, gocqlUUID(userID))
+	}
+	batch.Query(fmt.Sprintf(`DELETE FROM %s.watch_progress WHERE user_id = ?`, r.keyspace), gocqlUUID(userID))
+	batch.Query(fmt.Sprintf(`DELETE FROM %s.watch_history WHERE user_id = ?`, r.keyspace), gocqlUUID(userID))
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return fmt.Errorf("failed to clear watch history: %w", err)
+	}
+	return nil
+}
+
+// gocqlUUID converts a UUID for binding in CQL queries
+func gocqlUUID(id uuid.UUID) gocql.UUID {
+	return gocql.UUID(id)
+}
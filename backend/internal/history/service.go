@@ -0,0 +1,186 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db   *gorm.DB
+	repo Repository
+	now  func() time.Time
+}
+
+// NewService creates a new watch history service. Positions are kept in
+// repo; videos are looked up in db.
+func NewService(db *gorm.DB, repo Repository) Service {
+	return &serviceImpl{
+		db:   db,
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// RecordProgress stores a playback position
+func (s *serviceImpl) RecordProgress(ctx context.Context, userID, videoID uuid.UUID, position, duration float64) (*Item, error) {
+	if duration <= 0 {
+		return nil, ErrInvalidDuration
+	}
+	if position < 0 || position > duration {
+		return nil, ErrInvalidPosition
+	}
+
+	var v video.Video
+	if err := s.db.WithContext(ctx).Select("id", "title").First(&v, "id = ?", videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	entry := &Entry{
+		UserID:    userID,
+		VideoID:   videoID,
+		Position:  position,
+		Duration:  duration,
+		WatchedAt: s.now(),
+	}
+	if err := s.repo.SaveProgress(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	item := newItem(*entry, v.Title)
+	return &item, nil
+}
+
+// List returns a page of the user's history. Videos deleted since they were
+// watched are left out, so a page can be shorter than limit.
+func (s *serviceImpl) List(ctx context.Context, userID uuid.UUID, before time.Time, limit int) (*ListResponse, error) {
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	entries, err := s.repo.ListRecent(ctx, userID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	titles, err := s.videoTitles(ctx, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ListResponse{Items: make([]Item, 0, len(entries)), Limit: limit}
+	for _, entry := range entries {
+		title, ok := titles[entry.VideoID]
+		if !ok {
+			continue
+		}
+		response.Items = append(response.Items, newItem(entry, title))
+	}
+	if len(entries) == limit {
+		response.NextCursor = EncodeCursor(entries[len(entries)-1].WatchedAt)
+	}
+	return response, nil
+}
+
+// Clear deletes the user's history
+func (s *serviceImpl) Clear(ctx context.Context, userID uuid.UUID) error {
+	return s.repo.Clear(ctx, userID)
+}
+
+// ResumePosition returns where the user left off in a video
+func (s *serviceImpl) ResumePosition(ctx context.Context, userID, videoID uuid.UUID) (float64, bool, error) {
+	entry, err := s.repo.GetProgress(ctx, userID, videoID)
+	if err != nil || entry == nil {
+		return 0, false, err
+	}
+	position, ok := entry.ResumeAt()
+	return position, ok, nil
+}
+
+// Co-watching is sampled from this many of a video's viewers, and this many
+// of each viewer's most recently watched videos
+const (
+	coWatchViewers = 50
+	coWatchRecent  = 20
+)
+
+// CoWatched counts the other videos a sample of the video's viewers watched recently
+func (s *serviceImpl) CoWatched(ctx context.Context, videoID uuid.UUID) (map[uuid.UUID]int, error) {
+	viewers, err := s.repo.ListViewers(ctx, videoID, coWatchViewers)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int)
+	for _, viewer := range viewers {
+		entries, err := s.repo.ListRecent(ctx, viewer, time.Time{}, coWatchRecent)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.VideoID != videoID {
+				counts[entry.VideoID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// videoTitles returns the titles of the entries' videos that still exist
+func (s *serviceImpl) videoTitles(ctx context.Context, entries []Entry) (map[uuid.UUID]string, error) {
+	titles := make(map[uuid.UUID]string, len(entries))
+	if len(entries) == 0 {
+		return titles, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.VideoID)
+	}
+
+	var videos []video.Video
+	if err := s.db.WithContext(ctx).Select("id", "title").Where("id IN ?", ids).Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to get watched videos: %w", err)
+	}
+	for _, v := range videos {
+		titles[v.ID] = v.Title
+	}
+	return titles, nil
+}
+
+// newItem builds the history item for an entry
+func newItem(entry Entry, title string) Item {
+	return Item{
+		VideoID:    entry.VideoID,
+		Title:      title,
+		Position:   entry.Position,
+		Duration:   entry.Duration,
+		Completion: entry.Completion(),
+		WatchedAt:  entry.WatchedAt,
+	}
+}
+
+// EncodeCursor returns the cursor for the page after an entry watched at t
+func EncodeCursor(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// DecodeCursor parses a cursor returned in ListResponse.NextCursor
+func DecodeCursor(cursor string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, cursor)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidCursor, cursor)
+	}
+	return t, nil
+}
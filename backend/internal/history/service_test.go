@@ -0,0 +1,153 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRepository keeps entries in memory for tests
+type memoryRepository struct {
+	entries map[uuid.UUID]Entry
+}
+
+func (r *memoryRepository) SaveProgress(ctx context.Context, entry *Entry) error {
+	r.entries[entry.VideoID] = *entry
+	return nil
+}
+
+func (r *memoryRepository) GetProgress(ctx context.Context, userID, videoID uuid.UUID) (*Entry, error) {
+	entry, ok := r.entries[videoID]
+	if !ok || entry.UserID != userID {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (r *memoryRepository) ListRecent(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]Entry, error) {
+	return nil, nil
+}
+
+func (r *memoryRepository) ListViewers(ctx context.Context, videoID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	entry, ok := r.entries[videoID]
+	if !ok {
+		return nil, nil
+	}
+	return []uuid.UUID{entry.UserID}, nil
+}
+
+func (r *memoryRepository) Clear(ctx context.Context, userID uuid.UUID) error {
+	r.entries = map[uuid.UUID]Entry{}
+	return nil
+}
+
+func TestEntryCompletion(t *testing.T) {
+	assert.Equal(t, 50.0, Entry{Position: 30, Duration: 60}.Completion())
+	assert.Equal(t, 100.0, Entry{Position: 61, Duration: 60}.Completion(), "capped at 100")
+	assert.Zero(t, Entry{Position: 30}.Completion(), "unknown duration")
+}
+
+func TestEntryResumeAt(t *testing.T) {
+	position, ok := Entry{Position: 30, Duration: 60}.ResumeAt()
+	assert.True(t, ok)
+	assert.Equal(t, 30.0, position)
+
+	_, ok = Entry{Position: 58, Duration: 60}.ResumeAt()
+	assert.False(t, ok, "watched to the end")
+
+	_, ok = Entry{Position: 0, Duration: 60}.ResumeAt()
+	assert.False(t, ok, "not started")
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	watchedAt := time.Date(2026, 3, 1, 12, 30, 0, 123000000, time.UTC)
+	decoded, err := DecodeCursor(EncodeCursor(watchedAt))
+	require.NoError(t, err)
+	assert.True(t, watchedAt.Equal(decoded))
+
+	_, err = DecodeCursor("yesterday")
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+}
+
+func TestRecordProgressValidation(t *testing.T) {
+	service := NewService(nil, &memoryRepository{entries: map[uuid.UUID]Entry{}})
+	ctx := context.Background()
+
+	_, err := service.RecordProgress(ctx, uuid.New(), uuid.New(), 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidDuration)
+
+	_, err = service.RecordProgress(ctx, uuid.New(), uuid.New(), -1, 60)
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+
+	_, err = service.RecordProgress(ctx, uuid.New(), uuid.New(), 61, 60)
+	assert.ErrorIs(t, err, ErrInvalidPosition)
+}
+
+func TestResumePosition(t *testing.T) {
+	userID, videoID := uuid.New(), uuid.New()
+	repo := &memoryRepository{entries: map[uuid.UUID]Entry{
+		videoID: {UserID: userID, VideoID: videoID, Position: 754.2, Duration: 1800},
+	}}
+	service := NewService(nil, repo)
+
+	position, ok, err := service.ResumePosition(context.Background(), userID, videoID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 754.2, position)
+
+	// Other users and unwatched videos have nothing to resume
+	_, ok, err = service.ResumePosition(context.Background(), uuid.New(), videoID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, service.Clear(context.Background(), userID))
+	_, ok, err = service.ResumePosition(context.Background(), userID, videoID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+// viewingRepository serves several users' recent videos for co-watch tests
+type viewingRepository struct {
+	memoryRepository
+	watched map[uuid.UUID][]uuid.UUID
+}
+
+func (r *viewingRepository) ListViewers(ctx context.Context, videoID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var viewers []uuid.UUID
+	for userID, videos := range r.watched {
+		for _, id := range videos {
+			if id == videoID {
+				viewers = append(viewers, userID)
+			}
+		}
+	}
+	return viewers, nil
+}
+
+func (r *viewingRepository) ListRecent(ctx context.Context, userID uuid.UUID, before time.Time, limit int) ([]Entry, error) {
+	var entries []Entry
+	for _, id := range r.watched[userID] {
+		entries = append(entries, Entry{UserID: userID, VideoID: id})
+	}
+	return entries, nil
+}
+
+func TestCoWatched(t *testing.T) {
+	videoID, both, once, unrelated := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	repo := &viewingRepository{watched: map[uuid.UUID][]uuid.UUID{
+		alice: {videoID, both, once},
+		bob:   {both, videoID},
+		carol: {unrelated},
+	}}
+	service := NewService(nil, repo)
+
+	counts, err := service.CoWatched(context.Background(), videoID)
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]int{both: 2, once: 1}, counts, "only viewers of the video count, and never the video itself")
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/gin-gonic/gin"
+)
+
+// CacheHeader reports whether a response was served from the response cache
+const CacheHeader = "X-Cache"
+
+// CacheConfig controls the shared cache of list responses
+type CacheConfig struct {
+	Enabled bool          `mapstructure:"enabled" doc:"Cache hot list responses in Redis, shared by every API instance"`
+	TTL     time.Duration `mapstructure:"ttl" doc:"How long a cached response is served; writes through the API invalidate it sooner"`
+}
+
+// ResponseCacheStore keeps cached responses shared by every API instance
+type ResponseCacheStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// ResponseCache serves repeated GET requests from Redis. Responses are cached
+// by group, such as "videos", and a successful write to a group invalidates
+// all of its responses at once by bumping the group's generation, which is
+// part of every key.
+type ResponseCache struct {
+	store  ResponseCacheStore
+	config CacheConfig
+	logger Logger
+}
+
+// NewResponseCache creates a response cache over the given store
+func NewResponseCache(store ResponseCacheStore, config CacheConfig, logger Logger) *ResponseCache {
+	return &ResponseCache{
+		store:  store,
+		config: config,
+		logger: logger,
+	}
+}
+
+// cachedResponse is a response as stored in the cache
+type cachedResponse struct {
+	Status       int    `json:"status"`
+	ContentType  string `json:"contentType"`
+	ETag         string `json:"etag,omitempty"`
+	CacheControl string `json:"cacheControl,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+// Cache returns middleware serving GET responses of the group from the cache
+// and storing successful ones on a miss. Responses are cached per user, since
+// listings can differ between them, so the middleware must run after
+// authentication. It does nothing when caching is disabled, and requests are
+// handled normally if Redis is unavailable.
+func (rc *ResponseCache) Cache(group string) gin.HandlerFunc {
+	if !rc.enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key, err := rc.key(ctx, group, c)
+		if err != nil {
+			rc.logger.LogError(err, "Response cache lookup failed; handling request")
+			c.Next()
+			return
+		}
+
+		raw, err := rc.store.Get(ctx, key)
+		if err == nil {
+			var cached cachedResponse
+			if err := json.Unmarshal([]byte(raw), &cached); err == nil {
+				cached.write(c)
+				c.Abort()
+				return
+			}
+		} else if !errors.Is(err, cache.ErrNotFound) {
+			rc.logger.LogError(err, "Response cache lookup failed; handling request")
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Header(CacheHeader, "MISS")
+		c.Next()
+
+		if recorder.Status() != http.StatusOK || recorder.body.Len() == 0 {
+			return
+		}
+		header := recorder.Header()
+		value, err := json.Marshal(cachedResponse{
+			Status:       recorder.Status(),
+			ContentType:  header.Get("Content-Type"),
+			ETag:         header.Get("ETag"),
+			CacheControl: header.Get("Cache-Control"),
+			Body:         recorder.body.Bytes(),
+		})
+		if err == nil {
+			err = rc.store.Set(context.WithoutCancel(ctx), key, value, rc.config.TTL)
+		}
+		if err != nil {
+			rc.logger.LogError(err, "Failed to cache response")
+		}
+	}
+}
+
+// Invalidate returns middleware dropping the cached responses of the groups
+// once a write has succeeded
+func (rc *ResponseCache) Invalidate(groups ...string) gin.HandlerFunc {
+	if !rc.enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		c.Next()
+
+		if status := c.Writer.Status(); status < 200 || status >= 300 {
+			return
+		}
+		ctx := context.WithoutCancel(c.Request.Context())
+		for _, group := range groups {
+			if _, err := rc.store.Incr(ctx, generationKey(group)); err != nil {
+				rc.logger.LogError(err, "Failed to invalidate cached responses")
+			}
+		}
+	}
+}
+
+// enabled reports whether responses are cached
+func (rc *ResponseCache) enabled() bool {
+	return rc != nil && rc.config.Enabled && rc.config.TTL > 0
+}
+
+// key names the cache entry of the request in the group's current generation
+func (rc *ResponseCache) key(ctx context.Context, group string, c *gin.Context) (string, error) {
+	generation, err := rc.store.Get(ctx, generationKey(group))
+	if errors.Is(err, cache.ErrNotFound) {
+		generation = "0"
+	} else if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(c.Request.URL.RequestURI() + "\n" + c.GetString("userID")))
+	return "httpcache:" + group + ":" + generation + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// generationKey names the counter invalidating a group's cached responses
+func generationKey(group string) string {
+	return "httpcache:" + group + ":generation"
+}
+
+// write sends the cached response, or 304 when the request already has it
+func (r *cachedResponse) write(c *gin.Context) {
+	c.Header(CacheHeader, "HIT")
+	if r.ETag != "" {
+		c.Header("ETag", r.ETag)
+	}
+	if r.CacheControl != "" {
+		c.Header("Cache-Control", r.CacheControl)
+	}
+	if r.ETag != "" && etagMatches(c.GetHeader("If-None-Match"), r.ETag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Header("Content-Length", strconv.Itoa(len(r.Body)))
+	c.Data(r.Status, r.ContentType, r.Body)
+}
+
+// responseRecorder copies the response body while it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// memoryStore keeps cached responses in a map and ignores TTLs
+type memoryStore struct {
+	values map[string]string
+	err    error
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return "", cache.ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	switch v := value.(type) {
+	case []byte:
+		s.values[key] = string(v)
+	default:
+		s.values[key] = fmt.Sprint(v)
+	}
+	return nil
+}
+
+func (s *memoryStore) Incr(ctx context.Context, key string) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	var n int64
+	fmt.Sscan(s.values[key], &n)
+	n++
+	s.values[key] = fmt.Sprint(n)
+	return n, nil
+}
+
+func newCacheRouter(t *testing.T, store ResponseCacheStore, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	responseCache := NewResponseCache(store, CacheConfig{Enabled: true, TTL: time.Minute}, log)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-User"); userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+	router.GET("/videos", responseCache.Cache("videos"), func(c *gin.Context) {
+		*calls++
+		if NotModified(c, ETag(*calls), CacheControlPrivate) {
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"calls": *calls})
+	})
+	router.POST("/videos", responseCache.Invalidate("videos"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/invalid", responseCache.Invalidate("videos"), func(c *gin.Context) { c.Status(http.StatusBadRequest) })
+	return router
+}
+
+func TestResponseCache(t *testing.T) {
+	request := func(router *gin.Engine, method, path, userID, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if userID != "" {
+			req.Header.Set("X-User", userID)
+		}
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Serves repeated requests from the cache", func(t *testing.T) {
+		calls := 0
+		router := newCacheRouter(t, &memoryStore{values: map[string]string{}}, &calls)
+
+		first := request(router, http.MethodGet, "/videos", "alice", "")
+		if got := first.Header().Get(CacheHeader); got != "MISS" {
+			t.Errorf("Expected %s MISS, got %q", CacheHeader, got)
+		}
+		second := request(router, http.MethodGet, "/videos", "alice", "")
+		if got := second.Header().Get(CacheHeader); got != "HIT" {
+			t.Errorf("Expected %s HIT, got %q", CacheHeader, got)
+		}
+		if calls != 1 {
+			t.Errorf("Expected the handler to run once, ran %d times", calls)
+		}
+		if second.Body.String() != first.Body.String() || second.Header().Get("ETag") != first.Header().Get("ETag") {
+			t.Errorf("Expected the cached response, got %q with ETag %q", second.Body.String(), second.Header().Get("ETag"))
+		}
+
+		if request(router, http.MethodGet, "/videos?page=2", "alice", ""); calls != 2 {
+			t.Errorf("Expected another query string to miss the cache")
+		}
+		if request(router, http.MethodGet, "/videos", "bob", ""); calls != 3 {
+			t.Errorf("Expected another user to miss the cache")
+		}
+	})
+
+	t.Run("Cached responses honor If-None-Match", func(t *testing.T) {
+		calls := 0
+		router := newCacheRouter(t, &memoryStore{values: map[string]string{}}, &calls)
+
+		etag := request(router, http.MethodGet, "/videos", "", "").Header().Get("ETag")
+		w := request(router, http.MethodGet, "/videos", "", etag)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("Expected status 304, got %d", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected no body, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("Successful writes invalidate the group", func(t *testing.T) {
+		calls := 0
+		router := newCacheRouter(t, &memoryStore{values: map[string]string{}}, &calls)
+
+		request(router, http.MethodGet, "/videos", "", "")
+		request(router, http.MethodPost, "/invalid", "", "")
+		if request(router, http.MethodGet, "/videos", "", ""); calls != 1 {
+			t.Errorf("Expected a failed write to keep the cache")
+		}
+
+		request(router, http.MethodPost, "/videos", "", "")
+		w := request(router, http.MethodGet, "/videos", "", "")
+		if calls != 2 || w.Header().Get(CacheHeader) != "MISS" {
+			t.Errorf("Expected a write to invalidate the cache, handler ran %d times", calls)
+		}
+	})
+
+	t.Run("Handles requests when the store fails", func(t *testing.T) {
+		calls := 0
+		router := newCacheRouter(t, &memoryStore{values: map[string]string{}, err: errors.New("connection refused")}, &calls)
+
+		for i := 0; i < 2; i++ {
+			if w := request(router, http.MethodGet, "/videos", "", ""); w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+		}
+		if calls != 2 {
+			t.Errorf("Expected the handler to run for every request, ran %d times", calls)
+		}
+	})
+}
+
+func TestNotModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	etag := ETag("video", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	for name, tc := range map[string]struct {
+		ifNoneMatch string
+		want        bool
+	}{
+		"no header":   {"", false},
+		"same tag":    {etag, true},
+		"strong form": {etag[2:], true},
+		"in a list":   {`"other", ` + etag, true},
+		"wildcard":    {"*", true},
+		"different":   {`W/"other"`, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/videos", nil)
+			if tc.ifNoneMatch != "" {
+				c.Request.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+
+			if got := NotModified(c, etag, CacheControlPublic); got != tc.want {
+				t.Errorf("Expected %v, got %v", tc.want, got)
+			}
+			if got := w.Header().Get("ETag"); got != etag {
+				t.Errorf("Expected ETag %s, got %q", etag, got)
+			}
+			if tc.want && w.Code != http.StatusNotModified {
+				t.Errorf("Expected status 304, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if ETag("video", updatedAt) != ETag("video", updatedAt.In(time.FixedZone("CET", 3600))) {
+		t.Error("Expected the same instant in another zone to give the same ETag")
+	}
+	if ETag("video", updatedAt) == ETag("video", updatedAt.Add(time.Millisecond)) {
+		t.Error("Expected a later update to change the ETag")
+	}
+	var missing *time.Time
+	if ETag("video", missing) != ETag("video", "") {
+		t.Error("Expected a nil time to format as empty")
+	}
+}
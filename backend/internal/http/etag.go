@@ -0,0 +1,73 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache-Control values for reads carrying an ETag. Both let clients keep the
+// response but make them revalidate it with If-None-Match before reuse.
+const (
+	// CacheControlPrivate is for responses that depend on the requesting user
+	CacheControlPrivate = "private, no-cache"
+	// CacheControlPublic is for responses that are the same for everyone
+	CacheControlPublic = "public, no-cache"
+)
+
+// ETag returns a weak entity tag for the representation identified by parts,
+// typically IDs and updated_at times, so that it changes whenever one of
+// them does. Times are compared by instant; other parts are formatted with %v.
+func ETag(parts ...interface{}) string {
+	h := sha256.New()
+	for _, part := range parts {
+		switch v := part.(type) {
+		case time.Time:
+			part = v.UTC().Format(time.RFC3339Nano)
+		case *time.Time:
+			part = ""
+			if v != nil {
+				part = v.UTC().Format(time.RFC3339Nano)
+			}
+		}
+		fmt.Fprintf(h, "%v|", part)
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// NotModified sets the ETag and Cache-Control headers of the response and
+// reports whether the request's If-None-Match already matches etag. When it
+// does, 304 has been written and the handler must not write a body.
+func NotModified(c *gin.Context, etag, cacheControl string) bool {
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", cacheControl)
+	if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for: any listed
+// tag equal to etag, ignoring the weak prefix, or "*" matches
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for a write it may retry
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response repeated for a retried key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength bounds keys; UUIDs, the usual choice, are 36
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyConfig controls the replay of writes retried with an Idempotency-Key
+type IdempotencyConfig struct {
+	Enabled bool          `mapstructure:"enabled" doc:"Replay the original response to writes retried with the same Idempotency-Key"`
+	TTL     time.Duration `mapstructure:"ttl" doc:"How long a key's response is kept for retries"`
+}
+
+// IdempotencyStore keeps idempotency keys and their responses, shared by
+// every API instance
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// SetNX stores value unless key exists, reporting whether it was stored
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Idempotency makes writes safe to retry. A client sends a key of its
+// choosing with a write; the first request with the key is handled and its
+// response stored, and repeats get the stored response instead of writing
+// again. Keys are scoped to the user.
+type Idempotency struct {
+	store           IdempotencyStore
+	config          IdempotencyConfig
+	responseHandler ResponseHandler
+	logger          Logger
+}
+
+// NewIdempotency creates idempotency key handling over the given store
+func NewIdempotency(store IdempotencyStore, config IdempotencyConfig, responseHandler ResponseHandler, logger Logger) *Idempotency {
+	return &Idempotency{
+		store:           store,
+		config:          config,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// idempotentResponse is a key's entry in the store: pending while the first
+// request is handled, then the response it got
+type idempotentResponse struct {
+	Pending bool `json:"pending,omitempty"`
+	// Fingerprint identifies the method, path and body the key was used with
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Keys returns middleware handling requests that carry an Idempotency-Key.
+// A repeat of a handled request gets its response with Idempotent-Replayed:
+// true. A repeat while the first request is still being handled gets 409
+// CONFLICT, and reusing a key for a different request gets 422
+// INVALID_REQUEST. Server errors and 429 responses are not stored, so the
+// request can be retried with the same key. The middleware must run after
+// authentication; requests without a key, and all requests when Redis is
+// unavailable, are handled normally.
+func (i *Idempotency) Keys() gin.HandlerFunc {
+	if !i.enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(IdempotencyKeyHeader)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			i.responseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidRequest,
+				"Idempotency-Key must be at most "+strconv.Itoa(maxIdempotencyKeyLength)+" characters", nil)
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key := idempotencyStoreKey(c, idempotencyKey)
+		pending, _ := json.Marshal(idempotentResponse{Pending: true})
+		claimed, err := i.store.SetNX(ctx, key, pending, i.config.TTL)
+		if err != nil {
+			i.logger.LogError(err, "Idempotency key lookup failed; handling request")
+			c.Next()
+			return
+		}
+		if !claimed {
+			i.repeat(c, key)
+			return
+		}
+
+		fingerprint := newFingerprint(c)
+		c.Request.Body = readCloser{io.TeeReader(c.Request.Body, fingerprint), c.Request.Body}
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// Handlers that reject a request early leave some of the body unread
+		io.Copy(io.Discard, c.Request.Body)
+
+		ctx = context.WithoutCancel(ctx)
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			if err := i.store.Delete(ctx, key); err != nil {
+				i.logger.LogError(err, "Failed to release idempotency key")
+			}
+			return
+		}
+		value, err := json.Marshal(idempotentResponse{
+			Fingerprint: fingerprint.sum(),
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err == nil {
+			err = i.store.Set(ctx, key, value, i.config.TTL)
+		}
+		if err != nil {
+			i.logger.LogError(err, "Failed to store idempotent response")
+		}
+	}
+}
+
+// repeat answers a request whose key was already used
+func (i *Idempotency) repeat(c *gin.Context, key string) {
+	raw, err := i.store.Get(c.Request.Context(), key)
+	if errors.Is(err, cache.ErrNotFound) {
+		// The first request failed and released the key in the meantime
+		i.conflict(c)
+		return
+	}
+	var stored idempotentResponse
+	if err == nil {
+		err = json.Unmarshal([]byte(raw), &stored)
+	}
+	if err != nil {
+		i.logger.LogError(err, "Idempotency key lookup failed; handling request")
+		c.Next()
+		return
+	}
+	if stored.Pending {
+		i.conflict(c)
+		return
+	}
+
+	fingerprint := newFingerprint(c)
+	io.Copy(fingerprint, c.Request.Body)
+	if fingerprint.sum() != stored.Fingerprint {
+		i.responseHandler.ErrorResponse(c, http.StatusUnprocessableEntity, apierror.CodeInvalidRequest,
+			"Idempotency-Key was already used for a different request", nil)
+		c.Abort()
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+	c.Abort()
+}
+
+// conflict answers a repeat of a request that is still being handled
+func (i *Idempotency) conflict(c *gin.Context) {
+	c.Header("Retry-After", "1")
+	i.responseHandler.ErrorResponse(c, http.StatusConflict, apierror.CodeConflict,
+		"A request with this Idempotency-Key is still being processed", nil)
+	c.Abort()
+}
+
+// enabled reports whether idempotency keys are handled
+func (i *Idempotency) enabled() bool {
+	return i != nil && i.config.Enabled && i.config.TTL > 0
+}
+
+// idempotencyStoreKey names the store entry of the requesting user's key
+func idempotencyStoreKey(c *gin.Context, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(c.GetString("userID") + "\n" + idempotencyKey))
+	return "idempotency:" + hex.EncodeToString(sum[:])
+}
+
+// fingerprint hashes a request's method, path and body as the body is read,
+// so large uploads are not held in memory. Clients choose a new multipart
+// boundary for every attempt, so boundaries are left out.
+type fingerprint struct {
+	hash     hash.Hash
+	body     io.Writer
+	boundary *boundaryStripper
+}
+
+func newFingerprint(c *gin.Context) *fingerprint {
+	f := &fingerprint{hash: sha256.New()}
+	io.WriteString(f.hash, c.Request.Method+" "+c.Request.URL.Path+"\n")
+	f.body = f.hash
+	if _, params, err := mime.ParseMediaType(c.GetHeader("Content-Type")); err == nil && params["boundary"] != "" {
+		f.boundary = &boundaryStripper{w: f.hash, boundary: []byte(params["boundary"])}
+		f.body = f.boundary
+	}
+	return f
+}
+
+func (f *fingerprint) Write(p []byte) (int, error) {
+	return f.body.Write(p)
+}
+
+func (f *fingerprint) sum() string {
+	if f.boundary != nil {
+		f.boundary.flush()
+	}
+	return hex.EncodeToString(f.hash.Sum(nil))
+}
+
+// boundaryStripper writes to w with every occurrence of boundary removed,
+// holding back the end of each write in case a boundary continues in the next
+type boundaryStripper struct {
+	w        io.Writer
+	boundary []byte
+	pending  []byte
+}
+
+func (s *boundaryStripper) Write(p []byte) (int, error) {
+	s.pending = bytes.ReplaceAll(append(s.pending, p...), s.boundary, nil)
+	if keep := len(s.boundary) - 1; len(s.pending) > keep {
+		if _, err := s.w.Write(s.pending[:len(s.pending)-keep]); err != nil {
+			return 0, err
+		}
+		s.pending = append(s.pending[:0], s.pending[len(s.pending)-keep:]...)
+	}
+	return len(p), nil
+}
+
+func (s *boundaryStripper) flush() {
+	s.w.Write(s.pending)
+	s.pending = nil
+}
+
+// readCloser reads from Reader and closes Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,211 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *memoryStore) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	if _, ok := s.values[key]; ok {
+		return false, nil
+	}
+	return true, s.Set(ctx, key, value, ttl)
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	if s.err != nil {
+		return s.err
+	}
+	delete(s.values, key)
+	return nil
+}
+
+// newIdempotencyRouter routes POST /comments to a handler answering with the
+// given status and counting its calls
+func newIdempotencyRouter(t *testing.T, store IdempotencyStore, status *int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	idempotency := NewIdempotency(store, IdempotencyConfig{Enabled: true, TTL: time.Hour}, NewResponseHandler(log), log)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", c.GetHeader("X-User"))
+		c.Next()
+	})
+	router.POST("/comments", idempotency.Keys(), func(c *gin.Context) {
+		*calls++
+		c.JSON(*status, gin.H{"call": *calls})
+	})
+	router.POST("/uploads", idempotency.Keys(), func(c *gin.Context) {
+		*calls++
+		if _, err := c.FormFile("video"); err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"call": *calls})
+	})
+	return router
+}
+
+func TestIdempotency(t *testing.T) {
+	post := func(router *gin.Engine, path, user, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("X-User", user)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Replays the response to a repeated key", func(t *testing.T) {
+		status, calls := http.StatusOK, 0
+		router := newIdempotencyRouter(t, &memoryStore{values: map[string]string{}}, &status, &calls)
+
+		first := post(router, "/comments", "alice", "key-1", `{"content":"hi"}`)
+		second := post(router, "/comments", "alice", "key-1", `{"content":"hi"}`)
+		if calls != 1 {
+			t.Errorf("Expected the handler to run once, ran %d times", calls)
+		}
+		if second.Code != first.Code || second.Body.String() != first.Body.String() {
+			t.Errorf("Expected the original response, got %d %q", second.Code, second.Body.String())
+		}
+		if got := second.Header().Get(IdempotentReplayedHeader); got != "true" {
+			t.Errorf("Expected %s true, got %q", IdempotentReplayedHeader, got)
+		}
+		if got := first.Header().Get(IdempotentReplayedHeader); got != "" {
+			t.Errorf("Expected no %s on the first response, got %q", IdempotentReplayedHeader, got)
+		}
+	})
+
+	t.Run("Scopes keys to the user", func(t *testing.T) {
+		status, calls := http.StatusOK, 0
+		router := newIdempotencyRouter(t, &memoryStore{values: map[string]string{}}, &status, &calls)
+
+		post(router, "/comments", "alice", "key-1", `{"content":"hi"}`)
+		if w := post(router, "/comments", "bob", "key-1", `{"content":"hi"}`); w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("Expected another user's key not to replay")
+		}
+		if calls != 2 {
+			t.Errorf("Expected the handler to run for each user, ran %d times", calls)
+		}
+	})
+
+	t.Run("Rejects a key reused for a different request", func(t *testing.T) {
+		status, calls := http.StatusOK, 0
+		router := newIdempotencyRouter(t, &memoryStore{values: map[string]string{}}, &status, &calls)
+
+		post(router, "/comments", "alice", "key-1", `{"content":"hi"}`)
+		w := post(router, "/comments", "alice", "key-1", `{"content":"bye"}`)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", w.Code)
+		}
+		if calls != 1 {
+			t.Errorf("Expected the handler to run once, ran %d times", calls)
+		}
+	})
+
+	t.Run("Conflicts while the first request is pending", func(t *testing.T) {
+		status, calls := http.StatusOK, 0
+		store := &memoryStore{values: map[string]string{}}
+		router := newIdempotencyRouter(t, store, &status, &calls)
+
+		req := httptest.NewRequest(http.MethodPost, "/comments", nil)
+		req.Header.Set("X-User", "alice")
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = req
+		c.Set("userID", "alice")
+		store.values[idempotencyStoreKey(c, "key-1")] = `{"pending":true}`
+
+		w := post(router, "/comments", "alice", "key-1", `{"content":"hi"}`)
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+		if calls != 0 {
+			t.Errorf("Expected the handler not to run, ran %d times", calls)
+		}
+	})
+
+	t.Run("Lets server errors be retried", func(t *testing.T) {
+		status, calls := http.StatusInternalServerError, 0
+		router := newIdempotencyRouter(t, &memoryStore{values: map[string]string{}}, &status, &calls)
+
+		post(router, "/comments", "alice", "key-1", `{"content":"hi"}`)
+		status = http.StatusOK
+		if w := post(router, "/comments", "alice", "key-1", `{"content":"hi"}`); w.Code != http.StatusOK || w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("Expected the retry to be handled, got %d", w.Code)
+		}
+		if calls != 2 {
+			t.Errorf("Expected the handler to run twice, ran %d times", calls)
+		}
+	})
+
+	t.Run("Ignores multipart boundaries", func(t *testing.T) {
+		status, calls := http.StatusOK, 0
+		router := newIdempotencyRouter(t, &memoryStore{values: map[string]string{}}, &status, &calls)
+
+		upload := func(content string) *httptest.ResponseRecorder {
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("video", "clip.mp4")
+			part.Write([]byte(content))
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/uploads", body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("X-User", "alice")
+			req.Header.Set(IdempotencyKeyHeader, "upload-1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		upload("frames")
+		if w := upload("frames"); w.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Errorf("Expected a retry with a new boundary to replay, got %d", w.Code)
+		}
+		if w := upload("other frames"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected another file to be rejected, got %d", w.Code)
+		}
+		if calls != 1 {
+			t.Errorf("Expected the handler to run once, ran %d times", calls)
+		}
+	})
+
+	t.Run("Handles requests without a key normally", func(t *testing.T) {
+		status, calls := http.StatusOK, 0
+		router := newIdempotencyRouter(t, &memoryStore{values: map[string]string{}}, &status, &calls)
+
+		post(router, "/comments", "alice", "", `{"content":"hi"}`)
+		post(router, "/comments", "alice", "", `{"content":"hi"}`)
+		if calls != 2 {
+			t.Errorf("Expected the handler to run twice, ran %d times", calls)
+		}
+	})
+
+	t.Run("Handles requests when Redis is down", func(t *testing.T) {
+		status, calls := http.StatusOK, 0
+		router := newIdempotencyRouter(t, &memoryStore{values: map[string]string{}, err: context.DeadlineExceeded}, &status, &calls)
+
+		if w := post(router, "/comments", "alice", "key-1", `{"content":"hi"}`); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+}
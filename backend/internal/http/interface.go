@@ -1,6 +1,7 @@
 package http
 
 import (
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,6 +10,7 @@ type ResponseHandler interface {
 	SuccessResponse(c *gin.Context, data interface{}, message string)
 	ErrorResponse(c *gin.Context, status int, code, message string, err error)
 	ValidationErrorResponse(c *gin.Context, field, message string)
+	FieldErrorsResponse(c *gin.Context, code, message string, fields []apierror.FieldError)
 	NotFoundResponse(c *gin.Context, message string)
 	UnauthorizedResponse(c *gin.Context, message string)
 	ForbiddenResponse(c *gin.Context, message string)
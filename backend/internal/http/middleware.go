@@ -1,34 +1,36 @@
 package http
 
 import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// CORSMiddleware handles Cross-Origin Resource Sharing (CORS)
-func CORSMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+// maxLoggedBody is how much of a request or response body is logged
+const maxLoggedBody = 4 << 10
 
-		c.Next()
-	}
-}
-
-// RequestLoggerMiddleware logs incoming HTTP requests
-func RequestLoggerMiddleware(logger Logger) gin.HandlerFunc {
+// RequestLoggerMiddleware logs incoming HTTP requests. With logBodies set,
+// JSON, form and text bodies of the request and response are logged too, up
+// to maxLoggedBody bytes each. Bodies hold passwords, tokens and user
+// content, so this is only meant for development.
+func RequestLoggerMiddleware(logger Logger, logBodies bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
 
+		var requestBody string
+		var response *bodyLogWriter
+		if logBodies {
+			requestBody = peekBody(c.Request)
+			response = &bodyLogWriter{ResponseWriter: c.Writer}
+			c.Writer = response
+		}
+
 		// Process request
 		c.Next()
 
@@ -40,11 +42,85 @@ func RequestLoggerMiddleware(logger Logger) gin.HandlerFunc {
 			"latency_ms": time.Since(start).Milliseconds(),
 			"client_ip":  c.ClientIP(),
 		}
+		if logBodies {
+			if requestBody != "" {
+				fields["request_body"] = requestBody
+			}
+			if loggableBody(c.Writer.Header().Get("Content-Type")) && response.body.Len() > 0 {
+				fields["response_body"] = truncateBody(response.body.Bytes(), response.truncated)
+			}
+		}
 
 		logger.LogInfo("HTTP Request", fields)
 	}
 }
 
+// peekBody returns the start of a loggable request body and puts what it
+// read back in front of the rest, so handlers still see the whole body
+func peekBody(r *http.Request) string {
+	if r.Body == nil || !loggableBody(r.Header.Get("Content-Type")) {
+		return ""
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, maxLoggedBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil || len(head) == 0 {
+		return ""
+	}
+	if len(head) > maxLoggedBody {
+		return truncateBody(head[:maxLoggedBody], true)
+	}
+	return string(head)
+}
+
+// loggableBody reports whether a body of the given content type is text
+// worth logging; uploads and streamed video are not
+func loggableBody(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" ||
+		mediaType == "application/x-www-form-urlencoded" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasPrefix(mediaType, "text/")
+}
+
+func truncateBody(body []byte, truncated bool) string {
+	if truncated {
+		return string(body) + "...(truncated)"
+	}
+	return string(body)
+}
+
+// bodyLogWriter keeps the first maxLoggedBody bytes of the response body
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *bodyLogWriter) Write(data []byte) (int, error) {
+	w.keep(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) keep(data []byte) {
+	room := maxLoggedBody - w.body.Len()
+	if len(data) > room {
+		data = data[:room]
+		w.truncated = true
+	}
+	w.body.Write(data)
+}
+
 // RecoveryMiddleware recovers from any panics and logs the error
 func RecoveryMiddleware(responseHandler ResponseHandler, logger Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -58,3 +134,17 @@ func RecoveryMiddleware(responseHandler ResponseHandler, logger Logger) gin.Hand
 		c.Next()
 	}
 }
+
+// RedirectToBasePath redirects requests for the unversioned paths of earlier
+// releases to the same path under basePath. 308 keeps the method and body, so
+// clients resend uploads and other writes unchanged.
+func RedirectToBasePath(basePath string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		target := *c.Request.URL
+		target.Path = basePath + c.Request.URL.Path
+		target.RawPath = ""
+
+		c.Header("Deprecation", "true")
+		c.Redirect(http.StatusPermanentRedirect, target.RequestURI())
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordingLogger keeps the fields of the last info entry
+type recordingLogger struct {
+	fields map[string]interface{}
+}
+
+func (l *recordingLogger) LogInfo(msg string, fields map[string]interface{}) {
+	l.fields = fields
+}
+
+func (l *recordingLogger) LogError(err error, msg string) error {
+	return err
+}
+
+func TestRequestLoggerMiddlewareBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(log *recordingLogger, logBodies bool, received *string) *gin.Engine {
+		router := gin.New()
+		router.Use(RequestLoggerMiddleware(log, logBodies))
+		router.POST("/echo", func(c *gin.Context) {
+			body, _ := io.ReadAll(c.Request.Body)
+			*received = string(body)
+			c.JSON(http.StatusOK, gin.H{"length": len(body)})
+		})
+		return router
+	}
+	post := func(router *gin.Engine, contentType, body string) {
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("Logs JSON bodies when enabled", func(t *testing.T) {
+		log := &recordingLogger{}
+		var received string
+		post(newRouter(log, true, &received), "application/json", `{"content":"hello"}`)
+
+		if received != `{"content":"hello"}` {
+			t.Errorf("Expected the handler to read the whole body, got %q", received)
+		}
+		if got := log.fields["request_body"]; got != `{"content":"hello"}` {
+			t.Errorf("Expected the request body to be logged, got %v", got)
+		}
+		if got := log.fields["response_body"]; got != `{"length":19}` {
+			t.Errorf("Expected the response body to be logged, got %v", got)
+		}
+	})
+
+	t.Run("Truncates long bodies", func(t *testing.T) {
+		log := &recordingLogger{}
+		var received string
+		body := `"` + strings.Repeat("a", 2*maxLoggedBody) + `"`
+		post(newRouter(log, true, &received), "application/json", body)
+
+		if received != body {
+			t.Errorf("Expected the handler to read all %d bytes, got %d", len(body), len(received))
+		}
+		logged, _ := log.fields["request_body"].(string)
+		if !strings.HasSuffix(logged, "...(truncated)") || len(logged) > maxLoggedBody+len("...(truncated)") {
+			t.Errorf("Expected a truncated body, got %d bytes", len(logged))
+		}
+	})
+
+	t.Run("Skips binary bodies", func(t *testing.T) {
+		log := &recordingLogger{}
+		var received string
+		post(newRouter(log, true, &received), "multipart/form-data; boundary=x", "--x--")
+
+		if _, ok := log.fields["request_body"]; ok {
+			t.Errorf("Expected no request body in the log, got %v", log.fields["request_body"])
+		}
+		if received != "--x--" {
+			t.Errorf("Expected the handler to read the body, got %q", received)
+		}
+	})
+
+	t.Run("Logs no bodies when disabled", func(t *testing.T) {
+		log := &recordingLogger{}
+		var received string
+		post(newRouter(log, false, &received), "application/json", `{"password":"secret"}`)
+
+		if _, ok := log.fields["request_body"]; ok {
+			t.Error("Expected no request body in the log")
+		}
+		if _, ok := log.fields["response_body"]; ok {
+			t.Error("Expected no response body in the log")
+		}
+	})
+}
+
+func TestRedirectToBasePath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/video/*path", RedirectToBasePath("/api/v1"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/video/123/comment?draft=true", nil))
+
+	if w.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected status %d, got %d", http.StatusPermanentRedirect, w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/api/v1/video/123/comment?draft=true" {
+		t.Errorf("unexpected Location %q", location)
+	}
+	if w.Header().Get("Deprecation") != "true" {
+		t.Error("expected a Deprecation header")
+	}
+}
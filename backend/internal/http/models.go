@@ -22,6 +22,8 @@ type APIError struct {
 	Message string `json:"message" example:"Invalid input parameters"`
 	// Optional field name for validation errors
 	Field string `json:"field,omitempty" example:"email"`
+	// Every field that failed validation
+	Fields []ValidationError `json:"fields,omitempty"`
 }
 
 // PaginationResponse represents a paginated response
@@ -45,7 +47,7 @@ type ValidationError struct {
 	// Validation error message
 	Message string `json:"message" example:"Email is required"`
 	// Validation rule that failed
-	Rule string `json:"rule" example:"required"`
+	Rule string `json:"rule,omitempty" example:"required"`
 }
 
 // HealthResponse represents the health check response
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// countingStore allows capacity requests per key and never refills
+type countingStore struct {
+	taken map[string]int
+	err   error
+}
+
+func (s *countingStore) TakeToken(ctx context.Context, key string, capacity int, period time.Duration) (*cache.TokenBucketResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.taken[key]++
+	remaining := capacity - s.taken[key]
+	if remaining < 0 {
+		return &cache.TokenBucketResult{Limit: capacity, RetryAfter: 1500 * time.Millisecond, ResetAfter: period}, nil
+	}
+	return &cache.TokenBucketResult{Allowed: true, Limit: capacity, Remaining: remaining, ResetAfter: period}, nil
+}
+
+func newRateLimitRouter(t *testing.T, store RateLimitStore, userID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	limiter := NewRateLimiter(store, RateLimitConfig{
+		Enabled: true,
+		Rules: map[string]RateLimitRule{
+			"login": {Requests: 2, Period: time.Minute},
+		},
+	}, NewResponseHandler(log), log)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+	router.POST("/login", limiter.Limit("login"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/unlimited", limiter.Limit("missing"), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestRateLimiter(t *testing.T) {
+	post := func(router *gin.Engine, path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Blocks after the limit with headers", func(t *testing.T) {
+		router := newRateLimitRouter(t, &countingStore{taken: map[string]int{}}, "")
+
+		w := post(router, "/login", "10.0.0.1")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != "1" {
+			t.Errorf("Expected RateLimit-Remaining 1, got %q", got)
+		}
+		if got := w.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("Expected RateLimit-Policy 2;w=60, got %q", got)
+		}
+
+		post(router, "/login", "10.0.0.1")
+		w = post(router, "/login", "10.0.0.1")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected status 429, got %d", w.Code)
+		}
+		if got := w.Header().Get("Retry-After"); got != "2" {
+			t.Errorf("Expected Retry-After 2, got %q", got)
+		}
+
+		// Other clients have their own bucket
+		if w := post(router, "/login", "10.0.0.2"); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for another IP, got %d", w.Code)
+		}
+	})
+
+	t.Run("Authenticated users are limited by user", func(t *testing.T) {
+		store := &countingStore{taken: map[string]int{}}
+		router := newRateLimitRouter(t, store, "user-1")
+
+		post(router, "/login", "10.0.0.1")
+		post(router, "/login", "10.0.0.2")
+		if store.taken["ratelimit:login:user:user-1"] != 2 {
+			t.Errorf("Expected both requests counted against the user, got %v", store.taken)
+		}
+	})
+
+	t.Run("Unknown rules are not limited", func(t *testing.T) {
+		store := &countingStore{taken: map[string]int{}}
+		router := newRateLimitRouter(t, store, "")
+
+		for i := 0; i < 5; i++ {
+			if w := post(router, "/unlimited", "10.0.0.1"); w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+		}
+		if len(store.taken) != 0 {
+			t.Errorf("Expected no tokens taken, got %v", store.taken)
+		}
+	})
+
+	t.Run("Forwarded addresses need a trusted proxy", func(t *testing.T) {
+		forwarded := func(router *gin.Engine, forwardedFor string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		// With the default of no trusted proxies, a spoofed header does not
+		// get a fresh bucket
+		store := &countingStore{taken: map[string]int{}}
+		router := newRateLimitRouter(t, store, "")
+		if err := router.SetTrustedProxies([]string{}); err != nil {
+			t.Fatalf("SetTrustedProxies failed: %v", err)
+		}
+		forwarded(router, "203.0.113.1")
+		forwarded(router, "203.0.113.2")
+		if w := forwarded(router, "203.0.113.3"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status 429 with a spoofed X-Forwarded-For, got %d", w.Code)
+		}
+		if store.taken["ratelimit:login:ip:10.0.0.1"] != 3 {
+			t.Errorf("Expected every request counted against the connection's IP, got %v", store.taken)
+		}
+
+		// Behind a trusted proxy, clients are told apart by the header
+		store = &countingStore{taken: map[string]int{}}
+		router = newRateLimitRouter(t, store, "")
+		if err := router.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+			t.Fatalf("SetTrustedProxies failed: %v", err)
+		}
+		forwarded(router, "203.0.113.1")
+		if w := forwarded(router, "203.0.113.2"); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200 for another client behind the proxy, got %d", w.Code)
+		}
+		if store.taken["ratelimit:login:ip:203.0.113.1"] != 1 || store.taken["ratelimit:login:ip:203.0.113.2"] != 1 {
+			t.Errorf("Expected a bucket per forwarded client, got %v", store.taken)
+		}
+	})
+
+	t.Run("Store errors let requests through", func(t *testing.T) {
+		router := newRateLimitRouter(t, &countingStore{err: errors.New("redis down")}, "")
+
+		if w := post(router, "/login", "10.0.0.1"); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"net/http"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/gin-gonic/gin"
 )
 
@@ -49,20 +50,37 @@ func (h *responseHandler) ValidationErrorResponse(c *gin.Context, field, message
 	response := Response{
 		Success: false,
 		Error: &Error{
-			Code:    "VALIDATION_ERROR",
+			Code:    apierror.CodeValidation,
 			Message: message,
 			Field:   field,
+			Fields:  []apierror.FieldError{{Field: field, Message: message}},
 		},
 	}
 	c.JSON(http.StatusBadRequest, response)
 }
 
+// FieldErrorsResponse sends a validation error response listing every offending field
+func (h *responseHandler) FieldErrorsResponse(c *gin.Context, code, message string, fields []apierror.FieldError) {
+	response := Response{
+		Success: false,
+		Error: &Error{
+			Code:    code,
+			Message: message,
+			Fields:  fields,
+		},
+	}
+	if len(fields) > 0 {
+		response.Error.Field = fields[0].Field
+	}
+	c.JSON(apierror.Status(code), response)
+}
+
 // NotFoundResponse sends a not found error response
 func (h *responseHandler) NotFoundResponse(c *gin.Context, message string) {
 	response := Response{
 		Success: false,
 		Error: &Error{
-			Code:    "NOT_FOUND",
+			Code:    apierror.CodeNotFound,
 			Message: message,
 		},
 	}
@@ -74,7 +92,7 @@ func (h *responseHandler) UnauthorizedResponse(c *gin.Context, message string) {
 	response := Response{
 		Success: false,
 		Error: &Error{
-			Code:    "UNAUTHORIZED",
+			Code:    apierror.CodeUnauthorized,
 			Message: message,
 		},
 	}
@@ -86,7 +104,7 @@ func (h *responseHandler) ForbiddenResponse(c *gin.Context, message string) {
 	response := Response{
 		Success: false,
 		Error: &Error{
-			Code:    "FORBIDDEN",
+			Code:    apierror.CodeForbidden,
 			Message: message,
 		},
 	}
@@ -102,7 +120,7 @@ func (h *responseHandler) InternalErrorResponse(c *gin.Context, message string,
 	response := Response{
 		Success: false,
 		Error: &Error{
-			Code:    "INTERNAL_ERROR",
+			Code:    apierror.CodeInternal,
 			Message: message,
 		},
 	}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig controls which browser origins may call the API
+type CORSConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowedOrigins" doc:"Origins allowed to call the API from a browser, e.g. https://app.example.com; \"*\" allows any origin"`
+	AllowedMethods   []string      `mapstructure:"allowedMethods" doc:"Methods allowed in cross-origin requests"`
+	AllowedHeaders   []string      `mapstructure:"allowedHeaders" doc:"Request headers allowed in cross-origin requests"`
+	ExposedHeaders   []string      `mapstructure:"exposedHeaders" doc:"Response headers browsers let cross-origin scripts read"`
+	AllowCredentials bool          `mapstructure:"allowCredentials" doc:"Let browsers send cookies and HTTP auth with cross-origin requests"`
+	MaxAge           time.Duration `mapstructure:"maxAge" doc:"How long browsers may cache a preflight response"`
+}
+
+// SecurityHeadersConfig controls the security headers sent with every response
+type SecurityHeadersConfig struct {
+	HSTSMaxAge time.Duration `mapstructure:"hstsMaxAge" doc:"max-age of Strict-Transport-Security; 0 omits the header, e.g. when serving plain HTTP"`
+	SwaggerCSP string        `mapstructure:"swaggerCSP" doc:"Content-Security-Policy of the Swagger UI pages"`
+}
+
+// allowsOrigin reports whether a browser on origin may call the API
+func (cfg CORSConfig) allowsOrigin(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddleware handles Cross-Origin Resource Sharing (CORS). Requests from
+// allowed origins get the CORS headers; preflight requests are answered here,
+// and rejected with 403 for other origins.
+func CORSMiddleware(cfg CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge / time.Second))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		c.Writer.Header().Add("Vary", "Origin")
+		if !cfg.allowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Credentials cannot be combined with a wildcard origin, so the origin is echoed
+		c.Header("Access-Control-Allow-Origin", origin)
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposed != "" {
+			c.Header("Access-Control-Expose-Headers", exposed)
+		}
+		c.Next()
+	}
+}
+
+// SecurityHeadersMiddleware sets the security headers every response carries
+func SecurityHeadersMiddleware(cfg SecurityHeadersConfig) gin.HandlerFunc {
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int(cfg.HSTSMaxAge/time.Second))
+	}
+
+	return func(c *gin.Context) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		if hsts != "" {
+			c.Header("Strict-Transport-Security", hsts)
+		}
+		c.Next()
+	}
+}
+
+// ContentSecurityPolicy sets the Content-Security-Policy of HTML pages such
+// as the Swagger UI. API responses are JSON and do not need one.
+func ContentSecurityPolicy(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy != "" {
+			c.Header("Content-Security-Policy", policy)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSecurityRouter(cors CORSConfig, headers SecurityHeadersConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware(cors), SecurityHeadersMiddleware(headers))
+	router.GET("/videos", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/swagger/*any", ContentSecurityPolicy("default-src 'self'"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serve(router *gin.Engine, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	router := newSecurityRouter(CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"ETag"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}, SecurityHeadersConfig{})
+
+	t.Run("allowed origin", func(t *testing.T) {
+		w := serve(router, "GET", "/videos", map[string]string{"Origin": "https://app.example.com"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "ETag", w.Header().Get("Access-Control-Expose-Headers"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		w := serve(router, "GET", "/videos", map[string]string{"Origin": "https://evil.example.com"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		w := serve(router, "OPTIONS", "/videos", map[string]string{
+			"Origin":                        "https://app.example.com",
+			"Access-Control-Request-Method": "POST",
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("preflight from other origin", func(t *testing.T) {
+		w := serve(router, "OPTIONS", "/videos", map[string]string{
+			"Origin":                        "https://evil.example.com",
+			"Access-Control-Request-Method": "POST",
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("wildcard without credentials", func(t *testing.T) {
+		router := newSecurityRouter(CORSConfig{AllowedOrigins: []string{"*"}}, SecurityHeadersConfig{})
+		w := serve(router, "GET", "/videos", map[string]string{"Origin": "https://any.example.com"})
+		assert.Equal(t, "https://any.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	router := newSecurityRouter(CORSConfig{}, SecurityHeadersConfig{HSTSMaxAge: 24 * time.Hour})
+
+	w := serve(router, "GET", "/videos", nil)
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "max-age=86400; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+
+	w = serve(router, "GET", "/swagger/index.html", nil)
+	assert.Equal(t, "default-src 'self'", w.Header().Get("Content-Security-Policy"))
+
+	// A zero max-age omits HSTS
+	router = newSecurityRouter(CORSConfig{}, SecurityHeadersConfig{})
+	w = serve(router, "GET", "/videos", nil)
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+}
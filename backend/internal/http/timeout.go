@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultRouteTimeout is the timeouts entry for routes without their own entry
+const DefaultRouteTimeout = "default"
+
+// TimeoutMiddleware gives each request a context deadline. Timeouts are keyed
+// by route pattern relative to the API's basePath, e.g. "POST /video/:id", with
+// "default" covering every other route; a zero or missing timeout leaves the
+// request unbounded. Handlers that pass the request context on stop once it
+// expires, and a request that has not written a response by then gets 504.
+func TimeoutMiddleware(timeouts map[string]time.Duration, basePath string, responseHandler ResponseHandler) gin.HandlerFunc {
+	// Viper lowercases map keys, so match routes case-insensitively
+	byRoute := make(map[string]time.Duration, len(timeouts))
+	for route, timeout := range timeouts {
+		byRoute[strings.ToLower(route)] = timeout
+	}
+
+	return func(c *gin.Context) {
+		route := strings.TrimPrefix(c.FullPath(), basePath)
+		timeout, ok := byRoute[strings.ToLower(c.Request.Method+" "+route)]
+		if !ok {
+			timeout = byRoute[DefaultRouteTimeout]
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			responseHandler.ErrorResponse(c, http.StatusGatewayTimeout, apierror.CodeRequestTimeout,
+				"The request did not complete in time", ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(TimeoutMiddleware(map[string]time.Duration{
+		DefaultRouteTimeout:     10 * time.Millisecond,
+		"post /video/upload":    0,
+		"GET /video/:id/status": time.Hour,
+	}, "/api/v1", NewResponseHandler(log)))
+
+	// Waits for the request context unless it has no deadline
+	wait := func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); !ok {
+			c.Status(http.StatusOK)
+			return
+		}
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(50 * time.Millisecond):
+			c.Status(http.StatusOK)
+		}
+	}
+	api := router.Group("/api/v1")
+	api.GET("/videos", wait)
+	api.POST("/video/upload", wait)
+	api.GET("/video/:id/status", wait)
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/v1/videos", http.StatusGatewayTimeout},
+		{http.MethodPost, "/api/v1/video/upload", http.StatusOK},
+		{http.MethodGet, "/api/v1/video/123/status", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.want, w.Code)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package http
 
+import "github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+
 // Response represents a standard API response structure
 type Response struct {
 	Success bool        `json:"success"`
@@ -13,6 +15,8 @@ type Error struct {
 	Code    string `json:"code,omitempty"`
 	Message string `json:"message,omitempty"`
 	Field   string `json:"field,omitempty"`
+	// Fields lists every field that failed validation
+	Fields []apierror.FieldError `json:"fields,omitempty"`
 }
 
 // PaginatedResponse represents a paginated API response
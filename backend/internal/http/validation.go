@@ -0,0 +1,182 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// Request structs are validated by gin with go-playground/validator, using
+// their binding tags. Besides the built-in rules, every handler can use:
+//
+//   - uuid on uuid.UUID fields, which also makes required reject the nil UUID
+//   - enum on values whose type has an IsValid() bool method
+//
+// Packages add rules of their own with RegisterValidation.
+
+// enum is implemented by the API's enumerated string types
+type enum interface {
+	IsValid() bool
+}
+
+var (
+	// messagesMu guards messages
+	messagesMu sync.RWMutex
+	// messages holds the error message of each custom rule, without the field name
+	messages = map[string]string{
+		"enum": "is not a valid value",
+	}
+)
+
+func init() {
+	v := engine()
+	v.RegisterTagNameFunc(fieldName)
+	v.RegisterCustomTypeFunc(uuidValue, uuid.UUID{})
+	if err := v.RegisterValidation("enum", isValidEnum); err != nil {
+		panic(err)
+	}
+}
+
+// engine returns the validator gin binds requests with
+func engine() *validator.Validate {
+	return binding.Validator.Engine().(*validator.Validate)
+}
+
+// RegisterValidation adds a custom rule usable in binding tags. message
+// follows the field name in error responses, e.g. "must be a supported video
+// file". Rules are global, so registering a tag again replaces it.
+func RegisterValidation(tag, message string, fn validator.Func) error {
+	if err := engine().RegisterValidation(tag, fn); err != nil {
+		return fmt.Errorf("register validation %q: %w", tag, err)
+	}
+
+	messagesMu.Lock()
+	messages[tag] = message
+	messagesMu.Unlock()
+	return nil
+}
+
+// Bind decodes the JSON request body into obj and validates it. Errors are
+// *apierror.Error values ready for apierror.Abort: a malformed body is
+// INVALID_REQUEST, and failed rules are VALIDATION_ERROR listing every
+// offending field.
+func Bind(c *gin.Context, obj interface{}) error {
+	return BindWith(c, obj, binding.JSON)
+}
+
+// BindWith is Bind for other encodings, e.g. binding.FormMultipart
+func BindWith(c *gin.Context, obj interface{}, b binding.Binding) error {
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		return BindError(err)
+	}
+	return nil
+}
+
+// BindError converts an error from binding or validating a request into the
+// error shown to clients
+func BindError(err error) *apierror.Error {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		fields := make([]apierror.FieldError, 0, len(invalid))
+		for _, fe := range invalid {
+			fields = append(fields, apierror.FieldError{
+				Field:   fe.Field(),
+				Message: fe.Field() + " " + ruleMessage(fe),
+				Rule:    fe.Tag(),
+			})
+		}
+		return apierror.Invalid(fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return apierror.Invalid(apierror.FieldError{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type),
+			Rule:    "type",
+		})
+	}
+
+	return apierror.Wrap(err, apierror.CodeInvalidRequest, "Invalid request format")
+}
+
+// ruleMessage describes the rule a field failed, without the field name
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "uuid":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min", "gte":
+		return boundMessage("at least", fe)
+	case "max", "lte":
+		return boundMessage("at most", fe)
+	case "gt":
+		return boundMessage("more than", fe)
+	case "lt":
+		return boundMessage("less than", fe)
+	case "len":
+		return boundMessage("exactly", fe)
+	}
+
+	messagesMu.RLock()
+	message, ok := messages[fe.Tag()]
+	messagesMu.RUnlock()
+	if ok {
+		return message
+	}
+	return "is invalid"
+}
+
+// boundMessage describes a size rule in the unit of the field's kind
+func boundMessage(comparison string, fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fmt.Sprintf("must be %s %s characters", comparison, fe.Param())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("must have %s %s items", comparison, fe.Param())
+	default:
+		return fmt.Sprintf("must be %s %s", comparison, fe.Param())
+	}
+}
+
+// fieldName names fields by their JSON or form key, as clients know them
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name := strings.Split(field.Tag.Get(key), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// uuidValue validates uuid.UUID fields as strings, with the nil UUID empty
+func uuidValue(field reflect.Value) interface{} {
+	id, ok := field.Interface().(uuid.UUID)
+	if !ok || id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+// isValidEnum implements the enum rule
+func isValidEnum(fl validator.FieldLevel) bool {
+	value, ok := fl.Field().Interface().(enum)
+	return ok && value.IsValid()
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testColor string
+
+func (c testColor) IsValid() bool {
+	return c == "red" || c == "blue"
+}
+
+type bindTestRequest struct {
+	Name    string      `json:"name" binding:"required,max=5"`
+	OwnerID uuid.UUID   `json:"owner_id" binding:"required,uuid"`
+	Color   testColor   `json:"color" binding:"omitempty,enum"`
+	Tags    []string    `json:"tags" binding:"max=2"`
+	Colors  []testColor `json:"colors" binding:"dive,enum"`
+	Count   int         `json:"count" binding:"min=1"`
+	Even    int         `json:"even" binding:"omitempty,even"`
+}
+
+func bind(t *testing.T, body string) error {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+
+	var req bindTestRequest
+	return Bind(c, &req)
+}
+
+func TestBind(t *testing.T) {
+	require.NoError(t, RegisterValidation("even", "must be even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}))
+
+	owner := uuid.New().String()
+	assert.NoError(t, bind(t, `{"name":"abc","owner_id":"`+owner+`","color":"red","colors":["blue"],"count":1,"even":2}`))
+
+	err := bind(t, `{"name":"abcdef","owner_id":"`+uuid.Nil.String()+`","color":"green","tags":["a","b","c"],"colors":["red","pink"],"even":3}`)
+	var apiErr *apierror.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, apierror.CodeValidation, apiErr.Code)
+	assert.Equal(t, "name must be at most 5 characters", apiErr.Message)
+	assert.Equal(t, []apierror.FieldError{
+		{Field: "name", Message: "name must be at most 5 characters", Rule: "max"},
+		{Field: "owner_id", Message: "owner_id is required", Rule: "required"},
+		{Field: "color", Message: "color is not a valid value", Rule: "enum"},
+		{Field: "tags", Message: "tags must have at most 2 items", Rule: "max"},
+		{Field: "colors[1]", Message: "colors[1] is not a valid value", Rule: "enum"},
+		{Field: "count", Message: "count must be at least 1", Rule: "min"},
+		{Field: "even", Message: "even must be even", Rule: "even"},
+	}, apiErr.Fields)
+
+	// Wrong JSON types name the field
+	require.ErrorAs(t, bind(t, `{"name":"abc","owner_id":"`+owner+`","count":"one"}`), &apiErr)
+	assert.Equal(t, []apierror.FieldError{{Field: "count", Message: "count must be of type int", Rule: "type"}}, apiErr.Fields)
+
+	// Malformed bodies are not validation errors
+	require.ErrorAs(t, bind(t, `{"name":`), &apiErr)
+	assert.Equal(t, apierror.CodeInvalidRequest, apiErr.Code)
+	assert.Empty(t, apiErr.Fields)
+}
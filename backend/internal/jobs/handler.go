@@ -0,0 +1,176 @@
+package jobs
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for the job admin endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new job handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the job routes behind the authentication and
+// admin role middlewares
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, adminMiddleware gin.HandlerFunc) {
+	jobs := router.Group("/admin/jobs")
+	jobs.Use(authMiddleware, adminMiddleware)
+	{
+		jobs.GET("", h.handleList)
+		jobs.GET("/schedules", h.handleListSchedules)
+		jobs.GET("/:id", h.handleGet)
+		jobs.POST("/:id/cancel", h.handleCancel)
+	}
+}
+
+// @Summary List background jobs
+// @Description Get a page of background jobs, newest first, with their status, attempts and last error. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Only jobs in this status" Enums(pending, running, succeeded, failed, cancelled)
+// @Param type query string false "Only jobs of this type"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=ListResponse} "Jobs retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid status"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/jobs [get]
+func (h *Handler) handleList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter := ListFilter{Status: Status(c.Query("status")), Type: c.Query("type")}
+	list, err := h.service.List(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve jobs")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Jobs retrieved successfully")
+}
+
+// @Summary List job schedules
+// @Description Get the recurring job schedules with their cron spec, next run and last queued job. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=[]Schedule} "Schedules retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/jobs/schedules [get]
+func (h *Handler) handleListSchedules(c *gin.Context) {
+	schedules, err := h.service.ListSchedules(c.Request.Context())
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve job schedules")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, schedules, "Job schedules retrieved successfully")
+}
+
+// @Summary Get a background job
+// @Description Get a background job with its payload, attempts and last error. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=Job} "Job retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid job ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Job not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/jobs/{id} [get]
+func (h *Handler) handleGet(c *gin.Context) {
+	jobID, ok := h.getJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.Get(c.Request.Context(), jobID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve job")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, job, "Job retrieved successfully")
+}
+
+// @Summary Cancel a background job
+// @Description Stop a pending or running job from running again. A running attempt is interrupted; handlers that ignore their context finish the attempt, but its outcome is not recorded. Admins only.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=Job} "Job cancelled"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid job ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Job not found"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Job has already finished"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /admin/jobs/{id}/cancel [post]
+func (h *Handler) handleCancel(c *gin.Context) {
+	jobID, ok := h.getJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.Cancel(c.Request.Context(), jobID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to cancel job")
+		return
+	}
+
+	h.logger.LogInfo("Job cancelled", map[string]interface{}{
+		"job_id": job.ID,
+		"type":   job.Type,
+	})
+	h.responseHandler.SuccessResponse(c, job, "Job cancelled successfully")
+}
+
+// getJobID parses the job ID path parameter, writing an error response when it is invalid
+func (h *Handler) getJobID(c *gin.Context) (uuid.UUID, bool) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid job ID", err)
+		return uuid.Nil, false
+	}
+	return jobID, true
+}
+
+// handleServiceError maps job service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidStatus):
+		h.responseHandler.ValidationErrorResponse(c, "status", err.Error())
+	case errors.Is(err, ErrJobNotFound):
+		h.responseHandler.NotFoundResponse(c, "Job not found")
+	case errors.Is(err, ErrJobFinished):
+		h.responseHandler.ErrorResponse(c, http.StatusConflict, apierror.CodeConflict, err.Error(), err)
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, message, err)
+	}
+}
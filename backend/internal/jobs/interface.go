@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrJobNotFound is returned when no job has the given ID
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is returned when cancelling a job that already finished
+	ErrJobFinished = errors.New("job has already finished")
+	// ErrUnknownType is returned when enqueuing a job type no handler is registered for
+	ErrUnknownType = errors.New("no handler is registered for this job type")
+	// ErrInvalidStatus is returned when filtering jobs by an unknown status
+	ErrInvalidStatus = errors.New("status must be one of pending, running, succeeded, failed and cancelled")
+)
+
+// Func runs one attempt of a job. The context is cancelled when the attempt
+// times out, the job is cancelled or the runner stops, so handlers doing
+// long work should watch it. Returning an error retries the job with backoff
+// until its attempts are used up; wrap it with Permanent to fail at once.
+type Func func(ctx context.Context, job *Job) error
+
+// Service defines the interface for queuing and inspecting jobs
+type Service interface {
+	// Enqueue queues a job of a registered type to run as soon as a worker is free
+	Enqueue(ctx context.Context, jobType string, payload interface{}) (*Job, error)
+	// List returns a page of jobs, newest first
+	List(ctx context.Context, filter ListFilter, page, limit int) (*ListResponse, error)
+	// Get returns a job
+	Get(ctx context.Context, id uuid.UUID) (*Job, error)
+	// Cancel stops a pending or running job from running again and interrupts a running attempt
+	Cancel(ctx context.Context, id uuid.UUID) (*Job, error)
+	// ListSchedules returns the recurring schedules by name
+	ListSchedules(ctx context.Context) ([]Schedule, error)
+}
+
+// permanentError marks an error that should not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails without further attempts, such as
+// when its payload is invalid
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the state of a job
+type Status string
+
+const (
+	// StatusPending jobs are waiting for their first or next attempt
+	StatusPending Status = "pending"
+	// StatusRunning jobs have been claimed by a worker
+	StatusRunning Status = "running"
+	// StatusSucceeded jobs finished without error
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed jobs ran out of attempts or failed permanently
+	StatusFailed Status = "failed"
+	// StatusCancelled jobs were cancelled before they finished
+	StatusCancelled Status = "cancelled"
+)
+
+// IsValid reports whether s is a known status
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusPending, StatusRunning, StatusSucceeded, StatusFailed, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Finished reports whether a job in this status will not run again
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+// Job is a unit of background work, kept in the database so it survives restarts
+type Job struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type string    `gorm:"type:text;not null;index" json:"type" example:"jobs.prune"`
+	// Payload is the job's JSON input, handed to its handler as is
+	Payload     string `gorm:"type:text;not null;default:'{}'" json:"payload" example:"{}"`
+	Status      Status `gorm:"type:text;not null;index:idx_jobs_due" json:"status" example:"succeeded"`
+	Attempts    int    `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int    `gorm:"not null;default:1" json:"max_attempts" example:"5"`
+	LastError   string `gorm:"type:text" json:"last_error,omitempty"`
+	// Schedule names the recurring schedule that queued the job, if any
+	Schedule string `gorm:"type:text" json:"schedule,omitempty" example:"jobs.prune"`
+	// RunAt is when the job's next attempt is due
+	RunAt      time.Time  `gorm:"not null;default:now();index:idx_jobs_due" json:"run_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Job model
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// Decode unmarshals the job's payload into v
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(j.Payload), v)
+}
+
+// Schedule queues a job of its type every time its cron spec comes due. The
+// row is shared by every instance, so each run is queued once.
+type Schedule struct {
+	Name string `gorm:"type:text;primary_key" json:"name" example:"jobs.prune"`
+	// Spec is a five-field cron expression in UTC, or a descriptor such as @daily
+	Spec      string     `gorm:"type:text;not null" json:"spec" example:"@daily"`
+	Type      string     `gorm:"type:text;not null" json:"type" example:"jobs.prune"`
+	Payload   string     `gorm:"type:text;not null;default:'{}'" json:"payload" example:"{}"`
+	NextRunAt time.Time  `gorm:"not null" json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	LastJobID *uuid.UUID `gorm:"type:uuid" json:"last_job_id,omitempty"`
+	CreatedAt time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Schedule model
+func (Schedule) TableName() string {
+	return "job_schedules"
+}
+
+// ListFilter narrows a job listing; empty fields match every job
+type ListFilter struct {
+	Status Status
+	Type   string
+}
+
+// ListResponse represents a paginated list of jobs
+type ListResponse struct {
+	Jobs  []Job `json:"jobs"`
+	Total int64 `json:"total"`
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+}
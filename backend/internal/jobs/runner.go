@@ -0,0 +1,495 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Built-in job types and schedules
+const (
+	// TypePrune deletes finished jobs older than the retention period
+	TypePrune = "jobs.prune"
+	// PruneSchedule queues TypePrune every day
+	PruneSchedule = "jobs.prune"
+)
+
+// Config represents background job settings
+type Config struct {
+	// Workers is how many jobs run concurrently on this instance
+	Workers int
+	// PollInterval is how often due jobs and schedules are checked, and how
+	// often running jobs report that they are alive
+	PollInterval time.Duration
+	// MaxAttempts is how many times a job is tried before it is marked failed
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles with every further attempt
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the wait between attempts
+	MaxRetryDelay time.Duration
+	// Timeout bounds each attempt
+	Timeout time.Duration
+	// Retention is how long finished jobs are kept; 0 keeps them
+	Retention time.Duration
+}
+
+// registeredSchedule is a schedule registered in code, written to the
+// schedule table when the runner starts
+type registeredSchedule struct {
+	Schedule
+	cron *Cron
+}
+
+// Runner runs queued jobs on a pool of workers, retrying failures with
+// exponential backoff, and queues the jobs of recurring schedules. Jobs are
+// kept in the database, so jobs queued before a restart run once a runner
+// starts again, and several instances can share the queue.
+type Runner struct {
+	db        *gorm.DB
+	config    Config
+	logger    logger.Logger
+	handlers  map[string]Func
+	schedules []registeredSchedule
+	wake      chan struct{}
+	now       func() time.Time
+
+	// running maps the jobs this instance is running to their cancel functions
+	mu      sync.Mutex
+	running map[uuid.UUID]context.CancelFunc
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner creates a job runner; register handlers and schedules, then call Start
+func NewRunner(db *gorm.DB, config Config, logger logger.Logger) *Runner {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = 30 * time.Second
+	}
+	if config.MaxRetryDelay < config.RetryDelay {
+		config.MaxRetryDelay = config.RetryDelay
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = time.Hour
+	}
+	r := &Runner{
+		db:       db,
+		config:   config,
+		logger:   logger,
+		handlers: make(map[string]Func),
+		wake:     make(chan struct{}, config.Workers),
+		now:      time.Now,
+		running:  make(map[uuid.UUID]context.CancelFunc),
+	}
+	if config.Retention > 0 {
+		r.Register(TypePrune, r.prune)
+		// The spec is fixed, so it always parses
+		_ = r.Schedule(PruneSchedule, "@daily", TypePrune, nil)
+	}
+	return r
+}
+
+// Register sets the handler of a job type. Only registered types are
+// claimed, so instances running different versions leave each other's
+// jobs alone. Register before Start.
+func (r *Runner) Register(jobType string, handler Func) {
+	r.handlers[jobType] = handler
+}
+
+// Schedule queues a job of jobType with payload whenever spec, a cron
+// expression in UTC, comes due. Runs missed while no instance was up are
+// queued once. Schedule before Start.
+func (r *Runner) Schedule(name, spec, jobType string, payload interface{}) error {
+	cron, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	encoded, err := encodePayload(payload)
+	if err != nil {
+		return err
+	}
+	r.schedules = append(r.schedules, registeredSchedule{
+		Schedule: Schedule{Name: name, Spec: spec, Type: jobType, Payload: encoded},
+		cron:     cron,
+	})
+	return nil
+}
+
+// Start saves the registered schedules, requeues jobs cut off by a previous
+// shutdown and starts the workers, which run until Stop is called
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.saveSchedules(ctx)
+	r.requeueStale(ctx)
+
+	for i := 0; i < r.config.Workers; i++ {
+		r.wg.Add(1)
+		go r.work(ctx)
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			r.queueDue(ctx)
+			r.requeueStale(ctx)
+		}
+	}()
+}
+
+// Stop stops the workers, interrupting running jobs, and waits for them to
+// finish. Interrupted jobs run again after the next start.
+func (r *Runner) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// Wake tells idle workers that jobs were queued
+func (r *Runner) Wake() {
+	for i := 0; i < cap(r.wake); i++ {
+		select {
+		case r.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+// saveSchedules writes the registered schedules to the schedule table. A
+// schedule whose spec changed is due at its new spec's next time; otherwise
+// its next run is kept.
+func (r *Runner) saveSchedules(ctx context.Context) {
+	now := r.now().UTC()
+	for _, s := range r.schedules {
+		next := s.cron.Next(now)
+		schedule := s.Schedule
+		schedule.NextRunAt = next
+		if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "name"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"spec":        s.Spec,
+				"type":        s.Type,
+				"payload":     s.Payload,
+				"next_run_at": gorm.Expr("CASE WHEN job_schedules.spec = ? THEN job_schedules.next_run_at ELSE ? END", s.Spec, next),
+				"updated_at":  now,
+			}),
+		}).Create(&schedule).Error; err != nil {
+			r.logger.LogError(err, fmt.Sprintf("Failed to save job schedule %s", s.Name))
+		}
+	}
+}
+
+// queueDue queues a job for every schedule that came due. Moving the
+// schedule's next run and queuing its job happen together, and only when
+// the run is still due, so each run is queued by one instance.
+func (r *Runner) queueDue(ctx context.Context) {
+	now := r.now().UTC()
+	queued := false
+	for _, s := range r.schedules {
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			job := &Job{
+				ID:          uuid.New(),
+				Type:        s.Type,
+				Payload:     s.Payload,
+				Status:      StatusPending,
+				MaxAttempts: r.config.MaxAttempts,
+				Schedule:    s.Name,
+				RunAt:       now,
+			}
+			result := tx.Model(&Schedule{}).
+				Where("name = ? AND next_run_at <= ?", s.Name, now).
+				Updates(map[string]interface{}{
+					"next_run_at": s.cron.Next(now),
+					"last_run_at": now,
+					"last_job_id": job.ID,
+					"updated_at":  now,
+				})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			if err := tx.Create(job).Error; err != nil {
+				return err
+			}
+			queued = true
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			r.logger.LogError(err, fmt.Sprintf("Failed to queue scheduled job %s", s.Name))
+		}
+	}
+	if queued {
+		r.Wake()
+	}
+}
+
+// requeueStale returns running jobs whose worker stopped reporting, cut
+// off by a crash or restart, to the queue. The interrupted attempt counts.
+func (r *Runner) requeueStale(ctx context.Context) {
+	stale := r.now().Add(-4 * r.config.PollInterval)
+	result := r.db.WithContext(ctx).Model(&Job{}).
+		Where("status = ? AND updated_at < ?", StatusRunning, stale).
+		Updates(map[string]interface{}{"status": StatusPending, "run_at": r.now(), "updated_at": r.now()})
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			r.logger.LogError(result.Error, "Failed to requeue interrupted jobs")
+		}
+		return
+	}
+	if result.RowsAffected > 0 {
+		r.logger.LogWarn("Requeued interrupted jobs", map[string]interface{}{
+			"count": result.RowsAffected,
+		})
+	}
+}
+
+// work runs due jobs until there are none left, then waits to be woken or
+// for the next poll
+func (r *Runner) work(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			job, err := r.claim(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					r.logger.LogError(err, "Failed to claim job")
+				}
+				break
+			}
+			if job == nil {
+				break
+			}
+			r.run(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim marks the next due job of a registered type as running and returns
+// it, or nil when nothing is due. The conditional update keeps two workers,
+// or two instances, from running the same job.
+func (r *Runner) claim(ctx context.Context) (*Job, error) {
+	types := r.types()
+	if len(types) == 0 {
+		return nil, nil
+	}
+	for {
+		var job Job
+		err := r.db.WithContext(ctx).
+			Where("status = ? AND run_at <= ? AND type IN ?", StatusPending, r.now(), types).
+			Order("run_at").
+			First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load due job: %w", err)
+		}
+
+		now := r.now()
+		result := r.db.WithContext(ctx).Model(&Job{}).
+			Where("id = ? AND status = ?", job.ID, StatusPending).
+			Updates(map[string]interface{}{
+				"status":     StatusRunning,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"started_at": now,
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to claim job: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			job.Status = StatusRunning
+			job.Attempts++
+			job.StartedAt = &now
+			return &job, nil
+		}
+	}
+}
+
+// run runs one attempt of a claimed job and records the outcome
+func (r *Runner) run(ctx context.Context, job *Job) {
+	jobCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+	r.mu.Lock()
+	r.running[job.ID] = cancel
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.running, job.ID)
+		r.mu.Unlock()
+	}()
+
+	stopHeartbeat := r.heartbeat(jobCtx, job.ID, cancel)
+	err := r.call(jobCtx, job)
+	stopHeartbeat()
+
+	now := r.now()
+	updates := map[string]interface{}{"last_error": ""}
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		updates["status"] = StatusSucceeded
+		updates["finished_at"] = now
+	case ctx.Err() != nil:
+		// Stopped by shutdown rather than by the job, so the attempt is not counted
+		updates["status"] = StatusPending
+		updates["attempts"] = job.Attempts - 1
+		updates["run_at"] = now
+		updates["last_error"] = err.Error()
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		updates["status"] = StatusFailed
+		updates["finished_at"] = now
+		updates["last_error"] = err.Error()
+		r.logger.LogWarn("Job failed permanently", map[string]interface{}{
+			"job_id":   job.ID,
+			"type":     job.Type,
+			"attempts": job.Attempts,
+			"error":    err.Error(),
+		})
+	default:
+		updates["status"] = StatusPending
+		updates["run_at"] = now.Add(r.backoff(job.Attempts))
+		updates["last_error"] = err.Error()
+	}
+	r.record(job.ID, updates)
+}
+
+// call runs the job's handler, turning a panic into a permanent failure
+func (r *Runner) call(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = Permanent(fmt.Errorf("job panicked: %v", p))
+		}
+	}()
+	return r.handlers[job.Type](ctx, job)
+}
+
+// heartbeat marks the job alive every poll interval, so it is not taken for
+// an interrupted job, and cancels it once its status is no longer running,
+// such as when it was cancelled from another instance. The returned function
+// stops it.
+func (r *Runner) heartbeat(ctx context.Context, jobID uuid.UUID, cancel context.CancelFunc) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(r.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			result := r.db.WithContext(ctx).Model(&Job{}).
+				Where("id = ? AND status = ?", jobID, StatusRunning).
+				Update("updated_at", r.now())
+			if result.Error == nil && result.RowsAffected == 0 {
+				cancel()
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// record saves a job's outcome unless it was cancelled meanwhile. It runs
+// even while stopping, so an attempt that was made is never forgotten.
+func (r *Runner) record(jobID uuid.UUID, updates map[string]interface{}) {
+	updates["updated_at"] = r.now()
+	if err := r.db.Model(&Job{}).Where("id = ? AND status = ?", jobID, StatusRunning).Updates(updates).Error; err != nil {
+		r.logger.LogError(err, "Failed to record job outcome")
+	}
+}
+
+// interrupt cancels the attempt of a job running on this instance
+func (r *Runner) interrupt(jobID uuid.UUID) {
+	r.mu.Lock()
+	cancel, ok := r.running[jobID]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// backoff returns the wait before the attempt after the given one
+func (r *Runner) backoff(attempts int) time.Duration {
+	delay := r.config.RetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= r.config.MaxRetryDelay {
+			return r.config.MaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// types returns the registered job types
+func (r *Runner) types() []string {
+	types := make([]string, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// prune deletes finished jobs older than the retention period
+func (r *Runner) prune(ctx context.Context, job *Job) error {
+	result := r.db.WithContext(ctx).
+		Where("status IN ? AND finished_at < ?", []Status{StatusSucceeded, StatusFailed, StatusCancelled}, r.now().Add(-r.config.Retention)).
+		Delete(&Job{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to prune jobs: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		r.logger.LogInfo("Pruned finished jobs", map[string]interface{}{
+			"count": result.RowsAffected,
+		})
+	}
+	return nil
+}
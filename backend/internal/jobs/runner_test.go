@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	r := NewRunner(nil, Config{RetryDelay: time.Second, MaxRetryDelay: 5 * time.Second}, nil)
+
+	assert.Equal(t, time.Second, r.backoff(1))
+	assert.Equal(t, 2*time.Second, r.backoff(2))
+	assert.Equal(t, 4*time.Second, r.backoff(3))
+	assert.Equal(t, 5*time.Second, r.backoff(4))
+	assert.Equal(t, 5*time.Second, r.backoff(20))
+}
+
+func TestNewRunnerSchedulesPrune(t *testing.T) {
+	r := NewRunner(nil, Config{Retention: 24 * time.Hour}, nil)
+	assert.Equal(t, []string{TypePrune}, r.types())
+	require.Len(t, r.schedules, 1)
+	assert.Equal(t, PruneSchedule, r.schedules[0].Name)
+
+	r = NewRunner(nil, Config{}, nil)
+	assert.Empty(t, r.types(), "finished jobs are kept without a retention period")
+}
+
+func TestScheduleValidatesSpec(t *testing.T) {
+	r := NewRunner(nil, Config{}, nil)
+	assert.Error(t, r.Schedule("nightly", "every night", "export", nil))
+	require.NoError(t, r.Schedule("nightly", "0 2 * * *", "export", map[string]int{"days": 1}))
+	assert.Equal(t, `{"days":1}`, r.schedules[0].Payload)
+}
+
+func TestEnqueueUnknownType(t *testing.T) {
+	r := NewRunner(nil, Config{}, nil)
+	_, err := r.Enqueue(context.Background(), "export", nil)
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
+
+func TestListInvalidStatus(t *testing.T) {
+	r := NewRunner(nil, Config{}, nil)
+	_, err := r.List(context.Background(), ListFilter{Status: "stuck"}, 1, 20)
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+}
+
+func TestCallRecoversPanics(t *testing.T) {
+	r := NewRunner(nil, Config{}, nil)
+	r.Register("explode", func(ctx context.Context, job *Job) error {
+		panic("boom")
+	})
+
+	err := r.call(context.Background(), &Job{ID: uuid.New(), Type: "explode"})
+	var permanent *permanentError
+	assert.True(t, errors.As(err, &permanent), "a panic is not retried")
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestPermanent(t *testing.T) {
+	assert.NoError(t, Permanent(nil))
+
+	cause := errors.New("invalid payload")
+	err := Permanent(cause)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "invalid payload", err.Error())
+}
+
+func TestJobDecode(t *testing.T) {
+	var payload struct {
+		VideoID uuid.UUID `json:"video_id"`
+	}
+	id := uuid.New()
+	job := &Job{Payload: `{"video_id":"` + id.String() + `"}`}
+	require.NoError(t, job.Decode(&payload))
+	assert.Equal(t, id, payload.VideoID)
+}
+
+func TestStatusFinished(t *testing.T) {
+	for _, status := range []Status{StatusSucceeded, StatusFailed, StatusCancelled} {
+		assert.True(t, status.Finished(), status)
+	}
+	for _, status := range []Status{StatusPending, StatusRunning} {
+		assert.False(t, status.Finished(), status)
+	}
+	assert.False(t, Status("stuck").IsValid())
+}
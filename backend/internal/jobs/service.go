@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Enqueue queues a job of a registered type with payload, encoded as JSON,
+// to run as soon as a worker is free
+func (r *Runner) Enqueue(ctx context.Context, jobType string, payload interface{}) (*Job, error) {
+	if _, ok := r.handlers[jobType]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, jobType)
+	}
+	encoded, err := encodePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		Type:        jobType,
+		Payload:     encoded,
+		Status:      StatusPending,
+		MaxAttempts: r.config.MaxAttempts,
+		RunAt:       r.now(),
+	}
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue job: %w", err)
+	}
+	r.Wake()
+	return job, nil
+}
+
+// List returns a page of jobs, newest first
+func (r *Runner) List(ctx context.Context, filter ListFilter, page, limit int) (*ListResponse, error) {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	query := r.db.WithContext(ctx).Model(&Job{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+
+	jobs := make([]Job, 0, limit)
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return &ListResponse{
+		Jobs:  jobs,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// Get returns a job
+func (r *Runner) Get(ctx context.Context, id uuid.UUID) (*Job, error) {
+	var job Job
+	if err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	return &job, nil
+}
+
+// Cancel marks a pending or running job cancelled. A running attempt is
+// interrupted at once on this instance, and at its next heartbeat on others.
+func (r *Runner) Cancel(ctx context.Context, id uuid.UUID) (*Job, error) {
+	now := r.now()
+	result := r.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND status IN ?", id, []Status{StatusPending, StatusRunning}).
+		Updates(map[string]interface{}{
+			"status":      StatusCancelled,
+			"finished_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", result.Error)
+	}
+
+	job, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrJobFinished
+	}
+	r.interrupt(id)
+	return job, nil
+}
+
+// ListSchedules returns the recurring schedules by name
+func (r *Runner) ListSchedules(ctx context.Context) ([]Schedule, error) {
+	schedules := []Schedule{}
+	if err := r.db.WithContext(ctx).Order("name").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list job schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// encodePayload encodes a job payload as JSON; a nil payload is an empty object
+func encodePayload(payload interface{}) (string, error) {
+	if payload == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,227 @@
+package live
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// AMF0 type markers. RTMP commands and stream metadata are AMF0 encoded.
+const (
+	amfNumber      = 0x00
+	amfBoolean     = 0x01
+	amfString      = 0x02
+	amfObject      = 0x03
+	amfNull        = 0x05
+	amfUndefined   = 0x06
+	amfECMAArray   = 0x08
+	amfObjectEnd   = 0x09
+	amfStrictArray = 0x0a
+	amfDate        = 0x0b
+	amfLongString  = 0x0c
+)
+
+// maxAMFDepth bounds how deeply objects may nest, so a malicious client
+// cannot exhaust the stack
+const maxAMFDepth = 16
+
+// errAMF is returned for values that are not valid AMF0
+var errAMF = errors.New("invalid AMF0 value")
+
+// amfObj is an AMF0 object or ECMA array
+type amfObj map[string]interface{}
+
+// decodeAMF decodes every AMF0 value in data. Numbers are float64, strings
+// string, booleans bool, objects amfObj and strict arrays []interface{};
+// null and undefined are nil.
+func decodeAMF(data []byte) ([]interface{}, error) {
+	r := bytes.NewReader(data)
+	var values []interface{}
+	for r.Len() > 0 {
+		value, err := decodeAMFValue(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// decodeAMFValue decodes one value
+func decodeAMFValue(r *bytes.Reader, depth int) (interface{}, error) {
+	if depth > maxAMFDepth {
+		return nil, fmt.Errorf("%w: nested too deeply", errAMF)
+	}
+	marker, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errAMF, err)
+	}
+	switch marker {
+	case amfNumber:
+		var bits uint64
+		if err := binary.Read(r, binary.BigEndian, &bits); err != nil {
+			return nil, fmt.Errorf("%w: %v", errAMF, err)
+		}
+		return math.Float64frombits(bits), nil
+	case amfBoolean:
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errAMF, err)
+		}
+		return b != 0, nil
+	case amfString:
+		return decodeAMFString(r, 2)
+	case amfLongString:
+		return decodeAMFString(r, 4)
+	case amfObject:
+		return decodeAMFProperties(r, depth)
+	case amfECMAArray:
+		// The count is only a hint; properties end with an end marker as in objects
+		if _, err := r.Seek(4, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("%w: %v", errAMF, err)
+		}
+		return decodeAMFProperties(r, depth)
+	case amfStrictArray:
+		var count uint32
+		if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+			return nil, fmt.Errorf("%w: %v", errAMF, err)
+		}
+		if int64(count) > int64(r.Len()) {
+			return nil, fmt.Errorf("%w: array longer than its message", errAMF)
+		}
+		values := make([]interface{}, 0, count)
+		for i := uint32(0); i < count; i++ {
+			value, err := decodeAMFValue(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case amfDate:
+		// Milliseconds since the epoch as a number, then a time zone that is always 0
+		var bits uint64
+		if err := binary.Read(r, binary.BigEndian, &bits); err != nil {
+			return nil, fmt.Errorf("%w: %v", errAMF, err)
+		}
+		if _, err := r.Seek(2, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("%w: %v", errAMF, err)
+		}
+		return math.Float64frombits(bits), nil
+	case amfNull, amfUndefined:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported type marker 0x%02x", errAMF, marker)
+}
+
+// decodeAMFString decodes a string whose length takes lengthSize bytes
+func decodeAMFString(r *bytes.Reader, lengthSize int) (string, error) {
+	var length uint32
+	if lengthSize == 2 {
+		var short uint16
+		if err := binary.Read(r, binary.BigEndian, &short); err != nil {
+			return "", fmt.Errorf("%w: %v", errAMF, err)
+		}
+		length = uint32(short)
+	} else if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return "", fmt.Errorf("%w: %v", errAMF, err)
+	}
+	if int64(length) > int64(r.Len()) {
+		return "", fmt.Errorf("%w: string longer than its message", errAMF)
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", fmt.Errorf("%w: %v", errAMF, err)
+	}
+	return string(b), nil
+}
+
+// decodeAMFProperties decodes the properties of an object up to its end marker
+func decodeAMFProperties(r *bytes.Reader, depth int) (amfObj, error) {
+	obj := amfObj{}
+	for {
+		name, err := decodeAMFString(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			marker, err := r.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", errAMF, err)
+			}
+			if marker == amfObjectEnd {
+				return obj, nil
+			}
+			if err := r.UnreadByte(); err != nil {
+				return nil, err
+			}
+		}
+		value, err := decodeAMFValue(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj[name] = value
+	}
+}
+
+// encodeAMF encodes values as AMF0. It supports the types decodeAMF
+// returns, and ints as numbers; object properties are written sorted by name.
+func encodeAMF(values ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, value := range values {
+		encodeAMFValue(&buf, value)
+	}
+	return buf.Bytes()
+}
+
+// encodeAMFValue encodes one value
+func encodeAMFValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case float64:
+		buf.WriteByte(amfNumber)
+		_ = binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	case int:
+		encodeAMFValue(buf, float64(v))
+	case bool:
+		buf.WriteByte(amfBoolean)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case string:
+		if len(v) > math.MaxUint16 {
+			buf.WriteByte(amfLongString)
+			_ = binary.Write(buf, binary.BigEndian, uint32(len(v)))
+		} else {
+			buf.WriteByte(amfString)
+			_ = binary.Write(buf, binary.BigEndian, uint16(len(v)))
+		}
+		buf.WriteString(v)
+	case amfObj:
+		buf.WriteByte(amfObject)
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			_ = binary.Write(buf, binary.BigEndian, uint16(len(name)))
+			buf.WriteString(name)
+			encodeAMFValue(buf, v[name])
+		}
+		buf.Write([]byte{0, 0, amfObjectEnd})
+	case []interface{}:
+		buf.WriteByte(amfStrictArray)
+		_ = binary.Write(buf, binary.BigEndian, uint32(len(v)))
+		for _, item := range v {
+			encodeAMFValue(buf, item)
+		}
+	default:
+		buf.WriteByte(amfNull)
+	}
+}
@@ -0,0 +1,387 @@
+package live
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for live streams and streaming settings
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new live handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the streaming settings routes behind
+// authMiddleware, and the playback routes, which like video playback accept
+// API keys, behind readAuthMiddleware and readMiddleware
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, readAuthMiddleware, readMiddleware gin.HandlerFunc) {
+	me := router.Group("/me", authMiddleware)
+	{
+		me.GET("/live", h.handleGetSettings)
+		me.PATCH("/live", h.handleUpdateSettings)
+		me.GET("/stream-keys", h.handleListKeys)
+		me.POST("/stream-keys", h.handleCreateKey)
+		me.POST("/stream-keys/:id/rotate", h.handleRotateKey)
+		me.DELETE("/stream-keys/:id", h.handleRevokeKey)
+		me.GET("/streams", h.handleListSessions)
+	}
+
+	streams := router.Group("/live", readAuthMiddleware, readMiddleware)
+	{
+		streams.GET("", h.handleListLive)
+		streams.GET("/:id", h.handleGetStream)
+		streams.GET("/:id/hls/:file", h.handleFile)
+	}
+}
+
+// @Summary Get streaming settings
+// @Description Get the RTMP server to stream to, the title of the next streams, and the current stream while live. Stream keys are managed under /me/stream-keys.
+// @Tags live
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=Settings} "Streaming settings"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/live [get]
+func (h *Handler) handleGetSettings(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.Settings(c.Request.Context(), userID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve streaming settings")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, settings, "Streaming settings retrieved successfully")
+}
+
+// @Summary Update streaming settings
+// @Description Set the title of the next live streams, which their recordings are published with. Streams already live keep their title.
+// @Tags live
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SettingsRequest true "Streaming settings"
+// @Success 200 {object} httpHandler.APIResponse{data=Settings} "Streaming settings"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid title"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/live [patch]
+func (h *Handler) handleUpdateSettings(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req SettingsRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	settings, err := h.service.SetTitle(c.Request.Context(), userID, req.Title)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to update streaming settings")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, settings, "Streaming settings updated successfully")
+}
+
+// @Summary List stream keys
+// @Description List the current user's stream keys, newest first, including revoked ones. Keys are masked; the full key is only shown when it is created or rotated.
+// @Tags live
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=StreamKeyListResponse} "Stream keys"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/stream-keys [get]
+func (h *Handler) handleListKeys(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.service.ListKeys(c.Request.Context(), userID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to list stream keys")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, StreamKeyListResponse{Keys: keys}, "Stream keys retrieved successfully")
+}
+
+// @Summary Create a stream key
+// @Description Create a named stream key. Streaming software publishes to ingest_url with the key as the stream key; each key has one live stream at a time. The key is only shown in this response.
+// @Tags live
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateKeyRequest true "Key name"
+// @Success 200 {object} httpHandler.APIResponse{data=KeyResponse} "New stream key"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid name"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Too many active stream keys"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/stream-keys [post]
+func (h *Handler) handleCreateKey(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateKeyRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	key, err := h.service.CreateKey(c.Request.Context(), userID, req.Name)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to create stream key")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, key, "Stream key created successfully")
+}
+
+// @Summary Rotate a stream key
+// @Description Replace the secret of one of the current user's stream keys, keeping its name. The old key stops working, and a stream live with it is disconnected. The new key is only shown in this response.
+// @Tags live
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Stream key ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=KeyResponse} "Rotated stream key"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid key ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Stream key not found"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Stream key is revoked"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/stream-keys/{id}/rotate [post]
+func (h *Handler) handleRotateKey(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	keyID, ok := h.getKeyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.service.RotateKey(c.Request.Context(), userID, keyID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to rotate stream key")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, key, "Stream key rotated successfully")
+}
+
+// @Summary Revoke a stream key
+// @Description Revoke one of the current user's stream keys. It stops working, and a stream live with it is disconnected. Revoked keys stay listed.
+// @Tags live
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Stream key ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse "Stream key revoked"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid key ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Stream key not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/stream-keys/{id} [delete]
+func (h *Handler) handleRevokeKey(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	keyID, ok := h.getKeyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RevokeKey(c.Request.Context(), userID, keyID); err != nil {
+		h.handleServiceError(c, err, "Failed to revoke stream key")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, nil, "Stream key revoked successfully")
+}
+
+// @Summary List my streams
+// @Description List the current user's streams, live and ended, latest first, with the key each was published with, how long it lasted, and what was received. The average bitrate is known once a stream ends.
+// @Tags live
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Streams per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=SessionListResponse} "Streams"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /me/streams [get]
+func (h *Handler) handleListSessions(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	sessions, err := h.service.ListSessions(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to list streams")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, sessions, "Streams retrieved successfully")
+}
+
+// @Summary List live streams
+// @Description List the streams that are live, most recently started first
+// @Tags live
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param limit query int false "Maximum streams (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=StreamListResponse} "Live streams"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /live [get]
+func (h *Handler) handleListLive(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	streams, err := h.service.ListLive(c.Request.Context(), limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to list live streams")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, StreamListResponse{Streams: streams}, "Live streams retrieved successfully")
+}
+
+// @Summary Get a live stream
+// @Description Get a live or ended stream. Live streams have the playback_url of their HLS playlist; ended streams link the video their recording was published as, once it is processed.
+// @Tags live
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Stream ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=StreamResponse} "Live stream"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid stream ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Stream not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /live/{id} [get]
+func (h *Handler) handleGetStream(c *gin.Context) {
+	streamID, ok := h.getStreamID(c)
+	if !ok {
+		return
+	}
+
+	stream, err := h.service.GetStream(c.Request.Context(), streamID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve live stream")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, stream, "Live stream retrieved successfully")
+}
+
+// @Summary Get a live stream's HLS playlist or segment
+// @Description Serve the HLS playlist (index.m3u8) of a live stream, or one of the MPEG-TS segments it lists. The playlist lists the latest segments and changes as the stream goes on, so it is not cached; segments never change. Both are served while the stream is live, and until its recording is published.
+// @Tags live
+// @Produce application/vnd.apple.mpegurl
+// @Produce video/mp2t
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Stream ID (UUID)"
+// @Param file path string true "index.m3u8, or a segment name from the playlist"
+// @Success 200 {file} binary "Playlist or segment"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid stream ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Stream, playlist or segment not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /live/{id}/hls/{file} [get]
+func (h *Handler) handleFile(c *gin.Context) {
+	streamID, ok := h.getStreamID(c)
+	if !ok {
+		return
+	}
+
+	name := c.Param("file")
+	path, err := h.service.File(c.Request.Context(), streamID, name)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to serve live stream file")
+		return
+	}
+	if strings.HasSuffix(name, ".m3u8") {
+		c.Header("Content-Type", "application/vnd.apple.mpegurl")
+		c.Header("Cache-Control", "no-cache")
+	} else {
+		c.Header("Content-Type", "video/mp2t")
+		c.Header("Cache-Control", "private, max-age=3600, immutable")
+	}
+	c.File(path)
+}
+
+// getUserID returns the authenticated user's ID, aborting with an error when it is missing
+func (h *Handler) getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, _ := c.Get("userID")
+	str, _ := userIDStr.(string)
+	userID, err := uuid.Parse(str)
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "User not authenticated", nil)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// getStreamID parses the stream ID path parameter, aborting with an error when it is invalid
+func (h *Handler) getStreamID(c *gin.Context) (uuid.UUID, bool) {
+	streamID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid stream ID format", err)
+		return uuid.Nil, false
+	}
+	return streamID, true
+}
+
+// getKeyID parses the stream key ID path parameter, aborting with an error when it is invalid
+func (h *Handler) getKeyID(c *gin.Context) (uuid.UUID, bool) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid stream key ID format", err)
+		return uuid.Nil, false
+	}
+	return keyID, true
+}
+
+// handleServiceError maps live service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrStreamNotFound), errors.Is(err, ErrFileNotFound), errors.Is(err, ErrKeyNotFound):
+		h.responseHandler.NotFoundResponse(c, err.Error())
+	case errors.Is(err, ErrKeyRevoked):
+		h.responseHandler.ErrorResponse(c, http.StatusConflict, "STREAM_KEY_REVOKED", err.Error(), nil)
+	case errors.Is(err, ErrTooManyKeys):
+		h.responseHandler.ErrorResponse(c, http.StatusConflict, "STREAM_KEY_LIMIT", err.Error(), nil)
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
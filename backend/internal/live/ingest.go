@@ -0,0 +1,323 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+// readTimeout ends streams whose client sends nothing for this long
+const readTimeout = 30 * time.Second
+
+// IngestConfig represents how streams are received
+type IngestConfig struct {
+	// Addr is the address the RTMP server listens on
+	Addr string
+	// OutputDir holds a directory per stream with its playlist, segments and recording
+	OutputDir string
+	Packager  PackagerConfig
+}
+
+// output is where a stream's media is packaged
+type output interface {
+	mediaSink
+	// Close finishes the playlist and recording
+	Close() error
+}
+
+// countingOutput counts the bytes of the tags written to an output
+type countingOutput struct {
+	output
+	bytes int64
+}
+
+func (c *countingOutput) WriteTag(tagType uint8, timestamp uint32, payload []byte) error {
+	c.bytes += int64(len(payload))
+	return c.output.WriteTag(tagType, timestamp, payload)
+}
+
+// Ingest is the RTMP server streamers publish to. Each stream is packaged
+// as HLS while it is live and, when recording is on, published as a video
+// through uploader once it ends.
+type Ingest struct {
+	config   IngestConfig
+	tracker  StreamTracker
+	uploader Uploader
+	logger   logger.Logger
+	// startOutput starts packaging a stream into dir
+	startOutput func(dir string) (output, error)
+
+	listener net.Listener
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	// live maps the streams being received to their connections
+	live map[uuid.UUID]net.Conn
+	// sessions waits for connections, recordings for recordings being published
+	sessions   sync.WaitGroup
+	recordings sync.WaitGroup
+}
+
+// NewIngest creates an RTMP ingest server
+func NewIngest(config IngestConfig, tracker StreamTracker, uploader Uploader, logger logger.Logger) *Ingest {
+	if config.Packager.SegmentDuration <= 0 {
+		config.Packager.SegmentDuration = time.Second
+	}
+	if config.Packager.PlaylistSize < 1 {
+		config.Packager.PlaylistSize = 6
+	}
+	i := &Ingest{
+		config:   config,
+		tracker:  tracker,
+		uploader: uploader,
+		logger:   logger,
+		conns:    make(map[net.Conn]struct{}),
+		live:     make(map[uuid.UUID]net.Conn),
+	}
+	i.startOutput = func(dir string) (output, error) {
+		return startPackager(i.config.Packager, dir)
+	}
+	return i
+}
+
+// Start ends streams a previous run left live, then listens for streamers
+// until Stop is called
+func (i *Ingest) Start() error {
+	i.ctx, i.cancel = context.WithCancel(context.Background())
+	if err := i.tracker.EndAll(i.ctx); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(i.config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create live output directory: %w", err)
+	}
+	listener, err := net.Listen("tcp", i.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for RTMP on %s: %w", i.config.Addr, err)
+	}
+	i.listener = listener
+
+	i.logger.LogInfo("RTMP ingest listening", map[string]interface{}{
+		"addr": listener.Addr().String(),
+	})
+	i.sessions.Add(1)
+	go i.accept()
+	return nil
+}
+
+// Stop disconnects streamers, ending their streams, and waits for their
+// recordings to be published until ctx is done, when the rest are
+// interrupted
+func (i *Ingest) Stop(ctx context.Context) {
+	if i.listener == nil {
+		return
+	}
+	_ = i.listener.Close()
+	i.mu.Lock()
+	for conn := range i.conns {
+		_ = conn.Close()
+	}
+	i.mu.Unlock()
+	i.sessions.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		i.recordings.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		i.cancel()
+		<-done
+	}
+	i.cancel()
+}
+
+// Disconnect closes a live stream's connection, which ends the stream like
+// the streamer stopping does. It reports whether the stream was connected.
+func (i *Ingest) Disconnect(streamID uuid.UUID) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	conn, ok := i.live[streamID]
+	if ok {
+		_ = conn.Close()
+	}
+	return ok
+}
+
+// accept serves each connection until the listener is closed
+func (i *Ingest) accept() {
+	defer i.sessions.Done()
+	for {
+		conn, err := i.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				i.logger.LogError(err, "Failed to accept RTMP connection")
+			}
+			return
+		}
+		i.mu.Lock()
+		i.conns[conn] = struct{}{}
+		i.mu.Unlock()
+
+		i.sessions.Add(1)
+		go func() {
+			defer i.sessions.Done()
+			i.handle(conn)
+			i.mu.Lock()
+			delete(i.conns, conn)
+			i.mu.Unlock()
+		}()
+	}
+}
+
+// handle receives a stream from one connection, then ends it
+func (i *Ingest) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var stream *Stream
+	var out *countingOutput
+	err := newRTMPConn(conn, readTimeout).serve(func(key string) (mediaSink, error) {
+		var err error
+		if stream, err = i.tracker.Begin(i.ctx, key); err != nil {
+			return nil, err
+		}
+		dir := StreamDir(i.config.OutputDir, stream.ID)
+		var packaged output
+		if err = os.MkdirAll(dir, 0755); err == nil {
+			packaged, err = i.startOutput(dir)
+		}
+		if err != nil {
+			i.end(stream, Stats{})
+			stream = nil
+			return nil, fmt.Errorf("failed to start packaging: %w", err)
+		}
+		out = &countingOutput{output: packaged}
+		i.mu.Lock()
+		i.live[stream.ID] = conn
+		i.mu.Unlock()
+		i.logger.LogInfo("Live stream started", map[string]interface{}{
+			"streamId": stream.ID.String(),
+			"userId":   stream.UserID.String(),
+			"remote":   conn.RemoteAddr().String(),
+		})
+		return out, nil
+	})
+	if err != nil && !isDisconnect(err) {
+		fields := map[string]interface{}{
+			"remote": conn.RemoteAddr().String(),
+			"error":  err.Error(),
+		}
+		if errors.Is(err, errPublishRejected) {
+			i.logger.LogInfo("RTMP publish rejected", fields)
+		} else {
+			i.logger.LogWarn("RTMP connection failed", fields)
+		}
+	}
+	if stream == nil {
+		return
+	}
+
+	i.mu.Lock()
+	delete(i.live, stream.ID)
+	i.mu.Unlock()
+	if err := out.Close(); err != nil {
+		i.logger.LogError(err, "Failed to finish packaging live stream")
+	}
+	stats := Stats{BytesReceived: out.bytes}
+	i.end(stream, stats)
+	duration := time.Since(stream.StartedAt).Round(time.Second)
+	fields := map[string]interface{}{
+		"streamId": stream.ID.String(),
+		"duration": duration.String(),
+		"bytes":    stats.BytesReceived,
+	}
+	if duration > 0 {
+		fields["bitrateKbps"] = stats.BytesReceived * 8 / int64(duration/time.Millisecond)
+	}
+	i.logger.LogInfo("Live stream ended", fields)
+
+	if !i.config.Packager.Record {
+		i.removeDir(stream.ID)
+		return
+	}
+	i.recordings.Add(1)
+	go func() {
+		defer i.recordings.Done()
+		i.publishRecording(stream)
+	}()
+}
+
+// end marks a stream ended, even when shutting down
+func (i *Ingest) end(stream *Stream, stats Stats) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := i.tracker.End(ctx, stream.ID, stats); err != nil {
+		i.logger.LogError(err, "Failed to end live stream")
+	}
+}
+
+// publishRecording uploads a stream's recording through the video pipeline,
+// then removes the stream's files
+func (i *Ingest) publishRecording(stream *Stream) {
+	fields := map[string]interface{}{"streamId": stream.ID.String()}
+	path := filepath.Join(StreamDir(i.config.OutputDir, stream.ID), recordingName)
+	file, err := os.Open(path)
+	if err != nil {
+		fields["error"] = err.Error()
+		i.logger.LogWarn("Live stream left no recording", fields)
+		i.removeDir(stream.ID)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		i.logger.LogWarn("Live stream recording is empty", fields)
+		i.removeDir(stream.ID)
+		return
+	}
+
+	upload, err := i.uploader.InitializeUpload(i.ctx, stream.UserID, stream.Title, "", info.Size(), video.Taxonomy{})
+	if err != nil {
+		i.logger.LogError(err, "Failed to create video for live stream recording")
+		return
+	}
+	header := &multipart.FileHeader{Filename: recordingName, Size: info.Size()}
+	if err := i.uploader.ProcessUpload(i.ctx, upload, file, header); err != nil {
+		// The recording is kept, so it can still be uploaded by hand
+		i.logger.LogError(fmt.Errorf("recording of live stream %s kept at %s: %w", stream.ID, path, err), "Failed to publish live stream recording")
+		return
+	}
+
+	videoID := upload.VideoID
+	if upload.DuplicateOf != uuid.Nil {
+		videoID = upload.DuplicateOf
+	}
+	if err := i.tracker.Recorded(i.ctx, stream.ID, videoID); err != nil {
+		i.logger.LogError(err, "Failed to link live stream recording")
+	}
+	fields["videoId"] = videoID.String()
+	i.logger.LogInfo("Live stream recording published", fields)
+	i.removeDir(stream.ID)
+}
+
+// removeDir deletes a stream's playlist, segments and recording
+func (i *Ingest) removeDir(streamID uuid.UUID) {
+	if err := os.RemoveAll(StreamDir(i.config.OutputDir, streamID)); err != nil {
+		i.logger.LogWarn("Failed to remove live stream files", map[string]interface{}{
+			"streamId": streamID.String(),
+			"error":    err.Error(),
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Package live receives live streams over RTMP, packages them as HLS with
+// ffmpeg, and publishes their recordings through the video pipeline
+package live
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrStreamNotFound is returned when no live stream has the given ID
+	ErrStreamNotFound = errors.New("live stream not found")
+	// ErrFileNotFound is returned for playlists and segments that do not
+	// exist, or no longer do
+	ErrFileNotFound = errors.New("playlist or segment not found")
+	// ErrInvalidKey is returned when a stream is published with a key no user has
+	ErrInvalidKey = errors.New("invalid stream key")
+	// ErrAlreadyLive is returned when a stream key is published to while a
+	// stream with it is live
+	ErrAlreadyLive = errors.New("stream key is already live")
+	// ErrKeyNotFound is returned when the user has no stream key with the given ID
+	ErrKeyNotFound = errors.New("stream key not found")
+	// ErrKeyRevoked is returned when a revoked stream key is rotated
+	ErrKeyRevoked = errors.New("stream key is revoked")
+	// ErrTooManyKeys is returned when a user creates more active stream keys
+	// than the configured limit
+	ErrTooManyKeys = errors.New("too many stream keys")
+)
+
+// Service defines the interface for live streams and their settings
+type Service interface {
+	StreamTracker
+	// Settings returns how a user streams, and their current stream
+	Settings(ctx context.Context, userID uuid.UUID) (*Settings, error)
+	// SetTitle changes the title of a user's next streams
+	SetTitle(ctx context.Context, userID uuid.UUID, title string) (*Settings, error)
+	// CreateKey creates a stream key for a user
+	CreateKey(ctx context.Context, userID uuid.UUID, name string) (*KeyResponse, error)
+	// ListKeys returns a user's stream keys, masked, including revoked ones
+	ListKeys(ctx context.Context, userID uuid.UUID) ([]StreamKeyResponse, error)
+	// RotateKey replaces the secret of one of a user's stream keys
+	RotateKey(ctx context.Context, userID, keyID uuid.UUID) (*KeyResponse, error)
+	// RevokeKey stops one of a user's stream keys from working
+	RevokeKey(ctx context.Context, userID, keyID uuid.UUID) error
+	// ListSessions returns a page of a user's streams, latest first
+	ListSessions(ctx context.Context, userID uuid.UUID, page, limit int) (*SessionListResponse, error)
+	// SetDisconnector sets what ends streams whose key is rotated or revoked
+	SetDisconnector(disconnector Disconnector)
+	// ListLive returns up to limit streams that are live, latest first
+	ListLive(ctx context.Context, limit int) ([]StreamResponse, error)
+	// GetStream returns a live or ended stream
+	GetStream(ctx context.Context, streamID uuid.UUID) (*StreamResponse, error)
+	// File returns the path of a stream's playlist or segment
+	File(ctx context.Context, streamID uuid.UUID, name string) (string, error)
+}
+
+// StreamTracker records the streams the ingest server receives
+type StreamTracker interface {
+	// Begin authorizes a stream key and records a live stream of its owner
+	Begin(ctx context.Context, key string) (*Stream, error)
+	// End marks a stream ended, recording what was received
+	End(ctx context.Context, streamID uuid.UUID, stats Stats) error
+	// EndAll marks every stream still live ended, after the ingest server stopped without ending them
+	EndAll(ctx context.Context) error
+	// Recorded links a stream with the video its recording was published as
+	Recorded(ctx context.Context, streamID, videoID uuid.UUID) error
+}
+
+// Stats is what the ingest server received during a stream
+type Stats struct {
+	// BytesReceived counts the audio, video and metadata the streamer sent
+	BytesReceived int64
+}
+
+// Disconnector ends live streams. The ingest server implements it.
+type Disconnector interface {
+	// Disconnect closes a live stream's connection, reporting whether it was connected
+	Disconnect(streamID uuid.UUID) bool
+}
+
+// Uploader publishes recordings as videos. The video service implements it.
+type Uploader interface {
+	InitializeUpload(ctx context.Context, userID uuid.UUID, title, description string, size int64, taxonomy video.Taxonomy) (*video.VideoUpload, error)
+	ProcessUpload(ctx context.Context, upload *video.VideoUpload, file multipart.File, header *multipart.FileHeader) error
+}
+
+// EventPublisher notifies followers when a user goes live
+type EventPublisher interface {
+	PublishVideoEvent(ctx context.Context, event *notification.VideoEvent) error
+}
@@ -0,0 +1,166 @@
+package live
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the state of a live stream
+type Status string
+
+const (
+	// StatusLive streams are being received and can be watched
+	StatusLive Status = "live"
+	// StatusEnded streams stopped; their recording may be published as a video
+	StatusEnded Status = "ended"
+)
+
+// StreamKey is a secret a user publishes streams with. Only its hash is
+// stored; the key itself is shown when it is created or rotated.
+type StreamKey struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index"`
+	// Name tells the user's keys apart, e.g. the encoder each is used in
+	Name string `gorm:"type:text;not null"`
+	// KeyHash is the SHA-256 hash of the key
+	KeyHash string `gorm:"type:text;not null;uniqueIndex"`
+	// Prefix and LastFour are the start and end of the key, which masked keys show
+	Prefix     string `gorm:"type:text;not null"`
+	LastFour   string `gorm:"type:text;not null;default:''"`
+	LastUsedAt *time.Time
+	// RevokedAt is when the key stopped working
+	RevokedAt *time.Time
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+	UpdatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName keeps the live tables together
+func (StreamKey) TableName() string {
+	return "live_keys"
+}
+
+// StreamSettings are a user's settings for their streams
+type StreamSettings struct {
+	UserID uuid.UUID `gorm:"type:uuid;primary_key"`
+	// Title is given to the user's streams and their recordings; a dated
+	// default is used when it is empty
+	Title     string    `gorm:"type:text;not null;default:''"`
+	CreatedAt time.Time `gorm:"not null;default:now()"`
+	UpdatedAt time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName keeps the live tables together
+func (StreamSettings) TableName() string {
+	return "live_settings"
+}
+
+// Stream is a live stream, from when its owner starts publishing
+type Stream struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Title     string     `gorm:"type:text;not null"`
+	Status    Status     `gorm:"type:text;not null;index"`
+	StartedAt time.Time  `gorm:"not null"`
+	EndedAt   *time.Time `gorm:""`
+	// VideoID is the video the stream's recording was published as
+	VideoID *uuid.UUID `gorm:"type:uuid"`
+	// KeyID is the stream key the stream was published with
+	KeyID *uuid.UUID `gorm:"type:uuid;index"`
+	// BytesReceived counts the audio, video and metadata the streamer sent
+	BytesReceived int64     `gorm:"not null;default:0"`
+	CreatedAt     time.Time `gorm:"not null;default:now()"`
+	UpdatedAt     time.Time `gorm:"not null;default:now()"`
+}
+
+// TableName keeps the live tables together
+func (Stream) TableName() string {
+	return "live_streams"
+}
+
+// StreamResponse describes a live stream
+type StreamResponse struct {
+	ID     string `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	UserID string `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title  string `json:"title" example:"Friday night coding"`
+	Status Status `json:"status" example:"live" enums:"live,ended"`
+	// PlaybackURL is the HLS playlist, served while the stream is live and
+	// shortly after
+	PlaybackURL string     `json:"playback_url,omitempty" example:"https://pavilion.example.com/api/v1/live/7c9e6679-7425-40de-944b-e07fc1f90ae7/hls/index.m3u8"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	// VideoID is the video the stream's recording was published as, once processed
+	VideoID string `json:"video_id,omitempty" example:"5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"`
+}
+
+// StreamListResponse lists live streams
+type StreamListResponse struct {
+	Streams []StreamResponse `json:"streams"`
+}
+
+// SessionResponse describes one of a user's streams, as received by the
+// ingest server
+type SessionResponse struct {
+	StreamResponse
+	// KeyID is the stream key the stream was published with
+	KeyID   string `json:"key_id,omitempty" example:"3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c"`
+	KeyName string `json:"key_name,omitempty" example:"OBS at home"`
+	// DurationSeconds is how long the stream has been live, or was
+	DurationSeconds float64 `json:"duration_seconds" example:"7960"`
+	BytesReceived   int64   `json:"bytes_received" example:"5970000000"`
+	// BitrateKbps is the average bitrate the streamer sent; set once the stream ended
+	BitrateKbps int64 `json:"bitrate_kbps,omitempty" example:"6000"`
+}
+
+// SessionListResponse is a page of a user's streams, latest first
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+	Total    int64             `json:"total" example:"42"`
+	Page     int               `json:"page" example:"1"`
+	Limit    int               `json:"limit" example:"20"`
+}
+
+// StreamKeyResponse describes a stream key without revealing it
+type StreamKeyResponse struct {
+	ID   string `json:"id" example:"3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c"`
+	Name string `json:"name" example:"OBS at home"`
+	// MaskedKey is the start and end of the key
+	MaskedKey  string     `json:"masked_key" example:"pvs_3q2-7wEA...Zc8A"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// StreamKeyListResponse lists a user's stream keys, newest first
+type StreamKeyListResponse struct {
+	Keys []StreamKeyResponse `json:"keys"`
+}
+
+// Settings describe how a user streams
+type Settings struct {
+	// IngestURL is the RTMP server streaming software publishes to
+	IngestURL string `json:"ingest_url" example:"rtmp://live.example.com/live"`
+	// Title is given to the user's streams and their recordings
+	Title string `json:"title" example:"Friday night coding"`
+	// Current is the user's latest stream while they are live
+	Current *StreamResponse `json:"current,omitempty"`
+}
+
+// KeyResponse returns a created or rotated stream key. The key is only
+// shown once.
+type KeyResponse struct {
+	StreamKeyResponse
+	IngestURL string `json:"ingest_url" example:"rtmp://live.example.com/live"`
+	Key       string `json:"key" example:"pvs_3q2-7wEAhX0XkYc2Q3lq1bqgVtCk0o8T9l4GLJ1Zc8A"`
+}
+
+// SettingsRequest changes how a user streams
+type SettingsRequest struct {
+	// Title is given to the user's next streams and their recordings
+	Title string `json:"title" binding:"required,min=3,max=100" example:"Friday night coding"`
+}
+
+// CreateKeyRequest names a new stream key
+type CreateKeyRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50" example:"OBS at home"`
+}
@@ -0,0 +1,173 @@
+package live
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Files ffmpeg writes in a stream's directory
+const (
+	playlistName  = "index.m3u8"
+	segmentPrefix = "seg"
+	recordingName = "recording.mp4"
+)
+
+// PackagerConfig represents how streams are packaged for playback
+type PackagerConfig struct {
+	// FFmpegPath is the ffmpeg binary
+	FFmpegPath string
+	// SegmentDuration is the target length of HLS segments
+	SegmentDuration time.Duration
+	// PlaylistSize is how many segments live playlists list
+	PlaylistSize int
+	// Record keeps a copy of the whole stream
+	Record bool
+}
+
+// flvWriter writes FLV tags, the format RTMP media is carried in
+type flvWriter struct {
+	w      io.Writer
+	header bool
+}
+
+// WriteTag writes an audio (8), video (9) or script data (18) tag, preceded
+// by the FLV header on the first call
+func (f *flvWriter) WriteTag(tagType uint8, timestamp uint32, payload []byte) error {
+	buf := make([]byte, 0, 11+len(payload)+4+13)
+	if !f.header {
+		// FLV version 1 with audio and video, then a zero previous tag size
+		buf = append(buf, 'F', 'L', 'V', 1, 0x05, 0, 0, 0, 9, 0, 0, 0, 0)
+		f.header = true
+	}
+	buf = append(buf, tagType)
+	buf = appendUint24(buf, uint32(len(payload)))
+	buf = appendUint24(buf, timestamp&0xffffff)
+	buf = append(buf, byte(timestamp>>24), 0, 0, 0)
+	buf = append(buf, payload...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(11+len(payload)))
+	_, err := f.w.Write(buf)
+	return err
+}
+
+// packager runs ffmpeg, which reads a stream as FLV on stdin and writes its
+// HLS playlist and segments, and its recording, without re-encoding
+type packager struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	buf   *bufio.Writer
+	flv   *flvWriter
+	// stderr keeps the end of ffmpeg's output, to report why it failed
+	stderr tailBuffer
+	once   sync.Once
+	err    error
+}
+
+// startPackager starts ffmpeg writing into dir
+func startPackager(config PackagerConfig, dir string) (*packager, error) {
+	p := &packager{}
+	p.cmd = exec.Command(config.FFmpegPath, packagerArgs(config, dir)...)
+	p.cmd.Stderr = &p.stderr
+	stdin, err := p.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	p.stdin = stdin
+	p.buf = bufio.NewWriterSize(stdin, 64<<10)
+	p.flv = &flvWriter{w: p.buf}
+	return p, nil
+}
+
+// packagerArgs returns ffmpeg's arguments. Segments are cut at keyframes,
+// so they are as long as the streamer's keyframe interval when that is longer.
+func packagerArgs(config PackagerConfig, dir string) []string {
+	args := []string{
+		"-hide_banner", "-loglevel", "error",
+		"-fflags", "+genpts",
+		"-f", "flv", "-i", "pipe:0",
+		"-map", "0", "-c", "copy",
+		"-f", "hls",
+		"-hls_time", strconv.FormatFloat(config.SegmentDuration.Seconds(), 'f', -1, 64),
+		"-hls_list_size", strconv.Itoa(config.PlaylistSize),
+		"-hls_flags", "delete_segments+independent_segments+temp_file",
+		"-hls_segment_filename", filepath.Join(dir, segmentPrefix+"%05d.ts"),
+		filepath.Join(dir, playlistName),
+	}
+	if config.Record {
+		// Fragmented, so the recording stays readable if ffmpeg is killed
+		args = append(args,
+			"-map", "0", "-c", "copy",
+			"-f", "mp4", "-movflags", "+frag_keyframe+empty_moov+default_base_moof",
+			filepath.Join(dir, recordingName),
+		)
+	}
+	return args
+}
+
+// WriteTag passes a tag to ffmpeg
+func (p *packager) WriteTag(tagType uint8, timestamp uint32, payload []byte) error {
+	if err := p.flv.WriteTag(tagType, timestamp, payload); err != nil {
+		return fmt.Errorf("ffmpeg stopped reading: %w: %s", err, p.stderr.String())
+	}
+	// Flush video right away, keeping segments as fresh as possible
+	if tagType == msgVideo {
+		return p.flush()
+	}
+	return nil
+}
+
+// flush writes buffered tags to ffmpeg
+func (p *packager) flush() error {
+	if err := p.buf.Flush(); err != nil {
+		return fmt.Errorf("ffmpeg stopped reading: %w: %s", err, p.stderr.String())
+	}
+	return nil
+}
+
+// Close ends the input, so ffmpeg finishes the playlist and recording, and
+// waits for it to exit
+func (p *packager) Close() error {
+	p.once.Do(func() {
+		flushErr := p.flush()
+		_ = p.stdin.Close()
+		if err := p.cmd.Wait(); err != nil {
+			p.err = fmt.Errorf("ffmpeg failed: %w: %s", err, p.stderr.String())
+			return
+		}
+		p.err = flushErr
+	})
+	return p.err
+}
+
+// tailBuffer keeps the last 4 KiB written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (t *tailBuffer) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf.Write(b)
+	if extra := t.buf.Len() - 4096; extra > 0 {
+		t.buf.Next(extra)
+	}
+	return len(b), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.TrimSpace(t.buf.String())
+}
@@ -0,0 +1,499 @@
+package live
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// RTMP protocol constants. Only what publishing clients such as OBS and
+// FFmpeg use is implemented: the simple handshake, AMF0 commands, and audio,
+// video and metadata messages.
+const (
+	rtmpVersion       = 3
+	handshakeSize     = 1536
+	defaultChunkSize  = 128
+	serverChunkSize   = 4096
+	maxChunkSize      = 0xffffff
+	maxMessageSize    = 16 << 20
+	windowAckSize     = 2500000
+	extendedTimestamp = 0xffffff
+
+	// Chunk stream IDs the server sends on
+	csidControl = 2
+	csidCommand = 3
+	// streamID is the only message stream a connection creates
+	streamID = 1
+)
+
+// RTMP message types
+const (
+	msgSetChunkSize     = 1
+	msgAbort            = 2
+	msgAck              = 3
+	msgUserControl      = 4
+	msgWindowAckSize    = 5
+	msgSetPeerBandwidth = 6
+	msgAudio            = 8
+	msgVideo            = 9
+	msgDataAMF0         = 18
+	msgCommandAMF0      = 20
+)
+
+// setDataFrame prefixes metadata sent by publishers, and is not part of the
+// onMetaData tag written to FLV
+var setDataFrame = encodeAMF("@setDataFrame")
+
+// errPublishRejected is returned by serve when authorize rejected the stream key
+var errPublishRejected = errors.New("publish rejected")
+
+// message is an RTMP message reassembled from its chunks
+type message struct {
+	typeID    uint8
+	streamID  uint32
+	timestamp uint32
+	payload   []byte
+}
+
+// chunkStream is the state of a chunk stream, which message headers are
+// compressed against
+type chunkStream struct {
+	timestamp uint32
+	delta     uint32
+	length    uint32
+	typeID    uint8
+	streamID  uint32
+	extended  bool
+	payload   []byte
+}
+
+// mediaSink receives the audio, video and metadata a client publishes, as
+// FLV tag types and payloads
+type mediaSink interface {
+	WriteTag(tagType uint8, timestamp uint32, payload []byte) error
+}
+
+// authorizeFunc accepts a stream key, returning where the stream's media goes
+type authorizeFunc func(key string) (mediaSink, error)
+
+// rtmpConn is the server side of an RTMP connection
+type rtmpConn struct {
+	conn         net.Conn
+	r            *bufio.Reader
+	w            *bufio.Writer
+	timeout      time.Duration
+	inChunkSize  uint32
+	outChunkSize uint32
+	streams      map[uint32]*chunkStream
+	received     uint32
+	acked        uint32
+	// peerWindow is how many bytes the client may receive before acknowledging
+	peerWindow uint32
+}
+
+// newRTMPConn wraps a connection. Reads fail when the client sends nothing
+// for timeout.
+func newRTMPConn(conn net.Conn, timeout time.Duration) *rtmpConn {
+	return &rtmpConn{
+		conn:         conn,
+		r:            bufio.NewReaderSize(conn, 64<<10),
+		w:            bufio.NewWriterSize(conn, 16<<10),
+		timeout:      timeout,
+		inChunkSize:  defaultChunkSize,
+		outChunkSize: defaultChunkSize,
+		streams:      make(map[uint32]*chunkStream),
+	}
+}
+
+// serve runs the connection: the handshake, then commands until the client
+// publishes, then its media until it stops publishing or disconnects.
+// authorize is called with the stream key the client publishes to; when it
+// fails, the client is told the key is bad and serve returns
+// errPublishRejected. A client that unpublishes or disconnects after
+// publishing ends serve without an error.
+func (c *rtmpConn) serve(authorize authorizeFunc) error {
+	if err := c.handshake(); err != nil {
+		return fmt.Errorf("handshake failed: %w", err)
+	}
+
+	var sink mediaSink
+	for {
+		msg, err := c.readMessage()
+		if err != nil {
+			if sink != nil && isDisconnect(err) {
+				return nil
+			}
+			return err
+		}
+
+		switch msg.typeID {
+		case msgSetChunkSize:
+			if len(msg.payload) < 4 {
+				return errors.New("short set chunk size message")
+			}
+			size := binary.BigEndian.Uint32(msg.payload) & 0x7fffffff
+			if size < 1 || size > maxChunkSize {
+				return fmt.Errorf("invalid chunk size %d", size)
+			}
+			c.inChunkSize = size
+		case msgWindowAckSize:
+			if len(msg.payload) >= 4 {
+				c.peerWindow = binary.BigEndian.Uint32(msg.payload)
+			}
+		case msgCommandAMF0:
+			done, err := c.handleCommand(msg, &sink, authorize)
+			if err != nil || done {
+				return err
+			}
+		case msgDataAMF0:
+			if sink == nil {
+				continue
+			}
+			// Metadata is written to FLV as an onMetaData tag
+			payload := bytes.TrimPrefix(msg.payload, setDataFrame)
+			if err := sink.WriteTag(msgDataAMF0, msg.timestamp, payload); err != nil {
+				return err
+			}
+		case msgAudio, msgVideo:
+			if sink == nil || len(msg.payload) == 0 {
+				continue
+			}
+			if err := sink.WriteTag(msg.typeID, msg.timestamp, msg.payload); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handleCommand answers an AMF0 command, setting sink once the client
+// publishes. done is true when the client stopped publishing.
+func (c *rtmpConn) handleCommand(msg *message, sink *mediaSink, authorize authorizeFunc) (done bool, err error) {
+	values, err := decodeAMF(msg.payload)
+	if err != nil {
+		return false, err
+	}
+	if len(values) < 2 {
+		return false, errors.New("command without a name and transaction ID")
+	}
+	name, _ := values[0].(string)
+	txn, _ := values[1].(float64)
+
+	switch name {
+	case "connect":
+		return false, c.acceptConnect(txn)
+	case "createStream":
+		return false, c.writeCommand(0, "_result", txn, nil, streamID)
+	case "publish":
+		if *sink != nil {
+			return false, errors.New("already publishing")
+		}
+		key := ""
+		if len(values) > 3 {
+			key, _ = values[3].(string)
+		}
+		// Some clients append query parameters to the stream name
+		key, _, _ = strings.Cut(key, "?")
+		s, err := authorize(key)
+		if err != nil {
+			if writeErr := c.writeStatus("error", "NetStream.Publish.BadName", err.Error()); writeErr != nil {
+				return false, writeErr
+			}
+			return false, fmt.Errorf("%w: %v", errPublishRejected, err)
+		}
+		*sink = s
+		// Stream Begin, then the status that publishing started
+		if err := c.writeMessage(csidControl, &message{typeID: msgUserControl, payload: []byte{0, 0, 0, 0, 0, streamID}}); err != nil {
+			return false, err
+		}
+		return false, c.writeStatus("status", "NetStream.Publish.Start", "Publishing started.")
+	case "FCUnpublish", "deleteStream", "closeStream":
+		return *sink != nil, nil
+	}
+	// releaseStream, FCPublish and the like need no answer
+	return false, nil
+}
+
+// acceptConnect answers a connect command
+func (c *rtmpConn) acceptConnect(txn float64) error {
+	window := make([]byte, 4)
+	binary.BigEndian.PutUint32(window, windowAckSize)
+	if err := c.writeMessage(csidControl, &message{typeID: msgWindowAckSize, payload: window}); err != nil {
+		return err
+	}
+	// Dynamic limit type, so the client may send as much as the window
+	if err := c.writeMessage(csidControl, &message{typeID: msgSetPeerBandwidth, payload: append(window, 2)}); err != nil {
+		return err
+	}
+	chunkSize := make([]byte, 4)
+	binary.BigEndian.PutUint32(chunkSize, serverChunkSize)
+	if err := c.writeMessage(csidControl, &message{typeID: msgSetChunkSize, payload: chunkSize}); err != nil {
+		return err
+	}
+	c.outChunkSize = serverChunkSize
+
+	return c.writeCommand(0, "_result", txn,
+		amfObj{"fmsVer": "FMS/3,0,1,123", "capabilities": 31},
+		amfObj{"level": "status", "code": "NetConnection.Connect.Success", "description": "Connection succeeded.", "objectEncoding": 0},
+	)
+}
+
+// writeStatus sends an onStatus command on the message stream
+func (c *rtmpConn) writeStatus(level, code, description string) error {
+	return c.writeCommand(streamID, "onStatus", 0, nil, amfObj{"level": level, "code": code, "description": description})
+}
+
+// writeCommand sends an AMF0 command
+func (c *rtmpConn) writeCommand(stream uint32, name string, txn float64, args ...interface{}) error {
+	payload := encodeAMF(append([]interface{}{name, txn}, args...)...)
+	return c.writeMessage(csidCommand, &message{typeID: msgCommandAMF0, streamID: stream, payload: payload})
+}
+
+// handshake performs the simple handshake, which publishing clients accept
+// from servers that do not sign theirs
+func (c *rtmpConn) handshake() error {
+	c.setDeadline()
+	c0c1 := make([]byte, 1+handshakeSize)
+	if _, err := io.ReadFull(c.r, c0c1); err != nil {
+		return err
+	}
+	if c0c1[0] != rtmpVersion {
+		return fmt.Errorf("unsupported RTMP version %d", c0c1[0])
+	}
+
+	// S0, then S1 with our time and random bytes, then S2 echoing C1
+	s0s1s2 := make([]byte, 1+2*handshakeSize)
+	s0s1s2[0] = rtmpVersion
+	binary.BigEndian.PutUint32(s0s1s2[1:5], uint32(time.Now().Unix()))
+	if _, err := rand.Read(s0s1s2[9 : 1+handshakeSize]); err != nil {
+		return err
+	}
+	copy(s0s1s2[1+handshakeSize:], c0c1[1:])
+	if _, err := c.w.Write(s0s1s2); err != nil {
+		return err
+	}
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+
+	c2 := make([]byte, handshakeSize)
+	_, err := io.ReadFull(c.r, c2)
+	return err
+}
+
+// readMessage reads chunks until a message is complete
+func (c *rtmpConn) readMessage() (*message, error) {
+	for {
+		msg, err := c.readChunk()
+		if err != nil {
+			return nil, err
+		}
+		if msg != nil {
+			return msg, nil
+		}
+	}
+}
+
+// readChunk reads one chunk, returning the message it completes, if any
+func (c *rtmpConn) readChunk() (*message, error) {
+	c.setDeadline()
+	basic, err := c.readByte()
+	if err != nil {
+		return nil, err
+	}
+	format := basic >> 6
+	csid := uint32(basic & 0x3f)
+	switch csid {
+	case 0:
+		b, err := c.readByte()
+		if err != nil {
+			return nil, err
+		}
+		csid = 64 + uint32(b)
+	case 1:
+		b, err := c.readFull(2)
+		if err != nil {
+			return nil, err
+		}
+		csid = 64 + uint32(b[0]) + uint32(b[1])<<8
+	}
+
+	cs := c.streams[csid]
+	if cs == nil {
+		if format != 0 {
+			return nil, fmt.Errorf("chunk stream %d starts without a full header", csid)
+		}
+		cs = &chunkStream{}
+		c.streams[csid] = cs
+	}
+
+	headerSizes := [4]int{11, 7, 3, 0}
+	header, err := c.readFull(headerSizes[format])
+	if err != nil {
+		return nil, err
+	}
+	var timestamp uint32
+	if format < 3 {
+		timestamp = uint24(header[0:3])
+		cs.extended = timestamp == extendedTimestamp
+	}
+	if format < 2 {
+		cs.length = uint24(header[3:6])
+		cs.typeID = header[6]
+		if cs.length > maxMessageSize {
+			return nil, fmt.Errorf("message of %d bytes is too large", cs.length)
+		}
+	}
+	if format == 0 {
+		cs.streamID = binary.LittleEndian.Uint32(header[7:11])
+	}
+	if cs.extended {
+		ext, err := c.readFull(4)
+		if err != nil {
+			return nil, err
+		}
+		if format < 3 {
+			timestamp = binary.BigEndian.Uint32(ext)
+		}
+	}
+
+	// The timestamp changes when a chunk starts a message: type 0 headers
+	// carry it, the others a delta from the previous message
+	if len(cs.payload) == 0 {
+		switch format {
+		case 0:
+			cs.timestamp = timestamp
+			cs.delta = 0
+		case 1, 2:
+			cs.delta = timestamp
+			cs.timestamp += timestamp
+		case 3:
+			cs.timestamp += cs.delta
+		}
+	}
+
+	size := cs.length - uint32(len(cs.payload))
+	if size > c.inChunkSize {
+		size = c.inChunkSize
+	}
+	data, err := c.readFull(int(size))
+	if err != nil {
+		return nil, err
+	}
+	cs.payload = append(cs.payload, data...)
+	if err := c.acknowledge(); err != nil {
+		return nil, err
+	}
+	if uint32(len(cs.payload)) < cs.length {
+		return nil, nil
+	}
+
+	msg := &message{typeID: cs.typeID, streamID: cs.streamID, timestamp: cs.timestamp, payload: cs.payload}
+	cs.payload = nil
+	return msg, nil
+}
+
+// acknowledge tells the client how much was received each time another
+// window's worth arrived
+func (c *rtmpConn) acknowledge() error {
+	if c.peerWindow == 0 || c.received-c.acked < c.peerWindow {
+		return nil
+	}
+	c.acked = c.received
+	ack := make([]byte, 4)
+	binary.BigEndian.PutUint32(ack, c.received)
+	return c.writeMessage(csidControl, &message{typeID: msgAck, payload: ack})
+}
+
+// writeMessage sends a message in chunks with a full first header, and flushes
+func (c *rtmpConn) writeMessage(csid uint8, msg *message) error {
+	timestamp := msg.timestamp
+	extended := timestamp >= extendedTimestamp
+	if extended {
+		timestamp = extendedTimestamp
+	}
+
+	header := make([]byte, 0, 16)
+	header = append(header, csid)
+	header = appendUint24(header, timestamp)
+	header = appendUint24(header, uint32(len(msg.payload)))
+	header = append(header, msg.typeID)
+	header = binary.LittleEndian.AppendUint32(header, msg.streamID)
+	if extended {
+		header = binary.BigEndian.AppendUint32(header, msg.timestamp)
+	}
+
+	payload := msg.payload
+	for first := true; first || len(payload) > 0; first = false {
+		if first {
+			if _, err := c.w.Write(header); err != nil {
+				return err
+			}
+		} else {
+			if err := c.w.WriteByte(0xc0 | csid); err != nil {
+				return err
+			}
+			if extended {
+				if err := binary.Write(c.w, binary.BigEndian, msg.timestamp); err != nil {
+					return err
+				}
+			}
+		}
+		n := len(payload)
+		if n > int(c.outChunkSize) {
+			n = int(c.outChunkSize)
+		}
+		if _, err := c.w.Write(payload[:n]); err != nil {
+			return err
+		}
+		payload = payload[n:]
+	}
+	return c.w.Flush()
+}
+
+// setDeadline fails reads that wait longer than the timeout
+func (c *rtmpConn) setDeadline() {
+	if c.timeout > 0 {
+		_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+}
+
+// readByte reads one byte, counting it as received
+func (c *rtmpConn) readByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.received++
+	}
+	return b, err
+}
+
+// readFull reads n bytes, counting them as received
+func (c *rtmpConn) readFull(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		return nil, err
+	}
+	c.received += uint32(n)
+	return b, nil
+}
+
+// isDisconnect reports whether err means the client closed the connection
+func isDisconnect(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed)
+}
+
+// uint24 decodes a big-endian 24-bit integer
+func uint24(b []byte) uint32 {
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+// appendUint24 appends a big-endian 24-bit integer
+func appendUint24(b []byte, v uint32) []byte {
+	return append(b, byte(v>>16), byte(v>>8), byte(v))
+}
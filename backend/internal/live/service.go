@@ -0,0 +1,568 @@
+package live
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// keyPrefix starts every stream key, so leaked keys are recognizable
+const keyPrefix = "pvs_"
+
+// fileNamePattern matches the playlist and segments ffmpeg writes
+var fileNamePattern = regexp.MustCompile(`^(` + regexp.QuoteMeta(playlistName) + `|` + segmentPrefix + `[0-9]+\.ts)$`)
+
+// Config represents how live streams are offered
+type Config struct {
+	// IngestURL is the RTMP server shown to streamers
+	IngestURL string
+	// PublicURL is the base URL of the API playback URLs are built on
+	PublicURL string
+	// OutputDir holds a directory per stream with its playlist, segments and recording
+	OutputDir string
+	// MaxKeys limits the active stream keys of each user
+	MaxKeys int
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db           *gorm.DB
+	config       Config
+	publisher    EventPublisher
+	disconnector Disconnector
+	logger       logger.Logger
+}
+
+// NewService creates a new live service. publisher may be nil when the
+// notification system is unavailable.
+func NewService(db *gorm.DB, config Config, publisher EventPublisher, logger logger.Logger) Service {
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+	return &serviceImpl{
+		db:        db,
+		config:    config,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// SetDisconnector sets what ends streams whose key is rotated or revoked.
+// Without one, such streams continue until they end.
+func (s *serviceImpl) SetDisconnector(disconnector Disconnector) {
+	s.disconnector = disconnector
+}
+
+// Settings returns how a user streams
+func (s *serviceImpl) Settings(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	settings := &Settings{IngestURL: s.config.IngestURL}
+
+	var saved StreamSettings
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).First(&saved).Error
+	switch {
+	case err == nil:
+		settings.Title = saved.Title
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to load stream settings: %w", err)
+	}
+
+	var current Stream
+	err = s.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, StatusLive).
+		Order("started_at DESC").First(&current).Error
+	switch {
+	case err == nil:
+		response := s.toResponse(&current)
+		settings.Current = &response
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, fmt.Errorf("failed to load current stream: %w", err)
+	}
+	return settings, nil
+}
+
+// SetTitle changes the title of a user's next streams
+func (s *serviceImpl) SetTitle(ctx context.Context, userID uuid.UUID, title string) (*Settings, error) {
+	settings := StreamSettings{UserID: userID, Title: strings.TrimSpace(title), CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "updated_at"}),
+	}).Create(&settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to set stream title: %w", err)
+	}
+	return s.Settings(ctx, userID)
+}
+
+// CreateKey creates a stream key for a user, up to the configured number of
+// active keys
+func (s *serviceImpl) CreateKey(ctx context.Context, userID uuid.UUID, name string) (*KeyResponse, error) {
+	if s.config.MaxKeys > 0 {
+		var active int64
+		if err := s.db.WithContext(ctx).Model(&StreamKey{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).Count(&active).Error; err != nil {
+			return nil, fmt.Errorf("failed to count stream keys: %w", err)
+		}
+		if active >= int64(s.config.MaxKeys) {
+			return nil, fmt.Errorf("%w: at most %d active keys are allowed", ErrTooManyKeys, s.config.MaxKeys)
+		}
+	}
+
+	raw, err := generateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate stream key: %w", err)
+	}
+	now := time.Now()
+	key := StreamKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	setSecret(&key, raw)
+	if err := s.db.WithContext(ctx).Create(&key).Error; err != nil {
+		return nil, fmt.Errorf("failed to store stream key: %w", err)
+	}
+
+	s.logger.LogInfo("Stream key created", map[string]interface{}{
+		"userId": userID.String(),
+		"keyId":  key.ID.String(),
+		"prefix": key.Prefix,
+	})
+	return s.toKeyResponse(&key, raw), nil
+}
+
+// ListKeys returns a user's stream keys, newest first, including revoked ones
+func (s *serviceImpl) ListKeys(ctx context.Context, userID uuid.UUID) ([]StreamKeyResponse, error) {
+	var keys []StreamKey
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list stream keys: %w", err)
+	}
+	responses := make([]StreamKeyResponse, 0, len(keys))
+	for i := range keys {
+		responses = append(responses, toStreamKeyResponse(&keys[i]))
+	}
+	return responses, nil
+}
+
+// RotateKey replaces the secret of one of a user's stream keys, ending the
+// stream published with the old one
+func (s *serviceImpl) RotateKey(ctx context.Context, userID, keyID uuid.UUID) (*KeyResponse, error) {
+	key, err := s.findKey(ctx, userID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrKeyRevoked
+	}
+
+	raw, err := generateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate stream key: %w", err)
+	}
+	setSecret(key, raw)
+	key.UpdatedAt = time.Now()
+	if err := s.db.WithContext(ctx).Model(key).Updates(map[string]interface{}{
+		"key_hash":   key.KeyHash,
+		"prefix":     key.Prefix,
+		"last_four":  key.LastFour,
+		"updated_at": key.UpdatedAt,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to rotate stream key: %w", err)
+	}
+
+	s.logger.LogInfo("Stream key rotated", map[string]interface{}{
+		"userId": userID.String(),
+		"keyId":  keyID.String(),
+		"prefix": key.Prefix,
+	})
+	s.disconnect(ctx, keyID)
+	return s.toKeyResponse(key, raw), nil
+}
+
+// RevokeKey stops one of a user's stream keys from working, ending the
+// stream published with it. Revoking a revoked key succeeds.
+func (s *serviceImpl) RevokeKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	key, err := s.findKey(ctx, userID, keyID)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(key).
+		Updates(map[string]interface{}{"revoked_at": now, "updated_at": now}).Error; err != nil {
+		return fmt.Errorf("failed to revoke stream key: %w", err)
+	}
+
+	s.logger.LogInfo("Stream key revoked", map[string]interface{}{
+		"userId": userID.String(),
+		"keyId":  keyID.String(),
+	})
+	s.disconnect(ctx, keyID)
+	return nil
+}
+
+// disconnect ends the stream live with a key. Failures are logged; the
+// stream then continues until the streamer stops.
+func (s *serviceImpl) disconnect(ctx context.Context, keyID uuid.UUID) {
+	if s.disconnector == nil {
+		return
+	}
+	var streams []Stream
+	if err := s.db.WithContext(ctx).Where("key_id = ? AND status = ?", keyID, StatusLive).
+		Find(&streams).Error; err != nil {
+		s.logger.LogError(err, "Failed to find live streams of changed stream key")
+		return
+	}
+	for _, stream := range streams {
+		if s.disconnector.Disconnect(stream.ID) {
+			s.logger.LogInfo("Disconnected live stream of changed stream key", map[string]interface{}{
+				"streamId": stream.ID.String(),
+				"keyId":    keyID.String(),
+			})
+		}
+	}
+}
+
+// ListSessions returns a page of a user's streams, latest first
+func (s *serviceImpl) ListSessions(ctx context.Context, userID uuid.UUID, page, limit int) (*SessionListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	var total int64
+	query := s.db.WithContext(ctx).Model(&Stream{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count live streams: %w", err)
+	}
+	var streams []Stream
+	if err := query.Order("started_at DESC").Offset((page - 1) * limit).Limit(limit).
+		Find(&streams).Error; err != nil {
+		return nil, fmt.Errorf("failed to list live streams: %w", err)
+	}
+
+	// Key names are looked up together; keys are never deleted, only revoked
+	var keyIDs []uuid.UUID
+	for _, stream := range streams {
+		if stream.KeyID != nil {
+			keyIDs = append(keyIDs, *stream.KeyID)
+		}
+	}
+	names := make(map[uuid.UUID]string)
+	if len(keyIDs) > 0 {
+		var keys []StreamKey
+		if err := s.db.WithContext(ctx).Select("id", "name").Where("id IN ?", keyIDs).
+			Find(&keys).Error; err != nil {
+			return nil, fmt.Errorf("failed to load stream keys: %w", err)
+		}
+		for _, key := range keys {
+			names[key.ID] = key.Name
+		}
+	}
+
+	sessions := make([]SessionResponse, 0, len(streams))
+	now := time.Now()
+	for i := range streams {
+		sessions = append(sessions, s.toSession(&streams[i], names, now))
+	}
+	return &SessionListResponse{Sessions: sessions, Total: total, Page: page, Limit: limit}, nil
+}
+
+// ListLive returns streams that are live, latest first
+func (s *serviceImpl) ListLive(ctx context.Context, limit int) ([]StreamResponse, error) {
+	var streams []Stream
+	if err := s.db.WithContext(ctx).Where("status = ?", StatusLive).
+		Order("started_at DESC").Limit(limit).Find(&streams).Error; err != nil {
+		return nil, fmt.Errorf("failed to list live streams: %w", err)
+	}
+	responses := make([]StreamResponse, 0, len(streams))
+	for i := range streams {
+		responses = append(responses, s.toResponse(&streams[i]))
+	}
+	return responses, nil
+}
+
+// GetStream returns a live or ended stream
+func (s *serviceImpl) GetStream(ctx context.Context, streamID uuid.UUID) (*StreamResponse, error) {
+	stream, err := s.find(ctx, streamID)
+	if err != nil {
+		return nil, err
+	}
+	response := s.toResponse(stream)
+	return &response, nil
+}
+
+// File returns the path of a stream's playlist or segment. They exist while
+// the stream is live, and until its recording is published.
+func (s *serviceImpl) File(ctx context.Context, streamID uuid.UUID, name string) (string, error) {
+	if !fileNamePattern.MatchString(name) {
+		return "", ErrFileNotFound
+	}
+	if _, err := s.find(ctx, streamID); err != nil {
+		return "", err
+	}
+	path := filepath.Join(StreamDir(s.config.OutputDir, streamID), name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrFileNotFound
+	}
+	return path, nil
+}
+
+// Begin authorizes a stream key and records a live stream of its owner,
+// notifying their followers. Each key has one live stream at a time.
+func (s *serviceImpl) Begin(ctx context.Context, raw string) (*Stream, error) {
+	if !strings.HasPrefix(raw, keyPrefix) {
+		return nil, ErrInvalidKey
+	}
+	var key StreamKey
+	if err := s.db.WithContext(ctx).Where("key_hash = ? AND revoked_at IS NULL", hashKey(raw)).
+		First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return nil, fmt.Errorf("failed to look up stream key: %w", err)
+	}
+
+	var live int64
+	if err := s.db.WithContext(ctx).Model(&Stream{}).
+		Where("key_id = ? AND status = ?", key.ID, StatusLive).Count(&live).Error; err != nil {
+		return nil, fmt.Errorf("failed to check for live streams: %w", err)
+	}
+	if live > 0 {
+		return nil, ErrAlreadyLive
+	}
+
+	var settings StreamSettings
+	if err := s.db.WithContext(ctx).Where("user_id = ?", key.UserID).First(&settings).Error; err != nil &&
+		!errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load stream settings: %w", err)
+	}
+
+	now := time.Now()
+	title := settings.Title
+	if title == "" {
+		title = "Live stream " + now.UTC().Format("2006-01-02 15:04")
+	}
+	stream := &Stream{
+		ID:        uuid.New(),
+		UserID:    key.UserID,
+		Title:     title,
+		Status:    StatusLive,
+		StartedAt: now,
+		KeyID:     &key.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.db.WithContext(ctx).Create(stream).Error; err != nil {
+		return nil, fmt.Errorf("failed to record live stream: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Model(&key).Update("last_used_at", now).Error; err != nil {
+		s.logger.LogWarn("Failed to record stream key use", map[string]interface{}{
+			"keyId": key.ID.String(),
+			"error": err.Error(),
+		})
+	}
+
+	s.notifyStarted(ctx, stream)
+	return stream, nil
+}
+
+// notifyStarted tells the streamer's followers they went live. Failures are
+// logged; the stream goes ahead.
+func (s *serviceImpl) notifyStarted(ctx context.Context, stream *Stream) {
+	if s.publisher == nil {
+		return
+	}
+	event := &notification.VideoEvent{
+		BaseEvent: notification.BaseEvent{
+			Type:     notification.LiveStarted,
+			EventKey: stream.ID.String(),
+		},
+		UserID: stream.UserID,
+		Title:  stream.Title,
+		Metadata: map[string]interface{}{
+			"liveStreamId": stream.ID.String(),
+			"playbackUrl":  s.playbackURL(stream.ID),
+		},
+	}
+	if err := s.publisher.PublishVideoEvent(ctx, event); err != nil {
+		s.logger.LogError(err, "Failed to publish live started event")
+	}
+}
+
+// End marks a stream ended, recording what was received
+func (s *serviceImpl) End(ctx context.Context, streamID uuid.UUID, stats Stats) error {
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&Stream{}).Where("id = ? AND status = ?", streamID, StatusLive).
+		Updates(map[string]interface{}{
+			"status":         StatusEnded,
+			"ended_at":       now,
+			"bytes_received": stats.BytesReceived,
+			"updated_at":     now,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to end live stream: %w", err)
+	}
+	return nil
+}
+
+// EndAll marks every stream still live ended
+func (s *serviceImpl) EndAll(ctx context.Context) error {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&Stream{}).Where("status = ?", StatusLive).
+		Updates(map[string]interface{}{"status": StatusEnded, "ended_at": now, "updated_at": now})
+	if result.Error != nil {
+		return fmt.Errorf("failed to end live streams: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		s.logger.LogWarn("Ended live streams left live by the previous run", map[string]interface{}{
+			"streams": result.RowsAffected,
+		})
+	}
+	return nil
+}
+
+// Recorded links a stream with the video its recording was published as
+func (s *serviceImpl) Recorded(ctx context.Context, streamID, videoID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).Model(&Stream{}).Where("id = ?", streamID).
+		Updates(map[string]interface{}{"video_id": videoID, "updated_at": time.Now()}).Error; err != nil {
+		return fmt.Errorf("failed to link live stream recording: %w", err)
+	}
+	return nil
+}
+
+// findKey loads one of a user's stream keys
+func (s *serviceImpl) findKey(ctx context.Context, userID, keyID uuid.UUID) (*StreamKey, error) {
+	var key StreamKey
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", keyID, userID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to load stream key: %w", err)
+	}
+	return &key, nil
+}
+
+// find loads a stream
+func (s *serviceImpl) find(ctx context.Context, streamID uuid.UUID) (*Stream, error) {
+	var stream Stream
+	if err := s.db.WithContext(ctx).Where("id = ?", streamID).First(&stream).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStreamNotFound
+		}
+		return nil, fmt.Errorf("failed to load live stream: %w", err)
+	}
+	return &stream, nil
+}
+
+// toResponse describes a stream. Ended streams have no playback URL.
+func (s *serviceImpl) toResponse(stream *Stream) StreamResponse {
+	response := StreamResponse{
+		ID:        stream.ID.String(),
+		UserID:    stream.UserID.String(),
+		Title:     stream.Title,
+		Status:    stream.Status,
+		StartedAt: stream.StartedAt,
+		EndedAt:   stream.EndedAt,
+	}
+	if stream.Status == StatusLive {
+		response.PlaybackURL = s.playbackURL(stream.ID)
+	}
+	if stream.VideoID != nil {
+		response.VideoID = stream.VideoID.String()
+	}
+	return response
+}
+
+// toSession describes one of a user's streams with what was received. Live
+// streams have lasted until now; their bitrate is known once they end.
+func (s *serviceImpl) toSession(stream *Stream, keyNames map[uuid.UUID]string, now time.Time) SessionResponse {
+	session := SessionResponse{
+		StreamResponse: s.toResponse(stream),
+		BytesReceived:  stream.BytesReceived,
+	}
+	if stream.KeyID != nil {
+		session.KeyID = stream.KeyID.String()
+		session.KeyName = keyNames[*stream.KeyID]
+	}
+	end := now
+	if stream.EndedAt != nil {
+		end = *stream.EndedAt
+	}
+	duration := end.Sub(stream.StartedAt).Round(time.Second)
+	session.DurationSeconds = duration.Seconds()
+	if stream.EndedAt != nil && duration > 0 {
+		session.BitrateKbps = stream.BytesReceived * 8 / int64(duration/time.Millisecond)
+	}
+	return session
+}
+
+// toKeyResponse returns a created or rotated key
+func (s *serviceImpl) toKeyResponse(key *StreamKey, raw string) *KeyResponse {
+	return &KeyResponse{StreamKeyResponse: toStreamKeyResponse(key), IngestURL: s.config.IngestURL, Key: raw}
+}
+
+// toStreamKeyResponse describes a key, masked
+func toStreamKeyResponse(key *StreamKey) StreamKeyResponse {
+	return StreamKeyResponse{
+		ID:         key.ID.String(),
+		Name:       key.Name,
+		MaskedKey:  key.Prefix + "..." + key.LastFour,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// playbackURL returns the HLS playlist of a stream
+func (s *serviceImpl) playbackURL(streamID uuid.UUID) string {
+	return fmt.Sprintf("%s/live/%s/hls/%s", s.config.PublicURL, streamID, playlistName)
+}
+
+// StreamDir returns the directory a stream's playlist, segments and recording are written to
+func StreamDir(outputDir string, streamID uuid.UUID) string {
+	return filepath.Join(outputDir, streamID.String())
+}
+
+// setSecret stores a new secret on a key: its hash, and its start and end
+// for masking
+func setSecret(key *StreamKey, raw string) {
+	key.KeyHash = hashKey(raw)
+	key.Prefix = raw[:len(keyPrefix)+8]
+	key.LastFour = raw[len(raw)-4:]
+}
+
+// generateKey returns a new random stream key
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashKey returns the stored form of a stream key. Keys are random and long,
+// so a fast hash is enough.
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,555 @@
+package live
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testUserID = uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+
+func TestAMFRoundTrip(t *testing.T) {
+	data := encodeAMF("connect", 1, amfObj{
+		"app":    "live",
+		"fpad":   false,
+		"nested": amfObj{"level": "status"},
+	}, nil, []interface{}{"a", 2.5})
+
+	values, err := decodeAMF(data)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		"connect",
+		float64(1),
+		amfObj{"app": "live", "fpad": false, "nested": amfObj{"level": "status"}},
+		nil,
+		[]interface{}{"a", 2.5},
+	}, values)
+
+	// Truncated and unknown values are rejected
+	_, err = decodeAMF(data[:len(data)-3])
+	assert.ErrorIs(t, err, errAMF)
+	_, err = decodeAMF([]byte{0x11})
+	assert.ErrorIs(t, err, errAMF)
+}
+
+func TestFLVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	flv := &flvWriter{w: &buf}
+	require.NoError(t, flv.WriteTag(msgVideo, 0x01020304, []byte{0x17, 0x01}))
+	require.NoError(t, flv.WriteTag(msgAudio, 5, []byte{0xaf}))
+
+	assert.Equal(t, []byte{
+		// Header, written once, and the first previous tag size
+		'F', 'L', 'V', 1, 0x05, 0, 0, 0, 9, 0, 0, 0, 0,
+		// Video tag: type, size, timestamp with its extension, stream ID, data, tag size
+		9, 0, 0, 2, 0x02, 0x03, 0x04, 0x01, 0, 0, 0, 0x17, 0x01, 0, 0, 0, 13,
+		// Audio tag
+		8, 0, 0, 1, 0, 0, 5, 0, 0, 0, 0, 0xaf, 0, 0, 0, 12,
+	}, buf.Bytes())
+}
+
+func TestPackagerArgs(t *testing.T) {
+	config := PackagerConfig{FFmpegPath: "ffmpeg", SegmentDuration: 1500 * time.Millisecond, PlaylistSize: 6}
+	args := strings.Join(packagerArgs(config, "/live/1"), " ")
+	assert.Contains(t, args, "-f flv -i pipe:0")
+	assert.Contains(t, args, "-hls_time 1.5 -hls_list_size 6")
+	assert.Contains(t, args, "-hls_segment_filename /live/1/seg%05d.ts /live/1/index.m3u8")
+	assert.NotContains(t, args, recordingName)
+
+	config.Record = true
+	args = strings.Join(packagerArgs(config, "/live/1"), " ")
+	assert.True(t, strings.HasSuffix(args, "-f mp4 -movflags +frag_keyframe+empty_moov+default_base_moof /live/1/recording.mp4"), args)
+}
+
+func TestFileNamePattern(t *testing.T) {
+	for name, want := range map[string]bool{
+		"index.m3u8":       true,
+		"seg00042.ts":      true,
+		"recording.mp4":    false,
+		"seg.ts":           false,
+		"../index.m3u8":    false,
+		"index.m3u8.tmp":   false,
+		"seg00042.ts.tmp":  false,
+		"other/seg0001.ts": false,
+	} {
+		assert.Equal(t, want, fileNamePattern.MatchString(name), name)
+	}
+}
+
+func TestSetSecret(t *testing.T) {
+	raw, err := generateKey()
+	require.NoError(t, err)
+	key := &StreamKey{Name: "OBS at home"}
+	setSecret(key, raw)
+
+	assert.Equal(t, hashKey(raw), key.KeyHash)
+	assert.Equal(t, raw[:12], key.Prefix)
+	assert.Equal(t, raw[len(raw)-4:], key.LastFour)
+	masked := toStreamKeyResponse(key).MaskedKey
+	assert.Equal(t, key.Prefix+"..."+key.LastFour, masked)
+	assert.NotContains(t, masked, raw[12:len(raw)-4])
+}
+
+func TestToSession(t *testing.T) {
+	service := &serviceImpl{config: Config{PublicURL: "https://pavilion.example.com/api/v1"}}
+	keyID := uuid.New()
+	started := time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)
+	ended := started.Add(10 * time.Second)
+	stream := &Stream{
+		ID:            uuid.New(),
+		UserID:        testUserID,
+		Status:        StatusEnded,
+		StartedAt:     started,
+		EndedAt:       &ended,
+		KeyID:         &keyID,
+		BytesReceived: 7_500_000,
+	}
+
+	session := service.toSession(stream, map[uuid.UUID]string{keyID: "OBS at home"}, ended.Add(time.Hour))
+	assert.Equal(t, keyID.String(), session.KeyID)
+	assert.Equal(t, "OBS at home", session.KeyName)
+	assert.Equal(t, 10.0, session.DurationSeconds)
+	assert.Equal(t, int64(6000), session.BitrateKbps)
+	assert.Empty(t, session.PlaybackURL)
+
+	// Live streams have lasted until now, without a bitrate yet
+	stream.Status, stream.EndedAt = StatusLive, nil
+	session = service.toSession(stream, nil, started.Add(90*time.Second))
+	assert.Equal(t, 90.0, session.DurationSeconds)
+	assert.Zero(t, session.BitrateKbps)
+	assert.NotEmpty(t, session.PlaybackURL)
+}
+
+// fakeTracker accepts the key "pvs_good", recording the streams it begins and ends
+type fakeTracker struct {
+	mu       sync.Mutex
+	stream   *Stream
+	ended    []uuid.UUID
+	stats    Stats
+	recorded map[uuid.UUID]uuid.UUID
+}
+
+func (f *fakeTracker) Begin(ctx context.Context, key string) (*Stream, error) {
+	if key != "pvs_good" {
+		return nil, ErrInvalidKey
+	}
+	f.stream = &Stream{ID: uuid.New(), UserID: testUserID, Title: "Friday night coding", Status: StatusLive, StartedAt: time.Now()}
+	return f.stream, nil
+}
+
+func (f *fakeTracker) End(ctx context.Context, streamID uuid.UUID, stats Stats) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ended = append(f.ended, streamID)
+	f.stats = stats
+	return nil
+}
+
+func (f *fakeTracker) EndAll(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeTracker) Recorded(ctx context.Context, streamID, videoID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.recorded == nil {
+		f.recorded = make(map[uuid.UUID]uuid.UUID)
+	}
+	f.recorded[streamID] = videoID
+	return nil
+}
+
+// tag is an FLV tag a stream was packaged with
+type tag struct {
+	tagType   uint8
+	timestamp uint32
+	payload   []byte
+}
+
+// fakeOutput collects a stream's tags, writing a recording when closed
+type fakeOutput struct {
+	dir  string
+	tags []tag
+}
+
+func (f *fakeOutput) WriteTag(tagType uint8, timestamp uint32, payload []byte) error {
+	f.tags = append(f.tags, tag{tagType, timestamp, append([]byte(nil), payload...)})
+	return nil
+}
+
+func (f *fakeOutput) Close() error {
+	return os.WriteFile(filepath.Join(f.dir, recordingName), []byte("recording"), 0644)
+}
+
+// fakeUploader publishes recordings as the video videoID
+type fakeUploader struct {
+	videoID uuid.UUID
+	title   string
+	data    string
+}
+
+func (f *fakeUploader) InitializeUpload(ctx context.Context, userID uuid.UUID, title, description string, size int64, taxonomy video.Taxonomy) (*video.VideoUpload, error) {
+	f.title = title
+	return &video.VideoUpload{VideoID: f.videoID}, nil
+}
+
+func (f *fakeUploader) ProcessUpload(ctx context.Context, upload *video.VideoUpload, file multipart.File, header *multipart.FileHeader) error {
+	data, err := io.ReadAll(file)
+	f.data = string(data)
+	return err
+}
+
+// rtmpClient publishes to the server the way streaming software does
+type rtmpClient struct {
+	*rtmpConn
+	t *testing.T
+}
+
+// dialRTMP performs the client side of the handshake
+func dialRTMP(t *testing.T, conn net.Conn) *rtmpClient {
+	c := &rtmpClient{rtmpConn: newRTMPConn(conn, 5*time.Second), t: t}
+	c0c1 := make([]byte, 1+handshakeSize)
+	c0c1[0] = rtmpVersion
+	copy(c0c1[9:], "client random")
+	_, err := c.w.Write(c0c1)
+	require.NoError(t, err)
+	require.NoError(t, c.w.Flush())
+
+	s0s1s2 := make([]byte, 1+2*handshakeSize)
+	_, err = io.ReadFull(c.r, s0s1s2)
+	require.NoError(t, err)
+	require.Equal(t, c0c1[1:], s0s1s2[1+handshakeSize:], "S2 echoes C1")
+	_, err = c.w.Write(s0s1s2[1 : 1+handshakeSize])
+	require.NoError(t, err)
+	require.NoError(t, c.w.Flush())
+	return c
+}
+
+// send writes a message without waiting for an answer
+func (c *rtmpClient) send(typeID uint8, timestamp uint32, payload []byte) {
+	require.NoError(c.t, c.writeMessage(csidCommand, &message{typeID: typeID, streamID: streamID, timestamp: timestamp, payload: payload}))
+}
+
+// command sends a command, returning the command the server answers with
+func (c *rtmpClient) command(values ...interface{}) []interface{} {
+	c.send(msgCommandAMF0, 0, encodeAMF(values...))
+	for {
+		msg, err := c.readMessage()
+		require.NoError(c.t, err)
+		switch msg.typeID {
+		case msgSetChunkSize:
+			c.inChunkSize = binary.BigEndian.Uint32(msg.payload)
+		case msgCommandAMF0:
+			answer, err := decodeAMF(msg.payload)
+			require.NoError(c.t, err)
+			return answer
+		}
+	}
+}
+
+// newTestIngest returns an ingest server packaging streams into fakeOutputs
+func newTestIngest(t *testing.T, record bool) (*Ingest, *fakeTracker, *fakeUploader, *fakeOutput) {
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+	tracker := &fakeTracker{}
+	uploader := &fakeUploader{videoID: uuid.New()}
+	ingest := NewIngest(IngestConfig{OutputDir: t.TempDir(), Packager: PackagerConfig{Record: record}}, tracker, uploader, log)
+	ingest.ctx = context.Background()
+	out := &fakeOutput{}
+	ingest.startOutput = func(dir string) (output, error) {
+		out.dir = dir
+		return out, nil
+	}
+	return ingest, tracker, uploader, out
+}
+
+// serveTestConn runs a connection on ingest, returning the client end and
+// a channel closed once the server is done with it
+func serveTestConn(t *testing.T, ingest *Ingest) (*rtmpClient, chan struct{}) {
+	server, client := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		ingest.handle(server)
+		close(done)
+	}()
+	t.Cleanup(func() { client.Close() })
+	return dialRTMP(t, client), done
+}
+
+func TestIngestPublish(t *testing.T) {
+	ingest, tracker, uploader, out := newTestIngest(t, true)
+	c, done := serveTestConn(t, ingest)
+
+	answer := c.command("connect", 1, amfObj{"app": "live", "tcUrl": "rtmp://localhost/live"})
+	require.Len(t, answer, 4)
+	assert.Equal(t, "_result", answer[0])
+	assert.Equal(t, "NetConnection.Connect.Success", answer[3].(amfObj)["code"])
+
+	answer = c.command("createStream", 2, nil)
+	assert.Equal(t, []interface{}{"_result", float64(2), nil, float64(streamID)}, answer)
+
+	// OBS appends query parameters to the stream key
+	answer = c.command("publish", 3, nil, "pvs_good?bandwidth=6000", "live")
+	assert.Equal(t, "NetStream.Publish.Start", answer[3].(amfObj)["code"])
+
+	metadata := encodeAMF("onMetaData", amfObj{"width": 1280, "height": 720})
+	c.send(msgDataAMF0, 0, append(append([]byte(nil), setDataFrame...), metadata...))
+	// Larger than a chunk, so it is reassembled
+	keyframe := bytes.Repeat([]byte{0x17}, 300)
+	c.send(msgVideo, 40, keyframe)
+	c.send(msgAudio, 41, []byte{0xaf, 0x01})
+	c.send(msgCommandAMF0, 0, encodeAMF("FCUnpublish", 4, nil, "pvs_good"))
+	<-done
+	ingest.recordings.Wait()
+
+	stream := tracker.stream
+	require.NotNil(t, stream)
+	assert.Equal(t, []tag{
+		{msgDataAMF0, 0, metadata},
+		{msgVideo, 40, keyframe},
+		{msgAudio, 41, []byte{0xaf, 0x01}},
+	}, out.tags)
+	assert.Equal(t, []uuid.UUID{stream.ID}, tracker.ended)
+	assert.Equal(t, Stats{BytesReceived: int64(len(metadata) + len(keyframe) + 2)}, tracker.stats)
+
+	// The recording is published and linked, then the stream's files removed
+	assert.Equal(t, "Friday night coding", uploader.title)
+	assert.Equal(t, "recording", uploader.data)
+	assert.Equal(t, uploader.videoID, tracker.recorded[stream.ID])
+	assert.NoDirExists(t, StreamDir(ingest.config.OutputDir, stream.ID))
+}
+
+func TestIngestDisconnectWithoutRecording(t *testing.T) {
+	ingest, tracker, uploader, out := newTestIngest(t, false)
+	c, done := serveTestConn(t, ingest)
+
+	c.command("connect", 1, amfObj{"app": "live"})
+	c.command("createStream", 2, nil)
+	c.command("publish", 3, nil, "pvs_good", "live")
+	c.send(msgVideo, 0, []byte{0x17, 0x00})
+	// Dropping the connection ends the stream like unpublishing does
+	require.NoError(t, c.conn.Close())
+	<-done
+
+	require.NotNil(t, tracker.stream)
+	assert.Len(t, out.tags, 1)
+	assert.Equal(t, []uuid.UUID{tracker.stream.ID}, tracker.ended)
+	assert.Empty(t, uploader.title)
+	assert.NoDirExists(t, StreamDir(ingest.config.OutputDir, tracker.stream.ID))
+}
+
+func TestIngestDisconnect(t *testing.T) {
+	ingest, tracker, _, _ := newTestIngest(t, false)
+	c, done := serveTestConn(t, ingest)
+
+	c.command("connect", 1, amfObj{"app": "live"})
+	c.command("createStream", 2, nil)
+	c.command("publish", 3, nil, "pvs_good", "live")
+	require.NotNil(t, tracker.stream)
+
+	// A rotated or revoked key's stream is cut off
+	assert.True(t, ingest.Disconnect(tracker.stream.ID))
+	<-done
+	assert.Equal(t, []uuid.UUID{tracker.stream.ID}, tracker.ended)
+	assert.False(t, ingest.Disconnect(tracker.stream.ID))
+	_, err := c.readMessage()
+	assert.Error(t, err)
+}
+
+func TestIngestRejectsUnknownKeys(t *testing.T) {
+	ingest, tracker, _, out := newTestIngest(t, true)
+	c, done := serveTestConn(t, ingest)
+
+	c.command("connect", 1, amfObj{"app": "live"})
+	c.command("createStream", 2, nil)
+	answer := c.command("publish", 3, nil, "pvs_bad", "live")
+	assert.Equal(t, "onStatus", answer[0])
+	assert.Equal(t, "error", answer[3].(amfObj)["level"])
+	assert.Equal(t, "NetStream.Publish.BadName", answer[3].(amfObj)["code"])
+	<-done
+
+	assert.Nil(t, tracker.stream)
+	assert.Empty(t, tracker.ended)
+	assert.Empty(t, out.dir)
+}
+
+// fakeService serves one stream and a playlist, or fails with err
+type fakeService struct {
+	StreamTracker
+	path    string
+	title   string
+	keyName string
+	revoked uuid.UUID
+	page    int
+	limit   int
+	err     error
+}
+
+func (f *fakeService) Settings(ctx context.Context, userID uuid.UUID) (*Settings, error) {
+	return &Settings{IngestURL: "rtmp://live.example.com/live", Title: f.title}, f.err
+}
+
+func (f *fakeService) SetTitle(ctx context.Context, userID uuid.UUID, title string) (*Settings, error) {
+	f.title = title
+	return f.Settings(ctx, userID)
+}
+
+func (f *fakeService) CreateKey(ctx context.Context, userID uuid.UUID, name string) (*KeyResponse, error) {
+	f.keyName = name
+	return f.RotateKey(ctx, userID, uuid.New())
+}
+
+func (f *fakeService) ListKeys(ctx context.Context, userID uuid.UUID) ([]StreamKeyResponse, error) {
+	return []StreamKeyResponse{{Name: f.keyName, MaskedKey: "pvs_3q2-7wEA...Zc8A"}}, f.err
+}
+
+func (f *fakeService) RotateKey(ctx context.Context, userID, keyID uuid.UUID) (*KeyResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &KeyResponse{StreamKeyResponse: StreamKeyResponse{ID: keyID.String(), Name: f.keyName}, Key: "pvs_new"}, nil
+}
+
+func (f *fakeService) RevokeKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	f.revoked = keyID
+	return f.err
+}
+
+func (f *fakeService) ListSessions(ctx context.Context, userID uuid.UUID, page, limit int) (*SessionListResponse, error) {
+	f.page, f.limit = page, limit
+	return &SessionListResponse{Sessions: []SessionResponse{{BitrateKbps: 6000}}, Page: page, Limit: limit}, f.err
+}
+
+func (f *fakeService) SetDisconnector(disconnector Disconnector) {}
+
+func (f *fakeService) ListLive(ctx context.Context, limit int) ([]StreamResponse, error) {
+	return []StreamResponse{{ID: "7c9e6679-7425-40de-944b-e07fc1f90ae7", Status: StatusLive}}, f.err
+}
+
+func (f *fakeService) GetStream(ctx context.Context, streamID uuid.UUID) (*StreamResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &StreamResponse{ID: streamID.String(), Status: StatusLive}, nil
+}
+
+func (f *fakeService) File(ctx context.Context, streamID uuid.UUID, name string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	return filepath.Join(f.path, name), nil
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, playlistName), []byte("#EXTM3U\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "seg00001.ts"), []byte("segment"), 0644))
+
+	service := &fakeService{path: dir}
+	responseHandler := httpHandler.NewResponseHandler(log)
+	handler := NewHandler(service, responseHandler, log)
+	router := gin.New()
+	router.Use(apierror.Middleware(responseHandler))
+	authenticate := func(c *gin.Context) {
+		c.Set("userID", testUserID.String())
+	}
+	handler.RegisterRoutes(router, authenticate, authenticate, func(c *gin.Context) {})
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	streamPath := "/live/7c9e6679-7425-40de-944b-e07fc1f90ae7"
+	w := request(http.MethodGet, streamPath+"/hls/index.m3u8", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vnd.apple.mpegurl", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "#EXTM3U\n", w.Body.String())
+
+	w = request(http.MethodGet, streamPath+"/hls/seg00001.ts", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "video/mp2t", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Cache-Control"), "immutable")
+
+	w = request(http.MethodGet, streamPath, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"live"`)
+	assert.Contains(t, request(http.MethodGet, "/live?limit=500", "").Body.String(), `"streams":[{`)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/live/not-a-uuid", "").Code)
+
+	w = request(http.MethodPatch, "/me/live", `{"title":"Friday night coding"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Friday night coding", service.title)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPatch, "/me/live", `{"title":"ab"}`).Code)
+
+	w = request(http.MethodPost, "/me/stream-keys", `{"name":"OBS at home"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"key":"pvs_new"`)
+	assert.Contains(t, w.Body.String(), `"name":"OBS at home"`)
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/me/stream-keys", `{}`).Code)
+
+	w = request(http.MethodGet, "/me/stream-keys", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"masked_key":"pvs_3q2-7wEA...Zc8A"`)
+	assert.NotContains(t, w.Body.String(), `"key":`)
+
+	keyPath := "/me/stream-keys/3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c"
+	w = request(http.MethodPost, keyPath+"/rotate", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c"`)
+	require.Equal(t, http.StatusOK, request(http.MethodDelete, keyPath, "").Code)
+	assert.Equal(t, "3f6c1a2b-8d4e-4f0a-9c7b-2e5d6f7a8b9c", service.revoked.String())
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodDelete, "/me/stream-keys/not-a-uuid", "").Code)
+
+	w = request(http.MethodGet, "/me/streams?page=2&limit=5", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []int{2, 5}, []int{service.page, service.limit})
+	assert.Contains(t, w.Body.String(), `"bitrate_kbps":6000`)
+
+	service.err = ErrKeyRevoked
+	w = request(http.MethodPost, keyPath+"/rotate", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "STREAM_KEY_REVOKED")
+	service.err = ErrTooManyKeys
+	assert.Equal(t, http.StatusConflict, request(http.MethodPost, "/me/stream-keys", `{"name":"Laptop"}`).Code)
+	service.err = ErrKeyNotFound
+	assert.Equal(t, http.StatusNotFound, request(http.MethodDelete, keyPath, "").Code)
+
+	service.err = ErrFileNotFound
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, streamPath+"/hls/seg00002.ts", "").Code)
+	service.err = ErrStreamNotFound
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, streamPath, "").Code)
+	service.err = errors.New("connection refused")
+	assert.Equal(t, http.StatusInternalServerError, request(http.MethodGet, "/me/live", "").Code)
+}
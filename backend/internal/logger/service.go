@@ -83,7 +83,7 @@ func (l *zapLoggerService) LogInfo(msg string, fields map[string]interface{}) {
 
 func (l *zapLoggerService) LogError(err error, msg string) error {
 	if err != nil {
-		l.logger.Error(msg, zap.Error(err))
+		l.logger.Error(msg, append(l.convertFields(nil), zap.Error(err))...)
 	}
 	return err
 }
@@ -91,7 +91,7 @@ func (l *zapLoggerService) LogError(err error, msg string) error {
 func (l *zapLoggerService) LogErrorf(err error, format string, args ...interface{}) error {
 	if err != nil {
 		msg := fmt.Sprintf(format, args...)
-		l.logger.Error(msg, zap.Error(err))
+		l.logger.Error(msg, append(l.convertFields(nil), zap.Error(err))...)
 	}
 	return err
 }
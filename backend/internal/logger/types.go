@@ -27,19 +27,47 @@ type Config struct {
 	Development bool `mapstructure:"development" yaml:"development"`
 
 	// File output configuration
-	File struct {
-		Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
-		Path    string `mapstructure:"path" yaml:"path"`
-		Rotate  bool   `mapstructure:"rotate" yaml:"rotate"`
-		MaxSize string `mapstructure:"maxSize" yaml:"maxSize"`
-		MaxAge  string `mapstructure:"maxAge" yaml:"maxAge"`
-	} `mapstructure:"file" yaml:"file"`
+	File FileConfig `mapstructure:"file" yaml:"file"`
 
 	// Sampling configuration
-	Sampling struct {
-		Initial    int `mapstructure:"initial" yaml:"initial"`
-		Thereafter int `mapstructure:"thereafter" yaml:"thereafter"`
-	} `mapstructure:"sampling" yaml:"sampling"`
+	Sampling SamplingConfig `mapstructure:"sampling" yaml:"sampling"`
+}
+
+// FileConfig controls writing logs to a file
+type FileConfig struct {
+	Enabled bool   `mapstructure:"enabled" yaml:"enabled" doc:"Also write logs to a file"`
+	Path    string `mapstructure:"path" yaml:"path" doc:"Log file or directory path"`
+	Rotate  bool   `mapstructure:"rotate" yaml:"rotate" doc:"Rotate the log file"`
+	MaxSize string `mapstructure:"maxSize" yaml:"maxSize" doc:"Size at which the file is rotated, e.g. 100MB"`
+	MaxAge  string `mapstructure:"maxAge" yaml:"maxAge" doc:"How long rotated files are kept, e.g. 30d"`
+}
+
+// SamplingConfig limits repeated log entries per second
+type SamplingConfig struct {
+	Initial    int `mapstructure:"initial" yaml:"initial" doc:"Entries logged per second before sampling starts"`
+	Thereafter int `mapstructure:"thereafter" yaml:"thereafter" doc:"Log every Nth entry after the initial burst"`
+}
+
+// DefaultConfig returns the logger configuration used before the
+// application configuration has been loaded
+func DefaultConfig() *Config {
+	return &Config{
+		Level:       InfoLevel,
+		Format:      "json",
+		Output:      "stdout",
+		Development: false,
+		File: FileConfig{
+			Enabled: false,
+			Path:    "/var/log/pavilion",
+			Rotate:  true,
+			MaxSize: "100MB",
+			MaxAge:  "30d",
+		},
+		Sampling: SamplingConfig{
+			Initial:    100,
+			Thereafter: 100,
+		},
+	}
 }
 
 // StandardFields represents the standard fields that should be included in all logs
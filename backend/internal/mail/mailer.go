@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+)
+
+// Mailer sends plain text email
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// Config holds the SMTP settings used to deliver email
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// NewMailer returns an SMTP mailer, or a mailer that only logs messages when
+// no SMTP host is configured (useful in development)
+func NewMailer(cfg *Config, logger logger.Logger) Mailer {
+	if cfg == nil || cfg.Host == "" {
+		logger.LogWarn("SMTP host not configured, emails will be logged instead of sent", nil)
+		return &logMailer{logger: logger}
+	}
+	return &smtpMailer{config: cfg, logger: logger}
+}
+
+// smtpMailer delivers email through an SMTP server
+type smtpMailer struct {
+	config *Config
+	logger logger.Logger
+}
+
+// Send delivers a single plain text message
+func (m *smtpMailer) Send(ctx context.Context, to, subject, body string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.config.Host, m.config.Port)
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	msg := buildMessage(m.config.From, to, subject, body)
+	if err := smtp.SendMail(addr, auth, m.config.From, []string{to}, msg); err != nil {
+		m.logger.LogError(err, "Failed to send email")
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	m.logger.LogInfo("Email sent", map[string]interface{}{
+		"to":      to,
+		"subject": subject,
+	})
+	return nil
+}
+
+// logMailer writes messages to the log instead of sending them
+type logMailer struct {
+	logger logger.Logger
+}
+
+// Send logs the message
+func (m *logMailer) Send(_ context.Context, to, subject, body string) error {
+	m.logger.LogInfo("Email not sent, SMTP is not configured", map[string]interface{}{
+		"to":      to,
+		"subject": subject,
+		"body":    body,
+	})
+	return nil
+}
+
+// buildMessage formats an RFC 5322 message with the minimal headers
+func buildMessage(from, to, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + sanitizeHeader(to) + "\r\n")
+	b.WriteString("Subject: " + sanitizeHeader(subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(body)
+	return []byte(b.String())
+}
+
+// sanitizeHeader strips line breaks so values cannot inject extra headers
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,17 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildMessage(t *testing.T) {
+	msg := string(buildMessage("noreply@example.com", "user@example.com", "Hello\r\nBcc: evil@example.com", "Body"))
+
+	if !strings.Contains(msg, "Subject: HelloBcc: evil@example.com\r\n") {
+		t.Errorf("expected line breaks to be stripped from subject, got %q", msg)
+	}
+	if !strings.HasSuffix(msg, "\r\n\r\nBody") {
+		t.Errorf("expected body after blank line, got %q", msg)
+	}
+}
@@ -0,0 +1,209 @@
+package moderation
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for reports and the moderation queue
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new moderation handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers the report route for signed-in users and the
+// moderation queue behind moderatorMiddleware
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware, moderatorMiddleware gin.HandlerFunc) {
+	router.POST("/reports", authMiddleware, h.handleCreateReport)
+
+	queue := router.Group("/moderation/reports")
+	queue.Use(authMiddleware, moderatorMiddleware)
+	{
+		queue.GET("", h.handleListReports)
+		queue.GET("/:id", h.handleGetReport)
+		queue.PUT("/:id/resolve", h.handleResolveReport)
+	}
+}
+
+// @Summary Report content
+// @Description Report a video or comment to moderators. The content is snapshotted when the report is filed, and again whenever it is edited or deleted while the report is open, so moderators can see what was reported.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateReportRequest true "Reported content and reason"
+// @Success 200 {object} httpHandler.APIResponse{data=Report} "Report filed"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid report"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Content not found"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Already reported"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /reports [post]
+func (h *Handler) handleCreateReport(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateReportRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	report, err := h.service.CreateReport(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to file report")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, report, "Report filed successfully")
+}
+
+// @Summary List reports
+// @Description List reports in the moderation queue, newest first. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Only return reports with this status" Enums(open, resolved, dismissed)
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=ReportListResponse} "Reports retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Moderator role required"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /moderation/reports [get]
+func (h *Handler) handleListReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	list, err := h.service.ListReports(c.Request.Context(), ReportStatus(c.Query("status")), page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve reports")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Reports retrieved successfully")
+}
+
+// @Summary Get a report
+// @Description Get a report with every snapshot of the reported content, oldest first. Snapshots keep the original text and file references even after the content is edited or deleted. Moderators and admins only.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=ReportDetail} "Report retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid report ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Moderator role required"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Report not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /moderation/reports/{id} [get]
+func (h *Handler) handleGetReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid report ID", err)
+		return
+	}
+
+	report, err := h.service.GetReport(c.Request.Context(), reportID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve report")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, report, "Report retrieved successfully")
+}
+
+// @Summary Resolve a report
+// @Description Close a report as resolved or dismissed. Its evidence is kept. Moderators and admins only.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID (UUID)"
+// @Param request body ResolveReportRequest true "Outcome"
+// @Success 200 {object} httpHandler.APIResponse{data=Report} "Report resolved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid request"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Moderator role required"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Report not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /moderation/reports/{id}/resolve [put]
+func (h *Handler) handleResolveReport(c *gin.Context) {
+	moderatorID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid report ID", err)
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	report, err := h.service.ResolveReport(c.Request.Context(), moderatorID, reportID, &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to resolve report")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, report, "Report resolved successfully")
+}
+
+// getUserID returns the authenticated user's ID, writing an error response when it is missing
+func (h *Handler) getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// handleServiceError maps moderation service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidReport):
+		h.responseHandler.ValidationErrorResponse(c, "target_type", "target_type must be video or comment and a reason is required")
+	case errors.Is(err, ErrInvalidResolution):
+		h.responseHandler.ValidationErrorResponse(c, "status", "status must be resolved or dismissed")
+	case errors.Is(err, ErrTargetNotFound):
+		h.responseHandler.NotFoundResponse(c, "Reported content not found")
+	case errors.Is(err, ErrReportNotFound):
+		h.responseHandler.NotFoundResponse(c, "Report not found")
+	case errors.Is(err, ErrDuplicateReport):
+		h.responseHandler.ErrorResponse(c, http.StatusConflict, "DUPLICATE_REPORT", err.Error(), err)
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
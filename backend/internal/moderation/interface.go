@@ -0,0 +1,41 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidReport is returned when a report names an unknown target type or has no reason
+	ErrInvalidReport = errors.New("invalid report")
+	// ErrTargetNotFound is returned when the reported video or comment does not exist
+	ErrTargetNotFound = errors.New("reported content not found")
+	// ErrDuplicateReport is returned when the user already has an open report on the same content
+	ErrDuplicateReport = errors.New("you have already reported this content")
+	// ErrReportNotFound is returned when a report does not exist
+	ErrReportNotFound = errors.New("report not found")
+	// ErrInvalidResolution is returned when a report is resolved with a status other than resolved or dismissed
+	ErrInvalidResolution = errors.New("invalid report resolution")
+)
+
+// Service defines the interface for content reports and their evidence
+type Service interface {
+	// CreateReport files a report and snapshots the reported content as it is now
+	CreateReport(ctx context.Context, reporterID uuid.UUID, req *CreateReportRequest) (*Report, error)
+	// RecordChange snapshots content that has open reports; it does nothing for unreported content
+	RecordChange(ctx context.Context, targetType string, targetID uuid.UUID, trigger string) error
+	// ListReports returns reports, newest first, optionally filtered by status
+	ListReports(ctx context.Context, status ReportStatus, page, limit int) (*ReportListResponse, error)
+	// GetReport returns a report with every snapshot of the reported content
+	GetReport(ctx context.Context, id uuid.UUID) (*ReportDetail, error)
+	// ResolveReport closes a report as resolved or dismissed
+	ResolveReport(ctx context.Context, moderatorID, id uuid.UUID, req *ResolveReportRequest) (*Report, error)
+}
+
+// CommentReader loads comments for snapshots
+type CommentReader interface {
+	GetCommentByID(ctx context.Context, id uuid.UUID) (*comment.Comment, error)
+}
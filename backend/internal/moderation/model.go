@@ -0,0 +1,117 @@
+package moderation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Target types that can be reported
+const (
+	TargetVideo   = "video"
+	TargetComment = "comment"
+)
+
+// Snapshot triggers
+const (
+	// TriggerReport marks the snapshot taken when a report is filed
+	TriggerReport = "report"
+	// TriggerEdit marks a snapshot of reported content after it was edited
+	TriggerEdit = "edit"
+	// TriggerDelete marks a snapshot of reported content just before it was deleted
+	TriggerDelete = "delete"
+)
+
+// ReportStatus is the state of a report in the moderation queue
+type ReportStatus string
+
+const (
+	StatusOpen      ReportStatus = "open"
+	StatusResolved  ReportStatus = "resolved"
+	StatusDismissed ReportStatus = "dismissed"
+)
+
+// Report is a user's complaint about a video or comment
+type Report struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ReporterID uuid.UUID    `gorm:"type:uuid;not null;index" json:"reporter_id"`
+	TargetType string       `gorm:"type:text;not null;index:idx_reports_target" json:"target_type" enums:"video,comment"`
+	TargetID   uuid.UUID    `gorm:"type:uuid;not null;index:idx_reports_target" json:"target_id"`
+	Reason     string       `gorm:"type:text;not null" json:"reason"`
+	Details    string       `gorm:"type:text" json:"details,omitempty"`
+	Status     ReportStatus `gorm:"type:text;not null;default:'open';index" json:"status" enums:"open,resolved,dismissed"`
+	// Resolution is the moderator's note on how the report was handled
+	Resolution string     `gorm:"type:text" json:"resolution,omitempty"`
+	ResolvedBy *uuid.UUID `gorm:"type:uuid" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Report model
+func (Report) TableName() string {
+	return "content_reports"
+}
+
+// EvidenceSnapshot is a copy of reported content at one point in time. Snapshots
+// are kept per target rather than per report, so every report on the same
+// content shares its full edit history, and they are never modified or deleted
+// along with the content.
+type EvidenceSnapshot struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TargetType string    `gorm:"type:text;not null;index:idx_evidence_target" json:"target_type" enums:"video,comment"`
+	TargetID   uuid.UUID `gorm:"type:uuid;not null;index:idx_evidence_target" json:"target_id"`
+	// ReportID is set on the snapshot taken when that report was filed
+	ReportID *uuid.UUID `gorm:"type:uuid" json:"report_id,omitempty"`
+	Trigger  string     `gorm:"type:text;not null" json:"trigger" enums:"report,edit,delete"`
+	// Version numbers the snapshots of a target from 1
+	Version  int       `gorm:"not null" json:"version"`
+	AuthorID uuid.UUID `gorm:"type:uuid;not null" json:"author_id"`
+	// Title is the video title; empty for comments
+	Title string `gorm:"type:text" json:"title,omitempty"`
+	// Body is the video description or the comment text
+	Body string `gorm:"type:text" json:"body"`
+	// File references of a video, so the original upload can be retrieved
+	StoragePath string `gorm:"type:text" json:"storage_path,omitempty"`
+	IPFSCID     string `gorm:"column:ipfs_cid;type:text" json:"ipfs_cid,omitempty"`
+	Checksum    string `gorm:"type:text" json:"checksum,omitempty"`
+	// VideoID is the video a comment was posted on
+	VideoID *uuid.UUID `gorm:"type:uuid" json:"video_id,omitempty"`
+	// ContentUpdatedAt is when the content was last changed before the snapshot
+	ContentUpdatedAt time.Time `json:"content_updated_at"`
+	CapturedAt       time.Time `gorm:"not null;default:now()" json:"captured_at"`
+}
+
+// TableName specifies the table name for the EvidenceSnapshot model
+func (EvidenceSnapshot) TableName() string {
+	return "moderation_evidence"
+}
+
+// CreateReportRequest is the payload for reporting content
+type CreateReportRequest struct {
+	TargetType string    `json:"target_type" binding:"required,oneof=video comment" enums:"video,comment"`
+	TargetID   uuid.UUID `json:"target_id" binding:"required,uuid" swaggertype:"string" format:"uuid"`
+	Reason     string    `json:"reason" binding:"required,max=100" example:"harassment"`
+	Details    string    `json:"details" binding:"max=2000"`
+}
+
+// ResolveReportRequest is the payload for closing a report
+type ResolveReportRequest struct {
+	Status     ReportStatus `json:"status" binding:"required,oneof=resolved dismissed" enums:"resolved,dismissed"`
+	Resolution string       `json:"resolution" binding:"max=2000"`
+}
+
+// ReportDetail is a report together with the evidence captured for its content
+type ReportDetail struct {
+	Report
+	// Evidence lists every snapshot of the reported content, oldest first
+	Evidence []EvidenceSnapshot `json:"evidence"`
+}
+
+// ReportListResponse represents a paginated list of reports
+type ReportListResponse struct {
+	Reports []Report `json:"reports"`
+	Total   int64    `json:"total"`
+	Page    int      `json:"page"`
+	Limit   int      `json:"limit"`
+}
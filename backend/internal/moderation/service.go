@@ -0,0 +1,290 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db       *gorm.DB
+	comments CommentReader
+	logger   logger.Logger
+}
+
+// NewService creates a new moderation service. Comments cannot be reported
+// when comments is nil.
+func NewService(db *gorm.DB, comments CommentReader, logger logger.Logger) Service {
+	return &serviceImpl{
+		db:       db,
+		comments: comments,
+		logger:   logger,
+	}
+}
+
+// CreateReport files a report and snapshots the reported content
+func (s *serviceImpl) CreateReport(ctx context.Context, reporterID uuid.UUID, req *CreateReportRequest) (*Report, error) {
+	if !validTarget(req.TargetType) || req.TargetID == uuid.Nil || strings.TrimSpace(req.Reason) == "" {
+		return nil, ErrInvalidReport
+	}
+
+	snapshot, err := s.capture(ctx, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing int64
+	if err := s.db.WithContext(ctx).Model(&Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+			reporterID, req.TargetType, req.TargetID, StatusOpen).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check existing reports: %w", err)
+	}
+	if existing > 0 {
+		return nil, ErrDuplicateReport
+	}
+
+	report := &Report{
+		ID:         uuid.New(),
+		ReporterID: reporterID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     strings.TrimSpace(req.Reason),
+		Details:    req.Details,
+		Status:     StatusOpen,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(report).Error; err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		snapshot.ReportID = &report.ID
+		return saveSnapshot(tx, snapshot, TriggerReport)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.LogInfo("Content reported", map[string]interface{}{
+		"report_id":   report.ID,
+		"target_type": report.TargetType,
+		"target_id":   report.TargetID,
+		"reporter_id": reporterID,
+	})
+
+	return report, nil
+}
+
+// RecordChange snapshots content that has open reports
+func (s *serviceImpl) RecordChange(ctx context.Context, targetType string, targetID uuid.UUID, trigger string) error {
+	if !validTarget(targetType) {
+		return ErrInvalidReport
+	}
+
+	var open int64
+	if err := s.db.WithContext(ctx).Model(&Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, StatusOpen).
+		Count(&open).Error; err != nil {
+		return fmt.Errorf("failed to check reports: %w", err)
+	}
+	if open == 0 {
+		return nil
+	}
+
+	snapshot, err := s.capture(ctx, targetType, targetID)
+	if err != nil {
+		return err
+	}
+	if err := saveSnapshot(s.db.WithContext(ctx), snapshot, trigger); err != nil {
+		return err
+	}
+
+	s.logger.LogInfo("Captured evidence of reported content", map[string]interface{}{
+		"target_type": targetType,
+		"target_id":   targetID,
+		"trigger":     trigger,
+		"version":     snapshot.Version,
+	})
+
+	return nil
+}
+
+// ListReports returns reports, newest first
+func (s *serviceImpl) ListReports(ctx context.Context, status ReportStatus, page, limit int) (*ReportListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	query := s.db.WithContext(ctx).Model(&Report{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count reports: %w", err)
+	}
+
+	reports := make([]Report, 0, limit)
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	return &ReportListResponse{
+		Reports: reports,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}, nil
+}
+
+// GetReport returns a report with the evidence captured for its content
+func (s *serviceImpl) GetReport(ctx context.Context, id uuid.UUID) (*ReportDetail, error) {
+	var report Report
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+
+	evidence := make([]EvidenceSnapshot, 0)
+	if err := s.db.WithContext(ctx).
+		Where("target_type = ? AND target_id = ?", report.TargetType, report.TargetID).
+		Order("version ASC").
+		Find(&evidence).Error; err != nil {
+		return nil, fmt.Errorf("failed to load evidence: %w", err)
+	}
+
+	return &ReportDetail{Report: report, Evidence: evidence}, nil
+}
+
+// ResolveReport closes a report as resolved or dismissed
+func (s *serviceImpl) ResolveReport(ctx context.Context, moderatorID, id uuid.UUID, req *ResolveReportRequest) (*Report, error) {
+	if req.Status != StatusResolved && req.Status != StatusDismissed {
+		return nil, ErrInvalidResolution
+	}
+
+	var report Report
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to load report: %w", err)
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&report).Updates(map[string]interface{}{
+		"status":      req.Status,
+		"resolution":  req.Resolution,
+		"resolved_by": moderatorID,
+		"resolved_at": now,
+		"updated_at":  now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve report: %w", err)
+	}
+	report.Status = req.Status
+	report.Resolution = req.Resolution
+	report.ResolvedBy = &moderatorID
+	report.ResolvedAt = &now
+
+	s.logger.LogInfo("Report resolved", map[string]interface{}{
+		"report_id":    report.ID,
+		"status":       report.Status,
+		"moderator_id": moderatorID,
+	})
+
+	return &report, nil
+}
+
+// capture copies the current state of a video or comment into an unsaved snapshot
+func (s *serviceImpl) capture(ctx context.Context, targetType string, targetID uuid.UUID) (*EvidenceSnapshot, error) {
+	switch targetType {
+	case TargetVideo:
+		var v video.Video
+		if err := s.db.WithContext(ctx).Where("id = ?", targetID).First(&v).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTargetNotFound
+			}
+			return nil, fmt.Errorf("failed to load video: %w", err)
+		}
+		return &EvidenceSnapshot{
+			TargetType:       TargetVideo,
+			TargetID:         v.ID,
+			AuthorID:         v.UserID,
+			Title:            v.Title,
+			Body:             v.Description,
+			StoragePath:      v.StoragePath,
+			IPFSCID:          v.IPFSCID,
+			Checksum:         v.Checksum,
+			ContentUpdatedAt: v.UpdatedAt,
+		}, nil
+
+	case TargetComment:
+		if s.comments == nil {
+			return nil, ErrTargetNotFound
+		}
+		c, err := s.comments.GetCommentByID(ctx, targetID)
+		if errors.Is(err, comment.ErrCommentNotFound) {
+			return nil, ErrTargetNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load comment: %w", err)
+		}
+		if c == nil || c.DeletedAt != nil {
+			return nil, ErrTargetNotFound
+		}
+		videoID := c.VideoID
+		return &EvidenceSnapshot{
+			TargetType:       TargetComment,
+			TargetID:         c.ID,
+			AuthorID:         c.UserID,
+			Body:             c.Content,
+			VideoID:          &videoID,
+			ContentUpdatedAt: c.UpdatedAt,
+		}, nil
+	}
+
+	return nil, ErrInvalidReport
+}
+
+// saveSnapshot stores a snapshot as the next version of its target
+func saveSnapshot(tx *gorm.DB, snapshot *EvidenceSnapshot, trigger string) error {
+	var latest int
+	if err := tx.Model(&EvidenceSnapshot{}).
+		Where("target_type = ? AND target_id = ?", snapshot.TargetType, snapshot.TargetID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error; err != nil {
+		return fmt.Errorf("failed to number evidence snapshot: %w", err)
+	}
+
+	snapshot.ID = uuid.New()
+	snapshot.Trigger = trigger
+	snapshot.Version = latest + 1
+	snapshot.CapturedAt = time.Now()
+	if err := tx.Create(snapshot).Error; err != nil {
+		return fmt.Errorf("failed to store evidence snapshot: %w", err)
+	}
+	return nil
+}
+
+// validTarget reports whether targetType names content that can be reported
+func validTarget(targetType string) bool {
+	return targetType == TargetVideo || targetType == TargetComment
+}
@@ -0,0 +1,82 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubComments serves comments from memory
+type stubComments map[uuid.UUID]*comment.Comment
+
+func (s stubComments) GetCommentByID(ctx context.Context, id uuid.UUID) (*comment.Comment, error) {
+	c, ok := s[id]
+	if !ok {
+		return nil, comment.ErrCommentNotFound
+	}
+	return c, nil
+}
+
+func TestCreateReportValidation(t *testing.T) {
+	service := NewService(nil, nil, nil)
+
+	for _, req := range []CreateReportRequest{
+		{TargetType: "user", TargetID: uuid.New(), Reason: "spam"},
+		{TargetType: TargetVideo, Reason: "spam"},
+		{TargetType: TargetComment, TargetID: uuid.New(), Reason: "  "},
+	} {
+		_, err := service.CreateReport(context.Background(), uuid.New(), &req)
+		assert.ErrorIs(t, err, ErrInvalidReport)
+	}
+}
+
+func TestResolveReportRequiresOutcome(t *testing.T) {
+	service := NewService(nil, nil, nil)
+
+	for _, status := range []ReportStatus{"", StatusOpen, "closed"} {
+		_, err := service.ResolveReport(context.Background(), uuid.New(), uuid.New(), &ResolveReportRequest{Status: status})
+		assert.ErrorIs(t, err, ErrInvalidResolution)
+	}
+}
+
+func TestCaptureComment(t *testing.T) {
+	videoID := uuid.New()
+	deletedAt := time.Now()
+	live := &comment.Comment{
+		ID:        uuid.New(),
+		VideoID:   videoID,
+		UserID:    uuid.New(),
+		Content:   "original text",
+		UpdatedAt: time.Now().Add(-time.Hour),
+	}
+	deleted := &comment.Comment{ID: uuid.New(), VideoID: videoID, DeletedAt: &deletedAt}
+
+	service := &serviceImpl{comments: stubComments{live.ID: live, deleted.ID: deleted}}
+
+	t.Run("Live comment", func(t *testing.T) {
+		snapshot, err := service.capture(context.Background(), TargetComment, live.ID)
+		require.NoError(t, err)
+		assert.Equal(t, live.UserID, snapshot.AuthorID)
+		assert.Equal(t, "original text", snapshot.Body)
+		assert.Equal(t, &videoID, snapshot.VideoID)
+		assert.Equal(t, live.UpdatedAt, snapshot.ContentUpdatedAt)
+	})
+
+	t.Run("Deleted or missing comment", func(t *testing.T) {
+		_, err := service.capture(context.Background(), TargetComment, deleted.ID)
+		assert.ErrorIs(t, err, ErrTargetNotFound)
+
+		_, err = service.capture(context.Background(), TargetComment, uuid.New())
+		assert.ErrorIs(t, err, ErrTargetNotFound)
+	})
+
+	t.Run("Comments not configured", func(t *testing.T) {
+		_, err := (&serviceImpl{}).capture(context.Background(), TargetComment, live.ID)
+		assert.ErrorIs(t, err, ErrTargetNotFound)
+	})
+}
@@ -0,0 +1,179 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SampleActors is how many of the most recent actors a grouped notification lists
+const SampleActors = 3
+
+// aggregateScanLimit bounds how many of a user's recent notifications are
+// searched for a group to add to
+const aggregateScanLimit = 500
+
+// groupedBy names the metadata field holding the object that notifications of
+// each type are grouped on. Other types are always stored one by one.
+var groupedBy = map[EventType]string{
+	CommentCreated:  "videoId",
+	CommentReplied:  "parentId",
+	CommentReaction: "commentId",
+	UserFollowed:    "targetUserId",
+}
+
+// Aggregator stores notifications, collapsing events of the same type on the
+// same object into one unread notification for as long as the window after
+// it was created. The grouped notification is updated in place with the
+// number of actors and the most recent few of them.
+type Aggregator struct {
+	repository NotificationRepository
+	window     time.Duration
+	now        TimeFunc
+
+	// mu keeps two events for the same group from both starting one. Events
+	// handled by different instances may still do so.
+	mu sync.Mutex
+}
+
+// NewAggregator creates an aggregator over repository; a window of 0 stores
+// every notification on its own
+func NewAggregator(repository NotificationRepository, window time.Duration) *Aggregator {
+	return &Aggregator{
+		repository: repository,
+		window:     window,
+		now:        time.Now,
+	}
+}
+
+// SetClock replaces the clock used to find groups still within the window
+func (a *Aggregator) SetClock(now TimeFunc) {
+	a.now = now
+}
+
+// Save stores notification, or adds actorID to the unread notification it
+// groups with. Events without an actor are stored on their own.
+func (a *Aggregator) Save(ctx context.Context, notification *Notification, actorID uuid.UUID) error {
+	key := groupKey(notification)
+	if a.window <= 0 || key == "" || actorID == uuid.Nil {
+		return a.repository.SaveNotification(ctx, notification)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	group, err := a.findGroup(ctx, notification.UserID, key)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		notification.Metadata["groupKey"] = key
+		notification.Metadata["actorCount"] = 1
+		notification.Metadata["actors"] = []string{actorID.String()}
+		return a.repository.SaveNotification(ctx, notification)
+	}
+
+	actors := metadataStrings(group.Metadata["actors"])
+	for _, actor := range actors {
+		if actor == actorID.String() {
+			// A recent actor acting again, such as reacting twice, is not
+			// counted twice. Only the sampled actors are checked.
+			return nil
+		}
+	}
+	actors = append([]string{actorID.String()}, actors...)
+	if len(actors) > SampleActors {
+		actors = actors[:SampleActors]
+	}
+
+	count := metadataInt(group.Metadata["actorCount"]) + 1
+	group.Metadata["actorCount"] = count
+	group.Metadata["actors"] = actors
+	group.Content = groupedContent(group.Type, count)
+	return a.repository.UpdateNotification(ctx, group)
+}
+
+// findGroup returns the user's newest unread notification in the group
+// created within the window, or nil when there is none
+func (a *Aggregator) findGroup(ctx context.Context, userID uuid.UUID, key string) (*Notification, error) {
+	recent, err := a.repository.GetNotificationsSince(ctx, userID, a.now().Add(-a.window), aggregateScanLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification group: %w", err)
+	}
+
+	// Oldest first, so the last match is the newest
+	var group *Notification
+	for _, n := range recent {
+		if !n.IsRead() && n.Metadata["groupKey"] == key {
+			group = n
+		}
+	}
+	return group, nil
+}
+
+// groupKey identifies the notifications a notification is grouped with, or
+// is empty when it is not grouped
+func groupKey(notification *Notification) string {
+	field, ok := groupedBy[notification.Type]
+	if !ok || notification.Metadata == nil {
+		return ""
+	}
+	object, _ := notification.Metadata[field].(string)
+	if object == "" {
+		return ""
+	}
+	return string(notification.Type) + ":" + object
+}
+
+// groupedContent describes a notification grouping count actors
+func groupedContent(eventType EventType, count int) string {
+	switch eventType {
+	case CommentCreated:
+		return fmt.Sprintf("%d people commented on your video", count)
+	case CommentReplied:
+		return fmt.Sprintf("%d people replied to your comment", count)
+	case CommentReaction:
+		return fmt.Sprintf("%d people reacted to your comment", count)
+	case UserFollowed:
+		return fmt.Sprintf("%d people started following you", count)
+	default:
+		return fmt.Sprintf("%d new notifications", count)
+	}
+}
+
+// metadataInt reads a count from metadata, which holds float64 once it has
+// been stored as JSON
+func metadataInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// metadataStrings reads a list of strings from metadata, which holds
+// []interface{} once it has been stored as JSON
+func metadataStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return append([]string(nil), v...)
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
+	"github.com/google/uuid"
+)
+
+// DefaultCommentCountSubscription is the comment events subscription that
+// keeps video comment counts in line
+const DefaultCommentCountSubscription = "video-comment-counts"
+
+// CommentCountUpdater adds delta to a video's comment count
+type CommentCountUpdater interface {
+	AdjustCommentCount(ctx context.Context, videoID uuid.UUID, delta int) error
+}
+
+// CommentCountDelta returns how much a comment event changes the comment
+// count of its video: comments and replies add one, deletions take one away
+// and every other event leaves the count alone
+func CommentCountDelta(event *CommentEvent) int {
+	if event.VideoID == uuid.Nil {
+		return 0
+	}
+	switch event.Type {
+	case CommentCreated, CommentReplied:
+		return 1
+	case CommentDeleted:
+		return -1
+	}
+	return 0
+}
+
+// CommentCountConsumer reads the comment events topic and applies each
+// event's delta to its video's comment count. Events that fail to apply are
+// redelivered and, once the retries are used up, dead-lettered.
+type CommentCountConsumer struct {
+	consumer pulsar.Consumer
+	counts   CommentCountUpdater
+	logger   logger.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCommentCountConsumer subscribes to the comment events topic under
+// subscription, DefaultCommentCountSubscription if empty
+func (s *Service) NewCommentCountConsumer(subscription string, counts CommentCountUpdater) (*CommentCountConsumer, error) {
+	if s.pulsarClient == nil {
+		return nil, fmt.Errorf("notification service is disabled")
+	}
+	if subscription == "" {
+		subscription = DefaultCommentCountSubscription
+	}
+
+	options := pulsar.ConsumerOptions{
+		Topic:                       s.config.CommentEventsTopic,
+		SubscriptionName:            subscription,
+		Type:                        pulsar.Shared,
+		SubscriptionInitialPosition: pulsar.SubscriptionPositionEarliest,
+	}
+	if s.config.RetryEnabled && s.config.MaxRetries > 0 {
+		options.DLQ = &pulsar.DLQPolicy{
+			MaxDeliveries:   uint32(s.config.MaxRetries),
+			DeadLetterTopic: s.config.DeadLetterTopic,
+		}
+	}
+	consumer, err := s.pulsarClient.Subscribe(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to comment events: %w", err)
+	}
+	return &CommentCountConsumer{consumer: consumer, counts: counts, logger: s.logger}, nil
+}
+
+// Start consumes comment events until Stop is called
+func (c *CommentCountConsumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			msg, err := c.consumer.Receive(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.logger.LogError(err, "Failed to receive comment event")
+				continue
+			}
+			c.handle(ctx, msg)
+		}
+	}()
+}
+
+// Stop stops consuming, waits for the event in hand and closes the subscription
+func (c *CommentCountConsumer) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	c.consumer.Close()
+}
+
+// handle applies one message and acknowledges it, or asks for it again when
+// the count could not be updated
+func (c *CommentCountConsumer) handle(ctx context.Context, msg pulsar.Message) {
+	ctx, span := tracing.StartConsumer(ctx, msg)
+	defer span.End()
+
+	var event CommentEvent
+	if err := json.Unmarshal(msg.Payload(), &event); err != nil {
+		c.logger.LogWarn("Skipped malformed comment event", map[string]interface{}{
+			"messageId": msg.ID().String(),
+			"error":     err.Error(),
+		})
+		c.ack(msg)
+		return
+	}
+
+	if delta := CommentCountDelta(&event); delta != 0 {
+		if err := c.counts.AdjustCommentCount(ctx, event.VideoID, delta); err != nil {
+			if ctx.Err() == nil {
+				c.logger.LogError(err, "Failed to update video comment count")
+			}
+			c.consumer.Nack(msg)
+			return
+		}
+	}
+	c.ack(msg)
+}
+
+func (c *CommentCountConsumer) ack(msg pulsar.Message) {
+	if err := c.consumer.Ack(msg); err != nil {
+		c.logger.LogWarn("Failed to acknowledge comment event", map[string]interface{}{
+			"messageId": msg.ID().String(),
+			"error":     err.Error(),
+		})
+	}
+}
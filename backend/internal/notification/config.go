@@ -41,6 +41,12 @@ type ServiceConfig struct {
 	BackoffInitial    time.Duration
 	BackoffMax        time.Duration
 	BackoffMultiplier float64
+	
+	// Monitoring
+	LagPollInterval   time.Duration
+
+	// Aggregation; 0 stores every event as its own notification
+	AggregationWindow time.Duration
 }
 
 // NewServiceConfigFromConfig creates a notification service config from the application config
@@ -71,6 +77,8 @@ func NewServiceConfigFromConfig(cfg *config.Config) *ServiceConfig {
 		BackoffInitial:      cfg.Notification.BackoffInitial,
 		BackoffMax:          cfg.Notification.BackoffMax,
 		BackoffMultiplier:   cfg.Notification.BackoffMultiplier,
+		LagPollInterval:     cfg.Notification.LagPollInterval,
+		AggregationWindow:   cfg.Notification.AggregationWindow,
 	}
 }
 
@@ -103,5 +111,9 @@ func DefaultConfig() *ServiceConfig {
 		BackoffInitial:      1 * time.Second,
 		BackoffMax:          60 * time.Second,
 		BackoffMultiplier:   2.0,
+		
+		LagPollInterval:     30 * time.Second,
+
+		AggregationWindow:   time.Hour,
 	}
 }
\ No newline at end of file
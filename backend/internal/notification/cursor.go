@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = apierror.New(apierror.CodeInvalidParameter, "invalid notification cursor")
+
+// Cursor marks the position of the last notification returned in a page.
+// Notifications are ordered by created_at DESC within a user's partition, so the
+// next page starts strictly after this (created_at, id) pair.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ListOptions controls which notifications are returned for a user
+type ListOptions struct {
+	// Maximum number of notifications to return
+	Limit int
+	// Position to resume from; nil starts from the newest notification
+	Cursor *Cursor
+	// Only return notifications that have not been read yet
+	UnreadOnly bool
+}
+
+// NotificationPage is a single page of notifications for a user
+// @Description A page of notifications with the cursor for the next page and the total unread count
+type NotificationPage struct {
+	// Notifications in this page, newest first
+	Notifications []*Notification `json:"notifications"`
+	// Opaque cursor for the next page, empty when there are no more results
+	NextCursor string `json:"nextCursor,omitempty" example:"MTc0MTIwOTk2NjAwMDAwMDAwMHw1NTBlODQwMC1lMjliLTQxZDQtYTcxNi00NDY2NTU0NDAwMDA"`
+	// Whether more notifications are available after this page
+	HasMore bool `json:"hasMore" example:"true"`
+	// Total number of unread notifications for the user
+	TotalUnread int `json:"totalUnread" example:"3"`
+}
+
+// NewCursor creates a cursor pointing at the given notification
+func NewCursor(n *Notification) *Cursor {
+	return &Cursor{CreatedAt: n.CreatedAt, ID: n.ID}
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *Cursor) Encode() string {
+	raw := fmt.Sprintf("%d|%s", c.CreatedAt.UnixNano(), c.ID.String())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor previously produced by Cursor.Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// CursorFilter drops rows that were already returned before the cursor position.
+// Rows sharing the cursor's created_at are skipped up to and including the
+// cursor's own ID, since clustering order among ties is stable.
+type CursorFilter struct {
+	cursor   *Cursor
+	skipping bool
+}
+
+// NewCursorFilter creates a filter for rows read in clustering order; a nil cursor skips nothing
+func NewCursorFilter(cursor *Cursor) *CursorFilter {
+	return &CursorFilter{cursor: cursor, skipping: cursor != nil}
+}
+
+// Skip returns true if the row at (createdAt, id) was already returned in a previous page
+func (f *CursorFilter) Skip(createdAt time.Time, id uuid.UUID) bool {
+	if !f.skipping {
+		return false
+	}
+	if createdAt.After(f.cursor.CreatedAt) {
+		return true
+	}
+	if createdAt.Equal(f.cursor.CreatedAt) {
+		if id == f.cursor.ID {
+			f.skipping = false
+		}
+		return true
+	}
+	f.skipping = false
+	return false
+}
@@ -0,0 +1,428 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/mail"
+	"github.com/google/uuid"
+)
+
+// maxDigestNotifications caps the notifications a single digest counts and
+// marks; the oldest are digested first and the rest go into the next digest
+const maxDigestNotifications = 500
+
+// digestClaimTTL is how long an instance holds a user's due digest. It
+// outlasts sending one, after which the period has moved on; a digest that
+// failed is retried by any instance once the claim expires.
+const digestClaimTTL = 10 * time.Minute
+
+// DigestFrequency is how often a user is emailed a digest
+type DigestFrequency string
+
+// Digest frequencies
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// Period returns the time a digest of this frequency covers, or 0 when off
+func (f DigestFrequency) Period() time.Duration {
+	switch f {
+	case DigestDaily:
+		return 24 * time.Hour
+	case DigestWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// IsValid reports whether f is a known frequency
+func (f DigestFrequency) IsValid() bool {
+	return f == DigestOff || f == DigestDaily || f == DigestWeekly
+}
+
+// DigestPreferences controls how often a user is emailed their unread notifications
+// @Description How often unread notifications are emailed as a digest
+type DigestPreferences struct {
+	// off, daily or weekly
+	Frequency DigestFrequency `json:"frequency" binding:"required" enums:"off,daily,weekly" example:"daily"`
+	// When the next digest is due; unset while digests are off
+	NextDigestAt *time.Time `json:"nextDigestAt,omitempty"`
+}
+
+// DigestSubscription is a user's stored digest preference
+type DigestSubscription struct {
+	UserID    uuid.UUID
+	Frequency DigestFrequency
+	// PeriodStart is when the period the next digest covers began
+	PeriodStart time.Time
+}
+
+// due returns the end of the latest whole period since PeriodStart, and
+// whether a period has ended by now
+func (s *DigestSubscription) due(now time.Time) (time.Time, bool) {
+	period := s.Frequency.Period()
+	if period <= 0 || now.Before(s.PeriodStart.Add(period)) {
+		return time.Time{}, false
+	}
+	periods := now.Sub(s.PeriodStart) / period
+	return s.PeriodStart.Add(periods * period), true
+}
+
+// DigestStore stores digest subscriptions and which notifications were digested
+type DigestStore interface {
+	// GetDigestSubscription returns nil when the user has not set a frequency
+	GetDigestSubscription(ctx context.Context, userID uuid.UUID) (*DigestSubscription, error)
+	SaveDigestSubscription(ctx context.Context, subscription *DigestSubscription) error
+	ListDigestSubscriptions(ctx context.Context, frequency DigestFrequency) ([]*DigestSubscription, error)
+	// ListUndigested returns the user's unread notifications created after
+	// since that no digest included yet, oldest first
+	ListUndigested(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*Notification, error)
+	// MarkDigested records that a digest sent at included the notifications
+	MarkDigested(ctx context.Context, notifications []*Notification, at time.Time) error
+}
+
+// DigestRecipients looks up where users' digests are sent
+type DigestRecipients interface {
+	// DigestRecipient returns the user's name and email address; ok is false
+	// for users who should not be emailed, such as unverified or suspended ones
+	DigestRecipient(ctx context.Context, userID uuid.UUID) (name, email string, ok bool, err error)
+}
+
+// DigestClaimer claims keys, so that when several instances run the
+// scheduler only one of them sends each digest
+type DigestClaimer interface {
+	// SetNX stores key unless it exists, reporting whether it was stored
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+}
+
+// DigestConfig contains the settings of email digests
+type DigestConfig struct {
+	// MaxItems is how many notifications a digest lists; the rest are counted
+	MaxItems int
+	// URL of the notifications page linked from digests
+	URL string
+}
+
+// DigestService keeps users' digest preferences and emails each subscribed
+// user a summary of the notifications they have not read once their period
+// ends. Notifications a digest included are marked, so they are not sent
+// again even while they stay unread.
+type DigestService struct {
+	store      DigestStore
+	recipients DigestRecipients
+	mailer     mail.Mailer
+	config     DigestConfig
+	logger     logger.Logger
+	claimer    DigestClaimer
+	now        TimeFunc
+}
+
+// NewDigestService creates a digest service
+func NewDigestService(store DigestStore, recipients DigestRecipients, mailer mail.Mailer, config DigestConfig, logger logger.Logger) *DigestService {
+	if config.MaxItems < 1 {
+		config.MaxItems = 20
+	}
+	return &DigestService{
+		store:      store,
+		recipients: recipients,
+		mailer:     mailer,
+		config:     config,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// SetClaimer makes instances claim each digest before sending it; without
+// one, run a single scheduler
+func (d *DigestService) SetClaimer(claimer DigestClaimer) {
+	d.claimer = claimer
+}
+
+// SetClock replaces the clock periods are measured with, for tests
+func (d *DigestService) SetClock(now TimeFunc) {
+	d.now = now
+}
+
+// Preferences returns the user's digest preferences; digests are off by default
+func (d *DigestService) Preferences(ctx context.Context, userID uuid.UUID) (*DigestPreferences, error) {
+	subscription, err := d.store.GetDigestSubscription(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		return &DigestPreferences{Frequency: DigestOff}, nil
+	}
+	return subscription.preferences(), nil
+}
+
+// UpdatePreferences sets how often the user receives digests. Choosing a
+// new frequency starts its first period now; choosing the current one
+// keeps the period running.
+func (d *DigestService) UpdatePreferences(ctx context.Context, userID uuid.UUID, preferences *DigestPreferences) error {
+	if !preferences.Frequency.IsValid() {
+		return apierror.New(apierror.CodeValidation, fmt.Sprintf("unknown digest frequency %q; use off, daily or weekly", preferences.Frequency))
+	}
+
+	subscription, err := d.store.GetDigestSubscription(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if subscription == nil || subscription.Frequency != preferences.Frequency {
+		subscription = &DigestSubscription{
+			UserID:      userID,
+			Frequency:   preferences.Frequency,
+			PeriodStart: d.now().UTC(),
+		}
+		if err := d.store.SaveDigestSubscription(ctx, subscription); err != nil {
+			return err
+		}
+	}
+	*preferences = *subscription.preferences()
+	return nil
+}
+
+// SendDue emails every user whose period has ended their digest. Failures
+// are logged and the user is tried again on the next run.
+func (d *DigestService) SendDue(ctx context.Context) {
+	for _, frequency := range []DigestFrequency{DigestDaily, DigestWeekly} {
+		subscriptions, err := d.store.ListDigestSubscriptions(ctx, frequency)
+		if err != nil {
+			d.logger.LogError(err, "Failed to list digest subscriptions")
+			continue
+		}
+		for _, subscription := range subscriptions {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := d.send(ctx, subscription); err != nil {
+				d.logger.LogWarn("Failed to send notification digest", map[string]interface{}{
+					"user_id":   subscription.UserID.String(),
+					"frequency": string(subscription.Frequency),
+					"error":     err.Error(),
+				})
+			}
+		}
+	}
+}
+
+// send emails one user's digest if their period has ended, then starts
+// the next period. Periods without unread notifications send nothing.
+func (d *DigestService) send(ctx context.Context, subscription *DigestSubscription) error {
+	now := d.now().UTC()
+	periodEnd, ok := subscription.due(now)
+	if !ok {
+		return nil
+	}
+	if d.claimer != nil {
+		key := fmt.Sprintf("notifications:digest:%s:%d", subscription.UserID, periodEnd.Unix())
+		claimed, err := d.claimer.SetNX(ctx, key, 1, digestClaimTTL)
+		if err != nil || !claimed {
+			return err
+		}
+	}
+
+	// Look back a further period for notifications a full digest left out
+	since := subscription.PeriodStart.Add(-subscription.Frequency.Period())
+	notifications, err := d.store.ListUndigested(ctx, subscription.UserID, since, maxDigestNotifications)
+	if err != nil {
+		return err
+	}
+	if len(notifications) > 0 {
+		name, email, ok, err := d.recipients.DigestRecipient(ctx, subscription.UserID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			subject, body, err := d.render(subscription.Frequency, name, notifications)
+			if err != nil {
+				return err
+			}
+			if err := d.mailer.Send(ctx, email, subject, body); err != nil {
+				return err
+			}
+			if err := d.store.MarkDigested(ctx, notifications, now); err != nil {
+				return err
+			}
+			d.logger.LogInfo("Notification digest sent", map[string]interface{}{
+				"user_id":       subscription.UserID.String(),
+				"frequency":     string(subscription.Frequency),
+				"notifications": len(notifications),
+			})
+		}
+	}
+
+	subscription.PeriodStart = periodEnd
+	return d.store.SaveDigestSubscription(ctx, subscription)
+}
+
+// preferences returns the subscription as the preferences shown to its user
+func (s *DigestSubscription) preferences() *DigestPreferences {
+	preferences := &DigestPreferences{Frequency: s.Frequency}
+	if period := s.Frequency.Period(); period > 0 {
+		next := s.PeriodStart.Add(period)
+		preferences.NextDigestAt = &next
+	}
+	return preferences
+}
+
+// digestSubjectTemplate and digestBodyTemplate render digest emails from a digestData
+var (
+	digestSubjectTemplate = template.Must(template.New("digest-subject").Parse(
+		`Your {{.Frequency}} Pavilion digest: {{.Total}} unread notification{{if ne .Total 1}}s{{end}}`))
+
+	digestBodyTemplate = template.Must(template.New("digest-body").Parse(`Hi {{.Name}},
+
+Here is what happened on Pavilion in the past {{.Period}} that you have not read yet.
+{{range .Types}}
+  {{.Count}} × {{.Label}}{{end}}
+
+{{range .Items}}- {{.Content}} ({{.CreatedAt.Format "Jan 2, 15:04 MST"}})
+{{end}}{{if .More}}...and {{.More}} more.
+{{end}}{{if .URL}}
+See them all at {{.URL}}
+{{end}}
+You receive this digest {{.Frequency}} because you turned it on. To change how
+often, or to stop it, update your notification digest preferences.
+`))
+)
+
+// digestLabels name the notification types in digests
+var digestLabels = map[EventType]string{
+	VideoUploaded:        "videos uploaded",
+	VideoProcessed:       "videos ready",
+	VideoUpdated:         "video updates",
+	VideoDeleted:         "videos removed",
+	FollowedUserUploaded: "new videos from people you follow",
+	LiveStarted:          "live streams",
+	CommentCreated:       "comments",
+	CommentReplied:       "replies",
+	CommentReaction:      "reactions",
+	UserFollowed:         "new followers",
+	UserMentioned:        "mentions",
+	AuthEvent:            "account alerts",
+}
+
+// digestData is what digest templates are rendered with
+type digestData struct {
+	Name      string
+	Frequency DigestFrequency
+	Period    string
+	Total     int
+	Types     []digestType
+	Items     []*Notification
+	More      int
+	URL       string
+}
+
+// digestType counts the digest's notifications of one type
+type digestType struct {
+	Label string
+	Count int
+}
+
+// render returns the subject and body of a digest of notifications
+func (d *DigestService) render(frequency DigestFrequency, name string, notifications []*Notification) (string, string, error) {
+	data := digestData{
+		Name:      name,
+		Frequency: frequency,
+		Period:    "day",
+		Total:     len(notifications),
+		URL:       d.config.URL,
+	}
+	if frequency == DigestWeekly {
+		data.Period = "week"
+	}
+
+	counts := make(map[string]int)
+	for _, notification := range notifications {
+		label, ok := digestLabels[notification.Type]
+		if !ok {
+			label = "other notifications"
+		}
+		counts[label]++
+	}
+	for label, count := range counts {
+		data.Types = append(data.Types, digestType{Label: label, Count: count})
+	}
+	sort.Slice(data.Types, func(i, j int) bool {
+		if data.Types[i].Count != data.Types[j].Count {
+			return data.Types[i].Count > data.Types[j].Count
+		}
+		return data.Types[i].Label < data.Types[j].Label
+	})
+
+	// List the most recent ones
+	data.Items = make([]*Notification, 0, d.config.MaxItems)
+	for i := len(notifications) - 1; i >= 0 && len(data.Items) < d.config.MaxItems; i-- {
+		data.Items = append(data.Items, notifications[i])
+	}
+	data.More = len(notifications) - len(data.Items)
+
+	var subject, body bytes.Buffer
+	if err := digestSubjectTemplate.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("failed to render digest subject: %w", err)
+	}
+	if err := digestBodyTemplate.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return subject.String(), body.String(), nil
+}
+
+// DigestScheduler runs a digest service's due digests at an interval
+type DigestScheduler struct {
+	service  *DigestService
+	interval time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDigestScheduler creates a scheduler that checks for due digests every interval
+func NewDigestScheduler(service *DigestService, interval time.Duration) *DigestScheduler {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	return &DigestScheduler{service: service, interval: interval}
+}
+
+// Start sends due digests until Stop is called
+func (s *DigestScheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.service.SendDue(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.service.SendDue(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler and waits for a run in progress to finish
+func (s *DigestScheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
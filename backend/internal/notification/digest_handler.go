@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/gin-gonic/gin"
+)
+
+// SetDigest enables the email digest routes
+func (h *Handler) SetDigest(digest *DigestService) {
+	h.digest = digest
+}
+
+// registerDigestRoutes registers the email digest routes on the authenticated notifications group
+func (h *Handler) registerDigestRoutes(notifications gin.IRouter) {
+	if h.digest == nil {
+		return
+	}
+	digest := notifications.Group("/digest")
+	digest.GET("/preferences", h.handleGetDigestPreferences)
+	digest.PUT("/preferences", h.handleUpdateDigestPreferences)
+}
+
+// @Summary Get digest preferences
+// @Description Get how often the authenticated user is emailed a digest of their unread notifications, and when the next one is due
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=DigestPreferences} "Digest preferences retrieved successfully"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/digest/preferences [get]
+func (h *Handler) handleGetDigestPreferences(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.digest.Preferences(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to retrieve digest preferences"), "")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, preferences, "Digest preferences retrieved successfully")
+}
+
+// @Summary Update digest preferences
+// @Description Receive a daily or weekly email of the notifications that are still unread, or turn digests off. A new frequency starts its first period now. Only verified email addresses receive digests.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body DigestPreferences true "New digest frequency; nextDigestAt is ignored"
+// @Success 200 {object} httpHandler.APIResponse{data=DigestPreferences} "Digest preferences updated"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unknown frequency"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/digest/preferences [put]
+func (h *Handler) handleUpdateDigestPreferences(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	var preferences DigestPreferences
+	if err := httpHandler.Bind(c, &preferences); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	if err := h.digest.UpdatePreferences(c.Request.Context(), userID, &preferences); err != nil {
+		abortPushError(c, err, "Failed to update digest preferences")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, preferences, "Digest preferences updated")
+}
@@ -0,0 +1,197 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
+	"github.com/google/uuid"
+)
+
+// DefaultFollowerFanoutSubscription is the video events subscription that
+// notifies followers of uploads and live streams
+const DefaultFollowerFanoutSubscription = "follower-fanout"
+
+// followerNotificationID derives the ID of a follower's notification from
+// the event, so a redelivered event finds the notifications it already stored
+func followerNotificationID(eventID, followerID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(eventID, followerID[:])
+}
+
+// NotifyFollowers stores a notification for every follower of the user who
+// uploaded a video or went live, and returns how many it stored. Followers
+// who already have the event's notification are skipped, so it can be run
+// again after a failure.
+func (s *Service) NotifyFollowers(ctx context.Context, event *VideoEvent) (int, error) {
+	if s.followers == nil || s.repository == nil {
+		return 0, nil
+	}
+	if event.Type != VideoUploaded && event.Type != LiveStarted {
+		return 0, nil
+	}
+
+	followerType := FollowedUserUploaded
+	content := fmt.Sprintf("A creator you follow uploaded '%s'", event.Title)
+	if event.Type == LiveStarted {
+		followerType = LiveStarted
+		content = fmt.Sprintf("A creator you follow is live: '%s'", event.Title)
+	}
+
+	followerIDs, err := s.followers.GetFollowerIDs(ctx, event.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load followers: %w", err)
+	}
+
+	stored := 0
+	for _, followerID := range followerIDs {
+		if s.blocked(ctx, followerID, event.UserID) {
+			continue
+		}
+
+		id := followerNotificationID(event.ID, followerID)
+		if _, err := s.repository.GetNotification(ctx, id); err == nil {
+			continue
+		} else if !errors.Is(err, ErrNotificationNotFound) {
+			return stored, fmt.Errorf("failed to look up follower notification: %w", err)
+		}
+
+		notification := &Notification{
+			ID:        id,
+			UserID:    followerID,
+			Type:      followerType,
+			Content:   content,
+			Metadata:  createMetadataFromVideoEvent(event),
+			CreatedAt: event.CreatedAt,
+		}
+		notification.Metadata["eventType"] = string(followerType)
+
+		if err := s.repository.SaveNotification(ctx, notification); err != nil {
+			return stored, fmt.Errorf("failed to save follower notification: %w", err)
+		}
+		stored++
+	}
+	return stored, nil
+}
+
+// FollowerFanoutConsumer reads the video events topic and, for each
+// VIDEO_UPLOADED or LIVE_STARTED event, notifies the creator's followers.
+// Keeping the fan-out here leaves uploads unaffected by how many followers a
+// channel has. Events that fail to apply are redelivered and, once the
+// retries are used up, dead-lettered.
+type FollowerFanoutConsumer struct {
+	consumer pulsar.Consumer
+	service  *Service
+	logger   logger.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewFollowerFanoutConsumer subscribes to the video events topic under
+// subscription, DefaultFollowerFanoutSubscription if empty
+func (s *Service) NewFollowerFanoutConsumer(subscription string) (*FollowerFanoutConsumer, error) {
+	if s.pulsarClient == nil {
+		return nil, fmt.Errorf("notification service is disabled")
+	}
+	if subscription == "" {
+		subscription = DefaultFollowerFanoutSubscription
+	}
+
+	options := pulsar.ConsumerOptions{
+		Topic:                       s.config.VideoEventsTopic,
+		SubscriptionName:            subscription,
+		Type:                        pulsar.Shared,
+		SubscriptionInitialPosition: pulsar.SubscriptionPositionEarliest,
+	}
+	if s.config.RetryEnabled && s.config.MaxRetries > 0 {
+		options.DLQ = &pulsar.DLQPolicy{
+			MaxDeliveries:   uint32(s.config.MaxRetries),
+			DeadLetterTopic: s.config.DeadLetterTopic,
+		}
+	}
+	consumer, err := s.pulsarClient.Subscribe(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to video events: %w", err)
+	}
+	return &FollowerFanoutConsumer{consumer: consumer, service: s, logger: s.logger}, nil
+}
+
+// Start consumes video events until Stop is called
+func (c *FollowerFanoutConsumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			msg, err := c.consumer.Receive(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.logger.LogError(err, "Failed to receive video event")
+				continue
+			}
+			c.handle(ctx, msg)
+		}
+	}()
+}
+
+// Stop stops consuming, waits for the event in hand and closes the subscription
+func (c *FollowerFanoutConsumer) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	c.consumer.Close()
+}
+
+// handle notifies the followers of one upload or live stream and
+// acknowledges the message, or asks for it again when the fan-out could not
+// finish
+func (c *FollowerFanoutConsumer) handle(ctx context.Context, msg pulsar.Message) {
+	ctx, span := tracing.StartConsumer(ctx, msg)
+	defer span.End()
+
+	var event VideoEvent
+	if err := json.Unmarshal(msg.Payload(), &event); err != nil {
+		c.logger.LogWarn("Skipped malformed video event", map[string]interface{}{
+			"messageId": msg.ID().String(),
+			"error":     err.Error(),
+		})
+		c.ack(msg)
+		return
+	}
+
+	stored, err := c.service.NotifyFollowers(ctx, &event)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.LogError(err, "Failed to notify followers")
+		}
+		c.consumer.Nack(msg)
+		return
+	}
+	if stored > 0 {
+		c.logger.LogInfo("Notified followers", map[string]interface{}{
+			"eventType":     event.Type,
+			"videoId":       event.VideoID.String(),
+			"notifications": stored,
+		})
+	}
+	c.ack(msg)
+}
+
+func (c *FollowerFanoutConsumer) ack(msg pulsar.Message) {
+	if err := c.consumer.Ack(msg); err != nil {
+		c.logger.LogWarn("Failed to acknowledge video event", map[string]interface{}{
+			"messageId": msg.ID().String(),
+			"error":     err.Error(),
+		})
+	}
+}
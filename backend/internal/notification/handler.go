@@ -1,20 +1,27 @@
 package notification
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// maxNotificationsLimit caps the page size a client can request
+const maxNotificationsLimit = 100
+
 // Handler handles HTTP requests for notification endpoints
 type Handler struct {
 	service         NotificationService
 	responseHandler httpHandler.ResponseHandler
 	logger          logger.Logger
+	push            *PushService
+	digest          *DigestService
 }
 
 // NewHandler creates a new notification handler instance
@@ -27,29 +34,35 @@ func NewHandler(service NotificationService, responseHandler httpHandler.Respons
 }
 
 // RegisterRoutes registers all notification routes
-func (h *Handler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
 	// All notification routes require authentication
-	notifications := router.Group("/api/v1/notifications")
+	notifications := router.Group("/notifications")
 	notifications.Use(authMiddleware)
 	{
 		notifications.GET("/", h.handleGetNotifications)
 		notifications.GET("/unread-count", h.handleGetUnreadCount)
 		notifications.PUT("/:id/read", h.handleMarkAsRead)
 		notifications.PUT("/read-all", h.handleMarkAllAsRead)
+		notifications.GET("/read-state", h.handleGetReadState)
+		notifications.PUT("/read-state", h.handleUpdateReadState)
+		h.registerPushRoutes(notifications)
+		h.registerDigestRoutes(notifications)
 	}
 }
 
 // @Summary Get user notifications
-// @Description Retrieve a paginated list of notifications for the authenticated user
+// @Description Retrieve a cursor-paginated list of notifications for the authenticated user, along with the total unread count
 // @Tags notifications
 // @Produce json
 // @Security BearerAuth
-// @Param limit query int false "Number of notifications to return (default: 10)"
-// @Param page query int false "Page number for pagination (default: 1)"
-// @Success 200 {object} httpHandler.APIResponse{data=[]Notification} "Notifications retrieved successfully"
+// @Param limit query int false "Number of notifications to return (default: 10, max: 100)"
+// @Param cursor query string false "Cursor returned as nextCursor by the previous page"
+// @Param unreadOnly query bool false "Only return unread notifications (default: false)"
+// @Success 200 {object} httpHandler.APIResponse{data=NotificationPage} "Notifications retrieved successfully"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid query parameter"
 // @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
 // @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
-// @Router /api/v1/notifications/ [get]
+// @Router /notifications/ [get]
 func (h *Handler) handleGetNotifications(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
 	
@@ -66,73 +79,83 @@ func (h *Handler) handleGetNotifications(c *gin.Context) {
 			"request_id": requestID,
 			"error":      err.Error(),
 		})
-		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
 		return
 	}
 
 	// Parse pagination parameters
-	limit := 10 // Default limit
-	page := 1   // Default page
+	opts := ListOptions{Limit: 10}
 
 	if limitParam := c.Query("limit"); limitParam != "" {
 		parsedLimit, err := strconv.Atoi(limitParam)
-		if err != nil || parsedLimit <= 0 {
+		if err != nil || parsedLimit <= 0 || parsedLimit > maxNotificationsLimit {
 			h.logger.LogInfo("Invalid limit parameter", map[string]interface{}{
 				"request_id": requestID,
 				"limit":      limitParam,
 			})
-			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid limit parameter, must be a positive integer", nil)
+			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid limit parameter, must be an integer between 1 and 100", nil)
 			return
 		}
-		limit = parsedLimit
+		opts.Limit = parsedLimit
 	}
 
-	if pageParam := c.Query("page"); pageParam != "" {
-		parsedPage, err := strconv.Atoi(pageParam)
-		if err != nil || parsedPage <= 0 {
-			h.logger.LogInfo("Invalid page parameter", map[string]interface{}{
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		cursor, err := DecodeCursor(cursorParam)
+		if err != nil {
+			h.logger.LogInfo("Invalid cursor parameter", map[string]interface{}{
 				"request_id": requestID,
-				"page":       pageParam,
+				"cursor":     cursorParam,
 			})
-			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid page parameter, must be a positive integer", nil)
+			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid cursor parameter", nil)
 			return
 		}
-		page = parsedPage
+		opts.Cursor = cursor
 	}
 
-	// Calculate offset based on page and limit
-	offset := (page - 1) * limit
+	if unreadParam := c.Query("unreadOnly"); unreadParam != "" {
+		unreadOnly, err := strconv.ParseBool(unreadParam)
+		if err != nil {
+			h.logger.LogInfo("Invalid unreadOnly parameter", map[string]interface{}{
+				"request_id": requestID,
+				"unreadOnly": unreadParam,
+			})
+			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid unreadOnly parameter, must be a boolean", nil)
+			return
+		}
+		opts.UnreadOnly = unreadOnly
+	}
 
 	// Get notifications
-	notifications, err := h.service.GetUserNotifications(c.Request.Context(), userID, limit, offset)
+	page, err := h.service.GetUserNotifications(c.Request.Context(), userID, opts)
 	if err != nil {
 		h.logger.LogInfo("Failed to get notifications", map[string]interface{}{
 			"request_id": requestID,
 			"user_id":    userID.String(),
 			"error":      err.Error(),
 		})
-		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve notifications", err)
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to retrieve notifications"), "")
 		return
 	}
 
 	h.logger.LogInfo("Notifications retrieved successfully", map[string]interface{}{
-		"request_id": requestID,
-		"user_id":    userID.String(),
-		"count":      len(notifications),
+		"request_id":   requestID,
+		"user_id":      userID.String(),
+		"count":        len(page.Notifications),
+		"total_unread": page.TotalUnread,
 	})
 
-	h.responseHandler.SuccessResponse(c, notifications, "Notifications retrieved successfully")
+	h.responseHandler.SuccessResponse(c, page, "Notifications retrieved successfully")
 }
 
 // @Summary Get unread notification count
-// @Description Get the count of unread notifications for the authenticated user
+// @Description Get the count of unread notifications for the authenticated user, e.g. for a badge. The count is kept in Redis and recounted from storage every few minutes, so it is cheap to poll.
 // @Tags notifications
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} httpHandler.APIResponse{data=map[string]int} "Unread count retrieved successfully"
 // @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
 // @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
-// @Router /api/v1/notifications/unread-count [get]
+// @Router /notifications/unread-count [get]
 func (h *Handler) handleGetUnreadCount(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
 	
@@ -149,7 +172,7 @@ func (h *Handler) handleGetUnreadCount(c *gin.Context) {
 			"request_id": requestID,
 			"error":      err.Error(),
 		})
-		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
 		return
 	}
 
@@ -161,7 +184,7 @@ func (h *Handler) handleGetUnreadCount(c *gin.Context) {
 			"user_id":    userID.String(),
 			"error":      err.Error(),
 		})
-		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve unread notification count", err)
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to retrieve unread notification count"), "")
 		return
 	}
 
@@ -175,7 +198,7 @@ func (h *Handler) handleGetUnreadCount(c *gin.Context) {
 }
 
 // @Summary Mark notification as read
-// @Description Mark a specific notification as read
+// @Description Mark one of the authenticated user's notifications as read. Devices syncing several read marks at once should use PUT /notifications/read-state.
 // @Tags notifications
 // @Produce json
 // @Security BearerAuth
@@ -185,10 +208,15 @@ func (h *Handler) handleGetUnreadCount(c *gin.Context) {
 // @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
 // @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Notification not found"
 // @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
-// @Router /api/v1/notifications/{id}/read [put]
+// @Router /notifications/{id}/read [put]
 func (h *Handler) handleMarkAsRead(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
 	notificationID := c.Param("id")
+
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
 	
 	// Parse UUID from string
 	notifUUID, err := uuid.Parse(notificationID)
@@ -198,25 +226,23 @@ func (h *Handler) handleMarkAsRead(c *gin.Context) {
 			"notification_id": notificationID,
 			"error":           err.Error(),
 		})
-		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid notification ID format", err)
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid notification ID format", err)
 		return
 	}
 
 	// Mark notification as read
-	if err := h.service.MarkAsRead(c.Request.Context(), notifUUID); err != nil {
+	if err := h.service.MarkAsRead(c.Request.Context(), userID, notifUUID); err != nil {
 		h.logger.LogInfo("Failed to mark notification as read", map[string]interface{}{
 			"request_id":      requestID,
 			"notification_id": notificationID,
 			"error":           err.Error(),
 		})
 		
-		// Check for specific error types
-		if err.Error() == "notification not found" {
-			h.responseHandler.NotFoundResponse(c, "Notification not found")
-			return
+		// Missing notifications keep their own code; anything else is a database error
+		if !errors.Is(err, ErrNotificationNotFound) {
+			err = apierror.Wrap(err, apierror.CodeDatabase, "Failed to mark notification as read")
 		}
-		
-		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to mark notification as read", err)
+		apierror.Abort(c, err, "")
 		return
 	}
 
@@ -229,14 +255,14 @@ func (h *Handler) handleMarkAsRead(c *gin.Context) {
 }
 
 // @Summary Mark all notifications as read
-// @Description Mark all notifications as read for the authenticated user
+// @Description Mark all notifications as read for the authenticated user by moving their read watermark to now
 // @Tags notifications
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} httpHandler.APIResponse "All notifications marked as read"
 // @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
 // @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
-// @Router /api/v1/notifications/read-all [put]
+// @Router /notifications/read-all [put]
 func (h *Handler) handleMarkAllAsRead(c *gin.Context) {
 	requestID, _ := c.Get("request_id")
 	
@@ -253,7 +279,7 @@ func (h *Handler) handleMarkAllAsRead(c *gin.Context) {
 			"request_id": requestID,
 			"error":      err.Error(),
 		})
-		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
 		return
 	}
 
@@ -264,7 +290,7 @@ func (h *Handler) handleMarkAllAsRead(c *gin.Context) {
 			"user_id":    userID.String(),
 			"error":      err.Error(),
 		})
-		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to mark all notifications as read", err)
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to mark all notifications as read"), "")
 		return
 	}
 
@@ -2,10 +2,15 @@ package notification
 
 import (
 	"context"
+	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/google/uuid"
 )
 
+// ErrNotificationNotFound is returned when a notification does not exist
+var ErrNotificationNotFound = apierror.New(apierror.CodeNotFound, "notification not found")
+
 // EventType represents the type of notification event
 // @Description Type of notification event (e.g. VIDEO_UPLOADED, COMMENT_CREATED)
 type EventType string
@@ -16,18 +21,36 @@ const (
 	VideoProcessed EventType = "VIDEO_PROCESSED"
 	VideoUpdated   EventType = "VIDEO_UPDATED"
 	VideoDeleted   EventType = "VIDEO_DELETED"
+	// FollowedUserUploaded is delivered to followers when a creator uploads a video
+	FollowedUserUploaded EventType = "FOLLOWED_USER_UPLOADED"
+	// LiveStarted is delivered to followers when a creator goes live
+	LiveStarted EventType = "LIVE_STARTED"
 
 	// Comment related events
 	CommentCreated  EventType = "COMMENT_CREATED"
 	CommentReplied  EventType = "COMMENT_REPLIED"
 	CommentReaction EventType = "COMMENT_REACTION"
+	// CommentDeleted is published for consumers but never shown
+	CommentDeleted EventType = "COMMENT_DELETED"
 
 	// User related events
-	UserFollowed  EventType = "USER_FOLLOWED"
-	UserMentioned EventType = "USER_MENTIONED"
-	AuthEvent     EventType = "AUTH_EVENT"
+	UserFollowed   EventType = "USER_FOLLOWED"
+	UserUnfollowed EventType = "USER_UNFOLLOWED"
+	UserMentioned  EventType = "USER_MENTIONED"
+	AuthEvent      EventType = "AUTH_EVENT"
 )
 
+// IsValid reports whether t is one of the known event types
+func (t EventType) IsValid() bool {
+	switch t {
+	case VideoUploaded, VideoProcessed, VideoUpdated, VideoDeleted, FollowedUserUploaded, LiveStarted,
+		CommentCreated, CommentReplied, CommentReaction, CommentDeleted,
+		UserFollowed, UserUnfollowed, UserMentioned, AuthEvent:
+		return true
+	}
+	return false
+}
+
 // NotificationService defines the interface for notification operations
 type NotificationService interface {
 	// Publish methods for different event types
@@ -35,26 +58,61 @@ type NotificationService interface {
 	PublishCommentEvent(ctx context.Context, event *CommentEvent) error
 	PublishUserEvent(ctx context.Context, event *UserEvent) error
 
-	// Get notifications for a user
-	GetUserNotifications(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Notification, error)
+	// Get a page of notifications for a user, including the total unread count
+	GetUserNotifications(ctx context.Context, userID uuid.UUID, opts ListOptions) (*NotificationPage, error)
 	
 	// Get count of unread notifications
 	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
 	
 	// Mark notifications as read
-	MarkAsRead(ctx context.Context, notificationID uuid.UUID) error
+	MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
+
+	// Get and sync the user's read marks across devices
+	GetReadState(ctx context.Context, userID uuid.UUID) (*ReadState, error)
+	UpdateReadState(ctx context.Context, userID uuid.UUID, update *ReadStateUpdate) (*ReadState, error)
 	
 	// Close the service and release resources
 	Close() error
 }
 
+// FollowerLister looks up the followers of a user so that creator events can
+// be fanned out to them
+type FollowerLister interface {
+	GetFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// BlockChecker reports whether a user blocked another, so that events caused
+// by blocked users are not shown to the users who blocked them
+type BlockChecker interface {
+	IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error)
+}
+
 // NotificationRepository defines the interface for notification storage operations
 type NotificationRepository interface {
 	// CRUD operations
 	SaveNotification(ctx context.Context, notification *Notification) error
-	GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Notification, error)
+	// GetNotification returns ErrNotificationNotFound when the notification does not exist
+	GetNotification(ctx context.Context, notificationID uuid.UUID) (*Notification, error)
+	// UpdateNotification replaces the content and metadata of a stored notification
+	UpdateNotification(ctx context.Context, notification *Notification) error
+	GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, opts ListOptions) ([]*Notification, error)
+	// GetNotificationsSince returns notifications created after since, oldest first
+	GetNotificationsSince(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*Notification, error)
 	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
-	MarkAsRead(ctx context.Context, notificationID uuid.UUID) error
-	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
+	// MarkAsRead marks one of the user's notifications read on device. It
+	// returns ErrNotificationNotFound when the user has no such notification.
+	MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID, device string) error
+	// MarkAllAsRead moves the user's read watermark forward to at; an older
+	// watermark than the stored one is ignored
+	MarkAllAsRead(ctx context.Context, userID uuid.UUID, at time.Time, device string) error
+	// GetReadState returns the user's watermark, the notifications marked
+	// read after it and the unread count
+	GetReadState(ctx context.Context, userID uuid.UUID) (*ReadState, error)
+	// GetNotificationsByVideoID returns the notifications whose metadata
+	// refers to the video, in no particular order
+	GetNotificationsByVideoID(ctx context.Context, videoID uuid.UUID) ([]*Notification, error)
+	// ForgetVideo drops the record of which notifications refer to the
+	// video, once they have been redacted
+	ForgetVideo(ctx context.Context, videoID uuid.UUID) error
 }
\ No newline at end of file
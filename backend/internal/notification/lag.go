@@ -0,0 +1,204 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+)
+
+// SubscriptionLag describes how far a subscription is behind its topic
+type SubscriptionLag struct {
+	Name       string  `json:"name"`
+	Backlog    int64   `json:"backlog"`
+	Consumers  int     `json:"consumers"`
+	MsgRateOut float64 `json:"msgRateOut"`
+	// Stalled is set when the backlog is non-zero and did not shrink since the previous poll
+	Stalled bool `json:"stalled"`
+}
+
+// topicStatsResponse is the subset of the Pulsar admin topic stats used here
+type topicStatsResponse struct {
+	Subscriptions map[string]struct {
+		MsgBacklog int64             `json:"msgBacklog"`
+		MsgRateOut float64           `json:"msgRateOut"`
+		Consumers  []json.RawMessage `json:"consumers"`
+	} `json:"subscriptions"`
+}
+
+// LagMonitor periodically reads subscription backlogs from the Pulsar admin
+// API, so a stuck subscription shows up in metrics before users notice
+// missing notifications.
+type LagMonitor struct {
+	webServiceURL string
+	authToken     string
+	topics        []string
+	interval      time.Duration
+	client        *http.Client
+	metrics       *Metrics
+	logger        logger.Logger
+
+	mu       sync.Mutex
+	previous map[string]int64 // Backlog per topic/subscription at the previous poll
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewLagMonitor creates a lag monitor for the notification topics in config
+func NewLagMonitor(config *ServiceConfig, metrics *Metrics, logger logger.Logger) *LagMonitor {
+	interval := config.LagPollInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &LagMonitor{
+		webServiceURL: strings.TrimRight(config.PulsarWebServiceURL, "/"),
+		authToken:     config.AuthToken,
+		topics: []string{
+			config.VideoEventsTopic,
+			config.CommentEventsTopic,
+			config.UserEventsTopic,
+			config.DeadLetterTopic,
+			config.RetryQueueTopic,
+		},
+		interval: interval,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		metrics:  metrics,
+		logger:   logger,
+		previous: make(map[string]int64),
+	}
+}
+
+// Start polls the admin API until Stop is called
+func (l *LagMonitor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+
+		l.Poll(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				l.Poll(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops polling and waits for an in-flight poll to finish
+func (l *LagMonitor) Stop() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+	l.wg.Wait()
+}
+
+// Poll reads the backlog of every topic once and records it
+func (l *LagMonitor) Poll(ctx context.Context) {
+	for _, topic := range l.topics {
+		if topic == "" {
+			continue
+		}
+		subscriptions, err := l.fetch(ctx, topic)
+		if err != nil {
+			l.logger.LogWarn("Failed to read notification consumer lag", map[string]interface{}{
+				"topic": topic,
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		for _, s := range subscriptions {
+			if s.Stalled {
+				l.logger.LogWarn("Notification subscription is not making progress", map[string]interface{}{
+					"topic":        topic,
+					"subscription": s.Name,
+					"backlog":      s.Backlog,
+					"consumers":    s.Consumers,
+				})
+			}
+		}
+		l.metrics.RecordLag(topic, subscriptions)
+	}
+}
+
+// fetch reads the subscriptions of a topic from the Pulsar admin API
+func (l *LagMonitor) fetch(ctx context.Context, topic string) ([]SubscriptionLag, error) {
+	path, err := adminTopicPath(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, l.webServiceURL+"/admin/v2/"+path+"/stats", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stats request: %w", err)
+	}
+	if l.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+l.authToken)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch topic stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("topic stats returned status %d", resp.StatusCode)
+	}
+
+	var stats topicStatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to decode topic stats: %w", err)
+	}
+
+	return l.toLag(topic, &stats), nil
+}
+
+// toLag converts topic stats to subscription lag, comparing against the previous poll
+func (l *LagMonitor) toLag(topic string, stats *topicStatsResponse) []SubscriptionLag {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	subscriptions := make([]SubscriptionLag, 0, len(stats.Subscriptions))
+	for name, s := range stats.Subscriptions {
+		key := topic + "|" + name
+		previous, seen := l.previous[key]
+		l.previous[key] = s.MsgBacklog
+
+		subscriptions = append(subscriptions, SubscriptionLag{
+			Name:       name,
+			Backlog:    s.MsgBacklog,
+			Consumers:  len(s.Consumers),
+			MsgRateOut: s.MsgRateOut,
+			Stalled:    seen && s.MsgBacklog > 0 && s.MsgBacklog >= previous,
+		})
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].Name < subscriptions[j].Name })
+	return subscriptions
+}
+
+// adminTopicPath converts "persistent://tenant/ns/topic" to the admin REST path "persistent/tenant/ns/topic"
+func adminTopicPath(topic string) (string, error) {
+	domain, rest, ok := strings.Cut(topic, "://")
+	if !ok || (domain != "persistent" && domain != "non-persistent") {
+		return "", fmt.Errorf("invalid topic name %q", topic)
+	}
+	if strings.Count(rest, "/") != 2 {
+		return "", fmt.Errorf("topic %q must be fully qualified as tenant/namespace/topic", topic)
+	}
+	return domain + "/" + rest, nil
+}
@@ -0,0 +1,210 @@
+package notification
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Failure stages of a notification unit of work
+const (
+	StagePublish = "publish" // Sending the event to Pulsar failed
+	StagePersist = "persist" // Storing the notification in ScyllaDB failed
+)
+
+// TopicStats summarises processing of one notification topic
+type TopicStats struct {
+	Topic               string            `json:"topic"`
+	Processed           int64             `json:"processed"`
+	Failed              map[string]int64  `json:"failed"`
+	ThroughputPerMinute int64             `json:"throughputPerMinute"`
+	LastProcessedAt     *time.Time        `json:"lastProcessedAt,omitempty"`
+	LastFailureAt       *time.Time        `json:"lastFailureAt,omitempty"`
+	Subscriptions       []SubscriptionLag `json:"subscriptions"`
+}
+
+// Metrics records throughput, failures and consumer lag of the notification
+// pipeline. Counters are exported to Prometheus and mirrored in memory for
+// the admin endpoint. A nil *Metrics is valid and records nothing.
+type Metrics struct {
+	processed *prometheus.CounterVec
+	failed    *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	backlog   *prometheus.GaugeVec
+	stalled   *prometheus.GaugeVec
+
+	mu     sync.Mutex
+	topics map[string]*topicCounters
+	now    func() time.Time
+}
+
+// topicCounters holds the in-memory counters of one topic
+type topicCounters struct {
+	processed       int64
+	failed          map[string]int64
+	minute          time.Time // Start of the minute currently being counted
+	minuteCount     int64
+	lastMinuteCount int64
+	lastProcessedAt *time.Time
+	lastFailureAt   *time.Time
+	subscriptions   []SubscriptionLag
+}
+
+// NewMetrics creates notification metrics and registers them with registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		processed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "notification",
+			Name:      "events_processed_total",
+			Help:      "Notification events published and stored successfully.",
+		}, []string{"topic"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "notification",
+			Name:      "events_failed_total",
+			Help:      "Notification events that failed, by stage.",
+		}, []string{"topic", "stage"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "pavilion",
+			Subsystem: "notification",
+			Name:      "processing_duration_seconds",
+			Help:      "Time taken to publish and store a notification event.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"topic"}),
+		backlog: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "notification",
+			Name:      "consumer_backlog_messages",
+			Help:      "Messages waiting to be acknowledged by a subscription.",
+		}, []string{"topic", "subscription"}),
+		stalled: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "notification",
+			Name:      "consumer_stalled",
+			Help:      "1 when a subscription has a backlog that did not shrink since the previous poll.",
+		}, []string{"topic", "subscription"}),
+		topics: make(map[string]*topicCounters),
+		now:    time.Now,
+	}
+	registerer.MustRegister(m.processed, m.failed, m.duration, m.backlog, m.stalled)
+	return m
+}
+
+// RecordProcessed records a successful unit of work on topic
+func (m *Metrics) RecordProcessed(topic string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.processed.WithLabelValues(topic).Inc()
+	m.duration.WithLabelValues(topic).Observe(duration.Seconds())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.topic(topic)
+	now := m.now()
+	t.processed++
+	t.lastProcessedAt = &now
+	m.rollMinute(t, now)
+	t.minuteCount++
+}
+
+// RecordFailure records a unit of work on topic that failed at stage
+func (m *Metrics) RecordFailure(topic, stage string) {
+	if m == nil {
+		return
+	}
+	m.failed.WithLabelValues(topic, stage).Inc()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := m.topic(topic)
+	now := m.now()
+	t.failed[stage]++
+	t.lastFailureAt = &now
+}
+
+// RecordLag stores the latest subscription backlog of topic
+func (m *Metrics) RecordLag(topic string, subscriptions []SubscriptionLag) {
+	if m == nil {
+		return
+	}
+	for _, s := range subscriptions {
+		m.backlog.WithLabelValues(topic, s.Name).Set(float64(s.Backlog))
+		stalled := 0.0
+		if s.Stalled {
+			stalled = 1
+		}
+		m.stalled.WithLabelValues(topic, s.Name).Set(stalled)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.topic(topic).subscriptions = subscriptions
+}
+
+// Snapshot returns the current stats of every topic seen, ordered by topic
+func (m *Metrics) Snapshot() []TopicStats {
+	if m == nil {
+		return []TopicStats{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	stats := make([]TopicStats, 0, len(m.topics))
+	for name, t := range m.topics {
+		m.rollMinute(t, now)
+		failed := make(map[string]int64, len(t.failed))
+		for stage, count := range t.failed {
+			failed[stage] = count
+		}
+		subscriptions := make([]SubscriptionLag, len(t.subscriptions))
+		copy(subscriptions, t.subscriptions)
+
+		stats = append(stats, TopicStats{
+			Topic:               name,
+			Processed:           t.processed,
+			Failed:              failed,
+			ThroughputPerMinute: t.lastMinuteCount,
+			LastProcessedAt:     t.lastProcessedAt,
+			LastFailureAt:       t.lastFailureAt,
+			Subscriptions:       subscriptions,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Topic < stats[j].Topic })
+	return stats
+}
+
+// topic returns the counters for a topic, creating them on first use. Callers must hold mu.
+func (m *Metrics) topic(name string) *topicCounters {
+	t, ok := m.topics[name]
+	if !ok {
+		t = &topicCounters{failed: make(map[string]int64)}
+		m.topics[name] = t
+	}
+	return t
+}
+
+// rollMinute moves the per-minute counter forward so throughput reflects the
+// last complete minute. Callers must hold mu.
+func (m *Metrics) rollMinute(t *topicCounters, now time.Time) {
+	minute := now.Truncate(time.Minute)
+	switch {
+	case t.minute.IsZero():
+		t.minute = minute
+	case minute.Equal(t.minute):
+	case minute.Sub(t.minute) == time.Minute:
+		t.lastMinuteCount = t.minuteCount
+		t.minuteCount = 0
+		t.minute = minute
+	default:
+		// No events for over a minute
+		t.lastMinuteCount = 0
+		t.minuteCount = 0
+		t.minute = minute
+	}
+}
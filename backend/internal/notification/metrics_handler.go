@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"time"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsReport is the admin view of notification pipeline health
+type MetricsReport struct {
+	Topics []TopicStats `json:"topics"`
+	// Stalled lists "topic/subscription" pairs whose backlog is not shrinking
+	Stalled     []string  `json:"stalled"`
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// MetricsHandler serves notification pipeline metrics to operators
+type MetricsHandler struct {
+	metrics         *Metrics
+	responseHandler httpHandler.ResponseHandler
+}
+
+// NewMetricsHandler creates a new notification metrics handler
+func NewMetricsHandler(metrics *Metrics, responseHandler httpHandler.ResponseHandler) *MetricsHandler {
+	return &MetricsHandler{
+		metrics:         metrics,
+		responseHandler: responseHandler,
+	}
+}
+
+// RegisterRoutes registers the notification metrics routes behind the
+// authentication and admin role middlewares
+func (h *MetricsHandler) RegisterRoutes(router gin.IRouter, authMiddleware, adminMiddleware gin.HandlerFunc) {
+	admin := router.Group("/admin/notifications")
+	admin.Use(authMiddleware, adminMiddleware)
+	{
+		admin.GET("/metrics", h.handleGetMetrics)
+	}
+}
+
+// @Summary Get notification pipeline metrics
+// @Description Per-topic throughput, failure counts and subscription backlog of the notification pipeline. The same data is exported to Prometheus at /metrics.
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=MetricsReport} "Metrics retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Admin role required"
+// @Router /admin/notifications/metrics [get]
+func (h *MetricsHandler) handleGetMetrics(c *gin.Context) {
+	report := MetricsReport{
+		Topics:      h.metrics.Snapshot(),
+		Stalled:     []string{},
+		GeneratedAt: time.Now(),
+	}
+	for _, topic := range report.Topics {
+		for _, s := range topic.Subscriptions {
+			if s.Stalled {
+				report.Stalled = append(report.Stalled, topic.Topic+"/"+s.Name)
+			}
+		}
+	}
+
+	h.responseHandler.SuccessResponse(c, report, "Notification metrics retrieved successfully")
+}
@@ -37,6 +37,9 @@ type CommentEvent struct {
 	ParentID  uuid.UUID          `json:"parentId,omitempty"`
 	Content   string             `json:"content,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	// ActorID is the user who commented, replied or reacted; notifications
+	// about the same comment or video are grouped by it
+	ActorID uuid.UUID `json:"actorId,omitempty"`
 }
 
 // UserEvent represents a user-related notification event
@@ -59,8 +62,14 @@ type Notification struct {
 	Type      EventType          `json:"type" example:"VIDEO_UPLOADED"`
 	// Human-readable notification content
 	Content   string             `json:"content" example:"Your video 'My awesome video' has been uploaded successfully"`
-	// Additional metadata about the notification
+	// Additional metadata about the notification. Prefer payload, which has
+	// a fixed shape per type; metadata is kept for existing clients.
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	// Version of the payload's shape, see PayloadSchemaVersion
+	SchemaVersion int `json:"schemaVersion" example:"1"`
+	// Typed details of the notification, shaped by its type, e.g. a
+	// CommentReplyPayload for COMMENT_REPLIED
+	Payload json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
 	// When the notification was marked as read (null if unread)
 	ReadAt    *time.Time         `json:"readAt,omitempty"`
 	// When the notification was created
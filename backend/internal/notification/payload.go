@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PayloadSchemaVersion is the version of the payloads notifications are
+// stored with. Bump it when a payload struct changes incompatibly and teach
+// UpgradePayload to convert payloads of the older version.
+//
+// Version 1 is the first typed payload. Notifications stored before it have
+// version 0 and only metadata, from which their payload is derived.
+const PayloadSchemaVersion = 1
+
+// Grouping describes the actors a grouped notification collapses; see Aggregator
+type Grouping struct {
+	// Identifies the notifications grouped together
+	GroupKey string `json:"groupKey,omitempty" example:"COMMENT_REACTION:3f6c1a2b-9d4e-4f7a-8b1c-2d3e4f5a6b7c"`
+	// How many users acted
+	ActorCount int `json:"actorCount,omitempty" example:"4"`
+	// The most recent actors, most recent first, at most SampleActors
+	Actors []uuid.UUID `json:"actors,omitempty"`
+}
+
+// VideoPayload is the payload of VIDEO_UPLOADED, VIDEO_PROCESSED,
+// VIDEO_UPDATED and VIDEO_DELETED, sent to the video's owner
+type VideoPayload struct {
+	VideoID uuid.UUID `json:"videoId"`
+	// Owner of the video
+	UserID uuid.UUID `json:"userId"`
+	Title  string    `json:"title,omitempty"`
+	// Size in bytes of the uploaded file, on VIDEO_UPLOADED
+	FileSize int64 `json:"fileSize,omitempty"`
+	// IPFS CID of the uploaded file, on VIDEO_UPLOADED
+	IPFSCID string `json:"ipfsCid,omitempty"`
+}
+
+// FollowedUploadPayload is the payload of FOLLOWED_USER_UPLOADED, sent to
+// the followers of a creator who uploaded a video
+type FollowedUploadPayload struct {
+	VideoID uuid.UUID `json:"videoId"`
+	// The creator who uploaded it
+	UserID uuid.UUID `json:"userId"`
+	Title  string    `json:"title,omitempty"`
+}
+
+// LiveStartedPayload is the payload of LIVE_STARTED, sent to a streamer and their followers
+type LiveStartedPayload struct {
+	LiveStreamID uuid.UUID `json:"liveStreamId"`
+	// The streamer
+	UserID      uuid.UUID `json:"userId"`
+	Title       string    `json:"title,omitempty"`
+	PlaybackURL string    `json:"playbackUrl,omitempty"`
+}
+
+// CommentPayload is the payload of COMMENT_CREATED, sent to the video's owner
+type CommentPayload struct {
+	CommentID uuid.UUID `json:"commentId"`
+	VideoID   uuid.UUID `json:"videoId"`
+	// The commenter
+	ActorID uuid.UUID `json:"actorId,omitempty"`
+	// The start of the comment
+	ContentPreview string `json:"contentPreview,omitempty"`
+	Grouping
+}
+
+// CommentReplyPayload is the payload of COMMENT_REPLIED, sent to the author
+// of the comment replied to
+type CommentReplyPayload struct {
+	// The reply
+	CommentID uuid.UUID `json:"commentId"`
+	// The comment replied to
+	ParentID uuid.UUID `json:"parentId"`
+	VideoID  uuid.UUID `json:"videoId,omitempty"`
+	// Who replied
+	ActorID        uuid.UUID `json:"actorId,omitempty"`
+	ContentPreview string    `json:"contentPreview,omitempty"`
+	Grouping
+}
+
+// CommentReactionPayload is the payload of COMMENT_REACTION, sent to the comment's author
+type CommentReactionPayload struct {
+	CommentID uuid.UUID `json:"commentId"`
+	VideoID   uuid.UUID `json:"videoId,omitempty"`
+	// Who reacted
+	ActorID uuid.UUID `json:"actorId,omitempty"`
+	Grouping
+}
+
+// FollowPayload is the payload of USER_FOLLOWED, sent to the followed user
+type FollowPayload struct {
+	// The follower
+	UserID uuid.UUID `json:"userId"`
+	// The followed user
+	TargetUserID uuid.UUID `json:"targetUserId"`
+	Grouping
+}
+
+// UserPayload is the payload of USER_MENTIONED and AUTH_EVENT
+type UserPayload struct {
+	// The user who caused the event
+	UserID uuid.UUID `json:"userId"`
+	// The user notified
+	TargetUserID uuid.UUID `json:"targetUserId"`
+	Content      string    `json:"content,omitempty"`
+}
+
+// NewPayload returns a pointer to an empty payload of the struct for
+// eventType, or false for types without one
+func NewPayload(eventType EventType) (interface{}, bool) {
+	switch eventType {
+	case VideoUploaded, VideoProcessed, VideoUpdated, VideoDeleted:
+		return &VideoPayload{}, true
+	case FollowedUserUploaded:
+		return &FollowedUploadPayload{}, true
+	case LiveStarted:
+		return &LiveStartedPayload{}, true
+	case CommentCreated:
+		return &CommentPayload{}, true
+	case CommentReplied:
+		return &CommentReplyPayload{}, true
+	case CommentReaction:
+		return &CommentReactionPayload{}, true
+	case UserFollowed, UserUnfollowed:
+		return &FollowPayload{}, true
+	case UserMentioned, AuthEvent:
+		return &UserPayload{}, true
+	default:
+		return nil, false
+	}
+}
+
+// SetPayload derives the notification's typed payload from its metadata,
+// dropping fields its type does not have, and stamps it with the current
+// schema version
+func (n *Notification) SetPayload() error {
+	payload, ok := NewPayload(n.Type)
+	if !ok {
+		n.Payload, n.SchemaVersion = nil, PayloadSchemaVersion
+		return nil
+	}
+	metadata, err := json.Marshal(n.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification metadata: %w", err)
+	}
+	if err := json.Unmarshal(metadata, payload); err != nil {
+		return fmt.Errorf("metadata does not fit the %s payload: %w", n.Type, err)
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", n.Type, err)
+	}
+	n.Payload, n.SchemaVersion = encoded, PayloadSchemaVersion
+	return nil
+}
+
+// UpgradePayload brings a stored notification's payload to the current
+// schema version. Notifications stored before payloads existed get one
+// derived from their metadata.
+func (n *Notification) UpgradePayload() error {
+	switch {
+	case n.SchemaVersion == PayloadSchemaVersion:
+		return nil
+	case n.SchemaVersion == 0:
+		return n.SetPayload()
+	default:
+		return fmt.Errorf("notification %s has payload schema version %d, newer than %d", n.ID, n.SchemaVersion, PayloadSchemaVersion)
+	}
+}
+
+// DecodePayload returns the notification's payload as the struct for its
+// type, e.g. *CommentReplyPayload for COMMENT_REPLIED
+func (n *Notification) DecodePayload() (interface{}, error) {
+	payload, ok := NewPayload(n.Type)
+	if !ok {
+		return nil, fmt.Errorf("notifications of type %s have no payload", n.Type)
+	}
+	if err := n.DecodePayloadInto(payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// DecodePayloadInto unmarshals the notification's payload into v, which
+// should point to the payload struct for its type
+func (n *Notification) DecodePayloadInto(v interface{}) error {
+	if n.SchemaVersion != PayloadSchemaVersion {
+		return fmt.Errorf("notification %s has payload schema version %d, expected %d", n.ID, n.SchemaVersion, PayloadSchemaVersion)
+	}
+	if len(n.Payload) == 0 {
+		return fmt.Errorf("notification %s has no payload", n.ID)
+	}
+	if err := json.Unmarshal(n.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", n.Type, err)
+	}
+	return nil
+}
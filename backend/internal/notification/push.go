@@ -0,0 +1,331 @@
+package notification
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification/webpush"
+	"github.com/google/uuid"
+)
+
+// maxPushSubscriptions caps the devices a user can register for push
+const maxPushSubscriptions = 20
+
+// ErrTooManyPushSubscriptions is returned when a user registers more devices than allowed
+var ErrTooManyPushSubscriptions = apierror.New(apierror.CodeConflict,
+	fmt.Sprintf("at most %d devices can receive push notifications; unregister one first", maxPushSubscriptions))
+
+// PushSubscription is the Web Push subscription of one of a user's browsers
+// @Description A device registered for push notifications
+type PushSubscription struct {
+	UserID uuid.UUID `json:"-"`
+	// Push service URL the browser subscribed with
+	Endpoint string `json:"endpoint" example:"https://fcm.googleapis.com/fcm/send/abc123"`
+	// Subscription keys, kept private to the server
+	P256dh string `json:"-"`
+	Auth   string `json:"-"`
+	// Browser that registered the subscription
+	UserAgent string `json:"userAgent,omitempty"`
+	// When the push service expires the subscription, if it said so
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// PushPreferences controls which notifications a user receives as push
+// messages. Notifications are stored and listed either way.
+// @Description Which notifications are sent as push messages
+type PushPreferences struct {
+	// Whether any push messages are sent
+	Enabled bool `json:"enabled"`
+	// Notification types not sent as push messages
+	MutedTypes []EventType `json:"mutedTypes"`
+}
+
+// DefaultPushPreferences sends every notification type
+func DefaultPushPreferences() *PushPreferences {
+	return &PushPreferences{Enabled: true, MutedTypes: []EventType{}}
+}
+
+// allows reports whether a notification of eventType is sent as a push message
+func (p *PushPreferences) allows(eventType EventType) bool {
+	if !p.Enabled {
+		return false
+	}
+	for _, muted := range p.MutedTypes {
+		if muted == eventType {
+			return false
+		}
+	}
+	return true
+}
+
+// PushStore stores push subscriptions and preferences
+type PushStore interface {
+	// SavePushSubscription creates or replaces the user's subscription for its endpoint
+	SavePushSubscription(ctx context.Context, subscription *PushSubscription) error
+	DeletePushSubscription(ctx context.Context, userID uuid.UUID, endpoint string) error
+	ListPushSubscriptions(ctx context.Context, userID uuid.UUID) ([]*PushSubscription, error)
+	// GetPushPreferences returns nil when the user has not set preferences
+	GetPushPreferences(ctx context.Context, userID uuid.UUID) (*PushPreferences, error)
+	SavePushPreferences(ctx context.Context, userID uuid.UUID, preferences *PushPreferences) error
+}
+
+// PushSender delivers an encrypted message to a push service
+type PushSender interface {
+	Send(ctx context.Context, subscription webpush.Subscription, payload []byte, opts webpush.Options) error
+}
+
+// PushMessage is the payload the service worker receives
+type PushMessage struct {
+	ID        uuid.UUID `json:"id"`
+	Type      EventType `json:"type"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// PushService registers browsers for Web Push and sends them new
+// notifications. Deliveries run in the background, so a slow push service
+// never delays storing notifications.
+type PushService struct {
+	store     PushStore
+	sender    PushSender
+	publicKey string
+	ttl       time.Duration
+	timeout   time.Duration
+	logger    logger.Logger
+	now       TimeFunc
+	wg        sync.WaitGroup
+}
+
+// NewPushService creates a push service. publicKey is the VAPID key browsers
+// subscribe with; messages are kept by push services for ttl and each
+// delivery is abandoned after timeout.
+func NewPushService(store PushStore, sender PushSender, publicKey string, ttl, timeout time.Duration, logger logger.Logger) *PushService {
+	return &PushService{
+		store:     store,
+		sender:    sender,
+		publicKey: publicKey,
+		ttl:       ttl,
+		timeout:   timeout,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetClock replaces the clock used to expire subscriptions, for tests
+func (p *PushService) SetClock(now TimeFunc) {
+	p.now = now
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (p *PushService) PublicKey() string {
+	return p.publicKey
+}
+
+// Subscribe registers a browser's subscription, replacing any earlier
+// subscription with the same endpoint
+func (p *PushService) Subscribe(ctx context.Context, subscription *PushSubscription) error {
+	if err := validatePushSubscription(subscription); err != nil {
+		return err
+	}
+
+	existing, err := p.store.ListPushSubscriptions(ctx, subscription.UserID)
+	if err != nil {
+		return err
+	}
+	if len(existing) >= maxPushSubscriptions && !hasEndpoint(existing, subscription.Endpoint) {
+		return ErrTooManyPushSubscriptions
+	}
+
+	if subscription.CreatedAt.IsZero() {
+		subscription.CreatedAt = p.now()
+	}
+	return p.store.SavePushSubscription(ctx, subscription)
+}
+
+// Unsubscribe removes the user's subscription for endpoint, if there is one
+func (p *PushService) Unsubscribe(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	return p.store.DeletePushSubscription(ctx, userID, endpoint)
+}
+
+// ListSubscriptions returns the user's registered devices
+func (p *PushService) ListSubscriptions(ctx context.Context, userID uuid.UUID) ([]*PushSubscription, error) {
+	return p.store.ListPushSubscriptions(ctx, userID)
+}
+
+// Preferences returns the user's push preferences, or the defaults
+func (p *PushService) Preferences(ctx context.Context, userID uuid.UUID) (*PushPreferences, error) {
+	preferences, err := p.store.GetPushPreferences(ctx, userID)
+	if err != nil || preferences != nil {
+		return preferences, err
+	}
+	return DefaultPushPreferences(), nil
+}
+
+// UpdatePreferences replaces the user's push preferences
+func (p *PushService) UpdatePreferences(ctx context.Context, userID uuid.UUID, preferences *PushPreferences) error {
+	for _, eventType := range preferences.MutedTypes {
+		if !eventType.IsValid() {
+			return apierror.New(apierror.CodeValidation, fmt.Sprintf("unknown notification type %q", eventType))
+		}
+	}
+	if preferences.MutedTypes == nil {
+		preferences.MutedTypes = []EventType{}
+	}
+	return p.store.SavePushPreferences(ctx, userID, preferences)
+}
+
+// Notify sends a notification to the user's devices in the background
+func (p *PushService) Notify(notification *Notification) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		defer cancel()
+		p.deliver(ctx, notification)
+	}()
+}
+
+// Wait blocks until deliveries in progress have finished
+func (p *PushService) Wait() {
+	p.wg.Wait()
+}
+
+// deliver sends notification to every subscription of its user that allows
+// its type, deleting subscriptions that have expired or were revoked
+func (p *PushService) deliver(ctx context.Context, notification *Notification) {
+	preferences, err := p.Preferences(ctx, notification.UserID)
+	if err != nil {
+		p.logger.LogError(err, "Failed to load push preferences")
+		return
+	}
+	if !preferences.allows(notification.Type) {
+		return
+	}
+
+	subscriptions, err := p.store.ListPushSubscriptions(ctx, notification.UserID)
+	if err != nil {
+		p.logger.LogError(err, "Failed to load push subscriptions")
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(PushMessage{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Content:   notification.Content,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		p.logger.LogError(err, "Failed to encode push message")
+		return
+	}
+
+	opts := webpush.Options{TTL: p.ttl, Topic: pushTopic(notification.ID)}
+	for _, subscription := range subscriptions {
+		if subscription.ExpiresAt != nil && !subscription.ExpiresAt.After(p.now()) {
+			p.expire(ctx, subscription, "expired")
+			continue
+		}
+		err := p.sender.Send(ctx, webpush.Subscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, payload, opts)
+		switch {
+		case errors.Is(err, webpush.ErrSubscriptionGone):
+			p.expire(ctx, subscription, "gone")
+		case err != nil:
+			p.logger.LogWarn("Failed to send push notification", map[string]interface{}{
+				"user_id":         notification.UserID.String(),
+				"notification_id": notification.ID.String(),
+				"error":           err.Error(),
+			})
+		}
+	}
+}
+
+// expire deletes a subscription the push service no longer accepts
+func (p *PushService) expire(ctx context.Context, subscription *PushSubscription, reason string) {
+	if err := p.store.DeletePushSubscription(ctx, subscription.UserID, subscription.Endpoint); err != nil {
+		p.logger.LogError(err, "Failed to delete dead push subscription")
+		return
+	}
+	p.logger.LogInfo("Deleted dead push subscription", map[string]interface{}{
+		"user_id": subscription.UserID.String(),
+		"reason":  reason,
+	})
+}
+
+// pushTopic derives a Topic header from the notification ID, so a message
+// queued twice for an offline device is only shown once. Topics are limited
+// to 32 URL-safe characters.
+func pushTopic(id uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// validatePushSubscription checks the fields a browser's PushSubscription provides
+func validatePushSubscription(subscription *PushSubscription) error {
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return apierror.New(apierror.CodeValidation, "endpoint must be an https URL")
+	}
+	if key, err := decodePushKey(subscription.P256dh); err != nil || len(key) != 65 {
+		return apierror.New(apierror.CodeValidation, "keys.p256dh must be a base64url encoded P-256 public key")
+	}
+	if secret, err := decodePushKey(subscription.Auth); err != nil || len(secret) != 16 {
+		return apierror.New(apierror.CodeValidation, "keys.auth must be a base64url encoded 16-byte secret")
+	}
+	return nil
+}
+
+// decodePushKey decodes a base64url key, with or without padding
+func decodePushKey(key string) ([]byte, error) {
+	if decoded, err := base64.RawURLEncoding.DecodeString(key); err == nil {
+		return decoded, nil
+	}
+	return base64.URLEncoding.DecodeString(key)
+}
+
+func hasEndpoint(subscriptions []*PushSubscription, endpoint string) bool {
+	for _, subscription := range subscriptions {
+		if subscription.Endpoint == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// PushNotifier sends notifications as push messages once they are stored.
+// Notifications merged into an existing one by aggregation are updates, so
+// they do not send another message.
+type PushNotifier struct {
+	NotificationRepository
+	push *PushService
+}
+
+// NewPushNotifier wraps repository so saved notifications are pushed
+func NewPushNotifier(repository NotificationRepository, push *PushService) *PushNotifier {
+	return &PushNotifier{NotificationRepository: repository, push: push}
+}
+
+// SaveNotification saves a notification and pushes it when it is unread
+func (n *PushNotifier) SaveNotification(ctx context.Context, notification *Notification) error {
+	if err := n.NotificationRepository.SaveNotification(ctx, notification); err != nil {
+		return err
+	}
+	if !notification.IsRead() {
+		n.push.Notify(notification)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package notification
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PushSubscriptionRequest is the JSON of a browser's PushSubscription
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	// ExpirationTime is milliseconds since the epoch, or null
+	ExpirationTime *int64 `json:"expirationTime"`
+	Keys           struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys"`
+}
+
+// PushUnsubscribeRequest names the subscription to remove
+type PushUnsubscribeRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
+
+// PushPublicKeyResponse carries the key browsers subscribe with
+type PushPublicKeyResponse struct {
+	// VAPID public key, base64url encoded; pass it as applicationServerKey to pushManager.subscribe
+	PublicKey string `json:"publicKey" example:"BEl62iUYgUivxIkv69yViEuiBIa-Ib9-SkvMeAtA3LFgDzkrxZJjSgSnfckjBJuBkr3qBUYIHBQFLXYp5Nksh8U"`
+}
+
+// SetPush enables the Web Push routes
+func (h *Handler) SetPush(push *PushService) {
+	h.push = push
+}
+
+// registerPushRoutes registers the Web Push routes on the authenticated notifications group
+func (h *Handler) registerPushRoutes(notifications gin.IRouter) {
+	if h.push == nil {
+		return
+	}
+	push := notifications.Group("/push")
+	push.GET("/public-key", h.handleGetPushPublicKey)
+	push.GET("/subscriptions", h.handleListPushSubscriptions)
+	push.POST("/subscriptions", h.handlePushSubscribe)
+	push.DELETE("/subscriptions", h.handlePushUnsubscribe)
+	push.GET("/preferences", h.handleGetPushPreferences)
+	push.PUT("/preferences", h.handleUpdatePushPreferences)
+}
+
+// @Summary Get the Web Push public key
+// @Description Returns the VAPID public key to pass as applicationServerKey when subscribing a browser with pushManager.subscribe
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=PushPublicKeyResponse} "Public key retrieved successfully"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Router /notifications/push/public-key [get]
+func (h *Handler) handleGetPushPublicKey(c *gin.Context) {
+	h.responseHandler.SuccessResponse(c, PushPublicKeyResponse{PublicKey: h.push.PublicKey()}, "Public key retrieved successfully")
+}
+
+// @Summary List push devices
+// @Description List the browsers registered to receive push notifications for the authenticated user
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=[]PushSubscription} "Push subscriptions retrieved successfully"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/push/subscriptions [get]
+func (h *Handler) handleListPushSubscriptions(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := h.push.ListSubscriptions(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to retrieve push subscriptions"), "")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, subscriptions, "Push subscriptions retrieved successfully")
+}
+
+// @Summary Register a push device
+// @Description Register the PushSubscription of a browser, as returned by pushManager.subscribe, to receive notifications as push messages. Registering the same endpoint again replaces its keys. Subscriptions the push service reports as expired are removed automatically.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param subscription body PushSubscriptionRequest true "The browser's PushSubscription as JSON"
+// @Success 200 {object} httpHandler.APIResponse{data=PushSubscription} "Push subscription registered"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid endpoint or keys"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Too many devices registered"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/push/subscriptions [post]
+func (h *Handler) handlePushSubscribe(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	var req PushSubscriptionRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	subscription := &PushSubscription{
+		UserID:    userID,
+		Endpoint:  req.Endpoint,
+		P256dh:    req.Keys.P256dh,
+		Auth:      req.Keys.Auth,
+		UserAgent: c.Request.UserAgent(),
+	}
+	if req.ExpirationTime != nil {
+		expiresAt := time.UnixMilli(*req.ExpirationTime).UTC()
+		subscription.ExpiresAt = &expiresAt
+	}
+
+	if err := h.push.Subscribe(c.Request.Context(), subscription); err != nil {
+		h.logger.LogInfo("Failed to register push subscription", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"user_id":    userID.String(),
+			"error":      err.Error(),
+		})
+		abortPushError(c, err, "Failed to register push subscription")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, subscription, "Push subscription registered")
+}
+
+// @Summary Unregister a push device
+// @Description Stop sending push messages to a browser, e.g. after pushSubscription.unsubscribe. Unknown endpoints are ignored.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param subscription body PushUnsubscribeRequest true "Endpoint of the subscription to remove"
+// @Success 200 {object} httpHandler.APIResponse "Push subscription removed"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Missing endpoint"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/push/subscriptions [delete]
+func (h *Handler) handlePushUnsubscribe(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	var req PushUnsubscribeRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	if err := h.push.Unsubscribe(c.Request.Context(), userID, req.Endpoint); err != nil {
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to remove push subscription"), "")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, nil, "Push subscription removed")
+}
+
+// @Summary Get push preferences
+// @Description Get which notification types are sent to the authenticated user's devices as push messages
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=PushPreferences} "Push preferences retrieved successfully"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/push/preferences [get]
+func (h *Handler) handleGetPushPreferences(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.push.Preferences(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to retrieve push preferences"), "")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, preferences, "Push preferences retrieved successfully")
+}
+
+// @Summary Update push preferences
+// @Description Turn push messages off, or mute notification types. Muted notifications are still stored and listed.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body PushPreferences true "New push preferences"
+// @Success 200 {object} httpHandler.APIResponse{data=PushPreferences} "Push preferences updated"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unknown notification type"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/push/preferences [put]
+func (h *Handler) handleUpdatePushPreferences(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	var preferences PushPreferences
+	if err := httpHandler.Bind(c, &preferences); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	if err := h.push.UpdatePreferences(c.Request.Context(), userID, &preferences); err != nil {
+		abortPushError(c, err, "Failed to update push preferences")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, preferences, "Push preferences updated")
+}
+
+// abortPushError answers with err when it was rejected as invalid, and as a
+// database error otherwise
+func abortPushError(c *gin.Context, err error, message string) {
+	var rejected *apierror.Error
+	if !errors.As(err, &rejected) {
+		err = apierror.Wrap(err, apierror.CodeDatabase, message)
+	}
+	apierror.Abort(c, err, "")
+}
+
+// pushUserID returns the authenticated user, answering the request when there is none
+func (h *Handler) pushUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeInternal, "Invalid user ID format", err)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
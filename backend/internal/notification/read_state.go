@@ -0,0 +1,69 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxReadStateIDs caps the notifications one read-state update can mark read
+const maxReadStateIDs = 100
+
+// ReadWatermark is a user's last_read_at: every notification created at or
+// before At counts as read, whether or not it was marked read on its own
+type ReadWatermark struct {
+	At time.Time
+	// The device that last moved the watermark
+	Device string
+	// When the watermark was last moved
+	UpdatedAt time.Time
+}
+
+// Apply marks n read when the watermark covers it and it was not marked
+// read on its own. Its read time is when the watermark last moved.
+func (w ReadWatermark) Apply(n *Notification) {
+	if n.ReadAt != nil || w.At.IsZero() || n.CreatedAt.After(w.At) {
+		return
+	}
+	readAt := w.UpdatedAt
+	if readAt.IsZero() {
+		readAt = w.At
+	}
+	n.ReadAt = &readAt
+}
+
+// ReadReceipt records a notification newer than the watermark that was marked read
+type ReadReceipt struct {
+	NotificationID uuid.UUID `json:"notificationId" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// When it was marked read
+	ReadAt time.Time `json:"readAt" example:"2025-03-05T21:30:00Z"`
+	// The device it was read on, when the client said
+	Device string `json:"device,omitempty" example:"ios-4f2a"`
+}
+
+// ReadState is everything a device needs to bring its read marks in line
+// with the user's other devices: notifications up to lastReadAt are read,
+// and so are those listed in read
+type ReadState struct {
+	// Notifications created at or before this are read (null if the user never marked all read)
+	LastReadAt *time.Time `json:"lastReadAt" example:"2025-03-05T21:26:06Z"`
+	// The device that last moved lastReadAt
+	Device string `json:"device,omitempty" example:"web-9c1d"`
+	// When lastReadAt last moved
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" example:"2025-03-05T21:30:00Z"`
+	// Notifications newer than lastReadAt that were marked read one by one
+	Read []ReadReceipt `json:"read"`
+	// Notifications that are still unread
+	UnreadCount int `json:"unreadCount" example:"3"`
+}
+
+// ReadStateUpdate is what a device sends to sync its read marks
+type ReadStateUpdate struct {
+	// Mark every notification created at or before this read. A watermark
+	// older than the stored one is ignored and one in the future is clamped to now.
+	LastReadAt *time.Time `json:"lastReadAt,omitempty" example:"2025-03-05T21:26:06Z"`
+	// Notifications to mark read, at most 100; unknown IDs are skipped
+	Read []uuid.UUID `json:"read,omitempty" binding:"max=100"`
+	// Identifies the device, recorded on the read marks
+	Device string `json:"device,omitempty" binding:"max=64" example:"web-9c1d"`
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get read state
+// @Description Get the authenticated user's read marks, so a device can sync them: every notification created at or before lastReadAt is read, and so is every notification listed in read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=ReadState} "Read state retrieved successfully"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/read-state [get]
+func (h *Handler) handleGetReadState(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	state, err := h.service.GetReadState(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Wrap(err, apierror.CodeDatabase, "Failed to retrieve read state"), "")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, state, "Read state retrieved successfully")
+}
+
+// @Summary Sync read state
+// @Description Send a device's read marks and get back the merged state. lastReadAt moves the read watermark forward and is ignored when older than the stored one; the notifications listed in read are marked read one by one, skipping unknown IDs.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param update body ReadStateUpdate true "Read marks to apply"
+// @Success 200 {object} httpHandler.APIResponse{data=ReadState} "Read state updated"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid read state"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /notifications/read-state [put]
+func (h *Handler) handleUpdateReadState(c *gin.Context) {
+	userID, ok := h.pushUserID(c)
+	if !ok {
+		return
+	}
+
+	var update ReadStateUpdate
+	if err := httpHandler.Bind(c, &update); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	state, err := h.service.UpdateReadState(c.Request.Context(), userID, &update)
+	if err != nil {
+		abortPushError(c, err, "Failed to update read state")
+		return
+	}
+	h.responseHandler.SuccessResponse(c, state, "Read state updated")
+}
@@ -0,0 +1,143 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+)
+
+// DefaultReplaySubscription is the dead letter subscription replays read from
+const DefaultReplaySubscription = "dead-letter-replay"
+
+// ReplayOptions controls a replay of the dead letter topic
+type ReplayOptions struct {
+	// Subscription is the dead letter subscription to read; defaults to DefaultReplaySubscription
+	Subscription string
+	// Limit caps the messages read; 0 reads until the topic is drained
+	Limit int
+	// Wait is how long to wait for the next message before the topic
+	// counts as drained; defaults to 5s
+	Wait time.Duration
+	// DryRun counts the messages that would be replayed without publishing
+	// or acknowledging them
+	DryRun bool
+}
+
+// ReplayResult summarises a replay of the dead letter topic
+type ReplayResult struct {
+	// Replayed counts the messages published again, by topic
+	Replayed map[string]int
+	// Skipped counts messages whose original topic is unknown; they are
+	// not acknowledged and stay in the dead letter topic
+	Skipped int
+}
+
+// ReplayTopic returns the event topic a dead letter message came from: the
+// topic Pulsar recorded when it dead-lettered the message, or else the topic
+// of its event type. It is empty when neither names an event topic.
+func (c *ServiceConfig) ReplayTopic(properties map[string]string) string {
+	topic := properties[pulsar.SysPropertyRealTopic]
+	if i := strings.LastIndex(topic, "-partition-"); i >= 0 {
+		topic = topic[:i]
+	}
+	switch topic {
+	case "":
+	case c.VideoEventsTopic, c.CommentEventsTopic, c.UserEventsTopic:
+		return topic
+	default:
+		return ""
+	}
+
+	eventType := properties["eventType"]
+	switch {
+	case strings.HasPrefix(eventType, "VIDEO_"), eventType == string(FollowedUserUploaded), eventType == string(LiveStarted):
+		return c.VideoEventsTopic
+	case strings.HasPrefix(eventType, "COMMENT_"):
+		return c.CommentEventsTopic
+	case strings.HasPrefix(eventType, "USER_"), eventType == string(AuthEvent):
+		return c.UserEventsTopic
+	}
+	return ""
+}
+
+// ReplayDeadLetters publishes the messages of the dead letter topic to the
+// event topics they came from, acknowledging each once it is published
+func (s *Service) ReplayDeadLetters(ctx context.Context, opts ReplayOptions) (*ReplayResult, error) {
+	if s.pulsarClient == nil {
+		return nil, fmt.Errorf("notification service is disabled")
+	}
+	if opts.Subscription == "" {
+		opts.Subscription = DefaultReplaySubscription
+	}
+	if opts.Wait <= 0 {
+		opts.Wait = 5 * time.Second
+	}
+
+	consumer, err := s.pulsarClient.Subscribe(pulsar.ConsumerOptions{
+		Topic:                       s.config.DeadLetterTopic,
+		SubscriptionName:            opts.Subscription,
+		Type:                        pulsar.Shared,
+		SubscriptionInitialPosition: pulsar.SubscriptionPositionEarliest,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to dead letter topic: %w", err)
+	}
+	defer consumer.Close()
+
+	producers := map[string]pulsar.Producer{
+		s.config.VideoEventsTopic:   s.videoProducer,
+		s.config.CommentEventsTopic: s.commentProducer,
+		s.config.UserEventsTopic:    s.userProducer,
+	}
+	result := &ReplayResult{Replayed: make(map[string]int)}
+	for read := 0; opts.Limit == 0 || read < opts.Limit; read++ {
+		receiveCtx, cancel := context.WithTimeout(ctx, opts.Wait)
+		msg, err := consumer.Receive(receiveCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return result, fmt.Errorf("failed to read dead letter topic: %w", err)
+		}
+
+		topic := s.config.ReplayTopic(msg.Properties())
+		if topic == "" {
+			result.Skipped++
+			s.logger.LogWarn("Skipped dead letter with unknown topic", map[string]interface{}{
+				"messageId":  msg.ID().String(),
+				"properties": msg.Properties(),
+			})
+			continue
+		}
+		if opts.DryRun {
+			result.Replayed[topic]++
+			continue
+		}
+
+		// Pulsar's dead letter bookkeeping is not carried over
+		properties := make(map[string]string, len(msg.Properties()))
+		for key, value := range msg.Properties() {
+			if key != pulsar.SysPropertyRealTopic && key != pulsar.PropertyOriginMessageID {
+				properties[key] = value
+			}
+		}
+		if _, err := s.send(ctx, producers[topic], topic, &pulsar.ProducerMessage{
+			Payload:    msg.Payload(),
+			Key:        msg.Key(),
+			Properties: properties,
+			EventTime:  msg.EventTime(),
+		}); err != nil {
+			return result, fmt.Errorf("failed to replay dead letter %s: %w", msg.ID(), err)
+		}
+		if err := consumer.Ack(msg); err != nil {
+			return result, fmt.Errorf("failed to acknowledge dead letter %s: %w", msg.ID(), err)
+		}
+		result.Replayed[topic]++
+	}
+	return result, nil
+}
@@ -54,7 +54,9 @@ func (r *Repository) SaveNotification(ctx context.Context, notification *Notific
 		r.keyspace, r.table,
 	)
 
-	err := r.session.Query(query,
+	// Notifications about a video are indexed by it in the same batch
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(query,
 		notification.ID,
 		notification.UserID,
 		notification.Type,
@@ -62,7 +64,14 @@ func (r *Repository) SaveNotification(ctx context.Context, notification *Notific
 		notification.Metadata,
 		notification.ReadAt,
 		notification.CreatedAt,
-	).Exec()
+	)
+	if videoID, ok := notification.VideoID(); ok {
+		batch.Query(fmt.Sprintf(`
+			INSERT INTO %s.notifications_by_video (video_id, user_id, created_at, id) 
+			VALUES (?, ?, ?, ?)`, r.keyspace),
+			videoID, notification.UserID, notification.CreatedAt, notification.ID)
+	}
+	err := r.session.ExecuteBatch(batch.WithContext(ctx))
 
 	if err != nil {
 		r.logger.LogError(err, "Failed to save notification")
@@ -78,28 +87,93 @@ func (r *Repository) SaveNotification(ctx context.Context, notification *Notific
 	return nil
 }
 
-// GetNotificationsByUserID retrieves notifications for a user with pagination
-func (r *Repository) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Notification, error) {
-	// Validate input
-	if limit <= 0 {
-		limit = 10 // Default limit
+// GetNotification retrieves a notification by ID
+func (r *Repository) GetNotification(ctx context.Context, notificationID uuid.UUID) (*Notification, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, type, content, metadata, read_at, created_at 
+		FROM %s.%s 
+		WHERE id = ?`,
+		r.keyspace, r.table,
+	)
+
+	var id, uid gocql.UUID
+	var notificationType string
+	var notification Notification
+	err := r.session.Query(query, notificationID).WithContext(ctx).Scan(
+		&id, &uid, &notificationType, &notification.Content, &notification.Metadata, &notification.ReadAt, &notification.CreatedAt,
+	)
+	if err == gocql.ErrNotFound {
+		return nil, ErrNotificationNotFound
 	}
-	if offset < 0 {
-		offset = 0
+	if err != nil {
+		r.logger.LogError(err, "Failed to get notification")
+		return nil, fmt.Errorf("failed to get notification: %w", err)
 	}
 
-	// Execute the query with pagination
+	notification.ID = uuid.UUID(id)
+	notification.UserID = uuid.UUID(uid)
+	notification.Type = EventType(notificationType)
+
+	watermark, err := r.readWatermark(ctx, notification.UserID)
+	if err != nil {
+		return nil, err
+	}
+	watermark.Apply(&notification)
+	return &notification, nil
+}
+
+// UpdateNotification replaces the content and metadata of a notification in place
+func (r *Repository) UpdateNotification(ctx context.Context, notification *Notification) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.%s 
+		SET content = ?, metadata = ? 
+		WHERE user_id = ? AND created_at = ? AND id = ?`,
+		r.keyspace, r.table,
+	)
+
+	err := r.session.Query(query,
+		notification.Content,
+		notification.Metadata,
+		notification.UserID,
+		notification.CreatedAt,
+		notification.ID,
+	).WithContext(ctx).Exec()
+
+	if err != nil {
+		r.logger.LogError(err, "Failed to update notification")
+		return fmt.Errorf("failed to update notification: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotificationsByUserID retrieves notifications for a user with cursor pagination
+func (r *Repository) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, opts ListOptions) ([]*Notification, error) {
+	// Validate input
+	if opts.Limit <= 0 {
+		opts.Limit = 10 // Default limit
+	}
+
+	// Resume from the cursor position when one is given
 	query := fmt.Sprintf(`
 		SELECT id, user_id, type, content, metadata, read_at, created_at 
 		FROM %s.%s 
-		WHERE user_id = ? 
-		ORDER BY created_at DESC 
-		LIMIT ?`,
+		WHERE user_id = ?`,
 		r.keyspace, r.table,
 	)
+	args := []interface{}{userID}
+	if opts.Cursor != nil {
+		query += ` AND created_at <= ?`
+		args = append(args, opts.Cursor.CreatedAt)
+	}
+
+	watermark, err := r.readWatermark(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
 	// Execute query
-	iter := r.session.Query(query, userID, limit).PageSize(limit).Iter()
+	iter := r.session.Query(query, args...).WithContext(ctx).PageSize(opts.Limit).Iter()
 
 	// Process results
 	var notifications []*Notification
@@ -110,13 +184,12 @@ func (r *Repository) GetNotificationsByUserID(ctx context.Context, userID uuid.U
 	var readAt *time.Time
 	var createdAt time.Time
 
-	// Skip offset records
-	for i := 0; i < offset && iter.Scan(&id, &uid, &notificationType, &content, &metadata, &readAt, &createdAt); i++ {
-		// Just skipping records for offset
-	}
+	filter := NewCursorFilter(opts.Cursor)
+	for len(notifications) < opts.Limit && iter.Scan(&id, &uid, &notificationType, &content, &metadata, &readAt, &createdAt) {
+		if filter.Skip(createdAt, uuid.UUID(id)) {
+			continue
+		}
 
-	// Process the remaining records up to the limit
-	for iter.Scan(&id, &uid, &notificationType, &content, &metadata, &readAt, &createdAt) {
 		notif := &Notification{
 			ID:        uuid.UUID(id),
 			UserID:    uuid.UUID(uid),
@@ -126,6 +199,11 @@ func (r *Repository) GetNotificationsByUserID(ctx context.Context, userID uuid.U
 			ReadAt:    readAt,
 			CreatedAt: createdAt,
 		}
+		watermark.Apply(notif)
+		readAt = nil
+		if opts.UnreadOnly && notif.IsRead() {
+			continue
+		}
 		notifications = append(notifications, notif)
 	}
 
@@ -137,35 +215,133 @@ func (r *Repository) GetNotificationsByUserID(ctx context.Context, userID uuid.U
 	return notifications, nil
 }
 
+// GetNotificationsSince retrieves notifications created after since, oldest first
+func (r *Repository) GetNotificationsSince(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*Notification, error) {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, type, content, metadata, read_at, created_at 
+		FROM %s.%s 
+		WHERE user_id = ? AND created_at > ? 
+		ORDER BY created_at ASC 
+		LIMIT ?`,
+		r.keyspace, r.table,
+	)
+
+	watermark, err := r.readWatermark(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := r.session.Query(query, userID, since, limit).WithContext(ctx).Iter()
+
+	var notifications []*Notification
+	var id, uid gocql.UUID
+	var notificationType string
+	var content string
+	var metadata map[string]interface{}
+	var readAt *time.Time
+	var createdAt time.Time
+
+	for iter.Scan(&id, &uid, &notificationType, &content, &metadata, &readAt, &createdAt) {
+		notif := &Notification{
+			ID:        uuid.UUID(id),
+			UserID:    uuid.UUID(uid),
+			Type:      EventType(notificationType),
+			Content:   content,
+			Metadata:  metadata,
+			ReadAt:    readAt,
+			CreatedAt: createdAt,
+		}
+		watermark.Apply(notif)
+		notifications = append(notifications, notif)
+		metadata = nil
+		readAt = nil
+	}
+
+	if err := iter.Close(); err != nil {
+		r.logger.LogError(err, "Failed to get notifications since cursor")
+		return nil, fmt.Errorf("failed to get notifications since cursor: %w", err)
+	}
+
+	return notifications, nil
+}
+
 // GetUnreadCount gets the count of unread notifications for a user
 func (r *Repository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	state, err := r.GetReadState(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return state.UnreadCount, nil
+}
+
+// GetReadState returns the user's read watermark, the notifications newer
+// than it that were marked read and the count of those that were not
+func (r *Repository) GetReadState(ctx context.Context, userID uuid.UUID) (*ReadState, error) {
+	watermark, err := r.readWatermark(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ReadState{Device: watermark.Device, Read: []ReadReceipt{}}
 	query := fmt.Sprintf(`
-		SELECT COUNT(*) 
+		SELECT id, read_at, read_device 
 		FROM %s.%s 
-		WHERE user_id = ? AND read_at IS NULL`,
+		WHERE user_id = ?`,
 		r.keyspace, r.table,
 	)
+	args := []interface{}{userID}
+	if !watermark.At.IsZero() {
+		state.LastReadAt = &watermark.At
+		state.UpdatedAt = &watermark.UpdatedAt
+		query += ` AND created_at > ?`
+		args = append(args, watermark.At)
+	}
 
-	var count int
-	if err := r.session.Query(query, userID).Scan(&count); err != nil {
-		r.logger.LogError(err, "Failed to get unread count")
-		return 0, fmt.Errorf("failed to get unread count: %w", err)
+	iter := r.session.Query(query, args...).WithContext(ctx).Iter()
+
+	var id gocql.UUID
+	var readAt *time.Time
+	var device string
+	for iter.Scan(&id, &readAt, &device) {
+		if readAt == nil {
+			state.UnreadCount++
+		} else {
+			state.Read = append(state.Read, ReadReceipt{NotificationID: uuid.UUID(id), ReadAt: *readAt, Device: device})
+		}
+		readAt = nil
+		device = ""
+	}
+
+	if err := iter.Close(); err != nil {
+		r.logger.LogError(err, "Failed to get read state")
+		return nil, fmt.Errorf("failed to get read state: %w", err)
 	}
 
-	return count, nil
+	return state, nil
 }
 
-// MarkAsRead marks a notification as read
-func (r *Repository) MarkAsRead(ctx context.Context, notificationID uuid.UUID) error {
-	now := time.Now()
+// MarkAsRead marks one of the user's notifications as read, looking up its
+// clustering key so the update names the full primary key
+func (r *Repository) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID, device string) error {
+	notification, err := r.GetNotification(ctx, notificationID)
+	if err != nil {
+		return err
+	}
+	if notification.UserID != userID {
+		return ErrNotificationNotFound
+	}
+	if notification.IsRead() {
+		return nil
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE %s.%s 
-		SET read_at = ? 
-		WHERE id = ?`,
+		SET read_at = ?, read_device = ? 
+		WHERE user_id = ? AND created_at = ? AND id = ?`,
 		r.keyspace, r.table,
 	)
 
-	if err := r.session.Query(query, now, notificationID).Exec(); err != nil {
+	if err := r.session.Query(query, gocql.UUIDFromTime(time.Now()), device, userID, notification.CreatedAt, notificationID).WithContext(ctx).Exec(); err != nil {
 		r.logger.LogError(err, "Failed to mark notification as read")
 		return fmt.Errorf("failed to mark notification as read: %w", err)
 	}
@@ -173,20 +349,93 @@ func (r *Repository) MarkAsRead(ctx context.Context, notificationID uuid.UUID) e
 	return nil
 }
 
-// MarkAllAsRead marks all notifications for a user as read
-func (r *Repository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
-	now := time.Now()
+// MarkAllAsRead moves the user's read watermark to at, stamping the write
+// with at so the newest watermark wins
+func (r *Repository) MarkAllAsRead(ctx context.Context, userID uuid.UUID, at time.Time, device string) error {
 	query := fmt.Sprintf(`
-		UPDATE %s.%s 
-		SET read_at = ? 
-		WHERE user_id = ? AND read_at IS NULL`,
+		UPDATE %s.notification_read_state USING TIMESTAMP ? 
+		SET last_read_at = ?, device = ?, updated_at = ? 
+		WHERE user_id = ?`,
+		r.keyspace,
+	)
+
+	if err := r.session.Query(query, at.UnixMicro(), at, device, time.Now(), userID).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to move read watermark")
+		return fmt.Errorf("failed to move read watermark: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotificationsByVideoID retrieves the notifications indexed under a video
+func (r *Repository) GetNotificationsByVideoID(ctx context.Context, videoID uuid.UUID) ([]*Notification, error) {
+	indexQuery := fmt.Sprintf(`
+		SELECT user_id, created_at, id 
+		FROM %s.notifications_by_video 
+		WHERE video_id = ?`,
+		r.keyspace,
+	)
+	query := fmt.Sprintf(`
+		SELECT type, content, metadata 
+		FROM %s.%s 
+		WHERE user_id = ? AND created_at = ? AND id = ?`,
 		r.keyspace, r.table,
 	)
 
-	if err := r.session.Query(query, now, userID).Exec(); err != nil {
-		r.logger.LogError(err, "Failed to mark all notifications as read")
-		return fmt.Errorf("failed to mark all notifications as read: %w", err)
+	var notifications []*Notification
+	iter := r.session.Query(indexQuery, videoID).WithContext(ctx).Iter()
+	var uid, id gocql.UUID
+	var createdAt time.Time
+	for iter.Scan(&uid, &createdAt, &id) {
+		notification := &Notification{ID: uuid.UUID(id), UserID: uuid.UUID(uid), CreatedAt: createdAt}
+		var notificationType string
+		err := r.session.Query(query, uid, createdAt, id).WithContext(ctx).Scan(&notificationType, &notification.Content, &notification.Metadata)
+		if err == gocql.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			iter.Close()
+			r.logger.LogError(err, "Failed to get notification by video ID")
+			return nil, fmt.Errorf("failed to get notification by video ID: %w", err)
+		}
+		notification.Type = EventType(notificationType)
+		notifications = append(notifications, notification)
+	}
+	if err := iter.Close(); err != nil {
+		r.logger.LogError(err, "Failed to get notifications by video ID")
+		return nil, fmt.Errorf("failed to get notifications by video ID: %w", err)
 	}
 
+	return notifications, nil
+}
+
+// ForgetVideo drops a video's partition of notifications_by_video
+func (r *Repository) ForgetVideo(ctx context.Context, videoID uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s.notifications_by_video WHERE video_id = ?`, r.keyspace)
+	if err := r.session.Query(query, videoID).WithContext(ctx).Exec(); err != nil {
+		r.logger.LogError(err, "Failed to drop notifications by video ID")
+		return fmt.Errorf("failed to drop notifications by video ID: %w", err)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// readWatermark returns the user's read watermark, zero when there is none
+func (r *Repository) readWatermark(ctx context.Context, userID uuid.UUID) (ReadWatermark, error) {
+	query := fmt.Sprintf(`
+		SELECT last_read_at, device, updated_at 
+		FROM %s.notification_read_state 
+		WHERE user_id = ?`,
+		r.keyspace,
+	)
+
+	var watermark ReadWatermark
+	err := r.session.Query(query, userID).WithContext(ctx).Scan(&watermark.At, &watermark.Device, &watermark.UpdatedAt)
+	if err == gocql.ErrNotFound {
+		return ReadWatermark{}, nil
+	}
+	if err != nil {
+		r.logger.LogError(err, "Failed to get read watermark")
+		return ReadWatermark{}, fmt.Errorf("failed to get read watermark: %w", err)
+	}
+	return watermark, nil
+}
@@ -35,6 +35,16 @@ func (m *SchemaManager) CreateTables() error {
 		return err
 	}
 
+	// Create the read watermark table
+	if err := m.createReadStateTable(); err != nil {
+		return err
+	}
+
+	// Create the table of notifications by the video they refer to
+	if err := m.createVideoIndexTable(); err != nil {
+		return err
+	}
+
 	m.logger.LogInfo("Notification tables created successfully", nil)
 	return nil
 }
@@ -50,6 +60,7 @@ func (m *SchemaManager) createNotificationsTable() error {
 			content text,
 			metadata blob,
 			read_at timeuuid,
+			read_device text,
 			created_at timestamp,
 			PRIMARY KEY ((user_id), created_at, id)
 		) WITH CLUSTERING ORDER BY (created_at DESC, id ASC)`,
@@ -64,6 +75,48 @@ func (m *SchemaManager) createNotificationsTable() error {
 	return nil
 }
 
+// createReadStateTable creates the table of per-user read watermarks
+func (m *SchemaManager) createReadStateTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s.notification_read_state (
+			user_id uuid PRIMARY KEY,
+			last_read_at timestamp,
+			device text,
+			updated_at timestamp
+		)`,
+		m.keyspace,
+	)
+
+	if err := m.session.Query(query).Exec(); err != nil {
+		m.logger.LogError(err, "Failed to create notification read state table")
+		return fmt.Errorf("failed to create notification read state table: %w", err)
+	}
+
+	return nil
+}
+
+// createVideoIndexTable creates the table listing the notifications that
+// refer to each video
+func (m *SchemaManager) createVideoIndexTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s.notifications_by_video (
+			video_id uuid,
+			user_id uuid,
+			created_at timestamp,
+			id uuid,
+			PRIMARY KEY ((video_id), user_id, created_at, id)
+		)`,
+		m.keyspace,
+	)
+
+	if err := m.session.Query(query).Exec(); err != nil {
+		m.logger.LogError(err, "Failed to create notifications by video table")
+		return fmt.Errorf("failed to create notifications by video table: %w", err)
+	}
+
+	return nil
+}
+
 // createIndexes creates the necessary indexes for the notifications table
 func (m *SchemaManager) createIndexes() error {
 	// Create index on notification ID for quick lookups
@@ -110,6 +163,18 @@ func (m *SchemaManager) DropTables() error {
 		return fmt.Errorf("failed to drop notifications table: %w", err)
 	}
 
+	query = fmt.Sprintf(`DROP TABLE IF EXISTS %s.notification_read_state`, m.keyspace)
+	if err := m.session.Query(query).Exec(); err != nil {
+		m.logger.LogError(err, "Failed to drop notification read state table")
+		return fmt.Errorf("failed to drop notification read state table: %w", err)
+	}
+
+	query = fmt.Sprintf(`DROP TABLE IF EXISTS %s.notifications_by_video`, m.keyspace)
+	if err := m.session.Query(query).Exec(); err != nil {
+		m.logger.LogError(err, "Failed to drop notifications by video table")
+		return fmt.Errorf("failed to drop notifications by video table: %w", err)
+	}
+
 	m.logger.LogInfo("Notification tables dropped successfully", nil)
 	return nil
 }
\ No newline at end of file
@@ -3,11 +3,14 @@ package notification
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
 	"github.com/google/uuid"
 )
 
@@ -17,6 +20,10 @@ type Service struct {
 	logger       logger.Logger
 	pulsarClient pulsar.Client
 	repository   NotificationRepository
+	aggregator   *Aggregator
+	followers    FollowerLister
+	blocks       BlockChecker
+	metrics      *Metrics
 	
 	// Producers for different event types
 	videoProducer   pulsar.Producer
@@ -32,7 +39,7 @@ type Service struct {
 func NewService(ctx context.Context, config *ServiceConfig, logger logger.Logger, repository NotificationRepository) (*Service, error) {
 	if !config.Enabled {
 		logger.LogInfo("Notification service is disabled", nil)
-		return &Service{config: config, logger: logger, repository: repository}, nil
+		return &Service{config: config, logger: logger, repository: repository, aggregator: NewAggregator(repository, config.AggregationWindow)}, nil
 	}
 
 	// Create Pulsar client
@@ -63,6 +70,7 @@ func NewService(ctx context.Context, config *ServiceConfig, logger logger.Logger
 		logger:       logger,
 		pulsarClient: client,
 		repository:   repository,
+		aggregator:   NewAggregator(repository, config.AggregationWindow),
 	}
 
 	// Initialize producers
@@ -129,6 +137,16 @@ func (s *Service) createProducer(ctx context.Context, topic string) (pulsar.Prod
 	})
 }
 
+// send publishes msg under a producer span, carrying the trace context in
+// the message properties so consumers can continue the trace
+func (s *Service) send(ctx context.Context, producer pulsar.Producer, topic string, msg *pulsar.ProducerMessage) (pulsar.MessageID, error) {
+	ctx, span := tracing.StartProducer(ctx, topic)
+	tracing.InjectMessage(ctx, msg)
+	msgID, err := producer.Send(ctx, msg)
+	tracing.End(span, err)
+	return msgID, err
+}
+
 // PublishVideoEvent publishes a video-related notification event
 func (s *Service) PublishVideoEvent(ctx context.Context, event *VideoEvent) error {
 	if !s.config.Enabled {
@@ -150,6 +168,8 @@ func (s *Service) PublishVideoEvent(ctx context.Context, event *VideoEvent) erro
 		event.SequenceNumber = time.Now().UnixNano()
 	}
 
+	start := time.Now()
+
 	// Serialize the event to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -169,8 +189,9 @@ func (s *Service) PublishVideoEvent(ctx context.Context, event *VideoEvent) erro
 	}
 
 	// Send the message to Pulsar
-	msgID, err := s.videoProducer.Send(ctx, msg)
+	msgID, err := s.send(ctx, s.videoProducer, s.config.VideoEventsTopic, msg)
 	if err != nil {
+		s.metrics.RecordFailure(s.config.VideoEventsTopic, StagePublish)
 		return fmt.Errorf("failed to publish video event: %w", err)
 	}
 
@@ -192,17 +213,59 @@ func (s *Service) PublishVideoEvent(ctx context.Context, event *VideoEvent) erro
 	}
 
 	// Store the notification in the repository if we have one
+	var persistErr error
 	if s.repository != nil {
-		if err := s.repository.SaveNotification(ctx, notification); err != nil {
-			s.logger.LogError(err, "Failed to save video notification to repository")
+		if persistErr = s.repository.SaveNotification(ctx, notification); persistErr != nil {
+			s.logger.LogError(persistErr, "Failed to save video notification to repository")
 			// We don't return the error as the message was already published to Pulsar
 			// This is a non-critical error for the notification flow
 		}
 	}
+	s.recordOutcome(s.config.VideoEventsTopic, start, persistErr)
 
 	return nil
 }
 
+// SetFollowerLister enables fan-out of upload notifications to a creator's
+// followers by a FollowerFanoutConsumer
+func (s *Service) SetFollowerLister(followers FollowerLister) {
+	s.followers = followers
+}
+
+// SetBlockChecker stops notifications caused by users the recipient blocked
+func (s *Service) SetBlockChecker(blocks BlockChecker) {
+	s.blocks = blocks
+}
+
+// blocked reports whether recipientID blocked actorID. Lookup failures are
+// logged and the notification is delivered.
+func (s *Service) blocked(ctx context.Context, recipientID, actorID uuid.UUID) bool {
+	if s.blocks == nil || actorID == uuid.Nil {
+		return false
+	}
+	blocked, err := s.blocks.IsBlocked(ctx, recipientID, actorID)
+	if err != nil {
+		s.logger.LogError(err, "Failed to check blocks for notification")
+		return false
+	}
+	return blocked
+}
+
+// SetMetrics enables throughput and failure metrics for published events
+func (s *Service) SetMetrics(metrics *Metrics) {
+	s.metrics = metrics
+}
+
+// recordOutcome records a published event as processed, or as a persist
+// failure when storing the notification failed
+func (s *Service) recordOutcome(topic string, start time.Time, persistErr error) {
+	if persistErr != nil {
+		s.metrics.RecordFailure(topic, StagePersist)
+		return
+	}
+	s.metrics.RecordProcessed(topic, time.Since(start))
+}
+
 // PublishCommentEvent publishes a comment-related notification event
 func (s *Service) PublishCommentEvent(ctx context.Context, event *CommentEvent) error {
 	if !s.config.Enabled {
@@ -224,6 +287,8 @@ func (s *Service) PublishCommentEvent(ctx context.Context, event *CommentEvent)
 		event.SequenceNumber = time.Now().UnixNano()
 	}
 
+	start := time.Now()
+
 	// Serialize the event to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -243,8 +308,9 @@ func (s *Service) PublishCommentEvent(ctx context.Context, event *CommentEvent)
 	}
 
 	// Send the message
-	msgID, err := s.commentProducer.Send(ctx, msg)
+	msgID, err := s.send(ctx, s.commentProducer, s.config.CommentEventsTopic, msg)
 	if err != nil {
+		s.metrics.RecordFailure(s.config.CommentEventsTopic, StagePublish)
 		return fmt.Errorf("failed to publish comment event: %w", err)
 	}
 
@@ -265,14 +331,24 @@ func (s *Service) PublishCommentEvent(ctx context.Context, event *CommentEvent)
 		CreatedAt: event.CreatedAt,
 	}
 
-	// Store the notification in the repository if we have one
-	if s.repository != nil {
-		if err := s.repository.SaveNotification(ctx, notification); err != nil {
-			s.logger.LogError(err, "Failed to save comment notification to repository")
+	// Deletions only keep comment counts in line, and nobody is told about
+	// their own comments or about comments whose recipient is unknown
+	if event.Type == CommentDeleted || event.UserID == uuid.Nil || event.ActorID == event.UserID {
+		s.recordOutcome(s.config.CommentEventsTopic, start, nil)
+		return nil
+	}
+
+	// Store the notification in the repository if we have one, unless the
+	// recipient blocked the user who caused it
+	var persistErr error
+	if s.repository != nil && !s.blocked(ctx, event.UserID, event.ActorID) {
+		if persistErr = s.aggregator.Save(ctx, notification, event.ActorID); persistErr != nil {
+			s.logger.LogError(persistErr, "Failed to save comment notification to repository")
 			// We don't return the error as the message was already published to Pulsar
 			// This is a non-critical error for the notification flow
 		}
 	}
+	s.recordOutcome(s.config.CommentEventsTopic, start, persistErr)
 
 	return nil
 }
@@ -298,6 +374,8 @@ func (s *Service) PublishUserEvent(ctx context.Context, event *UserEvent) error
 		event.SequenceNumber = time.Now().UnixNano()
 	}
 
+	start := time.Now()
+
 	// Serialize the event to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -317,8 +395,9 @@ func (s *Service) PublishUserEvent(ctx context.Context, event *UserEvent) error
 	}
 
 	// Send the message
-	msgID, err := s.userProducer.Send(ctx, msg)
+	msgID, err := s.send(ctx, s.userProducer, s.config.UserEventsTopic, msg)
 	if err != nil {
+		s.metrics.RecordFailure(s.config.UserEventsTopic, StagePublish)
 		return fmt.Errorf("failed to publish user event: %w", err)
 	}
 
@@ -339,39 +418,75 @@ func (s *Service) PublishUserEvent(ctx context.Context, event *UserEvent) error
 		CreatedAt: event.CreatedAt,
 	}
 
-	// Store the notification in the repository if we have one
-	if s.repository != nil {
-		if err := s.repository.SaveNotification(ctx, notification); err != nil {
-			s.logger.LogError(err, "Failed to save user notification to repository")
+	// Unfollows are published for consumers but never shown to the target user
+	if event.Type == UserUnfollowed {
+		s.recordOutcome(s.config.UserEventsTopic, start, nil)
+		return nil
+	}
+
+	// Store the notification in the repository if we have one, unless the
+	// target user blocked the user who caused it
+	var persistErr error
+	if s.repository != nil && !s.blocked(ctx, event.TargetUserID, event.UserID) {
+		if persistErr = s.aggregator.Save(ctx, notification, event.UserID); persistErr != nil {
+			s.logger.LogError(persistErr, "Failed to save user notification to repository")
 			// We don't return the error as the message was already published to Pulsar
 			// This is a non-critical error for the notification flow
 		}
 	}
+	s.recordOutcome(s.config.UserEventsTopic, start, persistErr)
 
 	return nil
 }
 
-// GetUserNotifications gets notifications for a user
-func (s *Service) GetUserNotifications(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*Notification, error) {
+// GetUserNotifications gets a page of notifications for a user
+func (s *Service) GetUserNotifications(ctx context.Context, userID uuid.UUID, opts ListOptions) (*NotificationPage, error) {
 	if s.repository == nil {
 		s.logger.LogWarn("Repository not initialized, returning empty notifications list", map[string]interface{}{
 			"userID": userID.String(),
 		})
-		return []*Notification{}, nil
+		return &NotificationPage{Notifications: []*Notification{}}, nil
+	}
+
+	if opts.Limit <= 0 {
+		opts.Limit = 10
 	}
 
-	notifications, err := s.repository.GetNotificationsByUserID(ctx, userID, limit, offset)
+	// Fetch one extra row to find out whether another page exists
+	limit := opts.Limit
+	opts.Limit = limit + 1
+
+	notifications, err := s.repository.GetNotificationsByUserID(ctx, userID, opts)
 	if err != nil {
 		s.logger.LogError(err, "Failed to get notifications for user")
 		return nil, fmt.Errorf("failed to get notifications for user: %w", err)
 	}
 
+	unread, err := s.repository.GetUnreadCount(ctx, userID)
+	if err != nil {
+		s.logger.LogError(err, "Failed to get unread count for user")
+		return nil, fmt.Errorf("failed to get unread count for user: %w", err)
+	}
+
+	page := &NotificationPage{
+		Notifications: notifications,
+		TotalUnread:   unread,
+	}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		page.HasMore = true
+		page.NextCursor = NewCursor(page.Notifications[limit-1]).Encode()
+	}
+
 	s.logger.LogInfo("Retrieved notifications for user", map[string]interface{}{
-		"userID": userID.String(),
-		"count": len(notifications),
+		"userID":      userID.String(),
+		"count":       len(page.Notifications),
+		"unreadOnly":  opts.UnreadOnly,
+		"hasMore":     page.HasMore,
+		"totalUnread": unread,
 	})
 
-	return notifications, nil
+	return page, nil
 }
 
 // GetUnreadCount gets the count of unread notifications
@@ -397,8 +512,8 @@ func (s *Service) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, er
 	return count, nil
 }
 
-// MarkAsRead marks a notification as read
-func (s *Service) MarkAsRead(ctx context.Context, notificationID uuid.UUID) error {
+// MarkAsRead marks one of the user's notifications as read
+func (s *Service) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID) error {
 	if s.repository == nil {
 		s.logger.LogWarn("Repository not initialized, cannot mark notification as read", map[string]interface{}{
 			"notificationID": notificationID.String(),
@@ -406,20 +521,22 @@ func (s *Service) MarkAsRead(ctx context.Context, notificationID uuid.UUID) erro
 		return nil
 	}
 
-	err := s.repository.MarkAsRead(ctx, notificationID)
+	err := s.repository.MarkAsRead(ctx, userID, notificationID, "")
 	if err != nil {
 		s.logger.LogError(err, "Failed to mark notification as read")
 		return fmt.Errorf("failed to mark notification as read: %w", err)
 	}
 
 	s.logger.LogInfo("Marked notification as read", map[string]interface{}{
+		"userID":         userID.String(),
 		"notificationID": notificationID.String(),
 	})
 
 	return nil
 }
 
-// MarkAllAsRead marks all notifications as read
+// MarkAllAsRead marks all notifications as read by moving the user's read
+// watermark to now
 func (s *Service) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
 	if s.repository == nil {
 		s.logger.LogWarn("Repository not initialized, cannot mark all notifications as read", map[string]interface{}{
@@ -428,7 +545,7 @@ func (s *Service) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
 		return nil
 	}
 
-	err := s.repository.MarkAllAsRead(ctx, userID)
+	err := s.repository.MarkAllAsRead(ctx, userID, time.Now(), "")
 	if err != nil {
 		s.logger.LogError(err, "Failed to mark all notifications as read")
 		return fmt.Errorf("failed to mark all notifications as read: %w", err)
@@ -441,6 +558,95 @@ func (s *Service) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
 	return nil
 }
 
+// GetReadState returns the user's read watermark and the notifications
+// marked read after it
+func (s *Service) GetReadState(ctx context.Context, userID uuid.UUID) (*ReadState, error) {
+	if s.repository == nil {
+		s.logger.LogWarn("Repository not initialized, returning empty read state", map[string]interface{}{
+			"userID": userID.String(),
+		})
+		return &ReadState{Read: []ReadReceipt{}}, nil
+	}
+
+	state, err := s.repository.GetReadState(ctx, userID)
+	if err != nil {
+		s.logger.LogError(err, "Failed to get read state for user")
+		return nil, fmt.Errorf("failed to get read state for user: %w", err)
+	}
+	return state, nil
+}
+
+// UpdateReadState applies a device's read marks: the watermark moves forward
+// when lastReadAt is newer, and the listed notifications are marked read.
+// Notifications the user does not have are skipped, since a device may sync
+// marks for notifications deleted since. It returns the resulting state.
+func (s *Service) UpdateReadState(ctx context.Context, userID uuid.UUID, update *ReadStateUpdate) (*ReadState, error) {
+	if len(update.Read) > maxReadStateIDs {
+		return nil, apierror.New(apierror.CodeValidation, fmt.Sprintf("At most %d notifications can be marked read at once", maxReadStateIDs))
+	}
+	if s.repository == nil {
+		return s.GetReadState(ctx, userID)
+	}
+
+	if update.LastReadAt != nil {
+		at := *update.LastReadAt
+		if now := time.Now(); at.After(now) {
+			at = now
+		}
+		if err := s.repository.MarkAllAsRead(ctx, userID, at, update.Device); err != nil {
+			s.logger.LogError(err, "Failed to move read watermark")
+			return nil, fmt.Errorf("failed to move read watermark: %w", err)
+		}
+	}
+
+	skipped := 0
+	for _, notificationID := range update.Read {
+		err := s.repository.MarkAsRead(ctx, userID, notificationID, update.Device)
+		if errors.Is(err, ErrNotificationNotFound) {
+			skipped++
+			continue
+		}
+		if err != nil {
+			s.logger.LogError(err, "Failed to mark notification as read")
+			return nil, fmt.Errorf("failed to mark notification as read: %w", err)
+		}
+	}
+
+	s.logger.LogInfo("Updated read state for user", map[string]interface{}{
+		"userID":  userID.String(),
+		"device":  update.Device,
+		"read":    len(update.Read) - skipped,
+		"skipped": skipped,
+	})
+
+	return s.GetReadState(ctx, userID)
+}
+
+// Ping checks that the Pulsar broker is reachable by looking up the video
+// events topic. It does nothing when the service is disabled.
+func (s *Service) Ping(ctx context.Context) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		// The client has no context-aware call, so the lookup may outlive ctx
+		_, err := s.pulsarClient.TopicPartitions(s.config.VideoEventsTopic)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to reach Pulsar: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Close closes the notification service and releases resources
 func (s *Service) Close() error {
 	if !s.config.Enabled {
@@ -486,6 +692,8 @@ func createContentFromVideoEvent(event *VideoEvent) string {
 		return fmt.Sprintf("Your video '%s' has been updated", event.Title)
 	case VideoDeleted:
 		return fmt.Sprintf("Your video '%s' has been deleted", event.Title)
+	case LiveStarted:
+		return fmt.Sprintf("Your live stream '%s' has started", event.Title)
 	default:
 		return fmt.Sprintf("Video notification: %s", event.Title)
 	}
@@ -496,7 +704,9 @@ func createMetadataFromVideoEvent(event *VideoEvent) map[string]interface{} {
 	metadata := make(map[string]interface{})
 	
 	// Add basic video information
-	metadata["videoId"] = event.VideoID.String()
+	if event.VideoID != uuid.Nil {
+		metadata["videoId"] = event.VideoID.String()
+	}
 	metadata["userId"] = event.UserID.String()
 	metadata["eventType"] = string(event.Type)
 	
@@ -543,6 +753,11 @@ func createMetadataFromCommentEvent(event *CommentEvent) map[string]interface{}
 		metadata["videoId"] = event.VideoID.String()
 	}
 	
+	// Add the actor if available
+	if event.ActorID != uuid.Nil {
+		metadata["actorId"] = event.ActorID.String()
+	}
+
 	// Add parent comment ID if available
 	if event.ParentID != uuid.Nil {
 		metadata["parentId"] = event.ParentID.String()
@@ -573,6 +788,8 @@ func createContentFromUserEvent(event *UserEvent) string {
 	switch event.Type {
 	case UserFollowed:
 		return "Someone started following you"
+	case UserUnfollowed:
+		return "Someone stopped following you"
 	case UserMentioned:
 		return "You were mentioned in a comment"
 	case AuthEvent:
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reaction returns a reaction notification for recipient about commentID
+func reaction(recipient uuid.UUID, commentID string, at time.Time) *notification.Notification {
+	return &notification.Notification{
+		ID:        uuid.New(),
+		UserID:    recipient,
+		Type:      notification.CommentReaction,
+		Content:   "Someone reacted to your comment",
+		Metadata:  map[string]interface{}{"commentId": commentID},
+		CreatedAt: at,
+	}
+}
+
+// TestAggregatorGroupsReactions checks that reactions to the same comment are
+// collapsed into one notification counting the actors
+func TestAggregatorGroupsReactions(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	recipient := uuid.New()
+	commentID := uuid.New().String()
+	start := time.Now()
+
+	aggregator := notification.NewAggregator(repo, time.Hour)
+	aggregator.SetClock(func() time.Time { return start.Add(time.Minute) })
+
+	actors := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	for i, actor := range actors {
+		require.NoError(t, aggregator.Save(ctx, reaction(recipient, commentID, start.Add(time.Duration(i)*time.Second)), actor))
+	}
+	// Reacting again does not count the same user twice
+	require.NoError(t, aggregator.Save(ctx, reaction(recipient, commentID, start.Add(5*time.Second)), actors[3]))
+	// Reactions to another comment are a separate notification
+	require.NoError(t, aggregator.Save(ctx, reaction(recipient, uuid.New().String(), start.Add(6*time.Second)), actors[0]))
+
+	notifications, err := repo.GetNotificationsByUserID(ctx, recipient, notification.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+
+	grouped := notifications[1]
+	assert.Equal(t, "4 people reacted to your comment", grouped.Content)
+	assert.Equal(t, 4, grouped.Metadata["actorCount"])
+	assert.Equal(t, []string{actors[3].String(), actors[2].String(), actors[1].String()}, grouped.Metadata["actors"])
+	assert.True(t, grouped.CreatedAt.Equal(start), "the group keeps its place in the list")
+
+	unread, err := repo.GetUnreadCount(ctx, recipient)
+	require.NoError(t, err)
+	assert.Equal(t, 2, unread)
+}
+
+// TestAggregatorStartsNewGroups checks that read groups and groups older than
+// the window are not added to
+func TestAggregatorStartsNewGroups(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	recipient := uuid.New()
+	commentID := uuid.New().String()
+	start := time.Now()
+	now := start
+
+	aggregator := notification.NewAggregator(repo, time.Hour)
+	aggregator.SetClock(func() time.Time { return now })
+
+	first := reaction(recipient, commentID, start)
+	require.NoError(t, aggregator.Save(ctx, first, uuid.New()))
+	require.NoError(t, repo.MarkAsRead(ctx, recipient, first.ID, ""))
+
+	// The first group was read
+	now = start.Add(time.Minute)
+	require.NoError(t, aggregator.Save(ctx, reaction(recipient, commentID, now), uuid.New()))
+
+	// The second group is past the window
+	now = start.Add(2 * time.Hour)
+	require.NoError(t, aggregator.Save(ctx, reaction(recipient, commentID, now), uuid.New()))
+
+	notifications, err := repo.GetNotificationsByUserID(ctx, recipient, notification.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 3)
+	for _, n := range notifications {
+		assert.Equal(t, 1, n.Metadata["actorCount"])
+	}
+}
+
+// TestAggregatorDisabled checks that a window of 0 stores every notification
+func TestAggregatorDisabled(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	recipient := uuid.New()
+	commentID := uuid.New().String()
+
+	aggregator := notification.NewAggregator(repo, 0)
+	require.NoError(t, aggregator.Save(ctx, reaction(recipient, commentID, time.Now()), uuid.New()))
+	require.NoError(t, aggregator.Save(ctx, reaction(recipient, commentID, time.Now()), uuid.New()))
+
+	notifications, err := repo.GetNotificationsByUserID(ctx, recipient, notification.ListOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, notifications, 2)
+}
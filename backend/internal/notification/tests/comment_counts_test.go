@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentCountDelta(t *testing.T) {
+	videoID := uuid.New()
+	for eventType, expected := range map[notification.EventType]int{
+		notification.CommentCreated:  1,
+		notification.CommentReplied:  1,
+		notification.CommentDeleted:  -1,
+		notification.CommentReaction: 0,
+	} {
+		event := &notification.CommentEvent{BaseEvent: notification.BaseEvent{Type: eventType}, VideoID: videoID}
+		assert.Equal(t, expected, notification.CommentCountDelta(event), eventType)
+	}
+
+	orphan := &notification.CommentEvent{BaseEvent: notification.BaseEvent{Type: notification.CommentCreated}}
+	assert.Zero(t, notification.CommentCountDelta(orphan), "events without a video change no count")
+}
@@ -0,0 +1,306 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDigestStore keeps subscriptions and notifications in maps
+type memoryDigestStore struct {
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]*notification.DigestSubscription
+	notifications []*notification.Notification
+	digested      map[uuid.UUID]time.Time
+}
+
+func newMemoryDigestStore() *memoryDigestStore {
+	return &memoryDigestStore{
+		subscriptions: make(map[uuid.UUID]*notification.DigestSubscription),
+		digested:      make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (s *memoryDigestStore) GetDigestSubscription(ctx context.Context, userID uuid.UUID) (*notification.DigestSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if subscription, ok := s.subscriptions[userID]; ok {
+		copied := *subscription
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (s *memoryDigestStore) SaveDigestSubscription(ctx context.Context, subscription *notification.DigestSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *subscription
+	s.subscriptions[subscription.UserID] = &copied
+	return nil
+}
+
+func (s *memoryDigestStore) ListDigestSubscriptions(ctx context.Context, frequency notification.DigestFrequency) ([]*notification.DigestSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriptions := []*notification.DigestSubscription{}
+	for _, subscription := range s.subscriptions {
+		if subscription.Frequency == frequency {
+			copied := *subscription
+			subscriptions = append(subscriptions, &copied)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (s *memoryDigestStore) ListUndigested(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*notification.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notifications := []*notification.Notification{}
+	for _, n := range s.notifications {
+		if _, digested := s.digested[n.ID]; n.UserID == userID && n.CreatedAt.After(since) && n.ReadAt == nil && !digested {
+			notifications = append(notifications, n)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].CreatedAt.Before(notifications[j].CreatedAt) })
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func (s *memoryDigestStore) MarkDigested(ctx context.Context, notifications []*notification.Notification, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, n := range notifications {
+		s.digested[n.ID] = at
+	}
+	return nil
+}
+
+func (s *memoryDigestStore) add(userID uuid.UUID, eventType notification.EventType, content string, createdAt time.Time) *notification.Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := &notification.Notification{ID: uuid.New(), UserID: userID, Type: eventType, Content: content, CreatedAt: createdAt}
+	s.notifications = append(s.notifications, n)
+	return n
+}
+
+// staticRecipients emails the users it knows at <id>@example.com
+type staticRecipients map[uuid.UUID]string
+
+func (r staticRecipients) DigestRecipient(ctx context.Context, userID uuid.UUID) (string, string, bool, error) {
+	name, ok := r[userID]
+	return name, userID.String() + "@example.com", ok, nil
+}
+
+// sentEmail is a message recordingMailer was asked to send
+type sentEmail struct {
+	to, subject, body string
+}
+
+// recordingMailer records messages instead of sending them
+type recordingMailer struct {
+	mu   sync.Mutex
+	sent []sentEmail
+}
+
+func (m *recordingMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, sentEmail{to: to, subject: subject, body: body})
+	return nil
+}
+
+// memoryClaimer claims keys in a map
+type memoryClaimer struct {
+	mu     sync.Mutex
+	claims map[string]bool
+}
+
+func (c *memoryClaimer) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.claims[key] {
+		return false, nil
+	}
+	c.claims[key] = true
+	return true, nil
+}
+
+// testClock is a settable clock
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestDigestService(store *memoryDigestStore, recipients staticRecipients, mailer *recordingMailer, clock *testClock) *notification.DigestService {
+	digest := notification.NewDigestService(store, recipients, mailer,
+		notification.DigestConfig{MaxItems: 3, URL: "https://pavilion.example.com/notifications"}, testhelper.NewTestLogger(true))
+	digest.SetClock(clock.Now)
+	return digest
+}
+
+func TestDigestService_Preferences(t *testing.T) {
+	clock := &testClock{now: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)}
+	digest := newTestDigestService(newMemoryDigestStore(), staticRecipients{}, &recordingMailer{}, clock)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	preferences, err := digest.Preferences(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, &notification.DigestPreferences{Frequency: notification.DigestOff}, preferences, "digests are off by default")
+
+	var apiErr *apierror.Error
+	require.ErrorAs(t, digest.UpdatePreferences(ctx, userID, &notification.DigestPreferences{Frequency: "hourly"}), &apiErr)
+	assert.Equal(t, apierror.CodeValidation, apiErr.Code)
+
+	daily := &notification.DigestPreferences{Frequency: notification.DigestDaily}
+	require.NoError(t, digest.UpdatePreferences(ctx, userID, daily))
+	require.NotNil(t, daily.NextDigestAt)
+	assert.Equal(t, clock.now.Add(24*time.Hour), *daily.NextDigestAt)
+
+	clock.Advance(time.Hour)
+	again := &notification.DigestPreferences{Frequency: notification.DigestDaily}
+	require.NoError(t, digest.UpdatePreferences(ctx, userID, again))
+	assert.Equal(t, daily.NextDigestAt, again.NextDigestAt, "choosing the same frequency keeps the period")
+
+	weekly := &notification.DigestPreferences{Frequency: notification.DigestWeekly}
+	require.NoError(t, digest.UpdatePreferences(ctx, userID, weekly))
+	assert.Equal(t, clock.now.Add(7*24*time.Hour), *weekly.NextDigestAt, "a new frequency starts its period now")
+
+	off := &notification.DigestPreferences{Frequency: notification.DigestOff}
+	require.NoError(t, digest.UpdatePreferences(ctx, userID, off))
+	assert.Nil(t, off.NextDigestAt)
+}
+
+func TestDigestService_SendDue(t *testing.T) {
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	clock := &testClock{now: start}
+	store := newMemoryDigestStore()
+	mailer := &recordingMailer{}
+	userID := uuid.New()
+	digest := newTestDigestService(store, staticRecipients{userID: "Ada"}, mailer, clock)
+	ctx := context.Background()
+
+	require.NoError(t, digest.UpdatePreferences(ctx, userID, &notification.DigestPreferences{Frequency: notification.DigestDaily}))
+	for i := 1; i <= 4; i++ {
+		store.add(userID, notification.CommentCreated, fmt.Sprintf("comment %d", i), start.Add(time.Duration(i)*time.Hour))
+	}
+	store.add(userID, notification.UserFollowed, "bob followed you", start.Add(5*time.Hour))
+	read := store.add(userID, notification.UserFollowed, "carol followed you", start.Add(6*time.Hour))
+	readAt := start.Add(7 * time.Hour)
+	read.ReadAt = &readAt
+
+	clock.Advance(23 * time.Hour)
+	digest.SendDue(ctx)
+	assert.Empty(t, mailer.sent, "nothing is sent before the period ends")
+
+	clock.Advance(2 * time.Hour)
+	digest.SendDue(ctx)
+	require.Len(t, mailer.sent, 1)
+	email := mailer.sent[0]
+	assert.Equal(t, userID.String()+"@example.com", email.to)
+	assert.Equal(t, "Your daily Pavilion digest: 5 unread notifications", email.subject)
+	assert.Contains(t, email.body, "Hi Ada,")
+	assert.Contains(t, email.body, "4 × comments")
+	assert.Contains(t, email.body, "1 × new followers")
+	assert.Contains(t, email.body, "- bob followed you")
+	assert.Contains(t, email.body, "- comment 4")
+	assert.Contains(t, email.body, "- comment 3")
+	assert.NotContains(t, email.body, "- comment 2", "only the newest max items are listed")
+	assert.Contains(t, email.body, "...and 2 more.")
+	assert.NotContains(t, email.body, "carol", "read notifications are left out")
+	assert.Contains(t, email.body, "https://pavilion.example.com/notifications")
+
+	preferences, err := digest.Preferences(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(48*time.Hour), *preferences.NextDigestAt, "the next period starts where the last one ended")
+
+	store.add(userID, notification.CommentReplied, "dan replied", clock.Now())
+	clock.Advance(24 * time.Hour)
+	digest.SendDue(ctx)
+	require.Len(t, mailer.sent, 2)
+	assert.Equal(t, "Your daily Pavilion digest: 1 unread notification", mailer.sent[1].subject,
+		"notifications an earlier digest included are not sent again")
+
+	clock.Advance(24 * time.Hour)
+	digest.SendDue(ctx)
+	assert.Len(t, mailer.sent, 2, "periods without new notifications send nothing")
+}
+
+func TestDigestService_SkipsUnreachableUsers(t *testing.T) {
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	clock := &testClock{now: start}
+	store := newMemoryDigestStore()
+	mailer := &recordingMailer{}
+	digest := newTestDigestService(store, staticRecipients{}, mailer, clock)
+	userID := uuid.New()
+	ctx := context.Background()
+
+	require.NoError(t, digest.UpdatePreferences(ctx, userID, &notification.DigestPreferences{Frequency: notification.DigestWeekly}))
+	store.add(userID, notification.UserFollowed, "bob followed you", start.Add(time.Hour))
+	clock.Advance(8 * 24 * time.Hour)
+	digest.SendDue(ctx)
+
+	assert.Empty(t, mailer.sent)
+	assert.Empty(t, store.digested, "notifications are only marked once emailed")
+	preferences, err := digest.Preferences(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(14*24*time.Hour), *preferences.NextDigestAt)
+}
+
+func TestDigestService_ClaimsEachDigest(t *testing.T) {
+	start := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	clock := &testClock{now: start}
+	store := newMemoryDigestStore()
+	mailer := &recordingMailer{}
+	userID := uuid.New()
+	claimer := &memoryClaimer{claims: make(map[string]bool)}
+	ctx := context.Background()
+
+	// Two instances share the store and claims
+	first := newTestDigestService(store, staticRecipients{userID: "Ada"}, mailer, clock)
+	second := newTestDigestService(store, staticRecipients{userID: "Ada"}, mailer, clock)
+	first.SetClaimer(claimer)
+	second.SetClaimer(claimer)
+
+	require.NoError(t, first.UpdatePreferences(ctx, userID, &notification.DigestPreferences{Frequency: notification.DigestDaily}))
+	store.add(userID, notification.UserFollowed, "bob followed you", start.Add(time.Hour))
+	clock.Advance(25 * time.Hour)
+
+	// The second instance reads the subscription and notifications as they
+	// were before the first one sent the digest
+	subscriptions, err := store.ListDigestSubscriptions(ctx, notification.DigestDaily)
+	require.NoError(t, err)
+	first.SendDue(ctx)
+	require.Len(t, mailer.sent, 1)
+	for _, subscription := range subscriptions {
+		require.NoError(t, store.SaveDigestSubscription(ctx, subscription))
+	}
+	store.digested = make(map[uuid.UUID]time.Time)
+	second.SendDue(ctx)
+
+	assert.Len(t, mailer.sent, 1, "a digest is sent by the instance that claimed it")
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFollowers map[uuid.UUID][]uuid.UUID
+
+func (f fakeFollowers) GetFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return f[userID], nil
+}
+
+type fakeBlocks map[[2]uuid.UUID]bool
+
+func (f fakeBlocks) IsBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) (bool, error) {
+	return f[[2]uuid.UUID{blockerID, blockedID}], nil
+}
+
+// flakyRepository fails to save once it has saved failAfter notifications
+type flakyRepository struct {
+	*MockRepository
+	failAfter int
+	saved     int
+}
+
+func (r *flakyRepository) SaveNotification(ctx context.Context, n *notification.Notification) error {
+	if r.failAfter >= 0 && r.saved >= r.failAfter {
+		return errors.New("scylla unavailable")
+	}
+	r.saved++
+	return r.MockRepository.SaveNotification(ctx, n)
+}
+
+func TestNotifyFollowers(t *testing.T) {
+	ctx := context.Background()
+	config := notification.DefaultConfig()
+	config.Enabled = false
+
+	creator, blocker := uuid.New(), uuid.New()
+	followers := []uuid.UUID{uuid.New(), blocker, uuid.New(), uuid.New()}
+	repo := &flakyRepository{MockRepository: NewMockRepository(), failAfter: 1}
+	service, err := notification.NewService(ctx, config, testhelper.NewTestLogger(true), repo)
+	require.NoError(t, err)
+	service.SetFollowerLister(fakeFollowers{creator: followers})
+	service.SetBlockChecker(fakeBlocks{{blocker, creator}: true})
+
+	event := &notification.VideoEvent{
+		BaseEvent: notification.BaseEvent{ID: uuid.New(), Type: notification.VideoUploaded, CreatedAt: time.Now()},
+		VideoID:   uuid.New(),
+		UserID:    creator,
+		Title:     "Holiday",
+	}
+
+	// The first attempt fails part way and is redelivered
+	stored, err := service.NotifyFollowers(ctx, event)
+	assert.Error(t, err)
+	assert.Equal(t, 1, stored)
+
+	repo.failAfter = -1
+	stored, err = service.NotifyFollowers(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored, "followers notified by the first attempt are skipped")
+
+	stored, err = service.NotifyFollowers(ctx, event)
+	require.NoError(t, err)
+	assert.Zero(t, stored)
+
+	for _, followerID := range followers {
+		list, err := repo.GetNotificationsByUserID(ctx, followerID, notification.ListOptions{Limit: 10})
+		require.NoError(t, err)
+		if followerID == blocker {
+			assert.Empty(t, list, "followers who blocked the creator are not notified")
+			continue
+		}
+		require.Len(t, list, 1)
+		assert.Equal(t, notification.FollowedUserUploaded, list[0].Type)
+		assert.Equal(t, "A creator you follow uploaded 'Holiday'", list[0].Content)
+		assert.Equal(t, event.VideoID.String(), list[0].Metadata["videoId"])
+	}
+
+	// Other video events are not fanned out
+	event = &notification.VideoEvent{
+		BaseEvent: notification.BaseEvent{ID: uuid.New(), Type: notification.VideoUpdated, CreatedAt: time.Now()},
+		VideoID:   uuid.New(),
+		UserID:    creator,
+	}
+	stored, err = service.NotifyFollowers(ctx, event)
+	require.NoError(t, err)
+	assert.Zero(t, stored)
+}
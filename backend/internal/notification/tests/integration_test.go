@@ -27,7 +27,7 @@ func TestProducerConsumerIntegration(t *testing.T) {
 	config := notification.DefaultConfig()
 
 	// Create the notification service (producer)
-	service, err := notification.NewService(ctx, config, logger)
+	service, err := notification.NewService(ctx, config, logger, nil)
 	require.NoError(t, err)
 	defer service.Close()
 
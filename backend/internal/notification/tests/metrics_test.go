@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetricsSnapshot checks that processed and failed events are counted per topic
+func TestMetricsSnapshot(t *testing.T) {
+	metrics := notification.NewMetrics(prometheus.NewRegistry())
+
+	metrics.RecordProcessed("video-events", 10*time.Millisecond)
+	metrics.RecordProcessed("video-events", 20*time.Millisecond)
+	metrics.RecordFailure("video-events", notification.StagePersist)
+	metrics.RecordFailure("comment-events", notification.StagePublish)
+
+	stats := metrics.Snapshot()
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, "comment-events", stats[0].Topic)
+	assert.Equal(t, int64(0), stats[0].Processed)
+	assert.Equal(t, int64(1), stats[0].Failed[notification.StagePublish])
+	assert.NotNil(t, stats[0].LastFailureAt)
+
+	assert.Equal(t, "video-events", stats[1].Topic)
+	assert.Equal(t, int64(2), stats[1].Processed)
+	assert.Equal(t, int64(1), stats[1].Failed[notification.StagePersist])
+	assert.NotNil(t, stats[1].LastProcessedAt)
+}
+
+// TestNilMetrics checks that a service without metrics records nothing and does not panic
+func TestNilMetrics(t *testing.T) {
+	var metrics *notification.Metrics
+
+	metrics.RecordProcessed("video-events", time.Millisecond)
+	metrics.RecordFailure("video-events", notification.StagePublish)
+	metrics.RecordLag("video-events", nil)
+	assert.Empty(t, metrics.Snapshot())
+}
+
+// TestLagMonitorDetectsStalledSubscription polls a fake Pulsar admin API whose
+// backlog grows and checks that the subscription is reported as stalled
+func TestLagMonitorDetectsStalledSubscription(t *testing.T) {
+	var backlog atomic.Int64
+	backlog.Store(5)
+
+	var requestedPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `{"subscriptions":{"notification-workers":{"msgBacklog":%d,"msgRateOut":0,"consumers":[{}]}}}`, backlog.Load())
+	}))
+	defer server.Close()
+
+	config := notification.DefaultConfig()
+	config.PulsarWebServiceURL = server.URL
+	config.AuthToken = "test-token"
+	config.CommentEventsTopic = ""
+	config.UserEventsTopic = ""
+	config.DeadLetterTopic = ""
+	config.RetryQueueTopic = ""
+
+	metrics := notification.NewMetrics(prometheus.NewRegistry())
+	monitor := notification.NewLagMonitor(config, metrics, testhelper.NewTestLogger(true))
+
+	monitor.Poll(context.Background())
+	assert.Equal(t, "/admin/v2/persistent/pavilion/notifications/video-events/stats", requestedPath)
+
+	stats := metrics.Snapshot()
+	require.Len(t, stats, 1)
+	require.Len(t, stats[0].Subscriptions, 1)
+	sub := stats[0].Subscriptions[0]
+	assert.Equal(t, "notification-workers", sub.Name)
+	assert.Equal(t, int64(5), sub.Backlog)
+	assert.Equal(t, 1, sub.Consumers)
+	assert.False(t, sub.Stalled, "a single poll cannot tell whether the backlog is moving")
+
+	backlog.Store(8)
+	monitor.Poll(context.Background())
+	assert.True(t, metrics.Snapshot()[0].Subscriptions[0].Stalled)
+
+	backlog.Store(2)
+	monitor.Poll(context.Background())
+	assert.False(t, metrics.Snapshot()[0].Subscriptions[0].Stalled)
+}
@@ -2,7 +2,9 @@ package tests
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
 	"github.com/google/uuid"
@@ -11,7 +13,9 @@ import (
 // MockRepository is a simple in-memory repository for testing
 type MockRepository struct {
 	notifications map[uuid.UUID]*notification.Notification
-	unreadCount   map[uuid.UUID]int
+	devices       map[uuid.UUID]string
+	watermarks    map[uuid.UUID]notification.ReadWatermark
+	forgotten     map[uuid.UUID]bool
 	mutex         sync.RWMutex
 }
 
@@ -19,10 +23,19 @@ type MockRepository struct {
 func NewMockRepository() *MockRepository {
 	return &MockRepository{
 		notifications: make(map[uuid.UUID]*notification.Notification),
-		unreadCount:   make(map[uuid.UUID]int),
+		devices:       make(map[uuid.UUID]string),
+		watermarks:    make(map[uuid.UUID]notification.ReadWatermark),
+		forgotten:     make(map[uuid.UUID]bool),
 	}
 }
 
+// withWatermark returns a copy of n marked read when the user's watermark covers it
+func (r *MockRepository) withWatermark(n *notification.Notification) *notification.Notification {
+	copied := *n
+	r.watermarks[n.UserID].Apply(&copied)
+	return &copied
+}
+
 // SaveNotification saves a notification
 func (r *MockRepository) SaveNotification(ctx context.Context, notification *notification.Notification) error {
 	r.mutex.Lock()
@@ -30,55 +43,161 @@ func (r *MockRepository) SaveNotification(ctx context.Context, notification *not
 
 	r.notifications[notification.ID] = notification
 
-	// Update unread count
-	if notification.ReadAt == nil {
-		r.unreadCount[notification.UserID]++
+	return nil
+}
+
+// GetNotification gets a notification by ID
+func (r *MockRepository) GetNotification(ctx context.Context, notificationID uuid.UUID) (*notification.Notification, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	n, ok := r.notifications[notificationID]
+	if !ok {
+		return nil, notification.ErrNotificationNotFound
+	}
+	return r.withWatermark(n), nil
+}
+
+// UpdateNotification replaces the content and metadata of a notification
+func (r *MockRepository) UpdateNotification(ctx context.Context, notification *notification.Notification) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if stored, ok := r.notifications[notification.ID]; ok {
+		stored.Content = notification.Content
+		stored.Metadata = notification.Metadata
 	}
 
 	return nil
 }
 
-// GetNotificationsByUserID gets notifications for a user
-func (r *MockRepository) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*notification.Notification, error) {
+// GetNotificationsByUserID gets notifications for a user, newest first
+func (r *MockRepository) GetNotificationsByUserID(ctx context.Context, userID uuid.UUID, opts notification.ListOptions) ([]*notification.Notification, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	var result []*notification.Notification
+	var all []*notification.Notification
 	for _, n := range r.notifications {
 		if n.UserID == userID {
-			result = append(result, n)
+			all = append(all, r.withWatermark(n))
+		}
+	}
+
+	// Mirror the clustering order of the notifications table
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].CreatedAt.Equal(all[j].CreatedAt) {
+			return all[i].ID.String() < all[j].ID.String()
+		}
+		return all[i].CreatedAt.After(all[j].CreatedAt)
+	})
+
+	filter := notification.NewCursorFilter(opts.Cursor)
+	result := []*notification.Notification{}
+	for _, n := range all {
+		if len(result) >= opts.Limit {
+			break
+		}
+		if filter.Skip(n.CreatedAt, n.ID) {
+			continue
+		}
+		if opts.UnreadOnly && n.IsRead() {
+			continue
+		}
+		result = append(result, n)
+	}
+
+	return result, nil
+}
+
+// GetNotificationsSince gets notifications created after since, oldest first
+func (r *MockRepository) GetNotificationsSince(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]*notification.Notification, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := []*notification.Notification{}
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.CreatedAt.After(since) {
+			result = append(result, r.withWatermark(n))
 		}
 	}
 
-	// Apply offset and limit
-	if offset >= len(result) {
-		return []*notification.Notification{}, nil
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
 	}
+	return result, nil
+}
+
+// GetNotificationsByVideoID gets the notifications whose metadata refers to a
+// video, unless the video was forgotten since they were saved
+func (r *MockRepository) GetNotificationsByVideoID(ctx context.Context, videoID uuid.UUID) ([]*notification.Notification, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
 
-	end := offset + limit
-	if end > len(result) {
-		end = len(result)
+	result := []*notification.Notification{}
+	for _, n := range r.notifications {
+		if id, ok := n.VideoID(); ok && id == videoID && !r.forgotten[videoID] {
+			copied := *n
+			result = append(result, &copied)
+		}
 	}
+	return result, nil
+}
 
-	return result[offset:end], nil
+// ForgetVideo stops GetNotificationsByVideoID finding the video's notifications
+func (r *MockRepository) ForgetVideo(ctx context.Context, videoID uuid.UUID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.forgotten[videoID] = true
+	return nil
 }
 
 // GetUnreadCount gets the count of unread notifications
 func (r *MockRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	state, err := r.GetReadState(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return state.UnreadCount, nil
+}
+
+// GetReadState gets the user's watermark and the notifications read after it
+func (r *MockRepository) GetReadState(ctx context.Context, userID uuid.UUID) (*notification.ReadState, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	return r.unreadCount[userID], nil
+	watermark := r.watermarks[userID]
+	state := &notification.ReadState{Device: watermark.Device, Read: []notification.ReadReceipt{}}
+	if !watermark.At.IsZero() {
+		state.LastReadAt = &watermark.At
+		state.UpdatedAt = &watermark.UpdatedAt
+	}
+	for _, n := range r.notifications {
+		if n.UserID != userID || !n.CreatedAt.After(watermark.At) {
+			continue
+		}
+		if n.ReadAt == nil {
+			state.UnreadCount++
+		} else {
+			state.Read = append(state.Read, notification.ReadReceipt{NotificationID: n.ID, ReadAt: *n.ReadAt, Device: r.devices[n.ID]})
+		}
+	}
+
+	return state, nil
 }
 
-// MarkAsRead marks a notification as read
-func (r *MockRepository) MarkAsRead(ctx context.Context, notificationID uuid.UUID) error {
+// MarkAsRead marks one of the user's notifications as read
+func (r *MockRepository) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID, device string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	n, exists := r.notifications[notificationID]
-	if !exists {
-		return nil
+	if !exists || n.UserID != userID {
+		return notification.ErrNotificationNotFound
 	}
 
 	// Check if it's already read
@@ -89,30 +208,19 @@ func (r *MockRepository) MarkAsRead(ctx context.Context, notificationID uuid.UUI
 	// Mark as read
 	now := time.Now()
 	n.ReadAt = &now
-
-	// Update unread count
-	r.unreadCount[n.UserID]--
-	if r.unreadCount[n.UserID] < 0 {
-		r.unreadCount[n.UserID] = 0
-	}
+	r.devices[n.ID] = device
 
 	return nil
 }
 
-// MarkAllAsRead marks all notifications for a user as read
-func (r *MockRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
+// MarkAllAsRead moves the user's watermark forward to at
+func (r *MockRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID, at time.Time, device string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	now := ctx.Value("now").(notification.TimeFunc)()
-
-	for _, n := range r.notifications {
-		if n.UserID == userID && n.ReadAt == nil {
-			n.ReadAt = &now
-		}
+	if at.After(r.watermarks[userID].At) {
+		r.watermarks[userID] = notification.ReadWatermark{At: at, Device: device, UpdatedAt: time.Now()}
 	}
 
-	r.unreadCount[userID] = 0
-
 	return nil
-}
\ No newline at end of file
+}
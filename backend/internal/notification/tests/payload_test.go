@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotification_SetPayload(t *testing.T) {
+	commentID, parentID, videoID, actorID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	n := &notification.Notification{
+		ID:   uuid.New(),
+		Type: notification.CommentReplied,
+		Metadata: map[string]interface{}{
+			"commentId":      commentID.String(),
+			"parentId":       parentID.String(),
+			"videoId":        videoID.String(),
+			"actorId":        actorID.String(),
+			"userId":         uuid.New().String(),
+			"eventType":      "COMMENT_REPLIED",
+			"contentPreview": "Great point",
+			"groupKey":       "COMMENT_REPLIED:" + parentID.String(),
+			"actorCount":     2,
+			"actors":         []string{actorID.String(), uuid.New().String()},
+		},
+	}
+	require.NoError(t, n.SetPayload())
+	assert.Equal(t, notification.PayloadSchemaVersion, n.SchemaVersion)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(n.Payload, &fields))
+	assert.NotContains(t, fields, "eventType", "fields the type does not have are dropped")
+	assert.NotContains(t, fields, "userId")
+
+	decoded, err := n.DecodePayload()
+	require.NoError(t, err)
+	reply, ok := decoded.(*notification.CommentReplyPayload)
+	require.True(t, ok, "COMMENT_REPLIED decodes to a CommentReplyPayload, got %T", decoded)
+	assert.Equal(t, commentID, reply.CommentID)
+	assert.Equal(t, parentID, reply.ParentID)
+	assert.Equal(t, videoID, reply.VideoID)
+	assert.Equal(t, actorID, reply.ActorID)
+	assert.Equal(t, "Great point", reply.ContentPreview)
+	assert.Equal(t, 2, reply.ActorCount)
+	assert.Len(t, reply.Actors, 2)
+	assert.Equal(t, actorID, reply.Actors[0])
+}
+
+func TestNotification_SetPayloadFromStoredMetadata(t *testing.T) {
+	// Metadata read back from JSON holds float64 numbers and []interface{}
+	var metadata map[string]interface{}
+	videoID := uuid.New()
+	require.NoError(t, json.Unmarshal([]byte(`{"videoId":"`+videoID.String()+`","userId":"`+uuid.New().String()+
+		`","title":"Launch","fileSize":1048576,"ipfsCid":"bafy"}`), &metadata))
+
+	n := &notification.Notification{ID: uuid.New(), Type: notification.VideoUploaded, Metadata: metadata}
+	require.NoError(t, n.SetPayload())
+
+	var video notification.VideoPayload
+	require.NoError(t, n.DecodePayloadInto(&video))
+	assert.Equal(t, videoID, video.VideoID)
+	assert.Equal(t, "Launch", video.Title)
+	assert.Equal(t, int64(1048576), video.FileSize)
+	assert.Equal(t, "bafy", video.IPFSCID)
+}
+
+func TestNotification_SetPayloadRejectsMismatchedMetadata(t *testing.T) {
+	n := &notification.Notification{
+		ID:       uuid.New(),
+		Type:     notification.LiveStarted,
+		Metadata: map[string]interface{}{"liveStreamId": 42},
+	}
+	assert.Error(t, n.SetPayload())
+}
+
+func TestNotification_UpgradePayload(t *testing.T) {
+	followerID := uuid.New()
+	legacy := &notification.Notification{
+		ID:       uuid.New(),
+		Type:     notification.UserFollowed,
+		Metadata: map[string]interface{}{"userId": followerID.String(), "targetUserId": uuid.New().String()},
+	}
+	require.NoError(t, legacy.UpgradePayload(), "notifications stored before payloads get one from their metadata")
+	var follow notification.FollowPayload
+	require.NoError(t, legacy.DecodePayloadInto(&follow))
+	assert.Equal(t, followerID, follow.UserID)
+
+	newer := &notification.Notification{ID: uuid.New(), Type: notification.UserFollowed, SchemaVersion: notification.PayloadSchemaVersion + 1}
+	assert.Error(t, newer.UpgradePayload())
+	assert.Error(t, newer.DecodePayloadInto(&follow), "payloads of other versions are not decoded")
+
+	unknown := &notification.Notification{ID: uuid.New(), Type: "SOMETHING_ELSE"}
+	require.NoError(t, unknown.SetPayload())
+	assert.Nil(t, unknown.Payload)
+	_, err := unknown.DecodePayload()
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification/webpush"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Keys of the example subscription in RFC 8291
+const (
+	testP256dh = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	testAuth   = "BTBZMqHH6r4Tts7J_aSIgg"
+)
+
+// memoryPushStore keeps subscriptions and preferences in maps
+type memoryPushStore struct {
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]map[string]*notification.PushSubscription
+	preferences   map[uuid.UUID]*notification.PushPreferences
+}
+
+func newMemoryPushStore() *memoryPushStore {
+	return &memoryPushStore{
+		subscriptions: make(map[uuid.UUID]map[string]*notification.PushSubscription),
+		preferences:   make(map[uuid.UUID]*notification.PushPreferences),
+	}
+}
+
+func (s *memoryPushStore) SavePushSubscription(ctx context.Context, subscription *notification.PushSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscriptions[subscription.UserID] == nil {
+		s.subscriptions[subscription.UserID] = make(map[string]*notification.PushSubscription)
+	}
+	s.subscriptions[subscription.UserID][subscription.Endpoint] = subscription
+	return nil
+}
+
+func (s *memoryPushStore) DeletePushSubscription(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions[userID], endpoint)
+	return nil
+}
+
+func (s *memoryPushStore) ListPushSubscriptions(ctx context.Context, userID uuid.UUID) ([]*notification.PushSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscriptions := []*notification.PushSubscription{}
+	for _, subscription := range s.subscriptions[userID] {
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, nil
+}
+
+func (s *memoryPushStore) GetPushPreferences(ctx context.Context, userID uuid.UUID) (*notification.PushPreferences, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.preferences[userID], nil
+}
+
+func (s *memoryPushStore) SavePushPreferences(ctx context.Context, userID uuid.UUID, preferences *notification.PushPreferences) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preferences[userID] = preferences
+	return nil
+}
+
+// recordingSender records the endpoints messages were sent to, and fails
+// for endpoints listed in errs
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []string
+	errs map[string]error
+}
+
+func (s *recordingSender) Send(ctx context.Context, subscription webpush.Subscription, payload []byte, opts webpush.Options) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, subscription.Endpoint)
+	return s.errs[subscription.Endpoint]
+}
+
+func newTestPushService(store *memoryPushStore, sender *recordingSender) *notification.PushService {
+	return notification.NewPushService(store, sender, "test-public-key", time.Hour, time.Second, testhelper.NewTestLogger(true))
+}
+
+func subscribe(t *testing.T, push *notification.PushService, userID uuid.UUID, endpoint string) {
+	t.Helper()
+	require.NoError(t, push.Subscribe(context.Background(), &notification.PushSubscription{
+		UserID:   userID,
+		Endpoint: endpoint,
+		P256dh:   testP256dh,
+		Auth:     testAuth,
+	}))
+}
+
+func TestPushService_Subscribe(t *testing.T) {
+	push := newTestPushService(newMemoryPushStore(), &recordingSender{})
+	userID := uuid.New()
+
+	invalid := []*notification.PushSubscription{
+		{UserID: userID, Endpoint: "http://push.example.net/a", P256dh: testP256dh, Auth: testAuth},
+		{UserID: userID, Endpoint: "https://push.example.net/a", P256dh: "short", Auth: testAuth},
+		{UserID: userID, Endpoint: "https://push.example.net/a", P256dh: testP256dh, Auth: "c2hvcnQ"},
+	}
+	for _, subscription := range invalid {
+		var apiErr *apierror.Error
+		require.ErrorAs(t, push.Subscribe(context.Background(), subscription), &apiErr)
+		assert.Equal(t, apierror.CodeValidation, apiErr.Code)
+	}
+
+	for i := 0; i < 20; i++ {
+		subscribe(t, push, userID, fmt.Sprintf("https://push.example.net/%d", i))
+	}
+	err := push.Subscribe(context.Background(), &notification.PushSubscription{
+		UserID: userID, Endpoint: "https://push.example.net/another", P256dh: testP256dh, Auth: testAuth,
+	})
+	assert.ErrorIs(t, err, notification.ErrTooManyPushSubscriptions)
+
+	// Registering a known endpoint again only replaces its keys
+	subscribe(t, push, userID, "https://push.example.net/0")
+	subscriptions, err := push.ListSubscriptions(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 20)
+}
+
+func TestPushService_DeliversToAllowedDevices(t *testing.T) {
+	store := newMemoryPushStore()
+	sender := &recordingSender{errs: map[string]error{
+		"https://push.example.net/gone":  webpush.ErrSubscriptionGone,
+		"https://push.example.net/flaky": errors.New("push service returned 503"),
+	}}
+	push := newTestPushService(store, sender)
+	userID := uuid.New()
+
+	subscribe(t, push, userID, "https://push.example.net/ok")
+	subscribe(t, push, userID, "https://push.example.net/gone")
+	subscribe(t, push, userID, "https://push.example.net/flaky")
+	expired := time.Now().Add(-time.Minute)
+	require.NoError(t, store.SavePushSubscription(context.Background(), &notification.PushSubscription{
+		UserID: userID, Endpoint: "https://push.example.net/expired", P256dh: testP256dh, Auth: testAuth, ExpiresAt: &expired,
+	}))
+
+	push.Notify(&notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.UserFollowed, Content: "followed you"})
+	push.Wait()
+
+	assert.ElementsMatch(t, []string{"https://push.example.net/ok", "https://push.example.net/gone", "https://push.example.net/flaky"}, sender.sent,
+		"expired subscriptions are not sent to")
+	subscriptions, err := push.ListSubscriptions(context.Background(), userID)
+	require.NoError(t, err)
+	var endpoints []string
+	for _, subscription := range subscriptions {
+		endpoints = append(endpoints, subscription.Endpoint)
+	}
+	assert.ElementsMatch(t, []string{"https://push.example.net/ok", "https://push.example.net/flaky"}, endpoints,
+		"gone and expired subscriptions are deleted; failing ones are kept")
+}
+
+func TestPushService_RespectsPreferences(t *testing.T) {
+	store := newMemoryPushStore()
+	sender := &recordingSender{}
+	push := newTestPushService(store, sender)
+	userID := uuid.New()
+	subscribe(t, push, userID, "https://push.example.net/ok")
+
+	preferences, err := push.Preferences(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, notification.DefaultPushPreferences(), preferences)
+
+	err = push.UpdatePreferences(context.Background(), userID, &notification.PushPreferences{Enabled: true, MutedTypes: []notification.EventType{"NOT_A_TYPE"}})
+	assert.Error(t, err)
+
+	require.NoError(t, push.UpdatePreferences(context.Background(), userID, &notification.PushPreferences{
+		Enabled: true, MutedTypes: []notification.EventType{notification.CommentReaction},
+	}))
+	push.Notify(&notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.CommentReaction})
+	push.Notify(&notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.CommentReplied})
+	push.Wait()
+	assert.Len(t, sender.sent, 1, "muted types are not pushed")
+
+	require.NoError(t, push.UpdatePreferences(context.Background(), userID, &notification.PushPreferences{Enabled: false}))
+	push.Notify(&notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.CommentReplied})
+	push.Wait()
+	assert.Len(t, sender.sent, 1, "nothing is pushed when push is disabled")
+}
+
+func TestPushNotifier_PushesSavedNotifications(t *testing.T) {
+	store := newMemoryPushStore()
+	sender := &recordingSender{}
+	push := newTestPushService(store, sender)
+	userID := uuid.New()
+	subscribe(t, push, userID, "https://push.example.net/ok")
+
+	repo := NewMockRepository()
+	notifier := notification.NewPushNotifier(repo, push)
+	require.NoError(t, notifier.SaveNotification(context.Background(), &notification.Notification{
+		ID: uuid.New(), UserID: userID, Type: notification.UserMentioned, CreatedAt: time.Now(),
+	}))
+	readAt := time.Now()
+	require.NoError(t, notifier.SaveNotification(context.Background(), &notification.Notification{
+		ID: uuid.New(), UserID: userID, Type: notification.UserMentioned, CreatedAt: time.Now(), ReadAt: &readAt,
+	}))
+	push.Wait()
+
+	assert.Len(t, sender.sent, 1, "only unread notifications are pushed")
+	count, err := repo.GetUnreadCount(context.Background(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "notifications are still stored")
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWatermark_Apply(t *testing.T) {
+	at := time.Now().Add(-time.Hour)
+	watermark := notification.ReadWatermark{At: at, UpdatedAt: at.Add(time.Minute)}
+
+	older := &notification.Notification{CreatedAt: at.Add(-time.Second)}
+	watermark.Apply(older)
+	require.True(t, older.IsRead(), "notifications up to the watermark are read")
+	assert.Equal(t, at.Add(time.Minute), *older.ReadAt)
+
+	newer := &notification.Notification{CreatedAt: at.Add(time.Second)}
+	watermark.Apply(newer)
+	assert.False(t, newer.IsRead())
+
+	readAt := at.Add(-time.Hour)
+	ownMark := &notification.Notification{CreatedAt: at.Add(-time.Second), ReadAt: &readAt}
+	watermark.Apply(ownMark)
+	assert.Equal(t, readAt, *ownMark.ReadAt, "a notification's own read mark is kept")
+
+	notification.ReadWatermark{}.Apply(newer)
+	assert.False(t, newer.IsRead(), "a user without a watermark has read nothing")
+}
+
+// TestService_UpdateReadState syncs read marks from two devices
+func TestService_UpdateReadState(t *testing.T) {
+	ctx := context.Background()
+	config := notification.DefaultConfig()
+	config.Enabled = false
+	repo := NewMockRepository()
+	service, err := notification.NewService(ctx, config, testhelper.NewTestLogger(true), repo)
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	base := time.Now().Add(-time.Hour)
+	var ids []uuid.UUID
+	for i := 0; i < 4; i++ {
+		n := &notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.UserFollowed, CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, repo.SaveNotification(ctx, n))
+		ids = append(ids, n.ID)
+	}
+	other := &notification.Notification{ID: uuid.New(), UserID: uuid.New(), Type: notification.UserFollowed, CreatedAt: base}
+	require.NoError(t, repo.SaveNotification(ctx, other))
+
+	state, err := service.GetReadState(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, state.LastReadAt)
+	assert.Equal(t, 4, state.UnreadCount)
+
+	// The phone read the first two and then the last one
+	watermark := base.Add(time.Minute)
+	state, err = service.UpdateReadState(ctx, userID, &notification.ReadStateUpdate{
+		LastReadAt: &watermark,
+		Read:       []uuid.UUID{ids[3], other.ID, uuid.New()},
+		Device:     "phone",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, state.LastReadAt)
+	assert.True(t, watermark.Equal(*state.LastReadAt))
+	assert.Equal(t, "phone", state.Device)
+	assert.Equal(t, 1, state.UnreadCount)
+	require.Len(t, state.Read, 1, "other users' and unknown notifications are skipped")
+	assert.Equal(t, ids[3], state.Read[0].NotificationID)
+	assert.Equal(t, "phone", state.Read[0].Device)
+
+	// The laptop syncs an older watermark, which does not move it back
+	stale := base
+	state, err = service.UpdateReadState(ctx, userID, &notification.ReadStateUpdate{LastReadAt: &stale, Device: "laptop"})
+	require.NoError(t, err)
+	assert.True(t, watermark.Equal(*state.LastReadAt))
+	assert.Equal(t, "phone", state.Device)
+
+	page, err := service.GetUserNotifications(ctx, userID, notification.ListOptions{Limit: 10, UnreadOnly: true})
+	require.NoError(t, err)
+	require.Len(t, page.Notifications, 1)
+	assert.Equal(t, ids[2], page.Notifications[0].ID)
+
+	// A watermark in the future is clamped, so later notifications stay unread
+	future := time.Now().Add(time.Hour)
+	state, err = service.UpdateReadState(ctx, userID, &notification.ReadStateUpdate{LastReadAt: &future, Device: "laptop"})
+	require.NoError(t, err)
+	assert.True(t, state.LastReadAt.Before(future))
+	assert.Equal(t, 0, state.UnreadCount)
+	assert.Empty(t, state.Read, "marks older than the watermark are folded into it")
+
+	later := &notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.UserFollowed, CreatedAt: time.Now().Add(time.Minute)}
+	require.NoError(t, repo.SaveNotification(ctx, later))
+	count, err := service.GetUnreadCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestService_UpdateReadStateTooMany(t *testing.T) {
+	config := notification.DefaultConfig()
+	config.Enabled = false
+	service, err := notification.NewService(context.Background(), config, testhelper.NewTestLogger(true), NewMockRepository())
+	require.NoError(t, err)
+
+	update := &notification.ReadStateUpdate{Read: make([]uuid.UUID, 101)}
+	_, err = service.UpdateReadState(context.Background(), uuid.New(), update)
+	var apiErr *apierror.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, apierror.CodeValidation, apiErr.Code)
+}
+
+// TestUnreadCounter_ReadState checks that the count follows watermark moves
+func TestUnreadCounter_ReadState(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	store := &memoryCounterStore{values: map[string]int64{}}
+	counter := notification.NewUnreadCounter(repo, store, time.Minute, testhelper.NewTestLogger(true))
+	userID := uuid.New()
+	key := "notifications:unread:" + userID.String()
+
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		n := &notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.UserFollowed, CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, counter.SaveNotification(ctx, n))
+	}
+	_, err := counter.GetUnreadCount(ctx, userID)
+	require.NoError(t, err)
+
+	// Only the notifications up to the watermark stop counting
+	require.NoError(t, counter.MarkAllAsRead(ctx, userID, base.Add(time.Minute), "web"))
+	assert.Equal(t, int64(1), store.values[key])
+
+	store.values[key] = 7
+	state, err := counter.GetReadState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.UnreadCount)
+	assert.Equal(t, int64(1), store.values[key], "reading the state corrects a drifted count")
+}
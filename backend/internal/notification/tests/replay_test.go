@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReplayTopic checks that dead letters are routed back to the topic they came from
+func TestReplayTopic(t *testing.T) {
+	config := notification.DefaultConfig()
+
+	tests := []struct {
+		name       string
+		properties map[string]string
+		want       string
+	}{
+		{"recorded topic", map[string]string{"REAL_TOPIC": config.CommentEventsTopic, "eventType": "VIDEO_UPLOADED"}, config.CommentEventsTopic},
+		{"recorded partition", map[string]string{"REAL_TOPIC": config.UserEventsTopic + "-partition-2"}, config.UserEventsTopic},
+		{"unknown recorded topic", map[string]string{"REAL_TOPIC": "persistent://other/ns/topic", "eventType": "VIDEO_UPLOADED"}, ""},
+		{"video event", map[string]string{"eventType": "VIDEO_DELETED"}, config.VideoEventsTopic},
+		{"follower fan-out", map[string]string{"eventType": string(notification.FollowedUserUploaded)}, config.VideoEventsTopic},
+		{"live started", map[string]string{"eventType": string(notification.LiveStarted)}, config.VideoEventsTopic},
+		{"comment event", map[string]string{"eventType": "COMMENT_REPLIED"}, config.CommentEventsTopic},
+		{"auth event", map[string]string{"eventType": string(notification.AuthEvent)}, config.UserEventsTopic},
+		{"unknown event", map[string]string{"eventType": "SOMETHING_ELSE"}, ""},
+		{"no properties", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, config.ReplayTopic(tt.properties))
+		})
+	}
+}
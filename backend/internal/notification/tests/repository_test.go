@@ -1,7 +1,6 @@
 package tests
 
 import (
-	"context"
 	"testing"
 	"time"
 
@@ -41,7 +40,7 @@ func TestNotificationModel(t *testing.T) {
 	userID := uuid.New()
 	now := time.Now().Truncate(time.Millisecond) // Truncate to avoid precision issues
 	
-	notification := &notification.Notification{
+	n := &notification.Notification{
 		ID:        id,
 		UserID:    userID,
 		Type:      notification.VideoUploaded,
@@ -54,27 +53,44 @@ func TestNotificationModel(t *testing.T) {
 	}
 	
 	// Test ID
-	assert.Equal(t, id, notification.ID)
+	assert.Equal(t, id, n.ID)
 	
 	// Test JSON conversion
-	data, err := notification.ToJSON()
+	data, err := n.ToJSON()
 	require.NoError(t, err)
 	assert.NotEmpty(t, data)
 	
 	// Test unmarshalling
 	unmarshalled, err := notification.FromJSON(data)
 	require.NoError(t, err)
-	assert.Equal(t, notification.ID, unmarshalled.ID)
-	assert.Equal(t, notification.UserID, unmarshalled.UserID)
-	assert.Equal(t, notification.Type, unmarshalled.Type)
-	assert.Equal(t, notification.Content, unmarshalled.Content)
+	assert.Equal(t, n.ID, unmarshalled.ID)
+	assert.Equal(t, n.UserID, unmarshalled.UserID)
+	assert.Equal(t, n.Type, unmarshalled.Type)
+	assert.Equal(t, n.Content, unmarshalled.Content)
 	assert.Equal(t, now.Unix(), unmarshalled.CreatedAt.Unix())
 	
 	// Test read status
-	assert.False(t, notification.IsRead())
+	assert.False(t, n.IsRead())
 	
 	// Now mark as read
 	readTime := time.Now()
-	notification.ReadAt = &readTime
-	assert.True(t, notification.IsRead())
-}
\ No newline at end of file
+	n.ReadAt = &readTime
+	assert.True(t, n.IsRead())
+}
+
+// TestCursorEncoding verifies cursors survive a round trip and reject garbage
+func TestCursorEncoding(t *testing.T) {
+	n := &notification.Notification{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+	}
+
+	cursor := notification.NewCursor(n)
+	decoded, err := notification.DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, n.ID, decoded.ID)
+	assert.True(t, n.CreatedAt.Equal(decoded.CreatedAt))
+
+	_, err = notification.DecodeCursor("not-a-cursor")
+	assert.ErrorIs(t, err, notification.ErrInvalidCursor)
+}
@@ -91,11 +91,68 @@ func TestServiceWithDefaultValues(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Basic read capability tests - these are stubs for now
-	notifs, err := service.GetUserNotifications(context.Background(), uuid.New(), 10, 0)
+	page, err := service.GetUserNotifications(context.Background(), uuid.New(), notification.ListOptions{Limit: 10})
 	assert.NoError(t, err)
-	assert.Empty(t, notifs)
+	assert.Empty(t, page.Notifications)
 
 	count, err := service.GetUnreadCount(context.Background(), uuid.New())
 	assert.NoError(t, err)
 	assert.Equal(t, 0, count)
-}
\ No newline at end of file
+}
+
+// TestGetUserNotificationsPagination walks a user's notifications page by page using the cursor
+func TestGetUserNotificationsPagination(t *testing.T) {
+	config := notification.DefaultConfig()
+	config.Enabled = false
+
+	repo := NewMockRepository()
+	service, err := notification.NewService(context.Background(), config, testhelper.NewTestLogger(true), repo)
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	base := time.Now().Truncate(time.Millisecond)
+	for i := 0; i < 5; i++ {
+		n := &notification.Notification{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Type:      notification.VideoUploaded,
+			Content:   "Test notification",
+			CreatedAt: base.Add(-time.Duration(i) * time.Minute),
+		}
+		if i%2 == 1 {
+			readAt := base
+			n.ReadAt = &readAt
+		}
+		require.NoError(t, repo.SaveNotification(context.Background(), n))
+	}
+
+	// First page
+	page, err := service.GetUserNotifications(context.Background(), userID, notification.ListOptions{Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Notifications, 2)
+	assert.True(t, page.HasMore)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.Equal(t, 3, page.TotalUnread)
+	assert.True(t, page.Notifications[0].CreatedAt.After(page.Notifications[1].CreatedAt))
+
+	// Follow the cursor to the end
+	seen := len(page.Notifications)
+	for page.HasMore {
+		cursor, err := notification.DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+		page, err = service.GetUserNotifications(context.Background(), userID, notification.ListOptions{Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		seen += len(page.Notifications)
+	}
+	assert.Equal(t, 5, seen)
+	assert.Empty(t, page.NextCursor)
+
+	// Unread only
+	page, err = service.GetUserNotifications(context.Background(), userID, notification.ListOptions{Limit: 10, UnreadOnly: true})
+	require.NoError(t, err)
+	assert.Len(t, page.Notifications, 3)
+	for _, n := range page.Notifications {
+		assert.False(t, n.IsRead())
+	}
+	assert.False(t, page.HasMore)
+}
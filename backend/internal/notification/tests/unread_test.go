@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCounterStore keeps counters in a map and ignores TTLs
+type memoryCounterStore struct {
+	values map[string]int64
+	err    error
+}
+
+func (s *memoryCounterStore) Get(ctx context.Context, key string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return "", cache.ErrNotFound
+	}
+	return strconv.FormatInt(value, 10), nil
+}
+
+func (s *memoryCounterStore) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key] = int64(value.(int))
+	return nil
+}
+
+func (s *memoryCounterStore) AdjustCounter(ctx context.Context, key string, delta int64) (int64, bool, error) {
+	if s.err != nil {
+		return 0, false, s.err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return 0, false, nil
+	}
+	value = max(value+delta, 0)
+	s.values[key] = value
+	return value, true, nil
+}
+
+// TestUnreadCounter checks that the unread count follows new and read
+// notifications without recounting
+func TestUnreadCounter(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	store := &memoryCounterStore{values: map[string]int64{}}
+	counter := notification.NewUnreadCounter(repo, store, time.Minute, testhelper.NewTestLogger(true))
+	userID := uuid.New()
+
+	// A notification saved before the count is cached is found by the recount
+	first := &notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.UserFollowed, CreatedAt: time.Now()}
+	require.NoError(t, counter.SaveNotification(ctx, first))
+	count, err := counter.GetUnreadCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	second := &notification.Notification{ID: uuid.New(), UserID: userID, Type: notification.UserFollowed, CreatedAt: time.Now()}
+	require.NoError(t, counter.SaveNotification(ctx, second))
+	assert.Equal(t, int64(2), store.values["notifications:unread:"+userID.String()])
+
+	// Marking a notification read twice uncounts it once
+	require.NoError(t, counter.MarkAsRead(ctx, userID, first.ID, "web"))
+	require.NoError(t, counter.MarkAsRead(ctx, userID, first.ID, "web"))
+	count, err = counter.GetUnreadCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, counter.MarkAllAsRead(ctx, userID, time.Now(), "web"))
+	count, err = counter.GetUnreadCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	assert.ErrorIs(t, counter.MarkAsRead(ctx, userID, uuid.New(), "web"), notification.ErrNotificationNotFound)
+}
+
+// TestUnreadCounterRedisDown checks that counts come from the repository when Redis fails
+func TestUnreadCounterRedisDown(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockRepository()
+	store := &memoryCounterStore{values: map[string]int64{}, err: errors.New("connection refused")}
+	counter := notification.NewUnreadCounter(repo, store, time.Minute, testhelper.NewTestLogger(true))
+	userID := uuid.New()
+
+	require.NoError(t, counter.SaveNotification(ctx, &notification.Notification{ID: uuid.New(), UserID: userID, CreatedAt: time.Now()}))
+	count, err := counter.GetUnreadCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactVideoNotifications(t *testing.T) {
+	ctx := context.Background()
+	config := notification.DefaultConfig()
+	config.Enabled = false
+
+	repo := NewMockRepository()
+	service, err := notification.NewService(ctx, config, testhelper.NewTestLogger(true), repo)
+	require.NoError(t, err)
+
+	videoID, otherID, owner, actor := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	comment := &notification.Notification{
+		ID:      uuid.New(),
+		UserID:  owner,
+		Type:    notification.CommentCreated,
+		Content: "New comment on your video",
+		Metadata: map[string]interface{}{
+			"eventType":      "COMMENT_CREATED",
+			"userId":         owner.String(),
+			"actorId":        actor.String(),
+			"videoId":        videoID.String(),
+			"commentId":      uuid.New().String(),
+			"contentPreview": "Nice video",
+		},
+		CreatedAt: time.Now(),
+	}
+	notice := &notification.Notification{
+		ID:        uuid.New(),
+		UserID:    owner,
+		Type:      notification.VideoDeleted,
+		Content:   "Your video 'Holiday' has been deleted",
+		Metadata:  map[string]interface{}{"videoId": videoID.String(), "title": "Holiday"},
+		CreatedAt: time.Now(),
+	}
+	other := &notification.Notification{
+		ID:        uuid.New(),
+		UserID:    owner,
+		Type:      notification.VideoUploaded,
+		Content:   "Your video 'Other' has been uploaded successfully",
+		Metadata:  map[string]interface{}{"videoId": otherID.String(), "title": "Other"},
+		CreatedAt: time.Now(),
+	}
+	for _, n := range []*notification.Notification{comment, notice, other} {
+		require.NoError(t, repo.SaveNotification(ctx, n))
+	}
+
+	redacted, err := service.RedactVideoNotifications(ctx, videoID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, redacted)
+
+	stored, err := repo.GetNotification(ctx, comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, notification.RedactedVideoContent, stored.Content)
+	assert.Equal(t, map[string]interface{}{
+		"eventType":    "COMMENT_CREATED",
+		"userId":       owner.String(),
+		"actorId":      actor.String(),
+		"videoDeleted": true,
+	}, stored.Metadata)
+
+	stored, err = repo.GetNotification(ctx, notice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Holiday", stored.Metadata["title"], "the owner's deletion notice is kept")
+
+	stored, err = repo.GetNotification(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Other", stored.Metadata["title"], "notifications about other videos are kept")
+
+	redacted, err = service.RedactVideoNotifications(ctx, videoID)
+	require.NoError(t, err)
+	assert.Zero(t, redacted, "the video is forgotten once redacted")
+}
@@ -0,0 +1,153 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+)
+
+// UnreadCounterStore is the part of the Redis cache the unread counter uses
+type UnreadCounterStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// AdjustCounter adds delta to an existing counter, stopping at zero
+	AdjustCounter(ctx context.Context, key string, delta int64) (int64, bool, error)
+}
+
+// UnreadCounter keeps each user's unread notification count in Redis, so
+// polling the badge count does not scan the user's notifications in
+// ScyllaDB. New notifications increment the count and marking them read
+// decrements it. A count is recounted from ScyllaDB when it is missing or
+// older than the TTL, which corrects any drift, e.g. from a notification
+// marked read twice at once. Redis errors fall back to ScyllaDB.
+type UnreadCounter struct {
+	NotificationRepository
+	store  UnreadCounterStore
+	ttl    time.Duration
+	logger logger.Logger
+}
+
+// NewUnreadCounter wraps repository with counts recounted every ttl
+func NewUnreadCounter(repository NotificationRepository, store UnreadCounterStore, ttl time.Duration, logger logger.Logger) *UnreadCounter {
+	return &UnreadCounter{
+		NotificationRepository: repository,
+		store:                  store,
+		ttl:                    ttl,
+		logger:                 logger,
+	}
+}
+
+// SaveNotification saves a notification and counts it when it is unread
+func (u *UnreadCounter) SaveNotification(ctx context.Context, notification *Notification) error {
+	if err := u.NotificationRepository.SaveNotification(ctx, notification); err != nil {
+		return err
+	}
+	if !notification.IsRead() {
+		u.adjust(ctx, notification.UserID, 1)
+	}
+	return nil
+}
+
+// GetUnreadCount returns the user's count from Redis, recounting it on a miss
+func (u *UnreadCounter) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	cached, err := u.store.Get(ctx, unreadKey(userID))
+	if err == nil {
+		if count, err := strconv.Atoi(cached); err == nil {
+			return count, nil
+		}
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		u.logger.LogWarn("Failed to read unread count from Redis", map[string]interface{}{
+			"userID": userID.String(),
+			"error":  err.Error(),
+		})
+	}
+
+	count, err := u.NotificationRepository.GetUnreadCount(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	u.set(ctx, userID, count)
+	return count, nil
+}
+
+// MarkAsRead marks a notification as read, uncounting it if it was unread
+func (u *UnreadCounter) MarkAsRead(ctx context.Context, userID, notificationID uuid.UUID, device string) error {
+	notification, err := u.NotificationRepository.GetNotification(ctx, notificationID)
+	if err != nil {
+		return err
+	}
+	if err := u.NotificationRepository.MarkAsRead(ctx, userID, notificationID, device); err != nil {
+		return err
+	}
+	if !notification.IsRead() {
+		u.adjust(ctx, userID, -1)
+	}
+	return nil
+}
+
+// MarkAllAsRead moves the user's read watermark and recounts, since
+// notifications newer than the watermark stay unread
+func (u *UnreadCounter) MarkAllAsRead(ctx context.Context, userID uuid.UUID, at time.Time, device string) error {
+	if err := u.NotificationRepository.MarkAllAsRead(ctx, userID, at, device); err != nil {
+		return err
+	}
+	u.recount(ctx, userID)
+	return nil
+}
+
+// GetReadState returns the user's read state, storing the unread count it
+// includes
+func (u *UnreadCounter) GetReadState(ctx context.Context, userID uuid.UUID) (*ReadState, error) {
+	state, err := u.NotificationRepository.GetReadState(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	u.set(ctx, userID, state.UnreadCount)
+	return state, nil
+}
+
+// recount stores the user's count from the repository. When that fails it
+// stores zero, which is right unless notifications arrived after the
+// watermark, and is corrected at the next recount.
+func (u *UnreadCounter) recount(ctx context.Context, userID uuid.UUID) {
+	count, err := u.NotificationRepository.GetUnreadCount(ctx, userID)
+	if err != nil {
+		u.logger.LogWarn("Failed to recount unread notifications", map[string]interface{}{
+			"userID": userID.String(),
+			"error":  err.Error(),
+		})
+		u.set(ctx, userID, 0)
+		return
+	}
+	u.set(ctx, userID, count)
+}
+
+// adjust adds delta to the user's count if it is in Redis; a missing count
+// is recounted when it is next read
+func (u *UnreadCounter) adjust(ctx context.Context, userID uuid.UUID, delta int64) {
+	if _, _, err := u.store.AdjustCounter(context.WithoutCancel(ctx), unreadKey(userID), delta); err != nil {
+		u.logger.LogWarn("Failed to update unread count in Redis", map[string]interface{}{
+			"userID": userID.String(),
+			"error":  err.Error(),
+		})
+	}
+}
+
+// set stores the user's count until it is next recounted
+func (u *UnreadCounter) set(ctx context.Context, userID uuid.UUID, count int) {
+	if err := u.store.Set(context.WithoutCancel(ctx), unreadKey(userID), count, u.ttl); err != nil {
+		u.logger.LogWarn("Failed to store unread count in Redis", map[string]interface{}{
+			"userID": userID.String(),
+			"error":  err.Error(),
+		})
+	}
+}
+
+func unreadKey(userID uuid.UUID) string {
+	return "notifications:unread:" + userID.String()
+}
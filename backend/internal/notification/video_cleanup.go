@@ -0,0 +1,215 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
+	"github.com/google/uuid"
+)
+
+// DefaultVideoCleanupSubscription is the video events subscription that
+// cleans up after deleted videos
+const DefaultVideoCleanupSubscription = "video-deletion-cleanup"
+
+// RedactedVideoContent replaces the content of notifications about a deleted video
+const RedactedVideoContent = "This notification was about a video that has been deleted"
+
+// redactedMetadataKeys are the metadata entries a redacted notification
+// keeps: who it is for and who acted, but nothing that leads to the video
+// or describes it
+var redactedMetadataKeys = []string{"eventType", "userId", "actorId", "targetUserId", "groupKey", "actorCount", "actors"}
+
+// VideoCommentDeleter removes the comments of a deleted video
+type VideoCommentDeleter interface {
+	DeleteVideoComments(ctx context.Context, videoID uuid.UUID) (int, error)
+}
+
+// VideoID returns the video the notification's metadata refers to, if any
+func (n *Notification) VideoID() (uuid.UUID, bool) {
+	value, _ := n.Metadata["videoId"].(string)
+	videoID, err := uuid.Parse(value)
+	if err != nil || videoID == uuid.Nil {
+		return uuid.Nil, false
+	}
+	return videoID, true
+}
+
+// RedactVideo strips a notification about a deleted video down to its
+// recipient and actors, replacing its content and flagging it with
+// videoDeleted so clients stop linking to the video
+func (n *Notification) RedactVideo() {
+	metadata := map[string]interface{}{"videoDeleted": true}
+	for _, key := range redactedMetadataKeys {
+		if value, ok := n.Metadata[key]; ok {
+			metadata[key] = value
+		}
+	}
+	n.Metadata = metadata
+	n.Content = RedactedVideoContent
+}
+
+// RedactVideoNotifications redacts every stored notification that refers to
+// a deleted video, except the VIDEO_DELETED notice sent to its owner, and
+// returns how many it redacted. It can be run again after a failure.
+func (s *Service) RedactVideoNotifications(ctx context.Context, videoID uuid.UUID) (int, error) {
+	if s.repository == nil {
+		return 0, nil
+	}
+	notifications, err := s.repository.GetNotificationsByVideoID(ctx, videoID)
+	if err != nil {
+		return 0, err
+	}
+
+	redacted := 0
+	for _, notification := range notifications {
+		if notification.Type == VideoDeleted {
+			continue
+		}
+		notification.RedactVideo()
+		if err := s.repository.UpdateNotification(ctx, notification); err != nil {
+			return redacted, err
+		}
+		redacted++
+	}
+	return redacted, s.repository.ForgetVideo(ctx, videoID)
+}
+
+// VideoCleanupConsumer reads the video events topic and, for each
+// VIDEO_DELETED event, soft-deletes the video's comments and redacts the
+// notifications that refer to it. Events that fail to apply are redelivered
+// and, once the retries are used up, dead-lettered.
+type VideoCleanupConsumer struct {
+	consumer pulsar.Consumer
+	service  *Service
+	comments VideoCommentDeleter
+	logger   logger.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewVideoCleanupConsumer subscribes to the video events topic under
+// subscription, DefaultVideoCleanupSubscription if empty
+func (s *Service) NewVideoCleanupConsumer(subscription string, comments VideoCommentDeleter) (*VideoCleanupConsumer, error) {
+	if s.pulsarClient == nil {
+		return nil, fmt.Errorf("notification service is disabled")
+	}
+	if subscription == "" {
+		subscription = DefaultVideoCleanupSubscription
+	}
+
+	options := pulsar.ConsumerOptions{
+		Topic:                       s.config.VideoEventsTopic,
+		SubscriptionName:            subscription,
+		Type:                        pulsar.Shared,
+		SubscriptionInitialPosition: pulsar.SubscriptionPositionEarliest,
+	}
+	if s.config.RetryEnabled && s.config.MaxRetries > 0 {
+		options.DLQ = &pulsar.DLQPolicy{
+			MaxDeliveries:   uint32(s.config.MaxRetries),
+			DeadLetterTopic: s.config.DeadLetterTopic,
+		}
+	}
+	consumer, err := s.pulsarClient.Subscribe(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to video events: %w", err)
+	}
+	return &VideoCleanupConsumer{consumer: consumer, service: s, comments: comments, logger: s.logger}, nil
+}
+
+// Start consumes video events until Stop is called
+func (c *VideoCleanupConsumer) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			msg, err := c.consumer.Receive(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				c.logger.LogError(err, "Failed to receive video event")
+				continue
+			}
+			c.handle(ctx, msg)
+		}
+	}()
+}
+
+// Stop stops consuming, waits for the event in hand and closes the subscription
+func (c *VideoCleanupConsumer) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+	c.consumer.Close()
+}
+
+// handle cleans up after one deleted video and acknowledges the message, or
+// asks for it again when the cleanup could not finish
+func (c *VideoCleanupConsumer) handle(ctx context.Context, msg pulsar.Message) {
+	ctx, span := tracing.StartConsumer(ctx, msg)
+	defer span.End()
+
+	var event VideoEvent
+	if err := json.Unmarshal(msg.Payload(), &event); err != nil {
+		c.logger.LogWarn("Skipped malformed video event", map[string]interface{}{
+			"messageId": msg.ID().String(),
+			"error":     err.Error(),
+		})
+		c.ack(msg)
+		return
+	}
+	if event.Type != VideoDeleted || event.VideoID == uuid.Nil {
+		c.ack(msg)
+		return
+	}
+
+	if err := c.cleanUp(ctx, event.VideoID); err != nil {
+		if ctx.Err() == nil {
+			c.logger.LogError(err, "Failed to clean up after deleted video")
+		}
+		c.consumer.Nack(msg)
+		return
+	}
+	c.ack(msg)
+}
+
+// cleanUp deletes the comments of a deleted video and redacts the
+// notifications about it
+func (c *VideoCleanupConsumer) cleanUp(ctx context.Context, videoID uuid.UUID) error {
+	comments := 0
+	if c.comments != nil {
+		var err error
+		if comments, err = c.comments.DeleteVideoComments(ctx, videoID); err != nil {
+			return fmt.Errorf("failed to delete comments: %w", err)
+		}
+	}
+	notifications, err := c.service.RedactVideoNotifications(ctx, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to redact notifications: %w", err)
+	}
+	c.logger.LogInfo("Cleaned up after deleted video", map[string]interface{}{
+		"videoId":       videoID.String(),
+		"comments":      comments,
+		"notifications": notifications,
+	})
+	return nil
+}
+
+func (c *VideoCleanupConsumer) ack(msg pulsar.Message) {
+	if err := c.consumer.Ack(msg); err != nil {
+		c.logger.LogWarn("Failed to acknowledge video event", map[string]interface{}{
+			"messageId": msg.ID().String(),
+			"error":     err.Error(),
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTokenTTL is how long a VAPID token is valid; push services reject
+// tokens valid for more than 24 hours
+const vapidTokenTTL = 12 * time.Hour
+
+// VAPID identifies this server to push services. Browsers subscribe with the
+// public key, and push services only accept messages signed with the
+// matching private key.
+type VAPID struct {
+	publicKey  string
+	privateKey *ecdsa.PrivateKey
+	subject    string
+}
+
+// NewVAPID parses a base64url encoded P-256 key pair, as produced by
+// GenerateVAPIDKeys. subject is a mailto: or https: URL push services can
+// use to contact the operator.
+func NewVAPID(publicKey, privateKey, subject string) (*VAPID, error) {
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, fmt.Errorf("VAPID subject %q must be a mailto: or https:// URL", subject)
+	}
+	rawPrivate, err := decodeKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	rawPublic := key.PublicKey().Bytes()
+	if encoded := base64.RawURLEncoding.EncodeToString(rawPublic); strings.TrimRight(publicKey, "=") != encoded {
+		return nil, fmt.Errorf("VAPID public key does not match the private key")
+	}
+
+	// The uncompressed point is 0x04 followed by X and Y
+	signer := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(rawPublic[1:33]),
+			Y:     new(big.Int).SetBytes(rawPublic[33:]),
+		},
+		D: new(big.Int).SetBytes(rawPrivate),
+	}
+	return &VAPID{
+		publicKey:  base64.RawURLEncoding.EncodeToString(rawPublic),
+		privateKey: signer,
+		subject:    subject,
+	}, nil
+}
+
+// PublicKey returns the application server key browsers subscribe with
+func (v *VAPID) PublicKey() string {
+	return v.publicKey
+}
+
+// Authorization returns the Authorization header for a push service at
+// audience, the scheme and host of the subscription endpoint
+func (v *VAPID) Authorization(audience string, now time.Time) (string, error) {
+	// MapClaims keep aud a string; push services reject an array
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": now.Add(vapidTokenTTL).Unix(),
+		"sub": v.subject,
+	})
+	signed, err := token.SignedString(v.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	return "vapid t=" + signed + ", k=" + v.publicKey, nil
+}
+
+// GenerateVAPIDKeys creates a new key pair, base64url encoded
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
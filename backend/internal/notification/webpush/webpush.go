@@ -0,0 +1,206 @@
+// Package webpush delivers messages to browser push services using the Web
+// Push protocol: payloads are encrypted for the subscription (RFC 8291) and
+// requests are signed with the server's VAPID key (RFC 8292), so browsers
+// can show notifications without keeping a socket open to the server.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrSubscriptionGone is returned when the push service reports that a
+// subscription has expired or was unsubscribed; it should be deleted
+var ErrSubscriptionGone = errors.New("push subscription is no longer valid")
+
+// recordSize is the record size advertised in the encrypted payload header.
+// Payloads are sent as a single record, so it only needs to exceed them.
+const recordSize = 4096
+
+// MaxPayloadSize is the largest payload push services are required to accept
+// after encryption overhead is removed
+const MaxPayloadSize = 3993
+
+// Subscription is the push subscription a browser created for this server
+type Subscription struct {
+	Endpoint string
+	// P256dh is the subscription's public key, base64url encoded
+	P256dh string
+	// Auth is the subscription's authentication secret, base64url encoded
+	Auth string
+}
+
+// Options controls how the push service holds a message
+type Options struct {
+	// TTL is how long the push service keeps the message for an offline device
+	TTL time.Duration
+	// Urgency is very-low, low, normal or high; empty means normal
+	Urgency string
+	// Topic replaces an undelivered message sent with the same topic
+	Topic string
+}
+
+// Sender posts encrypted messages to push services
+type Sender struct {
+	client *http.Client
+	vapid  *VAPID
+	now    func() time.Time
+}
+
+// NewSender creates a sender signing requests with vapid
+func NewSender(vapid *VAPID, client *http.Client) *Sender {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Sender{client: client, vapid: vapid, now: time.Now}
+}
+
+// Send encrypts payload for sub and posts it to the subscription's push
+// service. It returns ErrSubscriptionGone when the service answers 404 or
+// 410, and an error for any other response that is not a success.
+func (s *Sender) Send(ctx context.Context, sub Subscription, payload []byte, opts Options) error {
+	if len(payload) > MaxPayloadSize {
+		return fmt.Errorf("push payload of %d bytes exceeds %d", len(payload), MaxPayloadSize)
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("invalid push endpoint %q", sub.Endpoint)
+	}
+
+	body, err := Encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.vapid.Authorization(endpoint.Scheme+"://"+endpoint.Host, s.now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(opts.TTL/time.Second)))
+	if opts.Urgency != "" {
+		req.Header.Set("Urgency", opts.Urgency)
+	}
+	if opts.Topic != "" {
+		req.Header.Set("Topic", opts.Topic)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push message: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("push service returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Encrypt encrypts payload for sub with the aes128gcm content encoding,
+// using a new key pair and salt for every message
+func Encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encrypt(sub, payload, serverKey, salt)
+}
+
+// encrypt implements RFC 8291 with the given server key and salt
+func encrypt(sub Subscription, payload []byte, serverKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	clientPublic, err := decodeKey(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	authSecret, err := decodeKey(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription auth secret: %w", err)
+	}
+	clientKey, err := ecdh.P256().NewPublicKey(clientPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	sharedSecret, err := serverKey.ECDH(clientKey)
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := serverKey.PublicKey().Bytes()
+
+	// Combine the shared secret with the auth secret, binding both public keys
+	keyInfo := "WebPush: info\x00" + string(clientPublic) + string(serverPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, then the server's public key as the key ID
+	header := make([]byte, 0, 16+4+1+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+
+	// A single record ends with the 0x02 delimiter and no padding
+	record := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// decodeKey decodes a base64url key, with or without padding, as browsers
+// produce both
+func decodeKey(key string) ([]byte, error) {
+	if decoded, err := base64.RawURLEncoding.DecodeString(key); err == nil {
+		return decoded, nil
+	}
+	return base64.URLEncoding.DecodeString(key)
+}
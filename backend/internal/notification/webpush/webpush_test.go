@@ -0,0 +1,128 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc8291Example is the example in Appendix A of RFC 8291
+var rfc8291Example = struct {
+	plaintext, serverPrivate, salt, result string
+	sub                                    Subscription
+}{
+	plaintext:     "When I grow up, I want to be a watermelon",
+	serverPrivate: "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw",
+	salt:          "DGv6ra1nlYgDCS1FRnbzlw",
+	sub: Subscription{
+		Endpoint: "https://push.example.net/push/JzLQ3raZJfFBR0aqvOMsLrt54w4rJUsV",
+		P256dh:   "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:     "BTBZMqHH6r4Tts7J_aSIgg",
+	},
+	result: "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+}
+
+func TestEncrypt_RFC8291Example(t *testing.T) {
+	example := rfc8291Example
+	rawKey, err := base64.RawURLEncoding.DecodeString(example.serverPrivate)
+	require.NoError(t, err)
+	serverKey, err := ecdh.P256().NewPrivateKey(rawKey)
+	require.NoError(t, err)
+	salt, err := base64.RawURLEncoding.DecodeString(example.salt)
+	require.NoError(t, err)
+
+	body, err := encrypt(example.sub, []byte(example.plaintext), serverKey, salt)
+	require.NoError(t, err)
+	assert.Equal(t, example.result, base64.RawURLEncoding.EncodeToString(body))
+}
+
+func TestEncrypt_NewKeysPerMessage(t *testing.T) {
+	first, err := Encrypt(rfc8291Example.sub, []byte("hello"))
+	require.NoError(t, err)
+	second, err := Encrypt(rfc8291Example.sub, []byte("hello"))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	_, err = Encrypt(Subscription{P256dh: "not-a-key", Auth: rfc8291Example.sub.Auth}, []byte("hello"))
+	assert.Error(t, err)
+}
+
+func TestNewVAPID(t *testing.T) {
+	public, private, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	vapid, err := NewVAPID(public, private, "mailto:ops@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, public, vapid.PublicKey())
+
+	otherPublic, _, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	_, err = NewVAPID(otherPublic, private, "mailto:ops@example.com")
+	assert.Error(t, err, "mismatched key pair")
+	_, err = NewVAPID(public, private, "ops@example.com")
+	assert.Error(t, err, "subject must be a URL")
+}
+
+func TestSender_Send(t *testing.T) {
+	public, private, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	vapid, err := NewVAPID(public, private, "mailto:ops@example.com")
+	require.NoError(t, err)
+
+	var received *http.Request
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		switch r.URL.Path {
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	sender := NewSender(vapid, server.Client())
+	sub := rfc8291Example.sub
+	sub.Endpoint = server.URL + "/push/abc"
+	require.NoError(t, sender.Send(context.Background(), sub, []byte(`{"type":"USER_FOLLOWED"}`), Options{TTL: time.Hour, Urgency: "normal"}))
+
+	assert.Equal(t, "aes128gcm", received.Header.Get("Content-Encoding"))
+	assert.Equal(t, "3600", received.Header.Get("TTL"))
+	assert.Equal(t, "normal", received.Header.Get("Urgency"))
+
+	// The token is signed by the VAPID key for the endpoint's origin
+	authorization := received.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(authorization, "vapid t="))
+	token, key, _ := strings.Cut(strings.TrimPrefix(authorization, "vapid t="), ", k=")
+	assert.Equal(t, public, key)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return &vapid.privateKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	require.NoError(t, err)
+	assert.Equal(t, server.URL, claims["aud"])
+	assert.Equal(t, "mailto:ops@example.com", claims["sub"])
+
+	sub.Endpoint = server.URL + "/gone"
+	assert.ErrorIs(t, sender.Send(context.Background(), sub, []byte("{}"), Options{}), ErrSubscriptionGone)
+
+	sub.Endpoint = server.URL + "/busy"
+	err = sender.Send(context.Background(), sub, []byte("{}"), Options{})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrSubscriptionGone))
+
+	sub.Endpoint = "http://push.example.net/insecure"
+	assert.Error(t, sender.Send(context.Background(), sub, []byte("{}"), Options{}), "endpoints must use https")
+}
@@ -0,0 +1,50 @@
+package p2p
+
+import (
+	"context"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/gin-gonic/gin"
+)
+
+// InfoSource reports the node's peer-to-peer identity and peers
+type InfoSource interface {
+	Info(ctx context.Context) (*Info, error)
+}
+
+// Handler handles HTTP requests for peer-to-peer endpoints
+type Handler struct {
+	node            InfoSource
+	responseHandler httpHandler.ResponseHandler
+}
+
+// NewHandler creates a new p2p handler instance
+func NewHandler(node InfoSource, responseHandler httpHandler.ResponseHandler) *Handler {
+	return &Handler{
+		node:            node,
+		responseHandler: responseHandler,
+	}
+}
+
+// RegisterRoutes registers the p2p routes. Peer info is public so clients and
+// peers can find the node without an account.
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/p2p/info", h.handleGetInfo)
+}
+
+// @Summary Get peer-to-peer node info
+// @Description Returns the peer ID and multiaddrs of the libp2p host videos are announced from, its connected peer count, and the Pavilion nodes heard on the announcement topic. Only served when p2p is enabled.
+// @Tags p2p
+// @Produce json
+// @Success 200 {object} httpHandler.APIResponse{data=Info} "P2P info retrieved successfully"
+// @Failure 503 {object} httpHandler.APIResponse{error=httpHandler.APIError} "The IPFS node is unreachable"
+// @Router /p2p/info [get]
+func (h *Handler) handleGetInfo(c *gin.Context) {
+	info, err := h.node.Info(c.Request.Context())
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, apierror.Status(apierror.CodeServiceUnavailable), apierror.CodeServiceUnavailable, "Failed to reach the P2P node", err)
+		return
+	}
+	h.responseHandler.SuccessResponse(c, info, "P2P info retrieved successfully")
+}
@@ -0,0 +1,44 @@
+package p2p
+
+import "time"
+
+// VideoAnnouncement is the metadata gossiped to peer Pavilion nodes once a
+// video is fully replicated to IPFS
+type VideoAnnouncement struct {
+	VideoID     string   `json:"video_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Duration is the video's length in seconds
+	Duration float64 `json:"duration,omitempty"`
+	// CIDs maps each rendition ("original", "720p", ...) to its IPFS CID
+	CIDs map[string]string `json:"cids"`
+	// Node is the base URL of the announcing node's API, when it has one
+	Node        string    `json:"node,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Info describes this node's place in the peer-to-peer network
+type Info struct {
+	// PeerID and Addresses identify the libp2p host clients and peers dial
+	PeerID       string   `json:"peer_id" example:"12D3KooWAbc"`
+	Addresses    []string `json:"addresses" example:"/ip4/203.0.113.7/tcp/4001/p2p/12D3KooWAbc"`
+	AgentVersion string   `json:"agent_version" example:"kubo/0.29.0/"`
+	// ConnectedPeers counts the libp2p peers currently connected, Pavilion or not
+	ConnectedPeers int `json:"connected_peers" example:"42"`
+	// Topic is the pubsub topic video announcements are gossiped on
+	Topic string `json:"topic" example:"pavilion/videos/v1"`
+	// PavilionPeers are the nodes this node has heard announcements from, most recent first
+	PavilionPeers []Peer `json:"pavilion_peers"`
+}
+
+// Peer is another Pavilion node heard on the announcement topic
+type Peer struct {
+	PeerID string `json:"peer_id" example:"12D3KooWXyz"`
+	// URL is the base URL of the peer's API, when it sent one
+	URL string `json:"url,omitempty" example:"https://pavilion.example.com/api/v1"`
+	// Videos counts the announcements heard from the peer
+	Videos   int       `json:"videos" example:"3"`
+	LastSeen time.Time `json:"last_seen"`
+}
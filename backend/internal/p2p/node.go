@@ -0,0 +1,283 @@
+// Package p2p shares videos with other Pavilion nodes over libp2p for
+// peer-assisted delivery. It drives the libp2p host of the IPFS node videos
+// are replicated to: CIDs are announced on that host's DHT and video metadata
+// is gossiped over its pubsub, so the backend does not run a second peer
+// identity or swarm beside the one already holding the files.
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	shell "github.com/ipfs/go-ipfs-api"
+)
+
+const (
+	// maxPeers bounds how many Pavilion peers are remembered; the least
+	// recently heard is forgotten first
+	maxPeers = 1000
+	// resubscribeDelay is how long to wait before subscribing to the topic
+	// again after the subscription broke
+	resubscribeDelay = 5 * time.Second
+)
+
+// Config controls how the node announces videos
+type Config struct {
+	// Topic is the pubsub topic video announcements are gossiped on
+	Topic string
+	// PublicURL is the base URL of this node's API, sent with announcements
+	PublicURL string
+	// ProvideTimeout bounds announcing one CID on the DHT
+	ProvideTimeout time.Duration
+}
+
+// Node announces videos through an IPFS node's libp2p host and listens for
+// the announcements of peer Pavilion nodes
+type Node struct {
+	shell  *shell.Shell
+	config Config
+	logger logger.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	self  string
+	peers map[string]*Peer
+	sub   *shell.PubSubSubscription
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewNode creates a node using the IPFS API at apiAddress; call Start to
+// listen for peers' announcements
+func NewNode(apiAddress string, config Config, logger logger.Logger) *Node {
+	return &Node{
+		shell:  shell.NewShell(apiAddress),
+		config: config,
+		logger: logger,
+		now:    time.Now,
+		peers:  make(map[string]*Peer),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start subscribes to the announcement topic in the background. The
+// subscription is retried while the IPFS node is unreachable.
+func (n *Node) Start() {
+	n.wg.Add(1)
+	go n.listen()
+}
+
+// Stop ends the subscription and waits for the listener to exit
+func (n *Node) Stop() {
+	close(n.done)
+	n.mu.Lock()
+	if n.sub != nil {
+		n.sub.Cancel()
+	}
+	n.mu.Unlock()
+	n.wg.Wait()
+}
+
+// Provide announces on the DHT that the IPFS node holds cid
+func (n *Node) Provide(ctx context.Context, cid string) error {
+	ctx, cancel := context.WithTimeout(ctx, n.config.ProvideTimeout)
+	defer cancel()
+
+	if err := n.shell.Request("routing/provide", cid).Exec(ctx, nil); err != nil {
+		return fmt.Errorf("failed to provide %s: %w", cid, err)
+	}
+	return nil
+}
+
+// PublishVideo gossips a video's metadata and CIDs on the announcement topic
+func (n *Node) PublishVideo(ctx context.Context, v *video.Video) error {
+	announcement := VideoAnnouncement{
+		VideoID:     v.ID.String(),
+		Title:       v.Title,
+		Description: v.Description,
+		Category:    string(v.Category),
+		Duration:    v.Duration,
+		CIDs:        video.RenditionCIDs(v),
+		Node:        n.config.PublicURL,
+		PublishedAt: n.now(),
+	}
+	for _, tag := range v.Tags {
+		announcement.Tags = append(announcement.Tags, tag.Name)
+	}
+
+	data, err := json.Marshal(announcement)
+	if err != nil {
+		return fmt.Errorf("failed to encode announcement: %w", err)
+	}
+	if err := n.shell.PubSubPublish(n.config.Topic, string(data)); err != nil {
+		return fmt.Errorf("failed to publish announcement: %w", err)
+	}
+	return nil
+}
+
+// Info returns the identity and connectivity of the libp2p host and the
+// Pavilion peers heard so far
+func (n *Node) Info(ctx context.Context) (*Info, error) {
+	var id shell.IdOutput
+	if err := n.shell.Request("id").Exec(ctx, &id); err != nil {
+		return nil, fmt.Errorf("failed to identify IPFS node: %w", err)
+	}
+
+	var swarm struct {
+		Peers []struct {
+			Peer string
+		}
+	}
+	if err := n.shell.Request("swarm/peers").Exec(ctx, &swarm); err != nil {
+		return nil, fmt.Errorf("failed to list swarm peers: %w", err)
+	}
+
+	info := &Info{
+		PeerID:         id.ID,
+		Addresses:      id.Addresses,
+		AgentVersion:   id.AgentVersion,
+		ConnectedPeers: len(swarm.Peers),
+		Topic:          n.config.Topic,
+		PavilionPeers:  n.Peers(),
+	}
+	if info.Addresses == nil {
+		info.Addresses = []string{}
+	}
+	return info, nil
+}
+
+// Peers returns the Pavilion peers heard so far, most recently heard first
+func (n *Node) Peers() []Peer {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	peers := make([]Peer, 0, len(n.peers))
+	for _, peer := range n.peers {
+		peers = append(peers, *peer)
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].LastSeen.After(peers[j].LastSeen)
+	})
+	return peers
+}
+
+// listen reads announcements until the node is stopped, subscribing again
+// whenever the subscription breaks
+func (n *Node) listen() {
+	defer n.wg.Done()
+
+	for {
+		if err := n.subscribe(); err != nil {
+			n.logger.LogWarn("P2P announcement subscription failed", map[string]interface{}{
+				"topic": n.config.Topic,
+				"error": err.Error(),
+			})
+		}
+
+		select {
+		case <-n.done:
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+// subscribe subscribes to the announcement topic and records announcements
+// until the subscription ends
+func (n *Node) subscribe() error {
+	id, err := n.shell.ID()
+	if err != nil {
+		return fmt.Errorf("failed to identify IPFS node: %w", err)
+	}
+	sub, err := n.shell.PubSubSubscribe(n.config.Topic)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	n.mu.Lock()
+	select {
+	case <-n.done:
+		n.mu.Unlock()
+		sub.Cancel()
+		return nil
+	default:
+	}
+	n.self = id.ID
+	n.sub = sub
+	n.mu.Unlock()
+	defer sub.Cancel()
+
+	n.logger.LogInfo("Listening for P2P video announcements", map[string]interface{}{
+		"topic":   n.config.Topic,
+		"peer_id": id.ID,
+	})
+	for {
+		message, err := sub.Next()
+		if err != nil {
+			select {
+			case <-n.done:
+				return nil
+			default:
+				return fmt.Errorf("subscription ended: %w", err)
+			}
+		}
+		n.receive(message.From.String(), message.Data)
+	}
+}
+
+// receive records an announcement from peerID. The node's own
+// announcements come back to it and are skipped.
+func (n *Node) receive(peerID string, data []byte) {
+	var announcement VideoAnnouncement
+	if err := json.Unmarshal(data, &announcement); err != nil || announcement.VideoID == "" {
+		n.logger.LogWarn("Ignoring malformed P2P announcement", map[string]interface{}{
+			"peer_id": peerID,
+		})
+		return
+	}
+
+	n.mu.Lock()
+	if peerID == n.self {
+		n.mu.Unlock()
+		return
+	}
+	peer, ok := n.peers[peerID]
+	if !ok {
+		if len(n.peers) >= maxPeers {
+			n.forgetOldestPeer()
+		}
+		peer = &Peer{PeerID: peerID}
+		n.peers[peerID] = peer
+	}
+	peer.URL = announcement.Node
+	peer.Videos++
+	peer.LastSeen = n.now()
+	n.mu.Unlock()
+
+	n.logger.LogInfo("Received P2P video announcement", map[string]interface{}{
+		"peer_id":  peerID,
+		"node":     announcement.Node,
+		"video_id": announcement.VideoID,
+		"cids":     len(announcement.CIDs),
+	})
+}
+
+// forgetOldestPeer drops the least recently heard peer. The caller holds n.mu.
+func (n *Node) forgetOldestPeer() {
+	var oldest *Peer
+	for _, peer := range n.peers {
+		if oldest == nil || peer.LastSeen.Before(oldest.LastSeen) {
+			oldest = peer
+		}
+	}
+	if oldest != nil {
+		delete(n.peers, oldest.PeerID)
+	}
+}
@@ -0,0 +1,177 @@
+package p2p
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	selfID = "QmYyQSo1c1Ym7orWxLYvCrM2EmxFTANf8wXmmE7DWjhx5N"
+	peerID = "12D3KooWD3eckifWpRn9wQpMG9R9hX3sD158z7EqHWmweQAJU5SA"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) LogInfo(string, map[string]interface{})                {}
+func (nopLogger) LogError(err error, _ string) error                    { return err }
+func (nopLogger) LogErrorf(err error, _ string, _ ...interface{}) error { return err }
+func (nopLogger) LogFatal(error, string)                                {}
+func (nopLogger) LogDebug(string, map[string]interface{})               {}
+func (nopLogger) LogWarn(string, map[string]interface{})                {}
+func (l nopLogger) WithFields(map[string]interface{}) logger.Logger     { return l }
+func (l nopLogger) WithContext(context.Context) logger.Logger           { return l }
+func (l nopLogger) WithRequestID(string) logger.Logger                  { return l }
+func (l nopLogger) WithUserID(string) logger.Logger                     { return l }
+
+// multibase encodes data the way the IPFS RPC API does for pubsub
+func multibase(data []byte) string {
+	return "u" + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// newTestNode returns a node using a fake IPFS RPC API served by handler
+func newTestNode(t *testing.T, handler http.HandlerFunc) *Node {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewNode(server.Listener.Addr().String(), Config{
+		Topic:          "pavilion/videos/v1",
+		PublicURL:      "https://pavilion.test/api/v1",
+		ProvideTimeout: time.Second,
+	}, nopLogger{})
+}
+
+func TestProvide(t *testing.T) {
+	var provided string
+	node := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v0/routing/provide", r.URL.Path)
+		provided = r.URL.Query().Get("arg")
+		fmt.Fprintln(w, `{"Type":4,"ID":""}`)
+	})
+
+	require.NoError(t, node.Provide(context.Background(), "bafyoriginal"))
+	assert.Equal(t, "bafyoriginal", provided)
+}
+
+func TestPublishVideo(t *testing.T) {
+	var topic string
+	var announcement VideoAnnouncement
+	node := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/version":
+			fmt.Fprintln(w, `{"Version":"0.29.0"}`)
+		case "/api/v0/pubsub/pub":
+			topic = r.URL.Query().Get("arg")
+			reader, err := r.MultipartReader()
+			require.NoError(t, err)
+			part, err := reader.NextPart()
+			require.NoError(t, err)
+			data, err := io.ReadAll(part)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(data, &announcement))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	published := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	node.now = func() time.Time { return published }
+
+	v := &video.Video{
+		ID:          uuid.New(),
+		Title:       "Test Video",
+		StoragePath: "videos/v/original.mp4",
+		IPFSCID:     "bafyoriginal",
+		Duration:    12.5,
+		Tags:        []video.Tag{{Name: "music"}},
+		Transcodes: []video.Transcode{{
+			Format:   "mp4",
+			Segments: []video.TranscodeSegment{{StoragePath: "videos/v/720p.mp4", IPFSCID: "bafy720p"}},
+		}},
+	}
+	require.NoError(t, node.PublishVideo(context.Background(), v))
+
+	assert.Equal(t, multibase([]byte("pavilion/videos/v1")), topic)
+	assert.Equal(t, VideoAnnouncement{
+		VideoID:     v.ID.String(),
+		Title:       "Test Video",
+		Tags:        []string{"music"},
+		Duration:    12.5,
+		CIDs:        map[string]string{"original": "bafyoriginal", "720p": "bafy720p"},
+		Node:        "https://pavilion.test/api/v1",
+		PublishedAt: published,
+	}, announcement)
+}
+
+func TestInfo(t *testing.T) {
+	node := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/id":
+			fmt.Fprintf(w, `{"ID":%q,"Addresses":["/ip4/203.0.113.7/tcp/4001/p2p/%s"],"AgentVersion":"kubo/0.29.0/"}`, selfID, selfID)
+		case "/api/v0/swarm/peers":
+			fmt.Fprintf(w, `{"Peers":[{"Addr":"/ip4/198.51.100.1/tcp/4001","Peer":%q}]}`, peerID)
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+	node.receive(peerID, []byte(`{"video_id":"v1","node":"https://peer.test/api/v1"}`))
+
+	info, err := node.Info(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, selfID, info.PeerID)
+	assert.Equal(t, []string{"/ip4/203.0.113.7/tcp/4001/p2p/" + selfID}, info.Addresses)
+	assert.Equal(t, 1, info.ConnectedPeers)
+	assert.Equal(t, "pavilion/videos/v1", info.Topic)
+	require.Len(t, info.PavilionPeers, 1)
+	assert.Equal(t, "https://peer.test/api/v1", info.PavilionPeers[0].URL)
+}
+
+func TestReceive(t *testing.T) {
+	node := NewNode("127.0.0.1:0", Config{}, nopLogger{})
+	node.self = selfID
+
+	node.receive(peerID, []byte(`{"video_id":"v1","node":"https://peer.test/api/v1"}`))
+	node.receive(peerID, []byte(`{"video_id":"v2","node":"https://peer.test/api/v1"}`))
+	// The node's own announcements and malformed ones are not recorded
+	node.receive(selfID, []byte(`{"video_id":"v3"}`))
+	node.receive("QmOther", []byte(`not json`))
+
+	peers := node.Peers()
+	require.Len(t, peers, 1)
+	assert.Equal(t, peerID, peers[0].PeerID)
+	assert.Equal(t, 2, peers[0].Videos)
+}
+
+func TestListen(t *testing.T) {
+	message := fmt.Sprintf(`{"from":%q,"data":%q,"seqno":%q,"topicIDs":[%q]}`,
+		peerID, multibase([]byte(`{"video_id":"v1"}`)), multibase([]byte{1}), multibase([]byte("pavilion/videos/v1")))
+	node := newTestNode(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/id":
+			fmt.Fprintf(w, `{"ID":%q}`, selfID)
+		case "/api/v0/pubsub/sub":
+			fmt.Fprintln(w, message)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	})
+
+	node.Start()
+	require.Eventually(t, func() bool { return len(node.Peers()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	node.Stop()
+	assert.Equal(t, peerID, node.Peers()[0].PeerID)
+}
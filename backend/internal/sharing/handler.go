@@ -0,0 +1,156 @@
+package sharing
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for oEmbed and the sitemap
+type Handler struct {
+	service         Service
+	maxAge          time.Duration
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new sharing handler instance. Clients and proxies
+// may reuse responses for maxAge.
+func NewHandler(service Service, maxAge time.Duration, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		maxAge:          maxAge,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers oEmbed and the sitemap, which are served at the
+// root of the domain where consumers and search engines look for them
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	router.GET("/oembed", h.handleOEmbed)
+	router.GET("/sitemap.xml", h.handleSitemap)
+}
+
+// @Summary Describe a shared video link
+// @Description oEmbed endpoint for watch page URLs, so shared links unfurl with the video's title, channel, thumbnail and an embedded player. The url must match sharing.watchUrl; its query and fragment are ignored. The player is scaled down, keeping its ratio, to fit maxwidth and maxheight. Served at the root of the domain as bare JSON; only served when sharing is enabled.
+// @Tags sharing
+// @Produce json
+// @Param url query string true "Watch page URL, e.g. https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"
+// @Param maxwidth query int false "Maximum player width in pixels"
+// @Param maxheight query int false "Maximum player height in pixels"
+// @Param format query string false "Response format; only json is supported" Enums(json)
+// @Success 200 {object} OEmbed "oEmbed response"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Missing url"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "URL is not a public video of this site"
+// @Failure 501 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Format not supported"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /oembed [get]
+func (h *Handler) handleOEmbed(c *gin.Context) {
+	pageURL := c.Query("url")
+	if pageURL == "" {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "url is required", nil)
+		return
+	}
+	if format := c.Query("format"); format != "" && format != "json" {
+		h.responseHandler.ErrorResponse(c, http.StatusNotImplemented, "NOT_IMPLEMENTED", "Only the json format is supported", nil)
+		return
+	}
+	maxWidth, _ := strconv.Atoi(c.Query("maxwidth"))
+	maxHeight, _ := strconv.Atoi(c.Query("maxheight"))
+
+	response, err := h.service.OEmbed(c.Request.Context(), pageURL, maxWidth, maxHeight)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to describe video")
+		return
+	}
+	c.Header("Cache-Control", h.cacheControl())
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Get the sitemap
+// @Description Sitemap of the watch pages of public videos, with video extension entries for videos whose thumbnail can be linked. When there are more videos than sharing.sitemapSize, the sitemap is a sitemap index of pages fetched with page. Served at the root of the domain; only served when sharing is enabled.
+// @Tags sharing
+// @Produce xml
+// @Param page query int false "Sitemap page, as linked from the sitemap index"
+// @Success 200 {object} URLSet "Sitemap, or sitemap index"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Page not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /sitemap.xml [get]
+func (h *Handler) handleSitemap(c *gin.Context) {
+	page := 1
+	if value := c.Query("page"); value != "" {
+		var err error
+		if page, err = strconv.Atoi(value); err != nil {
+			h.handleServiceError(c, ErrPageNotFound, "Failed to build sitemap")
+			return
+		}
+	} else {
+		pages, err := h.service.SitemapPages(c.Request.Context())
+		if err != nil {
+			h.handleServiceError(c, err, "Failed to build sitemap")
+			return
+		}
+		if pages > 1 {
+			index := &SitemapIndex{Xmlns: sitemapNamespace, Sitemaps: make([]SitemapRef, 0, pages)}
+			for i := 1; i <= pages; i++ {
+				index.Sitemaps = append(index.Sitemaps, SitemapRef{Loc: fmt.Sprintf("%s?page=%d", requestURL(c), i)})
+			}
+			h.writeXML(c, index)
+			return
+		}
+	}
+
+	urlSet, err := h.service.SitemapPage(c.Request.Context(), page)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to build sitemap")
+		return
+	}
+	h.writeXML(c, urlSet)
+}
+
+// writeXML writes an XML document with the sharing caching headers
+func (h *Handler) writeXML(c *gin.Context, document interface{}) {
+	body, err := xml.Marshal(document)
+	if err != nil {
+		h.responseHandler.InternalErrorResponse(c, "Failed to encode sitemap", err)
+		return
+	}
+	c.Header("Cache-Control", h.cacheControl())
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", append([]byte(xml.Header), body...))
+}
+
+// cacheControl returns the Cache-Control value of responses
+func (h *Handler) cacheControl() string {
+	if h.maxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", int64(h.maxAge/time.Second))
+}
+
+// requestURL returns the absolute URL a request was made to, without its query
+func requestURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + c.Request.URL.Path
+}
+
+// handleServiceError maps sharing service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrNotEmbeddable), errors.Is(err, ErrVideoNotFound), errors.Is(err, ErrPageNotFound):
+		h.responseHandler.NotFoundResponse(c, err.Error())
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
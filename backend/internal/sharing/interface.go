@@ -0,0 +1,28 @@
+package sharing
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotEmbeddable is returned for oEmbed URLs that are not watch pages of this site
+	ErrNotEmbeddable = errors.New("URL is not a video of this site")
+	// ErrVideoNotFound is returned when a video does not exist or is not public
+	ErrVideoNotFound = errors.New("video not found")
+	// ErrPageNotFound is returned for sitemap pages past the last
+	ErrPageNotFound = errors.New("sitemap page not found")
+)
+
+// Service defines the interface for sharing videos outside the API. Only
+// public videos are shared: completed, needing no entitlement, not
+// quarantined and not taken down.
+type Service interface {
+	// OEmbed describes the video at a watch page URL, with an embedded player
+	// fitting within maxWidth and maxHeight when they are positive
+	OEmbed(ctx context.Context, pageURL string, maxWidth, maxHeight int) (*OEmbed, error)
+	// SitemapPages returns how many pages the sitemap has; at least one
+	SitemapPages(ctx context.Context) (int, error)
+	// SitemapPage returns a page of the sitemap, oldest videos first
+	SitemapPage(ctx context.Context, page int) (*URLSet, error)
+}
@@ -0,0 +1,77 @@
+package sharing
+
+import "encoding/xml"
+
+// XML namespaces of sitemaps and their video extension
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	videoNamespace   = "http://www.google.com/schemas/sitemap-video/1.1"
+)
+
+// Limits search engines put on sitemap video entries
+const (
+	maxVideoDescription = 2048
+	maxVideoDuration    = 28800
+)
+
+// OEmbed is an oEmbed response of type video
+type OEmbed struct {
+	Type         string `json:"type" example:"video"`
+	Version      string `json:"version" example:"1.0"`
+	Title        string `json:"title" example:"Intro to Go"`
+	AuthorName   string `json:"author_name,omitempty" example:"John Doe"`
+	AuthorURL    string `json:"author_url,omitempty" example:"https://pavilion.example.com/channel/550e8400-e29b-41d4-a716-446655440000"`
+	ProviderName string `json:"provider_name" example:"Pavilion"`
+	ProviderURL  string `json:"provider_url" example:"https://pavilion.example.com"`
+	// CacheAge is how many seconds consumers may keep the response
+	CacheAge int64 `json:"cache_age,omitempty" example:"3600"`
+	// ThumbnailURL is the video's animated preview; the thumbnail fields are
+	// only set when it can be linked
+	ThumbnailURL    string `json:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int    `json:"thumbnail_width,omitempty" example:"320"`
+	ThumbnailHeight int    `json:"thumbnail_height,omitempty" example:"180"`
+	// HTML embeds the player in an iframe
+	HTML   string `json:"html" example:"<iframe src=\"https://pavilion.example.com/embed/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b\" width=\"640\" height=\"360\" frameborder=\"0\" allowfullscreen></iframe>"`
+	Width  int    `json:"width" example:"640"`
+	Height int    `json:"height" example:"360"`
+}
+
+// URLSet is a sitemap page
+type URLSet struct {
+	XMLName xml.Name     `xml:"urlset" swaggerignore:"true"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Video   string       `xml:"xmlns:video,attr"`
+	URLs    []SitemapURL `xml:"url"`
+}
+
+// SitemapURL is a video's watch page in a sitemap
+type SitemapURL struct {
+	Loc string `xml:"loc"`
+	// LastMod is when the video last changed, in W3C datetime format
+	LastMod string `xml:"lastmod"`
+	// Video describes the video on the page; only set when its thumbnail,
+	// which search engines require, can be linked
+	Video *SitemapVideo `xml:"video:video,omitempty"`
+}
+
+// SitemapVideo is a sitemap video extension entry
+type SitemapVideo struct {
+	ThumbnailLoc    string `xml:"video:thumbnail_loc"`
+	Title           string `xml:"video:title"`
+	Description     string `xml:"video:description"`
+	PlayerLoc       string `xml:"video:player_loc"`
+	Duration        int64  `xml:"video:duration,omitempty"`
+	PublicationDate string `xml:"video:publication_date"`
+}
+
+// SitemapIndex lists the pages of a sitemap too large for one
+type SitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex" swaggerignore:"true"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []SitemapRef `xml:"sitemap"`
+}
+
+// SitemapRef is a page of a sitemap index
+type SitemapRef struct {
+	Loc string `xml:"loc"`
+}
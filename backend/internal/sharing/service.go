@@ -0,0 +1,255 @@
+package sharing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Config represents how videos are shared
+type Config struct {
+	// ProviderName is the site name shown on unfurled links
+	ProviderName string
+	// SiteURL is the home page of the site
+	SiteURL string
+	// WatchURL, EmbedURL and ChannelURL are page URLs with {id} replaced by
+	// the video or user ID; ChannelURL may be empty
+	WatchURL   string
+	EmbedURL   string
+	ChannelURL string
+	// EmbedWidth and EmbedHeight are the size of the embedded player
+	EmbedWidth  int
+	EmbedHeight int
+	// ThumbnailWidth is the width previews are generated at. Their height is
+	// not recorded, so thumbnails are described as 16:9.
+	ThumbnailWidth int
+	// SitemapSize is how many videos each sitemap page lists
+	SitemapSize int
+	// MaxAge is how long consumers may keep oEmbed responses
+	MaxAge time.Duration
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db     *gorm.DB
+	config Config
+	cdn    video.PlaybackCDN
+	logger logger.Logger
+}
+
+// NewService creates a new sharing service. Thumbnails are linked on the CDN
+// when its URLs do not expire, and left out otherwise; cdn may be nil.
+func NewService(db *gorm.DB, config Config, cdn video.PlaybackCDN, logger logger.Logger) Service {
+	if config.EmbedWidth < 1 || config.EmbedHeight < 1 {
+		config.EmbedWidth, config.EmbedHeight = 640, 360
+	}
+	if config.SitemapSize < 1 {
+		config.SitemapSize = 50000
+	}
+	return &serviceImpl{
+		db:     db,
+		config: config,
+		cdn:    cdn,
+		logger: logger,
+	}
+}
+
+// OEmbed describes the video at a watch page URL
+func (s *serviceImpl) OEmbed(ctx context.Context, pageURL string, maxWidth, maxHeight int) (*OEmbed, error) {
+	videoID, err := s.matchWatchURL(pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var v video.Video
+	if err := s.db.WithContext(ctx).Model(&video.Video{}).Scopes(video.PublicVideos).
+		Where("videos.id = ?", videoID).First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVideoNotFound
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	var user auth.User
+	if err := s.db.WithContext(ctx).Where("id = ?", v.UserID).First(&user).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get video owner: %w", err)
+	}
+	return s.oEmbed(&v, &user, maxWidth, maxHeight), nil
+}
+
+// oEmbed builds the oEmbed response of a video
+func (s *serviceImpl) oEmbed(v *video.Video, user *auth.User, maxWidth, maxHeight int) *OEmbed {
+	width, height := fit(s.config.EmbedWidth, s.config.EmbedHeight, maxWidth, maxHeight)
+	embedURL := pageURL(s.config.EmbedURL, v.ID)
+	response := &OEmbed{
+		Type:         "video",
+		Version:      "1.0",
+		Title:        v.Title,
+		ProviderName: s.config.ProviderName,
+		ProviderURL:  s.config.SiteURL,
+		CacheAge:     int64(s.config.MaxAge / time.Second),
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" frameborder="0" allowfullscreen></iframe>`,
+			html.EscapeString(embedURL), width, height),
+		Width:  width,
+		Height: height,
+	}
+
+	response.AuthorName = user.Name
+	if response.AuthorName == "" {
+		response.AuthorName = user.Username
+	}
+	if s.config.ChannelURL != "" && user.ID != uuid.Nil {
+		response.AuthorURL = pageURL(s.config.ChannelURL, user.ID)
+	}
+	if thumbnail := s.thumbnailURL(v.PreviewPath); thumbnail != "" && s.config.ThumbnailWidth > 0 {
+		response.ThumbnailURL = thumbnail
+		response.ThumbnailWidth, response.ThumbnailHeight = fit(s.config.ThumbnailWidth, s.config.ThumbnailWidth*9/16, maxWidth, maxHeight)
+	}
+	return response
+}
+
+// matchWatchURL returns the ID of the video a watch page URL is for. The
+// query and fragment are ignored unless the watch URL template has them.
+func (s *serviceImpl) matchWatchURL(rawURL string) (uuid.UUID, error) {
+	prefix, suffix, ok := strings.Cut(s.config.WatchURL, "{id}")
+	if !ok || rawURL == "" {
+		return uuid.Nil, ErrNotEmbeddable
+	}
+	if !strings.Contains(s.config.WatchURL, "#") {
+		rawURL, _, _ = strings.Cut(rawURL, "#")
+	}
+	if !strings.Contains(s.config.WatchURL, "?") {
+		rawURL, _, _ = strings.Cut(rawURL, "?")
+	}
+	if !strings.HasPrefix(rawURL, prefix) || !strings.HasSuffix(rawURL, suffix) || len(rawURL) < len(prefix)+len(suffix) {
+		return uuid.Nil, ErrNotEmbeddable
+	}
+
+	videoID, err := uuid.Parse(rawURL[len(prefix) : len(rawURL)-len(suffix)])
+	if err != nil {
+		return uuid.Nil, ErrNotEmbeddable
+	}
+	return videoID, nil
+}
+
+// SitemapPages returns how many pages the sitemap has
+func (s *serviceImpl) SitemapPages(ctx context.Context) (int, error) {
+	var total int64
+	if err := s.db.WithContext(ctx).Model(&video.Video{}).Scopes(video.PublicVideos).Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to count videos: %w", err)
+	}
+	pages := int((total + int64(s.config.SitemapSize) - 1) / int64(s.config.SitemapSize))
+	return max(pages, 1), nil
+}
+
+// SitemapPage returns a page of the sitemap, oldest videos first, so pages
+// only change when their videos do
+func (s *serviceImpl) SitemapPage(ctx context.Context, page int) (*URLSet, error) {
+	if page < 1 {
+		return nil, ErrPageNotFound
+	}
+
+	var videos []video.Video
+	if err := s.db.WithContext(ctx).Model(&video.Video{}).Scopes(video.PublicVideos).
+		Select("videos.id, videos.title, videos.description, videos.preview_path, videos.duration, videos.created_at, videos.updated_at").
+		Order("videos.created_at, videos.id").
+		Offset((page - 1) * s.config.SitemapSize).Limit(s.config.SitemapSize).
+		Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+	// The first page is served even without videos, so the sitemap always exists
+	if len(videos) == 0 && page > 1 {
+		return nil, ErrPageNotFound
+	}
+
+	urlSet := &URLSet{Xmlns: sitemapNamespace, Video: videoNamespace, URLs: make([]SitemapURL, 0, len(videos))}
+	for i := range videos {
+		urlSet.URLs = append(urlSet.URLs, s.sitemapURL(&videos[i]))
+	}
+	return urlSet, nil
+}
+
+// sitemapURL builds the sitemap entry of a video
+func (s *serviceImpl) sitemapURL(v *video.Video) SitemapURL {
+	entry := SitemapURL{
+		Loc:     pageURL(s.config.WatchURL, v.ID),
+		LastMod: v.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+
+	thumbnail := s.thumbnailURL(v.PreviewPath)
+	if thumbnail == "" {
+		return entry
+	}
+	entry.Video = &SitemapVideo{
+		ThumbnailLoc:    thumbnail,
+		Title:           v.Title,
+		Description:     truncate(v.Description, maxVideoDescription),
+		PlayerLoc:       pageURL(s.config.EmbedURL, v.ID),
+		PublicationDate: v.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	// Search engines reject entries with durations out of range
+	if duration := int64(math.Round(v.Duration)); duration >= 1 && duration <= maxVideoDuration {
+		entry.Video.Duration = duration
+	}
+	// Search engines require a description; the title stands in for a missing one
+	if entry.Video.Description == "" {
+		entry.Video.Description = v.Title
+	}
+	return entry
+}
+
+// thumbnailURL links a video's preview on the CDN when its URLs do not
+// expire, since unfurled links and sitemaps are kept for a long time. It
+// returns an empty string otherwise.
+func (s *serviceImpl) thumbnailURL(key string) string {
+	if key == "" || s.cdn == nil || s.cdn.Signed() {
+		return ""
+	}
+	url, err := s.cdn.URL(key)
+	if err != nil {
+		s.logger.LogWarn("Failed to link preview on the CDN", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+		return ""
+	}
+	return url
+}
+
+// pageURL fills a page URL template with an ID
+func pageURL(template string, id uuid.UUID) string {
+	return strings.ReplaceAll(template, "{id}", id.String())
+}
+
+// fit scales width and height down to fit within maxWidth and maxHeight,
+// keeping their ratio; limits that are not positive are ignored
+func fit(width, height, maxWidth, maxHeight int) (int, int) {
+	if maxWidth > 0 && width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+	if maxHeight > 0 && height > maxHeight {
+		width = width * maxHeight / height
+		height = maxHeight
+	}
+	return width, height
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
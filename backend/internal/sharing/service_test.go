@@ -0,0 +1,235 @@
+package sharing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCDN links files on a fixed domain
+type fakeCDN struct {
+	signed bool
+}
+
+func (f fakeCDN) URL(key string) (string, error) {
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (f fakeCDN) Signed() bool {
+	return f.signed
+}
+
+// testConfig returns a configuration with every page URL set
+func testConfig() Config {
+	return Config{
+		ProviderName:   "Pavilion",
+		SiteURL:        "https://pavilion.example.com",
+		WatchURL:       "https://pavilion.example.com/watch/{id}",
+		EmbedURL:       "https://pavilion.example.com/embed/{id}",
+		ChannelURL:     "https://pavilion.example.com/channel/{id}",
+		EmbedWidth:     640,
+		EmbedHeight:    360,
+		ThumbnailWidth: 320,
+		MaxAge:         time.Hour,
+	}
+}
+
+// testVideo returns a public video with a preview
+func testVideo() *video.Video {
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &video.Video{
+		ID:          uuid.MustParse("5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b"),
+		UserID:      uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+		Title:       "Intro to Go",
+		PreviewPath: "videos/5f0c/preview.webp",
+		Duration:    754.2,
+		CreatedAt:   created,
+		UpdatedAt:   created.Add(time.Hour),
+	}
+}
+
+func TestMatchWatchURL(t *testing.T) {
+	service := NewService(nil, testConfig(), nil, nil).(*serviceImpl)
+	videoID := uuid.MustParse("5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b")
+
+	for _, pageURL := range []string{
+		"https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b",
+		"https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b?t=30",
+		"https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b#comments",
+	} {
+		id, err := service.matchWatchURL(pageURL)
+		require.NoError(t, err, pageURL)
+		assert.Equal(t, videoID, id)
+	}
+
+	for _, pageURL := range []string{
+		"",
+		"https://pavilion.example.com/watch/",
+		"https://pavilion.example.com/watch/not-a-uuid",
+		"https://other.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b",
+		"https://pavilion.example.com/embed/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b",
+	} {
+		_, err := service.matchWatchURL(pageURL)
+		assert.ErrorIs(t, err, ErrNotEmbeddable, pageURL)
+	}
+}
+
+func TestOEmbedResponse(t *testing.T) {
+	service := NewService(nil, testConfig(), fakeCDN{}, nil).(*serviceImpl)
+	user := &auth.User{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), Username: "johndoe", Name: "John Doe"}
+
+	response := service.oEmbed(testVideo(), user, 0, 0)
+	assert.Equal(t, &OEmbed{
+		Type:            "video",
+		Version:         "1.0",
+		Title:           "Intro to Go",
+		AuthorName:      "John Doe",
+		AuthorURL:       "https://pavilion.example.com/channel/550e8400-e29b-41d4-a716-446655440000",
+		ProviderName:    "Pavilion",
+		ProviderURL:     "https://pavilion.example.com",
+		CacheAge:        3600,
+		ThumbnailURL:    "https://cdn.example.com/videos/5f0c/preview.webp",
+		ThumbnailWidth:  320,
+		ThumbnailHeight: 180,
+		HTML:            `<iframe src="https://pavilion.example.com/embed/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b" width="640" height="360" frameborder="0" allowfullscreen></iframe>`,
+		Width:           640,
+		Height:          360,
+	}, response)
+
+	response = service.oEmbed(testVideo(), user, 320, 0)
+	assert.Equal(t, 320, response.Width)
+	assert.Equal(t, 180, response.Height)
+	assert.Contains(t, response.HTML, `width="320" height="180"`)
+
+	// Signed CDN URLs expire, so no thumbnail is given
+	service = NewService(nil, testConfig(), fakeCDN{signed: true}, nil).(*serviceImpl)
+	response = service.oEmbed(testVideo(), &auth.User{}, 0, 0)
+	assert.Empty(t, response.ThumbnailURL)
+	assert.Empty(t, response.AuthorURL)
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		maxWidth, maxHeight   int
+		wantWidth, wantHeight int
+	}{
+		{0, 0, 640, 360},
+		{1280, 720, 640, 360},
+		{480, 0, 480, 270},
+		{0, 180, 320, 180},
+		{480, 180, 320, 180},
+	}
+	for _, tt := range tests {
+		width, height := fit(640, 360, tt.maxWidth, tt.maxHeight)
+		assert.Equal(t, tt.wantWidth, width, "maxwidth %d maxheight %d", tt.maxWidth, tt.maxHeight)
+		assert.Equal(t, tt.wantHeight, height, "maxwidth %d maxheight %d", tt.maxWidth, tt.maxHeight)
+	}
+}
+
+func TestSitemapURL(t *testing.T) {
+	service := NewService(nil, testConfig(), fakeCDN{}, nil).(*serviceImpl)
+	entry := service.sitemapURL(testVideo())
+	assert.Equal(t, SitemapURL{
+		Loc:     "https://pavilion.example.com/watch/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b",
+		LastMod: "2026-05-01T13:00:00Z",
+		Video: &SitemapVideo{
+			ThumbnailLoc:    "https://cdn.example.com/videos/5f0c/preview.webp",
+			Title:           "Intro to Go",
+			Description:     "Intro to Go",
+			PlayerLoc:       "https://pavilion.example.com/embed/5f0c2a6e-2d4b-4c0a-9b8e-6b1f2d3c4a5b",
+			Duration:        754,
+			PublicationDate: "2026-05-01T12:00:00Z",
+		},
+	}, entry)
+
+	long := testVideo()
+	long.Description = strings.Repeat("é", maxVideoDescription+10)
+	long.Duration = maxVideoDuration + 1
+	entry = service.sitemapURL(long)
+	assert.Len(t, []rune(entry.Video.Description), maxVideoDescription)
+	assert.Zero(t, entry.Video.Duration)
+
+	// Without a thumbnail, search engines take no video entry
+	service = NewService(nil, testConfig(), nil, nil).(*serviceImpl)
+	assert.Nil(t, service.sitemapURL(testVideo()).Video)
+}
+
+// fakeService returns fixed responses
+type fakeService struct {
+	pages int
+	err   error
+}
+
+func (f *fakeService) OEmbed(ctx context.Context, pageURL string, maxWidth, maxHeight int) (*OEmbed, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &OEmbed{Type: "video", Version: "1.0", Width: maxWidth, Height: maxHeight}, nil
+}
+
+func (f *fakeService) SitemapPages(ctx context.Context) (int, error) {
+	return f.pages, nil
+}
+
+func (f *fakeService) SitemapPage(ctx context.Context, page int) (*URLSet, error) {
+	if page > f.pages {
+		return nil, ErrPageNotFound
+	}
+	return &URLSet{Xmlns: sitemapNamespace, Video: videoNamespace, URLs: []SitemapURL{{Loc: "https://pavilion.example.com/watch/1"}}}, nil
+}
+
+func TestHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(logger.DefaultConfig())
+	require.NoError(t, err)
+
+	service := &fakeService{pages: 1}
+	router := gin.New()
+	NewHandler(service, time.Hour, httpHandler.NewResponseHandler(log), log).RegisterRoutes(router)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := request("/oembed?url=https%3A%2F%2Fpavilion.example.com%2Fwatch%2F1&maxwidth=480&maxheight=270")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	var response OEmbed
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 480, response.Width)
+	assert.Equal(t, 270, response.Height)
+
+	assert.Equal(t, http.StatusBadRequest, request("/oembed").Code)
+	assert.Equal(t, http.StatusNotImplemented, request("/oembed?url=x&format=xml").Code)
+	service.err = ErrNotEmbeddable
+	assert.Equal(t, http.StatusNotFound, request("/oembed?url=x").Code)
+
+	w = request("/sitemap.xml")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"`)
+	assert.Contains(t, w.Body.String(), `<loc>https://pavilion.example.com/watch/1</loc>`)
+
+	service.pages = 2
+	w = request("/sitemap.xml")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	assert.Contains(t, w.Body.String(), `<loc>http://example.com/sitemap.xml?page=2</loc>`)
+	assert.Equal(t, http.StatusOK, request("/sitemap.xml?page=2").Code)
+	assert.Equal(t, http.StatusNotFound, request("/sitemap.xml?page=3").Code)
+	assert.Equal(t, http.StatusNotFound, request("/sitemap.xml?page=last").Code)
+}
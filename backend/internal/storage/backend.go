@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/storage/local"
+	"github.com/consensuslabs/pavilion-network/backend/internal/storage/s3"
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+)
+
+// NewBackend creates the storage selected by storage.backend. The S3
+// bucket is bootstrapped first when configured, so a misconfigured local
+// store fails at startup rather than on the first upload.
+func NewBackend(cfg *config.Config, loggerService logger.Logger) (Backend, error) {
+	if cfg.Storage.Backend == config.StorageBackendLocal {
+		localService, err := local.NewService(&local.Config{
+			Dir:           cfg.Storage.Local.Dir,
+			RootDirectory: cfg.Storage.S3.RootDirectory,
+			BaseURL:       cfg.Storage.Local.BaseURL,
+		}, loggerService)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize local storage: %v", err)
+		}
+		return localService, nil
+	}
+
+	s3Config := &videostorage.Config{
+		Endpoint:        cfg.Storage.S3.Endpoint,
+		AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+		SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+		UseSSL:          cfg.Storage.S3.UseSSL,
+		Region:          cfg.Storage.S3.Region,
+		Bucket:          cfg.Storage.S3.Bucket,
+		RootDirectory:   cfg.Storage.S3.RootDirectory,
+
+		PartSize:          int64(cfg.Storage.S3.Multipart.PartSizeMB) << 20,
+		UploadConcurrency: cfg.Storage.S3.Multipart.Concurrency,
+	}
+
+	// Debug log for S3 configuration
+	loggerService.LogInfo("S3 Configuration in App", map[string]interface{}{
+		"endpoint":        cfg.Storage.S3.Endpoint,
+		"region":          cfg.Storage.S3.Region,
+		"bucket":          cfg.Storage.S3.Bucket,
+		"useSSL":          cfg.Storage.S3.UseSSL,
+		"accessKeyID":     cfg.Storage.S3.AccessKeyID,
+		"secretAccessKey": cfg.Storage.S3.SecretAccessKey != "", // Don't log the actual secret
+		"accessKeyLength": len(cfg.Storage.S3.AccessKeyID),
+		"secretKeyLength": len(cfg.Storage.S3.SecretAccessKey),
+	})
+
+	s3Service, err := s3.NewService(s3Config, loggerService)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize S3 service: %v", err)
+	}
+
+	if cfg.Storage.S3.Bootstrap.Enabled {
+		bootstrapCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := s3Service.Bootstrap(bootstrapCtx, s3.BootstrapOptions{
+			CORSAllowedOrigins:        cfg.Storage.S3.Bootstrap.CORSAllowedOrigins,
+			AbortIncompleteUploadDays: cfg.Storage.S3.Bootstrap.AbortIncompleteUploadDays,
+		})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to bootstrap S3 storage: %v", err)
+		}
+	}
+	return s3Service, nil
+}
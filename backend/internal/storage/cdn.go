@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	"github.com/consensuslabs/pavilion-network/backend/internal/storage/cdn"
+)
+
+// cdnBackend hands out CDN URLs for a backend's files
+type cdnBackend struct {
+	Backend
+	cdn cdn.CDN
+}
+
+// WithCDN returns backend with its file URLs rewritten to the CDN's domain.
+// Only URLs change; files are still stored and read through backend, which
+// should be used directly wherever its other capabilities are needed.
+func WithCDN(backend Backend, c cdn.CDN) Backend {
+	return &cdnBackend{Backend: backend, cdn: c}
+}
+
+// GetVideoURL returns the CDN URL of a video file
+func (b *cdnBackend) GetVideoURL(ctx context.Context, key string) (string, error) {
+	return b.cdn.URL(key)
+}
+
+// GetAvatarURL returns the CDN URL of an avatar
+func (b *cdnBackend) GetAvatarURL(ctx context.Context, key string) (string, error) {
+	return b.cdn.URL(key)
+}
+
+// cdnTimeout bounds each purge request to the CDN's API
+const cdnTimeout = 30 * time.Second
+
+// NewCDN creates the CDN selected by storage.cdn.provider, or returns nil
+// when storage.cdn is disabled
+func NewCDN(cfg *config.Config) (cdn.CDN, error) {
+	cdnConfig := cfg.Storage.CDN
+	if !cdnConfig.Enabled {
+		return nil, nil
+	}
+
+	client := &http.Client{Timeout: cdnTimeout}
+	if cdnConfig.Provider == config.CDNProviderFastly {
+		return cdn.NewFastly(cdn.FastlyConfig{
+			Domain:      cdnConfig.Domain,
+			SignURLs:    cdnConfig.SignURLs,
+			URLTTL:      cdnConfig.URLTTL,
+			TokenSecret: cdnConfig.Fastly.TokenSecret,
+			APIToken:    cdnConfig.Fastly.APIToken,
+		}, client), nil
+	}
+
+	cloudFront, err := cdn.NewCloudFront(cdn.CloudFrontConfig{
+		Domain:          cdnConfig.Domain,
+		SignURLs:        cdnConfig.SignURLs,
+		URLTTL:          cdnConfig.URLTTL,
+		KeyPairID:       cdnConfig.CloudFront.KeyPairID,
+		PrivateKey:      cdnConfig.CloudFront.PrivateKey,
+		DistributionID:  cdnConfig.CloudFront.DistributionID,
+		AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+		SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+	}, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize CloudFront: %v", err)
+	}
+	return cloudFront, nil
+}
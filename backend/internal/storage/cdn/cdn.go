@@ -0,0 +1,61 @@
+// Package cdn serves stored files through a content delivery network in
+// front of the storage bucket. Providers rewrite file keys to URLs on the
+// CDN's domain, sign them when the CDN only serves signed requests, and
+// purge files from the CDN's caches when they change or are deleted.
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CDN providers, as named in the configuration
+const (
+	ProviderCloudFront = "cloudfront"
+	ProviderFastly     = "fastly"
+)
+
+// CDN serves stored files
+type CDN interface {
+	// URL returns the URL a stored file is served at, signed when Signed
+	URL(key string) (string, error)
+	// Signed reports whether URLs are signed, so they stop working once
+	// they expire
+	Signed() bool
+	// Purge drops stored files from the CDN's caches, so the next request
+	// for each goes to storage
+	Purge(ctx context.Context, keys []string) error
+}
+
+// urls builds the URLs of stored files on a CDN's domain
+type urls struct {
+	domain string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// plain returns the unsigned URL of key
+func (u urls) plain(key string) string {
+	return (&url.URL{Scheme: "https", Host: u.domain, Path: "/" + strings.TrimPrefix(key, "/")}).String()
+}
+
+// expiry returns when a URL signed now expires. Expiries are rounded to
+// half the TTL, so URLs signed close together are identical and players and
+// browsers can cache them; every URL stays valid for at least half the TTL.
+func (u urls) expiry() time.Time {
+	return u.now().Truncate(u.ttl / 2).Add(u.ttl)
+}
+
+// checkResponse returns an error describing a failed purge request
+func checkResponse(resp *http.Response, what string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("%s returned %s: %s", what, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,188 @@
+package cdn
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixedNow = time.Date(2024, 5, 1, 12, 10, 0, 0, time.UTC)
+
+func newTestKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestCloudFrontURL_Unsigned(t *testing.T) {
+	cf, err := NewCloudFront(CloudFrontConfig{Domain: "cdn.example.com"}, http.DefaultClient)
+	require.NoError(t, err)
+
+	got, err := cf.URL("videos/abc/720p.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/videos/abc/720p.mp4", got)
+	assert.False(t, cf.Signed())
+}
+
+func TestCloudFrontURL_SignedWithCannedPolicy(t *testing.T) {
+	key, pemKey := newTestKey(t)
+	cf, err := NewCloudFront(CloudFrontConfig{
+		Domain:     "cdn.example.com",
+		SignURLs:   true,
+		URLTTL:     time.Hour,
+		KeyPairID:  "K2JCJMDEHXQW5F",
+		PrivateKey: pemKey,
+	}, http.DefaultClient)
+	require.NoError(t, err)
+	cf.now = func() time.Time { return fixedNow }
+
+	got, err := cf.URL("videos/abc/720p.mp4")
+	require.NoError(t, err)
+	assert.True(t, cf.Signed())
+
+	u, err := url.Parse(got)
+	require.NoError(t, err)
+	query := u.Query()
+	// 12:10 rounds down to 12:00, so the URL expires at 13:00
+	expires := fixedNow.Truncate(30 * time.Minute).Add(time.Hour).Unix()
+	assert.Equal(t, fmt.Sprint(expires), query.Get("Expires"))
+	assert.Equal(t, "K2JCJMDEHXQW5F", query.Get("Key-Pair-Id"))
+
+	// The signature must verify against the canned policy of the unsigned URL
+	policy := fmt.Sprintf(`{"Statement":[{"Resource":"https://cdn.example.com/videos/abc/720p.mp4","Condition":{"DateLessThan":{"AWS:EpochTime":%d}}}]}`, expires)
+	encoded := strings.NewReplacer("-", "+", "_", "=", "~", "/").Replace(query.Get("Signature"))
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	digest := sha1.Sum([]byte(policy))
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA1, digest[:], signature))
+
+	// URLs signed within the same window are identical, so they can be cached
+	cf.now = func() time.Time { return fixedNow.Add(10 * time.Minute) }
+	again, err := cf.URL("videos/abc/720p.mp4")
+	require.NoError(t, err)
+	assert.Equal(t, got, again)
+}
+
+func TestNewCloudFront_InvalidKey(t *testing.T) {
+	_, err := NewCloudFront(CloudFrontConfig{Domain: "cdn.example.com", SignURLs: true, PrivateKey: "not a key"}, http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestCloudFrontPurge(t *testing.T) {
+	var mu sync.Mutex
+	var path, body, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		path, body, auth = r.URL.Path, string(data), r.Header.Get("Authorization")
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	cf, err := NewCloudFront(CloudFrontConfig{
+		Domain:          "cdn.example.com",
+		DistributionID:  "E1ABCDEF",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	}, server.Client())
+	require.NoError(t, err)
+	cf.api = server.URL
+
+	require.NoError(t, cf.Purge(context.Background(), []string{"videos/abc/original.mp4", "videos/abc/720p.mp4"}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "/distribution/E1ABCDEF/invalidation", path)
+	assert.Contains(t, body, "<Quantity>2</Quantity>")
+	assert.Contains(t, body, "<Path>/videos/abc/original.mp4</Path><Path>/videos/abc/720p.mp4</Path>")
+	assert.Contains(t, auth, "AWS4-HMAC-SHA256 Credential=key/")
+	assert.Contains(t, auth, "/us-east-1/cloudfront/aws4_request")
+}
+
+func TestCloudFrontPurge_WithoutDistributionDoesNothing(t *testing.T) {
+	cf, err := NewCloudFront(CloudFrontConfig{Domain: "cdn.example.com"}, &http.Client{
+		Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+			t.Fatal("Expected no request")
+			return nil, nil
+		}),
+	})
+	require.NoError(t, err)
+	assert.NoError(t, cf.Purge(context.Background(), []string{"videos/abc/720p.mp4"}))
+}
+
+func TestFastlyURL_Token(t *testing.T) {
+	f := NewFastly(FastlyConfig{Domain: "cdn.example.com", SignURLs: true, URLTTL: time.Hour, TokenSecret: "s3cret"}, http.DefaultClient)
+	f.now = func() time.Time { return fixedNow }
+
+	got, err := f.URL("videos/abc/720p.mp4")
+	require.NoError(t, err)
+
+	expires := fmt.Sprint(fixedNow.Truncate(30 * time.Minute).Add(time.Hour).Unix())
+	mac := hmac.New(sha1.New, []byte("s3cret"))
+	mac.Write([]byte("/videos/abc/720p.mp4" + expires))
+	assert.Equal(t, "https://cdn.example.com/videos/abc/720p.mp4?token="+expires+"_"+hex.EncodeToString(mac.Sum(nil)), got)
+	assert.True(t, f.Signed())
+}
+
+func TestFastlyPurge(t *testing.T) {
+	var mu sync.Mutex
+	var paths, keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		keys = append(keys, r.Header.Get("Fastly-Key"))
+		mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "missing.mp4") {
+			http.Error(w, `{"msg":"bad"}`, http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	f := NewFastly(FastlyConfig{Domain: "cdn.example.com", APIToken: "token"}, server.Client())
+	f.api = server.URL
+
+	require.NoError(t, f.Purge(context.Background(), []string{"videos/abc/720p.mp4", "videos/abc/480p.mp4"}))
+	err := f.Purge(context.Background(), []string{"videos/abc/missing.mp4"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"POST /purge/cdn.example.com/videos/abc/720p.mp4",
+		"POST /purge/cdn.example.com/videos/abc/480p.mp4",
+		"POST /purge/cdn.example.com/videos/abc/missing.mp4",
+	}, paths)
+	assert.Equal(t, []string{"token", "token", "token"}, keys)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,176 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// cloudFrontAPI is the CloudFront API endpoint. CloudFront is a global
+// service, signed for us-east-1.
+const (
+	cloudFrontAPI    = "https://cloudfront.amazonaws.com/2020-05-31"
+	cloudFrontRegion = "us-east-1"
+)
+
+// CloudFrontConfig represents a CloudFront distribution in front of the bucket
+type CloudFrontConfig struct {
+	// Domain is the distribution's domain or an alternate domain name
+	Domain string
+	// SignURLs signs URLs with a canned policy, for distributions that
+	// restrict viewer access
+	SignURLs bool
+	// URLTTL is how long signed URLs stay valid
+	URLTTL time.Duration
+	// KeyPairID is the ID of the public key, in a trusted key group, that
+	// matches PrivateKey
+	KeyPairID string
+	// PrivateKey is the PEM-encoded RSA key URLs are signed with
+	PrivateKey string
+	// DistributionID is the distribution purged when files change; files
+	// are not purged when it is empty
+	DistributionID string
+	// AccessKeyID and SecretAccessKey authenticate invalidation requests
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// CloudFront serves files through Amazon CloudFront
+type CloudFront struct {
+	urls
+	config CloudFrontConfig
+	key    *rsa.PrivateKey
+	signer *v4.Signer
+	client *http.Client
+	api    string
+}
+
+// NewCloudFront creates a CloudFront CDN, parsing the signing key when
+// URLs are signed
+func NewCloudFront(config CloudFrontConfig, client *http.Client) (*CloudFront, error) {
+	cf := &CloudFront{
+		urls:   urls{domain: config.Domain, ttl: config.URLTTL, now: time.Now},
+		config: config,
+		signer: v4.NewSigner(),
+		client: client,
+		api:    cloudFrontAPI,
+	}
+	if config.SignURLs {
+		key, err := parsePrivateKey(config.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		cf.key = key
+	}
+	return cf, nil
+}
+
+// parsePrivateKey parses a PEM-encoded RSA key in PKCS #1 or PKCS #8 form
+func parsePrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, fmt.Errorf("cloudfront private key is not PEM-encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cloudfront private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("cloudfront private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Signed implements CDN
+func (cf *CloudFront) Signed() bool {
+	return cf.key != nil
+}
+
+// URL implements CDN. Signed URLs carry a canned policy allowing the URL
+// until it expires.
+func (cf *CloudFront) URL(key string) (string, error) {
+	resource := cf.plain(key)
+	if cf.key == nil {
+		return resource, nil
+	}
+
+	expires := cf.expiry().Unix()
+	policy := fmt.Sprintf(`{"Statement":[{"Resource":"%s","Condition":{"DateLessThan":{"AWS:EpochTime":%d}}}]}`, resource, expires)
+	digest := sha1.Sum([]byte(policy))
+	signature, err := rsa.SignPKCS1v15(nil, cf.key, crypto.SHA1, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign cloudfront URL: %w", err)
+	}
+	return fmt.Sprintf("%s?Expires=%d&Signature=%s&Key-Pair-Id=%s", resource, expires, urlSafeBase64(signature), cf.config.KeyPairID), nil
+}
+
+// urlSafeBase64 encodes a signature the way CloudFront expects in query strings
+func urlSafeBase64(data []byte) string {
+	return strings.NewReplacer("+", "-", "=", "_", "/", "~").Replace(base64.StdEncoding.EncodeToString(data))
+}
+
+// invalidationBatch is the body of a CloudFront CreateInvalidation request
+type invalidationBatch struct {
+	XMLName         xml.Name `xml:"http://cloudfront.amazonaws.com/doc/2020-05-31/ InvalidationBatch"`
+	Quantity        int      `xml:"Paths>Quantity"`
+	Paths           []string `xml:"Paths>Items>Path"`
+	CallerReference string   `xml:"CallerReference"`
+}
+
+// Purge implements CDN with a single invalidation of every key. It does
+// nothing without a distribution ID.
+func (cf *CloudFront) Purge(ctx context.Context, keys []string) error {
+	if cf.config.DistributionID == "" || len(keys) == 0 {
+		return nil
+	}
+
+	batch := invalidationBatch{
+		Quantity:        len(keys),
+		CallerReference: fmt.Sprintf("pavilion-%d", time.Now().UnixNano()),
+	}
+	for _, key := range keys {
+		batch.Paths = append(batch.Paths, "/"+strings.TrimPrefix(key, "/"))
+	}
+	body, err := xml.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode invalidation: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/distribution/%s/invalidation", cf.api, cf.config.DistributionID), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create invalidation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	hash := sha256.Sum256(body)
+	credentials := aws.Credentials{AccessKeyID: cf.config.AccessKeyID, SecretAccessKey: cf.config.SecretAccessKey}
+	if err := cf.signer.SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), "cloudfront", cloudFrontRegion, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign invalidation request: %w", err)
+	}
+
+	resp, err := cf.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("invalidation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp, "cloudfront invalidation")
+}
@@ -0,0 +1,105 @@
+package cdn
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fastlyAPI is the Fastly API endpoint
+const fastlyAPI = "https://api.fastly.com"
+
+// FastlyConfig represents a Fastly service in front of the bucket
+type FastlyConfig struct {
+	// Domain is the service's domain
+	Domain string
+	// SignURLs adds a token to URLs, for services that validate tokens
+	SignURLs bool
+	// URLTTL is how long tokens stay valid
+	URLTTL time.Duration
+	// TokenSecret is the key tokens are signed with, shared with the service
+	TokenSecret string
+	// APIToken authenticates purge requests; files are not purged when it is empty
+	APIToken string
+}
+
+// Fastly serves files through Fastly
+type Fastly struct {
+	urls
+	config FastlyConfig
+	client *http.Client
+	api    string
+}
+
+// NewFastly creates a Fastly CDN
+func NewFastly(config FastlyConfig, client *http.Client) *Fastly {
+	return &Fastly{
+		urls:   urls{domain: config.Domain, ttl: config.URLTTL, now: time.Now},
+		config: config,
+		client: client,
+		api:    fastlyAPI,
+	}
+}
+
+// Signed implements CDN
+func (f *Fastly) Signed() bool {
+	return f.config.SignURLs
+}
+
+// URL implements CDN. Signed URLs carry a token=<expiry>_<signature>
+// parameter, the signature being the hex HMAC-SHA1 of the path followed by
+// the expiry, as Fastly's token validation expects.
+func (f *Fastly) URL(key string) (string, error) {
+	plain := f.plain(key)
+	if !f.config.SignURLs {
+		return plain, nil
+	}
+
+	u, err := url.Parse(plain)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse fastly URL: %w", err)
+	}
+	expires := strconv.FormatInt(f.expiry().Unix(), 10)
+	mac := hmac.New(sha1.New, []byte(f.config.TokenSecret))
+	mac.Write([]byte(u.EscapedPath() + expires))
+	return plain + "?token=" + expires + "_" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Purge implements CDN, purging each key's URL. It does nothing without an
+// API token.
+func (f *Fastly) Purge(ctx context.Context, keys []string) error {
+	if f.config.APIToken == "" {
+		return nil
+	}
+	for _, key := range keys {
+		if err := f.purge(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purge purges the URL of one key
+func (f *Fastly) purge(ctx context.Context, key string) error {
+	target := f.api + "/purge/" + f.config.Domain + "/" + strings.TrimPrefix(key, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create purge request: %w", err)
+	}
+	req.Header.Set("Fastly-Key", f.config.APIToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("purge request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp, "fastly purge of "+key)
+}
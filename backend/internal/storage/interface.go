@@ -14,17 +14,28 @@ type IPFSService interface {
 	videostorage.Service
 	GetGatewayURL(cid string) string
 	DownloadFile(cid string) (string, error)
+	// Unpin releases the local pin on cid so the node can garbage collect it
+	Unpin(ctx context.Context, cid string) error
 }
 
-// S3Service defines S3-specific operations
-type S3Service interface {
+// Backend stores video files and avatars. It is implemented by the S3
+// service and by the local filesystem driver; storage.backend selects one.
+type Backend interface {
 	videostorage.Service
-	// UploadVideo uploads a video file to S3 with the standardized path structure
-	UploadVideo(ctx context.Context, videoID uuid.UUID, resolution string, reader io.Reader) (string, error)
-	// GetVideoURL returns the URL for a video in S3
-	GetVideoURL(ctx context.Context, key string) (string, error)
-	// DeleteVideo deletes a video and its transcoded versions from S3
-	DeleteVideo(ctx context.Context, videoID uuid.UUID) error
+	// DownloadVideo opens a stored video by key for reading
+	DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error)
+	// OpenVideo opens a stored video by key for reading at any offset
+	OpenVideo(ctx context.Context, key string) (videostorage.Object, error)
+	// UploadCaption stores a file of a video's captions, named like
+	// videostorage.CaptionFile, and returns its key. Keys can be passed to
+	// GetVideoURL.
+	UploadCaption(ctx context.Context, videoID uuid.UUID, fileName, contentType string, reader io.Reader) (string, error)
+	// UploadAvatar uploads a user avatar image and returns its key
+	UploadAvatar(ctx context.Context, userID uuid.UUID, ext string, contentType string, reader io.Reader) (string, error)
+	// GetAvatarURL returns a URL for an avatar key
+	GetAvatarURL(ctx context.Context, key string) (string, error)
+	// Ping checks that the storage can be reached and used
+	Ping(ctx context.Context) error
 }
 
 // Logger interface for logging operations
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/storage"
 	"github.com/google/uuid"
@@ -28,14 +29,15 @@ func NewService(cfg *storage.IPFSConfig, logger storage.Logger) *Service {
 }
 
 // UploadVideo uploads a video file to IPFS and returns its CID
-func (s *Service) UploadVideo(_ context.Context, videoID uuid.UUID, resolution string, reader io.Reader) (string, error) {
+func (s *Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resolution string, reader io.Reader) (string, error) {
 	s.logger.LogInfo("Starting IPFS upload", map[string]interface{}{
 		"video_id":   videoID,
 		"resolution": resolution,
 		"gateway":    s.gatewayURL,
 	})
 	
-	cid, err := s.shell.Add(reader)
+	// The shell has no context-aware add, so stop feeding it once ctx is done
+	cid, err := s.shell.Add(&contextReader{ctx: ctx, reader: reader})
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to upload to IPFS: video_id=%s, resolution=%s", 
 			videoID, resolution)
@@ -53,6 +55,19 @@ func (s *Service) UploadVideo(_ context.Context, videoID uuid.UUID, resolution s
 	return cid, nil
 }
 
+// contextReader fails reads once ctx is done, aborting the request it feeds
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
 // GetVideoURL returns the IPFS gateway URL for a given CID
 func (s *Service) GetVideoURL(_ context.Context, cid string) (string, error) {
 	return s.gatewayURL + cid, nil
@@ -64,6 +79,17 @@ func (s *Service) DeleteVideo(_ context.Context, _ uuid.UUID) error {
 	return nil
 }
 
+// ArchiveVideo is a no-op for IPFS, where content is addressed by CID and
+// never replaced
+func (s *Service) ArchiveVideo(_ context.Context, _ uuid.UUID, _ int) error {
+	return nil
+}
+
+// DeleteVideoVersion is a no-op for IPFS as we don't pin files in MVP
+func (s *Service) DeleteVideoVersion(_ context.Context, _ uuid.UUID, _ int) error {
+	return nil
+}
+
 // DownloadFile downloads a file from IPFS using its CID
 func (s *Service) DownloadFile(cid string) (string, error) {
 	r, err := s.shell.Cat(cid)
@@ -92,6 +118,28 @@ func (s *Service) GetGatewayURL(cid string) string {
 	return s.gatewayURL + cid
 }
 
+// Unpin removes the pin on cid. A CID that is not pinned counts as unpinned.
+func (s *Service) Unpin(ctx context.Context, cid string) error {
+	if err := s.shell.Request("pin/rm", cid).Exec(ctx, nil); err != nil {
+		if strings.Contains(err.Error(), "not pinned") {
+			return nil
+		}
+		return fmt.Errorf("failed to unpin %s: %w", cid, err)
+	}
+	return nil
+}
+
+// Ping checks that the IPFS API is reachable
+func (s *Service) Ping(ctx context.Context) error {
+	var version struct {
+		Version string
+	}
+	if err := s.shell.Request("version").Exec(ctx, &version); err != nil {
+		return fmt.Errorf("failed to reach IPFS API: %w", err)
+	}
+	return nil
+}
+
 // Close closes any open IPFS connections and resources
 func (s *Service) Close() error {
 	// Currently, the IPFS service doesn't maintain any long-lived connections
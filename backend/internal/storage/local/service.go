@@ -0,0 +1,336 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+	"github.com/google/uuid"
+)
+
+// Config represents the local filesystem storage configuration
+type Config struct {
+	// Dir is the directory files are stored in
+	Dir string
+	// RootDirectory is the key prefix for videos, "videos" when empty
+	RootDirectory string
+	// BaseURL is the URL the contents of Dir are served from
+	BaseURL string
+}
+
+// Service stores videos and avatars on the local filesystem, using the same
+// keys as S3. It is meant for development and CI, where no object store is
+// available; files are served by the API server itself.
+type Service struct {
+	config *Config
+	logger logger.Logger
+}
+
+// NewService creates a local storage service, creating its directory if needed
+func NewService(cfg *Config, logger logger.Logger) (*Service, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("local storage directory is required")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create local storage directory: %w", err)
+	}
+
+	logger.LogInfo("Using local filesystem storage", map[string]interface{}{
+		"dir":      cfg.Dir,
+		"base_url": cfg.BaseURL,
+	})
+	return &Service{config: cfg, logger: logger}, nil
+}
+
+func (s *Service) rootDir() string {
+	if s.config.RootDirectory != "" {
+		return s.config.RootDirectory
+	}
+	return "videos"
+}
+
+// path returns the file for key, refusing keys that would escape the storage directory
+func (s *Service) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", fmt.Errorf("invalid storage key: %s", key)
+	}
+	return filepath.Join(s.config.Dir, filepath.FromSlash(key)), nil
+}
+
+// url returns the URL key is served from
+func (s *Service) url(key string) string {
+	return strings.TrimSuffix(s.config.BaseURL, "/") + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+// UploadVideo stores a video file with the standardized path structure
+func (s *Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resolution string, reader io.Reader) (string, error) {
+	if !videostorage.ValidateRendition(resolution) {
+		return "", fmt.Errorf("invalid resolution for video upload: %s", resolution)
+	}
+
+	key := path.Join(s.rootDir(), videoID.String(), videostorage.FileName(resolution))
+	if err := s.write(ctx, key, reader); err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to store video: video_id=%s, resolution=%s, key=%s",
+			videoID, resolution, key))
+		return "", fmt.Errorf("failed to store video: %w", err)
+	}
+
+	s.logger.LogInfo("Stored video on local filesystem", map[string]interface{}{
+		"video_id":   videoID,
+		"resolution": resolution,
+		"key":        key,
+	})
+	return key, nil
+}
+
+// write stores reader under key. The file is written to a temporary name and
+// renamed, so readers never see a partial file.
+func (s *Service) write(ctx context.Context, key string, reader io.Reader) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	writer := &progressWriter{ctx: ctx, w: file, total: -1, progress: videostorage.ProgressFromContext(ctx)}
+	if seeker, ok := reader.(io.Seeker); ok {
+		if current, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			if end, err := seeker.Seek(0, io.SeekEnd); err == nil {
+				writer.total = end - current
+			}
+			if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+				file.Close()
+				return fmt.Errorf("failed to seek upload: %w", err)
+			}
+		}
+	}
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+	return os.Rename(file.Name(), target)
+}
+
+// progressWriter reports bytes written to a progress callback and stops when ctx is cancelled
+type progressWriter struct {
+	ctx      context.Context
+	w        io.Writer
+	written  int64
+	total    int64
+	progress videostorage.ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.progress != nil {
+		p.progress(p.written, p.total)
+	}
+	return n, err
+}
+
+// GetVideoURL returns the URL a stored video is served from
+func (s *Service) GetVideoURL(_ context.Context, key string) (string, error) {
+	if !strings.HasPrefix(key, s.rootDir()+"/") {
+		return "", fmt.Errorf("invalid video key format: %s", key)
+	}
+	return s.url(key), nil
+}
+
+// DownloadVideo opens a stored video by key for reading. The caller must close the reader.
+func (s *Service) DownloadVideo(_ context.Context, key string) (io.ReadCloser, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stored video: %w", err)
+	}
+	return reader, nil
+}
+
+// OpenVideo opens a stored video by key for reading at any offset
+func (s *Service) OpenVideo(_ context.Context, key string) (videostorage.Object, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", videostorage.ErrNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to open stored video: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open stored video: %w", err)
+	}
+	return &object{File: file, info: info}, nil
+}
+
+// object is an open stored file
+type object struct {
+	*os.File
+	info fs.FileInfo
+}
+
+func (o *object) Size() int64        { return o.info.Size() }
+func (o *object) ModTime() time.Time { return o.info.ModTime() }
+
+// DeleteVideo deletes a video and its transcoded versions
+func (s *Service) DeleteVideo(_ context.Context, videoID uuid.UUID) error {
+	dir, err := s.path(path.Join(s.rootDir(), videoID.String()))
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to delete video files: video_id=%s", videoID))
+		return fmt.Errorf("failed to delete video files: %w", err)
+	}
+
+	s.logger.LogInfo("Deleted video files from local filesystem", map[string]interface{}{
+		"video_id": videoID,
+	})
+	return nil
+}
+
+// ArchiveVideo copies the files in a video's directory into the version's
+// directory. Files are hard-linked where possible; since writes replace files
+// by renaming, the archived copies keep their content.
+func (s *Service) ArchiveVideo(ctx context.Context, videoID uuid.UUID, version int) error {
+	videoDir := path.Join(s.rootDir(), videoID.String())
+	dir, err := s.path(videoDir)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to list video files: %w", err)
+	}
+
+	versionDir := path.Join(videoDir, videostorage.VersionDir(version))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		source := filepath.Join(dir, entry.Name())
+		target, err := s.path(path.Join(versionDir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.Link(source, target); err == nil || errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err := s.copyFile(ctx, source, path.Join(versionDir, entry.Name())); err != nil {
+			s.logger.LogError(err, fmt.Sprintf("Failed to archive video file: video_id=%s, file=%s", videoID, entry.Name()))
+			return fmt.Errorf("failed to archive video files: %w", err)
+		}
+	}
+
+	s.logger.LogInfo("Archived video files on local filesystem", map[string]interface{}{
+		"video_id": videoID,
+		"version":  version,
+	})
+	return nil
+}
+
+// copyFile writes a copy of the file at source under key
+func (s *Service) copyFile(ctx context.Context, source, key string) error {
+	file, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return s.write(ctx, key, file)
+}
+
+// DeleteVideoVersion deletes the files archived for a version
+func (s *Service) DeleteVideoVersion(_ context.Context, videoID uuid.UUID, version int) error {
+	dir, err := s.path(path.Join(s.rootDir(), videoID.String(), videostorage.VersionDir(version)))
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to delete video version files: video_id=%s, version=%d", videoID, version))
+		return fmt.Errorf("failed to delete video version files: %w", err)
+	}
+	return nil
+}
+
+// UploadCaption stores a file of a video's captions and returns its key
+func (s *Service) UploadCaption(ctx context.Context, videoID uuid.UUID, fileName, _ string, reader io.Reader) (string, error) {
+	key := path.Join(s.rootDir(), videoID.String(), videostorage.CaptionFile(fileName))
+	if err := s.write(ctx, key, reader); err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to store caption file: video_id=%s, key=%s", videoID, key))
+		return "", fmt.Errorf("failed to store caption file: %w", err)
+	}
+	return key, nil
+}
+
+// UploadAvatar stores a user avatar image and returns its key
+func (s *Service) UploadAvatar(ctx context.Context, userID uuid.UUID, ext string, _ string, reader io.Reader) (string, error) {
+	// Each upload gets a new key so cached copies of the old avatar are never served
+	key := path.Join("avatars", userID.String(), uuid.New().String()+ext)
+	if err := s.write(ctx, key, reader); err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to store avatar: user_id=%s, key=%s", userID, key))
+		return "", fmt.Errorf("failed to store avatar: %w", err)
+	}
+	return key, nil
+}
+
+// GetAvatarURL returns the URL a stored avatar is served from
+func (s *Service) GetAvatarURL(_ context.Context, key string) (string, error) {
+	if !strings.HasPrefix(key, "avatars/") {
+		return "", fmt.Errorf("invalid avatar key format: %s", key)
+	}
+	return s.url(key), nil
+}
+
+// Ping checks that the storage directory exists and is writable
+func (s *Service) Ping(_ context.Context) error {
+	file, err := os.CreateTemp(s.config.Dir, ".ping-*")
+	if err != nil {
+		return fmt.Errorf("local storage directory %s is not writable: %w", s.config.Dir, err)
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// Close implements the storage.Service interface
+func (s *Service) Close() error {
+	return nil
+}
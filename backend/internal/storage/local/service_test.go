@@ -0,0 +1,186 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+)
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	service, err := NewService(&Config{
+		Dir:     t.TempDir(),
+		BaseURL: "http://localhost:8080/storage/",
+	}, testhelper.NewTestLogger(false))
+	require.NoError(t, err)
+	return service
+}
+
+func TestService_VideoRoundTrip(t *testing.T) {
+	service := newTestService(t)
+	videoID := uuid.New()
+	content := []byte("not really a video")
+
+	var stored, total int64
+	ctx := videostorage.WithProgress(context.Background(), func(n, size int64) {
+		stored, total = n, size
+	})
+
+	key, err := service.UploadVideo(ctx, videoID, "720p", bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, "videos/"+videoID.String()+"/720p.mp4", key, "keys match the S3 layout")
+	assert.Equal(t, int64(len(content)), stored)
+	assert.Equal(t, int64(len(content)), total)
+
+	url, err := service.GetVideoURL(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/storage/"+key, url)
+
+	reader, err := service.DownloadVideo(context.Background(), key)
+	require.NoError(t, err)
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+
+	require.NoError(t, service.DeleteVideo(context.Background(), videoID))
+	_, err = service.DownloadVideo(context.Background(), key)
+	assert.Error(t, err)
+}
+
+func TestService_OpenVideo(t *testing.T) {
+	service := newTestService(t)
+
+	key, err := service.UploadVideo(context.Background(), uuid.New(), "480p", bytes.NewReader([]byte("0123456789")))
+	require.NoError(t, err)
+
+	object, err := service.OpenVideo(context.Background(), key)
+	require.NoError(t, err)
+	defer object.Close()
+	assert.Equal(t, int64(10), object.Size())
+
+	_, err = object.Seek(6, io.SeekStart)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(object)
+	require.NoError(t, err)
+	assert.Equal(t, "6789", string(rest))
+
+	_, err = service.OpenVideo(context.Background(), "videos/missing/480p.mp4")
+	assert.ErrorIs(t, err, videostorage.ErrNotFound)
+}
+
+func TestService_UploadVideo_RenditionFileNames(t *testing.T) {
+	service := newTestService(t)
+	videoID := uuid.New()
+
+	key, err := service.UploadVideo(context.Background(), videoID, videostorage.RenditionAudio, bytes.NewReader([]byte("m4a")))
+	require.NoError(t, err)
+	assert.Equal(t, "videos/"+videoID.String()+"/audio.m4a", key)
+
+	key, err = service.UploadVideo(context.Background(), videoID, videostorage.RenditionPreview, bytes.NewReader([]byte("webp")))
+	require.NoError(t, err)
+	assert.Equal(t, "videos/"+videoID.String()+"/preview.webp", key)
+
+	_, err = service.UploadVideo(context.Background(), videoID, "1080p", bytes.NewReader([]byte("mp4")))
+	assert.Error(t, err)
+}
+
+func TestService_RejectsKeysOutsideDir(t *testing.T) {
+	service := newTestService(t)
+
+	for _, key := range []string{"../secret", "videos/../../secret", "/etc/passwd"} {
+		_, err := service.DownloadVideo(context.Background(), key)
+		assert.Error(t, err, key)
+	}
+}
+
+func TestService_CancelledUploadLeavesNoFile(t *testing.T) {
+	service := newTestService(t)
+	videoID := uuid.New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := service.UploadVideo(ctx, videoID, "original", bytes.NewReader(make([]byte, 1024)))
+	require.ErrorIs(t, err, context.Canceled)
+
+	entries, err := os.ReadDir(filepath.Join(service.config.Dir, "videos", videoID.String()))
+	require.NoError(t, err)
+	assert.Empty(t, entries, "partial files are removed")
+}
+
+func TestService_Avatar(t *testing.T) {
+	service := newTestService(t)
+
+	key, err := service.UploadAvatar(context.Background(), uuid.New(), ".png", "image/png", bytes.NewReader([]byte("png")))
+	require.NoError(t, err)
+
+	url, err := service.GetAvatarURL(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/storage/"+key, url)
+
+	_, err = service.GetAvatarURL(context.Background(), "videos/other.mp4")
+	assert.Error(t, err)
+}
+
+func TestService_UploadCaption(t *testing.T) {
+	service := newTestService(t)
+	videoID := uuid.New()
+
+	key, err := service.UploadCaption(context.Background(), videoID, "pt-BR.vtt", "text/vtt", bytes.NewReader([]byte("WEBVTT\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "videos/"+videoID.String()+"/captions/pt-BR.vtt", key)
+
+	url, err := service.GetVideoURL(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/storage/"+key, url)
+
+	// Captions go with the rest of the video's files
+	require.NoError(t, service.DeleteVideo(context.Background(), videoID))
+	_, err = service.DownloadVideo(context.Background(), key)
+	assert.Error(t, err)
+}
+
+func TestService_Ping(t *testing.T) {
+	service := newTestService(t)
+	assert.NoError(t, service.Ping(context.Background()))
+}
+
+func TestService_ArchiveVideo(t *testing.T) {
+	service := newTestService(t)
+	videoID := uuid.New()
+
+	key, err := service.UploadVideo(context.Background(), videoID, "original", bytes.NewReader([]byte("first")))
+	require.NoError(t, err)
+	require.NoError(t, service.ArchiveVideo(context.Background(), videoID, 1))
+
+	// Replacing the file leaves the archived copy as it was
+	_, err = service.UploadVideo(context.Background(), videoID, "original", bytes.NewReader([]byte("second")))
+	require.NoError(t, err)
+
+	archived := "videos/" + videoID.String() + "/versions/1/original.mp4"
+	for want, key := range map[string]string{"second": key, "first": archived} {
+		reader, err := service.DownloadVideo(context.Background(), key)
+		require.NoError(t, err)
+		got, err := io.ReadAll(reader)
+		reader.Close()
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	}
+
+	require.NoError(t, service.DeleteVideoVersion(context.Background(), videoID, 1))
+	_, err = service.DownloadVideo(context.Background(), archived)
+	assert.Error(t, err)
+	_, err = service.DownloadVideo(context.Background(), key)
+	assert.NoError(t, err, "the current files are kept")
+}
@@ -0,0 +1,217 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/google/uuid"
+)
+
+// selfTestPrefix is where the bootstrap self-test writes its probe object
+const selfTestPrefix = ".pavilion-selftest/"
+
+// BootstrapOptions configures the storage bootstrap
+type BootstrapOptions struct {
+	// CORSAllowedOrigins are the origins allowed to fetch objects directly; no CORS rules are set when empty
+	CORSAllowedOrigins []string
+	// AbortIncompleteUploadDays removes unfinished multipart uploads after this many days; 0 disables the rule
+	AbortIncompleteUploadDays int
+}
+
+// Bootstrap prepares the configured bucket for use: it creates the bucket if it
+// does not exist, applies CORS and lifecycle rules and runs a write/read/delete
+// self-test. It is meant for MinIO and local development, where a missing
+// bucket or wrong credentials otherwise only show up as an opaque error on the
+// first upload.
+func (s *S3Service) Bootstrap(ctx context.Context, opts BootstrapOptions) error {
+	s.logger.LogInfo("Bootstrapping S3 storage", map[string]interface{}{
+		"endpoint": s.config.Endpoint,
+		"bucket":   s.config.Bucket,
+	})
+
+	if s.config.Bucket == "" {
+		return fmt.Errorf("S3 bootstrap failed: no bucket configured")
+	}
+
+	if err := s.ensureBucket(ctx); err != nil {
+		return err
+	}
+
+	if len(opts.CORSAllowedOrigins) > 0 {
+		_, err := s.client.PutBucketCors(ctx, &s3.PutBucketCorsInput{
+			Bucket: aws.String(s.config.Bucket),
+			CORSConfiguration: &types.CORSConfiguration{
+				CORSRules: []types.CORSRule{{
+					AllowedOrigins: opts.CORSAllowedOrigins,
+					AllowedMethods: []string{"GET", "HEAD", "PUT"},
+					AllowedHeaders: []string{"*"},
+					ExposeHeaders:  []string{"ETag", "Content-Length", "Content-Range"},
+					MaxAgeSeconds:  aws.Int32(3600),
+				}},
+			},
+		})
+		// MinIO configures CORS server-wide and rejects the bucket-level call
+		if err != nil && !isNotImplemented(err) {
+			return fmt.Errorf("S3 bootstrap failed to set CORS rules (%s): %w", describeS3Error(err, s.config.Bucket), err)
+		}
+		if err != nil {
+			s.logger.LogWarn("Storage does not support bucket CORS rules; configure CORS on the server instead", map[string]interface{}{
+				"bucket": s.config.Bucket,
+			})
+		}
+	}
+
+	if opts.AbortIncompleteUploadDays > 0 {
+		_, err := s.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket: aws.String(s.config.Bucket),
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+				Rules: []types.LifecycleRule{{
+					ID:     aws.String("pavilion-abort-incomplete-uploads"),
+					Status: types.ExpirationStatusEnabled,
+					Filter: &types.LifecycleRuleFilter{Prefix: aws.String("")},
+					AbortIncompleteMultipartUpload: &types.AbortIncompleteMultipartUpload{
+						DaysAfterInitiation: aws.Int32(int32(opts.AbortIncompleteUploadDays)),
+					},
+				}},
+			},
+		})
+		if err != nil && !isNotImplemented(err) {
+			return fmt.Errorf("S3 bootstrap failed to set lifecycle rules (%s): %w", describeS3Error(err, s.config.Bucket), err)
+		}
+		if err != nil {
+			s.logger.LogWarn("Storage does not support bucket lifecycle rules", map[string]interface{}{
+				"bucket": s.config.Bucket,
+			})
+		}
+	}
+
+	if err := s.SelfTest(ctx); err != nil {
+		return err
+	}
+
+	s.logger.LogInfo("S3 storage bootstrap completed", map[string]interface{}{
+		"bucket": s.config.Bucket,
+	})
+	return nil
+}
+
+// SelfTest writes, reads back and deletes a small object to check that the
+// credentials can use the bucket
+func (s *S3Service) SelfTest(ctx context.Context) error {
+	key := selfTestPrefix + uuid.New().String()
+	payload := []byte("pavilion storage self-test")
+
+	if _, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(payload),
+	}); err != nil {
+		return fmt.Errorf("S3 self-test failed to write (%s): %w", describeS3Error(err, s.config.Bucket), err)
+	}
+
+	// Always try to clean up, even when the read fails
+	defer func() {
+		if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(s.config.Bucket),
+			Key:    aws.String(key),
+		}); err != nil {
+			s.logger.LogWarn("S3 self-test failed to delete its probe object", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("S3 self-test failed to read (%s): %w", describeS3Error(err, s.config.Bucket), err)
+	}
+	defer result.Body.Close()
+
+	got, err := io.ReadAll(result.Body)
+	if err != nil {
+		return fmt.Errorf("S3 self-test failed to read: %w", err)
+	}
+	if !bytes.Equal(got, payload) {
+		return fmt.Errorf("S3 self-test read back different content than it wrote")
+	}
+
+	return nil
+}
+
+// ensureBucket creates the configured bucket unless it already exists
+func (s *S3Service) ensureBucket(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.config.Bucket)})
+	if err == nil {
+		return nil
+	}
+
+	var notFound *types.NotFound
+	if !errors.As(err, &notFound) {
+		return fmt.Errorf("S3 bootstrap failed to check bucket (%s): %w", describeS3Error(err, s.config.Bucket), err)
+	}
+
+	input := &s3.CreateBucketInput{Bucket: aws.String(s.config.Bucket)}
+	// us-east-1 is the default location and must not be sent as a constraint
+	if s.config.Region != "" && s.config.Region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(s.config.Region),
+		}
+	}
+
+	if _, err := s.client.CreateBucket(ctx, input); err != nil {
+		var owned *types.BucketAlreadyOwnedByYou
+		if errors.As(err, &owned) {
+			return nil
+		}
+		return fmt.Errorf("S3 bootstrap failed to create bucket (%s): %w", describeS3Error(err, s.config.Bucket), err)
+	}
+
+	s.logger.LogInfo("Created S3 bucket", map[string]interface{}{
+		"bucket": s.config.Bucket,
+		"region": s.config.Region,
+	})
+	return nil
+}
+
+// describeS3Error turns common S3 error codes into a short hint about what to fix
+func describeS3Error(err error, bucket string) string {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		if strings.Contains(err.Error(), "connection refused") || strings.Contains(err.Error(), "no such host") {
+			return "storage endpoint is unreachable"
+		}
+		return "unknown error"
+	}
+
+	switch apiErr.ErrorCode() {
+	case "InvalidAccessKeyId":
+		return "invalid access key ID"
+	case "SignatureDoesNotMatch":
+		return "signature mismatch (check secret key)"
+	case "NoSuchBucket", "NotFound":
+		return fmt.Sprintf("bucket '%s' does not exist", bucket)
+	case "PermanentRedirect", "AuthorizationHeaderMalformed":
+		return "bucket is in a different region than configured"
+	case "AccessDenied", "Forbidden":
+		return "access denied (check permissions)"
+	}
+	return apiErr.ErrorCode()
+}
+
+// isNotImplemented reports whether the storage does not support an operation
+func isNotImplemented(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "NotImplemented"
+}
@@ -0,0 +1,250 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+)
+
+const (
+	// minPartSize is the smallest part S3 accepts, except for the last one
+	minPartSize = 5 << 20
+	// defaultPartSize is used when no part size is configured
+	defaultPartSize = 16 << 20
+	// maxParts is the most parts S3 accepts for one object
+	maxParts = 10000
+	// defaultUploadConcurrency is used when no concurrency is configured
+	defaultUploadConcurrency = 4
+	// abortTimeout bounds aborting a failed multipart upload, which runs even
+	// when the upload was cancelled
+	abortTimeout = 30 * time.Second
+)
+
+// partSize returns the part size for an upload of size bytes, or of unknown
+// size when size is negative. Known sizes get parts large enough to stay
+// within the part limit.
+func (s *S3Service) partSize(size int64) int64 {
+	partSize := s.config.PartSize
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+	partSize = max(partSize, minPartSize)
+	if size > partSize*maxParts {
+		partSize = (size + maxParts - 1) / maxParts
+	}
+	return partSize
+}
+
+func (s *S3Service) uploadConcurrency() int {
+	if s.config.UploadConcurrency < 1 {
+		return defaultUploadConcurrency
+	}
+	return s.config.UploadConcurrency
+}
+
+// putObject stores body under key. Bodies that fit in one part are sent in a
+// single request; larger ones are sent as a multipart upload with parts
+// uploaded in parallel. size is the length of body, or -1 if unknown; an
+// empty contentType leaves the object's type to S3.
+// Progress is reported to the callback set with videostorage.WithProgress.
+func (s *S3Service) putObject(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	progress := videostorage.ProgressFromContext(ctx)
+	partSize := s.partSize(size)
+
+	first := make([]byte, partSize)
+	n, err := io.ReadFull(body, first)
+	switch {
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        aws.String(s.config.Bucket),
+			Key:           aws.String(key),
+			Body:          bytes.NewReader(first[:n]),
+			ContentLength: aws.Int64(int64(n)),
+			ContentType:   optional(contentType),
+		})
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(int64(n), int64(n))
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	return s.multipartUpload(ctx, key, contentType, first, body, partSize, size, progress)
+}
+
+// optional returns a pointer to value, or nil when it is empty
+func optional(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return aws.String(value)
+}
+
+// multipartUpload sends first and the rest of body as a multipart upload.
+// A failed upload is aborted so its parts are not kept; if aborting fails
+// too, the bucket's lifecycle rule removes them later.
+func (s *S3Service) multipartUpload(ctx context.Context, key, contentType string, first []byte, body io.Reader, partSize, size int64, progress videostorage.ProgressFunc) error {
+	created, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.config.Bucket),
+		Key:         aws.String(key),
+		ContentType: optional(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+	uploadID := aws.ToString(created.UploadId)
+
+	s.logger.LogInfo("Started multipart upload", map[string]interface{}{
+		"key":         key,
+		"upload_id":   uploadID,
+		"part_size":   partSize,
+		"concurrency": s.uploadConcurrency(),
+	})
+
+	parts, err := s.uploadParts(ctx, key, uploadID, first, body, partSize, size, progress)
+	if err == nil {
+		_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(s.config.Bucket),
+			Key:             aws.String(key),
+			UploadId:        aws.String(uploadID),
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		if err != nil {
+			err = fmt.Errorf("failed to complete multipart upload: %w", err)
+		}
+	}
+	if err != nil {
+		s.abortMultipartUpload(ctx, key, uploadID)
+		return err
+	}
+	return nil
+}
+
+// abortMultipartUpload discards an unfinished upload's parts. It runs even
+// if ctx is cancelled, since a cancelled upload is the common reason to abort.
+func (s *S3Service) abortMultipartUpload(ctx context.Context, key, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortTimeout)
+	defer cancel()
+
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.config.Bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to abort multipart upload, the bucket lifecycle rule will remove it: key=%s, upload_id=%s",
+			key, uploadID))
+		return
+	}
+	s.logger.LogInfo("Aborted multipart upload", map[string]interface{}{
+		"key":       key,
+		"upload_id": uploadID,
+	})
+}
+
+// uploadPart is one part of a multipart upload waiting to be sent
+type uploadPart struct {
+	number int32
+	data   []byte
+}
+
+// uploadParts reads body in partSize chunks after first and uploads them
+// with a pool of workers. Only the parts being sent and the one being read
+// are held in memory. The first error stops the upload.
+func (s *S3Service) uploadParts(ctx context.Context, key, uploadID string, first []byte, body io.Reader, partSize, size int64, progress videostorage.ProgressFunc) ([]types.CompletedPart, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		mu        sync.Mutex
+		completed []types.CompletedPart
+		stored    int64
+		wg        sync.WaitGroup
+	)
+
+	queue := make(chan uploadPart)
+	for i := 0; i < s.uploadConcurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range queue {
+				out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+					Bucket:        aws.String(s.config.Bucket),
+					Key:           aws.String(key),
+					UploadId:      aws.String(uploadID),
+					PartNumber:    aws.Int32(part.number),
+					Body:          bytes.NewReader(part.data),
+					ContentLength: aws.Int64(int64(len(part.data))),
+				})
+				if err != nil {
+					cancel(fmt.Errorf("failed to upload part %d: %w", part.number, err))
+					continue
+				}
+
+				mu.Lock()
+				completed = append(completed, types.CompletedPart{
+					ETag:       out.ETag,
+					PartNumber: aws.Int32(part.number),
+				})
+				stored += int64(len(part.data))
+				if progress != nil {
+					progress(stored, size)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	readErr := func() error {
+		defer close(queue)
+		part := uploadPart{number: 1, data: first}
+		for {
+			select {
+			case queue <- part:
+			case <-ctx.Done():
+				return nil
+			}
+
+			data := make([]byte, partSize)
+			n, err := io.ReadFull(body, data)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("failed to read upload: %w", err)
+			}
+			if part.number == maxParts {
+				return fmt.Errorf("upload exceeds %d parts of %d bytes", maxParts, partSize)
+			}
+			part = uploadPart{number: part.number + 1, data: data[:n]}
+		}
+	}()
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	// S3 requires the parts in ascending order
+	slices.SortFunc(completed, func(a, b types.CompletedPart) int {
+		return int(aws.ToInt32(a.PartNumber) - aws.ToInt32(b.PartNumber))
+	})
+	return completed, nil
+}
@@ -0,0 +1,183 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) LogInfo(string, map[string]interface{})                {}
+func (nopLogger) LogError(err error, _ string) error                    { return err }
+func (nopLogger) LogErrorf(err error, _ string, _ ...interface{}) error { return err }
+func (nopLogger) LogFatal(error, string)                                {}
+func (nopLogger) LogDebug(string, map[string]interface{})               {}
+func (nopLogger) LogWarn(string, map[string]interface{})                {}
+func (l nopLogger) WithFields(map[string]interface{}) logger.Logger     { return l }
+func (l nopLogger) WithContext(context.Context) logger.Logger           { return l }
+func (l nopLogger) WithRequestID(string) logger.Logger                  { return l }
+func (l nopLogger) WithUserID(string) logger.Logger                     { return l }
+
+// fakeS3 records the requests of single and multipart uploads
+type fakeS3 struct {
+	mu        sync.Mutex
+	puts      int
+	parts     map[int]int // part number to size
+	completed []int       // part numbers in the order they were completed
+	aborted   bool
+	failPart  int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		fmt.Fprint(w, `<InitiateMultipartUploadResult><Bucket>test</Bucket><Key>k</Key><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`)
+
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		body, _ := io.ReadAll(r.Body)
+		if number == f.failPart {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`)
+			return
+		}
+		f.parts[number] = len(body)
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var req struct {
+			Parts []struct {
+				PartNumber int
+			} `xml:"Part"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		_ = xml.Unmarshal(body, &req)
+		for _, part := range req.Parts {
+			f.completed = append(f.completed, part.PartNumber)
+		}
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>test</Bucket><Key>k</Key><ETag>"done"</ETag></CompleteMultipartUploadResult>`)
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		f.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPut:
+		_, _ = io.Copy(io.Discard, r.Body)
+		f.puts++
+		w.Header().Set("ETag", `"single"`)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func newTestService(t *testing.T, fake *fakeS3) *S3Service {
+	t.Helper()
+	fake.parts = make(map[int]int)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	service, err := NewService(&videostorage.Config{
+		Endpoint:          server.URL,
+		AccessKeyID:       "test",
+		SecretAccessKey:   "test",
+		Region:            "us-east-1",
+		Bucket:            "test",
+		PartSize:          minPartSize,
+		UploadConcurrency: 3,
+	}, nopLogger{})
+	require.NoError(t, err)
+	return service
+}
+
+func TestUploadVideo_Multipart(t *testing.T) {
+	fake := &fakeS3{}
+	service := newTestService(t, fake)
+
+	size := 2*minPartSize + 1024
+	var progress []int64
+	ctx := videostorage.WithProgress(context.Background(), func(stored, total int64) {
+		assert.Equal(t, int64(size), total)
+		progress = append(progress, stored)
+	})
+
+	_, err := service.UploadVideo(ctx, uuid.New(), "original", bytes.NewReader(make([]byte, size)))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[int]int{1: minPartSize, 2: minPartSize, 3: 1024}, fake.parts)
+	assert.Equal(t, []int{1, 2, 3}, fake.completed, "parts are completed in order")
+	assert.False(t, fake.aborted)
+	assert.Zero(t, fake.puts)
+	require.Len(t, progress, 3)
+	assert.Equal(t, int64(size), progress[2])
+}
+
+func TestUploadVideo_MultipartUnknownSize(t *testing.T) {
+	fake := &fakeS3{}
+	service := newTestService(t, fake)
+
+	// A plain reader hides the size, so parts are read until EOF
+	body := io.LimitReader(bytes.NewReader(make([]byte, 3*minPartSize)), 3*minPartSize)
+	_, err := service.UploadVideo(context.Background(), uuid.New(), "720p", body)
+	require.NoError(t, err)
+
+	assert.Len(t, fake.parts, 3)
+	assert.Equal(t, []int{1, 2, 3}, fake.completed)
+}
+
+func TestUploadVideo_MultipartFailureAborts(t *testing.T) {
+	fake := &fakeS3{failPart: 2}
+	service := newTestService(t, fake)
+
+	_, err := service.UploadVideo(context.Background(), uuid.New(), "original", bytes.NewReader(make([]byte, 3*minPartSize)))
+	require.Error(t, err)
+
+	assert.True(t, fake.aborted, "a failed upload is aborted")
+	assert.Empty(t, fake.completed)
+}
+
+func TestUploadVideo_SmallFileSingleRequest(t *testing.T) {
+	fake := &fakeS3{}
+	service := newTestService(t, fake)
+
+	var stored int64
+	ctx := videostorage.WithProgress(context.Background(), func(n, _ int64) { stored = n })
+	_, err := service.UploadVideo(ctx, uuid.New(), "360p", bytes.NewReader(make([]byte, 1024)))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, fake.puts)
+	assert.Empty(t, fake.parts)
+	assert.Equal(t, int64(1024), stored)
+}
+
+func TestPartSize(t *testing.T) {
+	service := &S3Service{config: &videostorage.Config{}}
+	assert.Equal(t, int64(defaultPartSize), service.partSize(-1))
+
+	service.config.PartSize = 1 << 20
+	assert.Equal(t, int64(minPartSize), service.partSize(-1), "parts are at least the S3 minimum")
+
+	// Large files get bigger parts to stay within the part limit
+	size := int64(maxParts)*minPartSize + 1
+	assert.GreaterOrEqual(t, service.partSize(size)*maxParts, size)
+}
@@ -0,0 +1,94 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+)
+
+// objectServer serves one object, recording the Range header of each GET
+type objectServer struct {
+	mu      sync.Mutex
+	key     string
+	content []byte
+	ranges  []string
+}
+
+func (o *objectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/"+o.key) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Method == http.MethodGet {
+		o.mu.Lock()
+		o.ranges = append(o.ranges, r.Header.Get("Range"))
+		o.mu.Unlock()
+	}
+	http.ServeContent(w, r, "", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), bytes.NewReader(o.content))
+}
+
+func newObjectService(t *testing.T, server *objectServer) *S3Service {
+	t.Helper()
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	service, err := NewService(&videostorage.Config{
+		Endpoint:        httpServer.URL,
+		AccessKeyID:     "test",
+		SecretAccessKey: "test",
+		Region:          "us-east-1",
+		Bucket:          "test",
+	}, nopLogger{})
+	require.NoError(t, err)
+	return service
+}
+
+func TestOpenVideo_RangedReads(t *testing.T) {
+	server := &objectServer{key: "videos/v/720p.mp4", content: []byte("0123456789")}
+	service := newObjectService(t, server)
+
+	object, err := service.OpenVideo(context.Background(), server.key)
+	require.NoError(t, err)
+	defer object.Close()
+
+	assert.Equal(t, int64(10), object.Size())
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), object.ModTime().UTC())
+	assert.Empty(t, server.ranges, "nothing is fetched until read")
+
+	_, err = object.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(object, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "456", string(buf))
+
+	rest, err := io.ReadAll(object)
+	require.NoError(t, err)
+	assert.Equal(t, "789", string(rest), "reads continue from the open range")
+
+	_, err = object.Seek(-2, io.SeekEnd)
+	require.NoError(t, err)
+	tail, err := io.ReadAll(object)
+	require.NoError(t, err)
+	assert.Equal(t, "89", string(tail))
+
+	assert.Equal(t, []string{"bytes=4-", "bytes=8-"}, server.ranges)
+}
+
+func TestOpenVideo_NotFound(t *testing.T) {
+	service := newObjectService(t, &objectServer{key: "videos/v/720p.mp4"})
+
+	_, err := service.OpenVideo(context.Background(), "videos/v/480p.mp4")
+	assert.ErrorIs(t, err, videostorage.ErrNotFound)
+}
@@ -53,6 +53,9 @@ func NewService(cfg *videostorage.Config, logger logger.Logger) (*S3Service, err
 	// Create S3 client
 	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
 		o.UsePathStyle = true
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(endpointURL(cfg.Endpoint, cfg.UseSSL))
+		}
 	})
 
 	return &S3Service{
@@ -62,6 +65,17 @@ func NewService(cfg *videostorage.Config, logger logger.Logger) (*S3Service, err
 	}, nil
 }
 
+// endpointURL adds a scheme to endpoints given as host:port, e.g. "localhost:9000" for MinIO
+func endpointURL(endpoint string, useSSL bool) string {
+	if strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	if useSSL {
+		return "https://" + endpoint
+	}
+	return "http://" + endpoint
+}
+
 // UploadVideo uploads a video file to S3 with the standardized path structure
 func (s *S3Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resolution string, reader io.Reader) (string, error) {
 	// Log the beginning of the upload process
@@ -76,7 +90,7 @@ func (s *S3Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resoluti
 	})
 
 	// Validate resolution
-	if !videostorage.ValidateResolution(resolution) {
+	if !videostorage.ValidateRendition(resolution) {
 		errMsg := fmt.Sprintf("Invalid resolution for video upload: %s", resolution)
 		s.logger.LogError(nil, errMsg)
 		return "", fmt.Errorf("S3_UPLOAD_VALIDATION_ERROR: %s", errMsg)
@@ -95,8 +109,8 @@ func (s *S3Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resoluti
 		})
 	}
 
-	// Construct the standardized path: {root_dir}/{video_id}/[original|720p|480p|360p].mp4
-	key := fmt.Sprintf("%s/%s/%s.mp4", rootDir, videoID, resolution)
+	// Construct the standardized path: {root_dir}/{video_id}/[original|720p|480p|360p].mp4, audio.m4a or preview.webp
+	key := fmt.Sprintf("%s/%s/%s", rootDir, videoID, videostorage.FileName(resolution))
 	
 	s.logger.LogInfo("Constructed S3 upload key", map[string]interface{}{
 		"video_id":   videoID,
@@ -115,7 +129,7 @@ func (s *S3Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resoluti
 			// Go to end to get total size
 			size, err := readSeeker.Seek(0, io.SeekEnd)
 			if err == nil {
-				contentLength = size
+				contentLength = size - currentPos
 				// Go back to original position
 				_, err = readSeeker.Seek(currentPos, io.SeekStart)
 				if err != nil {
@@ -138,37 +152,20 @@ func (s *S3Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resoluti
 		s.logger.LogInfo("Could not determine content length, uploading with unknown size", nil)
 	}
 
-	// Upload the file
-	s.logger.LogInfo("Sending PutObject request to S3", map[string]interface{}{
+	// Upload the file, in parts if it is larger than one
+	s.logger.LogInfo("Sending video to S3", map[string]interface{}{
 		"bucket": s.config.Bucket,
 		"key":    key,
 	})
-	
-	result, err := s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(s.config.Bucket),
-		Key:    aws.String(key),
-		Body:   reader,
-	})
-	
+
+	err := s.putObject(ctx, key, "", reader, contentLength)
+
 	if err != nil {
 		errMsg := fmt.Sprintf("Failed to upload video to S3: video_id=%s, resolution=%s, bucket=%s, key=%s",
 			videoID, resolution, s.config.Bucket, key)
 		s.logger.LogError(err, errMsg)
 		
-		// Attempt to provide more specific error information
-		errorDetails := "unknown error"
-		if strings.Contains(err.Error(), "InvalidAccessKeyId") {
-			errorDetails = "invalid access key ID"
-		} else if strings.Contains(err.Error(), "SignatureDoesNotMatch") {
-			errorDetails = "signature mismatch (check secret key)"
-		} else if strings.Contains(err.Error(), "NoSuchBucket") {
-			errorDetails = fmt.Sprintf("bucket '%s' does not exist", s.config.Bucket)
-		} else if strings.Contains(err.Error(), "PermanentRedirect") {
-			errorDetails = "bucket is in a different region than configured"
-		} else if strings.Contains(err.Error(), "AccessDenied") {
-			errorDetails = "access denied (check permissions)"
-		}
-		
+		errorDetails := describeS3Error(err, s.config.Bucket)
 		return "", fmt.Errorf("S3_UPLOAD_FAILED: %s (%s): %w", errMsg, errorDetails, err)
 	}
 
@@ -177,7 +174,6 @@ func (s *S3Service) UploadVideo(ctx context.Context, videoID uuid.UUID, resoluti
 		"resolution":   resolution,
 		"bucket":       s.config.Bucket,
 		"key":          key,
-		"etag":         result.ETag,
 		"full_path":    fmt.Sprintf("s3://%s/%s", s.config.Bucket, key),
 	})
 
@@ -212,6 +208,21 @@ func (s *S3Service) GetVideoURL(ctx context.Context, key string) (string, error)
 	return presignedURL.URL, nil
 }
 
+// DownloadVideo opens a stored video by key for reading. The caller must close the reader.
+func (s *S3Service) DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to download video from S3: bucket=%s, key=%s",
+			s.config.Bucket, key))
+		return nil, fmt.Errorf("failed to download video from S3: %w", err)
+	}
+
+	return result.Body, nil
+}
+
 // DeleteVideo deletes a video and its transcoded versions from S3
 func (s *S3Service) DeleteVideo(ctx context.Context, videoID uuid.UUID) error {
 	// Get the root directory, default to "videos" if not specified
@@ -263,6 +274,175 @@ func (s *S3Service) DeleteVideo(ctx context.Context, videoID uuid.UUID) error {
 	return nil
 }
 
+// ArchiveVideo copies the objects directly under a video's prefix into the
+// version's prefix
+func (s *S3Service) ArchiveVideo(ctx context.Context, videoID uuid.UUID, version int) error {
+	rootDir := "videos"
+	if s.config.RootDirectory != "" {
+		rootDir = s.config.RootDirectory
+	}
+	prefix := fmt.Sprintf("%s/%s/", rootDir, videoID)
+	versionPrefix := prefix + videostorage.VersionDir(version) + "/"
+
+	// The delimiter leaves out objects archived for earlier versions
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(s.config.Bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			s.logger.LogError(err, fmt.Sprintf("Failed to list objects for archiving: video_id=%s, prefix=%s",
+				videoID, prefix))
+			return fmt.Errorf("failed to list objects for archiving: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			key := versionPrefix + strings.TrimPrefix(*obj.Key, prefix)
+			_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+				Bucket:     aws.String(s.config.Bucket),
+				CopySource: aws.String(s.config.Bucket + "/" + *obj.Key),
+				Key:        aws.String(key),
+			})
+			if err != nil {
+				s.logger.LogError(err, fmt.Sprintf("Failed to archive object: video_id=%s, key=%s",
+					videoID, *obj.Key))
+				return fmt.Errorf("failed to archive video files: %w", err)
+			}
+		}
+	}
+
+	s.logger.LogInfo("Successfully archived video files in S3", map[string]interface{}{
+		"video_id": videoID,
+		"version":  version,
+		"prefix":   versionPrefix,
+	})
+
+	return nil
+}
+
+// DeleteVideoVersion deletes the objects archived for a version from S3
+func (s *S3Service) DeleteVideoVersion(ctx context.Context, videoID uuid.UUID, version int) error {
+	rootDir := "videos"
+	if s.config.RootDirectory != "" {
+		rootDir = s.config.RootDirectory
+	}
+	prefix := fmt.Sprintf("%s/%s/%s/", rootDir, videoID, videostorage.VersionDir(version))
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.Bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	var deleteErr error
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			s.logger.LogError(err, fmt.Sprintf("Failed to list objects for deletion: video_id=%s, prefix=%s",
+				videoID, prefix))
+			return fmt.Errorf("failed to list objects for deletion: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(s.config.Bucket),
+				Key:    obj.Key,
+			})
+			if err != nil {
+				s.logger.LogError(err, fmt.Sprintf("Failed to delete object: video_id=%s, key=%s",
+					videoID, *obj.Key))
+				deleteErr = err
+			}
+		}
+	}
+
+	if deleteErr != nil {
+		return fmt.Errorf("failed to delete some video version files: %w", deleteErr)
+	}
+
+	return nil
+}
+
+// UploadCaption uploads a file of a video's captions and returns its key.
+// Copies of the video with captions burned in can be large, so they are sent
+// in parts like videos.
+func (s *S3Service) UploadCaption(ctx context.Context, videoID uuid.UUID, fileName, contentType string, reader io.Reader) (string, error) {
+	rootDir := "videos"
+	if s.config.RootDirectory != "" {
+		rootDir = s.config.RootDirectory
+	}
+	key := fmt.Sprintf("%s/%s/%s", rootDir, videoID, videostorage.CaptionFile(fileName))
+
+	if err := s.putObject(ctx, key, contentType, reader, -1); err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to upload caption file to S3: video_id=%s, bucket=%s, key=%s",
+			videoID, s.config.Bucket, key))
+		return "", fmt.Errorf("failed to upload caption file to S3: %w", err)
+	}
+
+	s.logger.LogInfo("Successfully uploaded caption file to S3", map[string]interface{}{
+		"video_id": videoID,
+		"bucket":   s.config.Bucket,
+		"key":      key,
+	})
+	return key, nil
+}
+
+// UploadAvatar uploads a user avatar image and returns its key
+func (s *S3Service) UploadAvatar(ctx context.Context, userID uuid.UUID, ext string, contentType string, reader io.Reader) (string, error) {
+	// Each upload gets a new key so cached copies of the old avatar are never served
+	key := fmt.Sprintf("avatars/%s/%s%s", userID, uuid.New(), ext)
+
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.config.Bucket),
+		Key:         aws.String(key),
+		Body:        reader,
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to upload avatar to S3: user_id=%s, bucket=%s, key=%s",
+			userID, s.config.Bucket, key))
+		return "", fmt.Errorf("failed to upload avatar to S3: %w", err)
+	}
+
+	s.logger.LogInfo("Successfully uploaded avatar to S3", map[string]interface{}{
+		"user_id": userID,
+		"bucket":  s.config.Bucket,
+		"key":     key,
+	})
+
+	return key, nil
+}
+
+// GetAvatarURL returns a presigned URL for an avatar key
+func (s *S3Service) GetAvatarURL(ctx context.Context, key string) (string, error) {
+	if !strings.HasPrefix(key, "avatars/") {
+		return "", fmt.Errorf("invalid avatar key format: %s", key)
+	}
+
+	presignClient := s3.NewPresignClient(s.client)
+	presignedURL, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to generate presigned avatar URL: bucket=%s, key=%s",
+			s.config.Bucket, key))
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return presignedURL.URL, nil
+}
+
+// Ping checks that the bucket exists and is accessible with the configured credentials
+func (s *S3Service) Ping(ctx context.Context) error {
+	if _, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.config.Bucket)}); err != nil {
+		return fmt.Errorf("failed to access bucket %s: %w", s.config.Bucket, err)
+	}
+	return nil
+}
+
 // Close implements the storage.Service interface
 func (s *S3Service) Close() error {
 	// No need to close the S3 client
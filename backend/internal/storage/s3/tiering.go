@@ -0,0 +1,41 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// SetStorageClass moves a stored file to another storage class by copying it
+// onto itself, keeping its metadata. A single copy handles objects up to
+// 5 GB, more than any upload accepted.
+func (s *S3Service) SetStorageClass(ctx context.Context, key, class string) error {
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:            aws.String(s.config.Bucket),
+		CopySource:        aws.String(s.config.Bucket + "/" + key),
+		Key:               aws.String(key),
+		StorageClass:      types.StorageClass(class),
+		MetadataDirective: types.MetadataDirectiveCopy,
+	})
+	if err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to change storage class: key=%s, class=%s", key, class))
+		return fmt.Errorf("failed to change storage class (%s): %w", describeS3Error(err, s.config.Bucket), err)
+	}
+	return nil
+}
+
+// DeleteFile deletes a single stored file. Deleting a missing file succeeds.
+func (s *S3Service) DeleteFile(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to delete object: key=%s", key))
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
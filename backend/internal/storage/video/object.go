@@ -0,0 +1,20 @@
+package videostorage
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a stored file does not exist
+var ErrNotFound = errors.New("stored file not found")
+
+// Object is a stored file opened for reading from any offset, as needed to
+// serve HTTP range requests
+type Object interface {
+	io.ReadSeekCloser
+	// Size returns the length of the file in bytes
+	Size() int64
+	// ModTime returns when the file was last written
+	ModTime() time.Time
+}
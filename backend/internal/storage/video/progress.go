@@ -0,0 +1,23 @@
+package videostorage
+
+import "context"
+
+// ProgressFunc reports how many bytes of an upload have been stored so far.
+// total is -1 when the size of the upload is not known.
+type ProgressFunc func(stored, total int64)
+
+type progressKey struct{}
+
+// WithProgress returns a context under which UploadVideo reports its
+// progress to fn. fn may be called from several goroutines, but not
+// concurrently.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ProgressFromContext returns the progress callback set with WithProgress,
+// or nil if there is none
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
@@ -2,6 +2,7 @@ package videostorage
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/google/uuid"
@@ -15,6 +16,11 @@ type Service interface {
 	GetVideoURL(ctx context.Context, key string) (string, error)
 	// DeleteVideo deletes a video and its transcoded versions
 	DeleteVideo(ctx context.Context, videoID uuid.UUID) error
+	// ArchiveVideo copies a video's current files into the directory of a
+	// version, where they are kept when new files replace them
+	ArchiveVideo(ctx context.Context, videoID uuid.UUID, version int) error
+	// DeleteVideoVersion deletes the files archived for a version
+	DeleteVideoVersion(ctx context.Context, videoID uuid.UUID, version int) error
 	// Close closes any open connections
 	Close() error
 }
@@ -29,6 +35,12 @@ type Config struct {
 	Region          string `mapstructure:"region" yaml:"region"`
 	Bucket          string `mapstructure:"bucket" yaml:"bucket"`
 	RootDirectory   string `mapstructure:"rootDirectory" yaml:"root_directory"`
+
+	// PartSize is the size in bytes of each part of a multipart upload. Uploads
+	// that fit in one part are sent in a single request.
+	PartSize int64 `mapstructure:"partSize" yaml:"part_size"`
+	// UploadConcurrency is how many parts of one upload are sent in parallel
+	UploadConcurrency int `mapstructure:"uploadConcurrency" yaml:"upload_concurrency"`
 }
 
 // ValidateResolution checks if the resolution is valid
@@ -41,3 +53,42 @@ func ValidateResolution(resolution string) bool {
 	}
 	return validResolutions[resolution]
 }
+
+// Renditions stored next to the video resolutions
+const (
+	// RenditionAudio is the audio-only rendition, for low-bandwidth playback
+	RenditionAudio = "audio"
+	// RenditionPreview is the short animated preview shown in listings
+	RenditionPreview = "preview"
+)
+
+// ValidateRendition checks if name is a valid resolution or one of the
+// renditions that are not video
+func ValidateRendition(name string) bool {
+	return ValidateResolution(name) || name == RenditionAudio || name == RenditionPreview
+}
+
+// FileName returns the name a rendition is stored under in its video's
+// directory: audio.m4a, preview.webp, or the resolution with .mp4
+func FileName(rendition string) string {
+	switch rendition {
+	case RenditionAudio:
+		return rendition + ".m4a"
+	case RenditionPreview:
+		return rendition + ".webp"
+	default:
+		return rendition + ".mp4"
+	}
+}
+
+// VersionDir returns the directory, relative to its video's, that the files
+// of an archived version are kept in
+func VersionDir(version int) string {
+	return fmt.Sprintf("versions/%d", version)
+}
+
+// CaptionFile returns the path, relative to its video's directory, that a
+// caption track or a copy of the video with captions burned in is stored under
+func CaptionFile(name string) string {
+	return "captions/" + name
+}
@@ -0,0 +1,36 @@
+package sync
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a sync cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid sync cursor")
+
+// EncodeCursor returns the opaque cursor form of a point in time
+func EncodeCursor(t time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(t.UnixNano(), 10)))
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor. An empty cursor
+// means the client has never synced, so every change is returned.
+func DecodeCursor(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || nanos < 0 {
+		return time.Time{}, ErrInvalidCursor
+	}
+
+	return time.Unix(0, nanos).UTC(), nil
+}
@@ -0,0 +1,96 @@
+package sync
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxChangesLimit caps how many changes a client can request per call
+const maxChangesLimit = 500
+
+// Handler handles HTTP requests for sync endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new sync handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers all sync routes
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	syncGroup := router.Group("/sync")
+	syncGroup.Use(authMiddleware)
+	{
+		syncGroup.GET("/changes", h.handleGetChanges)
+	}
+}
+
+// @Summary Get changes since a cursor
+// @Description Returns a compact changefeed of the caller's own videos, notifications and follows since the given cursor, oldest first. Notifications appear when created and again when marked read; marking all read appears as a notification_read_state change carrying the read watermark and unread count. Following a user appears as a follow change with the user's ID, and unfollowing as its delete. Omit since on the first sync, then pass back nextCursor.
+// @Tags sync
+// @Produce json
+// @Security BearerAuth
+// @Param since query string false "Cursor returned as nextCursor by the previous sync"
+// @Param limit query int false "Maximum number of changes to return (default: 100, max: 500)"
+// @Success 200 {object} httpHandler.APIResponse{data=ChangeSet} "Changes retrieved successfully"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid query parameter"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /sync/changes [get]
+func (h *Handler) handleGetChanges(c *gin.Context) {
+	requestID, _ := c.Get("request_id")
+
+	// Get user ID from context (set by auth middleware)
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return
+	}
+
+	limit := 100
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsedLimit, err := strconv.Atoi(limitParam)
+		if err != nil || parsedLimit <= 0 || parsedLimit > maxChangesLimit {
+			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid limit parameter, must be an integer between 1 and 500", nil)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	changes, err := h.service.GetChanges(c.Request.Context(), userID, c.Query("since"), limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid since parameter", nil)
+			return
+		}
+		h.logger.LogInfo("Failed to get sync changes", map[string]interface{}{
+			"request_id": requestID,
+			"user_id":    userID.String(),
+			"error":      err.Error(),
+		})
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve changes", err)
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, changes, "Changes retrieved successfully")
+}
@@ -0,0 +1,23 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Source provides the changes for one entity type. New entity types join the
+// changefeed by implementing Source and being registered with the service.
+type Source interface {
+	// Entity returns the entity type this source reports on
+	Entity() EntityType
+	// Changes returns up to limit changes relevant to userID that happened after since, oldest first
+	Changes(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]Change, error)
+}
+
+// Service defines the interface for differential sync operations
+type Service interface {
+	// GetChanges returns the caller's changes after the given cursor
+	GetChanges(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*ChangeSet, error)
+}
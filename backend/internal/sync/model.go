@@ -0,0 +1,57 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EntityType identifies the kind of entity a change refers to
+// @Description Kind of entity a change refers to (e.g. video, notification)
+type EntityType string
+
+const (
+	EntityVideo        EntityType = "video"
+	EntityNotification EntityType = "notification"
+	// EntityFollow is a user the caller follows, with the followed user's ID
+	// as its ID; unfollows are deletes
+	EntityFollow EntityType = "follow"
+	// EntityNotificationReadState is the user's notification read state, with
+	// the user's ID as its ID
+	EntityNotificationReadState EntityType = "notification_read_state"
+)
+
+// Operation describes what happened to an entity
+// @Description Change operation: upsert (created or updated) or delete
+type Operation string
+
+const (
+	OperationUpsert Operation = "upsert"
+	OperationDelete Operation = "delete"
+)
+
+// Change is a single entry in a user's changefeed
+// @Description A single changed entity. Data is omitted for deletes.
+type Change struct {
+	// Kind of entity that changed
+	Entity EntityType `json:"entity" example:"video"`
+	// ID of the entity that changed
+	ID uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Whether the entity was created/updated or deleted
+	Op Operation `json:"op" example:"upsert"`
+	// When the change happened
+	ChangedAt time.Time `json:"changedAt" example:"2025-03-05T21:26:06Z"`
+	// Current state of the entity, omitted for deletes
+	Data interface{} `json:"data,omitempty"`
+}
+
+// ChangeSet is a page of changes returned by the sync endpoint
+// @Description A page of changes in the order they happened, with a cursor to resume from
+type ChangeSet struct {
+	// Changes in chronological order
+	Changes []Change `json:"changes"`
+	// Cursor to pass as since on the next request
+	NextCursor string `json:"nextCursor" example:"MTc0MTIwOTk2NjAwMDAwMDAwMA"`
+	// Whether more changes are immediately available
+	HasMore bool `json:"hasMore" example:"false"`
+}
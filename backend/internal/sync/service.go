@@ -0,0 +1,86 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+)
+
+// serviceImpl merges the changes reported by every registered source
+type serviceImpl struct {
+	sources []Source
+	logger  logger.Logger
+}
+
+// NewService creates a new sync service over the given sources
+func NewService(logger logger.Logger, sources ...Source) Service {
+	return &serviceImpl{
+		sources: sources,
+		logger:  logger,
+	}
+}
+
+// GetChanges returns up to limit changes after the cursor, oldest first
+func (s *serviceImpl) GetChanges(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*ChangeSet, error) {
+	since, err := DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+
+	// Ask every source for one more than a page so we know if anything is left
+	var all []Change
+	truncated := false
+	for _, source := range s.sources {
+		changes, err := source.Changes(ctx, userID, since, limit+1)
+		if err != nil {
+			s.logger.LogError(err, fmt.Sprintf("Failed to load %s changes", source.Entity()))
+			return nil, fmt.Errorf("failed to load %s changes: %w", source.Entity(), err)
+		}
+		if len(changes) > limit {
+			truncated = true
+		}
+		all = append(all, changes...)
+	}
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].ChangedAt.Before(all[j].ChangedAt)
+	})
+
+	// The cursor is a timestamp, so a page must never end in the middle of a
+	// group of changes that share one; otherwise the rest would be skipped.
+	page := all
+	if len(page) > limit {
+		end := limit
+		for end < len(all) && all[end].ChangedAt.Equal(all[limit-1].ChangedAt) {
+			end++
+		}
+		page = all[:end]
+	}
+
+	set := &ChangeSet{
+		Changes:    page,
+		NextCursor: cursor,
+		HasMore:    len(page) < len(all) || truncated,
+	}
+	if set.Changes == nil {
+		set.Changes = []Change{}
+	}
+	if len(page) > 0 {
+		set.NextCursor = EncodeCursor(page[len(page)-1].ChangedAt)
+	}
+
+	s.logger.LogInfo("Sync changes retrieved", map[string]interface{}{
+		"userID":  userID.String(),
+		"count":   len(set.Changes),
+		"hasMore": set.HasMore,
+	})
+
+	return set, nil
+}
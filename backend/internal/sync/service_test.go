@@ -0,0 +1,82 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource returns a fixed list of changes filtered by since
+type fakeSource struct {
+	entity  EntityType
+	changes []Change
+}
+
+func (f *fakeSource) Entity() EntityType { return f.entity }
+
+func (f *fakeSource) Changes(_ context.Context, _ uuid.UUID, since time.Time, limit int) ([]Change, error) {
+	var out []Change
+	for _, c := range f.changes {
+		if c.ChangedAt.After(since) && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func TestGetChangesMergesSourcesInOrder(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	videos := &fakeSource{entity: EntityVideo, changes: []Change{
+		{Entity: EntityVideo, ID: uuid.New(), Op: OperationUpsert, ChangedAt: base.Add(1 * time.Second)},
+		{Entity: EntityVideo, ID: uuid.New(), Op: OperationDelete, ChangedAt: base.Add(4 * time.Second)},
+	}}
+	notifications := &fakeSource{entity: EntityNotification, changes: []Change{
+		{Entity: EntityNotification, ID: uuid.New(), Op: OperationUpsert, ChangedAt: base.Add(2 * time.Second)},
+		{Entity: EntityNotification, ID: uuid.New(), Op: OperationUpsert, ChangedAt: base.Add(3 * time.Second)},
+	}}
+
+	service := NewService(testhelper.NewTestLogger(true), videos, notifications)
+
+	first, err := service.GetChanges(context.Background(), uuid.New(), "", 3)
+	require.NoError(t, err)
+	require.Len(t, first.Changes, 3)
+	assert.True(t, first.HasMore)
+	assert.Equal(t, EntityVideo, first.Changes[0].Entity)
+	assert.Equal(t, EntityNotification, first.Changes[1].Entity)
+
+	second, err := service.GetChanges(context.Background(), uuid.New(), first.NextCursor, 3)
+	require.NoError(t, err)
+	require.Len(t, second.Changes, 1)
+	assert.False(t, second.HasMore)
+	assert.Equal(t, OperationDelete, second.Changes[0].Op)
+
+	// Nothing new: the cursor stays where it was
+	third, err := service.GetChanges(context.Background(), uuid.New(), second.NextCursor, 3)
+	require.NoError(t, err)
+	assert.Empty(t, third.Changes)
+	assert.Equal(t, second.NextCursor, third.NextCursor)
+}
+
+func TestGetChangesDoesNotSplitTies(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	source := &fakeSource{entity: EntityVideo, changes: []Change{
+		{Entity: EntityVideo, ID: uuid.New(), ChangedAt: at},
+		{Entity: EntityVideo, ID: uuid.New(), ChangedAt: at},
+		{Entity: EntityVideo, ID: uuid.New(), ChangedAt: at.Add(time.Second)},
+	}}
+
+	set, err := NewService(testhelper.NewTestLogger(true), source).GetChanges(context.Background(), uuid.New(), "", 1)
+	require.NoError(t, err)
+	assert.Len(t, set.Changes, 2)
+	assert.True(t, set.HasMore)
+}
+
+func TestGetChangesRejectsInvalidCursor(t *testing.T) {
+	_, err := NewService(testhelper.NewTestLogger(true)).GetChanges(context.Background(), uuid.New(), "%%%", 10)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
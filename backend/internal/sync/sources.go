@@ -0,0 +1,233 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/follow"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// videoSource reports changes to videos owned by the user, including soft deletes
+type videoSource struct {
+	db *gorm.DB
+}
+
+// NewVideoSource creates a source for the caller's own videos
+func NewVideoSource(db *gorm.DB) Source {
+	return &videoSource{db: db}
+}
+
+// Entity returns the entity type this source reports on
+func (s *videoSource) Entity() EntityType {
+	return EntityVideo
+}
+
+// Changes returns videos created, updated or deleted after since
+func (s *videoSource) Changes(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]Change, error) {
+	var videos []video.Video
+
+	// Soft deletes only touch deleted_at, so it takes precedence as the change time
+	if err := s.db.WithContext(ctx).Unscoped().Preload("Upload").
+		Where("user_id = ?", userID).
+		Where("COALESCE(deleted_at, updated_at) > ?", since).
+		Order("COALESCE(deleted_at, updated_at) ASC").
+		Limit(limit).
+		Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to query video changes: %w", err)
+	}
+
+	changes := make([]Change, 0, len(videos))
+	for i := range videos {
+		v := &videos[i]
+		change := Change{
+			Entity:    EntityVideo,
+			ID:        v.ID,
+			Op:        OperationUpsert,
+			ChangedAt: v.UpdatedAt,
+		}
+		if v.DeletedAt.Valid {
+			change.Op = OperationDelete
+			change.ChangedAt = v.DeletedAt.Time
+		} else {
+			change.Data = v.ToVideoInfo()
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// notificationSource reports notifications delivered to the user, and those
+// the user marked read
+type notificationSource struct {
+	repository notification.NotificationRepository
+}
+
+// NewNotificationSource creates a source for the caller's notifications
+func NewNotificationSource(repository notification.NotificationRepository) Source {
+	return &notificationSource{repository: repository}
+}
+
+// Entity returns the entity type this source reports on
+func (s *notificationSource) Entity() EntityType {
+	return EntityNotification
+}
+
+// Changes returns notifications created after since, at their creation time,
+// and notifications marked read one by one after since, at their read time.
+// Marking all read moves the read watermark instead, which
+// readStateSource reports.
+func (s *notificationSource) Changes(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]Change, error) {
+	notifications, err := s.repository.GetNotificationsSince(ctx, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0, len(notifications))
+	created := make(map[uuid.UUID]bool, len(notifications))
+	for _, n := range notifications {
+		created[n.ID] = true
+		changes = append(changes, Change{
+			Entity:    EntityNotification,
+			ID:        n.ID,
+			Op:        OperationUpsert,
+			ChangedAt: n.CreatedAt,
+			Data:      n,
+		})
+	}
+
+	state, err := s.repository.GetReadState(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// Notifications created after since already carry their read mark
+	var receipts []notification.ReadReceipt
+	for _, receipt := range state.Read {
+		if receipt.ReadAt.After(since) && !created[receipt.NotificationID] {
+			receipts = append(receipts, receipt)
+		}
+	}
+	sort.Slice(receipts, func(i, j int) bool { return receipts[i].ReadAt.Before(receipts[j].ReadAt) })
+	if len(receipts) > limit {
+		receipts = receipts[:limit]
+	}
+	for _, receipt := range receipts {
+		n, err := s.repository.GetNotification(ctx, receipt.NotificationID)
+		if errors.Is(err, notification.ErrNotificationNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, Change{
+			Entity:    EntityNotification,
+			ID:        n.ID,
+			Op:        OperationUpsert,
+			ChangedAt: receipt.ReadAt,
+			Data:      n,
+		})
+	}
+
+	// Both lists stop after limit changes, so only the first limit of the
+	// merged list are sure to have nothing missing before them
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].ChangedAt.Before(changes[j].ChangedAt) })
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// readStateSource reports the user's notification read state when it moves,
+// so devices can update their read marks and unread badge after another
+// device marked notifications read
+type readStateSource struct {
+	repository notification.NotificationRepository
+}
+
+// NewReadStateSource creates a source for the caller's notification read state
+func NewReadStateSource(repository notification.NotificationRepository) Source {
+	return &readStateSource{repository: repository}
+}
+
+// Entity returns the entity type this source reports on
+func (s *readStateSource) Entity() EntityType {
+	return EntityNotificationReadState
+}
+
+// Changes returns the read state if the watermark moved or a notification
+// was marked read after since. The change time is the latest of these.
+func (s *readStateSource) Changes(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]Change, error) {
+	state, err := s.repository.GetReadState(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changedAt time.Time
+	if state.UpdatedAt != nil {
+		changedAt = *state.UpdatedAt
+	}
+	for _, receipt := range state.Read {
+		if receipt.ReadAt.After(changedAt) {
+			changedAt = receipt.ReadAt
+		}
+	}
+	if limit <= 0 || !changedAt.After(since) {
+		return nil, nil
+	}
+
+	return []Change{{
+		Entity:    EntityNotificationReadState,
+		ID:        userID,
+		Op:        OperationUpsert,
+		ChangedAt: changedAt,
+		Data:      state,
+	}}, nil
+}
+
+// followSource reports the users the caller follows, with unfollows as deletes
+type followSource struct {
+	follows follow.Service
+}
+
+// NewFollowSource creates a source for the users the caller follows
+func NewFollowSource(follows follow.Service) Source {
+	return &followSource{follows: follows}
+}
+
+// Entity returns the entity type this source reports on
+func (s *followSource) Entity() EntityType {
+	return EntityFollow
+}
+
+// Changes returns users followed or unfollowed after since
+func (s *followSource) Changes(ctx context.Context, userID uuid.UUID, since time.Time, limit int) ([]Change, error) {
+	follows, err := s.follows.FollowingChanges(ctx, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0, len(follows))
+	for i := range follows {
+		f := &follows[i]
+		change := Change{
+			Entity:    EntityFollow,
+			ID:        f.User.ID,
+			Op:        OperationUpsert,
+			ChangedAt: f.ChangedAt,
+		}
+		if f.Unfollowed {
+			change.Op = OperationDelete
+		} else {
+			change.Data = &f.User
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -0,0 +1,152 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/follow"
+	"github.com/consensuslabs/pavilion-network/backend/internal/notification"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotifications stores one user's notifications and read state; the
+// rest of the repository is not used by the sources
+type fakeNotifications struct {
+	notification.NotificationRepository
+	notifications []*notification.Notification
+	state         notification.ReadState
+}
+
+func (f *fakeNotifications) GetNotificationsSince(_ context.Context, _ uuid.UUID, since time.Time, limit int) ([]*notification.Notification, error) {
+	var out []*notification.Notification
+	for _, n := range f.notifications {
+		if n.CreatedAt.After(since) && len(out) < limit {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeNotifications) GetNotification(_ context.Context, id uuid.UUID) (*notification.Notification, error) {
+	for _, n := range f.notifications {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return nil, notification.ErrNotificationNotFound
+}
+
+func (f *fakeNotifications) GetReadState(context.Context, uuid.UUID) (*notification.ReadState, error) {
+	state := f.state
+	return &state, nil
+}
+
+func TestNotificationSourceReportsReads(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	readAt := base.Add(time.Hour)
+	older := &notification.Notification{ID: uuid.New(), CreatedAt: base, ReadAt: &readAt}
+	newer := &notification.Notification{ID: uuid.New(), CreatedAt: base.Add(2 * time.Hour)}
+	repo := &fakeNotifications{
+		notifications: []*notification.Notification{older, newer},
+		state: notification.ReadState{Read: []notification.ReadReceipt{
+			{NotificationID: older.ID, ReadAt: readAt},
+			{NotificationID: uuid.New(), ReadAt: readAt}, // deleted since
+		}},
+	}
+	source := NewNotificationSource(repo)
+
+	// A device that synced before the read learns of it at the read time
+	changes, err := source.Changes(context.Background(), uuid.New(), base.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, older.ID, changes[0].ID)
+	assert.Equal(t, readAt, changes[0].ChangedAt)
+	assert.Equal(t, older, changes[0].Data)
+	assert.Equal(t, newer.ID, changes[1].ID)
+
+	// A notification created after the cursor is reported once, read mark included
+	changes, err = source.Changes(context.Background(), uuid.New(), time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, base, changes[0].ChangedAt)
+
+	// Nothing left after the last change
+	changes, err = source.Changes(context.Background(), uuid.New(), newer.CreatedAt, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestReadStateSource(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	userID := uuid.New()
+	repo := &fakeNotifications{}
+	source := NewReadStateSource(repo)
+
+	changes, err := source.Changes(context.Background(), userID, time.Time{}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "nothing was ever marked read")
+
+	// Marking all read moves the watermark
+	watermark, movedAt := base, base.Add(time.Minute)
+	repo.state = notification.ReadState{LastReadAt: &watermark, UpdatedAt: &movedAt, UnreadCount: 2}
+	changes, err = source.Changes(context.Background(), userID, base, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, EntityNotificationReadState, changes[0].Entity)
+	assert.Equal(t, userID, changes[0].ID)
+	assert.Equal(t, movedAt, changes[0].ChangedAt)
+	assert.Equal(t, 2, changes[0].Data.(*notification.ReadState).UnreadCount)
+
+	// A later read of a single notification moves the change time
+	repo.state.Read = []notification.ReadReceipt{{NotificationID: uuid.New(), ReadAt: base.Add(time.Hour)}}
+	changes, err = source.Changes(context.Background(), userID, movedAt, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, base.Add(time.Hour), changes[0].ChangedAt)
+
+	changes, err = source.Changes(context.Background(), userID, base.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+// fakeFollows returns fixed follow changes; the rest of the service is not
+// used by the source
+type fakeFollows struct {
+	follow.Service
+	changes []follow.FollowChange
+}
+
+func (f *fakeFollows) FollowingChanges(_ context.Context, _ uuid.UUID, since time.Time, limit int) ([]follow.FollowChange, error) {
+	var out []follow.FollowChange
+	for _, c := range f.changes {
+		if c.ChangedAt.After(since) && len(out) < limit {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func TestFollowSource(t *testing.T) {
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	followed := follow.UserSummary{ID: uuid.New(), Username: "alice", FollowedAt: base}
+	unfollowed := follow.UserSummary{ID: uuid.New(), Username: "bob", FollowedAt: base}
+	source := NewFollowSource(&fakeFollows{changes: []follow.FollowChange{
+		{User: followed, ChangedAt: base},
+		{User: unfollowed, ChangedAt: base.Add(time.Hour), Unfollowed: true},
+	}})
+	assert.Equal(t, EntityFollow, source.Entity())
+
+	changes, err := source.Changes(context.Background(), uuid.New(), time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, Change{Entity: EntityFollow, ID: followed.ID, Op: OperationUpsert, ChangedAt: base, Data: &followed}, changes[0])
+	assert.Equal(t, Change{Entity: EntityFollow, ID: unfollowed.ID, Op: OperationDelete, ChangedAt: base.Add(time.Hour)}, changes[1])
+
+	changes, err = source.Changes(context.Background(), uuid.New(), base, 10)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OperationDelete, changes[0].Op)
+}
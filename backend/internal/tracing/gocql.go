@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	rowsAffected = attribute.Key("db.rows_affected")
+	cqlAttempt   = attribute.Key("db.cassandra.attempt")
+	cqlHost      = attribute.Key("db.cassandra.coordinator.host")
+	cqlRows      = attribute.Key("db.cassandra.rows")
+)
+
+// CQLObserver records a span for every ScyllaDB query and batch. gocql reports
+// queries after they finish, so spans are back-dated to the observed start.
+// Queries run with WithContext(ctx) join the trace in ctx.
+type CQLObserver struct{}
+
+// NewCQLObserver creates an observer for cluster.QueryObserver and cluster.BatchObserver
+func NewCQLObserver() *CQLObserver {
+	return &CQLObserver{}
+}
+
+// ObserveQuery implements gocql.QueryObserver
+func (o *CQLObserver) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	attrs := []attribute.KeyValue{
+		semconv.DBStatement(q.Statement),
+		cqlRows.Int(q.Rows),
+	}
+	o.record(ctx, cqlOperation(q.Statement), q.Keyspace, q.Host, q.Attempt, q.Start, q.End, q.Err, attrs)
+}
+
+// ObserveBatch implements gocql.BatchObserver
+func (o *CQLObserver) ObserveBatch(ctx context.Context, b gocql.ObservedBatch) {
+	attrs := []attribute.KeyValue{
+		semconv.DBStatement(strings.Join(b.Statements, "; ")),
+	}
+	o.record(ctx, "BATCH", b.Keyspace, b.Host, b.Attempt, b.Start, b.End, b.Err, attrs)
+}
+
+func (o *CQLObserver) record(ctx context.Context, operation, keyspace string, host *gocql.HostInfo, attempt int, start, end time.Time, err error, attrs []attribute.KeyValue) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	attrs = append(attrs,
+		semconv.DBSystemCassandra,
+		semconv.DBName(keyspace),
+		semconv.DBOperation(operation),
+		cqlAttempt.Int(attempt),
+	)
+	if host != nil {
+		attrs = append(attrs, cqlHost.String(host.ConnectAddress().String()))
+	}
+
+	_, span := Tracer().Start(ctx, "cql."+strings.ToLower(operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// cqlOperation returns the leading keyword of a CQL statement, such as SELECT
+func cqlOperation(statement string) string {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartProducer begins a span for publishing a message to topic. Call
+// InjectMessage with the returned context so consumers continue the trace.
+func StartProducer(ctx context.Context, topic string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("pulsar"),
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingOperationPublish,
+		),
+	)
+}
+
+// InjectMessage writes the trace context in ctx into the message properties
+func InjectMessage(ctx context.Context, msg *pulsar.ProducerMessage) {
+	if msg.Properties == nil {
+		msg.Properties = make(map[string]string)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Properties))
+}
+
+// ExtractMessage returns ctx carrying the trace context found in a received
+// message's properties
+func ExtractMessage(ctx context.Context, msg pulsar.Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Properties()))
+}
+
+// StartConsumer begins a span for processing msg as a child of the span that
+// published it
+func StartConsumer(ctx context.Context, msg pulsar.Message) (context.Context, trace.Span) {
+	ctx = ExtractMessage(ctx, msg)
+	return Tracer().Start(ctx, msg.Topic()+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String("pulsar"),
+			semconv.MessagingDestinationName(msg.Topic()),
+			semconv.MessagingOperationReceive,
+		),
+	)
+}
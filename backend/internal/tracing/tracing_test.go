@@ -0,0 +1,194 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apache/pulsar-client-go/pulsar"
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordSpans installs a tracer provider that keeps finished spans in memory
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	_, err := Init(context.Background(), Config{})
+	require.NoError(t, err)
+	return recorder
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestInitDisabledLeavesProviderAlone(t *testing.T) {
+	provider := otel.GetTracerProvider()
+
+	shutdown, err := Init(context.Background(), Config{Enabled: false})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Equal(t, provider, otel.GetTracerProvider())
+	assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+}
+
+func TestEndRecordsError(t *testing.T) {
+	recorder := recordSpans(t)
+
+	_, span := Start(context.Background(), "work")
+	End(span, errors.New("boom"))
+	_, span = Start(context.Background(), "ok")
+	End(span, nil)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "boom", spans[0].Status().Description)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestPulsarMessageCarriesTraceContext(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx, span := StartProducer(context.Background(), "video-events")
+	msg := &pulsar.ProducerMessage{Properties: map[string]string{"eventType": "uploaded"}}
+	InjectMessage(ctx, msg)
+	span.End()
+
+	assert.Equal(t, "uploaded", msg.Properties["eventType"])
+	require.Contains(t, msg.Properties, "traceparent")
+
+	// A consumer extracting the properties continues the producer's trace
+	received := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(msg.Properties))
+	remote := trace.SpanContextFromContext(received)
+	require.True(t, remote.IsValid())
+	assert.True(t, remote.IsRemote())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, spans[0].SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), remote.SpanID())
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+}
+
+func TestInjectMessageCreatesProperties(t *testing.T) {
+	recordSpans(t)
+
+	ctx, span := Start(context.Background(), "publish")
+	defer span.End()
+
+	msg := &pulsar.ProducerMessage{}
+	InjectMessage(ctx, msg)
+	assert.Contains(t, msg.Properties, "traceparent")
+}
+
+func TestCQLObserverBackdatesSpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	parent, parentSpan := Start(context.Background(), "request")
+	start := time.Now().Add(-50 * time.Millisecond)
+	end := start.Add(20 * time.Millisecond)
+	NewCQLObserver().ObserveQuery(parent, gocql.ObservedQuery{
+		Keyspace:  "pavilion",
+		Statement: "  select * from notifications where user_id = ?",
+		Start:     start,
+		End:       end,
+		Rows:      3,
+		Err:       errors.New("timeout"),
+	})
+	parentSpan.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	query := spans[0]
+	assert.Equal(t, "cql.select", query.Name())
+	assert.Equal(t, start, query.StartTime())
+	assert.Equal(t, end, query.EndTime())
+	assert.Equal(t, parentSpan.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, codes.Error, query.Status().Code)
+
+	rows, ok := attributeValue(query, cqlRows)
+	require.True(t, ok)
+	assert.Equal(t, int64(3), rows.AsInt64())
+}
+
+func TestCQLObserverBatch(t *testing.T) {
+	recorder := recordSpans(t)
+
+	NewCQLObserver().ObserveBatch(context.Background(), gocql.ObservedBatch{
+		Statements: []string{"INSERT INTO a", "DELETE FROM b"},
+		Start:      time.Now(),
+		End:        time.Now(),
+	})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "cql.batch", spans[0].Name())
+	statement, ok := attributeValue(spans[0], "db.statement")
+	require.True(t, ok)
+	assert.Equal(t, "INSERT INTO a; DELETE FROM b", statement.AsString())
+}
+
+func TestGormPluginTracesStatements(t *testing.T) {
+	recorder := recordSpans(t)
+
+	// DryRun builds statements without a server, which is all the callbacks need
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(NewGormPlugin()))
+
+	type Widget struct {
+		ID   int
+		Name string
+	}
+
+	ctx, parent := Start(context.Background(), "request")
+	var widgets []Widget
+	db.WithContext(ctx).Where("name = ?", "gear").Find(&widgets)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	query := spans[0]
+	assert.Equal(t, "gorm.query", query.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+
+	statement, ok := attributeValue(query, "db.statement")
+	require.True(t, ok)
+	assert.Contains(t, statement.AsString(), `SELECT * FROM "widgets" WHERE name = `)
+	table, ok := attributeValue(query, "db.sql.table")
+	require.True(t, ok)
+	assert.Equal(t, "widgets", table.AsString())
+}
+
+func TestCQLOperation(t *testing.T) {
+	assert.Equal(t, "SELECT", cqlOperation("select * from t"))
+	assert.Equal(t, "INSERT", cqlOperation("\n\tINSERT INTO t"))
+	assert.Equal(t, "QUERY", cqlOperation("   "))
+}
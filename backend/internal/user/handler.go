@@ -0,0 +1,216 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for public profile endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new profile handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers all profile routes
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	users := router.Group("/users")
+	{
+		// Public routes
+		users.GET("/:id/profile", h.handleGetProfile)
+		users.GET("/:id/videos", h.handleListVideos)
+
+		// Protected routes, only for the profile owner
+		protected := users.Group("")
+		protected.Use(authMiddleware)
+		{
+			protected.PATCH("/:id/profile", h.handleUpdateProfile)
+			protected.POST("/:id/avatar", h.handleUploadAvatar)
+		}
+	}
+}
+
+// @Summary Get user profile
+// @Description Get a user's public profile with display name, avatar, bio and follower count
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse{data=Profile} "Profile retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/profile [get]
+func (h *Handler) handleGetProfile(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return
+	}
+
+	profile, err := h.service.GetProfile(c.Request.Context(), userID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve profile")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, profile, "Profile retrieved successfully")
+}
+
+// @Summary List user videos
+// @Description Get a paginated list of a user's public videos, newest first
+// @Tags users
+// @Produce json
+// @Param id path string true "User ID (UUID)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=video.VideoListResponse} "Videos retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid user ID"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "User not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/videos [get]
+func (h *Handler) handleListVideos(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	videos, err := h.service.ListVideos(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve videos")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, videos, "Videos retrieved successfully")
+}
+
+// @Summary Update user profile
+// @Description Update the authenticated user's display name and bio
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID), must be the authenticated user"
+// @Param request body UpdateProfileRequest true "Profile fields to update"
+// @Success 200 {object} httpHandler.APIResponse{data=Profile} "Profile updated"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid request"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Not the profile owner"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/profile [patch]
+func (h *Handler) handleUpdateProfile(c *gin.Context) {
+	userID, ok := h.authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	profile, err := h.service.UpdateProfile(c.Request.Context(), userID, req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to update profile")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, profile, "Profile updated successfully")
+}
+
+// @Summary Upload avatar
+// @Description Upload a new avatar image (JPEG, PNG or WebP, max 5MB) for the authenticated user
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID), must be the authenticated user"
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} httpHandler.APIResponse{data=Profile} "Avatar uploaded"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Missing or invalid image"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 403 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Not the profile owner"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /users/{id}/avatar [post]
+func (h *Handler) handleUploadAvatar(c *gin.Context) {
+	userID, ok := h.authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	file, fileHeader, err := c.Request.FormFile("avatar")
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "ERR_NO_FILE", "No avatar file received", err)
+		return
+	}
+	defer file.Close()
+
+	profile, err := h.service.UploadAvatar(c.Request.Context(), userID, file, fileHeader.Size)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to upload avatar")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, profile, "Avatar uploaded successfully")
+}
+
+// authorizeOwner returns the path user ID if it matches the authenticated user
+func (h *Handler) authorizeOwner(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+
+	callerID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid user ID", err)
+		return uuid.Nil, false
+	}
+
+	if userID != callerID {
+		h.responseHandler.ForbiddenResponse(c, "You can only modify your own profile")
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// handleServiceError maps profile service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		h.responseHandler.NotFoundResponse(c, "User not found")
+	case errors.Is(err, ErrInvalidProfile), errors.Is(err, ErrInvalidAvatar):
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", err.Error(), err)
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
@@ -0,0 +1,45 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUserNotFound is returned when no active user has the given ID
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidProfile is returned when profile fields fail validation
+	ErrInvalidProfile = errors.New("invalid profile")
+	// ErrInvalidAvatar is returned when an avatar is too large or not a supported image
+	ErrInvalidAvatar = errors.New("invalid avatar")
+)
+
+// Service defines the interface for public profile operations
+type Service interface {
+	// GetProfile returns the public profile of a user
+	GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error)
+	// GetProfiles returns the public profiles of several users at once,
+	// leaving out users that do not exist or are inactive
+	GetProfiles(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*Profile, error)
+	// UpdateProfile changes a user's display name and bio
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req UpdateProfileRequest) (*Profile, error)
+	// UploadAvatar stores a new avatar image for a user
+	UploadAvatar(ctx context.Context, userID uuid.UUID, reader io.Reader, size int64) (*Profile, error)
+	// ListVideos returns a user's public videos, newest first
+	ListVideos(ctx context.Context, userID uuid.UUID, page, limit int) (*video.VideoListResponse, error)
+}
+
+// AvatarStorage stores avatar images
+type AvatarStorage interface {
+	UploadAvatar(ctx context.Context, userID uuid.UUID, ext string, contentType string, reader io.Reader) (string, error)
+	GetAvatarURL(ctx context.Context, key string) (string, error)
+}
+
+// FollowerCounter reports how many followers a user has
+type FollowerCounter interface {
+	CountFollowers(ctx context.Context, userID uuid.UUID) (int64, error)
+}
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Profile is the public view of a user
+// @Description Public profile information for a user's channel page
+type Profile struct {
+	// User ID
+	ID uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	// Username
+	Username string `json:"username" example:"johndoe"`
+	// Display name
+	Name string `json:"name" example:"John Doe"`
+	// Short biography
+	Bio string `json:"bio" example:"Filmmaker and open source enthusiast"`
+	// Temporary URL of the avatar image, empty if the user has none
+	AvatarURL string `json:"avatarUrl,omitempty" example:"https://storage.example.com/avatars/550e8400-e29b-41d4-a716-446655440000/avatar.png"`
+	// Number of followers
+	FollowerCount int64 `json:"followerCount" example:"42"`
+	// When the user joined
+	CreatedAt time.Time `json:"createdAt" example:"2025-03-05T21:26:06Z"`
+}
+
+// UpdateProfileRequest contains the profile fields a user can change
+// @Description Profile fields to update; omitted fields are left unchanged
+type UpdateProfileRequest struct {
+	// Display name
+	Name *string `json:"name,omitempty" example:"John Doe"`
+	// Short biography (max 500 characters)
+	Bio *string `json:"bio,omitempty" example:"Filmmaker and open source enthusiast"`
+}
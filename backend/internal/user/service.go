@@ -0,0 +1,252 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxBioLength is the maximum number of characters in a bio
+	maxBioLength = 500
+	// maxNameLength is the maximum number of characters in a display name
+	maxNameLength = 100
+	// MaxAvatarSize is the largest accepted avatar image in bytes
+	MaxAvatarSize = 5 << 20
+)
+
+// avatarExtensions maps the accepted avatar content types to file extensions
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db        *gorm.DB
+	avatars   AvatarStorage
+	followers FollowerCounter
+	logger    logger.Logger
+}
+
+// NewService creates a new profile service
+func NewService(db *gorm.DB, avatars AvatarStorage, followers FollowerCounter, logger logger.Logger) Service {
+	return &serviceImpl{
+		db:        db,
+		avatars:   avatars,
+		followers: followers,
+		logger:    logger,
+	}
+}
+
+// GetProfile returns the public profile of a user
+func (s *serviceImpl) GetProfile(ctx context.Context, userID uuid.UUID) (*Profile, error) {
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toProfile(ctx, u)
+}
+
+// GetProfiles returns the public profiles of the active users among userIDs
+func (s *serviceImpl) GetProfiles(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*Profile, error) {
+	var users []auth.User
+	if err := s.db.WithContext(ctx).Where("id IN ? AND active = ?", userIDs, true).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	profiles := make(map[uuid.UUID]*Profile, len(users))
+	for i := range users {
+		profile, err := s.toProfile(ctx, &users[i])
+		if err != nil {
+			return nil, err
+		}
+		profiles[profile.ID] = profile
+	}
+	return profiles, nil
+}
+
+// UpdateProfile changes a user's display name and bio
+func (s *serviceImpl) UpdateProfile(ctx context.Context, userID uuid.UUID, req UpdateProfileRequest) (*Profile, error) {
+	if err := validateProfile(req); err != nil {
+		return nil, err
+	}
+
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Bio != nil {
+		updates["bio"] = strings.TrimSpace(*req.Bio)
+	}
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(u).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update profile: %w", err)
+		}
+	}
+
+	return s.toProfile(ctx, u)
+}
+
+// UploadAvatar validates and stores a new avatar image for a user
+func (s *serviceImpl) UploadAvatar(ctx context.Context, userID uuid.UUID, reader io.Reader, size int64) (*Profile, error) {
+	if size <= 0 || size > MaxAvatarSize {
+		return nil, fmt.Errorf("%w: must be between 1 byte and %d bytes", ErrInvalidAvatar, MaxAvatarSize)
+	}
+
+	u, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sniff the content type from the file itself rather than trusting the client
+	head := make([]byte, 512)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported image type %s", ErrInvalidAvatar, contentType)
+	}
+
+	body := io.MultiReader(bytes.NewReader(head), io.LimitReader(reader, MaxAvatarSize-int64(n)))
+	key, err := s.avatars.UploadAvatar(ctx, userID, ext, contentType, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+
+	if err := s.db.WithContext(ctx).Model(u).Update("avatar_path", key).Error; err != nil {
+		return nil, fmt.Errorf("failed to update avatar: %w", err)
+	}
+
+	return s.toProfile(ctx, u)
+}
+
+// ListVideos returns a user's public videos, newest first. Only videos whose
+// upload has completed are public; deleted, quarantined and taken down videos
+// are left out.
+func (s *serviceImpl) ListVideos(ctx context.Context, userID uuid.UUID, page, limit int) (*video.VideoListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	query := video.ListedVideos(s.db.WithContext(ctx).Model(&video.Video{}).
+		Joins("JOIN video_uploads ON video_uploads.video_id = videos.id").
+		Where("videos.user_id = ? AND video_uploads.status = ?", userID, video.UploadStatusCompleted))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count videos: %w", err)
+	}
+
+	var videos []video.Video
+	if err := query.Preload("Upload").Preload("Transcodes").Preload("Transcodes.Segments").
+		Order("videos.created_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	response := &video.VideoListResponse{
+		Videos: make([]video.VideoDetailsResponse, 0, len(videos)),
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	}
+	for i := range videos {
+		details := videos[i].ToVideoDetailsResponse()
+		// The channel page is public, so gated videos never expose playback locations here
+		if videos[i].RequiresEntitlement {
+			details.RedactPlayback()
+		}
+		response.Videos = append(response.Videos, details)
+	}
+
+	return response, nil
+}
+
+// getUser loads an active user or returns ErrUserNotFound
+func (s *serviceImpl) getUser(ctx context.Context, userID uuid.UUID) (*auth.User, error) {
+	var u auth.User
+	if err := s.db.WithContext(ctx).Where("id = ? AND active = ?", userID, true).First(&u).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &u, nil
+}
+
+// toProfile builds the public profile, resolving the avatar URL and follower count
+func (s *serviceImpl) toProfile(ctx context.Context, u *auth.User) (*Profile, error) {
+	profile := &Profile{
+		ID:        u.ID,
+		Username:  u.Username,
+		Name:      u.Name,
+		Bio:       u.Bio,
+		CreatedAt: u.CreatedAt,
+	}
+
+	if u.AvatarPath != "" && s.avatars != nil {
+		url, err := s.avatars.GetAvatarURL(ctx, u.AvatarPath)
+		if err != nil {
+			// A missing avatar should not hide the rest of the profile
+			s.logger.LogWarn("Failed to resolve avatar URL", map[string]interface{}{
+				"user_id": u.ID.String(),
+				"error":   err.Error(),
+			})
+		} else {
+			profile.AvatarURL = url
+		}
+	}
+
+	if s.followers != nil {
+		count, err := s.followers.CountFollowers(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		profile.FollowerCount = count
+	}
+
+	return profile, nil
+}
+
+// validateProfile checks the length limits of profile fields
+func validateProfile(req UpdateProfileRequest) error {
+	if req.Name != nil && len([]rune(strings.TrimSpace(*req.Name))) > maxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidProfile, maxNameLength)
+	}
+	if req.Bio != nil && len([]rune(strings.TrimSpace(*req.Bio))) > maxBioLength {
+		return fmt.Errorf("%w: bio must be at most %d characters", ErrInvalidProfile, maxBioLength)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a database that builds statements without running them
+// and the SQL of every query it builds
+func dryRunDB(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		queries = append(queries, db.Statement.SQL.String())
+	}))
+	return db, &queries
+}
+
+func TestValidateProfile(t *testing.T) {
+	name := "Jane Doe"
+	bio := "Documentary filmmaker"
+	assert.NoError(t, validateProfile(UpdateProfileRequest{Name: &name, Bio: &bio}))
+	assert.NoError(t, validateProfile(UpdateProfileRequest{}))
+
+	longBio := strings.Repeat("a", maxBioLength+1)
+	assert.ErrorIs(t, validateProfile(UpdateProfileRequest{Bio: &longBio}), ErrInvalidProfile)
+
+	longName := strings.Repeat("a", maxNameLength+1)
+	assert.ErrorIs(t, validateProfile(UpdateProfileRequest{Name: &longName}), ErrInvalidProfile)
+}
+
+func TestUploadAvatarRejectsOversizedFiles(t *testing.T) {
+	service := NewService(nil, nil, nil, nil)
+
+	_, err := service.UploadAvatar(context.Background(), uuid.New(), strings.NewReader(""), MaxAvatarSize+1)
+	assert.ErrorIs(t, err, ErrInvalidAvatar)
+
+	_, err = service.UploadAvatar(context.Background(), uuid.New(), strings.NewReader(""), 0)
+	assert.ErrorIs(t, err, ErrInvalidAvatar)
+}
+
+func TestProfileOmitsPrivateFields(t *testing.T) {
+	suspended := time.Now()
+	u := &auth.User{
+		ID:            uuid.New(),
+		Username:      "janedoe",
+		Email:         "jane@example.com",
+		Password:      "hash",
+		Name:          "Jane Doe",
+		Bio:           "Documentary filmmaker",
+		EmailVerified: true,
+		Role:          "admin",
+		Active:        true,
+		SuspendedAt:   &suspended,
+	}
+	service := NewService(nil, nil, nil, nil).(*serviceImpl)
+
+	profile, err := service.toProfile(context.Background(), u)
+	require.NoError(t, err)
+	data, err := json.Marshal(profile)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.ElementsMatch(t, []string{"id", "username", "name", "bio", "followerCount", "createdAt"}, keys(fields))
+	assert.NotContains(t, string(data), u.Email)
+	assert.NotContains(t, string(data), "admin")
+}
+
+func TestListVideosOnlyPublic(t *testing.T) {
+	db, queries := dryRunDB(t)
+	service := NewService(db, nil, nil, nil)
+	userID := uuid.New()
+
+	_, err := service.ListVideos(context.Background(), userID, 1, 10)
+	require.NoError(t, err)
+
+	// The user lookup, the count and the page
+	require.Len(t, *queries, 3)
+	assert.Contains(t, (*queries)[0], "active = $")
+	for _, query := range (*queries)[1:] {
+		assert.Contains(t, query, "videos.user_id = $")
+		assert.Contains(t, query, "video_uploads.status = $", "unfinished uploads are not public")
+		assert.Contains(t, query, `"videos"."deleted_at" IS NULL`, "deleted videos are left out")
+		assert.Contains(t, query, "videos.scan_status NOT IN", "quarantined videos are left out")
+		assert.Contains(t, query, "videos.taken_down_at IS NULL", "taken down videos are left out")
+	}
+}
+
+func keys(fields map[string]interface{}) []string {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package video
+
+import (
+	"context"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/google/uuid"
+)
+
+// VideoCache keeps video records, with their upload, transcodes and tags, in
+// Redis so GetVideo does not run its preloads on every request. Only videos
+// whose upload completed are cached, as they change rarely; writes through
+// the video and entitlement services invalidate the entry, and other
+// changes, such as IPFS replication finishing, show once it expires.
+// A nil *VideoCache caches nothing.
+type VideoCache struct {
+	cache *cache.ReadThrough
+}
+
+// NewVideoCache creates a video cache whose entries expire after ttl
+func NewVideoCache(service cache.Service, ttl time.Duration, metrics *cache.Metrics) *VideoCache {
+	return &VideoCache{cache: cache.NewReadThrough(service, "video", ttl, metrics)}
+}
+
+// get returns the cached video, calling load on a miss
+func (c *VideoCache) get(ctx context.Context, videoID uuid.UUID, load func() (*Video, error)) (*Video, error) {
+	if c == nil {
+		return load()
+	}
+
+	var video Video
+	err := c.cache.Get(ctx, videoID.String(), &video, func() (bool, error) {
+		loaded, err := load()
+		if err != nil {
+			return false, err
+		}
+		video = *loaded
+		return loaded.Upload != nil && loaded.Upload.Status == UploadStatusCompleted, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &video, nil
+}
+
+// Invalidate drops the cached record of a video after it was changed
+func (c *VideoCache) Invalidate(ctx context.Context, videoID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	c.cache.Invalidate(ctx, videoID.String())
+}
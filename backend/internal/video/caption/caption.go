@@ -0,0 +1,194 @@
+// Package caption validates subtitle files and converts them to WebVTT, the
+// format browsers play text tracks in.
+package caption
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format is a subtitle file format, named by its file extension
+type Format string
+
+const (
+	// FormatSRT is SubRip, the most common format subtitles are authored in
+	FormatSRT Format = ".srt"
+	// FormatVTT is WebVTT, which tracks are stored and served as
+	FormatVTT Format = ".vtt"
+)
+
+// MaxSize is the largest subtitle file accepted, in bytes
+const MaxSize = 2 << 20
+
+// ContentType is the media type of normalized tracks
+const ContentType = "text/vtt; charset=utf-8"
+
+// ErrInvalid is returned for files that are not well-formed subtitles
+var ErrInvalid = errors.New("invalid subtitle file")
+
+// languagePattern matches BCP 47 tags of a language and optional subtags,
+// such as en, pt-BR or zh-Hant
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// ValidLanguage reports whether tag is a BCP 47 language tag
+func ValidLanguage(tag string) bool {
+	return len(tag) <= 35 && languagePattern.MatchString(tag)
+}
+
+// FormatOf returns the format of a file by its extension
+func FormatOf(name string) (Format, bool) {
+	switch format := Format(strings.ToLower(filepath.Ext(name))); format {
+	case FormatSRT, FormatVTT:
+		return format, true
+	}
+	return "", false
+}
+
+// Normalize validates a subtitle file and returns it as WebVTT in UTF-8 with
+// Unix line endings. SubRip cues are converted; WebVTT files keep their
+// header, styles and cue settings. Every cue must end no earlier than it
+// starts.
+func Normalize(data []byte, format Format) ([]byte, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: not UTF-8 text", ErrInvalid)
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(text, "\n")
+
+	switch format {
+	case FormatSRT:
+		return fromSRT(lines)
+	case FormatVTT:
+		return checkVTT(lines)
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalid, format)
+}
+
+// fromSRT converts the lines of a SubRip file to WebVTT. Cue numbers are
+// dropped, since WebVTT does not need them.
+func fromSRT(lines []string) ([]byte, error) {
+	var out strings.Builder
+	out.WriteString("WEBVTT\n")
+
+	cues := 0
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+		// A cue is an optional number, the timing line and its text
+		if !strings.Contains(lines[i], "-->") && i+1 < len(lines) && strings.Contains(lines[i+1], "-->") {
+			i++
+		}
+		start, end, err := parseTiming(lines[i], ',')
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, i+1, err)
+		}
+		fmt.Fprintf(&out, "\n%s --> %s\n", formatTime(start), formatTime(end))
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			i++
+			// WebVTT ends a cue's text at an arrow, which SubRip allows
+			out.WriteString(strings.ReplaceAll(lines[i], "-->", "->"))
+			out.WriteByte('\n')
+		}
+		cues++
+	}
+
+	if cues == 0 {
+		return nil, fmt.Errorf("%w: no cues", ErrInvalid)
+	}
+	return []byte(out.String()), nil
+}
+
+// checkVTT validates the header and cue timings of a WebVTT file
+func checkVTT(lines []string) ([]byte, error) {
+	header := lines[0]
+	if header != "WEBVTT" && !strings.HasPrefix(header, "WEBVTT ") && !strings.HasPrefix(header, "WEBVTT\t") {
+		return nil, fmt.Errorf("%w: missing WEBVTT header", ErrInvalid)
+	}
+
+	cues := 0
+	for i, line := range lines[1:] {
+		if !strings.Contains(line, "-->") {
+			continue
+		}
+		if _, _, err := parseTiming(line, '.'); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, i+2, err)
+		}
+		cues++
+	}
+
+	if cues == 0 {
+		return nil, fmt.Errorf("%w: no cues", ErrInvalid)
+	}
+	text := strings.Join(lines, "\n")
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return []byte(text), nil
+}
+
+// parseTiming parses a cue timing line, "start --> end" followed by any
+// settings, into milliseconds
+func parseTiming(line string, separator byte) (start, end int64, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[1] != "-->" {
+		return 0, 0, fmt.Errorf("expected a cue timing, got %q", line)
+	}
+	if start, err = parseTime(fields[0], separator); err != nil {
+		return 0, 0, err
+	}
+	if end, err = parseTime(fields[2], separator); err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("cue ends at %s, before it starts at %s", fields[2], fields[0])
+	}
+	return start, end, nil
+}
+
+// parseTime parses a timestamp, [hours:]minutes:seconds followed by separator
+// and milliseconds, into milliseconds. SubRip files written with a period
+// instead of a comma are common, so either is accepted.
+func parseTime(value string, separator byte) (int64, error) {
+	invalid := fmt.Errorf("invalid timestamp %q", value)
+
+	dot := strings.LastIndexAny(value, string([]byte{separator, '.'}))
+	if dot < 0 || len(value)-dot-1 != 3 {
+		return 0, invalid
+	}
+	millis, err := strconv.ParseInt(value[dot+1:], 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+
+	parts := strings.Split(value[:dot], ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, invalid
+	}
+	var total int64
+	for i, part := range parts {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 || len(part) < 2 {
+			return 0, invalid
+		}
+		// Only the hours are unbounded
+		if (i > 0 || len(parts) == 2) && n > 59 {
+			return 0, invalid
+		}
+		total = total*60 + n
+	}
+	return total*1000 + millis, nil
+}
+
+// formatTime formats milliseconds as a WebVTT timestamp, hh:mm:ss.ttt
+func formatTime(ms int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,67 @@
+package caption
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize_SRT(t *testing.T) {
+	srt := "\ufeff1\r\n00:00:01,000 --> 00:00:04,250\r\nHello there\r\n\r\n2\r\n00:01:02,500 --> 01:00:00,000 X1:40 X2:600\r\nTwo lines --> with an arrow\r\nof text\r\n"
+
+	vtt, err := Normalize([]byte(srt), FormatSRT)
+	require.NoError(t, err)
+	assert.Equal(t, "WEBVTT\n\n00:00:01.000 --> 00:00:04.250\nHello there\n\n00:01:02.500 --> 01:00:00.000\nTwo lines -> with an arrow\nof text\n", string(vtt))
+}
+
+func TestNormalize_VTT(t *testing.T) {
+	vtt := "WEBVTT - English\r\n\r\nintro\r\n00:01.000 --> 00:04.000 align:start\r\n<v Anna>Hello"
+
+	out, err := Normalize([]byte(vtt), FormatVTT)
+	require.NoError(t, err)
+	assert.Equal(t, "WEBVTT - English\n\nintro\n00:01.000 --> 00:04.000 align:start\n<v Anna>Hello\n", string(out))
+}
+
+func TestNormalize_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format Format
+	}{
+		{"ends before it starts", "1\n00:00:05,000 --> 00:00:04,000\nBackwards\n", FormatSRT},
+		{"malformed timestamp", "1\n00:00:5,000 --> 00:00:06,000\nShort seconds\n", FormatSRT},
+		{"seconds out of range", "1\n00:00:75,000 --> 00:01:20,000\nToo many seconds\n", FormatSRT},
+		{"text without timing", "Just some text\nand more\n", FormatSRT},
+		{"no cues", "\n\n", FormatSRT},
+		{"missing header", "00:01.000 --> 00:02.000\nHello\n", FormatVTT},
+		{"header only", "WEBVTT\n", FormatVTT},
+		{"not UTF-8", "WEBVTT\n\n00:01.000 --> 00:02.000\n\xff\xfe\n", FormatVTT},
+		{"unsupported format", "anything", Format(".ass")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Normalize([]byte(tt.data), tt.format)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	format, ok := FormatOf("English.SRT")
+	assert.True(t, ok)
+	assert.Equal(t, FormatSRT, format)
+
+	_, ok = FormatOf("subtitles.ass")
+	assert.False(t, ok)
+}
+
+func TestValidLanguage(t *testing.T) {
+	for _, tag := range []string{"en", "pt-BR", "zh-Hant", "yue", "es-419"} {
+		assert.True(t, ValidLanguage(tag), tag)
+	}
+	for _, tag := range []string{"", "EN", "english", "en_US", "en-", "../en"} {
+		assert.False(t, ValidLanguage(tag), tag)
+	}
+}
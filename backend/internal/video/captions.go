@@ -0,0 +1,248 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/caption"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tempfile"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidCaption is returned for caption files that are not well-formed subtitles
+	ErrInvalidCaption = apierror.Validation("file", "file must be a well-formed SubRip or WebVTT file")
+	// ErrBurnInNotReady is returned when captions are to be burned into a video that has not finished processing
+	ErrBurnInNotReady = apierror.New(apierror.CodeConflict, "captions can only be burned in once the video has finished processing")
+)
+
+// CaptionRequest represents the form of a caption upload
+type CaptionRequest struct {
+	File *multipart.FileHeader `form:"file" binding:"required,captionfile" swaggerignore:"true"`
+	// Language is a BCP 47 tag; a track already in the language is replaced
+	Language string `form:"language" binding:"required,captionlang" example:"pt-BR"`
+	Label    string `form:"label" binding:"max=64" example:"Português (Brasil)"`
+	// BurnIn also stores a copy of the video with the captions drawn onto the picture
+	BurnIn bool `form:"burn_in"`
+}
+
+// VideoCaption is a video's caption track in one language, stored as WebVTT
+// next to the video's renditions
+type VideoCaption struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	VideoID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_video_captions_video_language" json:"video_id"`
+	Language    string    `gorm:"size:35;not null;uniqueIndex:idx_video_captions_video_language" json:"language"`
+	Label       string    `gorm:"size:64" json:"label"`
+	StoragePath string    `gorm:"not null" json:"storage_path"`
+	// BurnedInPath is the storage key of a copy of the video with the
+	// captions drawn onto the picture; empty unless one was requested
+	BurnedInPath string    `json:"burned_in_path,omitempty"`
+	CreatedAt    time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// CaptionUpload is a subtitle file to store as a video's track in a language
+type CaptionUpload struct {
+	Language string
+	Label    string
+	Format   caption.Format
+	Data     []byte
+	BurnIn   bool
+}
+
+// CaptionTrack describes a caption track in responses
+type CaptionTrack struct {
+	Language string `json:"language" example:"en"`
+	Label    string `json:"label" example:"English"`
+	// URL serves the track as WebVTT; URLs from S3 expire
+	URL string `json:"url"`
+	// BurnedInURL serves a copy of the video with the captions drawn onto the picture
+	BurnedInURL string    `json:"burned_in_url,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CaptionListResponse represents the caption tracks of a video
+type CaptionListResponse struct {
+	VideoID  string         `json:"video_id"`
+	Captions []CaptionTrack `json:"captions"`
+}
+
+// CaptionStore stores caption files next to a video's renditions. The
+// storage backends implement it.
+type CaptionStore interface {
+	UploadCaption(ctx context.Context, videoID uuid.UUID, fileName, contentType string, reader io.Reader) (string, error)
+	GetVideoURL(ctx context.Context, key string) (string, error)
+	DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// CaptionServiceImpl implements the CaptionService interface
+type CaptionServiceImpl struct {
+	repo        VideoRepository
+	store       CaptionStore
+	ffmpeg      *ffmpeg.Service
+	tempManager tempfile.TempFileManager
+	cache       *VideoCache
+	logger      Logger
+}
+
+// NewCaptionService creates a new caption service instance
+func NewCaptionService(
+	repo VideoRepository,
+	store CaptionStore,
+	ffmpeg *ffmpeg.Service,
+	tempManager tempfile.TempFileManager,
+	cache *VideoCache,
+	logger Logger,
+) CaptionService {
+	return &CaptionServiceImpl{
+		repo:        repo,
+		store:       store,
+		ffmpeg:      ffmpeg,
+		tempManager: tempManager,
+		cache:       cache,
+		logger:      logger,
+	}
+}
+
+// UploadCaption converts a subtitle file to WebVTT and stores it as the
+// video's track in its language, replacing any earlier one. Burning the
+// captions in re-encodes the video, so it takes about as long as a transcode.
+func (s *CaptionServiceImpl) UploadCaption(ctx context.Context, video *Video, upload CaptionUpload) (*CaptionTrack, error) {
+	vtt, err := caption.Normalize(upload.Data, upload.Format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCaption, err)
+	}
+	if upload.BurnIn && (video.Upload == nil || video.Upload.Status != UploadStatusCompleted) {
+		return nil, ErrBurnInNotReady
+	}
+
+	key, err := s.store.UploadCaption(ctx, video.ID, upload.Language+string(caption.FormatVTT), caption.ContentType, bytes.NewReader(vtt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to store captions: %w", err)
+	}
+
+	var burnedIn string
+	if upload.BurnIn {
+		if burnedIn, err = s.burnIn(ctx, video, upload.Language, vtt); err != nil {
+			return nil, err
+		}
+	}
+
+	record := VideoCaption{
+		VideoID:      video.ID,
+		Language:     upload.Language,
+		Label:        upload.Label,
+		StoragePath:  key,
+		BurnedInPath: burnedIn,
+	}
+	if err := s.repo.SaveCaption(ctx, &record); err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, video.ID)
+
+	s.logger.LogInfo("Caption track stored", map[string]interface{}{
+		"video_id":  video.ID,
+		"language":  upload.Language,
+		"burned_in": burnedIn != "",
+	})
+	return s.track(ctx, record)
+}
+
+// Tracks returns the caption tracks of a video, ordered by language, with
+// URLs they can be fetched from
+func (s *CaptionServiceImpl) Tracks(ctx context.Context, video *Video) ([]CaptionTrack, error) {
+	tracks := make([]CaptionTrack, 0, len(video.Captions))
+	for _, record := range video.Captions {
+		track, err := s.track(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, *track)
+	}
+	return tracks, nil
+}
+
+func (s *CaptionServiceImpl) track(ctx context.Context, record VideoCaption) (*CaptionTrack, error) {
+	url, err := s.store.GetVideoURL(ctx, record.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caption URL: %w", err)
+	}
+	track := &CaptionTrack{
+		Language:  record.Language,
+		Label:     record.Label,
+		URL:       url,
+		UpdatedAt: record.UpdatedAt,
+	}
+	if record.BurnedInPath != "" {
+		if track.BurnedInURL, err = s.store.GetVideoURL(ctx, record.BurnedInPath); err != nil {
+			return nil, fmt.Errorf("failed to get burned-in video URL: %w", err)
+		}
+	}
+	return track, nil
+}
+
+// burnIn encodes a copy of the video's original file with the WebVTT track
+// vtt drawn onto the picture, and returns its storage key
+func (s *CaptionServiceImpl) burnIn(ctx context.Context, video *Video, language string, vtt []byte) (string, error) {
+	tempDir, err := s.tempManager.CreateTempDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer s.tempManager.CleanupDir(tempDir)
+
+	originalPath := filepath.Join(tempDir, "original.mp4")
+	if err := s.download(ctx, video.StoragePath, originalPath); err != nil {
+		return "", err
+	}
+	captionsPath := filepath.Join(tempDir, language+string(caption.FormatVTT))
+	if err := os.WriteFile(captionsPath, vtt, 0644); err != nil {
+		return "", fmt.Errorf("failed to write captions: %w", err)
+	}
+
+	metadata, err := s.ffmpeg.GetMetadata(ctx, originalPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read video metadata: %w", err)
+	}
+	outputPath := filepath.Join(tempDir, "burned-in.mp4")
+	if err := s.ffmpeg.BurnCaptions(ctx, originalPath, captionsPath, outputPath, metadata); err != nil {
+		return "", fmt.Errorf("failed to burn in captions: %w", err)
+	}
+
+	output, err := os.Open(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open burned-in video: %w", err)
+	}
+	defer output.Close()
+	key, err := s.store.UploadCaption(ctx, video.ID, language+".mp4", "video/mp4", output)
+	if err != nil {
+		return "", fmt.Errorf("failed to store burned-in video: %w", err)
+	}
+	return key, nil
+}
+
+// download copies the stored file at key to path
+func (s *CaptionServiceImpl) download(ctx context.Context, key, path string) error {
+	reader, err := s.store.DownloadVideo(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+	defer reader.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("failed to download video: %w", err)
+	}
+	return file.Close()
+}
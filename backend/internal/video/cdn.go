@@ -0,0 +1,58 @@
+package video
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// purgeCDN drops a video's files from the CDN's caches after they were
+// deleted, replaced or taken down, so the CDN stops serving the old copies.
+// A failed purge is logged; cached copies then expire on their own.
+func (s *VideoServiceImpl) purgeCDN(ctx context.Context, videoID uuid.UUID) {
+	if s.cdn == nil {
+		return
+	}
+
+	video, err := s.repo.GetFiles(ctx, videoID)
+	if err != nil {
+		s.logger.LogError("Failed to load video for CDN purge", map[string]interface{}{
+			"error":    err.Error(),
+			"video_id": videoID,
+		})
+		return
+	}
+
+	keys := cdnKeys(video)
+	if err := s.cdn.Purge(ctx, keys); err != nil {
+		s.logger.LogError("Failed to purge video from CDN", map[string]interface{}{
+			"error":    err.Error(),
+			"video_id": videoID,
+			"keys":     len(keys),
+		})
+	}
+}
+
+// cdnKeys returns the storage keys of every file of a video the CDN may serve
+func cdnKeys(video *Video) []string {
+	var keys []string
+	add := func(key string) {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	add(video.StoragePath)
+	add(video.AudioPath)
+	add(video.PreviewPath)
+	for _, transcode := range video.Transcodes {
+		for _, segment := range transcode.Segments {
+			add(segment.StoragePath)
+		}
+	}
+	for _, caption := range video.Captions {
+		add(caption.StoragePath)
+		add(caption.BurnedInPath)
+	}
+	return keys
+}
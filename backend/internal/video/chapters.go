@@ -0,0 +1,194 @@
+package video
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+)
+
+// MaxChapters is the most chapters a video can have
+const MaxChapters = 100
+
+var (
+	// ErrChaptersNotReady is returned when setting chapters on a video whose duration is not known yet
+	ErrChaptersNotReady = apierror.New(apierror.CodeConflict, "chapters can only be set once the video has finished processing")
+	// ErrInvalidChapters is returned for chapters that do not fit the video
+	ErrInvalidChapters = apierror.Validation("chapters", "chapters must start at 0:00, in order, within the video")
+)
+
+// Chapter is a titled section of a video, from its start to the next chapter's
+type Chapter struct {
+	Title string `json:"title" binding:"required,max=100" example:"Intro"`
+	// Start is the offset of the chapter in seconds
+	Start float64 `json:"start" binding:"min=0" example:"0"`
+}
+
+// ChaptersRequest represents the request for setting a video's chapters
+type ChaptersRequest struct {
+	// Chapters replace the video's chapters; an empty list removes them, so
+	// chapters are taken from the description again
+	Chapters []Chapter `json:"chapters" binding:"max=100,dive"`
+}
+
+// ChaptersResponse represents the chapters of a video
+type ChaptersResponse struct {
+	VideoID  string    `json:"video_id"`
+	Chapters []Chapter `json:"chapters"`
+}
+
+// ChapterList is a list of chapters, stored as JSON
+type ChapterList []Chapter
+
+// Value implements driver.Valuer
+func (l ChapterList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (l *ChapterList) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into ChapterList", value)
+	}
+	return json.Unmarshal(raw, l)
+}
+
+// ValidateChapters checks that chapters start at 0:00 and are in order, each
+// starting after the one before and before the end of a video of the given
+// duration in seconds
+func ValidateChapters(chapters []Chapter, duration float64) error {
+	for i, chapter := range chapters {
+		switch {
+		case i == 0 && chapter.Start != 0:
+			return fmt.Errorf("%w: the first chapter starts at %s", ErrInvalidChapters, formatOffset(chapter.Start))
+		case i > 0 && chapter.Start <= chapters[i-1].Start:
+			return fmt.Errorf("%w: %q starts at or before the chapter before it", ErrInvalidChapters, chapter.Title)
+		case chapter.Start >= duration:
+			return fmt.Errorf("%w: %q starts at %s, after the video ends at %s", ErrInvalidChapters, chapter.Title, formatOffset(chapter.Start), formatOffset(duration))
+		}
+	}
+	return nil
+}
+
+// chapterLine matches a line of a chapter list in a description: a timestamp
+// such as 0:00, 05:30 or 1:02:03, then the title, optionally after a dash
+var chapterLine = regexp.MustCompile(`^\s*(?:(\d{1,2}):)?(\d{1,2}):(\d{2})\s*(?:[-–—:|]\s*)?(\S.*?)\s*$`)
+
+// ParseChapters returns the chapter list in a description, the way creators
+// write one for other platforms: lines starting with a timestamp and a title,
+// the first at 0:00, in order. Other lines are ignored. Fewer than two
+// chapters, or a list out of order, is not a chapter list and returns nil.
+func ParseChapters(description string) []Chapter {
+	var chapters []Chapter
+	for _, line := range strings.Split(description, "\n") {
+		match := chapterLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		hours, _ := strconv.Atoi(match[1])
+		minutes, _ := strconv.Atoi(match[2])
+		seconds, _ := strconv.Atoi(match[3])
+		if seconds > 59 || (match[1] != "" && minutes > 59) {
+			continue
+		}
+		start := float64(hours*3600 + minutes*60 + seconds)
+		chapters = append(chapters, Chapter{Title: match[4], Start: start})
+	}
+
+	if len(chapters) < 2 || len(chapters) > MaxChapters {
+		return nil
+	}
+	if err := ValidateChapters(chapters, chapters[len(chapters)-1].Start+1); err != nil {
+		return nil
+	}
+	return chapters
+}
+
+// formatOffset formats seconds as m:ss or h:mm:ss, like chapter lists
+func formatOffset(seconds float64) string {
+	s := int(seconds)
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
+
+// duration returns the length of the video in seconds, or 0 when it is not
+// known. Videos processed before the duration was recorded fall back to
+// their longest rendition.
+func (v *Video) duration() float64 {
+	if v.Duration > 0 {
+		return v.Duration
+	}
+	var longest int
+	for _, t := range v.Transcodes {
+		for _, s := range t.Segments {
+			longest = max(longest, s.Duration)
+		}
+	}
+	return float64(longest)
+}
+
+// chapters returns the chapters players show: those the creator set, or else
+// those listed in the description. Chapters a replaced file is too short
+// for are left out.
+func (v *Video) chapters() []Chapter {
+	chapters := []Chapter(v.Chapters)
+	if len(chapters) == 0 {
+		chapters = ParseChapters(v.Description)
+	}
+	duration := v.duration()
+	if duration <= 0 {
+		return chapters
+	}
+	for i, chapter := range chapters {
+		if chapter.Start >= duration {
+			return chapters[:i]
+		}
+	}
+	return chapters
+}
+
+// SetChapters replaces the chapters of a video after checking them against
+// its duration
+func (s *VideoServiceImpl) SetChapters(ctx context.Context, videoID uuid.UUID, chapters []Chapter) error {
+	video, err := s.GetVideo(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	duration := video.duration()
+	if duration <= 0 {
+		return ErrChaptersNotReady
+	}
+	if err := ValidateChapters(chapters, duration); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetChapters(ctx, videoID, ChapterList(chapters)); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	return nil
+}
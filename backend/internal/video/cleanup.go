@@ -0,0 +1,443 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// Reasons a video is purged by the cleanup worker
+const (
+	PurgeReasonDeleted = "deleted" // Soft-deleted longer than the retention period
+	PurgeReasonFailed  = "failed"  // Upload failed or was interrupted and never retried
+	// PurgeReasonReplaced candidates are the IDs of video versions, not
+	// videos: the archived files of versions replaced longer ago than the
+	// retention period
+	PurgeReasonReplaced = "replaced"
+)
+
+// Stages at which purging a video can fail
+const (
+	PurgeStageStorage  = "storage"
+	PurgeStageIPFS     = "ipfs"
+	PurgeStageDatabase = "database"
+)
+
+// Unpinner releases IPFS pins
+type Unpinner interface {
+	Unpin(ctx context.Context, cid string) error
+}
+
+// CleanupConfig controls the orphaned storage cleanup worker
+type CleanupConfig struct {
+	// Interval between cleanup runs
+	Interval time.Duration
+	// DeletedAfter is how long soft-deleted videos are kept before they are purged
+	DeletedAfter time.Duration
+	// FailedAfter is how long failed and interrupted uploads are kept before they are purged
+	FailedAfter time.Duration
+	// VersionsAfter is how long the files of replaced video versions are kept before they are purged
+	VersionsAfter time.Duration
+	// BatchSize caps the videos purged for each reason in one run
+	BatchSize int
+	// DryRun logs and counts what would be purged without deleting anything
+	DryRun bool
+}
+
+// CleanupResult summarises one cleanup run
+type CleanupResult struct {
+	// Candidates lists the videos found for purging, by reason
+	Candidates map[string][]uuid.UUID
+	// Purged is the number of videos removed; always 0 in a dry run
+	Purged int
+	// Failed is the number of videos that could not be purged and will be retried
+	Failed int
+}
+
+// CleanupMetrics exports cleanup outcomes to Prometheus. A nil
+// *CleanupMetrics is valid and records nothing.
+type CleanupMetrics struct {
+	candidates *prometheus.GaugeVec
+	purged     *prometheus.CounterVec
+	failed     *prometheus.CounterVec
+	lastRun    prometheus.Gauge
+}
+
+// NewCleanupMetrics creates cleanup metrics and registers them with registerer
+func NewCleanupMetrics(registerer prometheus.Registerer) *CleanupMetrics {
+	m := &CleanupMetrics{
+		candidates: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_cleanup",
+			Name:      "candidates",
+			Help:      "Videos found for purging in the latest run, including dry runs.",
+		}, []string{"reason"}),
+		purged: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_cleanup",
+			Name:      "purged_total",
+			Help:      "Videos whose storage and records were removed.",
+		}, []string{"reason"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_cleanup",
+			Name:      "failures_total",
+			Help:      "Videos that could not be purged, by stage.",
+		}, []string{"stage"}),
+		lastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_cleanup",
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Unix time the latest cleanup run finished.",
+		}),
+	}
+	registerer.MustRegister(m.candidates, m.purged, m.failed, m.lastRun)
+	return m
+}
+
+func (m *CleanupMetrics) recordRun(result CleanupResult) {
+	if m == nil {
+		return
+	}
+	for _, reason := range purgeReasons {
+		m.candidates.WithLabelValues(reason).Set(float64(len(result.Candidates[reason])))
+	}
+	m.lastRun.SetToCurrentTime()
+}
+
+func (m *CleanupMetrics) recordPurged(reason string) {
+	if m == nil {
+		return
+	}
+	m.purged.WithLabelValues(reason).Inc()
+}
+
+func (m *CleanupMetrics) recordFailure(stage string) {
+	if m == nil {
+		return
+	}
+	m.failed.WithLabelValues(stage).Inc()
+}
+
+// purgeReasons lists the reasons in the order the cleanup worker purges them
+var purgeReasons = []string{PurgeReasonDeleted, PurgeReasonFailed, PurgeReasonReplaced}
+
+// PurgeError reports the stage at which purging a video failed. Stages run in
+// order, so an error at a later stage means the earlier ones completed.
+type PurgeError struct {
+	VideoID uuid.UUID
+	Stage   string
+	Err     error
+}
+
+func (e *PurgeError) Error() string {
+	return fmt.Sprintf("failed to purge video %s at %s: %v", e.VideoID, e.Stage, e.Err)
+}
+
+func (e *PurgeError) Unwrap() error {
+	return e.Err
+}
+
+// Purger removes a video's stored files, IPFS pins and database records
+type Purger struct {
+	db      *gorm.DB
+	storage videostorage.Service
+	ipfs    Unpinner
+}
+
+// NewPurger creates a purger; ipfs may be nil when pins are not managed
+func NewPurger(db *gorm.DB, storage videostorage.Service, ipfs Unpinner) *Purger {
+	return &Purger{db: db, storage: storage, ipfs: ipfs}
+}
+
+// Purge deletes the video's S3 objects, unpins its IPFS CIDs, including
+// those of replaced versions not purged yet, and then hard-deletes its rows.
+// Records go last so a failed purge can be retried: nothing is lost that a
+// later attempt would need. Deleted videos are included.
+func (p *Purger) Purge(ctx context.Context, videoID uuid.UUID) error {
+	var video Video
+	if err := p.db.WithContext(ctx).Unscoped().Preload("Transcodes.Segments").First(&video, "id = ?", videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+		}
+		return &PurgeError{VideoID: videoID, Stage: PurgeStageDatabase, Err: err}
+	}
+
+	if err := p.storage.DeleteVideo(ctx, videoID); err != nil {
+		return &PurgeError{VideoID: videoID, Stage: PurgeStageStorage, Err: err}
+	}
+
+	if p.ipfs != nil {
+		cids := videoCIDs(&video)
+		var versions []VideoVersion
+		if err := p.db.WithContext(ctx).Where("video_id = ? AND purged_at IS NULL", videoID).Find(&versions).Error; err != nil {
+			return &PurgeError{VideoID: videoID, Stage: PurgeStageDatabase, Err: err}
+		}
+		for _, version := range versions {
+			for _, cid := range version.IPFSCIDs {
+				if !slices.Contains(cids, cid) {
+					cids = append(cids, cid)
+				}
+			}
+		}
+		for _, cid := range cids {
+			if err := p.ipfs.Unpin(ctx, cid); err != nil {
+				return &PurgeError{VideoID: videoID, Stage: PurgeStageIPFS, Err: err}
+			}
+		}
+	}
+
+	if err := deleteVideoRecords(p.db.WithContext(ctx), videoID); err != nil {
+		return &PurgeError{VideoID: videoID, Stage: PurgeStageDatabase, Err: err}
+	}
+	return nil
+}
+
+// videoCIDs returns every IPFS CID recorded for video and its renditions
+func videoCIDs(video *Video) []string {
+	var cids []string
+	if video.IPFSCID != "" {
+		cids = append(cids, video.IPFSCID)
+	}
+	for _, transcode := range video.Transcodes {
+		for _, segment := range transcode.Segments {
+			if segment.IPFSCID != "" {
+				cids = append(cids, segment.IPFSCID)
+			}
+		}
+	}
+	return cids
+}
+
+// deleteVideoRecords hard-deletes a video and the rows that belong to it,
+// children first to satisfy foreign keys
+func deleteVideoRecords(db *gorm.DB, videoID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteTranscodeRecords(tx, videoID); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM video_tags WHERE video_id = ?", videoID).Error; err != nil {
+			return fmt.Errorf("failed to delete video tags: %w", err)
+		}
+		if err := tx.Where("video_id = ?", videoID).Delete(&VideoVersion{}).Error; err != nil {
+			return fmt.Errorf("failed to delete versions: %w", err)
+		}
+		if err := tx.Where("video_id = ?", videoID).Delete(&VideoCaption{}).Error; err != nil {
+			return fmt.Errorf("failed to delete captions: %w", err)
+		}
+		if err := tx.Where("video_id = ?", videoID).Delete(&VideoUpload{}).Error; err != nil {
+			return fmt.Errorf("failed to delete upload: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&Video{}, "id = ?", videoID).Error; err != nil {
+			return fmt.Errorf("failed to delete video: %w", err)
+		}
+		return nil
+	})
+}
+
+// deleteTranscodeRecords hard-deletes a video's transcodes and their segments
+func deleteTranscodeRecords(tx *gorm.DB, videoID uuid.UUID) error {
+	transcodes := tx.Model(&Transcode{}).Select("id").Where("video_id = ?", videoID)
+	if err := tx.Where("transcode_id IN (?)", transcodes).Delete(&TranscodeSegment{}).Error; err != nil {
+		return fmt.Errorf("failed to delete segments: %w", err)
+	}
+	if err := tx.Where("video_id = ?", videoID).Delete(&Transcode{}).Error; err != nil {
+		return fmt.Errorf("failed to delete transcodes: %w", err)
+	}
+	return nil
+}
+
+// OrphanCleaner periodically purges videos whose files would otherwise stay
+// in storage forever: videos soft-deleted longer than the retention period,
+// uploads that failed or were interrupted, and the archived files of replaced
+// versions
+type OrphanCleaner struct {
+	db      *gorm.DB
+	purger  *Purger
+	config  CleanupConfig
+	metrics *CleanupMetrics
+	logger  Logger
+	now     func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewOrphanCleaner creates a cleaner; call Start to run it periodically
+func NewOrphanCleaner(db *gorm.DB, purger *Purger, config CleanupConfig, metrics *CleanupMetrics, logger Logger) *OrphanCleaner {
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 100
+	}
+	return &OrphanCleaner{
+		db:      db,
+		purger:  purger,
+		config:  config,
+		metrics: metrics,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Start runs cleanup once and then on every interval until Stop is called
+func (c *OrphanCleaner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := c.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				c.logger.LogError("Orphaned storage cleanup failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the cleaner and waits for a running pass to finish
+func (c *OrphanCleaner) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+}
+
+// RunOnce finds purge candidates and, unless in dry-run mode, purges them.
+// Videos that fail to purge are logged and retried on the next run.
+func (c *OrphanCleaner) RunOnce(ctx context.Context) (CleanupResult, error) {
+	result := CleanupResult{Candidates: make(map[string][]uuid.UUID)}
+
+	deleted, err := c.deletedCandidates(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to find deleted videos: %w", err)
+	}
+	result.Candidates[PurgeReasonDeleted] = deleted
+
+	failed, err := c.failedCandidates(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to find failed uploads: %w", err)
+	}
+	result.Candidates[PurgeReasonFailed] = failed
+
+	replaced, err := c.replacedCandidates(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to find replaced versions: %w", err)
+	}
+	result.Candidates[PurgeReasonReplaced] = replaced
+
+	defer func() { c.metrics.recordRun(result) }()
+
+	if c.config.DryRun {
+		if len(deleted)+len(failed)+len(replaced) > 0 {
+			c.logger.LogInfo("Cleanup dry run: videos that would be purged", map[string]interface{}{
+				"deleted":  deleted,
+				"failed":   failed,
+				"replaced": replaced,
+			})
+		}
+		return result, nil
+	}
+
+	for _, reason := range purgeReasons {
+		purge, idField := c.purger.Purge, "video_id"
+		if reason == PurgeReasonReplaced {
+			purge, idField = c.purger.PurgeVersion, "version_id"
+		}
+		for _, id := range result.Candidates[reason] {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			if err := purge(ctx, id); err != nil {
+				result.Failed++
+				stage := PurgeStageDatabase
+				var purgeErr *PurgeError
+				if errors.As(err, &purgeErr) {
+					stage = purgeErr.Stage
+				}
+				c.metrics.recordFailure(stage)
+				c.logger.LogError("Failed to purge video", map[string]interface{}{
+					"error":  err.Error(),
+					idField:  id,
+					"reason": reason,
+					"stage":  stage,
+				})
+				continue
+			}
+			result.Purged++
+			c.metrics.recordPurged(reason)
+		}
+	}
+
+	if result.Purged > 0 || result.Failed > 0 {
+		c.logger.LogInfo("Purged orphaned video storage", map[string]interface{}{
+			"purged": result.Purged,
+			"failed": result.Failed,
+		})
+	}
+	return result, nil
+}
+
+// deletedCandidates returns videos soft-deleted before the retention cutoff
+func (c *OrphanCleaner) deletedCandidates(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	cutoff := c.now().Add(-c.config.DeletedAfter)
+	err := c.db.WithContext(ctx).Unscoped().Model(&Video{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Order("deleted_at").
+		Limit(c.config.BatchSize).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// failedCandidates returns live videos whose first upload failed or was
+// interrupted before the cutoff. Deleted ones are left to deletedCandidates,
+// and a failed replacement keeps the video, which still has its metadata and
+// comments, for the owner to retry.
+func (c *OrphanCleaner) failedCandidates(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	cutoff := c.now().Add(-c.config.FailedAfter)
+	err := c.db.WithContext(ctx).Model(&VideoUpload{}).
+		Joins("JOIN videos ON videos.id = video_uploads.video_id AND videos.deleted_at IS NULL").
+		Where("video_uploads.status IN ?", []UploadStatus{UploadStatusFailed, UploadStatusInterrupted}).
+		Where("video_uploads.version = 1").
+		Where("video_uploads.updated_at < ?", cutoff).
+		Order("video_uploads.updated_at").
+		Limit(c.config.BatchSize).
+		Pluck("video_uploads.video_id", &ids).Error
+	return ids, err
+}
+
+// replacedCandidates returns the versions replaced before the cutoff whose
+// archived files have not been purged yet
+func (c *OrphanCleaner) replacedCandidates(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	cutoff := c.now().Add(-c.config.VersionsAfter)
+	err := c.db.WithContext(ctx).Model(&VideoVersion{}).
+		Where("purged_at IS NULL AND replaced_at < ?", cutoff).
+		Order("replaced_at").
+		Limit(c.config.BatchSize).
+		Pluck("id", &ids).Error
+	return ids, err
+}
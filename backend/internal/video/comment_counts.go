@@ -0,0 +1,149 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CommentCounter counts the comments of several videos at once, leaving out
+// videos without comments
+type CommentCounter interface {
+	CountByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+// CommentCountConfig controls the comment count reconciler
+type CommentCountConfig struct {
+	// Interval between reconciliation runs
+	Interval time.Duration
+	// BatchSize is how many videos are recounted at once
+	BatchSize int
+}
+
+// CommentCountReconciler periodically recounts the comments of every video
+// in the comment store and corrects the comment counts that drifted, such as
+// when a comment event was lost or applied twice
+type CommentCountReconciler struct {
+	db       *gorm.DB
+	comments CommentCounter
+	config   CommentCountConfig
+	logger   Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewCommentCountReconciler creates a reconciler; call Start to run it periodically
+func NewCommentCountReconciler(db *gorm.DB, comments CommentCounter, config CommentCountConfig, logger Logger) *CommentCountReconciler {
+	if config.Interval <= 0 {
+		config.Interval = 6 * time.Hour
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 100
+	}
+	return &CommentCountReconciler{
+		db:       db,
+		comments: comments,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Start reconciles once and then on every interval until Stop is called
+func (r *CommentCountReconciler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				r.logger.LogError("Comment count reconciliation failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the reconciler and waits for a running pass to finish
+func (r *CommentCountReconciler) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+}
+
+// RunOnce walks every video in batches and sets the comment counts that
+// differ from the comment store, returning how many it corrected. A comment
+// event applied while its video is recounted can be overwritten; the next
+// run corrects that.
+func (r *CommentCountReconciler) RunOnce(ctx context.Context) (int, error) {
+	corrected := 0
+	last := uuid.Nil
+	for {
+		var videos []Video
+		if err := r.db.WithContext(ctx).Select("id", "comment_count").
+			Where("id > ?", last).
+			Order("id").
+			Limit(r.config.BatchSize).
+			Find(&videos).Error; err != nil {
+			return corrected, fmt.Errorf("failed to list videos: %w", err)
+		}
+		if len(videos) == 0 {
+			break
+		}
+
+		ids := make([]uuid.UUID, len(videos))
+		for i := range videos {
+			ids[i] = videos[i].ID
+		}
+		counts, err := r.comments.CountByVideos(ctx, ids)
+		if err != nil {
+			return corrected, fmt.Errorf("failed to count comments: %w", err)
+		}
+
+		for _, v := range videos {
+			count := int64(counts[v.ID])
+			if v.CommentCount == count {
+				continue
+			}
+			if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", v.ID).
+				UpdateColumn("comment_count", count).Error; err != nil {
+				return corrected, fmt.Errorf("failed to correct comment count of %s: %w", v.ID, err)
+			}
+			corrected++
+			r.logger.LogDebug("Corrected comment count", map[string]interface{}{
+				"videoID": v.ID,
+				"was":     v.CommentCount,
+				"count":   count,
+			})
+		}
+
+		if len(videos) < r.config.BatchSize {
+			break
+		}
+		last = videos[len(videos)-1].ID
+	}
+
+	if corrected > 0 {
+		r.logger.LogInfo("Corrected drifted comment counts", map[string]interface{}{
+			"corrected": corrected,
+		})
+	}
+	return corrected, nil
+}
@@ -0,0 +1,47 @@
+package video
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// CommentsPolicy is whether and how a video takes comments
+type CommentsPolicy string
+
+const (
+	// CommentsEnabled publishes comments as they are posted
+	CommentsEnabled CommentsPolicy = "enabled"
+	// CommentsDisabled rejects new comments; existing ones are still listed
+	CommentsDisabled CommentsPolicy = "disabled"
+	// CommentsReviewRequired holds comments by anyone but the owner until the
+	// owner approves them
+	CommentsReviewRequired CommentsPolicy = "review_required"
+)
+
+// IsValid reports whether p is a known policy
+func (p CommentsPolicy) IsValid() bool {
+	switch p {
+	case CommentsEnabled, CommentsDisabled, CommentsReviewRequired:
+		return true
+	}
+	return false
+}
+
+// SetCommentsPolicy changes whether and how a video takes comments
+func (s *VideoServiceImpl) SetCommentsPolicy(ctx context.Context, videoID uuid.UUID, policy CommentsPolicy) error {
+	if err := s.repo.SetCommentsPolicy(ctx, videoID, policy); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	return nil
+}
+
+// SetEmbeddable allows or forbids playing a video in the embedded player
+func (s *VideoServiceImpl) SetEmbeddable(ctx context.Context, videoID uuid.UUID, embeddable bool) error {
+	if err := s.repo.SetEmbeddable(ctx, videoID, embeddable); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	return nil
+}
@@ -0,0 +1,104 @@
+package video
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxCountries is the most countries a video may be allowed or blocked in
+const MaxCountries = 250
+
+// ErrInvalidCountry is returned for country codes that are not two letters
+var ErrInvalidCountry = errors.New("invalid country code")
+
+// CountryList is a list of ISO 3166-1 alpha-2 country codes, stored as JSON
+type CountryList []string
+
+// Value implements driver.Valuer
+func (l CountryList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (l *CountryList) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into CountryList", value)
+	}
+	return json.Unmarshal(raw, l)
+}
+
+// contains reports whether the list has country
+func (l CountryList) contains(country string) bool {
+	for _, code := range l {
+		if code == country {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeCountries uppercases, sorts and deduplicates country codes,
+// which must be ISO 3166-1 alpha-2 codes such as US or DE
+func NormalizeCountries(codes []string) (CountryList, error) {
+	seen := make(map[string]bool, len(codes))
+	countries := CountryList{}
+	for _, code := range codes {
+		country := strings.ToUpper(strings.TrimSpace(code))
+		if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCountry, code)
+		}
+		if !seen[country] {
+			seen[country] = true
+			countries = append(countries, country)
+		}
+	}
+	if len(countries) > MaxCountries {
+		return nil, fmt.Errorf("%w: at most %d countries may be listed", ErrInvalidCountry, MaxCountries)
+	}
+	sort.Strings(countries)
+	return countries, nil
+}
+
+// AvailableIn reports whether the video may be played in a country, given
+// as an ISO code, or an empty string when it is not known. Videos with
+// allowed countries are only played where the country is known to be one of
+// them; blocked countries only stop playback where the country is known.
+func (v *Video) AvailableIn(country string) bool {
+	if len(v.AllowedCountries) > 0 && (country == "" || !v.AllowedCountries.contains(country)) {
+		return false
+	}
+	return country == "" || !v.BlockedCountries.contains(country)
+}
+
+// SetCountries replaces the countries a video is only played in, and those
+// it is never played in; empty lists lift the restriction
+func (s *VideoServiceImpl) SetCountries(ctx context.Context, videoID uuid.UUID, allowed, blocked CountryList) error {
+	if err := s.repo.SetCountries(ctx, videoID, allowed, blocked); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	return nil
+}
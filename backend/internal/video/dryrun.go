@@ -0,0 +1,212 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+)
+
+// DryRunOptions controls what a pipeline dry run plans
+type DryRunOptions struct {
+	// Renditions to plan; empty plans the ladder uploads are transcoded to
+	Resolutions []string
+	// Seconds of each rendition to encode to estimate transcode times; 0 skips encoding
+	SampleSeconds float64
+}
+
+// DryRunReport describes what the upload pipeline would do with a file
+type DryRunReport struct {
+	File   string      `json:"file"`
+	Size   int64       `json:"size"`
+	Source *SourceInfo `json:"source,omitempty"`
+	// Stages in the order the pipeline runs them
+	Stages []PlannedStage `json:"stages"`
+	Checks []DryRunCheck  `json:"checks"`
+	// Valid is true when every check passed
+	Valid bool `json:"valid"`
+	// EstimatedSeconds is the sum of the stage estimates
+	EstimatedSeconds float64 `json:"estimated_seconds"`
+}
+
+// SourceInfo is the probed metadata of the source file
+type SourceInfo struct {
+	Duration   float64 `json:"duration"`
+	Width      int     `json:"width"`
+	Height     int     `json:"height"`
+	Format     string  `json:"format"`
+	VideoCodec string  `json:"video_codec"`
+	AudioCodec string  `json:"audio_codec"`
+	Bitrate    int64   `json:"bitrate"`
+
+	Streams []ffmpeg.StreamInfo `json:"streams"`
+}
+
+// PlannedStage is one step of the pipeline
+type PlannedStage struct {
+	Name       string `json:"name"` // "probe" or "transcode"
+	Resolution string `json:"resolution,omitempty"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	// TimeoutSeconds is the deadline the pipeline would apply; 0 means none
+	TimeoutSeconds float64 `json:"timeout_seconds,omitempty"`
+	// EstimatedSeconds is measured for probes and extrapolated from the sample encode for transcodes
+	EstimatedSeconds float64 `json:"estimated_seconds,omitempty"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// DryRunCheck is the result of one validation
+type DryRunCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// DryRun runs a file through the checks, probing and rendition planning of
+// the upload pipeline without writing to storage or the database. With
+// SampleSeconds set, the start of each rendition is encoded to a temporary
+// directory to check the encoder settings and estimate transcode times.
+// Operators use it to tune FFmpeg profiles before rolling them out.
+func DryRun(ctx context.Context, ff *ffmpeg.Service, limits LimitsConfig, path string, opts DryRunOptions) (*DryRunReport, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample file: %w", err)
+	}
+
+	report := &DryRunReport{File: filepath.Base(path), Size: info.Size()}
+	report.check("file_size", limits.MaxFileSize <= 0 || info.Size() <= limits.MaxFileSize,
+		fmt.Sprintf("file size exceeds maximum allowed size of %d bytes", limits.MaxFileSize))
+	report.check("file_type", allowedFormat(path, limits.AllowedFormats),
+		fmt.Sprintf("invalid file type, allowed formats: %v", limits.AllowedFormats))
+
+	start := time.Now()
+	metadata, err := ff.GetMetadata(ctx, path)
+	probe := PlannedStage{Name: "probe", EstimatedSeconds: time.Since(start).Seconds()}
+	if err != nil {
+		probe.Error = err.Error()
+		report.Stages = append(report.Stages, probe)
+		report.check("probe", false, err.Error())
+		return report.finish(), nil
+	}
+	report.Stages = append(report.Stages, probe)
+	report.check("probe", true, "")
+
+	report.Source = &SourceInfo{
+		Duration:   metadata.Duration,
+		Width:      metadata.Width,
+		Height:     metadata.Height,
+		Format:     metadata.Format,
+		VideoCodec: metadata.VideoCodec,
+		AudioCodec: metadata.AudioCodec,
+		Bitrate:    metadata.Bitrate,
+		Streams:    metadata.Streams,
+	}
+	report.check("duration", metadata.Duration > 0, "source duration is unknown, so transcodes run without a deadline")
+	report.check("video_stream", metadata.Validate() == nil, "no video stream found")
+	if metadata.Validate() == nil {
+		// The codec and duration limits and the decode check applied to uploads
+		mediaErr := limits.CheckMedia(metadata)
+		report.check("media", mediaErr == nil, fmt.Sprint(mediaErr))
+		decodeErr := ff.VerifyDecodable(ctx, path)
+		report.check("decode", decodeErr == nil, fmt.Sprint(decodeErr))
+	}
+
+	resolutions := opts.Resolutions
+	if len(resolutions) == 0 {
+		resolutions = transcodeLadder
+	}
+
+	var sampleDir string
+	if opts.SampleSeconds > 0 && metadata.Validate() == nil {
+		sampleDir, err = os.MkdirTemp("", "pavilion-dryrun-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create sample directory: %w", err)
+		}
+		defer os.RemoveAll(sampleDir)
+	}
+
+	for _, resolution := range resolutions {
+		stage := PlannedStage{Name: "transcode", Resolution: resolution}
+
+		width, height, err := ff.OutputDimensions(resolution, metadata)
+		if err != nil {
+			stage.Error = err.Error()
+			report.Stages = append(report.Stages, stage)
+			report.check("resolution:"+resolution, false, err.Error())
+			continue
+		}
+		stage.Width, stage.Height = width, height
+		stage.TimeoutSeconds = ff.TranscodeTimeout(metadata.Duration).Seconds()
+
+		if sampleDir != "" {
+			report.sample(ctx, ff, &stage, path, filepath.Join(sampleDir, resolution+".mp4"), metadata.Duration, opts.SampleSeconds)
+		}
+		report.Stages = append(report.Stages, stage)
+	}
+
+	return report.finish(), nil
+}
+
+// sample encodes the start of a rendition and extrapolates the time to
+// encode all of it
+func (r *DryRunReport) sample(ctx context.Context, ff *ffmpeg.Service, stage *PlannedStage, inputPath, outputPath string, duration, seconds float64) {
+	if duration > 0 && seconds > duration {
+		seconds = duration
+	}
+
+	start := time.Now()
+	err := ff.EncodeSample(ctx, inputPath, outputPath, stage.Resolution, stage.Width, stage.Height, seconds)
+	elapsed := time.Since(start).Seconds()
+	if err != nil {
+		stage.Error = err.Error()
+		r.check("encode:"+stage.Resolution, false, err.Error())
+		return
+	}
+	r.check("encode:"+stage.Resolution, true, "")
+
+	if duration <= 0 {
+		return
+	}
+	stage.EstimatedSeconds = elapsed * duration / seconds
+	if stage.TimeoutSeconds > 0 {
+		r.check("deadline:"+stage.Resolution, stage.EstimatedSeconds <= stage.TimeoutSeconds,
+			fmt.Sprintf("estimated %.0fs exceeds the %.0fs deadline", stage.EstimatedSeconds, stage.TimeoutSeconds))
+	}
+}
+
+// check records a validation; message is kept only for failures
+func (r *DryRunReport) check(name string, passed bool, message string) {
+	if passed {
+		message = ""
+	}
+	r.Checks = append(r.Checks, DryRunCheck{Name: name, Passed: passed, Message: message})
+}
+
+// finish totals the estimates and decides whether the file would pass
+func (r *DryRunReport) finish() *DryRunReport {
+	r.Valid = true
+	for _, check := range r.Checks {
+		if !check.Passed {
+			r.Valid = false
+		}
+	}
+	for _, stage := range r.Stages {
+		r.EstimatedSeconds += stage.EstimatedSeconds
+	}
+	return r
+}
+
+// allowedFormat reports whether path has one of the allowed extensions
+func allowedFormat(path string, formats []string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, format := range formats {
+		if format == ext {
+			return true
+		}
+	}
+	return false
+}
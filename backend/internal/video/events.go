@@ -0,0 +1,157 @@
+package video
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload event names, as sent in the event field of GET /video/:id/events
+const (
+	// UploadEventStatus carries a VideoStatusResponse: the full status when
+	// the stream opens and each time it is read again
+	UploadEventStatus = "status"
+	// UploadEventStage carries a StageEvent when the upload reaches a stage or ends
+	UploadEventStage = "stage"
+	// UploadEventStored carries a StoredEvent while the original is stored
+	UploadEventStored = "stored"
+	// UploadEventRendition carries a RenditionEvent when a rendition changes status
+	UploadEventRendition = "rendition"
+	// UploadEventProgress carries a TranscodeProgressEvent while a resolution is encoded
+	UploadEventProgress = "progress"
+	// UploadEventEnd carries the final VideoStatusResponse; the stream closes after it
+	UploadEventEnd = "end"
+)
+
+// uploadEventBuffer is how many events a slow client may fall behind by
+// before further events are dropped for it
+const uploadEventBuffer = 64
+
+// UploadEvent is a change in the processing of an upload. Data is one of
+// the event types below.
+type UploadEvent struct {
+	Name string
+	Data interface{}
+}
+
+// StageEvent reports that an upload reached a stage, or ended with Status
+type StageEvent struct {
+	Status UploadStatus `json:"status"`
+	Stage  UploadStage  `json:"stage"`
+	At     time.Time    `json:"at"`
+	// DuplicateOf is set when the upload was discarded as a duplicate of this video
+	DuplicateOf *uuid.UUID `json:"duplicate_of,omitempty"`
+}
+
+// StoredEvent reports how much of the original has reached storage
+type StoredEvent struct {
+	StoredBytes int64 `json:"stored_bytes"`
+	// TotalBytes is -1 when the size of the file is not known
+	TotalBytes int64 `json:"total_bytes"`
+}
+
+// RenditionEvent reports a rendition's new status
+type RenditionEvent struct {
+	Resolution string          `json:"resolution"`
+	Status     RenditionStatus `json:"status"`
+}
+
+// TranscodeProgressEvent reports how much of the source a resolution has
+// been encoded from, in whole percent
+type TranscodeProgressEvent struct {
+	Resolution string `json:"resolution"`
+	Percent    int    `json:"percent"`
+}
+
+// UploadEvents fans the progress of uploads processed by this instance out
+// to the clients following them. A nil *UploadEvents publishes nothing and
+// its subscriptions never receive events.
+type UploadEvents struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan UploadEvent]struct{}
+	closed      bool
+}
+
+// NewUploadEvents creates an upload event hub
+func NewUploadEvents() *UploadEvents {
+	return &UploadEvents{subscribers: make(map[uuid.UUID]map[chan UploadEvent]struct{})}
+}
+
+// Subscribe returns the events published for videoID from now on, and a
+// function that ends the subscription. The channel is closed when the
+// upload's processing ends, or by Close.
+func (e *UploadEvents) Subscribe(videoID uuid.UUID) (<-chan UploadEvent, func()) {
+	if e == nil {
+		return nil, func() {}
+	}
+	ch := make(chan UploadEvent, uploadEventBuffer)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if e.subscribers[videoID] == nil {
+		e.subscribers[videoID] = make(map[chan UploadEvent]struct{})
+	}
+	e.subscribers[videoID][ch] = struct{}{}
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.subscribers[videoID][ch]; ok {
+			delete(e.subscribers[videoID], ch)
+			if len(e.subscribers[videoID]) == 0 {
+				delete(e.subscribers, videoID)
+			}
+			close(ch)
+		}
+	}
+}
+
+// Publish hands an event to each subscriber of videoID. Subscribers whose
+// buffer is full miss it; the status they read again catches them up.
+func (e *UploadEvents) Publish(videoID uuid.UUID, name string, data interface{}) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subscribers[videoID] {
+		select {
+		case ch <- UploadEvent{Name: name, Data: data}:
+		default:
+		}
+	}
+}
+
+// Finish closes the subscriptions to videoID, whose processing has ended
+func (e *UploadEvents) Finish(videoID uuid.UUID) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subscribers[videoID] {
+		close(ch)
+	}
+	delete(e.subscribers, videoID)
+}
+
+// Close ends every subscription and refuses new ones, so streams end at shutdown
+func (e *UploadEvents) Close() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	for videoID, subscribers := range e.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+		delete(e.subscribers, videoID)
+	}
+}
@@ -0,0 +1,202 @@
+package ffmpeg
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Hardware accelerators for H.264 encoding. HWAccelAuto uses the first one
+// that works on this machine.
+const (
+	HWAccelNone         = "none"
+	HWAccelAuto         = "auto"
+	HWAccelVAAPI        = "vaapi"
+	HWAccelNVENC        = "nvenc"
+	HWAccelVideoToolbox = "videotoolbox"
+)
+
+// DefaultVAAPIDevice is the render node used for VAAPI when none is configured
+const DefaultVAAPIDevice = "/dev/dri/renderD128"
+
+// detectTimeout bounds each FFmpeg run made while detecting an accelerator
+const detectTimeout = 15 * time.Second
+
+// hwEncoders maps each accelerator to its FFmpeg H.264 encoder
+var hwEncoders = map[string]string{
+	HWAccelVAAPI:        "h264_vaapi",
+	HWAccelNVENC:        "h264_nvenc",
+	HWAccelVideoToolbox: "h264_videotoolbox",
+}
+
+// ValidHWAccel reports whether name is a supported hwAccel setting. An empty
+// name means software encoding.
+func ValidHWAccel(name string) bool {
+	switch name {
+	case "", HWAccelNone, HWAccelAuto:
+		return true
+	}
+	_, ok := hwEncoders[name]
+	return ok
+}
+
+// Profile is the encoding settings of one rendition. Bitrates use FFmpeg
+// notation, e.g. 2500k; empty or zero values leave FFmpeg's defaults.
+type Profile struct {
+	// VideoBitrate is the target video bitrate
+	VideoBitrate string `mapstructure:"videoBitrate" yaml:"video_bitrate" doc:"Target video bitrate, e.g. 2500k; empty encodes at constant quality"`
+	// MaxBitrate caps the video bitrate, also when encoding at constant quality
+	MaxBitrate string `mapstructure:"maxBitrate" yaml:"max_bitrate" doc:"Peak video bitrate, e.g. 3000k"`
+	// CRF is the constant quality level; lower is better. Hardware encoders
+	// use it as their nearest equivalent, except VideoToolbox, which ignores it.
+	CRF int `mapstructure:"crf" yaml:"crf" doc:"Constant quality level, lower is better (0-51); 0 leaves the encoder default"`
+	// AudioBitrate is ignored when the audio stream is copied
+	AudioBitrate string `mapstructure:"audioBitrate" yaml:"audio_bitrate" doc:"Audio bitrate, e.g. 128k; ignored when audioCodec is copy"`
+}
+
+// Encoder returns the accelerator used for encoding, or HWAccelNone for
+// software. The configured accelerator is checked the first time, by
+// encoding a few test frames; if it does not work, encoding falls back to
+// software and a warning is logged.
+func (s *Service) Encoder(ctx context.Context) string {
+	s.detectOnce.Do(func() {
+		s.encoder = s.detectEncoder(context.WithoutCancel(ctx))
+	})
+	return s.encoder
+}
+
+func (s *Service) detectEncoder(ctx context.Context) string {
+	var candidates []string
+	switch s.config.HWAccel {
+	case "", HWAccelNone:
+		return HWAccelNone
+	case HWAccelAuto:
+		candidates = []string{HWAccelNVENC, HWAccelVAAPI}
+		if runtime.GOOS == "darwin" {
+			candidates = []string{HWAccelVideoToolbox}
+		}
+	default:
+		candidates = []string{s.config.HWAccel}
+	}
+
+	for _, accel := range candidates {
+		if err := s.probeEncoder(ctx, accel); err != nil {
+			s.logger.LogWarn("Hardware encoder unavailable", map[string]interface{}{
+				"accelerator": accel,
+				"error":       err.Error(),
+			})
+			continue
+		}
+		s.logger.LogInfo("Using hardware encoder", map[string]interface{}{
+			"accelerator": accel,
+			"encoder":     hwEncoders[accel],
+		})
+		return accel
+	}
+
+	s.logger.LogWarn("Falling back to software encoding", map[string]interface{}{
+		"hw_accel":    s.config.HWAccel,
+		"video_codec": s.config.VideoCodec,
+	})
+	return HWAccelNone
+}
+
+// probeEncoder checks that FFmpeg was built with the accelerator's encoder
+// and that it can encode on this machine's hardware
+func (s *Service) probeEncoder(ctx context.Context, accel string) error {
+	encoder, ok := hwEncoders[accel]
+	if !ok {
+		return fmt.Errorf("unknown accelerator %q", accel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, detectTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, s.config.Path, "-hide_banner", "-encoders").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list FFmpeg encoders: %w", err)
+	}
+	if !hasEncoder(string(output), encoder) {
+		return fmt.Errorf("FFmpeg has no %s encoder", encoder)
+	}
+
+	// A build can include the encoder without the driver or device it needs
+	args := []string{"-hide_banner", "-v", "error"}
+	args = append(args, s.deviceArgs(accel)...)
+	args = append(args, "-f", "lavfi", "-i", "color=c=black:s=256x144:d=0.1")
+	args = append(args, s.videoEncodeArgs(accel, 256, 144)...)
+	args = append(args, "-frames:v", "1", "-f", "null", "-")
+	if output, err := exec.CommandContext(ctx, s.config.Path, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("test encode failed: %w: %s", err, lastLine(string(output)))
+	}
+	return nil
+}
+
+// hasEncoder reports whether the output of "ffmpeg -encoders" lists encoder
+func hasEncoder(output, encoder string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[1] == encoder {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceArgs returns the arguments that open the accelerator's device; they go before the input
+func (s *Service) deviceArgs(accel string) []string {
+	if accel != HWAccelVAAPI {
+		return nil
+	}
+	device := s.config.VAAPIDevice
+	if device == "" {
+		device = DefaultVAAPIDevice
+	}
+	return []string{"-vaapi_device", device}
+}
+
+// videoEncodeArgs returns the arguments that scale frames to width x height
+// and encode them with the accelerator, or in software for HWAccelNone
+func (s *Service) videoEncodeArgs(accel string, width, height int) []string {
+	dimensions := fmt.Sprintf("%dx%d", width, height)
+	switch accel {
+	case HWAccelVAAPI:
+		// Frames are uploaded to the GPU and scaled there
+		return []string{"-vf", fmt.Sprintf("format=nv12,hwupload,scale_vaapi=w=%d:h=%d", width, height), "-c:v", hwEncoders[accel]}
+	case HWAccelNVENC, HWAccelVideoToolbox:
+		return []string{"-s", dimensions, "-c:v", hwEncoders[accel]}
+	default:
+		return []string{"-c:v", s.config.VideoCodec, "-s", dimensions, "-preset", s.config.Preset}
+	}
+}
+
+// profileArgs returns the rate control and audio arguments of a profile
+func (s *Service) profileArgs(accel string, profile Profile) []string {
+	var args []string
+	if profile.CRF > 0 {
+		switch accel {
+		case HWAccelNVENC:
+			args = append(args, "-cq", fmt.Sprint(profile.CRF))
+		case HWAccelVAAPI:
+			args = append(args, "-qp", fmt.Sprint(profile.CRF))
+		case HWAccelVideoToolbox:
+			// VideoToolbox has no equivalent quality scale; bitrates still apply
+		default:
+			args = append(args, "-crf", fmt.Sprint(profile.CRF))
+		}
+	}
+	if profile.VideoBitrate != "" {
+		args = append(args, "-b:v", profile.VideoBitrate)
+	}
+	if profile.MaxBitrate != "" {
+		args = append(args, "-maxrate", profile.MaxBitrate, "-bufsize", profile.MaxBitrate)
+	}
+
+	args = append(args, "-c:a", s.config.AudioCodec)
+	if profile.AudioBitrate != "" && s.config.AudioCodec != "copy" {
+		args = append(args, "-b:a", profile.AudioBitrate)
+	}
+	return args
+}
@@ -0,0 +1,100 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) LogInfo(string, map[string]interface{})                {}
+func (nopLogger) LogError(err error, _ string) error                    { return err }
+func (nopLogger) LogErrorf(err error, _ string, _ ...interface{}) error { return err }
+func (nopLogger) LogFatal(error, string)                                {}
+func (nopLogger) LogDebug(string, map[string]interface{})               {}
+func (nopLogger) LogWarn(string, map[string]interface{})                {}
+func (l nopLogger) WithFields(map[string]interface{}) logger.Logger     { return l }
+func (l nopLogger) WithContext(context.Context) logger.Logger           { return l }
+func (l nopLogger) WithRequestID(string) logger.Logger                  { return l }
+func (l nopLogger) WithUserID(string) logger.Logger                     { return l }
+
+// fakeFFmpeg writes a script standing in for FFmpeg. It lists encoders and
+// fails test encodes with workingEncoder missing from its arguments.
+func fakeFFmpeg(t *testing.T, encoders, workingEncoder string) string {
+	t.Helper()
+	script := `#!/bin/sh
+case "$*" in
+*-encoders*) printf '%s\n' "` + encoders + `"; exit 0 ;;
+*` + workingEncoder + `*) exit 0 ;;
+*) echo "Device creation failed" >&2; exit 1 ;;
+esac
+`
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+const encoderList = `Encoders:
+ V....D libx264              libx264 H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10 (codec h264)
+ V....D h264_nvenc           NVIDIA NVENC H.264 encoder (codec h264)
+ V....D h264_vaapi           H.264/AVC (VAAPI) (codec h264)`
+
+func TestEncoder_UsesWorkingAccelerator(t *testing.T) {
+	service := NewService(&Config{Path: fakeFFmpeg(t, encoderList, "h264_vaapi"), HWAccel: HWAccelVAAPI}, nopLogger{})
+	assert.Equal(t, HWAccelVAAPI, service.Encoder(context.Background()))
+}
+
+func TestEncoder_FallsBackToSoftware(t *testing.T) {
+	// NVENC is built in, but the test encode fails as there is no GPU
+	service := NewService(&Config{Path: fakeFFmpeg(t, encoderList, "none"), HWAccel: HWAccelNVENC}, nopLogger{})
+	assert.Equal(t, HWAccelNone, service.Encoder(context.Background()))
+
+	// VideoToolbox is not built in at all
+	service = NewService(&Config{Path: fakeFFmpeg(t, encoderList, "h264_videotoolbox"), HWAccel: HWAccelVideoToolbox}, nopLogger{})
+	assert.Equal(t, HWAccelNone, service.Encoder(context.Background()))
+}
+
+func TestEncoder_SoftwareSkipsDetection(t *testing.T) {
+	service := NewService(&Config{Path: "/nonexistent/ffmpeg", HWAccel: HWAccelNone}, nopLogger{})
+	assert.Equal(t, HWAccelNone, service.Encoder(context.Background()))
+}
+
+func TestEncodeArgs(t *testing.T) {
+	service := NewService(&Config{
+		VideoCodec:  "libx264",
+		AudioCodec:  "aac",
+		Preset:      "fast",
+		VAAPIDevice: "/dev/dri/renderD129",
+		Profiles: map[string]Profile{
+			"720p": {CRF: 23, MaxBitrate: "3000k", AudioBitrate: "128k"},
+			"360p": {VideoBitrate: "600k"},
+		},
+	}, nopLogger{})
+
+	args := strings.Join(service.encodeArgs(HWAccelNone, "in.mp4", "out.mp4", "720p", 1280, 720, 0), " ")
+	assert.Equal(t, "-i in.mp4 -c:v libx264 -s 1280x720 -preset fast -crf 23 -maxrate 3000k -bufsize 3000k -c:a aac -b:a 128k -y out.mp4", args)
+
+	args = strings.Join(service.encodeArgs(HWAccelVAAPI, "in.mp4", "out.mp4", "720p", 1280, 720, 5), " ")
+	assert.Equal(t, "-vaapi_device /dev/dri/renderD129 -i in.mp4 -t 5 -vf format=nv12,hwupload,scale_vaapi=w=1280:h=720 -c:v h264_vaapi -qp 23 -maxrate 3000k -bufsize 3000k -c:a aac -b:a 128k -y out.mp4", args)
+
+	args = strings.Join(service.encodeArgs(HWAccelNVENC, "in.mp4", "out.mp4", "360p", 640, 360, 0), " ")
+	assert.Equal(t, "-i in.mp4 -s 640x360 -c:v h264_nvenc -b:v 600k -c:a aac -y out.mp4", args)
+
+	// Resolutions without a profile keep FFmpeg's defaults
+	args = strings.Join(service.encodeArgs(HWAccelNone, "in.mp4", "out.mp4", "480p", 854, 480, 0), " ")
+	assert.Equal(t, "-i in.mp4 -c:v libx264 -s 854x480 -preset fast -c:a aac -y out.mp4", args)
+}
+
+func TestProfileArgs_CopiedAudioKeepsBitrate(t *testing.T) {
+	service := NewService(&Config{AudioCodec: "copy"}, nopLogger{})
+	assert.Equal(t, []string{"-c:a", "copy"}, service.profileArgs(HWAccelNone, Profile{AudioBitrate: "128k"}))
+}
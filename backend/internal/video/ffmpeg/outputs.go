@@ -0,0 +1,191 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrNoAudioStream is returned by ExtractAudio for sources without audio
+var ErrNoAudioStream = errors.New("file has no audio stream")
+
+// Codecs for the audio-only rendition. Both are stored in an M4A container.
+const (
+	AudioCodecAAC  = "aac"
+	AudioCodecOpus = "libopus"
+)
+
+// AudioOutput configures the audio-only rendition, which lets listeners on
+// slow connections play a video's sound without its picture
+type AudioOutput struct {
+	Enabled bool   `mapstructure:"enabled" yaml:"enabled" doc:"Store an audio-only rendition of uploads that have sound"`
+	Codec   string `mapstructure:"codec" yaml:"codec" doc:"Audio codec: aac, or libopus for smaller files on browsers that support Opus in MP4"`
+	Bitrate string `mapstructure:"bitrate" yaml:"bitrate" doc:"Audio bitrate, e.g. 64k"`
+}
+
+// PreviewOutput configures the animated preview shown when hovering over a
+// video in listings
+type PreviewOutput struct {
+	Enabled bool    `mapstructure:"enabled" yaml:"enabled" doc:"Store a short animated WebP preview of each upload"`
+	Seconds float64 `mapstructure:"seconds" yaml:"seconds" doc:"Length of the preview in seconds (3-5 recommended)"`
+	Width   int     `mapstructure:"width" yaml:"width" doc:"Preview width in pixels; smaller sources are not upscaled"`
+	FPS     int     `mapstructure:"fps" yaml:"fps" doc:"Preview frame rate"`
+	Quality int     `mapstructure:"quality" yaml:"quality" doc:"WebP quality, 0-100"`
+}
+
+// ValidAudioCodec reports whether codec can be used for the audio-only rendition
+func ValidAudioCodec(codec string) bool {
+	return codec == AudioCodecAAC || codec == AudioCodecOpus
+}
+
+// AudioEnabled reports whether uploads get an audio-only rendition
+func (s *Service) AudioEnabled() bool {
+	return s.config.Audio.Enabled
+}
+
+// PreviewEnabled reports whether uploads get an animated preview
+func (s *Service) PreviewEnabled() bool {
+	return s.config.Preview.Enabled
+}
+
+// ExtractAudio writes the first audio stream of inputPath to outputPath as
+// an M4A file. It is bounded by the source's duration like a transcode.
+func (s *Service) ExtractAudio(ctx context.Context, inputPath, outputPath string, metadata *VideoMetadata) (err error) {
+	ctx, span := tracing.Start(ctx, "ffmpeg.audio", attribute.String("ffmpeg.input", inputPath))
+	defer func() { tracing.End(span, err) }()
+
+	if metadata.AudioStream() == nil {
+		return ErrNoAudioStream
+	}
+	return s.runOutput(ctx, "audio extraction", outputPath, s.TranscodeTimeout(metadata.Duration), metadata.Duration, s.audioArgs(inputPath, outputPath))
+}
+
+// Preview writes a short, silent, looping WebP animation of inputPath to
+// outputPath. The clip is taken a tenth of the way in, past most intros and
+// black frames, and kept within the source.
+func (s *Service) Preview(ctx context.Context, inputPath, outputPath string, metadata *VideoMetadata) (err error) {
+	ctx, span := tracing.Start(ctx, "ffmpeg.preview", attribute.String("ffmpeg.input", inputPath))
+	defer func() { tracing.End(span, err) }()
+
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+	clip := min(s.config.Preview.Seconds, metadata.Duration)
+	return s.runOutput(ctx, "preview", outputPath, s.TranscodeTimeout(s.config.Preview.Seconds), clip, s.previewArgs(inputPath, outputPath, metadata.Duration))
+}
+
+// BurnCaptions writes a copy of inputPath to outputPath with the WebVTT or
+// SubRip track at captionsPath drawn onto the picture, for players that
+// cannot show text tracks. The sound is copied as is.
+func (s *Service) BurnCaptions(ctx context.Context, inputPath, captionsPath, outputPath string, metadata *VideoMetadata) (err error) {
+	ctx, span := tracing.Start(ctx, "ffmpeg.burn_captions", attribute.String("ffmpeg.input", inputPath))
+	defer func() { tracing.End(span, err) }()
+
+	if err := metadata.Validate(); err != nil {
+		return err
+	}
+	return s.runOutput(ctx, "caption burn-in", outputPath, s.TranscodeTimeout(metadata.Duration), metadata.Duration, s.burnArgs(inputPath, captionsPath, outputPath))
+}
+
+func (s *Service) audioArgs(inputPath, outputPath string) []string {
+	args := []string{"-i", inputPath, "-map", "0:a:0", "-vn", "-c:a", s.config.Audio.Codec}
+	if s.config.Audio.Bitrate != "" {
+		args = append(args, "-b:a", s.config.Audio.Bitrate)
+	}
+	// The mp4 muxer rather than ipod, which .m4a selects, as it also takes Opus
+	return append(args, "-movflags", "+faststart", "-f", "mp4", "-y", outputPath)
+}
+
+func (s *Service) previewArgs(inputPath, outputPath string, duration float64) []string {
+	preview := s.config.Preview
+	start := duration / 10
+	if start+preview.Seconds > duration {
+		start = max(0, duration-preview.Seconds)
+	}
+
+	return []string{
+		"-ss", strconv.FormatFloat(start, 'f', 3, 64),
+		"-t", strconv.FormatFloat(preview.Seconds, 'f', -1, 64),
+		"-i", inputPath,
+		"-an",
+		"-vf", fmt.Sprintf("fps=%d,scale=w='min(%d,iw)':h=-2", preview.FPS, preview.Width),
+		"-c:v", "libwebp",
+		"-quality", strconv.Itoa(preview.Quality),
+		"-loop", "0",
+		"-y", outputPath,
+	}
+}
+
+func (s *Service) burnArgs(inputPath, captionsPath, outputPath string) []string {
+	// The subtitles filter runs on the CPU, so the frames are encoded there too
+	args := []string{
+		"-i", inputPath,
+		"-vf", "subtitles=filename=" + filterValue(captionsPath),
+		"-c:v", "libx264",
+	}
+	if s.config.Preset != "" {
+		args = append(args, "-preset", s.config.Preset)
+	}
+	return append(args, "-c:a", "copy", "-movflags", "+faststart", "-y", outputPath)
+}
+
+var (
+	// optionEscaper and graphEscaper quote a value for the two levels of
+	// parsing a filtergraph goes through: filter options, then the graph
+	optionEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`)
+	graphEscaper  = strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`)
+)
+
+// filterValue escapes value, such as a path, for use as a filter option
+func filterValue(value string) string {
+	return graphEscaper.Replace(optionEscaper.Replace(value))
+}
+
+// runOutput runs FFmpeg with args to produce outputPath, stopping it after
+// timeout unless that is 0. Progress set with WithProgress is reported
+// against duration, the seconds of media the output covers.
+func (s *Service) runOutput(ctx context.Context, name, outputPath string, timeout time.Duration, duration float64, args []string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	args = append([]string{"-hide_banner", "-v", "error"}, args...)
+	progress := ProgressFromContext(ctx)
+	if progress != nil && duration > 0 {
+		args = append(append([]string{}, progressArgs...), args...)
+	} else {
+		progress = nil
+	}
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.config.Path, args...)
+	cmd.Stderr = &output
+	if err := runWithProgress(cmd, &output, duration, progress); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.logger.LogError(err, fmt.Sprintf("FFmpeg %s exceeded its deadline: output=%s, timeout=%s", name, outputPath, timeout))
+			return fmt.Errorf("%s exceeded its deadline of %s: %w", name, timeout, ErrTimeout)
+		}
+		s.logger.LogError(err, fmt.Sprintf("FFmpeg %s failed: output=%s", name, outputPath))
+		return fmt.Errorf("%s failed: %w: %s", name, err, lastLine(output.String()))
+	}
+
+	if size := getFileSize(outputPath); size <= 0 {
+		return fmt.Errorf("%s produced no output: %s", name, outputPath)
+	}
+	return nil
+}
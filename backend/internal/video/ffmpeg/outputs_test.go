@@ -0,0 +1,144 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOutputFFmpeg writes a script standing in for FFmpeg that records its
+// arguments to a file next to it and writes a placeholder to its output, the
+// last argument
+func fakeOutputFFmpeg(t *testing.T) (path, argsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	path = filepath.Join(dir, "ffmpeg")
+	argsFile = filepath.Join(dir, "args")
+	script := `#!/bin/sh
+echo "$*" > "` + argsFile + `"
+for last; do :; done
+echo encoded > "$last"
+`
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path, argsFile
+}
+
+func newOutputService(path string) *Service {
+	return NewService(&Config{
+		Path:    path,
+		Audio:   AudioOutput{Enabled: true, Codec: AudioCodecOpus, Bitrate: "64k"},
+		Preview: PreviewOutput{Enabled: true, Seconds: 4, Width: 320, FPS: 10, Quality: 60},
+	}, nopLogger{})
+}
+
+var withAudio = &VideoMetadata{
+	Duration: 120,
+	Streams: []StreamInfo{
+		{Index: 0, Type: StreamTypeVideo, Codec: "h264", Width: 1920, Height: 1080},
+		{Index: 1, Type: StreamTypeAudio, Codec: "aac", Channels: 2},
+	},
+}
+
+func TestExtractAudio(t *testing.T) {
+	path, argsFile := fakeOutputFFmpeg(t)
+	service := newOutputService(path)
+	output := filepath.Join(t.TempDir(), "out", "audio.m4a")
+
+	require.NoError(t, service.ExtractAudio(context.Background(), "in.mp4", output, withAudio))
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "-hide_banner -v error -i in.mp4 -map 0:a:0 -vn -c:a libopus -b:a 64k -movflags +faststart -f mp4 -y "+output,
+		strings.TrimSpace(string(args)))
+	assert.FileExists(t, output)
+}
+
+func TestExtractAudio_NoAudioStream(t *testing.T) {
+	service := newOutputService("/nonexistent/ffmpeg")
+	silent := &VideoMetadata{Streams: []StreamInfo{{Type: StreamTypeVideo, Codec: "h264"}}}
+
+	err := service.ExtractAudio(context.Background(), "in.mp4", filepath.Join(t.TempDir(), "audio.m4a"), silent)
+	assert.ErrorIs(t, err, ErrNoAudioStream)
+}
+
+func TestPreview(t *testing.T) {
+	path, argsFile := fakeOutputFFmpeg(t)
+	service := newOutputService(path)
+	output := filepath.Join(t.TempDir(), "preview.webp")
+
+	require.NoError(t, service.Preview(context.Background(), "in.mp4", output, withAudio))
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "-hide_banner -v error -ss 12.000 -t 4 -i in.mp4 -an -vf fps=10,scale=w='min(320,iw)':h=-2 -c:v libwebp -quality 60 -loop 0 -y "+output,
+		strings.TrimSpace(string(args)))
+}
+
+func TestPreviewArgs_StartsWithinSource(t *testing.T) {
+	service := newOutputService("ffmpeg")
+
+	// A tenth of the way in would run past the end of a short clip
+	args := service.previewArgs("in.mp4", "out.webp", 4.2)
+	assert.Equal(t, []string{"-ss", "0.200"}, args[:2])
+
+	// Clips shorter than the preview start at the beginning
+	args = service.previewArgs("in.mp4", "out.webp", 2)
+	assert.Equal(t, []string{"-ss", "0.000"}, args[:2])
+}
+
+func TestBurnCaptions(t *testing.T) {
+	path, argsFile := fakeOutputFFmpeg(t)
+	service := newOutputService(path)
+	output := filepath.Join(t.TempDir(), "captions", "en.mp4")
+
+	require.NoError(t, service.BurnCaptions(context.Background(), "in.mp4", "/tmp/en.vtt", output, withAudio))
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "-hide_banner -v error -i in.mp4 -vf subtitles=filename=/tmp/en.vtt -c:v libx264 -c:a copy -movflags +faststart -y "+output,
+		strings.TrimSpace(string(args)))
+	assert.FileExists(t, output)
+}
+
+func TestFilterValue(t *testing.T) {
+	assert.Equal(t, `C\\:/subs/it\\\'s\,\[1\].vtt`, filterValue(`C:/subs/it's,[1].vtt`))
+}
+
+func TestRunOutput_ReportsFFmpegError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho 'Unknown encoder libwebp' >&2\nexit 1\n"), 0755))
+	service := newOutputService(path)
+
+	err := service.Preview(context.Background(), "in.mp4", filepath.Join(t.TempDir(), "preview.webp"), withAudio)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown encoder libwebp")
+	assert.NotErrorIs(t, err, ErrTimeout)
+}
+
+func TestPreview_ReportsProgress(t *testing.T) {
+	// The fake FFmpeg writes progress when asked to, then its output
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	script := `#!/bin/sh
+case "$*" in -progress\ pipe:1\ -nostats\ *) printf 'out_time_us=1000000\nprogress=continue\nout_time_us=4000000\nprogress=end\n';; esac
+for last; do :; done
+echo encoded > "$last"
+`
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	service := newOutputService(path)
+
+	var reported []float64
+	ctx := WithProgress(context.Background(), func(percent float64) {
+		reported = append(reported, percent)
+	})
+	output := filepath.Join(t.TempDir(), "preview.webp")
+	require.NoError(t, service.Preview(ctx, "in.mp4", output, withAudio))
+
+	// Progress is measured against the clip, not the whole source
+	assert.Equal(t, []float64{25, 100}, reported)
+	assert.FileExists(t, output)
+}
@@ -0,0 +1,237 @@
+package ffmpeg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrNoVideoStream is returned by VideoMetadata.Validate for files without
+// a video stream, such as audio files, which cannot be transcoded
+var ErrNoVideoStream = errors.New("file has no video stream")
+
+// ErrUnreadable is wrapped by errors for files FFmpeg cannot read, such as
+// text files renamed to .mp4 or truncated uploads
+var ErrUnreadable = errors.New("file is not a readable media file")
+
+// Stream types reported by FFprobe
+const (
+	StreamTypeVideo    = "video"
+	StreamTypeAudio    = "audio"
+	StreamTypeSubtitle = "subtitle"
+)
+
+// StreamInfo describes one stream of a media file
+type StreamInfo struct {
+	Index     int     `json:"index"`                // Position of the stream in the container
+	Type      string  `json:"type"`                 // Stream type: video, audio, subtitle, data or attachment
+	Codec     string  `json:"codec"`                // Codec name, e.g. h264 or aac
+	Width     int     `json:"width,omitempty"`      // Width in pixels; video only
+	Height    int     `json:"height,omitempty"`     // Height in pixels; video only
+	FrameRate float64 `json:"frame_rate,omitempty"` // Frames per second; video only
+	Rotation  int     `json:"rotation,omitempty"`   // Display rotation in degrees, clockwise, normalized to 0, 90, 180 or 270; video only
+	Channels  int     `json:"channels,omitempty"`   // Number of channels; audio only
+	// AttachedPicture is set for cover art, which FFprobe reports as a video
+	// stream with a single frame
+	AttachedPicture bool    `json:"attached_picture,omitempty"`
+	Duration        float64 `json:"duration,omitempty"` // Duration in seconds; 0 when the container does not report it per stream
+	Bitrate         int64   `json:"bitrate,omitempty"`  // Bitrate in bits per second; 0 when unknown
+	Language        string  `json:"language,omitempty"` // ISO 639-2 language tag, if any
+}
+
+// Validate checks that the file can be transcoded
+func (m *VideoMetadata) Validate() error {
+	if m.VideoStream() == nil {
+		return ErrNoVideoStream
+	}
+	return nil
+}
+
+// VerifyDecodable decodes the first frame of the file's video stream. A
+// file can have valid headers and still fail to decode, so this is checked
+// before the file is accepted rather than when the first transcode fails.
+// It is bounded by the probe timeout.
+func (s *Service) VerifyDecodable(ctx context.Context, filePath string) error {
+	if s.config.ProbeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ProbeTimeout)
+		defer cancel()
+	}
+
+	output, err := exec.CommandContext(ctx, s.config.Path,
+		"-hide_banner", "-v", "error",
+		"-i", filePath,
+		"-map", "0:v:0",
+		"-frames:v", "1",
+		"-f", "null", os.DevNull,
+	).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("failed to decode video within %s: %w", s.config.ProbeTimeout, ErrTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%w: %s", ErrUnreadable, lastLine(string(output)))
+	}
+	return fmt.Errorf("failed to run FFmpeg: %w", err)
+}
+
+// VideoStream returns the first video stream that is not cover art, or nil
+func (m *VideoMetadata) VideoStream() *StreamInfo {
+	for i := range m.Streams {
+		if m.Streams[i].Type == StreamTypeVideo && !m.Streams[i].AttachedPicture {
+			return &m.Streams[i]
+		}
+	}
+	return nil
+}
+
+// AudioStream returns the first audio stream, or nil
+func (m *VideoMetadata) AudioStream() *StreamInfo {
+	for i := range m.Streams {
+		if m.Streams[i].Type == StreamTypeAudio {
+			return &m.Streams[i]
+		}
+	}
+	return nil
+}
+
+// probeOutput is the JSON printed by "ffprobe -show_format -show_streams"
+type probeOutput struct {
+	Streams []probeStream `json:"streams"`
+	Format  probeFormat   `json:"format"`
+}
+
+type probeFormat struct {
+	FormatName string `json:"format_name"`
+	Duration   string `json:"duration"`
+	BitRate    string `json:"bit_rate"`
+}
+
+type probeStream struct {
+	Index        int               `json:"index"`
+	CodecType    string            `json:"codec_type"`
+	CodecName    string            `json:"codec_name"`
+	Width        int               `json:"width"`
+	Height       int               `json:"height"`
+	RFrameRate   string            `json:"r_frame_rate"`
+	AvgFrameRate string            `json:"avg_frame_rate"`
+	Channels     int               `json:"channels"`
+	Duration     string            `json:"duration"`
+	BitRate      string            `json:"bit_rate"`
+	Tags         map[string]string `json:"tags"`
+	Disposition  map[string]int    `json:"disposition"`
+	SideDataList []struct {
+		Rotation *float64 `json:"rotation"`
+	} `json:"side_data_list"`
+}
+
+// parseProbeOutput decodes FFprobe's JSON output into metadata. Numbers
+// FFprobe reports as "N/A" or leaves out are left at zero.
+func parseProbeOutput(output []byte) (*VideoMetadata, error) {
+	var probe probeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	metadata := &VideoMetadata{
+		Duration: parseFloat(probe.Format.Duration),
+		Format:   probe.Format.FormatName,
+		Bitrate:  parseInt(probe.Format.BitRate),
+		Streams:  make([]StreamInfo, 0, len(probe.Streams)),
+	}
+	for _, stream := range probe.Streams {
+		metadata.Streams = append(metadata.Streams, stream.info())
+	}
+
+	if video := metadata.VideoStream(); video != nil {
+		metadata.Width = video.Width
+		metadata.Height = video.Height
+		metadata.VideoCodec = video.Codec
+		if metadata.Duration == 0 {
+			metadata.Duration = video.Duration
+		}
+	}
+	if audio := metadata.AudioStream(); audio != nil {
+		metadata.AudioCodec = audio.Codec
+	}
+	return metadata, nil
+}
+
+func (p probeStream) info() StreamInfo {
+	info := StreamInfo{
+		Index:           p.Index,
+		Type:            p.CodecType,
+		Codec:           p.CodecName,
+		Width:           p.Width,
+		Height:          p.Height,
+		Channels:        p.Channels,
+		AttachedPicture: p.Disposition["attached_pic"] == 1,
+		Duration:        parseFloat(p.Duration),
+		Bitrate:         parseInt(p.BitRate),
+		Language:        p.Tags["language"],
+	}
+	if p.CodecType == StreamTypeVideo {
+		// avg_frame_rate is 0/0 for some streams; r_frame_rate is always set
+		info.FrameRate = parseFrameRate(p.AvgFrameRate)
+		if info.FrameRate == 0 {
+			info.FrameRate = parseFrameRate(p.RFrameRate)
+		}
+		info.Rotation = p.rotation()
+	}
+	return info
+}
+
+// rotation returns the clockwise display rotation. Newer FFprobe versions
+// report the display matrix in side data, as a counter-clockwise angle;
+// older ones report a "rotate" tag.
+func (p probeStream) rotation() int {
+	degrees := 0
+	if rotate, ok := p.Tags["rotate"]; ok {
+		degrees, _ = strconv.Atoi(rotate)
+	}
+	for _, side := range p.SideDataList {
+		if side.Rotation != nil {
+			degrees = -int(math.Round(*side.Rotation))
+			break
+		}
+	}
+	return ((degrees % 360) + 360) % 360
+}
+
+// parseFrameRate parses a rational frame rate such as "30000/1001"
+func parseFrameRate(rate string) float64 {
+	num, den, ok := strings.Cut(rate, "/")
+	if !ok {
+		return parseFloat(rate)
+	}
+	n, d := parseFloat(num), parseFloat(den)
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+func parseFloat(value string) float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f
+}
+
+func parseInt(value string) int64 {
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return i
+}
@@ -0,0 +1,173 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// phoneRecording is FFprobe output for a portrait phone recording with
+// cover art, two audio tracks and a subtitle track
+const phoneRecording = `{
+    "streams": [
+        {
+            "index": 0,
+            "codec_name": "hevc",
+            "codec_type": "video",
+            "width": 1920,
+            "height": 1080,
+            "r_frame_rate": "30/1",
+            "avg_frame_rate": "30000/1001",
+            "duration": "12.512000",
+            "bit_rate": "8000000",
+            "disposition": {"default": 1, "attached_pic": 0},
+            "tags": {"language": "und"},
+            "side_data_list": [
+                {"side_data_type": "Display Matrix", "rotation": -90}
+            ]
+        },
+        {
+            "index": 1,
+            "codec_name": "aac",
+            "codec_type": "audio",
+            "channels": 2,
+            "bit_rate": "128000",
+            "tags": {"language": "eng"}
+        },
+        {
+            "index": 2,
+            "codec_name": "opus",
+            "codec_type": "audio",
+            "channels": 6,
+            "tags": {"language": "fra"}
+        },
+        {
+            "index": 3,
+            "codec_name": "mov_text",
+            "codec_type": "subtitle"
+        },
+        {
+            "index": 4,
+            "codec_name": "mjpeg",
+            "codec_type": "video",
+            "width": 600,
+            "height": 600,
+            "disposition": {"default": 0, "attached_pic": 1}
+        }
+    ],
+    "format": {
+        "format_name": "mov,mp4,m4a,3gp,3g2,mj2",
+        "duration": "12.540000",
+        "bit_rate": "8200000"
+    }
+}`
+
+func TestParseProbeOutput(t *testing.T) {
+	metadata, err := parseProbeOutput([]byte(phoneRecording))
+	require.NoError(t, err)
+
+	assert.Equal(t, 12.54, metadata.Duration)
+	assert.Equal(t, "mov,mp4,m4a,3gp,3g2,mj2", metadata.Format)
+	assert.Equal(t, int64(8200000), metadata.Bitrate)
+	assert.Equal(t, 1920, metadata.Width)
+	assert.Equal(t, 1080, metadata.Height)
+	assert.Equal(t, "hevc", metadata.VideoCodec)
+	assert.Equal(t, "aac", metadata.AudioCodec)
+	require.Len(t, metadata.Streams, 5)
+
+	video := metadata.Streams[0]
+	assert.InDelta(t, 29.97, video.FrameRate, 0.01)
+	assert.Equal(t, 90, video.Rotation)
+	assert.Equal(t, int64(8000000), video.Bitrate)
+
+	assert.Equal(t, 6, metadata.Streams[2].Channels)
+	assert.Equal(t, "fra", metadata.Streams[2].Language)
+	assert.Equal(t, StreamTypeSubtitle, metadata.Streams[3].Type)
+	assert.True(t, metadata.Streams[4].AttachedPicture)
+	assert.NoError(t, metadata.Validate())
+}
+
+func TestParseProbeOutput_MissingValues(t *testing.T) {
+	// Raw streams report no container duration or bitrate
+	metadata, err := parseProbeOutput([]byte(`{
+		"streams": [{"codec_type": "video", "codec_name": "h264", "width": 640, "height": 360,
+			"r_frame_rate": "25/1", "avg_frame_rate": "0/0", "duration": "4.000000", "tags": {"rotate": "270"}}],
+		"format": {"format_name": "h264", "duration": "N/A", "bit_rate": "N/A"}
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, 4.0, metadata.Duration, "falls back to the video stream's duration")
+	assert.Zero(t, metadata.Bitrate)
+	assert.Empty(t, metadata.AudioCodec)
+	assert.Equal(t, 25.0, metadata.Streams[0].FrameRate)
+	assert.Equal(t, 270, metadata.Streams[0].Rotation)
+}
+
+func TestParseProbeOutput_InvalidJSON(t *testing.T) {
+	_, err := parseProbeOutput([]byte("Invalid data found when processing input"))
+	assert.Error(t, err)
+}
+
+func TestValidate_RequiresVideoStream(t *testing.T) {
+	// An MP3 with cover art has no video stream that can be transcoded
+	metadata, err := parseProbeOutput([]byte(`{
+		"streams": [
+			{"codec_type": "audio", "codec_name": "mp3", "channels": 2},
+			{"codec_type": "video", "codec_name": "png", "width": 500, "height": 500, "disposition": {"attached_pic": 1}}
+		],
+		"format": {"format_name": "mp3", "duration": "180.000000"}
+	}`))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, metadata.Validate(), ErrNoVideoStream)
+	assert.Zero(t, metadata.Width)
+	assert.Empty(t, metadata.VideoCodec)
+	assert.Equal(t, "mp3", metadata.AudioCodec)
+}
+
+func TestTranscode_RejectsFileWithoutVideo(t *testing.T) {
+	dir := t.TempDir()
+	probe := filepath.Join(dir, "ffprobe")
+	script := "#!/bin/sh\necho '{\"streams\": [{\"codec_type\": \"audio\", \"codec_name\": \"aac\"}], \"format\": {\"duration\": \"3.0\"}}'\n"
+	require.NoError(t, os.WriteFile(probe, []byte(script), 0755))
+	input := filepath.Join(dir, "podcast.m4a")
+	require.NoError(t, os.WriteFile(input, []byte("audio"), 0644))
+
+	// FFmpeg is never run, so it does not need to exist
+	service := NewService(&Config{Path: "/nonexistent/ffmpeg", ProbePath: probe}, nopLogger{})
+	err := service.Transcode(context.Background(), input, filepath.Join(dir, "out", "720p.mp4"), "720p")
+	assert.ErrorIs(t, err, ErrNoVideoStream)
+}
+
+func TestGetMetadata_Unreadable(t *testing.T) {
+	dir := t.TempDir()
+	probe := filepath.Join(dir, "ffprobe")
+	script := "#!/bin/sh\necho 'notes.mp4: Invalid data found when processing input' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(probe, []byte(script), 0755))
+	input := filepath.Join(dir, "notes.mp4")
+	require.NoError(t, os.WriteFile(input, []byte("not a video"), 0644))
+
+	service := NewService(&Config{Path: "/nonexistent/ffmpeg", ProbePath: probe}, nopLogger{})
+	_, err := service.GetMetadata(context.Background(), input)
+	assert.ErrorIs(t, err, ErrUnreadable)
+}
+
+func TestVerifyDecodable(t *testing.T) {
+	dir := t.TempDir()
+	encoder := filepath.Join(dir, "ffmpeg")
+	// Fails for files named broken.mp4, as FFmpeg does for corrupt streams
+	script := "#!/bin/sh\ncase \"$*\" in *broken.mp4*) echo 'Invalid NAL unit size' >&2; exit 1;; esac\n"
+	require.NoError(t, os.WriteFile(encoder, []byte(script), 0755))
+
+	service := NewService(&Config{Path: encoder, ProbeTimeout: time.Minute}, nopLogger{})
+	assert.NoError(t, service.VerifyDecodable(context.Background(), filepath.Join(dir, "clip.mp4")))
+
+	err := service.VerifyDecodable(context.Background(), filepath.Join(dir, "broken.mp4"))
+	assert.ErrorIs(t, err, ErrUnreadable)
+	assert.Contains(t, err.Error(), "Invalid NAL unit size")
+}
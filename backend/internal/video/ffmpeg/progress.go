@@ -0,0 +1,78 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ProgressFunc reports how much of the source an encode has got through, as
+// a percentage of its duration
+type ProgressFunc func(percent float64)
+
+type progressKey struct{}
+
+// WithProgress returns a context under which Transcode, ExtractAudio,
+// Preview and BurnCaptions report their progress to fn. fn is called from a
+// single goroutine.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ProgressFromContext returns the progress callback set with WithProgress,
+// or nil if there is none
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// progressArgs make FFmpeg write key=value progress blocks to stdout instead
+// of its statistics line
+var progressArgs = []string{"-progress", "pipe:1", "-nostats"}
+
+// readProgress reads FFmpeg's -progress output from r until it ends, reporting
+// each block against the source's duration in seconds. The output is read to
+// the end even if it cannot be parsed, so FFmpeg never blocks on it.
+func readProgress(r io.Reader, duration float64, fn ProgressFunc) {
+	var position float64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+		switch key {
+		// out_time_ms is in microseconds as well; older FFmpeg only writes it
+		case "out_time_us", "out_time_ms":
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				position = float64(us) / 1e6
+			}
+		case "progress":
+			if value == "end" {
+				fn(100)
+			} else {
+				fn(min(position/duration*100, 100))
+			}
+		}
+	}
+	io.Copy(io.Discard, r)
+}
+
+// runWithProgress runs cmd, reporting the progress FFmpeg writes to its
+// stdout to fn against duration. Without fn, stdout goes to output.
+func runWithProgress(cmd *exec.Cmd, output io.Writer, duration float64, fn ProgressFunc) error {
+	if fn == nil {
+		cmd.Stdout = output
+		return cmd.Run()
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Wait closes the pipe, so it is read to the end first
+	readProgress(stdout, duration, fn)
+	return cmd.Wait()
+}
@@ -0,0 +1,75 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// progressOutput is what FFmpeg writes with -progress for a 10 second source:
+// a block that has not started, two updates and the end
+const progressOutput = `frame=0
+out_time_us=N/A
+out_time_ms=N/A
+progress=continue
+frame=75
+out_time_us=2500000
+out_time_ms=2500000
+progress=continue
+frame=240
+out_time_us=8000000
+progress=continue
+frame=300
+out_time_us=10040000
+progress=end
+`
+
+func TestReadProgress(t *testing.T) {
+	var reported []float64
+	readProgress(strings.NewReader(progressOutput), 10, func(percent float64) {
+		reported = append(reported, percent)
+	})
+	assert.Equal(t, []float64{0, 25, 80, 100}, reported)
+}
+
+func TestReadProgress_PositionPastDuration(t *testing.T) {
+	var reported []float64
+	readProgress(strings.NewReader("out_time_us=12000000\nprogress=continue\n"), 10, func(percent float64) {
+		reported = append(reported, percent)
+	})
+	assert.Equal(t, []float64{100}, reported)
+}
+
+func TestTranscode_ReportsProgress(t *testing.T) {
+	dir := t.TempDir()
+	probe := filepath.Join(dir, "ffprobe")
+	probeScript := "#!/bin/sh\necho '{\"streams\": [{\"codec_type\": \"video\", \"codec_name\": \"h264\", \"width\": 1280, \"height\": 720}], \"format\": {\"duration\": \"10.0\"}}'\n"
+	require.NoError(t, os.WriteFile(probe, []byte(probeScript), 0755))
+
+	// The fake FFmpeg writes progress when asked to, then its output
+	ffmpeg := filepath.Join(dir, "ffmpeg")
+	ffmpegScript := `#!/bin/sh
+case "$*" in *"-progress pipe:1 -nostats"*) printf '` + strings.ReplaceAll(progressOutput, "\n", `\n`) + `';; esac
+for last; do :; done
+echo encoded > "$last"
+`
+	require.NoError(t, os.WriteFile(ffmpeg, []byte(ffmpegScript), 0755))
+	input := filepath.Join(dir, "clip.mp4")
+	require.NoError(t, os.WriteFile(input, []byte("video"), 0644))
+
+	var reported []float64
+	ctx := WithProgress(context.Background(), func(percent float64) {
+		reported = append(reported, percent)
+	})
+	service := NewService(&Config{Path: ffmpeg, ProbePath: probe, HWAccel: HWAccelNone}, nopLogger{})
+	require.NoError(t, service.Transcode(ctx, input, filepath.Join(dir, "out", "480p.mp4"), "480p"))
+	assert.Equal(t, []float64{0, 25, 80, 100}, reported)
+
+	// Without a callback FFmpeg is not asked for progress
+	require.NoError(t, service.Transcode(context.Background(), input, filepath.Join(dir, "out", "360p.mp4"), "360p"))
+}
@@ -2,6 +2,7 @@ package ffmpeg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,14 +10,25 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrTimeout is wrapped by errors from FFmpeg and FFprobe runs that were
+// stopped because they exceeded their deadline
+var ErrTimeout = errors.New("ffmpeg deadline exceeded")
+
 // Service handles FFmpeg operations
 type Service struct {
 	config *Config
 	logger logger.Logger
+
+	detectOnce sync.Once
+	encoder    string // Accelerator in use, set by Encoder
 }
 
 // Config represents FFmpeg configuration
@@ -28,6 +40,17 @@ type Config struct {
 	Preset      string   // Encoding preset (e.g., medium)
 	OutputPath  string   // Path for transcoded outputs
 	Resolutions []string // List of output resolutions
+
+	TimeoutFactor float64       // Transcode deadline as a multiple of the source duration; 0 disables it
+	MinTimeout    time.Duration // Lower bound for transcode deadlines
+	ProbeTimeout  time.Duration // Deadline for each FFprobe run; 0 disables it
+
+	HWAccel     string             // Hardware encoder: none, auto, vaapi, nvenc or videotoolbox
+	VAAPIDevice string             // Render node used by VAAPI
+	Profiles    map[string]Profile // Encoding settings by resolution
+
+	Audio   AudioOutput   // Audio-only rendition
+	Preview PreviewOutput // Animated preview
 }
 
 // VideoMetadata represents video file metadata
@@ -39,6 +62,10 @@ type VideoMetadata struct {
 	VideoCodec string  // Video codec
 	AudioCodec string  // Audio codec
 	Bitrate    int64   // Bitrate in bits per second
+
+	// Streams lists every stream in the file. Width, Height and the codecs
+	// above are those of the first video and audio streams.
+	Streams []StreamInfo
 }
 
 // NewService creates a new FFmpeg service
@@ -50,12 +77,21 @@ func NewService(config *Config, logger logger.Logger) *Service {
 }
 
 // GetMetadata extracts metadata from a video file
-func (s *Service) GetMetadata(ctx context.Context, filePath string) (*VideoMetadata, error) {
+func (s *Service) GetMetadata(ctx context.Context, filePath string) (_ *VideoMetadata, err error) {
+	ctx, span := tracing.Start(ctx, "ffprobe", attribute.String("ffmpeg.input", filePath))
+	defer func() { tracing.End(span, err) }()
+
 	// Check if file exists
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("file does not exist: %s", filePath)
 	}
 
+	if s.config.ProbeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.ProbeTimeout)
+		defer cancel()
+	}
+
 	// Run ffprobe command
 	cmd := exec.CommandContext(ctx, s.config.ProbePath,
 		"-v", "quiet",
@@ -67,50 +103,36 @@ func (s *Service) GetMetadata(ctx context.Context, filePath string) (*VideoMetad
 
 	output, err := cmd.Output()
 	if err != nil {
-		s.logger.LogError(err, fmt.Sprintf("Failed to get video metadata: path=%s", filePath))
-		return nil, fmt.Errorf("failed to get video metadata: %w", err)
-	}
-
-	// Parse the JSON output and extract metadata
-	// For MVP, we'll use a simplified approach with string parsing
-	metadata := &VideoMetadata{}
-
-	// Extract duration
-	if durationStr := s.extractValue(string(output), "duration"); durationStr != "" {
-		if duration, err := strconv.ParseFloat(durationStr, 64); err == nil {
-			metadata.Duration = duration
-		}
-	}
-
-	// Extract resolution
-	if width := s.extractValue(string(output), "width"); width != "" {
-		if w, err := strconv.Atoi(width); err == nil {
-			metadata.Width = w
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.logger.LogError(err, fmt.Sprintf("FFprobe exceeded its deadline: path=%s, timeout=%s", filePath, s.config.ProbeTimeout))
+			return nil, fmt.Errorf("failed to get video metadata within %s: %w", s.config.ProbeTimeout, ErrTimeout)
 		}
-	}
-	if height := s.extractValue(string(output), "height"); height != "" {
-		if h, err := strconv.Atoi(height); err == nil {
-			metadata.Height = h
+		s.logger.LogError(err, fmt.Sprintf("Failed to get video metadata: path=%s", filePath))
+		// FFprobe ran but could not read the file, as opposed to failing to start
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to get video metadata: %w", ErrUnreadable)
 		}
+		return nil, fmt.Errorf("failed to get video metadata: %w", err)
 	}
 
-	// Extract format
-	metadata.Format = s.extractValue(string(output), "format_name")
-	metadata.VideoCodec = s.extractValue(string(output), "codec_name")
-	metadata.AudioCodec = s.extractValue(string(output), "codec_name")
-
-	// Extract bitrate
-	if bitrate := s.extractValue(string(output), "bit_rate"); bitrate != "" {
-		if br, err := strconv.ParseInt(bitrate, 10, 64); err == nil {
-			metadata.Bitrate = br
-		}
+	metadata, err := parseProbeOutput(output)
+	if err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Failed to parse video metadata: path=%s", filePath))
+		return nil, fmt.Errorf("%w: %w", ErrUnreadable, err)
 	}
 
 	return metadata, nil
 }
 
 // Transcode transcodes a video file to the specified resolution
-func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resolution string) error {
+func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resolution string) (err error) {
+	ctx, span := tracing.Start(ctx, "ffmpeg.transcode",
+		attribute.String("ffmpeg.input", inputPath),
+		attribute.String("ffmpeg.resolution", resolution),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	// Log detailed input values at the start
 	s.logger.LogInfo("Beginning transcoding process", map[string]interface{}{
 		"input_path": inputPath,
@@ -154,6 +176,10 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 		s.logger.LogError(err, errMsg)
 		return fmt.Errorf("%s: %w", errMsg, err)
 	}
+	if err := metadata.Validate(); err != nil {
+		s.logger.LogError(err, fmt.Sprintf("Cannot transcode input: path=%s", inputPath))
+		return err
+	}
 
 	s.logger.LogInfo("Video metadata extracted", map[string]interface{}{
 		"duration": metadata.Duration,
@@ -165,63 +191,25 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 		"bitrate": metadata.Bitrate,
 	})
 
-	// Convert resolution string to actual dimensions
-	var width, height int
-	switch resolution {
-	case "720p":
-		width, height = 1280, 720
-	case "480p":
-		width, height = 854, 480
-	case "360p":
-		width, height = 640, 360
-	case "original":
-		// Use original dimensions
-		width, height = metadata.Width, metadata.Height
-	default:
-		errMsg := fmt.Sprintf("Unsupported resolution: %s", resolution)
-		s.logger.LogError(nil, errMsg)
-		return fmt.Errorf(errMsg)
+	width, height, err := s.OutputDimensions(resolution, metadata)
+	if err != nil {
+		s.logger.LogError(nil, err.Error())
+		return err
 	}
-
-	// Skip upscaling if the target resolution is higher than the original
-	if width > metadata.Width || height > metadata.Height {
+	if nominalWidth, nominalHeight, _ := nominalDimensions(resolution); nominalWidth > metadata.Width || nominalHeight > metadata.Height {
 		s.logger.LogInfo("Skipping upscaling", map[string]interface{}{
 			"original_width":  metadata.Width,
 			"original_height": metadata.Height,
 			"target_width":    width,
 			"target_height":   height,
 			"resolution":      resolution,
-			"action": "adjusting dimensions to avoid upscaling",
+			"action":          "adjusting dimensions to avoid upscaling",
 		})
-		// Use original dimensions but maintain aspect ratio
-		if metadata.Width > metadata.Height {
-			// Landscape orientation
-			height = (metadata.Height * width) / metadata.Width
-			// Ensure height is even
-			if height%2 != 0 {
-				height += 1 // Add 1 instead of subtracting to avoid making it too small
-			}
-		} else {
-			// Portrait or square orientation
-			width = (metadata.Width * height) / metadata.Height
-			// Ensure width is even
-			if width%2 != 0 {
-				width += 1 // Add 1 instead of subtracting to avoid making it too small
-			}
-		}
-	}
-
-	// Ensure dimensions are even (required by most codecs)
-	// Double-check to make sure both dimensions are even, regardless of previous calculations
-	if width%2 != 0 {
-		width += 1 // Add 1 instead of subtracting to avoid making it too small
-	}
-	if height%2 != 0 {
-		height += 1 // Add 1 instead of subtracting to avoid making it too small
 	}
 
 	// Format the resolution as "widthxheight"
 	resolutionArg := fmt.Sprintf("%dx%d", width, height)
+	encoder := s.Encoder(ctx)
 
 	s.logger.LogInfo("Starting transcoding", map[string]interface{}{
 		"input":           inputPath,
@@ -233,18 +221,30 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 		"video_codec":     s.config.VideoCodec,
 		"audio_codec":     s.config.AudioCodec,
 		"preset":          s.config.Preset,
+		"hw_accel":        encoder,
 	})
 
-	// Build FFmpeg command with proper resolution format
-	cmd := exec.CommandContext(ctx, s.config.Path,
-		"-i", inputPath,
-		"-c:v", s.config.VideoCodec,
-		"-c:a", s.config.AudioCodec,
-		"-s", resolutionArg, // Use the formatted resolution
-		"-preset", s.config.Preset,
-		"-y", // Overwrite output file if it exists
-		outputPath,
-	)
+	// Bound the encode by the source's length so a stuck FFmpeg cannot hold the upload forever
+	timeout := s.TranscodeTimeout(metadata.Duration)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	} else if s.config.TimeoutFactor > 0 {
+		s.logger.LogWarn("Source duration unknown, transcoding without a deadline", map[string]interface{}{
+			"input":      inputPath,
+			"resolution": resolution,
+		})
+	}
+
+	// Build FFmpeg command with proper resolution format, reporting progress
+	// when the caller asked for it and the source's length is known
+	args := s.encodeArgs(encoder, inputPath, outputPath, resolution, width, height, 0)
+	progress := ProgressFromContext(ctx)
+	if progress != nil && metadata.Duration > 0 {
+		args = append(append([]string{}, progressArgs...), args...)
+	}
+	cmd := exec.CommandContext(ctx, s.config.Path, args...)
 
 	// Log the exact command being executed
 	s.logger.LogInfo("Executing FFmpeg command", map[string]interface{}{
@@ -259,6 +259,14 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
+	var stdout io.ReadCloser
+	if progress != nil && metadata.Duration > 0 {
+		if stdout, err = cmd.StdoutPipe(); err != nil {
+			s.logger.LogError(err, "Failed to create stdout pipe")
+			return fmt.Errorf("failed to create stdout pipe: %w", err)
+		}
+	}
+
 	// Start the command
 	if err := cmd.Start(); err != nil {
 		errMsg := fmt.Sprintf("Failed to start transcoding: input=%s, output=%s", inputPath, outputPath)
@@ -270,6 +278,16 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 		"pid": cmd.Process.Pid,
 	})
 
+	// Read progress until FFmpeg exits and closes its end of the pipe
+	var progressDone chan struct{}
+	if stdout != nil {
+		progressDone = make(chan struct{})
+		go func() {
+			defer close(progressDone)
+			readProgress(stdout, metadata.Duration, progress)
+		}()
+	}
+
 	// Read stderr in a goroutine
 	go func() {
 		buf := make([]byte, 1024)
@@ -290,8 +308,20 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 		}
 	}()
 
-	// Wait for the command to complete
+	// Wait for the command to complete. Wait closes the progress pipe, so
+	// its output is read to the end first.
+	if progressDone != nil {
+		<-progressDone
+	}
 	if err := cmd.Wait(); err != nil {
+		// CommandContext kills FFmpeg at the deadline; report that apart from encode errors
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			errMsg := fmt.Sprintf("Transcoding exceeded its deadline: input=%s, output=%s, dimensions=%s, timeout=%s",
+				inputPath, outputPath, resolutionArg, timeout)
+			s.logger.LogError(err, errMsg)
+			return fmt.Errorf("TRANSCODE_TIMEOUT: %s: %w", errMsg, ErrTimeout)
+		}
+
 		errMsg := fmt.Sprintf("Transcoding failed: input=%s, output=%s, dimensions=%s",
 			inputPath, outputPath, resolutionArg)
 		s.logger.LogError(err, errMsg)
@@ -318,7 +348,7 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 	if fileInfo.Size() == 0 {
 		errMsg := fmt.Sprintf("Transcoded file is empty: %s", outputPath)
 		s.logger.LogError(nil, errMsg)
-		return fmt.Errorf(errMsg)
+		return fmt.Errorf("%s", errMsg)
 	}
 
 	s.logger.LogInfo("Transcoding completed successfully", map[string]interface{}{
@@ -333,6 +363,117 @@ func (s *Service) Transcode(ctx context.Context, inputPath, outputPath, resoluti
 	return nil
 }
 
+// EncodeSample encodes the first seconds of inputPath at the given dimensions
+// with the encoder and profile of resolution. Dry runs use it to check that a
+// profile encodes and to measure how fast.
+func (s *Service) EncodeSample(ctx context.Context, inputPath, outputPath, resolution string, width, height int, seconds float64) (err error) {
+	ctx, span := tracing.Start(ctx, "ffmpeg.sample",
+		attribute.String("ffmpeg.input", inputPath),
+		attribute.String("ffmpeg.dimensions", fmt.Sprintf("%dx%d", width, height)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, s.config.Path, s.encodeArgs(s.Encoder(ctx), inputPath, outputPath, resolution, width, height, seconds)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("sample encode exceeded its deadline: %w", ErrTimeout)
+		}
+		return fmt.Errorf("sample encode failed: %w: %s", err, lastLine(string(output)))
+	}
+	return nil
+}
+
+// encodeArgs builds the FFmpeg arguments for encoding inputPath to
+// outputPath as the given resolution, scaled to width x height, with the
+// accelerator and the resolution's profile. A positive limit encodes only
+// that many seconds.
+func (s *Service) encodeArgs(accel, inputPath, outputPath, resolution string, width, height int, limit float64) []string {
+	args := append(s.deviceArgs(accel), "-i", inputPath)
+	if limit > 0 {
+		args = append(args, "-t", strconv.FormatFloat(limit, 'f', -1, 64))
+	}
+	args = append(args, s.videoEncodeArgs(accel, width, height)...)
+	args = append(args, s.profileArgs(accel, s.config.Profiles[resolution])...)
+	return append(args,
+		"-y", // Overwrite output file if it exists
+		outputPath,
+	)
+}
+
+// OutputDimensions returns the frame size of a rendition of a source with the
+// given metadata. Renditions are never upscaled: when the source is smaller
+// than the rendition, the source is kept at its aspect ratio instead. Both
+// dimensions are even, as most codecs require.
+func (s *Service) OutputDimensions(resolution string, metadata *VideoMetadata) (width, height int, err error) {
+	width, height, ok := nominalDimensions(resolution)
+	if !ok {
+		return 0, 0, fmt.Errorf("Unsupported resolution: %s", resolution)
+	}
+	if resolution == "original" {
+		width, height = metadata.Width, metadata.Height
+	}
+
+	if width > metadata.Width || height > metadata.Height {
+		if metadata.Width > metadata.Height {
+			// Landscape orientation
+			height = (metadata.Height * width) / metadata.Width
+		} else if metadata.Height > 0 {
+			// Portrait or square orientation
+			width = (metadata.Width * height) / metadata.Height
+		}
+	}
+
+	// Round up rather than down to avoid making the frame too small
+	if width%2 != 0 {
+		width++
+	}
+	if height%2 != 0 {
+		height++
+	}
+	return width, height, nil
+}
+
+// nominalDimensions returns the frame size of a named rendition. "original"
+// is supported but has no fixed size.
+func nominalDimensions(resolution string) (width, height int, ok bool) {
+	switch resolution {
+	case "720p":
+		return 1280, 720, true
+	case "480p":
+		return 854, 480, true
+	case "360p":
+		return 640, 360, true
+	case "original":
+		return 0, 0, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// lastLine returns the last non-empty line of FFmpeg's output, which holds the error
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
+
+// TranscodeTimeout returns the deadline for transcoding one rendition of a
+// source lasting duration seconds, or 0 when no deadline applies
+func (s *Service) TranscodeTimeout(duration float64) time.Duration {
+	if s.config.TimeoutFactor <= 0 || duration <= 0 {
+		return 0
+	}
+
+	timeout := time.Duration(duration * s.config.TimeoutFactor * float64(time.Second))
+	if timeout < s.config.MinTimeout {
+		return s.config.MinTimeout
+	}
+	return timeout
+}
+
 // getFileSize is a helper to safely get file size
 func getFileSize(path string) int64 {
 	info, err := os.Stat(path)
@@ -341,19 +482,3 @@ func getFileSize(path string) int64 {
 	}
 	return info.Size()
 }
-
-// extractValue is a helper function to extract values from ffprobe output
-func (s *Service) extractValue(output, key string) string {
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		if strings.Contains(line, fmt.Sprintf("\"%s\":", key)) {
-			parts := strings.Split(line, ":")
-			if len(parts) >= 2 {
-				value := strings.TrimSpace(parts[1])
-				value = strings.Trim(value, "\",")
-				return value
-			}
-		}
-	}
-	return ""
-}
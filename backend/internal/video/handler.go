@@ -3,13 +3,22 @@ package video
 import (
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
-	"path/filepath"
+	"path"
 	"strconv"
 	"strings"
-
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/audit"
+	"github.com/consensuslabs/pavilion-network/backend/internal/geoip"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/caption"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 )
 
@@ -20,6 +29,9 @@ type VideoHandler struct {
 
 // NewVideoHandler creates a new VideoHandler instance
 func NewVideoHandler(app *App) *VideoHandler {
+	if app.Config != nil {
+		registerValidators(app.Config.Video)
+	}
 	return &VideoHandler{app: app}
 }
 
@@ -29,17 +41,29 @@ func NewVideoHandler(app *App) *VideoHandler {
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param video formData file true "Video file to upload (.mp4, .mov)"
 // @Param title formData string true "Video title (3-100 characters)" minLength(3) maxLength(100)
 // @Param description formData string false "Video description (max 1000 characters)" maxLength(1000)
+// @Param category formData string false "Video category" Enums(education, entertainment, gaming, music, news, science, sports, technology, other)
+// @Param tags formData string false "Comma-separated tags (max 10, each up to 32 letters, digits or hyphens)"
+// @Param Idempotency-Key header string false "Key making retries of this upload return the original response instead of uploading again"
 // @Success 200 {object} APIResponse{data=UploadResponse} "Upload completed successfully"
 // @Failure 400 {object} APIResponse "Invalid request format or validation error"
 // @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 409 {object} APIResponse "An upload with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} APIResponse "Not a decodable video, unsupported codec or too long (ERR_INVALID_MEDIA), rejected by the content scan (ERR_CONTENT_REJECTED), or Idempotency-Key reused for a different request (INVALID_REQUEST)"
+// @Failure 429 {object} APIResponse "Too many uploads, or the transcode backlog is full; see the Retry-After header"
 // @Failure 500 {object} APIResponse "Processing error"
+// @Failure 503 {object} APIResponse "Server is shutting down, or temporary storage for uploads is full"
 // @Router /video/upload [post]
 func (h *VideoHandler) HandleUpload(c *gin.Context) {
 	requestID := c.GetString("request_id")
 
+	if !h.admitUpload(c) {
+		return
+	}
+
 	// Authentication is already handled by middleware
 	// The following authentication check is removed as it's redundant and insecure
 	// if !isAuthenticated(c) {
@@ -53,113 +77,93 @@ func (h *VideoHandler) HandleUpload(c *gin.Context) {
 			"request_id": requestID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "ERR_NO_FILE", "No video file received", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeNoFile, "No video file received", err)
 		return
 	}
 	defer file.Close()
 
-	title := c.PostForm("title")
-	description := c.PostForm("description")
-
-	if err := h.validateVideoUpload(fileHeader, title, description); err != nil {
+	var req UploadRequest
+	if err := httpHandler.BindWith(c, &req, binding.FormMultipart); err != nil {
 		h.app.Logger.LogInfo("Video upload validation failed", map[string]interface{}{
 			"request_id": requestID,
 			"filename":   fileHeader.Filename,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "ERR_VALIDATION", err.Error(), err)
+		// Upload validation errors keep their original code for existing clients
+		var invalid *apierror.Error
+		if errors.As(err, &invalid) && invalid.Code == apierror.CodeValidation {
+			invalid.Code = apierror.CodeUploadValidation
+		}
+		apierror.Abort(c, err, "")
 		return
 	}
+	title, description := req.Title, req.Description
+	// Already validated, so normalizing cannot fail
+	tags, _ := NormalizeTags(splitTags(req.Tags))
+	taxonomy := Taxonomy{Category: req.Category, Tags: tags}
 
-	// Create initial upload record
-	upload, err := h.app.Video.InitializeUpload(title, description, fileHeader.Size)
+	// Create initial upload record owned by the authenticated user
+	upload, err := h.app.Video.InitializeUpload(c.Request.Context(), getUserID(c), title, description, fileHeader.Size, taxonomy)
 	if err != nil {
 		h.app.Logger.LogInfo("Failed to initialize upload", map[string]interface{}{
 			"request_id": requestID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "UPLOAD_FAILED", "Failed to initialize upload", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeUploadFailed, "Failed to initialize upload", err)
 		return
 	}
 
 	// Process upload synchronously
-	if err := h.app.Video.ProcessUpload(upload, file, fileHeader); err != nil {
+	if err := h.app.Video.ProcessUpload(c.Request.Context(), upload, file, fileHeader); err != nil {
 		h.app.Logger.LogInfo("Video processing failed", map[string]interface{}{
 			"request_id": requestID,
 			"filename":   fileHeader.Filename,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "TRANSCODE_FAILED", "Video transcoding failed", err)
+		h.respondProcessingError(c, err, upload.VideoID, title)
 		return
 	}
 
-	// Get updated video record with transcodes
-	video, err := h.app.Video.GetVideo(upload.VideoID)
+	// Get updated video record with transcodes. A duplicate upload is
+	// answered with the video it duplicates.
+	videoID := upload.VideoID
+	if upload.DuplicateOf != uuid.Nil {
+		videoID = upload.DuplicateOf
+	}
+	video, err := h.app.Video.GetVideo(c.Request.Context(), videoID)
 	if err != nil {
 		h.app.Logger.LogInfo("Failed to get video details", map[string]interface{}{
 			"request_id": requestID,
-			"video_id":   upload.VideoID,
+			"video_id":   videoID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve video details", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve video details", err)
 		return
 	}
 
-	// Build response
-	response := UploadResponse{
-		ID:          video.ID.String(),
-		FileID:      video.FileID,
-		StoragePath: video.StoragePath,
-		IPFSCID:     video.IPFSCID,
-		Status:      string(upload.Status),
-		Transcodes:  make([]TranscodeInfo, 0),
-	}
-
-	// Add transcodes to response
-	for _, t := range video.Transcodes {
-		segments := make([]TranscodeSegmentInfo, 0, len(t.Segments))
-		resolution := "original" // Default resolution
-
-		for _, s := range t.Segments {
-			segments = append(segments, TranscodeSegmentInfo{
-				ID:          s.ID.String(),
-				StoragePath: s.StoragePath,
-				IPFSCID:     s.IPFSCID,
-				Duration:    s.Duration,
-			})
-
-			// Extract resolution from storage path (e.g., videos/{video_id}/720p.mp4)
-			if s.StoragePath != "" {
-				parts := strings.Split(s.StoragePath, "/")
-				if len(parts) > 0 {
-					lastPart := parts[len(parts)-1]
-					if strings.HasSuffix(lastPart, ".mp4") {
-						res := strings.TrimSuffix(lastPart, ".mp4")
-						if res == "720p" || res == "480p" || res == "360p" {
-							resolution = res
-						}
-					}
-				}
-			}
-		}
-
-		response.Transcodes = append(response.Transcodes, TranscodeInfo{
-			ID:         t.ID.String(),
-			Format:     t.Format,
-			Resolution: resolution,
-			Segments:   segments,
-			CreatedAt:  t.CreatedAt,
-		})
-	}
+	response := newUploadResponse(video, upload)
 
 	h.app.Logger.LogInfo("Video upload completed successfully", map[string]interface{}{
 		"request_id": requestID,
-		"video_id":   upload.VideoID,
-		"file_path":  upload.Video.StoragePath,
+		"video_id":   video.ID,
+		"file_path":  video.StoragePath,
+		"duplicate":  response.Duplicate,
 	})
 
-	// Send notification if notification service is available
-	if h.app.NotificationService != nil {
+	if !response.Duplicate {
+		h.publishWebhook(c, WebhookVideoProcessed, map[string]interface{}{
+			"video_id":           video.ID,
+			"title":              video.Title,
+			"status":             response.Status,
+			"transcode_failures": upload.TranscodeFailures,
+		})
+		h.recordAudit(c, audit.VideoUploaded, video.ID, map[string]string{"title": video.Title})
+		h.announceVideo(c, video)
+	}
+
+	// Send notification if notification service is available; followers
+	// were already told about a duplicate's video
+	if h.app.NotificationService != nil && !response.Duplicate {
 		// Get user ID from context
 		userIDStr, exists := c.Get("userID")
 		if exists {
@@ -201,40 +205,93 @@ func (h *VideoHandler) HandleUpload(c *gin.Context) {
 	h.app.ResponseHandler.SuccessResponse(c, response, "Upload completed successfully")
 }
 
-// validateVideoUpload validates the video upload request
-func (h *VideoHandler) validateVideoUpload(fileHeader *multipart.FileHeader, title, description string) error {
-	if fileHeader == nil {
-		return errors.New("no video file provided")
+// respondProcessingError answers a request whose upload failed to process,
+// telling the uploader's webhooks unless the server is shutting down
+func (h *VideoHandler) respondProcessingError(c *gin.Context, err error, videoID uuid.UUID, title string) {
+	if errors.Is(err, ErrShuttingDown) {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, err.Error(), err)
+		return
 	}
-
-	// Validate file size
-	if fileHeader.Size > h.app.Config.Video.MaxFileSize {
-		return fmt.Errorf("file size exceeds maximum allowed size of %d bytes", h.app.Config.Video.MaxFileSize)
+	reason := "processing failed"
+	if errors.Is(err, ErrInvalidMedia) {
+		reason = err.Error()
+	} else if errors.Is(err, ErrContentRejected) {
+		reason = ErrContentRejected.Error()
 	}
-
-	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
-	validExts := make(map[string]bool)
-	for _, format := range h.app.Config.Video.AllowedFormats {
-		validExts[format] = true
+	h.publishWebhook(c, WebhookVideoFailed, map[string]interface{}{
+		"video_id": videoID,
+		"title":    title,
+		"reason":   reason,
+	})
+	if errors.Is(err, ErrInvalidMedia) {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusUnprocessableEntity, apierror.CodeInvalidMedia, err.Error(), err)
+		return
 	}
-	if !validExts[ext] {
-		return fmt.Errorf("invalid file type, allowed formats: %v", h.app.Config.Video.AllowedFormats)
+	if errors.Is(err, ErrContentRejected) {
+		// The findings are for admins; the uploader only learns the file was rejected
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusUnprocessableEntity, apierror.CodeContentRejected, ErrContentRejected.Error(), err)
+		return
 	}
+	h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeTranscodeFailed, "Video transcoding failed", err)
+}
 
-	// Validate title
-	if len(title) < h.app.Config.Video.MinTitleLength || len(title) > h.app.Config.Video.MaxTitleLength {
-		return fmt.Errorf("title must be between %d and %d characters",
-			h.app.Config.Video.MinTitleLength,
-			h.app.Config.Video.MaxTitleLength)
+// newUploadResponse describes a processed upload and the video it belongs to
+func newUploadResponse(video *Video, upload *VideoUpload) UploadResponse {
+	response := UploadResponse{
+		ID:          video.ID.String(),
+		FileID:      video.FileID,
+		StoragePath: video.StoragePath,
+		IPFSCID:     video.IPFSCID,
+		Replication: string(video.Replication),
+		Status:      string(upload.Status),
+		Transcodes:  make([]TranscodeInfo, 0),
+		Duplicate:   upload.DuplicateOf != uuid.Nil,
+		Version:     upload.Version,
+	}
+	if video.Upload != nil {
+		// A duplicate is answered with the earlier video's version
+		response.Version = video.Upload.Version
 	}
 
-	// Validate description if provided
-	if description != "" && len(description) > h.app.Config.Video.MaxDescLength {
-		return fmt.Errorf("description cannot exceed %d characters", h.app.Config.Video.MaxDescLength)
+	// Add transcodes to response
+	for _, t := range video.Transcodes {
+		segments := make([]TranscodeSegmentInfo, 0, len(t.Segments))
+		resolution := "original" // Default resolution
+
+		for _, s := range t.Segments {
+			segments = append(segments, TranscodeSegmentInfo{
+				ID:          s.ID.String(),
+				StoragePath: s.StoragePath,
+				IPFSCID:     s.IPFSCID,
+				Replication: string(s.Replication),
+				Duration:    s.Duration,
+			})
+
+			// Extract resolution from storage path (e.g., videos/{video_id}/720p.mp4)
+			if s.StoragePath != "" {
+				parts := strings.Split(s.StoragePath, "/")
+				if len(parts) > 0 {
+					lastPart := parts[len(parts)-1]
+					if strings.HasSuffix(lastPart, ".mp4") {
+						res := strings.TrimSuffix(lastPart, ".mp4")
+						if res == "720p" || res == "480p" || res == "360p" {
+							resolution = res
+						}
+					}
+				}
+			}
+		}
+
+		response.Transcodes = append(response.Transcodes, TranscodeInfo{
+			ID:         t.ID.String(),
+			Format:     t.Format,
+			Resolution: resolution,
+			Segments:   segments,
+			CreatedAt:  t.CreatedAt,
+		})
 	}
 
-	return nil
+	return response
 }
 
 // @Summary Get video details
@@ -242,10 +299,16 @@ func (h *VideoHandler) validateVideoUpload(fileHeader *multipart.FileHeader, tit
 // @Tags video
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Video ID (UUID)"
+// @Param If-None-Match header string false "ETag of a copy the client already has"
 // @Success 200 {object} APIResponse{data=VideoDetailsResponse} "Video details retrieved successfully"
+// @Success 304 "The client's copy is current"
 // @Failure 401 {object} APIResponse "Unauthorized"
-// @Failure 404 {object} APIResponse "Video not found or has been deleted"
+// @Failure 402 {object} APIResponse "Video requires an entitlement the user does not hold"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 451 {object} APIResponse "Video is not available in the client's country (GEO_BLOCKED)"
 // @Failure 500 {object} APIResponse "Internal server error"
 // @Router /video/{id} [get]
 func (h *VideoHandler) GetVideo(c *gin.Context) {
@@ -267,44 +330,67 @@ func (h *VideoHandler) GetVideo(c *gin.Context) {
 			"video_id":   videoID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID format", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
 		return
 	}
 
 	// Get video details
-	video, err := h.app.Video.GetVideo(uuid)
+	video, err := h.app.Video.GetVideo(c.Request.Context(), uuid)
+	if err == nil && isHidden(c, video) {
+		err = fmt.Errorf("%w: %s", ErrVideoNotFound, uuid)
+	}
 	if err != nil {
-		// Check for specific error messages
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "video not found") || strings.Contains(errMsg, "video has been deleted") {
-			h.app.Logger.LogInfo("Video not found or has been deleted", map[string]interface{}{
-				"request_id": requestID,
-				"video_id":   videoID,
-				"error":      errMsg,
-			})
-
-			// Use different error codes for not found vs deleted
-			errorCode := "VIDEO_NOT_FOUND"
-			if strings.Contains(errMsg, "has been deleted") {
-				errorCode = "VIDEO_DELETED"
-			}
-
-			h.app.ResponseHandler.ErrorResponse(c, http.StatusNotFound, errorCode, errMsg, nil)
-			return
-		}
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video details", "Failed to retrieve video details")
+		return
+	}
 
-		// For other errors
-		h.app.Logger.LogInfo("Failed to get video details", map[string]interface{}{
+	// Gated videos are only playable by their owner and entitled users
+	allowed, err := h.canPlay(c, video)
+	if err != nil {
+		h.app.Logger.LogError("Failed to check video entitlement", map[string]interface{}{
 			"request_id": requestID,
 			"video_id":   videoID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve video details", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeEntitlementCheckFailed, "Failed to check video access", err)
+		return
+	}
+	if !allowed {
+		h.app.Logger.LogInfo("Video requires entitlement", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusPaymentRequired, apierror.CodeEntitlementRequired, "An entitlement is required to play this video", nil)
 		return
 	}
 
+	// Region restrictions apply to everyone but the owner and staff
+	if h.geoBlocked(c, video) {
+		h.app.Logger.LogInfo("Video is not available in the client's country", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusUnavailableForLegalReasons, apierror.CodeGeoBlocked, "This video is not available in your country", nil)
+		return
+	}
+	h.recordPlayback(c, video)
+
 	// Convert to API response
 	response := video.ToVideoDetailsResponse()
+	response.ResumeAt = h.resumePosition(c, video)
+	response.PlaybackURLs = h.playbackURLs(c, video)
+
+	// View counts do not change the ETag, so they may lag until the video is
+	// updated. Signed playback URLs do, so clients never keep expired ones.
+	var resumeAt float64
+	if response.ResumeAt != nil {
+		resumeAt = *response.ResumeAt
+	}
+	if httpHandler.NotModified(c, httpHandler.ETag(getUserID(c), video.version(), resumeAt, response.PlaybackURLs), httpHandler.CacheControlPrivate) {
+		return
+	}
+
+	response.Captions = h.captionTracks(c, video)
 
 	h.app.Logger.LogInfo("Video details retrieved successfully", map[string]interface{}{
 		"request_id": requestID,
@@ -315,415 +401,2221 @@ func (h *VideoHandler) GetVideo(c *gin.Context) {
 	h.app.ResponseHandler.SuccessResponse(c, response, "Video details retrieved successfully")
 }
 
-// Helper function to parse UUID from string
-func parseUUID(id string) (uuid.UUID, error) {
-	return uuid.Parse(id)
-}
-
-// @Summary List videos
-// @Description Retrieve a paginated list of videos with detailed information including transcodes
+// @Summary Stream video
+// @Description Stream a stored rendition of a video. Range requests are supported, so players can seek without downloading the whole file.
 // @Tags video
-// @Produce json
+// @Produce video/mp4
 // @Security BearerAuth
-// @Param limit query int false "Number of videos to return (default: 10, max: 50)"
-// @Param page query int false "Page number for pagination (default: 1)"
-// @Success 200 {object} APIResponse{data=VideoListResponse} "Videos retrieved successfully with detailed information"
-// @Failure 400 {object} APIResponse "Invalid request parameters"
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param resolution path string true "Rendition to stream" Enums(original, 720p, 480p, 360p)
+// @Param Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Success 200 {file} file "Whole rendition"
+// @Success 206 {file} file "Requested byte range"
+// @Failure 400 {object} APIResponse "Invalid video ID or resolution"
 // @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 402 {object} APIResponse "Video requires an entitlement the user does not hold"
+// @Failure 404 {object} APIResponse "Video or rendition not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 416 {string} string "Requested range not satisfiable"
+// @Failure 451 {object} APIResponse "Video is not available in the client's country (GEO_BLOCKED)"
 // @Failure 500 {object} APIResponse "Internal server error"
-// @Router /videos [get]
-func (h *VideoHandler) ListVideos(c *gin.Context) {
+// @Failure 503 {object} APIResponse "Streaming is not available, or the rendition was dropped by storage tiering and is being regenerated; see the Retry-After header"
+// @Router /video/{id}/stream/{resolution} [get]
+func (h *VideoHandler) StreamVideo(c *gin.Context) {
 	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+	resolution := c.Param("resolution")
 
-	// Authentication is already handled by middleware
-	// The following authentication check is removed as it's redundant and insecure
-	// if !isAuthenticated(c) {
-	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
-	//	return
-	// }
-
-	// Parse pagination parameters
-	limit := 10 // Default limit
-	page := 1   // Default page
+	if h.app.Streams == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeStreamUnavailable, "Video streaming is not available", nil)
+		return
+	}
 
-	if limitParam := c.Query("limit"); limitParam != "" {
-		parsedLimit, err := strconv.Atoi(limitParam)
-		if err != nil || parsedLimit <= 0 {
-			h.app.Logger.LogInfo("Invalid limit parameter", map[string]interface{}{
-				"request_id": requestID,
-				"limit":      limitParam,
-			})
-			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid limit parameter, must be a positive integer", nil)
-			return
-		}
-		// Cap limit to prevent excessive queries
-		if parsedLimit > 50 {
-			parsedLimit = 50
-		}
-		limit = parsedLimit
+	uuid, err := parseUUID(videoID)
+	if err != nil {
+		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+	if !videostorage.ValidateResolution(resolution) {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter,
+			fmt.Sprintf("Invalid resolution: %s", resolution), nil)
+		return
 	}
 
-	if pageParam := c.Query("page"); pageParam != "" {
-		parsedPage, err := strconv.Atoi(pageParam)
-		if err != nil || parsedPage <= 0 {
-			h.app.Logger.LogInfo("Invalid page parameter", map[string]interface{}{
-				"request_id": requestID,
-				"page":       pageParam,
-			})
-			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid page parameter, must be a positive integer", nil)
-			return
-		}
-		page = parsedPage
+	video, err := h.app.Video.GetVideo(c.Request.Context(), uuid)
+	if err == nil && isHidden(c, video) {
+		err = fmt.Errorf("%w: %s", ErrVideoNotFound, uuid)
+	}
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for streaming", "Failed to retrieve video")
+		return
 	}
 
-	// Query videos
-	videos, err := h.app.Video.ListVideos(page, limit)
+	allowed, err := h.canPlay(c, video)
 	if err != nil {
-		h.app.Logger.LogInfo("Failed to list videos", map[string]interface{}{
+		h.app.Logger.LogError("Failed to check video entitlement", map[string]interface{}{
 			"request_id": requestID,
+			"video_id":   videoID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve videos", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeEntitlementCheckFailed, "Failed to check video access", err)
+		return
+	}
+	if !allowed {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusPaymentRequired, apierror.CodeEntitlementRequired, "An entitlement is required to play this video", nil)
 		return
 	}
 
-	// Build response with detailed video information
-	videoDetails := make([]VideoDetailsResponse, 0, len(videos))
-	for _, video := range videos {
-		videoDetails = append(videoDetails, video.ToVideoDetailsResponse())
+	// Region restrictions apply to everyone but the owner and staff
+	if h.geoBlocked(c, video) {
+		h.app.Logger.LogInfo("Video is not available in the client's country", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusUnavailableForLegalReasons, apierror.CodeGeoBlocked, "This video is not available in your country", nil)
+		return
 	}
 
-	response := VideoListResponse{
-		Videos: videoDetails,
-		Page:   page,
-		Limit:  limit,
-		Total:  int64(len(videoDetails)), // For MVP, this is just the current page count - note we're converting to int64
+	key, ok := renditionKey(video, resolution)
+	if !ok {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusNotFound, apierror.CodeRenditionNotFound,
+			fmt.Sprintf("Video has no %s rendition", resolution), nil)
+		return
 	}
 
-	h.app.Logger.LogInfo("Videos retrieved successfully", map[string]interface{}{
-		"request_id": requestID,
-		"count":      len(videos),
-		"page":       page,
-		"limit":      limit,
-	})
+	// Renditions dropped by storage tiering are transcoded again on demand;
+	// players retry, or pick another resolution, meanwhile
+	if resolution != "original" && video.RenditionsDroppedAt != nil && h.app.Tiering != nil {
+		h.app.Tiering.RegenerateRenditions(video.ID)
+		c.Header("Retry-After", strconv.Itoa(int(regenerateRetryAfter/time.Second)))
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeRenditionRegenerating,
+			fmt.Sprintf("The %s rendition is being regenerated", resolution), nil)
+		return
+	}
 
-	// Directly pass the VideoListResponse to SuccessResponse without wrapping it in APIResponse
-	h.app.ResponseHandler.SuccessResponse(c, response, "Videos retrieved successfully")
+	object, err := h.app.Streams.OpenVideo(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, videostorage.ErrNotFound) {
+			h.app.Logger.LogInfo("Stored rendition is missing", map[string]interface{}{
+				"request_id": requestID,
+				"video_id":   videoID,
+				"key":        key,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusNotFound, apierror.CodeRenditionNotFound,
+				fmt.Sprintf("Video has no %s rendition", resolution), nil)
+			return
+		}
+		h.app.Logger.LogError("Failed to open stored video", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"key":        key,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to open video", err)
+		return
+	}
+	defer object.Close()
+
+	// ServeContent answers Range and If-Range requests and sets
+	// Content-Length and Accept-Ranges from the object's size
+	c.Header("Content-Type", "video/mp4")
+	c.Header("Cache-Control", "private, max-age=3600")
+	http.ServeContent(c.Writer, c.Request, "", object.ModTime(), object)
 }
 
-// @Summary Get video upload status
-// @Description Retrieve the current upload status of a specific video
+// @Summary List video sources
+// @Description Every place each rendition of a video can be fetched from, so clients can pick one or fetch parts from several: the API's stream endpoint, the CDN, a temporary storage link and the IPFS copy, with its CID, gateway URL and peers known to hold it. Each rendition carries the SHA-256 fetched files must match. Resolutions dropped by storage tiering are left out until streaming one regenerates them; gated videos follow the same rules as GET /video/{id}.
 // @Tags video
 // @Produce json
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Param id path string true "Video ID (UUID)"
-// @Success 200 {object} APIResponse{data=map[string]string} "Video status retrieved successfully"
+// @Success 200 {object} APIResponse{data=VideoSourcesResponse} "Sources retrieved successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID"
 // @Failure 401 {object} APIResponse "Unauthorized"
-// @Failure 404 {object} APIResponse "Video not found or has been deleted"
+// @Failure 402 {object} APIResponse "Video requires an entitlement the user does not hold"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 451 {object} APIResponse "Video is not available in the client's country (GEO_BLOCKED)"
 // @Failure 500 {object} APIResponse "Internal server error"
-// @Router /video/{id}/status [get]
-func (h *VideoHandler) GetVideoStatus(c *gin.Context) {
+// @Router /video/{id}/sources [get]
+func (h *VideoHandler) GetVideoSources(c *gin.Context) {
 	requestID := c.GetString("request_id")
 	videoID := c.Param("id")
 
-	// Authentication is already handled by middleware
-	// The following authentication check is removed as it's redundant and insecure
-	// if !isAuthenticated(c) {
-	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
-	//	return
-	// }
-
-	// Parse UUID from string
 	uuid, err := parseUUID(videoID)
 	if err != nil {
-		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
-			"error":      err.Error(),
-		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID format", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
 		return
 	}
 
-	// Get video details
-	video, err := h.app.Video.GetVideo(uuid)
+	video, err := h.app.Video.GetVideo(c.Request.Context(), uuid)
+	if err == nil && isHidden(c, video) {
+		err = fmt.Errorf("%w: %s", ErrVideoNotFound, uuid)
+	}
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video sources", "Failed to retrieve video")
+		return
+	}
+
+	allowed, err := h.canPlay(c, video)
+	if err != nil {
+		h.app.Logger.LogError("Failed to check video entitlement", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeEntitlementCheckFailed, "Failed to check video access", err)
+		return
+	}
+	if !allowed {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusPaymentRequired, apierror.CodeEntitlementRequired, "An entitlement is required to play this video", nil)
+		return
+	}
+
+	// Region restrictions apply to everyone but the owner and staff
+	if h.geoBlocked(c, video) {
+		h.app.Logger.LogInfo("Video is not available in the client's country", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusUnavailableForLegalReasons, apierror.CodeGeoBlocked, "This video is not available in your country", nil)
+		return
+	}
+
+	response := VideoSourcesResponse{VideoID: video.ID.String(), Renditions: []RenditionSources{}}
+	for _, file := range renditionFiles(video) {
+		response.Renditions = append(response.Renditions, RenditionSources{
+			Rendition: file.name,
+			SHA256:    file.checksum,
+			Size:      file.size,
+			Sources:   h.renditionSources(c, video, file),
+		})
+	}
+
+	h.app.ResponseHandler.SuccessResponse(c, response, "Sources retrieved successfully")
+}
+
+// renditionSources lists where one rendition can be fetched from. Unsigned
+// CDN URLs keep working for anyone they are shared with, so gated videos
+// only get signed ones, as with playback URLs.
+func (h *VideoHandler) renditionSources(c *gin.Context, video *Video, file renditionFile) []VideoSource {
+	sources := []VideoSource{}
+	if h.app.Streams != nil {
+		streamPath := strings.TrimSuffix(c.Request.URL.Path, "/sources") + "/stream/" + file.name
+		sources = append(sources, VideoSource{Type: SourceStream, URL: streamPath})
+	}
+
+	logFailure := func(source string, err error) {
+		h.app.Logger.LogError("Failed to link video source", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"video_id":   video.ID,
+			"rendition":  file.name,
+			"source":     source,
+			"error":      err.Error(),
+		})
+	}
+	if h.app.CDN != nil && (!video.RequiresEntitlement || h.app.CDN.Signed()) {
+		if url, err := h.app.CDN.URL(file.key); err != nil {
+			logFailure(SourceCDN, err)
+		} else {
+			sources = append(sources, VideoSource{Type: SourceCDN, URL: url})
+		}
+	}
+	if h.app.Files != nil {
+		if url, err := h.app.Files.GetVideoURL(c.Request.Context(), file.key); err != nil {
+			logFailure(SourceStorage, err)
+		} else {
+			sources = append(sources, VideoSource{Type: SourceStorage, URL: url})
+		}
+	}
+	if file.cid != "" {
+		sources = append(sources, h.app.Sources.ipfsSource(file.cid))
+	}
+	return sources
+}
+
+// regenerateRetryAfter is how long players are asked to wait for dropped
+// renditions to be transcoded again
+const regenerateRetryAfter = 30 * time.Second
+
+// renditionKey returns the storage key of a video's rendition at resolution
+func renditionKey(video *Video, resolution string) (string, bool) {
+	if resolution == "original" {
+		return video.StoragePath, video.StoragePath != ""
+	}
+	if segment := renditionSegment(video, resolution); segment != nil {
+		return segment.StoragePath, true
+	}
+	return "", false
+}
+
+// renditionSegment returns the stored file of a video's resolution, or nil
+func renditionSegment(video *Video, resolution string) *TranscodeSegment {
+	for _, transcode := range video.Transcodes {
+		if transcode.Format != "mp4" {
+			continue
+		}
+		for i, segment := range transcode.Segments {
+			if path.Base(segment.StoragePath) == resolution+".mp4" {
+				return &transcode.Segments[i]
+			}
+		}
+	}
+	return nil
+}
+
+// canPlay reports whether the requesting user may play the video
+func (h *VideoHandler) canPlay(c *gin.Context, video *Video) (bool, error) {
+	if !video.RequiresEntitlement || h.app.Entitlements == nil {
+		return true, nil
+	}
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		return false, nil
+	}
+	if userID == video.UserID {
+		return true, nil
+	}
+	return h.app.Entitlements.HasAccess(c.Request.Context(), userID, video.ID)
+}
+
+// geoBlocked reports whether region restrictions stop the requesting client
+// from playing the video. Owners and staff can play their videos anywhere,
+// and nothing is blocked when GeoIP is not configured.
+func (h *VideoHandler) geoBlocked(c *gin.Context, video *Video) bool {
+	country, located := geoip.Country(c)
+	if !located || canManage(c, video, "moderator", "admin") {
+		return false
+	}
+	return !video.AvailableIn(country)
+}
+
+// Helper function to parse UUID from string
+func parseUUID(id string) (uuid.UUID, error) {
+	return uuid.Parse(id)
+}
+
+// getUserID returns the authenticated user's ID set by the auth middleware, or uuid.Nil if absent
+func getUserID(c *gin.Context) uuid.UUID {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		return uuid.Nil
+	}
+	str, ok := userIDStr.(string)
+	if !ok {
+		return uuid.Nil
+	}
+	userID, err := uuid.Parse(str)
 	if err != nil {
-		// Check for specific error messages
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "video not found") || strings.Contains(errMsg, "video has been deleted") {
-			h.app.Logger.LogInfo("Video not found or has been deleted", map[string]interface{}{
+		return uuid.Nil
+	}
+	return userID
+}
+
+// canManage reports whether the requesting user may change the video. Owners
+// may always manage their own videos; staffRoles lists the roles allowed to
+// act on anyone's. Role names are the ones issued by the auth package.
+func canManage(c *gin.Context, video *Video, staffRoles ...string) bool {
+	if userID := getUserID(c); userID != uuid.Nil && userID == video.UserID {
+		return true
+	}
+	role := c.GetString("role")
+	for _, staff := range staffRoles {
+		if role == staff {
+			return true
+		}
+	}
+	return false
+}
+
+// isHidden reports whether the video's content scan or a takedown hides it
+// from the requesting user
+func isHidden(c *gin.Context, video *Video) bool {
+	return video.HiddenFrom(getUserID(c), c.GetString("role"))
+}
+
+// @Summary List videos
+// @Description Retrieve a paginated list of videos with detailed information including transcodes
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param limit query int false "Number of videos to return (default: 10, max: 50)"
+// @Param page query int false "Page number for pagination (default: 1)"
+// @Param tag query string false "Only videos with this tag"
+// @Param category query string false "Only videos in this category" Enums(education, entertainment, gaming, music, news, science, sports, technology, other)
+// @Param sort query string false "Order of the videos (default: newest)" Enums(newest, oldest, most_viewed, title)
+// @Param origin query string false "Only this instance's videos, or only those of subscribed remote instances; both are listed by default when federation is enabled, for the first 1000 videos" Enums(local, remote)
+// @Param If-None-Match header string false "ETag of a copy the client already has"
+// @Success 200 {object} APIResponse{data=VideoListResponse} "Videos retrieved successfully with detailed information"
+// @Success 304 "The client's copy is current"
+// @Failure 400 {object} APIResponse "Invalid request parameters"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /videos [get]
+func (h *VideoHandler) ListVideos(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	// Authentication is already handled by middleware
+	// The following authentication check is removed as it's redundant and insecure
+	// if !isAuthenticated(c) {
+	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
+	//	return
+	// }
+
+	// Parse pagination parameters
+	limit := 10 // Default limit
+	page := 1   // Default page
+
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsedLimit, err := strconv.Atoi(limitParam)
+		if err != nil || parsedLimit <= 0 {
+			h.app.Logger.LogInfo("Invalid limit parameter", map[string]interface{}{
 				"request_id": requestID,
-				"video_id":   videoID,
-				"error":      errMsg,
+				"limit":      limitParam,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid limit parameter, must be a positive integer", nil)
+			return
+		}
+		// Cap limit to prevent excessive queries
+		if parsedLimit > 50 {
+			parsedLimit = 50
+		}
+		limit = parsedLimit
+	}
+
+	if pageParam := c.Query("page"); pageParam != "" {
+		parsedPage, err := strconv.Atoi(pageParam)
+		if err != nil || parsedPage <= 0 {
+			h.app.Logger.LogInfo("Invalid page parameter", map[string]interface{}{
+				"request_id": requestID,
+				"page":       pageParam,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid page parameter, must be a positive integer", nil)
+			return
+		}
+		page = parsedPage
+	}
+
+	var filter ListFilter
+	if tag := c.Query("tag"); tag != "" {
+		tags, err := NormalizeTags([]string{tag})
+		if err != nil || len(tags) == 0 {
+			h.app.Logger.LogInfo("Invalid tag parameter", map[string]interface{}{
+				"request_id": requestID,
+				"tag":        tag,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid tag parameter", err)
+			return
+		}
+		filter.Tag = tags[0]
+	}
+	if category := Category(c.Query("category")); category != "" {
+		if !category.IsValid() {
+			h.app.Logger.LogInfo("Invalid category parameter", map[string]interface{}{
+				"request_id": requestID,
+				"category":   category,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid category parameter", nil)
+			return
+		}
+		filter.Category = category
+	}
+	if sort := ListSort(c.Query("sort")); sort != "" {
+		if !sort.IsValid() {
+			h.app.Logger.LogInfo("Invalid sort parameter", map[string]interface{}{
+				"request_id": requestID,
+				"sort":       sort,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid sort parameter, must be one of newest, oldest, most_viewed or title", nil)
+			return
+		}
+		filter.Sort = sort
+	}
+
+	// Remote videos are listed alongside local ones unless the origin says otherwise
+	origin := c.Query("origin")
+	if origin != "" && origin != OriginLocal && origin != OriginRemote {
+		h.app.Logger.LogInfo("Invalid origin parameter", map[string]interface{}{
+			"request_id": requestID,
+			"origin":     origin,
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid origin parameter, must be local or remote", nil)
+		return
+	}
+	includeLocal := origin != OriginRemote
+	includeRemote := h.app.Remote != nil && origin != OriginLocal
+	merged := includeLocal && includeRemote
+	if merged && page*limit > maxMergedListing {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter,
+			fmt.Sprintf("Only the first %d videos can be listed across origins, pass origin=local or origin=remote to page further", maxMergedListing), nil)
+		return
+	}
+
+	// Merged listings read both sources from the first video and page the merged result
+	sourcePage, sourceLimit := page, limit
+	if merged {
+		sourcePage, sourceLimit = 1, page*limit
+	}
+
+	// Query videos
+	var videos []Video
+	var total int64
+	videoDetails := []VideoDetailsResponse{}
+	viewerID := getUserID(c)
+	if includeLocal {
+		var err error
+		videos, total, err = h.app.Video.ListVideos(c.Request.Context(), sourcePage, sourceLimit, filter)
+		if err != nil {
+			h.app.Logger.LogInfo("Failed to list videos", map[string]interface{}{
+				"request_id": requestID,
+				"error":      err.Error(),
 			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve videos", err)
+			return
+		}
 
-			// Use different error codes for not found vs deleted
-			errorCode := "VIDEO_NOT_FOUND"
-			if strings.Contains(errMsg, "has been deleted") {
-				errorCode = "VIDEO_DELETED"
+		// Build response with detailed video information
+		for _, video := range videos {
+			details := video.ToVideoDetailsResponse()
+			// Listings never check entitlements; clients fetch a gated video by ID to play it
+			if video.RequiresEntitlement && video.UserID != viewerID {
+				details.RedactPlayback()
 			}
+			videoDetails = append(videoDetails, details)
+		}
+	}
+
+	var remote []VideoDetailsResponse
+	if includeRemote {
+		var remoteTotal int64
+		var err error
+		remote, remoteTotal, err = h.app.Remote.ListRemoteVideos(c.Request.Context(), (sourcePage-1)*sourceLimit, sourceLimit, filter)
+		if err != nil {
+			h.app.Logger.LogInfo("Failed to list remote videos", map[string]interface{}{
+				"request_id": requestID,
+				"error":      err.Error(),
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve videos", err)
+			return
+		}
+		total += remoteTotal
+		if merged {
+			videoDetails = pageOf(mergeListings(videoDetails, remote, filter.Sort), (page-1)*limit, limit)
+		} else {
+			videoDetails = remote
+		}
+	}
+
+	response := VideoListResponse{
+		Videos: videoDetails,
+		Page:   page,
+		Limit:  limit,
+		Total:  total,
+	}
+
+	versions := []interface{}{viewerID, total}
+	for i := range videos {
+		versions = append(versions, videos[i].version())
+	}
+	for i := range remote {
+		versions = append(versions, remote[i].ID, remote[i].UpdatedAt)
+	}
+	if httpHandler.NotModified(c, httpHandler.ETag(versions...), httpHandler.CacheControlPrivate) {
+		return
+	}
+
+	h.app.Logger.LogInfo("Videos retrieved successfully", map[string]interface{}{
+		"request_id": requestID,
+		"count":      len(videoDetails),
+		"total":      total,
+		"page":       page,
+		"limit":      limit,
+	})
+
+	// Directly pass the VideoListResponse to SuccessResponse without wrapping it in APIResponse
+	h.app.ResponseHandler.SuccessResponse(c, response, "Videos retrieved successfully")
+}
+
+// @Summary List related videos
+// @Description Retrieve the videos most related to a video by shared tags, the same channel and co-watching, best first. Rankings are cached, so they can be a few minutes stale.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param limit query int false "Number of videos to return (default: 10, max: 50)"
+// @Param If-None-Match header string false "ETag of a copy the client already has"
+// @Success 200 {object} APIResponse{data=RelatedVideosResponse} "Related videos retrieved successfully"
+// @Success 304 "The client's copy is current"
+// @Failure 400 {object} APIResponse "Invalid video ID or limit"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 451 {object} APIResponse "Video is not available in the client's country (GEO_BLOCKED)"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Failure 503 {object} APIResponse "Related videos are not available"
+// @Router /video/{id}/related [get]
+func (h *VideoHandler) GetRelatedVideos(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	if h.app.Related == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Related videos are not available", nil)
+		return
+	}
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
 
-			h.app.ResponseHandler.ErrorResponse(c, http.StatusNotFound, errorCode, errMsg, nil)
+	limit := 10
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsedLimit, err := strconv.Atoi(limitParam)
+		if err != nil || parsedLimit <= 0 {
+			h.app.Logger.LogInfo("Invalid limit parameter", map[string]interface{}{
+				"request_id": requestID,
+				"limit":      limitParam,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid limit parameter, must be a positive integer", nil)
 			return
 		}
+		limit = min(parsedLimit, MaxRelated)
+	}
+
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err == nil && isHidden(c, video) {
+		err = fmt.Errorf("%w: %s", ErrVideoNotFound, id)
+	}
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for related videos", "Failed to retrieve related videos")
+		return
+	}
+	if h.geoBlocked(c, video) {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusUnavailableForLegalReasons, apierror.CodeGeoBlocked, "This video is not available in your country", nil)
+		return
+	}
+
+	related, err := h.app.Related.ListRelated(c.Request.Context(), video)
+	if err != nil {
+		h.app.Logger.LogError("Failed to list related videos", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve related videos", err)
+		return
+	}
+
+	viewerID := getUserID(c)
+	response := RelatedVideosResponse{Videos: []VideoDetailsResponse{}}
+	versions := []interface{}{viewerID}
+	for i := range related {
+		if len(response.Videos) == limit {
+			break
+		}
+		if isHidden(c, &related[i]) || h.geoBlocked(c, &related[i]) {
+			continue
+		}
+		details := related[i].ToVideoDetailsResponse()
+		// Listings never check entitlements; clients fetch a gated video by ID to play it
+		if related[i].RequiresEntitlement && related[i].UserID != viewerID {
+			details.RedactPlayback()
+		}
+		response.Videos = append(response.Videos, details)
+		versions = append(versions, related[i].version())
+	}
+	if httpHandler.NotModified(c, httpHandler.ETag(versions...), httpHandler.CacheControlPrivate) {
+		return
+	}
+
+	h.app.Logger.LogInfo("Related videos retrieved successfully", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"count":      len(response.Videos),
+	})
+	h.app.ResponseHandler.SuccessResponse(c, response, "Related videos retrieved successfully")
+}
+
+// @Summary Get video upload status
+// @Description Retrieve the current upload status of a specific video: the stage of processing it has reached and when it entered each stage, how much of the original file has been stored, the progress and timing of each rendition, and how many files are pinned to IPFS
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {object} APIResponse{data=VideoStatusResponse} "Video status retrieved successfully"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /video/{id}/status [get]
+func (h *VideoHandler) GetVideoStatus(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	// Authentication is already handled by middleware
+	// The following authentication check is removed as it's redundant and insecure
+	// if !isAuthenticated(c) {
+	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
+	//	return
+	// }
+
+	// Parse UUID from string
+	uuid, err := parseUUID(videoID)
+	if err != nil {
+		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	// Get video details
+	video, err := h.app.Video.GetVideo(c.Request.Context(), uuid)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video status", "Failed to retrieve video status")
+		return
+	}
+
+	// Get upload status
+	response := statusResponse(video)
+
+	h.app.Logger.LogInfo("Video status retrieved successfully", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"status":     response.Status,
+		"stage":      response.Stage,
+	})
+
+	// Directly pass the status to SuccessResponse without wrapping it in APIResponse
+	h.app.ResponseHandler.SuccessResponse(c, response, "Video status retrieved successfully")
+}
+
+// statusResponse describes the processing of video's upload
+func statusResponse(video *Video) VideoStatusResponse {
+	response := VideoStatusResponse{Status: "unknown", TotalBytes: video.FileSize}
+	if upload := video.Upload; upload != nil {
+		response.Status = string(upload.Status)
+		response.StoredBytes = upload.StoredBytes
+		response.TranscodeFailures = upload.TranscodeFailures
+		response.Renditions = upload.Renditions
+		response.RenditionTimes = upload.RenditionTimes
+		response.RenditionProgress = upload.RenditionProgress
+		response.TranscodePercent = transcodePercent(upload)
+		response.Stage = upload.Stage
+		response.StageTimes = upload.StageTimes
+		response.StartedAt = &upload.StartTime
+		response.EndedAt = upload.EndTime
+		response.UpdatedAt = &upload.UpdatedAt
+	}
+	response.IPFSPinned, response.IPFSFiles = ipfsProgress(video)
+	return response
+}
+
+// uploadEventsPollInterval is how often an event stream reads the upload's
+// status again. It catches streams up on uploads processed by another
+// instance and on events dropped for slow clients, and keeps idle
+// connections open through proxies.
+const uploadEventsPollInterval = 5 * time.Second
+
+// @Summary Stream upload progress
+// @Description Follow the processing of a video's upload as Server-Sent Events. The stream opens with a status event carrying the same data as GET /video/{id}/status, then sends stage (the upload reached a stage or ended), stored (bytes of the original stored), rendition (a rendition changed status) and progress (percent of a resolution encoded) events as they happen, and the status again every 5 seconds. It ends with an end event carrying the final status once the upload completes, fails or is interrupted; end has no data when the video no longer exists, as when a duplicate upload is discarded. Only the owner or an admin can follow an upload.
+// @Tags video
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} APIResponse "Invalid video ID"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not the owner of the video"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /video/{id}/events [get]
+func (h *VideoHandler) StreamUploadEvents(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	// Subscribe before reading the status, so no event falls in between
+	events, stop := h.app.UploadEvents.Subscribe(id)
+	defer stop()
+
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for upload events", "Failed to retrieve video status")
+		return
+	}
+	if !canManage(c, video, "admin") {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to follow this upload", nil)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(name string, data interface{}) {
+		c.SSEvent(name, data)
+		c.Writer.Flush()
+	}
+	if uploadEnded(video) {
+		send(UploadEventEnd, statusResponse(video))
+		return
+	}
+	send(UploadEventStatus, statusResponse(video))
+
+	ticker := time.NewTicker(uploadEventsPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-events:
+			if ok {
+				send(event.Name, event.Data)
+				continue
+			}
+			// Processing ended, or the server is shutting down and the
+			// client reconnects elsewhere
+			video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+			switch {
+			case err != nil:
+				send(UploadEventEnd, struct{}{})
+			case uploadEnded(video):
+				send(UploadEventEnd, statusResponse(video))
+			}
+			return
+		case <-ticker.C:
+			video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+			switch {
+			case errors.Is(err, ErrVideoNotFound) || errors.Is(err, ErrVideoDeleted):
+				send(UploadEventEnd, struct{}{})
+				return
+			case err != nil:
+				// Try again at the next tick
+				continue
+			case uploadEnded(video):
+				send(UploadEventEnd, statusResponse(video))
+				return
+			}
+			send(UploadEventStatus, statusResponse(video))
+		}
+	}
+}
+
+// uploadEnded reports whether video's upload is no longer being processed
+func uploadEnded(video *Video) bool {
+	if video.Upload == nil {
+		return true
+	}
+	switch video.Upload.Status {
+	case UploadStatusPending, UploadStatusUploading:
+		return false
+	}
+	return true
+}
+
+// @Summary Update video details
+// @Description Update a video's title, description, category, tags, comments policy, whether it may be embedded or the countries it is played in. Tags replace the existing set. Country restrictions apply to everyone but the owner and staff, and only when GeoIP is configured: videos with allowed countries are only played where the client's country is known to be one of them, and blocked countries get 451 GEO_BLOCKED. Only the owner or an admin can update a video.
+// @Tags video
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param request body VideoUpdateRequest true "Update request"
+// @Success 200 {object} APIResponse{data=VideoDetailsResponse} "Video updated successfully"
+// @Failure 400 {object} APIResponse "Invalid request format or validation error"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not the video owner or an admin"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /video/{id} [patch]
+func (h *VideoHandler) UpdateVideo(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	// Authentication is already handled by middleware
+	// The following authentication check is removed as it's redundant and insecure
+	// if !isAuthenticated(c) {
+	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
+	//	return
+	// }
+
+	// Parse UUID from string
+	uuid, err := parseUUID(videoID)
+	if err != nil {
+		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	// Parse request body
+	var request VideoUpdateRequest
+	err = httpHandler.Bind(c, &request)
+	metadataChanged := request.Title != nil || request.Description != nil || request.Category != nil || request.Tags != nil
+	countriesChanged := request.AllowedCountries != nil || request.BlockedCountries != nil
+	if err == nil && !metadataChanged && request.CommentsPolicy == nil && request.Embeddable == nil && !countriesChanged {
+		err = ErrEmptyUpdate
+	}
+	if err != nil {
+		h.app.Logger.LogInfo("Invalid update request format", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	// Get the current video to check if it exists
+	video, err := h.app.Video.GetVideo(c.Request.Context(), uuid)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for update", "Failed to retrieve video for update")
+		return
+	}
+
+	// Only the owner or an admin may edit a video
+	if !canManage(c, video, "admin") {
+		h.app.Logger.LogInfo("Video update forbidden", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to update this video", nil)
+		return
+	}
+
+	// Extract values to update
+	title := video.Title
+	description := video.Description
+
+	if request.Title != nil {
+		title = *request.Title
+	}
+	if request.Description != nil {
+		description = *request.Description
+	}
+
+	taxonomy := Taxonomy{Category: video.Category, Tags: tagNames(video.Tags)}
+	if request.Category != nil {
+		taxonomy.Category = *request.Category
+	}
+	if request.Tags != nil {
+		// Already validated, so normalizing cannot fail
+		taxonomy.Tags, _ = NormalizeTags(*request.Tags)
+	}
+
+	// Update the video
+	if metadataChanged {
+		if err := h.app.Video.UpdateVideo(c.Request.Context(), uuid, title, description, taxonomy); err != nil {
+			h.app.Logger.LogInfo("Failed to update video", map[string]interface{}{
+				"request_id": requestID,
+				"video_id":   videoID,
+				"error":      err.Error(),
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeUpdateFailed, "Failed to update video", err)
+			return
+		}
+
+		h.recordEvidence(c, uuid, "edit")
+	}
+	if request.CommentsPolicy != nil {
+		if err := h.app.Video.SetCommentsPolicy(c.Request.Context(), uuid, *request.CommentsPolicy); err != nil {
+			h.app.Logger.LogInfo("Failed to update comments policy", map[string]interface{}{
+				"request_id": requestID,
+				"video_id":   videoID,
+				"error":      err.Error(),
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeUpdateFailed, "Failed to update video", err)
+			return
+		}
+	}
+	if request.Embeddable != nil {
+		if err := h.app.Video.SetEmbeddable(c.Request.Context(), uuid, *request.Embeddable); err != nil {
+			h.app.Logger.LogInfo("Failed to update embedding", map[string]interface{}{
+				"request_id": requestID,
+				"video_id":   videoID,
+				"error":      err.Error(),
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeUpdateFailed, "Failed to update video", err)
+			return
+		}
+	}
+	if countriesChanged {
+		// Already validated, so normalizing cannot fail
+		allowed, blocked := video.AllowedCountries, video.BlockedCountries
+		if request.AllowedCountries != nil {
+			allowed, _ = NormalizeCountries(*request.AllowedCountries)
+		}
+		if request.BlockedCountries != nil {
+			blocked, _ = NormalizeCountries(*request.BlockedCountries)
+		}
+		if err := h.app.Video.SetCountries(c.Request.Context(), uuid, allowed, blocked); err != nil {
+			h.app.Logger.LogInfo("Failed to update country restrictions", map[string]interface{}{
+				"request_id": requestID,
+				"video_id":   videoID,
+				"error":      err.Error(),
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeUpdateFailed, "Failed to update video", err)
+			return
+		}
+	}
+
+	// Get updated video
+	updatedVideo, err := h.app.Video.GetVideo(c.Request.Context(), uuid)
+	if err != nil {
+		h.app.Logger.LogInfo("Failed to get updated video", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve updated video", err)
+		return
+	}
+
+	h.app.Logger.LogInfo("Video updated successfully", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+	})
+
+	// Directly pass the VideoDetailsResponse to SuccessResponse without wrapping it in APIResponse
+	h.app.ResponseHandler.SuccessResponse(c, updatedVideo.ToVideoDetailsResponse(), "Video updated successfully")
+}
+
+// splitTags accepts tags as repeated form fields, comma-separated, or both
+func splitTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+	return tags
+}
+
+// @Summary Popular tags
+// @Description List the tags used by the most videos, most used first
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param limit query int false "Number of tags to return (default: 20, max: 100)"
+// @Success 200 {object} APIResponse{data=[]TagCount} "Popular tags retrieved successfully"
+// @Failure 400 {object} APIResponse "Invalid request parameters"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /tags/popular [get]
+func (h *VideoHandler) PopularTags(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	limit := 20
+	if limitParam := c.Query("limit"); limitParam != "" {
+		parsedLimit, err := strconv.Atoi(limitParam)
+		if err != nil || parsedLimit <= 0 {
+			h.app.Logger.LogInfo("Invalid limit parameter", map[string]interface{}{
+				"request_id": requestID,
+				"limit":      limitParam,
+			})
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid limit parameter, must be a positive integer", nil)
+			return
+		}
+		if parsedLimit > 100 {
+			parsedLimit = 100
+		}
+		limit = parsedLimit
+	}
+
+	tags, err := h.app.Video.PopularTags(c.Request.Context(), limit)
+	if err != nil {
+		h.app.Logger.LogInfo("Failed to count tags", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve popular tags", err)
+		return
+	}
+	if tags == nil {
+		tags = []TagCount{}
+	}
+
+	h.app.ResponseHandler.SuccessResponse(c, tags, "Popular tags retrieved successfully")
+}
+
+// @Summary Delete video
+// @Description Soft delete a video (marks as deleted but preserves the record). Owners can delete their own videos; moderators and admins can delete any video. Files are kept until the retention period passes, then purged with the record; until then the video is in its owner's trash and can be restored.
+// @Description With hard=true the video's files, IPFS pins and records are removed immediately; only the owner and admins may do this. If a step fails the video stays deleted and the request can be retried.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param hard query bool false "Permanently delete the video now"
+// @Success 200 {object} APIResponse "Video deleted successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID format"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not the video owner, a moderator or an admin; hard deletes need the owner or an admin"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /video/{id} [delete]
+func (h *VideoHandler) DeleteVideo(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	// Authentication is already handled by middleware
+	// The following authentication check is removed as it's redundant and insecure
+	// if !isAuthenticated(c) {
+	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
+	//	return
+	// }
+
+	// Parse UUID from string
+	uuid, err := parseUUID(videoID)
+	if err != nil {
+		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	// Check if video exists
+	video, err := h.app.Video.GetVideo(c.Request.Context(), uuid)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for deletion", "Failed to retrieve video for deletion")
+		return
+	}
+
+	// Moderators and admins may take down other users' videos, but only
+	// owners and admins may remove one for good
+	hard, _ := strconv.ParseBool(c.Query("hard"))
+	staffRoles := []string{"moderator", "admin"}
+	if hard {
+		staffRoles = []string{"admin"}
+	}
+	if !canManage(c, video, staffRoles...) {
+		h.app.Logger.LogInfo("Video deletion forbidden", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"hard":       hard,
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to delete this video", nil)
+		return
+	}
+
+	// Capture reported videos while they can still be read
+	h.recordEvidence(c, uuid, "delete")
+
+	if hard {
+		h.purgeVideo(c, video, requestID)
+		return
+	}
+
+	// Soft delete the video
+	if err := h.app.Video.DeleteVideo(c.Request.Context(), uuid, getUserID(c)); err != nil {
+		h.app.Logger.LogInfo("Failed to delete video", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDeleteFailed, "Failed to delete video", err)
+		return
+	}
+
+	h.app.Logger.LogInfo("Video soft deleted successfully", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+	})
+	h.recordAudit(c, audit.VideoDeleted, uuid, map[string]string{"owner": video.UserID.String(), "hard": "false"})
+	h.publishDeleted(c, video, false)
+
+	// Directly pass a success message to SuccessResponse without wrapping it in APIResponse
+	h.app.ResponseHandler.SuccessResponse(c, nil, "Video deleted successfully")
+}
+
+// @Summary Replace a video's file
+// @Description Upload a new file for an existing video. The video keeps its ID, metadata, comments and views; the file is processed like a new upload and replaces the current renditions. The files being replaced are archived as a version and kept for the version retention period. Only the owner can replace a video.
+// @Tags video
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param video formData file true "New video file (.mp4, .mov)"
+// @Success 200 {object} APIResponse{data=UploadResponse} "Video replaced successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID, request format or validation error"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not the video owner"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 409 {object} APIResponse "The video's upload is still being processed"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 422 {object} APIResponse "Not a decodable video, unsupported codec or too long (ERR_INVALID_MEDIA), or rejected by the content scan (ERR_CONTENT_REJECTED)"
+// @Failure 429 {object} APIResponse "Too many uploads, or the transcode backlog is full; see the Retry-After header"
+// @Failure 500 {object} APIResponse "Processing error"
+// @Failure 503 {object} APIResponse "Server is shutting down"
+// @Router /video/{id}/replace [post]
+func (h *VideoHandler) HandleReplace(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	if !h.admitUpload(c) {
+		return
+	}
+
+	file, fileHeader, err := c.Request.FormFile("video")
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeNoFile, "No video file received", err)
+		return
+	}
+	defer file.Close()
+
+	var req ReplaceRequest
+	if err := httpHandler.BindWith(c, &req, binding.FormMultipart); err != nil {
+		h.app.Logger.LogInfo("Video replacement validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"filename":   fileHeader.Filename,
+			"error":      err.Error(),
+		})
+		// Replacements answer validation errors like uploads
+		var invalid *apierror.Error
+		if errors.As(err, &invalid) && invalid.Code == apierror.CodeValidation {
+			invalid.Code = apierror.CodeUploadValidation
+		}
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for replacement", "Failed to retrieve video for replacement")
+		return
+	}
+
+	// Only the owner may replace a video's file
+	if !canManage(c, video) {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to replace this video", nil)
+		return
+	}
+
+	// Capture reported videos before their file changes
+	h.recordEvidence(c, id, "edit")
+
+	upload, err := h.app.Video.ReplaceVideo(c.Request.Context(), id, fileHeader.Size)
+	if err != nil {
+		if errors.Is(err, ErrReplaceInProgress) {
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusConflict, apierror.CodeConflict, err.Error(), err)
+			return
+		}
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to start video replacement", "Failed to replace video")
+		return
+	}
+
+	// Process the new file synchronously, like an upload
+	if err := h.app.Video.ProcessUpload(c.Request.Context(), upload, file, fileHeader); err != nil {
+		h.app.Logger.LogInfo("Video replacement processing failed", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"filename":   fileHeader.Filename,
+			"error":      err.Error(),
+		})
+		h.respondProcessingError(c, err, id, video.Title)
+		return
+	}
+
+	replaced, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve video details", err)
+		return
+	}
+	response := newUploadResponse(replaced, upload)
+
+	h.app.Logger.LogInfo("Video replaced successfully", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"version":    response.Version,
+	})
+
+	// Followers are not notified again; the video is not new to them
+	h.publishWebhook(c, WebhookVideoProcessed, map[string]interface{}{
+		"video_id":           replaced.ID,
+		"title":              replaced.Title,
+		"status":             response.Status,
+		"version":            response.Version,
+		"transcode_failures": upload.TranscodeFailures,
+	})
+
+	h.app.ResponseHandler.SuccessResponse(c, response, "Video replaced successfully")
+}
+
+// @Summary List video versions
+// @Description List the files a video has had, newest first: the current one followed by those it replaced. Replaced versions are marked purged once their archived files are deleted. Only the owner, moderators and admins can see a video's versions.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {object} APIResponse{data=VersionListResponse} "Versions retrieved successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not the video owner, a moderator or an admin"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /video/{id}/versions [get]
+func (h *VideoHandler) ListVersions(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for versions", "Failed to retrieve video")
+		return
+	}
+	if !canManage(c, video, "moderator", "admin") {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to view this video's versions", nil)
+		return
+	}
+
+	versions, err := h.app.Video.ListVersions(c.Request.Context(), id)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to list video versions", "Failed to retrieve video versions")
+		return
+	}
+
+	h.app.ResponseHandler.SuccessResponse(c, VersionListResponse{VideoID: videoID, Versions: versions}, "Versions retrieved successfully")
+}
+
+// @Summary Upload captions
+// @Description Add a caption track to a video in one language, replacing the track already in that language. SubRip (.srt) and WebVTT (.vtt) files are accepted and stored as WebVTT. With burn_in, a copy of the video with the captions drawn onto the picture is also stored, for players without text track support; this re-encodes the video and takes about as long as a transcode. Only the owner and admins can upload captions.
+// @Tags video
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param file formData file true "Subtitle file (.srt or .vtt, at most 2 MiB)"
+// @Param language formData string true "BCP 47 language tag, e.g. en or pt-BR"
+// @Param label formData string false "Name shown in players' track menus (max 64 characters)" maxLength(64)
+// @Param burn_in formData boolean false "Also store a copy of the video with the captions burned in"
+// @Success 200 {object} APIResponse{data=CaptionTrack} "Captions uploaded successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID, or a malformed or unsupported subtitle file"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not the video owner or an admin"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 409 {object} APIResponse "Captions cannot be burned in before the video has finished processing"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 429 {object} APIResponse "Too many uploads, or the transcode backlog is full; see the Retry-After header"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Failure 503 {object} APIResponse "Captions are not available"
+// @Router /video/{id}/captions [post]
+func (h *VideoHandler) HandleCaptionUpload(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	if h.app.Captions == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Captions are not available", nil)
+		return
+	}
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	var req CaptionRequest
+	if err := httpHandler.BindWith(c, &req, binding.FormMultipart); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for captions", "Failed to retrieve video")
+		return
+	}
+	if !canManage(c, video, "admin") {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to add captions to this video", nil)
+		return
+	}
+
+	data, err := readFormFile(req.File)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeNoFile, "Failed to read the caption file", err)
+		return
+	}
+	format, _ := caption.FormatOf(req.File.Filename)
+
+	// Captions are shown with the video, so reported videos are captured first
+	h.recordEvidence(c, id, "edit")
+
+	track, err := h.app.Captions.UploadCaption(c.Request.Context(), video, CaptionUpload{
+		Language: req.Language,
+		Label:    req.Label,
+		Format:   format,
+		Data:     data,
+		BurnIn:   req.BurnIn,
+	})
+	if err != nil {
+		h.app.Logger.LogInfo("Caption upload failed", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"language":   req.Language,
+			"error":      err.Error(),
+		})
+		apierror.Abort(c, err, "Failed to store captions")
+		return
+	}
+
+	h.app.Logger.LogInfo("Captions uploaded successfully", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"language":   req.Language,
+		"burned_in":  req.BurnIn,
+	})
+	h.app.ResponseHandler.SuccessResponse(c, track, "Captions uploaded successfully")
+}
+
+// readFormFile returns the contents of an uploaded file
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// @Summary List captions
+// @Description List a video's caption tracks by language, with URLs serving them as WebVTT. URLs from S3 expire, so clients should fetch them again rather than store them.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {object} APIResponse{data=CaptionListResponse} "Captions retrieved successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 402 {object} APIResponse "Video requires an entitlement the user does not hold"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Failure 503 {object} APIResponse "Captions are not available"
+// @Router /video/{id}/captions [get]
+func (h *VideoHandler) ListCaptions(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	if h.app.Captions == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Captions are not available", nil)
+		return
+	}
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err == nil && isHidden(c, video) {
+		err = fmt.Errorf("%w: %s", ErrVideoNotFound, id)
+	}
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for captions", "Failed to retrieve video")
+		return
+	}
+
+	// Captions are part of playback, so gated videos keep them to entitled users
+	allowed, err := h.canPlay(c, video)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeEntitlementCheckFailed, "Failed to check video access", err)
+		return
+	}
+	if !allowed {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusPaymentRequired, apierror.CodeEntitlementRequired, "An entitlement is required to play this video", nil)
+		return
+	}
+
+	tracks, err := h.app.Captions.Tracks(c.Request.Context(), video)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeInternal, "Failed to retrieve captions", err)
+		return
+	}
+
+	h.app.ResponseHandler.SuccessResponse(c, CaptionListResponse{VideoID: videoID, Captions: tracks}, "Captions retrieved successfully")
+}
+
+// captionTracks returns the caption tracks shown in a video's details. They
+// are left out, rather than failing the request, when they cannot be resolved.
+func (h *VideoHandler) captionTracks(c *gin.Context, video *Video) []CaptionTrack {
+	if h.app.Captions == nil || len(video.Captions) == 0 {
+		return nil
+	}
+	tracks, err := h.app.Captions.Tracks(c.Request.Context(), video)
+	if err != nil {
+		h.app.Logger.LogError("Failed to resolve caption tracks", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"video_id":   video.ID,
+			"error":      err.Error(),
+		})
+		return nil
+	}
+	return tracks
+}
+
+// playbackURLs returns the CDN URLs of a video's renditions, or nil without
+// a CDN. Renditions dropped by storage tiering are left out until they are
+// regenerated, as are all but the preview of gated videos when URLs are not
+// signed, since unsigned URLs keep working for anyone they are shared with.
+func (h *VideoHandler) playbackURLs(c *gin.Context, video *Video) map[string]string {
+	if h.app.CDN == nil {
+		return nil
+	}
+
+	keys := map[string]string{videostorage.RenditionPreview: video.PreviewPath}
+	if !video.RequiresEntitlement || h.app.CDN.Signed() {
+		keys["original"] = video.StoragePath
+		keys[videostorage.RenditionAudio] = video.AudioPath
+		if video.RenditionsDroppedAt == nil {
+			for _, resolution := range []string{"720p", "480p", "360p"} {
+				keys[resolution], _ = renditionKey(video, resolution)
+			}
+		}
+	}
+
+	urls := make(map[string]string, len(keys))
+	for name, key := range keys {
+		if key == "" {
+			continue
+		}
+		url, err := h.app.CDN.URL(key)
+		if err != nil {
+			h.app.Logger.LogError("Failed to build playback URL", map[string]interface{}{
+				"request_id": c.GetString("request_id"),
+				"video_id":   video.ID,
+				"rendition":  name,
+				"error":      err.Error(),
+			})
+			continue
+		}
+		urls[name] = url
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	return urls
+}
+
+// @Summary Set video chapters
+// @Description Replace a video's chapters, shown by players as a chapter list. The first chapter starts at 0, each starts after the one before, and all start before the end of the video. An empty list removes them; video details then show the chapters listed in the description, if any. Only the owner and admins can set chapters.
+// @Tags video
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param request body ChaptersRequest true "Chapters, in order"
+// @Success 200 {object} APIResponse{data=ChaptersResponse} "Chapters updated successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID, or chapters out of order or past the end of the video"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not the video owner or an admin"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 409 {object} APIResponse "The video has not finished processing"
+// @Failure 410 {object} APIResponse "Video has been deleted"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /video/{id}/chapters [patch]
+func (h *VideoHandler) UpdateChapters(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	var request ChaptersRequest
+	if err := httpHandler.Bind(c, &request); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get video for chapters", "Failed to retrieve video")
+		return
+	}
+	if !canManage(c, video, "admin") {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to update this video", nil)
+		return
+	}
+
+	h.recordEvidence(c, id, "edit")
+
+	chapters := request.Chapters
+	if chapters == nil {
+		chapters = []Chapter{}
+	}
+	if err := h.app.Video.SetChapters(c.Request.Context(), id, chapters); err != nil {
+		h.app.Logger.LogInfo("Failed to set video chapters", map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID,
+			"error":      err.Error(),
+		})
+		apierror.Abort(c, err, "Failed to update chapters")
+		return
+	}
+
+	h.app.Logger.LogInfo("Video chapters updated", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"chapters":   len(chapters),
+	})
+	h.app.ResponseHandler.SuccessResponse(c, ChaptersResponse{VideoID: videoID, Chapters: chapters}, "Chapters updated successfully")
+}
+
+// @Summary List deleted videos
+// @Description List the requesting user's deleted videos that can still be restored, most recently deleted first. purge_at is when each is purged for good. Videos removed by a moderator or admin are listed with restorable=false; only an admin can restore them.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param limit query int false "Number of videos to return (default: 20, max: 50)"
+// @Param page query int false "Page number for pagination (default: 1)"
+// @Success 200 {object} APIResponse{data=TrashListResponse} "Deleted videos retrieved successfully"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Failure 503 {object} APIResponse "The trash is not configured"
+// @Router /me/videos/trash [get]
+func (h *VideoHandler) ListTrash(c *gin.Context) {
+	if h.app.Trash == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "The trash is not available", nil)
+		return
+	}
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, apierror.CodeUnauthorized, "Authentication required", nil)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 {
+		limit = 20
+	} else if limit > 50 {
+		limit = 50
+	}
+
+	trash, err := h.app.Trash.List(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to list deleted videos")
+		return
+	}
+	h.app.ResponseHandler.SuccessResponse(c, trash, "Deleted videos retrieved successfully")
+}
+
+// @Summary Restore a deleted video
+// @Description Restore a video from the trash before it is purged. Owners can restore videos they deleted themselves; videos removed by a moderator or admin can only be restored by an admin. The video comes back as it was, with its files, comments and views.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {object} APIResponse{data=VideoDetailsResponse} "Video restored successfully"
+// @Failure 400 {object} APIResponse "Invalid video ID format"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Not allowed to restore this video"
+// @Failure 404 {object} APIResponse "No deleted video with this ID"
+// @Failure 409 {object} APIResponse "The video is being purged or past the retention period"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Failure 503 {object} APIResponse "The trash is not configured"
+// @Router /video/{id}/restore [post]
+func (h *VideoHandler) UndeleteVideo(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+	if h.app.Trash == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "The trash is not available", nil)
+		return
+	}
+
+	deleted, err := h.app.Trash.Get(c.Request.Context(), id)
+	if err != nil {
+		apierror.Abort(c, err, "Failed to get deleted video")
+		return
+	}
 
-		// For other errors
-		h.app.Logger.LogInfo("Failed to get video status", map[string]interface{}{
+	// Owners may only undo their own deletes, not a moderator's removal
+	userID := getUserID(c)
+	ownDelete := userID != uuid.Nil && userID == deleted.UserID && deleted.DeletedBy != nil && *deleted.DeletedBy == userID
+	if !ownDelete && c.GetString("role") != "admin" {
+		h.app.Logger.LogInfo("Video restore forbidden", map[string]interface{}{
 			"request_id": requestID,
 			"video_id":   videoID,
-			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve video status", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusForbidden, apierror.CodeForbidden, "You do not have permission to restore this video", nil)
 		return
 	}
 
-	// Get upload status
-	status := "unknown"
-	if video.Upload != nil {
-		status = string(video.Upload.Status)
+	if err := h.app.Trash.Restore(c.Request.Context(), id); err != nil {
+		apierror.Abort(c, err, "Failed to restore video")
+		return
 	}
 
-	h.app.Logger.LogInfo("Video status retrieved successfully", map[string]interface{}{
+	video, err := h.app.Video.GetVideo(c.Request.Context(), id)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to get restored video", "Failed to retrieve restored video")
+		return
+	}
+
+	h.app.Logger.LogInfo("Video restored", map[string]interface{}{
 		"request_id": requestID,
 		"video_id":   videoID,
-		"status":     status,
 	})
-
-	// Directly pass the status map to SuccessResponse without wrapping it in APIResponse
-	h.app.ResponseHandler.SuccessResponse(c, map[string]string{"status": status}, "Video status retrieved successfully")
+	h.app.ResponseHandler.SuccessResponse(c, video.ToVideoDetailsResponse(), "Video restored successfully")
 }
 
-// @Summary Update video details
-// @Description Update a video's title and/or description
-// @Tags video
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param id path string true "Video ID (UUID)"
-// @Param request body VideoUpdateRequest true "Update request"
-// @Success 200 {object} APIResponse{data=VideoDetailsResponse} "Video updated successfully"
-// @Failure 400 {object} APIResponse "Invalid request format or validation error"
-// @Failure 401 {object} APIResponse "Unauthorized"
-// @Failure 404 {object} APIResponse "Video not found or has been deleted"
-// @Failure 500 {object} APIResponse "Internal server error"
-// @Router /video/{id} [patch]
-func (h *VideoHandler) UpdateVideo(c *gin.Context) {
-	requestID := c.GetString("request_id")
-	videoID := c.Param("id")
+// purgeVideo permanently deletes a video for DeleteVideo
+func (h *VideoHandler) purgeVideo(c *gin.Context, video *Video, requestID string) {
+	videoID := video.ID
+	if err := h.app.Video.PurgeVideo(c.Request.Context(), videoID); err != nil {
+		fields := map[string]interface{}{
+			"request_id": requestID,
+			"video_id":   videoID.String(),
+			"error":      err.Error(),
+		}
+		var purgeErr *PurgeError
+		if errors.As(err, &purgeErr) {
+			fields["stage"] = purgeErr.Stage
+		}
+		h.app.Logger.LogInfo("Failed to permanently delete video", fields)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDeleteFailed, "Failed to permanently delete video; the video is hidden and the request can be retried", err)
+		return
+	}
 
-	// Authentication is already handled by middleware
-	// The following authentication check is removed as it's redundant and insecure
-	// if !isAuthenticated(c) {
-	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
-	//	return
-	// }
+	h.app.Logger.LogInfo("Video permanently deleted", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID.String(),
+	})
+	h.recordAudit(c, audit.VideoDeleted, videoID, map[string]string{"owner": video.UserID.String(), "hard": "true"})
+	h.publishDeleted(c, video, true)
+	h.app.ResponseHandler.SuccessResponse(c, nil, "Video permanently deleted")
+}
 
-	// Parse UUID from string
-	uuid, err := parseUUID(videoID)
-	if err != nil {
-		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
+// publishDeleted publishes VIDEO_DELETED for a video that was just deleted,
+// telling its owner and letting consumers remove the comments and redact the
+// notifications that point at it. Failures are logged and do not affect the
+// deletion.
+func (h *VideoHandler) publishDeleted(c *gin.Context, video *Video, hard bool) {
+	if h.app.NotificationService == nil {
+		return
+	}
+	event := &VideoEvent{
+		ID:      uuid.New(),
+		Type:    "VIDEO_DELETED",
+		VideoID: video.ID,
+		UserID:  video.UserID,
+		Title:   video.Title,
+		Metadata: map[string]interface{}{
+			"hard": hard,
+		},
+	}
+	if err := h.app.NotificationService.PublishVideoEvent(c.Request.Context(), event); err != nil {
+		h.app.Logger.LogError("Failed to publish video deletion", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"video_id":   video.ID.String(),
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID format", err)
-		return
 	}
+}
 
-	// Parse request body
-	var request VideoUpdateRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		h.app.Logger.LogInfo("Invalid update request format", map[string]interface{}{
-			"request_id": requestID,
+// recordEvidence snapshots a video for open moderation reports. Failures are
+// logged but do not block the change, since the report snapshot already holds
+// the original.
+func (h *VideoHandler) recordEvidence(c *gin.Context, videoID uuid.UUID, trigger string) {
+	if h.app.Evidence == nil {
+		return
+	}
+	if err := h.app.Evidence.RecordChange(c.Request.Context(), "video", videoID, trigger); err != nil {
+		h.app.Logger.LogError("Failed to record moderation evidence", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
 			"video_id":   videoID,
+			"trigger":    trigger,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format", err)
-		return
 	}
+}
 
-	// Validate the request
-	if err := h.validateUpdateRequest(&request); err != nil {
-		h.app.Logger.LogInfo("Video update validation failed", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
+// publishWebhook queues an event for the uploader's webhooks. Failures are
+// logged and do not affect the upload.
+func (h *VideoHandler) publishWebhook(c *gin.Context, event string, data map[string]interface{}) {
+	if h.app.Webhooks == nil {
+		return
+	}
+	if err := h.app.Webhooks.Publish(c.Request.Context(), getUserID(c), event, data); err != nil {
+		h.app.Logger.LogError("Failed to publish webhook event", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"event":      event,
+			"video_id":   data["video_id"],
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", err.Error(), err)
+	}
+}
+
+// announceVideo delivers a new video to the channel's Fediverse followers.
+// Failures are logged and do not affect the upload.
+func (h *VideoHandler) announceVideo(c *gin.Context, video *Video) {
+	if h.app.Fediverse == nil {
 		return
 	}
+	if err := h.app.Fediverse.AnnounceVideo(c.Request.Context(), video); err != nil {
+		h.app.Logger.LogError("Failed to announce video to the Fediverse", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"video_id":   video.ID,
+			"error":      err.Error(),
+		})
+	}
+}
 
-	// Get the current video to check if it exists
-	video, err := h.app.Video.GetVideo(uuid)
-	if err != nil {
-		// Check for specific error messages
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "video not found") || strings.Contains(errMsg, "video has been deleted") {
-			h.app.Logger.LogInfo("Video not found or has been deleted", map[string]interface{}{
-				"request_id": requestID,
-				"video_id":   videoID,
-				"error":      errMsg,
-			})
+// admitUpload turns the request away with 429 while the transcode backlog is
+// full, so uploads wait at the client instead of piling up on the server, and
+// with 503 while the request body would not fit in temporary storage
+func (h *VideoHandler) admitUpload(c *gin.Context) bool {
+	err := h.app.Transcodes.Admit()
+	var backlog *TranscodeBacklogError
+	if !errors.As(err, &backlog) {
+		return h.admitTempSpace(c)
+	}
+	h.app.Logger.LogInfo("Upload rejected, transcode backlog is full", map[string]interface{}{
+		"request_id": c.GetString("request_id"),
+		"pending":    backlog.Pending,
+	})
+	if seconds := int((backlog.RetryAfter + time.Second - 1) / time.Second); seconds > 0 {
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	h.app.ResponseHandler.ErrorResponse(c, http.StatusTooManyRequests, apierror.CodeRateLimited, "Too many videos are waiting to be processed, please retry later", err)
+	return false
+}
 
-			// Use different error codes for not found vs deleted
-			errorCode := "VIDEO_NOT_FOUND"
-			if strings.Contains(errMsg, "has been deleted") {
-				errorCode = "VIDEO_DELETED"
-			}
+// admitTempSpace turns the request away with 503 when its body would take
+// the temporary files past their budget. Bodies of unknown length are
+// checked against the current usage alone.
+func (h *VideoHandler) admitTempSpace(c *gin.Context) bool {
+	if h.app.TempSpace == nil {
+		return true
+	}
+	err := h.app.TempSpace.CheckSpace(max(c.Request.ContentLength, 0))
+	if err == nil {
+		return true
+	}
+	h.app.Logger.LogInfo("Upload rejected, temporary storage is full", map[string]interface{}{
+		"request_id": c.GetString("request_id"),
+		"error":      err.Error(),
+	})
+	h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "The server is out of space for uploads, please retry later", err)
+	return false
+}
 
-			h.app.ResponseHandler.ErrorResponse(c, http.StatusNotFound, errorCode, errMsg, nil)
-			return
-		}
+// recordAudit appends a video event by the current user to the audit log
+func (h *VideoHandler) recordAudit(c *gin.Context, eventType audit.EventType, videoID uuid.UUID, details map[string]string) {
+	if h.app.Audit == nil {
+		return
+	}
+	h.app.Audit.Record(c.Request.Context(), audit.Event{
+		Type:      eventType,
+		ActorID:   getUserID(c),
+		TargetID:  videoID.String(),
+		Details:   details,
+		IPAddress: c.ClientIP(),
+	})
+}
 
-		// For other errors
-		h.app.Logger.LogInfo("Failed to get video for update", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
+// recordPlayback counts a view and logs a playback start for the owner's
+// access log. The owner's own views are not counted, and failures never block
+// playback.
+func (h *VideoHandler) recordPlayback(c *gin.Context, video *Video) {
+	if getUserID(c) == video.UserID {
+		return
+	}
+	if err := h.app.Video.RecordView(c.Request.Context(), video.ID); err != nil {
+		h.app.Logger.LogError("Failed to record video view", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"video_id":   video.ID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve video for update", err)
+	}
+	if h.app.Access == nil {
 		return
 	}
+	if err := h.app.Access.RecordPlayback(c.Request.Context(), video.ID, c.Request); err != nil {
+		h.app.Logger.LogError("Failed to record video playback", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"video_id":   video.ID,
+			"error":      err.Error(),
+		})
+	}
+}
 
-	// Extract values to update
-	title := video.Title
-	description := video.Description
-
-	if request.Title != nil {
-		title = *request.Title
+// resumePosition returns where the requesting user left off in the video.
+// Failures are logged and leave it unset, so playback starts from the beginning.
+func (h *VideoHandler) resumePosition(c *gin.Context, video *Video) *float64 {
+	userID := getUserID(c)
+	if h.app.History == nil || userID == uuid.Nil {
+		return nil
 	}
-	if request.Description != nil {
-		description = *request.Description
+	position, ok, err := h.app.History.ResumePosition(c.Request.Context(), userID, video.ID)
+	if err != nil {
+		h.app.Logger.LogError("Failed to get resume position", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
+			"video_id":   video.ID,
+			"error":      err.Error(),
+		})
+		return nil
 	}
+	if !ok {
+		return nil
+	}
+	return &position
+}
 
-	// Update the video
-	if err := h.app.Video.UpdateVideo(uuid, title, description); err != nil {
-		h.app.Logger.LogInfo("Failed to update video", map[string]interface{}{
+// respondVideoLookupError responds to an error from VideoService.GetVideo:
+// 404 for unknown videos, 410 for deleted ones and 500 for anything else.
+// logMessage and message describe the failure for the other errors.
+func (h *VideoHandler) respondVideoLookupError(c *gin.Context, err error, requestID, videoID, logMessage, message string) {
+	if errors.Is(err, ErrVideoNotFound) || errors.Is(err, ErrVideoDeleted) {
+		h.app.Logger.LogInfo("Video not found or has been deleted", map[string]interface{}{
 			"request_id": requestID,
 			"video_id":   videoID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update video", err)
+
+		code := apierror.CodeVideoNotFound
+		if errors.Is(err, ErrVideoDeleted) {
+			code = apierror.CodeVideoDeleted
+		}
+		h.app.ResponseHandler.ErrorResponse(c, apierror.Status(code), code, err.Error(), nil)
 		return
 	}
 
-	// Get updated video
-	updatedVideo, err := h.app.Video.GetVideo(uuid)
+	h.app.Logger.LogInfo(logMessage, map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"error":      err.Error(),
+	})
+	h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, message, err)
+}
+
+// @Summary List content scan results
+// @Description List uploads whose content scan needs review, most recently scanned first: flagged, quarantined, blocked and failed scans, or only those with the given status. Admins only.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Only results with this status" Enums(clean, flagged, quarantined, blocked, error, released)
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} APIResponse{data=ScanListResponse} "Scan results retrieved"
+// @Failure 400 {object} APIResponse "Invalid status"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Admin role required"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /admin/scans [get]
+func (h *VideoHandler) ListScanResults(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	status := ScanStatus(c.Query("status"))
+	if !status.IsValid() {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidParameter, "Invalid status parameter", nil)
+		return
+	}
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	videos, err := h.app.Video.ListScanResults(c.Request.Context(), status, page, limit)
 	if err != nil {
-		h.app.Logger.LogInfo("Failed to get updated video", map[string]interface{}{
+		h.app.Logger.LogError("Failed to list scan results", map[string]interface{}{
 			"request_id": requestID,
-			"video_id":   videoID,
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve updated video", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to retrieve scan results", err)
 		return
 	}
 
-	h.app.Logger.LogInfo("Video updated successfully", map[string]interface{}{
+	results := make([]ScanReport, 0, len(videos))
+	for _, video := range videos {
+		results = append(results, video.ToScanReport())
+	}
+	h.app.ResponseHandler.SuccessResponse(c, ScanListResponse{Results: results, Page: page, Limit: limit}, "Scan results retrieved successfully")
+}
+
+// @Summary Release a video from its content scan
+// @Description Clear a flagged, quarantined or failed content scan, publishing the video. Blocked uploads have nothing stored and cannot be released. Admins only.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID (UUID)"
+// @Success 200 {object} APIResponse "Video released"
+// @Failure 400 {object} APIResponse "Invalid video ID"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Admin role required"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 409 {object} APIResponse "Video has no scan result to release"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /admin/videos/{id}/release [post]
+func (h *VideoHandler) ReleaseVideo(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
+
+	if err := h.app.Video.ReleaseVideo(c.Request.Context(), id); err != nil {
+		if errors.Is(err, ErrNotReleasable) {
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusConflict, apierror.CodeConflict, err.Error(), err)
+			return
+		}
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to release video", "Failed to release video")
+		return
+	}
+
+	h.app.Logger.LogInfo("Video released from content scan", map[string]interface{}{
 		"request_id": requestID,
 		"video_id":   videoID,
+		"admin_id":   getUserID(c),
 	})
-
-	// Directly pass the VideoDetailsResponse to SuccessResponse without wrapping it in APIResponse
-	h.app.ResponseHandler.SuccessResponse(c, updatedVideo.ToVideoDetailsResponse(), "Video updated successfully")
+	h.app.ResponseHandler.SuccessResponse(c, nil, "Video released successfully")
 }
 
-// validateUpdateRequest validates the video update request
-func (h *VideoHandler) validateUpdateRequest(request *VideoUpdateRequest) error {
-	// Check if at least one field is being updated
-	if request.Title == nil && request.Description == nil {
-		return errors.New("at least one field (title or description) must be provided")
+// @Summary Take down a video
+// @Description Hide a video from listings and from everyone but its owner and staff, who see the reason. Taking down a video that is already down updates the reason. Admins only.
+// @Tags video
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param request body TakedownRequest true "Takedown reason"
+// @Success 200 {object} APIResponse "Video taken down"
+// @Failure 400 {object} APIResponse "Invalid video ID or missing reason"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Admin role required"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Router /admin/videos/{id}/takedown [post]
+func (h *VideoHandler) TakeDownVideo(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
 	}
 
-	// Validate title if provided
-	if request.Title != nil {
-		if len(*request.Title) < h.app.Config.Video.MinTitleLength || len(*request.Title) > h.app.Config.Video.MaxTitleLength {
-			return fmt.Errorf("title must be between %d and %d characters",
-				h.app.Config.Video.MinTitleLength,
-				h.app.Config.Video.MaxTitleLength)
-		}
+	var req TakedownRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
 	}
 
-	// Validate description if provided
-	if request.Description != nil && len(*request.Description) > h.app.Config.Video.MaxDescLength {
-		return fmt.Errorf("description cannot exceed %d characters", h.app.Config.Video.MaxDescLength)
+	if err := h.app.Video.TakeDownVideo(c.Request.Context(), id, req.Reason); err != nil {
+		if errors.Is(err, ErrTakedownReason) {
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeValidation, "Reason is required", err)
+			return
+		}
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to take down video", "Failed to take down video")
+		return
 	}
+	h.recordEvidence(c, id, "takedown")
 
-	return nil
+	h.app.Logger.LogInfo("Video taken down", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"admin_id":   getUserID(c),
+		"reason":     req.Reason,
+	})
+	h.app.ResponseHandler.SuccessResponse(c, nil, "Video taken down successfully")
 }
 
-// @Summary Delete video
-// @Description Soft delete a video (marks as deleted but preserves the record)
+// @Summary Restore a taken down video
+// @Description Lift a takedown, publishing the video again. Admins only.
 // @Tags video
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Video ID (UUID)"
-// @Success 200 {object} APIResponse "Video deleted successfully"
-// @Failure 400 {object} APIResponse "Invalid video ID format"
+// @Success 200 {object} APIResponse "Video restored"
+// @Failure 400 {object} APIResponse "Invalid video ID"
 // @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Admin role required"
 // @Failure 404 {object} APIResponse "Video not found"
+// @Failure 409 {object} APIResponse "Video is not taken down"
 // @Failure 500 {object} APIResponse "Internal server error"
-// @Router /video/{id} [delete]
-func (h *VideoHandler) DeleteVideo(c *gin.Context) {
+// @Router /admin/videos/{id}/takedown [delete]
+func (h *VideoHandler) RestoreVideo(c *gin.Context) {
 	requestID := c.GetString("request_id")
 	videoID := c.Param("id")
 
-	// Authentication is already handled by middleware
-	// The following authentication check is removed as it's redundant and insecure
-	// if !isAuthenticated(c) {
-	//	h.app.ResponseHandler.ErrorResponse(c, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", nil)
-	//	return
-	// }
+	id, err := parseUUID(videoID)
+	if err != nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
+		return
+	}
 
-	// Parse UUID from string
-	uuid, err := parseUUID(videoID)
+	if err := h.app.Video.RestoreVideo(c.Request.Context(), id); err != nil {
+		if errors.Is(err, ErrNotTakenDown) {
+			h.app.ResponseHandler.ErrorResponse(c, http.StatusConflict, apierror.CodeConflict, err.Error(), err)
+			return
+		}
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to restore video", "Failed to restore video")
+		return
+	}
+
+	h.app.Logger.LogInfo("Video restored from takedown", map[string]interface{}{
+		"request_id": requestID,
+		"video_id":   videoID,
+		"admin_id":   getUserID(c),
+	})
+	h.app.ResponseHandler.SuccessResponse(c, nil, "Video restored successfully")
+}
+
+// @Summary Get the storage tiering report
+// @Description The storage tiering policy, originals and their bytes by storage class, videos whose renditions are dropped, per-video overrides and the outcome of the latest run. Admins only.
+// @Tags video
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} APIResponse{data=TieringReport} "Tiering report retrieved"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Admin role required"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Failure 503 {object} APIResponse "Storage tiering is not available with this storage backend"
+// @Router /admin/storage/tiering [get]
+func (h *VideoHandler) GetTieringReport(c *gin.Context) {
+	if h.app.Tiering == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Storage tiering is not available", nil)
+		return
+	}
+
+	report, err := h.app.Tiering.Report(c.Request.Context())
 	if err != nil {
-		h.app.Logger.LogInfo("Invalid video ID format", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
+		h.app.Logger.LogError("Failed to build tiering report", map[string]interface{}{
+			"request_id": c.GetString("request_id"),
 			"error":      err.Error(),
 		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid video ID format", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, apierror.CodeDatabase, "Failed to build tiering report", err)
 		return
 	}
+	h.app.ResponseHandler.SuccessResponse(c, report, "Tiering report retrieved successfully")
+}
 
-	// Check if video exists
-	video, err := h.app.Video.GetVideo(uuid)
+// @Summary Set a video's storage tiering override
+// @Description Exempt a video from storage tiering, or tier it at the next run whatever its age and views. Exempting a video whose renditions were dropped regenerates them; an original already moved keeps its storage class. Admins only.
+// @Tags video
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Video ID (UUID)"
+// @Param request body TieringOverrideRequest true "Override"
+// @Success 200 {object} APIResponse{data=VideoTiering} "Override set"
+// @Failure 400 {object} APIResponse "Invalid video ID or override"
+// @Failure 401 {object} APIResponse "Unauthorized"
+// @Failure 403 {object} APIResponse "Admin role required"
+// @Failure 404 {object} APIResponse "Video not found"
+// @Failure 500 {object} APIResponse "Internal server error"
+// @Failure 503 {object} APIResponse "Storage tiering is not available with this storage backend"
+// @Router /admin/videos/{id}/tiering [put]
+func (h *VideoHandler) SetTieringOverride(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	videoID := c.Param("id")
+
+	if h.app.Tiering == nil {
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Storage tiering is not available", nil)
+		return
+	}
+
+	id, err := parseUUID(videoID)
 	if err != nil {
-		h.app.Logger.LogInfo("Failed to get video for deletion", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
-			"error":      err.Error(),
-		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve video for deletion", err)
+		h.app.ResponseHandler.ErrorResponse(c, http.StatusBadRequest, apierror.CodeInvalidID, "Invalid video ID format", err)
 		return
 	}
 
-	if video == nil {
-		h.app.Logger.LogInfo("Video not found for deletion", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
-		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusNotFound, "VIDEO_NOT_FOUND", "Video not found", nil)
+	var req TieringOverrideRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
 		return
 	}
 
-	// Soft delete the video
-	if err := h.app.Video.DeleteVideo(uuid); err != nil {
-		h.app.Logger.LogInfo("Failed to delete video", map[string]interface{}{
-			"request_id": requestID,
-			"video_id":   videoID,
-			"error":      err.Error(),
-		})
-		h.app.ResponseHandler.ErrorResponse(c, http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete video", err)
+	tiering, err := h.app.Tiering.SetOverride(c.Request.Context(), id, req.Override)
+	if err != nil {
+		h.respondVideoLookupError(c, err, requestID, videoID, "Failed to set tiering override", "Failed to set tiering override")
 		return
 	}
 
-	h.app.Logger.LogInfo("Video soft deleted successfully", map[string]interface{}{
+	h.app.Logger.LogInfo("Video tiering override set", map[string]interface{}{
 		"request_id": requestID,
 		"video_id":   videoID,
+		"admin_id":   getUserID(c),
+		"override":   req.Override,
 	})
-
-	// Directly pass a success message to SuccessResponse without wrapping it in APIResponse
-	h.app.ResponseHandler.SuccessResponse(c, nil, "Video deleted successfully")
+	h.app.ResponseHandler.SuccessResponse(c, tiering, "Tiering override set successfully")
 }
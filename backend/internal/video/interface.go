@@ -1,22 +1,218 @@
 package video
 
 import (
+	"context"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+var (
+	// ErrVideoNotFound is returned when no video has the given ID
+	ErrVideoNotFound = apierror.New(apierror.CodeVideoNotFound, "video not found")
+	// ErrVideoDeleted is returned when the video exists but has been soft-deleted
+	ErrVideoDeleted = apierror.New(apierror.CodeVideoDeleted, "video has been deleted")
+	// ErrEmptyUpdate is returned when an update request changes nothing
+	ErrEmptyUpdate = apierror.New(apierror.CodeValidation, "at least one field (title, description, category, tags or comments_policy) must be provided")
+)
+
 // VideoService defines the interface for video operations
 type VideoService interface {
-	InitializeUpload(title, description string, size int64) (*VideoUpload, error)
-	ProcessUpload(upload *VideoUpload, file multipart.File, header *multipart.FileHeader) error
-	GetVideo(videoID uuid.UUID) (*Video, error)
-	ListVideos(page, limit int) ([]Video, error)
-	// DeleteVideo performs a soft delete of a video by setting its DeletedAt field
-	DeleteVideo(videoID uuid.UUID) error
-	UpdateVideo(videoID uuid.UUID, title, description string) error
+	InitializeUpload(ctx context.Context, userID uuid.UUID, title, description string, size int64, taxonomy Taxonomy) (*VideoUpload, error)
+	// ProcessUpload stores and transcodes an upload; cancelling ctx stops the work and marks the upload failed
+	ProcessUpload(ctx context.Context, upload *VideoUpload, file multipart.File, header *multipart.FileHeader) error
+	GetVideo(ctx context.Context, videoID uuid.UUID) (*Video, error)
+	// ListVideos returns a page of published videos and how many match the filter in total
+	ListVideos(ctx context.Context, page, limit int, filter ListFilter) ([]Video, int64, error)
+	// RecordView counts a playback start of a video
+	RecordView(ctx context.Context, videoID uuid.UUID) error
+	// AdjustCommentCount adds delta to a video's comment count
+	AdjustCommentCount(ctx context.Context, videoID uuid.UUID, delta int) error
+	// DeleteVideo performs a soft delete of a video by setting its DeletedAt field, moving it to the trash of its owner
+	DeleteVideo(ctx context.Context, videoID, deletedBy uuid.UUID) error
+	// PurgeVideo permanently deletes a video's files, IPFS pins and records
+	PurgeVideo(ctx context.Context, videoID uuid.UUID) error
+	// UpdateVideo replaces a video's metadata, including its category and tags
+	UpdateVideo(ctx context.Context, videoID uuid.UUID, title, description string, taxonomy Taxonomy) error
+	// PopularTags returns the tags used by the most videos, most used first
+	PopularTags(ctx context.Context, limit int) ([]TagCount, error)
+	// ListScanResults returns videos with the given scan status, or every scan needing review when status is empty
+	ListScanResults(ctx context.Context, status ScanStatus, page, limit int) ([]Video, error)
+	// ReleaseVideo clears a flagged, quarantined or failed scan so the video is published
+	ReleaseVideo(ctx context.Context, videoID uuid.UUID) error
+	// TakeDownVideo hides a video from everyone but its owner and staff, recording why
+	TakeDownVideo(ctx context.Context, videoID uuid.UUID, reason string) error
+	// RestoreVideo lifts a takedown
+	RestoreVideo(ctx context.Context, videoID uuid.UUID) error
+	// ReplaceVideo archives a video's current files as a version and prepares its upload for a new file
+	ReplaceVideo(ctx context.Context, videoID uuid.UUID, size int64) (*VideoUpload, error)
+	// ListVersions returns a video's version history, newest first
+	ListVersions(ctx context.Context, videoID uuid.UUID) ([]VersionInfo, error)
+	// SetChapters replaces a video's chapters after checking them against its duration
+	SetChapters(ctx context.Context, videoID uuid.UUID, chapters []Chapter) error
+	// SetCommentsPolicy changes whether and how a video takes comments
+	SetCommentsPolicy(ctx context.Context, videoID uuid.UUID, policy CommentsPolicy) error
+	// SetEmbeddable allows or forbids playing a video in the embedded player
+	SetEmbeddable(ctx context.Context, videoID uuid.UUID, embeddable bool) error
+	// SetCountries replaces the countries a video is only played in, and
+	// those it is never played in
+	SetCountries(ctx context.Context, videoID uuid.UUID, allowed, blocked CountryList) error
+	// ReprocessVideo transcodes a video's stored original again, replacing its renditions
+	ReprocessVideo(ctx context.Context, videoID uuid.UUID) error
+}
+
+// VideoRepository stores videos with their uploads, transcodes and tags for
+// VideoService. GormVideoRepository is the database implementation.
+type VideoRepository interface {
+	// Transaction runs fn with a repository whose writes are committed
+	// together, or not at all when fn returns an error
+	Transaction(ctx context.Context, fn func(repo VideoRepository) error) error
+	// Create stores a new video and its upload, tagged with tags; missing tags are created
+	Create(ctx context.Context, video *Video, upload *VideoUpload, tags []string) error
+	// GetByID returns a video with its upload, transcodes, tags and captions. It
+	// returns ErrVideoDeleted for soft-deleted videos and ErrVideoNotFound for unknown ones.
+	GetByID(ctx context.Context, videoID uuid.UUID) (*Video, error)
+	// List returns a page of the videos listings show and how many match the filter in total
+	List(ctx context.Context, page, limit int, filter ListFilter) ([]Video, int64, error)
+	// Update replaces a video's title, description, category and tags
+	Update(ctx context.Context, videoID uuid.UUID, title, description string, taxonomy Taxonomy) error
+	// SetChecksum records the SHA-256 of a video's original file
+	SetChecksum(ctx context.Context, videoID uuid.UUID, checksum string) error
+	// FindDuplicate returns the oldest completed, undeleted video with the given
+	// checksum uploaded by the owner of videoID, or nil if there is none
+	FindDuplicate(ctx context.Context, videoID uuid.UUID, checksum string) (*Video, error)
+	// SoftDelete hides a video, recording who deleted it
+	SoftDelete(ctx context.Context, videoID, deletedBy uuid.UUID) error
+	// MarkPurging soft-deletes a video if it is not already and keeps it out of the trash for good
+	MarkPurging(ctx context.Context, videoID uuid.UUID) error
+	// DeleteRecords hard-deletes a video with its upload, transcodes, tags, captions and versions
+	DeleteRecords(ctx context.Context, videoID uuid.UUID) error
+	// IncrementViews counts a view and records when it happened, leaving updated_at alone
+	IncrementViews(ctx context.Context, videoID uuid.UUID) error
+	// AdjustCommentCount adds delta to a video's comment count, never taking it below zero
+	AdjustCommentCount(ctx context.Context, videoID uuid.UUID, delta int) error
+	// PopularTags returns the tags used by the most undeleted videos, most used first
+	PopularTags(ctx context.Context, limit int) ([]TagCount, error)
+	// SetUploadStatus writes an upload's status and end time
+	SetUploadStatus(ctx context.Context, upload *VideoUpload) error
+	// SetStoredBytes records how much of an upload's original has reached storage
+	SetStoredBytes(ctx context.Context, uploadID uuid.UUID, stored int64) error
+	// SetStage records the stage an upload has reached and when it entered each stage
+	SetStage(ctx context.Context, uploadID uuid.UUID, stage UploadStage, times StageTimes) error
+	// SetRenditions records the progress of an upload's renditions and their timing
+	SetRenditions(ctx context.Context, uploadID uuid.UUID, statuses RenditionStatuses, times RenditionTimes) error
+	// SetRenditionProgress records how much of each of an upload's renditions has been encoded
+	SetRenditionProgress(ctx context.Context, uploadID uuid.UUID, progress RenditionProgress) error
+	// DeleteTranscodes hard-deletes a video's transcodes and their segments
+	DeleteTranscodes(ctx context.Context, videoID uuid.UUID) error
+	// CreateTranscode stores a transcode and its segments, filling in their IDs
+	CreateTranscode(ctx context.Context, transcode *Transcode) error
+	// SetOutputs records a video's duration and the storage keys of its audio
+	// rendition and preview, and marks its renditions as present
+	SetOutputs(ctx context.Context, videoID uuid.UUID, outputs RenditionOutputs) error
+	// CompleteUpload writes an upload's status, stage, end time, transcode
+	// failures and renditions
+	CompleteUpload(ctx context.Context, upload *VideoUpload) error
+
+	// GetFiles returns a video, deleted or not, with its transcodes' segments
+	// and its captions: every file stored for it. It returns ErrVideoNotFound
+	// for unknown videos.
+	GetFiles(ctx context.Context, videoID uuid.UUID) (*Video, error)
+	// SetChapters replaces a video's chapters
+	SetChapters(ctx context.Context, videoID uuid.UUID, chapters ChapterList) error
+	// SetCommentsPolicy changes whether and how a video takes comments
+	SetCommentsPolicy(ctx context.Context, videoID uuid.UUID, policy CommentsPolicy) error
+	// SetEmbeddable allows or forbids playing a video in the embedded player
+	SetEmbeddable(ctx context.Context, videoID uuid.UUID, embeddable bool) error
+	// SetCountries replaces the countries a video is only played in, and those it is never played in
+	SetCountries(ctx context.Context, videoID uuid.UUID, allowed, blocked CountryList) error
+
+	// SetScanResult records the result of scanning a video's upload, scanned now
+	SetScanResult(ctx context.Context, videoID uuid.UUID, status ScanStatus, scanner string, findings []string) error
+	// ListScanResults returns a page of undeleted videos with one of the scan
+	// statuses, most recently scanned first
+	ListScanResults(ctx context.Context, statuses []ScanStatus, page, limit int) ([]Video, error)
+	// SetScanStatus changes a video's scan status if it is one of from, and
+	// reports whether it did
+	SetScanStatus(ctx context.Context, videoID uuid.UUID, status ScanStatus, from []ScanStatus) (bool, error)
+
+	// TakeDown marks a video taken down for reason, keeping the time of an
+	// earlier takedown. It returns ErrVideoNotFound for unknown videos.
+	TakeDown(ctx context.Context, videoID uuid.UUID, reason string) error
+	// LiftTakedown clears a video's takedown
+	LiftTakedown(ctx context.Context, videoID uuid.UUID) error
+
+	// ResetUpload sets an upload back to pending as version, started at
+	// startTime, and clears its progress. It reports false, changing nothing,
+	// when the stored upload's status or version no longer match upload's.
+	ResetUpload(ctx context.Context, upload *VideoUpload, version int, startTime time.Time) (bool, error)
+	// CreateVersion records a file a video was replaced with
+	CreateVersion(ctx context.Context, version *VideoVersion) error
+	// SetReplacedFile records the size of a video's new file and clears its
+	// CID, replication status and storage class
+	SetReplacedFile(ctx context.Context, videoID uuid.UUID, size int64) error
+	// ListVersions returns the versions a video's file replaced, newest first
+	ListVersions(ctx context.Context, videoID uuid.UUID) ([]VideoVersion, error)
+
+	// ListTrashed returns a page of the user's deleted videos that are not
+	// being purged and were deleted since deletedSince, when it is set, most
+	// recently deleted first, and how many there are in total
+	ListTrashed(ctx context.Context, userID uuid.UUID, deletedSince time.Time, page, limit int) ([]Video, int64, error)
+	// GetTrashed returns a deleted video. It returns ErrVideoNotFound for
+	// unknown videos and those that are not deleted.
+	GetTrashed(ctx context.Context, videoID uuid.UUID) (*Video, error)
+	// Undelete restores a deleted video that is not being purged and was
+	// deleted since deletedSince, when it is set, and reports whether it did
+	Undelete(ctx context.Context, videoID uuid.UUID, deletedSince time.Time) (bool, error)
+
+	// SaveCaption stores a video's caption track, replacing its track in the
+	// same language, and bumps the video's updated_at
+	SaveCaption(ctx context.Context, caption *VideoCaption) error
+
+	// SharedTags returns up to limit other videos sharing tags with a video,
+	// most shared tags first
+	SharedTags(ctx context.Context, videoID uuid.UUID, limit int) ([]TagOverlap, error)
+	// ChannelVideos returns up to limit IDs of the user's undeleted videos
+	// other than exclude, most viewed first
+	ChannelVideos(ctx context.Context, userID, exclude uuid.UUID, limit int) ([]uuid.UUID, error)
+	// ListListed returns the videos among ids that listings show, with their
+	// upload, transcodes and tags, in no particular order
+	ListListed(ctx context.Context, ids []uuid.UUID) ([]Video, error)
+}
+
+// TagOverlap is a video sharing tags with another
+type TagOverlap struct {
+	VideoID uuid.UUID
+	Shared  int
+}
+
+// CaptionService stores and serves the caption tracks of videos
+type CaptionService interface {
+	// UploadCaption validates a subtitle file and stores it as the video's WebVTT track in its language, replacing any earlier one
+	UploadCaption(ctx context.Context, video *Video, upload CaptionUpload) (*CaptionTrack, error)
+	// Tracks returns the video's caption tracks with URLs they can be fetched from
+	Tracks(ctx context.Context, video *Video) ([]CaptionTrack, error)
+}
+
+// RelatedLister lists the videos related to a video, best first
+type RelatedLister interface {
+	ListRelated(ctx context.Context, video *Video) ([]Video, error)
+}
+
+// TrashService lists and restores soft-deleted videos until they are purged
+type TrashService interface {
+	// List returns a page of the user's deleted videos that have not been purged, most recently deleted first
+	List(ctx context.Context, userID uuid.UUID, page, limit int) (*TrashListResponse, error)
+	// Get returns a deleted video that can still be restored
+	Get(ctx context.Context, videoID uuid.UUID) (*Video, error)
+	// Restore undeletes a video returned by Get
+	Restore(ctx context.Context, videoID uuid.UUID) error
 }
 
 // IPFSService defines the interface for IPFS operations
@@ -33,6 +229,9 @@ type ResponseHandler interface {
 
 // Logger defines the interface for logging operations
 type Logger interface {
+	// LogDebug is for tracing details, such as IDs and content sizes, that
+	// are only logged when the level is debug
+	LogDebug(message string, fields map[string]interface{})
 	LogInfo(message string, fields map[string]interface{})
 	LogError(message string, fields map[string]interface{})
 }
@@ -41,3 +240,95 @@ type Logger interface {
 type NotificationService interface {
 	PublishVideoEvent(ctx interface{}, event interface{}) error
 }
+
+// EntitlementChecker decides whether a user may play a video that requires entitlement
+type EntitlementChecker interface {
+	HasAccess(ctx context.Context, userID, videoID uuid.UUID) (bool, error)
+}
+
+// EvidenceRecorder snapshots reported content when it changes, so moderators
+// keep what was reported after the owner edits or deletes it
+type EvidenceRecorder interface {
+	RecordChange(ctx context.Context, targetType string, targetID uuid.UUID, trigger string) error
+}
+
+// ResumeLookup returns where a user left off in a video, or false when there is nothing to resume
+type ResumeLookup interface {
+	ResumePosition(ctx context.Context, userID, videoID uuid.UUID) (float64, bool, error)
+}
+
+// TieringService reports on storage tiering, sets per-video overrides and
+// regenerates the renditions tiering dropped
+type TieringService interface {
+	Report(ctx context.Context) (*TieringReport, error)
+	SetOverride(ctx context.Context, videoID uuid.UUID, override TieringOverride) (*VideoTiering, error)
+	// RegenerateRenditions transcodes a video's dropped renditions again in the background
+	RegenerateRenditions(videoID uuid.UUID)
+}
+
+// PlaybackCDN hands out URLs for stored video files on a content delivery network
+type PlaybackCDN interface {
+	URL(key string) (string, error)
+	// Signed reports whether URLs expire, so they may be given out for gated videos
+	Signed() bool
+}
+
+// FileURLs links stored files. The storage backends implement it.
+type FileURLs interface {
+	GetVideoURL(ctx context.Context, key string) (string, error)
+}
+
+// TempSpace checks that the temporary files of an upload fit in the budget;
+// tempfile.Manager implements it
+type TempSpace interface {
+	// CheckSpace returns tempfile.ErrSpaceExhausted when size more bytes would exceed the budget
+	CheckSpace(size int64) error
+}
+
+// CDNPurger drops stored files from a content delivery network's caches
+type CDNPurger interface {
+	Purge(ctx context.Context, keys []string) error
+}
+
+// PeerAnnouncer shares replicated videos with the peer-to-peer network
+type PeerAnnouncer interface {
+	// Provide announces on the DHT that the IPFS node holds cid
+	Provide(ctx context.Context, cid string) error
+	// PublishVideo gossips a video's metadata and CIDs to peer Pavilion nodes
+	PublishVideo(ctx context.Context, video *Video) error
+}
+
+// RemoteVideoLister lists videos of remote Pavilion instances this node
+// subscribes to, as references carrying their origin
+type RemoteVideoLister interface {
+	// ListRemoteVideos returns up to limit remote videos after skipping
+	// offset, in filter's order, and how many match the filter in total
+	ListRemoteVideos(ctx context.Context, offset, limit int, filter ListFilter) ([]VideoDetailsResponse, int64, error)
+}
+
+// StreamSource opens stored video files for streaming
+type StreamSource interface {
+	OpenVideo(ctx context.Context, key string) (videostorage.Object, error)
+}
+
+// Webhook events sent about a user's uploads
+const (
+	WebhookVideoProcessed = "video.processed"
+	WebhookVideoFailed    = "video.failed"
+)
+
+// WebhookPublisher queues events for delivery to a user's registered webhooks
+type WebhookPublisher interface {
+	Publish(ctx context.Context, userID uuid.UUID, event string, data interface{}) error
+}
+
+// FediversePublisher delivers newly published videos to the channel's
+// ActivityPub followers
+type FediversePublisher interface {
+	AnnounceVideo(ctx context.Context, video *Video) error
+}
+
+// AccessRecorder logs playback starts with coarse viewer information for the video's owner
+type AccessRecorder interface {
+	RecordPlayback(ctx context.Context, videoID uuid.UUID, r *http.Request) error
+}
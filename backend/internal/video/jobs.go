@@ -0,0 +1,108 @@
+package video
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+)
+
+// ErrShuttingDown is returned for uploads that arrive after shutdown has begun
+var ErrShuttingDown = apierror.New(apierror.CodeServiceUnavailable, "server is shutting down, retry the upload later")
+
+// JobTracker keeps track of uploads being processed so shutdown can let them
+// finish. Jobs run under the caller's context and are also cancelled when
+// Drain gives up waiting for them.
+type JobTracker struct {
+	mu      sync.Mutex
+	closed  bool
+	running int
+	idle    chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewJobTracker creates a tracker that accepts jobs until Drain is called
+func NewJobTracker() *JobTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobTracker{ctx: ctx, cancel: cancel}
+}
+
+// Begin registers a job. The returned context is cancelled when ctx is or
+// when Drain times out; call done once the job has finished cleaning up.
+// Begin fails with ErrShuttingDown once Drain has been called.
+func (t *JobTracker) Begin(ctx context.Context) (context.Context, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, nil, ErrShuttingDown
+	}
+	t.running++
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(t.ctx, cancel)
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			stop()
+			cancel()
+			t.finish()
+		})
+	}
+	return jobCtx, done, nil
+}
+
+func (t *JobTracker) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running--
+	if t.running == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// Running returns the number of jobs in progress
+func (t *JobTracker) Running() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.running
+}
+
+// Drain stops accepting jobs and waits up to timeout for running ones to
+// finish. Jobs still running then are cancelled, and Drain waits for them to
+// record the interruption and remove their files until ctx is done. It
+// returns the number of jobs that were cancelled.
+func (t *JobTracker) Drain(ctx context.Context, timeout time.Duration) int {
+	t.mu.Lock()
+	t.closed = true
+	if t.running == 0 {
+		t.mu.Unlock()
+		return 0
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-idle:
+		return 0
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	interrupted := t.Running()
+	t.cancel()
+	select {
+	case <-idle:
+	case <-ctx.Done():
+	}
+	return interrupted
+}
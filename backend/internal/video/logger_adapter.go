@@ -1,6 +1,11 @@
 package video
 
-import "github.com/consensuslabs/pavilion-network/backend/internal/logger"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+)
 
 // LoggerAdapter adapts the internal logger to the video package's Logger interface
 type LoggerAdapter struct {
@@ -12,12 +17,27 @@ func NewLoggerAdapter(logger logger.Logger) Logger {
 	return &LoggerAdapter{logger: logger}
 }
 
+// LogDebug implements the video.Logger interface
+func (l *LoggerAdapter) LogDebug(message string, fields map[string]interface{}) {
+	l.logger.LogDebug(message, fields)
+}
+
 // LogInfo implements the video.Logger interface
 func (l *LoggerAdapter) LogInfo(message string, fields map[string]interface{}) {
 	l.logger.LogInfo(message, fields)
 }
 
-// LogError implements the video.Logger interface
+// LogError implements the video.Logger interface. The "error" field, when
+// present, becomes the logged error; the other fields are kept.
 func (l *LoggerAdapter) LogError(message string, fields map[string]interface{}) {
-	l.logger.LogError(nil, message)
+	err := errors.New(message)
+	rest := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if k == "error" {
+			err = fmt.Errorf("%v", v)
+			continue
+		}
+		rest[k] = v
+	}
+	l.logger.WithFields(rest).LogError(err, message)
 }
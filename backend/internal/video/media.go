@@ -0,0 +1,31 @@
+package video
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+)
+
+// ErrInvalidMedia is returned for uploads that are not a video the pipeline
+// accepts. Errors wrap it with the reason, which is shown to the uploader.
+var ErrInvalidMedia = apierror.New(apierror.CodeInvalidMedia, "invalid media file")
+
+// CheckMedia checks probed upload metadata against the limits: the file must
+// have a video stream in an allowed codec and be no longer than MaxDuration
+func (l LimitsConfig) CheckMedia(metadata *ffmpeg.VideoMetadata) error {
+	stream := metadata.VideoStream()
+	if stream == nil {
+		return fmt.Errorf("%w: the file has no video stream", ErrInvalidMedia)
+	}
+	if len(l.AllowedVideoCodecs) > 0 && !slices.Contains(l.AllowedVideoCodecs, stream.Codec) {
+		return fmt.Errorf("%w: unsupported video codec %q, supported codecs: %v", ErrInvalidMedia, stream.Codec, l.AllowedVideoCodecs)
+	}
+	duration := time.Duration(metadata.Duration * float64(time.Second))
+	if l.MaxDuration > 0 && duration > l.MaxDuration {
+		return fmt.Errorf("%w: the video is %s long, the maximum is %s", ErrInvalidMedia, duration.Round(time.Second), l.MaxDuration)
+	}
+	return nil
+}
@@ -9,19 +9,84 @@ import (
 
 // Video represents a video entity in the database
 type Video struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	FileID      string         `gorm:"unique;not null" json:"file_id"`
-	Title       string         `gorm:"not null" json:"title"`
-	Description string         `json:"description"`
-	StoragePath string         `gorm:"not null" json:"storage_path"`
-	IPFSCID     string         `gorm:"column:ipfs_cid" json:"ipfs_cid"`
-	Checksum    string         `gorm:"size:64" json:"checksum"`
-	FileSize    int64          `gorm:"not null" json:"file_size"`
-	CreatedAt   time.Time      `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt   time.Time      `gorm:"not null;default:now()" json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	Upload      *VideoUpload   `gorm:"foreignKey:VideoID" json:"upload,omitempty"`
-	Transcodes  []Transcode    `gorm:"foreignKey:VideoID" json:"transcodes,omitempty"`
+	ID          uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID         `gorm:"type:uuid;index" json:"user_id"`
+	FileID      string            `gorm:"unique;not null" json:"file_id"`
+	Title       string            `gorm:"not null" json:"title"`
+	Description string            `json:"description"`
+	StoragePath string            `gorm:"not null" json:"storage_path"`
+	IPFSCID     string            `gorm:"column:ipfs_cid" json:"ipfs_cid"`
+	Replication ReplicationStatus `gorm:"column:replication_status;type:text;not null;default:'s3-only'" json:"replication_status"`
+	// ReplicationUpdatedAt is when the replication status last changed; it
+	// leaves UpdatedAt alone, since clients read that as a content change
+	ReplicationUpdatedAt *time.Time `gorm:"column:replication_updated_at" json:"-"`
+	Checksum             string     `gorm:"size:64;index" json:"checksum"`
+	// AudioPath and PreviewPath are the storage keys of the audio-only
+	// rendition and the animated preview; empty when they were not produced
+	AudioPath   string `gorm:"column:audio_path" json:"audio_path,omitempty"`
+	PreviewPath string `gorm:"column:preview_path" json:"preview_path,omitempty"`
+	// ScanStatus, Scanner, ScanFindings and ScannedAt record the content scan
+	// of the upload. They are only shown to admins; see ScanReport.
+	ScanStatus   ScanStatus `gorm:"column:scan_status;type:text;not null;default:'';index" json:"-"`
+	Scanner      string     `gorm:"column:scanner" json:"-"`
+	ScanFindings string     `gorm:"column:scan_findings;type:text" json:"-"`
+	ScannedAt    *time.Time `gorm:"column:scanned_at" json:"-"`
+	// TakenDownAt and TakedownReason are set when an admin takes the video
+	// down; it is then hidden like a quarantined video until restored
+	TakenDownAt    *time.Time `gorm:"column:taken_down_at;index" json:"-"`
+	TakedownReason string     `gorm:"column:takedown_reason;type:text" json:"-"`
+	// RequiresEntitlement restricts playback to the owner and users holding an entitlement
+	RequiresEntitlement bool           `gorm:"not null;default:false" json:"requires_entitlement"`
+	Category            Category       `gorm:"type:text;index" json:"category,omitempty"`
+	FileSize            int64          `gorm:"not null" json:"file_size"`
+	Views               int64          `gorm:"not null;default:0;index" json:"views"`
+	CreatedAt           time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt           time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
+	Upload              *VideoUpload   `gorm:"foreignKey:VideoID" json:"upload,omitempty"`
+	Transcodes          []Transcode    `gorm:"foreignKey:VideoID" json:"transcodes,omitempty"`
+	Tags                []Tag          `gorm:"many2many:video_tags" json:"tags,omitempty"`
+	Captions            []VideoCaption `gorm:"foreignKey:VideoID" json:"captions,omitempty"`
+	// CommentCount follows comment events and is reconciled with the comment
+	// store periodically, so it may briefly lag behind
+	CommentCount int64 `gorm:"not null;default:0" json:"comment_count"`
+	// Duration is the length of the current file in seconds; 0 for videos
+	// processed before it was recorded
+	Duration float64 `gorm:"not null;default:0" json:"duration"`
+	// Chapters are set by the creator; without them, chapters are taken from the description
+	Chapters ChapterList `gorm:"type:text" json:"chapters,omitempty"`
+	// CommentsPolicy is whether and how the video takes comments
+	CommentsPolicy CommentsPolicy `gorm:"column:comments_policy;type:text;not null;default:'enabled'" json:"comments_policy"`
+	// Embeddable is whether the video may be played in the embedded player
+	Embeddable bool `gorm:"not null;default:true" json:"embeddable"`
+	// AllowedCountries and BlockedCountries restrict where the video is
+	// played, by ISO 3166-1 alpha-2 code; see AvailableIn
+	AllowedCountries CountryList `gorm:"type:text" json:"allowed_countries,omitempty"`
+	BlockedCountries CountryList `gorm:"type:text" json:"blocked_countries,omitempty"`
+	// StorageClass is the S3 storage class the original was moved to by
+	// storage tiering; empty while it is in the bucket's default class
+	StorageClass string `gorm:"column:storage_class;type:text;not null;default:''" json:"-"`
+	// TieringOverride exempts the video from storage tiering or tiers it early
+	TieringOverride TieringOverride `gorm:"column:tiering_override;type:text;not null;default:'default'" json:"-"`
+	// RenditionsDroppedAt is set while the video's resolutions are dropped
+	// from storage; they are transcoded again when next streamed
+	RenditionsDroppedAt *time.Time `gorm:"column:renditions_dropped_at" json:"-"`
+	// LastViewedAt is when a view was last counted; storage tiering drops
+	// the renditions of videos that go unwatched
+	LastViewedAt *time.Time `gorm:"column:last_viewed_at" json:"-"`
+	// DeletedBy is the user who moved the video to the trash
+	DeletedBy *uuid.UUID `gorm:"type:uuid" json:"-"`
+	// Purging is set once a permanent delete has started, after which the
+	// video cannot be restored
+	Purging bool `gorm:"not null;default:false" json:"-"`
+}
+
+// Tag is a free-form label shared by the videos that use it. Names are
+// normalized with NormalizeTags.
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"-"`
+	Name      string    `gorm:"uniqueIndex;not null" json:"name"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"-"`
 }
 
 // VideoUpload represents the upload process tracking
@@ -31,9 +96,30 @@ type VideoUpload struct {
 	StartTime time.Time    `gorm:"not null" json:"start_time"`
 	EndTime   *time.Time   `json:"end_time,omitempty"`
 	Status    UploadStatus `gorm:"type:upload_status;not null" json:"status"`
-	CreatedAt time.Time    `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt time.Time    `gorm:"not null;default:now()" json:"updated_at"`
-	Video     *Video       `gorm:"foreignKey:VideoID" json:"-"`
+	// StoredBytes is how much of the original file has reached storage
+	StoredBytes int64 `gorm:"not null;default:0" json:"stored_bytes"`
+	// Version counts the files the video has had; it goes up each time a
+	// completed upload is replaced
+	Version int `gorm:"not null;default:1" json:"version"`
+	// DuplicateOf is set by ProcessUpload when the file is identical to an
+	// earlier upload by the same user. The new video is discarded and the
+	// earlier one stands in for it.
+	DuplicateOf uuid.UUID `gorm:"-" json:"-"`
+	// TranscodeFailures records renditions that could not be produced and why
+	TranscodeFailures TranscodeFailures `gorm:"type:text" json:"transcode_failures,omitempty"`
+	// Renditions records the progress of each rendition while the upload is processed
+	Renditions RenditionStatuses `gorm:"type:text" json:"renditions,omitempty"`
+	// RenditionTimes records when each rendition started and finished
+	RenditionTimes RenditionTimes `gorm:"type:text" json:"rendition_times,omitempty"`
+	// RenditionProgress records how much of each rendition has been encoded
+	RenditionProgress RenditionProgress `gorm:"type:text" json:"rendition_progress,omitempty"`
+	// Stage is the step of the pipeline the upload has reached, and
+	// StageTimes when it entered each step
+	Stage      UploadStage `gorm:"type:text" json:"stage,omitempty"`
+	StageTimes StageTimes  `gorm:"type:text" json:"stage_times,omitempty"`
+	CreatedAt  time.Time   `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt  time.Time   `gorm:"not null;default:now()" json:"updated_at"`
+	Video      *Video      `gorm:"foreignKey:VideoID" json:"-"`
 }
 
 // Transcode represents a transcoded version of a video
@@ -49,14 +135,20 @@ type Transcode struct {
 
 // TranscodeSegment represents a segment of a transcoded video
 type TranscodeSegment struct {
-	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	TranscodeID uuid.UUID  `gorm:"type:uuid;not null" json:"transcode_id"`
-	StoragePath string     `gorm:"not null" json:"storage_path"`
-	IPFSCID     string     `gorm:"column:ipfs_cid" json:"ipfs_cid"`
-	Duration    int        `json:"duration"`
-	CreatedAt   time.Time  `gorm:"not null;default:now()" json:"created_at"`
-	UpdatedAt   time.Time  `gorm:"not null;default:now()" json:"updated_at"`
-	Transcode   *Transcode `gorm:"foreignKey:TranscodeID" json:"-"`
+	ID          uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TranscodeID uuid.UUID         `gorm:"type:uuid;not null" json:"transcode_id"`
+	StoragePath string            `gorm:"not null" json:"storage_path"`
+	IPFSCID     string            `gorm:"column:ipfs_cid" json:"ipfs_cid"`
+	Replication ReplicationStatus `gorm:"column:replication_status;type:text;not null;default:'s3-only'" json:"replication_status"`
+	// ReplicationUpdatedAt is when the replication status last changed
+	ReplicationUpdatedAt *time.Time `gorm:"column:replication_updated_at" json:"-"`
+	// Checksum is the hex SHA-256 of the stored file, for clients to verify
+	// copies fetched from any source
+	Checksum  string     `gorm:"size:64" json:"checksum,omitempty"`
+	Duration  int        `json:"duration"`
+	CreatedAt time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+	Transcode *Transcode `gorm:"foreignKey:TranscodeID" json:"-"`
 }
 
 // BeforeCreate hook for Video
@@ -67,6 +159,32 @@ func (v *Video) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// HiddenFrom reports whether the video's content scan or a takedown hides it
+// from the user with the given ID and role. Quarantined and taken down videos
+// look like they do not exist to anyone but their owner and staff.
+func (v *Video) HiddenFrom(userID uuid.UUID, role string) bool {
+	if !v.ScanStatus.Hidden() && v.TakenDownAt == nil {
+		return false
+	}
+	return userID != v.UserID && role != "moderator" && role != "admin"
+}
+
+// ListedVideos scopes a query on videos to those listings may show: not
+// quarantined and not taken down
+func ListedVideos(db *gorm.DB) *gorm.DB {
+	return db.Where("videos.scan_status NOT IN ?", []ScanStatus{ScanQuarantined, ScanBlocked}).
+		Where("videos.taken_down_at IS NULL")
+}
+
+// PublicVideos scopes a query on videos to those published for anyone to
+// find: completed uploads that need no entitlement, are not quarantined and
+// are not taken down
+func PublicVideos(db *gorm.DB) *gorm.DB {
+	return ListedVideos(db.Joins("JOIN video_uploads ON video_uploads.video_id = videos.id").
+		Where("video_uploads.status = ?", UploadStatusCompleted).
+		Where("videos.requires_entitlement = ?", false))
+}
+
 // ToVideoInfo converts Video to VideoInfo response type
 func (v *Video) ToVideoInfo() VideoInfo {
 	var status string
@@ -92,6 +210,7 @@ func (v *Video) ToVideoDetailsResponse() VideoDetailsResponse {
 				ID:          s.ID.String(),
 				StoragePath: s.StoragePath,
 				IPFSCID:     s.IPFSCID,
+				Replication: string(s.Replication),
 				Duration:    s.Duration,
 			})
 		}
@@ -109,18 +228,45 @@ func (v *Video) ToVideoDetailsResponse() VideoDetailsResponse {
 	}
 
 	return VideoDetailsResponse{
-		ID:          v.ID.String(),
-		FileID:      v.FileID,
-		Title:       v.Title,
-		Description: v.Description,
-		StoragePath: v.StoragePath,
-		IPFSCID:     v.IPFSCID,
-		Status:      status,
-		FileSize:    v.FileSize,
-		CreatedAt:   v.CreatedAt,
-		UpdatedAt:   v.UpdatedAt,
-		Transcodes:  transcodes,
+		ID:                  v.ID.String(),
+		UserID:              v.UserID.String(),
+		FileID:              v.FileID,
+		Title:               v.Title,
+		Description:         v.Description,
+		StoragePath:         v.StoragePath,
+		AudioPath:           v.AudioPath,
+		PreviewPath:         v.PreviewPath,
+		IPFSCID:             v.IPFSCID,
+		Replication:         string(v.Replication),
+		Status:              status,
+		RequiresEntitlement: v.RequiresEntitlement,
+		Category:            string(v.Category),
+		Tags:                tagNames(v.Tags),
+		FileSize:            v.FileSize,
+		Views:               v.Views,
+		CommentCount:        v.CommentCount,
+		CreatedAt:           v.CreatedAt,
+		UpdatedAt:           v.UpdatedAt,
+		Transcodes:          transcodes,
+		TakenDownAt:         v.TakenDownAt,
+		TakedownReason:      v.TakedownReason,
+		Duration:            v.duration(),
+		Chapters:            v.chapters(),
+		CommentsPolicy:      v.CommentsPolicy,
+		Embeddable:          v.Embeddable,
+		AllowedCountries:    v.AllowedCountries,
+		BlockedCountries:    v.BlockedCountries,
+	}
+}
+
+// version identifies the state of the video shown in API responses, for
+// ETags. It changes when the video or its upload is updated.
+func (v *Video) version() string {
+	version := v.ID.String() + "@" + v.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	if v.Upload != nil {
+		version += "/" + v.Upload.UpdatedAt.UTC().Format(time.RFC3339Nano)
 	}
+	return version
 }
 
 // ToAPIResponse converts Video to a generic APIResponse
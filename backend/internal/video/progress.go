@@ -0,0 +1,190 @@
+package video
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UploadStage is the step of the pipeline an upload has reached. Failed and
+// interrupted uploads keep the stage they stopped at.
+type UploadStage string
+
+const (
+	// UploadStageReceiving means the file is being saved and hashed
+	UploadStageReceiving UploadStage = "receiving"
+	// UploadStageValidating means the file is checked for duplicates and probed
+	UploadStageValidating UploadStage = "validating"
+	// UploadStageScanning means the file is with the content scanner
+	UploadStageScanning UploadStage = "scanning"
+	// UploadStageStoringOriginal means the original file is being uploaded to storage
+	UploadStageStoringOriginal UploadStage = "storing_original"
+	// UploadStageTranscoding means the renditions are being produced; see Renditions
+	UploadStageTranscoding UploadStage = "transcoding"
+	// UploadStageFinalizing means the renditions are being recorded
+	UploadStageFinalizing UploadStage = "finalizing"
+	// UploadStageCompleted means processing finished. The files are pinned to
+	// IPFS afterwards, in the background.
+	UploadStageCompleted UploadStage = "completed"
+)
+
+// StageTimes maps a stage to when the upload entered it, stored as JSON
+type StageTimes map[UploadStage]time.Time
+
+// Value implements driver.Valuer
+func (t StageTimes) Value() (driver.Value, error) {
+	return jsonValue(len(t), t)
+}
+
+// Scan implements sql.Scanner
+func (t *StageTimes) Scan(value interface{}) error {
+	*t = nil
+	return jsonScan(value, t, "StageTimes")
+}
+
+// RenditionTiming records when a rendition started transcoding and when it
+// was stored or failed
+type RenditionTiming struct {
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// RenditionTimes maps a resolution to its timing, stored as JSON
+type RenditionTimes map[string]RenditionTiming
+
+// Value implements driver.Valuer
+func (t RenditionTimes) Value() (driver.Value, error) {
+	return jsonValue(len(t), t)
+}
+
+// Scan implements sql.Scanner
+func (t *RenditionTimes) Scan(value interface{}) error {
+	*t = nil
+	return jsonScan(value, t, "RenditionTimes")
+}
+
+// RenditionProgress maps a resolution to how much of it has been encoded, in
+// whole percent, stored as JSON
+type RenditionProgress map[string]int
+
+// Value implements driver.Valuer
+func (p RenditionProgress) Value() (driver.Value, error) {
+	return jsonValue(len(p), p)
+}
+
+// Scan implements sql.Scanner
+func (p *RenditionProgress) Scan(value interface{}) error {
+	*p = nil
+	return jsonScan(value, p, "RenditionProgress")
+}
+
+// jsonValue encodes v for a text column, or NULL when it has no entries
+func jsonValue(entries int, v interface{}) (driver.Value, error) {
+	if entries == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// jsonScan decodes a text column written by jsonValue into dest
+func jsonScan(value interface{}, dest interface{}, name string) error {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for %s: %T", name, value)
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+// enterStage records that upload reached stage, so the status endpoint can
+// show where processing is. Failing to record it does not stop the upload.
+func (s *VideoServiceImpl) enterStage(ctx context.Context, upload *VideoUpload, stage UploadStage) {
+	if upload.StageTimes == nil {
+		upload.StageTimes = make(StageTimes)
+	}
+	now := time.Now()
+	upload.Stage = stage
+	upload.StageTimes[stage] = now
+	s.events.Publish(upload.VideoID, UploadEventStage, StageEvent{Status: upload.Status, Stage: stage, At: now})
+	if err := s.repo.SetStage(ctx, upload.ID, stage, upload.StageTimes); err != nil {
+		s.logger.LogError("Failed to record upload stage", map[string]interface{}{
+			"error":    err.Error(),
+			"video_id": upload.VideoID,
+			"stage":    stage,
+		})
+	}
+}
+
+// endEvents announces that upload ended with its current status, or was
+// discarded as a duplicate of duplicateOf, and closes the streams following it
+func (s *VideoServiceImpl) endEvents(upload *VideoUpload, duplicateOf *uuid.UUID) {
+	if s.events == nil {
+		return
+	}
+	at := time.Now()
+	if upload.EndTime != nil {
+		at = *upload.EndTime
+	}
+	s.events.Publish(upload.VideoID, UploadEventStage, StageEvent{
+		Status:      upload.Status,
+		Stage:       upload.Stage,
+		At:          at,
+		DuplicateOf: duplicateOf,
+	})
+	s.events.Finish(upload.VideoID)
+}
+
+// transcodePercent averages the encoding progress of upload's renditions,
+// counting those that were stored or failed as done
+func transcodePercent(upload *VideoUpload) int {
+	if len(upload.Renditions) == 0 {
+		return 0
+	}
+	total := 0
+	for resolution, status := range upload.Renditions {
+		switch status {
+		case RenditionCompleted, RenditionFailed:
+			total += 100
+		default:
+			total += upload.RenditionProgress[resolution]
+		}
+	}
+	return total / len(upload.Renditions)
+}
+
+// ipfsProgress counts the files of video that are pinned to IPFS, out of
+// the original and its stored renditions
+func ipfsProgress(video *Video) (pinned, total int) {
+	count := func(status ReplicationStatus) {
+		total++
+		if status == ReplicationReplicated {
+			pinned++
+		}
+	}
+	if video.StoragePath != "" {
+		count(video.Replication)
+	}
+	for _, transcode := range video.Transcodes {
+		for _, segment := range transcode.Segments {
+			count(segment.Replication)
+		}
+	}
+	return pinned, total
+}
@@ -0,0 +1,183 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/cache"
+	"github.com/google/uuid"
+)
+
+// MaxRelated is the most related videos ranked, cached and listed for a video
+const MaxRelated = 50
+
+// RelatedScore is how closely a video relates to another; higher is closer
+type RelatedScore struct {
+	VideoID uuid.UUID
+	Score   float64
+}
+
+// RelatedScorer ranks the videos related to a video, best first. SignalScorer
+// is the default; a smarter recommender can take its place in NewRelatedVideos.
+type RelatedScorer interface {
+	ScoreRelated(ctx context.Context, video *Video, limit int) ([]RelatedScore, error)
+}
+
+// CoWatchLookup counts, for each other video, how many of a video's recent
+// viewers also watched it
+type CoWatchLookup interface {
+	CoWatched(ctx context.Context, videoID uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+// RelatedWeights weighs the signals SignalScorer adds up
+type RelatedWeights struct {
+	// Tags scores sharing every tag with the video; sharing some scores in proportion
+	Tags float64
+	// Channel scores being uploaded by the same user
+	Channel float64
+	// CoWatch scores the video most watched by the video's viewers; others score in proportion
+	CoWatch float64
+}
+
+// DefaultRelatedWeights favour co-watching, then shared tags, then the channel
+var DefaultRelatedWeights = RelatedWeights{Tags: 1, Channel: 0.5, CoWatch: 1.5}
+
+// SignalScorer ranks related videos by shared tags, same channel and
+// co-watching, adding up the weighted score of each signal
+type SignalScorer struct {
+	repo    VideoRepository
+	coWatch CoWatchLookup
+	weights RelatedWeights
+	logger  Logger
+}
+
+// NewSignalScorer creates the default scorer. coWatch may be nil, leaving
+// co-watching out; when it fails, the other signals are still used.
+func NewSignalScorer(repo VideoRepository, coWatch CoWatchLookup, weights RelatedWeights, logger Logger) *SignalScorer {
+	return &SignalScorer{
+		repo:    repo,
+		coWatch: coWatch,
+		weights: weights,
+		logger:  logger,
+	}
+}
+
+// ScoreRelated returns up to limit videos sharing a signal with video, best first
+func (s *SignalScorer) ScoreRelated(ctx context.Context, video *Video, limit int) ([]RelatedScore, error) {
+	candidates := limit * 4
+	scores := make(map[uuid.UUID]float64)
+
+	if len(video.Tags) > 0 && s.weights.Tags > 0 {
+		shared, err := s.repo.SharedTags(ctx, video.ID, candidates)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range shared {
+			scores[row.VideoID] += s.weights.Tags * float64(row.Shared) / float64(len(video.Tags))
+		}
+	}
+
+	if s.weights.Channel > 0 {
+		channel, err := s.repo.ChannelVideos(ctx, video.UserID, video.ID, candidates)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range channel {
+			scores[id] += s.weights.Channel
+		}
+	}
+
+	if s.coWatch != nil && s.weights.CoWatch > 0 {
+		counts, err := s.coWatch.CoWatched(ctx, video.ID)
+		if err != nil {
+			s.logger.LogError("Failed to look up co-watched videos", map[string]interface{}{
+				"error":   err.Error(),
+				"videoID": video.ID,
+			})
+		}
+		most := 0
+		for _, count := range counts {
+			most = max(most, count)
+		}
+		for id, count := range counts {
+			scores[id] += s.weights.CoWatch * float64(count) / float64(most)
+		}
+	}
+
+	return rankScores(scores, limit), nil
+}
+
+// rankScores orders scores best first, breaking ties by ID so rankings are
+// stable, and keeps the first limit
+func rankScores(scores map[uuid.UUID]float64, limit int) []RelatedScore {
+	ranked := make([]RelatedScore, 0, len(scores))
+	for id, score := range scores {
+		ranked = append(ranked, RelatedScore{VideoID: id, Score: score})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].VideoID.String() < ranked[j].VideoID.String()
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// RelatedVideos lists the videos related to a video. Rankings are cached per
+// video, so they can be stale by the cache's TTL; the videos are loaded on
+// every request, so deleted and hidden ones drop out at once.
+type RelatedVideos struct {
+	repo     VideoRepository
+	scorer   RelatedScorer
+	rankings *cache.ReadThrough
+}
+
+// NewRelatedVideos creates related video listings ranked by scorer. rankings
+// may be nil, ranking videos on every request.
+func NewRelatedVideos(repo VideoRepository, scorer RelatedScorer, rankings *cache.ReadThrough) *RelatedVideos {
+	return &RelatedVideos{
+		repo:     repo,
+		scorer:   scorer,
+		rankings: rankings,
+	}
+}
+
+// ListRelated returns up to MaxRelated listed videos related to video, best first
+func (r *RelatedVideos) ListRelated(ctx context.Context, video *Video) ([]Video, error) {
+	var ranked []RelatedScore
+	if err := r.rankings.Get(ctx, video.ID.String(), &ranked, func() (bool, error) {
+		var err error
+		ranked, err = r.scorer.ScoreRelated(ctx, video, MaxRelated)
+		return err == nil, err
+	}); err != nil {
+		return nil, fmt.Errorf("failed to rank related videos: %w", err)
+	}
+	if len(ranked) == 0 {
+		return []Video{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(ranked))
+	for i, score := range ranked {
+		ids[i] = score.VideoID
+	}
+	found, err := r.repo.ListListed(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load related videos: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]Video, len(found))
+	for _, v := range found {
+		byID[v.ID] = v
+	}
+	videos := make([]Video, 0, len(found))
+	for _, id := range ids {
+		if v, ok := byID[id]; ok {
+			videos = append(videos, v)
+		}
+	}
+	return videos, nil
+}
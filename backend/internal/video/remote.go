@@ -0,0 +1,75 @@
+package video
+
+import "strings"
+
+// Origins a listing can be restricted to
+const (
+	// OriginLocal lists only this instance's videos
+	OriginLocal = "local"
+	// OriginRemote lists only videos of subscribed remote instances
+	OriginRemote = "remote"
+)
+
+// maxMergedListing bounds how deep into a listing of both local and remote
+// videos a page may start, since every page re-reads both sources from the
+// first video
+const maxMergedListing = 1000
+
+// mergeListings merges local and remote videos, each already in sort's
+// order, into one list in that order
+func mergeListings(local, remote []VideoDetailsResponse, sort ListSort) []VideoDetailsResponse {
+	merged := make([]VideoDetailsResponse, 0, len(local)+len(remote))
+	i, j := 0, 0
+	for i < len(local) && j < len(remote) {
+		if sort.before(&remote[j], &local[i]) {
+			merged = append(merged, remote[j])
+			j++
+		} else {
+			merged = append(merged, local[i])
+			i++
+		}
+	}
+	merged = append(merged, local[i:]...)
+	return append(merged, remote[j:]...)
+}
+
+// before reports whether a is listed before b, matching orderClause
+func (s ListSort) before(a, b *VideoDetailsResponse) bool {
+	switch s {
+	case SortOldest:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	case SortMostViewed:
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	case SortTitle:
+		if titleA, titleB := strings.ToLower(a.Title), strings.ToLower(b.Title); titleA != titleB {
+			return titleA < titleB
+		}
+		return a.ID < b.ID
+	default:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	}
+}
+
+// pageOf returns the page of videos starting at offset
+func pageOf(videos []VideoDetailsResponse, offset, limit int) []VideoDetailsResponse {
+	if offset >= len(videos) {
+		return []VideoDetailsResponse{}
+	}
+	end := offset + limit
+	if end > len(videos) {
+		end = len(videos)
+	}
+	return videos[offset:end]
+}
@@ -0,0 +1,494 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReplicationStatus tracks whether a stored file has been pinned to IPFS
+type ReplicationStatus string
+
+const (
+	// ReplicationS3Only means the file only exists in S3, either because it has
+	// not been queued yet or because replication gave up
+	ReplicationS3Only ReplicationStatus = "s3-only"
+	// ReplicationReplicating means a worker is currently adding the file to IPFS
+	ReplicationReplicating ReplicationStatus = "replicating"
+	// ReplicationReplicated means the file has an IPFS CID
+	ReplicationReplicated ReplicationStatus = "replicated"
+)
+
+// ErrReplicationQueueFull is returned when a job cannot be queued without blocking
+var ErrReplicationQueueFull = errors.New("replication queue is full")
+
+// ReplicationJob identifies a single file to copy from S3 to IPFS
+type ReplicationJob struct {
+	VideoID uuid.UUID
+	// SegmentID is the rendition segment to replicate; uuid.Nil means the original upload
+	SegmentID uuid.UUID
+	// Key is the S3 object key of the file
+	Key string
+}
+
+// Replicator accepts files for asynchronous IPFS replication
+type Replicator interface {
+	Enqueue(job ReplicationJob) error
+}
+
+// ReplicationSource reads files back from primary storage for replication
+type ReplicationSource interface {
+	DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// ReplicationStore keeps the replication status of original files and
+// rendition segments
+type ReplicationStore interface {
+	// Pending returns up to limit files to replicate: those left S3 only, and
+	// those marked replicating but not touched since staleBefore, whose worker
+	// must have died
+	Pending(ctx context.Context, staleBefore time.Time, limit int) ([]ReplicationJob, error)
+	// Claim marks a file replicating for a worker. It returns false when the
+	// file is already replicated, or replicating and touched since staleBefore.
+	Claim(ctx context.Context, job ReplicationJob, staleBefore time.Time) (bool, error)
+	// SetStatus updates a file's status, and its CID when one is given
+	SetStatus(ctx context.Context, job ReplicationJob, status ReplicationStatus, cid string) error
+	// LoadVideo returns a video with its transcodes, segments and tags
+	LoadVideo(ctx context.Context, videoID uuid.UUID) (*Video, error)
+}
+
+// ReplicationConfig controls concurrency and retries of the replication queue
+type ReplicationConfig struct {
+	Workers    int           // Number of concurrent IPFS adds
+	QueueSize  int           // Maximum number of jobs waiting for a worker
+	MaxRetries int           // Attempts per job before giving up
+	RetryDelay time.Duration // Base delay between attempts, multiplied by the attempt number
+	// RescanInterval is how often files left S3 only are queued again; 0
+	// only queues them at start
+	RescanInterval time.Duration
+	// StaleAfter is how long a file may stay replicating without an attempt
+	// before it is queued again; it should exceed the longest single IPFS add
+	StaleAfter time.Duration
+}
+
+// DefaultReplicationStaleAfter is used when ReplicationConfig.StaleAfter is not set
+const DefaultReplicationStaleAfter = time.Hour
+
+// ReplicationQueue copies uploaded files to IPFS in the background so a slow
+// IPFS node never stalls video processing. The queue is bounded: when it is
+// full, Enqueue fails fast and the file stays "s3-only" until the next rescan
+// queues it again, as it does files replication gave up on.
+type ReplicationQueue struct {
+	store  ReplicationStore
+	ipfs   IPFSService
+	source ReplicationSource
+	peers  PeerAnnouncer
+	config ReplicationConfig
+	logger Logger
+
+	jobs chan ReplicationJob
+	// queued holds the jobs waiting in jobs, so rescans don't queue them twice
+	queued   map[ReplicationJob]bool
+	queuedMu sync.Mutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewReplicationQueue creates a replication queue; call Start to begin
+// processing. peers may be nil, in which case replicated files are not
+// announced to the peer-to-peer network.
+func NewReplicationQueue(store ReplicationStore, ipfs IPFSService, source ReplicationSource, peers PeerAnnouncer, config ReplicationConfig, logger Logger) *ReplicationQueue {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 1
+	}
+	if config.MaxRetries < 1 {
+		config.MaxRetries = 1
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = DefaultReplicationStaleAfter
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ReplicationQueue{
+		store:  store,
+		ipfs:   ipfs,
+		source: source,
+		peers:  peers,
+		config: config,
+		logger: logger,
+		jobs:   make(chan ReplicationJob, config.QueueSize),
+		queued: make(map[ReplicationJob]bool),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start launches the worker pool and queues pending files, now and then
+// every RescanInterval
+func (q *ReplicationQueue) Start() {
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	q.wg.Add(1)
+	go q.rescan()
+}
+
+// Stop stops accepting work and waits for in-flight jobs to finish. Jobs still
+// waiting in the queue keep their "s3-only" status and are retried on the next start.
+func (q *ReplicationQueue) Stop() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+// Enqueue schedules a job without blocking the caller. A job already waiting
+// in the queue is not queued again.
+func (q *ReplicationQueue) Enqueue(job ReplicationJob) error {
+	if q.ctx.Err() != nil {
+		return fmt.Errorf("replication queue stopped: %w", q.ctx.Err())
+	}
+
+	q.queuedMu.Lock()
+	defer q.queuedMu.Unlock()
+	if q.queued[job] {
+		return nil
+	}
+
+	select {
+	case q.jobs <- job:
+		q.queued[job] = true
+		return nil
+	default:
+		q.logger.LogError("Replication queue full, leaving file in S3 only", map[string]interface{}{
+			"video_id":   job.VideoID,
+			"segment_id": job.SegmentID,
+			"queue_size": q.config.QueueSize,
+		})
+		return ErrReplicationQueueFull
+	}
+}
+
+// Pending returns the number of jobs waiting for a worker
+func (q *ReplicationQueue) Pending() int {
+	return len(q.jobs)
+}
+
+// EnqueuePending queues files that are not replicated and that no worker is
+// replicating, such as those left behind by a full queue, a restart or a
+// worker that gave up. It stops early once the queue is full.
+func (q *ReplicationQueue) EnqueuePending() error {
+	jobs, err := q.store.Pending(q.ctx, time.Now().Add(-q.config.StaleAfter), q.config.QueueSize)
+	if err != nil {
+		return fmt.Errorf("failed to list unreplicated files: %w", err)
+	}
+	for _, job := range jobs {
+		if err := q.Enqueue(job); err != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// rescan queues pending files at start and then every RescanInterval until
+// the queue is stopped
+func (q *ReplicationQueue) rescan() {
+	defer q.wg.Done()
+
+	var tick <-chan time.Time
+	if q.config.RescanInterval > 0 {
+		ticker := time.NewTicker(q.config.RescanInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		if err := q.EnqueuePending(); err != nil && q.ctx.Err() == nil {
+			q.logger.LogError("Failed to queue pending IPFS replications", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-tick:
+		}
+	}
+}
+
+// worker processes jobs until the queue is stopped
+func (q *ReplicationQueue) worker() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case job := <-q.jobs:
+			q.queuedMu.Lock()
+			delete(q.queued, job)
+			q.queuedMu.Unlock()
+			q.process(job)
+		}
+	}
+}
+
+// process replicates a single job, retrying with a linear backoff. Each
+// attempt marks the file replicating again, so rescans leave it alone while
+// attempts continue.
+func (q *ReplicationQueue) process(job ReplicationJob) {
+	claimed, err := q.store.Claim(context.Background(), job, time.Now().Add(-q.config.StaleAfter))
+	if err != nil {
+		q.logger.LogError("Failed to mark file as replicating", map[string]interface{}{
+			"video_id":   job.VideoID,
+			"segment_id": job.SegmentID,
+			"error":      err.Error(),
+		})
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= q.config.MaxRetries; attempt++ {
+		if attempt > 1 {
+			if err := q.setStatus(job, ReplicationReplicating, ""); err != nil {
+				q.logger.LogError("Failed to mark file as replicating", map[string]interface{}{
+					"video_id":   job.VideoID,
+					"segment_id": job.SegmentID,
+					"error":      err.Error(),
+				})
+			}
+		}
+
+		cid, err := q.replicate(job)
+		if err == nil {
+			if err := q.setStatus(job, ReplicationReplicated, cid); err != nil {
+				q.logger.LogError("Failed to record IPFS CID", map[string]interface{}{
+					"video_id":   job.VideoID,
+					"segment_id": job.SegmentID,
+					"cid":        cid,
+					"error":      err.Error(),
+				})
+				return
+			}
+			q.logger.LogInfo("Replicated file to IPFS", map[string]interface{}{
+				"video_id":   job.VideoID,
+				"segment_id": job.SegmentID,
+				"cid":        cid,
+				"attempts":   attempt,
+			})
+			q.announce(job, cid)
+			return
+		}
+
+		lastErr = err
+		q.logger.LogError("IPFS replication attempt failed", map[string]interface{}{
+			"video_id":   job.VideoID,
+			"segment_id": job.SegmentID,
+			"attempt":    attempt,
+			"error":      err.Error(),
+		})
+
+		if attempt < q.config.MaxRetries {
+			select {
+			case <-q.ctx.Done():
+				q.setStatus(job, ReplicationS3Only, "")
+				return
+			case <-time.After(q.config.RetryDelay * time.Duration(attempt)):
+			}
+		}
+	}
+
+	q.logger.LogError("Giving up on IPFS replication", map[string]interface{}{
+		"video_id":   job.VideoID,
+		"segment_id": job.SegmentID,
+		"error":      lastErr.Error(),
+	})
+	q.setStatus(job, ReplicationS3Only, "")
+}
+
+// replicate streams the file from S3 into IPFS and returns its CID
+func (q *ReplicationQueue) replicate(job ReplicationJob) (string, error) {
+	reader, err := q.source.DownloadVideo(q.ctx, job.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s from storage: %w", job.Key, err)
+	}
+	defer reader.Close()
+
+	cid, err := q.ipfs.UploadFileStream(reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to add %s to IPFS: %w", job.Key, err)
+	}
+	return cid, nil
+}
+
+// setStatus updates the replication status, and the CID when one is given.
+// It runs even while the queue stops, so interrupted jobs are put back.
+func (q *ReplicationQueue) setStatus(job ReplicationJob, status ReplicationStatus, cid string) error {
+	return q.store.SetStatus(context.Background(), job, status, cid)
+}
+
+// announce provides a replicated file on the DHT, and once every file of its
+// video is replicated, gossips the video to peer Pavilion nodes. Gated and
+// hidden videos are provided but not gossiped. Failures are logged; the file
+// stays reachable by CID through the IPFS node.
+func (q *ReplicationQueue) announce(job ReplicationJob, cid string) {
+	if q.peers == nil {
+		return
+	}
+
+	if err := q.peers.Provide(q.ctx, cid); err != nil {
+		q.logger.LogError("Failed to announce CID on the DHT", map[string]interface{}{
+			"video_id": job.VideoID,
+			"cid":      cid,
+			"error":    err.Error(),
+		})
+	}
+
+	video, err := q.store.LoadVideo(q.ctx, job.VideoID)
+	if err != nil {
+		q.logger.LogError("Failed to load video for peer announcement", map[string]interface{}{
+			"video_id": job.VideoID,
+			"error":    err.Error(),
+		})
+		return
+	}
+	if !fullyReplicated(video) || video.RequiresEntitlement || video.ScanStatus.Hidden() || video.TakenDownAt != nil {
+		return
+	}
+
+	if err := q.peers.PublishVideo(q.ctx, video); err != nil {
+		q.logger.LogError("Failed to gossip video to peers", map[string]interface{}{
+			"video_id": job.VideoID,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// fullyReplicated reports whether a video's original and every rendition
+// have an IPFS CID
+func fullyReplicated(video *Video) bool {
+	if video.Replication != ReplicationReplicated {
+		return false
+	}
+	for _, transcode := range video.Transcodes {
+		for _, segment := range transcode.Segments {
+			if segment.Replication != ReplicationReplicated {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GormReplicationStore is the GORM implementation of ReplicationStore
+type GormReplicationStore struct {
+	db *gorm.DB
+}
+
+// NewGormReplicationStore creates a replication store backed by db
+func NewGormReplicationStore(db *gorm.DB) *GormReplicationStore {
+	return &GormReplicationStore{db: db}
+}
+
+// claimable matches the files of table that are S3 only, or replicating but
+// stale. Files marked replicating before claims were timed count as stale.
+func claimable(db *gorm.DB, table string, staleBefore time.Time) *gorm.DB {
+	return db.Where(fmt.Sprintf("%[1]s.replication_status = ? OR (%[1]s.replication_status = ? AND "+
+		"(%[1]s.replication_updated_at IS NULL OR %[1]s.replication_updated_at < ?))", table),
+		ReplicationS3Only, ReplicationReplicating, staleBefore)
+}
+
+// Pending lists original files before rendition segments. Originals are
+// listed once their upload has completed; until then the file may still be
+// on its way to storage or about to be replaced.
+func (s *GormReplicationStore) Pending(ctx context.Context, staleBefore time.Time, limit int) ([]ReplicationJob, error) {
+	var videos []Video
+	if err := claimable(s.db.WithContext(ctx), "videos", staleBefore).
+		Joins("JOIN video_uploads ON video_uploads.video_id = videos.id").
+		Where("video_uploads.status = ?", UploadStatusCompleted).
+		Order("videos.created_at").Limit(limit).Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to list unreplicated videos: %w", err)
+	}
+	jobs := make([]ReplicationJob, 0, len(videos))
+	for _, v := range videos {
+		jobs = append(jobs, ReplicationJob{VideoID: v.ID, Key: v.StoragePath})
+	}
+	if len(jobs) >= limit {
+		return jobs, nil
+	}
+
+	var segments []TranscodeSegment
+	if err := claimable(s.db.WithContext(ctx).Preload("Transcode"), "transcode_segments", staleBefore).
+		Order("created_at").Limit(limit - len(jobs)).Find(&segments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list unreplicated segments: %w", err)
+	}
+	for _, seg := range segments {
+		if seg.Transcode == nil {
+			continue
+		}
+		jobs = append(jobs, ReplicationJob{VideoID: seg.Transcode.VideoID, SegmentID: seg.ID, Key: seg.StoragePath})
+	}
+	return jobs, nil
+}
+
+// Claim updates the status only if the file is still claimable, so two
+// instances never replicate the same file at once
+func (s *GormReplicationStore) Claim(ctx context.Context, job ReplicationJob, staleBefore time.Time) (bool, error) {
+	updates := map[string]interface{}{
+		"replication_status":     ReplicationReplicating,
+		"replication_updated_at": time.Now(),
+	}
+	var result *gorm.DB
+	if job.SegmentID == uuid.Nil {
+		result = claimable(s.db.WithContext(ctx), "videos", staleBefore).
+			Model(&Video{}).Where("id = ?", job.VideoID).UpdateColumns(updates)
+	} else {
+		result = claimable(s.db.WithContext(ctx), "transcode_segments", staleBefore).
+			Model(&TranscodeSegment{}).Where("id = ?", job.SegmentID).UpdateColumns(updates)
+	}
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetStatus updates a video's or segment's replication status. Only the
+// replication columns change, so replication is not reported to clients as
+// a change to the video.
+func (s *GormReplicationStore) SetStatus(ctx context.Context, job ReplicationJob, status ReplicationStatus, cid string) error {
+	updates := map[string]interface{}{
+		"replication_status":     status,
+		"replication_updated_at": time.Now(),
+	}
+	if cid != "" {
+		updates["ipfs_cid"] = cid
+	}
+
+	if job.SegmentID == uuid.Nil {
+		return s.db.WithContext(ctx).Model(&Video{}).Where("id = ?", job.VideoID).UpdateColumns(updates).Error
+	}
+	return s.db.WithContext(ctx).Model(&TranscodeSegment{}).Where("id = ?", job.SegmentID).UpdateColumns(updates).Error
+}
+
+// LoadVideo loads a video with its transcodes, segments and tags
+func (s *GormReplicationStore) LoadVideo(ctx context.Context, videoID uuid.UUID) (*Video, error) {
+	var video Video
+	if err := s.db.WithContext(ctx).Preload("Transcodes.Segments").Preload("Tags").First(&video, "id = ?", videoID).Error; err != nil {
+		return nil, err
+	}
+	return &video, nil
+}
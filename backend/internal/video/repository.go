@@ -0,0 +1,633 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormVideoRepository implements VideoRepository on the SQL database
+type GormVideoRepository struct {
+	db *gorm.DB
+}
+
+// NewGormVideoRepository creates a video repository on db
+func NewGormVideoRepository(db *gorm.DB) *GormVideoRepository {
+	return &GormVideoRepository{db: db}
+}
+
+// Transaction runs fn with a repository bound to a database transaction
+func (r *GormVideoRepository) Transaction(ctx context.Context, fn func(repo VideoRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormVideoRepository{db: tx})
+	})
+}
+
+// Create stores a new video and its upload in one transaction
+func (r *GormVideoRepository) Create(ctx context.Context, video *Video, upload *VideoUpload, tags []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		records, err := findOrCreateTags(tx, tags)
+		if err != nil {
+			return err
+		}
+		video.Tags = records
+		if err := tx.Create(video).Error; err != nil {
+			return fmt.Errorf("failed to create video record: %w", err)
+		}
+		if err := tx.Create(upload).Error; err != nil {
+			return fmt.Errorf("failed to create upload record: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetByID reads a video and its upload, transcodes, tags and captions
+func (r *GormVideoRepository) GetByID(ctx context.Context, videoID uuid.UUID) (*Video, error) {
+	var video Video
+
+	// Use Unscoped to check if the video exists at all, including soft-deleted ones
+	var count int64
+	if err := r.db.WithContext(ctx).Unscoped().Model(&Video{}).Where("id = ?", videoID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check video existence: %w", err)
+	}
+
+	// Now try to get the non-deleted video
+	if err := r.db.WithContext(ctx).Preload("Upload").Preload("Transcodes").Preload("Transcodes.Segments").Preload("Tags").Preload("Captions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("language")
+	}).First(&video, videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if count > 0 {
+				// Video exists but is soft-deleted
+				return nil, fmt.Errorf("%w: %s", ErrVideoDeleted, videoID)
+			}
+			// Video doesn't exist at all
+			return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	return &video, nil
+}
+
+// List reads a page of listed videos, optionally narrowed to one tag or category
+func (r *GormVideoRepository) List(ctx context.Context, page, limit int, filter ListFilter) ([]Video, int64, error) {
+	var videos []Video
+	offset := (page - 1) * limit
+
+	query := listedVideos(r.db.WithContext(ctx))
+	if filter.Category != "" {
+		query = query.Where("videos.category = ?", filter.Category)
+	}
+	if filter.Tag != "" {
+		query = query.Where("videos.id IN (?)", r.db.Table("video_tags").
+			Select("video_tags.video_id").
+			Joins("JOIN tags ON tags.id = video_tags.tag_id").
+			Where("tags.name = ?", filter.Tag))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count videos: %w", err)
+	}
+
+	if err := query.Preload("Upload").Preload("Transcodes").Preload("Transcodes.Segments").Preload("Tags").
+		Order(filter.Sort.orderClause()).Offset(offset).Limit(limit).Find(&videos).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	return videos, total, nil
+}
+
+// listedVideos narrows db to the videos listings show: not deleted, not hidden
+// by a content scan and not taken down
+func listedVideos(db *gorm.DB) *gorm.DB {
+	// Note: GORM's default scope already excludes soft-deleted records
+	// but we're being explicit here for clarity
+	return ListedVideos(db.Model(&Video{}).Where("videos.deleted_at IS NULL"))
+}
+
+// Update sets a video's metadata and replaces its tags in one transaction
+func (r *GormVideoRepository) Update(ctx context.Context, videoID uuid.UUID, title, description string, taxonomy Taxonomy) error {
+	updates := map[string]interface{}{
+		"title":       title,
+		"description": description,
+		"category":    taxonomy.Category,
+		"updated_at":  time.Now(),
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Video{}).Where("id = ?", videoID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update video: %w", err)
+		}
+
+		tags, err := findOrCreateTags(tx, taxonomy.Tags)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Video{ID: videoID}).Association("Tags").Replace(tags); err != nil {
+			return fmt.Errorf("failed to update video tags: %w", err)
+		}
+		return nil
+	})
+}
+
+// findOrCreateTags returns the tag records for names, creating missing ones
+func findOrCreateTags(tx *gorm.DB, names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tag := Tag{Name: name}
+		if err := tx.Where(Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return nil, fmt.Errorf("failed to save tag %q: %w", name, err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// SetChecksum records the checksum of a video's original
+func (r *GormVideoRepository) SetChecksum(ctx context.Context, videoID uuid.UUID, checksum string) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Update("checksum", checksum).Error; err != nil {
+		return fmt.Errorf("failed to record checksum: %w", err)
+	}
+	return nil
+}
+
+// FindDuplicate looks up an earlier completed upload of the same file by the same owner
+func (r *GormVideoRepository) FindDuplicate(ctx context.Context, videoID uuid.UUID, checksum string) (*Video, error) {
+	var existing Video
+	err := r.db.WithContext(ctx).
+		Joins("JOIN video_uploads ON video_uploads.video_id = videos.id").
+		Where("videos.user_id = (?)", r.db.Model(&Video{}).Select("user_id").Where("id = ?", videoID)).
+		Where("videos.checksum = ? AND videos.id <> ?", checksum, videoID).
+		Where("video_uploads.status = ?", UploadStatusCompleted).
+		Order("videos.created_at").
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up duplicate uploads: %w", err)
+	}
+	return &existing, nil
+}
+
+// SoftDelete sets a video's deleted_at and deleted_by
+func (r *GormVideoRepository) SoftDelete(ctx context.Context, videoID, deletedBy uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to delete video: %w", err)
+	}
+	return nil
+}
+
+// MarkPurging flags a video as being purged and soft-deletes it
+func (r *GormVideoRepository) MarkPurging(ctx context.Context, videoID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&Video{}).Where("id = ?", videoID).Update("purging", true).Error; err != nil {
+		return fmt.Errorf("failed to delete video: %w", err)
+	}
+	if err := r.db.WithContext(ctx).Delete(&Video{}, videoID).Error; err != nil {
+		return fmt.Errorf("failed to delete video: %w", err)
+	}
+	return nil
+}
+
+// DeleteRecords hard-deletes a video and the records attached to it
+func (r *GormVideoRepository) DeleteRecords(ctx context.Context, videoID uuid.UUID) error {
+	return deleteVideoRecords(r.db.WithContext(ctx), videoID)
+}
+
+// IncrementViews updates the view columns in place
+func (r *GormVideoRepository) IncrementViews(ctx context.Context, videoID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).
+		UpdateColumns(map[string]interface{}{
+			"views":          gorm.Expr("views + 1"),
+			"last_viewed_at": time.Now(),
+		}).Error; err != nil {
+		return fmt.Errorf("failed to record view: %w", err)
+	}
+	return nil
+}
+
+// AdjustCommentCount updates the comment count in place, deleted videos included
+func (r *GormVideoRepository) AdjustCommentCount(ctx context.Context, videoID uuid.UUID, delta int) error {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&Video{}).Where("id = ?", videoID).
+		UpdateColumn("comment_count", gorm.Expr("GREATEST(comment_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update comment count: %w", err)
+	}
+	return nil
+}
+
+// PopularTags counts the undeleted videos of each tag
+func (r *GormVideoRepository) PopularTags(ctx context.Context, limit int) ([]TagCount, error) {
+	var counts []TagCount
+	if err := r.db.WithContext(ctx).Table("tags").
+		Select("tags.name AS name, COUNT(*) AS count").
+		Joins("JOIN video_tags ON video_tags.tag_id = tags.id").
+		Joins("JOIN videos ON videos.id = video_tags.video_id").
+		Where("videos.deleted_at IS NULL").
+		Group("tags.name").
+		Order("count DESC, tags.name").
+		Limit(limit).
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count tags: %w", err)
+	}
+	return counts, nil
+}
+
+// SetUploadStatus writes the upload's status and end time
+func (r *GormVideoRepository) SetUploadStatus(ctx context.Context, upload *VideoUpload) error {
+	if err := r.db.WithContext(ctx).Model(upload).Updates(map[string]interface{}{
+		"status":     upload.Status,
+		"end_time":   upload.EndTime,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update upload status: %w", err)
+	}
+	return nil
+}
+
+// SetStoredBytes writes an upload's progress
+func (r *GormVideoRepository) SetStoredBytes(ctx context.Context, uploadID uuid.UUID, stored int64) error {
+	if err := r.db.WithContext(ctx).Model(&VideoUpload{}).Where("id = ?", uploadID).Update("stored_bytes", stored).Error; err != nil {
+		return fmt.Errorf("failed to record upload progress: %w", err)
+	}
+	return nil
+}
+
+// SetStage writes the stage an upload has reached and its stage times
+func (r *GormVideoRepository) SetStage(ctx context.Context, uploadID uuid.UUID, stage UploadStage, times StageTimes) error {
+	if err := r.db.WithContext(ctx).Model(&VideoUpload{}).Where("id = ?", uploadID).Updates(map[string]interface{}{
+		"stage":       stage,
+		"stage_times": times,
+		"updated_at":  time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record upload stage: %w", err)
+	}
+	return nil
+}
+
+// SetRenditions writes the status and timing of each rendition of an upload
+func (r *GormVideoRepository) SetRenditions(ctx context.Context, uploadID uuid.UUID, statuses RenditionStatuses, times RenditionTimes) error {
+	if err := r.db.WithContext(ctx).Model(&VideoUpload{}).Where("id = ?", uploadID).Updates(map[string]interface{}{
+		"renditions":      statuses,
+		"rendition_times": times,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record rendition status: %w", err)
+	}
+	return nil
+}
+
+// SetRenditionProgress writes how much of each rendition of an upload has been encoded
+func (r *GormVideoRepository) SetRenditionProgress(ctx context.Context, uploadID uuid.UUID, progress RenditionProgress) error {
+	if err := r.db.WithContext(ctx).Model(&VideoUpload{}).Where("id = ?", uploadID).Update("rendition_progress", progress).Error; err != nil {
+		return fmt.Errorf("failed to record rendition progress: %w", err)
+	}
+	return nil
+}
+
+// DeleteTranscodes hard-deletes a video's transcodes and their segments
+func (r *GormVideoRepository) DeleteTranscodes(ctx context.Context, videoID uuid.UUID) error {
+	return deleteTranscodeRecords(r.db.WithContext(ctx), videoID)
+}
+
+// CreateTranscode inserts a transcode, then its segments
+func (r *GormVideoRepository) CreateTranscode(ctx context.Context, transcode *Transcode) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Omit(clause.Associations).Create(transcode).Error; err != nil {
+		return fmt.Errorf("failed to create transcode record: %w", err)
+	}
+	for i := range transcode.Segments {
+		segment := &transcode.Segments[i]
+		segment.TranscodeID = transcode.ID
+		if err := db.Omit(clause.Associations).Create(segment).Error; err != nil {
+			return fmt.Errorf("failed to create segment record: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetOutputs writes the transcoding outputs and clears renditions_dropped_at,
+// since renditions dropped by storage tiering are back
+func (r *GormVideoRepository) SetOutputs(ctx context.Context, videoID uuid.UUID, outputs RenditionOutputs) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"audio_path":            outputs.AudioPath,
+		"preview_path":          outputs.PreviewPath,
+		"duration":              outputs.Duration,
+		"renditions_dropped_at": nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record renditions and duration: %w", err)
+	}
+	return nil
+}
+
+// CompleteUpload writes the outcome of processing an upload
+func (r *GormVideoRepository) CompleteUpload(ctx context.Context, upload *VideoUpload) error {
+	if err := r.db.WithContext(ctx).Model(upload).Updates(map[string]interface{}{
+		"status":             upload.Status,
+		"transcode_failures": upload.TranscodeFailures,
+		"renditions":         upload.Renditions,
+		"rendition_times":    upload.RenditionTimes,
+		"rendition_progress": upload.RenditionProgress,
+		"stage":              upload.Stage,
+		"stage_times":        upload.StageTimes,
+		"end_time":           upload.EndTime,
+		"updated_at":         time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to complete upload: %w", err)
+	}
+	return nil
+}
+
+// GetFiles reads a video unscoped, with the records of its stored files
+func (r *GormVideoRepository) GetFiles(ctx context.Context, videoID uuid.UUID) (*Video, error) {
+	var video Video
+	if err := r.db.WithContext(ctx).Unscoped().Preload("Transcodes.Segments").Preload("Captions").
+		First(&video, "id = ?", videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+	return &video, nil
+}
+
+// SetChapters writes a video's chapters
+func (r *GormVideoRepository) SetChapters(ctx context.Context, videoID uuid.UUID, chapters ChapterList) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"chapters":   chapters,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update chapters: %w", err)
+	}
+	return nil
+}
+
+// SetCommentsPolicy writes a video's comments policy
+func (r *GormVideoRepository) SetCommentsPolicy(ctx context.Context, videoID uuid.UUID, policy CommentsPolicy) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"comments_policy": policy,
+		"updated_at":      time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update comments policy: %w", err)
+	}
+	return nil
+}
+
+// SetEmbeddable writes whether a video can be embedded
+func (r *GormVideoRepository) SetEmbeddable(ctx context.Context, videoID uuid.UUID, embeddable bool) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"embeddable": embeddable,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update embedding: %w", err)
+	}
+	return nil
+}
+
+// SetCountries writes a video's country restrictions
+func (r *GormVideoRepository) SetCountries(ctx context.Context, videoID uuid.UUID, allowed, blocked CountryList) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"allowed_countries": allowed,
+		"blocked_countries": blocked,
+		"updated_at":        time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update country restrictions: %w", err)
+	}
+	return nil
+}
+
+// SetScanResult writes a scan result, its findings one per line
+func (r *GormVideoRepository) SetScanResult(ctx context.Context, videoID uuid.UUID, status ScanStatus, scanner string, findings []string) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"scan_status":   status,
+		"scanner":       scanner,
+		"scan_findings": strings.Join(findings, "\n"),
+		"scanned_at":    time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to record scan result: %w", err)
+	}
+	return nil
+}
+
+// ListScanResults reads a page of videos by scan status
+func (r *GormVideoRepository) ListScanResults(ctx context.Context, statuses []ScanStatus, page, limit int) ([]Video, error) {
+	var videos []Video
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("scan_status IN ?", statuses).
+		Order("scanned_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to list scan results: %w", err)
+	}
+	return videos, nil
+}
+
+// SetScanStatus updates the scan status only when it is one of from
+func (r *GormVideoRepository) SetScanStatus(ctx context.Context, videoID uuid.UUID, status ScanStatus, from []ScanStatus) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&Video{}).
+		Where("id = ? AND scan_status IN ?", videoID, from).
+		Update("scan_status", status)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update scan status: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TakeDown writes a takedown, keeping taken_down_at when already set
+func (r *GormVideoRepository) TakeDown(ctx context.Context, videoID uuid.UUID, reason string) error {
+	result := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"taken_down_at":   gorm.Expr("COALESCE(taken_down_at, ?)", time.Now()),
+		"takedown_reason": reason,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to take down video: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+	}
+	return nil
+}
+
+// LiftTakedown clears taken_down_at and the reason
+func (r *GormVideoRepository) LiftTakedown(ctx context.Context, videoID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"taken_down_at":   nil,
+		"takedown_reason": "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to restore video: %w", err)
+	}
+	return nil
+}
+
+// ResetUpload matches the status and version read by the caller, so two
+// replacements of the same video never both go ahead
+func (r *GormVideoRepository) ResetUpload(ctx context.Context, upload *VideoUpload, version int, startTime time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&VideoUpload{}).
+		Where("id = ? AND status = ? AND version = ?", upload.ID, upload.Status, upload.Version).
+		Updates(map[string]interface{}{
+			"status":             UploadStatusPending,
+			"version":            version,
+			"start_time":         startTime,
+			"end_time":           nil,
+			"stored_bytes":       0,
+			"transcode_failures": nil,
+			"renditions":         nil,
+			"updated_at":         startTime,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to reset upload: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateVersion inserts a version record
+func (r *GormVideoRepository) CreateVersion(ctx context.Context, version *VideoVersion) error {
+	if err := r.db.WithContext(ctx).Create(version).Error; err != nil {
+		return fmt.Errorf("failed to record version: %w", err)
+	}
+	return nil
+}
+
+// SetReplacedFile resets the columns describing a video's original file
+func (r *GormVideoRepository) SetReplacedFile(ctx context.Context, videoID uuid.UUID, size int64) error {
+	if err := r.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"file_size":          size,
+		"ipfs_cid":           "",
+		"replication_status": ReplicationS3Only,
+		"storage_class":      "",
+		"updated_at":         time.Now(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update video: %w", err)
+	}
+	return nil
+}
+
+// ListVersions reads a video's versions, newest first
+func (r *GormVideoRepository) ListVersions(ctx context.Context, videoID uuid.UUID) ([]VideoVersion, error) {
+	var versions []VideoVersion
+	if err := r.db.WithContext(ctx).Where("video_id = ?", videoID).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	return versions, nil
+}
+
+// trashed narrows db to the deleted videos that can still be restored
+func trashed(db *gorm.DB, deletedSince time.Time) *gorm.DB {
+	query := db.Unscoped().Model(&Video{}).Where("deleted_at IS NOT NULL AND NOT purging")
+	if !deletedSince.IsZero() {
+		query = query.Where("deleted_at >= ?", deletedSince)
+	}
+	return query
+}
+
+// ListTrashed reads a page of the user's restorable videos
+func (r *GormVideoRepository) ListTrashed(ctx context.Context, userID uuid.UUID, deletedSince time.Time, page, limit int) ([]Video, int64, error) {
+	query := trashed(r.db.WithContext(ctx), deletedSince).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted videos: %w", err)
+	}
+
+	var videos []Video
+	if err := query.Order("deleted_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&videos).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list deleted videos: %w", err)
+	}
+	return videos, total, nil
+}
+
+// GetTrashed reads a deleted video, purging or not
+func (r *GormVideoRepository) GetTrashed(ctx context.Context, videoID uuid.UUID) (*Video, error) {
+	var video Video
+	err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&video, "id = ?", videoID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted video: %w", err)
+	}
+	return &video, nil
+}
+
+// Undelete clears deleted_at and deleted_by of a restorable video
+func (r *GormVideoRepository) Undelete(ctx context.Context, videoID uuid.UUID, deletedSince time.Time) (bool, error) {
+	result := trashed(r.db.WithContext(ctx), deletedSince).Where("id = ?", videoID).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"deleted_by": nil,
+		"updated_at": time.Now(),
+	})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to restore video: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SaveCaption upserts the track by video and language in one transaction
+// with the video's updated_at, since captions are part of the video's
+// details and their ETag changes
+func (r *GormVideoRepository) SaveCaption(ctx context.Context, caption *VideoCaption) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		record := VideoCaption{}
+		if err := tx.Where(VideoCaption{VideoID: caption.VideoID, Language: caption.Language}).
+			Assign(map[string]interface{}{
+				"label":          caption.Label,
+				"storage_path":   caption.StoragePath,
+				"burned_in_path": caption.BurnedInPath,
+				"updated_at":     now,
+			}).
+			FirstOrCreate(&record).Error; err != nil {
+			return fmt.Errorf("failed to save caption track: %w", err)
+		}
+		if err := tx.Model(&Video{}).Where("id = ?", caption.VideoID).Update("updated_at", now).Error; err != nil {
+			return fmt.Errorf("failed to update video: %w", err)
+		}
+		*caption = record
+		return nil
+	})
+}
+
+// SharedTags counts the tags other videos share with the video
+func (r *GormVideoRepository) SharedTags(ctx context.Context, videoID uuid.UUID, limit int) ([]TagOverlap, error) {
+	var shared []TagOverlap
+	if err := r.db.WithContext(ctx).Table("video_tags AS other").
+		Select("other.video_id, COUNT(*) AS shared").
+		Joins("JOIN video_tags AS this ON this.tag_id = other.tag_id").
+		Where("this.video_id = ? AND other.video_id <> ?", videoID, videoID).
+		Group("other.video_id").
+		Order("shared DESC").
+		Limit(limit).
+		Find(&shared).Error; err != nil {
+		return nil, fmt.Errorf("failed to find videos sharing tags: %w", err)
+	}
+	return shared, nil
+}
+
+// ChannelVideos plucks the IDs of the user's most viewed videos
+func (r *GormVideoRepository) ChannelVideos(ctx context.Context, userID, exclude uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&Video{}).
+		Where("user_id = ? AND id <> ?", userID, exclude).
+		Order("views DESC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to find videos of the channel: %w", err)
+	}
+	return ids, nil
+}
+
+// ListListed reads the listed videos among ids
+func (r *GormVideoRepository) ListListed(ctx context.Context, ids []uuid.UUID) ([]Video, error) {
+	var videos []Video
+	if err := listedVideos(r.db.WithContext(ctx)).Where("videos.id IN ?", ids).
+		Preload("Upload").Preload("Transcodes").Preload("Transcodes.Segments").Preload("Tags").
+		Find(&videos).Error; err != nil {
+		return nil, fmt.Errorf("failed to load videos: %w", err)
+	}
+	return videos, nil
+}
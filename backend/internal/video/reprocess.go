@@ -0,0 +1,116 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+)
+
+// ErrReprocessInProgress is returned when reprocessing a video whose upload is still being processed
+var ErrReprocessInProgress = apierror.New(apierror.CodeConflict, "the video's upload is still being processed")
+
+// ReprocessVideo transcodes a video's stored original again, replacing its
+// renditions, e.g. after the FFmpeg profiles changed or when transcoding
+// failed. The video stays playable from its current renditions meanwhile,
+// and keeps them when reprocessing fails.
+func (s *VideoServiceImpl) ReprocessVideo(ctx context.Context, videoID uuid.UUID) error {
+	video, err := s.repo.GetByID(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	upload := video.Upload
+	if upload == nil {
+		return fmt.Errorf("video %s has no upload record", videoID)
+	}
+	if upload.Status == UploadStatusPending || upload.Status == UploadStatusUploading {
+		return ErrReprocessInProgress
+	}
+	source, ok := s.storage.(ReplicationSource)
+	if !ok {
+		return fmt.Errorf("video storage cannot read back stored files")
+	}
+
+	if s.jobs != nil {
+		jobCtx, done, err := s.jobs.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer done()
+		ctx = jobCtx
+	}
+
+	tempDir, err := s.tempManager.CreateTempDir()
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer s.tempManager.CleanupDir(tempDir)
+
+	originalPath := filepath.Join(tempDir, "original.mp4")
+	if err := downloadOriginal(ctx, source, video.StoragePath, originalPath); err != nil {
+		return err
+	}
+	metadata, err := s.probeUpload(ctx, originalPath)
+	if err != nil {
+		return err
+	}
+
+	s.logger.LogInfo("Reprocessing video", map[string]interface{}{
+		"video_id": videoID,
+		"duration": metadata.Duration,
+	})
+
+	// The original is already replicated; only the new renditions are queued
+	previous := upload.Status
+	previousCIDs := videoCIDs(&Video{Transcodes: video.Transcodes})
+	if err := s.transcode(ctx, upload, tempDir, originalPath, metadata, nil); err != nil {
+		// A failed upload is purged by the cleanup worker, so the upload
+		// goes back to how it was before
+		s.setUploadStatus(ctx, upload, previous)
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	s.purgeCDN(ctx, videoID)
+
+	// The previous renditions' pins are released now their records are gone
+	if s.purger != nil && s.purger.ipfs != nil {
+		for _, cid := range previousCIDs {
+			if err := s.purger.ipfs.Unpin(ctx, cid); err != nil {
+				s.logger.LogError("Failed to unpin replaced rendition", map[string]interface{}{
+					"error":    err.Error(),
+					"video_id": videoID,
+					"cid":      cid,
+				})
+			}
+		}
+	}
+
+	s.logger.LogInfo("Video reprocessed", map[string]interface{}{
+		"video_id":           videoID,
+		"transcode_failures": upload.TranscodeFailures,
+	})
+	return nil
+}
+
+// downloadOriginal copies the stored file at key to path
+func downloadOriginal(ctx context.Context, source ReplicationSource, key, path string) error {
+	reader, err := source.DownloadVideo(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to download original: %w", err)
+	}
+	defer reader.Close()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("failed to download original: %w", err)
+	}
+	return file.Close()
+}
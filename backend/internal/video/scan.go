@@ -0,0 +1,201 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/scan"
+	"github.com/google/uuid"
+)
+
+// ScanStatus is the outcome of scanning an upload, stored on its video
+type ScanStatus string
+
+const (
+	// ScanNotScanned is the status of videos uploaded while scanning was disabled
+	ScanNotScanned ScanStatus = ""
+	ScanClean      ScanStatus = "clean"
+	// ScanFlagged videos were published; admins review them
+	ScanFlagged ScanStatus = "flagged"
+	// ScanQuarantined videos are hidden from everyone but their owner and
+	// staff until an admin releases them
+	ScanQuarantined ScanStatus = "quarantined"
+	// ScanBlocked uploads were rejected before anything was stored
+	ScanBlocked ScanStatus = "blocked"
+	// ScanError uploads could not be scanned and were accepted because the
+	// scanner fails open
+	ScanError ScanStatus = "error"
+	// ScanReleased videos were flagged or quarantined and cleared by an admin
+	ScanReleased ScanStatus = "released"
+)
+
+// IsValid reports whether s is a status a video can have
+func (s ScanStatus) IsValid() bool {
+	switch s {
+	case ScanNotScanned, ScanClean, ScanFlagged, ScanQuarantined, ScanBlocked, ScanError, ScanReleased:
+		return true
+	}
+	return false
+}
+
+// Hidden reports whether videos with this status are kept out of listings
+// and from viewers other than their owner and staff
+func (s ScanStatus) Hidden() bool {
+	return s == ScanQuarantined || s == ScanBlocked
+}
+
+// reviewStatuses are the scan results admins need to look at
+var reviewStatuses = []ScanStatus{ScanFlagged, ScanQuarantined, ScanBlocked, ScanError}
+
+// releasableStatuses are the scan results an admin can clear
+var releasableStatuses = []ScanStatus{ScanFlagged, ScanQuarantined, ScanError}
+
+// ScanAction is what happens to uploads the scanner flags
+type ScanAction string
+
+const (
+	// ScanActionBlock rejects the upload with ERR_CONTENT_REJECTED
+	ScanActionBlock ScanAction = "block"
+	// ScanActionQuarantine processes the upload but hides the video
+	ScanActionQuarantine ScanAction = "quarantine"
+	// ScanActionFlag publishes the video and records the findings for admins
+	ScanActionFlag ScanAction = "flag"
+)
+
+// IsValid reports whether a is a known action
+func (a ScanAction) IsValid() bool {
+	return a == ScanActionBlock || a == ScanActionQuarantine || a == ScanActionFlag
+}
+
+var (
+	// ErrContentRejected is returned for uploads the scanner flags when the action is block
+	ErrContentRejected = apierror.New(apierror.CodeContentRejected, "upload rejected by content scan")
+	// ErrNotReleasable is returned when releasing a video that is not flagged, quarantined or unscanned after an error
+	ErrNotReleasable = apierror.New(apierror.CodeConflict, "video has no scan result to release")
+)
+
+// ContentScanner is the scanning stage of the upload pipeline: the scanner
+// and what to do with the files it flags
+type ContentScanner struct {
+	Scanner scan.Scanner
+	Action  ScanAction
+	// FailOpen accepts uploads the scanner could not check, recording the
+	// error for admins, instead of failing them
+	FailOpen bool
+}
+
+// ScanReport is a video's scan result as shown to admins
+type ScanReport struct {
+	VideoID   string     `json:"video_id" example:"123e4567-e89b-12d3-a456-426614174000"`
+	UserID    string     `json:"user_id" example:"123e4567-e89b-12d3-a456-426614174001"`
+	Title     string     `json:"title" example:"My upload"`
+	Status    ScanStatus `json:"status" example:"quarantined"`
+	Scanner   string     `json:"scanner,omitempty" example:"clamav"`
+	Findings  []string   `json:"findings,omitempty" example:"Eicar-Test-Signature"`
+	ScannedAt *time.Time `json:"scanned_at,omitempty"`
+}
+
+// ScanListResponse is a page of scan results
+type ScanListResponse struct {
+	Results []ScanReport `json:"results"`
+	Page    int          `json:"page"`
+	Limit   int          `json:"limit"`
+}
+
+// ToScanReport converts a video's scan columns to a ScanReport
+func (v *Video) ToScanReport() ScanReport {
+	report := ScanReport{
+		VideoID:   v.ID.String(),
+		UserID:    v.UserID.String(),
+		Title:     v.Title,
+		Status:    v.ScanStatus,
+		Scanner:   v.Scanner,
+		ScannedAt: v.ScannedAt,
+	}
+	if v.ScanFindings != "" {
+		report.Findings = strings.Split(v.ScanFindings, "\n")
+	}
+	return report
+}
+
+// scanUpload runs the content scanner over a saved upload and records the
+// result on the video. Flagged uploads are rejected with ErrContentRejected
+// when the action is block.
+func (s *VideoServiceImpl) scanUpload(ctx context.Context, upload *VideoUpload, path string) error {
+	if s.scanner == nil {
+		return nil
+	}
+
+	status := ScanClean
+	var findings []string
+	result, err := s.scanner.Scanner.Scan(ctx, path)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return fmt.Errorf("upload scan cancelled: %w", ctx.Err())
+	case err != nil && !s.scanner.FailOpen:
+		return fmt.Errorf("failed to scan upload: %w", err)
+	case err != nil:
+		s.logger.LogError("Content scan failed, accepting upload", map[string]interface{}{
+			"video_id": upload.VideoID,
+			"error":    err.Error(),
+		})
+		status, findings = ScanError, []string{err.Error()}
+	case result.Flagged:
+		findings = result.Findings
+		switch s.scanner.Action {
+		case ScanActionBlock:
+			status = ScanBlocked
+		case ScanActionQuarantine:
+			status = ScanQuarantined
+		default:
+			status = ScanFlagged
+		}
+		s.logger.LogInfo("Content scan flagged upload", map[string]interface{}{
+			"video_id": upload.VideoID,
+			"status":   status,
+			"findings": findings,
+		})
+	}
+
+	if err := s.repo.SetScanResult(ctx, upload.VideoID, status, s.scanner.Scanner.Name(), findings); err != nil {
+		return err
+	}
+
+	if status == ScanBlocked {
+		return fmt.Errorf("%w: %s", ErrContentRejected, strings.Join(findings, ", "))
+	}
+	return nil
+}
+
+// ListScanResults returns videos whose scan needs review, newest first:
+// those with the given status, or every flagged, quarantined, blocked or
+// failed scan when status is empty
+func (s *VideoServiceImpl) ListScanResults(ctx context.Context, status ScanStatus, page, limit int) ([]Video, error) {
+	statuses := reviewStatuses
+	if status != ScanNotScanned {
+		statuses = []ScanStatus{status}
+	}
+	return s.repo.ListScanResults(ctx, statuses, page, limit)
+}
+
+// ReleaseVideo clears a flagged, quarantined or failed scan, publishing the
+// video. Blocked uploads have nothing stored and cannot be released.
+func (s *VideoServiceImpl) ReleaseVideo(ctx context.Context, videoID uuid.UUID) error {
+	video, err := s.repo.GetByID(ctx, videoID)
+	if err != nil {
+		return err
+	}
+
+	released, err := s.repo.SetScanStatus(ctx, videoID, ScanReleased, releasableStatuses)
+	if err != nil {
+		return fmt.Errorf("failed to release video: %w", err)
+	}
+	if !released {
+		return fmt.Errorf("%w: scan status is %q", ErrNotReleasable, video.ScanStatus)
+	}
+	s.cache.Invalidate(ctx, videoID)
+	return nil
+}
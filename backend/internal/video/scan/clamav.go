@@ -0,0 +1,118 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// clamChunkSize is how much of the file is sent in each INSTREAM chunk.
+// clamd's StreamMaxLength limits the total.
+const clamChunkSize = 64 << 10
+
+// ClamAV scans files with a clamd daemon over TCP, streaming them with the
+// INSTREAM command so clamd does not need access to the upload directory
+type ClamAV struct {
+	address string
+	timeout time.Duration
+	dialer  net.Dialer
+}
+
+// NewClamAV creates a scanner for the clamd listening on address, e.g.
+// clamav:3310. Each scan is stopped after timeout unless that is 0.
+func NewClamAV(address string, timeout time.Duration) *ClamAV {
+	return &ClamAV{address: address, timeout: timeout}
+}
+
+// Name implements Scanner
+func (c *ClamAV) Name() string {
+	return ProviderClamAV
+}
+
+// Scan implements Scanner
+func (c *ClamAV) Scan(ctx context.Context, path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	// Closing the connection unblocks reads and writes once ctx is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	reply, err := c.instream(conn, file)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("clamd scan stopped: %w", ctx.Err())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseClamReply(reply)
+}
+
+// instream sends the file in length-prefixed chunks, ends the stream with a
+// zero-length chunk and reads clamd's null-terminated reply
+func (c *ClamAV) instream(conn net.Conn, file io.Reader) (string, error) {
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return "", fmt.Errorf("failed to send command to clamd: %w", err)
+	}
+
+	buf := make([]byte, 4+clamChunkSize)
+	for {
+		n, err := file.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return "", fmt.Errorf("failed to send file to clamd: %w", err)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return "", fmt.Errorf("failed to send file to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return strings.TrimRight(reply, "\x00"), nil
+}
+
+// parseClamReply parses replies such as "stream: OK",
+// "stream: Eicar-Signature FOUND" and
+// "INSTREAM size limit exceeded. ERROR"
+func parseClamReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(reply)
+	switch {
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return &Result{Flagged: true, Findings: []string{signature}}, nil
+	case strings.HasSuffix(reply, ": OK"):
+		return &Result{}, nil
+	default:
+		return nil, fmt.Errorf("clamd: %s", reply)
+	}
+}
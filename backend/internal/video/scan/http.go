@@ -0,0 +1,80 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTP sends files to an external moderation API, such as an NSFW
+// classifier. The file is POSTed as the request body and the API answers
+// 200 with a JSON verdict:
+//
+//	{"flagged": true, "labels": ["nudity"]}
+type HTTP struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// httpVerdict is the moderation API's answer
+type httpVerdict struct {
+	Flagged bool     `json:"flagged"`
+	Labels  []string `json:"labels"`
+}
+
+// NewHTTP creates a scanner that posts files to url, authenticating with
+// token as a bearer token when it is set. Each scan is stopped after
+// timeout unless that is 0.
+func NewHTTP(url, token string, timeout time.Duration) *HTTP {
+	return &HTTP{url: url, token: token, client: &http.Client{Timeout: timeout}}
+}
+
+// Name implements Scanner
+func (h *HTTP) Name() string {
+	return ProviderHTTP
+}
+
+// Scan implements Scanner
+func (h *HTTP) Scan(ctx context.Context, path string) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("moderation API returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var verdict httpVerdict
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&verdict); err != nil {
+		return nil, fmt.Errorf("failed to decode moderation verdict: %w", err)
+	}
+	return &Result{Flagged: verdict.Flagged, Findings: verdict.Labels}, nil
+}
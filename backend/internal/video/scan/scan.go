@@ -0,0 +1,29 @@
+// Package scan checks uploaded files for malware or unwanted content before
+// they are stored. Scanners are pluggable; the video service decides what
+// happens to the files they flag.
+package scan
+
+import "context"
+
+// Scanner providers, as named in the configuration
+const (
+	ProviderClamAV = "clamav"
+	ProviderHTTP   = "http"
+)
+
+// Result is a scanner's verdict on one file
+type Result struct {
+	// Flagged is set when the scanner found something
+	Flagged bool
+	// Findings names what was found, such as virus signatures or moderation labels
+	Findings []string
+}
+
+// Scanner checks files
+type Scanner interface {
+	// Name identifies the scanner in stored scan results
+	Name() string
+	// Scan checks the file at path. An error means the file could not be
+	// checked, not that it was flagged.
+	Scan(ctx context.Context, path string) (*Result, error)
+}
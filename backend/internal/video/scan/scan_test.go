@@ -0,0 +1,133 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eicar is the standard antivirus test file
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "original.mp4")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// fakeClamd accepts INSTREAM scans and reports files containing the EICAR
+// string, the way clamd does
+func fakeClamd(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				if command, err := reader.ReadString(0); err != nil || command != "zINSTREAM\x00" {
+					io.WriteString(conn, "UNKNOWN COMMAND\x00")
+					return
+				}
+				var stream bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&stream, reader, int64(size)); err != nil {
+						return
+					}
+				}
+				if bytes.Contains(stream.Bytes(), []byte(eicar)) {
+					io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+					return
+				}
+				io.WriteString(conn, "stream: OK\x00")
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestClamAV(t *testing.T) {
+	scanner := NewClamAV(fakeClamd(t), 5*time.Second)
+
+	result, err := scanner.Scan(context.Background(), writeFile(t, "not a virus"))
+	require.NoError(t, err)
+	assert.False(t, result.Flagged)
+
+	// Larger than one chunk, so the signature spans the stream
+	infected := string(bytes.Repeat([]byte{0}, clamChunkSize-10)) + eicar
+	result, err = scanner.Scan(context.Background(), writeFile(t, infected))
+	require.NoError(t, err)
+	assert.True(t, result.Flagged)
+	assert.Equal(t, []string{"Eicar-Test-Signature"}, result.Findings)
+}
+
+func TestClamAV_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = NewClamAV(address, time.Second).Scan(context.Background(), writeFile(t, "clip"))
+	assert.ErrorContains(t, err, "failed to connect to clamd")
+}
+
+func TestParseClamReply(t *testing.T) {
+	_, err := parseClamReply("INSTREAM size limit exceeded. ERROR")
+	assert.ErrorContains(t, err, "size limit exceeded")
+}
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte("explicit")) {
+			io.WriteString(w, `{"flagged": true, "labels": ["nudity", "violence"]}`)
+			return
+		}
+		io.WriteString(w, `{"flagged": false}`)
+	}))
+	defer server.Close()
+
+	scanner := NewHTTP(server.URL, "secret", 5*time.Second)
+	result, err := scanner.Scan(context.Background(), writeFile(t, "holiday clip"))
+	require.NoError(t, err)
+	assert.False(t, result.Flagged)
+
+	result, err = scanner.Scan(context.Background(), writeFile(t, "explicit clip"))
+	require.NoError(t, err)
+	assert.True(t, result.Flagged)
+	assert.Equal(t, []string{"nudity", "violence"}, result.Findings)
+
+	_, err = NewHTTP(server.URL, "wrong", 5*time.Second).Scan(context.Background(), writeFile(t, "clip"))
+	assert.ErrorContains(t, err, "401")
+}
@@ -2,62 +2,101 @@ package video
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/tracing"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/tempfile"
 	"github.com/google/uuid"
-	"gorm.io/gorm"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// transcodeLadder lists the renditions every upload is transcoded to, highest first
+var transcodeLadder = []string{"720p", "480p", "360p"}
+
 // VideoServiceImpl implements the VideoService interface
 type VideoServiceImpl struct {
-	db          *gorm.DB
-	ipfs        IPFSService
+	repo        VideoRepository
+	replicator  Replicator
 	storage     videostorage.Service
 	ffmpeg      *ffmpeg.Service
+	limits      LimitsConfig
+	scanner     *ContentScanner
 	tempManager tempfile.TempFileManager
+	jobs        *JobTracker
+	scheduler   *TranscodeScheduler
+	purger      *Purger
+	cache       *VideoCache
+	cdn         CDNPurger
+	events      *UploadEvents
 	logger      Logger
 }
 
-// NewVideoService creates a new video service instance
+// NewVideoService creates a new video service instance. Videos and
+// everything stored with them are read and written through repo. cdn may be
+// nil when files are not served through a CDN, and events when upload
+// progress is not streamed.
 func NewVideoService(
-	db *gorm.DB,
-	ipfs IPFSService,
+	repo VideoRepository,
+	replicator Replicator,
 	storage videostorage.Service,
 	ffmpeg *ffmpeg.Service,
+	limits LimitsConfig,
+	scanner *ContentScanner,
 	tempManager tempfile.TempFileManager,
+	jobs *JobTracker,
+	scheduler *TranscodeScheduler,
+	purger *Purger,
+	cache *VideoCache,
+	cdn CDNPurger,
+	events *UploadEvents,
 	logger Logger,
 ) VideoService {
 	return &VideoServiceImpl{
-		db:          db,
-		ipfs:        ipfs,
+		repo:        repo,
+		replicator:  replicator,
 		storage:     storage,
 		ffmpeg:      ffmpeg,
+		limits:      limits,
+		scanner:     scanner,
 		tempManager: tempManager,
+		jobs:        jobs,
+		scheduler:   scheduler,
+		purger:      purger,
+		cache:       cache,
+		cdn:         cdn,
+		events:      events,
 		logger:      logger,
 	}
 }
 
-// InitializeUpload creates a new video upload record
-func (s *VideoServiceImpl) InitializeUpload(title, description string, size int64) (*VideoUpload, error) {
+// InitializeUpload creates a new video upload record owned by the given user
+func (s *VideoServiceImpl) InitializeUpload(ctx context.Context, userID uuid.UUID, title, description string, size int64, taxonomy Taxonomy) (*VideoUpload, error) {
 	videoID := uuid.New()
 	fileID := uuid.New().String()
 
 	// Create the video record
 	video := &Video{
 		ID:          videoID,
+		UserID:      userID,
 		FileID:      fileID,
 		Title:       title,
 		Description: description,
+		Category:    taxonomy.Category,
 		StoragePath: fmt.Sprintf("videos/%s/original.mp4", videoID),
 		FileSize:    size,
+		Replication: ReplicationS3Only,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -71,18 +110,7 @@ func (s *VideoServiceImpl) InitializeUpload(title, description string, size int6
 		UpdatedAt: time.Now(),
 	}
 
-	// Start a transaction
-	err := s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(video).Error; err != nil {
-			return fmt.Errorf("failed to create video record: %w", err)
-		}
-		if err := tx.Create(upload).Error; err != nil {
-			return fmt.Errorf("failed to create upload record: %w", err)
-		}
-		return nil
-	})
-
-	if err != nil {
+	if err := s.repo.Create(ctx, video, upload, taxonomy.Tags); err != nil {
 		return nil, err
 	}
 
@@ -90,15 +118,31 @@ func (s *VideoServiceImpl) InitializeUpload(title, description string, size int6
 	return upload, nil
 }
 
-// ProcessUpload handles the video upload process
-func (s *VideoServiceImpl) ProcessUpload(upload *VideoUpload, file multipart.File, header *multipart.FileHeader) error {
-	ctx := context.Background()
+// ProcessUpload handles the video upload process. Cancelling ctx stops
+// transcoding and storage uploads and marks the upload interrupted.
+func (s *VideoServiceImpl) ProcessUpload(ctx context.Context, upload *VideoUpload, file multipart.File, header *multipart.FileHeader) (err error) {
+	ctx, span := tracing.Start(ctx, "video.process_upload",
+		attribute.String("video.id", upload.VideoID.String()),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	// Let shutdown wait for this upload, or cancel it if it takes too long
+	if s.jobs != nil {
+		jobCtx, done, err := s.jobs.Begin(ctx)
+		if err != nil {
+			s.setUploadStatus(ctx, upload, UploadStatusInterrupted)
+			return err
+		}
+		defer done()
+		ctx = jobCtx
+	}
 
 	// Update status to uploading
 	upload.Status = UploadStatusUploading
-	if err := s.db.Model(upload).Update("status", UploadStatusUploading).Error; err != nil {
-		return fmt.Errorf("failed to update upload status: %w", err)
+	if err := s.repo.SetUploadStatus(ctx, upload); err != nil {
+		return err
 	}
+	s.enterStage(ctx, upload, UploadStageReceiving)
 
 	// Create temporary directory for processing
 	tempDir, err := s.tempManager.CreateTempDir()
@@ -119,18 +163,64 @@ func (s *VideoServiceImpl) ProcessUpload(upload *VideoUpload, file multipart.Fil
 		return fmt.Errorf("failed to seek file: %w", err)
 	}
 
-	if _, err := io.Copy(tempFile, file); err != nil {
+	// Hash the file while it is saved, for deduplication
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tempFile, hash), file); err != nil {
 		return fmt.Errorf("failed to save temp file: %w", err)
 	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+	s.enterStage(ctx, upload, UploadStageValidating)
+
+	// A file the user has already uploaded is not stored or transcoded
+	// again. A replacement is never discarded: the video it replaces has
+	// comments and history of its own.
+	if upload.Version <= 1 {
+		duplicate, err := s.repo.FindDuplicate(ctx, upload.VideoID, checksum)
+		if err != nil {
+			s.failUpload(ctx, upload)
+			return err
+		}
+		if duplicate != nil {
+			return s.discardDuplicate(ctx, upload, duplicate)
+		}
+	}
+	if err := s.repo.SetChecksum(ctx, upload.VideoID, checksum); err != nil {
+		s.failUpload(ctx, upload)
+		return err
+	}
 
-	// Get video metadata
-	metadata, err := s.ffmpeg.GetMetadata(ctx, originalPath)
+	// Probe the file before anything is stored, so files that are not a
+	// video the pipeline accepts are rejected with the reason
+	metadata, err := s.probeUpload(ctx, originalPath)
 	if err != nil {
-		s.logger.LogError("Failed to get video metadata", map[string]interface{}{
-			"error": err.Error(),
-			"path":  originalPath,
-		})
-		return fmt.Errorf("failed to get video metadata: %w", err)
+		if errors.Is(err, ErrInvalidMedia) {
+			s.logger.LogInfo("Rejected invalid media", map[string]interface{}{
+				"reason":   err.Error(),
+				"video_id": upload.VideoID,
+			})
+		} else {
+			s.logger.LogError("Failed to get video metadata", map[string]interface{}{
+				"error": err.Error(),
+				"path":  originalPath,
+			})
+		}
+		s.failUpload(ctx, upload)
+		return err
+	}
+
+	// Scan for malware or unwanted content, also before anything is stored
+	if s.scanner != nil {
+		s.enterStage(ctx, upload, UploadStageScanning)
+	}
+	if err := s.scanUpload(ctx, upload, originalPath); err != nil {
+		if !errors.Is(err, ErrContentRejected) {
+			s.logger.LogError("Failed to scan upload", map[string]interface{}{
+				"error":    err.Error(),
+				"video_id": upload.VideoID,
+			})
+		}
+		s.failUpload(ctx, upload)
+		return err
 	}
 
 	// Log the video metadata for debugging purposes
@@ -147,131 +237,103 @@ func (s *VideoServiceImpl) ProcessUpload(upload *VideoUpload, file multipart.Fil
 		return fmt.Errorf("failed to seek file: %w", err)
 	}
 
-	_, err = s.storage.UploadVideo(ctx, upload.VideoID, "original", file)
+	s.enterStage(ctx, upload, UploadStageStoringOriginal)
+	progressCtx := videostorage.WithProgress(ctx, s.recordStoredBytes(ctx, upload))
+	originalKey, err := s.storage.UploadVideo(progressCtx, upload.VideoID, "original", file)
 	if err != nil {
-		upload.Status = UploadStatusFailed
-		s.db.Model(upload).Updates(map[string]interface{}{
-			"status":     UploadStatusFailed,
-			"end_time":   time.Now(),
-			"updated_at": time.Now(),
-		})
+		s.failUpload(ctx, upload)
 		return fmt.Errorf("failed to upload to S3: %w", err)
 	}
 
-	// Upload original to IPFS
-	if _, err := file.Seek(0, 0); err != nil {
-		return fmt.Errorf("failed to seek file: %w", err)
-	}
+	// IPFS replication of the original is queued with the renditions once
+	// the records are committed
+	return s.transcode(ctx, upload, tempDir, originalPath, metadata, []ReplicationJob{{VideoID: upload.VideoID, Key: originalKey}})
+}
 
-	cid, err := s.ipfs.UploadFileStream(file)
-	if err != nil {
-		s.logger.LogError("Failed to upload to IPFS", map[string]interface{}{
-			"error": err.Error(),
-			"path":  originalPath,
+// transcode produces and stores every rendition of the original at
+// originalPath, then records them and completes the upload. The renditions
+// are queued for IPFS replication after replicationJobs.
+func (s *VideoServiceImpl) transcode(ctx context.Context, upload *VideoUpload, tempDir, originalPath string, metadata *ffmpeg.VideoMetadata, replicationJobs []ReplicationJob) error {
+	// Transcode every rendition through the shared scheduler, which runs them
+	// alongside other uploads' renditions with short videos first. The
+	// audio-only rendition and the preview follow the video resolutions.
+	planned := s.planRenditions(originalPath, metadata)
+	names := make([]string, len(planned))
+	for i, rendition := range planned {
+		names[i] = rendition.name
+	}
+	s.enterStage(ctx, upload, UploadStageTranscoding)
+	renditions := s.newRenditionTracker(upload)
+	renditions.queue(ctx, names)
+
+	results := make([]renditionResult, len(planned))
+	waits := make([]<-chan error, len(planned))
+	for i, rendition := range planned {
+		waits[i] = s.scheduler.Submit(ctx, TranscodeTask{
+			VideoID:    upload.VideoID,
+			Resolution: rendition.name,
+			Duration:   time.Duration(metadata.Duration * float64(time.Second)),
+			Run: func(ctx context.Context) error {
+				results[i] = s.produceRendition(ctx, renditions, tempDir, rendition)
+				if results[i].failure != "" {
+					return fmt.Errorf("rendition %s failed: %s", rendition.name, results[i].failure)
+				}
+				return nil
+			},
 		})
-		// Continue processing even if IPFS upload fails
 	}
 
-	// Process transcoding for different resolutions
+	// Wait for every rendition, since they share the temp directory
+	var schedulerErr error
+	for _, wait := range waits {
+		if err := <-wait; errors.Is(err, ErrTranscodeSchedulerStopped) {
+			schedulerErr = err
+		}
+	}
+	if schedulerErr != nil {
+		s.setUploadStatus(ctx, upload, UploadStatusInterrupted)
+		return fmt.Errorf("upload processing interrupted: %w", schedulerErr)
+	}
+	if err := ctx.Err(); err != nil {
+		s.failUpload(ctx, upload)
+		return fmt.Errorf("upload processing cancelled: %w", err)
+	}
+
 	transcodeResults := make([]*Transcode, 0)
 	successfulResolutions := make([]string, 0)
 	failedResolutions := make([]string, 0)
+	failures := make(TranscodeFailures)
 
-	// Map to store metadata and CIDs for each resolution
-	resolutionData := make(map[string]struct {
-		duration int
-		ipfsCID  string
-	})
+	// Map to store metadata and S3 keys for each resolution
+	resolutionData := make(map[string]renditionResult)
 
-	for _, resolution := range []string{"720p", "480p", "360p"} {
-		// Create transcode record
-		transcode := &Transcode{
+	for i, resolution := range transcodeLadder {
+		if results[i].failure != "" {
+			failedResolutions = append(failedResolutions, resolution)
+			failures[resolution] = results[i].failure
+			continue
+		}
+		resolutionData[resolution] = results[i]
+		transcodeResults = append(transcodeResults, &Transcode{
 			VideoID:   upload.VideoID,
 			Format:    "mp4",
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
-		}
-
-		// Perform transcoding
-		outputPath := filepath.Join(tempDir, fmt.Sprintf("%s.mp4", resolution))
-		if err := s.ffmpeg.Transcode(ctx, originalPath, outputPath, resolution); err != nil {
-			s.logger.LogError("Failed to transcode video", map[string]interface{}{
-				"error":      err.Error(),
-				"resolution": resolution,
-				"input":      originalPath,
-				"output":     outputPath,
-			})
-			failedResolutions = append(failedResolutions, resolution)
-			continue // Skip this resolution but continue with others
-		}
-
-		// Upload transcoded file to S3
-		transcodedFile, err := os.Open(outputPath)
-		if err != nil {
-			s.logger.LogError("Failed to open transcoded file", map[string]interface{}{
-				"error": err.Error(),
-				"path":  outputPath,
-			})
-			failedResolutions = append(failedResolutions, resolution)
-			continue
-		}
-
-		_, err = s.storage.UploadVideo(ctx, upload.VideoID, resolution, transcodedFile)
-		transcodedFile.Close()
-		if err != nil {
-			s.logger.LogError("Failed to upload transcoded file to S3", map[string]interface{}{
-				"error":      err.Error(),
-				"resolution": resolution,
-			})
-			failedResolutions = append(failedResolutions, resolution)
-			continue
-		}
-
-		// Upload transcoded file to IPFS
-		transcodedFile, err = os.Open(outputPath)
-		if err != nil {
-			s.logger.LogError("Failed to open transcoded file for IPFS", map[string]interface{}{
-				"error": err.Error(),
-				"path":  outputPath,
-			})
-			failedResolutions = append(failedResolutions, resolution)
-			continue
-		}
-
-		transcodedCID, err := s.ipfs.UploadFileStream(transcodedFile)
-		transcodedFile.Close()
-		if err != nil {
-			s.logger.LogError("Failed to upload transcoded file to IPFS", map[string]interface{}{
-				"error":      err.Error(),
-				"resolution": resolution,
-			})
-			// Continue without IPFS CID
-		}
+		})
+		successfulResolutions = append(successfulResolutions, resolution)
+	}
 
-		// Get transcoded file metadata
-		transcodedMetadata, err := s.ffmpeg.GetMetadata(ctx, outputPath)
-		if err != nil {
-			s.logger.LogError("Failed to get transcoded video metadata", map[string]interface{}{
-				"error":      err.Error(),
-				"resolution": resolution,
-				"path":       outputPath,
-			})
-			failedResolutions = append(failedResolutions, resolution)
+	// The audio-only rendition and preview are optional; the upload completes
+	// without them. Missing ones are cleared, since a replaced file may have had them.
+	// The duration is recorded with the renditions; chapters are checked against it.
+	outputs := RenditionOutputs{Duration: metadata.Duration}
+	for i := len(transcodeLadder); i < len(planned); i++ {
+		name := planned[i].name
+		if results[i].failure != "" {
+			failures[name] = results[i].failure
 			continue
 		}
-
-		// Store metadata and CID for this resolution
-		resolutionData[resolution] = struct {
-			duration int
-			ipfsCID  string
-		}{
-			duration: int(transcodedMetadata.Duration),
-			ipfsCID:  transcodedCID,
-		}
-
-		// Add to successful resolutions
-		transcodeResults = append(transcodeResults, transcode)
-		successfulResolutions = append(successfulResolutions, resolution)
+		*outputFields[name](&outputs) = results[i].key
 	}
 
 	// Log summary of transcoding results
@@ -279,6 +341,7 @@ func (s *VideoServiceImpl) ProcessUpload(upload *VideoUpload, file multipart.Fil
 		"video_id":               upload.VideoID,
 		"successful_resolutions": successfulResolutions,
 		"failed_resolutions":     failedResolutions,
+		"failure_reasons":        failures,
 		"total_successful":       len(successfulResolutions),
 		"total_failed":           len(failedResolutions),
 	})
@@ -291,143 +354,505 @@ func (s *VideoServiceImpl) ProcessUpload(upload *VideoUpload, file multipart.Fil
 	}
 
 	// Start a transaction to update all records
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Update video with IPFS CID
-		if err := tx.Model(upload.Video).Updates(map[string]interface{}{
-			"ipfs_cid":   cid,
-			"updated_at": time.Now(),
-		}).Error; err != nil {
-			return fmt.Errorf("failed to update video record: %w", err)
+	s.enterStage(ctx, upload, UploadStageFinalizing)
+	err := s.repo.Transaction(ctx, func(repo VideoRepository) error {
+		// Earlier transcodes, of a replaced file or of a reprocessed one,
+		// give way to the new ones
+		if err := repo.DeleteTranscodes(ctx, upload.VideoID); err != nil {
+			return err
 		}
 
-		// Create transcodes and segments for each resolution
+		// Create transcodes, each with a single segment
 		for i, transcode := range transcodeResults {
-			// First create the transcode record
-			if err := tx.Create(transcode).Error; err != nil {
-				return fmt.Errorf("failed to create transcode record: %w", err)
+			resolution := successfulResolutions[i]
+			data := resolutionData[resolution]
+			transcode.Segments = []TranscodeSegment{{
+				StoragePath: fmt.Sprintf("videos/%s/%s.mp4", upload.VideoID, resolution),
+				Replication: ReplicationS3Only,
+				Checksum:    data.checksum,
+				Duration:    data.duration,
+				CreatedAt:   time.Now(),
+				UpdatedAt:   time.Now(),
+			}}
+			if err := repo.CreateTranscode(ctx, transcode); err != nil {
+				return err
 			}
 
-			// Create a single segment for this transcode
-			if i < len(successfulResolutions) {
-				resolution := successfulResolutions[i]
-				data := resolutionData[resolution]
-
-				segment := &TranscodeSegment{
-					TranscodeID: transcode.ID,
-					StoragePath: fmt.Sprintf("videos/%s/%s.mp4", upload.VideoID, resolution),
-					IPFSCID:     data.ipfsCID,
-					Duration:    data.duration,
-					CreatedAt:   time.Now(),
-					UpdatedAt:   time.Now(),
-				}
+			replicationJobs = append(replicationJobs, ReplicationJob{
+				VideoID:   upload.VideoID,
+				SegmentID: transcode.Segments[0].ID,
+				Key:       data.key,
+			})
+		}
 
-				if err := tx.Create(segment).Error; err != nil {
-					s.logger.LogError("Failed to create segment record", map[string]interface{}{
-						"error":      err.Error(),
-						"resolution": resolution,
-					})
-					return fmt.Errorf("failed to create segment record: %w", err)
-				}
-			}
+		// Renditions dropped by storage tiering are back
+		if err := repo.SetOutputs(ctx, upload.VideoID, outputs); err != nil {
+			return err
 		}
 
 		// Update upload status to completed
-		return tx.Model(upload).Updates(map[string]interface{}{
-			"status":     UploadStatusCompleted,
-			"end_time":   time.Now(),
-			"updated_at": time.Now(),
-		}).Error
+		now := time.Now()
+		upload.Status = UploadStatusCompleted
+		upload.EndTime = &now
+		upload.TranscodeFailures = failures
+		upload.Renditions, upload.RenditionTimes, upload.RenditionProgress = renditions.snapshot()
+		upload.Stage = UploadStageCompleted
+		upload.StageTimes[UploadStageCompleted] = now
+		return repo.CompleteUpload(ctx, upload)
 	})
 
 	if err != nil {
+		s.failUpload(ctx, upload)
 		return fmt.Errorf("failed to update records: %w", err)
 	}
+	s.endEvents(upload, nil)
+
+	s.enqueueReplication(replicationJobs)
 
 	return nil
 }
 
-// GetVideo retrieves a video by ID
-func (s *VideoServiceImpl) GetVideo(videoID uuid.UUID) (*Video, error) {
-	var video Video
+// discardDuplicate deletes the records InitializeUpload created for an
+// upload that duplicates existing, and points the upload at existing
+func (s *VideoServiceImpl) discardDuplicate(ctx context.Context, upload *VideoUpload, existing *Video) error {
+	if err := s.repo.DeleteRecords(ctx, upload.VideoID); err != nil {
+		s.failUpload(ctx, upload)
+		return fmt.Errorf("failed to discard duplicate upload: %w", err)
+	}
 
-	// Use Unscoped to check if the video exists at all, including soft-deleted ones
-	var count int64
-	if err := s.db.Unscoped().Model(&Video{}).Where("id = ?", videoID).Count(&count).Error; err != nil {
-		return nil, fmt.Errorf("failed to check video existence: %w", err)
+	s.logger.LogInfo("Upload duplicates an existing video", map[string]interface{}{
+		"video_id":    upload.VideoID,
+		"existing_id": existing.ID,
+		"checksum":    existing.Checksum,
+	})
+	upload.DuplicateOf = existing.ID
+	upload.Status = UploadStatusCompleted
+	upload.Stage = UploadStageCompleted
+	s.endEvents(upload, &existing.ID)
+	return nil
+}
+
+// probeUpload reads the metadata of a saved upload and checks that it is a
+// decodable video within the limits. Files that are not are reported with
+// ErrInvalidMedia; other errors mean the file could not be checked.
+func (s *VideoServiceImpl) probeUpload(ctx context.Context, path string) (*ffmpeg.VideoMetadata, error) {
+	metadata, err := s.ffmpeg.GetMetadata(ctx, path)
+	if errors.Is(err, ffmpeg.ErrUnreadable) {
+		return nil, fmt.Errorf("%w: the file is not a readable video", ErrInvalidMedia)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video metadata: %w", err)
+	}
+	if err := s.limits.CheckMedia(metadata); err != nil {
+		return nil, err
 	}
 
-	// Now try to get the non-deleted video
-	if err := s.db.Preload("Upload").Preload("Transcodes").Preload("Transcodes.Segments").First(&video, videoID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			if count > 0 {
-				// Video exists but is soft-deleted
-				return nil, fmt.Errorf("video has been deleted: %s", videoID)
-			}
-			// Video doesn't exist at all
-			return nil, fmt.Errorf("video not found: %s", videoID)
+	if err := s.ffmpeg.VerifyDecodable(ctx, path); err != nil {
+		if errors.Is(err, ffmpeg.ErrUnreadable) {
+			return nil, fmt.Errorf("%w: the video stream cannot be decoded", ErrInvalidMedia)
 		}
-		return nil, fmt.Errorf("failed to get video: %w", err)
+		return nil, fmt.Errorf("failed to decode video: %w", err)
 	}
+	return metadata, nil
+}
+
+// renditionResult is the outcome of transcoding and storing one rendition
+type renditionResult struct {
+	key      string
+	duration int
+	// checksum is the hex SHA-256 of the stored file
+	checksum string
+	// failure is why the rendition is missing; empty when it was stored
+	failure TranscodeFailureReason
+}
 
-	return &video, nil
+// outputFields maps the renditions that are not video resolutions to the
+// RenditionOutputs field holding their storage key
+var outputFields = map[string]func(outputs *RenditionOutputs) *string{
+	videostorage.RenditionAudio:   func(outputs *RenditionOutputs) *string { return &outputs.AudioPath },
+	videostorage.RenditionPreview: func(outputs *RenditionOutputs) *string { return &outputs.PreviewPath },
 }
 
-// ListVideos retrieves a list of videos with pagination
-func (s *VideoServiceImpl) ListVideos(page, limit int) ([]Video, error) {
-	var videos []Video
-	offset := (page - 1) * limit
+// plannedRendition is one output of an upload and how to encode it
+type plannedRendition struct {
+	name   string
+	encode func(ctx context.Context, outputPath string) (duration int, err error)
+}
 
-	// Note: GORM's default scope already excludes soft-deleted records
-	// but we're being explicit here for clarity
-	if err := s.db.Preload("Upload").Preload("Transcodes").Preload("Transcodes.Segments").
-		Where("deleted_at IS NULL").
-		Offset(offset).Limit(limit).Find(&videos).Error; err != nil {
-		return nil, fmt.Errorf("failed to list videos: %w", err)
+// planRenditions lists the outputs of an upload: the transcode ladder first,
+// then the audio-only rendition for sources with sound and the preview when
+// they are enabled
+func (s *VideoServiceImpl) planRenditions(originalPath string, metadata *ffmpeg.VideoMetadata) []plannedRendition {
+	planned := make([]plannedRendition, 0, len(transcodeLadder)+len(outputFields))
+	for _, resolution := range transcodeLadder {
+		planned = append(planned, plannedRendition{
+			name: resolution,
+			encode: func(ctx context.Context, outputPath string) (int, error) {
+				if err := s.ffmpeg.Transcode(ctx, originalPath, outputPath, resolution); err != nil {
+					return 0, err
+				}
+				transcodedMetadata, err := s.ffmpeg.GetMetadata(ctx, outputPath)
+				if err != nil {
+					return 0, fmt.Errorf("failed to get transcoded video metadata: %w", err)
+				}
+				return int(transcodedMetadata.Duration), nil
+			},
+		})
+	}
+
+	if s.ffmpeg.AudioEnabled() && metadata.AudioStream() != nil {
+		planned = append(planned, plannedRendition{
+			name: videostorage.RenditionAudio,
+			encode: func(ctx context.Context, outputPath string) (int, error) {
+				return 0, s.ffmpeg.ExtractAudio(ctx, originalPath, outputPath, metadata)
+			},
+		})
+	}
+	if s.ffmpeg.PreviewEnabled() {
+		planned = append(planned, plannedRendition{
+			name: videostorage.RenditionPreview,
+			encode: func(ctx context.Context, outputPath string) (int, error) {
+				return 0, s.ffmpeg.Preview(ctx, originalPath, outputPath, metadata)
+			},
+		})
 	}
 
-	return videos, nil
+	return planned
 }
 
-// DeleteVideo soft deletes a video by ID
-func (s *VideoServiceImpl) DeleteVideo(videoID uuid.UUID) error {
-	ctx := context.Background()
+// produceRendition encodes a rendition into tempDir and stores it,
+// recording its progress in renditions
+func (s *VideoServiceImpl) produceRendition(ctx context.Context, renditions *renditionTracker, tempDir string, rendition plannedRendition) renditionResult {
+	resolution := rendition.name
+	fail := func(reason TranscodeFailureReason) renditionResult {
+		renditions.set(ctx, resolution, RenditionFailed)
+		return renditionResult{failure: reason}
+	}
+	renditions.set(ctx, resolution, RenditionTranscoding)
 
-	// Get video details first
-	video, err := s.GetVideo(videoID)
+	// Perform transcoding, following its progress
+	outputPath := filepath.Join(tempDir, videostorage.FileName(resolution))
+	encodeCtx := ffmpeg.WithProgress(ctx, func(percent float64) {
+		renditions.progress(ctx, resolution, percent)
+	})
+	duration, err := rendition.encode(encodeCtx, outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to get video details: %w", err)
+		s.logger.LogError("Failed to transcode video", map[string]interface{}{
+			"error":      err.Error(),
+			"resolution": resolution,
+			"output":     outputPath,
+			"reason":     transcodeFailureReason(err),
+		})
+		return fail(transcodeFailureReason(err))
 	}
-	if video == nil {
-		return fmt.Errorf("video not found: %s", videoID)
+
+	// Upload transcoded file to S3
+	renditions.set(ctx, resolution, RenditionStoring)
+	transcodedFile, err := os.Open(outputPath)
+	if err != nil {
+		s.logger.LogError("Failed to open transcoded file", map[string]interface{}{
+			"error": err.Error(),
+			"path":  outputPath,
+		})
+		return fail(TranscodeFailureEncode)
+	}
+	checksum, err := fileChecksum(transcodedFile)
+	if err != nil {
+		transcodedFile.Close()
+		s.logger.LogError("Failed to hash transcoded file", map[string]interface{}{
+			"error": err.Error(),
+			"path":  outputPath,
+		})
+		return fail(TranscodeFailureEncode)
 	}
 
-	// Delete files from S3
-	if err := s.storage.DeleteVideo(ctx, videoID); err != nil {
-		s.logger.LogError("Failed to delete video files from S3", map[string]interface{}{
-			"error":   err.Error(),
-			"videoID": videoID,
+	transcodedKey, err := s.storage.UploadVideo(ctx, renditions.upload.VideoID, resolution, transcodedFile)
+	transcodedFile.Close()
+	if err != nil {
+		s.logger.LogError("Failed to upload transcoded file to S3", map[string]interface{}{
+			"error":      err.Error(),
+			"resolution": resolution,
+		})
+		return fail(TranscodeFailureStorage)
+	}
+
+	renditions.set(ctx, resolution, RenditionCompleted)
+	return renditionResult{key: transcodedKey, duration: duration, checksum: checksum}
+}
+
+// fileChecksum returns the hex SHA-256 of file, leaving it at its start
+func fileChecksum(file *os.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// renditionTracker saves the progress of an upload's renditions, which are
+// transcoded concurrently, so the status endpoint can show partial completion
+type renditionTracker struct {
+	service  *VideoServiceImpl
+	upload   *VideoUpload
+	mu       sync.Mutex
+	statuses RenditionStatuses
+	times    RenditionTimes
+	percent  RenditionProgress
+	// percentSaved is when the encoding progress was last written
+	percentSaved time.Time
+}
+
+func (s *VideoServiceImpl) newRenditionTracker(upload *VideoUpload) *renditionTracker {
+	return &renditionTracker{
+		service:  s,
+		upload:   upload,
+		statuses: make(RenditionStatuses),
+		times:    make(RenditionTimes),
+		percent:  make(RenditionProgress),
+	}
+}
+
+// queue marks resolutions as waiting for a worker
+func (t *renditionTracker) queue(ctx context.Context, resolutions []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, resolution := range resolutions {
+		t.statuses[resolution] = RenditionQueued
+		t.publish(resolution, RenditionQueued)
+	}
+	t.save(ctx)
+}
+
+// set records the status of one rendition, and when it started transcoding
+// or finished
+func (t *renditionTracker) set(ctx context.Context, resolution string, status RenditionStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.statuses[resolution] = status
+
+	now := time.Now()
+	timing := t.times[resolution]
+	switch status {
+	case RenditionTranscoding:
+		timing.StartedAt = &now
+	case RenditionCompleted, RenditionFailed:
+		timing.FinishedAt = &now
+	}
+	t.times[resolution] = timing
+	t.publish(resolution, status)
+	t.save(ctx)
+
+	// A stored rendition is fully encoded, whatever progress was last written
+	if status == RenditionCompleted && t.percent[resolution] != 100 {
+		t.percent[resolution] = 100
+		t.saveProgress(ctx)
+	}
+}
+
+// progress records how much of a resolution has been encoded, each time it
+// reaches another whole percent. Clients following the upload get every
+// percent; the database at most one write per renditionProgressInterval.
+func (t *renditionTracker) progress(ctx context.Context, resolution string, percent float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	whole := int(percent)
+	if last, ok := t.percent[resolution]; ok && whole <= last {
+		return
+	}
+	t.percent[resolution] = whole
+	t.service.events.Publish(t.upload.VideoID, UploadEventProgress, TranscodeProgressEvent{Resolution: resolution, Percent: whole})
+	if time.Since(t.percentSaved) >= renditionProgressInterval {
+		t.saveProgress(ctx)
+	}
+}
+
+// publish announces a rendition's new status to the clients following the upload
+func (t *renditionTracker) publish(resolution string, status RenditionStatus) {
+	t.service.events.Publish(t.upload.VideoID, UploadEventRendition, RenditionEvent{Resolution: resolution, Status: status})
+}
+
+// snapshot returns a copy of the current statuses, timing and progress
+func (t *renditionTracker) snapshot() (RenditionStatuses, RenditionTimes, RenditionProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return maps.Clone(t.statuses), maps.Clone(t.times), maps.Clone(t.percent)
+}
+
+// save writes the statuses; the caller holds t.mu, so writes land in order
+func (t *renditionTracker) save(ctx context.Context) {
+	if err := t.service.repo.SetRenditions(ctx, t.upload.ID, t.statuses, t.times); err != nil {
+		t.service.logger.LogError("Failed to record rendition status", map[string]interface{}{
+			"error":    err.Error(),
+			"video_id": t.upload.VideoID,
 		})
-		// Continue with database deletion even if S3 deletion fails
 	}
+}
+
+// saveProgress writes the encoding progress; the caller holds t.mu
+func (t *renditionTracker) saveProgress(ctx context.Context) {
+	t.percentSaved = time.Now()
+	if err := t.service.repo.SetRenditionProgress(ctx, t.upload.ID, t.percent); err != nil {
+		t.service.logger.LogError("Failed to record rendition progress", map[string]interface{}{
+			"error":    err.Error(),
+			"video_id": t.upload.VideoID,
+		})
+	}
+}
+
+// failUpload marks upload failed, or interrupted when ctx was cancelled and
+// the upload can be retried as it is
+func (s *VideoServiceImpl) failUpload(ctx context.Context, upload *VideoUpload) {
+	status := UploadStatusFailed
+	if ctx.Err() != nil {
+		status = UploadStatusInterrupted
+	}
+	s.setUploadStatus(ctx, upload, status)
+}
+
+// setUploadStatus ends upload with status. It runs even when ctx is cancelled
+// so uploads are not left in progress.
+func (s *VideoServiceImpl) setUploadStatus(ctx context.Context, upload *VideoUpload, status UploadStatus) {
+	now := time.Now()
+	upload.Status = status
+	upload.EndTime = &now
+	if err := s.repo.SetUploadStatus(context.WithoutCancel(ctx), upload); err != nil {
+		s.logger.LogError("Failed to update upload status", map[string]interface{}{
+			"error":    err.Error(),
+			"video_id": upload.VideoID,
+			"status":   status,
+		})
+	}
+	s.endEvents(upload, nil)
+}
+
+// storedBytesInterval limits how often upload progress is written to the database
+const storedBytesInterval = time.Second
+
+// renditionProgressInterval limits how often encoding progress is written to the database
+const renditionProgressInterval = time.Second
+
+// recordStoredBytes returns a progress callback that saves how much of the
+// original has reached storage, so the status endpoint can report it.
+// Writes are throttled; the final count is always saved.
+func (s *VideoServiceImpl) recordStoredBytes(ctx context.Context, upload *VideoUpload) videostorage.ProgressFunc {
+	var lastWrite time.Time
+	return func(stored, total int64) {
+		if stored != total && time.Since(lastWrite) < storedBytesInterval {
+			return
+		}
+		lastWrite = time.Now()
+
+		upload.StoredBytes = stored
+		s.events.Publish(upload.VideoID, UploadEventStored, StoredEvent{StoredBytes: stored, TotalBytes: total})
+		if err := s.repo.SetStoredBytes(ctx, upload.ID, stored); err != nil {
+			s.logger.LogError("Failed to record upload progress", map[string]interface{}{
+				"error":    err.Error(),
+				"video_id": upload.VideoID,
+			})
+		}
+	}
+}
+
+// enqueueReplication hands files to the IPFS replication queue. Files that
+// cannot be queued stay "s3-only" and are picked up again later.
+func (s *VideoServiceImpl) enqueueReplication(jobs []ReplicationJob) {
+	if s.replicator == nil {
+		return
+	}
+
+	for _, job := range jobs {
+		if err := s.replicator.Enqueue(job); err != nil {
+			s.logger.LogError("Failed to queue IPFS replication", map[string]interface{}{
+				"error":      err.Error(),
+				"video_id":   job.VideoID,
+				"segment_id": job.SegmentID,
+			})
+		}
+	}
+}
+
+// GetVideo retrieves a video by ID, from the video cache when it holds it
+func (s *VideoServiceImpl) GetVideo(ctx context.Context, videoID uuid.UUID) (*Video, error) {
+	return s.cache.get(ctx, videoID, func() (*Video, error) {
+		return s.repo.GetByID(ctx, videoID)
+	})
+}
+
+// ListVideos retrieves a list of videos with pagination, optionally narrowed
+// to one tag or category
+func (s *VideoServiceImpl) ListVideos(ctx context.Context, page, limit int, filter ListFilter) ([]Video, int64, error) {
+	return s.repo.List(ctx, page, limit, filter)
+}
 
-	// Soft delete from database (GORM will automatically set DeletedAt)
-	if err := s.db.Delete(&Video{}, videoID).Error; err != nil {
-		return fmt.Errorf("failed to delete video: %w", err)
+// RecordView counts a playback start of a video and records when it was
+// watched. The columns are updated in place, leaving updated_at alone, and
+// the cached video keeps its count until it expires.
+func (s *VideoServiceImpl) RecordView(ctx context.Context, videoID uuid.UUID) error {
+	return s.repo.IncrementViews(ctx, videoID)
+}
+
+// AdjustCommentCount adds delta to a video's comment count, never taking it
+// below zero. Like views, the cached video keeps its count until it expires.
+func (s *VideoServiceImpl) AdjustCommentCount(ctx context.Context, videoID uuid.UUID, delta int) error {
+	return s.repo.AdjustCommentCount(ctx, videoID, delta)
+}
+
+// DeleteVideo soft deletes a video by ID. Its files stay in storage until
+// the retention cleanup purges them.
+func (s *VideoServiceImpl) DeleteVideo(ctx context.Context, videoID, deletedBy uuid.UUID) error {
+	// Get video details first
+	video, err := s.GetVideo(ctx, videoID)
+	if err != nil {
+		return fmt.Errorf("failed to get video details: %w", err)
 	}
+	if video == nil {
+		return fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+	}
+
+	// Soft delete from database, recording who did it so the trash knows who may restore it
+	if err := s.repo.SoftDelete(ctx, videoID, deletedBy); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	s.purgeCDN(ctx, videoID)
 
 	return nil
 }
 
-// UpdateVideo updates a video's metadata
-func (s *VideoServiceImpl) UpdateVideo(videoID uuid.UUID, title, description string) error {
-	updates := map[string]interface{}{
-		"title":       title,
-		"description": description,
-		"updated_at":  time.Now(),
+// PurgeVideo permanently deletes a video's files, IPFS pins and records. The
+// video is soft-deleted first so it disappears at once; if a later step fails
+// it stays hidden, and the purge can be retried or is finished by the
+// retention cleanup.
+func (s *VideoServiceImpl) PurgeVideo(ctx context.Context, videoID uuid.UUID) error {
+	// Files may be gone before the records, so the video leaves the trash for good
+	if err := s.repo.MarkPurging(ctx, videoID); err != nil {
+		return err
 	}
+	s.cache.Invalidate(ctx, videoID)
 
-	if err := s.db.Model(&Video{}).Where("id = ?", videoID).Updates(updates).Error; err != nil {
-		return fmt.Errorf("failed to update video: %w", err)
+	if err := s.purger.Purge(ctx, videoID); err != nil {
+		s.logger.LogError("Failed to purge video", map[string]interface{}{
+			"error":   err.Error(),
+			"videoID": videoID,
+		})
+		return err
 	}
 	return nil
 }
+
+// UpdateVideo updates a video's metadata and replaces its tags
+func (s *VideoServiceImpl) UpdateVideo(ctx context.Context, videoID uuid.UUID, title, description string, taxonomy Taxonomy) error {
+	if err := s.repo.Update(ctx, videoID, title, description, taxonomy); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	return nil
+}
+
+// PopularTags returns the tags used by the most videos that are not deleted
+func (s *VideoServiceImpl) PopularTags(ctx context.Context, limit int) ([]TagCount, error) {
+	return s.repo.PopularTags(ctx, limit)
+}
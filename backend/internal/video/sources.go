@@ -0,0 +1,146 @@
+package video
+
+import "strings"
+
+// Kinds of video sources
+const (
+	// SourceStream is the API's stream endpoint, which needs the caller's credentials
+	SourceStream = "stream"
+	// SourceCDN is the CDN in front of storage
+	SourceCDN = "cdn"
+	// SourceStorage is a temporary link straight to the stored file
+	SourceStorage = "storage"
+	// SourceIPFS is the file's IPFS copy, fetched by CID
+	SourceIPFS = "ipfs"
+)
+
+// SourceConfig describes where videos can be fetched from besides the API
+type SourceConfig struct {
+	// IPFSGateway is the HTTP gateway IPFS copies are linked at; they are
+	// listed by CID only when it is empty
+	IPFSGateway string
+	// IPFSPeers are multiaddrs of the IPFS nodes pinning videos, which
+	// clients may dial directly to fetch them
+	IPFSPeers []string
+}
+
+// VideoSource is one place a rendition can be fetched from
+type VideoSource struct {
+	Type string `json:"type" enums:"stream,cdn,storage,ipfs" example:"ipfs"`
+	// URL fetches the file over HTTP; CDN and storage URLs may expire
+	URL string `json:"url,omitempty" example:"https://ipfs.io/ipfs/bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"`
+	// CID identifies an IPFS copy, and URI addresses it for IPFS-native clients
+	CID string `json:"cid,omitempty" example:"bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"`
+	URI string `json:"uri,omitempty" example:"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"`
+	// Peers are multiaddrs of IPFS nodes known to hold the CID
+	Peers []string `json:"peers,omitempty" example:"/dns4/ipfs.example.com/tcp/4001/p2p/12D3KooWAbc"`
+}
+
+// RenditionSources lists every place one rendition can be fetched from,
+// with the hash the fetched file must match
+type RenditionSources struct {
+	Rendition string `json:"rendition" example:"720p"`
+	// SHA256 is the hex SHA-256 of the file; omitted for renditions stored
+	// before checksums were recorded
+	SHA256 string `json:"sha256,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// Size is the file's size in bytes, when known
+	Size    int64         `json:"size,omitempty" example:"104857600"`
+	Sources []VideoSource `json:"sources"`
+}
+
+// VideoSourcesResponse lists where each of a video's renditions can be fetched from
+type VideoSourcesResponse struct {
+	VideoID    string             `json:"video_id"`
+	Renditions []RenditionSources `json:"renditions"`
+}
+
+// renditionFile is a stored rendition a video can be fetched as
+type renditionFile struct {
+	name     string
+	key      string
+	checksum string
+	size     int64
+	cid      string
+}
+
+// renditionFiles returns the original and each stored resolution of a
+// video. Resolutions dropped by storage tiering are left out until they are
+// regenerated.
+func renditionFiles(video *Video) []renditionFile {
+	files := []renditionFile{}
+	if video.StoragePath != "" {
+		files = append(files, renditionFile{
+			name:     "original",
+			key:      video.StoragePath,
+			checksum: video.Checksum,
+			size:     video.FileSize,
+			cid:      video.IPFSCID,
+		})
+	}
+	if video.RenditionsDroppedAt != nil {
+		return files
+	}
+	for _, resolution := range []string{"720p", "480p", "360p"} {
+		if segment := renditionSegment(video, resolution); segment != nil {
+			files = append(files, renditionFile{
+				name:     resolution,
+				key:      segment.StoragePath,
+				checksum: segment.Checksum,
+				cid:      segment.IPFSCID,
+			})
+		}
+	}
+	return files
+}
+
+// ipfsSource returns the IPFS source of a CID. The gateway may be given
+// with or without its /ipfs/ path.
+func (c SourceConfig) ipfsSource(cid string) VideoSource {
+	source := VideoSource{Type: SourceIPFS, CID: cid, URI: "ipfs://" + cid, Peers: c.IPFSPeers}
+	if c.IPFSGateway != "" {
+		gateway := strings.TrimSuffix(c.IPFSGateway, "/")
+		if !strings.HasSuffix(gateway, "/ipfs") {
+			gateway += "/ipfs"
+		}
+		source.URL = gateway + "/" + cid
+	}
+	return source
+}
+
+// RenditionCIDs maps each of a video's stored renditions that has been
+// replicated to IPFS to its CID
+func RenditionCIDs(video *Video) map[string]string {
+	cids := map[string]string{}
+	for _, file := range renditionFiles(video) {
+		if file.cid != "" {
+			cids[file.name] = file.cid
+		}
+	}
+	return cids
+}
+
+// Rendition is a stored rendition of a video, for packages linking its files
+type Rendition struct {
+	// Name is original or a resolution, such as 720p
+	Name string
+	Key  string
+	// Size is the file's size in bytes; 0 when unknown
+	Size int64
+	CID  string
+}
+
+// Renditions returns the original and each stored resolution of a video,
+// leaving out resolutions dropped by storage tiering
+func Renditions(video *Video) []Rendition {
+	renditions := []Rendition{}
+	for _, file := range renditionFiles(video) {
+		renditions = append(renditions, Rendition{Name: file.name, Key: file.key, Size: file.size, CID: file.cid})
+	}
+	return renditions
+}
+
+// GatewayURL returns the URL an IPFS copy is fetched at over HTTP, or an
+// empty string when no gateway is configured
+func (c SourceConfig) GatewayURL(cid string) string {
+	return c.ipfsSource(cid).URL
+}
@@ -0,0 +1,54 @@
+package video
+
+import (
+	"context"
+	"strings"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTakedownReason is returned when taking a video down without a reason
+	ErrTakedownReason = apierror.Validation("reason", "a takedown reason is required")
+	// ErrNotTakenDown is returned when restoring a video that was not taken down
+	ErrNotTakenDown = apierror.New(apierror.CodeConflict, "video is not taken down")
+)
+
+// TakedownRequest is the payload for taking a video down
+type TakedownRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Copyright claim"`
+}
+
+// TakeDownVideo hides a video from listings and from everyone but its owner
+// and staff. Taking down a video that is already down updates the reason.
+func (s *VideoServiceImpl) TakeDownVideo(ctx context.Context, videoID uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrTakedownReason
+	}
+
+	if err := s.repo.TakeDown(ctx, videoID, reason); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	s.purgeCDN(ctx, videoID)
+	return nil
+}
+
+// RestoreVideo lifts a takedown, publishing the video again
+func (s *VideoServiceImpl) RestoreVideo(ctx context.Context, videoID uuid.UUID) error {
+	video, err := s.repo.GetByID(ctx, videoID)
+	if err != nil {
+		return err
+	}
+	if video.TakenDownAt == nil {
+		return ErrNotTakenDown
+	}
+
+	if err := s.repo.LiftTakedown(ctx, videoID); err != nil {
+		return err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	return nil
+}
@@ -0,0 +1,104 @@
+package video
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Category is the single topic a video is filed under
+type Category string
+
+const (
+	CategoryEducation     Category = "education"
+	CategoryEntertainment Category = "entertainment"
+	CategoryGaming        Category = "gaming"
+	CategoryMusic         Category = "music"
+	CategoryNews          Category = "news"
+	CategoryScience       Category = "science"
+	CategorySports        Category = "sports"
+	CategoryTechnology    Category = "technology"
+	CategoryOther         Category = "other"
+)
+
+// Categories lists every category in display order
+var Categories = []Category{
+	CategoryEducation,
+	CategoryEntertainment,
+	CategoryGaming,
+	CategoryMusic,
+	CategoryNews,
+	CategoryScience,
+	CategorySports,
+	CategoryTechnology,
+	CategoryOther,
+}
+
+// IsValid reports whether c is a known category. The empty category means
+// uncategorized and is also valid.
+func (c Category) IsValid() bool {
+	if c == "" {
+		return true
+	}
+	for _, category := range Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// MaxTags is the most tags a video can have
+	MaxTags = 10
+	// MaxTagLength is the longest tag accepted, in characters
+	MaxTagLength = 32
+)
+
+// tagPattern matches normalized tags: lowercase letters, digits and inner hyphens
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Taxonomy is how a video is classified
+type Taxonomy struct {
+	Category Category
+	// Tags are normalized tag names; see NormalizeTags
+	Tags []string
+}
+
+// TagCount is a tag with the number of videos using it
+type TagCount struct {
+	Name  string `json:"name" example:"tutorial"`
+	Count int64  `json:"count" example:"42"`
+}
+
+// NormalizeTags lowercases and trims tags, drops a leading '#', removes
+// duplicates and empty entries, and checks the result against the tag rules
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > MaxTagLength || !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: tags are up to %d letters, digits or hyphens", tag, MaxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("a video can have at most %d tags", MaxTags)
+	}
+	return normalized, nil
+}
+
+// tagNames returns the names of tags
+func tagNames(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
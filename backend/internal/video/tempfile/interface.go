@@ -16,4 +16,8 @@ type TempFileManager interface {
 
 	// GetActiveDirs returns a list of all active temporary directories
 	GetActiveDirs() []string
+
+	// CheckSpace returns ErrSpaceExhausted when size more bytes would take
+	// the temporary files past the budget
+	CheckSpace(size int64) error
 }
@@ -1,15 +1,22 @@
 package tempfile
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
 	"github.com/google/uuid"
 )
 
+// ErrSpaceExhausted is returned by CheckSpace when the temporary files would
+// grow past the configured budget
+var ErrSpaceExhausted = errors.New("temporary storage budget exceeded")
+
 // Manager handles temporary file operations
 type Manager struct {
 	baseDir     string
@@ -17,27 +24,154 @@ type Manager struct {
 	logger      logger.Logger
 	mu          sync.RWMutex
 	permissions os.FileMode
+	maxBytes    int64
+	metrics     *Metrics
 }
 
 // Config represents the configuration for the temporary file manager
 type Config struct {
-	BaseDir     string      // Base directory for temporary files
-	Permissions os.FileMode // File permissions for created directories
+	BaseDir     string        // Base directory for temporary files
+	Permissions os.FileMode   // File permissions for created directories
+	StaleAfter  time.Duration // Age at which directories left behind are removed on startup; 0 keeps them
+	MaxBytes    int64         // Space the temporary files may use before CheckSpace fails; 0 sets no limit
+	Metrics     *Metrics      // Optional
 }
 
-// NewManager creates a new temporary file manager
+// NewManager creates a new temporary file manager. Directories under the base
+// directory left behind by crashed processes are removed once nothing in them
+// has changed for StaleAfter.
 func NewManager(config *Config, logger logger.Logger) (*Manager, error) {
 	// Create base directory if it doesn't exist
 	if err := os.MkdirAll(config.BaseDir, config.Permissions); err != nil {
 		return nil, fmt.Errorf("failed to create base directory: %w", err)
 	}
 
-	return &Manager{
+	m := &Manager{
 		baseDir:     config.BaseDir,
 		activeDirs:  make(map[string]bool),
 		logger:      logger,
 		permissions: config.Permissions,
-	}, nil
+		maxBytes:    config.MaxBytes,
+		metrics:     config.Metrics,
+	}
+	m.metrics.setBudget(config.MaxBytes)
+
+	if config.StaleAfter > 0 {
+		if _, err := m.SweepStale(config.StaleAfter); err != nil {
+			m.logger.LogError(err, "Failed to remove stale temporary directories")
+		}
+	}
+	m.measure()
+	return m, nil
+}
+
+// SweepStale removes the directories under the base directory, other than
+// this manager's, in which nothing has changed for at least age. Other
+// processes sharing the base directory keep theirs as long as they are
+// written to. It returns how many directories were removed.
+func (m *Manager) SweepStale(age time.Duration) (int, error) {
+	entries, err := os.ReadDir(m.baseDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read temporary directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-age)
+	removed := 0
+	var freed int64
+	for _, entry := range entries {
+		// Only directories named like the ones CreateTempDir makes are swept
+		if _, err := uuid.Parse(entry.Name()); err != nil || !entry.IsDir() {
+			continue
+		}
+		dirPath := filepath.Join(m.baseDir, entry.Name())
+		if m.IsManaged(dirPath) {
+			continue
+		}
+		size, modified, err := measureDir(dirPath)
+		if err != nil || modified.After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(dirPath); err != nil {
+			m.logger.LogError(err, fmt.Sprintf("Failed to remove stale temporary directory: path=%s", dirPath))
+			continue
+		}
+		removed++
+		freed += size
+	}
+
+	m.metrics.addSwept(removed)
+	if removed > 0 {
+		m.logger.LogInfo("Removed stale temporary directories", map[string]interface{}{
+			"count":       removed,
+			"freed_bytes": freed,
+		})
+	}
+	return removed, nil
+}
+
+// Usage returns the bytes used by the files under the base directory,
+// including those of other processes sharing it
+func (m *Manager) Usage() (int64, error) {
+	size, _, err := measureDir(m.baseDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure temporary directory: %w", err)
+	}
+	return size, nil
+}
+
+// CheckSpace returns ErrSpaceExhausted when size more bytes would take the
+// temporary files past the budget. Uploads admitted at the same time are
+// each checked against the current usage, so the budget can be overrun by
+// the uploads in flight.
+func (m *Manager) CheckSpace(size int64) error {
+	used := m.measure()
+	if m.maxBytes <= 0 || used+size <= m.maxBytes {
+		return nil
+	}
+	m.metrics.addRejected()
+	return fmt.Errorf("%w: %d of %d bytes in use", ErrSpaceExhausted, used, m.maxBytes)
+}
+
+// measure records the current usage in the metrics and returns it; a usage
+// that cannot be measured counts as none
+func (m *Manager) measure() int64 {
+	used, err := m.Usage()
+	if err != nil {
+		m.logger.LogError(err, "Failed to measure temporary storage")
+		return 0
+	}
+	m.metrics.setUsage(used)
+	return used
+}
+
+// measureDir returns the size of the regular files under dir and the latest
+// time anything in it was modified. Files removed while it runs are skipped.
+func measureDir(dir string) (int64, time.Time, error) {
+	var size int64
+	var modified time.Time
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path != dir {
+				return nil
+			}
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		return nil
+	})
+	return size, modified, err
 }
 
 // CreateTempDir creates a new temporary directory and returns its path
@@ -57,6 +191,7 @@ func (m *Manager) CreateTempDir() (string, error) {
 
 	// Track the directory
 	m.activeDirs[dirPath] = true
+	m.metrics.setDirectories(len(m.activeDirs))
 
 	m.logger.LogInfo("Created temporary directory", map[string]interface{}{
 		"path": dirPath,
@@ -67,6 +202,14 @@ func (m *Manager) CreateTempDir() (string, error) {
 
 // CleanupDir removes a temporary directory and its contents
 func (m *Manager) CleanupDir(dirPath string) error {
+	if err := m.cleanupDir(dirPath); err != nil {
+		return err
+	}
+	m.measure()
+	return nil
+}
+
+func (m *Manager) cleanupDir(dirPath string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -83,6 +226,7 @@ func (m *Manager) CleanupDir(dirPath string) error {
 
 	// Remove from tracking
 	delete(m.activeDirs, dirPath)
+	m.metrics.setDirectories(len(m.activeDirs))
 
 	m.logger.LogInfo("Cleaned up temporary directory", map[string]interface{}{
 		"path": dirPath,
@@ -93,8 +237,10 @@ func (m *Manager) CleanupDir(dirPath string) error {
 
 // CleanupAll removes all managed temporary directories
 func (m *Manager) CleanupAll() error {
+	defer m.measure()
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	defer func() { m.metrics.setDirectories(len(m.activeDirs)) }()
 
 	var lastErr error
 	for dirPath := range m.activeDirs {
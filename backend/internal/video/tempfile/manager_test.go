@@ -0,0 +1,102 @@
+package tempfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quietLogger discards what the manager logs
+type quietLogger struct {
+	logger.Logger
+}
+
+func (quietLogger) LogInfo(msg string, fields map[string]interface{}) {}
+func (quietLogger) LogError(err error, msg string) error              { return err }
+
+// writeDir creates dir under base holding a file of size bytes, last modified at modified
+func writeDir(t *testing.T, base, dir string, size int, modified time.Time) string {
+	t.Helper()
+	path := filepath.Join(base, dir)
+	require.NoError(t, os.MkdirAll(path, 0755))
+	file := filepath.Join(path, "original.mp4")
+	require.NoError(t, os.WriteFile(file, make([]byte, size), 0644))
+	require.NoError(t, os.Chtimes(file, modified, modified))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	return path
+}
+
+// TestNewManager_SweepsStaleDirectories verifies only directories named like
+// managed ones, with nothing changed within the TTL, are removed on startup
+func TestNewManager_SweepsStaleDirectories(t *testing.T) {
+	base := t.TempDir()
+	old := time.Now().Add(-2 * time.Hour)
+	stale := writeDir(t, base, uuid.NewString(), 10, old)
+	fresh := writeDir(t, base, uuid.NewString(), 10, time.Now())
+	foreign := writeDir(t, base, "keep-me", 10, old)
+
+	// A file still being written keeps its directory, however old the directory is
+	active := writeDir(t, base, uuid.NewString(), 10, old)
+	require.NoError(t, os.WriteFile(filepath.Join(active, "720p.mp4"), []byte("x"), 0644))
+	require.NoError(t, os.Chtimes(active, old, old))
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	_, err := NewManager(&Config{BaseDir: base, Permissions: 0755, StaleAfter: time.Hour, Metrics: metrics}, quietLogger{})
+	require.NoError(t, err)
+
+	assert.NoDirExists(t, stale)
+	assert.DirExists(t, fresh)
+	assert.DirExists(t, foreign)
+	assert.DirExists(t, active)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.swept))
+	assert.Equal(t, 31.0, testutil.ToFloat64(metrics.usage))
+}
+
+// TestManager_CheckSpace verifies uploads are rejected once they would take
+// the temporary files past the budget, and that no budget rejects nothing
+func TestManager_CheckSpace(t *testing.T) {
+	base := t.TempDir()
+	writeDir(t, base, uuid.NewString(), 600, time.Now())
+
+	metrics := NewMetrics(prometheus.NewRegistry())
+	manager, err := NewManager(&Config{BaseDir: base, Permissions: 0755, MaxBytes: 1000, Metrics: metrics}, quietLogger{})
+	require.NoError(t, err)
+
+	assert.NoError(t, manager.CheckSpace(400))
+	err = manager.CheckSpace(401)
+	assert.True(t, errors.Is(err, ErrSpaceExhausted))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.rejected))
+	assert.Equal(t, 1000.0, testutil.ToFloat64(metrics.budget))
+
+	unlimited, err := NewManager(&Config{BaseDir: base, Permissions: 0755}, quietLogger{})
+	require.NoError(t, err)
+	assert.NoError(t, unlimited.CheckSpace(1<<40))
+}
+
+// TestManager_CleanupDirUpdatesUsage verifies the usage metric follows
+// directories being created and removed
+func TestManager_CleanupDirUpdatesUsage(t *testing.T) {
+	metrics := NewMetrics(prometheus.NewRegistry())
+	manager, err := NewManager(&Config{BaseDir: t.TempDir(), Permissions: 0755, Metrics: metrics}, quietLogger{})
+	require.NoError(t, err)
+
+	dir, err := manager.CreateTempDir()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.directories))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "original.mp4"), make([]byte, 100), 0644))
+	require.NoError(t, manager.CheckSpace(0))
+	assert.Equal(t, 100.0, testutil.ToFloat64(metrics.usage))
+
+	require.NoError(t, manager.CleanupDir(dir))
+	assert.Zero(t, testutil.ToFloat64(metrics.directories))
+	assert.Zero(t, testutil.ToFloat64(metrics.usage))
+}
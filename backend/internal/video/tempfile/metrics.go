@@ -0,0 +1,86 @@
+package tempfile
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics exports the temporary file space to Prometheus. A nil *Metrics is
+// valid and records nothing.
+type Metrics struct {
+	usage       prometheus.Gauge
+	budget      prometheus.Gauge
+	directories prometheus.Gauge
+	swept       prometheus.Counter
+	rejected    prometheus.Counter
+}
+
+// NewMetrics creates temporary file metrics and registers them with registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		usage: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_temp",
+			Name:      "bytes_in_use",
+			Help:      "Bytes used by temporary upload files, measured when uploads are admitted and temporary directories removed.",
+		}),
+		budget: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_temp",
+			Name:      "budget_bytes",
+			Help:      "Bytes temporary upload files may use before uploads are rejected; 0 when there is no limit.",
+		}),
+		directories: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_temp",
+			Name:      "directories",
+			Help:      "Temporary directories held by uploads and other processing.",
+		}),
+		swept: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_temp",
+			Name:      "stale_directories_removed_total",
+			Help:      "Temporary directories left behind by earlier processes that were removed.",
+		}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_temp",
+			Name:      "rejected_total",
+			Help:      "Uploads rejected because temporary storage was over budget.",
+		}),
+	}
+	registerer.MustRegister(m.usage, m.budget, m.directories, m.swept, m.rejected)
+	return m
+}
+
+func (m *Metrics) setUsage(bytes int64) {
+	if m == nil {
+		return
+	}
+	m.usage.Set(float64(bytes))
+}
+
+func (m *Metrics) setBudget(bytes int64) {
+	if m == nil {
+		return
+	}
+	m.budget.Set(float64(bytes))
+}
+
+func (m *Metrics) setDirectories(count int) {
+	if m == nil {
+		return
+	}
+	m.directories.Set(float64(count))
+}
+
+func (m *Metrics) addSwept(count int) {
+	if m == nil {
+		return
+	}
+	m.swept.Add(float64(count))
+}
+
+func (m *Metrics) addRejected() {
+	if m == nil {
+		return
+	}
+	m.rejected.Inc()
+}
@@ -2,6 +2,7 @@ package e2e
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -15,9 +16,11 @@ import (
 	"time"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
 	httpPkg "github.com/consensuslabs/pavilion-network/backend/internal/http"
 	"github.com/consensuslabs/pavilion-network/backend/internal/storage"
 	"github.com/consensuslabs/pavilion-network/backend/internal/storage/ipfs"
+	"github.com/consensuslabs/pavilion-network/backend/internal/storage/local"
 	"github.com/consensuslabs/pavilion-network/backend/internal/storage/s3"
 	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
@@ -184,12 +187,12 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 	require.NoError(t, err, "Failed to load test configuration")
 
 	// VERY IMPORTANT: Ensure the FFmpeg output directory exists
-	if testConfig.FFmpeg.OutputPath != "" {
-		if err := os.MkdirAll(testConfig.FFmpeg.OutputPath, 0755); err != nil {
-			testLogger.LogError(err, fmt.Sprintf("Failed to create FFmpeg output directory: %s", testConfig.FFmpeg.OutputPath))
+	if testConfig.Ffmpeg.OutputPath != "" {
+		if err := os.MkdirAll(testConfig.Ffmpeg.OutputPath, 0755); err != nil {
+			testLogger.LogError(err, fmt.Sprintf("Failed to create FFmpeg output directory: %s", testConfig.Ffmpeg.OutputPath))
 		} else {
 			testLogger.LogInfo("Created FFmpeg output directory", map[string]interface{}{
-				"directory": testConfig.FFmpeg.OutputPath,
+				"directory": testConfig.Ffmpeg.OutputPath,
 			})
 		}
 	}
@@ -208,11 +211,7 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 
 	// Create auth service for authentication
 	jwtService := auth.NewJWTService(&auth.Config{
-		JWT: struct {
-			Secret          string
-			AccessTokenTTL  time.Duration
-			RefreshTokenTTL time.Duration
-		}{
+		JWT: config.JWTConfig{
 			Secret:          "test-secret-key",
 			AccessTokenTTL:  time.Hour,
 			RefreshTokenTTL: time.Hour * 24 * 7,
@@ -220,11 +219,7 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 	})
 	refreshTokenRepo := auth.NewRefreshTokenRepository(db, testLogger)
 	authConfig := &auth.Config{
-		JWT: struct {
-			Secret          string
-			AccessTokenTTL  time.Duration
-			RefreshTokenTTL time.Duration
-		}{
+		JWT: config.JWTConfig{
 			Secret:          "test-secret-key",
 			AccessTokenTTL:  time.Hour,
 			RefreshTokenTTL: time.Hour * 24 * 7,
@@ -289,47 +284,57 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 		"envKeyID":       os.Getenv("S3_ACCESS_KEY_ID") != "",
 		"envSecretKey":   os.Getenv("S3_SECRET_ACCESS_KEY") != "",
 	})
-	s3Service, err := s3.NewService(s3Config, testLogger)
+	// Without S3 credentials the pipeline runs against local storage
+	var storageBackend storage.Backend
+	if s3Config.AccessKeyID == "" || s3Config.SecretAccessKey == "" {
+		storageBackend, err = local.NewService(&local.Config{
+			Dir:           t.TempDir(),
+			RootDirectory: s3Config.RootDirectory,
+			BaseURL:       "http://localhost/storage",
+		}, testLogger)
+	} else {
+		storageBackend, err = s3.NewService(s3Config, testLogger)
+	}
 	require.NoError(t, err, "Failed to create storage service")
 
 	// Create FFmpeg service
 	// Check if we have a custom output directory from environment
 	if customOutputDir := os.Getenv("FFMPEG_OUTPUT_PATH"); customOutputDir != "" {
 		testLogger.LogInfo("Using custom FFmpeg output directory from environment", map[string]interface{}{
-			"original": testConfig.FFmpeg.OutputPath,
+			"original": testConfig.Ffmpeg.OutputPath,
 			"custom":   customOutputDir,
 		})
-		testConfig.FFmpeg.OutputPath = customOutputDir
+		testConfig.Ffmpeg.OutputPath = customOutputDir
 	}
 
 	// Ensure the output directory exists
-	if err := os.MkdirAll(testConfig.FFmpeg.OutputPath, 0755); err != nil {
-		testLogger.LogError(err, fmt.Sprintf("Failed to create FFmpeg output directory: %s", testConfig.FFmpeg.OutputPath))
+	if err := os.MkdirAll(testConfig.Ffmpeg.OutputPath, 0755); err != nil {
+		testLogger.LogError(err, fmt.Sprintf("Failed to create FFmpeg output directory: %s", testConfig.Ffmpeg.OutputPath))
 	}
 
 	// Log FFmpeg configuration
 	testLogger.LogInfo("FFmpeg configuration", map[string]interface{}{
-		"ffmpegPath":           testConfig.FFmpeg.Path,
-		"ffprobePath":          testConfig.FFmpeg.ProbePath,
-		"outputPath":           testConfig.FFmpeg.OutputPath,
-		"isAbsoluteOutputPath": filepath.IsAbs(testConfig.FFmpeg.OutputPath),
-		"outputDirExists":      dirExists(testConfig.FFmpeg.OutputPath),
-		"resolutions":          testConfig.FFmpeg.Resolutions,
-		"codec":                testConfig.FFmpeg.VideoCodec,
-		"audioCodec":           testConfig.FFmpeg.AudioCodec,
-		"preset":               testConfig.FFmpeg.Preset,
+		"ffmpegPath":           testConfig.Ffmpeg.Path,
+		"ffprobePath":          testConfig.Ffmpeg.ProbePath,
+		"outputPath":           testConfig.Ffmpeg.OutputPath,
+		"isAbsoluteOutputPath": filepath.IsAbs(testConfig.Ffmpeg.OutputPath),
+		"outputDirExists":      dirExists(testConfig.Ffmpeg.OutputPath),
+		"resolutions":          testConfig.Ffmpeg.Resolutions,
+		"codec":                testConfig.Ffmpeg.VideoCodec,
+		"audioCodec":           testConfig.Ffmpeg.AudioCodec,
+		"preset":               testConfig.Ffmpeg.Preset,
 	})
 
 	// Check if FFmpeg paths exist
-	if _, err := os.Stat(testConfig.FFmpeg.Path); err != nil {
-		testLogger.LogError(err, fmt.Sprintf("FFmpeg executable not found at: %s", testConfig.FFmpeg.Path))
+	if _, err := os.Stat(testConfig.Ffmpeg.Path); err != nil {
+		testLogger.LogError(err, fmt.Sprintf("FFmpeg executable not found at: %s", testConfig.Ffmpeg.Path))
 	}
-	if _, err := os.Stat(testConfig.FFmpeg.ProbePath); err != nil {
-		testLogger.LogError(err, fmt.Sprintf("FFprobe executable not found at: %s", testConfig.FFmpeg.ProbePath))
+	if _, err := os.Stat(testConfig.Ffmpeg.ProbePath); err != nil {
+		testLogger.LogError(err, fmt.Sprintf("FFprobe executable not found at: %s", testConfig.Ffmpeg.ProbePath))
 	}
 
 	// Ensure output directory exists
-	outputDir := testConfig.FFmpeg.OutputPath
+	outputDir := testConfig.Ffmpeg.OutputPath
 	if !filepath.IsAbs(outputDir) {
 		cwd, _ := os.Getwd()
 		testLogger.LogInfo("FFmpeg output path is relative, using working directory as base", map[string]interface{}{
@@ -339,13 +344,13 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 	}
 
 	ffmpegConfig := &ffmpeg.Config{
-		Path:        testConfig.FFmpeg.Path,
-		ProbePath:   testConfig.FFmpeg.ProbePath,
-		VideoCodec:  testConfig.FFmpeg.VideoCodec,
-		AudioCodec:  testConfig.FFmpeg.AudioCodec,
-		Preset:      testConfig.FFmpeg.Preset,
-		OutputPath:  testConfig.FFmpeg.OutputPath,
-		Resolutions: testConfig.FFmpeg.Resolutions,
+		Path:        testConfig.Ffmpeg.Path,
+		ProbePath:   testConfig.Ffmpeg.ProbePath,
+		VideoCodec:  testConfig.Ffmpeg.VideoCodec,
+		AudioCodec:  testConfig.Ffmpeg.AudioCodec,
+		Preset:      testConfig.Ffmpeg.Preset,
+		OutputPath:  testConfig.Ffmpeg.OutputPath,
+		Resolutions: testConfig.Ffmpeg.Resolutions,
 	}
 	ffmpegService := ffmpeg.NewService(ffmpegConfig, testLogger)
 
@@ -383,13 +388,45 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 		}
 	}
 
+	// Create IPFS replication queue; uploads are pinned in the background
+	replicationQueue := video.NewReplicationQueue(
+		video.NewGormReplicationStore(db),
+		ipfsAdapter,
+		storageBackend,
+		nil,
+		video.ReplicationConfig{Workers: 1, QueueSize: 10, MaxRetries: 1},
+		video.NewLoggerAdapter(testLogger),
+	)
+	replicationQueue.Start()
+	t.Cleanup(replicationQueue.Stop)
+
+	transcodeScheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{Workers: 2}, nil)
+	transcodeScheduler.Start()
+	t.Cleanup(transcodeScheduler.Stop)
+
+	videoLimits := video.LimitsConfig{
+		MaxFileSize:    testConfig.Video.MaxSize,
+		MinTitleLength: testConfig.Video.MinTitleLength,
+		MaxTitleLength: testConfig.Video.MaxTitleLength,
+		MaxDescLength:  testConfig.Video.MaxDescLength,
+		AllowedFormats: testConfig.Video.AllowedFormats,
+	}
+
 	// Create video service with real dependencies
 	videoService := video.NewVideoService(
-		db,
-		ipfsAdapter,
-		s3Service,
+		video.NewGormVideoRepository(db),
+		replicationQueue,
+		storageBackend,
 		ffmpegService,
+		videoLimits,
+		nil, // Uploads are not scanned
 		tempManager,
+		video.NewJobTracker(),
+		transcodeScheduler,
+		video.NewPurger(db, storageBackend, nil),
+		nil, // Videos are not cached
+		nil, // Files are not served through a CDN
+		nil, // Upload progress is not streamed
 		video.NewLoggerAdapter(testLogger),
 	)
 
@@ -405,34 +442,22 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 		return auth.AuthMiddleware(authService, responseHandler)
 	}
 
-	// Setup routes
+	// Setup routes at the paths SetupRoutes serves them
 	apiGroup := router.Group("/api/v1")
-	videoRoutes := apiGroup.Group("/videos")
+	videoRoutes := apiGroup.Group("")
 	videoRoutes.Use(authMiddleware())
 
 	// Create video app
 	videoConfig := &video.Config{
-		Video: struct {
-			MaxFileSize    int64    `yaml:"max_file_size"`
-			MinTitleLength int      `yaml:"min_title_length"`
-			MaxTitleLength int      `yaml:"max_title_length"`
-			MaxDescLength  int      `yaml:"max_desc_length"`
-			AllowedFormats []string `yaml:"allowed_formats"`
-		}{
-			MaxFileSize:    testConfig.Video.MaxSize,
-			MinTitleLength: testConfig.Video.MinTitleLength,
-			MaxTitleLength: testConfig.Video.MaxTitleLength,
-			MaxDescLength:  testConfig.Video.MaxDescLength,
-			AllowedFormats: testConfig.Video.AllowedFormats,
-		},
+		Video: videoLimits,
 		FFmpeg: video.FfmpegConfig{
-			Path:        testConfig.FFmpeg.Path,
-			ProbePath:   testConfig.FFmpeg.ProbePath,
-			VideoCodec:  testConfig.FFmpeg.VideoCodec,
-			AudioCodec:  testConfig.FFmpeg.AudioCodec,
-			Preset:      testConfig.FFmpeg.Preset,
-			OutputPath:  testConfig.FFmpeg.OutputPath,
-			Resolutions: testConfig.FFmpeg.Resolutions,
+			Path:        testConfig.Ffmpeg.Path,
+			ProbePath:   testConfig.Ffmpeg.ProbePath,
+			VideoCodec:  testConfig.Ffmpeg.VideoCodec,
+			AudioCodec:  testConfig.Ffmpeg.AudioCodec,
+			Preset:      testConfig.Ffmpeg.Preset,
+			OutputPath:  testConfig.Ffmpeg.OutputPath,
+			Resolutions: testConfig.Ffmpeg.Resolutions,
 		},
 	}
 
@@ -446,12 +471,12 @@ func setupTestEnvironment(t *testing.T) (*gin.Engine, video.VideoService, *auth.
 
 	// Register video routes
 	videoHandler := video.NewVideoHandler(videoApp)
-	videoRoutes.GET("", videoHandler.ListVideos)
-	videoRoutes.GET("/:id", videoHandler.GetVideo)
-	videoRoutes.GET("/:id/status", videoHandler.GetVideoStatus)
-	videoRoutes.POST("/upload", videoHandler.HandleUpload)
-	videoRoutes.PUT("/:id", videoHandler.UpdateVideo)
-	videoRoutes.DELETE("/:id", videoHandler.DeleteVideo)
+	videoRoutes.GET("/videos", videoHandler.ListVideos)
+	videoRoutes.GET("/video/:id", videoHandler.GetVideo)
+	videoRoutes.GET("/video/:id/status", videoHandler.GetVideoStatus)
+	videoRoutes.POST("/video/upload", videoHandler.HandleUpload)
+	videoRoutes.PATCH("/video/:id", videoHandler.UpdateVideo)
+	videoRoutes.DELETE("/video/:id", videoHandler.DeleteVideo)
 
 	return router, videoService, authService
 }
@@ -478,7 +503,7 @@ func createTestUser(t *testing.T, authService *auth.Service) string {
 	require.NoError(t, err, "Failed to mark email as verified")
 
 	// Login user
-	loginResp, err := authService.Login(email, "Pass123!")
+	loginResp, err := authService.Login(email, "Pass123!", auth.ClientInfo{})
 	require.NoError(t, err, "Failed to login test user")
 	require.NotEmpty(t, loginResp.AccessToken, "Access token should not be empty")
 
@@ -559,17 +584,12 @@ func TestVideoLifecycle(t *testing.T) {
 		t.Skip("Skipping E2E test: E2E_TEST environment variable not set to true")
 	}
 
-	// Load environment variables from .env.test file before checking for S3 credentials
+	// Load environment variables from .env.test file; without S3 credentials local storage is used
 	_, err := testhelper.LoadTestConfig()
 	if err != nil {
 		t.Logf("Warning: Failed to load test configuration: %v", err)
 	}
 
-	// Skip test if no S3 credentials
-	if os.Getenv("S3_ACCESS_KEY_ID") == "" || os.Getenv("S3_SECRET_ACCESS_KEY") == "" {
-		t.Skip("Skipping test: S3 credentials not set in environment variables")
-	}
-
 	// Create an alternate directory for FFmpeg output
 	altOutputDir, err := os.MkdirTemp("", "ffmpeg-test-output")
 	if err != nil {
@@ -609,7 +629,7 @@ func TestVideoLifecycle(t *testing.T) {
 		t.Logf("S3 Endpoint: %s", testConfig.Storage.S3.Endpoint)
 		t.Logf("S3 Bucket: %s", testConfig.Storage.S3.Bucket)
 		t.Logf("Temp Dir: %s", testConfig.Storage.TempDir)
-		t.Logf("FFmpeg Output Path: %s", testConfig.FFmpeg.OutputPath)
+		t.Logf("FFmpeg Output Path: %s", testConfig.Ffmpeg.OutputPath)
 
 		// Get the test video file path
 		videoFilePath := getTestVideoPath(t)
@@ -656,7 +676,7 @@ func TestVideoLifecycle(t *testing.T) {
 		require.NoError(t, err, "Failed to close writer")
 
 		// Create request
-		req, err := http.NewRequest("POST", "/api/v1/videos/upload", body)
+		req, err := http.NewRequest("POST", "/api/v1/video/upload", body)
 		require.NoError(t, err, "Failed to create request")
 
 		// Set content type and authorization headers
@@ -697,7 +717,7 @@ func TestVideoLifecycle(t *testing.T) {
 	// Test: Get video by ID
 	t.Run("Get Video", func(t *testing.T) {
 		// Create request
-		req, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/videos/%s", testVideo.ID), nil)
+		req, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/video/%s", testVideo.ID), nil)
 		require.NoError(t, err, "Failed to create request")
 
 		// Set authorization header
@@ -810,7 +830,7 @@ func TestVideoLifecycle(t *testing.T) {
 		require.NoError(t, err, "Failed to marshal update payload")
 
 		// Create request
-		req, err := http.NewRequest("PUT", fmt.Sprintf("/api/v1/videos/%s", testVideo.ID), bytes.NewBuffer(payloadBytes))
+		req, err := http.NewRequest("PATCH", fmt.Sprintf("/api/v1/video/%s", testVideo.ID), bytes.NewBuffer(payloadBytes))
 		require.NoError(t, err, "Failed to create request")
 
 		// Set headers
@@ -834,7 +854,7 @@ func TestVideoLifecycle(t *testing.T) {
 		assert.Nil(t, response.Error, "Error should be nil")
 
 		// Verify video was updated in database
-		updatedVideo, err := videoService.GetVideo(testVideo.ID)
+		updatedVideo, err := videoService.GetVideo(context.Background(), testVideo.ID)
 		require.NoError(t, err, "Failed to get updated video")
 		assert.Equal(t, "Updated Test Video", updatedVideo.Title, "Video title should be updated")
 		assert.Equal(t, "This is an updated test video", updatedVideo.Description, "Video description should be updated")
@@ -843,7 +863,7 @@ func TestVideoLifecycle(t *testing.T) {
 	// Test: Delete video
 	t.Run("Delete Video", func(t *testing.T) {
 		// Create request
-		req, err := http.NewRequest("DELETE", fmt.Sprintf("/api/v1/videos/%s", testVideo.ID), nil)
+		req, err := http.NewRequest("DELETE", fmt.Sprintf("/api/v1/video/%s", testVideo.ID), nil)
 		require.NoError(t, err, "Failed to create request")
 
 		// Set authorization header
@@ -870,9 +890,9 @@ func TestVideoLifecycle(t *testing.T) {
 		assert.Nil(t, response.Error, "Error should be nil")
 
 		// Verify video is not found after soft delete
-		video, err := videoService.GetVideo(testVideo.ID)
-		assert.Nil(t, video, "Video should not be found after soft delete")
-		assert.Error(t, err, "Error should be returned when trying to get a soft-deleted video")
+		deletedVideo, err := videoService.GetVideo(context.Background(), testVideo.ID)
+		assert.Nil(t, deletedVideo, "Video should not be found after soft delete")
+		assert.ErrorIs(t, err, video.ErrVideoDeleted, "ErrVideoDeleted should be returned when trying to get a soft-deleted video")
 	})
 }
 
@@ -889,6 +909,10 @@ func TestBasicEnvironment(t *testing.T) {
 
 	// Check S3 credentials
 	t.Run("S3 Credentials", func(t *testing.T) {
+		if testConfig.Storage.S3.AccessKeyID == "" && os.Getenv("S3_ACCESS_KEY_ID") == "" {
+			t.Skip("No S3 credentials; the video tests use local storage")
+		}
+
 		// Verify S3 credentials are set
 		t.Logf("S3 Access Key Present: %v", testConfig.Storage.S3.AccessKeyID != "")
 		t.Logf("S3 Secret Key Present: %v", testConfig.Storage.S3.SecretAccessKey != "")
@@ -910,9 +934,9 @@ func TestBasicEnvironment(t *testing.T) {
 	// Check FFmpeg paths
 	t.Run("FFmpeg Configuration", func(t *testing.T) {
 		// Verify FFmpeg executables exist
-		ffmpegPath := testConfig.FFmpeg.Path
-		ffprobePath := testConfig.FFmpeg.ProbePath
-		outputPath := testConfig.FFmpeg.OutputPath
+		ffmpegPath := testConfig.Ffmpeg.Path
+		ffprobePath := testConfig.Ffmpeg.ProbePath
+		outputPath := testConfig.Ffmpeg.OutputPath
 
 		t.Logf("FFmpeg Path: %s", ffmpegPath)
 		t.Logf("FFprobe Path: %s", ffprobePath)
@@ -960,7 +984,7 @@ func TestVideoErrorCases(t *testing.T) {
 	t.Run("Get Non-existent Video", func(t *testing.T) {
 		// Create request with random UUID
 		nonExistentID := uuid.New()
-		req, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/videos/%s", nonExistentID), nil)
+		req, err := http.NewRequest("GET", fmt.Sprintf("/api/v1/video/%s", nonExistentID), nil)
 		require.NoError(t, err, "Failed to create request")
 
 		// Set authorization header
@@ -1025,7 +1049,7 @@ func TestVideoErrorCases(t *testing.T) {
 		require.NoError(t, err, "Failed to close writer")
 
 		// Create request
-		req, err := http.NewRequest("POST", "/api/v1/videos/upload", body)
+		req, err := http.NewRequest("POST", "/api/v1/video/upload", body)
 		require.NoError(t, err, "Failed to create request")
 
 		// Set content type and authorization headers
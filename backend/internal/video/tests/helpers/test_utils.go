@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
 	"github.com/gin-gonic/gin"
@@ -89,7 +90,7 @@ func VideoConfigForTest() *video.Config {
 	config.Video.MinTitleLength = 3
 	config.Video.MaxTitleLength = 100
 	config.Video.MaxDescLength = 1000
-	config.Video.AllowedFormats = []string{"mp4", "mov", "avi"}
+	config.Video.AllowedFormats = []string{".mp4", ".mov", ".avi"}
 
 	// Set FFmpeg config
 	config.FFmpeg.Path = "/usr/bin/ffmpeg"
@@ -193,3 +194,12 @@ func SetupMockDependencies() (*mocks.MockVideoService, *mocks.MockResponseHandle
 func AuthenticateRequest(c *gin.Context) {
 	c.Request.Header.Set("Authorization", "Bearer test-token")
 }
+
+// AbortedWith returns the error a handler passed to apierror.Abort, which the
+// apierror middleware would have written, or nil when it did not abort
+func AbortedWith(c *gin.Context) *apierror.Error {
+	if len(c.Errors) == 0 {
+		return nil
+	}
+	return apierror.From(c.Errors.Last().Err, "")
+}
@@ -35,7 +35,8 @@ func TestVideoEndpoints_Integration(t *testing.T) {
 	// Set expectations for the mock services
 	for i, testVideo := range testVideos {
 		// Associate each video with its upload
-		mockVideoService.On("GetVideo", testVideo.ID).Return(&testVideo, nil)
+		mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(&testVideo, nil)
+		mockVideoService.On("RecordView", mock.Anything, testVideo.ID).Return(nil)
 
 		// Associate upload with each video
 		upload := testUploads[i]
@@ -43,8 +44,8 @@ func TestVideoEndpoints_Integration(t *testing.T) {
 	}
 
 	// Set up expectations for listing videos
-	mockVideoService.On("ListVideos", 1, 10).Return(testVideos, nil)
-	mockVideoService.On("ListVideos", 2, 1).Return([]video.Video{testVideos[2]}, nil)
+	mockVideoService.On("ListVideos", mock.Anything, 1, 10, video.ListFilter{}).Return(testVideos, int64(3), nil)
+	mockVideoService.On("ListVideos", mock.Anything, 2, 1, video.ListFilter{}).Return([]video.Video{testVideos[2]}, int64(3), nil)
 
 	// Add expectations for logger calls
 	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
@@ -87,11 +88,10 @@ func TestVideoEndpoints_Integration(t *testing.T) {
 
 	// Register video endpoints
 	videoHandler := handler
-	videoRoutes := apiGroup.Group("/videos")
 	{
-		videoRoutes.GET("", videoHandler.ListVideos)
-		videoRoutes.GET("/:id", videoHandler.GetVideo)
-		videoRoutes.GET("/:id/status", videoHandler.GetVideoStatus)
+		apiGroup.GET("/videos", videoHandler.ListVideos)
+		apiGroup.GET("/video/:id", videoHandler.GetVideo)
+		apiGroup.GET("/video/:id/status", videoHandler.GetVideoStatus)
 	}
 
 	// Mock token for auth in requests
@@ -125,7 +125,7 @@ func TestVideoEndpoints_Integration(t *testing.T) {
 
 		// Make request
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/api/v1/videos/"+targetVideo.ID.String(), nil)
+		req, _ := http.NewRequest("GET", "/api/v1/video/"+targetVideo.ID.String(), nil)
 		req.Header.Set("Authorization", "Bearer "+mockToken)
 		router.ServeHTTP(w, req)
 
@@ -141,7 +141,7 @@ func TestVideoEndpoints_Integration(t *testing.T) {
 
 		// Make request
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/api/v1/videos/"+targetVideo.ID.String()+"/status", nil)
+		req, _ := http.NewRequest("GET", "/api/v1/video/"+targetVideo.ID.String()+"/status", nil)
 		req.Header.Set("Authorization", "Bearer "+mockToken)
 		router.ServeHTTP(w, req)
 
@@ -152,23 +152,21 @@ func TestVideoEndpoints_Integration(t *testing.T) {
 	t.Run("Get Non-Existent Video", func(t *testing.T) {
 		nonExistentID := uuid.New()
 
-		// Create custom error for not found
-		notFoundErr := fmt.Errorf("Video not found")
+		notFoundErr := fmt.Errorf("%w: %s", video.ErrVideoNotFound, nonExistentID)
 
 		// Expect error when video not found
-		mockVideoService.On("GetVideo", nonExistentID).Return(nil, notFoundErr)
+		mockVideoService.On("GetVideo", mock.Anything, nonExistentID).Return(nil, notFoundErr)
 
-		// Expect error response - update to match actual implementation
-		mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusInternalServerError,
-			"DATABASE_ERROR", "Failed to retrieve video details", mock.Anything).Return()
+		// Expect a not found response
+		mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound,
+			"VIDEO_NOT_FOUND", notFoundErr.Error(), mock.Anything).Return()
 
 		// Make request
 		w := httptest.NewRecorder()
-		req, _ := http.NewRequest("GET", "/api/v1/videos/"+nonExistentID.String(), nil)
+		req, _ := http.NewRequest("GET", "/api/v1/video/"+nonExistentID.String(), nil)
 		req.Header.Set("Authorization", "Bearer "+mockToken)
 		router.ServeHTTP(w, req)
 
-		// Check response code - update to match actual implementation
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusNotFound, w.Code)
 	})
 }
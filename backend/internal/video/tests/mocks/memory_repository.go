@@ -0,0 +1,831 @@
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MemoryVideoRepository is an in-memory video.VideoRepository for tests. It
+// follows the GORM implementation: soft-deleted videos are skipped except
+// where the database queries them unscoped, and transactions are rolled back
+// when their function fails. Returned videos are copies.
+type MemoryVideoRepository struct {
+	mu       sync.Mutex
+	videos   map[uuid.UUID]*video.Video
+	tags     map[string]video.Tag
+	versions map[uuid.UUID][]video.VideoVersion
+}
+
+// NewMemoryVideoRepository creates an empty in-memory repository
+func NewMemoryVideoRepository() *MemoryVideoRepository {
+	return &MemoryVideoRepository{
+		videos:   make(map[uuid.UUID]*video.Video),
+		tags:     make(map[string]video.Tag),
+		versions: make(map[uuid.UUID][]video.VideoVersion),
+	}
+}
+
+// Transaction runs fn and restores the stored videos, tags and versions when it fails
+func (r *MemoryVideoRepository) Transaction(ctx context.Context, fn func(repo video.VideoRepository) error) error {
+	r.mu.Lock()
+	videos := make(map[uuid.UUID]*video.Video, len(r.videos))
+	for id, v := range r.videos {
+		videos[id] = copyVideo(v)
+	}
+	tags := make(map[string]video.Tag, len(r.tags))
+	for name, tag := range r.tags {
+		tags[name] = tag
+	}
+	versions := make(map[uuid.UUID][]video.VideoVersion, len(r.versions))
+	for id, list := range r.versions {
+		versions[id] = append([]video.VideoVersion(nil), list...)
+	}
+	r.mu.Unlock()
+
+	if err := fn(r); err != nil {
+		r.mu.Lock()
+		r.videos, r.tags, r.versions = videos, tags, versions
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Create stores a copy of v with upload attached
+func (r *MemoryVideoRepository) Create(ctx context.Context, v *video.Video, upload *video.VideoUpload, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	if _, ok := r.videos[v.ID]; ok {
+		return fmt.Errorf("failed to create video record: duplicate id %s", v.ID)
+	}
+	if upload.ID == uuid.Nil {
+		upload.ID = uuid.New()
+	}
+	upload.VideoID = v.ID
+	v.Tags = r.findOrCreateTags(tags)
+
+	stored := copyVideo(v)
+	stored.Upload = copyUpload(upload)
+	r.videos[v.ID] = stored
+	return nil
+}
+
+// GetByID returns a copy of the video
+func (r *MemoryVideoRepository) GetByID(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", video.ErrVideoNotFound, videoID)
+	}
+	if v.DeletedAt.Valid {
+		return nil, fmt.Errorf("%w: %s", video.ErrVideoDeleted, videoID)
+	}
+	return copyVideo(v), nil
+}
+
+// List returns a page of listed videos in the filter's order
+func (r *MemoryVideoRepository) List(ctx context.Context, page, limit int, filter video.ListFilter) ([]video.Video, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []*video.Video
+	for _, v := range r.videos {
+		if !listed(v) || (filter.Category != "" && v.Category != filter.Category) || (filter.Tag != "" && !hasTag(v, filter.Tag)) {
+			continue
+		}
+		matched = append(matched, v)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return listedBefore(matched[i], matched[j], filter.Sort)
+	})
+
+	return pageOf(matched, page, limit), int64(len(matched)), nil
+}
+
+// Update sets the video's metadata and replaces its tags
+func (r *MemoryVideoRepository) Update(ctx context.Context, videoID uuid.UUID, title, description string, taxonomy video.Taxonomy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		v.Title = title
+		v.Description = description
+		v.Category = taxonomy.Category
+		v.UpdatedAt = time.Now()
+		v.Tags = r.findOrCreateTags(taxonomy.Tags)
+	}
+	return nil
+}
+
+// SetChecksum records the video's checksum
+func (r *MemoryVideoRepository) SetChecksum(ctx context.Context, videoID uuid.UUID, checksum string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		v.Checksum = checksum
+	}
+	return nil
+}
+
+// FindDuplicate returns the oldest completed video of the same owner with checksum
+func (r *MemoryVideoRepository) FindDuplicate(ctx context.Context, videoID uuid.UUID, checksum string) (*video.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner, ok := r.videos[videoID]
+	if !ok {
+		return nil, nil
+	}
+	var oldest *video.Video
+	for _, v := range r.videos {
+		if v.DeletedAt.Valid || v.ID == videoID || v.UserID != owner.UserID || v.Checksum != checksum {
+			continue
+		}
+		if v.Upload == nil || v.Upload.Status != video.UploadStatusCompleted {
+			continue
+		}
+		if oldest == nil || v.CreatedAt.Before(oldest.CreatedAt) {
+			oldest = v
+		}
+	}
+	if oldest == nil {
+		return nil, nil
+	}
+	return copyVideo(oldest), nil
+}
+
+// SoftDelete marks the video deleted by deletedBy
+func (r *MemoryVideoRepository) SoftDelete(ctx context.Context, videoID, deletedBy uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		v.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		v.DeletedBy = &deletedBy
+	}
+	return nil
+}
+
+// MarkPurging flags the video as purging and soft-deletes it
+func (r *MemoryVideoRepository) MarkPurging(ctx context.Context, videoID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.videos[videoID]; ok {
+		v.Purging = true
+		if !v.DeletedAt.Valid {
+			v.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+// DeleteRecords removes the video and everything stored with it
+func (r *MemoryVideoRepository) DeleteRecords(ctx context.Context, videoID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.videos, videoID)
+	delete(r.versions, videoID)
+	return nil
+}
+
+// IncrementViews counts a view
+func (r *MemoryVideoRepository) IncrementViews(ctx context.Context, videoID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		now := time.Now()
+		v.Views++
+		v.LastViewedAt = &now
+	}
+	return nil
+}
+
+// AdjustCommentCount adds delta to the comment count, stopping at zero
+func (r *MemoryVideoRepository) AdjustCommentCount(ctx context.Context, videoID uuid.UUID, delta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.videos[videoID]; ok {
+		v.CommentCount = max(v.CommentCount+int64(delta), 0)
+	}
+	return nil
+}
+
+// PopularTags counts the undeleted videos of each tag
+func (r *MemoryVideoRepository) PopularTags(ctx context.Context, limit int) ([]video.TagCount, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[string]int64)
+	for _, v := range r.videos {
+		if v.DeletedAt.Valid {
+			continue
+		}
+		for _, tag := range v.Tags {
+			counts[tag.Name]++
+		}
+	}
+	result := make([]video.TagCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, video.TagCount{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// SetUploadStatus writes the upload's status and end time
+func (r *MemoryVideoRepository) SetUploadStatus(ctx context.Context, upload *video.VideoUpload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored := r.upload(upload.ID); stored != nil {
+		stored.Status = upload.Status
+		stored.EndTime = upload.EndTime
+		stored.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// SetStoredBytes records the upload's progress
+func (r *MemoryVideoRepository) SetStoredBytes(ctx context.Context, uploadID uuid.UUID, stored int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload := r.upload(uploadID); upload != nil {
+		upload.StoredBytes = stored
+	}
+	return nil
+}
+
+// SetStage records the stage the upload has reached
+func (r *MemoryVideoRepository) SetStage(ctx context.Context, uploadID uuid.UUID, stage video.UploadStage, times video.StageTimes) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload := r.upload(uploadID); upload != nil {
+		upload.Stage = stage
+		upload.StageTimes = maps.Clone(times)
+		upload.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// SetRenditions records the status and timing of the upload's renditions
+func (r *MemoryVideoRepository) SetRenditions(ctx context.Context, uploadID uuid.UUID, statuses video.RenditionStatuses, times video.RenditionTimes) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload := r.upload(uploadID); upload != nil {
+		upload.Renditions = maps.Clone(statuses)
+		upload.RenditionTimes = maps.Clone(times)
+	}
+	return nil
+}
+
+// SetRenditionProgress records how much of each rendition has been encoded
+func (r *MemoryVideoRepository) SetRenditionProgress(ctx context.Context, uploadID uuid.UUID, progress video.RenditionProgress) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if upload := r.upload(uploadID); upload != nil {
+		upload.RenditionProgress = maps.Clone(progress)
+	}
+	return nil
+}
+
+// DeleteTranscodes removes the video's transcodes
+func (r *MemoryVideoRepository) DeleteTranscodes(ctx context.Context, videoID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v, ok := r.videos[videoID]; ok {
+		v.Transcodes = nil
+	}
+	return nil
+}
+
+// CreateTranscode assigns IDs to the transcode and its segments and stores a copy
+func (r *MemoryVideoRepository) CreateTranscode(ctx context.Context, transcode *video.Transcode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[transcode.VideoID]
+	if !ok {
+		return fmt.Errorf("failed to create transcode record: no video %s", transcode.VideoID)
+	}
+	if transcode.ID == uuid.Nil {
+		transcode.ID = uuid.New()
+	}
+	for i := range transcode.Segments {
+		if transcode.Segments[i].ID == uuid.Nil {
+			transcode.Segments[i].ID = uuid.New()
+		}
+		transcode.Segments[i].TranscodeID = transcode.ID
+	}
+	stored := *transcode
+	stored.Segments = append([]video.TranscodeSegment(nil), transcode.Segments...)
+	v.Transcodes = append(v.Transcodes, stored)
+	return nil
+}
+
+// SetOutputs records the outputs and clears RenditionsDroppedAt
+func (r *MemoryVideoRepository) SetOutputs(ctx context.Context, videoID uuid.UUID, outputs video.RenditionOutputs) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		v.AudioPath = outputs.AudioPath
+		v.PreviewPath = outputs.PreviewPath
+		v.Duration = outputs.Duration
+		v.RenditionsDroppedAt = nil
+	}
+	return nil
+}
+
+// CompleteUpload writes the outcome of processing the upload
+func (r *MemoryVideoRepository) CompleteUpload(ctx context.Context, upload *video.VideoUpload) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored := r.upload(upload.ID); stored != nil {
+		stored.Status = upload.Status
+		stored.TranscodeFailures = maps.Clone(upload.TranscodeFailures)
+		stored.Renditions = maps.Clone(upload.Renditions)
+		stored.RenditionTimes = maps.Clone(upload.RenditionTimes)
+		stored.RenditionProgress = maps.Clone(upload.RenditionProgress)
+		stored.Stage = upload.Stage
+		stored.StageTimes = maps.Clone(upload.StageTimes)
+		stored.EndTime = upload.EndTime
+		stored.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// GetFiles returns a copy of the video, deleted or not
+func (r *MemoryVideoRepository) GetFiles(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", video.ErrVideoNotFound, videoID)
+	}
+	return copyVideo(v), nil
+}
+
+// SetChapters replaces the video's chapters
+func (r *MemoryVideoRepository) SetChapters(ctx context.Context, videoID uuid.UUID, chapters video.ChapterList) error {
+	return r.update(videoID, func(v *video.Video) {
+		v.Chapters = append(video.ChapterList(nil), chapters...)
+	})
+}
+
+// SetCommentsPolicy sets the video's comments policy
+func (r *MemoryVideoRepository) SetCommentsPolicy(ctx context.Context, videoID uuid.UUID, policy video.CommentsPolicy) error {
+	return r.update(videoID, func(v *video.Video) {
+		v.CommentsPolicy = policy
+	})
+}
+
+// SetEmbeddable sets whether the video can be embedded
+func (r *MemoryVideoRepository) SetEmbeddable(ctx context.Context, videoID uuid.UUID, embeddable bool) error {
+	return r.update(videoID, func(v *video.Video) {
+		v.Embeddable = embeddable
+	})
+}
+
+// SetCountries replaces the video's country restrictions
+func (r *MemoryVideoRepository) SetCountries(ctx context.Context, videoID uuid.UUID, allowed, blocked video.CountryList) error {
+	return r.update(videoID, func(v *video.Video) {
+		v.AllowedCountries = append(video.CountryList(nil), allowed...)
+		v.BlockedCountries = append(video.CountryList(nil), blocked...)
+	})
+}
+
+// SetScanResult records the scan result, its findings one per line
+func (r *MemoryVideoRepository) SetScanResult(ctx context.Context, videoID uuid.UUID, status video.ScanStatus, scanner string, findings []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		now := time.Now()
+		v.ScanStatus = status
+		v.Scanner = scanner
+		v.ScanFindings = strings.Join(findings, "\n")
+		v.ScannedAt = &now
+	}
+	return nil
+}
+
+// ListScanResults returns a page of undeleted videos by scan status, most recently scanned first
+func (r *MemoryVideoRepository) ListScanResults(ctx context.Context, statuses []video.ScanStatus, page, limit int) ([]video.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []*video.Video
+	for _, v := range r.videos {
+		if !v.DeletedAt.Valid && slices.Contains(statuses, v.ScanStatus) {
+			matched = append(matched, v)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i].ScannedAt, matched[j].ScannedAt
+		return a != nil && (b == nil || a.After(*b))
+	})
+	return pageOf(matched, page, limit), nil
+}
+
+// SetScanStatus changes the scan status when it is one of from
+func (r *MemoryVideoRepository) SetScanStatus(ctx context.Context, videoID uuid.UUID, status video.ScanStatus, from []video.ScanStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v := r.scoped(videoID)
+	if v == nil || !slices.Contains(from, v.ScanStatus) {
+		return false, nil
+	}
+	v.ScanStatus = status
+	return true, nil
+}
+
+// TakeDown marks the video taken down, keeping an earlier takedown time
+func (r *MemoryVideoRepository) TakeDown(ctx context.Context, videoID uuid.UUID, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v := r.scoped(videoID)
+	if v == nil {
+		return fmt.Errorf("%w: %s", video.ErrVideoNotFound, videoID)
+	}
+	if v.TakenDownAt == nil {
+		now := time.Now()
+		v.TakenDownAt = &now
+	}
+	v.TakedownReason = reason
+	return nil
+}
+
+// LiftTakedown clears the video's takedown
+func (r *MemoryVideoRepository) LiftTakedown(ctx context.Context, videoID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		v.TakenDownAt = nil
+		v.TakedownReason = ""
+	}
+	return nil
+}
+
+// ResetUpload sets the upload back to pending if its status and version still match
+func (r *MemoryVideoRepository) ResetUpload(ctx context.Context, upload *video.VideoUpload, version int, startTime time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.upload(upload.ID)
+	if stored == nil || stored.Status != upload.Status || stored.Version != upload.Version {
+		return false, nil
+	}
+	stored.Status = video.UploadStatusPending
+	stored.Version = version
+	stored.StartTime = startTime
+	stored.EndTime = nil
+	stored.StoredBytes = 0
+	stored.TranscodeFailures = nil
+	stored.Renditions = nil
+	stored.UpdatedAt = startTime
+	return true, nil
+}
+
+// CreateVersion stores a copy of the version
+func (r *MemoryVideoRepository) CreateVersion(ctx context.Context, version *video.VideoVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if version.ID == uuid.Nil {
+		version.ID = uuid.New()
+	}
+	for _, existing := range r.versions[version.VideoID] {
+		if existing.Version == version.Version {
+			return fmt.Errorf("failed to record version: duplicate version %d", version.Version)
+		}
+	}
+	r.versions[version.VideoID] = append(r.versions[version.VideoID], *version)
+	return nil
+}
+
+// SetReplacedFile records the new file's size and clears its replication
+func (r *MemoryVideoRepository) SetReplacedFile(ctx context.Context, videoID uuid.UUID, size int64) error {
+	return r.update(videoID, func(v *video.Video) {
+		v.FileSize = size
+		v.IPFSCID = ""
+		v.Replication = video.ReplicationS3Only
+		v.StorageClass = ""
+	})
+}
+
+// ListVersions returns copies of the video's versions, newest first
+func (r *MemoryVideoRepository) ListVersions(ctx context.Context, videoID uuid.UUID) ([]video.VideoVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := append([]video.VideoVersion(nil), r.versions[videoID]...)
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version > versions[j].Version
+	})
+	return versions, nil
+}
+
+// ListTrashed returns a page of the user's restorable videos, most recently deleted first
+func (r *MemoryVideoRepository) ListTrashed(ctx context.Context, userID uuid.UUID, deletedSince time.Time, page, limit int) ([]video.Video, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []*video.Video
+	for _, v := range r.videos {
+		if v.UserID == userID && restorable(v, deletedSince) {
+			matched = append(matched, v)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].DeletedAt.Time.After(matched[j].DeletedAt.Time)
+	})
+	return pageOf(matched, page, limit), int64(len(matched)), nil
+}
+
+// GetTrashed returns a copy of a deleted video
+func (r *MemoryVideoRepository) GetTrashed(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok || !v.DeletedAt.Valid {
+		return nil, fmt.Errorf("%w: %s", video.ErrVideoNotFound, videoID)
+	}
+	return copyVideo(v), nil
+}
+
+// Undelete restores a restorable video
+func (r *MemoryVideoRepository) Undelete(ctx context.Context, videoID uuid.UUID, deletedSince time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[videoID]
+	if !ok || !restorable(v, deletedSince) {
+		return false, nil
+	}
+	v.DeletedAt = gorm.DeletedAt{}
+	v.DeletedBy = nil
+	v.UpdatedAt = time.Now()
+	return true, nil
+}
+
+// SaveCaption stores the track, replacing the video's track in the same language
+func (r *MemoryVideoRepository) SaveCaption(ctx context.Context, caption *video.VideoCaption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v, ok := r.videos[caption.VideoID]
+	if !ok {
+		return fmt.Errorf("failed to save caption track: no video %s", caption.VideoID)
+	}
+	now := time.Now()
+	v.UpdatedAt = now
+	caption.UpdatedAt = now
+	for i := range v.Captions {
+		if v.Captions[i].Language == caption.Language {
+			caption.ID, caption.CreatedAt = v.Captions[i].ID, v.Captions[i].CreatedAt
+			v.Captions[i] = *caption
+			return nil
+		}
+	}
+	caption.ID, caption.CreatedAt = uuid.New(), now
+	v.Captions = append(v.Captions, *caption)
+	sort.Slice(v.Captions, func(i, j int) bool {
+		return v.Captions[i].Language < v.Captions[j].Language
+	})
+	return nil
+}
+
+// SharedTags counts the tags each other video shares with the video, deleted videos included
+func (r *MemoryVideoRepository) SharedTags(ctx context.Context, videoID uuid.UUID, limit int) ([]video.TagOverlap, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	this, ok := r.videos[videoID]
+	if !ok {
+		return nil, nil
+	}
+	var shared []video.TagOverlap
+	for _, v := range r.videos {
+		if v.ID == videoID {
+			continue
+		}
+		count := 0
+		for _, tag := range this.Tags {
+			if hasTag(v, tag.Name) {
+				count++
+			}
+		}
+		if count > 0 {
+			shared = append(shared, video.TagOverlap{VideoID: v.ID, Shared: count})
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		return shared[i].Shared > shared[j].Shared
+	})
+	if len(shared) > limit {
+		shared = shared[:limit]
+	}
+	return shared, nil
+}
+
+// ChannelVideos returns the IDs of the user's most viewed undeleted videos
+func (r *MemoryVideoRepository) ChannelVideos(ctx context.Context, userID, exclude uuid.UUID, limit int) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matched []*video.Video
+	for _, v := range r.videos {
+		if v.UserID == userID && v.ID != exclude && !v.DeletedAt.Valid {
+			matched = append(matched, v)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].Views > matched[j].Views
+	})
+	ids := make([]uuid.UUID, 0, min(len(matched), limit))
+	for i := 0; i < len(matched) && i < limit; i++ {
+		ids = append(ids, matched[i].ID)
+	}
+	return ids, nil
+}
+
+// ListListed returns copies of the listed videos among ids
+func (r *MemoryVideoRepository) ListListed(ctx context.Context, ids []uuid.UUID) ([]video.Video, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var videos []video.Video
+	for _, id := range ids {
+		if v, ok := r.videos[id]; ok && listed(v) {
+			videos = append(videos, *copyVideo(v))
+		}
+	}
+	return videos, nil
+}
+
+// update applies fn to the stored video unless it is missing or soft-deleted,
+// and bumps its updated_at
+func (r *MemoryVideoRepository) update(videoID uuid.UUID, fn func(v *video.Video)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if v := r.scoped(videoID); v != nil {
+		fn(v)
+		v.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+// scoped returns the stored video unless it is missing or soft-deleted; the caller holds r.mu
+func (r *MemoryVideoRepository) scoped(videoID uuid.UUID) *video.Video {
+	v, ok := r.videos[videoID]
+	if !ok || v.DeletedAt.Valid {
+		return nil
+	}
+	return v
+}
+
+// upload returns the stored upload with the given ID; the caller holds r.mu
+func (r *MemoryVideoRepository) upload(uploadID uuid.UUID) *video.VideoUpload {
+	for _, v := range r.videos {
+		if v.Upload != nil && v.Upload.ID == uploadID {
+			return v.Upload
+		}
+	}
+	return nil
+}
+
+// findOrCreateTags returns the tags named names, creating missing ones; the caller holds r.mu
+func (r *MemoryVideoRepository) findOrCreateTags(names []string) []video.Tag {
+	tags := make([]video.Tag, 0, len(names))
+	for _, name := range names {
+		tag, ok := r.tags[name]
+		if !ok {
+			tag = video.Tag{ID: uuid.New(), Name: name, CreatedAt: time.Now()}
+			r.tags[name] = tag
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// restorable reports whether v is deleted, not being purged, and deleted since deletedSince when it is set
+func restorable(v *video.Video, deletedSince time.Time) bool {
+	return v.DeletedAt.Valid && !v.Purging && (deletedSince.IsZero() || !v.DeletedAt.Time.Before(deletedSince))
+}
+
+// pageOf returns copies of a page of videos
+func pageOf(videos []*video.Video, page, limit int) []video.Video {
+	offset := (page - 1) * limit
+	result := make([]video.Video, 0, limit)
+	for i := offset; i >= 0 && i < len(videos) && len(result) < limit; i++ {
+		result = append(result, *copyVideo(videos[i]))
+	}
+	return result
+}
+
+// listed reports whether listings show v
+func listed(v *video.Video) bool {
+	return !v.DeletedAt.Valid && v.TakenDownAt == nil &&
+		v.ScanStatus != video.ScanQuarantined && v.ScanStatus != video.ScanBlocked
+}
+
+func hasTag(v *video.Video, name string) bool {
+	for _, tag := range v.Tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// listedBefore orders videos like the ORDER BY clauses of the GORM repository
+func listedBefore(a, b *video.Video, order video.ListSort) bool {
+	switch order {
+	case video.SortOldest:
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	case video.SortMostViewed:
+		if a.Views != b.Views {
+			return a.Views > b.Views
+		}
+	case video.SortTitle:
+		if titleA, titleB := strings.ToLower(a.Title), strings.ToLower(b.Title); titleA != titleB {
+			return titleA < titleB
+		}
+		return a.ID.String() < b.ID.String()
+	}
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID.String() > b.ID.String()
+}
+
+// copyVideo copies v and the upload, transcodes, tags and captions attached to it
+func copyVideo(v *video.Video) *video.Video {
+	c := *v
+	if v.Upload != nil {
+		c.Upload = copyUpload(v.Upload)
+	}
+	c.Tags = append([]video.Tag(nil), v.Tags...)
+	c.Captions = append([]video.VideoCaption(nil), v.Captions...)
+	c.Transcodes = make([]video.Transcode, len(v.Transcodes))
+	for i, transcode := range v.Transcodes {
+		c.Transcodes[i] = transcode
+		c.Transcodes[i].Segments = append([]video.TranscodeSegment(nil), transcode.Segments...)
+	}
+	return &c
+}
+
+func copyUpload(upload *video.VideoUpload) *video.VideoUpload {
+	c := *upload
+	c.Video = nil
+	c.TranscodeFailures = maps.Clone(upload.TranscodeFailures)
+	c.Renditions = maps.Clone(upload.Renditions)
+	c.RenditionTimes = maps.Clone(upload.RenditionTimes)
+	c.RenditionProgress = maps.Clone(upload.RenditionProgress)
+	c.StageTimes = maps.Clone(upload.StageTimes)
+	return &c
+}
@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockEntitlementChecker is a mock implementation of the EntitlementChecker interface
+type MockEntitlementChecker struct {
+	mock.Mock
+}
+
+// HasAccess mocks the entitlement check
+func (m *MockEntitlementChecker) HasAccess(ctx context.Context, userID, videoID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, videoID)
+	return args.Bool(0), args.Error(1)
+}
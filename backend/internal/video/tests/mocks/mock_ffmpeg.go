@@ -50,6 +50,11 @@ func (m *MockTempFileManager) GetActiveDirs() []string {
 	return args.Get(0).([]string)
 }
 
+func (m *MockTempFileManager) CheckSpace(size int64) error {
+	args := m.Called(size)
+	return args.Error(0)
+}
+
 func (m *MockTempFileManager) IsManaged(dirPath string) bool {
 	args := m.Called(dirPath)
 	return args.Bool(0)
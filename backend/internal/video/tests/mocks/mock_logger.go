@@ -10,6 +10,11 @@ type MockLogger struct {
 	mock.Mock
 }
 
+// LogDebug implements the Logger interface
+func (m *MockLogger) LogDebug(message string, fields map[string]interface{}) {
+	m.Called(message, fields)
+}
+
 // LogInfo implements the Logger interface
 func (m *MockLogger) LogInfo(message string, fields map[string]interface{}) {
 	m.Called(message, fields)
@@ -25,6 +30,11 @@ type TestLoggerAdapter struct {
 	Logger *testhelper.TestLogger
 }
 
+// LogDebug passes debug messages to the test logger
+func (a *TestLoggerAdapter) LogDebug(message string, fields map[string]interface{}) {
+	a.Logger.LogDebug(message, fields)
+}
+
 // LogInfo adapts the testhelper logger's LogInfo method to match our interface
 func (a *TestLoggerAdapter) LogInfo(message string, fields map[string]interface{}) {
 	// Pass the message and fields to the test logger
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"io"
 	"mime/multipart"
 
@@ -14,42 +15,129 @@ type MockVideoService struct {
 	mock.Mock
 }
 
-func (m *MockVideoService) InitializeUpload(title, description string, size int64) (*video.VideoUpload, error) {
-	args := m.Called(title, description, size)
+func (m *MockVideoService) InitializeUpload(ctx context.Context, userID uuid.UUID, title, description string, size int64, taxonomy video.Taxonomy) (*video.VideoUpload, error) {
+	args := m.Called(ctx, userID, title, description, size, taxonomy)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*video.VideoUpload), args.Error(1)
 }
 
-func (m *MockVideoService) ProcessUpload(upload *video.VideoUpload, file multipart.File, header *multipart.FileHeader) error {
-	args := m.Called(upload, file, header)
+func (m *MockVideoService) ProcessUpload(ctx context.Context, upload *video.VideoUpload, file multipart.File, header *multipart.FileHeader) error {
+	args := m.Called(ctx, upload, file, header)
 	return args.Error(0)
 }
 
-func (m *MockVideoService) GetVideo(videoID uuid.UUID) (*video.Video, error) {
-	args := m.Called(videoID)
+func (m *MockVideoService) GetVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	args := m.Called(ctx, videoID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*video.Video), args.Error(1)
 }
 
-func (m *MockVideoService) ListVideos(page, limit int) ([]video.Video, error) {
-	args := m.Called(page, limit)
+func (m *MockVideoService) ListVideos(ctx context.Context, page, limit int, filter video.ListFilter) ([]video.Video, int64, error) {
+	args := m.Called(ctx, page, limit, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]video.Video), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockVideoService) RecordView(ctx context.Context, videoID uuid.UUID) error {
+	args := m.Called(ctx, videoID)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) AdjustCommentCount(ctx context.Context, videoID uuid.UUID, delta int) error {
+	args := m.Called(ctx, videoID, delta)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) DeleteVideo(ctx context.Context, videoID, deletedBy uuid.UUID) error {
+	args := m.Called(ctx, videoID, deletedBy)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) PurgeVideo(ctx context.Context, videoID uuid.UUID) error {
+	args := m.Called(ctx, videoID)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) UpdateVideo(ctx context.Context, videoID uuid.UUID, title, description string, taxonomy video.Taxonomy) error {
+	args := m.Called(ctx, videoID, title, description, taxonomy)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) PopularTags(ctx context.Context, limit int) ([]video.TagCount, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]video.TagCount), args.Error(1)
+}
+
+func (m *MockVideoService) ListScanResults(ctx context.Context, status video.ScanStatus, page, limit int) ([]video.Video, error) {
+	args := m.Called(ctx, status, page, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]video.Video), args.Error(1)
 }
 
-func (m *MockVideoService) DeleteVideo(videoID uuid.UUID) error {
-	args := m.Called(videoID)
+func (m *MockVideoService) ReleaseVideo(ctx context.Context, videoID uuid.UUID) error {
+	args := m.Called(ctx, videoID)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) TakeDownVideo(ctx context.Context, videoID uuid.UUID, reason string) error {
+	args := m.Called(ctx, videoID, reason)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) RestoreVideo(ctx context.Context, videoID uuid.UUID) error {
+	args := m.Called(ctx, videoID)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) ReplaceVideo(ctx context.Context, videoID uuid.UUID, size int64) (*video.VideoUpload, error) {
+	args := m.Called(ctx, videoID, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*video.VideoUpload), args.Error(1)
+}
+
+func (m *MockVideoService) ListVersions(ctx context.Context, videoID uuid.UUID) ([]video.VersionInfo, error) {
+	args := m.Called(ctx, videoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]video.VersionInfo), args.Error(1)
+}
+
+func (m *MockVideoService) SetCommentsPolicy(ctx context.Context, videoID uuid.UUID, policy video.CommentsPolicy) error {
+	args := m.Called(ctx, videoID, policy)
 	return args.Error(0)
 }
 
-func (m *MockVideoService) UpdateVideo(videoID uuid.UUID, title, description string) error {
-	args := m.Called(videoID, title, description)
+func (m *MockVideoService) SetEmbeddable(ctx context.Context, videoID uuid.UUID, embeddable bool) error {
+	args := m.Called(ctx, videoID, embeddable)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) SetCountries(ctx context.Context, videoID uuid.UUID, allowed, blocked video.CountryList) error {
+	args := m.Called(ctx, videoID, allowed, blocked)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) SetChapters(ctx context.Context, videoID uuid.UUID, chapters []video.Chapter) error {
+	args := m.Called(ctx, videoID, chapters)
+	return args.Error(0)
+}
+
+func (m *MockVideoService) ReprocessVideo(ctx context.Context, videoID uuid.UUID) error {
+	args := m.Called(ctx, videoID)
 	return args.Error(0)
 }
 
@@ -84,3 +172,60 @@ func (m *MockIPFSService) DownloadFile(cid string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+
+// MockWebhookPublisher is a mock implementation of WebhookPublisher
+type MockWebhookPublisher struct {
+	mock.Mock
+}
+
+func (m *MockWebhookPublisher) Publish(ctx context.Context, userID uuid.UUID, event string, data interface{}) error {
+	args := m.Called(ctx, userID, event, data)
+	return args.Error(0)
+}
+
+// MockCaptionService is a mock implementation of CaptionService
+type MockCaptionService struct {
+	mock.Mock
+}
+
+func (m *MockCaptionService) UploadCaption(ctx context.Context, v *video.Video, upload video.CaptionUpload) (*video.CaptionTrack, error) {
+	args := m.Called(ctx, v, upload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*video.CaptionTrack), args.Error(1)
+}
+
+func (m *MockCaptionService) Tracks(ctx context.Context, v *video.Video) ([]video.CaptionTrack, error) {
+	args := m.Called(ctx, v)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]video.CaptionTrack), args.Error(1)
+}
+
+// MockTrashService is a mock implementation of TrashService
+type MockTrashService struct {
+	mock.Mock
+}
+
+func (m *MockTrashService) List(ctx context.Context, userID uuid.UUID, page, limit int) (*video.TrashListResponse, error) {
+	args := m.Called(ctx, userID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*video.TrashListResponse), args.Error(1)
+}
+
+func (m *MockTrashService) Get(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	args := m.Called(ctx, videoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*video.Video), args.Error(1)
+}
+
+func (m *MockTrashService) Restore(ctx context.Context, videoID uuid.UUID) error {
+	args := m.Called(ctx, videoID)
+	return args.Error(0)
+}
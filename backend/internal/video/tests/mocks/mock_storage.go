@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,6 +24,16 @@ func (m *MockStorageService) DeleteVideo(ctx context.Context, videoID uuid.UUID)
 	return args.Error(0)
 }
 
+func (m *MockStorageService) ArchiveVideo(ctx context.Context, videoID uuid.UUID, version int) error {
+	args := m.Called(ctx, videoID, version)
+	return args.Error(0)
+}
+
+func (m *MockStorageService) DeleteVideoVersion(ctx context.Context, videoID uuid.UUID, version int) error {
+	args := m.Called(ctx, videoID, version)
+	return args.Error(0)
+}
+
 func (m *MockStorageService) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -32,3 +43,26 @@ func (m *MockStorageService) GetVideoURL(ctx context.Context, key string) (strin
 	args := m.Called(ctx, key)
 	return args.String(0), args.Error(1)
 }
+
+// MockUnpinner is a mock implementation of video.Unpinner
+type MockUnpinner struct {
+	mock.Mock
+}
+
+func (m *MockUnpinner) Unpin(ctx context.Context, cid string) error {
+	args := m.Called(ctx, cid)
+	return args.Error(0)
+}
+
+// MockStreamSource is a mock implementation of video.StreamSource
+type MockStreamSource struct {
+	mock.Mock
+}
+
+func (m *MockStreamSource) OpenVideo(ctx context.Context, key string) (videostorage.Object, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(videostorage.Object), args.Error(1)
+}
@@ -0,0 +1,234 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/caption"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+const testSRT = "1\n00:00:01,000 --> 00:00:02,500\nOlá\n"
+
+// captionContext returns a request uploading fileName with the given form fields, made by userID
+func captionContext(t *testing.T, videoID, userID uuid.UUID, fileName string, fields map[string]string) *gin.Context {
+	t.Helper()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	part.Write([]byte(testSRT))
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	require.NoError(t, writer.Close())
+
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("POST", "/video/"+videoID.String()+"/captions", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", userID.String())
+	c.Set("role", "user")
+	return c
+}
+
+// TestHandleCaptionUpload_Success tests that the owner's subtitle file is stored as a track
+func TestHandleCaptionUpload_Success(t *testing.T) {
+	owner := uuid.New()
+	testVideo := &video.Video{ID: uuid.New(), UserID: owner}
+	c := captionContext(t, testVideo.ID, owner, "legendas.srt", map[string]string{"language": "pt-BR", "label": "Português"})
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	captions := new(mocks.MockCaptionService)
+	app.Captions = captions
+	track := &video.CaptionTrack{Language: "pt-BR", Label: "Português", URL: "https://cdn.example.com/videos/x/captions/pt-BR.vtt"}
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	captions.On("UploadCaption", mock.Anything, testVideo, video.CaptionUpload{
+		Language: "pt-BR",
+		Label:    "Português",
+		Format:   caption.FormatSRT,
+		Data:     []byte(testSRT),
+	}).Return(track, nil)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, track, "Captions uploaded successfully").Return()
+
+	video.NewVideoHandler(app).HandleCaptionUpload(c)
+
+	captions.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestHandleCaptionUpload_Validation tests that unsupported files and language tags are rejected before anything is stored
+func TestHandleCaptionUpload_Validation(t *testing.T) {
+	owner := uuid.New()
+	videoID := uuid.New()
+	c := captionContext(t, videoID, owner, "subtitles.ass", map[string]string{"language": "English"})
+
+	mockVideoService, _, _, app := helpers.SetupMockDependencies()
+	captions := new(mocks.MockCaptionService)
+	app.Captions = captions
+
+	video.NewVideoHandler(app).HandleCaptionUpload(c)
+
+	mockVideoService.AssertNotCalled(t, "GetVideo", mock.Anything, mock.Anything)
+	captions.AssertNotCalled(t, "UploadCaption", mock.Anything, mock.Anything, mock.Anything)
+	apiErr := helpers.AbortedWith(c)
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status())
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "file", Message: "file must be a .srt or .vtt file of at most 2097152 bytes", Rule: "captionfile"},
+			{Field: "language", Message: "language must be a BCP 47 language tag, e.g. en or pt-BR", Rule: "captionlang"},
+		}, apiErr.Fields)
+	}
+}
+
+// TestHandleCaptionUpload_NotOwner tests that other users cannot add captions
+func TestHandleCaptionUpload_NotOwner(t *testing.T) {
+	testVideo := &video.Video{ID: uuid.New(), UserID: uuid.New()}
+	c := captionContext(t, testVideo.ID, uuid.New(), "en.srt", map[string]string{"language": "en"})
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	captions := new(mocks.MockCaptionService)
+	app.Captions = captions
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).HandleCaptionUpload(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	captions.AssertNotCalled(t, "UploadCaption", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestHandleCaptionUpload_Unavailable tests that uploads get 503 when captions are not configured
+func TestHandleCaptionUpload_Unavailable(t *testing.T) {
+	owner := uuid.New()
+	c := captionContext(t, uuid.New(), owner, "en.srt", map[string]string{"language": "en"})
+
+	_, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).HandleCaptionUpload(c)
+
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestListCaptions tests that viewers get a video's tracks
+func TestListCaptions(t *testing.T) {
+	testVideo := &video.Video{ID: uuid.New(), UserID: uuid.New(), Captions: []video.VideoCaption{{Language: "en"}}}
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/video/"+testVideo.ID.String()+"/captions", nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+	c.Set("userID", uuid.New().String())
+
+	tracks := []video.CaptionTrack{{Language: "en", Label: "English", URL: "https://cdn.example.com/en.vtt"}}
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	captions := new(mocks.MockCaptionService)
+	app.Captions = captions
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	captions.On("Tracks", mock.Anything, testVideo).Return(tracks, nil)
+	mockResponseHandler.On("SuccessResponse", mock.Anything, video.CaptionListResponse{
+		VideoID:  testVideo.ID.String(),
+		Captions: tracks,
+	}, "Captions retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListCaptions(c)
+
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestUploadCaption_InvalidFile tests that malformed subtitles are a validation error on the file
+func TestUploadCaption_InvalidFile(t *testing.T) {
+	service := video.NewCaptionService(nil, nil, nil, nil, nil, new(mocks.MockLogger))
+
+	_, err := service.UploadCaption(context.Background(), &video.Video{ID: uuid.New()}, video.CaptionUpload{
+		Language: "en",
+		Format:   caption.FormatSRT,
+		Data:     []byte("1\n00:00:05,000 --> 00:00:01,000\nBackwards\n"),
+	})
+
+	assert.ErrorIs(t, err, video.ErrInvalidCaption)
+	apiErr := apierror.From(err, "")
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status())
+	assert.Equal(t, "file", apiErr.Field)
+}
+
+// TestUploadCaption_BurnInNotReady tests that captions are only burned into processed videos
+func TestUploadCaption_BurnInNotReady(t *testing.T) {
+	service := video.NewCaptionService(nil, nil, nil, nil, nil, new(mocks.MockLogger))
+	processing := &video.Video{ID: uuid.New(), Upload: &video.VideoUpload{Status: video.UploadStatusUploading}}
+
+	_, err := service.UploadCaption(context.Background(), processing, video.CaptionUpload{
+		Language: "en",
+		Format:   caption.FormatSRT,
+		Data:     []byte(testSRT),
+		BurnIn:   true,
+	})
+
+	assert.ErrorIs(t, err, video.ErrBurnInNotReady)
+}
+
+// captionFiles stores caption files under their names
+type captionFiles map[string][]byte
+
+func (f captionFiles) UploadCaption(ctx context.Context, videoID uuid.UUID, fileName, contentType string, reader io.Reader) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	key := "videos/" + videoID.String() + "/captions/" + fileName
+	f[key] = data
+	return key, nil
+}
+
+func (f captionFiles) GetVideoURL(ctx context.Context, key string) (string, error) {
+	return "https://cdn.example.com/" + key, nil
+}
+
+func (f captionFiles) DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(f[key])), nil
+}
+
+// TestUploadCaption_ReplacesLanguage verifies uploading a track in a language
+// the video already has replaces it, and other languages are kept
+func TestUploadCaption_ReplacesLanguage(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	logger := new(mocks.MockLogger)
+	logger.On("LogInfo", "Caption track stored", mock.Anything).Return()
+	service := video.NewCaptionService(repo, captionFiles{}, nil, nil, nil, logger)
+	v := createVideo(t, repo, &video.Video{Title: "Talk"})
+
+	upload := func(language, label string) *video.CaptionTrack {
+		track, err := service.UploadCaption(ctx, v, video.CaptionUpload{Language: language, Label: label, Format: caption.FormatSRT, Data: []byte(testSRT)})
+		require.NoError(t, err)
+		return track
+	}
+	upload("pt-BR", "Português")
+	upload("en", "English")
+	track := upload("en", "English (CC)")
+	assert.Equal(t, "https://cdn.example.com/videos/"+v.ID.String()+"/captions/en.vtt", track.URL)
+
+	stored, err := repo.GetByID(ctx, v.ID)
+	require.NoError(t, err)
+	tracks, err := service.Tracks(ctx, stored)
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+	assert.Equal(t, "en", tracks[0].Language)
+	assert.Equal(t, "English (CC)", tracks[0].Label)
+	assert.Equal(t, "pt-BR", tracks[1].Language)
+}
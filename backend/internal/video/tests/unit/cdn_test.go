@@ -0,0 +1,172 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCDN serves files from cdn.test
+type fakeCDN struct {
+	signed bool
+}
+
+func (f fakeCDN) URL(key string) (string, error) {
+	return "https://cdn.test/" + key, nil
+}
+
+func (f fakeCDN) Signed() bool {
+	return f.signed
+}
+
+func TestGetVideo_PlaybackURLs(t *testing.T) {
+	dropped := time.Now()
+	tests := []struct {
+		name     string
+		modify   func(v *video.Video)
+		signed   bool
+		expected map[string]string
+	}{
+		{
+			name: "every rendition",
+			expected: map[string]string{
+				"original": "https://cdn.test/videos/v/original.mp4",
+				"720p":     "https://cdn.test/videos/v/720p.mp4",
+				"audio":    "https://cdn.test/videos/v/audio.m4a",
+				"preview":  "https://cdn.test/videos/v/preview.webp",
+			},
+		},
+		{
+			name:   "dropped renditions are left out",
+			modify: func(v *video.Video) { v.RenditionsDroppedAt = &dropped },
+			expected: map[string]string{
+				"original": "https://cdn.test/videos/v/original.mp4",
+				"audio":    "https://cdn.test/videos/v/audio.m4a",
+				"preview":  "https://cdn.test/videos/v/preview.webp",
+			},
+		},
+		{
+			name:   "gated video with unsigned URLs only gets its preview",
+			modify: func(v *video.Video) { v.RequiresEntitlement = true },
+			expected: map[string]string{
+				"preview": "https://cdn.test/videos/v/preview.webp",
+			},
+		},
+		{
+			name:   "gated video with signed URLs",
+			modify: func(v *video.Video) { v.RequiresEntitlement = true },
+			signed: true,
+			expected: map[string]string{
+				"original": "https://cdn.test/videos/v/original.mp4",
+				"720p":     "https://cdn.test/videos/v/720p.mp4",
+				"audio":    "https://cdn.test/videos/v/audio.m4a",
+				"preview":  "https://cdn.test/videos/v/preview.webp",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := helpers.SetupTestContext()
+			ownerID := uuid.New()
+			testVideo := &video.Video{
+				ID:          uuid.New(),
+				UserID:      ownerID,
+				Title:       "Test Video",
+				StoragePath: "videos/v/original.mp4",
+				AudioPath:   "videos/v/audio.m4a",
+				PreviewPath: "videos/v/preview.webp",
+				Transcodes: []video.Transcode{{
+					Format:   "mp4",
+					Segments: []video.TranscodeSegment{{StoragePath: "videos/v/720p.mp4"}},
+				}},
+			}
+			if tt.modify != nil {
+				tt.modify(testVideo)
+			}
+			c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s", testVideo.ID), nil)
+			c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+			// The owner may play gated videos
+			c.Set("userID", ownerID.String())
+
+			mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+			app.CDN = fakeCDN{signed: tt.signed}
+			mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+			mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
+			var got map[string]string
+			mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.VideoDetailsResponse) bool {
+				got = response.PlaybackURLs
+				return true
+			}), "Video details retrieved successfully").Return()
+
+			video.NewVideoHandler(app).GetVideo(c)
+
+			mockResponseHandler.AssertExpectations(t)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestGetVideo_NoPlaybackURLsWithoutCDN(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	testVideo := &video.Video{ID: uuid.New(), Title: "Test Video", StoragePath: "videos/v/original.mp4"}
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s", testVideo.ID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockVideoService.On("RecordView", mock.Anything, testVideo.ID).Return(nil).Maybe()
+	mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.VideoDetailsResponse) bool {
+		return response.PlaybackURLs == nil
+	}), "Video details retrieved successfully").Return()
+
+	video.NewVideoHandler(app).GetVideo(c)
+
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// purgedKeys records the keys purged from the CDN
+type purgedKeys struct {
+	keys []string
+}
+
+func (p *purgedKeys) Purge(ctx context.Context, keys []string) error {
+	p.keys = append(p.keys, keys...)
+	return nil
+}
+
+// TestTakeDownVideo_PurgesCDN verifies taking a video down purges every
+// file stored for it from the CDN
+func TestTakeDownVideo_PurgesCDN(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	cdn := &purgedKeys{}
+	service := video.NewVideoService(repo, nil, nil, nil, video.LimitsConfig{}, nil, nil, nil, nil, nil, nil, cdn, nil, new(mocks.MockLogger))
+
+	v := createVideo(t, repo, &video.Video{StoragePath: "videos/talk/original.mp4", PreviewPath: "videos/talk/preview.mp4"})
+	require.NoError(t, repo.CreateTranscode(ctx, &video.Transcode{
+		VideoID:  v.ID,
+		Segments: []video.TranscodeSegment{{StoragePath: "videos/talk/720p.mp4"}},
+	}))
+	require.NoError(t, repo.SaveCaption(ctx, &video.VideoCaption{VideoID: v.ID, Language: "en", StoragePath: "videos/talk/captions/en.vtt"}))
+
+	require.NoError(t, service.TakeDownVideo(ctx, v.ID, "Copyright claim"))
+	assert.ElementsMatch(t, []string{
+		"videos/talk/original.mp4",
+		"videos/talk/preview.mp4",
+		"videos/talk/720p.mp4",
+		"videos/talk/captions/en.vtt",
+	}, cdn.keys)
+}
@@ -0,0 +1,141 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+)
+
+// TestParseChapters tests reading chapter lists from descriptions
+func TestParseChapters(t *testing.T) {
+	description := "In this video:\n\n00:00 Intro\n1:30 - Setting up\n12:05 – Writing tests\n1:02:03 Outro\n\nThanks for watching!"
+
+	assert.Equal(t, []video.Chapter{
+		{Title: "Intro", Start: 0},
+		{Title: "Setting up", Start: 90},
+		{Title: "Writing tests", Start: 725},
+		{Title: "Outro", Start: 3723},
+	}, video.ParseChapters(description))
+
+	for name, description := range map[string]string{
+		"no timestamps":        "Just a description",
+		"a single timestamp":   "Skip to 2:30 for the good part",
+		"not starting at zero": "0:10 Intro\n1:00 Main part",
+		"out of order":         "0:00 Intro\n5:00 Part two\n3:00 Part one",
+		"invalid seconds":      "0:00 Intro\n1:75 Main part",
+	} {
+		assert.Nil(t, video.ParseChapters(description), name)
+	}
+}
+
+// TestValidateChapters tests that chapters must fit the video in order
+func TestValidateChapters(t *testing.T) {
+	assert.NoError(t, video.ValidateChapters([]video.Chapter{{Title: "Intro", Start: 0}, {Title: "Main", Start: 60}}, 120))
+	assert.NoError(t, video.ValidateChapters(nil, 120))
+
+	for name, chapters := range map[string][]video.Chapter{
+		"not starting at zero": {{Title: "Intro", Start: 5}},
+		"out of order":         {{Title: "Intro", Start: 0}, {Title: "B", Start: 60}, {Title: "A", Start: 30}},
+		"duplicate start":      {{Title: "Intro", Start: 0}, {Title: "Again", Start: 0}},
+		"past the end":         {{Title: "Intro", Start: 0}, {Title: "Credits", Start: 120}},
+	} {
+		assert.ErrorIs(t, video.ValidateChapters(chapters, 120), video.ErrInvalidChapters, name)
+	}
+}
+
+// TestVideoDetails_Chapters tests which chapters video details show
+func TestVideoDetails_Chapters(t *testing.T) {
+	v := &video.Video{
+		ID:          uuid.New(),
+		Description: "0:00 Intro\n2:00 Main\n9:00 Bloopers",
+		Duration:    300,
+	}
+
+	// Description chapters past the end of the file are left out
+	assert.Equal(t, []video.Chapter{{Title: "Intro", Start: 0}, {Title: "Main", Start: 120}}, v.ToVideoDetailsResponse().Chapters)
+
+	// The creator's chapters take precedence over the description
+	v.Chapters = video.ChapterList{{Title: "Start", Start: 0}}
+	assert.Equal(t, []video.Chapter{{Title: "Start", Start: 0}}, v.ToVideoDetailsResponse().Chapters)
+	assert.Equal(t, float64(300), v.ToVideoDetailsResponse().Duration)
+}
+
+// chaptersContext returns a request setting chapters with the given JSON body, made by userID
+func chaptersContext(videoID, userID uuid.UUID, body string) *gin.Context {
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("PATCH", "/video/"+videoID.String()+"/chapters", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", userID.String())
+	c.Set("role", "user")
+	return c
+}
+
+// TestUpdateChapters_Success tests that the owner can set chapters
+func TestUpdateChapters_Success(t *testing.T) {
+	owner := uuid.New()
+	testVideo := &video.Video{ID: uuid.New(), UserID: owner}
+	c := chaptersContext(testVideo.ID, owner, `{"chapters":[{"title":"Intro","start":0},{"title":"Demo","start":42.5}]}`)
+	chapters := []video.Chapter{{Title: "Intro", Start: 0}, {Title: "Demo", Start: 42.5}}
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockVideoService.On("SetChapters", mock.Anything, testVideo.ID, chapters).Return(nil)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, video.ChaptersResponse{
+		VideoID:  testVideo.ID.String(),
+		Chapters: chapters,
+	}, "Chapters updated successfully").Return()
+
+	video.NewVideoHandler(app).UpdateChapters(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestUpdateChapters_PastTheEnd tests that chapters the video is too short for are a validation error
+func TestUpdateChapters_PastTheEnd(t *testing.T) {
+	owner := uuid.New()
+	testVideo := &video.Video{ID: uuid.New(), UserID: owner}
+	c := chaptersContext(testVideo.ID, owner, `{"chapters":[{"title":"Intro","start":0},{"title":"Credits","start":600}]}`)
+	invalid := fmt.Errorf("%w: %q starts at 10:00, after the video ends at 5:00", video.ErrInvalidChapters, "Credits")
+
+	mockVideoService, _, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockVideoService.On("SetChapters", mock.Anything, testVideo.ID, mock.Anything).Return(invalid)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+
+	video.NewVideoHandler(app).UpdateChapters(c)
+
+	apiErr := helpers.AbortedWith(c)
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status())
+		assert.Equal(t, "chapters", apiErr.Field)
+		assert.Contains(t, apiErr.Message, "after the video ends")
+	}
+}
+
+// TestUpdateChapters_NotOwner tests that other users cannot set chapters
+func TestUpdateChapters_NotOwner(t *testing.T) {
+	testVideo := &video.Video{ID: uuid.New(), UserID: uuid.New()}
+	c := chaptersContext(testVideo.ID, uuid.New(), `{"chapters":[]}`)
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).UpdateChapters(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "SetChapters", mock.Anything, mock.Anything, mock.Anything)
+}
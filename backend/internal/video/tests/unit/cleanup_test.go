@@ -0,0 +1,128 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// dryRunDB returns a database that builds statements without running them
+// and the SQL of every query it builds
+func dryRunDB(t *testing.T) (*gorm.DB, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+
+	var queries []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		queries = append(queries, db.Statement.SQL.String())
+	}))
+	return db, &queries
+}
+
+// TestPurger_StorageFailureKeepsRecords verifies nothing after a failed stage runs, so the purge can be retried
+func TestPurger_StorageFailureKeepsRecords(t *testing.T) {
+	db, _ := dryRunDB(t)
+	storage := new(mocks.MockStorageService)
+	unpinner := new(mocks.MockUnpinner)
+	videoID := uuid.New()
+
+	storage.On("DeleteVideo", mock.Anything, videoID).Return(errors.New("access denied"))
+
+	err := video.NewPurger(db, storage, unpinner).Purge(context.Background(), videoID)
+
+	var purgeErr *video.PurgeError
+	require.ErrorAs(t, err, &purgeErr)
+	assert.Equal(t, video.PurgeStageStorage, purgeErr.Stage)
+	assert.Equal(t, videoID, purgeErr.VideoID)
+	storage.AssertExpectations(t)
+	unpinner.AssertNotCalled(t, "Unpin", mock.Anything, mock.Anything)
+}
+
+// TestPurger_VersionStorageFailureKeepsPins verifies a version whose files
+// could not be deleted keeps its pins and stays unpurged for the next run
+func TestPurger_VersionStorageFailureKeepsPins(t *testing.T) {
+	db, _ := dryRunDB(t)
+	storage := new(mocks.MockStorageService)
+	unpinner := new(mocks.MockUnpinner)
+
+	storage.On("DeleteVideoVersion", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("access denied"))
+
+	err := video.NewPurger(db, storage, unpinner).PurgeVersion(context.Background(), uuid.New())
+
+	var purgeErr *video.PurgeError
+	require.ErrorAs(t, err, &purgeErr)
+	assert.Equal(t, video.PurgeStageStorage, purgeErr.Stage)
+	storage.AssertExpectations(t)
+	unpinner.AssertNotCalled(t, "Unpin", mock.Anything, mock.Anything)
+}
+
+// TestOrphanCleaner_DryRunDeletesNothing verifies a dry run only looks for candidates
+func TestOrphanCleaner_DryRunDeletesNothing(t *testing.T) {
+	db, queries := dryRunDB(t)
+	storage := new(mocks.MockStorageService)
+	logger := new(mocks.MockLogger)
+	registry := prometheus.NewRegistry()
+
+	cleaner := video.NewOrphanCleaner(db, video.NewPurger(db, storage, nil), video.CleanupConfig{
+		DeletedAfter:  30 * 24 * time.Hour,
+		FailedAfter:   24 * time.Hour,
+		VersionsAfter: 30 * 24 * time.Hour,
+		BatchSize:     50,
+		DryRun:        true,
+	}, video.NewCleanupMetrics(registry), logger)
+
+	result, err := cleaner.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, result.Purged)
+	assert.Zero(t, result.Failed)
+	storage.AssertNotCalled(t, "DeleteVideo", mock.Anything, mock.Anything)
+
+	// One query per reason, oldest first and bounded by the batch size
+	require.Len(t, *queries, 3)
+	deleted, failed, replaced := (*queries)[0], (*queries)[1], (*queries)[2]
+	assert.Contains(t, deleted, "deleted_at IS NOT NULL AND deleted_at <")
+	assert.Contains(t, deleted, "ORDER BY deleted_at LIMIT")
+	assert.Contains(t, failed, "video_uploads.status IN")
+	assert.Contains(t, failed, "videos.deleted_at IS NULL")
+	assert.Contains(t, failed, "ORDER BY video_uploads.updated_at LIMIT")
+	assert.Contains(t, failed, "video_uploads.version = 1")
+	assert.Contains(t, replaced, "purged_at IS NULL AND replaced_at <")
+	assert.Contains(t, replaced, "ORDER BY replaced_at LIMIT")
+
+	count, err := testutil.GatherAndCount(registry, "pavilion_video_cleanup_last_run_timestamp_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+// TestOrphanCleaner_StopsWithoutRunning verifies Stop is safe before Start
+func TestOrphanCleaner_StopsWithoutRunning(t *testing.T) {
+	db, _ := dryRunDB(t)
+	cleaner := video.NewOrphanCleaner(db, video.NewPurger(db, new(mocks.MockStorageService), nil), video.CleanupConfig{}, nil, new(mocks.MockLogger))
+	cleaner.Stop()
+}
+
+// TestPurgeError_Message verifies purge errors name the stage and keep the cause
+func TestPurgeError_Message(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := &video.PurgeError{VideoID: uuid.New(), Stage: video.PurgeStageIPFS, Err: cause}
+	assert.True(t, strings.Contains(err.Error(), "at ipfs"))
+	assert.ErrorIs(t, err, cause)
+}
@@ -0,0 +1,48 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// countingComments records the videos it was asked to count
+type countingComments struct {
+	asked [][]uuid.UUID
+}
+
+func (c *countingComments) CountByVideos(ctx context.Context, videoIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	c.asked = append(c.asked, videoIDs)
+	return map[uuid.UUID]int{}, nil
+}
+
+// TestCommentCountReconciler_WalksVideosByID verifies videos are read in
+// batches in ID order, and that no comments are counted without videos
+func TestCommentCountReconciler_WalksVideosByID(t *testing.T) {
+	db, queries := dryRunDB(t)
+	comments := &countingComments{}
+
+	reconciler := video.NewCommentCountReconciler(db, comments, video.CommentCountConfig{BatchSize: 25}, new(mocks.MockLogger))
+	corrected, err := reconciler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, corrected)
+	assert.Empty(t, comments.asked)
+
+	require.Len(t, *queries, 1)
+	assert.Contains(t, (*queries)[0], `SELECT "id","comment_count" FROM "videos"`)
+	assert.Contains(t, (*queries)[0], "id >")
+	assert.Contains(t, (*queries)[0], "ORDER BY id LIMIT")
+
+	reconciler.Stop()
+}
+
+func TestVideoDetails_CommentCount(t *testing.T) {
+	details := (&video.Video{ID: uuid.New(), CommentCount: 42}).ToVideoDetailsResponse()
+	assert.Equal(t, int64(42), details.CommentCount)
+}
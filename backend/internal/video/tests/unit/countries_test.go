@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/geoip"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedCountry locates every client in one country
+type fixedCountry string
+
+func (f fixedCountry) Country(ip net.IP) (string, error) {
+	return string(f), nil
+}
+
+func TestAvailableIn(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed video.CountryList
+		blocked video.CountryList
+		country string
+		want    bool
+	}{
+		{name: "unrestricted", country: "DE", want: true},
+		{name: "unrestricted, unknown country", want: true},
+		{name: "allowed", allowed: video.CountryList{"CA", "US"}, country: "US", want: true},
+		{name: "not allowed", allowed: video.CountryList{"CA", "US"}, country: "DE", want: false},
+		{name: "allowed, unknown country", allowed: video.CountryList{"US"}, want: false},
+		{name: "blocked", blocked: video.CountryList{"DE"}, country: "DE", want: false},
+		{name: "not blocked", blocked: video.CountryList{"DE"}, country: "FR", want: true},
+		{name: "blocked, unknown country", blocked: video.CountryList{"DE"}, want: true},
+		{name: "allowed and blocked", allowed: video.CountryList{"DE"}, blocked: video.CountryList{"DE"}, country: "DE", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &video.Video{AllowedCountries: tt.allowed, BlockedCountries: tt.blocked}
+			assert.Equal(t, tt.want, v.AvailableIn(tt.country))
+		})
+	}
+}
+
+func TestNormalizeCountries(t *testing.T) {
+	countries, err := video.NormalizeCountries([]string{"us", " CA ", "US"})
+	require.NoError(t, err)
+	assert.Equal(t, video.CountryList{"CA", "US"}, countries)
+
+	for _, code := range []string{"", "USA", "U1", "ü"} {
+		_, err := video.NormalizeCountries([]string{code})
+		assert.ErrorIs(t, err, video.ErrInvalidCountry, code)
+	}
+}
+
+// geoRestrictedRequest prepares a GetVideo request by viewerID for a video
+// blocked in Germany, from a client located in country
+func geoRestrictedRequest(viewerID, ownerID uuid.UUID, country string) (*gin.Context, *httptest.ResponseRecorder, *video.Video) {
+	c, w := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", viewerID.String())
+	helpers.AuthenticateRequest(c)
+	if country != "" {
+		geoip.Middleware(fixedCountry(country), nil)(c)
+	}
+
+	testVideo := &video.Video{
+		ID:               videoID,
+		UserID:           ownerID,
+		Title:            "Licensed",
+		StoragePath:      "videos/licensed.mp4",
+		BlockedCountries: video.CountryList{"DE"},
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+	return c, w, testVideo
+}
+
+func TestGetVideo_GeoBlocked(t *testing.T) {
+	c, w, testVideo := geoRestrictedRequest(uuid.New(), uuid.New(), "DE")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockLogger.On("LogInfo", "Video is not available in the client's country", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusUnavailableForLegalReasons, "GEO_BLOCKED", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).GetVideo(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "RecordView", mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, w.Code)
+}
+
+func TestGetVideo_GeoRestrictionsSkipped(t *testing.T) {
+	ownerID := uuid.New()
+	tests := []struct {
+		name     string
+		viewerID uuid.UUID
+		country  string
+	}{
+		{name: "owner", viewerID: ownerID, country: "DE"},
+		{name: "other country", viewerID: uuid.New(), country: "FR"},
+		// Without GeoIP, no country is looked up and nothing is blocked
+		{name: "geoip not configured", viewerID: uuid.New()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w, testVideo := geoRestrictedRequest(tt.viewerID, ownerID, tt.country)
+
+			mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+			mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+			mockVideoService.On("RecordView", mock.Anything, testVideo.ID).Return(nil)
+			mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
+			mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video details retrieved successfully").Return()
+
+			video.NewVideoHandler(app).GetVideo(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+	}
+}
+
+func TestGetVideoSources_GeoBlocked(t *testing.T) {
+	c, w, testVideo := geoRestrictedRequest(uuid.New(), uuid.New(), "DE")
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s/sources", testVideo.ID), nil)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockLogger.On("LogInfo", "Video is not available in the client's country", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusUnavailableForLegalReasons, "GEO_BLOCKED", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).GetVideoSources(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusUnavailableForLegalReasons, w.Code)
+}
+
+// TestUpdateVideo_Countries tests that country codes are normalized, and
+// that the list left out of the request is kept
+func TestUpdateVideo_Countries(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	videoID := uuid.New()
+	ownerID := uuid.New()
+	c.Request = httptest.NewRequest("PATCH", fmt.Sprintf("/video/%s", videoID), bytes.NewBufferString(`{"allowed_countries":["us","ca","US"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", ownerID.String())
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Config = helpers.VideoConfigForTest()
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+		ID:               videoID,
+		UserID:           ownerID,
+		Title:            "Original Title",
+		BlockedCountries: video.CountryList{"DE"},
+	}, nil)
+	mockVideoService.On("SetCountries", mock.Anything, videoID, video.CountryList{"CA", "US"}, video.CountryList{"DE"}).Return(nil)
+	mockLogger.On("LogInfo", "Video updated successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video updated successfully").Return()
+
+	video.NewVideoHandler(app).UpdateVideo(c)
+
+	mockVideoService.AssertExpectations(t)
+	assert.Equal(t, 200, w.Code)
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+	"github.com/consensuslabs/pavilion-network/backend/testhelper"
+)
+
+// fakeProbe prints ffprobe output for a 60 second 1920x1080 source
+const fakeProbe = `#!/bin/sh
+cat <<'EOF'
+{
+    "streams": [
+        {
+            "index": 0,
+            "codec_type": "video",
+            "codec_name": "h264",
+            "width": 1920,
+            "height": 1080,
+            "avg_frame_rate": "30/1"
+        },
+        {
+            "index": 1,
+            "codec_type": "audio",
+            "codec_name": "aac",
+            "channels": 2
+        }
+    ],
+    "format": {
+        "format_name": "mov,mp4,m4a,3gp,3g2,mj2",
+        "duration": "60.000000",
+        "bit_rate": "5000000"
+    }
+}
+EOF
+`
+
+// fakeEncoder writes a placeholder to the output file, the last argument
+const fakeEncoder = `#!/bin/sh
+for last; do :; done
+echo encoded > "$last"
+`
+
+// newDryRunFFmpeg returns an FFmpeg service backed by the fake binaries
+func newDryRunFFmpeg(t *testing.T) *ffmpeg.Service {
+	dir := t.TempDir()
+	probePath := filepath.Join(dir, "ffprobe")
+	encoderPath := filepath.Join(dir, "ffmpeg")
+	require.NoError(t, os.WriteFile(probePath, []byte(fakeProbe), 0755))
+	require.NoError(t, os.WriteFile(encoderPath, []byte(fakeEncoder), 0755))
+
+	return ffmpeg.NewService(&ffmpeg.Config{
+		Path:          encoderPath,
+		ProbePath:     probePath,
+		VideoCodec:    "libx264",
+		AudioCodec:    "aac",
+		Preset:        "medium",
+		TimeoutFactor: 3,
+	}, testhelper.NewTestLogger(false))
+}
+
+// writeSample creates a sample file with the given name
+func writeSample(t *testing.T, name string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte("not really a video"), 0644))
+	return path
+}
+
+func TestDryRun_PlansLadder(t *testing.T) {
+	ff := newDryRunFFmpeg(t)
+	limits := video.LimitsConfig{MaxFileSize: 1 << 20, AllowedFormats: []string{".mp4"}}
+
+	report, err := video.DryRun(context.Background(), ff, limits, writeSample(t, "sample.mp4"), video.DryRunOptions{SampleSeconds: 2})
+	require.NoError(t, err)
+
+	assert.True(t, report.Valid, "checks: %+v", report.Checks)
+	require.NotNil(t, report.Source)
+	assert.Equal(t, 1920, report.Source.Width)
+	assert.Equal(t, 60.0, report.Source.Duration)
+	assert.Equal(t, "h264", report.Source.VideoCodec)
+	assert.Equal(t, "aac", report.Source.AudioCodec)
+	assert.Len(t, report.Source.Streams, 2)
+
+	// The probe followed by one transcode per rendition of the upload ladder
+	require.Len(t, report.Stages, 4)
+	assert.Equal(t, "probe", report.Stages[0].Name)
+	for i, want := range []struct {
+		resolution    string
+		width, height int
+	}{{"720p", 1280, 720}, {"480p", 854, 480}, {"360p", 640, 360}} {
+		stage := report.Stages[i+1]
+		assert.Equal(t, want.resolution, stage.Resolution)
+		assert.Equal(t, want.width, stage.Width)
+		assert.Equal(t, want.height, stage.Height)
+		assert.Equal(t, 180.0, stage.TimeoutSeconds)
+		assert.Greater(t, stage.EstimatedSeconds, 0.0)
+		assert.Empty(t, stage.Error)
+	}
+}
+
+func TestDryRun_ReportsFailedChecks(t *testing.T) {
+	ff := newDryRunFFmpeg(t)
+	limits := video.LimitsConfig{MaxFileSize: 4, AllowedFormats: []string{".mp4"}}
+
+	report, err := video.DryRun(context.Background(), ff, limits, writeSample(t, "sample.mkv"), video.DryRunOptions{
+		Resolutions: []string{"1080p", "original"},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, report.Valid)
+	failed := map[string]bool{}
+	for _, check := range report.Checks {
+		if !check.Passed {
+			failed[check.Name] = true
+			assert.NotEmpty(t, check.Message)
+		}
+	}
+	assert.Equal(t, map[string]bool{"file_size": true, "file_type": true, "resolution:1080p": true}, failed)
+
+	// Planning goes on past failures, and nothing is encoded without a sample length
+	require.Len(t, report.Stages, 3)
+	assert.NotEmpty(t, report.Stages[1].Error)
+	assert.Equal(t, 1920, report.Stages[2].Width)
+	assert.Zero(t, report.Stages[2].EstimatedSeconds)
+}
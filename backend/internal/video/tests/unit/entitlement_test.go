@@ -0,0 +1,100 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// gatedVideoRequest prepares a GetVideo request for a video that requires entitlement
+func gatedVideoRequest(viewerID, ownerID uuid.UUID) (*gin.Context, *httptest.ResponseRecorder, *video.Video) {
+	c, w := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", viewerID.String())
+	helpers.AuthenticateRequest(c)
+
+	testVideo := &video.Video{
+		ID:                  videoID,
+		UserID:              ownerID,
+		Title:               "Rental",
+		StoragePath:         "videos/rental.mp4",
+		RequiresEntitlement: true,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+	}
+	return c, w, testVideo
+}
+
+func TestGetVideo_EntitlementRequired(t *testing.T) {
+	viewerID := uuid.New()
+	c, w, testVideo := gatedVideoRequest(viewerID, uuid.New())
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	checker := new(mocks.MockEntitlementChecker)
+	app.Entitlements = checker
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	checker.On("HasAccess", mock.Anything, viewerID, testVideo.ID).Return(false, nil)
+	mockLogger.On("LogInfo", "Video requires entitlement", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusPaymentRequired, "ENTITLEMENT_REQUIRED", mock.Anything, nil).Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.GetVideo(c)
+
+	checker.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+}
+
+func TestGetVideo_EntitledViewer(t *testing.T) {
+	viewerID := uuid.New()
+	c, w, testVideo := gatedVideoRequest(viewerID, uuid.New())
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	checker := new(mocks.MockEntitlementChecker)
+	app.Entitlements = checker
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockVideoService.On("RecordView", mock.Anything, testVideo.ID).Return(nil)
+	checker.On("HasAccess", mock.Anything, viewerID, testVideo.ID).Return(true, nil)
+	mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video details retrieved successfully").Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.GetVideo(c)
+
+	checker.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetVideo_OwnerSkipsEntitlementCheck(t *testing.T) {
+	ownerID := uuid.New()
+	c, w, testVideo := gatedVideoRequest(ownerID, ownerID)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	checker := new(mocks.MockEntitlementChecker)
+	app.Entitlements = checker
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video details retrieved successfully").Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.GetVideo(c)
+
+	checker.AssertNotCalled(t, "HasAccess", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
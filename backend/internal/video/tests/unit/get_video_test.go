@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -72,7 +73,7 @@ func TestGetVideo_Success(t *testing.T) {
 	}
 
 	// Set up mock expectations
-	mockVideoService.On("GetVideo", videoID).Return(testVideo, nil)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(testVideo, nil)
 	mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
 	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video details retrieved successfully").Return()
 
@@ -136,8 +137,8 @@ func TestGetVideo_NotFound(t *testing.T) {
 	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
 
 	// Set up mock expectations for not found case
-	notFoundErr := fmt.Errorf("video not found: %s", videoID)
-	mockVideoService.On("GetVideo", videoID).Return(nil, notFoundErr)
+	notFoundErr := fmt.Errorf("%w: %s", video.ErrVideoNotFound, videoID)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(nil, notFoundErr)
 	mockLogger.On("LogInfo", "Video not found or has been deleted", mock.Anything).Return()
 	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "VIDEO_NOT_FOUND", fmt.Sprintf("video not found: %s", videoID), nil).Return()
 
@@ -154,6 +155,39 @@ func TestGetVideo_NotFound(t *testing.T) {
 	assert.Equal(t, 404, w.Code, "Should return HTTP 404 Not Found")
 }
 
+func TestGetVideo_Deleted(t *testing.T) {
+	// Setup test context
+	c, w := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	// Add authentication header
+	helpers.AuthenticateRequest(c)
+
+	// Setup mock dependencies
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	// Set up mock expectations for a soft-deleted video
+	deletedErr := fmt.Errorf("%w: %s", video.ErrVideoDeleted, videoID)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(nil, deletedErr)
+	mockLogger.On("LogInfo", "Video not found or has been deleted", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusGone, "VIDEO_DELETED", fmt.Sprintf("video has been deleted: %s", videoID), nil).Return()
+
+	// Create handler and call it
+	handler := video.NewVideoHandler(app)
+	handler.GetVideo(c)
+
+	// Verify expectations
+	mockVideoService.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+
+	// Additional assertions
+	assert.Equal(t, 410, w.Code, "Should return HTTP 410 Gone")
+}
+
 func TestGetVideo_DatabaseError(t *testing.T) {
 	// Setup test context
 	c, w := helpers.SetupTestContext()
@@ -170,7 +204,7 @@ func TestGetVideo_DatabaseError(t *testing.T) {
 
 	// Set up mock expectations for database error case
 	dbErr := fmt.Errorf("database error")
-	mockVideoService.On("GetVideo", videoID).Return(nil, dbErr)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(nil, dbErr)
 	mockLogger.On("LogInfo", "Failed to get video details", mock.Anything).Return()
 	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve video details", dbErr).Return()
 
@@ -186,3 +220,84 @@ func TestGetVideo_DatabaseError(t *testing.T) {
 	// Additional assertions
 	assert.Equal(t, 500, w.Code, "Should return HTTP 500 Internal Server Error")
 }
+
+// resumeLookup returns a fixed resume position
+type resumeLookup struct {
+	position float64
+}
+
+func (r resumeLookup) ResumePosition(ctx context.Context, userID, videoID uuid.UUID) (float64, bool, error) {
+	return r.position, true, nil
+}
+
+func TestGetVideo_ResumeAt(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", uuid.New().String())
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.History = resumeLookup{position: 754.2}
+
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{ID: videoID, Title: "Test Video"}, nil)
+	mockVideoService.On("RecordView", mock.Anything, videoID).Return(nil)
+	mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.VideoDetailsResponse) bool {
+		return response.ResumeAt != nil && *response.ResumeAt == 754.2
+	}), "Video details retrieved successfully").Return()
+
+	video.NewVideoHandler(app).GetVideo(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, 200, w.Code)
+}
+
+// TestGetVideo_CountsViews tests that views are counted for everyone but the owner
+func TestGetVideo_CountsViews(t *testing.T) {
+	ownerID := uuid.New()
+	for name, viewerID := range map[string]uuid.UUID{
+		"viewer": uuid.New(),
+		"owner":  ownerID,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, _ := helpers.SetupTestContext()
+			testVideo := &video.Video{ID: uuid.New(), UserID: ownerID, Title: "Test Video"}
+			c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s", testVideo.ID), nil)
+			c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+			c.Set("userID", viewerID.String())
+
+			mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+			mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+			mockVideoService.On("RecordView", mock.Anything, testVideo.ID).Return(nil).Maybe()
+			mockLogger.On("LogInfo", "Video details retrieved successfully", mock.Anything).Return()
+			mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video details retrieved successfully").Return()
+
+			video.NewVideoHandler(app).GetVideo(c)
+
+			if viewerID == ownerID {
+				mockVideoService.AssertNotCalled(t, "RecordView", mock.Anything, mock.Anything)
+			} else {
+				mockVideoService.AssertCalled(t, "RecordView", mock.Anything, testVideo.ID)
+			}
+		})
+	}
+}
+
+func TestVideoDetails_AudioAndPreview(t *testing.T) {
+	testVideo := &video.Video{
+		ID:          uuid.New(),
+		StoragePath: "videos/test/original.mp4",
+		AudioPath:   "videos/test/audio.m4a",
+		PreviewPath: "videos/test/preview.webp",
+	}
+
+	details := testVideo.ToVideoDetailsResponse()
+	assert.Equal(t, "videos/test/audio.m4a", details.AudioPath)
+	assert.Equal(t, "videos/test/preview.webp", details.PreviewPath)
+
+	// Viewers without access keep the preview shown in listings, but not the audio
+	details.RedactPlayback()
+	assert.Empty(t, details.AudioPath)
+	assert.Equal(t, "videos/test/preview.webp", details.PreviewPath)
+}
@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+)
+
+// TestJobTracker_DrainWaitsForJobs verifies shutdown lets running jobs finish and refuses new ones
+func TestJobTracker_DrainWaitsForJobs(t *testing.T) {
+	tracker := video.NewJobTracker()
+
+	ctx, done, err := tracker.Begin(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, tracker.Running())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		done()
+	}()
+
+	interrupted := tracker.Drain(context.Background(), time.Second)
+	assert.Equal(t, 0, interrupted)
+	assert.Equal(t, 0, tracker.Running())
+	assert.ErrorIs(t, ctx.Err(), context.Canceled, "a finished job's context is released")
+
+	_, _, err = tracker.Begin(context.Background())
+	assert.ErrorIs(t, err, video.ErrShuttingDown)
+}
+
+// TestJobTracker_DrainInterruptsSlowJobs verifies jobs still running at the timeout are cancelled
+func TestJobTracker_DrainInterruptsSlowJobs(t *testing.T) {
+	tracker := video.NewJobTracker()
+
+	ctx, done, err := tracker.Begin(context.Background())
+	require.NoError(t, err)
+
+	// The job cleans up as soon as it is cancelled
+	go func() {
+		<-ctx.Done()
+		done()
+	}()
+
+	interrupted := tracker.Drain(context.Background(), 10*time.Millisecond)
+	assert.Equal(t, 1, interrupted)
+	assert.Equal(t, 0, tracker.Running())
+}
+
+// TestJobTracker_CallerCancellation verifies a job stops when its caller's context does
+func TestJobTracker_CallerCancellation(t *testing.T) {
+	tracker := video.NewJobTracker()
+
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, done, err := tracker.Begin(parent)
+	require.NoError(t, err)
+	defer done()
+
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+
+	// Finishing twice must not unbalance the count
+	done()
+	done()
+	assert.Equal(t, 0, tracker.Running())
+}
+
+// TestJobTracker_DrainWithoutJobs verifies an idle tracker drains immediately
+func TestJobTracker_DrainWithoutJobs(t *testing.T) {
+	tracker := video.NewJobTracker()
+	assert.Equal(t, 0, tracker.Drain(context.Background(), time.Hour))
+}
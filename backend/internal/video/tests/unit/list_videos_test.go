@@ -1,10 +1,12 @@
 package unit
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -36,7 +38,7 @@ func TestListVideos_Success(t *testing.T) {
 	testVideos := helpers.SetupTestVideos(3)
 
 	// Set up mock expectations
-	mockVideoService.On("ListVideos", 1, 10).Return(testVideos, nil)
+	mockVideoService.On("ListVideos", mock.Anything, 1, 10, video.ListFilter{}).Return(testVideos, int64(3), nil)
 	mockLogger.On("LogInfo", "Videos retrieved successfully", mock.Anything).Return()
 	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Videos retrieved successfully").Return()
 
@@ -53,6 +55,31 @@ func TestListVideos_Success(t *testing.T) {
 	assert.Equal(t, 200, w.Code, "Should return HTTP 200 OK")
 }
 
+// TestListVideos_PassesRequestContext tests that the service runs under the
+// request's context, so a client disconnect cancels the query
+func TestListVideos_PassesRequestContext(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+
+	type ctxKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+	defer cancel()
+	c.Request = httptest.NewRequest("GET", "/videos?page=1&limit=10", nil).WithContext(ctx)
+	c.Set("request_id", "test-request-id")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	fromRequest := mock.MatchedBy(func(got context.Context) bool {
+		return got.Value(ctxKey{}) == "request"
+	})
+	mockVideoService.On("ListVideos", fromRequest, 1, 10, video.ListFilter{}).Return(helpers.SetupTestVideos(1), int64(1), nil)
+	mockLogger.On("LogInfo", "Videos retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Videos retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertExpectations(t)
+}
+
 // TestListVideos_InvalidParameters tests listing videos with invalid parameters
 func TestListVideos_InvalidParameters(t *testing.T) {
 	// Setup test context
@@ -108,7 +135,7 @@ func TestListVideos_DatabaseError(t *testing.T) {
 
 	// Set up mock expectations for database error
 	dbErr := fmt.Errorf("database error")
-	mockVideoService.On("ListVideos", 1, 10).Return(nil, dbErr)
+	mockVideoService.On("ListVideos", mock.Anything, 1, 10, video.ListFilter{}).Return(nil, int64(0), dbErr)
 	mockLogger.On("LogInfo", "Failed to list videos", mock.Anything).Return()
 	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve videos", dbErr).Return()
 
@@ -124,3 +151,129 @@ func TestListVideos_DatabaseError(t *testing.T) {
 	// Additional assertions
 	assert.Equal(t, 500, w.Code, "Should return HTTP 500 Internal Server Error")
 }
+
+// TestListVideos_Sort tests that a valid sort is passed to the service and the total comes from it
+func TestListVideos_Sort(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/videos?sort=most_viewed", nil)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("ListVideos", mock.Anything, 1, 10, video.ListFilter{Sort: video.SortMostViewed}).Return(helpers.SetupTestVideos(2), int64(57), nil)
+	mockLogger.On("LogInfo", "Videos retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.VideoListResponse) bool {
+		return response.Total == 57 && len(response.Videos) == 2
+	}), "Videos retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestListVideos_InvalidSort tests that unknown sort orders are rejected
+func TestListVideos_InvalidSort(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/videos?sort=random", nil)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockLogger.On("LogInfo", "Invalid sort parameter", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusBadRequest, "INVALID_PARAMETER", mock.Anything, mock.Anything).Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertNotCalled(t, "ListVideos", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// fakeRemote lists fixed remote videos, in the newest-first order
+type fakeRemote struct {
+	videos []video.VideoDetailsResponse
+	offset int
+	limit  int
+}
+
+func (f *fakeRemote) ListRemoteVideos(_ context.Context, offset, limit int, _ video.ListFilter) ([]video.VideoDetailsResponse, int64, error) {
+	f.offset, f.limit = offset, limit
+	end := offset + limit
+	if end > len(f.videos) {
+		end = len(f.videos)
+	}
+	if offset > end {
+		offset = end
+	}
+	return f.videos[offset:end], int64(len(f.videos)), nil
+}
+
+// TestListVideos_MergesRemoteVideos tests that remote videos are interleaved
+// with local ones in the listing's order and carry their origin
+func TestListVideos_MergesRemoteVideos(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/videos?page=2&limit=2", nil)
+
+	now := time.Now()
+	local := helpers.SetupTestVideos(2)
+	local[0].CreatedAt = now.Add(-time.Minute)
+	local[1].CreatedAt = now.Add(-3 * time.Minute)
+	remote := &fakeRemote{videos: []video.VideoDetailsResponse{
+		{ID: uuid.NewString(), Title: "Remote 1", CreatedAt: now, Origin: "https://peer.example.com/api/v1"},
+		{ID: uuid.NewString(), Title: "Remote 2", CreatedAt: now.Add(-2 * time.Minute), Origin: "https://peer.example.com/api/v1"},
+	}}
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Remote = remote
+	mockVideoService.On("ListVideos", mock.Anything, 1, 4, video.ListFilter{}).Return(local, int64(2), nil)
+	mockLogger.On("LogInfo", "Videos retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.VideoListResponse) bool {
+		return response.Total == 4 && len(response.Videos) == 2 &&
+			response.Videos[0].Title == "Remote 2" && response.Videos[0].Origin != "" &&
+			response.Videos[1].ID == local[1].ID.String() && response.Videos[1].Origin == ""
+	}), "Videos retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, 0, remote.offset)
+	assert.Equal(t, 4, remote.limit)
+}
+
+// TestListVideos_RemoteOrigin tests that origin=remote pages remote videos
+// without reading local ones
+func TestListVideos_RemoteOrigin(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/videos?origin=remote&page=2&limit=1", nil)
+
+	remote := &fakeRemote{videos: []video.VideoDetailsResponse{
+		{ID: uuid.NewString(), Title: "Remote 1"},
+		{ID: uuid.NewString(), Title: "Remote 2"},
+	}}
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Remote = remote
+	mockLogger.On("LogInfo", "Videos retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.VideoListResponse) bool {
+		return response.Total == 2 && len(response.Videos) == 1 && response.Videos[0].Title == "Remote 2"
+	}), "Videos retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertNotCalled(t, "ListVideos", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, 1, remote.offset)
+}
+
+// TestListVideos_InvalidOrigin tests that unknown origins are rejected
+func TestListVideos_InvalidOrigin(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/videos?origin=peer", nil)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockLogger.On("LogInfo", "Invalid origin parameter", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusBadRequest, "INVALID_PARAMETER", mock.Anything, mock.Anything).Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertNotCalled(t, "ListVideos", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
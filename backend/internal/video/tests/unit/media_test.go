@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
+)
+
+func probed(codec string, seconds float64) *ffmpeg.VideoMetadata {
+	return &ffmpeg.VideoMetadata{
+		Duration:   seconds,
+		VideoCodec: codec,
+		Streams: []ffmpeg.StreamInfo{
+			{Index: 0, Type: ffmpeg.StreamTypeVideo, Codec: codec, Width: 1280, Height: 720},
+			{Index: 1, Type: ffmpeg.StreamTypeAudio, Codec: "aac", Channels: 2},
+		},
+	}
+}
+
+func TestCheckMedia(t *testing.T) {
+	limits := video.LimitsConfig{
+		MaxDuration:        time.Hour,
+		AllowedVideoCodecs: []string{"h264", "vp9"},
+	}
+
+	assert.NoError(t, limits.CheckMedia(probed("h264", 600)))
+
+	err := limits.CheckMedia(probed("theora", 600))
+	assert.ErrorIs(t, err, video.ErrInvalidMedia)
+	assert.Contains(t, err.Error(), `"theora"`)
+
+	err = limits.CheckMedia(probed("vp9", 2*3600))
+	assert.ErrorIs(t, err, video.ErrInvalidMedia)
+	assert.Contains(t, err.Error(), "2h0m0s")
+
+	// Cover art alone is not a video
+	audioOnly := &ffmpeg.VideoMetadata{Duration: 180, Streams: []ffmpeg.StreamInfo{
+		{Type: ffmpeg.StreamTypeAudio, Codec: "mp3"},
+		{Type: ffmpeg.StreamTypeVideo, Codec: "png", AttachedPicture: true},
+	}}
+	assert.ErrorIs(t, limits.CheckMedia(audioOnly), video.ErrInvalidMedia)
+}
+
+func TestCheckMedia_NoLimits(t *testing.T) {
+	assert.NoError(t, video.LimitsConfig{}.CheckMedia(probed("theora", 10*3600)))
+}
@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// coWatchCounts serves fixed co-watch counts
+type coWatchCounts struct {
+	counts map[uuid.UUID]int
+	err    error
+}
+
+func (c coWatchCounts) CoWatched(ctx context.Context, videoID uuid.UUID) (map[uuid.UUID]int, error) {
+	return c.counts, c.err
+}
+
+// relatedList serves fixed related videos
+type relatedList []video.Video
+
+func (l relatedList) ListRelated(ctx context.Context, v *video.Video) ([]video.Video, error) {
+	return l, nil
+}
+
+// TestSignalScorer_RanksCoWatching verifies co-watch counts are ranked
+// relative to the most co-watched video
+func TestSignalScorer_RanksCoWatching(t *testing.T) {
+	most, less, least := uuid.New(), uuid.New(), uuid.New()
+	scorer := video.NewSignalScorer(mocks.NewMemoryVideoRepository(), coWatchCounts{counts: map[uuid.UUID]int{least: 1, most: 4, less: 2}}, video.DefaultRelatedWeights, new(mocks.MockLogger))
+	source := &video.Video{ID: uuid.New(), UserID: uuid.New(), Tags: []video.Tag{{Name: "go"}}}
+
+	scores, err := scorer.ScoreRelated(context.Background(), source, 2)
+	require.NoError(t, err)
+	require.Len(t, scores, 2, "limited")
+	assert.Equal(t, most, scores[0].VideoID)
+	assert.Equal(t, video.DefaultRelatedWeights.CoWatch, scores[0].Score)
+	assert.Equal(t, less, scores[1].VideoID)
+	assert.Equal(t, video.DefaultRelatedWeights.CoWatch/2, scores[1].Score)
+}
+
+// TestSignalScorer_TagsAndChannel verifies videos sharing more tags and
+// videos of the same channel rank higher, and deleted videos of the channel
+// are left out
+func TestSignalScorer_TagsAndChannel(t *testing.T) {
+	repo := mocks.NewMemoryVideoRepository()
+	channel := uuid.New()
+	source := createVideo(t, repo, &video.Video{UserID: channel, Title: "Source"}, "go", "web")
+	both := createVideo(t, repo, &video.Video{UserID: uuid.New(), Title: "Both tags"}, "go", "web")
+	one := createVideo(t, repo, &video.Video{UserID: uuid.New(), Title: "One tag"}, "go")
+	sibling := createVideo(t, repo, &video.Video{UserID: channel, Title: "Same channel"})
+	deleted := createVideo(t, repo, &video.Video{UserID: channel, Title: "Deleted"})
+	createVideo(t, repo, &video.Video{UserID: uuid.New(), Title: "Unrelated"}, "cooking")
+	require.NoError(t, repo.SoftDelete(context.Background(), deleted.ID, channel))
+
+	weights := video.RelatedWeights{Tags: 1, Channel: 0.8}
+	scorer := video.NewSignalScorer(repo, nil, weights, new(mocks.MockLogger))
+	stored, err := repo.GetByID(context.Background(), source.ID)
+	require.NoError(t, err)
+
+	scores, err := scorer.ScoreRelated(context.Background(), stored, 10)
+	require.NoError(t, err)
+	require.Len(t, scores, 3)
+	assert.Equal(t, video.RelatedScore{VideoID: both.ID, Score: 1}, scores[0])
+	assert.Equal(t, video.RelatedScore{VideoID: sibling.ID, Score: 0.8}, scores[1])
+	assert.Equal(t, video.RelatedScore{VideoID: one.ID, Score: 0.5}, scores[2])
+}
+
+// TestSignalScorer_CoWatchFailure verifies a failing watch history is logged
+// and leaves the other signals to rank
+func TestSignalScorer_CoWatchFailure(t *testing.T) {
+	logger := new(mocks.MockLogger)
+	logger.On("LogError", "Failed to look up co-watched videos", mock.Anything).Return()
+	scorer := video.NewSignalScorer(mocks.NewMemoryVideoRepository(), coWatchCounts{err: errors.New("scylla down")}, video.DefaultRelatedWeights, logger)
+
+	scores, err := scorer.ScoreRelated(context.Background(), &video.Video{ID: uuid.New()}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, scores)
+	logger.AssertExpectations(t)
+}
+
+// getRelated calls GetRelatedVideos for testVideo with query and returns the response
+func getRelated(t *testing.T, app *video.App, mockVideoService *mocks.MockVideoService, mockResponseHandler *mocks.MockResponseHandler, testVideo *video.Video, query string) video.RelatedVideosResponse {
+	t.Helper()
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/video/%s/related?%s", testVideo.ID, query), nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+	c.Set("userID", testVideo.UserID.String())
+
+	var got video.RelatedVideosResponse
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.RelatedVideosResponse) bool {
+		got = response
+		return true
+	}), "Related videos retrieved successfully").Return()
+
+	video.NewVideoHandler(app).GetRelatedVideos(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	return got
+}
+
+func TestGetRelatedVideos(t *testing.T) {
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockLogger.On("LogInfo", "Related videos retrieved successfully", mock.Anything).Return()
+	testVideo := &video.Video{ID: uuid.New(), UserID: uuid.New()}
+	gated := video.Video{ID: uuid.New(), UserID: uuid.New(), StoragePath: "videos/gated.mp4", RequiresEntitlement: true}
+	own := video.Video{ID: uuid.New(), UserID: testVideo.UserID, StoragePath: "videos/own.mp4", RequiresEntitlement: true}
+	app.Related = relatedList{gated, own, {ID: uuid.New()}}
+
+	got := getRelated(t, app, mockVideoService, mockResponseHandler, testVideo, "limit=2")
+
+	// Listed best first up to the limit, with gated videos redacted for other viewers
+	require.Len(t, got.Videos, 2)
+	assert.Equal(t, gated.ID.String(), got.Videos[0].ID)
+	assert.Empty(t, got.Videos[0].StoragePath)
+	assert.Equal(t, own.ID.String(), got.Videos[1].ID)
+	assert.Equal(t, "videos/own.mp4", got.Videos[1].StoragePath)
+}
+
+func TestGetRelatedVideos_Unavailable(t *testing.T) {
+	_, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	c, _ := helpers.SetupTestContext()
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/video/%s/related", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).GetRelatedVideos(c)
+
+	mockResponseHandler.AssertExpectations(t)
+}
@@ -0,0 +1,333 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// replicationRow is a file's replication state in fakeReplicationStore
+type replicationRow struct {
+	status   video.ReplicationStatus
+	cid      string
+	touched  time.Time
+	statuses []video.ReplicationStatus
+}
+
+// fakeReplicationStore keeps replication states in memory, claiming files
+// the way the database store does
+type fakeReplicationStore struct {
+	mu   sync.Mutex
+	rows map[video.ReplicationJob]*replicationRow
+}
+
+func newFakeReplicationStore() *fakeReplicationStore {
+	return &fakeReplicationStore{rows: make(map[video.ReplicationJob]*replicationRow)}
+}
+
+func (s *fakeReplicationStore) add(status video.ReplicationStatus, touched time.Time) video.ReplicationJob {
+	job := video.ReplicationJob{VideoID: uuid.New(), Key: "videos/" + uuid.NewString() + ".mp4"}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows[job] = &replicationRow{status: status, touched: touched}
+	return job
+}
+
+func (s *fakeReplicationStore) row(job video.ReplicationJob) replicationRow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row := *s.rows[job]
+	row.statuses = append([]video.ReplicationStatus(nil), row.statuses...)
+	return row
+}
+
+func (s *fakeReplicationStore) claimable(row *replicationRow, staleBefore time.Time) bool {
+	return row.status == video.ReplicationS3Only ||
+		(row.status == video.ReplicationReplicating && row.touched.Before(staleBefore))
+}
+
+func (s *fakeReplicationStore) Pending(ctx context.Context, staleBefore time.Time, limit int) ([]video.ReplicationJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []video.ReplicationJob
+	for job, row := range s.rows {
+		if len(jobs) < limit && s.claimable(row, staleBefore) {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (s *fakeReplicationStore) Claim(ctx context.Context, job video.ReplicationJob, staleBefore time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row := s.rows[job]
+	if !s.claimable(row, staleBefore) {
+		return false, nil
+	}
+	row.status, row.touched = video.ReplicationReplicating, time.Now()
+	row.statuses = append(row.statuses, row.status)
+	return true, nil
+}
+
+func (s *fakeReplicationStore) SetStatus(ctx context.Context, job video.ReplicationJob, status video.ReplicationStatus, cid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row := s.rows[job]
+	row.status, row.touched = status, time.Now()
+	if cid != "" {
+		row.cid = cid
+	}
+	row.statuses = append(row.statuses, status)
+	return nil
+}
+
+func (s *fakeReplicationStore) LoadVideo(ctx context.Context, videoID uuid.UUID) (*video.Video, error) {
+	return nil, errors.New("not used without peers")
+}
+
+// fakeIPFS fails the first failures adds of each file and records when each add was tried
+type fakeIPFS struct {
+	mu       sync.Mutex
+	failures int
+	attempts map[string][]time.Time
+}
+
+func newFakeIPFS(failures int) *fakeIPFS {
+	return &fakeIPFS{failures: failures, attempts: make(map[string][]time.Time)}
+}
+
+func (f *fakeIPFS) UploadFileStream(file io.Reader) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	key := string(data)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts[key] = append(f.attempts[key], time.Now())
+	if len(f.attempts[key]) <= f.failures {
+		return "", errors.New("ipfs node unavailable")
+	}
+	return "cid-" + key, nil
+}
+
+func (f *fakeIPFS) DownloadFile(cid string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (f *fakeIPFS) attemptsFor(key string) []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Time(nil), f.attempts[key]...)
+}
+
+// keySource serves each file's key as its content
+type keySource struct{}
+
+func (keySource) DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(key)), nil
+}
+
+func newReplicationLogger() *mocks.MockLogger {
+	logger := new(mocks.MockLogger)
+	logger.On("LogError", mock.Anything, mock.Anything).Return()
+	logger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	return logger
+}
+
+// TestReplicationQueue_Backpressure verifies a full queue rejects jobs instead of blocking the caller
+func TestReplicationQueue_Backpressure(t *testing.T) {
+	logger := new(mocks.MockLogger)
+	logger.On("LogError", mock.Anything, mock.Anything).Return()
+
+	// Workers are never started, so queued jobs stay queued
+	queue := video.NewReplicationQueue(nil, nil, nil, nil, video.ReplicationConfig{Workers: 1, QueueSize: 2, MaxRetries: 1}, logger)
+
+	job := video.ReplicationJob{VideoID: uuid.New()}
+	assert.NoError(t, queue.Enqueue(job))
+	assert.NoError(t, queue.Enqueue(job), "a job already waiting is not queued twice")
+	assert.NoError(t, queue.Enqueue(video.ReplicationJob{VideoID: uuid.New()}))
+	assert.ErrorIs(t, queue.Enqueue(video.ReplicationJob{VideoID: uuid.New()}), video.ErrReplicationQueueFull)
+	assert.Equal(t, 2, queue.Pending())
+
+	// A stopped queue no longer accepts work
+	queue.Stop()
+	assert.Error(t, queue.Enqueue(video.ReplicationJob{VideoID: uuid.New()}))
+}
+
+// TestReplicationQueue_RetriesWithBackoff verifies failed adds are retried,
+// each wait longer than the last, until one succeeds
+func TestReplicationQueue_RetriesWithBackoff(t *testing.T) {
+	store := newFakeReplicationStore()
+	ipfs := newFakeIPFS(2)
+	delay := 20 * time.Millisecond
+	queue := video.NewReplicationQueue(store, ipfs, keySource{}, nil,
+		video.ReplicationConfig{Workers: 1, QueueSize: 10, MaxRetries: 3, RetryDelay: delay}, newReplicationLogger())
+	job := store.add(video.ReplicationS3Only, time.Now())
+
+	queue.Start()
+	defer queue.Stop()
+
+	require.Eventually(t, func() bool {
+		return store.row(job).status == video.ReplicationReplicated
+	}, 2*time.Second, 5*time.Millisecond)
+
+	row := store.row(job)
+	assert.Equal(t, "cid-"+job.Key, row.cid)
+	assert.Equal(t, []video.ReplicationStatus{
+		video.ReplicationReplicating, // claimed
+		video.ReplicationReplicating, // second attempt
+		video.ReplicationReplicating, // third attempt
+		video.ReplicationReplicated,
+	}, row.statuses)
+
+	attempts := ipfs.attemptsFor(job.Key)
+	require.Len(t, attempts, 3)
+	assert.GreaterOrEqual(t, attempts[1].Sub(attempts[0]), delay)
+	assert.GreaterOrEqual(t, attempts[2].Sub(attempts[1]), 2*delay, "the delay grows with each attempt")
+}
+
+// TestReplicationQueue_GivesUpAfterMaxRetries verifies a file is put back to
+// S3 only once its attempts are used up
+func TestReplicationQueue_GivesUpAfterMaxRetries(t *testing.T) {
+	store := newFakeReplicationStore()
+	ipfs := newFakeIPFS(100)
+	queue := video.NewReplicationQueue(store, ipfs, keySource{}, nil,
+		video.ReplicationConfig{Workers: 1, QueueSize: 10, MaxRetries: 2, RetryDelay: time.Millisecond}, newReplicationLogger())
+	job := store.add(video.ReplicationS3Only, time.Now())
+
+	queue.Start()
+	defer queue.Stop()
+
+	require.Eventually(t, func() bool {
+		return len(store.row(job).statuses) == 3
+	}, 2*time.Second, 5*time.Millisecond)
+
+	row := store.row(job)
+	assert.Equal(t, []video.ReplicationStatus{
+		video.ReplicationReplicating,
+		video.ReplicationReplicating,
+		video.ReplicationS3Only,
+	}, row.statuses)
+	assert.Empty(t, row.cid)
+	assert.Len(t, ipfs.attemptsFor(job.Key), 2)
+}
+
+// TestReplicationQueue_Rescan verifies files left S3 only, by a full queue or
+// by retries running out, are queued again on the rescan interval
+func TestReplicationQueue_Rescan(t *testing.T) {
+	store := newFakeReplicationStore()
+	ipfs := newFakeIPFS(2)
+	queue := video.NewReplicationQueue(store, ipfs, keySource{}, nil, video.ReplicationConfig{
+		Workers:        1,
+		QueueSize:      1,
+		MaxRetries:     2,
+		RetryDelay:     time.Millisecond,
+		RescanInterval: 20 * time.Millisecond,
+	}, newReplicationLogger())
+
+	// The queue is full before the workers start, so the second upload is dropped
+	uploaded := store.add(video.ReplicationS3Only, time.Now())
+	dropped := store.add(video.ReplicationS3Only, time.Now())
+	require.NoError(t, queue.Enqueue(uploaded))
+	require.ErrorIs(t, queue.Enqueue(dropped), video.ErrReplicationQueueFull)
+
+	queue.Start()
+	defer queue.Stop()
+
+	require.Eventually(t, func() bool {
+		return store.row(uploaded).status == video.ReplicationReplicated &&
+			store.row(dropped).status == video.ReplicationReplicated
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// Both failed twice, gave up, and succeeded once picked up again
+	for _, job := range []video.ReplicationJob{uploaded, dropped} {
+		row := store.row(job)
+		assert.Contains(t, row.statuses, video.ReplicationS3Only)
+		assert.Equal(t, video.ReplicationReplicated, row.statuses[len(row.statuses)-1])
+		assert.Len(t, ipfs.attemptsFor(job.Key), 3)
+	}
+}
+
+// TestReplicationQueue_SkipsReplicating verifies rescans leave files a worker
+// is replicating alone, and pick up those it abandoned
+func TestReplicationQueue_SkipsReplicating(t *testing.T) {
+	store := newFakeReplicationStore()
+	ipfs := newFakeIPFS(0)
+	queue := video.NewReplicationQueue(store, ipfs, keySource{}, nil, video.ReplicationConfig{
+		Workers:        1,
+		QueueSize:      10,
+		MaxRetries:     1,
+		RescanInterval: 10 * time.Millisecond,
+		StaleAfter:     time.Hour,
+	}, newReplicationLogger())
+
+	active := store.add(video.ReplicationReplicating, time.Now())
+	abandoned := store.add(video.ReplicationReplicating, time.Now().Add(-2*time.Hour))
+
+	// Queued directly, a file another worker claimed is not replicated either
+	require.NoError(t, queue.Enqueue(active))
+
+	queue.Start()
+	require.Eventually(t, func() bool {
+		return store.row(abandoned).status == video.ReplicationReplicated
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// Give a few more rescans the chance to pick up the active file
+	time.Sleep(50 * time.Millisecond)
+	queue.Stop()
+
+	assert.Equal(t, video.ReplicationReplicating, store.row(active).status)
+	assert.Empty(t, store.row(active).statuses)
+	assert.Empty(t, ipfs.attemptsFor(active.Key))
+}
+
+// TestGormReplicationStore_Statements verifies only completed uploads are
+// listed and claims leave updated_at, which clients read, unchanged
+func TestGormReplicationStore_Statements(t *testing.T) {
+	db, queries := dryRunDB(t)
+	// Updates would otherwise open a transaction, which dry run cannot do
+	db = db.Session(&gorm.Session{SkipDefaultTransaction: true})
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:capture", func(db *gorm.DB) {
+		*queries = append(*queries, db.Statement.SQL.String())
+	}))
+	store := video.NewGormReplicationStore(db)
+	ctx := context.Background()
+	staleBefore := time.Now().Add(-time.Hour)
+
+	_, err := store.Pending(ctx, staleBefore, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, *queries)
+	assert.Contains(t, (*queries)[0], "JOIN video_uploads ON video_uploads.video_id = videos.id")
+	assert.Contains(t, (*queries)[0], "video_uploads.status =")
+
+	*queries = nil
+	videoJob := video.ReplicationJob{VideoID: uuid.New()}
+	segmentJob := video.ReplicationJob{VideoID: uuid.New(), SegmentID: uuid.New()}
+	for _, job := range []video.ReplicationJob{videoJob, segmentJob} {
+		_, err = store.Claim(ctx, job, staleBefore)
+		require.NoError(t, err)
+		require.NoError(t, store.SetStatus(ctx, job, video.ReplicationReplicated, "bafy"))
+	}
+
+	require.Len(t, *queries, 4)
+	for _, query := range *queries {
+		assert.Contains(t, query, "replication_updated_at")
+		assert.NotContains(t, query, `"updated_at"`)
+	}
+}
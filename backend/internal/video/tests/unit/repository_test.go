@@ -0,0 +1,242 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// newMemoryVideoService returns a video service storing videos in memory
+func newMemoryVideoService(repo video.VideoRepository) video.VideoService {
+	return video.NewVideoService(repo, nil, nil, nil, video.LimitsConfig{}, nil, nil, nil, nil, nil, nil, nil, nil, new(mocks.MockLogger))
+}
+
+// createVideo stores a video with a completed upload directly in repo
+func createVideo(t *testing.T, repo video.VideoRepository, v *video.Video, tags ...string) *video.Video {
+	t.Helper()
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	if v.CreatedAt.IsZero() {
+		v.CreatedAt = time.Now()
+	}
+	upload := &video.VideoUpload{Status: video.UploadStatusCompleted}
+	require.NoError(t, repo.Create(context.Background(), v, upload, tags))
+	return v
+}
+
+// TestVideoService_InitializeUploadStoresVideo verifies a new upload is
+// stored with its tags and can be read back
+func TestVideoService_InitializeUploadStoresVideo(t *testing.T) {
+	ctx := context.Background()
+	service := newMemoryVideoService(mocks.NewMemoryVideoRepository())
+	userID := uuid.New()
+
+	upload, err := service.InitializeUpload(ctx, userID, "Intro", "First video", 1024, video.Taxonomy{
+		Category: "education",
+		Tags:     []string{"go", "tutorial"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, video.UploadStatusPending, upload.Status)
+
+	stored, err := service.GetVideo(ctx, upload.VideoID)
+	require.NoError(t, err)
+	assert.Equal(t, userID, stored.UserID)
+	assert.Equal(t, "Intro", stored.Title)
+	assert.Equal(t, video.Category("education"), stored.Category)
+	require.NotNil(t, stored.Upload)
+	assert.Equal(t, upload.ID, stored.Upload.ID)
+	require.Len(t, stored.Tags, 2)
+	assert.Equal(t, "go", stored.Tags[0].Name)
+}
+
+// TestVideoService_ListVideosSkipsHiddenVideos verifies deleted, taken down
+// and quarantined videos stay out of listings and the filters apply
+func TestVideoService_ListVideosSkipsHiddenVideos(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	service := newMemoryVideoService(repo)
+	now := time.Now()
+
+	older := createVideo(t, repo, &video.Video{Title: "Older", Category: "music", CreatedAt: now.Add(-time.Hour)}, "live")
+	newer := createVideo(t, repo, &video.Video{Title: "Newer", Category: "music", CreatedAt: now}, "live", "jazz")
+	createVideo(t, repo, &video.Video{Title: "Taken down", TakenDownAt: &now}, "live")
+	createVideo(t, repo, &video.Video{Title: "Quarantined", ScanStatus: video.ScanQuarantined}, "live")
+	deleted := createVideo(t, repo, &video.Video{Title: "Deleted"}, "live")
+	require.NoError(t, service.DeleteVideo(ctx, deleted.ID, uuid.New()))
+
+	videos, total, err := service.ListVideos(ctx, 1, 10, video.ListFilter{Tag: "live"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, videos, 2)
+	assert.Equal(t, newer.ID, videos[0].ID)
+	assert.Equal(t, older.ID, videos[1].ID)
+
+	videos, total, err = service.ListVideos(ctx, 2, 1, video.ListFilter{Category: "music", Sort: video.SortNewest})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, videos, 1)
+	assert.Equal(t, older.ID, videos[0].ID)
+
+	require.NoError(t, service.RecordView(ctx, older.ID))
+	videos, _, err = service.ListVideos(ctx, 1, 10, video.ListFilter{Sort: video.SortMostViewed})
+	require.NoError(t, err)
+	require.NotEmpty(t, videos)
+	assert.Equal(t, older.ID, videos[0].ID)
+	assert.EqualValues(t, 1, videos[0].Views)
+}
+
+// TestVideoService_DeleteVideoHidesVideo verifies a deleted video is
+// reported as deleted, not missing, and no longer counts towards tags
+func TestVideoService_DeleteVideoHidesVideo(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	service := newMemoryVideoService(repo)
+
+	kept := createVideo(t, repo, &video.Video{Title: "Kept"}, "news")
+	deleted := createVideo(t, repo, &video.Video{Title: "Deleted"}, "news", "sports")
+
+	require.NoError(t, service.DeleteVideo(ctx, deleted.ID, uuid.New()))
+
+	_, err := service.GetVideo(ctx, deleted.ID)
+	assert.ErrorIs(t, err, video.ErrVideoDeleted)
+	_, err = service.GetVideo(ctx, uuid.New())
+	assert.ErrorIs(t, err, video.ErrVideoNotFound)
+	err = service.DeleteVideo(ctx, deleted.ID, uuid.New())
+	assert.ErrorIs(t, err, video.ErrVideoDeleted)
+
+	tags, err := service.PopularTags(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []video.TagCount{{Name: "news", Count: 1}}, tags)
+
+	_, err = service.GetVideo(ctx, kept.ID)
+	assert.NoError(t, err)
+}
+
+// TestVideoService_UpdateVideoReplacesTags verifies metadata and tags are replaced together
+func TestVideoService_UpdateVideoReplacesTags(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	service := newMemoryVideoService(repo)
+	v := createVideo(t, repo, &video.Video{Title: "Draft"}, "old")
+
+	require.NoError(t, service.UpdateVideo(ctx, v.ID, "Final", "Done", video.Taxonomy{Category: "gaming", Tags: []string{"new"}}))
+
+	updated, err := service.GetVideo(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Final", updated.Title)
+	assert.Equal(t, "Done", updated.Description)
+	assert.Equal(t, video.Category("gaming"), updated.Category)
+	require.Len(t, updated.Tags, 1)
+	assert.Equal(t, "new", updated.Tags[0].Name)
+}
+
+// TestVideoService_AdjustCommentCountStopsAtZero verifies the count never goes negative
+func TestVideoService_AdjustCommentCountStopsAtZero(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	service := newMemoryVideoService(repo)
+	v := createVideo(t, repo, &video.Video{Title: "Talk"})
+
+	require.NoError(t, service.AdjustCommentCount(ctx, v.ID, 2))
+	require.NoError(t, service.AdjustCommentCount(ctx, v.ID, -5))
+
+	stored, err := service.GetVideo(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.CommentCount)
+}
+
+// TestMemoryVideoRepository_TransactionRollsBack verifies writes made in a
+// failed transaction are undone
+func TestMemoryVideoRepository_TransactionRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	v := createVideo(t, repo, &video.Video{Title: "Clip"})
+
+	failure := errors.New("segment rejected")
+	err := repo.Transaction(ctx, func(tx video.VideoRepository) error {
+		if err := tx.CreateTranscode(ctx, &video.Transcode{VideoID: v.ID, Format: "mp4"}); err != nil {
+			return err
+		}
+		if err := tx.SetOutputs(ctx, v.ID, video.RenditionOutputs{Duration: 42}); err != nil {
+			return err
+		}
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	stored, err := repo.GetByID(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Transcodes)
+	assert.Zero(t, stored.Duration)
+}
+
+// TestGormVideoRepository_ListFilters verifies the listing query hides
+// unlisted videos and applies the tag and category filters
+func TestGormVideoRepository_ListFilters(t *testing.T) {
+	db, queries := dryRunDB(t)
+	repo := video.NewGormVideoRepository(db)
+
+	_, _, err := repo.List(context.Background(), 1, 10, video.ListFilter{Tag: "jazz", Category: "music"})
+	require.NoError(t, err)
+
+	// The tag subquery is built before the count
+	require.GreaterOrEqual(t, len(*queries), 2)
+	count := (*queries)[1]
+	assert.Contains(t, count, "SELECT count(*)")
+	assert.Contains(t, count, "videos.deleted_at IS NULL")
+	assert.Contains(t, count, "videos.scan_status NOT IN")
+	assert.Contains(t, count, "videos.taken_down_at IS NULL")
+	assert.Contains(t, count, "videos.category =")
+	assert.Contains(t, count, "tags.name =")
+}
+
+// TestGormVideoRepository_RelatedSignals verifies the queries behind the
+// related video signals
+func TestGormVideoRepository_RelatedSignals(t *testing.T) {
+	db, queries := dryRunDB(t)
+	repo := video.NewGormVideoRepository(db)
+	ctx := context.Background()
+
+	_, err := repo.SharedTags(ctx, uuid.New(), 10)
+	require.NoError(t, err)
+	_, err = repo.ChannelVideos(ctx, uuid.New(), uuid.New(), 10)
+	require.NoError(t, err)
+	_, err = repo.ListListed(ctx, []uuid.UUID{uuid.New()})
+	require.NoError(t, err)
+
+	require.GreaterOrEqual(t, len(*queries), 3)
+	assert.Contains(t, (*queries)[0], "JOIN video_tags AS this ON this.tag_id = other.tag_id")
+	assert.Contains(t, (*queries)[1], "user_id = $")
+	assert.Contains(t, (*queries)[1], "ORDER BY views DESC")
+	assert.Contains(t, (*queries)[2], "videos.taken_down_at IS NULL")
+	assert.Contains(t, (*queries)[2], "videos.id IN")
+}
+
+// TestVideoService_Settings verifies per-video settings are stored
+func TestVideoService_Settings(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	service := newMemoryVideoService(repo)
+	v := createVideo(t, repo, &video.Video{Title: "Talk", Embeddable: true})
+
+	require.NoError(t, service.SetCommentsPolicy(ctx, v.ID, video.CommentsReviewRequired))
+	require.NoError(t, service.SetEmbeddable(ctx, v.ID, false))
+	require.NoError(t, service.SetCountries(ctx, v.ID, video.CountryList{"BR", "PT"}, video.CountryList{}))
+
+	stored, err := repo.GetByID(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, video.CommentsReviewRequired, stored.CommentsPolicy)
+	assert.False(t, stored.Embeddable)
+	assert.Equal(t, video.CountryList{"BR", "PT"}, stored.AllowedCountries)
+	assert.Empty(t, stored.BlockedCountries)
+	assert.True(t, stored.UpdatedAt.After(v.UpdatedAt))
+}
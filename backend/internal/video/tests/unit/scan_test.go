@@ -0,0 +1,187 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+func quarantinedVideo(ownerID uuid.UUID) *video.Video {
+	return &video.Video{
+		ID:          uuid.New(),
+		UserID:      ownerID,
+		Title:       "Quarantined",
+		StoragePath: "videos/quarantined/original.mp4",
+		ScanStatus:  video.ScanQuarantined,
+	}
+}
+
+// TestGetVideo_QuarantinedHidden tests that quarantined videos look missing to other users
+func TestGetVideo_QuarantinedHidden(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	testVideo := quarantinedVideo(uuid.New())
+	c.Request = httptest.NewRequest("GET", "/video/"+testVideo.ID.String(), nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "user")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "VIDEO_NOT_FOUND", fmt.Sprintf("video not found: %s", testVideo.ID), nil).Return()
+
+	video.NewVideoHandler(app).GetVideo(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestGetVideo_QuarantinedVisibleToOwnerAndStaff tests that owners and staff still see quarantined videos
+func TestGetVideo_QuarantinedVisibleToOwnerAndStaff(t *testing.T) {
+	ownerID := uuid.New()
+	for name, viewer := range map[string]struct {
+		userID uuid.UUID
+		role   string
+	}{
+		"owner":     {ownerID, "user"},
+		"moderator": {uuid.New(), "moderator"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, _ := helpers.SetupTestContext()
+			testVideo := quarantinedVideo(ownerID)
+			c.Request = httptest.NewRequest("GET", "/video/"+testVideo.ID.String(), nil)
+			c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+			c.Set("userID", viewer.userID.String())
+			c.Set("role", viewer.role)
+
+			mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+			mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+			mockVideoService.On("RecordView", mock.Anything, testVideo.ID).Return(nil).Maybe()
+			mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+			mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video details retrieved successfully").Return()
+
+			video.NewVideoHandler(app).GetVideo(c)
+
+			mockResponseHandler.AssertExpectations(t)
+		})
+	}
+}
+
+// TestListScanResults tests that admins get scan results with their findings
+func TestListScanResults(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/admin/scans?status=quarantined", nil)
+
+	scannedAt := time.Now()
+	testVideo := quarantinedVideo(uuid.New())
+	testVideo.Scanner = "clamav"
+	testVideo.ScanFindings = "Eicar-Test-Signature\nWin.Test.Other"
+	testVideo.ScannedAt = &scannedAt
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("ListScanResults", mock.Anything, video.ScanQuarantined, 1, 20).Return([]video.Video{*testVideo}, nil)
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(list video.ScanListResponse) bool {
+		return len(list.Results) == 1 &&
+			list.Results[0].Status == video.ScanQuarantined &&
+			assert.ObjectsAreEqual([]string{"Eicar-Test-Signature", "Win.Test.Other"}, list.Results[0].Findings)
+	}), "Scan results retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListScanResults(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestListScanResults_InvalidStatus tests that unknown statuses are rejected
+func TestListScanResults_InvalidStatus(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/admin/scans?status=infected", nil)
+
+	_, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid status parameter", nil).Return()
+
+	video.NewVideoHandler(app).ListScanResults(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestReleaseVideo_NotReleasable tests that videos without a scan to clear get 409
+func TestReleaseVideo_NotReleasable(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("POST", "/admin/videos/"+videoID.String()+"/release", nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	notReleasable := fmt.Errorf("%w: scan status is %q", video.ErrNotReleasable, video.ScanClean)
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("ReleaseVideo", mock.Anything, videoID).Return(notReleasable)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusConflict, "CONFLICT", notReleasable.Error(), notReleasable).Return()
+
+	video.NewVideoHandler(app).ReleaseVideo(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestScanStatus_Hidden(t *testing.T) {
+	assert.True(t, video.ScanQuarantined.Hidden())
+	assert.True(t, video.ScanBlocked.Hidden())
+	for _, status := range []video.ScanStatus{video.ScanNotScanned, video.ScanClean, video.ScanFlagged, video.ScanError, video.ScanReleased} {
+		assert.False(t, status.Hidden(), status)
+	}
+}
+
+// TestVideoService_ScanReview verifies scans needing review are listed most
+// recently scanned first, and only flagged, quarantined and failed scans
+// can be released
+func TestVideoService_ScanReview(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	service := newMemoryVideoService(repo)
+	scanned := func(minutesAgo int) *time.Time {
+		at := time.Now().Add(-time.Duration(minutesAgo) * time.Minute)
+		return &at
+	}
+	quarantined := createVideo(t, repo, &video.Video{Title: "Quarantined", ScanStatus: video.ScanQuarantined, ScannedAt: scanned(1)})
+	blocked := createVideo(t, repo, &video.Video{Title: "Blocked", ScanStatus: video.ScanBlocked, ScannedAt: scanned(2)})
+	flagged := createVideo(t, repo, &video.Video{Title: "Flagged", ScanStatus: video.ScanFlagged, ScannedAt: scanned(3)})
+	createVideo(t, repo, &video.Video{Title: "Clean", ScanStatus: video.ScanClean, ScannedAt: scanned(0)})
+
+	review, err := service.ListScanResults(ctx, video.ScanNotScanned, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, review, 3)
+	assert.Equal(t, []uuid.UUID{quarantined.ID, blocked.ID, flagged.ID}, []uuid.UUID{review[0].ID, review[1].ID, review[2].ID})
+
+	page, err := service.ListScanResults(ctx, video.ScanNotScanned, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, flagged.ID, page[0].ID)
+
+	only, err := service.ListScanResults(ctx, video.ScanBlocked, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, only, 1)
+	assert.Equal(t, blocked.ID, only[0].ID)
+
+	require.NoError(t, service.ReleaseVideo(ctx, quarantined.ID))
+	released, err := repo.GetByID(ctx, quarantined.ID)
+	require.NoError(t, err)
+	assert.Equal(t, video.ScanReleased, released.ScanStatus)
+
+	assert.ErrorIs(t, service.ReleaseVideo(ctx, quarantined.ID), video.ErrNotReleasable, "already released")
+	assert.ErrorIs(t, service.ReleaseVideo(ctx, blocked.ID), video.ErrNotReleasable)
+	assert.ErrorIs(t, service.ReleaseVideo(ctx, uuid.New()), video.ErrVideoNotFound)
+}
@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// storageURLs links stored files under storage.test
+type storageURLs struct{}
+
+func (storageURLs) GetVideoURL(ctx context.Context, key string) (string, error) {
+	return "https://storage.test/" + key + "?X-Amz-Signature=sig", nil
+}
+
+// sourcesVideo is a video whose original and 720p are stored, the
+// original replicated to IPFS
+func sourcesVideo() *video.Video {
+	return &video.Video{
+		ID:          uuid.New(),
+		Title:       "Test Video",
+		StoragePath: "videos/v/original.mp4",
+		Checksum:    "aaaa",
+		FileSize:    2048,
+		IPFSCID:     "bafyoriginal",
+		Transcodes: []video.Transcode{{
+			Format:   "mp4",
+			Segments: []video.TranscodeSegment{{StoragePath: "videos/v/720p.mp4", Checksum: "bbbb"}},
+		}},
+	}
+}
+
+// getSources calls GetVideoSources for testVideo and returns the response
+func getSources(t *testing.T, app *video.App, mockVideoService *mocks.MockVideoService, mockResponseHandler *mocks.MockResponseHandler, testVideo *video.Video) video.VideoSourcesResponse {
+	t.Helper()
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/api/v1/video/%s/sources", testVideo.ID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+	c.Set("userID", testVideo.UserID.String())
+
+	var got video.VideoSourcesResponse
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.VideoSourcesResponse) bool {
+		got = response
+		return true
+	}), "Sources retrieved successfully").Return()
+
+	video.NewVideoHandler(app).GetVideoSources(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	return got
+}
+
+func TestGetVideoSources(t *testing.T) {
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	app.Streams = new(mocks.MockStreamSource)
+	app.CDN = fakeCDN{}
+	app.Files = storageURLs{}
+	app.Sources = video.SourceConfig{
+		IPFSGateway: "https://ipfs.test/",
+		IPFSPeers:   []string{"/dns4/ipfs.test/tcp/4001/p2p/12D3KooWTest"},
+	}
+	testVideo := sourcesVideo()
+
+	got := getSources(t, app, mockVideoService, mockResponseHandler, testVideo)
+
+	assert.Equal(t, testVideo.ID.String(), got.VideoID)
+	require.Len(t, got.Renditions, 2)
+
+	original := got.Renditions[0]
+	assert.Equal(t, "original", original.Rendition)
+	assert.Equal(t, "aaaa", original.SHA256)
+	assert.Equal(t, int64(2048), original.Size)
+	assert.Equal(t, []video.VideoSource{
+		{Type: video.SourceStream, URL: fmt.Sprintf("/api/v1/video/%s/stream/original", testVideo.ID)},
+		{Type: video.SourceCDN, URL: "https://cdn.test/videos/v/original.mp4"},
+		{Type: video.SourceStorage, URL: "https://storage.test/videos/v/original.mp4?X-Amz-Signature=sig"},
+		{
+			Type:  video.SourceIPFS,
+			URL:   "https://ipfs.test/ipfs/bafyoriginal",
+			CID:   "bafyoriginal",
+			URI:   "ipfs://bafyoriginal",
+			Peers: []string{"/dns4/ipfs.test/tcp/4001/p2p/12D3KooWTest"},
+		},
+	}, original.Sources)
+
+	// The 720p rendition is not replicated yet, so it has no IPFS source
+	rendition := got.Renditions[1]
+	assert.Equal(t, "720p", rendition.Rendition)
+	assert.Equal(t, "bbbb", rendition.SHA256)
+	require.Len(t, rendition.Sources, 3)
+	assert.Equal(t, video.SourceStorage, rendition.Sources[2].Type)
+}
+
+func TestGetVideoSources_GatedAndDropped(t *testing.T) {
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	app.CDN = fakeCDN{}
+	testVideo := sourcesVideo()
+	testVideo.UserID = uuid.New()
+	testVideo.RequiresEntitlement = true
+	dropped := time.Now()
+	testVideo.RenditionsDroppedAt = &dropped
+
+	got := getSources(t, app, mockVideoService, mockResponseHandler, testVideo)
+
+	// Dropped resolutions are left out, and unsigned CDN URLs are not given out for gated videos
+	require.Len(t, got.Renditions, 1)
+	assert.Equal(t, []video.VideoSource{
+		{Type: video.SourceIPFS, CID: "bafyoriginal", URI: "ipfs://bafyoriginal"},
+	}, got.Renditions[0].Sources)
+}
@@ -0,0 +1,212 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	videostorage "github.com/consensuslabs/pavilion-network/backend/internal/storage/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryObject is a stored file held in memory
+type memoryObject struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (o *memoryObject) Size() int64        { return o.Reader.Size() }
+func (o *memoryObject) ModTime() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+func (o *memoryObject) Close() error       { o.closed = true; return nil }
+
+// streamRequest prepares a StreamVideo request for a video with a 720p rendition
+func streamRequest(resolution, rangeHeader string) (*gin.Context, *httptest.ResponseRecorder, *video.Video) {
+	c, w := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s/stream/%s", videoID, resolution), nil)
+	if rangeHeader != "" {
+		c.Request.Header.Set("Range", rangeHeader)
+	}
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}, {Key: "resolution", Value: resolution}}
+	helpers.AuthenticateRequest(c)
+
+	testVideo := &video.Video{
+		ID:          videoID,
+		UserID:      uuid.New(),
+		StoragePath: fmt.Sprintf("videos/%s/original.mp4", videoID),
+		Transcodes: []video.Transcode{{
+			Format: "mp4",
+			Segments: []video.TranscodeSegment{
+				{StoragePath: fmt.Sprintf("videos/%s/720p.mp4", videoID)},
+			},
+		}},
+	}
+	return c, w, testVideo
+}
+
+func TestStreamVideo_Range(t *testing.T) {
+	c, w, testVideo := streamRequest("720p", "bytes=2-5")
+
+	mockVideoService, _, _, app := helpers.SetupMockDependencies()
+	streams := new(mocks.MockStreamSource)
+	app.Streams = streams
+
+	object := &memoryObject{Reader: bytes.NewReader([]byte("0123456789"))}
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	streams.On("OpenVideo", mock.Anything, testVideo.Transcodes[0].Segments[0].StoragePath).Return(object, nil)
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	streams.AssertExpectations(t)
+	assert.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "2345", w.Body.String())
+	assert.Equal(t, "bytes 2-5/10", w.Header().Get("Content-Range"))
+	assert.Equal(t, "4", w.Header().Get("Content-Length"))
+	assert.Equal(t, "video/mp4", w.Header().Get("Content-Type"))
+	assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+	assert.True(t, object.closed)
+}
+
+func TestStreamVideo_WholeOriginal(t *testing.T) {
+	c, w, testVideo := streamRequest("original", "")
+
+	mockVideoService, _, _, app := helpers.SetupMockDependencies()
+	streams := new(mocks.MockStreamSource)
+	app.Streams = streams
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	streams.On("OpenVideo", mock.Anything, testVideo.StoragePath).
+		Return(&memoryObject{Reader: bytes.NewReader([]byte("0123456789"))}, nil)
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0123456789", w.Body.String())
+	assert.Equal(t, "10", w.Header().Get("Content-Length"))
+}
+
+func TestStreamVideo_RangeNotSatisfiable(t *testing.T) {
+	c, w, testVideo := streamRequest("720p", "bytes=20-30")
+
+	mockVideoService, _, _, app := helpers.SetupMockDependencies()
+	streams := new(mocks.MockStreamSource)
+	app.Streams = streams
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	streams.On("OpenVideo", mock.Anything, mock.Anything).
+		Return(&memoryObject{Reader: bytes.NewReader([]byte("0123456789"))}, nil)
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, w.Code)
+}
+
+func TestStreamVideo_MissingRendition(t *testing.T) {
+	c, w, testVideo := streamRequest("480p", "")
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	streams := new(mocks.MockStreamSource)
+	app.Streams = streams
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "RENDITION_NOT_FOUND", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	streams.AssertNotCalled(t, "OpenVideo", mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStreamVideo_StoredFileMissing(t *testing.T) {
+	c, w, testVideo := streamRequest("720p", "")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	streams := new(mocks.MockStreamSource)
+	app.Streams = streams
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	streams.On("OpenVideo", mock.Anything, mock.Anything).Return(nil, videostorage.ErrNotFound)
+	mockLogger.On("LogInfo", "Stored rendition is missing", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "RENDITION_NOT_FOUND", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStreamVideo_InvalidResolution(t *testing.T) {
+	c, w, _ := streamRequest("4k", "")
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	app.Streams = new(mocks.MockStreamSource)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusBadRequest, "INVALID_PARAMETER", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	mockVideoService.AssertNotCalled(t, "GetVideo", mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStreamVideo_EntitlementRequired(t *testing.T) {
+	c, w, testVideo := streamRequest("720p", "")
+	testVideo.RequiresEntitlement = true
+	viewerID := uuid.New()
+	c.Set("userID", viewerID.String())
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	streams := new(mocks.MockStreamSource)
+	checker := new(mocks.MockEntitlementChecker)
+	app.Streams = streams
+	app.Entitlements = checker
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	checker.On("HasAccess", mock.Anything, viewerID, testVideo.ID).Return(false, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusPaymentRequired, "ENTITLEMENT_REQUIRED", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	streams.AssertNotCalled(t, "OpenVideo", mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+}
+
+// regeneratingTiering records the videos whose renditions were regenerated
+type regeneratingTiering struct {
+	video.TieringService
+	regenerated []uuid.UUID
+}
+
+func (t *regeneratingTiering) RegenerateRenditions(videoID uuid.UUID) {
+	t.regenerated = append(t.regenerated, videoID)
+}
+
+func TestStreamVideo_DroppedRenditionIsRegenerated(t *testing.T) {
+	c, w, testVideo := streamRequest("720p", "")
+	dropped := time.Now()
+	testVideo.RenditionsDroppedAt = &dropped
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	streams := new(mocks.MockStreamSource)
+	tiering := &regeneratingTiering{}
+	app.Streams = streams
+	app.Tiering = tiering
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusServiceUnavailable, "RENDITION_REGENERATING", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).StreamVideo(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Equal(t, []uuid.UUID{testVideo.ID}, tiering.regenerated)
+	streams.AssertNotCalled(t, "OpenVideo", mock.Anything, mock.Anything)
+}
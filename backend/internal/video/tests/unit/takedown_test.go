@@ -0,0 +1,141 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// TestGetVideo_TakenDownHidden tests that taken down videos look missing to other users
+func TestGetVideo_TakenDownHidden(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	takenDownAt := time.Now()
+	testVideo := &video.Video{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		Title:          "Taken down",
+		StoragePath:    "videos/takendown/original.mp4",
+		TakenDownAt:    &takenDownAt,
+		TakedownReason: "Copyright claim",
+	}
+	c.Request = httptest.NewRequest("GET", "/video/"+testVideo.ID.String(), nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "user")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "VIDEO_NOT_FOUND", fmt.Sprintf("video not found: %s", testVideo.ID), nil).Return()
+
+	video.NewVideoHandler(app).GetVideo(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestTakeDownVideo tests that admins can take a video down with a reason
+func TestTakeDownVideo(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("POST", "/admin/videos/"+videoID.String()+"/takedown", bytes.NewBufferString(`{"reason":"Copyright claim"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockVideoService.On("TakeDownVideo", mock.Anything, videoID, "Copyright claim").Return(nil)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, nil, "Video taken down successfully").Return()
+
+	video.NewVideoHandler(app).TakeDownVideo(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestTakeDownVideo_MissingReason tests that takedowns without a reason are rejected
+func TestTakeDownVideo_MissingReason(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("POST", "/admin/videos/"+videoID.String()+"/takedown", bytes.NewBufferString(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+
+	video.NewVideoHandler(app).TakeDownVideo(c)
+
+	mockVideoService.AssertNotCalled(t, "TakeDownVideo", mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertNotCalled(t, "ErrorResponse", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	apiErr := helpers.AbortedWith(c)
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status())
+		assert.Equal(t, []apierror.FieldError{{Field: "reason", Message: "reason is required", Rule: "required"}}, apiErr.Fields)
+	}
+}
+
+// TestRestoreVideo_NotTakenDown tests that restoring a published video gets 409
+func TestRestoreVideo_NotTakenDown(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("DELETE", "/admin/videos/"+videoID.String()+"/takedown", nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("RestoreVideo", mock.Anything, videoID).Return(video.ErrNotTakenDown)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusConflict, "CONFLICT", video.ErrNotTakenDown.Error(), video.ErrNotTakenDown).Return()
+
+	video.NewVideoHandler(app).RestoreVideo(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+// TestVideoService_TakedownAndRestore verifies a takedown hides a video from
+// listings, keeps its first time when repeated, and is lifted by a restore
+func TestVideoService_TakedownAndRestore(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	service := newMemoryVideoService(repo)
+	v := createVideo(t, repo, &video.Video{UserID: uuid.New(), Title: "Claimed"})
+
+	assert.ErrorIs(t, service.TakeDownVideo(ctx, uuid.New(), "Copyright claim"), video.ErrVideoNotFound)
+	assert.ErrorIs(t, service.TakeDownVideo(ctx, v.ID, "  "), video.ErrTakedownReason)
+
+	require.NoError(t, service.TakeDownVideo(ctx, v.ID, "Copyright claim"))
+	first, err := repo.GetByID(ctx, v.ID)
+	require.NoError(t, err)
+	require.NotNil(t, first.TakenDownAt)
+	listed, _, err := service.ListVideos(ctx, 1, 10, video.ListFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+
+	require.NoError(t, service.TakeDownVideo(ctx, v.ID, "Court order"))
+	second, err := repo.GetByID(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Court order", second.TakedownReason)
+	assert.Equal(t, first.TakenDownAt, second.TakenDownAt, "the first takedown time is kept")
+
+	require.NoError(t, service.RestoreVideo(ctx, v.ID))
+	restored, err := repo.GetByID(ctx, v.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.TakenDownAt)
+	assert.Empty(t, restored.TakedownReason)
+	assert.ErrorIs(t, service.RestoreVideo(ctx, v.ID), video.ErrNotTakenDown)
+	assert.ErrorIs(t, service.RestoreVideo(ctx, uuid.New()), video.ErrVideoNotFound)
+}
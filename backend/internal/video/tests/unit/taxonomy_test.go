@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+)
+
+// TestNormalizeTags tests tag cleanup and validation
+func TestNormalizeTags(t *testing.T) {
+	tags, err := video.NormalizeTags([]string{" Go ", "#tutorial", "go", "", "web-dev"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "tutorial", "web-dev"}, tags)
+
+	_, err = video.NormalizeTags([]string{"two words"})
+	assert.Error(t, err, "spaces are not allowed")
+
+	tooMany := make([]string, 0, video.MaxTags+1)
+	for i := 0; i <= video.MaxTags; i++ {
+		tooMany = append(tooMany, string(rune('a'+i)))
+	}
+	_, err = video.NormalizeTags(tooMany)
+	assert.Error(t, err, "more than MaxTags tags are rejected")
+}
+
+// TestCategoryIsValid tests category validation
+func TestCategoryIsValid(t *testing.T) {
+	assert.True(t, video.CategoryMusic.IsValid())
+	assert.True(t, video.Category("").IsValid(), "uncategorized is valid")
+	assert.False(t, video.Category("cooking").IsValid())
+}
+
+// TestListVideos_Filters tests that tag and category filters reach the service
+func TestListVideos_Filters(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/videos?tag=%23Golang&category=technology", nil)
+	c.Set("userId", uuid.New())
+	c.Set("request_id", "test-request-id")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	filter := video.ListFilter{Tag: "golang", Category: video.CategoryTechnology}
+	mockVideoService.On("ListVideos", mock.Anything, 1, 10, filter).Return(helpers.SetupTestVideos(1), int64(1), nil)
+	mockLogger.On("LogInfo", "Videos retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Videos retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestListVideos_InvalidCategory tests that unknown categories are rejected
+func TestListVideos_InvalidCategory(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/videos?category=cooking", nil)
+	c.Set("request_id", "test-request-id")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	mockLogger.On("LogInfo", "Invalid category parameter", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusBadRequest, "INVALID_PARAMETER", "Invalid category parameter", mock.Anything).Return()
+
+	video.NewVideoHandler(app).ListVideos(c)
+
+	mockVideoService.AssertNotCalled(t, "ListVideos", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// TestPopularTags tests the popular tags endpoint caps the limit
+func TestPopularTags(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/tags/popular?limit=500", nil)
+	c.Set("request_id", "test-request-id")
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	tags := []video.TagCount{{Name: "golang", Count: 3}, {Name: "tutorial", Count: 1}}
+	mockVideoService.On("PopularTags", mock.Anything, 100).Return(tags, nil)
+	mockResponseHandler.On("SuccessResponse", mock.Anything, tags, "Popular tags retrieved successfully").Return()
+
+	video.NewVideoHandler(app).PopularTags(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// recordingTierer records the storage calls made by tiering
+type recordingTierer struct {
+	classes map[string]string
+	deleted []string
+}
+
+func (r *recordingTierer) SetStorageClass(ctx context.Context, key, class string) error {
+	if r.classes == nil {
+		r.classes = make(map[string]string)
+	}
+	r.classes[key] = class
+	return nil
+}
+
+func (r *recordingTierer) DeleteFile(ctx context.Context, key string) error {
+	r.deleted = append(r.deleted, key)
+	return nil
+}
+
+// countingReprocessor counts reprocessed videos
+type countingReprocessor struct {
+	calls int
+}
+
+func (r *countingReprocessor) ReprocessVideo(ctx context.Context, videoID uuid.UUID) error {
+	r.calls++
+	return nil
+}
+
+// TestStorageTiering_DryRunChangesNothing verifies a dry run only looks for candidates
+func TestStorageTiering_DryRunChangesNothing(t *testing.T) {
+	db, queries := dryRunDB(t)
+	tierer := &recordingTierer{}
+	registry := prometheus.NewRegistry()
+
+	tiering := video.NewStorageTiering(db, tierer, &countingReprocessor{}, nil, video.TieringConfig{
+		StorageClass:        video.StorageClassStandardIA,
+		OriginalsAfter:      90 * 24 * time.Hour,
+		RenditionsIdleAfter: 180 * 24 * time.Hour,
+		BatchSize:           50,
+		DryRun:              true,
+	}, video.NewTieringMetrics(registry), new(mocks.MockLogger))
+
+	result, err := tiering.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, result.Applied)
+	assert.Empty(t, tierer.classes)
+	assert.Empty(t, tierer.deleted)
+
+	// One query per action, over completed uploads not exempted
+	require.Len(t, *queries, 2)
+	originals, renditions := (*queries)[0], (*queries)[1]
+	for _, query := range []string{originals, renditions} {
+		assert.Contains(t, query, "video_uploads.status =")
+		assert.Contains(t, query, "videos.tiering_override <>")
+		assert.Contains(t, query, "LIMIT")
+	}
+	assert.Contains(t, originals, "videos.storage_class <>")
+	assert.Contains(t, originals, "(video_uploads.end_time <")
+	assert.Contains(t, renditions, "videos.renditions_dropped_at IS NULL")
+	assert.Contains(t, renditions, "COALESCE(videos.last_viewed_at, video_uploads.end_time) <")
+
+	count, err := testutil.GatherAndCount(registry, "pavilion_video_tiering_last_run_timestamp_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+// TestStorageTiering_OnlyArchiveOverridesWithoutThresholds verifies zero
+// periods leave out everything but videos overridden to archive
+func TestStorageTiering_OnlyArchiveOverridesWithoutThresholds(t *testing.T) {
+	db, queries := dryRunDB(t)
+	tiering := video.NewStorageTiering(db, &recordingTierer{}, &countingReprocessor{}, nil, video.TieringConfig{
+		StorageClass: video.StorageClassGlacierIR,
+		DryRun:       true,
+	}, nil, new(mocks.MockLogger))
+
+	_, err := tiering.RunOnce(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, *queries, 2)
+	for _, query := range *queries {
+		assert.Contains(t, query, "videos.tiering_override =")
+		assert.NotContains(t, query, "end_time <")
+	}
+}
+
+// TestStorageTiering_NoRegenerationAfterStop verifies Stop is safe before
+// Start and that renditions are not regenerated once stopped
+func TestStorageTiering_NoRegenerationAfterStop(t *testing.T) {
+	db, _ := dryRunDB(t)
+	reprocessor := &countingReprocessor{}
+	tiering := video.NewStorageTiering(db, &recordingTierer{}, reprocessor, nil, video.TieringConfig{}, nil, new(mocks.MockLogger))
+	tiering.Stop()
+
+	tiering.RegenerateRenditions(uuid.New())
+	tiering.Stop()
+	assert.Zero(t, reprocessor.calls)
+}
+
+func TestTieringOverride_IsValid(t *testing.T) {
+	assert.True(t, video.TieringExempt.IsValid())
+	assert.False(t, video.TieringOverride("keep").IsValid())
+	assert.True(t, video.ValidTieringStorageClass("GLACIER_IR"))
+	assert.False(t, video.ValidTieringStorageClass("GLACIER"), "classes needing a restore are refused")
+}
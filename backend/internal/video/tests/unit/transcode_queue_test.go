@@ -0,0 +1,238 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+)
+
+// blockWorker occupies the scheduler's only worker until the returned function is called
+func blockWorker(t *testing.T, scheduler *video.TranscodeScheduler) (release func()) {
+	t.Helper()
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	scheduler.Submit(context.Background(), video.TranscodeTask{
+		Resolution: "720p",
+		Run: func(context.Context) error {
+			close(started)
+			<-unblock
+			return nil
+		},
+	})
+	<-started
+	return func() { close(unblock) }
+}
+
+func TestTranscodeScheduler_ShortVideosFirst(t *testing.T) {
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{Workers: 1}, nil)
+	scheduler.Start()
+	defer scheduler.Stop()
+	release := blockWorker(t, scheduler)
+
+	var mu sync.Mutex
+	var order []string
+	var waits []<-chan error
+	submit := func(name string, duration time.Duration) {
+		waits = append(waits, scheduler.Submit(context.Background(), video.TranscodeTask{
+			VideoID:    uuid.New(),
+			Resolution: "480p",
+			Duration:   duration,
+			Run: func(context.Context) error {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				return nil
+			},
+		}))
+	}
+	submit("long", time.Hour)
+	submit("short", time.Minute)
+	submit("medium-first", 10*time.Minute)
+	submit("medium-second", 10*time.Minute)
+	assert.Equal(t, 4, scheduler.Pending())
+
+	release()
+	for _, wait := range waits {
+		require.NoError(t, <-wait)
+	}
+	assert.Equal(t, []string{"short", "medium-first", "medium-second", "long"}, order,
+		"shorter videos run first, then in submission order")
+}
+
+func TestTranscodeScheduler_RunsInParallel(t *testing.T) {
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{Workers: 3}, nil)
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	// Each task waits until all three are running
+	var running sync.WaitGroup
+	running.Add(3)
+	var waits []<-chan error
+	for _, resolution := range []string{"720p", "480p", "360p"} {
+		waits = append(waits, scheduler.Submit(context.Background(), video.TranscodeTask{
+			Resolution: resolution,
+			Run: func(context.Context) error {
+				running.Done()
+				running.Wait()
+				return nil
+			},
+		}))
+	}
+	for _, wait := range waits {
+		select {
+		case err := <-wait:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("renditions did not run in parallel")
+		}
+	}
+}
+
+func TestTranscodeScheduler_CancelledTaskIsDropped(t *testing.T) {
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{Workers: 1}, nil)
+	scheduler.Start()
+	defer scheduler.Stop()
+	release := blockWorker(t, scheduler)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := false
+	wait := scheduler.Submit(ctx, video.TranscodeTask{
+		Resolution: "360p",
+		Run: func(context.Context) error {
+			ran = true
+			return nil
+		},
+	})
+	cancel()
+
+	select {
+	case err := <-wait:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled task was not dropped while the worker was busy")
+	}
+	assert.False(t, ran)
+	assert.Zero(t, scheduler.Pending())
+}
+
+func TestTranscodeScheduler_StopFailsQueuedTasks(t *testing.T) {
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{Workers: 1}, nil)
+	scheduler.Start()
+	release := blockWorker(t, scheduler)
+
+	wait := scheduler.Submit(context.Background(), video.TranscodeTask{
+		Resolution: "480p",
+		Run:        func(context.Context) error { return nil },
+	})
+
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.Stop()
+		close(stopped)
+	}()
+	assert.ErrorIs(t, <-wait, video.ErrTranscodeSchedulerStopped)
+
+	release()
+	<-stopped
+	assert.ErrorIs(t, <-scheduler.Submit(context.Background(), video.TranscodeTask{}), video.ErrTranscodeSchedulerStopped)
+}
+
+func TestTranscodeScheduler_NilRunsInline(t *testing.T) {
+	var scheduler *video.TranscodeScheduler
+	failure := errors.New("encode failed")
+
+	err := <-scheduler.Submit(context.Background(), video.TranscodeTask{
+		Run: func(context.Context) error { return failure },
+	})
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestTranscodeScheduler_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{Workers: 1}, video.NewTranscodeMetrics(registry))
+	scheduler.Start()
+	defer scheduler.Stop()
+	release := blockWorker(t, scheduler)
+
+	wait := scheduler.Submit(context.Background(), video.TranscodeTask{
+		Resolution: "480p",
+		Run:        func(context.Context) error { return errors.New("encode failed") },
+	})
+
+	gauge := func(name string) float64 {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			if family.GetName() == name {
+				return family.GetMetric()[0].GetGauge().GetValue()
+			}
+		}
+		t.Fatalf("metric %s not found", name)
+		return 0
+	}
+	assert.Equal(t, float64(1), gauge("pavilion_video_transcode_queue_depth"))
+	assert.Equal(t, float64(1), gauge("pavilion_video_transcode_running"))
+
+	release()
+	require.Error(t, <-wait)
+	assert.Equal(t, float64(0), gauge("pavilion_video_transcode_queue_depth"))
+
+	count, err := testutil.GatherAndCount(registry, "pavilion_video_transcode_tasks_total")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "one series per resolution and result")
+}
+
+func TestTranscodeScheduler_AdmitRejectsFullBacklog(t *testing.T) {
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{
+		Workers:    1,
+		MaxBacklog: 2,
+		RetryAfter: 30 * time.Second,
+	}, nil)
+	scheduler.Start()
+	defer scheduler.Stop()
+	release := blockWorker(t, scheduler)
+	defer release()
+
+	queue := func() {
+		scheduler.Submit(context.Background(), video.TranscodeTask{
+			Resolution: "480p",
+			Run:        func(context.Context) error { return nil },
+		})
+	}
+	queue()
+	assert.NoError(t, scheduler.Admit(), "the running rendition does not count toward the backlog")
+	assert.NoError(t, scheduler.Check(context.Background()))
+
+	queue()
+	var backlog *video.TranscodeBacklogError
+	require.ErrorAs(t, scheduler.Admit(), &backlog)
+	assert.Equal(t, 2, backlog.Pending)
+	assert.Equal(t, 30*time.Second, backlog.RetryAfter)
+	assert.Error(t, scheduler.Check(context.Background()))
+}
+
+func TestTranscodeScheduler_AdmitWithoutLimit(t *testing.T) {
+	var unset *video.TranscodeScheduler
+	assert.NoError(t, unset.Admit())
+
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{Workers: 1}, nil)
+	scheduler.Start()
+	defer scheduler.Stop()
+	release := blockWorker(t, scheduler)
+	defer release()
+	for i := 0; i < 5; i++ {
+		scheduler.Submit(context.Background(), video.TranscodeTask{Run: func(context.Context) error { return nil }})
+	}
+	assert.NoError(t, scheduler.Admit(), "a MaxBacklog of 0 never rejects uploads")
+}
@@ -0,0 +1,203 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// restoreContext returns a request restoring videoID, made by userID with the given role
+func restoreContext(videoID, userID uuid.UUID, role string) *gin.Context {
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("POST", "/video/"+videoID.String()+"/restore", nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", userID.String())
+	c.Set("role", role)
+	return c
+}
+
+// deletedVideo returns a video owned by owner and deleted by deletedBy
+func deletedVideo(owner, deletedBy uuid.UUID) *video.Video {
+	return &video.Video{
+		ID:        uuid.New(),
+		UserID:    owner,
+		Title:     "Deleted Video",
+		DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+		DeletedBy: &deletedBy,
+	}
+}
+
+// TestListTrash tests that users list their own deleted videos
+func TestListTrash(t *testing.T) {
+	userID := uuid.New()
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/me/videos/trash?page=2&limit=100", nil)
+	c.Set("userID", userID.String())
+
+	trash := &mocks.MockTrashService{}
+	_, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	app.Trash = trash
+	response := &video.TrashListResponse{Videos: []video.TrashedVideo{}, Page: 2, Limit: 50}
+	trash.On("List", mock.Anything, userID, 2, 50).Return(response, nil)
+	mockResponseHandler.On("SuccessResponse", mock.Anything, response, "Deleted videos retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListTrash(c)
+
+	trash.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestUndeleteVideo_Owner tests that owners can restore videos they deleted
+func TestUndeleteVideo_Owner(t *testing.T) {
+	owner := uuid.New()
+	deleted := deletedVideo(owner, owner)
+	c := restoreContext(deleted.ID, owner, "user")
+
+	trash := &mocks.MockTrashService{}
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Trash = trash
+	trash.On("Get", mock.Anything, deleted.ID).Return(deleted, nil)
+	trash.On("Restore", mock.Anything, deleted.ID).Return(nil)
+	mockVideoService.On("GetVideo", mock.Anything, deleted.ID).Return(&video.Video{ID: deleted.ID, UserID: owner}, nil)
+	mockLogger.On("LogInfo", "Video restored", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video restored successfully").Return()
+
+	video.NewVideoHandler(app).UndeleteVideo(c)
+
+	trash.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestUndeleteVideo_RemovedByModerator tests that owners cannot undo a moderator's removal but admins can
+func TestUndeleteVideo_RemovedByModerator(t *testing.T) {
+	owner := uuid.New()
+	deleted := deletedVideo(owner, uuid.New())
+
+	t.Run("Owner", func(t *testing.T) {
+		c := restoreContext(deleted.ID, owner, "user")
+
+		trash := &mocks.MockTrashService{}
+		_, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+		app.Trash = trash
+		trash.On("Get", mock.Anything, deleted.ID).Return(deleted, nil)
+		mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+		mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, nil).Return()
+
+		video.NewVideoHandler(app).UndeleteVideo(c)
+
+		mockResponseHandler.AssertExpectations(t)
+		trash.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		c := restoreContext(deleted.ID, uuid.New(), "admin")
+
+		trash := &mocks.MockTrashService{}
+		mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+		app.Trash = trash
+		trash.On("Get", mock.Anything, deleted.ID).Return(deleted, nil)
+		trash.On("Restore", mock.Anything, deleted.ID).Return(nil)
+		mockVideoService.On("GetVideo", mock.Anything, deleted.ID).Return(&video.Video{ID: deleted.ID, UserID: owner}, nil)
+		mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+		mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video restored successfully").Return()
+
+		video.NewVideoHandler(app).UndeleteVideo(c)
+
+		trash.AssertExpectations(t)
+	})
+}
+
+// TestUndeleteVideo_NotRestorable tests that videos being purged cannot be restored
+func TestUndeleteVideo_NotRestorable(t *testing.T) {
+	owner := uuid.New()
+	videoID := uuid.New()
+	c := restoreContext(videoID, owner, "user")
+
+	trash := &mocks.MockTrashService{}
+	_, _, _, app := helpers.SetupMockDependencies()
+	app.Trash = trash
+	trash.On("Get", mock.Anything, videoID).Return(nil, video.ErrNotRestorable)
+
+	video.NewVideoHandler(app).UndeleteVideo(c)
+
+	apiErr := helpers.AbortedWith(c)
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, http.StatusConflict, apiErr.Status())
+	}
+	trash.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+}
+
+// TestTrashService_RetentionAndRestore verifies the trash lists the owner's
+// restorable videos, leaving out those past the retention or being purged,
+// and restores them
+func TestTrashService_RetentionAndRestore(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	logger := new(mocks.MockLogger)
+	logger.On("LogInfo", "Video restored from trash", mock.Anything).Return()
+	trash := video.NewTrashService(repo, 7*24*time.Hour, nil, logger)
+	owner, moderator := uuid.New(), uuid.New()
+	deleted := func(title string, ago time.Duration, by uuid.UUID, purging bool) *video.Video {
+		return createVideo(t, repo, &video.Video{
+			UserID:    owner,
+			Title:     title,
+			DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-ago), Valid: true},
+			DeletedBy: &by,
+			Purging:   purging,
+		})
+	}
+	recent := deleted("Recent", time.Hour, owner, false)
+	removed := deleted("Removed", 2*time.Hour, moderator, false)
+	expired := deleted("Expired", 8*24*time.Hour, owner, false)
+	purging := deleted("Purging", time.Minute, owner, true)
+	live := createVideo(t, repo, &video.Video{UserID: owner, Title: "Live"})
+	createVideo(t, repo, &video.Video{
+		UserID:    uuid.New(),
+		Title:     "Someone else's",
+		DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+	})
+
+	list, err := trash.List(ctx, owner, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), list.Total)
+	require.Len(t, list.Videos, 1)
+	assert.Equal(t, recent.ID.String(), list.Videos[0].ID)
+	assert.True(t, list.Videos[0].Restorable)
+	require.NotNil(t, list.Videos[0].PurgeAt)
+
+	list, err = trash.List(ctx, owner, 2, 1)
+	require.NoError(t, err)
+	require.Len(t, list.Videos, 1)
+	assert.Equal(t, removed.ID.String(), list.Videos[0].ID)
+	assert.False(t, list.Videos[0].Restorable, "removed by a moderator")
+
+	_, err = trash.Get(ctx, expired.ID)
+	assert.ErrorIs(t, err, video.ErrNotRestorable)
+	_, err = trash.Get(ctx, purging.ID)
+	assert.ErrorIs(t, err, video.ErrNotRestorable)
+	_, err = trash.Get(ctx, live.ID)
+	assert.ErrorIs(t, err, video.ErrVideoNotFound)
+	assert.ErrorIs(t, trash.Restore(ctx, expired.ID), video.ErrNotRestorable)
+
+	got, err := trash.Get(ctx, recent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Recent", got.Title)
+	require.NoError(t, trash.Restore(ctx, recent.ID))
+	restored, err := repo.GetByID(ctx, recent.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedBy)
+	assert.ErrorIs(t, trash.Restore(ctx, recent.ID), video.ErrNotRestorable, "no longer deleted")
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
 )
@@ -40,8 +41,10 @@ func TestUpdateVideo_Success(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
 
-	// Add authentication header
+	// Add authentication header and authenticate as the owner
 	helpers.AuthenticateRequest(c)
+	ownerID := uuid.New()
+	c.Set("userID", ownerID.String())
 
 	// Setup mock dependencies
 	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
@@ -50,12 +53,13 @@ func TestUpdateVideo_Success(t *testing.T) {
 	app.Config = helpers.VideoConfigForTest()
 
 	// Set up mock expectations
-	mockVideoService.On("GetVideo", videoID).Return(&video.Video{
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
 		ID:          videoID,
+		UserID:      ownerID,
 		Title:       "Original Title",
 		Description: "Original Description",
 	}, nil)
-	mockVideoService.On("UpdateVideo", videoID, title, description).Return(nil)
+	mockVideoService.On("UpdateVideo", mock.Anything, videoID, title, description, video.Taxonomy{Tags: []string{}}).Return(nil)
 	mockLogger.On("LogInfo", "Video updated successfully", mock.Anything).Return()
 	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video updated successfully").Return()
 
@@ -131,7 +135,7 @@ func TestUpdateVideo_Unauthorized(t *testing.T) {
 // TestUpdateVideo_InvalidRequest tests updating a video with invalid request data
 func TestUpdateVideo_InvalidRequest(t *testing.T) {
 	// Setup test context
-	c, w := helpers.SetupTestContext()
+	c, _ := helpers.SetupTestContext()
 
 	// Create a test UUID
 	videoID := uuid.New()
@@ -152,7 +156,6 @@ func TestUpdateVideo_InvalidRequest(t *testing.T) {
 
 	// Set up mock expectations
 	mockLogger.On("LogInfo", "Invalid update request format", mock.Anything).Return()
-	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request format", mock.Anything).Return()
 
 	// Create handler and call it
 	handler := video.NewVideoHandler(app)
@@ -163,7 +166,91 @@ func TestUpdateVideo_InvalidRequest(t *testing.T) {
 	mockResponseHandler.AssertExpectations(t)
 
 	// Additional assertions
-	assert.Equal(t, 400, w.Code, "Should return HTTP 400 Bad Request")
+	apiErr := helpers.AbortedWith(c)
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, "INVALID_REQUEST", apiErr.Code)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status())
+	}
+}
+
+// TestUpdateVideo_ValidationErrors tests that every invalid field is reported
+func TestUpdateVideo_ValidationErrors(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+	videoID := uuid.New()
+	body := `{"title":"ab","category":"cooking","tags":["not a tag"],"comments_policy":"off"}`
+	c.Request = httptest.NewRequest("PUT", fmt.Sprintf("/videos/%s", videoID), bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	mockVideoService, _, mockLogger, app := helpers.SetupMockDependencies()
+	app.Config = helpers.VideoConfigForTest()
+	mockLogger.On("LogInfo", "Invalid update request format", mock.Anything).Return()
+
+	video.NewVideoHandler(app).UpdateVideo(c)
+
+	mockVideoService.AssertNotCalled(t, "GetVideo", mock.Anything, mock.Anything)
+	apiErr := helpers.AbortedWith(c)
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, "VALIDATION_ERROR", apiErr.Code)
+		assert.Equal(t, []apierror.FieldError{
+			{Field: "title", Message: "title must be between 3 and 100 characters", Rule: "videotitle"},
+			{Field: "category", Message: "category is not a valid value", Rule: "enum"},
+			{Field: "tags", Message: "tags must be at most 10 tags of up to 32 letters, digits or hyphens", Rule: "videotags"},
+			{Field: "comments_policy", Message: "comments_policy is not a valid value", Rule: "enum"},
+		}, apiErr.Fields)
+	}
+}
+
+// TestUpdateVideo_CommentsPolicy tests that changing only the comments policy
+// leaves the rest of the video alone
+func TestUpdateVideo_CommentsPolicy(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	videoID := uuid.New()
+	ownerID := uuid.New()
+	c.Request = httptest.NewRequest("PATCH", fmt.Sprintf("/video/%s", videoID), bytes.NewBufferString(`{"comments_policy":"review_required"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", ownerID.String())
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Config = helpers.VideoConfigForTest()
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{ID: videoID, UserID: ownerID, Title: "Original Title"}, nil)
+	mockVideoService.On("SetCommentsPolicy", mock.Anything, videoID, video.CommentsReviewRequired).Return(nil)
+	mockLogger.On("LogInfo", "Video updated successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video updated successfully").Return()
+
+	video.NewVideoHandler(app).UpdateVideo(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "UpdateVideo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, 200, w.Code)
+}
+
+// TestUpdateVideo_Embeddable tests that embedding can be turned off on its own
+func TestUpdateVideo_Embeddable(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+	videoID := uuid.New()
+	ownerID := uuid.New()
+	c.Request = httptest.NewRequest("PATCH", fmt.Sprintf("/video/%s", videoID), bytes.NewBufferString(`{"embeddable":false}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", ownerID.String())
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Config = helpers.VideoConfigForTest()
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{ID: videoID, UserID: ownerID, Title: "Original Title", Embeddable: true}, nil)
+	mockVideoService.On("SetEmbeddable", mock.Anything, videoID, false).Return(nil)
+	mockLogger.On("LogInfo", "Video updated successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video updated successfully").Return()
+
+	video.NewVideoHandler(app).UpdateVideo(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "UpdateVideo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockVideoService.AssertNotCalled(t, "SetCommentsPolicy", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, 200, w.Code)
 }
 
 // TestDeleteVideo_Success tests the successful deletion of a video
@@ -178,19 +265,22 @@ func TestDeleteVideo_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/videos/%s", videoID), nil)
 	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
 
-	// Add authentication header
+	// Add authentication header and authenticate as the owner
 	helpers.AuthenticateRequest(c)
+	ownerID := uuid.New()
+	c.Set("userID", ownerID.String())
 
 	// Setup mock dependencies
 	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
 
 	// Set up mock expectations
-	mockVideoService.On("GetVideo", videoID).Return(&video.Video{
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
 		ID:          videoID,
+		UserID:      ownerID,
 		Title:       "Test Video",
 		Description: "Test Description",
 	}, nil)
-	mockVideoService.On("DeleteVideo", videoID).Return(nil)
+	mockVideoService.On("DeleteVideo", mock.Anything, videoID, mock.Anything).Return(nil)
 	mockLogger.On("LogInfo", "Video soft deleted successfully", mock.Anything).Return()
 	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video deleted successfully").Return()
 
@@ -266,9 +356,10 @@ func TestDeleteVideo_NotFound(t *testing.T) {
 	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
 
 	// Set up mock expectations
-	mockVideoService.On("GetVideo", videoID).Return(nil, nil)
-	mockLogger.On("LogInfo", "Video not found for deletion", mock.Anything).Return()
-	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "VIDEO_NOT_FOUND", "Video not found", mock.Anything).Return()
+	notFoundErr := fmt.Errorf("%w: %s", video.ErrVideoNotFound, videoID)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(nil, notFoundErr)
+	mockLogger.On("LogInfo", "Video not found or has been deleted", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "VIDEO_NOT_FOUND", fmt.Sprintf("video not found: %s", videoID), nil).Return()
 
 	// Create handler and call it
 	handler := video.NewVideoHandler(app)
@@ -282,3 +373,280 @@ func TestDeleteVideo_NotFound(t *testing.T) {
 	// Additional assertions
 	assert.Equal(t, 404, w.Code, "Should return HTTP 404 Not Found")
 }
+
+// TestUpdateVideo_Forbidden tests that a moderator cannot edit another user's video
+func TestUpdateVideo_Forbidden(t *testing.T) {
+	// Setup test context
+	c, w := helpers.SetupTestContext()
+
+	// Create a test UUID
+	videoID := uuid.New()
+
+	// Create update request body
+	title := "Updated Title"
+	jsonData, _ := json.Marshal(map[string]*string{"title": &title})
+
+	// Create request
+	c.Request = httptest.NewRequest("PUT", fmt.Sprintf("/videos/%s", videoID), bytes.NewBuffer(jsonData))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	// Authenticate as a moderator who does not own the video
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "moderator")
+
+	// Setup mock dependencies
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Config = helpers.VideoConfigForTest()
+
+	// Set up mock expectations
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+		ID:     videoID,
+		UserID: uuid.New(),
+		Title:  "Original Title",
+	}, nil)
+	mockLogger.On("LogInfo", "Video update forbidden", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, mock.Anything).Return()
+
+	// Create handler and call it
+	handler := video.NewVideoHandler(app)
+	handler.UpdateVideo(c)
+
+	// Verify expectations
+	mockVideoService.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "UpdateVideo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertExpectations(t)
+
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should return HTTP 403 Forbidden")
+}
+
+// TestDeleteVideo_Forbidden tests that a regular user cannot delete another user's video
+func TestDeleteVideo_Forbidden(t *testing.T) {
+	// Setup test context
+	c, w := helpers.SetupTestContext()
+
+	// Create a test UUID
+	videoID := uuid.New()
+
+	// Create request
+	c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/videos/%s", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	// Authenticate as a user who does not own the video
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "user")
+
+	// Setup mock dependencies
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	// Set up mock expectations
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+		ID:     videoID,
+		UserID: uuid.New(),
+	}, nil)
+	mockLogger.On("LogInfo", "Video deletion forbidden", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, mock.Anything).Return()
+
+	// Create handler and call it
+	handler := video.NewVideoHandler(app)
+	handler.DeleteVideo(c)
+
+	// Verify expectations
+	mockVideoService.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "DeleteVideo", mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertExpectations(t)
+
+	assert.Equal(t, http.StatusForbidden, w.Code, "Should return HTTP 403 Forbidden")
+}
+
+// TestDeleteVideo_Moderator tests that a moderator can take down another user's video
+func TestDeleteVideo_Moderator(t *testing.T) {
+	// Setup test context
+	c, w := helpers.SetupTestContext()
+
+	// Create a test UUID
+	videoID := uuid.New()
+
+	// Create request
+	c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/videos/%s", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	// Authenticate as a moderator who does not own the video
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "moderator")
+
+	// Setup mock dependencies
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	// Set up mock expectations
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+		ID:     videoID,
+		UserID: uuid.New(),
+	}, nil)
+	mockVideoService.On("DeleteVideo", mock.Anything, videoID, mock.Anything).Return(nil)
+	mockLogger.On("LogInfo", "Video soft deleted successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video deleted successfully").Return()
+
+	// Create handler and call it
+	handler := video.NewVideoHandler(app)
+	handler.DeleteVideo(c)
+
+	// Verify expectations
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+
+	assert.Equal(t, 200, w.Code, "Should return HTTP 200 OK")
+}
+
+// TestDeleteVideo_Hard tests that the owner can permanently delete a video
+func TestDeleteVideo_Hard(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/videos/%s?hard=true", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	helpers.AuthenticateRequest(c)
+	ownerID := uuid.New()
+	c.Set("userID", ownerID.String())
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+		ID:     videoID,
+		UserID: ownerID,
+	}, nil)
+	mockVideoService.On("PurgeVideo", mock.Anything, videoID).Return(nil)
+	mockLogger.On("LogInfo", "Video permanently deleted", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video permanently deleted").Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.DeleteVideo(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "DeleteVideo", mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertExpectations(t)
+
+	assert.Equal(t, 200, w.Code, "Should return HTTP 200 OK")
+}
+
+// recordingNotifications records the video events published through it
+type recordingNotifications struct {
+	events []*video.VideoEvent
+}
+
+func (n *recordingNotifications) PublishVideoEvent(ctx interface{}, event interface{}) error {
+	n.events = append(n.events, event.(*video.VideoEvent))
+	return nil
+}
+
+// TestDeleteVideo_PublishesDeletion tests that deleting a video publishes
+// VIDEO_DELETED for its owner, so its comments and notifications are cleaned up
+func TestDeleteVideo_PublishesDeletion(t *testing.T) {
+	for _, hard := range []bool{false, true} {
+		c, _ := helpers.SetupTestContext()
+
+		videoID := uuid.New()
+		c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/videos/%s?hard=%t", videoID, hard), nil)
+		c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+		helpers.AuthenticateRequest(c)
+		ownerID := uuid.New()
+		c.Set("userID", ownerID.String())
+
+		mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+		notifications := &recordingNotifications{}
+		app.NotificationService = notifications
+
+		mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+			ID:     videoID,
+			UserID: ownerID,
+			Title:  "Test Video",
+		}, nil)
+		mockVideoService.On("DeleteVideo", mock.Anything, videoID, mock.Anything).Return(nil)
+		mockVideoService.On("PurgeVideo", mock.Anything, videoID).Return(nil)
+		mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+		mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, mock.Anything).Return()
+
+		handler := video.NewVideoHandler(app)
+		handler.DeleteVideo(c)
+
+		if assert.Len(t, notifications.events, 1, "hard=%t", hard) {
+			event := notifications.events[0]
+			assert.Equal(t, "VIDEO_DELETED", event.Type)
+			assert.Equal(t, videoID, event.VideoID)
+			assert.Equal(t, ownerID, event.UserID, "the owner is told")
+			assert.Equal(t, "Test Video", event.Title)
+			assert.Equal(t, hard, event.Metadata["hard"])
+		}
+	}
+}
+
+// TestDeleteVideo_HardModeratorForbidden tests that moderators can only soft delete other users' videos
+func TestDeleteVideo_HardModeratorForbidden(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/videos/%s?hard=true", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "moderator")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+		ID:     videoID,
+		UserID: uuid.New(),
+	}, nil)
+	mockLogger.On("LogInfo", "Video deletion forbidden", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", "You do not have permission to delete this video", nil).Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.DeleteVideo(c)
+
+	mockVideoService.AssertNotCalled(t, "PurgeVideo", mock.Anything, mock.Anything)
+	mockVideoService.AssertNotCalled(t, "DeleteVideo", mock.Anything, mock.Anything, mock.Anything)
+	mockResponseHandler.AssertExpectations(t)
+
+	assert.Equal(t, 403, w.Code, "Should return HTTP 403 Forbidden")
+}
+
+// TestDeleteVideo_HardPurgeFails tests the response when removing the video's files fails
+func TestDeleteVideo_HardPurgeFails(t *testing.T) {
+	c, w := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/videos/%s?hard=true", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+
+	helpers.AuthenticateRequest(c)
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "admin")
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	purgeErr := &video.PurgeError{VideoID: videoID, Stage: video.PurgeStageStorage, Err: fmt.Errorf("access denied")}
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{
+		ID:     videoID,
+		UserID: uuid.New(),
+	}, nil)
+	mockVideoService.On("PurgeVideo", mock.Anything, videoID).Return(purgeErr)
+	mockLogger.On("LogInfo", "Failed to permanently delete video", mock.MatchedBy(func(fields map[string]interface{}) bool {
+		return fields["stage"] == video.PurgeStageStorage
+	})).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusInternalServerError, "DELETE_FAILED", mock.Anything, purgeErr).Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.DeleteVideo(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockLogger.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+
+	assert.Equal(t, 500, w.Code, "Should return HTTP 500 Internal Server Error")
+}
@@ -0,0 +1,166 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+)
+
+// eventsRequest prepares a request for the upload events of videoID by userID
+func eventsRequest(videoID, userID uuid.UUID) (*gin.Context, *httptest.ResponseRecorder) {
+	c, w := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/video/%s/events", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", userID.String())
+	helpers.AuthenticateRequest(c)
+	return c, w
+}
+
+// TestStreamUploadEvents_StreamsUntilProcessingEnds verifies the stream
+// opens with the status, passes on published events and ends with the final
+// status once processing ends
+func TestStreamUploadEvents_StreamsUntilProcessingEnds(t *testing.T) {
+	ownerID := uuid.New()
+	videoID := uuid.New()
+	c, w := eventsRequest(videoID, ownerID)
+
+	mockVideoService, _, _, app := helpers.SetupMockDependencies()
+	app.UploadEvents = video.NewUploadEvents()
+
+	uploading := &video.Video{ID: videoID, UserID: ownerID, Upload: &video.VideoUpload{
+		Status: video.UploadStatusUploading,
+		Stage:  video.UploadStageTranscoding,
+	}}
+	completed := &video.Video{ID: videoID, UserID: ownerID, Upload: &video.VideoUpload{
+		Status: video.UploadStatusCompleted,
+		Stage:  video.UploadStageCompleted,
+	}}
+
+	// Processing moves on and ends while the stream opens
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(uploading, nil).Once().Run(func(mock.Arguments) {
+		app.UploadEvents.Publish(videoID, video.UploadEventProgress, video.TranscodeProgressEvent{Resolution: "720p", Percent: 40})
+		app.UploadEvents.Publish(videoID, video.UploadEventRendition, video.RenditionEvent{Resolution: "720p", Status: video.RenditionCompleted})
+		app.UploadEvents.Finish(videoID)
+	})
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(completed, nil).Once()
+
+	video.NewVideoHandler(app).StreamUploadEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+
+	// The events arrive in order
+	body := w.Body.String()
+	events := []string{
+		`event:status` + "\n" + `data:{"status":"uploading"`,
+		`event:progress` + "\n" + `data:{"resolution":"720p","percent":40}`,
+		`event:rendition` + "\n" + `data:{"resolution":"720p","status":"completed"}`,
+		`event:end` + "\n" + `data:{"status":"completed"`,
+	}
+	rest := body
+	for _, event := range events {
+		i := strings.Index(rest, event)
+		require.GreaterOrEqual(t, i, 0, "missing %q in %s", event, body)
+		rest = rest[i+len(event):]
+	}
+	mockVideoService.AssertExpectations(t)
+}
+
+// TestStreamUploadEvents_EndedUpload verifies a stream for an upload that
+// has already ended sends only the final status
+func TestStreamUploadEvents_EndedUpload(t *testing.T) {
+	ownerID := uuid.New()
+	videoID := uuid.New()
+	c, w := eventsRequest(videoID, ownerID)
+
+	mockVideoService, _, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{ID: videoID, UserID: ownerID, Upload: &video.VideoUpload{
+		Status: video.UploadStatusFailed,
+		Stage:  video.UploadStageScanning,
+	}}, nil)
+
+	video.NewVideoHandler(app).StreamUploadEvents(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "event:end\n"+`data:{"status":"failed"`)
+	assert.Contains(t, w.Body.String(), `"stage":"scanning"`)
+	assert.NotContains(t, w.Body.String(), "event:status")
+}
+
+// TestStreamUploadEvents_ClosesWhenClientLeaves verifies the stream ends
+// when the client disconnects
+func TestStreamUploadEvents_ClosesWhenClientLeaves(t *testing.T) {
+	ownerID := uuid.New()
+	videoID := uuid.New()
+	c, _ := eventsRequest(videoID, ownerID)
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+
+	mockVideoService, _, _, app := helpers.SetupMockDependencies()
+	app.UploadEvents = video.NewUploadEvents()
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{ID: videoID, UserID: ownerID, Upload: &video.VideoUpload{
+		Status: video.UploadStatusUploading,
+	}}, nil).Run(func(mock.Arguments) { cancel() })
+
+	done := make(chan struct{})
+	go func() {
+		video.NewVideoHandler(app).StreamUploadEvents(c)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end when the client left")
+	}
+}
+
+// TestStreamUploadEvents_Forbidden verifies only the owner or an admin can follow an upload
+func TestStreamUploadEvents_Forbidden(t *testing.T) {
+	videoID := uuid.New()
+	c, w := eventsRequest(videoID, uuid.New())
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(&video.Video{ID: videoID, UserID: uuid.New(), Upload: &video.VideoUpload{
+		Status: video.UploadStatusUploading,
+	}}, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).StreamUploadEvents(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	assert.NotContains(t, w.Header().Get("Content-Type"), "text/event-stream")
+}
+
+// TestUploadEvents_Close verifies closing the hub ends subscriptions and refuses new ones
+func TestUploadEvents_Close(t *testing.T) {
+	events := video.NewUploadEvents()
+	first, stop := events.Subscribe(uuid.New())
+	defer stop()
+
+	events.Close()
+	_, ok := <-first
+	assert.False(t, ok)
+
+	later, _ := events.Subscribe(uuid.New())
+	_, ok = <-later
+	assert.False(t, ok)
+
+	// A nil hub never delivers and can be used freely
+	var none *video.UploadEvents
+	none.Publish(uuid.New(), video.UploadEventStage, nil)
+	_, stopNone := none.Subscribe(uuid.New())
+	stopNone()
+}
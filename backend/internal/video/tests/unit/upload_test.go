@@ -2,6 +2,7 @@ package unit
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"mime/multipart"
 	"net/http"
@@ -15,7 +16,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tempfile"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
 )
@@ -35,12 +38,14 @@ func TestHandleUpload_Success(t *testing.T) {
 	config.Video.MaxDescLength = 1000
 
 	// Create a new app instance
+	webhooks := new(mocks.MockWebhookPublisher)
 	app := &video.App{
 		Config:          config,
 		Video:           mockVideoService,
 		IPFS:            mockIPFSService,
 		ResponseHandler: mockResponseHandler,
 		Logger:          mockLogger,
+		Webhooks:        webhooks,
 	}
 
 	// Create a new video handler with the app
@@ -107,7 +112,7 @@ func TestHandleUpload_Success(t *testing.T) {
 		UpdatedAt:   time.Now(),
 	}
 
-	mockVideoService.On("InitializeUpload", fileName, "Test video description", mock.Anything).Return(&video.VideoUpload{
+	mockVideoService.On("InitializeUpload", mock.Anything, mock.Anything, fileName, "Test video description", mock.Anything, video.Taxonomy{Tags: []string{}}).Return(&video.VideoUpload{
 		ID:        uploadId,
 		VideoID:   videoId,
 		Status:    video.UploadStatusPending,
@@ -117,13 +122,18 @@ func TestHandleUpload_Success(t *testing.T) {
 		Video:     testVideo,
 	}, nil)
 
-	mockVideoService.On("ProcessUpload", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockVideoService.On("ProcessUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	// Expect video service to create a video (if needed)
 	mockVideoService.On("CreateVideo", userId, fileName, "Test video description").Return(testVideo, nil)
 
 	// Expect GetVideo to be called after processing the upload
-	mockVideoService.On("GetVideo", videoId).Return(testVideo, nil)
+	mockVideoService.On("GetVideo", mock.Anything, videoId).Return(testVideo, nil)
+
+	// Expect the uploader's webhooks to be told the video was processed
+	webhooks.On("Publish", mock.Anything, mock.Anything, video.WebhookVideoProcessed, mock.MatchedBy(func(data map[string]interface{}) bool {
+		return data["video_id"] == videoId && data["title"] == fileName
+	})).Return(nil)
 
 	// Expect success response
 	// Using mock.Anything for all parameters since we can't know the exact values
@@ -134,6 +144,7 @@ func TestHandleUpload_Success(t *testing.T) {
 
 	// Verify status code
 	assert.Equal(t, http.StatusOK, w.Code)
+	webhooks.AssertExpectations(t)
 }
 
 // TestHandleUpload_InvalidFile tests the upload handler when no file is provided
@@ -237,13 +248,18 @@ func TestHandleUpload_SizeExceeded(t *testing.T) {
 	// Set up mock expectations - we only need one of these to be called
 	// Since we're setting authenticated=true, we expect the validation error
 	mockLogger.On("LogInfo", "Video upload validation failed", mock.Anything).Return()
-	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusBadRequest, "ERR_VALIDATION", mock.Anything, mock.Anything).Return()
 
 	// Call the handler
 	handler.HandleUpload(ctx)
 
 	// Verify expectations
-	mockResponseHandler.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "InitializeUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	apiErr := helpers.AbortedWith(ctx)
+	if assert.NotNil(t, apiErr) {
+		assert.Equal(t, "ERR_VALIDATION", apiErr.Code)
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status())
+		assert.Equal(t, []apierror.FieldError{{Field: "video", Message: "video must be at most 10 bytes", Rule: "videosize"}}, apiErr.Fields)
+	}
 }
 
 // TestHandleUpload_Unauthorized tests that authentication is required for the Upload endpoint
@@ -346,7 +362,7 @@ func TestHandleUpload_TranscodingFailed(t *testing.T) {
 	uploadId := uuid.New()
 	videoId := uuid.New()
 
-	mockVideoService.On("InitializeUpload", fileName, "Test video description", mock.Anything).Return(&video.VideoUpload{
+	mockVideoService.On("InitializeUpload", mock.Anything, mock.Anything, fileName, "Test video description", mock.Anything, video.Taxonomy{Tags: []string{}}).Return(&video.VideoUpload{
 		ID:        uploadId,
 		VideoID:   videoId,
 		Status:    video.UploadStatusPending,
@@ -357,7 +373,7 @@ func TestHandleUpload_TranscodingFailed(t *testing.T) {
 
 	// Simulate transcoding failure
 	transcodeError := fmt.Errorf("transcoding failed")
-	mockVideoService.On("ProcessUpload", mock.Anything, mock.Anything, mock.Anything).Return(transcodeError)
+	mockVideoService.On("ProcessUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(transcodeError)
 
 	// Expect error logging and response
 	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
@@ -374,3 +390,223 @@ func TestHandleUpload_TranscodingFailed(t *testing.T) {
 	// Verify status code
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+// TestHandleUpload_ShuttingDown tests that uploads arriving during shutdown get 503
+func TestHandleUpload_ShuttingDown(t *testing.T) {
+	mockVideoService, _, _, _,
+		_, mockResponseHandler, mockLogger := helpers.SetupMockServices()
+
+	config := helpers.VideoConfigForTest()
+	config.Video.AllowedFormats = []string{".mp4"}
+	handler := video.NewVideoHandler(&video.App{
+		Config:          config,
+		Video:           mockVideoService,
+		ResponseHandler: mockResponseHandler,
+		Logger:          mockLogger,
+	})
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("video", "test-video.mp4")
+	part.Write([]byte("test video file contents"))
+	writer.WriteField("title", "test-video.mp4")
+	writer.Close()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest("POST", "/video/upload", body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx.Set("request_id", "test-request-id")
+
+	mockVideoService.On("InitializeUpload", mock.Anything, mock.Anything, "test-video.mp4", "", mock.Anything, video.Taxonomy{Tags: []string{}}).
+		Return(&video.VideoUpload{ID: uuid.New(), VideoID: uuid.New(), Status: video.UploadStatusPending}, nil)
+	mockVideoService.On("ProcessUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(video.ErrShuttingDown)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", video.ErrShuttingDown.Error(), video.ErrShuttingDown).Return()
+
+	handler.HandleUpload(ctx)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// TestHandleUpload_TranscodeBacklogFull tests that uploads get 429 with
+// Retry-After while the transcode backlog is full
+func TestHandleUpload_TranscodeBacklogFull(t *testing.T) {
+	mockVideoService, _, _, _,
+		_, mockResponseHandler, mockLogger := helpers.SetupMockServices()
+
+	scheduler := video.NewTranscodeScheduler(video.TranscodeSchedulerConfig{
+		Workers:    1,
+		MaxBacklog: 1,
+		RetryAfter: 1500 * time.Millisecond,
+	}, nil)
+	scheduler.Start()
+	defer scheduler.Stop()
+	release := blockWorker(t, scheduler)
+	defer release()
+	scheduler.Submit(context.Background(), video.TranscodeTask{Run: func(context.Context) error { return nil }})
+
+	handler := video.NewVideoHandler(&video.App{
+		Config:          helpers.VideoConfigForTest(),
+		Video:           mockVideoService,
+		ResponseHandler: mockResponseHandler,
+		Logger:          mockLogger,
+		Transcodes:      scheduler,
+	})
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest("POST", "/video/upload", nil)
+	ctx.Set("request_id", "test-request-id")
+
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusTooManyRequests, "RATE_LIMITED", mock.Anything, mock.Anything).Return()
+
+	handler.HandleUpload(ctx)
+
+	mockResponseHandler.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "InitializeUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"), "rounded up to whole seconds")
+}
+
+// TestHandleUpload_TempSpaceExhausted tests that uploads get 503 when their
+// body would take temporary storage past its budget
+func TestHandleUpload_TempSpaceExhausted(t *testing.T) {
+	mockVideoService, _, _, _,
+		mockTempManager, mockResponseHandler, mockLogger := helpers.SetupMockServices()
+
+	handler := video.NewVideoHandler(&video.App{
+		Config:          helpers.VideoConfigForTest(),
+		Video:           mockVideoService,
+		ResponseHandler: mockResponseHandler,
+		Logger:          mockLogger,
+		TempSpace:       mockTempManager,
+	})
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest("POST", "/video/upload", bytes.NewReader(make([]byte, 2048)))
+	ctx.Set("request_id", "test-request-id")
+
+	mockTempManager.On("CheckSpace", int64(2048)).Return(tempfile.ErrSpaceExhausted)
+	mockLogger.On("LogInfo", "Upload rejected, temporary storage is full", mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", mock.Anything, tempfile.ErrSpaceExhausted).Return()
+
+	handler.HandleUpload(ctx)
+
+	mockTempManager.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "InitializeUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+// TestHandleUpload_InvalidMedia tests that files failing the probe get 422
+func TestHandleUpload_InvalidMedia(t *testing.T) {
+	mockVideoService, _, _, _,
+		_, mockResponseHandler, mockLogger := helpers.SetupMockServices()
+
+	webhooks := new(mocks.MockWebhookPublisher)
+	config := helpers.VideoConfigForTest()
+	config.Video.AllowedFormats = []string{".mp4"}
+	handler := video.NewVideoHandler(&video.App{
+		Config:          config,
+		Video:           mockVideoService,
+		ResponseHandler: mockResponseHandler,
+		Logger:          mockLogger,
+		Webhooks:        webhooks,
+	})
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("video", "notes.mp4")
+	part.Write([]byte("not a video"))
+	writer.WriteField("title", "notes.mp4")
+	writer.Close()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest("POST", "/video/upload", body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx.Set("request_id", "test-request-id")
+	userID := uuid.New()
+	ctx.Set("userID", userID.String())
+
+	invalid := fmt.Errorf("%w: the file is not a readable video", video.ErrInvalidMedia)
+	videoID := uuid.New()
+	mockVideoService.On("InitializeUpload", mock.Anything, userID, "notes.mp4", "", mock.Anything, video.Taxonomy{Tags: []string{}}).
+		Return(&video.VideoUpload{ID: uuid.New(), VideoID: videoID, Status: video.UploadStatusPending}, nil)
+	webhooks.On("Publish", mock.Anything, userID, video.WebhookVideoFailed, map[string]interface{}{
+		"video_id": videoID,
+		"title":    "notes.mp4",
+		"reason":   invalid.Error(),
+	}).Return(nil)
+	mockVideoService.On("ProcessUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(invalid)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusUnprocessableEntity, "ERR_INVALID_MEDIA", invalid.Error(), invalid).Return()
+
+	handler.HandleUpload(ctx)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	webhooks.AssertExpectations(t)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+// TestHandleUpload_Duplicate tests that re-uploading a file returns the earlier video
+func TestHandleUpload_Duplicate(t *testing.T) {
+	mockVideoService, _, _, _,
+		_, mockResponseHandler, mockLogger := helpers.SetupMockServices()
+
+	config := helpers.VideoConfigForTest()
+	config.Video.AllowedFormats = []string{".mp4"}
+	handler := video.NewVideoHandler(&video.App{
+		Config:          config,
+		Video:           mockVideoService,
+		ResponseHandler: mockResponseHandler,
+		Logger:          mockLogger,
+		// No expectations: webhooks were already told about the earlier video
+		Webhooks: new(mocks.MockWebhookPublisher),
+	})
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("video", "again.mp4")
+	part.Write([]byte("test video file contents"))
+	writer.WriteField("title", "again.mp4")
+	writer.Close()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request, _ = http.NewRequest("POST", "/video/upload", body)
+	ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ctx.Set("request_id", "test-request-id")
+
+	existing := &video.Video{ID: uuid.New(), FileID: "earlier-file-id", StoragePath: "videos/earlier/original.mp4"}
+	mockVideoService.On("InitializeUpload", mock.Anything, mock.Anything, "again.mp4", "", mock.Anything, video.Taxonomy{Tags: []string{}}).
+		Return(&video.VideoUpload{ID: uuid.New(), VideoID: uuid.New(), Status: video.UploadStatusPending}, nil)
+	mockVideoService.On("ProcessUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			upload := args.Get(1).(*video.VideoUpload)
+			upload.DuplicateOf = existing.ID
+			upload.Status = video.UploadStatusCompleted
+		}).
+		Return(nil)
+	mockVideoService.On("GetVideo", mock.Anything, existing.ID).Return(existing, nil)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(response video.UploadResponse) bool {
+		return response.Duplicate && response.ID == existing.ID.String() && response.FileID == "earlier-file-id"
+	}), "Upload completed successfully").Return()
+
+	handler.HandleUpload(ctx)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
@@ -0,0 +1,211 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/helpers"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/tests/mocks"
+)
+
+// replaceContext returns a request replacing testVideo's file, made by userID
+func replaceContext(t *testing.T, videoID, userID uuid.UUID) *gin.Context {
+	t.Helper()
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("video", "new-cut.mp4")
+	require.NoError(t, err)
+	part.Write([]byte("new video file contents"))
+	require.NoError(t, writer.Close())
+
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("POST", "/video/"+videoID.String()+"/replace", body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	c.Set("userID", userID.String())
+	c.Set("role", "user")
+	return c
+}
+
+// replaceDependencies returns mocks with upload limits the test file meets
+func replaceDependencies() (*mocks.MockVideoService, *mocks.MockResponseHandler, *mocks.MockLogger, *video.App) {
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+	app.Config.Video.MaxFileSize = 10 * 1024 * 1024
+	app.Config.Video.AllowedFormats = []string{".mp4", ".mov"}
+	app.Config.Video.MaxTitleLength = 100
+	app.Config.Video.MaxDescLength = 1000
+	return mockVideoService, mockResponseHandler, mockLogger, app
+}
+
+// TestHandleReplace_Success tests that the owner's new file is processed as the next version
+func TestHandleReplace_Success(t *testing.T) {
+	owner := uuid.New()
+	testVideo := &video.Video{ID: uuid.New(), UserID: owner, Title: "Original cut", StoragePath: "videos/x/original.mp4"}
+	c := replaceContext(t, testVideo.ID, owner)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := replaceDependencies()
+	webhooks := new(mocks.MockWebhookPublisher)
+	app.Webhooks = webhooks
+	upload := &video.VideoUpload{ID: uuid.New(), VideoID: testVideo.ID, Status: video.UploadStatusPending, Version: 2}
+	replaced := *testVideo
+	replaced.Upload = &video.VideoUpload{Status: video.UploadStatusCompleted, Version: 2}
+
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil).Once()
+	mockVideoService.On("ReplaceVideo", mock.Anything, testVideo.ID, int64(len("new video file contents"))).Return(upload, nil)
+	mockVideoService.On("ProcessUpload", mock.Anything, upload, mock.Anything, mock.Anything).Return(nil)
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(&replaced, nil).Once()
+	webhooks.On("Publish", mock.Anything, owner, video.WebhookVideoProcessed, mock.Anything).Return(nil)
+	mockLogger.On("LogInfo", mock.Anything, mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(resp video.UploadResponse) bool {
+		return resp.ID == testVideo.ID.String() && resp.Version == 2 && !resp.Duplicate
+	}), "Video replaced successfully").Return()
+
+	video.NewVideoHandler(app).HandleReplace(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+	webhooks.AssertExpectations(t)
+}
+
+// TestHandleReplace_NotOwner tests that only the owner can replace a video, staff included
+func TestHandleReplace_NotOwner(t *testing.T) {
+	testVideo := &video.Video{ID: uuid.New(), UserID: uuid.New(), Title: "Original cut"}
+	c := replaceContext(t, testVideo.ID, uuid.New())
+	c.Set("role", "admin")
+
+	mockVideoService, mockResponseHandler, _, app := replaceDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).HandleReplace(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "ReplaceVideo", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestHandleReplace_InProgress tests that a video still being processed cannot be replaced
+func TestHandleReplace_InProgress(t *testing.T) {
+	owner := uuid.New()
+	testVideo := &video.Video{ID: uuid.New(), UserID: owner, Title: "Original cut"}
+	c := replaceContext(t, testVideo.ID, owner)
+
+	mockVideoService, mockResponseHandler, _, app := replaceDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockVideoService.On("ReplaceVideo", mock.Anything, testVideo.ID, mock.Anything).Return(nil, video.ErrReplaceInProgress)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusConflict, "CONFLICT", video.ErrReplaceInProgress.Error(), video.ErrReplaceInProgress).Return()
+
+	video.NewVideoHandler(app).HandleReplace(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "ProcessUpload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestListVersions tests that staff can see a video's version history
+func TestListVersions(t *testing.T) {
+	testVideo := &video.Video{ID: uuid.New(), UserID: uuid.New()}
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/video/"+testVideo.ID.String()+"/versions", nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "moderator")
+
+	replacedAt := time.Now()
+	versions := []video.VersionInfo{
+		{Version: 2, Current: true, FileSize: 20},
+		{Version: 1, FileSize: 10, ReplacedAt: &replacedAt},
+	}
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockVideoService.On("ListVersions", mock.Anything, testVideo.ID).Return(versions, nil)
+	mockResponseHandler.On("SuccessResponse", mock.Anything, video.VersionListResponse{
+		VideoID:  testVideo.ID.String(),
+		Versions: versions,
+	}, "Versions retrieved successfully").Return()
+
+	video.NewVideoHandler(app).ListVersions(c)
+
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestListVersions_Forbidden tests that other users cannot see a video's versions
+func TestListVersions_Forbidden(t *testing.T) {
+	testVideo := &video.Video{ID: uuid.New(), UserID: uuid.New()}
+	c, _ := helpers.SetupTestContext()
+	c.Request = httptest.NewRequest("GET", "/video/"+testVideo.ID.String()+"/versions", nil)
+	c.Params = []gin.Param{{Key: "id", Value: testVideo.ID.String()}}
+	c.Set("userID", uuid.New().String())
+	c.Set("role", "user")
+
+	mockVideoService, mockResponseHandler, _, app := helpers.SetupMockDependencies()
+	mockVideoService.On("GetVideo", mock.Anything, testVideo.ID).Return(testVideo, nil)
+	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusForbidden, "FORBIDDEN", mock.Anything, nil).Return()
+
+	video.NewVideoHandler(app).ListVersions(c)
+
+	mockResponseHandler.AssertExpectations(t)
+	mockVideoService.AssertNotCalled(t, "ListVersions", mock.Anything, mock.Anything)
+}
+
+// TestVideoService_ReplaceVideo verifies replacing a completed upload
+// archives it as a version, and a failed archive leaves the video as it was
+func TestVideoService_ReplaceVideo(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMemoryVideoRepository()
+	storage := new(mocks.MockStorageService)
+	logger := new(mocks.MockLogger)
+	logger.On("LogInfo", "Video replacement started", mock.Anything).Return()
+	service := video.NewVideoService(repo, nil, storage, nil, video.LimitsConfig{}, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+	v := createVideo(t, repo, &video.Video{Title: "Talk", Checksum: "abc", FileSize: 100, IPFSCID: "cid-1", Replication: video.ReplicationReplicated})
+	storage.On("ArchiveVideo", mock.Anything, v.ID, 0).Return(nil).Once()
+
+	upload, err := service.ReplaceVideo(ctx, v.ID, 200)
+	require.NoError(t, err)
+	require.Equal(t, video.UploadStatusPending, upload.Status)
+	require.Equal(t, 1, upload.Version)
+
+	stored, err := repo.GetByID(ctx, v.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(200), stored.FileSize)
+	require.Empty(t, stored.IPFSCID)
+	require.Equal(t, video.ReplicationS3Only, stored.Replication)
+
+	versions, err := service.ListVersions(ctx, v.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.True(t, versions[0].Current)
+	require.Equal(t, 1, versions[0].Version)
+	require.Equal(t, 0, versions[1].Version)
+	require.Equal(t, "abc", versions[1].Checksum)
+	require.Equal(t, int64(100), versions[1].FileSize)
+
+	_, err = service.ReplaceVideo(ctx, v.ID, 300)
+	require.ErrorIs(t, err, video.ErrReplaceInProgress, "the new file is still pending")
+
+	// Archiving the files fails, so the version and the upload's reset roll back
+	failing := createVideo(t, repo, &video.Video{Title: "Failing", FileSize: 100})
+	storage.On("ArchiveVideo", mock.Anything, failing.ID, 0).Return(errors.New("access denied")).Once()
+	_, err = service.ReplaceVideo(ctx, failing.ID, 200)
+	require.Error(t, err)
+
+	stored, err = repo.GetByID(ctx, failing.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), stored.FileSize)
+	require.Equal(t, video.UploadStatusCompleted, stored.Upload.Status)
+	versions, err = service.ListVersions(ctx, failing.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 1)
+	storage.AssertExpectations(t)
+}
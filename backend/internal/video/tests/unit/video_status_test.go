@@ -56,7 +56,7 @@ func TestGetVideoStatus_Success(t *testing.T) {
 	}
 
 	// Set up mock expectations
-	mockVideoService.On("GetVideo", videoID).Return(testVideo, nil)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(testVideo, nil)
 	mockLogger.On("LogInfo", "Video status retrieved successfully", mock.Anything).Return()
 	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.Anything, "Video status retrieved successfully").Return()
 
@@ -73,6 +73,110 @@ func TestGetVideoStatus_Success(t *testing.T) {
 	assert.Equal(t, 200, w.Code, "Should return HTTP 200 OK")
 }
 
+// TestGetVideoStatus_TranscodeTimeout tests that renditions stopped at their deadline are reported apart from encode errors
+func TestGetVideoStatus_TranscodeTimeout(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/videos/%s/status", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	helpers.AuthenticateRequest(c)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	testVideo := &video.Video{
+		ID: videoID,
+		Upload: &video.VideoUpload{
+			VideoID: videoID,
+			Status:  video.UploadStatusCompleted,
+			TranscodeFailures: video.TranscodeFailures{
+				"720p": video.TranscodeFailureTimeout,
+				"480p": video.TranscodeFailureEncode,
+			},
+		},
+	}
+
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(testVideo, nil)
+	mockLogger.On("LogInfo", "Video status retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(data video.VideoStatusResponse) bool {
+		return data.Status == "completed" &&
+			data.TranscodeFailures["720p"] == video.TranscodeFailureTimeout &&
+			data.TranscodeFailures["480p"] == video.TranscodeFailureEncode
+	}), "Video status retrieved successfully").Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.GetVideoStatus(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
+// TestGetVideoStatus_Progress tests that the stage, timing and IPFS pinning of an upload in progress are reported
+func TestGetVideoStatus_Progress(t *testing.T) {
+	c, _ := helpers.SetupTestContext()
+
+	videoID := uuid.New()
+	c.Request = httptest.NewRequest("GET", fmt.Sprintf("/videos/%s/status", videoID), nil)
+	c.Params = []gin.Param{{Key: "id", Value: videoID.String()}}
+	helpers.AuthenticateRequest(c)
+
+	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
+
+	started := time.Now().Add(-time.Minute)
+	transcoding := started.Add(30 * time.Second)
+	testVideo := &video.Video{
+		ID:          videoID,
+		FileSize:    2048,
+		StoragePath: fmt.Sprintf("videos/%s/original.mp4", videoID),
+		Replication: video.ReplicationReplicated,
+		Transcodes: []video.Transcode{{Segments: []video.TranscodeSegment{
+			{Replication: video.ReplicationReplicating},
+		}}},
+		Upload: &video.VideoUpload{
+			VideoID:     videoID,
+			Status:      video.UploadStatusUploading,
+			StartTime:   started,
+			StoredBytes: 2048,
+			Stage:       video.UploadStageTranscoding,
+			StageTimes: video.StageTimes{
+				video.UploadStageReceiving:   started,
+				video.UploadStageTranscoding: transcoding,
+			},
+			Renditions: video.RenditionStatuses{
+				"1080p": video.RenditionCompleted,
+				"720p":  video.RenditionTranscoding,
+				"480p":  video.RenditionQueued,
+			},
+			RenditionTimes: video.RenditionTimes{
+				"720p": {StartedAt: &transcoding},
+			},
+			RenditionProgress: video.RenditionProgress{"1080p": 100, "720p": 42},
+		},
+	}
+
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(testVideo, nil)
+	mockLogger.On("LogInfo", "Video status retrieved successfully", mock.Anything).Return()
+	mockResponseHandler.On("SuccessResponse", mock.Anything, mock.MatchedBy(func(data video.VideoStatusResponse) bool {
+		return data.Status == "uploading" &&
+			data.Stage == video.UploadStageTranscoding &&
+			data.StageTimes[video.UploadStageTranscoding].Equal(transcoding) &&
+			data.StartedAt != nil && data.StartedAt.Equal(started) &&
+			data.EndedAt == nil &&
+			data.StoredBytes == 2048 && data.TotalBytes == 2048 &&
+			data.RenditionTimes["720p"].StartedAt.Equal(transcoding) &&
+			data.RenditionTimes["720p"].FinishedAt == nil &&
+			data.RenditionProgress["720p"] == 42 &&
+			data.TranscodePercent == 47 &&
+			data.IPFSPinned == 1 && data.IPFSFiles == 2
+	}), "Video status retrieved successfully").Return()
+
+	handler := video.NewVideoHandler(app)
+	handler.GetVideoStatus(c)
+
+	mockVideoService.AssertExpectations(t)
+	mockResponseHandler.AssertExpectations(t)
+}
+
 // TestGetVideoStatus_InvalidID tests getting a video status with an invalid ID
 func TestGetVideoStatus_InvalidID(t *testing.T) {
 	// Setup test context
@@ -123,10 +227,10 @@ func TestGetVideoStatus_NotFound(t *testing.T) {
 	mockVideoService, mockResponseHandler, mockLogger, app := helpers.SetupMockDependencies()
 
 	// Create a not found error
-	notFoundErr := fmt.Errorf("video not found: %s", videoID)
+	notFoundErr := fmt.Errorf("%w: %s", video.ErrVideoNotFound, videoID)
 
 	// Set up mock expectations for not found case
-	mockVideoService.On("GetVideo", videoID).Return(nil, notFoundErr)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(nil, notFoundErr)
 	mockLogger.On("LogInfo", "Video not found or has been deleted", mock.Anything).Return()
 	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusNotFound, "VIDEO_NOT_FOUND", fmt.Sprintf("video not found: %s", videoID), nil).Return()
 
@@ -161,7 +265,7 @@ func TestGetVideoStatus_DatabaseError(t *testing.T) {
 
 	// Set up mock expectations for database error case
 	dbErr := fmt.Errorf("database error")
-	mockVideoService.On("GetVideo", videoID).Return(nil, dbErr)
+	mockVideoService.On("GetVideo", mock.Anything, videoID).Return(nil, dbErr)
 	mockLogger.On("LogInfo", "Failed to get video status", mock.Anything).Return()
 	mockResponseHandler.On("ErrorResponse", mock.Anything, http.StatusInternalServerError, "DATABASE_ERROR", "Failed to retrieve video status", dbErr).Return()
 
@@ -0,0 +1,643 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// Storage classes originals can be moved to. They all serve reads at once,
+// which streaming, reprocessing and regenerating renditions rely on, so
+// classes that need a restore first, like GLACIER and DEEP_ARCHIVE, are not
+// offered.
+const (
+	StorageClassStandardIA         = "STANDARD_IA"
+	StorageClassOneZoneIA          = "ONEZONE_IA"
+	StorageClassIntelligentTiering = "INTELLIGENT_TIERING"
+	StorageClassGlacierIR          = "GLACIER_IR"
+)
+
+// StorageClassStandard is reported for originals in the bucket's default class
+const StorageClassStandard = "STANDARD"
+
+// ValidTieringStorageClass reports whether originals can be moved to class
+func ValidTieringStorageClass(class string) bool {
+	switch class {
+	case StorageClassStandardIA, StorageClassOneZoneIA, StorageClassIntelligentTiering, StorageClassGlacierIR:
+		return true
+	}
+	return false
+}
+
+// TieringOverride is how storage tiering treats one video
+type TieringOverride string
+
+const (
+	// TieringDefault follows the tiering policy
+	TieringDefault TieringOverride = "default"
+	// TieringExempt leaves the video out of tiering. An original already
+	// moved stays in its class; dropped renditions are regenerated.
+	TieringExempt TieringOverride = "exempt"
+	// TieringArchive tiers the video at the next run, whatever its age and views
+	TieringArchive TieringOverride = "archive"
+)
+
+// IsValid reports whether o is a known override
+func (o TieringOverride) IsValid() bool {
+	switch o {
+	case TieringDefault, TieringExempt, TieringArchive:
+		return true
+	}
+	return false
+}
+
+// Actions taken by storage tiering
+const (
+	// TieringActionOriginal moves an original to the cheaper storage class
+	TieringActionOriginal = "original"
+	// TieringActionRenditions drops a video's resolutions until it is streamed again
+	TieringActionRenditions = "renditions"
+)
+
+// tieringActions lists the actions in the order a tiering run takes them
+var tieringActions = []string{TieringActionOriginal, TieringActionRenditions}
+
+// StorageTierer changes the storage class of stored files and deletes single
+// files. The S3 backend implements it.
+type StorageTierer interface {
+	SetStorageClass(ctx context.Context, key, class string) error
+	DeleteFile(ctx context.Context, key string) error
+}
+
+// Reprocessor transcodes a video's stored original again
+type Reprocessor interface {
+	ReprocessVideo(ctx context.Context, videoID uuid.UUID) error
+}
+
+// TieringConfig controls the storage tiering worker
+type TieringConfig struct {
+	// Interval between tiering runs
+	Interval time.Duration
+	// StorageClass is the class originals are moved to; see ValidTieringStorageClass
+	StorageClass string
+	// OriginalsAfter is how long after its upload completed an original is
+	// moved; 0 only moves those of videos overridden to archive
+	OriginalsAfter time.Duration
+	// RenditionsIdleAfter is how long a video goes unwatched before its
+	// resolutions are dropped; 0 only drops those of videos overridden to archive
+	RenditionsIdleAfter time.Duration
+	// BatchSize caps the videos tiered for each action in one run
+	BatchSize int
+	// DryRun logs and counts what would be tiered without changing anything
+	DryRun bool
+}
+
+// TieringResult summarises one tiering run
+type TieringResult struct {
+	// Candidates lists the videos found for tiering, by action
+	Candidates map[string][]uuid.UUID
+	// Applied is the number of actions taken; always 0 in a dry run
+	Applied int
+	// Failed is the number of actions that failed
+	Failed int
+}
+
+// TieringRun is the outcome of a tiering run, as reported to admins
+type TieringRun struct {
+	FinishedAt time.Time      `json:"finished_at"`
+	DryRun     bool           `json:"dry_run"`
+	Candidates map[string]int `json:"candidates"`
+	Applied    int            `json:"applied"`
+	Failed     int            `json:"failed"`
+}
+
+// StorageClassUsage counts the originals kept in a storage class
+type StorageClassUsage struct {
+	StorageClass string `json:"storage_class" example:"STANDARD_IA"`
+	Videos       int64  `json:"videos"`
+	Bytes        int64  `json:"bytes"`
+}
+
+// TieringReport shows the tiering policy and where videos stand
+type TieringReport struct {
+	// Enabled is whether tiering runs periodically; overrides and
+	// regenerating renditions work either way
+	Enabled            bool   `json:"enabled"`
+	DryRun             bool   `json:"dry_run"`
+	StorageClass       string `json:"storage_class"`
+	OriginalsAfterDays int    `json:"originals_after_days"`
+	RenditionsIdleDays int    `json:"renditions_idle_days"`
+	// Originals counts originals and their bytes by storage class
+	Originals []StorageClassUsage `json:"originals"`
+	// RenditionsDropped counts videos whose resolutions are dropped
+	RenditionsDropped int64 `json:"renditions_dropped"`
+	// Regenerating counts videos whose renditions this instance is transcoding again
+	Regenerating int `json:"regenerating"`
+	// Overrides counts videos that do not follow the policy, by override
+	Overrides map[TieringOverride]int64 `json:"overrides"`
+	// LastRun is the outcome of this instance's latest run; absent before the first
+	LastRun *TieringRun `json:"last_run,omitempty"`
+}
+
+// VideoTiering is the tiering state of one video
+type VideoTiering struct {
+	VideoID             uuid.UUID       `json:"video_id"`
+	Override            TieringOverride `json:"override" swaggertype:"string" enums:"default,exempt,archive"`
+	StorageClass        string          `json:"storage_class"`
+	RenditionsDroppedAt *time.Time      `json:"renditions_dropped_at,omitempty"`
+	Regenerating        bool            `json:"regenerating"`
+}
+
+// TieringOverrideRequest sets how storage tiering treats a video
+type TieringOverrideRequest struct {
+	Override TieringOverride `json:"override" binding:"required,enum" swaggertype:"string" enums:"default,exempt,archive" example:"exempt"`
+}
+
+// TieringMetrics exports tiering outcomes to Prometheus. A nil
+// *TieringMetrics is valid and records nothing.
+type TieringMetrics struct {
+	candidates    *prometheus.GaugeVec
+	applied       *prometheus.CounterVec
+	failed        *prometheus.CounterVec
+	regenerations prometheus.Counter
+	lastRun       prometheus.Gauge
+}
+
+// NewTieringMetrics creates tiering metrics and registers them with registerer
+func NewTieringMetrics(registerer prometheus.Registerer) *TieringMetrics {
+	m := &TieringMetrics{
+		candidates: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_tiering",
+			Name:      "candidates",
+			Help:      "Videos found for tiering in the latest run, including dry runs.",
+		}, []string{"action"}),
+		applied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_tiering",
+			Name:      "applied_total",
+			Help:      "Originals moved to a cheaper storage class and videos whose renditions were dropped.",
+		}, []string{"action"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_tiering",
+			Name:      "failures_total",
+			Help:      "Tiering actions that failed.",
+		}, []string{"action"}),
+		regenerations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_tiering",
+			Name:      "regenerations_total",
+			Help:      "Dropped renditions transcoded again because the video was streamed.",
+		}),
+		lastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_tiering",
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Unix time the latest tiering run finished.",
+		}),
+	}
+	registerer.MustRegister(m.candidates, m.applied, m.failed, m.regenerations, m.lastRun)
+	return m
+}
+
+func (m *TieringMetrics) recordRun(result TieringResult) {
+	if m == nil {
+		return
+	}
+	for _, action := range tieringActions {
+		m.candidates.WithLabelValues(action).Set(float64(len(result.Candidates[action])))
+	}
+	m.lastRun.SetToCurrentTime()
+}
+
+func (m *TieringMetrics) recordApplied(action string) {
+	if m == nil {
+		return
+	}
+	m.applied.WithLabelValues(action).Inc()
+}
+
+func (m *TieringMetrics) recordFailure(action string) {
+	if m == nil {
+		return
+	}
+	m.failed.WithLabelValues(action).Inc()
+}
+
+func (m *TieringMetrics) recordRegeneration() {
+	if m == nil {
+		return
+	}
+	m.regenerations.Inc()
+}
+
+// StorageTiering cuts storage costs of videos as they age: it periodically
+// moves originals to a cheaper storage class and drops the resolutions of
+// videos nobody watches, which are transcoded again from the original when
+// the video is next streamed. The audio-only rendition and the preview are
+// small and kept.
+type StorageTiering struct {
+	db          *gorm.DB
+	storage     StorageTierer
+	reprocessor Reprocessor
+	cache       *VideoCache
+	config      TieringConfig
+	metrics     *TieringMetrics
+	logger      Logger
+	now         func() time.Time
+
+	// ctx is cancelled by Stop, ending periodic runs and regenerations
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running bool
+	lastRun *TieringRun
+	// regenerating holds the videos whose renditions are being transcoded again
+	regenerating map[uuid.UUID]struct{}
+}
+
+// NewStorageTiering creates a tiering worker; call Start to run it
+// periodically. Renditions can be regenerated and overrides set without
+// starting it. cache may be nil.
+func NewStorageTiering(db *gorm.DB, storage StorageTierer, reprocessor Reprocessor, cache *VideoCache, config TieringConfig, metrics *TieringMetrics, logger Logger) *StorageTiering {
+	if config.Interval <= 0 {
+		config.Interval = 24 * time.Hour
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 100
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &StorageTiering{
+		db:           db,
+		storage:      storage,
+		reprocessor:  reprocessor,
+		cache:        cache,
+		config:       config,
+		metrics:      metrics,
+		logger:       logger,
+		now:          time.Now,
+		ctx:          ctx,
+		cancel:       cancel,
+		regenerating: make(map[uuid.UUID]struct{}),
+	}
+}
+
+// Start runs tiering once and then on every interval until Stop is called
+func (t *StorageTiering) Start() {
+	t.mu.Lock()
+	t.running = true
+	t.mu.Unlock()
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(t.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := t.RunOnce(t.ctx); err != nil && t.ctx.Err() == nil {
+				t.logger.LogError("Storage tiering failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			select {
+			case <-t.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops periodic runs and regenerations and waits for them to finish.
+// An interrupted regeneration starts over when the video is next streamed.
+func (t *StorageTiering) Stop() {
+	// Cancelling under the lock keeps regenerations from starting after Wait
+	t.mu.Lock()
+	t.cancel()
+	t.mu.Unlock()
+	t.wg.Wait()
+}
+
+// RunOnce finds tiering candidates and, unless in dry-run mode, moves their
+// originals and drops their renditions. Failed actions are logged and
+// retried on the next run.
+func (t *StorageTiering) RunOnce(ctx context.Context) (TieringResult, error) {
+	result := TieringResult{Candidates: make(map[string][]uuid.UUID)}
+
+	originals, err := t.originalCandidates(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to find originals to tier: %w", err)
+	}
+	result.Candidates[TieringActionOriginal] = originals
+
+	renditions, err := t.renditionCandidates(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to find idle renditions: %w", err)
+	}
+	result.Candidates[TieringActionRenditions] = renditions
+
+	defer func() { t.recordRun(result) }()
+
+	if t.config.DryRun {
+		if len(originals)+len(renditions) > 0 {
+			t.logger.LogInfo("Tiering dry run: videos that would be tiered", map[string]interface{}{
+				"originals":     originals,
+				"renditions":    renditions,
+				"storage_class": t.config.StorageClass,
+			})
+		}
+		return result, nil
+	}
+
+	for _, action := range tieringActions {
+		apply := t.tierOriginal
+		if action == TieringActionRenditions {
+			apply = t.dropRenditions
+		}
+		for _, id := range result.Candidates[action] {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			if err := apply(ctx, id); err != nil {
+				result.Failed++
+				t.metrics.recordFailure(action)
+				t.logger.LogError("Failed to tier video", map[string]interface{}{
+					"error":    err.Error(),
+					"video_id": id,
+					"action":   action,
+				})
+				continue
+			}
+			result.Applied++
+			t.metrics.recordApplied(action)
+		}
+	}
+
+	if result.Applied > 0 || result.Failed > 0 {
+		t.logger.LogInfo("Tiered video storage", map[string]interface{}{
+			"applied": result.Applied,
+			"failed":  result.Failed,
+		})
+	}
+	return result, nil
+}
+
+// recordRun keeps the outcome of a run for the report and exports it
+func (t *StorageTiering) recordRun(result TieringResult) {
+	t.metrics.recordRun(result)
+
+	run := &TieringRun{
+		FinishedAt: t.now(),
+		DryRun:     t.config.DryRun,
+		Candidates: make(map[string]int),
+		Applied:    result.Applied,
+		Failed:     result.Failed,
+	}
+	for action, ids := range result.Candidates {
+		run.Candidates[action] = len(ids)
+	}
+	t.mu.Lock()
+	t.lastRun = run
+	t.mu.Unlock()
+}
+
+// completedVideos selects live videos whose upload completed, with a stored original
+func (t *StorageTiering) completedVideos(ctx context.Context) *gorm.DB {
+	return t.db.WithContext(ctx).Model(&Video{}).
+		Joins("JOIN video_uploads ON video_uploads.video_id = videos.id").
+		Where("video_uploads.status = ? AND videos.storage_path <> ''", UploadStatusCompleted).
+		Where("videos.tiering_override <> ?", TieringExempt)
+}
+
+// originalCandidates returns videos whose original is due to move to the
+// storage class, oldest uploads first. The age counts from when the current
+// file's upload completed, so a replaced original gets a full period.
+func (t *StorageTiering) originalCandidates(ctx context.Context) ([]uuid.UUID, error) {
+	query := t.completedVideos(ctx).Where("videos.storage_class <> ?", t.config.StorageClass)
+	if t.config.OriginalsAfter > 0 {
+		cutoff := t.now().Add(-t.config.OriginalsAfter)
+		query = query.Where("(video_uploads.end_time < ? OR videos.tiering_override = ?)", cutoff, TieringArchive)
+	} else {
+		query = query.Where("videos.tiering_override = ?", TieringArchive)
+	}
+
+	var ids []uuid.UUID
+	err := query.Order("video_uploads.end_time").Limit(t.config.BatchSize).Pluck("videos.id", &ids).Error
+	return ids, err
+}
+
+// renditionCandidates returns videos with stored resolutions that have not
+// been watched since the idle cutoff, longest idle first. Videos never
+// watched count as idle from when their upload completed.
+func (t *StorageTiering) renditionCandidates(ctx context.Context) ([]uuid.UUID, error) {
+	query := t.completedVideos(ctx).
+		Where("videos.renditions_dropped_at IS NULL").
+		Where("EXISTS (SELECT 1 FROM transcodes WHERE transcodes.video_id = videos.id AND transcodes.format = 'mp4')")
+	if t.config.RenditionsIdleAfter > 0 {
+		cutoff := t.now().Add(-t.config.RenditionsIdleAfter)
+		query = query.Where("(COALESCE(videos.last_viewed_at, video_uploads.end_time) < ? OR videos.tiering_override = ?)", cutoff, TieringArchive)
+	} else {
+		query = query.Where("videos.tiering_override = ?", TieringArchive)
+	}
+
+	var ids []uuid.UUID
+	err := query.Order("COALESCE(videos.last_viewed_at, video_uploads.end_time)").
+		Limit(t.config.BatchSize).Pluck("videos.id", &ids).Error
+	return ids, err
+}
+
+// tierOriginal moves a video's original to the storage class
+func (t *StorageTiering) tierOriginal(ctx context.Context, videoID uuid.UUID) error {
+	var video Video
+	if err := t.db.WithContext(ctx).Select("id", "storage_path").First(&video, "id = ?", videoID).Error; err != nil {
+		return fmt.Errorf("failed to get video: %w", err)
+	}
+	if err := t.storage.SetStorageClass(ctx, video.StoragePath, t.config.StorageClass); err != nil {
+		return err
+	}
+	if err := t.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).
+		UpdateColumn("storage_class", t.config.StorageClass).Error; err != nil {
+		return fmt.Errorf("failed to record storage class: %w", err)
+	}
+	t.cache.Invalidate(ctx, videoID)
+	return nil
+}
+
+// dropRenditions deletes a video's stored resolutions. The video is marked
+// first, so streams regenerate the renditions rather than miss them; if a
+// file cannot be deleted the mark stays, and the file is replaced when the
+// renditions are regenerated.
+func (t *StorageTiering) dropRenditions(ctx context.Context, videoID uuid.UUID) error {
+	var transcodes []Transcode
+	if err := t.db.WithContext(ctx).Preload("Segments").
+		Where("video_id = ? AND format = ?", videoID, "mp4").Find(&transcodes).Error; err != nil {
+		return fmt.Errorf("failed to get transcodes: %w", err)
+	}
+
+	if err := t.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).
+		UpdateColumn("renditions_dropped_at", t.now()).Error; err != nil {
+		return fmt.Errorf("failed to mark renditions dropped: %w", err)
+	}
+	t.cache.Invalidate(ctx, videoID)
+
+	for _, transcode := range transcodes {
+		for _, segment := range transcode.Segments {
+			if err := t.storage.DeleteFile(ctx, segment.StoragePath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RegenerateRenditions transcodes a video's dropped renditions again in the
+// background. Calls for a video already being regenerated do nothing.
+func (t *StorageTiering) RegenerateRenditions(videoID uuid.UUID) {
+	t.mu.Lock()
+	if _, ok := t.regenerating[videoID]; ok || t.ctx.Err() != nil {
+		t.mu.Unlock()
+		return
+	}
+	t.regenerating[videoID] = struct{}{}
+	t.wg.Add(1)
+	t.mu.Unlock()
+	t.metrics.recordRegeneration()
+
+	go func() {
+		defer t.wg.Done()
+		defer func() {
+			t.mu.Lock()
+			delete(t.regenerating, videoID)
+			t.mu.Unlock()
+		}()
+
+		// The video is being watched, so its idle period starts over and the
+		// renditions are not dropped again at the next run
+		if err := t.db.WithContext(t.ctx).Model(&Video{}).Where("id = ?", videoID).
+			UpdateColumn("last_viewed_at", t.now()).Error; err != nil {
+			t.logger.LogError("Failed to record video as watched", map[string]interface{}{
+				"error":    err.Error(),
+				"video_id": videoID,
+			})
+		}
+
+		t.logger.LogInfo("Regenerating dropped renditions", map[string]interface{}{
+			"video_id": videoID,
+		})
+		if err := t.reprocessor.ReprocessVideo(t.ctx, videoID); err != nil {
+			t.logger.LogError("Failed to regenerate dropped renditions", map[string]interface{}{
+				"error":    err.Error(),
+				"video_id": videoID,
+			})
+		}
+	}()
+}
+
+// isRegenerating reports whether the video's renditions are being transcoded again
+func (t *StorageTiering) isRegenerating(videoID uuid.UUID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.regenerating[videoID]
+	return ok
+}
+
+// SetOverride changes how tiering treats a video. Exempting a video whose
+// renditions were dropped regenerates them.
+func (t *StorageTiering) SetOverride(ctx context.Context, videoID uuid.UUID, override TieringOverride) (*VideoTiering, error) {
+	var video Video
+	if err := t.db.WithContext(ctx).Select("id", "storage_class", "renditions_dropped_at").
+		First(&video, "id = ?", videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrVideoNotFound, videoID)
+		}
+		return nil, fmt.Errorf("failed to get video: %w", err)
+	}
+
+	if err := t.db.WithContext(ctx).Model(&Video{}).Where("id = ?", videoID).
+		UpdateColumn("tiering_override", override).Error; err != nil {
+		return nil, fmt.Errorf("failed to update tiering override: %w", err)
+	}
+	t.cache.Invalidate(ctx, videoID)
+
+	if override == TieringExempt && video.RenditionsDroppedAt != nil {
+		t.RegenerateRenditions(videoID)
+	}
+
+	storageClass := video.StorageClass
+	if storageClass == "" {
+		storageClass = StorageClassStandard
+	}
+	return &VideoTiering{
+		VideoID:             videoID,
+		Override:            override,
+		StorageClass:        storageClass,
+		RenditionsDroppedAt: video.RenditionsDroppedAt,
+		Regenerating:        t.isRegenerating(videoID),
+	}, nil
+}
+
+// Report shows the tiering policy, storage used by originals in each class,
+// dropped renditions and overrides. Deleted videos are left out.
+func (t *StorageTiering) Report(ctx context.Context) (*TieringReport, error) {
+	t.mu.Lock()
+	report := &TieringReport{
+		Enabled:            t.running,
+		DryRun:             t.config.DryRun,
+		StorageClass:       t.config.StorageClass,
+		OriginalsAfterDays: int(t.config.OriginalsAfter / (24 * time.Hour)),
+		RenditionsIdleDays: int(t.config.RenditionsIdleAfter / (24 * time.Hour)),
+		Originals:          []StorageClassUsage{},
+		Regenerating:       len(t.regenerating),
+		Overrides:          make(map[TieringOverride]int64),
+		LastRun:            t.lastRun,
+	}
+	t.mu.Unlock()
+
+	var usage []StorageClassUsage
+	if err := t.db.WithContext(ctx).Model(&Video{}).
+		Select("storage_class, COUNT(*) AS videos, COALESCE(SUM(file_size), 0) AS bytes").
+		Where("storage_path <> ''").
+		Group("storage_class").Order("storage_class").
+		Scan(&usage).Error; err != nil {
+		return nil, fmt.Errorf("failed to count originals by storage class: %w", err)
+	}
+	for _, class := range usage {
+		if class.StorageClass == "" {
+			class.StorageClass = StorageClassStandard
+		}
+		report.Originals = append(report.Originals, class)
+	}
+
+	if err := t.db.WithContext(ctx).Model(&Video{}).
+		Where("renditions_dropped_at IS NOT NULL").
+		Count(&report.RenditionsDropped).Error; err != nil {
+		return nil, fmt.Errorf("failed to count dropped renditions: %w", err)
+	}
+
+	var overrides []struct {
+		TieringOverride TieringOverride
+		Count           int64
+	}
+	if err := t.db.WithContext(ctx).Model(&Video{}).
+		Select("tiering_override, COUNT(*) AS count").
+		Where("tiering_override <> ?", TieringDefault).
+		Group("tiering_override").
+		Scan(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to count tiering overrides: %w", err)
+	}
+	for _, override := range overrides {
+		report.Overrides[override.TieringOverride] = override.Count
+	}
+
+	return report, nil
+}
@@ -0,0 +1,333 @@
+package video
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrTranscodeSchedulerStopped is returned for renditions still queued when the scheduler stops
+var ErrTranscodeSchedulerStopped = errors.New("transcode scheduler stopped")
+
+// TranscodeTask is one rendition of an upload waiting to be transcoded
+type TranscodeTask struct {
+	VideoID    uuid.UUID
+	Resolution string
+	// Duration is the length of the source video; shorter videos run first
+	Duration time.Duration
+	// Run transcodes and stores the rendition
+	Run func(ctx context.Context) error
+}
+
+// TranscodeSchedulerConfig controls how many renditions are transcoded at once
+type TranscodeSchedulerConfig struct {
+	Workers int // Number of concurrent FFmpeg transcodes across all uploads
+	// MaxBacklog is the number of waiting renditions at which new uploads are
+	// turned away; 0 never turns them away
+	MaxBacklog int
+	// RetryAfter is how long clients turned away should wait before retrying
+	RetryAfter time.Duration
+}
+
+// TranscodeBacklogError is returned by Admit while the backlog is at its limit
+type TranscodeBacklogError struct {
+	Pending    int
+	RetryAfter time.Duration
+}
+
+func (e *TranscodeBacklogError) Error() string {
+	return fmt.Sprintf("transcode backlog of %d renditions is at its limit", e.Pending)
+}
+
+// TranscodeScheduler runs renditions from all uploads on a shared pool of
+// workers, so the server never runs more FFmpeg processes than configured.
+// Waiting renditions are ordered by the length of their source video, then
+// by submission, so short clips are not stuck behind long uploads.
+//
+// A nil *TranscodeScheduler runs each task in the caller's goroutine.
+type TranscodeScheduler struct {
+	config  TranscodeSchedulerConfig
+	metrics *TranscodeMetrics
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   transcodeHeap
+	seq     uint64
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewTranscodeScheduler creates a scheduler; call Start to begin processing
+func NewTranscodeScheduler(config TranscodeSchedulerConfig, metrics *TranscodeMetrics) *TranscodeScheduler {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	s := &TranscodeScheduler{config: config, metrics: metrics}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// Start launches the worker pool
+func (s *TranscodeScheduler) Start() {
+	for i := 0; i < s.config.Workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+}
+
+// Stop waits for running renditions to finish. Renditions still queued are
+// not run; their callers receive ErrTranscodeSchedulerStopped.
+func (s *TranscodeScheduler) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	for s.queue.Len() > 0 {
+		queued := heap.Pop(&s.queue).(*queuedTranscode)
+		queued.stop()
+		queued.finish(ErrTranscodeSchedulerStopped)
+	}
+	s.metrics.setQueueDepth(0)
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// Submit queues task and returns a channel that receives the result of its
+// Run once it has finished. If ctx is cancelled while the task is waiting,
+// it is dropped and the channel receives ctx.Err().
+func (s *TranscodeScheduler) Submit(ctx context.Context, task TranscodeTask) <-chan error {
+	done := make(chan error, 1)
+	if s == nil {
+		done <- task.Run(ctx)
+		return done
+	}
+
+	queued := &queuedTranscode{ctx: ctx, task: task, done: done, queuedAt: time.Now()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		done <- ErrTranscodeSchedulerStopped
+		return done
+	}
+	s.seq++
+	queued.seq = s.seq
+	heap.Push(&s.queue, queued)
+	s.metrics.setQueueDepth(s.queue.Len())
+	s.cond.Signal()
+
+	queued.stop = context.AfterFunc(ctx, func() { s.drop(queued) })
+	return done
+}
+
+// Pending returns the number of renditions waiting for a worker
+func (s *TranscodeScheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue.Len()
+}
+
+// Admit reports whether the scheduler can take another upload. It returns a
+// *TranscodeBacklogError while MaxBacklog or more renditions are waiting.
+func (s *TranscodeScheduler) Admit() error {
+	if s == nil || s.config.MaxBacklog <= 0 {
+		return nil
+	}
+	if pending := s.Pending(); pending >= s.config.MaxBacklog {
+		return &TranscodeBacklogError{Pending: pending, RetryAfter: s.config.RetryAfter}
+	}
+	return nil
+}
+
+// Check reports the backlog for health checks; it fails while Admit would
+func (s *TranscodeScheduler) Check(ctx context.Context) error {
+	return s.Admit()
+}
+
+// drop removes a cancelled task that has not started
+func (s *TranscodeScheduler) drop(queued *queuedTranscode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if queued.index < 0 {
+		return
+	}
+	heap.Remove(&s.queue, queued.index)
+	s.metrics.setQueueDepth(s.queue.Len())
+	s.metrics.recordResult(queued.task.Resolution, transcodeResultCancelled)
+	queued.finish(queued.ctx.Err())
+}
+
+// worker runs tasks until the scheduler is stopped
+func (s *TranscodeScheduler) worker() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		for s.queue.Len() == 0 && !s.stopped {
+			s.cond.Wait()
+		}
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+		queued := heap.Pop(&s.queue).(*queuedTranscode)
+		s.metrics.setQueueDepth(s.queue.Len())
+		s.mu.Unlock()
+
+		queued.stop()
+		s.run(queued)
+	}
+}
+
+func (s *TranscodeScheduler) run(queued *queuedTranscode) {
+	if err := queued.ctx.Err(); err != nil {
+		s.metrics.recordResult(queued.task.Resolution, transcodeResultCancelled)
+		queued.finish(err)
+		return
+	}
+
+	s.metrics.observeWait(time.Since(queued.queuedAt))
+	s.metrics.addRunning(1)
+	err := queued.task.Run(queued.ctx)
+	s.metrics.addRunning(-1)
+
+	result := transcodeResultCompleted
+	if err != nil {
+		result = transcodeResultFailed
+	}
+	s.metrics.recordResult(queued.task.Resolution, result)
+	queued.finish(err)
+}
+
+// queuedTranscode is a task waiting in the scheduler's heap
+type queuedTranscode struct {
+	ctx      context.Context
+	task     TranscodeTask
+	seq      uint64
+	queuedAt time.Time
+	done     chan error
+	stop     func() bool
+	// index is the position in the heap, or -1 once the task has left it
+	index int
+}
+
+func (q *queuedTranscode) finish(err error) {
+	q.done <- err
+}
+
+// transcodeHeap orders tasks by source duration, then by submission
+type transcodeHeap []*queuedTranscode
+
+func (h transcodeHeap) Len() int { return len(h) }
+
+func (h transcodeHeap) Less(i, j int) bool {
+	if h[i].task.Duration != h[j].task.Duration {
+		return h[i].task.Duration < h[j].task.Duration
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h transcodeHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *transcodeHeap) Push(x any) {
+	queued := x.(*queuedTranscode)
+	queued.index = len(*h)
+	*h = append(*h, queued)
+}
+
+func (h *transcodeHeap) Pop() any {
+	old := *h
+	n := len(old)
+	queued := old[n-1]
+	old[n-1] = nil
+	queued.index = -1
+	*h = old[:n-1]
+	return queued
+}
+
+// Outcomes recorded by TranscodeMetrics
+const (
+	transcodeResultCompleted = "completed"
+	transcodeResultFailed    = "failed"
+	transcodeResultCancelled = "cancelled"
+)
+
+// TranscodeMetrics exports the transcode queue to Prometheus. A nil
+// *TranscodeMetrics is valid and records nothing.
+type TranscodeMetrics struct {
+	queueDepth prometheus.Gauge
+	running    prometheus.Gauge
+	wait       prometheus.Histogram
+	tasks      *prometheus.CounterVec
+}
+
+// NewTranscodeMetrics creates transcode queue metrics and registers them with registerer
+func NewTranscodeMetrics(registerer prometheus.Registerer) *TranscodeMetrics {
+	m := &TranscodeMetrics{
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_transcode",
+			Name:      "queue_depth",
+			Help:      "Renditions waiting for a transcode worker.",
+		}),
+		running: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_transcode",
+			Name:      "running",
+			Help:      "Renditions being transcoded.",
+		}),
+		wait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_transcode",
+			Name:      "queue_wait_seconds",
+			Help:      "Time renditions waited for a transcode worker.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 4, 8),
+		}),
+		tasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "pavilion",
+			Subsystem: "video_transcode",
+			Name:      "tasks_total",
+			Help:      "Renditions that left the queue, by resolution and result.",
+		}, []string{"resolution", "result"}),
+	}
+	registerer.MustRegister(m.queueDepth, m.running, m.wait, m.tasks)
+	return m
+}
+
+func (m *TranscodeMetrics) setQueueDepth(depth int) {
+	if m == nil {
+		return
+	}
+	m.queueDepth.Set(float64(depth))
+}
+
+func (m *TranscodeMetrics) addRunning(delta float64) {
+	if m == nil {
+		return
+	}
+	m.running.Add(delta)
+}
+
+func (m *TranscodeMetrics) observeWait(wait time.Duration) {
+	if m == nil {
+		return
+	}
+	m.wait.Observe(wait.Seconds())
+}
+
+func (m *TranscodeMetrics) recordResult(resolution, result string) {
+	if m == nil {
+		return
+	}
+	m.tasks.WithLabelValues(resolution, result).Inc()
+}
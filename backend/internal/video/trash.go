@@ -0,0 +1,126 @@
+package video
+
+import (
+	"context"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+)
+
+// ErrNotRestorable is returned for deleted videos that are being purged or
+// past the retention period
+var ErrNotRestorable = apierror.New(apierror.CodeConflict, "video can no longer be restored")
+
+// TrashedVideo describes a deleted video in the trash
+type TrashedVideo struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt is when the video's files and records are purged; omitted when
+	// deleted videos are kept until purged by hand
+	PurgeAt *time.Time `json:"purge_at,omitempty"`
+	// Restorable is false for videos removed by a moderator or admin, which
+	// only an admin can restore
+	Restorable bool `json:"restorable"`
+}
+
+// TrashListResponse represents a page of the trash
+type TrashListResponse struct {
+	Videos []TrashedVideo `json:"videos"`
+	Total  int64          `json:"total"`
+	Page   int            `json:"page"`
+	Limit  int            `json:"limit"`
+}
+
+// TrashServiceImpl implements the TrashService interface
+type TrashServiceImpl struct {
+	repo VideoRepository
+	// retention is how long deleted videos are kept; 0 keeps them until purged by hand
+	retention time.Duration
+	cache     *VideoCache
+	logger    Logger
+	now       func() time.Time
+}
+
+// NewTrashService creates a trash over videos kept for retention after
+// they are deleted, matching the retention cleanup; 0 means they are kept
+// until purged by hand
+func NewTrashService(repo VideoRepository, retention time.Duration, cache *VideoCache, logger Logger) TrashService {
+	return &TrashServiceImpl{
+		repo:      repo,
+		retention: retention,
+		cache:     cache,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// List returns a page of the user's deleted videos, most recently deleted first
+func (s *TrashServiceImpl) List(ctx context.Context, userID uuid.UUID, page, limit int) (*TrashListResponse, error) {
+	videos, total, err := s.repo.ListTrashed(ctx, userID, s.cutoff(), page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &TrashListResponse{
+		Videos: make([]TrashedVideo, 0, len(videos)),
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	}
+	for _, video := range videos {
+		item := TrashedVideo{
+			ID:         video.ID.String(),
+			Title:      video.Title,
+			DeletedAt:  video.DeletedAt.Time,
+			Restorable: video.DeletedBy != nil && *video.DeletedBy == video.UserID,
+		}
+		if s.retention > 0 {
+			purgeAt := video.DeletedAt.Time.Add(s.retention)
+			item.PurgeAt = &purgeAt
+		}
+		response.Videos = append(response.Videos, item)
+	}
+	return response, nil
+}
+
+// Get returns a deleted video that can still be restored
+func (s *TrashServiceImpl) Get(ctx context.Context, videoID uuid.UUID) (*Video, error) {
+	video, err := s.repo.GetTrashed(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	if video.Purging || video.DeletedAt.Time.Before(s.cutoff()) {
+		return nil, ErrNotRestorable
+	}
+	return video, nil
+}
+
+// Restore undeletes a video. The retention cleanup only purges videos
+// deleted before the cutoff, and those are not restored, so a video cannot be
+// restored while it is being purged.
+func (s *TrashServiceImpl) Restore(ctx context.Context, videoID uuid.UUID) error {
+	restored, err := s.repo.Undelete(ctx, videoID, s.cutoff())
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return ErrNotRestorable
+	}
+	s.cache.Invalidate(ctx, videoID)
+
+	s.logger.LogInfo("Video restored from trash", map[string]interface{}{
+		"video_id": videoID,
+	})
+	return nil
+}
+
+// cutoff is the deletion time before which videos are purged, zero when
+// deleted videos are kept until purged by hand
+func (s *TrashServiceImpl) cutoff() time.Time {
+	if s.retention <= 0 {
+		return time.Time{}
+	}
+	return s.now().Add(-s.retention)
+}
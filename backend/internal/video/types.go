@@ -1,8 +1,15 @@
 package video
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
 	"time"
 
+	"github.com/consensuslabs/pavilion-network/backend/internal/audit"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/ffmpeg"
 	"github.com/google/uuid"
 )
 
@@ -14,29 +21,66 @@ type App struct {
 	IPFS                IPFSService
 	ResponseHandler     ResponseHandler
 	NotificationService NotificationService
+	Entitlements        EntitlementChecker  // Optional; when nil, entitlement is not enforced
+	Evidence            EvidenceRecorder    // Optional; when nil, changes to reported videos are not snapshotted
+	Access              AccessRecorder      // Optional; when nil, playback starts are not logged
+	History             ResumeLookup        // Optional; when nil, video details have no resume position
+	Streams             StreamSource        // Optional; when nil, videos cannot be streamed through the API
+	Webhooks            WebhookPublisher    // Optional; when nil, processing events are not sent to webhooks
+	Fediverse           FediversePublisher  // Optional; when nil, new videos are not delivered to ActivityPub followers
+	Captions            CaptionService      // Optional; when nil, captions cannot be uploaded or listed
+	Trash               TrashService        // Optional; when nil, deleted videos cannot be listed or restored
+	Related             RelatedLister       // Optional; when nil, related videos cannot be listed
+	Audit               audit.Recorder      // Optional; when nil, uploads and deletions are not audited
+	Transcodes          *TranscodeScheduler // Optional; when nil, uploads are never turned away for a transcode backlog
+	TempSpace           TempSpace           // Optional; when nil, uploads are never turned away for lack of temporary storage
+	UploadEvents        *UploadEvents       // Optional; when nil, event streams only follow uploads by reading their status again
+	Tiering             TieringService      // Optional; when nil, storage tiering cannot be managed and dropped renditions are not regenerated
+	CDN                 PlaybackCDN         // Optional; when nil, video details have no playback URLs
+	Files               FileURLs            // Optional; when nil, video sources have no direct storage links
+	Sources             SourceConfig        // Where video sources point besides the API, CDN and storage
+	Remote              RemoteVideoLister   // Optional; when nil, listings only include this instance's videos
 }
 
 // Config represents the configuration for video handling
 type Config struct {
-	Video struct {
-		MaxFileSize    int64    `yaml:"max_file_size"`    // Maximum allowed file size in bytes
-		MinTitleLength int      `yaml:"min_title_length"` // Minimum length for video title
-		MaxTitleLength int      `yaml:"max_title_length"` // Maximum length for video title
-		MaxDescLength  int      `yaml:"max_desc_length"`  // Maximum length for video description
-		AllowedFormats []string `yaml:"allowed_formats"`  // List of allowed video formats
-	}
+	Video  LimitsConfig `yaml:"video"`
 	FFmpeg FfmpegConfig `yaml:"ffmpeg"` // FFmpeg configuration
 }
 
+// LimitsConfig represents the limits enforced on uploaded videos
+type LimitsConfig struct {
+	MaxFileSize    int64    `yaml:"max_file_size"`    // Maximum allowed file size in bytes
+	MinTitleLength int      `yaml:"min_title_length"` // Minimum length for video title
+	MaxTitleLength int      `yaml:"max_title_length"` // Maximum length for video title
+	MaxDescLength  int      `yaml:"max_desc_length"`  // Maximum length for video description
+	AllowedFormats []string `yaml:"allowed_formats"`  // List of allowed video formats
+	// MaxDuration is the longest video accepted; 0 means no limit
+	MaxDuration time.Duration `yaml:"max_duration"`
+	// AllowedVideoCodecs lists the FFmpeg names of accepted video codecs; empty accepts any codec FFmpeg decodes
+	AllowedVideoCodecs []string `yaml:"allowed_video_codecs"`
+}
+
 // FfmpegConfig represents FFmpeg configuration settings
 type FfmpegConfig struct {
-	Path        string   `yaml:"path"`        // Path to FFmpeg binary
-	ProbePath   string   `yaml:"probe_path"`  // Path to FFprobe binary
-	VideoCodec  string   `yaml:"video_codec"` // Video codec to use (e.g., h264)
-	AudioCodec  string   `yaml:"audio_codec"` // Audio codec to use (e.g., aac)
-	Preset      string   `yaml:"preset"`      // Encoding preset
-	OutputPath  string   `yaml:"output_path"` // Path for transcoded outputs
-	Resolutions []string `yaml:"resolutions"` // List of output resolutions
+	Path        string   `mapstructure:"path" yaml:"path" doc:"Path to the FFmpeg binary"`
+	ProbePath   string   `mapstructure:"probePath" yaml:"probe_path" doc:"Path to the FFprobe binary"`
+	VideoCodec  string   `mapstructure:"videoCodec" yaml:"video_codec" doc:"Video codec, e.g. libx264"`
+	AudioCodec  string   `mapstructure:"audioCodec" yaml:"audio_codec" doc:"Audio codec, e.g. aac or copy"`
+	Preset      string   `mapstructure:"preset" yaml:"preset" doc:"Encoding preset"`
+	OutputPath  string   `mapstructure:"outputPath" yaml:"output_path" doc:"Directory for transcoded outputs"`
+	Resolutions []string `mapstructure:"resolutions" yaml:"resolutions" doc:"Output resolutions, e.g. 720p"`
+	// TimeoutFactor bounds each rendition to this multiple of the source's playback time
+	TimeoutFactor float64       `mapstructure:"timeoutFactor" yaml:"timeout_factor" doc:"Transcode deadline per rendition as a multiple of the source duration; 0 disables the deadline"`
+	MinTimeout    time.Duration `mapstructure:"minTimeout" yaml:"min_timeout" doc:"Lower bound for transcode deadlines, so short clips still get time to start up"`
+	ProbeTimeout  time.Duration `mapstructure:"probeTimeout" yaml:"probe_timeout" doc:"Deadline for each ffprobe run; 0 disables the deadline"`
+	// HWAccel selects a hardware encoder; software encoding is used when it is unavailable
+	HWAccel     string                    `mapstructure:"hwAccel" yaml:"hw_accel" doc:"Hardware encoder: none, auto, vaapi, nvenc or videotoolbox; falls back to software encoding when unavailable"`
+	VAAPIDevice string                    `mapstructure:"vaapiDevice" yaml:"vaapi_device" doc:"Render node used for VAAPI encoding"`
+	Profiles    map[string]ffmpeg.Profile `mapstructure:"profiles" yaml:"profiles" doc:"Encoding settings by resolution"`
+	// Audio and Preview are produced alongside the video resolutions
+	Audio   ffmpeg.AudioOutput   `mapstructure:"audio" yaml:"audio"`
+	Preview ffmpeg.PreviewOutput `mapstructure:"preview" yaml:"preview"`
 }
 
 // UploadStatus represents the status of a video upload
@@ -47,17 +91,128 @@ const (
 	UploadStatusUploading UploadStatus = "uploading"
 	UploadStatusCompleted UploadStatus = "completed"
 	UploadStatusFailed    UploadStatus = "failed"
+	// UploadStatusInterrupted means processing was cancelled, by the client
+	// disconnecting or the server shutting down, and the file can be uploaded again
+	UploadStatusInterrupted UploadStatus = "interrupted"
 )
 
 // IsValid checks if the status is a valid upload status
 func (s UploadStatus) IsValid() bool {
 	switch s {
-	case UploadStatusPending, UploadStatusUploading, UploadStatusCompleted, UploadStatusFailed:
+	case UploadStatusPending, UploadStatusUploading, UploadStatusCompleted, UploadStatusFailed, UploadStatusInterrupted:
 		return true
 	}
 	return false
 }
 
+// TranscodeFailureReason explains why a rendition could not be produced
+type TranscodeFailureReason string
+
+const (
+	// TranscodeFailureTimeout means FFmpeg ran past the deadline derived from the source duration
+	TranscodeFailureTimeout TranscodeFailureReason = "timeout"
+	// TranscodeFailureEncode means FFmpeg failed or produced an unusable file
+	TranscodeFailureEncode TranscodeFailureReason = "encode_error"
+	// TranscodeFailureStorage means the rendition could not be stored
+	TranscodeFailureStorage TranscodeFailureReason = "storage_error"
+)
+
+// transcodeFailureReason classifies an FFmpeg error
+func transcodeFailureReason(err error) TranscodeFailureReason {
+	if errors.Is(err, ffmpeg.ErrTimeout) {
+		return TranscodeFailureTimeout
+	}
+	return TranscodeFailureEncode
+}
+
+// TranscodeFailures maps a resolution to the reason it is missing, stored as JSON
+type TranscodeFailures map[string]TranscodeFailureReason
+
+// Value implements driver.Valuer
+func (f TranscodeFailures) Value() (driver.Value, error) {
+	if len(f) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (f *TranscodeFailures) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*f = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for TranscodeFailures: %T", value)
+	}
+	if len(raw) == 0 {
+		*f = nil
+		return nil
+	}
+	return json.Unmarshal(raw, f)
+}
+
+// RenditionStatus is the progress of one rendition of an upload
+type RenditionStatus string
+
+const (
+	// RenditionQueued means the rendition is waiting for a transcode worker
+	RenditionQueued RenditionStatus = "queued"
+	// RenditionTranscoding means FFmpeg is producing the rendition
+	RenditionTranscoding RenditionStatus = "transcoding"
+	// RenditionStoring means the rendition is being uploaded to storage
+	RenditionStoring RenditionStatus = "storing"
+	// RenditionCompleted means the rendition is stored; it is playable once the upload completes
+	RenditionCompleted RenditionStatus = "completed"
+	// RenditionFailed means the rendition could not be produced; see TranscodeFailures for why
+	RenditionFailed RenditionStatus = "failed"
+)
+
+// RenditionStatuses maps a resolution to its progress, stored as JSON
+type RenditionStatuses map[string]RenditionStatus
+
+// Value implements driver.Valuer
+func (r RenditionStatuses) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (r *RenditionStatuses) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*r = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for RenditionStatuses: %T", value)
+	}
+	if len(raw) == 0 {
+		*r = nil
+		return nil
+	}
+	return json.Unmarshal(raw, r)
+}
+
 // APIResponse represents a standardized API response
 type APIResponse struct {
 	Message string      `json:"message,omitempty"`
@@ -71,8 +226,14 @@ type UploadResponse struct {
 	FileID      string          `json:"file_id"`
 	StoragePath string          `json:"storage_path"`
 	IPFSCID     string          `json:"ipfs_cid"`
+	Replication string          `json:"replication_status"`
 	Status      string          `json:"status"`
 	Transcodes  []TranscodeInfo `json:"transcodes,omitempty"`
+	// Duplicate is set when the file was already uploaded by the same user;
+	// the response describes that earlier video
+	Duplicate bool `json:"duplicate"`
+	// Version counts the files the video has had, starting at 1
+	Version int `json:"version"`
 }
 
 // VideoListResponse represents the response for listing videos
@@ -83,6 +244,11 @@ type VideoListResponse struct {
 	Limit  int                    `json:"limit"`
 }
 
+// RelatedVideosResponse represents the videos related to a video, best first
+type RelatedVideosResponse struct {
+	Videos []VideoDetailsResponse `json:"videos"`
+}
+
 // VideoInfo represents the basic video information
 type VideoInfo struct {
 	ID          string    `json:"id"`
@@ -92,19 +258,101 @@ type VideoInfo struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// VideoStatusResponse represents the processing status of a video
+type VideoStatusResponse struct {
+	Status string `json:"status" example:"completed"`
+	// Bytes of the original file stored so far, out of TotalBytes
+	StoredBytes int64 `json:"stored_bytes" example:"52428800"`
+	TotalBytes  int64 `json:"total_bytes" example:"104857600"`
+	// Renditions that could not be produced, by resolution: timeout, encode_error or storage_error
+	TranscodeFailures TranscodeFailures `json:"transcode_failures,omitempty" swaggertype:"object,string" example:"720p:timeout"`
+	// Progress of each rendition: queued, transcoding, storing, completed or failed
+	Renditions RenditionStatuses `json:"renditions,omitempty" swaggertype:"object,string" example:"480p:completed"`
+	// When each rendition started transcoding and was stored or failed
+	RenditionTimes RenditionTimes `json:"rendition_times,omitempty"`
+	// How much of each rendition has been encoded, in percent
+	RenditionProgress RenditionProgress `json:"rendition_progress,omitempty" swaggertype:"object,integer" example:"720p:42"`
+	// How much of the renditions has been produced overall, in percent.
+	// Stored and failed renditions count as done.
+	TranscodePercent int `json:"transcode_percent" example:"71"`
+	// Stage of the pipeline: receiving, validating, scanning,
+	// storing_original, transcoding, finalizing or completed. Failed and
+	// interrupted uploads keep the stage they stopped at.
+	Stage UploadStage `json:"stage,omitempty" example:"transcoding"`
+	// When the upload entered each stage
+	StageTimes StageTimes `json:"stage_times,omitempty" swaggertype:"object,string" example:"transcoding:2026-01-02T15:04:05Z"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	EndedAt    *time.Time `json:"ended_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	// Files pinned to IPFS, out of the original and its stored renditions.
+	// Pinning happens in the background once the upload completes.
+	IPFSPinned int `json:"ipfs_pinned" example:"3"`
+	IPFSFiles  int `json:"ipfs_files" example:"5"`
+}
+
 // VideoDetailsResponse represents the detailed video information
 type VideoDetailsResponse struct {
-	ID          string          `json:"id"`
-	FileID      string          `json:"file_id"`
-	Title       string          `json:"title"`
-	Description string          `json:"description"`
-	StoragePath string          `json:"storage_path"`
-	IPFSCID     string          `json:"ipfs_cid"`
-	Status      string          `json:"status"`
-	FileSize    int64           `json:"file_size"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	Transcodes  []TranscodeInfo `json:"transcodes,omitempty"`
+	ID                  string          `json:"id"`
+	UserID              string          `json:"user_id"`
+	FileID              string          `json:"file_id"`
+	Title               string          `json:"title"`
+	Description         string          `json:"description"`
+	StoragePath         string          `json:"storage_path"`
+	IPFSCID             string          `json:"ipfs_cid"`
+	Replication         string          `json:"replication_status"`
+	Status              string          `json:"status"`
+	RequiresEntitlement bool            `json:"requires_entitlement"`
+	Category            string          `json:"category,omitempty" example:"education"`
+	Tags                []string        `json:"tags" example:"tutorial,golang"`
+	FileSize            int64           `json:"file_size"`
+	Views               int64           `json:"views" example:"1280"`
+	CommentCount        int64           `json:"comment_count" example:"42"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+	Transcodes          []TranscodeInfo `json:"transcodes,omitempty"`
+	// AudioPath is the storage key of the audio-only rendition, for low-bandwidth playback
+	AudioPath string `json:"audio_path,omitempty" example:"videos/3f6c.../audio.m4a"`
+	// PreviewPath is the storage key of a few seconds of silent, looping WebP animation for listings
+	PreviewPath string `json:"preview_path,omitempty" example:"videos/3f6c.../preview.webp"`
+	// ResumeAt is where the requesting user left off, in seconds; only set on GET /video/{id}
+	ResumeAt *float64 `json:"resume_at,omitempty" example:"754.2"`
+	// TakenDownAt and TakedownReason are set on videos an admin took down,
+	// which only their owner and staff can see
+	TakenDownAt    *time.Time `json:"taken_down_at,omitempty"`
+	TakedownReason string     `json:"takedown_reason,omitempty" example:"Copyright claim"`
+	// Captions are the video's caption tracks; only set on GET /video/{id}
+	Captions []CaptionTrack `json:"captions,omitempty"`
+	// Duration is the length of the video in seconds, when known
+	Duration float64 `json:"duration,omitempty" example:"754.2"`
+	// Chapters are those the creator set, or else those listed in the description
+	Chapters []Chapter `json:"chapters,omitempty"`
+	// CommentsPolicy is whether the video takes comments, and whether they are held for the owner's review
+	CommentsPolicy CommentsPolicy `json:"comments_policy" swaggertype:"string" enums:"enabled,disabled,review_required" example:"enabled"`
+	// Embeddable is whether the video may be played in the embedded player on other sites
+	Embeddable bool `json:"embeddable" example:"true"`
+	// AllowedCountries are the only countries the video is played in, and
+	// BlockedCountries those it is not played in, by ISO 3166-1 alpha-2 code
+	AllowedCountries []string `json:"allowed_countries,omitempty" example:"US,CA"`
+	BlockedCountries []string `json:"blocked_countries,omitempty" example:"DE"`
+	// PlaybackURLs are CDN URLs of the video's renditions, by name: original,
+	// 720p, 480p, 360p, audio and preview; only set on GET /video/{id} when a
+	// CDN is configured
+	PlaybackURLs map[string]string `json:"playback_urls,omitempty" swaggertype:"object,string" example:"720p:https://cdn.example.com/videos/3f6c.../720p.mp4"`
+	// Origin is the API base URL of the remote instance a federated video
+	// lives on, where it is fetched by ID; omitted for this instance's videos
+	Origin string `json:"origin,omitempty" example:"https://peer.example.com/api/v1"`
+}
+
+// RedactPlayback removes storage locations from a response for viewers who
+// may see that a video exists but may not play it. The preview is kept so
+// listings can still show it.
+func (r *VideoDetailsResponse) RedactPlayback() {
+	r.StoragePath = ""
+	r.AudioPath = ""
+	r.IPFSCID = ""
+	r.Transcodes = nil
+	r.Captions = nil
+	r.PlaybackURLs = nil
 }
 
 // TranscodeInfo represents transcode information in responses
@@ -121,13 +369,89 @@ type TranscodeSegmentInfo struct {
 	ID          string `json:"id"`
 	StoragePath string `json:"storage_path"`
 	IPFSCID     string `json:"ipfs_cid"`
+	Replication string `json:"replication_status"`
 	Duration    int    `json:"duration"`
 }
 
+// UploadRequest represents the form of a video upload
+type UploadRequest struct {
+	Video       *multipart.FileHeader `form:"video" binding:"required,videoformat,videosize" swaggerignore:"true"`
+	Title       string                `form:"title" binding:"videotitle"`
+	Description string                `form:"description" binding:"videodesc"`
+	Category    Category              `form:"category" binding:"enum"`
+	// Tags may also be given as one comma-separated value
+	Tags []string `form:"tags" binding:"videotags"`
+}
+
 // VideoUpdateRequest represents the request for updating video metadata
 type VideoUpdateRequest struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Title       *string `json:"title,omitempty" binding:"omitnil,videotitle"`
+	Description *string `json:"description,omitempty" binding:"omitnil,videodesc"`
+	// Category replaces the category; an empty string removes it
+	Category *Category `json:"category,omitempty" binding:"omitnil,enum" swaggertype:"string" example:"education"`
+	// Tags replaces all tags; an empty list removes them
+	Tags *[]string `json:"tags,omitempty" binding:"omitnil,videotags"`
+	// CommentsPolicy turns comments off, or holds them for the owner's review
+	CommentsPolicy *CommentsPolicy `json:"comments_policy,omitempty" binding:"omitnil,enum" swaggertype:"string" enums:"enabled,disabled,review_required" example:"review_required"`
+	// Embeddable allows or forbids playing the video in the embedded player
+	Embeddable *bool `json:"embeddable,omitempty" example:"false"`
+	// AllowedCountries replaces the only countries the video is played in,
+	// and BlockedCountries those it is not played in, as ISO 3166-1 alpha-2
+	// codes; an empty list lifts the restriction
+	AllowedCountries *[]string `json:"allowed_countries,omitempty" binding:"omitnil,countries" example:"US,CA"`
+	BlockedCountries *[]string `json:"blocked_countries,omitempty" binding:"omitnil,countries" example:"DE"`
+}
+
+// ListFilter narrows and orders a video listing. Empty fields do not
+// filter, and an empty Sort lists the newest videos first.
+type ListFilter struct {
+	Tag      string
+	Category Category
+	Sort     ListSort
+}
+
+// ListSort is the order of a video listing
+type ListSort string
+
+// Orders accepted by ListVideos
+const (
+	SortNewest     ListSort = "newest"
+	SortOldest     ListSort = "oldest"
+	SortMostViewed ListSort = "most_viewed"
+	SortTitle      ListSort = "title"
+)
+
+// IsValid reports whether s is one of the listing orders
+func (s ListSort) IsValid() bool {
+	switch s {
+	case SortNewest, SortOldest, SortMostViewed, SortTitle:
+		return true
+	}
+	return false
+}
+
+// orderClause returns the ORDER BY clause for s. Ties are broken by ID so
+// that consecutive pages neither overlap nor skip videos.
+func (s ListSort) orderClause() string {
+	switch s {
+	case SortOldest:
+		return "videos.created_at ASC, videos.id ASC"
+	case SortMostViewed:
+		return "videos.views DESC, videos.created_at DESC, videos.id DESC"
+	case SortTitle:
+		return "lower(videos.title) ASC, videos.id ASC"
+	default:
+		return "videos.created_at DESC, videos.id DESC"
+	}
+}
+
+// RenditionOutputs are what transcoding records on a video besides its
+// transcodes. The paths are empty for renditions that were not produced.
+type RenditionOutputs struct {
+	AudioPath   string
+	PreviewPath string
+	// Duration is the length of the original in seconds
+	Duration float64
 }
 
 // VideoEvent represents the structure of a video event for notifications
@@ -0,0 +1,107 @@
+package video
+
+import (
+	"fmt"
+	"mime/multipart"
+	"sync/atomic"
+
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video/caption"
+	"github.com/go-playground/validator/v10"
+)
+
+// validationLimits holds the limits the binding rules check. The validator
+// caches rules per struct type, so the rules read the limits from here
+// instead of capturing them, letting a new configuration take effect.
+var validationLimits atomic.Pointer[LimitsConfig]
+
+// registerValidators adds the binding rules of video requests. Their limits
+// come from the configuration, so they are registered by NewVideoHandler.
+func registerValidators(limits LimitsConfig) {
+	validationLimits.Store(&limits)
+
+	rules := []struct {
+		tag     string
+		message string
+		fn      validator.Func
+	}{
+		{
+			tag:     "videoformat",
+			message: fmt.Sprintf("must be one of the allowed formats %v", limits.AllowedFormats),
+			fn: func(fl validator.FieldLevel) bool {
+				file, ok := fl.Field().Interface().(multipart.FileHeader)
+				return ok && allowedFormat(file.Filename, validationLimits.Load().AllowedFormats)
+			},
+		},
+		{
+			tag:     "videosize",
+			message: fmt.Sprintf("must be at most %d bytes", limits.MaxFileSize),
+			fn: func(fl validator.FieldLevel) bool {
+				file, ok := fl.Field().Interface().(multipart.FileHeader)
+				return ok && file.Size <= validationLimits.Load().MaxFileSize
+			},
+		},
+		{
+			tag:     "captionfile",
+			message: fmt.Sprintf("must be a .srt or .vtt file of at most %d bytes", caption.MaxSize),
+			fn: func(fl validator.FieldLevel) bool {
+				file, ok := fl.Field().Interface().(multipart.FileHeader)
+				_, known := caption.FormatOf(file.Filename)
+				return ok && known && file.Size <= caption.MaxSize
+			},
+		},
+		{
+			tag:     "captionlang",
+			message: "must be a BCP 47 language tag, e.g. en or pt-BR",
+			fn: func(fl validator.FieldLevel) bool {
+				return caption.ValidLanguage(fl.Field().String())
+			},
+		},
+		{
+			tag:     "videotitle",
+			message: fmt.Sprintf("must be between %d and %d characters", limits.MinTitleLength, limits.MaxTitleLength),
+			fn: func(fl validator.FieldLevel) bool {
+				limits, n := validationLimits.Load(), len(fl.Field().String())
+				return n >= limits.MinTitleLength && n <= limits.MaxTitleLength
+			},
+		},
+		{
+			tag:     "videodesc",
+			message: fmt.Sprintf("cannot exceed %d characters", limits.MaxDescLength),
+			fn: func(fl validator.FieldLevel) bool {
+				return len(fl.Field().String()) <= validationLimits.Load().MaxDescLength
+			},
+		},
+		{
+			tag:     "videotags",
+			message: fmt.Sprintf("must be at most %d tags of up to %d letters, digits or hyphens", MaxTags, MaxTagLength),
+			fn: func(fl validator.FieldLevel) bool {
+				tags, ok := fl.Field().Interface().([]string)
+				if !ok {
+					return false
+				}
+				_, err := NormalizeTags(splitTags(tags))
+				return err == nil
+			},
+		},
+		{
+			tag:     "countries",
+			message: fmt.Sprintf("must be at most %d ISO 3166-1 alpha-2 country codes, e.g. US", MaxCountries),
+			fn: func(fl validator.FieldLevel) bool {
+				codes, ok := fl.Field().Interface().([]string)
+				if !ok {
+					return false
+				}
+				_, err := NormalizeCountries(codes)
+				return err == nil
+			},
+		},
+	}
+
+	for _, rule := range rules {
+		// Only empty tags and nil functions are rejected
+		if err := httpHandler.RegisterValidation(rule.tag, rule.message, rule.fn); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package video
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"slices"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrReplaceInProgress is returned when replacing a video whose upload is still being processed
+var ErrReplaceInProgress = apierror.New(apierror.CodeConflict, "the video's upload is still being processed")
+
+// ReplaceRequest represents the form of a video replacement
+type ReplaceRequest struct {
+	Video *multipart.FileHeader `form:"video" binding:"required,videoformat,videosize" swaggerignore:"true"`
+}
+
+// VideoVersion records a file a video was replaced with. The version's
+// renditions are archived in storage until the cleanup worker purges them.
+type VideoVersion struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	VideoID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_video_versions_video_version" json:"video_id"`
+	Version  int       `gorm:"not null;uniqueIndex:idx_video_versions_video_version" json:"version"`
+	Checksum string    `gorm:"size:64" json:"checksum"`
+	FileSize int64     `gorm:"not null" json:"file_size"`
+	// IPFSCIDs are the pins of the version's files, released when it is purged
+	IPFSCIDs   CIDList   `gorm:"column:ipfs_cids;type:text" json:"-"`
+	UploadedAt time.Time `gorm:"not null" json:"uploaded_at"`
+	ReplacedAt time.Time `gorm:"not null;index" json:"replaced_at"`
+	// PurgedAt is set once the archived files are deleted
+	PurgedAt  *time.Time `gorm:"index" json:"purged_at,omitempty"`
+	CreatedAt time.Time  `gorm:"not null;default:now()" json:"created_at"`
+}
+
+// CIDList is a list of IPFS CIDs, stored as JSON
+type CIDList []string
+
+// Value implements driver.Valuer
+func (l CIDList) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner
+func (l *CIDList) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into CIDList", value)
+	}
+	return json.Unmarshal(raw, l)
+}
+
+// VersionInfo describes one version of a video in its history
+type VersionInfo struct {
+	Version int `json:"version" example:"2"`
+	// Current is set for the version the video plays now
+	Current    bool       `json:"current"`
+	FileSize   int64      `json:"file_size"`
+	Checksum   string     `json:"checksum,omitempty"`
+	UploadedAt time.Time  `json:"uploaded_at"`
+	ReplacedAt *time.Time `json:"replaced_at,omitempty"`
+	// Purged is set once the version's archived files have been deleted
+	Purged bool `json:"purged"`
+}
+
+// VersionListResponse represents the version history of a video, newest first
+type VersionListResponse struct {
+	VideoID  string        `json:"video_id"`
+	Versions []VersionInfo `json:"versions"`
+}
+
+// ReplaceVideo prepares a video's upload to be processed again with a new
+// file of the given size. When the current upload completed, its files are
+// archived as a version and the upload's version number goes up; a failed or
+// interrupted upload left nothing worth keeping and is retried in place. The
+// video keeps its ID, metadata and everything attached to it.
+func (s *VideoServiceImpl) ReplaceVideo(ctx context.Context, videoID uuid.UUID, size int64) (*VideoUpload, error) {
+	video, err := s.repo.GetByID(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+	upload := video.Upload
+	if upload == nil {
+		return nil, fmt.Errorf("video %s has no upload record", videoID)
+	}
+	if upload.Status == UploadStatusPending || upload.Status == UploadStatusUploading {
+		return nil, ErrReplaceInProgress
+	}
+
+	archive := upload.Status == UploadStatusCompleted
+	version := upload.Version
+	if archive {
+		version++
+	}
+	now := time.Now()
+
+	err = s.repo.Transaction(ctx, func(repo VideoRepository) error {
+		// Matching the status and version read above keeps two replacements
+		// of the same video from both going ahead
+		reset, err := repo.ResetUpload(ctx, upload, version, now)
+		if err != nil {
+			return err
+		}
+		if !reset {
+			return ErrReplaceInProgress
+		}
+
+		if archive {
+			if err := repo.CreateVersion(ctx, &VideoVersion{
+				VideoID:    videoID,
+				Version:    upload.Version,
+				Checksum:   video.Checksum,
+				FileSize:   video.FileSize,
+				IPFSCIDs:   videoCIDs(video),
+				UploadedAt: upload.StartTime,
+				ReplacedAt: now,
+			}); err != nil {
+				return err
+			}
+			// Files are archived last, so a failure rolls back the records
+			if err := s.storage.ArchiveVideo(ctx, videoID, upload.Version); err != nil {
+				return fmt.Errorf("failed to archive video files: %w", err)
+			}
+		}
+
+		return repo.SetReplacedFile(ctx, videoID, size)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate(ctx, videoID)
+	// The files were archived, and the new ones are stored under the same keys
+	s.purgeCDN(ctx, videoID)
+
+	s.logger.LogInfo("Video replacement started", map[string]interface{}{
+		"video_id": videoID,
+		"version":  version,
+		"archived": archive,
+	})
+
+	upload.Status = UploadStatusPending
+	upload.Version = version
+	upload.StartTime = now
+	upload.EndTime = nil
+	upload.StoredBytes = 0
+	upload.TranscodeFailures = nil
+	upload.Renditions = nil
+	upload.UpdatedAt = now
+	video.FileSize = size
+	video.IPFSCID = ""
+	video.Replication = ReplicationS3Only
+	video.StorageClass = ""
+	video.Upload = nil
+	upload.Video = video
+	return upload, nil
+}
+
+// ListVersions returns a video's version history, newest first: the current
+// upload followed by the versions it replaced
+func (s *VideoServiceImpl) ListVersions(ctx context.Context, videoID uuid.UUID) ([]VersionInfo, error) {
+	video, err := s.GetVideo(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	archived, err := s.repo.ListVersions(ctx, videoID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]VersionInfo, 0, len(archived)+1)
+	if video.Upload != nil {
+		versions = append(versions, VersionInfo{
+			Version:    video.Upload.Version,
+			Current:    true,
+			FileSize:   video.FileSize,
+			Checksum:   video.Checksum,
+			UploadedAt: video.Upload.StartTime,
+		})
+	}
+	for _, v := range archived {
+		versions = append(versions, VersionInfo{
+			Version:    v.Version,
+			FileSize:   v.FileSize,
+			Checksum:   v.Checksum,
+			UploadedAt: v.UploadedAt,
+			ReplacedAt: &v.ReplacedAt,
+			Purged:     v.PurgedAt != nil,
+		})
+	}
+	return versions, nil
+}
+
+// PurgeVersion deletes the archived files of a replaced version and releases
+// its IPFS pins, keeping any the video still uses. The version stays in the
+// history, marked purged.
+func (p *Purger) PurgeVersion(ctx context.Context, versionID uuid.UUID) error {
+	var version VideoVersion
+	if err := p.db.WithContext(ctx).First(&version, "id = ?", versionID).Error; err != nil {
+		return &PurgeError{VideoID: version.VideoID, Stage: PurgeStageDatabase, Err: err}
+	}
+
+	if err := p.storage.DeleteVideoVersion(ctx, version.VideoID, version.Version); err != nil {
+		return &PurgeError{VideoID: version.VideoID, Stage: PurgeStageStorage, Err: err}
+	}
+
+	if p.ipfs != nil && len(version.IPFSCIDs) > 0 {
+		// A file the version shares with the current upload keeps its pin
+		var current Video
+		if err := p.db.WithContext(ctx).Unscoped().Preload("Transcodes.Segments").First(&current, "id = ?", version.VideoID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return &PurgeError{VideoID: version.VideoID, Stage: PurgeStageDatabase, Err: err}
+		}
+		inUse := videoCIDs(&current)
+		for _, cid := range version.IPFSCIDs {
+			if slices.Contains(inUse, cid) {
+				continue
+			}
+			if err := p.ipfs.Unpin(ctx, cid); err != nil {
+				return &PurgeError{VideoID: version.VideoID, Stage: PurgeStageIPFS, Err: err}
+			}
+		}
+	}
+
+	if err := p.db.WithContext(ctx).Model(&VideoVersion{}).Where("id = ?", versionID).Update("purged_at", time.Now()).Error; err != nil {
+		return &PurgeError{VideoID: version.VideoID, Stage: PurgeStageDatabase, Err: err}
+	}
+	return nil
+}
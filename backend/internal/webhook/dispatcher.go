@@ -0,0 +1,344 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Headers sent with every delivery
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the body keyed with the webhook secret, prefixed with "sha256="
+	SignatureHeader = "X-Pavilion-Signature"
+	// EventHeader carries the event type
+	EventHeader = "X-Pavilion-Event"
+	// DeliveryHeader carries the delivery ID, which stays the same across retries
+	DeliveryHeader = "X-Pavilion-Delivery"
+)
+
+// ErrPrivateAddress is returned when a webhook URL resolves to a loopback,
+// private or link-local address and private networks are not allowed
+var ErrPrivateAddress = errors.New("webhook address is not publicly routable")
+
+// DispatcherConfig represents webhook delivery settings
+type DispatcherConfig struct {
+	// Workers is how many deliveries are sent concurrently
+	Workers int
+	// MaxAttempts is how many times a delivery is tried before it is marked failed
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles with every further attempt
+	RetryDelay time.Duration
+	// MaxRetryDelay caps the wait between attempts
+	MaxRetryDelay time.Duration
+	// Timeout bounds each delivery request
+	Timeout time.Duration
+	// PollInterval is how often the delivery table is checked for due retries
+	PollInterval time.Duration
+	// AllowPrivateNetworks permits webhook URLs on loopback and private
+	// addresses; only for development, since it lets users reach internal services
+	AllowPrivateNetworks bool
+}
+
+// Dispatcher delivers queued webhook events, retrying failures with
+// exponential backoff. Deliveries are kept in the database, so events queued
+// before a restart are sent once a dispatcher starts again.
+type Dispatcher struct {
+	db     *gorm.DB
+	config DispatcherConfig
+	client *http.Client
+	logger logger.Logger
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a webhook dispatcher
+func NewDispatcher(db *gorm.DB, config DispatcherConfig, logger logger.Logger) *Dispatcher {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = 30 * time.Second
+	}
+	if config.MaxRetryDelay < config.RetryDelay {
+		config.MaxRetryDelay = config.RetryDelay
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 5 * time.Second
+	}
+	return &Dispatcher{
+		db:     db,
+		config: config,
+		client: newClient(config.Timeout, config.AllowPrivateNetworks),
+		logger: logger,
+		wake:   make(chan struct{}, config.Workers),
+	}
+}
+
+// Start requeues deliveries interrupted by a previous shutdown and starts the
+// workers, which run until Stop is called
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	// A delivery still marked in progress long after its timeout was cut off
+	// by a crash or restart
+	stale := time.Now().Add(-2 * d.config.Timeout)
+	if err := d.db.WithContext(ctx).Model(&Delivery{}).
+		Where("status = ? AND updated_at < ?", DeliveryInProgress, stale).
+		Updates(map[string]interface{}{"status": DeliveryPending, "updated_at": time.Now()}).Error; err != nil {
+		d.logger.LogError(err, "Failed to requeue interrupted webhook deliveries")
+	}
+
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+		go d.work(ctx)
+	}
+}
+
+// Stop stops the workers and waits for deliveries being sent to finish
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+// Wake tells idle workers that deliveries were queued
+func (d *Dispatcher) Wake() {
+	for i := 0; i < cap(d.wake); i++ {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+// work sends due deliveries until there are none left, then waits to be
+// woken or for the next poll
+func (d *Dispatcher) work(ctx context.Context) {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			delivery, err := d.claim(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					d.logger.LogError(err, "Failed to claim webhook delivery")
+				}
+				break
+			}
+			if delivery == nil {
+				break
+			}
+			d.deliver(ctx, delivery)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim marks the next due delivery as in progress and returns it, or nil
+// when nothing is due. The conditional update keeps two workers, or two
+// instances, from sending the same delivery.
+func (d *Dispatcher) claim(ctx context.Context) (*Delivery, error) {
+	for {
+		var delivery Delivery
+		err := d.db.WithContext(ctx).
+			Where("status = ? AND next_attempt_at <= ?", DeliveryPending, time.Now()).
+			Order("next_attempt_at").
+			First(&delivery).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load due webhook delivery: %w", err)
+		}
+
+		result := d.db.WithContext(ctx).Model(&Delivery{}).
+			Where("id = ? AND status = ?", delivery.ID, DeliveryPending).
+			Updates(map[string]interface{}{"status": DeliveryInProgress, "updated_at": time.Now()})
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to claim webhook delivery: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			return &delivery, nil
+		}
+	}
+}
+
+// deliver sends a claimed delivery and records the outcome, scheduling a
+// retry when attempts remain
+func (d *Dispatcher) deliver(ctx context.Context, delivery *Delivery) {
+	var webhook Webhook
+	if err := d.db.WithContext(ctx).First(&webhook, "id = ?", delivery.WebhookID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The webhook was deleted after the event was queued
+			d.record(delivery.ID, map[string]interface{}{
+				"status":     DeliveryFailed,
+				"last_error": "webhook deleted",
+			})
+			return
+		}
+		d.logger.LogError(err, "Failed to load webhook for delivery")
+		d.record(delivery.ID, map[string]interface{}{"status": DeliveryPending})
+		return
+	}
+
+	// An attempt already underway is finished rather than cut off by Stop;
+	// the client timeout bounds how long that takes
+	attempts := delivery.Attempts + 1
+	status, err := d.send(context.WithoutCancel(ctx), &webhook, delivery)
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":        attempts,
+		"response_status": status,
+		"last_error":      "",
+	}
+	switch {
+	case err == nil:
+		updates["status"] = DeliverySucceeded
+		updates["delivered_at"] = now
+	case attempts >= d.config.MaxAttempts:
+		updates["status"] = DeliveryFailed
+		updates["last_error"] = err.Error()
+		d.logger.LogWarn("Webhook delivery failed permanently", map[string]interface{}{
+			"webhook_id":  webhook.ID,
+			"delivery_id": delivery.ID,
+			"event":       delivery.Event,
+			"attempts":    attempts,
+			"error":       err.Error(),
+		})
+	default:
+		updates["status"] = DeliveryPending
+		updates["last_error"] = err.Error()
+		updates["next_attempt_at"] = now.Add(d.backoff(attempts))
+	}
+	d.record(delivery.ID, updates)
+}
+
+// record saves a delivery's outcome. It runs even while stopping, so an
+// attempt that was made is never forgotten.
+func (d *Dispatcher) record(deliveryID uuid.UUID, updates map[string]interface{}) {
+	updates["updated_at"] = time.Now()
+	if err := d.db.Model(&Delivery{}).Where("id = ?", deliveryID).Updates(updates).Error; err != nil {
+		d.logger.LogError(err, "Failed to record webhook delivery")
+	}
+}
+
+// send posts a delivery's payload to the webhook, returning the response
+// status. Anything but a 2xx response is an error.
+func (d *Dispatcher) send(ctx context.Context, webhook *Webhook, delivery *Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Pavilion-Webhooks/1.0")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the wait before the attempt after the given one
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.RetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxRetryDelay {
+			return d.config.MaxRetryDelay
+		}
+	}
+	return delay
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newClient returns the HTTP client deliveries are sent with. Redirects are
+// not followed, and unless allowPrivate is set, connections to addresses that
+// are not publicly routable are refused after DNS resolution, so webhooks
+// cannot be used to reach internal services.
+func newClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublic reports whether ip is a publicly routable unicast address
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
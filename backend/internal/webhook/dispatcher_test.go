@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"video.processed"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), Sign("secret", body))
+	assert.NotEqual(t, Sign("secret", body), Sign("other-secret", body))
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, DispatcherConfig{RetryDelay: time.Second, MaxRetryDelay: 5 * time.Second}, nil)
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 4*time.Second, d.backoff(3))
+	assert.Equal(t, 5*time.Second, d.backoff(4))
+	assert.Equal(t, 5*time.Second, d.backoff(20))
+}
+
+func TestIsPublic(t *testing.T) {
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.True(t, isPublic(net.ParseIP(addr)), addr)
+	}
+	for _, addr := range []string{"127.0.0.1", "10.0.0.5", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fd00::1"} {
+		assert.False(t, isPublic(net.ParseIP(addr)), addr)
+	}
+}
+
+func TestSend(t *testing.T) {
+	delivery := &Delivery{
+		ID:      uuid.New(),
+		Event:   EventVideoProcessed,
+		Payload: `{"event":"video.processed","data":{}}`,
+	}
+
+	t.Run("Signed delivery", func(t *testing.T) {
+		var received *http.Request
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		d := NewDispatcher(nil, DispatcherConfig{AllowPrivateNetworks: true}, nil)
+		status, err := d.send(context.Background(), &Webhook{URL: server.URL, Secret: "secret"}, delivery)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.Equal(t, delivery.Payload, string(body))
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, EventVideoProcessed, received.Header.Get(EventHeader))
+		assert.Equal(t, delivery.ID.String(), received.Header.Get(DeliveryHeader))
+		assert.Equal(t, Sign("secret", body), received.Header.Get(SignatureHeader))
+	})
+
+	t.Run("Error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		d := NewDispatcher(nil, DispatcherConfig{AllowPrivateNetworks: true}, nil)
+		status, err := d.send(context.Background(), &Webhook{URL: server.URL}, delivery)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadGateway, status)
+	})
+
+	t.Run("Redirects are not followed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+		}))
+		defer server.Close()
+
+		d := NewDispatcher(nil, DispatcherConfig{AllowPrivateNetworks: true}, nil)
+		status, err := d.send(context.Background(), &Webhook{URL: server.URL}, delivery)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusFound, status)
+	})
+
+	t.Run("Private addresses are refused", func(t *testing.T) {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		defer server.Close()
+
+		d := NewDispatcher(nil, DispatcherConfig{}, nil)
+		_, err := d.send(context.Background(), &Webhook{URL: server.URL}, delivery)
+		assert.ErrorIs(t, err, ErrPrivateAddress)
+		assert.False(t, called)
+	})
+}
@@ -0,0 +1,207 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/apierror"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Handler handles HTTP requests for webhook endpoints
+type Handler struct {
+	service         Service
+	responseHandler httpHandler.ResponseHandler
+	logger          logger.Logger
+}
+
+// NewHandler creates a new webhook handler instance
+func NewHandler(service Service, responseHandler httpHandler.ResponseHandler, logger logger.Logger) *Handler {
+	return &Handler{
+		service:         service,
+		responseHandler: responseHandler,
+		logger:          logger,
+	}
+}
+
+// RegisterRoutes registers all webhook routes
+func (h *Handler) RegisterRoutes(router gin.IRouter, authMiddleware gin.HandlerFunc) {
+	webhooks := router.Group("/webhooks")
+	webhooks.Use(authMiddleware)
+	{
+		webhooks.POST("", h.handleCreate)
+		webhooks.GET("", h.handleList)
+		webhooks.DELETE("/:id", h.handleDelete)
+		webhooks.GET("/:id/deliveries", h.handleListDeliveries)
+	}
+}
+
+// @Summary Register a webhook
+// @Description Register a URL to receive JSON callbacks for video.processed, video.failed and comment.created events on your videos. Each delivery is a POST carrying X-Pavilion-Event, X-Pavilion-Delivery and an X-Pavilion-Signature header of the form "sha256=<hex HMAC-SHA256 of the body>" keyed with the secret returned here, which is not shown again. Failed deliveries are retried with exponential backoff.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateRequest true "Webhook URL and events"
+// @Success 200 {object} httpHandler.APIResponse{data=CreateResponse} "Webhook registered"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid URL or events"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 409 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Webhook limit reached"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /webhooks [post]
+func (h *Handler) handleCreate(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req CreateRequest
+	if err := httpHandler.Bind(c, &req); err != nil {
+		apierror.Abort(c, err, "")
+		return
+	}
+
+	webhook, err := h.service.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to register webhook")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, webhook, "Webhook registered successfully")
+}
+
+// @Summary List my webhooks
+// @Description Get the authenticated user's webhooks. Secrets are not included.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} httpHandler.APIResponse{data=[]Webhook} "Webhooks retrieved"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /webhooks [get]
+func (h *Handler) handleList(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve webhooks")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, webhooks, "Webhooks retrieved successfully")
+}
+
+// @Summary Delete a webhook
+// @Description Stop sending events to a webhook and remove its delivery log
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID (UUID)"
+// @Success 200 {object} httpHandler.APIResponse "Webhook deleted"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid webhook ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Webhook not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /webhooks/{id} [delete]
+func (h *Handler) handleDelete(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	webhookID, ok := h.getWebhookID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, webhookID); err != nil {
+		h.handleServiceError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, nil, "Webhook deleted successfully")
+}
+
+// @Summary List webhook deliveries
+// @Description Get a page of a webhook's delivery log, newest first, with the payload sent, the number of attempts and the last response or error
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID (UUID)"
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Success 200 {object} httpHandler.APIResponse{data=DeliveryListResponse} "Deliveries retrieved"
+// @Failure 400 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Invalid webhook ID"
+// @Failure 401 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Unauthorized"
+// @Failure 404 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Webhook not found"
+// @Failure 500 {object} httpHandler.APIResponse{error=httpHandler.APIError} "Internal server error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Handler) handleListDeliveries(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+	webhookID, ok := h.getWebhookID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	list, err := h.service.ListDeliveries(c.Request.Context(), userID, webhookID, page, limit)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to retrieve webhook deliveries")
+		return
+	}
+
+	h.responseHandler.SuccessResponse(c, list, "Webhook deliveries retrieved successfully")
+}
+
+// getUserID returns the authenticated user's ID, writing an error response when it is missing
+func (h *Handler) getUserID(c *gin.Context) (uuid.UUID, bool) {
+	userIDStr, exists := c.Get("userID")
+	if !exists {
+		h.responseHandler.UnauthorizedResponse(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDStr.(string))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid user ID format", err)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// getWebhookID parses the webhook ID path parameter, writing an error response when it is invalid
+func (h *Handler) getWebhookID(c *gin.Context) (uuid.UUID, bool) {
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.responseHandler.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID", err)
+		return uuid.Nil, false
+	}
+	return webhookID, true
+}
+
+// handleServiceError maps webhook service errors to HTTP responses
+func (h *Handler) handleServiceError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidURL):
+		h.responseHandler.ValidationErrorResponse(c, "url", err.Error())
+	case errors.Is(err, ErrInvalidEvents):
+		h.responseHandler.ValidationErrorResponse(c, "events", err.Error())
+	case errors.Is(err, ErrTooManyWebhooks):
+		h.responseHandler.ErrorResponse(c, http.StatusConflict, "WEBHOOK_LIMIT", err.Error(), err)
+	case errors.Is(err, ErrWebhookNotFound):
+		h.responseHandler.NotFoundResponse(c, "Webhook not found")
+	default:
+		h.logger.LogError(err, message)
+		h.responseHandler.ErrorResponse(c, http.StatusInternalServerError, "DATABASE_ERROR", message, err)
+	}
+}
@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidURL is returned when a webhook URL is not an absolute http or https URL
+	ErrInvalidURL = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrInvalidEvents is returned when a webhook subscribes to no events or to an unknown one
+	ErrInvalidEvents = errors.New("events must be one or more of video.processed, video.failed and comment.created")
+	// ErrTooManyWebhooks is returned when a user already has the maximum number of webhooks
+	ErrTooManyWebhooks = errors.New("webhook limit reached")
+	// ErrWebhookNotFound is returned when the webhook does not exist or belongs to someone else
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// Service defines the interface for webhook registration and event publishing
+type Service interface {
+	// Create registers a webhook for the user and returns it with its signing secret
+	Create(ctx context.Context, userID uuid.UUID, req *CreateRequest) (*CreateResponse, error)
+	// List returns the user's webhooks, newest first
+	List(ctx context.Context, userID uuid.UUID) ([]Webhook, error)
+	// Delete removes one of the user's webhooks together with its delivery log
+	Delete(ctx context.Context, userID, webhookID uuid.UUID) error
+	// ListDeliveries returns a page of a webhook's delivery log, newest first
+	ListDeliveries(ctx context.Context, userID, webhookID uuid.UUID, page, limit int) (*DeliveryListResponse, error)
+	// Publish queues an event for delivery to the user's webhooks subscribed to it
+	Publish(ctx context.Context, userID uuid.UUID, event string, data interface{}) error
+	// PublishForVideo queues an event for the webhooks of a video's owner
+	PublishForVideo(ctx context.Context, videoID uuid.UUID, event string, data interface{}) error
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/comment"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+)
+
+// Event types delivered to webhooks
+const (
+	// EventVideoProcessed is sent when an upload has been stored and transcoded
+	EventVideoProcessed = video.WebhookVideoProcessed
+	// EventVideoFailed is sent when an upload could not be processed
+	EventVideoFailed = video.WebhookVideoFailed
+	// EventCommentCreated is sent when someone comments on one of the user's videos
+	EventCommentCreated = comment.WebhookCommentCreated
+)
+
+// knownEvents are the event types a webhook can subscribe to
+var knownEvents = []string{EventVideoProcessed, EventVideoFailed, EventCommentCreated}
+
+// EventTypes is a set of event types stored as a comma-separated list
+type EventTypes []string
+
+// Has reports whether event is in the set
+func (e EventTypes) Has(event string) bool {
+	for _, subscribed := range e {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements driver.Valuer
+func (e EventTypes) Value() (driver.Value, error) {
+	return strings.Join(e, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (e *EventTypes) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	case nil:
+		*e = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type for EventTypes: %T", value)
+	}
+
+	*e = nil
+	for _, part := range strings.Split(raw, ",") {
+		if part != "" {
+			*e = append(*e, part)
+		}
+	}
+	return nil
+}
+
+// Webhook is a URL registered by a user to receive signed event callbacks
+type Webhook struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	URL    string    `gorm:"type:text;not null" json:"url" example:"https://example.com/hooks/pavilion"`
+	// Secret keys the HMAC signature of every delivery; it is only shown when the webhook is created
+	Secret    string     `gorm:"type:text;not null" json:"-"`
+	Events    EventTypes `gorm:"type:text;not null" json:"events" swaggertype:"array,string" example:"video.processed,video.failed"`
+	CreatedAt time.Time  `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// DeliveryStatus is the state of a single event delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are waiting for their first or next attempt
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryInProgress deliveries have been claimed by a dispatcher worker
+	DeliveryInProgress DeliveryStatus = "delivering"
+	// DeliverySucceeded deliveries were answered with a 2xx status
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed deliveries ran out of attempts
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Delivery is one event sent, or to be sent, to a webhook, kept as its delivery log
+type Delivery struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WebhookID uuid.UUID `gorm:"type:uuid;not null;index" json:"webhook_id"`
+	Event     string    `gorm:"type:text;not null" json:"event" example:"video.processed"`
+	// Payload is the exact JSON body sent, so every attempt carries the same signature
+	Payload        string         `gorm:"type:text;not null" json:"payload"`
+	Status         DeliveryStatus `gorm:"type:text;not null;index:idx_webhook_deliveries_due" json:"status" example:"succeeded"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int            `json:"response_status,omitempty" example:"200"`
+	LastError      string         `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt  time.Time      `gorm:"not null;default:now();index:idx_webhook_deliveries_due" json:"next_attempt_at"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time      `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"not null;default:now()" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Delivery model
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Envelope is the JSON body of every delivery
+type Envelope struct {
+	// ID is the delivery ID, also sent in the X-Pavilion-Delivery header, so receivers can drop retries they already handled
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event" example:"video.processed"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// CreateRequest registers a webhook
+type CreateRequest struct {
+	URL    string   `json:"url" binding:"required,url" example:"https://example.com/hooks/pavilion"`
+	Events []string `json:"events" binding:"required,min=1" example:"video.processed,video.failed"`
+}
+
+// CreateResponse is a newly registered webhook with the secret its deliveries are signed with
+type CreateResponse struct {
+	Webhook
+	Secret string `json:"secret" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+// DeliveryListResponse represents a paginated delivery log
+type DeliveryListResponse struct {
+	Deliveries []Delivery `json:"deliveries"`
+	Total      int64      `json:"total"`
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+}
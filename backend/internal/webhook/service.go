@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/consensuslabs/pavilion-network/backend/internal/logger"
+	"github.com/consensuslabs/pavilion-network/backend/internal/video"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Config represents webhook registration settings
+type Config struct {
+	// MaxPerUser bounds how many webhooks a user can register
+	MaxPerUser int
+}
+
+// serviceImpl implements the Service interface
+type serviceImpl struct {
+	db         *gorm.DB
+	config     Config
+	dispatcher *Dispatcher
+	logger     logger.Logger
+}
+
+// NewService creates a new webhook service. Published events are stored for
+// the dispatcher, which is woken to deliver them; a nil dispatcher leaves
+// them for whichever instance polls next.
+func NewService(db *gorm.DB, config Config, dispatcher *Dispatcher, logger logger.Logger) Service {
+	if config.MaxPerUser < 1 {
+		config.MaxPerUser = 10
+	}
+	return &serviceImpl{
+		db:         db,
+		config:     config,
+		dispatcher: dispatcher,
+		logger:     logger,
+	}
+}
+
+// Create registers a webhook for the user
+func (s *serviceImpl) Create(ctx context.Context, userID uuid.UUID, req *CreateRequest) (*CreateResponse, error) {
+	if err := validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&Webhook{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+	if count >= int64(s.config.MaxPerUser) {
+		return nil, fmt.Errorf("%w: at most %d per user", ErrTooManyWebhooks, s.config.MaxPerUser)
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	webhook := Webhook{
+		ID:        uuid.New(),
+		UserID:    userID,
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.db.WithContext(ctx).Create(&webhook).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return &CreateResponse{Webhook: webhook, Secret: secret}, nil
+}
+
+// List returns the user's webhooks, newest first
+func (s *serviceImpl) List(ctx context.Context, userID uuid.UUID) ([]Webhook, error) {
+	webhooks := make([]Webhook, 0)
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Delete removes one of the user's webhooks and its delivery log
+func (s *serviceImpl) Delete(ctx context.Context, userID, webhookID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", webhookID, userID).Delete(&Webhook{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete webhook: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrWebhookNotFound
+		}
+		if err := tx.Where("webhook_id = ?", webhookID).Delete(&Delivery{}).Error; err != nil {
+			return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListDeliveries returns a page of a webhook's delivery log, newest first
+func (s *serviceImpl) ListDeliveries(ctx context.Context, userID, webhookID uuid.UUID, page, limit int) (*DeliveryListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	} else if limit > 100 {
+		limit = 100
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&Webhook{}).
+		Where("id = ? AND user_id = ?", webhookID, userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to load webhook: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrWebhookNotFound
+	}
+
+	query := s.db.WithContext(ctx).Model(&Delivery{}).Where("webhook_id = ?", webhookID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]Delivery, 0, limit)
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return &DeliveryListResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
+
+// Publish queues an event for the user's webhooks subscribed to it
+func (s *serviceImpl) Publish(ctx context.Context, userID uuid.UUID, event string, data interface{}) error {
+	var webhooks []Webhook
+	if err := s.db.WithContext(ctx).Select("id", "events").Where("user_id = ?", userID).Find(&webhooks).Error; err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	now := time.Now()
+	var deliveries []Delivery
+	for _, webhook := range webhooks {
+		if !webhook.Events.Has(event) {
+			continue
+		}
+		delivery := Delivery{
+			ID:            uuid.New(),
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Status:        DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		payload, err := json.Marshal(Envelope{
+			ID:        delivery.ID,
+			Event:     event,
+			CreatedAt: now,
+			Data:      data,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode %s payload: %w", event, err)
+		}
+		delivery.Payload = string(payload)
+		deliveries = append(deliveries, delivery)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := s.db.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries: %w", err)
+	}
+	if s.dispatcher != nil {
+		s.dispatcher.Wake()
+	}
+	return nil
+}
+
+// PublishForVideo queues an event for the webhooks of a video's owner
+func (s *serviceImpl) PublishForVideo(ctx context.Context, videoID uuid.UUID, event string, data interface{}) error {
+	var v video.Video
+	if err := s.db.WithContext(ctx).Unscoped().Select("id", "user_id").First(&v, "id = ?", videoID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load video: %w", err)
+	}
+	return s.Publish(ctx, v.UserID, event, data)
+}
+
+// validateURL checks that a webhook URL is an absolute http or https URL.
+// Whether it points at a private network is checked when delivering, since
+// the name may resolve differently by then.
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// normalizeEvents validates requested event types and removes duplicates
+func normalizeEvents(requested []string) (EventTypes, error) {
+	var events EventTypes
+	for _, event := range requested {
+		known := false
+		for _, k := range knownEvents {
+			if event == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidEvents, event)
+		}
+		if !events.Has(event) {
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return nil, ErrInvalidEvents
+	}
+	return events, nil
+}
+
+// generateSecret returns a new random signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -27,13 +28,18 @@ import (
 // @license.url   http://www.apache.org/licenses/LICENSE-2.0.html
 
 // @host      localhost:8080
-// @BasePath  /
+// @BasePath  /api/v1
 
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
 // @description JWT token for authentication
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Scoped API key for server-to-server access
+
 // @tag.name auth
 // @tag.description Authentication endpoints
 
@@ -46,39 +52,38 @@ import (
 // @tag.name health
 // @tag.description Health check endpoints
 
+// @tag.name users
+// @tag.description Public user profile and channel endpoints
+
+// @tag.name follows
+// @tag.description User follow and follower listing endpoints
+
+// @tag.name blocks
+// @tag.description Blocking users endpoints
+
+// @tag.name entitlements
+// @tag.description Paid and time-limited video access endpoints
+
+// @tag.name moderation
+// @tag.description Content reports and the moderation queue
+
+// @tag.name sync
+// @tag.description Incremental sync endpoints for offline-capable clients
+
+// @tag.name admin
+// @tag.description Administrative endpoints restricted to the admin role
+
 // @securityDefinitions.basic  BasicAuth
 func main() {
+	validateOnly := flag.Bool("validate", false, "validate the configuration and exit")
+	flag.Parse()
+
 	// Create a root context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Initialize logger first
-	loggerConfig := &logger.Config{
-		Level:       logger.Level("info"),
-		Format:      "json",
-		Output:      "stdout",
-		Development: false,
-		File: struct {
-			Enabled bool   `mapstructure:"enabled" yaml:"enabled"`
-			Path    string `mapstructure:"path" yaml:"path"`
-			Rotate  bool   `mapstructure:"rotate" yaml:"rotate"`
-			MaxSize string `mapstructure:"maxSize" yaml:"maxSize"`
-			MaxAge  string `mapstructure:"maxAge" yaml:"maxAge"`
-		}{
-			Enabled: false,
-			Path:    "/var/log/pavilion",
-			Rotate:  true,
-			MaxSize: "100MB",
-			MaxAge:  "30d",
-		},
-		Sampling: struct {
-			Initial    int `mapstructure:"initial" yaml:"initial"`
-			Thereafter int `mapstructure:"thereafter" yaml:"thereafter"`
-		}{
-			Initial:    100,
-			Thereafter: 100,
-		},
-	}
+	loggerConfig := logger.DefaultConfig()
 
 	loggerService, err := logger.NewLogger(loggerConfig)
 	if err != nil {
@@ -91,6 +96,10 @@ func main() {
 	if err != nil {
 		loggerService.LogFatal(err, "Failed to load configuration")
 	}
+	if *validateOnly {
+		fmt.Println("Configuration is valid")
+		return
+	}
 
 	// Create and initialize the application
 	app, err := NewApp(ctx, cfg)
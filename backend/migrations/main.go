@@ -1,27 +1,223 @@
 package migrations
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/database"
 	"gorm.io/gorm"
 )
 
+// ErrChecksumMismatch is returned when an applied migration's file has
+// changed since it was applied
+var ErrChecksumMismatch = errors.New("applied migration has been modified")
+
 // MigrationRunner handles database migrations
 type MigrationRunner struct {
-	db     *gorm.DB
-	config *database.MigrationConfig
+	db         *gorm.DB
+	config     *database.MigrationConfig
+	migrations []Migration
 }
 
-// NewMigrationRunner creates a new migration runner
-func NewMigrationRunner(db *gorm.DB, config *database.MigrationConfig) *MigrationRunner {
+// NewMigrationRunner creates a new migration runner over the given
+// migrations, ordered by version
+func NewMigrationRunner(db *gorm.DB, config *database.MigrationConfig, migrations []Migration) *MigrationRunner {
 	return &MigrationRunner{
-		db:     db,
-		config: config,
+		db:         db,
+		config:     config,
+		migrations: migrations,
 	}
 }
 
-// RunMigrations runs all migrations in the specified direction
+// Status describes one migration, or an applied migration whose file no
+// longer exists
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	BatchNo   int
+	// Modified is set when the file changed after the migration was applied
+	Modified bool
+	// Missing is set when an applied migration has no file
+	Missing bool
+}
+
+// Status lists every migration with whether it has been applied, ordered by name
+func (r *MigrationRunner) Status() ([]Status, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		status := Status{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Name]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			status.BatchNo = record.BatchNo
+			status.Modified = record.Hash != m.Checksum
+			delete(applied, m.Name)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		statuses = append(statuses, Status{
+			Name:      record.Name,
+			Applied:   true,
+			AppliedAt: record.AppliedAt,
+			BatchNo:   record.BatchNo,
+			Missing:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// Up applies the pending migrations up to and including target, or all of
+// them when target is 0, in one batch. Nothing is applied while an applied
+// migration's file has been modified.
+func (r *MigrationRunner) Up(target int) ([]Migration, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range r.migrations {
+		if record, ok := applied[m.Name]; ok && record.Hash != m.Checksum {
+			return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, m.Name)
+		}
+	}
+
+	batchNo, err := r.nextBatch()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range r.migrations {
+		if target > 0 && m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Name]; ok {
+			continue
+		}
+
+		r.config.Logger.LogInfo("Applying migration", map[string]interface{}{
+			"name":  m.Name,
+			"batch": batchNo,
+		})
+		record := database.MigrationRecord{Name: m.Name, Hash: m.Checksum, BatchNo: batchNo}
+		if err := r.run(m.Up, m.UpInTransaction(), func(tx *gorm.DB) error {
+			record.AppliedAt = time.Now()
+			return tx.Create(&record).Error
+		}); err != nil {
+			return done, fmt.Errorf("failed to apply migration %s: %w", m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Rollback reverts the given number of most recently applied migrations, or
+// the whole last batch when steps is 0
+func (r *MigrationRunner) Rollback(steps int) ([]Migration, error) {
+	records, err := r.config.GetAppliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	// Newest first; migrations applied in one batch are in name order
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].BatchNo != records[j].BatchNo {
+			return records[i].BatchNo > records[j].BatchNo
+		}
+		return records[i].Name > records[j].Name
+	})
+	if steps <= 0 {
+		steps = 0
+		for _, record := range records {
+			if record.BatchNo != records[0].BatchNo {
+				break
+			}
+			steps++
+		}
+	}
+	if steps > len(records) {
+		steps = len(records)
+	}
+
+	byName := make(map[string]Migration, len(r.migrations))
+	for _, m := range r.migrations {
+		byName[m.Name] = m
+	}
+
+	var done []Migration
+	for _, record := range records[:steps] {
+		m, ok := byName[record.Name]
+		if !ok {
+			return done, fmt.Errorf("cannot roll back migration %s: its file is missing", record.Name)
+		}
+
+		r.config.Logger.LogInfo("Rolling back migration", map[string]interface{}{
+			"name":  m.Name,
+			"batch": record.BatchNo,
+		})
+		if err := r.run(m.Down, m.DownInTransaction(), func(tx *gorm.DB) error {
+			return tx.Delete(&database.MigrationRecord{}, record.ID).Error
+		}); err != nil {
+			return done, fmt.Errorf("failed to roll back migration %s: %w", m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// run executes a migration's SQL and then updates schema_migrations, both in
+// one transaction unless the file opted out of it
+func (r *MigrationRunner) run(sql string, inTransaction bool, track func(tx *gorm.DB) error) error {
+	if !inTransaction {
+		if err := r.db.Exec(sql).Error; err != nil {
+			return err
+		}
+		return track(r.db)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(sql).Error; err != nil {
+			return err
+		}
+		return track(tx)
+	})
+}
+
+// applied returns the applied migrations by name
+func (r *MigrationRunner) applied() (map[string]database.MigrationRecord, error) {
+	if err := r.config.InitializeMigrationTable(); err != nil {
+		return nil, fmt.Errorf("failed to initialize migration table: %w", err)
+	}
+	records, err := r.config.GetAppliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	applied := make(map[string]database.MigrationRecord, len(records))
+	for _, record := range records {
+		applied[record.Name] = record
+	}
+	return applied, nil
+}
+
+// nextBatch returns the batch number for migrations applied now
+func (r *MigrationRunner) nextBatch() (int, error) {
+	var batchNo int
+	if err := r.db.Model(&database.MigrationRecord{}).Select("COALESCE(MAX(batch_no), 0) + 1").Row().Scan(&batchNo); err != nil {
+		return 0, fmt.Errorf("failed to determine batch number: %w", err)
+	}
+	return batchNo, nil
+}
+
+// RunMigrations runs all migrations in the specified direction: "up" applies
+// every pending migration and "down" rolls back the last batch
 func RunMigrations(db *gorm.DB, direction string) error {
 	// Initialize migration config with a default logger
 	defaultLogger, err := database.NewDefaultLogger()
@@ -60,7 +256,28 @@ func RunMigrations(db *gorm.DB, direction string) error {
 		return nil
 	}
 
-	// For now, we rely on auto-migration in development/testing
-	// Add versioned migrations here when needed for production
+	migrations, err := Embedded()
+	if err != nil {
+		return err
+	}
+	runner := NewMigrationRunner(db, migrationConfig, migrations)
+
+	var done []Migration
+	switch direction {
+	case "up":
+		done, err = runner.Up(0)
+	case "down":
+		done, err = runner.Rollback(0)
+	default:
+		return fmt.Errorf("unknown migration direction %q", direction)
+	}
+	if err != nil {
+		return err
+	}
+
+	migrationConfig.Logger.LogInfo("Migrations completed", map[string]interface{}{
+		"direction": direction,
+		"count":     len(done),
+	})
 	return nil
 }
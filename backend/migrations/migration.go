@@ -0,0 +1,122 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// files holds the versioned SQL migrations shipped with the binary
+//
+//go:embed sql/*.sql
+var files embed.FS
+
+// noTransactionDirective at the start of a file runs it outside a
+// transaction, for statements CockroachDB does not allow in one, such as
+// adding an enum value that is used in the same migration
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// fileNamePattern matches NNNN_description.up.sql and NNNN_description.down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change with the SQL to apply and revert it
+type Migration struct {
+	Version int
+	// Name is the file name without the direction and extension, e.g.
+	// 0001_initial_schema. It identifies the migration in schema_migrations.
+	Name string
+	Up   string
+	Down string
+	// Checksum is the SHA-256 of the up SQL, recorded when the migration is
+	// applied so later edits to an applied file are detected
+	Checksum string
+}
+
+// UpInTransaction reports whether the up SQL runs in a transaction
+func (m *Migration) UpInTransaction() bool {
+	return !strings.HasPrefix(strings.TrimSpace(m.Up), noTransactionDirective)
+}
+
+// DownInTransaction reports whether the down SQL runs in a transaction
+func (m *Migration) DownInTransaction() bool {
+	return !strings.HasPrefix(strings.TrimSpace(m.Down), noTransactionDirective)
+}
+
+// Embedded returns the migrations shipped with the binary, ordered by version
+func Embedded() ([]Migration, error) {
+	sub, err := fs.Sub(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+// Load reads the migrations in the root of fsys, ordered by version. Every
+// version needs both an up and a down file, and versions must be unique.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named NNNN_description.up.sql or NNNN_description.down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version == 0 {
+			return nil, fmt.Errorf("migration file %s: versions start at 1", entry.Name())
+		}
+		name := match[1] + "_" + match[2]
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migrations %s and %s have the same version", m.Name, name)
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+			m.Checksum = checksum(m.Up)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s has no up file", m.Name)
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("migration %s has no down file", m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// checksum returns the hex SHA-256 of a migration's SQL
+func checksum(sql string) string {
+	sum := sha256.Sum256([]byte(sql))
+	return hex.EncodeToString(sum[:])
+}
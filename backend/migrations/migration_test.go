@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOrdersAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":        {Data: []byte("CREATE INDEX a ON t (a);")},
+		"0002_add_index.down.sql":      {Data: []byte("DROP INDEX a;")},
+		"0001_create_table.up.sql":     {Data: []byte("CREATE TABLE t (a int);")},
+		"0001_create_table.down.sql":   {Data: []byte("DROP TABLE t;")},
+		"0003_add_enum_value.up.sql":   {Data: []byte("-- migrate:no-transaction\nALTER TYPE s ADD VALUE 'x';")},
+		"0003_add_enum_value.down.sql": {Data: []byte("SELECT 1;")},
+		"README.md":                    {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "0001_create_table", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Equal(t, checksum("CREATE TABLE t (a int);"), migrations[0].Checksum)
+	assert.Equal(t, "0002_add_index", migrations[1].Name)
+
+	assert.True(t, migrations[0].UpInTransaction())
+	assert.False(t, migrations[2].UpInTransaction())
+	assert.True(t, migrations[2].DownInTransaction())
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {
+			"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (a int);")},
+		},
+		"missing up": {
+			"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		},
+		"duplicate version": {
+			"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a int);")},
+			"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+			"0001_other.up.sql":          {Data: []byte("SELECT 1;")},
+		},
+		"bad name": {
+			"create_table.sql": {Data: []byte("CREATE TABLE t (a int);")},
+		},
+		"version zero": {
+			"0000_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a int);")},
+			"0000_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		},
+	}
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	migrations, err := Embedded()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "versions have no gaps")
+	}
+}
@@ -0,0 +1,23 @@
+-- migrate:no-transaction
+-- Drops the whole baseline schema, children before the tables they reference.
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS video_access_logs;
+DROP TABLE IF EXISTS moderation_evidence;
+DROP TABLE IF EXISTS content_reports;
+DROP TABLE IF EXISTS entitlements;
+DROP TABLE IF EXISTS followers;
+DROP TABLE IF EXISTS video_captions;
+DROP TABLE IF EXISTS video_versions;
+DROP TABLE IF EXISTS video_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS transcode_segments;
+DROP TABLE IF EXISTS transcodes;
+DROP TABLE IF EXISTS video_uploads;
+DROP TABLE IF EXISTS videos;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS o_auth_identities;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
+DROP TYPE IF EXISTS upload_status;
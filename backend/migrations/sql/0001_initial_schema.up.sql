@@ -0,0 +1,311 @@
+-- migrate:no-transaction
+-- Baseline of the CockroachDB schema as created by GORM auto-migration.
+-- Every statement is conditional so databases created by auto-migration
+-- can record this migration without changes. It runs outside a transaction,
+-- since CockroachDB advises against many schema changes in one; a failed run
+-- can simply be retried.
+
+CREATE TYPE IF NOT EXISTS upload_status AS ENUM ('pending', 'uploading', 'completed', 'failed', 'interrupted');
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid DEFAULT gen_random_uuid(),
+    username text NOT NULL,
+    email text NOT NULL,
+    password text NOT NULL,
+    name text,
+    bio text,
+    avatar_path text,
+    email_verified boolean DEFAULT false,
+    verification_sent_at timestamptz,
+    failed_login_attempts bigint NOT NULL DEFAULT 0,
+    locked_until timestamptz,
+    last_login_at timestamptz,
+    role text NOT NULL DEFAULT 'user',
+    active boolean DEFAULT true,
+    suspended_at timestamptz,
+    suspension_reason text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_username UNIQUE (username),
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    token text NOT NULL,
+    expires_at timestamptz,
+    created_at timestamptz,
+    revoked_at timestamptz,
+    ip_address text,
+    user_agent text,
+    last_used_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_refresh_tokens FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT uni_refresh_tokens_token UNIQUE (token)
+);
+
+CREATE TABLE IF NOT EXISTS o_auth_identities (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    provider text NOT NULL,
+    subject text NOT NULL,
+    email text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_identities_provider_subject ON o_auth_identities (provider, subject);
+CREATE INDEX IF NOT EXISTS idx_o_auth_identities_user_id ON o_auth_identities (user_id);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    prefix text NOT NULL,
+    key_hash text NOT NULL,
+    scopes text NOT NULL,
+    last_used_at timestamptz,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS videos (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid,
+    file_id text NOT NULL,
+    title text NOT NULL,
+    description text,
+    storage_path text NOT NULL,
+    ipfs_cid text,
+    replication_status text NOT NULL DEFAULT 's3-only',
+    checksum varchar(64),
+    audio_path text,
+    preview_path text,
+    scan_status text NOT NULL DEFAULT '',
+    scanner text,
+    scan_findings text,
+    scanned_at timestamptz,
+    taken_down_at timestamptz,
+    takedown_reason text,
+    requires_entitlement boolean NOT NULL DEFAULT false,
+    category text,
+    file_size bigint NOT NULL,
+    views bigint NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    deleted_at timestamptz,
+    duration decimal NOT NULL DEFAULT 0,
+    chapters text,
+    deleted_by uuid,
+    purging boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_videos_file_id UNIQUE (file_id)
+);
+CREATE INDEX IF NOT EXISTS idx_videos_user_id ON videos (user_id);
+CREATE INDEX IF NOT EXISTS idx_videos_deleted_at ON videos (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_videos_views ON videos (views);
+CREATE INDEX IF NOT EXISTS idx_videos_category ON videos (category);
+CREATE INDEX IF NOT EXISTS idx_videos_taken_down_at ON videos (taken_down_at);
+CREATE INDEX IF NOT EXISTS idx_videos_scan_status ON videos (scan_status);
+CREATE INDEX IF NOT EXISTS idx_videos_checksum ON videos (checksum);
+
+CREATE TABLE IF NOT EXISTS video_uploads (
+    id uuid DEFAULT gen_random_uuid(),
+    video_id uuid NOT NULL,
+    start_time timestamptz NOT NULL,
+    end_time timestamptz,
+    status upload_status NOT NULL,
+    stored_bytes bigint NOT NULL DEFAULT 0,
+    version bigint NOT NULL DEFAULT 1,
+    transcode_failures text,
+    renditions text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_videos_upload FOREIGN KEY (video_id) REFERENCES videos (id),
+    CONSTRAINT uni_video_uploads_video_id UNIQUE (video_id)
+);
+
+CREATE TABLE IF NOT EXISTS transcodes (
+    id uuid DEFAULT gen_random_uuid(),
+    video_id uuid NOT NULL,
+    format text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_videos_transcodes FOREIGN KEY (video_id) REFERENCES videos (id),
+    CONSTRAINT chk_transcodes_format CHECK (format IN ('mp4', 'hls'))
+);
+
+CREATE TABLE IF NOT EXISTS transcode_segments (
+    id uuid DEFAULT gen_random_uuid(),
+    transcode_id uuid NOT NULL,
+    storage_path text NOT NULL,
+    ipfs_cid text,
+    replication_status text NOT NULL DEFAULT 's3-only',
+    duration bigint,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_transcodes_segments FOREIGN KEY (transcode_id) REFERENCES transcodes (id)
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+    id uuid DEFAULT gen_random_uuid(),
+    name text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_name ON tags (name);
+
+CREATE TABLE IF NOT EXISTS video_tags (
+    video_id uuid,
+    tag_id uuid,
+    PRIMARY KEY (video_id, tag_id),
+    CONSTRAINT fk_video_tags_video FOREIGN KEY (video_id) REFERENCES videos (id),
+    CONSTRAINT fk_video_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+);
+
+CREATE TABLE IF NOT EXISTS video_versions (
+    id uuid DEFAULT gen_random_uuid(),
+    video_id uuid NOT NULL,
+    version bigint NOT NULL,
+    checksum varchar(64),
+    file_size bigint NOT NULL,
+    ipfs_cids text,
+    uploaded_at timestamptz NOT NULL,
+    replaced_at timestamptz NOT NULL,
+    purged_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_video_versions_purged_at ON video_versions (purged_at);
+CREATE INDEX IF NOT EXISTS idx_video_versions_replaced_at ON video_versions (replaced_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_video_versions_video_version ON video_versions (video_id, version);
+
+CREATE TABLE IF NOT EXISTS video_captions (
+    id uuid DEFAULT gen_random_uuid(),
+    video_id uuid NOT NULL,
+    language varchar(35) NOT NULL,
+    label varchar(64),
+    storage_path text NOT NULL,
+    burned_in_path text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_videos_captions FOREIGN KEY (video_id) REFERENCES videos (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_video_captions_video_language ON video_captions (video_id, language);
+
+CREATE TABLE IF NOT EXISTS followers (
+    follower_id uuid,
+    followee_id uuid,
+    created_at timestamptz NOT NULL,
+    updated_at timestamptz NOT NULL,
+    deleted_at timestamptz,
+    PRIMARY KEY (follower_id, followee_id)
+);
+CREATE INDEX IF NOT EXISTS idx_followers_deleted_at ON followers (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_followers_followee_id ON followers (followee_id);
+
+CREATE TABLE IF NOT EXISTS entitlements (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    video_id uuid NOT NULL,
+    source text NOT NULL,
+    reference text,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_entitlements_source_reference ON entitlements (source, reference);
+CREATE INDEX IF NOT EXISTS idx_entitlements_user_video ON entitlements (user_id, video_id);
+
+CREATE TABLE IF NOT EXISTS content_reports (
+    id uuid DEFAULT gen_random_uuid(),
+    reporter_id uuid NOT NULL,
+    target_type text NOT NULL,
+    target_id uuid NOT NULL,
+    reason text NOT NULL,
+    details text,
+    status text NOT NULL DEFAULT 'open',
+    resolution text,
+    resolved_by uuid,
+    resolved_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_content_reports_status ON content_reports (status);
+CREATE INDEX IF NOT EXISTS idx_reports_target ON content_reports (target_type, target_id);
+CREATE INDEX IF NOT EXISTS idx_content_reports_reporter_id ON content_reports (reporter_id);
+
+CREATE TABLE IF NOT EXISTS moderation_evidence (
+    id uuid DEFAULT gen_random_uuid(),
+    target_type text NOT NULL,
+    target_id uuid NOT NULL,
+    report_id uuid,
+    trigger text NOT NULL,
+    version bigint NOT NULL,
+    author_id uuid NOT NULL,
+    title text,
+    body text,
+    storage_path text,
+    ipfs_cid text,
+    checksum text,
+    video_id uuid,
+    content_updated_at timestamptz,
+    captured_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_evidence_target ON moderation_evidence (target_type, target_id);
+
+CREATE TABLE IF NOT EXISTS video_access_logs (
+    id uuid DEFAULT gen_random_uuid(),
+    video_id uuid NOT NULL,
+    country text,
+    device_class text NOT NULL,
+    referrer text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_video_access_logs_created_at ON video_access_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_video_access_logs_video_created ON video_access_logs (video_id, created_at);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid DEFAULT gen_random_uuid(),
+    webhook_id uuid NOT NULL,
+    event text NOT NULL,
+    payload text NOT NULL,
+    status text NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    response_status bigint,
+    last_error text,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    delivered_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS auth_security_events (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    type text NOT NULL,
+    outcome text NOT NULL,
+    reason text,
+    method text,
+    ip_address text,
+    user_agent text,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_auth_security_events_user_created ON auth_security_events (user_id, created_at);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS user_blocks (
+    blocker_id uuid,
+    blocked_id uuid,
+    created_at timestamptz NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_id ON user_blocks (blocked_id);
@@ -0,0 +1 @@
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS comments_policy text NOT NULL DEFAULT 'enabled';
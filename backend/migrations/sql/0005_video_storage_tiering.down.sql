@@ -0,0 +1,4 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS last_viewed_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS renditions_dropped_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS tiering_override;
+ALTER TABLE videos DROP COLUMN IF EXISTS storage_class;
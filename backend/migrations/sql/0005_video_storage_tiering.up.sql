@@ -0,0 +1,4 @@
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS storage_class text NOT NULL DEFAULT '';
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS tiering_override text NOT NULL DEFAULT 'default';
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS renditions_dropped_at timestamptz;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS last_viewed_at timestamptz;
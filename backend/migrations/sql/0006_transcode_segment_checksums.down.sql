@@ -0,0 +1 @@
+ALTER TABLE transcode_segments DROP COLUMN IF EXISTS checksum;
@@ -0,0 +1 @@
+ALTER TABLE transcode_segments ADD COLUMN IF NOT EXISTS checksum varchar(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS federation_remote_videos;
+DROP TABLE IF EXISTS federation_instances;
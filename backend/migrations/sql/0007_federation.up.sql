@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS federation_instances (
+    id uuid DEFAULT gen_random_uuid(),
+    url text NOT NULL,
+    name text NOT NULL,
+    public_key text NOT NULL,
+    outbox_cursor text NOT NULL DEFAULT '',
+    last_polled_at timestamptz,
+    last_error text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_federation_instances_url ON federation_instances (url);
+
+CREATE TABLE IF NOT EXISTS federation_remote_videos (
+    id uuid DEFAULT gen_random_uuid(),
+    instance_id uuid NOT NULL,
+    remote_id uuid NOT NULL,
+    title text NOT NULL,
+    description text NOT NULL DEFAULT '',
+    category text NOT NULL DEFAULT '',
+    tags text NOT NULL DEFAULT '',
+    duration float8 NOT NULL DEFAULT 0,
+    views bigint NOT NULL DEFAULT 0,
+    ipfs_cid text NOT NULL DEFAULT '',
+    published_at timestamptz NOT NULL,
+    remote_updated_at timestamptz NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_federation_remote_videos_remote ON federation_remote_videos (instance_id, remote_id);
+CREATE INDEX IF NOT EXISTS idx_federation_remote_videos_published_at ON federation_remote_videos (published_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS activitypub_followers;
+DROP TABLE IF EXISTS activitypub_actor_keys;
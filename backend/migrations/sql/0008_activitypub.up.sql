@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS activitypub_actor_keys (
+    user_id uuid NOT NULL,
+    private_key_pem text NOT NULL,
+    public_key_pem text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS activitypub_followers (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    actor_id text NOT NULL,
+    inbox text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_activitypub_followers_actor ON activitypub_followers (user_id, actor_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS embed_domains;
+ALTER TABLE videos DROP COLUMN IF EXISTS embeddable;
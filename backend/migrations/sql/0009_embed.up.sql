@@ -0,0 +1,10 @@
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS embeddable bool NOT NULL DEFAULT true;
+
+CREATE TABLE IF NOT EXISTS embed_domains (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    domain text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_embed_domains_user_domain ON embed_domains (user_id, domain);
@@ -0,0 +1,2 @@
+ALTER TABLE videos DROP COLUMN IF EXISTS blocked_countries;
+ALTER TABLE videos DROP COLUMN IF EXISTS allowed_countries;
@@ -0,0 +1,2 @@
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS allowed_countries text;
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS blocked_countries text;
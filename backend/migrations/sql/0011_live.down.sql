@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS live_streams;
+DROP TABLE IF EXISTS live_stream_keys;
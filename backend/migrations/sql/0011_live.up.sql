@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS live_stream_keys (
+    user_id uuid NOT NULL,
+    key_hash text,
+    prefix text NOT NULL DEFAULT '',
+    title text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_live_stream_keys_key_hash ON live_stream_keys (key_hash);
+
+CREATE TABLE IF NOT EXISTS live_streams (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    title text NOT NULL,
+    status text NOT NULL,
+    started_at timestamptz NOT NULL,
+    ended_at timestamptz,
+    video_id uuid,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_live_streams_user_id ON live_streams (user_id);
+CREATE INDEX IF NOT EXISTS idx_live_streams_status ON live_streams (status);
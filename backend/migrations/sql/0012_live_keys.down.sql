@@ -0,0 +1,25 @@
+-- migrate:no-transaction
+ALTER TABLE live_streams DROP COLUMN IF EXISTS bytes_received;
+ALTER TABLE live_streams DROP COLUMN IF EXISTS key_id;
+
+CREATE TABLE IF NOT EXISTS live_stream_keys (
+    user_id uuid NOT NULL,
+    key_hash text,
+    prefix text NOT NULL DEFAULT '',
+    title text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_live_stream_keys_key_hash ON live_stream_keys (key_hash);
+-- Users keep their newest active key
+INSERT INTO live_stream_keys (user_id, key_hash, prefix, created_at, updated_at)
+SELECT DISTINCT ON (user_id) user_id, key_hash, prefix, created_at, updated_at FROM live_keys
+WHERE revoked_at IS NULL
+ORDER BY user_id, created_at DESC
+ON CONFLICT (user_id) DO NOTHING;
+INSERT INTO live_stream_keys (user_id, title, created_at, updated_at)
+SELECT user_id, title, created_at, updated_at FROM live_settings
+ON CONFLICT (user_id) DO UPDATE SET title = excluded.title;
+DROP TABLE IF EXISTS live_settings;
+DROP TABLE IF EXISTS live_keys;
@@ -0,0 +1,51 @@
+-- migrate:no-transaction
+-- Stream keys move to their own table, so users can hold several, and stream
+-- titles to live_settings. CockroachDB does not drop a table in a transaction
+-- that copied rows out of it, so every statement is conditional.
+CREATE TABLE IF NOT EXISTS live_keys (
+    id uuid DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    key_hash text NOT NULL,
+    prefix text NOT NULL,
+    last_four text NOT NULL DEFAULT '',
+    last_used_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_live_keys_key_hash ON live_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_live_keys_user_id ON live_keys (user_id);
+
+CREATE TABLE IF NOT EXISTS live_settings (
+    user_id uuid NOT NULL,
+    title text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS live_stream_keys (
+    user_id uuid NOT NULL,
+    key_hash text,
+    prefix text NOT NULL DEFAULT '',
+    title text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id)
+);
+-- The end of earlier keys was not stored, so they are masked by their prefix alone
+INSERT INTO live_keys (user_id, name, key_hash, prefix, created_at, updated_at)
+SELECT user_id, 'Default', key_hash, prefix, updated_at, updated_at FROM live_stream_keys
+WHERE key_hash IS NOT NULL
+ON CONFLICT (key_hash) DO NOTHING;
+INSERT INTO live_settings (user_id, title, created_at, updated_at)
+SELECT user_id, title, created_at, updated_at FROM live_stream_keys
+WHERE title <> ''
+ON CONFLICT (user_id) DO NOTHING;
+DROP TABLE IF EXISTS live_stream_keys;
+
+ALTER TABLE live_streams ADD COLUMN IF NOT EXISTS key_id uuid;
+ALTER TABLE live_streams ADD COLUMN IF NOT EXISTS bytes_received int8 NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_live_streams_key_id ON live_streams (key_id);
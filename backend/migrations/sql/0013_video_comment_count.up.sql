@@ -0,0 +1 @@
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS comment_count int8 NOT NULL DEFAULT 0;
@@ -0,0 +1,34 @@
+-- Background jobs run by the worker pool in internal/jobs, and the recurring
+-- schedules that queue them
+CREATE TABLE IF NOT EXISTS jobs (
+    id uuid DEFAULT gen_random_uuid(),
+    type text NOT NULL,
+    payload text NOT NULL DEFAULT '{}',
+    status text NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    max_attempts bigint NOT NULL DEFAULT 1,
+    last_error text,
+    schedule text,
+    run_at timestamptz NOT NULL DEFAULT now(),
+    started_at timestamptz,
+    finished_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs (status, run_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_type ON jobs (type);
+CREATE INDEX IF NOT EXISTS idx_jobs_created_at ON jobs (created_at);
+
+CREATE TABLE IF NOT EXISTS job_schedules (
+    name text NOT NULL,
+    spec text NOT NULL,
+    type text NOT NULL,
+    payload text NOT NULL DEFAULT '{}',
+    next_run_at timestamptz NOT NULL,
+    last_run_at timestamptz,
+    last_job_id uuid,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (name)
+);
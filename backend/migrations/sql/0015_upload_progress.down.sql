@@ -0,0 +1,3 @@
+ALTER TABLE video_uploads DROP COLUMN IF EXISTS rendition_times;
+ALTER TABLE video_uploads DROP COLUMN IF EXISTS stage_times;
+ALTER TABLE video_uploads DROP COLUMN IF EXISTS stage;
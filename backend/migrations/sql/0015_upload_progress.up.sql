@@ -0,0 +1,5 @@
+-- The stage of the pipeline an upload has reached, and when it entered each
+-- stage and each rendition started and finished
+ALTER TABLE video_uploads ADD COLUMN IF NOT EXISTS stage text;
+ALTER TABLE video_uploads ADD COLUMN IF NOT EXISTS stage_times text;
+ALTER TABLE video_uploads ADD COLUMN IF NOT EXISTS rendition_times text;
@@ -0,0 +1 @@
+ALTER TABLE video_uploads DROP COLUMN IF EXISTS rendition_progress;
@@ -0,0 +1,2 @@
+-- How much of each rendition of an upload has been encoded, in percent
+ALTER TABLE video_uploads ADD COLUMN IF NOT EXISTS rendition_progress text;
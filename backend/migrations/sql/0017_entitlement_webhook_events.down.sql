@@ -0,0 +1 @@
+DROP TABLE IF EXISTS entitlement_webhook_events;
@@ -0,0 +1,9 @@
+-- IDs of entitlement webhook events already applied, so a replayed or
+-- retried event is ignored. Rows are pruned once their timestamp can no
+-- longer pass the webhook's skew check.
+CREATE TABLE IF NOT EXISTS entitlement_webhook_events (
+    id text NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_entitlement_webhook_events_received_at ON entitlement_webhook_events (received_at);
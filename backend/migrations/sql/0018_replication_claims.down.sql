@@ -0,0 +1,2 @@
+ALTER TABLE transcode_segments DROP COLUMN IF EXISTS replication_updated_at;
+ALTER TABLE videos DROP COLUMN IF EXISTS replication_updated_at;
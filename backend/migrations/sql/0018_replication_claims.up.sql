@@ -0,0 +1,4 @@
+-- When a file's replication status last changed, so stale claims can be
+-- found without touching updated_at, which clients read as content changes
+ALTER TABLE videos ADD COLUMN IF NOT EXISTS replication_updated_at timestamptz;
+ALTER TABLE transcode_segments ADD COLUMN IF NOT EXISTS replication_updated_at timestamptz;
@@ -2,41 +2,216 @@ package main
 
 import (
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
+	httpHandler "github.com/consensuslabs/pavilion-network/backend/internal/http"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// SetupRoutes configures all the routes for our application
+// legacyRoutes are the unversioned paths the API was served at before it moved
+// under server.basePath. They redirect to the same path under the base path.
+var legacyRoutes = []string{
+	"/auth/*path",
+	"/comment/*path",
+	"/me/*path",
+	"/sync/*path",
+	"/tags/*path",
+	"/video/*path",
+	"/videos",
+	"/admin/*path",
+}
+
+// SetupRoutes configures all the routes for our application. API routes are
+// registered under server.basePath; static files, health checks, metrics and
+// Swagger stay at the root.
 func SetupRoutes(router *gin.Engine, app *App) {
 	// Static file serving
 	router.Static("/public", "../frontend/public")
 	router.Static("/uploads", app.Config.Storage.UploadDir)
+	if app.Config.Storage.Backend == config.StorageBackendLocal {
+		// Stored videos and avatars, linked in place of presigned S3 URLs
+		router.Static("/storage", app.Config.Storage.Local.Dir)
+	}
+
+	// Health checks: /health/live for liveness, /health/ready checks dependencies
+	app.healthHandler.RegisterRoutes(router)
 
-	// Health check
-	router.GET("/health", app.healthHandler.HandleHealthCheck)
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	basePath := app.Config.Server.BasePath
+	api := router.Group(basePath)
+	if basePath != "" {
+		for _, route := range legacyRoutes {
+			router.Any(route, httpHandler.RedirectToBasePath(basePath))
+		}
+
+		// Health checks are also served under the base path, where the API docs list them
+		app.healthHandler.RegisterRoutes(api)
+	}
 
 	// Register auth routes
-	app.authHandler.RegisterRoutes(router)
+	app.authHandler.RegisterRoutes(api)
 
 	// Register comment routes if comment service is available
 	if app.commentHandler != nil {
-		app.commentHandler.RegisterRoutes(router, app.auth)
+		app.commentHandler.RegisterRoutes(api, app.auth)
 	}
 
 	// Register notification routes if notification service is available
 	if app.notificationHandler != nil {
-		app.notificationHandler.RegisterRoutes(router, auth.AuthMiddleware(app.auth, app.httpHandler))
+		app.notificationHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Register notification pipeline metrics routes
+	if app.notificationMetrics != nil {
+		app.notificationMetrics.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler), auth.RequireRole(auth.RoleAdmin, app.httpHandler))
+	}
+
+	// Register follow routes
+	if app.followHandler != nil {
+		app.followHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Register block routes
+	if app.blockHandler != nil {
+		app.blockHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Register public profile routes
+	if app.userHandler != nil {
+		app.userHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Register entitlement routes
+	if app.entitlementHandler != nil {
+		app.entitlementHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Register content report and moderation queue routes
+	if app.moderationHandler != nil {
+		app.moderationHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler), auth.RequireRole(auth.RoleModerator, app.httpHandler))
+	}
+
+	// Register video access log routes
+	if app.accessLogHandler != nil {
+		app.accessLogHandler.RegisterRoutes(api, auth.APIKeyOrBearerMiddleware(app.auth, app.httpHandler), auth.RequireScope(auth.ScopeRead, app.httpHandler))
+	}
+
+	// Register webhook registration and delivery log routes
+	if app.webhookHandler != nil {
+		app.webhookHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Register operator dashboard routes
+	if app.adminHandler != nil {
+		app.adminHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler), auth.RequireRole(auth.RoleAdmin, app.httpHandler))
+	}
+
+	// Register the background job admin routes
+	if app.jobsHandler != nil {
+		app.jobsHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler), auth.RequireRole(auth.RoleAdmin, app.httpHandler))
 	}
 
-	// Protected routes group
-	protected := router.Group("")
-	protected.Use(auth.AuthMiddleware(app.auth, app.httpHandler))
+	// Register audit log routes
+	if app.auditHandler != nil {
+		app.auditHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler), auth.RequireRole(auth.RoleAdmin, app.httpHandler))
+	}
+
+	// Register watch history routes
+	if app.historyHandler != nil {
+		app.historyHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Register the GraphQL endpoint, which like the video routes accepts API keys
+	if app.graphqlHandler != nil {
+		app.graphqlHandler.RegisterRoutes(api, auth.APIKeyOrBearerMiddleware(app.auth, app.httpHandler), auth.RequireScope(auth.ScopeRead, app.httpHandler))
+	}
+
+	// Register peer-to-peer info routes when p2p is enabled
+	if app.p2pHandler != nil {
+		app.p2pHandler.RegisterRoutes(api)
+	}
+
+	// Register the federation outbox and the admin subscription routes
+	if app.federationHandler != nil {
+		app.federationHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler), auth.RequireRole(auth.RoleAdmin, app.httpHandler))
+	}
+
+	// Register the ActivityPub actors and WebFinger, which lives at the domain root
+	if app.activityPubHandler != nil {
+		app.activityPubHandler.RegisterRoutes(api)
+		app.activityPubHandler.RegisterWellKnownRoutes(router)
+	}
+
+	// Register the public channel and latest videos feeds
+	if app.feedHandler != nil {
+		app.feedHandler.RegisterRoutes(api)
+	}
+
+	// Register oEmbed and the sitemap, which live at the domain root
+	if app.sharingHandler != nil {
+		app.sharingHandler.RegisterRoutes(router)
+	}
+
+	// Register the embedded player, which lives at the domain root, and the
+	// allowed domain routes
+	if app.embedHandler != nil {
+		app.embedHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+		app.embedHandler.RegisterPlayerRoutes(router)
+	}
+
+	// Register live stream playback and streaming settings routes
+	if app.liveHandler != nil {
+		app.liveHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler),
+			auth.APIKeyOrBearerMiddleware(app.auth, app.httpHandler), auth.RequireScope(auth.ScopeRead, app.httpHandler))
+	}
+
+	// Register differential sync routes
+	if app.syncHandler != nil {
+		app.syncHandler.RegisterRoutes(api, auth.AuthMiddleware(app.auth, app.httpHandler))
+	}
+
+	// Video routes accept either a user's bearer token or a scoped API key
+	videos := api.Group("")
+	videos.Use(auth.APIKeyOrBearerMiddleware(app.auth, app.httpHandler))
+	{
+		read := auth.RequireScope(auth.ScopeRead, app.httpHandler)
+		upload := auth.RequireScope(auth.ScopeUpload, app.httpHandler)
+		invalidate := app.responseCache.Invalidate("videos")
+
+		videos.POST("/video/upload", upload, app.idempotency.Keys(), app.rateLimiter.Limit("upload"), invalidate, app.videoHandler.HandleUpload)
+		videos.GET("/videos", read, app.responseCache.Cache("videos"), app.videoHandler.ListVideos)
+		videos.GET("/tags/popular", read, app.videoHandler.PopularTags)
+		videos.GET("/video/:id", read, app.videoHandler.GetVideo)
+		videos.GET("/video/:id/status", read, app.videoHandler.GetVideoStatus)
+		videos.GET("/video/:id/events", read, app.videoHandler.StreamUploadEvents)
+		videos.GET("/video/:id/stream/:resolution", read, app.videoHandler.StreamVideo)
+		videos.GET("/video/:id/sources", read, app.videoHandler.GetVideoSources)
+		videos.GET("/video/:id/related", read, app.videoHandler.GetRelatedVideos)
+		// Comment listings carry the video's comments policy
+		videos.PATCH("/video/:id", upload, app.responseCache.Invalidate("videos", "comments"), app.videoHandler.UpdateVideo)
+		videos.DELETE("/video/:id", upload, invalidate, app.videoHandler.DeleteVideo)
+		videos.POST("/video/:id/restore", upload, invalidate, app.videoHandler.UndeleteVideo)
+		videos.GET("/me/videos/trash", read, app.videoHandler.ListTrash)
+		videos.POST("/video/:id/replace", upload, app.rateLimiter.Limit("upload"), invalidate, app.videoHandler.HandleReplace)
+		videos.GET("/video/:id/versions", read, app.videoHandler.ListVersions)
+		videos.POST("/video/:id/captions", upload, app.rateLimiter.Limit("upload"), invalidate, app.videoHandler.HandleCaptionUpload)
+		videos.GET("/video/:id/captions", read, app.videoHandler.ListCaptions)
+		videos.PATCH("/video/:id/chapters", upload, invalidate, app.videoHandler.UpdateChapters)
+	}
+
+	// Content scan review is for admins signed in with a bearer token
+	scans := api.Group("/admin")
+	scans.Use(auth.AuthMiddleware(app.auth, app.httpHandler), auth.RequireRole(auth.RoleAdmin, app.httpHandler))
 	{
-		// Video routes that require authentication
-		protected.POST("/video/upload", app.videoHandler.HandleUpload)
-		protected.GET("/videos", app.videoHandler.ListVideos)
-		protected.GET("/video/:id", app.videoHandler.GetVideo)
-		protected.GET("/video/:id/status", app.videoHandler.GetVideoStatus)
-		protected.PATCH("/video/:id", app.videoHandler.UpdateVideo)
-		protected.DELETE("/video/:id", app.videoHandler.DeleteVideo)
+		invalidate := app.responseCache.Invalidate("videos")
+
+		scans.GET("/scans", app.videoHandler.ListScanResults)
+		scans.POST("/videos/:id/release", invalidate, app.videoHandler.ReleaseVideo)
+		scans.POST("/videos/:id/takedown", invalidate, app.videoHandler.TakeDownVideo)
+		scans.DELETE("/videos/:id/takedown", invalidate, app.videoHandler.RestoreVideo)
+		scans.GET("/storage/tiering", app.videoHandler.GetTieringReport)
+		scans.PUT("/videos/:id/tiering", app.videoHandler.SetTieringOverride)
 	}
 }
@@ -7,206 +7,50 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/joho/godotenv"
-	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/consensuslabs/pavilion-network/backend/internal/auth"
+	"github.com/consensuslabs/pavilion-network/backend/internal/config"
 	"github.com/consensuslabs/pavilion-network/backend/internal/video"
 	"github.com/consensuslabs/pavilion-network/backend/migrations"
 )
 
-// Config represents the minimal configuration needed for the test environment.
-type Config struct {
-	Environment string `mapstructure:"environment"`
-	Server      struct {
-		Host string `mapstructure:"host"`
-		Port int    `mapstructure:"port"`
-	} `mapstructure:"server"`
-	Database struct {
-		Driver   string `mapstructure:"driver"`
-		Host     string `mapstructure:"host"`
-		Port     int    `mapstructure:"port"`
-		User     string `mapstructure:"user"`
-		Password string `mapstructure:"password"`
-		Name     string `mapstructure:"dbname"`
-		SSLMode  string `mapstructure:"sslmode"`
-	} `mapstructure:"database"`
-	Storage struct {
-		UploadDir string `mapstructure:"uploadDir"`
-		TempDir   string `mapstructure:"tempDir"`
-		IPFS      struct {
-			APIAddress string `mapstructure:"apiAddress"`
-			Gateway    string `mapstructure:"gateway"`
-		} `mapstructure:"ipfs"`
-		S3 struct {
-			Endpoint        string `mapstructure:"endpoint"`
-			Bucket          string `mapstructure:"bucket"`
-			Region          string `mapstructure:"region"`
-			AccessKeyID     string `mapstructure:"accessKeyId"`
-			SecretAccessKey string `mapstructure:"secretAccessKey"`
-			UseSSL          bool   `mapstructure:"useSSL"`
-			RootDirectory   string `mapstructure:"root_directory"`
-		} `mapstructure:"s3"`
-	} `mapstructure:"storage"`
-	FFmpeg struct {
-		Path        string   `mapstructure:"path"`
-		ProbePath   string   `mapstructure:"probePath"`
-		VideoCodec  string   `mapstructure:"videoCodec"`
-		AudioCodec  string   `mapstructure:"audioCodec"`
-		Preset      string   `mapstructure:"preset"`
-		OutputPath  string   `mapstructure:"outputPath"`
-		Resolutions []string `mapstructure:"resolutions"`
-	} `mapstructure:"ffmpeg"`
-	Video struct {
-		MaxSize        int64    `mapstructure:"maxSize"`
-		MinTitleLength int      `mapstructure:"minTitleLength"`
-		MaxTitleLength int      `mapstructure:"maxTitleLength"`
-		MaxDescLength  int      `mapstructure:"maxDescLength"`
-		AllowedFormats []string `mapstructure:"allowedFormats"`
-	} `mapstructure:"video"`
-	Logging struct {
-		Level       string `mapstructure:"level"`
-		Format      string `mapstructure:"format"`
-		Output      string `mapstructure:"output"`
-		Development bool   `mapstructure:"development"`
-	} `mapstructure:"logging"`
+// LoadTestConfig loads config_test.yaml and .env.test through the config
+// package. They are looked up in TEST_CONFIG_DIR when it is set, otherwise in
+// the nearest directory above the working directory that has config_test.yaml.
+func LoadTestConfig() (*config.Config, error) {
+	dir := os.Getenv("TEST_CONFIG_DIR")
+	if dir == "" {
+		var err error
+		if dir, err = findTestConfigDir(); err != nil {
+			return nil, err
+		}
+	}
+	return config.NewConfigService(NewTestLogger(false)).LoadEnvironment(dir, "test")
 }
 
-// LoadTestConfig loads the test configuration from config_test.yaml.
-func LoadTestConfig() (*Config, error) {
-	// Load environment variables from .env.test from alternative locations
-	envFiles := []string{
-		".env.test",
-		"../.env.test",
-		"../../.env.test",
-		"../../../.env.test",
-		"../../../../.env.test",
+// findTestConfigDir walks up from the working directory to the one holding
+// config_test.yaml, stopping at the module root
+func findTestConfigDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
 	}
-
-	// Print current working directory for debugging
-	cwd, _ := os.Getwd()
-	fmt.Println("Current working directory for config loading:", cwd)
-	
-	envLoaded := false
-	for _, envFile := range envFiles {
-		absPath, _ := filepath.Abs(envFile)
-		if _, err := os.Stat(absPath); err == nil {
-			fmt.Printf("Found .env.test at: %s\n", absPath)
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "config_test.yaml")); err == nil {
+			return dir, nil
 		}
-		
-		if err := godotenv.Load(envFile); err == nil {
-			envLoaded = true
-			fmt.Printf("Successfully loaded env from: %s\n", envFile)
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
 			break
 		}
-	}
-
-	if !envLoaded {
-		fmt.Println("Warning: .env.test not loaded from any location, proceeding without it")
-	}
-	
-	// Try looking for an env file at the project root level
-	rootSearchPaths := []string{
-		"/Users/umitdogan/workout/dev/pavilion-network-mvp/pavilion-network/backend/.env.test",
-		"/Users/umitdogan/workout/dev/pavilion-network-mvp/pavilion-network/.env.test",
-	}
-	
-	for _, envFile := range rootSearchPaths {
-		if _, err := os.Stat(envFile); err == nil {
-			fmt.Printf("Found additional .env.test at: %s\n", envFile)
-			if err := godotenv.Load(envFile); err == nil {
-				fmt.Printf("Successfully loaded env from additional path: %s\n", envFile)
-				envLoaded = true
-			}
-		}
-	}
-
-	v := viper.New()
-	
-	// Set up environment variables mapping
-	v.SetEnvPrefix("")  // No prefix for environment variables
-	v.AutomaticEnv()
-	
-	// Map specific environment variables to config keys for S3
-	v.BindEnv("storage.s3.accessKeyId", "S3_ACCESS_KEY_ID")
-	v.BindEnv("storage.s3.secretAccessKey", "S3_SECRET_ACCESS_KEY")
-	
-	// Debug output of environment variables
-	fmt.Println("Environment variables:")
-	fmt.Printf("S3_ACCESS_KEY_ID set: %v\n", os.Getenv("S3_ACCESS_KEY_ID") != "")
-	fmt.Printf("S3_SECRET_ACCESS_KEY set: %v\n", os.Getenv("S3_SECRET_ACCESS_KEY") != "")
-
-	// Check if TEST_CONFIG_FILE environment variable is set to explicitly specify config file location.
-	if cfgFile := os.Getenv("TEST_CONFIG_FILE"); cfgFile != "" {
-		v.SetConfigFile(cfgFile)
-	} else {
-		// Use the intended test config file with test database settings
-		v.SetConfigName("config_test")
-		v.SetConfigType("yaml")
-
-		// Add paths for different test directory depths
-		// For auth tests (2 levels deep)
-		v.AddConfigPath("..")
-		v.AddConfigPath("../..")
-
-		// For video e2e tests (4 levels deep)
-		v.AddConfigPath("../../..")
-		v.AddConfigPath("../../../..")
-
-		// Also try the current directory
-		v.AddConfigPath(".")
-
-		// Try to find the project root by looking for go.mod
-		if wd, err := os.Getwd(); err == nil {
-			// Start from current directory and go up to find project root
-			dir := wd
-			for i := 0; i < 5; i++ { // Look up to 5 levels up
-				// Check if this directory contains config_test.yaml
-				if _, err := os.Stat(filepath.Join(dir, "config_test.yaml")); err == nil {
-					v.AddConfigPath(dir)
-					break
-				}
-
-				// Check if this is the project root (contains go.mod)
-				if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
-					v.AddConfigPath(dir) // Found project root
-					break
-				}
-
-				// Go up one directory
-				parentDir := filepath.Dir(dir)
-				if parentDir == dir {
-					// We've reached the filesystem root
-					break
-				}
-				dir = parentDir
-			}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
 		}
+		dir = parent
 	}
-
-	// Print all config paths for debugging
-	fmt.Println("Looking for config_test.yaml in these paths:")
-	// Viper doesn't expose a method to get all config paths, so we'll just list the ones we added
-	fmt.Println(" - Current directory")
-	fmt.Println(" - Parent directory (..)")
-	fmt.Println(" - Grandparent directory (../..)")
-	fmt.Println(" - Great-grandparent directory (../../..)")
-	fmt.Println(" - Great-great-grandparent directory (../../../..)")
-	fmt.Println(" - Project root (if found)")
-
-	if err := v.ReadInConfig(); err != nil {
-		return nil, err
-	}
-
-	fmt.Println("Loaded config from:", v.ConfigFileUsed())
-
-	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, err
-	}
-	return &cfg, nil
+	return "", fmt.Errorf("config_test.yaml not found above the working directory; set TEST_CONFIG_DIR")
 }
 
 // SetupTestDB connects to the test database and runs migrations.
@@ -225,8 +69,8 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 			"host":    cfg.Database.Host,
 			"port":    cfg.Database.Port,
 			"user":    cfg.Database.User,
-			"dbname":  cfg.Database.Name,
-			"sslmode": cfg.Database.SSLMode,
+			"dbname":  cfg.Database.Dbname,
+			"sslmode": cfg.Database.Sslmode,
 		},
 	})
 
@@ -238,15 +82,15 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		logger.LogInfo("Overriding database name", map[string]interface{}{
 			"test_db": testDB,
 		})
-		cfg.Database.Name = testDB
+		cfg.Database.Dbname = testDB
 	}
 	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Database.Host,
 		cfg.Database.Port,
 		cfg.Database.User,
 		cfg.Database.Password,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
+		cfg.Database.Dbname,
+		cfg.Database.Sslmode,
 	)
 
 	// Use the DSN to open a database connection.
@@ -256,7 +100,7 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Explicitly switch to the specified test database to avoid connecting to default_db
-	if err := db.Exec("USE " + cfg.Database.Name).Error; err != nil {
+	if err := db.Exec("USE " + cfg.Database.Dbname).Error; err != nil {
 		t.Fatalf("failed to execute USE query on test database: %v", err)
 	}
 
@@ -275,7 +119,7 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Auto migrate auth models.
-	if err := db.AutoMigrate(&auth.User{}, &auth.RefreshToken{}); err != nil {
+	if err := db.AutoMigrate(&auth.User{}, &auth.RefreshToken{}, &auth.OAuthIdentity{}, &auth.APIKey{}, &auth.SecurityEvent{}); err != nil {
 		t.Fatalf("failed auto migrating auth models: %v", err)
 	}
 
@@ -288,7 +132,7 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 
 	if exists == 0 {
 		logger.LogInfo("Creating upload_status enum type", nil)
-		if err := db.Exec("CREATE TYPE upload_status AS ENUM ('pending', 'uploading', 'completed', 'failed')").Error; err != nil {
+		if err := db.Exec("CREATE TYPE upload_status AS ENUM ('pending', 'uploading', 'completed', 'failed', 'interrupted')").Error; err != nil {
 			// Ignore error if type already exists
 			if !strings.Contains(err.Error(), "already exists") {
 				t.Fatalf("failed to create upload_status enum type: %v", err)
@@ -302,6 +146,8 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		&video.VideoUpload{},
 		&video.Transcode{},
 		&video.TranscodeSegment{},
+		&video.VideoVersion{},
+		&video.VideoCaption{},
 	}
 
 	// Auto migrate video models